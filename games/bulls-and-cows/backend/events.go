@@ -0,0 +1,55 @@
+package main
+
+import (
+	"github.com/achgithub/activity-hub-common/events"
+)
+
+// Stream events. Payload field names are what the frontend reads.
+
+type StreamOpened struct {
+	Message string `json:"message"`
+	GameID  string `json:"gameId"`
+}
+
+type CodeSet struct {
+	GameID  string `json:"gameId"`
+	Player  string `json:"player"`
+	Waiting bool   `json:"waiting"`
+}
+
+type CodesReady struct {
+	GameID string `json:"gameId"`
+	Status string `json:"status"`
+}
+
+// GuessMade carries a scored guess; the code and winner are revealed once
+// the game is over
+type GuessMade struct {
+	Guess      Guess   `json:"guess"`
+	Status     string  `json:"status"`
+	SecretCode string  `json:"secretCode,omitempty"`
+	Winner     *string `json:"winner,omitempty"`
+}
+
+type GuessSubmitted struct {
+	GameID  string `json:"gameId"`
+	Turn    int    `json:"turn"`
+	Player  string `json:"player"`
+	Waiting bool   `json:"waiting"`
+}
+
+type TurnComplete struct {
+	GameID string `json:"gameId"`
+	Turn   int    `json:"turn"`
+	Status string `json:"status"`
+}
+
+var (
+	evConnected      = events.Register[StreamOpened]("connected", 1, "Stream opened")
+	evGameCreated    = events.Register[Game]("game_created", 1, "A game was created")
+	evCodeSet        = events.Register[CodeSet]("code_set", 1, "One player set their code")
+	evBothCodesSet   = events.Register[CodesReady]("both_codes_set", 1, "Both codes are set and the game has started")
+	evGuessMade      = events.Register[GuessMade]("guess_made", 1, "A guess was scored")
+	evGuessSubmitted = events.Register[GuessSubmitted]("guess_submitted", 1, "A simultaneous-mode guess is waiting for the opponent's")
+	evTurnComplete   = events.Register[TurnComplete]("turn_complete", 1, "Both simultaneous-mode guesses were scored")
+)
@@ -8,7 +8,6 @@ require (
 	github.com/google/uuid v1.3.0
 	github.com/gorilla/mux v1.8.0
 	github.com/lib/pq v1.10.7
-	github.com/rs/cors v1.8.2
 )
 
 replace github.com/achgithub/activity-hub-common => ../../../lib/activity-hub-common
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	authlib "github.com/achgithub/activity-hub-common/auth"
+	apphttp "github.com/achgithub/activity-hub-common/http"
 	"github.com/go-redis/redis/v8"
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
@@ -17,11 +18,13 @@ import (
 
 // GetConfig returns app configuration for the identity shell
 func GetConfig(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(ConfigResponse{
-		AppName:    "Bulls and Cows",
-		MinPlayers: 0,
-		MaxPlayers: 2,
+	apphttp.WriteConfig(w, apphttp.AppConfig{
+		AppID:        "bulls-and-cows",
+		Name:         "Bulls and Cows",
+		Icon:         "🐂🐄",
+		Description:  "Crack the secret code by guessing the right combination of colors or numbers",
+		Capabilities: apphttp.Capabilities{Challenges: true, SSE: true},
+		Extra:        map[string]interface{}{"minPlayers": 0, "maxPlayers": 2},
 		GameOptions: []GameOption{
 			{
 				ID:      "mode",
@@ -147,7 +150,7 @@ func CreateGame(db *sql.DB, redisClient *redis.Client) http.HandlerFunc {
 		redisClient.Set(ctx, fmt.Sprintf("game:%s", gameID), gameJSON, time.Hour)
 
 		// Publish game created event
-		PublishGameEvent(redisClient, gameID, "game_created", game)
+		PublishGameEvent(redisClient, evGameCreated.New(gameID, game))
 
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]interface{}{
@@ -119,20 +119,13 @@ func SetCode(db *sql.DB, redisClient *redis.Client) http.HandlerFunc {
 			log.Printf("Both codes set for game %s - game starting!", gameID)
 
 			// Publish game_started event to both players
-			PublishGameEvent(redisClient, gameID, "both_codes_set", map[string]interface{}{
-				"gameId": gameID,
-				"status": "active",
-			})
+			PublishGameEvent(redisClient, evBothCodesSet.New(gameID, CodesReady{GameID: gameID, Status: "active"}))
 		} else {
 			// One code set, waiting for the other
 			log.Printf("Code set for game %s - waiting for other player", gameID)
 
 			// Publish code_set event
-			PublishGameEvent(redisClient, gameID, "code_set", map[string]interface{}{
-				"gameId":  gameID,
-				"player":  userID,
-				"waiting": true,
-			})
+			PublishGameEvent(redisClient, evCodeSet.New(gameID, CodeSet{GameID: gameID, Player: userID, Waiting: true}))
 		}
 
 		w.Header().Set("Content-Type", "application/json")
@@ -186,15 +186,12 @@ func handleSoloGuess(w http.ResponseWriter, db *sql.DB, redisClient *redis.Clien
 	}
 
 	// Publish SSE event
-	eventPayload := map[string]interface{}{
-		"guess":  guessResponse,
-		"status": newStatus,
-	}
+	eventPayload := GuessMade{Guess: guessResponse, Status: newStatus}
 	if newStatus != "active" {
-		eventPayload["secretCode"] = game.SecretCode
-		eventPayload["winner"] = winner
+		eventPayload.SecretCode = game.SecretCode
+		eventPayload.Winner = winner
 	}
-	PublishGameEvent(redisClient, gameID, "guess_made", eventPayload)
+	PublishGameEvent(redisClient, evGuessMade.New(gameID, eventPayload))
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
@@ -292,11 +289,7 @@ func handleTwoPlayerGuess(w http.ResponseWriter, db *sql.DB, redisClient *redis.
 		db.QueryRow("SELECT status, winner FROM games WHERE id = $1", gameID).Scan(&newStatus, &winner)
 
 		// Publish turn_complete event
-		PublishGameEvent(redisClient, gameID, "turn_complete", map[string]interface{}{
-			"gameId": gameID,
-			"turn":   currentTurn,
-			"status": newStatus,
-		})
+		PublishGameEvent(redisClient, evTurnComplete.New(gameID, TurnComplete{GameID: gameID, Turn: currentTurn, Status: newStatus}))
 
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]interface{}{
@@ -307,12 +300,7 @@ func handleTwoPlayerGuess(w http.ResponseWriter, db *sql.DB, redisClient *redis.
 		})
 	} else {
 		// Waiting for opponent to guess
-		PublishGameEvent(redisClient, gameID, "guess_submitted", map[string]interface{}{
-			"gameId":  gameID,
-			"turn":    currentTurn,
-			"player":  userID,
-			"waiting": true,
-		})
+		PublishGameEvent(redisClient, evGuessSubmitted.New(gameID, GuessSubmitted{GameID: gameID, Turn: currentTurn, Player: userID, Waiting: true}))
 
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]interface{}{
@@ -10,11 +10,14 @@ import (
 	"path/filepath"
 
 	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/achgithub/activity-hub-common/cli"
 	"github.com/achgithub/activity-hub-common/database"
+	"github.com/achgithub/activity-hub-common/events"
+	apphttp "github.com/achgithub/activity-hub-common/http"
+	"github.com/achgithub/activity-hub-common/maintenance"
 	"github.com/go-redis/redis/v8"
 	"github.com/gorilla/mux"
 	_ "github.com/lib/pq"
-	"github.com/rs/cors"
 )
 
 var (
@@ -23,18 +26,43 @@ var (
 )
 
 func main() {
-	// Get port from environment or use default
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "4091"
-	}
+	cli.New("bulls-and-cows",
+		cli.Serve(serve),
+		cli.Migrate(openDatabase, cli.Migrations{
+			Dir:      "../database",
+			Schema:   []string{"schema_v2.sql"},
+			Upgrades: []string{"migrate_to_v2.sql"},
+		}),
+	).Main()
+}
 
+// openDatabase connects to bulls_and_cows_db
+func openDatabase() (*sql.DB, error) {
 	// Get database host from environment or use default
 	dbHost := os.Getenv("DB_HOST")
 	if dbHost == "" {
 		dbHost = "localhost"
 	}
 
+	connStr := fmt.Sprintf("host=%s port=5555 user=activityhub password=pubgames dbname=bulls_and_cows_db sslmode=disable", dbHost)
+	conn, err := sql.Open("postgres", connStr)
+	if err != nil {
+		return nil, err
+	}
+	if err := conn.Ping(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+	return conn, nil
+}
+
+func serve() {
+	// Get port from environment or use default
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "4091"
+	}
+
 	// Get Redis address from environment or use default
 	redisAddr := os.Getenv("REDIS_ADDR")
 	if redisAddr == "" {
@@ -42,18 +70,12 @@ func main() {
 	}
 
 	// Initialize database connection
-	connStr := fmt.Sprintf("host=%s port=5555 user=activityhub password=pubgames dbname=bulls_and_cows_db sslmode=disable", dbHost)
 	var err error
-	db, err = sql.Open("postgres", connStr)
+	db, err = openDatabase()
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 	defer db.Close()
-
-	// Test database connection
-	if err := db.Ping(); err != nil {
-		log.Fatalf("Failed to ping database: %v", err)
-	}
 	log.Printf("Connected to PostgreSQL database: bulls_and_cows_db")
 
 	// Initialize Redis client
@@ -88,6 +110,7 @@ func main() {
 
 	// Public endpoints
 	r.HandleFunc("/api/config", GetConfig).Methods("GET")
+	r.HandleFunc("/api/events/schema", events.SchemaHandler).Methods("GET")
 
 	// SSE endpoint (uses query-param auth)
 	r.Handle("/api/game/{gameId}/stream", sseMiddleware(http.HandlerFunc(StreamGame(redisClient)))).Methods("GET")
@@ -113,14 +136,9 @@ func main() {
 	}
 
 	// Setup CORS
-	c := cors.New(cors.Options{
-		AllowedOrigins:   []string{"*"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"*"},
-		AllowCredentials: true,
-	})
-
-	handler := c.Handler(r)
+	cors := apphttp.NewCORSPolicy(identityDB)
+	appMaintenance := maintenance.New(identityDB)
+	handler := cors.Middleware(appMaintenance.Middleware("bulls-and-cows")(apphttp.Versioned(r)))
 
 	// Start server
 	log.Printf("Bulls and Cows server starting on port %s", port)
@@ -2,12 +2,12 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
 	"time"
 
+	"github.com/achgithub/activity-hub-common/events"
 	"github.com/go-redis/redis/v8"
 )
 
@@ -18,22 +18,14 @@ type SSEClient struct {
 	events chan string
 }
 
-// SSEEvent represents an event sent to clients
-type SSEEvent struct {
-	Type    string      `json:"type"`
-	Payload interface{} `json:"payload"`
-}
-
 // StreamGameUpdates handles SSE connections for game updates
 func StreamGameUpdates(w http.ResponseWriter, r *http.Request, gameID, userID string, redisClient *redis.Client) {
 	// Set SSE headers
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
 	w.Header().Set("Connection", "keep-alive")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
 
-	flusher, ok := w.(http.Flusher)
-	if !ok {
+	if _, ok := w.(http.Flusher); !ok {
 		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
 		return
 	}
@@ -49,10 +41,7 @@ func StreamGameUpdates(w http.ResponseWriter, r *http.Request, gameID, userID st
 	log.Printf("[SSE] User %s connected to game %s stream", userID, gameID)
 
 	// Send initial connection message
-	sendSSEMessage(w, flusher, "connected", map[string]string{
-		"message": "Connected to game stream",
-		"gameId":  gameID,
-	})
+	events.Send(w, evConnected.New(gameID, StreamOpened{Message: "Connected to game stream", GameID: gameID}))
 
 	// Keepalive ticker
 	keepalive := time.NewTicker(30 * time.Second)
@@ -67,46 +56,16 @@ func StreamGameUpdates(w http.ResponseWriter, r *http.Request, gameID, userID st
 			return
 		case msg := <-ch:
 			// Forward Redis message to client
-			fmt.Fprintf(w, "data: %s\n\n", msg.Payload)
-			flusher.Flush()
+			events.Forward(w, msg.Payload)
 		case <-keepalive.C:
 			// Send keepalive
-			sendSSEMessage(w, flusher, "keepalive", map[string]int64{
-				"timestamp": time.Now().Unix(),
-			})
+			events.Send(w, events.Ping.New(gameID, events.NoPayload{}))
 		}
 	}
 }
 
-// PublishGameEvent publishes an event to all clients watching a game
-func PublishGameEvent(redisClient *redis.Client, gameID, eventType string, payload interface{}) error {
-	event := SSEEvent{
-		Type:    eventType,
-		Payload: payload,
-	}
-
-	data, err := json.Marshal(event)
-	if err != nil {
-		return fmt.Errorf("failed to marshal event: %w", err)
-	}
-
+// PublishGameEvent publishes an event to all clients watching its game
+func PublishGameEvent(redisClient *redis.Client, event events.Envelope) error {
 	ctx := context.Background()
-	return redisClient.Publish(ctx, fmt.Sprintf("game:%s", gameID), string(data)).Err()
-}
-
-// sendSSEMessage sends a formatted SSE message
-func sendSSEMessage(w http.ResponseWriter, flusher http.Flusher, eventType string, payload interface{}) {
-	event := SSEEvent{
-		Type:    eventType,
-		Payload: payload,
-	}
-
-	data, err := json.Marshal(event)
-	if err != nil {
-		log.Printf("[SSE] Error marshaling message: %v", err)
-		return
-	}
-
-	fmt.Fprintf(w, "data: %s\n\n", string(data))
-	flusher.Flush()
+	return redisClient.Publish(ctx, fmt.Sprintf("game:%s", event.Session), event.Encode()).Err()
 }
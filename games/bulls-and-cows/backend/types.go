@@ -66,14 +66,6 @@ type MakeGuessRequest struct {
 	Guess string `json:"guess"`
 }
 
-// ConfigResponse represents the app configuration
-type ConfigResponse struct {
-	AppName     string       `json:"appName"`
-	MinPlayers  int          `json:"minPlayers"`
-	MaxPlayers  int          `json:"maxPlayers"`
-	GameOptions []GameOption `json:"gameOptions"`
-}
-
 // GameOption represents a configurable game option
 type GameOption struct {
 	ID      string        `json:"id"`
@@ -1,7 +1,24 @@
 import { useEffect, useState, useCallback, useRef } from 'react';
 
+// Single-use stream token from the shell, so the session token never goes in
+// the EventSource URL. Empty on failure: the stream then rejects the connection
+// and the normal reconnect path retries.
+async function fetchStreamToken(token: string): Promise<string> {
+  try {
+    const res = await fetch(`http://${window.location.hostname}:3001/api/auth/stream-token`, {
+      method: 'POST',
+      headers: { 'Authorization': `Bearer ${token}` },
+    });
+    return res.ok ? (await res.json()).token : '';
+  } catch {
+    return '';
+  }
+}
+
 export interface SSEEvent {
   type: string;
+  version: number; // Payload schema version; see GET /api/events/schema
+  session?: string;
   payload: any;
 }
 
@@ -23,7 +40,7 @@ export function useGameSocket(
   const reconnectTimeoutRef = useRef<NodeJS.Timeout | null>(null);
   const reconnectAttemptsRef = useRef(0);
 
-  const connect = useCallback(() => {
+  const connect = useCallback(async () => {
     if (!gameId || !token) return;
 
     // Close existing connection
@@ -33,7 +50,8 @@ export function useGameSocket(
 
     const host = window.location.hostname;
     const port = window.location.port || '4091';
-    const url = `http://${host}:${port}/api/game/${gameId}/stream?token=${token}`;
+    const streamToken = await fetchStreamToken(token);
+    const url = `http://${host}:${port}/api/game/${gameId}/stream?token=${encodeURIComponent(streamToken)}`;
 
     console.log('[SSE] Connecting to:', url);
 
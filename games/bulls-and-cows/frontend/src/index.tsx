@@ -9,11 +9,35 @@ link.rel = 'stylesheet';
 link.href = `http://${window.location.hostname}:3001/shared/activity-hub.css`;
 document.head.appendChild(link);
 
+// The shell opens apps with a single-use ?launch= token. Swap it for the
+// session token before rendering, so the session token never sits in the URL.
+async function resolveLaunchToken() {
+  const params = new URLSearchParams(window.location.search);
+  const launch = params.get('launch');
+  if (!launch) return;
+  try {
+    const res = await fetch(`http://${window.location.hostname}:3001/api/auth/launch`, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({ launch }),
+    });
+    if (res.ok) {
+      const data = await res.json();
+      sessionStorage.setItem('token', data.token);
+    }
+  } catch (err) {
+    console.error('Launch token exchange failed:', err);
+  }
+  params.delete('launch');
+  const query = params.toString();
+  window.history.replaceState({}, document.title, window.location.pathname + (query ? `?${query}` : ''));
+}
+
 const root = ReactDOM.createRoot(
   document.getElementById('root') as HTMLElement
 );
-root.render(
+resolveLaunchToken().then(() => root.render(
   <React.StrictMode>
     <App />
   </React.StrictMode>
-);
+));
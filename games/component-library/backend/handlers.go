@@ -9,6 +9,7 @@ import (
 	"time"
 
 	authlib "github.com/achgithub/activity-hub-common/auth"
+	apphttp "github.com/achgithub/activity-hub-common/http"
 	"github.com/go-redis/redis/v8"
 )
 
@@ -17,12 +18,19 @@ const REDIS_PUBSUB_CHANNEL = "component_library:updates"
 
 // HandleConfig returns app configuration
 func HandleConfig(w http.ResponseWriter, r *http.Request) {
-	config := map[string]interface{}{
-		"appName": APP_NAME,
-		"appIcon": "📚",
-	}
+	apphttp.WriteConfig(w, apphttp.AppConfig{
+		AppID:        "component-library",
+		Name:         APP_NAME,
+		Icon:         "📚",
+		Description:  "Living style guide — showcases all Activity Hub CSS components with live examples and code snippets",
+		Capabilities: apphttp.Capabilities{SSE: true},
+	})
+}
+
+// HandleHealth answers the shell's status dashboard probe
+func HandleHealth(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(config)
+	w.Write([]byte(`{"status":"ok","service":"component-library"}`))
 }
 
 // HandleGetCounter returns the current counter value from Redis
@@ -6,8 +6,10 @@ import (
 	"net/http"
 
 	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/achgithub/activity-hub-common/cli"
 	"github.com/achgithub/activity-hub-common/database"
-	"github.com/gorilla/handlers"
+	apphttp "github.com/achgithub/activity-hub-common/http"
+	"github.com/achgithub/activity-hub-common/maintenance"
 	"github.com/gorilla/mux"
 	_ "github.com/lib/pq"
 )
@@ -17,6 +19,21 @@ var db *sql.DB
 const APP_NAME = "Component Library"
 
 func main() {
+	cli.New("component-library",
+		cli.Serve(serve),
+		cli.Migrate(openAppDB, cli.Migrations{
+			Dir:    "../database",
+			Schema: []string{"schema.sql"},
+		}),
+	).Main()
+}
+
+// openAppDB connects to component_library_db for commands other than serve
+func openAppDB() (*sql.DB, error) {
+	return database.InitDatabase("component_library")
+}
+
+func serve() {
 	log.Printf("📚 %s Backend Starting", APP_NAME)
 
 	// Initialize Redis
@@ -47,8 +64,9 @@ func main() {
 	// Setup router
 	r := mux.NewRouter()
 
-	// Public endpoint
+	// Public endpoints
 	r.HandleFunc("/api/config", HandleConfig).Methods("GET")
+	r.HandleFunc("/api/health", HandleHealth).Methods("GET")
 
 	// Protected endpoints (require authentication)
 	r.Handle("/api/counter", authMiddleware(http.HandlerFunc(HandleGetCounter))).Methods("GET")
@@ -58,20 +76,26 @@ func main() {
 	// SSE endpoint for real-time counter updates
 	r.Handle("/api/events", sseMiddleware(http.HandlerFunc(HandleSSE))).Methods("GET")
 
+	// Reference real-time patterns (see patterns.go)
+	r.Handle("/api/patterns/counter", authMiddleware(http.HandlerFunc(HandleGetPatternCounter))).Methods("GET")
+	r.Handle("/api/patterns/counter", authMiddleware(http.HandlerFunc(HandleUpdatePatternCounter))).Methods("POST")
+	r.Handle("/api/patterns/presence", authMiddleware(http.HandlerFunc(HandleGetPatternPresence))).Methods("GET")
+	r.Handle("/api/patterns/presence", authMiddleware(http.HandlerFunc(HandlePatternHeartbeat))).Methods("POST")
+	r.Handle("/api/patterns/presence", authMiddleware(http.HandlerFunc(HandleLeavePatternPresence))).Methods("DELETE")
+	r.Handle("/api/patterns/typing", authMiddleware(http.HandlerFunc(HandlePatternTyping))).Methods("POST")
+	r.Handle("/api/patterns/toast", authMiddleware(http.HandlerFunc(HandleSendPatternToast))).Methods("POST")
+	r.Handle("/api/patterns/events", sseMiddleware(http.HandlerFunc(HandlePatternEvents))).Methods("GET")
+
 	// Serve static files (React build output)
 	staticDir := "./static"
 	r.PathPrefix("/").Handler(http.FileServer(http.Dir(staticDir)))
 
 	// CORS configuration
-	corsHandler := handlers.CORS(
-		handlers.AllowedOrigins([]string{"*"}),
-		handlers.AllowedMethods([]string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}),
-		handlers.AllowedHeaders([]string{"Content-Type", "Authorization"}),
-		handlers.AllowCredentials(),
-	)
+	cors := apphttp.NewCORSPolicy(identityDB)
+	appMaintenance := maintenance.New(identityDB)
 
 	// Start server
 	port := "5010"
 	log.Printf("🚀 %s backend listening on :%s (Admin Only)", APP_NAME, port)
-	log.Fatal(http.ListenAndServe(":"+port, corsHandler(r)))
+	log.Fatal(http.ListenAndServe(":"+port, cors.Middleware(appMaintenance.Middleware("component-library")(apphttp.Versioned(r)))))
 }
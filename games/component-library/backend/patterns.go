@@ -0,0 +1,378 @@
+package main
+
+// Reference implementations of real-time patterns used by the production lobby.
+// New apps should copy these rather than inventing their own variants:
+//   - Optimistic counter: versioned writes with 409 + current state on conflict
+//   - Presence list: heartbeat keys with a TTL, like identity-shell user:device:* (single device)
+//   - Typing indicator: short-TTL keys plus a broadcast on change
+//   - Toast stream: per-user and broadcast notifications over one SSE connection
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	PATTERNS_COUNTER_KEY     = "component_library:patterns:counter"
+	PATTERNS_PRESENCE_PREFIX = "component_library:patterns:presence:"
+	PATTERNS_TYPING_PREFIX   = "component_library:patterns:typing:"
+	PATTERNS_CHANNEL         = "component_library:patterns:updates"
+
+	// Same TTLs as the lobby: clients heartbeat every ~10s
+	presenceTTL = 30 * time.Second
+	typingTTL   = 5 * time.Second
+)
+
+// patternsUserChannel is the per-user channel for targeted toasts
+func patternsUserChannel(email string) string {
+	return "component_library:patterns:user:" + email
+}
+
+// publishPattern broadcasts a JSON event to every patterns stream
+func publishPattern(event map[string]interface{}) {
+	data, _ := json.Marshal(event)
+	if err := redisClient.Publish(ctx, PATTERNS_CHANNEL, data).Err(); err != nil {
+		log.Printf("Failed to publish pattern event: %v", err)
+	}
+}
+
+// ----- Optimistic counter -----
+
+// CounterState is the versioned value clients hold locally
+type CounterState struct {
+	Value   int64 `json:"value"`
+	Version int64 `json:"version"`
+}
+
+func loadCounterState(tx redis.Cmdable) (CounterState, error) {
+	var state CounterState
+	fields, err := tx.HGetAll(ctx, PATTERNS_COUNTER_KEY).Result()
+	if err != nil {
+		return state, err
+	}
+	state.Value, _ = strconv.ParseInt(fields["value"], 10, 64)
+	state.Version, _ = strconv.ParseInt(fields["version"], 10, 64)
+	return state, nil
+}
+
+// HandleGetPatternCounter - GET /api/patterns/counter
+func HandleGetPatternCounter(w http.ResponseWriter, r *http.Request) {
+	state, err := loadCounterState(redisClient)
+	if err != nil {
+		http.Error(w, "Failed to load counter", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(state)
+}
+
+// HandleUpdatePatternCounter - POST /api/patterns/counter  {delta, expectedVersion}
+// The client applies the delta locally first, then sends the version it based the
+// change on. If someone else got there first the write is rejected with 409 and the
+// current state, so the client can roll back its optimistic value and retry.
+func HandleUpdatePatternCounter(w http.ResponseWriter, r *http.Request) {
+	user, ok := authlib.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req struct {
+		Delta           int64 `json:"delta"`
+		ExpectedVersion int64 `json:"expectedVersion"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Delta == 0 {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	var result CounterState
+	conflict := false
+
+	// WATCH makes the read-check-write atomic: EXEC fails if the key changed underneath us
+	err := redisClient.Watch(ctx, func(tx *redis.Tx) error {
+		current, err := loadCounterState(tx)
+		if err != nil {
+			return err
+		}
+		if current.Version != req.ExpectedVersion {
+			conflict = true
+			result = current
+			return nil
+		}
+
+		result = CounterState{Value: current.Value + req.Delta, Version: current.Version + 1}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.HSet(ctx, PATTERNS_COUNTER_KEY, "value", result.Value, "version", result.Version)
+			return nil
+		})
+		return err
+	}, PATTERNS_COUNTER_KEY)
+
+	if err == redis.TxFailedErr {
+		// Lost the race between read and write - report as a conflict with fresh state
+		conflict = true
+		result, err = loadCounterState(redisClient)
+	}
+	if err != nil {
+		log.Printf("Failed to update pattern counter: %v", err)
+		http.Error(w, "Failed to update counter", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if conflict {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "Counter changed since your last read",
+			"current": result,
+		})
+		return
+	}
+
+	publishPattern(map[string]interface{}{
+		"type":    "counter_update",
+		"value":   result.Value,
+		"version": result.Version,
+		"user":    user.Name,
+	})
+
+	json.NewEncoder(w).Encode(result)
+}
+
+// ----- Presence list -----
+
+// PatternPresence mirrors identity-shell's UserPresence shape
+type PatternPresence struct {
+	Email       string `json:"email"`
+	DisplayName string `json:"displayName"`
+	Status      string `json:"status"`
+	LastSeen    int64  `json:"lastSeen"`
+	Typing      bool   `json:"typing"`
+}
+
+// HandlePatternHeartbeat - POST /api/patterns/presence  {status}
+// Call every ~10s while the page is open; the key expires if the client goes away
+func HandlePatternHeartbeat(w http.ResponseWriter, r *http.Request) {
+	user, ok := authlib.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req struct {
+		Status string `json:"status"`
+	}
+	json.NewDecoder(r.Body).Decode(&req)
+	if req.Status == "" {
+		req.Status = "online"
+	}
+
+	key := PATTERNS_PRESENCE_PREFIX + user.Email
+	existed, _ := redisClient.Exists(ctx, key).Result()
+
+	data, _ := json.Marshal(PatternPresence{
+		Email:       user.Email,
+		DisplayName: user.Name,
+		Status:      req.Status,
+		LastSeen:    time.Now().Unix(),
+	})
+	if err := redisClient.Set(ctx, key, data, presenceTTL).Err(); err != nil {
+		http.Error(w, "Failed to update presence", http.StatusInternalServerError)
+		return
+	}
+
+	// Only broadcast joins, not every heartbeat
+	if existed == 0 {
+		publishPattern(map[string]interface{}{"type": "presence_update"})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"success": true})
+}
+
+// HandleGetPatternPresence - GET /api/patterns/presence
+func HandleGetPatternPresence(w http.ResponseWriter, r *http.Request) {
+	keys, err := redisClient.Keys(ctx, PATTERNS_PRESENCE_PREFIX+"*").Result()
+	if err != nil {
+		http.Error(w, "Failed to load presence", http.StatusInternalServerError)
+		return
+	}
+
+	users := []PatternPresence{}
+	for _, key := range keys {
+		data, err := redisClient.Get(ctx, key).Result()
+		if err != nil {
+			continue // Expired between Keys() and Get()
+		}
+
+		var p PatternPresence
+		if json.Unmarshal([]byte(data), &p) != nil {
+			continue
+		}
+		typing, _ := redisClient.Exists(ctx, PATTERNS_TYPING_PREFIX+p.Email).Result()
+		p.Typing = typing > 0
+		users = append(users, p)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"users": users,
+		"count": len(users),
+	})
+}
+
+// HandleLeavePatternPresence - DELETE /api/patterns/presence
+func HandleLeavePatternPresence(w http.ResponseWriter, r *http.Request) {
+	user, ok := authlib.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	redisClient.Del(ctx, PATTERNS_PRESENCE_PREFIX+user.Email, PATTERNS_TYPING_PREFIX+user.Email)
+	publishPattern(map[string]interface{}{"type": "presence_update"})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"success": true})
+}
+
+// ----- Typing indicator -----
+
+// HandlePatternTyping - POST /api/patterns/typing  {typing}
+// Clients send typing=true at most every few seconds while typing and typing=false
+// when they stop. The short TTL clears the indicator if the stop message is lost.
+func HandlePatternTyping(w http.ResponseWriter, r *http.Request) {
+	user, ok := authlib.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req struct {
+		Typing bool `json:"typing"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	key := PATTERNS_TYPING_PREFIX + user.Email
+	if req.Typing {
+		redisClient.Set(ctx, key, "1", typingTTL)
+	} else {
+		redisClient.Del(ctx, key)
+	}
+
+	publishPattern(map[string]interface{}{
+		"type":   "typing",
+		"email":  user.Email,
+		"name":   user.Name,
+		"typing": req.Typing,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"success": true})
+}
+
+// ----- Toast / notification stream -----
+
+// HandleSendPatternToast - POST /api/patterns/toast  {message, level, to?}
+// Omit "to" to broadcast; set it to an email to target one user
+func HandleSendPatternToast(w http.ResponseWriter, r *http.Request) {
+	user, ok := authlib.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req struct {
+		Message string `json:"message"`
+		Level   string `json:"level"`
+		To      string `json:"to"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || strings.TrimSpace(req.Message) == "" {
+		http.Error(w, "Message is required", http.StatusBadRequest)
+		return
+	}
+
+	switch req.Level {
+	case "", "info":
+		req.Level = "info"
+	case "success", "warning", "error":
+	default:
+		http.Error(w, "Level must be info, success, warning or error", http.StatusBadRequest)
+		return
+	}
+
+	toast := map[string]interface{}{
+		"type":    "toast",
+		"id":      fmt.Sprintf("%d", time.Now().UnixNano()),
+		"message": req.Message,
+		"level":   req.Level,
+		"from":    user.Name,
+	}
+
+	if req.To == "" {
+		publishPattern(toast)
+	} else {
+		data, _ := json.Marshal(toast)
+		redisClient.Publish(ctx, patternsUserChannel(req.To), data)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"success": true})
+}
+
+// HandlePatternEvents - GET /api/patterns/events
+// One SSE stream carrying broadcast events plus toasts addressed to this user,
+// with a keepalive ping like the lobby stream
+func HandlePatternEvents(w http.ResponseWriter, r *http.Request) {
+	user, ok := authlib.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "SSE not supported", http.StatusInternalServerError)
+		return
+	}
+
+	pubsub := redisClient.Subscribe(ctx, PATTERNS_CHANNEL, patternsUserChannel(user.Email))
+	defer pubsub.Close()
+
+	fmt.Fprintf(w, "data: %s\n\n", `{"type":"connected"}`)
+	flusher.Flush()
+
+	ch := pubsub.Channel()
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case msg := <-ch:
+			fmt.Fprintf(w, "data: %s\n\n", msg.Payload)
+			flusher.Flush()
+		case <-ticker.C:
+			fmt.Fprintf(w, ": ping\n\n")
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
@@ -13,6 +13,7 @@ import LoadingSection from './components/LoadingSection';
 import ModalsSection from './components/ModalsSection';
 import GameComponentsSection from './components/GameComponentsSection';
 import PatternsSection from './components/PatternsSection';
+import RealtimeSection from './components/RealtimeSection';
 
 // Parse query params from URL
 function useQueryParams() {
@@ -21,7 +22,7 @@ function useQueryParams() {
     return {
       userId: params.get('userId'),
       userName: params.get('userName') || 'Unknown',
-      token: params.get('token'),
+      token: params.get('token') || sessionStorage.getItem('token'),
     };
   }, []);
 }
@@ -38,7 +39,8 @@ type TabType =
   | 'loading'
   | 'modals'
   | 'game'
-  | 'patterns';
+  | 'patterns'
+  | 'realtime';
 
 interface Tab {
   id: TabType;
@@ -59,6 +61,7 @@ const TABS: Tab[] = [
   { id: 'modals', label: 'Modals', component: ModalsSection },
   { id: 'game', label: 'Game Components', component: GameComponentsSection },
   { id: 'patterns', label: 'Common Patterns', component: PatternsSection },
+  { id: 'realtime', label: 'Real-time Patterns', component: RealtimeSection },
 ];
 
 function App() {
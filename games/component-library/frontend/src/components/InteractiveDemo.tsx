@@ -2,6 +2,20 @@ import React, { useState, useEffect } from 'react';
 
 const API_BASE = window.location.origin;
 
+// Single-use stream token from the shell, so the session token never goes in
+// the EventSource URL. Empty on failure, which the stream rejects.
+async function fetchStreamToken(token: string): Promise<string> {
+  try {
+    const res = await fetch(`http://${window.location.hostname}:3001/api/auth/stream-token`, {
+      method: 'POST',
+      headers: { 'Authorization': `Bearer ${token}` },
+    });
+    return res.ok ? (await res.json()).token : '';
+  } catch {
+    return '';
+  }
+}
+
 interface Activity {
   userEmail: string;
   userName: string;
@@ -50,39 +64,47 @@ function InteractiveDemo({ token }: Props) {
 
   // Setup SSE connection for real-time updates
   useEffect(() => {
-    const eventSource = new EventSource(
-      `${API_BASE}/api/events?token=${encodeURIComponent(token)}`
-    );
-
-    eventSource.onmessage = (event) => {
-      try {
-        const data = JSON.parse(event.data);
-
-        if (data.type === 'counter_update') {
-          setCounter(data.counter);
-
-          // Add to activity log (optimistic update)
-          const newActivity: Activity = {
-            userEmail: '',
-            userName: data.user,
-            action: 'increment',
-            counterValue: data.counter,
-            createdAt: new Date().toISOString(),
-          };
-          setActivities((prev) => [newActivity, ...prev].slice(0, 20));
+    let current: EventSource | null = null;
+    let cancelled = false;
+
+    fetchStreamToken(token).then((streamToken) => {
+      if (cancelled) return;
+      const eventSource = new EventSource(
+        `${API_BASE}/api/events?token=${encodeURIComponent(streamToken)}`
+      );
+      current = eventSource;
+
+      eventSource.onmessage = (event) => {
+        try {
+          const data = JSON.parse(event.data);
+
+          if (data.type === 'counter_update') {
+            setCounter(data.counter);
+
+            // Add to activity log (optimistic update)
+            const newActivity: Activity = {
+              userEmail: '',
+              userName: data.user,
+              action: 'increment',
+              counterValue: data.counter,
+              createdAt: new Date().toISOString(),
+            };
+            setActivities((prev) => [newActivity, ...prev].slice(0, 20));
+          }
+        } catch (err) {
+          console.error('SSE parse error:', err);
         }
-      } catch (err) {
-        console.error('SSE parse error:', err);
-      }
-    };
+      };
 
-    eventSource.onerror = () => {
-      console.error('SSE connection error');
-      eventSource.close();
-    };
+      eventSource.onerror = () => {
+        console.error('SSE connection error');
+        eventSource.close();
+      };
+    });
 
     return () => {
-      eventSource.close();
+      cancelled = true;
+      current?.close();
     };
   }, [token]);
 
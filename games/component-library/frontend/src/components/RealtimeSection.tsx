@@ -0,0 +1,326 @@
+import React, { useState, useEffect, useRef, useCallback } from 'react';
+
+const API_BASE = window.location.origin;
+
+// Single-use stream token from the shell, so the session token never goes in
+// the EventSource URL. Empty on failure, which the stream rejects.
+async function fetchStreamToken(token: string): Promise<string> {
+  try {
+    const res = await fetch(`http://${window.location.hostname}:3001/api/auth/stream-token`, {
+      method: 'POST',
+      headers: { 'Authorization': `Bearer ${token}` },
+    });
+    return res.ok ? (await res.json()).token : '';
+  } catch {
+    return '';
+  }
+}
+
+interface Props {
+  token: string;
+}
+
+interface CounterState {
+  value: number;
+  version: number;
+}
+
+interface PresenceUser {
+  email: string;
+  displayName: string;
+  status: string;
+  lastSeen: number;
+  typing: boolean;
+}
+
+interface Toast {
+  id: string;
+  message: string;
+  level: 'info' | 'success' | 'warning' | 'error';
+  from: string;
+}
+
+const HEARTBEAT_MS = 10000;
+const TYPING_IDLE_MS = 2000;
+const TOAST_MS = 4000;
+
+function RealtimeSection({ token }: Props) {
+  const authHeaders = { Authorization: `Bearer ${token}`, 'Content-Type': 'application/json' };
+
+  const [counter, setCounter] = useState<CounterState>({ value: 0, version: 0 });
+  const [conflicts, setConflicts] = useState(0);
+  const [users, setUsers] = useState<PresenceUser[]>([]);
+  const [typingNames, setTypingNames] = useState<Record<string, string>>({});
+  const [draft, setDraft] = useState('');
+  const [toasts, setToasts] = useState<Toast[]>([]);
+
+  const typingTimer = useRef<ReturnType<typeof setTimeout> | null>(null);
+  const isTyping = useRef(false);
+
+  const loadPresence = useCallback(() => {
+    fetch(`${API_BASE}/api/patterns/presence`, { headers: { Authorization: `Bearer ${token}` } })
+      .then((res) => res.json())
+      .then((data) => setUsers(data.users || []))
+      .catch((err) => console.error('Failed to load presence:', err));
+  }, [token]);
+
+  // Initial counter + presence heartbeat
+  useEffect(() => {
+    fetch(`${API_BASE}/api/patterns/counter`, { headers: { Authorization: `Bearer ${token}` } })
+      .then((res) => res.json())
+      .then(setCounter)
+      .catch((err) => console.error('Failed to load counter:', err));
+
+    const heartbeat = () =>
+      fetch(`${API_BASE}/api/patterns/presence`, {
+        method: 'POST',
+        headers: { Authorization: `Bearer ${token}`, 'Content-Type': 'application/json' },
+        body: JSON.stringify({ status: 'online' }),
+      }).then(loadPresence);
+
+    heartbeat();
+    const interval = setInterval(heartbeat, HEARTBEAT_MS);
+
+    return () => {
+      clearInterval(interval);
+      fetch(`${API_BASE}/api/patterns/presence`, {
+        method: 'DELETE',
+        headers: { Authorization: `Bearer ${token}` },
+      });
+    };
+  }, [token, loadPresence]);
+
+  // One SSE stream for counter, presence, typing and toasts
+  useEffect(() => {
+    let current: EventSource | null = null;
+    let cancelled = false;
+
+    fetchStreamToken(token).then((streamToken) => {
+      if (cancelled) return;
+      const eventSource = new EventSource(
+        `${API_BASE}/api/patterns/events?token=${encodeURIComponent(streamToken)}`
+      );
+      current = eventSource;
+
+      eventSource.onmessage = (event) => {
+        try {
+          const data = JSON.parse(event.data);
+
+          switch (data.type) {
+            case 'counter_update':
+              // Server state is authoritative - only move forward
+              setCounter((prev) =>
+                data.version > prev.version ? { value: data.value, version: data.version } : prev
+              );
+              break;
+            case 'presence_update':
+              loadPresence();
+              break;
+            case 'typing':
+              setTypingNames((prev) => {
+                const next = { ...prev };
+                if (data.typing) {
+                  next[data.email] = data.name;
+                } else {
+                  delete next[data.email];
+                }
+                return next;
+              });
+              break;
+            case 'toast':
+              setToasts((prev) => [...prev, data]);
+              setTimeout(() => {
+                setToasts((prev) => prev.filter((t) => t.id !== data.id));
+              }, TOAST_MS);
+              break;
+          }
+        } catch (err) {
+          console.error('SSE parse error:', err);
+        }
+      };
+    });
+
+    return () => {
+      cancelled = true;
+      current?.close();
+    };
+  }, [token, loadPresence]);
+
+  // Optimistic update: apply locally, roll back to server state on 409
+  const handleCounter = async (delta: number) => {
+    const basedOn = counter;
+    setCounter({ value: basedOn.value + delta, version: basedOn.version });
+
+    try {
+      const res = await fetch(`${API_BASE}/api/patterns/counter`, {
+        method: 'POST',
+        headers: authHeaders,
+        body: JSON.stringify({ delta, expectedVersion: basedOn.version }),
+      });
+      const data = await res.json();
+
+      if (res.status === 409) {
+        setConflicts((c) => c + 1);
+        setCounter(data.current);
+        return;
+      }
+      setCounter(data);
+    } catch (err) {
+      console.error('Counter update failed:', err);
+      setCounter(basedOn);
+    }
+  };
+
+  const sendTyping = (typing: boolean) => {
+    isTyping.current = typing;
+    fetch(`${API_BASE}/api/patterns/typing`, {
+      method: 'POST',
+      headers: authHeaders,
+      body: JSON.stringify({ typing }),
+    });
+  };
+
+  // Debounced typing indicator: start on first keystroke, stop after idle
+  const handleDraftChange = (value: string) => {
+    setDraft(value);
+    if (!isTyping.current) sendTyping(true);
+    if (typingTimer.current) clearTimeout(typingTimer.current);
+    typingTimer.current = setTimeout(() => sendTyping(false), TYPING_IDLE_MS);
+  };
+
+  const handleSendToast = async (level: Toast['level']) => {
+    if (!draft.trim()) return;
+    await fetch(`${API_BASE}/api/patterns/toast`, {
+      method: 'POST',
+      headers: authHeaders,
+      body: JSON.stringify({ message: draft, level }),
+    });
+    setDraft('');
+    if (typingTimer.current) clearTimeout(typingTimer.current);
+    sendTyping(false);
+  };
+
+  const typingList = Object.values(typingNames);
+
+  return (
+    <>
+      <div className="ah-card">
+        <h2>Real-time Patterns</h2>
+        <p className="ah-meta">
+          Reference implementations that mirror the production lobby APIs. Open this tab
+          in two browsers to see them interact. Backend code: <code>backend/patterns.go</code>.
+        </p>
+      </div>
+
+      {/* Toast stack */}
+      {toasts.length > 0 && (
+        <div>
+          {toasts.map((toast) => (
+            <div key={toast.id} className={`ah-banner ah-banner--${toast.level}`}>
+              <strong>{toast.from}:</strong> {toast.message}
+            </div>
+          ))}
+        </div>
+      )}
+
+      {/* Optimistic Counter */}
+      <div className="component-section">
+        <div className="component-demo">
+          <div className="component-header">
+            <span className="component-name">Optimistic Counter</span>
+            <code className="component-class">POST /api/patterns/counter</code>
+          </div>
+          <p className="component-purpose">
+            Apply the change locally, send the version it was based on, roll back on 409
+          </p>
+          <div className="component-preview">
+            <div className="counter-display">
+              <div className="counter-value">{counter.value}</div>
+              <p className="ah-meta">
+                version {counter.version} · {conflicts} conflict(s) resolved
+              </p>
+            </div>
+            <div className="ah-flex">
+              <button className="ah-btn-outline" onClick={() => handleCounter(-1)}>− 1</button>
+              <button className="ah-btn-primary" onClick={() => handleCounter(1)}>+ 1</button>
+            </div>
+          </div>
+          <div className="component-code">
+            <pre><code>{`// Optimistic: show the new value immediately
+setCounter({ value: basedOn.value + delta, version: basedOn.version });
+
+const res = await fetch('/api/patterns/counter', {
+  method: 'POST',
+  body: JSON.stringify({ delta, expectedVersion: basedOn.version }),
+});
+if (res.status === 409) {
+  // Someone else won - adopt the server state
+  setCounter((await res.json()).current);
+}`}</code></pre>
+          </div>
+        </div>
+      </div>
+
+      {/* Presence List */}
+      <div className="component-section">
+        <div className="component-demo">
+          <div className="component-header">
+            <span className="component-name">Presence List</span>
+            <code className="component-class">POST /api/patterns/presence</code>
+          </div>
+          <p className="component-purpose">
+            Heartbeat every 10s; the 30s TTL drops users who close the tab
+          </p>
+          <div className="component-preview">
+            {users.length === 0 ? (
+              <p className="ah-meta">Nobody here yet</p>
+            ) : (
+              <div className="activity-list">
+                {users.map((u) => (
+                  <div key={u.email} className="activity-item">
+                    <div className="activity-user">
+                      🟢 {u.displayName} {u.typing && <span className="ah-meta">typing…</span>}
+                    </div>
+                    <div className="activity-time">{u.email}</div>
+                  </div>
+                ))}
+              </div>
+            )}
+          </div>
+        </div>
+      </div>
+
+      {/* Typing Indicator + Toasts */}
+      <div className="component-section">
+        <div className="component-demo">
+          <div className="component-header">
+            <span className="component-name">Typing Indicator &amp; Toasts</span>
+            <code className="component-class">POST /api/patterns/typing · /toast</code>
+          </div>
+          <p className="component-purpose">
+            Typing starts on the first keystroke and stops after 2s idle (5s server TTL as a backstop)
+          </p>
+          <div className="component-preview">
+            <input
+              className="ah-input"
+              placeholder="Type a message..."
+              value={draft}
+              onChange={(e) => handleDraftChange(e.target.value)}
+            />
+            <p className="ah-meta">
+              {typingList.length > 0 ? `${typingList.join(', ')} typing…` : ' '}
+            </p>
+            <div className="ah-flex ah-flex-wrap">
+              <button className="ah-btn-primary" onClick={() => handleSendToast('info')}>Send info toast</button>
+              <button className="ah-btn-outline" onClick={() => handleSendToast('success')}>Success</button>
+              <button className="ah-btn-outline" onClick={() => handleSendToast('warning')}>Warning</button>
+              <button className="ah-btn-danger" onClick={() => handleSendToast('error')}>Error</button>
+            </div>
+          </div>
+        </div>
+      </div>
+    </>
+  );
+}
+
+export default RealtimeSection;
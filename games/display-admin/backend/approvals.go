@@ -0,0 +1,172 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// initialContentStatus is approved for reviewers' own uploads, pending for contributors
+func initialContentStatus(user *AuthUser) string {
+	if user.CanReviewContent() {
+		return "approved"
+	}
+	return "pending"
+}
+
+// handleApproveContent approves a pending content item so it can be added to playlists
+func handleApproveContent(w http.ResponseWriter, r *http.Request) {
+	reviewContent(w, r, "approved")
+}
+
+// handleRejectContent rejects a pending content item, with an optional reason for the contributor
+func handleRejectContent(w http.ResponseWriter, r *http.Request) {
+	reviewContent(w, r, "rejected")
+}
+
+// decisionAction names each review decision in the audit log
+var decisionAction = map[string]string{"approved": "approve", "rejected": "reject"}
+
+// reviewContent records a decision on pending content and notifies the contributor
+func reviewContent(w http.ResponseWriter, r *http.Request, decision string) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	user := getUserFromContext(r)
+
+	var req struct {
+		Note string `json:"note"`
+	}
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	before := snapshot(contentSnapshot, id)
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("❌ Error starting review transaction: %v", err)
+		respondError(w, "Failed to review content", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	var contentID int
+	var title string
+	var createdBy sql.NullString
+	err = tx.QueryRow(`
+		UPDATE content_items
+		SET status = $1, review_note = NULLIF($2, ''), reviewed_by = $3,
+		    reviewed_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $4 AND status = 'pending' AND deleted_at IS NULL
+		RETURNING id, title, created_by
+	`, decision, req.Note, user.ActorEmail(), id).Scan(&contentID, &title, &createdBy)
+	if err == sql.ErrNoRows {
+		respondError(w, "Content not found or not pending review", http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Printf("❌ Error reviewing content: %v", err)
+		respondError(w, "Failed to review content", http.StatusInternalServerError)
+		return
+	}
+
+	if createdBy.Valid && createdBy.String != "" {
+		_, err = tx.Exec(`
+			INSERT INTO content_notifications (user_email, content_item_id, content_title, decision, note, decided_by)
+			VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6)
+		`, createdBy.String, contentID, title, decision, req.Note, user.ActorEmail())
+		if err != nil {
+			log.Printf("❌ Error creating content notification: %v", err)
+			respondError(w, "Failed to review content", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("❌ Error committing content review: %v", err)
+		respondError(w, "Failed to review content", http.StatusInternalServerError)
+		return
+	}
+
+	logAuditChange(r, "content_"+decisionAction[decision], id, map[string]interface{}{
+		"note": req.Note,
+	}, before, snapshot(contentSnapshot, id))
+
+	log.Printf("✅ Content %d (%s) %s by %s", contentID, title, decision, user.Email)
+	respondJSON(w, APIResponse{Success: true, Data: map[string]interface{}{
+		"id":     contentID,
+		"status": decision,
+	}})
+}
+
+// handleGetNotifications returns the user's review decisions, unread first
+func handleGetNotifications(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+
+	rows, err := db.Query(`
+		SELECT id, content_item_id, content_title, decision, COALESCE(note, ''),
+		       COALESCE(decided_by, ''), created_at, read_at
+		FROM content_notifications
+		WHERE user_email = $1
+		ORDER BY read_at IS NOT NULL, created_at DESC
+		LIMIT 50
+	`, user.Email)
+	if err != nil {
+		log.Printf("❌ Error querying notifications: %v", err)
+		respondError(w, "Failed to fetch notifications", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	notifications := []ContentNotification{}
+	for rows.Next() {
+		var n ContentNotification
+		var contentID sql.NullInt64
+		var readAt sql.NullTime
+		err := rows.Scan(&n.ID, &contentID, &n.ContentTitle, &n.Decision, &n.Note,
+			&n.DecidedBy, &n.CreatedAt, &readAt)
+		if err != nil {
+			log.Printf("❌ Error scanning notification: %v", err)
+			continue
+		}
+		if contentID.Valid {
+			v := int(contentID.Int64)
+			n.ContentItemID = &v
+		}
+		if readAt.Valid {
+			n.ReadAt = &readAt.Time
+		}
+		notifications = append(notifications, n)
+	}
+
+	respondJSON(w, APIResponse{Success: true, Data: notifications})
+}
+
+// handleMarkNotificationRead dismisses one of the user's notifications
+func handleMarkNotificationRead(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	user := getUserFromContext(r)
+
+	result, err := db.Exec(`
+		UPDATE content_notifications SET read_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND user_email = $2 AND read_at IS NULL
+	`, id, user.Email)
+	if err != nil {
+		log.Printf("❌ Error marking notification read: %v", err)
+		respondError(w, "Failed to update notification", http.StatusInternalServerError)
+		return
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		respondError(w, "Notification not found", http.StatusNotFound)
+		return
+	}
+
+	respondJSON(w, APIResponse{Success: true, Data: map[string]string{"message": "Notification marked read"}})
+}
@@ -18,8 +18,8 @@ func handleGetAssignments(w http.ResponseWriter, r *http.Request) {
 		       da.created_at, da.updated_at,
 		       d.name AS display_name, p.name AS playlist_name
 		FROM display_assignments da
-		JOIN displays d ON da.display_id = d.id
-		JOIN playlists p ON da.playlist_id = p.id
+		JOIN displays d ON da.display_id = d.id AND d.deleted_at IS NULL
+		JOIN playlists p ON da.playlist_id = p.id AND p.deleted_at IS NULL
 		ORDER BY da.created_at DESC
 	`)
 	if err != nil {
@@ -77,8 +77,8 @@ func handleGetDisplayAssignments(w http.ResponseWriter, r *http.Request) {
 		       da.created_at, da.updated_at,
 		       d.name AS display_name, p.name AS playlist_name
 		FROM display_assignments da
-		JOIN displays d ON da.display_id = d.id
-		JOIN playlists p ON da.playlist_id = p.id
+		JOIN displays d ON da.display_id = d.id AND d.deleted_at IS NULL
+		JOIN playlists p ON da.playlist_id = p.id AND p.deleted_at IS NULL
 		WHERE da.display_id = $1
 		ORDER BY da.priority DESC, da.created_at DESC
 	`, displayID)
@@ -141,8 +141,8 @@ func handleGetAssignment(w http.ResponseWriter, r *http.Request) {
 		       da.created_at, da.updated_at,
 		       d.name AS display_name, p.name AS playlist_name
 		FROM display_assignments da
-		JOIN displays d ON da.display_id = d.id
-		JOIN playlists p ON da.playlist_id = p.id
+		JOIN displays d ON da.display_id = d.id AND d.deleted_at IS NULL
+		JOIN playlists p ON da.playlist_id = p.id AND p.deleted_at IS NULL
 		WHERE da.id = $1
 	`, id).Scan(&a.ID, &a.DisplayID, &a.PlaylistID, &a.Priority,
 		&startDate, &endDate, &startTime, &endTime, &daysOfWeek,
@@ -264,6 +264,8 @@ func handleUpdateAssignment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	before := snapshot(assignmentSnapshot, id)
+
 	var assignment DisplayAssignment
 	var startDate, endDate sql.NullTime
 	var startTime, endTime, daysOfWeek sql.NullString
@@ -315,6 +317,8 @@ func handleUpdateAssignment(w http.ResponseWriter, r *http.Request) {
 		assignment.DaysOfWeek = &daysOfWeek.String
 	}
 
+	logAuditChange(r, "assignment_update", id, nil, before, snapshot(assignmentSnapshot, id))
+
 	log.Printf("✅ Updated assignment ID: %s", id)
 	respondJSON(w, APIResponse{Success: true, Data: assignment})
 }
@@ -324,6 +328,7 @@ func handleDeleteAssignment(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
+	before := snapshot(assignmentSnapshot, id)
 	result, err := db.Exec("DELETE FROM display_assignments WHERE id = $1", id)
 	if err != nil {
 		log.Printf("❌ Error deleting assignment: %v", err)
@@ -337,6 +342,8 @@ func handleDeleteAssignment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	logAuditChange(r, "assignment_delete", id, nil, before, nil)
+
 	log.Printf("✅ Deleted assignment ID: %s", id)
 	respondJSON(w, APIResponse{Success: true, Data: map[string]string{"message": "Assignment deleted"}})
 }
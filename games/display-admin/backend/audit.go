@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/achgithub/activity-hub-common/audit"
+)
+
+// auditLog is display_admin_db's audit_log, served at GET /api/audit
+var auditLog *audit.Log
+
+// Snapshot queries for rows whose changes are audited with before/after
+// copies. Each selects one row by $1.
+const (
+	contentSnapshot = `SELECT * FROM content_items WHERE id = $1`
+	// A playlist with its items in order, so adding, removing or reordering
+	// items shows up as a change to the playlist
+	playlistSnapshot = `
+		SELECT p.id, p.guid, p.name, p.description, p.is_active, p.deleted_at, p.deleted_by,
+		       COALESCE((
+		           SELECT json_agg(json_build_object(
+		                      'contentItemId', pi.content_item_id, 'overrideDuration', pi.override_duration
+		                  ) ORDER BY pi.display_order, pi.id)
+		           FROM playlist_items pi WHERE pi.playlist_id = p.id
+		       ), '[]') AS items
+		FROM playlists p
+		WHERE p.id = $1`
+	// Never the token - it would let anyone reading the log play the display.
+	// With its zone bindings, so layout changes show up as display changes.
+	displaySnapshot = `
+		SELECT d.id, d.guid, d.name, d.location, d.description, d.is_active, d.venue_id,
+		       d.after_hours_playlist_id, d.ticker_id, d.layout,
+		       COALESCE((
+		           SELECT json_agg(json_build_object(
+		                      'zone', z.zone, 'playlistId', z.playlist_id, 'contentItemId', z.content_item_id
+		                  ) ORDER BY z.zone)
+		           FROM display_zones z WHERE z.display_id = d.id
+		       ), '[]') AS zones,
+		       d.token_rotated_at, d.revoked_at, d.deleted_at, d.deleted_by
+		FROM displays d WHERE d.id = $1`
+	assignmentSnapshot = `SELECT * FROM display_assignments WHERE id = $1`
+	// A ticker's settings and messages, audited against the ticker's content item
+	tickerSnapshot = `
+		SELECT c.id, c.title, COALESCE(s.activity_feed, FALSE) AS activity_feed,
+		       COALESCE((
+		           SELECT json_agg(json_build_object(
+		                      'id', m.id, 'text', m.text, 'isActive', m.is_active,
+		                      'startsAt', m.starts_at, 'endsAt', m.ends_at
+		                  ) ORDER BY m.created_at, m.id)
+		           FROM ticker_messages m WHERE m.content_item_id = c.id
+		       ), '[]') AS messages
+		FROM content_items c
+		LEFT JOIN ticker_settings s ON s.content_item_id = c.id
+		WHERE c.id = $1`
+	photoSnapshot = `SELECT * FROM photo_submissions WHERE id = $1`
+)
+
+// snapshot copies a row for the audit log, or returns nil if it can't be
+// read - the change itself has already happened or is about to.
+func snapshot(query string, id interface{}) json.RawMessage {
+	row, err := audit.Snapshot(db, query, id)
+	if err != nil {
+		log.Printf("⚠️  Failed to snapshot %v for audit log: %v", id, err)
+	}
+	return row
+}
+
+// logAuditChange records an admin change with the row before and after, from
+// snapshot. before is nil for something created, after for something deleted.
+func logAuditChange(r *http.Request, actionType, targetID string, details map[string]interface{}, before, after json.RawMessage) {
+	e := audit.Entry{Action: actionType, TargetID: targetID, Before: before, After: after}
+	if user := getUserFromContext(r); user != nil {
+		e.AdminEmail = user.Email
+		e.ImpersonatedBy = user.ImpersonatedBy
+	}
+	if details != nil {
+		e.Details, _ = json.Marshal(details)
+	}
+	if err := auditLog.Record(e); err != nil {
+		log.Printf("⚠️  Failed to log audit action: %v", err)
+	}
+}
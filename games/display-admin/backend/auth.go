@@ -6,6 +6,8 @@ import (
 	"log"
 	"net/http"
 	"strings"
+
+	"github.com/lib/pq"
 )
 
 // AuthUser represents an authenticated user
@@ -13,6 +15,36 @@ type AuthUser struct {
 	Email   string
 	Name    string
 	IsAdmin bool
+	VenueID int // 0 = chain-wide
+	Roles   []string
+
+	ImpersonatedBy string // super_user behind an impersonate- token
+}
+
+// ActorEmail is who really made the request: the super_user when
+// impersonating. Record it in created_by, reviewed_by and the other *_by
+// columns.
+func (u *AuthUser) ActorEmail() string {
+	if u.ImpersonatedBy != "" {
+		return u.ImpersonatedBy
+	}
+	return u.Email
+}
+
+// HasRole reports whether the user has the given identity role
+func (u *AuthUser) HasRole(role string) bool {
+	for _, r := range u.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// CanReviewContent reports whether the user approves contributor content
+// (display admins and setup admins)
+func (u *AuthUser) CanReviewContent() bool {
+	return u.IsAdmin || u.HasRole("setup_admin")
 }
 
 // Context key for storing authenticated user
@@ -41,25 +73,41 @@ func AuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
 			return
 		}
 
-		// Extract token (format: "Bearer demo-token-{email}")
+		// Extract token (format: "Bearer demo-token-{email}" or "Bearer impersonate-{uuid}")
 		token := strings.TrimPrefix(authHeader, "Bearer ")
-		if !strings.HasPrefix(token, "demo-token-") {
+		var email, impersonatedBy string
+		switch {
+		case strings.HasPrefix(token, "demo-token-"):
+			email = strings.TrimPrefix(token, "demo-token-")
+		case strings.HasPrefix(token, "impersonate-"):
+			err := identityDB.QueryRow(`
+				SELECT impersonated_email, super_user_email
+				FROM impersonation_sessions
+				WHERE impersonation_token = $1 AND is_active = true
+			`, token).Scan(&email, &impersonatedBy)
+			if err == sql.ErrNoRows {
+				log.Printf("❌ Invalid or expired impersonation token")
+				http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
+				return
+			} else if err != nil {
+				log.Printf("❌ Database error during auth: %v", err)
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+		default:
 			log.Printf("❌ Invalid token format: %s", token)
 			http.Error(w, "Invalid token format", http.StatusUnauthorized)
 			return
 		}
-
-		// Extract email from token
-		email := strings.TrimPrefix(token, "demo-token-")
 		log.Printf("🔍 Validating token for user: %s", email)
 
 		// Query user from identity database
-		var user AuthUser
+		user := AuthUser{ImpersonatedBy: impersonatedBy}
 		err := identityDB.QueryRow(`
-			SELECT email, name, is_admin
+			SELECT email, name, is_admin, COALESCE(venue_id, 0), COALESCE(roles, '{}')
 			FROM users
 			WHERE email = $1
-		`, email).Scan(&user.Email, &user.Name, &user.IsAdmin)
+		`, email).Scan(&user.Email, &user.Name, &user.IsAdmin, &user.VenueID, pq.Array(&user.Roles))
 
 		if err == sql.ErrNoRows {
 			log.Printf("❌ User not found in identity database: %s", email)
@@ -100,6 +148,46 @@ func AdminMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+// ContributorMiddleware allows admins and display_contributor users
+// Must be chained after AuthMiddleware
+func ContributorMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := getUserFromContext(r)
+		if user == nil {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		if !user.IsAdmin && !user.HasRole("display_contributor") {
+			log.Printf("❌ User %s attempted contributor action without role", user.Email)
+			http.Error(w, "Admin or display_contributor access required", http.StatusForbidden)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// ReviewerMiddleware allows users who approve contributor content
+// Must be chained after AuthMiddleware
+func ReviewerMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := getUserFromContext(r)
+		if user == nil {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		if !user.CanReviewContent() {
+			log.Printf("❌ User %s attempted to review content", user.Email)
+			http.Error(w, "Display admin or setup_admin access required", http.StatusForbidden)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
 // getUserFromContext extracts authenticated user from request context
 func getUserFromContext(r *http.Request) *AuthUser {
 	user, ok := r.Context().Value(userContextKey).(AuthUser)
@@ -108,3 +196,25 @@ func getUserFromContext(r *http.Request) *AuthUser {
 	}
 	return &user
 }
+
+// userVenueID returns the authenticated user's venue (0 = chain-wide or unauthenticated)
+func userVenueID(r *http.Request) int {
+	if user := getUserFromContext(r); user != nil {
+		return user.VenueID
+	}
+	return 0
+}
+
+// handleGetMe returns the signed-in user's permissions so the UI can show
+// contributor or reviewer controls
+func handleGetMe(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	respondJSON(w, APIResponse{Success: true, Data: map[string]interface{}{
+		"email":       user.Email,
+		"name":        user.Name,
+		"is_admin":    user.IsAdmin,
+		"roles":       user.Roles,
+		"can_review":  user.CanReviewContent(),
+		"contributor": user.HasRole("display_contributor"),
+	}})
+}
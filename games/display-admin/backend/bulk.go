@@ -0,0 +1,319 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/lib/pq"
+)
+
+// handleAddPlaylistItems appends several content items to a playlist in one
+// transaction. Either every item is added (in the order given) or none are;
+// items already in the playlist are skipped.
+func handleAddPlaylistItems(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	playlistID := vars["id"]
+
+	var req struct {
+		ContentItemIDs   []int `json:"content_item_ids"`
+		OverrideDuration *int  `json:"override_duration"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if len(req.ContentItemIDs) == 0 {
+		respondError(w, "content_item_ids is required", http.StatusBadRequest)
+		return
+	}
+
+	before := snapshot(playlistSnapshot, playlistID)
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("❌ Error starting transaction: %v", err)
+		respondError(w, "Failed to add items to playlist", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	err = tx.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM playlists WHERE id = $1 AND deleted_at IS NULL)
+	`, playlistID).Scan(&exists)
+	if err != nil {
+		log.Printf("❌ Error checking playlist: %v", err)
+		respondError(w, "Failed to add items to playlist", http.StatusInternalServerError)
+		return
+	}
+	if !exists {
+		respondError(w, "Playlist not found", http.StatusNotFound)
+		return
+	}
+
+	// Every item has to be live and approved before anything is added
+	rows, err := tx.Query(`
+		SELECT id, status, content_type FROM content_items WHERE id = ANY($1) AND deleted_at IS NULL
+	`, pq.Array(req.ContentItemIDs))
+	if err != nil {
+		log.Printf("❌ Error fetching content status: %v", err)
+		respondError(w, "Failed to add items to playlist", http.StatusInternalServerError)
+		return
+	}
+	statuses := map[int]string{}
+	tickers := map[int]bool{}
+	for rows.Next() {
+		var id int
+		var status, contentType string
+		if err := rows.Scan(&id, &status, &contentType); err != nil {
+			continue
+		}
+		statuses[id] = status
+		tickers[id] = contentType == "ticker"
+	}
+	rows.Close()
+
+	for _, id := range req.ContentItemIDs {
+		status, ok := statuses[id]
+		if !ok {
+			respondError(w, fmt.Sprintf("Content %d not found", id), http.StatusNotFound)
+			return
+		}
+		if status != "approved" {
+			respondError(w, fmt.Sprintf("Content %d must be approved before it can be added to a playlist", id), http.StatusConflict)
+			return
+		}
+		if tickers[id] {
+			respondError(w, fmt.Sprintf("Content %d is a ticker - tickers are set on a display, not added to a playlist", id), http.StatusBadRequest)
+			return
+		}
+	}
+
+	var maxOrder sql.NullInt32
+	if err := tx.QueryRow("SELECT MAX(display_order) FROM playlist_items WHERE playlist_id = $1", playlistID).Scan(&maxOrder); err != nil {
+		log.Printf("❌ Error fetching playlist order: %v", err)
+		respondError(w, "Failed to add items to playlist", http.StatusInternalServerError)
+		return
+	}
+	nextOrder := 0
+	if maxOrder.Valid {
+		nextOrder = int(maxOrder.Int32) + 1
+	}
+
+	added := 0
+	for _, id := range req.ContentItemIDs {
+		result, err := tx.Exec(`
+			INSERT INTO playlist_items (playlist_id, content_item_id, display_order, override_duration)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (playlist_id, content_item_id) DO NOTHING
+		`, playlistID, id, nextOrder, req.OverrideDuration)
+		if err != nil {
+			log.Printf("❌ Error adding playlist item: %v", err)
+			respondError(w, "Failed to add items to playlist", http.StatusInternalServerError)
+			return
+		}
+		if n, _ := result.RowsAffected(); n > 0 {
+			added++
+			nextOrder++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("❌ Error committing transaction: %v", err)
+		respondError(w, "Failed to add items to playlist", http.StatusInternalServerError)
+		return
+	}
+
+	invalidatePlaylist(playlistID)
+	logAuditChange(r, "playlist_items_add", playlistID, map[string]interface{}{
+		"content_item_ids": req.ContentItemIDs,
+	}, before, snapshot(playlistSnapshot, playlistID))
+
+	log.Printf("✅ Added %d content items to playlist %s", added, playlistID)
+	respondJSON(w, APIResponse{Success: true, Data: map[string]int{
+		"added":   added,
+		"skipped": len(req.ContentItemIDs) - added,
+	}})
+}
+
+// handleClonePlaylist copies a playlist and its items (order and duration
+// overrides included) into a new playlist owned by the current admin.
+// Content in the trash isn't copied.
+func handleClonePlaylist(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	sourceID := vars["id"]
+
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req struct {
+		Name string `json:"name"`
+	}
+
+	// The body is optional; the copy is named after the original by default
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("❌ Error starting transaction: %v", err)
+		respondError(w, "Failed to clone playlist", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	var sourceName, description string
+	err = tx.QueryRow(`
+		SELECT name, COALESCE(description, '') FROM playlists WHERE id = $1 AND deleted_at IS NULL
+	`, sourceID).Scan(&sourceName, &description)
+	if err == sql.ErrNoRows {
+		respondError(w, "Playlist not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Printf("❌ Error fetching playlist: %v", err)
+		respondError(w, "Failed to clone playlist", http.StatusInternalServerError)
+		return
+	}
+
+	name := req.Name
+	if name == "" {
+		name = sourceName + " (copy)"
+	}
+
+	var playlist Playlist
+	var createdBy sql.NullString
+	err = tx.QueryRow(`
+		INSERT INTO playlists (name, description, created_by, is_active)
+		VALUES ($1, $2, $3, true)
+		RETURNING id, name, description, is_active, created_by, created_at, updated_at, guid::text
+	`, name, description, user.ActorEmail()).Scan(
+		&playlist.ID, &playlist.Name, &playlist.Description, &playlist.IsActive,
+		&createdBy, &playlist.CreatedAt, &playlist.UpdatedAt, &playlist.Guid,
+	)
+	if err != nil {
+		log.Printf("❌ Error creating playlist copy: %v", err)
+		respondError(w, "Failed to clone playlist", http.StatusInternalServerError)
+		return
+	}
+	playlist.CreatedBy = createdBy.String
+
+	// Renumber from 0 so gaps left by trashed content close up
+	_, err = tx.Exec(`
+		INSERT INTO playlist_items (playlist_id, content_item_id, display_order, override_duration)
+		SELECT $1, pi.content_item_id,
+		       ROW_NUMBER() OVER (ORDER BY pi.display_order) - 1,
+		       pi.override_duration
+		FROM playlist_items pi
+		JOIN content_items c ON pi.content_item_id = c.id AND c.deleted_at IS NULL
+		WHERE pi.playlist_id = $2
+	`, playlist.ID, sourceID)
+	if err != nil {
+		log.Printf("❌ Error copying playlist items: %v", err)
+		respondError(w, "Failed to clone playlist", http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("❌ Error committing transaction: %v", err)
+		respondError(w, "Failed to clone playlist", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("✅ Cloned playlist %s as %s by %s", sourceID, playlist.Name, user.Email)
+	respondJSON(w, APIResponse{Success: true, Data: playlist})
+}
+
+// handleCopyAssignments copies one display's assignments (schedule and
+// priority included) onto another, optionally replacing what the target
+// display already has. Assignments to playlists in the trash are left behind.
+func handleCopyAssignments(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		FromDisplayID int  `json:"from_display_id"`
+		ToDisplayID   int  `json:"to_display_id"`
+		Replace       bool `json:"replace"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.FromDisplayID == 0 || req.ToDisplayID == 0 {
+		respondError(w, "from_display_id and to_display_id are required", http.StatusBadRequest)
+		return
+	}
+	if req.FromDisplayID == req.ToDisplayID {
+		respondError(w, "Source and target display must differ", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("❌ Error starting transaction: %v", err)
+		respondError(w, "Failed to copy assignments", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	// Both displays must be live and in the admin's venue
+	var found int
+	err = tx.QueryRow(`
+		SELECT COUNT(*) FROM displays
+		WHERE id IN ($1, $2) AND deleted_at IS NULL AND ($3 = 0 OR venue_id = $3)
+	`, req.FromDisplayID, req.ToDisplayID, userVenueID(r)).Scan(&found)
+	if err != nil {
+		log.Printf("❌ Error checking displays: %v", err)
+		respondError(w, "Failed to copy assignments", http.StatusInternalServerError)
+		return
+	}
+	if found != 2 {
+		respondError(w, "Display not found", http.StatusNotFound)
+		return
+	}
+
+	if req.Replace {
+		if _, err := tx.Exec("DELETE FROM display_assignments WHERE display_id = $1", req.ToDisplayID); err != nil {
+			log.Printf("❌ Error clearing assignments: %v", err)
+			respondError(w, "Failed to copy assignments", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	result, err := tx.Exec(`
+		INSERT INTO display_assignments (display_id, playlist_id, priority,
+		                                  start_date, end_date, start_time, end_time, days_of_week)
+		SELECT $1, da.playlist_id, da.priority,
+		       da.start_date, da.end_date, da.start_time, da.end_time, da.days_of_week
+		FROM display_assignments da
+		JOIN playlists p ON da.playlist_id = p.id AND p.deleted_at IS NULL
+		WHERE da.display_id = $2
+		ORDER BY da.id
+	`, req.ToDisplayID, req.FromDisplayID)
+	if err != nil {
+		log.Printf("❌ Error copying assignments: %v", err)
+		respondError(w, "Failed to copy assignments", http.StatusInternalServerError)
+		return
+	}
+	copied, _ := result.RowsAffected()
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("❌ Error committing transaction: %v", err)
+		respondError(w, "Failed to copy assignments", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("✅ Copied %d assignments: display %d -> display %d", copied, req.FromDisplayID, req.ToDisplayID)
+	respondJSON(w, APIResponse{Success: true, Data: map[string]int64{"copied": copied}})
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/achgithub/activity-hub-common/bus"
+	redislib "github.com/achgithub/activity-hub-common/redis"
+	"github.com/redis/go-redis/v9"
+)
+
+// Queued display commands go through the event bus rather than straight to
+// the push hub. If display admin stops between queuing a command and pushing
+// it, the consumer group picks up from the last command it handled when it
+// comes back. TVs that reconnected meanwhile already got it from their
+// backlog, which the status check below skips.
+
+// busClient is nil when Redis is unavailable; commands are then pushed
+// straight to the hub
+var busClient *redis.Client
+
+func initBus() {
+	client, err := redislib.InitRedis()
+	if err != nil {
+		log.Printf("⚠️  Event bus unavailable, display commands are pushed directly: %v", err)
+		return
+	}
+	busClient = client
+	go bus.NewConsumer(client, "display-admin", handleCommandEvent, bus.StreamDisplayCommands).Run(context.Background())
+}
+
+// queueCommand puts a new command on the bus, reporting whether it got there
+func queueCommand(cmd DisplayCommand) bool {
+	if busClient == nil {
+		return false
+	}
+	ev := bus.DisplayCommandQueued.New("", bus.DisplayCommand{CommandID: cmd.ID, DisplayID: cmd.DisplayID, Command: cmd.Command})
+	if _, err := bus.Publish(context.Background(), busClient, bus.StreamDisplayCommands, ev); err != nil {
+		log.Printf("⚠️  Failed to queue command %d on the bus: %v", cmd.ID, err)
+		return false
+	}
+	return true
+}
+
+// handleCommandEvent pushes a queued command to the display's open streams.
+// Commands already delivered (the TV reconnected and read its backlog) or
+// expired are skipped; an offline TV gets it from the backlog on reconnect.
+func handleCommandEvent(ctx context.Context, msg bus.Message) error {
+	queued, err := bus.DisplayCommandQueued.Payload(msg.Event)
+	if err != nil {
+		log.Printf("⚠️  Ignoring bus event %s: %v", msg.ID, err)
+		return nil
+	}
+
+	cmd, err := scanCommand(db.QueryRow(`SELECT `+commandColumns+` FROM display_commands WHERE id = $1`, queued.CommandID))
+	if err == sql.ErrNoRows {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if cmd.Status != "pending" || cmd.CreatedAt.Before(time.Now().Add(-commandTTL)) {
+		return nil
+	}
+
+	if displayPush.publish(cmd) > 0 {
+		markDelivered(cmd.ID)
+		log.Printf("📤 Pushed %s to display %d", cmd.Command, cmd.DisplayID)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/achgithub/activity-hub-common/cache"
+	redislib "github.com/achgithub/activity-hub-common/redis"
+	"github.com/redis/go-redis/v9"
+)
+
+// TVs poll their playlist, so the playlists they show are cached in Redis
+// (which playlist is active is still worked out per request, as it depends on
+// the time of day). Every write to a playlist, its items or their content
+// drops the affected entries; the TTL bounds anything missed.
+const cacheTTL = 5 * time.Minute
+
+// readCache is nil when Redis is unavailable, in which case playlists are
+// loaded from the database every time
+var readCache *cache.Cache
+
+// redisStore adapts the go-redis client to cache.Store
+type redisStore struct {
+	client *redis.Client
+}
+
+func (s redisStore) Get(ctx context.Context, key string) (string, error) {
+	v, err := s.client.Get(ctx, key).Result()
+	if err == redis.Nil {
+		return "", cache.ErrMiss
+	}
+	return v, err
+}
+
+func (s redisStore) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	return s.client.Set(ctx, key, value, ttl).Err()
+}
+
+func (s redisStore) Del(ctx context.Context, keys ...string) error {
+	return s.client.Del(ctx, keys...).Err()
+}
+
+func initCache() {
+	client, err := redislib.InitRedis()
+	if err != nil {
+		log.Printf("⚠️  Redis not available, playlists will not be cached: %v", err)
+		return
+	}
+	readCache = cache.New(redisStore{client}, "cache:display-admin:", cacheTTL)
+	log.Println("✅ Connected to Redis (playlist cache)")
+}
+
+// invalidatePlaylist drops a playlist's cached content
+func invalidatePlaylist(playlistID string) {
+	if err := readCache.Invalidate(context.Background(), "playlist:"+playlistID); err != nil {
+		log.Printf("⚠️  Failed to invalidate cached playlist %s: %v", playlistID, err)
+	}
+}
+
+// invalidateContentPlaylists drops the cached playlists a content item is in
+func invalidateContentPlaylists(contentID string) {
+	if readCache == nil {
+		return
+	}
+	rows, err := db.Query("SELECT DISTINCT playlist_id FROM playlist_items WHERE content_item_id = $1", contentID)
+	if err != nil {
+		log.Printf("⚠️  Failed to find playlists for content %s: %v", contentID, err)
+		return
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var playlistID string
+		if rows.Scan(&playlistID) == nil {
+			keys = append(keys, "playlist:"+playlistID)
+		}
+	}
+	if err := readCache.Invalidate(context.Background(), keys...); err != nil {
+		log.Printf("⚠️  Failed to invalidate cached playlists for content %s: %v", contentID, err)
+	}
+}
@@ -0,0 +1,425 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Remote commands an admin can send to a display
+const (
+	commandRefresh    = "refresh"     // Re-fetch the playlist now
+	commandClearCache = "clear_cache" // Drop cached content, then refresh
+	commandReboot     = "reboot"      // Reload the player app
+)
+
+var validCommands = map[string]bool{commandRefresh: true, commandClearCache: true, commandReboot: true}
+
+// Commands not picked up within this long (display offline) are expired rather
+// than run when the display reconnects hours later
+const commandTTL = 10 * time.Minute
+
+// pushHub fans display commands out to connected TVs (display ID → streams)
+type pushHub struct {
+	mu      sync.Mutex
+	streams map[int]map[chan DisplayCommand]bool
+}
+
+var displayPush = &pushHub{streams: map[int]map[chan DisplayCommand]bool{}}
+
+func (h *pushHub) subscribe(displayID int) chan DisplayCommand {
+	ch := make(chan DisplayCommand, 8)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.streams[displayID] == nil {
+		h.streams[displayID] = map[chan DisplayCommand]bool{}
+	}
+	h.streams[displayID][ch] = true
+	return ch
+}
+
+func (h *pushHub) unsubscribe(displayID int, ch chan DisplayCommand) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.streams[displayID], ch)
+	if len(h.streams[displayID]) == 0 {
+		delete(h.streams, displayID)
+	}
+}
+
+// publish sends a command to every open stream for the display, returning
+// how many received it (0 = offline; it's delivered on reconnect)
+func (h *pushHub) publish(cmd DisplayCommand) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	sent := 0
+	for ch := range h.streams[cmd.DisplayID] {
+		select {
+		case ch <- cmd:
+			sent++
+		default:
+			log.Printf("⚠️ Push stream for display %d is full, dropping command %d", cmd.DisplayID, cmd.ID)
+		}
+	}
+	return sent
+}
+
+// disconnect closes every open stream for the display (its token was
+// rotated or revoked), telling the TV to stop
+func (h *pushHub) disconnect(displayID int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.streams[displayID] {
+		close(ch)
+	}
+	delete(h.streams, displayID)
+}
+
+func (h *pushHub) connected(displayID int) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.streams[displayID]) > 0
+}
+
+const commandColumns = `id, display_id, command, status, COALESCE(message, ''), COALESCE(created_by, ''),
+	created_at, delivered_at, acknowledged_at`
+
+func scanCommand(row interface{ Scan(...interface{}) error }) (DisplayCommand, error) {
+	var c DisplayCommand
+	var deliveredAt, acknowledgedAt sql.NullTime
+	err := row.Scan(&c.ID, &c.DisplayID, &c.Command, &c.Status, &c.Message, &c.CreatedBy,
+		&c.CreatedAt, &deliveredAt, &acknowledgedAt)
+	if deliveredAt.Valid {
+		c.DeliveredAt = &deliveredAt.Time
+	}
+	if acknowledgedAt.Valid {
+		c.AcknowledgedAt = &acknowledgedAt.Time
+	}
+	return c, err
+}
+
+// markDelivered records that a command reached the display's stream
+func markDelivered(commandID int) {
+	_, err := db.Exec(`
+		UPDATE display_commands SET status = 'delivered', delivered_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND status = 'pending'
+	`, commandID)
+	if err != nil {
+		log.Printf("❌ Error marking command %d delivered: %v", commandID, err)
+	}
+}
+
+// handleSendDisplayCommand queues a command for a display, to be pushed if the display is connected
+func handleSendDisplayCommand(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req struct {
+		Command string `json:"command"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if !validCommands[req.Command] {
+		respondError(w, "Command must be refresh, clear_cache or reboot", http.StatusBadRequest)
+		return
+	}
+
+	var displayID int
+	err := db.QueryRow("SELECT id FROM displays WHERE id = $1 AND ($2 = 0 OR venue_id = $2) AND deleted_at IS NULL", id, userVenueID(r)).Scan(&displayID)
+	if err == sql.ErrNoRows {
+		respondError(w, "Display not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Printf("❌ Error fetching display: %v", err)
+		respondError(w, "Failed to fetch display", http.StatusInternalServerError)
+		return
+	}
+
+	createdBy := ""
+	if user := getUserFromContext(r); user != nil {
+		createdBy = user.ActorEmail()
+	}
+
+	cmd, err := scanCommand(db.QueryRow(`
+		INSERT INTO display_commands (display_id, command, created_by)
+		VALUES ($1, $2, $3)
+		RETURNING `+commandColumns,
+		displayID, req.Command, createdBy))
+	if err != nil {
+		log.Printf("❌ Error creating display command: %v", err)
+		respondError(w, "Failed to create command", http.StatusInternalServerError)
+		return
+	}
+
+	// Pushed by the bus consumer (see bus.go), or here if the bus is down
+	if queueCommand(cmd) {
+		log.Printf("📤 Queued %s for display %d", cmd.Command, displayID)
+		respondJSON(w, APIResponse{Success: true, Data: cmd})
+		return
+	}
+	if displayPush.publish(cmd) > 0 {
+		markDelivered(cmd.ID)
+		cmd.Status = "delivered"
+	}
+
+	log.Printf("📤 Sent %s to display %d (status: %s)", cmd.Command, displayID, cmd.Status)
+	respondJSON(w, APIResponse{Success: true, Data: cmd})
+}
+
+// handleGetDisplayCommands returns a display's recent commands with their acknowledgement status
+func handleGetDisplayCommands(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var displayID int
+	err := db.QueryRow("SELECT id FROM displays WHERE id = $1 AND ($2 = 0 OR venue_id = $2) AND deleted_at IS NULL", id, userVenueID(r)).Scan(&displayID)
+	if err == sql.ErrNoRows {
+		respondError(w, "Display not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Printf("❌ Error fetching display: %v", err)
+		respondError(w, "Failed to fetch display", http.StatusInternalServerError)
+		return
+	}
+
+	expireStaleCommands(displayID)
+
+	rows, err := db.Query(`
+		SELECT `+commandColumns+`
+		FROM display_commands
+		WHERE display_id = $1
+		ORDER BY created_at DESC
+		LIMIT 20
+	`, displayID)
+	if err != nil {
+		log.Printf("❌ Error querying display commands: %v", err)
+		respondError(w, "Failed to fetch commands", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	commands := []DisplayCommand{}
+	for rows.Next() {
+		c, err := scanCommand(rows)
+		if err != nil {
+			log.Printf("❌ Error scanning display command: %v", err)
+			continue
+		}
+		commands = append(commands, c)
+	}
+
+	respondJSON(w, APIResponse{Success: true, Data: map[string]interface{}{
+		"connected": displayPush.connected(displayID),
+		"commands":  commands,
+	}})
+}
+
+// expireStaleCommands gives up on commands the display never picked up
+func expireStaleCommands(displayID int) {
+	_, err := db.Exec(`
+		UPDATE display_commands SET status = 'expired'
+		WHERE display_id = $1 AND status = 'pending' AND created_at < $2
+	`, displayID, time.Now().Add(-commandTTL))
+	if err != nil {
+		log.Printf("❌ Error expiring display commands: %v", err)
+	}
+}
+
+// displayIDForToken looks up an active display by its TV token
+func displayIDForToken(token string) (int, error) {
+	var displayID int
+	err := db.QueryRow("SELECT id FROM displays WHERE token = $1 AND is_active = true AND deleted_at IS NULL", token).Scan(&displayID)
+	return displayID, err
+}
+
+// handleDisplayStream is the push channel TVs hold open (Server-Sent Events).
+// Commands still pending from while the display was offline are sent first.
+func handleDisplayStream(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	token := vars["token"]
+
+	displayID, err := displayIDForToken(token)
+	if err == sql.ErrNoRows {
+		respondError(w, "Display not found or inactive", http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Printf("❌ Error fetching display by token: %v", err)
+		respondError(w, "Failed to fetch display", http.StatusInternalServerError)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		respondError(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	// Subscribe before reading the backlog so nothing sent in between is missed
+	ch := displayPush.subscribe(displayID)
+	defer displayPush.unsubscribe(displayID, ch)
+	log.Printf("📺 Display %d connected to push channel", displayID)
+
+	// A command sent while we read the backlog arrives both ways; send it once
+	sent := map[int]bool{}
+	send := func(cmd DisplayCommand) bool {
+		if sent[cmd.ID] {
+			return true
+		}
+		sent[cmd.ID] = true
+		data, _ := json.Marshal(cmd)
+		if _, err := fmt.Fprintf(w, "event: command\ndata: %s\n\n", data); err != nil {
+			return false
+		}
+		flusher.Flush()
+		markDelivered(cmd.ID)
+		return true
+	}
+
+	expireStaleCommands(displayID)
+	rows, err := db.Query(`
+		SELECT `+commandColumns+`
+		FROM display_commands
+		WHERE display_id = $1 AND status = 'pending'
+		ORDER BY created_at
+	`, displayID)
+	if err != nil {
+		log.Printf("❌ Error querying pending commands: %v", err)
+		return
+	}
+	pending := []DisplayCommand{}
+	for rows.Next() {
+		if c, err := scanCommand(rows); err == nil {
+			pending = append(pending, c)
+		}
+	}
+	rows.Close()
+	for _, cmd := range pending {
+		if !send(cmd) {
+			return
+		}
+	}
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	connectionID := openConnection(displayID)
+	defer closeConnection(connectionID)
+
+	keepAlive := time.NewTicker(30 * time.Second)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			log.Printf("📺 Display %d disconnected from push channel", displayID)
+			return
+		case cmd, ok := <-ch:
+			if !ok {
+				// Token rotated or revoked: the TV must pair again
+				log.Printf("🔒 Display %d stream closed: token no longer valid", displayID)
+				fmt.Fprint(w, "event: revoked\ndata: {}\n\n")
+				flusher.Flush()
+				return
+			}
+			if !send(cmd) {
+				return
+			}
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keepalive\n\n")
+			flusher.Flush()
+			touchConnection(connectionID)
+		}
+	}
+}
+
+// openConnection records a TV connecting its push stream (uptime)
+func openConnection(displayID int) int {
+	var id int
+	if err := db.QueryRow(
+		`INSERT INTO display_connections (display_id) VALUES ($1) RETURNING id`, displayID,
+	).Scan(&id); err != nil {
+		log.Printf("⚠️  Failed to record connection for display %d: %v", displayID, err)
+	}
+	return id
+}
+
+// touchConnection marks a connection still up after a keepalive went through
+func touchConnection(connectionID int) {
+	if connectionID == 0 {
+		return
+	}
+	db.Exec(`UPDATE display_connections SET last_seen_at = NOW() WHERE id = $1`, connectionID)
+}
+
+// closeConnection records the stream closing
+func closeConnection(connectionID int) {
+	if connectionID == 0 {
+		return
+	}
+	db.Exec(`UPDATE display_connections SET last_seen_at = NOW(), disconnected_at = NOW() WHERE id = $1`, connectionID)
+}
+
+// handleAckDisplayCommand records a TV's result for a command (done or failed)
+func handleAckDisplayCommand(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	token := vars["token"]
+	commandID, err := strconv.Atoi(vars["commandId"])
+	if err != nil {
+		respondError(w, "Invalid command ID", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		Status  string `json:"status"` // done | failed
+		Message string `json:"message"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Status != "done" && req.Status != "failed" {
+		respondError(w, "Status must be done or failed", http.StatusBadRequest)
+		return
+	}
+
+	displayID, err := displayIDForToken(token)
+	if err == sql.ErrNoRows {
+		respondError(w, "Display not found or inactive", http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Printf("❌ Error fetching display by token: %v", err)
+		respondError(w, "Failed to fetch display", http.StatusInternalServerError)
+		return
+	}
+
+	cmd, err := scanCommand(db.QueryRow(`
+		UPDATE display_commands
+		SET status = $1, message = NULLIF($2, ''), acknowledged_at = CURRENT_TIMESTAMP,
+		    delivered_at = COALESCE(delivered_at, CURRENT_TIMESTAMP)
+		WHERE id = $3 AND display_id = $4 AND status IN ('pending', 'delivered')
+		RETURNING `+commandColumns,
+		req.Status, req.Message, commandID, displayID))
+	if err == sql.ErrNoRows {
+		respondError(w, "Command not found or already acknowledged", http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Printf("❌ Error acknowledging display command: %v", err)
+		respondError(w, "Failed to acknowledge command", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("✅ Display %d acknowledged %s: %s", displayID, cmd.Command, cmd.Status)
+	respondJSON(w, APIResponse{Success: true, Data: cmd})
+}
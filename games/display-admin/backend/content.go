@@ -4,38 +4,52 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
-	"io"
 	"log"
 	"net/http"
 	"os"
 	"path/filepath"
-	"strings"
 	"time"
 
+	"github.com/achgithub/activity-hub-common/listquery"
+	"github.com/achgithub/activity-hub-common/upload"
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 )
 
-// handleGetContent returns all content items (with optional type filtering)
+// contentList is what the content library can be sorted and filtered by
+var contentList = listquery.Spec{
+	Sorts: map[string]string{
+		"created": "created_at",
+		"updated": "updated_at",
+		"title":   "title",
+	},
+	DefaultSort: "-created",
+	Tiebreak:    "id",
+	Filters: map[string]listquery.Filter{
+		"type":   listquery.Equals("content_type"),
+		"status": listquery.Equals("status"),
+		"q":      listquery.Search("title"),
+	},
+}
+
+// handleGetContent returns a page of content items (with optional type, status and title filtering).
+// Contributors only see their own submissions.
 func handleGetContent(w http.ResponseWriter, r *http.Request) {
-	contentType := r.URL.Query().Get("type")
+	q, err := listquery.Parse(r.URL.Query(), contentList)
+	if err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	q.Where("deleted_at IS NULL")
+	if user := getUserFromContext(r); user != nil && !user.CanReviewContent() {
+		q.Where("created_by = ?", user.Email)
+	}
 
-	query := `
+	query, args := q.SQL(`
 		SELECT id, title, content_type, duration_seconds, file_path, url,
 		       text_content, bg_color, text_color, is_active, created_by,
-		       created_at, updated_at
-		FROM content_items
-		WHERE 1=1
-	`
-	args := []interface{}{}
-
-	if contentType != "" {
-		query += " AND content_type = $1"
-		args = append(args, contentType)
-	}
-
-	query += " ORDER BY created_at DESC"
-
+		       created_at, updated_at, status, COALESCE(review_note, ''), COALESCE(reviewed_by, ''), guid::text
+		FROM content_items`)
 	rows, err := db.Query(query, args...)
 	if err != nil {
 		log.Printf("❌ Error querying content: %v", err)
@@ -51,7 +65,8 @@ func handleGetContent(w http.ResponseWriter, r *http.Request) {
 
 		err := rows.Scan(&c.ID, &c.Title, &c.ContentType, &c.DurationSeconds,
 			&filePath, &url, &textContent, &bgColor, &textColor,
-			&c.IsActive, &createdBy, &c.CreatedAt, &c.UpdatedAt)
+			&c.IsActive, &createdBy, &c.CreatedAt, &c.UpdatedAt,
+			&c.Status, &c.ReviewNote, &c.ReviewedBy, &c.Guid)
 		if err != nil {
 			log.Printf("❌ Error scanning content: %v", err)
 			continue
@@ -66,8 +81,9 @@ func handleGetContent(w http.ResponseWriter, r *http.Request) {
 
 		content = append(content, c)
 	}
+	content, page := listquery.Finish(q, content)
 
-	respondJSON(w, APIResponse{Success: true, Data: content})
+	respondJSON(w, APIResponse{Success: true, Data: content, Page: &page})
 }
 
 // handleCreateContent creates a new content item
@@ -90,7 +106,7 @@ func handleCreateContent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	validTypes := []string{"image", "url", "social_feed", "leaderboard", "schedule", "announcement"}
+	validTypes := []string{"image", "url", "social_feed", "leaderboard", "schedule", "announcement", "activity_feed", "medal_table", "ticker", "photo_wall"}
 	isValidType := false
 	for _, t := range validTypes {
 		if req.ContentType == t {
@@ -111,18 +127,19 @@ func handleCreateContent(w http.ResponseWriter, r *http.Request) {
 	var filePath, url, textContent, bgColor, textColor, createdBy sql.NullString
 	err := db.QueryRow(`
 		INSERT INTO content_items (title, content_type, duration_seconds, file_path, url,
-		                           text_content, bg_color, text_color, created_by, is_active)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, true)
+		                           text_content, bg_color, text_color, created_by, is_active, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, true, $10)
 		RETURNING id, title, content_type, duration_seconds, file_path, url,
 		          text_content, bg_color, text_color, is_active, created_by,
-		          created_at, updated_at
+		          created_at, updated_at, status, COALESCE(review_note, ''), COALESCE(reviewed_by, ''), guid::text
 	`, req.Title, req.ContentType, req.DurationSeconds, nullString(req.FilePath),
 		nullString(req.URL), nullString(req.TextContent), nullString(req.BgColor),
-		nullString(req.TextColor), user.Email).Scan(
+		nullString(req.TextColor), user.ActorEmail(), initialContentStatus(user)).Scan(
 		&content.ID, &content.Title, &content.ContentType, &content.DurationSeconds,
 		&filePath, &url, &textContent, &bgColor,
 		&textColor, &content.IsActive, &createdBy,
 		&content.CreatedAt, &content.UpdatedAt,
+		&content.Status, &content.ReviewNote, &content.ReviewedBy, &content.Guid,
 	)
 
 	if err != nil {
@@ -138,7 +155,7 @@ func handleCreateContent(w http.ResponseWriter, r *http.Request) {
 	content.TextColor = textColor.String
 	content.CreatedBy = createdBy.String
 
-	log.Printf("✅ Created content: %s (type: %s) by %s", content.Title, content.ContentType, user.Email)
+	log.Printf("✅ Created content: %s (type: %s, %s) by %s", content.Title, content.ContentType, content.Status, user.Email)
 	respondJSON(w, APIResponse{Success: true, Data: content})
 }
 
@@ -150,30 +167,17 @@ func handleUploadImage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Parse multipart form (10MB max)
-	if err := r.ParseMultipartForm(10 << 20); err != nil {
-		respondError(w, "Failed to parse form", http.StatusBadRequest)
-		return
-	}
-
-	file, header, err := r.FormFile("image")
+	// Type and size come from the file's content; images are re-encoded
+	file, err := upload.Read(w, r, "image", upload.Images)
 	if err != nil {
-		respondError(w, "Image file is required", http.StatusBadRequest)
-		return
-	}
-	defer file.Close()
-
-	// Validate content type
-	contentType := header.Header.Get("Content-Type")
-	if !strings.HasPrefix(contentType, "image/") {
-		respondError(w, "File must be an image", http.StatusBadRequest)
+		respondError(w, err.Error(), upload.StatusCode(err))
 		return
 	}
 
 	// Get form fields
 	title := r.FormValue("title")
 	if title == "" {
-		title = header.Filename
+		title = file.Name
 	}
 
 	durationSeconds := 10 // Default
@@ -182,8 +186,7 @@ func handleUploadImage(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Generate unique filename
-	ext := filepath.Ext(header.Filename)
-	filename := fmt.Sprintf("%d-%s%s", time.Now().Unix(), uuid.New().String()[:8], ext)
+	filename := fmt.Sprintf("%d-%s%s", time.Now().Unix(), uuid.New().String()[:8], file.Ext)
 	filePath := filepath.Join("./uploads", filename)
 
 	// Ensure uploads directory exists
@@ -194,15 +197,7 @@ func handleUploadImage(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Save file
-	dst, err := os.Create(filePath)
-	if err != nil {
-		log.Printf("❌ Error creating file: %v", err)
-		respondError(w, "Failed to save file", http.StatusInternalServerError)
-		return
-	}
-	defer dst.Close()
-
-	if _, err := io.Copy(dst, file); err != nil {
+	if err := os.WriteFile(filePath, file.Data, 0644); err != nil {
 		log.Printf("❌ Error writing file: %v", err)
 		respondError(w, "Failed to save file", http.StatusInternalServerError)
 		return
@@ -214,16 +209,17 @@ func handleUploadImage(w http.ResponseWriter, r *http.Request) {
 	// Create content item in database
 	var content ContentItem
 	err = db.QueryRow(`
-		INSERT INTO content_items (title, content_type, duration_seconds, file_path, created_by, is_active)
-		VALUES ($1, 'image', $2, $3, $4, true)
+		INSERT INTO content_items (title, content_type, duration_seconds, file_path, created_by, is_active, status)
+		VALUES ($1, 'image', $2, $3, $4, true, $5)
 		RETURNING id, title, content_type, duration_seconds, file_path, url,
 		          text_content, bg_color, text_color, is_active, created_by,
-		          created_at, updated_at
-	`, title, durationSeconds, relPath, user.Email).Scan(
+		          created_at, updated_at, status, COALESCE(review_note, ''), COALESCE(reviewed_by, ''), guid::text
+	`, title, durationSeconds, relPath, user.ActorEmail(), initialContentStatus(user)).Scan(
 		&content.ID, &content.Title, &content.ContentType, &content.DurationSeconds,
 		&content.FilePath, &content.URL, &content.TextContent, &content.BgColor,
 		&content.TextColor, &content.IsActive, &content.CreatedBy,
 		&content.CreatedAt, &content.UpdatedAt,
+		&content.Status, &content.ReviewNote, &content.ReviewedBy, &content.Guid,
 	)
 
 	if err != nil {
@@ -248,12 +244,13 @@ func handleGetContentItem(w http.ResponseWriter, r *http.Request) {
 	err := db.QueryRow(`
 		SELECT id, title, content_type, duration_seconds, file_path, url,
 		       text_content, bg_color, text_color, is_active, created_by,
-		       created_at, updated_at
+		       created_at, updated_at, status, COALESCE(review_note, ''), COALESCE(reviewed_by, ''), guid::text
 		FROM content_items
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`, id).Scan(&c.ID, &c.Title, &c.ContentType, &c.DurationSeconds,
 		&filePath, &url, &textContent, &bgColor, &textColor,
-		&c.IsActive, &createdBy, &c.CreatedAt, &c.UpdatedAt)
+		&c.IsActive, &createdBy, &c.CreatedAt, &c.UpdatedAt,
+		&c.Status, &c.ReviewNote, &c.ReviewedBy, &c.Guid)
 
 	if err == sql.ErrNoRows {
 		respondError(w, "Content not found", http.StatusNotFound)
@@ -286,6 +283,8 @@ func handleUpdateContent(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Update with COALESCE to keep existing values if not provided
+	before := snapshot(contentSnapshot, id)
+
 	var content ContentItem
 	var filePath, url, textContent, bgColor, textColor, createdBy sql.NullString
 
@@ -300,15 +299,16 @@ func handleUpdateContent(w http.ResponseWriter, r *http.Request) {
 		    text_color = COALESCE(NULLIF($7, ''), text_color),
 		    is_active = COALESCE($8, is_active),
 		    updated_at = CURRENT_TIMESTAMP
-		WHERE id = $9
+		WHERE id = $9 AND deleted_at IS NULL
 		RETURNING id, title, content_type, duration_seconds, file_path, url,
 		          text_content, bg_color, text_color, is_active, created_by,
-		          created_at, updated_at
+		          created_at, updated_at, status, COALESCE(review_note, ''), COALESCE(reviewed_by, ''), guid::text
 	`, req.Title, req.DurationSeconds, req.FilePath, req.URL, req.TextContent,
 		req.BgColor, req.TextColor, &req.IsActive, id).Scan(
 		&content.ID, &content.Title, &content.ContentType, &content.DurationSeconds,
 		&filePath, &url, &textContent, &bgColor, &textColor,
 		&content.IsActive, &createdBy, &content.CreatedAt, &content.UpdatedAt,
+		&content.Status, &content.ReviewNote, &content.ReviewedBy, &content.Guid,
 	)
 
 	if err == sql.ErrNoRows {
@@ -327,20 +327,24 @@ func handleUpdateContent(w http.ResponseWriter, r *http.Request) {
 	content.TextColor = textColor.String
 	content.CreatedBy = createdBy.String
 
+	invalidateContentPlaylists(id)
+	logAuditChange(r, "content_update", id, nil, before, snapshot(contentSnapshot, id))
+
 	log.Printf("✅ Updated content: %s", content.Title)
 	respondJSON(w, APIResponse{Success: true, Data: content})
 }
 
-// handleDeleteContent deletes a content item
+// handleDeleteContent moves a content item to the trash; it drops out of the
+// playlists it is in until restored. Uploaded files are kept until the purge.
 func handleDeleteContent(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	// Get file path before deleting (to clean up uploaded file)
-	var filePath sql.NullString
-	db.QueryRow("SELECT file_path FROM content_items WHERE id = $1", id).Scan(&filePath)
-
-	result, err := db.Exec("DELETE FROM content_items WHERE id = $1", id)
+	before := snapshot(contentSnapshot, id)
+	result, err := db.Exec(`
+		UPDATE content_items SET deleted_at = CURRENT_TIMESTAMP, deleted_by = $2
+		WHERE id = $1 AND deleted_at IS NULL
+	`, id, deletedBy(r))
 	if err != nil {
 		log.Printf("❌ Error deleting content: %v", err)
 		respondError(w, "Failed to delete content", http.StatusInternalServerError)
@@ -353,18 +357,11 @@ func handleDeleteContent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Clean up uploaded file if it exists
-	if filePath.Valid && filePath.String != "" {
-		// Convert relative path to filesystem path
-		fsPath := strings.TrimPrefix(filePath.String, "/uploads/")
-		fsPath = filepath.Join("./uploads", fsPath)
-		if err := os.Remove(fsPath); err != nil {
-			log.Printf("⚠️  Warning: Could not delete file: %s", fsPath)
-		}
-	}
+	invalidateContentPlaylists(id)
+	logAuditChange(r, "content_delete", id, nil, before, snapshot(contentSnapshot, id))
 
-	log.Printf("✅ Deleted content ID: %s", id)
-	respondJSON(w, APIResponse{Success: true, Data: map[string]string{"message": "Content deleted"}})
+	log.Printf("🗑️  Moved content ID %s to trash", id)
+	respondJSON(w, APIResponse{Success: true, Data: map[string]string{"message": "Content moved to trash"}})
 }
 
 // nullString converts empty string to NULL for database
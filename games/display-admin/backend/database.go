@@ -28,13 +28,9 @@ func InitDatabases() error {
 	log.Println("✅ Connected to identity database (pubgames)")
 
 	// Connect to display admin database
-	db, err = sql.Open("postgres", "host=localhost port=5555 user=pubgames password=pubgames dbname=display_admin_db sslmode=disable")
+	db, err = openAppDB()
 	if err != nil {
-		return fmt.Errorf("failed to connect to display_admin_db: %w", err)
-	}
-
-	if err = db.Ping(); err != nil {
-		return fmt.Errorf("failed to ping display_admin_db: %w", err)
+		return err
 	}
 
 	log.Println("✅ Connected to display_admin_db")
@@ -47,6 +43,26 @@ func InitDatabases() error {
 	return nil
 }
 
+// openAppDB connects to display_admin_db
+func openAppDB() (*sql.DB, error) {
+	conn, err := sql.Open("postgres", "host=localhost port=5555 user=pubgames password=pubgames dbname=display_admin_db sslmode=disable")
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to display_admin_db: %w", err)
+	}
+
+	if err = conn.Ping(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to ping display_admin_db: %w", err)
+	}
+	return conn, nil
+}
+
+// migrateTables is the migrate command: createTables without the server
+func migrateTables(conn *sql.DB) error {
+	db = conn
+	return createTables()
+}
+
 // createTables creates all required tables if they don't exist
 func createTables() error {
 	schema := `
@@ -63,19 +79,50 @@ func createTables() error {
 
 	CREATE INDEX IF NOT EXISTS idx_displays_token ON displays(token);
 
+	-- Venue the display is installed at (NULL = chain-wide)
+	ALTER TABLE displays ADD COLUMN IF NOT EXISTS venue_id INTEGER;
+	CREATE INDEX IF NOT EXISTS idx_displays_venue ON displays(venue_id);
+
+	-- Shown instead of the scheduled playlists while the venue is closed
+	ALTER TABLE displays ADD COLUMN IF NOT EXISTS after_hours_playlist_id INTEGER;
+
+	-- Ticker content item scrolled along the bottom over whatever is playing
+	ALTER TABLE displays ADD COLUMN IF NOT EXISTS ticker_id INTEGER;
+
+	-- Screen layout template (layouts.go): fullscreen, sidebar, ticker, sidebar_ticker
+	ALTER TABLE displays ADD COLUMN IF NOT EXISTS layout VARCHAR(30) NOT NULL DEFAULT 'fullscreen';
+
+	-- Token lifecycle: rotated on pairing or on demand, revoked for lost devices
+	ALTER TABLE displays ADD COLUMN IF NOT EXISTS token_rotated_at TIMESTAMP;
+	ALTER TABLE displays ADD COLUMN IF NOT EXISTS revoked_at TIMESTAMP;
+
+	-- Stable IDs across environments (serial IDs differ between Pi and dev)
+	ALTER TABLE displays ADD COLUMN IF NOT EXISTS guid UUID NOT NULL DEFAULT gen_random_uuid() UNIQUE;
+
+	-- Short-lived numeric codes a TV exchanges for its token during setup
+	CREATE TABLE IF NOT EXISTS display_pairing_codes (
+		code VARCHAR(6) PRIMARY KEY,
+		display_id INTEGER NOT NULL REFERENCES displays(id) ON DELETE CASCADE,
+		created_by VARCHAR(255),           -- Admin email
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		expires_at TIMESTAMP NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_display_pairing_codes_display ON display_pairing_codes(display_id);
+
 	-- Content items (images, URLs, announcements, etc.)
 	CREATE TABLE IF NOT EXISTS content_items (
 		id SERIAL PRIMARY KEY,
 		title VARCHAR(255) NOT NULL,
-		content_type VARCHAR(50) NOT NULL, -- image, url, social_feed, leaderboard, schedule, announcement
+		content_type VARCHAR(50) NOT NULL, -- image, url, social_feed, leaderboard, schedule, announcement, activity_feed, medal_table, ticker, photo_wall
 		duration_seconds INTEGER NOT NULL DEFAULT 10,
 
 		-- Type-specific fields (use appropriate field based on content_type)
 		file_path VARCHAR(500),           -- For image: path to uploaded file
 		url TEXT,                          -- For url, social_feed, leaderboard, schedule
 		text_content TEXT,                 -- For announcement
-		bg_color VARCHAR(20),              -- For announcement background
-		text_color VARCHAR(20),            -- For announcement text color
+		bg_color VARCHAR(20),              -- For announcement and ticker background
+		text_color VARCHAR(20),            -- For announcement and ticker text color
 
 		is_active BOOLEAN DEFAULT true,
 		created_by VARCHAR(255),           -- Admin email who created this
@@ -83,6 +130,52 @@ func createTables() error {
 		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 	);
 
+	-- Approval workflow: display_contributor uploads start pending until a reviewer
+	-- approves them (existing content and admin uploads are approved)
+	ALTER TABLE content_items ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'approved'; -- approved, pending, rejected
+	ALTER TABLE content_items ADD COLUMN IF NOT EXISTS review_note TEXT;
+	ALTER TABLE content_items ADD COLUMN IF NOT EXISTS reviewed_by VARCHAR(255);
+	ALTER TABLE content_items ADD COLUMN IF NOT EXISTS reviewed_at TIMESTAMP;
+	CREATE INDEX IF NOT EXISTS idx_content_items_status ON content_items(status);
+	ALTER TABLE content_items ADD COLUMN IF NOT EXISTS guid UUID NOT NULL DEFAULT gen_random_uuid() UNIQUE;
+
+	-- Review decisions for contributors to see
+	CREATE TABLE IF NOT EXISTS content_notifications (
+		id SERIAL PRIMARY KEY,
+		user_email VARCHAR(255) NOT NULL,  -- Contributor who submitted the content
+		content_item_id INTEGER REFERENCES content_items(id) ON DELETE SET NULL,
+		content_title VARCHAR(255) NOT NULL, -- Kept if the content is later deleted
+		decision VARCHAR(20) NOT NULL,     -- approved, rejected
+		note TEXT,
+		decided_by VARCHAR(255),
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		read_at TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_content_notifications_user ON content_notifications(user_email, read_at);
+
+	-- Messages a ticker content item scrolls, each optionally limited to a window
+	CREATE TABLE IF NOT EXISTS ticker_messages (
+		id SERIAL PRIMARY KEY,
+		content_item_id INTEGER NOT NULL REFERENCES content_items(id) ON DELETE CASCADE,
+		text TEXT NOT NULL,
+		is_active BOOLEAN DEFAULT true,
+		starts_at TIMESTAMPTZ,             -- NULL = from now
+		ends_at TIMESTAMPTZ,               -- NULL = until removed
+		created_by VARCHAR(255),           -- Admin email
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_ticker_messages_content ON ticker_messages(content_item_id);
+
+	-- Per-ticker settings: also scroll the venue's activity feed (game results etc.)
+	CREATE TABLE IF NOT EXISTS ticker_settings (
+		content_item_id INTEGER PRIMARY KEY REFERENCES content_items(id) ON DELETE CASCADE,
+		activity_feed BOOLEAN NOT NULL DEFAULT false,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
 	-- Playlists (ordered sequences of content)
 	CREATE TABLE IF NOT EXISTS playlists (
 		id SERIAL PRIMARY KEY,
@@ -94,6 +187,17 @@ func createTables() error {
 		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 	);
 
+	ALTER TABLE playlists ADD COLUMN IF NOT EXISTS guid UUID NOT NULL DEFAULT gen_random_uuid() UNIQUE;
+
+	-- Trash: deletes set deleted_at; rows are restorable until purged after the
+	-- retention period (TRASH_RETENTION_DAYS)
+	ALTER TABLE displays ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+	ALTER TABLE displays ADD COLUMN IF NOT EXISTS deleted_by VARCHAR(255);
+	ALTER TABLE content_items ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+	ALTER TABLE content_items ADD COLUMN IF NOT EXISTS deleted_by VARCHAR(255);
+	ALTER TABLE playlists ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+	ALTER TABLE playlists ADD COLUMN IF NOT EXISTS deleted_by VARCHAR(255);
+
 	-- Links content to playlists with ordering
 	CREATE TABLE IF NOT EXISTS playlist_items (
 		id SERIAL PRIMARY KEY,
@@ -129,6 +233,81 @@ func createTables() error {
 
 	CREATE INDEX IF NOT EXISTS idx_display_assignments_display ON display_assignments(display_id);
 	CREATE INDEX IF NOT EXISTS idx_display_assignments_priority ON display_assignments(display_id, priority DESC);
+
+	-- What each zone of a display's layout shows: a playlist, or one content item as a
+	-- live widget. Unbound zones fall back (main to the schedule, ticker to displays.ticker_id)
+	CREATE TABLE IF NOT EXISTS display_zones (
+		display_id INTEGER NOT NULL REFERENCES displays(id) ON DELETE CASCADE,
+		zone VARCHAR(20) NOT NULL,         -- main, sidebar, ticker
+		playlist_id INTEGER REFERENCES playlists(id) ON DELETE SET NULL,
+		content_item_id INTEGER REFERENCES content_items(id) ON DELETE SET NULL,
+		PRIMARY KEY (display_id, zone),
+		CHECK (playlist_id IS NULL OR content_item_id IS NULL)
+	);
+
+	-- Photos signed-in users send in for the photo wall (e.g. on quiz night). They
+	-- wait for a reviewer, then photo_wall content shows them until they expire.
+	CREATE TABLE IF NOT EXISTS photo_submissions (
+		id SERIAL PRIMARY KEY,
+		file_path VARCHAR(500),            -- Cleared on rejection, when the file is deleted
+		caption VARCHAR(140),
+		submitted_by VARCHAR(255) NOT NULL,
+		venue_id INTEGER,                  -- Venue whose walls show it (NULL = every wall)
+		status VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending, approved, rejected
+		review_note TEXT,
+		reviewed_by VARCHAR(255),
+		reviewed_at TIMESTAMP,
+		expires_at TIMESTAMP NOT NULL,     -- Deleted, with its file, after this
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_photo_submissions_status ON photo_submissions(status, venue_id);
+	CREATE INDEX IF NOT EXISTS idx_photo_submissions_expires ON photo_submissions(expires_at);
+	CREATE INDEX IF NOT EXISTS idx_photo_submissions_submitter ON photo_submissions(submitted_by, created_at DESC);
+
+	-- Remote commands pushed to displays, with the TV's acknowledgement
+	CREATE TABLE IF NOT EXISTS display_commands (
+		id SERIAL PRIMARY KEY,
+		display_id INTEGER NOT NULL REFERENCES displays(id) ON DELETE CASCADE,
+		command VARCHAR(20) NOT NULL,      -- refresh, clear_cache, reboot
+		status VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending, delivered, done, failed, expired
+		message TEXT,                      -- Optional detail from the TV (e.g. failure reason)
+		created_by VARCHAR(255),           -- Admin email
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		delivered_at TIMESTAMP,
+		acknowledged_at TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_display_commands_display ON display_commands(display_id, created_at DESC);
+
+	-- Each time a TV holds its push stream open; uptime is the time covered.
+	-- last_seen_at moves on with every keepalive, so a connection that dropped
+	-- without closing (power cut) still ends about when it did.
+	CREATE TABLE IF NOT EXISTS display_connections (
+		id SERIAL PRIMARY KEY,
+		display_id INTEGER NOT NULL REFERENCES displays(id) ON DELETE CASCADE,
+		connected_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		last_seen_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		disconnected_at TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_display_connections_display ON display_connections(display_id, connected_at);
+
+	-- Admin changes, with the changed row before and after (activity-hub-common audit)
+	CREATE TABLE IF NOT EXISTS audit_log (
+		id SERIAL PRIMARY KEY,
+		admin_email VARCHAR(255) NOT NULL,
+		impersonated_by VARCHAR(255),
+		action_type VARCHAR(50) NOT NULL,  -- content_update, playlist_reorder, display_delete, ...
+		target_id VARCHAR(100) NOT NULL,
+		details JSONB,
+		before_snapshot JSONB,             -- NULL for something created
+		after_snapshot JSONB,              -- NULL for something deleted
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_audit_log_created ON audit_log(created_at DESC);
+	CREATE INDEX IF NOT EXISTS idx_audit_log_target ON audit_log(target_id, created_at DESC);
 	`
 
 	_, err := db.Exec(schema)
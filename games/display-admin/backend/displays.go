@@ -11,13 +11,14 @@ import (
 	"github.com/gorilla/mux"
 )
 
-// handleGetDisplays returns all displays
+// handleGetDisplays returns all displays in the admin's venue (all venues for chain-wide admins)
 func handleGetDisplays(w http.ResponseWriter, r *http.Request) {
 	rows, err := db.Query(`
-		SELECT id, name, location, description, token, is_active, created_at
+		SELECT `+displayColumns+`
 		FROM displays
+		WHERE ($1 = 0 OR venue_id = $1) AND deleted_at IS NULL
 		ORDER BY created_at DESC
-	`)
+	`, userVenueID(r))
 	if err != nil {
 		log.Printf("❌ Error querying displays: %v", err)
 		respondError(w, "Failed to fetch displays", http.StatusInternalServerError)
@@ -27,8 +28,7 @@ func handleGetDisplays(w http.ResponseWriter, r *http.Request) {
 
 	displays := []Display{}
 	for rows.Next() {
-		var d Display
-		err := rows.Scan(&d.ID, &d.Name, &d.Location, &d.Description, &d.Token, &d.IsActive, &d.CreatedAt)
+		d, err := scanDisplay(rows)
 		if err != nil {
 			log.Printf("❌ Error scanning display: %v", err)
 			continue
@@ -60,15 +60,17 @@ func handleCreateDisplay(w http.ResponseWriter, r *http.Request) {
 	// Generate unique token
 	token := uuid.New().String()
 
-	var display Display
-	err := db.QueryRow(`
-		INSERT INTO displays (name, location, description, token, is_active)
-		VALUES ($1, $2, $3, $4, true)
-		RETURNING id, name, location, description, token, is_active, created_at
-	`, req.Name, req.Location, req.Description, token).Scan(
-		&display.ID, &display.Name, &display.Location, &display.Description,
-		&display.Token, &display.IsActive, &display.CreatedAt,
-	)
+	// Displays belong to the creating admin's venue (NULL for chain-wide admins)
+	var venueID interface{}
+	if v := userVenueID(r); v != 0 {
+		venueID = v
+	}
+
+	display, err := scanDisplay(db.QueryRow(`
+		INSERT INTO displays (name, location, description, token, is_active, venue_id)
+		VALUES ($1, $2, $3, $4, true, $5)
+		RETURNING `+displayColumns,
+		req.Name, req.Location, req.Description, token, venueID))
 
 	if err != nil {
 		log.Printf("❌ Error creating display: %v", err)
@@ -85,13 +87,11 @@ func handleGetDisplay(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	var display Display
-	err := db.QueryRow(`
-		SELECT id, name, location, description, token, is_active, created_at
+	display, err := scanDisplay(db.QueryRow(`
+		SELECT `+displayColumns+`
 		FROM displays
-		WHERE id = $1
-	`, id).Scan(&display.ID, &display.Name, &display.Location, &display.Description,
-		&display.Token, &display.IsActive, &display.CreatedAt)
+		WHERE id = $1 AND ($2 = 0 OR venue_id = $2) AND deleted_at IS NULL
+	`, id, userVenueID(r)))
 
 	if err == sql.ErrNoRows {
 		respondError(w, "Display not found", http.StatusNotFound)
@@ -115,6 +115,12 @@ func handleUpdateDisplay(w http.ResponseWriter, r *http.Request) {
 		Location    string `json:"location"`
 		Description string `json:"description"`
 		IsActive    *bool  `json:"is_active"`
+
+		// Playlist shown while the venue is closed; 0 clears it
+		AfterHoursPlaylistID *int `json:"after_hours_playlist_id"`
+
+		// Ticker scrolled over the playlist; 0 clears it
+		TickerID *int `json:"ticker_id"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -151,6 +157,53 @@ func handleUpdateDisplay(w http.ResponseWriter, r *http.Request) {
 		argCount++
 	}
 
+	if req.AfterHoursPlaylistID != nil {
+		var playlistID interface{}
+		if *req.AfterHoursPlaylistID != 0 {
+			var exists bool
+			err := db.QueryRow(`
+				SELECT EXISTS(SELECT 1 FROM playlists WHERE id = $1 AND deleted_at IS NULL)
+			`, *req.AfterHoursPlaylistID).Scan(&exists)
+			if err != nil {
+				log.Printf("❌ Error checking after-hours playlist: %v", err)
+				respondError(w, "Failed to update display", http.StatusInternalServerError)
+				return
+			}
+			if !exists {
+				respondError(w, "After-hours playlist not found", http.StatusBadRequest)
+				return
+			}
+			playlistID = *req.AfterHoursPlaylistID
+		}
+		query += fmt.Sprintf("after_hours_playlist_id = $%d, ", argCount)
+		args = append(args, playlistID)
+		argCount++
+	}
+
+	if req.TickerID != nil {
+		var tickerID interface{}
+		if *req.TickerID != 0 {
+			var exists bool
+			err := db.QueryRow(`
+				SELECT EXISTS(SELECT 1 FROM content_items
+				              WHERE id = $1 AND content_type = 'ticker' AND status = 'approved' AND deleted_at IS NULL)
+			`, *req.TickerID).Scan(&exists)
+			if err != nil {
+				log.Printf("❌ Error checking ticker: %v", err)
+				respondError(w, "Failed to update display", http.StatusInternalServerError)
+				return
+			}
+			if !exists {
+				respondError(w, "Ticker not found or not approved", http.StatusBadRequest)
+				return
+			}
+			tickerID = *req.TickerID
+		}
+		query += fmt.Sprintf("ticker_id = $%d, ", argCount)
+		args = append(args, tickerID)
+		argCount++
+	}
+
 	// Remove trailing comma and space
 	if argCount > 1 {
 		query = query[:len(query)-2]
@@ -159,14 +212,12 @@ func handleUpdateDisplay(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	query += fmt.Sprintf(" WHERE id = $%d RETURNING id, name, location, description, token, is_active, created_at", argCount)
-	args = append(args, id)
+	query += fmt.Sprintf(" WHERE id = $%d AND ($%d = 0 OR venue_id = $%d) AND deleted_at IS NULL RETURNING "+displayColumns, argCount, argCount+1, argCount+1)
+	args = append(args, id, userVenueID(r))
 
-	var display Display
-	err := db.QueryRow(query, args...).Scan(
-		&display.ID, &display.Name, &display.Location, &display.Description,
-		&display.Token, &display.IsActive, &display.CreatedAt,
-	)
+	before := snapshot(displaySnapshot, id)
+
+	display, err := scanDisplay(db.QueryRow(query, args...))
 
 	if err == sql.ErrNoRows {
 		respondError(w, "Display not found", http.StatusNotFound)
@@ -177,54 +228,72 @@ func handleUpdateDisplay(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	logAuditChange(r, "display_update", id, nil, before, snapshot(displaySnapshot, id))
+
 	log.Printf("✅ Updated display: %s", display.Name)
 	respondJSON(w, APIResponse{Success: true, Data: display})
 }
 
-// handleDeleteDisplay deletes a display
+// handleDeleteDisplay moves a display to the trash. Its token stops working
+// and outstanding pairing codes are dropped; restoring brings it back as it was.
 func handleDeleteDisplay(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	result, err := db.Exec("DELETE FROM displays WHERE id = $1", id)
-	if err != nil {
+	before := snapshot(displaySnapshot, id)
+	var displayID int
+	err := db.QueryRow(`
+		UPDATE displays SET deleted_at = CURRENT_TIMESTAMP, deleted_by = $3
+		WHERE id = $1 AND ($2 = 0 OR venue_id = $2) AND deleted_at IS NULL
+		RETURNING id
+	`, id, userVenueID(r), deletedBy(r)).Scan(&displayID)
+	if err == sql.ErrNoRows {
+		respondError(w, "Display not found", http.StatusNotFound)
+		return
+	} else if err != nil {
 		log.Printf("❌ Error deleting display: %v", err)
 		respondError(w, "Failed to delete display", http.StatusInternalServerError)
 		return
 	}
 
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		respondError(w, "Display not found", http.StatusNotFound)
-		return
-	}
+	db.Exec("DELETE FROM display_pairing_codes WHERE display_id = $1", displayID)
+	displayPush.disconnect(displayID)
+	logAuditChange(r, "display_delete", id, nil, before, snapshot(displaySnapshot, id))
 
-	log.Printf("✅ Deleted display ID: %s", id)
-	respondJSON(w, APIResponse{Success: true, Data: map[string]string{"message": "Display deleted"}})
+	log.Printf("🗑️  Moved display ID %s to trash", id)
+	respondJSON(w, APIResponse{Success: true, Data: map[string]string{"message": "Display moved to trash"}})
 }
 
-// handleGetDisplayURL returns the full runtime URL for a display
+// handleGetDisplayURL returns a runtime URL that pairs the TV with a fresh,
+// short-lived code (the display token itself never appears in a URL)
 func handleGetDisplayURL(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	var token string
-	err := db.QueryRow("SELECT token FROM displays WHERE id = $1", id).Scan(&token)
+	var displayID int
+	err := db.QueryRow("SELECT id FROM displays WHERE id = $1 AND ($2 = 0 OR venue_id = $2) AND deleted_at IS NULL", id, userVenueID(r)).Scan(&displayID)
 	if err == sql.ErrNoRows {
 		respondError(w, "Display not found", http.StatusNotFound)
 		return
 	} else if err != nil {
-		log.Printf("❌ Error fetching display token: %v", err)
+		log.Printf("❌ Error fetching display: %v", err)
 		respondError(w, "Failed to fetch display", http.StatusInternalServerError)
 		return
 	}
 
-	// Get host from environment or use default
-	host := getEnv("RUNTIME_HOST", "192.168.1.45")
-	runtimePort := getEnv("RUNTIME_PORT", "5051")
-	url := fmt.Sprintf("http://%s:%s?token=%s", host, runtimePort, token)
+	createdBy := ""
+	if user := getUserFromContext(r); user != nil {
+		createdBy = user.ActorEmail()
+	}
+
+	pc, err := issuePairingCode(displayID, createdBy)
+	if err != nil {
+		log.Printf("❌ Error creating pairing code: %v", err)
+		respondError(w, "Failed to create pairing code", http.StatusInternalServerError)
+		return
+	}
 
-	respondJSON(w, APIResponse{Success: true, Data: map[string]string{"url": url}})
+	respondJSON(w, APIResponse{Success: true, Data: map[string]string{"url": pc.URL, "code": pc.Code}})
 }
 
 // handleGetDisplayByToken returns display info by token (for TVs)
@@ -232,13 +301,11 @@ func handleGetDisplayByToken(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	token := vars["token"]
 
-	var display Display
-	err := db.QueryRow(`
-		SELECT id, name, location, description, token, is_active, created_at
+	display, err := scanDisplay(db.QueryRow(`
+		SELECT `+displayColumns+`
 		FROM displays
-		WHERE token = $1 AND is_active = true
-	`, token).Scan(&display.ID, &display.Name, &display.Location, &display.Description,
-		&display.Token, &display.IsActive, &display.CreatedAt)
+		WHERE token = $1 AND is_active = true AND deleted_at IS NULL
+	`, token))
 
 	if err == sql.ErrNoRows {
 		respondError(w, "Display not found or inactive", http.StatusNotFound)
@@ -273,11 +340,11 @@ func handleGetDisplayCurrentPlaylist(w http.ResponseWriter, r *http.Request) {
 	var playlist Playlist
 	var createdBy sql.NullString
 	err := db.QueryRow(`
-		SELECT id, name, description, is_active, created_by, created_at, updated_at
+		SELECT id, name, description, is_active, created_by, created_at, updated_at, guid::text
 		FROM playlists
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`, playlistID).Scan(&playlist.ID, &playlist.Name, &playlist.Description, &playlist.IsActive,
-		&createdBy, &playlist.CreatedAt, &playlist.UpdatedAt)
+		&createdBy, &playlist.CreatedAt, &playlist.UpdatedAt, &playlist.Guid)
 
 	if err != nil {
 		log.Printf("❌ Error fetching playlist: %v", err)
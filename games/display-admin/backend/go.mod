@@ -3,11 +3,17 @@ module github.com/yourusername/pub-games-v3/games/display-admin/backend
 go 1.25
 
 require (
+	github.com/achgithub/activity-hub-common v0.1.1
 	github.com/google/uuid v1.6.0
-	github.com/gorilla/handlers v1.5.2
 	github.com/gorilla/mux v1.8.1
 	github.com/lib/pq v1.10.9
+	github.com/redis/go-redis/v9 v9.7.0
 	github.com/skip2/go-qrcode v0.0.0-20200617195104-da1b6568686e
 )
 
-require github.com/felixge/httpsnoop v1.0.3 // indirect
+replace github.com/achgithub/activity-hub-common => ../../../lib/activity-hub-common
+
+require (
+	github.com/cespare/xxhash/v2 v2.2.0 // indirect
+	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
+)
@@ -0,0 +1,381 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// Layout templates split a display's screen into zones, each showing its own
+// playlist or live widget. Zones are placed in percent of the screen, so the
+// runtime doesn't need to know the templates. "fullscreen" is one main zone,
+// which is how every display worked before layouts.
+var layoutTemplates = []LayoutTemplate{
+	{Name: "fullscreen", Label: "Full screen", Zones: []LayoutZone{
+		{Name: "main", X: 0, Y: 0, Width: 100, Height: 100},
+	}},
+	{Name: "sidebar", Label: "Main + sidebar", Zones: []LayoutZone{
+		{Name: "main", X: 0, Y: 0, Width: 75, Height: 100},
+		{Name: "sidebar", X: 75, Y: 0, Width: 25, Height: 100},
+	}},
+	{Name: "ticker", Label: "Main + ticker", Zones: []LayoutZone{
+		{Name: "main", X: 0, Y: 0, Width: 100, Height: 90},
+		{Name: "ticker", X: 0, Y: 90, Width: 100, Height: 10},
+	}},
+	{Name: "sidebar_ticker", Label: "Main + sidebar + ticker", Zones: []LayoutZone{
+		{Name: "main", X: 0, Y: 0, Width: 75, Height: 90},
+		{Name: "sidebar", X: 75, Y: 0, Width: 25, Height: 90},
+		{Name: "ticker", X: 0, Y: 90, Width: 100, Height: 10},
+	}},
+}
+
+// findLayout returns the named template, or false if there is none
+func findLayout(name string) (LayoutTemplate, bool) {
+	for _, t := range layoutTemplates {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return LayoutTemplate{}, false
+}
+
+func (t LayoutTemplate) hasZone(name string) bool {
+	for _, z := range t.Zones {
+		if z.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// handleGetLayouts returns the layout templates displays can use
+func handleGetLayouts(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, APIResponse{Success: true, Data: layoutTemplates})
+}
+
+// loadZoneBindings returns a display's zone bindings, by zone
+func loadZoneBindings(displayID interface{}) (map[string]ZoneBinding, error) {
+	rows, err := db.Query(`
+		SELECT zone, COALESCE(playlist_id, 0), COALESCE(content_item_id, 0)
+		FROM display_zones WHERE display_id = $1
+	`, displayID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	bindings := map[string]ZoneBinding{}
+	for rows.Next() {
+		var b ZoneBinding
+		if err := rows.Scan(&b.Zone, &b.PlaylistID, &b.ContentItemID); err != nil {
+			return nil, err
+		}
+		bindings[b.Zone] = b
+	}
+	return bindings, rows.Err()
+}
+
+// handleGetDisplayLayout returns a display's layout and what each zone is set to show
+func handleGetDisplayLayout(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	var layout string
+	err := db.QueryRow(`
+		SELECT layout FROM displays
+		WHERE id = $1 AND ($2 = 0 OR venue_id = $2) AND deleted_at IS NULL
+	`, id, userVenueID(r)).Scan(&layout)
+	if err == sql.ErrNoRows {
+		respondError(w, "Display not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Printf("❌ Error fetching display layout: %v", err)
+		respondError(w, "Failed to fetch layout", http.StatusInternalServerError)
+		return
+	}
+
+	bindings, err := loadZoneBindings(id)
+	if err != nil {
+		log.Printf("❌ Error fetching zone bindings: %v", err)
+		respondError(w, "Failed to fetch layout", http.StatusInternalServerError)
+		return
+	}
+
+	// Every zone of the layout, in template order, unbound ones included
+	result := DisplayLayout{Layout: layout, Zones: []ZoneBinding{}}
+	if t, ok := findLayout(layout); ok {
+		for _, z := range t.Zones {
+			b := bindings[z.Name]
+			b.Zone = z.Name
+			result.Zones = append(result.Zones, b)
+		}
+	}
+	respondJSON(w, APIResponse{Success: true, Data: result})
+}
+
+// checkZoneBinding returns why a binding can't be used, or "" if it can. The
+// ticker zone takes a ticker; the other zones a playlist or any other content.
+func checkZoneBinding(b ZoneBinding) (string, error) {
+	if b.PlaylistID != 0 && b.ContentItemID != 0 {
+		return fmt.Sprintf("Zone %s can show a playlist or a content item, not both", b.Zone), nil
+	}
+	if b.PlaylistID != 0 {
+		if b.Zone == "ticker" {
+			return "The ticker zone shows a ticker, not a playlist", nil
+		}
+		var exists bool
+		err := db.QueryRow(`
+			SELECT EXISTS(SELECT 1 FROM playlists WHERE id = $1 AND deleted_at IS NULL)
+		`, b.PlaylistID).Scan(&exists)
+		if err != nil || !exists {
+			return fmt.Sprintf("Playlist %d not found", b.PlaylistID), err
+		}
+	}
+	if b.ContentItemID != 0 {
+		var contentType string
+		err := db.QueryRow(`
+			SELECT content_type FROM content_items
+			WHERE id = $1 AND status = 'approved' AND deleted_at IS NULL
+		`, b.ContentItemID).Scan(&contentType)
+		if err == sql.ErrNoRows {
+			return fmt.Sprintf("Content %d not found or not approved", b.ContentItemID), nil
+		} else if err != nil {
+			return "", err
+		}
+		if (b.Zone == "ticker") != (contentType == "ticker") {
+			return "Tickers go in the ticker zone, and only there", nil
+		}
+	}
+	return "", nil
+}
+
+// handleUpdateDisplayLayout sets a display's layout and replaces its zone
+// bindings. Zones left out (or with both IDs 0) show their default.
+func handleUpdateDisplayLayout(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	var req DisplayLayout
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	template, ok := findLayout(req.Layout)
+	if !ok {
+		respondError(w, "Unknown layout", http.StatusBadRequest)
+		return
+	}
+
+	seen := map[string]bool{}
+	bindings := []ZoneBinding{}
+	for _, b := range req.Zones {
+		if !template.hasZone(b.Zone) {
+			respondError(w, fmt.Sprintf("Layout %s has no %s zone", template.Name, b.Zone), http.StatusBadRequest)
+			return
+		}
+		if seen[b.Zone] {
+			respondError(w, fmt.Sprintf("Zone %s is set twice", b.Zone), http.StatusBadRequest)
+			return
+		}
+		seen[b.Zone] = true
+		if b.PlaylistID == 0 && b.ContentItemID == 0 {
+			continue
+		}
+		problem, err := checkZoneBinding(b)
+		if err != nil {
+			log.Printf("❌ Error checking zone binding: %v", err)
+			respondError(w, "Failed to update layout", http.StatusInternalServerError)
+			return
+		}
+		if problem != "" {
+			respondError(w, problem, http.StatusBadRequest)
+			return
+		}
+		bindings = append(bindings, b)
+	}
+
+	before := snapshot(displaySnapshot, id)
+
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("❌ Error starting transaction: %v", err)
+		respondError(w, "Failed to update layout", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE displays SET layout = $1
+		WHERE id = $2 AND ($3 = 0 OR venue_id = $3) AND deleted_at IS NULL
+	`, template.Name, id, userVenueID(r))
+	if err != nil {
+		log.Printf("❌ Error updating display layout: %v", err)
+		respondError(w, "Failed to update layout", http.StatusInternalServerError)
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		respondError(w, "Display not found", http.StatusNotFound)
+		return
+	}
+
+	if _, err := tx.Exec(`DELETE FROM display_zones WHERE display_id = $1`, id); err != nil {
+		log.Printf("❌ Error clearing zone bindings: %v", err)
+		respondError(w, "Failed to update layout", http.StatusInternalServerError)
+		return
+	}
+	for _, b := range bindings {
+		_, err := tx.Exec(`
+			INSERT INTO display_zones (display_id, zone, playlist_id, content_item_id)
+			VALUES ($1, $2, NULLIF($3, 0), NULLIF($4, 0))
+		`, id, b.Zone, b.PlaylistID, b.ContentItemID)
+		if err != nil {
+			log.Printf("❌ Error saving zone binding: %v", err)
+			respondError(w, "Failed to update layout", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("❌ Error committing layout: %v", err)
+		respondError(w, "Failed to update layout", http.StatusInternalServerError)
+		return
+	}
+
+	logAuditChange(r, "display_layout_update", id, map[string]interface{}{"layout": template.Name},
+		before, snapshot(displaySnapshot, id))
+
+	log.Printf("✅ Display %s layout set to %s (%d zones bound)", id, template.Name, len(bindings))
+	respondJSON(w, APIResponse{Success: true, Data: DisplayLayout{Layout: template.Name, Zones: bindings}})
+}
+
+// handleGetLayoutByToken returns everything a TV shows in one document: its
+// layout's zones with each one's playlist, widget or ticker right now
+func handleGetLayoutByToken(w http.ResponseWriter, r *http.Request) {
+	token := mux.Vars(r)["token"]
+
+	var displayID, tickerID, venueID int
+	var layout string
+	err := db.QueryRow(`
+		SELECT id, layout, COALESCE(ticker_id, 0), COALESCE(venue_id, 0) FROM displays
+		WHERE token = $1 AND is_active = true AND deleted_at IS NULL
+	`, token).Scan(&displayID, &layout, &tickerID, &venueID)
+	if err == sql.ErrNoRows {
+		respondError(w, "Display not found or inactive", http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Printf("❌ Error fetching display by token: %v", err)
+		respondError(w, "Failed to fetch display", http.StatusInternalServerError)
+		return
+	}
+
+	template, ok := findLayout(layout)
+	if !ok {
+		template, _ = findLayout("fullscreen")
+	}
+	bindings, err := loadZoneBindings(displayID)
+	if err != nil {
+		log.Printf("❌ Error fetching zone bindings: %v", err)
+		respondError(w, "Failed to fetch layout", http.StatusInternalServerError)
+		return
+	}
+
+	doc := LayoutDocument{Layout: template.Name, Zones: []ZoneContent{}}
+	for _, z := range template.Zones {
+		zone, err := loadZoneContent(r, z, bindings[z.Name], displayID, tickerID, venueID)
+		if err != nil {
+			log.Printf("❌ Error loading %s zone for display %d: %v", z.Name, displayID, err)
+			respondError(w, "Failed to fetch layout", http.StatusInternalServerError)
+			return
+		}
+		doc.Zones = append(doc.Zones, zone)
+	}
+
+	// Without a ticker zone the display's ticker scrolls over the top
+	if !template.hasZone("ticker") {
+		if doc.Ticker, err = loadTickerFeed(tickerID, venueID); err != nil {
+			log.Printf("❌ Error fetching display ticker: %v", err)
+			respondError(w, "Failed to fetch layout", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	respondJSON(w, APIResponse{Success: true, Data: doc})
+}
+
+// loadZoneContent works out what a zone shows now. A trashed playlist or
+// content item leaves the zone empty rather than failing the whole document.
+func loadZoneContent(r *http.Request, z LayoutZone, b ZoneBinding, displayID, tickerID, venueID int) (ZoneContent, error) {
+	zone := ZoneContent{LayoutZone: z, Source: "none"}
+
+	playlistID, source := b.PlaylistID, "playlist"
+	switch {
+	case b.ContentItemID != 0 && z.Name == "ticker":
+		tickerID = b.ContentItemID
+	case b.ContentItemID != 0:
+		item, err := loadWidget(b.ContentItemID)
+		if item != nil && item.ContentType == "photo_wall" {
+			// A photo wall on its own cycles its photos like a playlist
+			wall, err := withPhotoWalls(r.Context(), PlaylistWithContent{Items: []ContentItem{*item}}, venueID)
+			if len(wall.Items) > 0 {
+				zone.Source, zone.Playlist = "widget", &wall
+			}
+			return zone, err
+		}
+		if item != nil {
+			zone.Source, zone.Item = "widget", item
+		}
+		return zone, err
+	case playlistID == 0 && z.Name == "main":
+		playlistID, source = getActivePlaylistForDisplay(fmt.Sprint(displayID)), "schedule"
+	}
+
+	if z.Name == "ticker" {
+		feed, err := loadTickerFeed(tickerID, venueID)
+		if feed != nil {
+			zone.Source, zone.Ticker = "ticker", feed
+		}
+		return zone, err
+	}
+
+	if playlistID == 0 {
+		return zone, nil
+	}
+	playlist, err := cachedPlaylist(r.Context(), playlistID)
+	if err == sql.ErrNoRows {
+		return zone, nil
+	} else if err != nil {
+		return zone, err
+	}
+	if playlist, err = withPhotoWalls(r.Context(), playlist, venueID); err != nil {
+		return zone, err
+	}
+	zone.Source, zone.Playlist = source, &playlist
+	return zone, nil
+}
+
+// loadWidget reads a content item shown on its own in a zone, or nil if it
+// has been trashed or is no longer approved
+func loadWidget(contentID int) (*ContentItem, error) {
+	var c ContentItem
+	var filePath, url, textContent, bgColor, textColor sql.NullString
+	err := db.QueryRow(`
+		SELECT id, title, content_type, duration_seconds, file_path, url,
+		       text_content, bg_color, text_color, is_active
+		FROM content_items
+		WHERE id = $1 AND status = 'approved' AND deleted_at IS NULL
+	`, contentID).Scan(&c.ID, &c.Title, &c.ContentType, &c.DurationSeconds,
+		&filePath, &url, &textContent, &bgColor, &textColor, &c.IsActive)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	c.FilePath = filePath.String
+	c.URL = url.String
+	c.TextContent = textContent.String
+	c.BgColor = bgColor.String
+	c.TextColor = textColor.String
+	return &c, nil
+}
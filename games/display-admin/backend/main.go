@@ -6,7 +6,12 @@ import (
 	"net/http"
 	"os"
 
-	"github.com/gorilla/handlers"
+	"github.com/achgithub/activity-hub-common/audit"
+	"github.com/achgithub/activity-hub-common/cli"
+	"github.com/achgithub/activity-hub-common/hours"
+	apphttp "github.com/achgithub/activity-hub-common/http"
+	"github.com/achgithub/activity-hub-common/maintenance"
+	"github.com/achgithub/activity-hub-common/upload"
 	"github.com/gorilla/mux"
 	_ "github.com/lib/pq"
 )
@@ -17,6 +22,14 @@ const (
 )
 
 func main() {
+	cli.New("display-admin",
+		cli.Serve(serve),
+		cli.MigrateFunc(openAppDB, migrateTables),
+		resetDisplayTokenCommand(),
+	).Main()
+}
+
+func serve() {
 	log.Printf("📺 %s Backend Starting", APP_NAME)
 
 	// Initialize databases
@@ -26,13 +39,33 @@ func main() {
 	defer db.Close()
 	defer identityDB.Close()
 
+	// Opening hours switch displays to their after-hours playlist (edited in setup-admin)
+	openingHours = hours.New(identityDB)
+
+	auditLog = audit.New(db)
+
+	// Redis caches the playlists TVs poll (optional; without it they're read from the database)
+	initCache()
+
+	// Display commands go to TVs through the event bus (optional; see bus.go)
+	initBus()
+
+	// Permanently remove trash past the retention period, outside opening hours
+	go runTrashPurge()
+
+	// Delete photo wall submissions (and their files) once they expire
+	go runPhotoExpiry()
+
 	// Setup router
 	r := mux.NewRouter()
 
 	// Health check (public)
 	r.HandleFunc("/api/health", handleHealth).Methods("GET")
 
-	// All endpoints require admin authentication
+	// Signed-in user's permissions (admins, reviewers and contributors)
+	r.HandleFunc("/api/me", AuthMiddleware(handleGetMe)).Methods("GET")
+
+	// All endpoints require admin authentication unless noted
 	// Display Management
 	r.HandleFunc("/api/displays", AuthMiddleware(AdminMiddleware(handleGetDisplays))).Methods("GET")
 	r.HandleFunc("/api/displays", AuthMiddleware(AdminMiddleware(handleCreateDisplay))).Methods("POST")
@@ -41,16 +74,44 @@ func main() {
 	r.HandleFunc("/api/displays/{id}", AuthMiddleware(AdminMiddleware(handleDeleteDisplay))).Methods("DELETE")
 	r.HandleFunc("/api/displays/{id}/qr", AuthMiddleware(AdminMiddleware(handleGetDisplayQR))).Methods("GET")
 	r.HandleFunc("/api/displays/{id}/url", AuthMiddleware(AdminMiddleware(handleGetDisplayURL))).Methods("GET")
+	r.HandleFunc("/api/displays/{id}/pairing-code", AuthMiddleware(AdminMiddleware(handleCreatePairingCode))).Methods("POST")
+	r.HandleFunc("/api/displays/{id}/rotate-token", AuthMiddleware(AdminMiddleware(handleRotateDisplayToken))).Methods("POST")
+	r.HandleFunc("/api/displays/{id}/revoke", AuthMiddleware(AdminMiddleware(handleRevokeDisplay))).Methods("POST")
 	r.HandleFunc("/api/displays/{id}/current-playlist", AuthMiddleware(AdminMiddleware(handleGetDisplayCurrentPlaylist))).Methods("GET")
+	r.HandleFunc("/api/displays/{id}/commands", AuthMiddleware(AdminMiddleware(handleGetDisplayCommands))).Methods("GET")
+	r.HandleFunc("/api/displays/{id}/commands", AuthMiddleware(AdminMiddleware(handleSendDisplayCommand))).Methods("POST")
+	r.HandleFunc("/api/displays/{id}/layout", AuthMiddleware(AdminMiddleware(handleGetDisplayLayout))).Methods("GET")
+	r.HandleFunc("/api/displays/{id}/layout", AuthMiddleware(AdminMiddleware(handleUpdateDisplayLayout))).Methods("PUT")
+	r.HandleFunc("/api/layouts", AuthMiddleware(AdminMiddleware(handleGetLayouts))).Methods("GET")
 
 	// Content Management
-	r.HandleFunc("/api/content", AuthMiddleware(AdminMiddleware(handleGetContent))).Methods("GET")
-	r.HandleFunc("/api/content", AuthMiddleware(AdminMiddleware(handleCreateContent))).Methods("POST")
-	r.HandleFunc("/api/content/upload-image", AuthMiddleware(AdminMiddleware(handleUploadImage))).Methods("POST")
+	// (display_contributor users can list and submit their own content, pending review)
+	r.HandleFunc("/api/content", AuthMiddleware(ContributorMiddleware(handleGetContent))).Methods("GET")
+	r.HandleFunc("/api/content", AuthMiddleware(ContributorMiddleware(handleCreateContent))).Methods("POST")
+	r.HandleFunc("/api/content/upload-image", AuthMiddleware(ContributorMiddleware(handleUploadImage))).Methods("POST")
+	r.HandleFunc("/api/content/{id}/approve", AuthMiddleware(ReviewerMiddleware(handleApproveContent))).Methods("POST")
+	r.HandleFunc("/api/content/{id}/reject", AuthMiddleware(ReviewerMiddleware(handleRejectContent))).Methods("POST")
+	r.HandleFunc("/api/notifications", AuthMiddleware(ContributorMiddleware(handleGetNotifications))).Methods("GET")
+	r.HandleFunc("/api/notifications/{id}/read", AuthMiddleware(ContributorMiddleware(handleMarkNotificationRead))).Methods("POST")
 	r.HandleFunc("/api/content/{id}", AuthMiddleware(AdminMiddleware(handleGetContentItem))).Methods("GET")
 	r.HandleFunc("/api/content/{id}", AuthMiddleware(AdminMiddleware(handleUpdateContent))).Methods("PUT")
 	r.HandleFunc("/api/content/{id}", AuthMiddleware(AdminMiddleware(handleDeleteContent))).Methods("DELETE")
 
+	// Ticker messages and settings (ticker content items)
+	r.HandleFunc("/api/content/{id}/ticker", AuthMiddleware(AdminMiddleware(handleGetTicker))).Methods("GET")
+	r.HandleFunc("/api/content/{id}/ticker", AuthMiddleware(AdminMiddleware(handleUpdateTickerSettings))).Methods("PUT")
+	r.HandleFunc("/api/content/{id}/ticker/messages", AuthMiddleware(AdminMiddleware(handleCreateTickerMessage))).Methods("POST")
+	r.HandleFunc("/api/content/{id}/ticker/messages/{messageId}", AuthMiddleware(AdminMiddleware(handleUpdateTickerMessage))).Methods("PUT")
+	r.HandleFunc("/api/content/{id}/ticker/messages/{messageId}", AuthMiddleware(AdminMiddleware(handleDeleteTickerMessage))).Methods("DELETE")
+
+	// Photo wall (any signed-in user submits; reviewers moderate)
+	r.HandleFunc("/api/photos", AuthMiddleware(handleSubmitPhoto)).Methods("POST")
+	r.HandleFunc("/api/photos/mine", AuthMiddleware(handleGetMyPhotos)).Methods("GET")
+	r.HandleFunc("/api/photos", AuthMiddleware(ReviewerMiddleware(handleGetPhotos))).Methods("GET")
+	r.HandleFunc("/api/photos/{id}/approve", AuthMiddleware(ReviewerMiddleware(handleApprovePhoto))).Methods("POST")
+	r.HandleFunc("/api/photos/{id}/reject", AuthMiddleware(ReviewerMiddleware(handleRejectPhoto))).Methods("POST")
+	r.HandleFunc("/api/photos/{id}", AuthMiddleware(handleDeletePhoto)).Methods("DELETE")
+
 	// Playlist Management
 	r.HandleFunc("/api/playlists", AuthMiddleware(AdminMiddleware(handleGetPlaylists))).Methods("GET")
 	r.HandleFunc("/api/playlists", AuthMiddleware(AdminMiddleware(handleCreatePlaylist))).Methods("POST")
@@ -58,44 +119,59 @@ func main() {
 	r.HandleFunc("/api/playlists/{id}", AuthMiddleware(AdminMiddleware(handleUpdatePlaylist))).Methods("PUT")
 	r.HandleFunc("/api/playlists/{id}", AuthMiddleware(AdminMiddleware(handleDeletePlaylist))).Methods("DELETE")
 	r.HandleFunc("/api/playlists/{id}/items", AuthMiddleware(AdminMiddleware(handleAddPlaylistItem))).Methods("POST")
+	r.HandleFunc("/api/playlists/{id}/items/batch", AuthMiddleware(AdminMiddleware(handleAddPlaylistItems))).Methods("POST")
 	r.HandleFunc("/api/playlists/{id}/items/{itemId}", AuthMiddleware(AdminMiddleware(handleUpdatePlaylistItem))).Methods("PUT")
 	r.HandleFunc("/api/playlists/{id}/items/{itemId}", AuthMiddleware(AdminMiddleware(handleRemovePlaylistItem))).Methods("DELETE")
 	r.HandleFunc("/api/playlists/{id}/reorder", AuthMiddleware(AdminMiddleware(handleReorderPlaylist))).Methods("POST")
+	r.HandleFunc("/api/playlists/{id}/clone", AuthMiddleware(AdminMiddleware(handleClonePlaylist))).Methods("POST")
 
 	// Display Assignments
 	r.HandleFunc("/api/assignments", AuthMiddleware(AdminMiddleware(handleGetAssignments))).Methods("GET")
 	r.HandleFunc("/api/assignments", AuthMiddleware(AdminMiddleware(handleCreateAssignment))).Methods("POST")
+	r.HandleFunc("/api/assignments/copy", AuthMiddleware(AdminMiddleware(handleCopyAssignments))).Methods("POST")
 	r.HandleFunc("/api/assignments/display/{displayId}", AuthMiddleware(AdminMiddleware(handleGetDisplayAssignments))).Methods("GET")
 	r.HandleFunc("/api/assignments/{id}", AuthMiddleware(AdminMiddleware(handleGetAssignment))).Methods("GET")
 	r.HandleFunc("/api/assignments/{id}", AuthMiddleware(AdminMiddleware(handleUpdateAssignment))).Methods("PUT")
 	r.HandleFunc("/api/assignments/{id}", AuthMiddleware(AdminMiddleware(handleDeleteAssignment))).Methods("DELETE")
 
-	// Preview (playlist preview requires auth, display preview is public for TVs)
+	// Preview (admin; TVs fetch their playlist by token)
 	r.HandleFunc("/api/preview/playlist/{id}", AuthMiddleware(AdminMiddleware(handlePreviewPlaylist))).Methods("GET")
-	r.HandleFunc("/api/preview/display/{id}", handlePreviewDisplay).Methods("GET")
+	r.HandleFunc("/api/preview/display/{id}", AuthMiddleware(AdminMiddleware(handlePreviewDisplay))).Methods("GET")
+
+	// Playlist cache hit/miss counters
+	r.HandleFunc("/api/cache/stats", AuthMiddleware(AdminMiddleware(readCache.StatsHandler))).Methods("GET")
+
+	// Trash (deleted displays, content and playlists)
+	r.HandleFunc("/api/trash", AuthMiddleware(AdminMiddleware(handleGetTrash))).Methods("GET")
+	r.HandleFunc("/api/trash/{type}/{id}/restore", AuthMiddleware(AdminMiddleware(handleRestoreTrash))).Methods("POST")
+
+	// Audit log of admin changes, with before/after snapshots (admin only)
+	r.HandleFunc("/api/audit", AuthMiddleware(AdminMiddleware(auditLog.AdminHandler().ServeHTTP))).Methods("GET")
 
 	// Display Runtime API (consumed by TVs - no authentication)
+	r.HandleFunc("/api/display/pair", handlePairDisplay).Methods("POST")
 	r.HandleFunc("/api/display/by-token/{token}", handleGetDisplayByToken).Methods("GET")
+	r.HandleFunc("/api/display/by-token/{token}/playlist", handleGetPlaylistByToken).Methods("GET")
+	r.HandleFunc("/api/display/by-token/{token}/ticker", handleGetTickerByToken).Methods("GET")
+	r.HandleFunc("/api/display/by-token/{token}/layout", handleGetLayoutByToken).Methods("GET")
+	r.HandleFunc("/api/display/by-token/{token}/stream", handleDisplayStream).Methods("GET")
+	r.HandleFunc("/api/display/by-token/{token}/commands/{commandId}/ack", handleAckDisplayCommand).Methods("POST")
 
-	// Serve uploaded images
-	r.PathPrefix("/uploads/").Handler(http.StripPrefix("/uploads/", http.FileServer(http.Dir("./uploads"))))
+	// Serve uploaded images (files only: a listing would show photos waiting for review)
+	r.PathPrefix("/uploads/").Handler(http.StripPrefix("/uploads/", upload.FileServer("./uploads")))
 
 	// Serve static frontend files (React build output)
 	staticDir := getEnv("STATIC_DIR", "./static")
 	r.PathPrefix("/").Handler(spaHandler{staticPath: staticDir, indexPath: "index.html"})
 
 	// CORS configuration
-	corsHandler := handlers.CORS(
-		handlers.AllowedOrigins([]string{"*"}),
-		handlers.AllowedMethods([]string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}),
-		handlers.AllowedHeaders([]string{"Content-Type", "Authorization"}),
-		handlers.AllowCredentials(),
-	)
+	cors := apphttp.NewCORSPolicy(identityDB)
+	appMaintenance := maintenance.New(identityDB)
 
 	// Start server
 	port := getEnv("BACKEND_PORT", BACKEND_PORT)
 	log.Printf("🚀 %s backend listening on :%s", APP_NAME, port)
-	log.Fatal(http.ListenAndServe(":"+port, corsHandler(r)))
+	log.Fatal(http.ListenAndServe(":"+port, cors.Middleware(appMaintenance.Middleware("display-admin")(apphttp.Versioned(r)))))
 }
 
 // handleHealth - Health check endpoint
@@ -163,3 +239,12 @@ func respondError(w http.ResponseWriter, message string, statusCode int) {
 // - playlists.go: Playlist CRUD + reordering
 // - assignments.go: Assignment CRUD + scheduling
 // - preview.go: Preview logic + active playlist determination
+// - approvals.go: Contributor content review + decision notifications
+// - commands.go: Remote display commands + push channel (SSE) + acknowledgements
+// - pairing.go: Pairing codes + token rotation/revocation
+// - trash.go: Soft-deleted items + restore + retention purge
+// - bulk.go: Batch playlist items + playlist cloning + assignment copying
+// - cache.go: Redis cache for the playlists TVs poll + invalidation
+// - ticker.go: Ticker messages + settings + the ticker TVs scroll
+// - layouts.go: Layout templates + zone bindings + the layout document TVs show
+// - photos.go: Photo wall submissions + moderation queue + expiry
@@ -1,38 +1,170 @@
 package main
 
-import "time"
+import (
+	"time"
+
+	"github.com/achgithub/activity-hub-common/listquery"
+)
 
 // Display represents a physical TV/screen
 type Display struct {
-	ID          int       `json:"id"`
-	Name        string    `json:"name"`
-	Location    string    `json:"location"`
-	Description string    `json:"description"`
-	Token       string    `json:"token"` // UUID for TV identification
-	IsActive    bool      `json:"is_active"`
-	CreatedAt   time.Time `json:"created_at"`
+	ID             int        `json:"id"`
+	Guid           string     `json:"guid"` // Stable across environments, unlike ID
+	Name           string     `json:"name"`
+	Location       string     `json:"location"`
+	Description    string     `json:"description"`
+	Token          string     `json:"token"` // UUID for TV identification
+	IsActive       bool       `json:"is_active"`
+	VenueID        int        `json:"venue_id"` // 0 = chain-wide
+	TokenRotatedAt *time.Time `json:"token_rotated_at,omitempty"`
+	RevokedAt      *time.Time `json:"revoked_at,omitempty"` // Set when a lost device was cut off
+	CreatedAt      time.Time  `json:"created_at"`
+
+	// Playlist shown while the venue is closed (0 = keep the schedule)
+	AfterHoursPlaylistID int `json:"after_hours_playlist_id"`
+
+	// Ticker content item scrolled over the playlist (0 = none)
+	TickerID int `json:"ticker_id"`
+
+	// Screen layout template, see layoutTemplates
+	Layout string `json:"layout"`
+}
+
+// PairingCode is a short-lived code a TV enters to receive its display token
+type PairingCode struct {
+	Code      string    `json:"code"`
+	DisplayID int       `json:"display_id"`
+	URL       string    `json:"url"` // Runtime URL that pairs automatically
+	ExpiresAt time.Time `json:"expires_at"`
 }
 
 // ContentItem represents a piece of displayable content
 type ContentItem struct {
 	ID              int       `json:"id"`
 	Title           string    `json:"title"`
-	ContentType     string    `json:"content_type"` // image, url, social_feed, leaderboard, schedule, announcement
+	ContentType     string    `json:"content_type"` // image, url, social_feed, leaderboard, schedule, announcement, activity_feed, medal_table, ticker
 	DurationSeconds int       `json:"duration_seconds"`
-	FilePath        string    `json:"file_path,omitempty"`        // For image
-	URL             string    `json:"url,omitempty"`              // For url, social_feed, leaderboard, schedule
-	TextContent     string    `json:"text_content,omitempty"`     // For announcement
-	BgColor         string    `json:"bg_color,omitempty"`         // For announcement
-	TextColor       string    `json:"text_color,omitempty"`       // For announcement
+	FilePath        string    `json:"file_path,omitempty"`    // For image
+	URL             string    `json:"url,omitempty"`          // For url, social_feed, leaderboard, schedule
+	TextContent     string    `json:"text_content,omitempty"` // For announcement
+	BgColor         string    `json:"bg_color,omitempty"`     // For announcement, ticker
+	TextColor       string    `json:"text_color,omitempty"`   // For announcement, ticker
 	IsActive        bool      `json:"is_active"`
 	CreatedBy       string    `json:"created_by"`
 	CreatedAt       time.Time `json:"created_at"`
 	UpdatedAt       time.Time `json:"updated_at"`
+	Status          string    `json:"status"`                // approved, pending (contributor upload), rejected
+	ReviewNote      string    `json:"review_note,omitempty"` // Reviewer's reason when rejected
+	ReviewedBy      string    `json:"reviewed_by,omitempty"`
+	Guid            string    `json:"guid"`
+}
+
+// TickerMessage is one line a ticker content item scrolls
+type TickerMessage struct {
+	ID            int        `json:"id"`
+	ContentItemID int        `json:"content_item_id"`
+	Text          string     `json:"text"`
+	IsActive      bool       `json:"is_active"`
+	StartsAt      *time.Time `json:"starts_at,omitempty"` // Not shown before
+	EndsAt        *time.Time `json:"ends_at,omitempty"`   // Not shown from
+	CreatedBy     string     `json:"created_by"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// TickerFeed is what a display's ticker overlay scrolls right now
+type TickerFeed struct {
+	TickerID     int                 `json:"ticker_id"`
+	Title        string              `json:"title"`
+	BgColor      string              `json:"bg_color,omitempty"`
+	TextColor    string              `json:"text_color,omitempty"`
+	ActivityFeed bool                `json:"activity_feed"` // Also scroll the venue's activity feed
+	VenueID      int                 `json:"venue_id"`      // Venue to take the activity feed from (0 = all)
+	Messages     []TickerFeedMessage `json:"messages"`
+}
+
+// TickerFeedMessage is a message as sent to displays
+type TickerFeedMessage struct {
+	ID   int    `json:"id"`
+	Text string `json:"text"`
+}
+
+// PhotoSubmission is a photo a signed-in user sent in for the photo wall
+type PhotoSubmission struct {
+	ID          int        `json:"id"`
+	FilePath    string     `json:"file_path,omitempty"` // Empty once rejected
+	Caption     string     `json:"caption"`
+	SubmittedBy string     `json:"submitted_by"`
+	VenueID     int        `json:"venue_id"` // 0 = every wall
+	Status      string     `json:"status"`   // pending, approved, rejected
+	ReviewNote  string     `json:"review_note,omitempty"`
+	ReviewedBy  string     `json:"reviewed_by,omitempty"`
+	ReviewedAt  *time.Time `json:"reviewed_at,omitempty"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// WallPhoto is an approved photo as a photo wall shows it
+type WallPhoto struct {
+	ID       int    `json:"id"`
+	FilePath string `json:"file_path"`
+	Caption  string `json:"caption"`
+	Credit   string `json:"credit"` // Submitter's public name
+}
+
+// LayoutTemplate splits the screen into named zones
+type LayoutTemplate struct {
+	Name  string       `json:"name"`
+	Label string       `json:"label"`
+	Zones []LayoutZone `json:"zones"`
+}
+
+// LayoutZone is a zone's place on screen, in percent of the screen
+type LayoutZone struct {
+	Name   string `json:"name"` // main, sidebar, ticker
+	X      int    `json:"x"`
+	Y      int    `json:"y"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// ZoneBinding is what an admin set a display's zone to show. Both IDs 0 means
+// the zone's default: the schedule for main, the display's ticker for ticker.
+type ZoneBinding struct {
+	Zone          string `json:"zone"`
+	PlaylistID    int    `json:"playlist_id"`
+	ContentItemID int    `json:"content_item_id"` // Shown on its own as a live widget
+}
+
+// DisplayLayout is a display's layout template and zone bindings
+type DisplayLayout struct {
+	Layout string        `json:"layout"`
+	Zones  []ZoneBinding `json:"zones"`
+}
+
+// LayoutDocument is everything a TV shows, zone by zone, in one response
+type LayoutDocument struct {
+	Layout string        `json:"layout"`
+	Zones  []ZoneContent `json:"zones"`
+	// The display's ticker as an overlay, when the layout has no ticker zone
+	Ticker *TickerFeed `json:"ticker"`
+}
+
+// ZoneContent is what one zone shows right now. Source says which of
+// Playlist, Item or Ticker is set; none is when there's nothing to show. A
+// photo wall widget comes as a Playlist of its photos.
+type ZoneContent struct {
+	LayoutZone
+	Source   string               `json:"source"` // schedule, playlist, widget, ticker, none
+	Playlist *PlaylistWithContent `json:"playlist,omitempty"`
+	Item     *ContentItem         `json:"item,omitempty"`
+	Ticker   *TickerFeed          `json:"ticker,omitempty"`
 }
 
 // Playlist represents an ordered sequence of content
 type Playlist struct {
 	ID          int       `json:"id"`
+	Guid        string    `json:"guid"`
 	Name        string    `json:"name"`
 	Description string    `json:"description"`
 	IsActive    bool      `json:"is_active"`
@@ -79,9 +211,45 @@ type DisplayAssignmentWithDetails struct {
 	PlaylistName string `json:"playlist_name"`
 }
 
-// APIResponse is a generic response wrapper
+// DisplayCommand is a remote command sent to a display over its push channel
+type DisplayCommand struct {
+	ID             int        `json:"id"`
+	DisplayID      int        `json:"display_id"`
+	Command        string     `json:"command"` // refresh, clear_cache, reboot
+	Status         string     `json:"status"`  // pending, delivered, done, failed, expired
+	Message        string     `json:"message,omitempty"`
+	CreatedBy      string     `json:"created_by"`
+	CreatedAt      time.Time  `json:"created_at"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+}
+
+// ContentNotification tells a contributor a reviewer approved or rejected their content
+type ContentNotification struct {
+	ID            int        `json:"id"`
+	ContentItemID *int       `json:"content_item_id,omitempty"` // NULL once the content is deleted
+	ContentTitle  string     `json:"content_title"`
+	Decision      string     `json:"decision"` // approved, rejected
+	Note          string     `json:"note,omitempty"`
+	DecidedBy     string     `json:"decided_by"`
+	CreatedAt     time.Time  `json:"created_at"`
+	ReadAt        *time.Time `json:"read_at,omitempty"`
+}
+
+// TrashItem is a deleted display, content item or playlist awaiting restore or purge
+type TrashItem struct {
+	Type      string    `json:"type"` // display, content, playlist
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	DeletedAt time.Time `json:"deleted_at"`
+	DeletedBy string    `json:"deleted_by"`
+	PurgeAt   time.Time `json:"purge_at"`
+}
+
+// APIResponse is a generic response wrapper. Page is set on paged lists.
 type APIResponse struct {
-	Success bool        `json:"success"`
-	Data    interface{} `json:"data,omitempty"`
-	Error   string      `json:"error,omitempty"`
+	Success bool            `json:"success"`
+	Data    interface{}     `json:"data,omitempty"`
+	Error   string          `json:"error,omitempty"`
+	Page    *listquery.Page `json:"page,omitempty"`
 }
@@ -0,0 +1,346 @@
+package main
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/achgithub/activity-hub-common/cli"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/lib/pq"
+)
+
+// Pairing codes are only good for a few minutes: long enough to walk to the TV
+const pairingCodeTTL = 10 * time.Minute
+
+// Failed pairing attempts allowed per client within pairingCodeTTL, so the
+// 6-digit code space can't be walked
+const maxPairAttempts = 10
+
+const displayColumns = `id, guid::text, name, location, description, token, is_active, COALESCE(venue_id, 0),
+	token_rotated_at, revoked_at, created_at, COALESCE(after_hours_playlist_id, 0), COALESCE(ticker_id, 0),
+	layout`
+
+func scanDisplay(row interface{ Scan(...interface{}) error }) (Display, error) {
+	var d Display
+	var rotatedAt, revokedAt sql.NullTime
+	err := row.Scan(&d.ID, &d.Guid, &d.Name, &d.Location, &d.Description, &d.Token, &d.IsActive, &d.VenueID,
+		&rotatedAt, &revokedAt, &d.CreatedAt, &d.AfterHoursPlaylistID, &d.TickerID, &d.Layout)
+	if rotatedAt.Valid {
+		d.TokenRotatedAt = &rotatedAt.Time
+	}
+	if revokedAt.Valid {
+		d.RevokedAt = &revokedAt.Time
+	}
+	return d, err
+}
+
+// pairLimiter counts failed pairing attempts per client IP
+type pairLimiter struct {
+	mu       sync.Mutex
+	failures map[string][]time.Time
+}
+
+var pairAttempts = &pairLimiter{failures: map[string][]time.Time{}}
+
+func (l *pairLimiter) recent(ip string) []time.Time {
+	cutoff := time.Now().Add(-pairingCodeTTL)
+	kept := l.failures[ip][:0]
+	for _, t := range l.failures[ip] {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+	if len(kept) == 0 {
+		delete(l.failures, ip)
+	} else {
+		l.failures[ip] = kept
+	}
+	return kept
+}
+
+func (l *pairLimiter) blocked(ip string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.recent(ip)) >= maxPairAttempts
+}
+
+func (l *pairLimiter) fail(ip string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.failures[ip] = append(l.recent(ip), time.Now())
+}
+
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// runtimeURL is the display runtime address TVs open
+func runtimeURL() string {
+	host := getEnv("RUNTIME_HOST", "192.168.1.45")
+	runtimePort := getEnv("RUNTIME_PORT", "5051")
+	return fmt.Sprintf("http://%s:%s", host, runtimePort)
+}
+
+// pairingURL opens the runtime and pairs it with the given code
+func pairingURL(code string) string {
+	return fmt.Sprintf("%s?pair=%s", runtimeURL(), code)
+}
+
+func randomPairingCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+// issuePairingCode replaces any outstanding code for the display with a new one
+func issuePairingCode(displayID int, createdBy string) (PairingCode, error) {
+	pc := PairingCode{DisplayID: displayID}
+
+	if _, err := db.Exec(`
+		DELETE FROM display_pairing_codes WHERE display_id = $1 OR expires_at < CURRENT_TIMESTAMP
+	`, displayID); err != nil {
+		return pc, err
+	}
+
+	// Retry on the rare collision with another display's live code
+	for attempt := 0; attempt < 5; attempt++ {
+		code, err := randomPairingCode()
+		if err != nil {
+			return pc, err
+		}
+		err = db.QueryRow(`
+			INSERT INTO display_pairing_codes (code, display_id, created_by, expires_at)
+			VALUES ($1, $2, $3, $4)
+			RETURNING code, expires_at
+		`, code, displayID, createdBy, time.Now().Add(pairingCodeTTL)).Scan(&pc.Code, &pc.ExpiresAt)
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			continue
+		}
+		if err != nil {
+			return pc, err
+		}
+		pc.URL = pairingURL(pc.Code)
+		return pc, nil
+	}
+	return pc, fmt.Errorf("could not allocate a unique pairing code")
+}
+
+// handleCreatePairingCode issues a short-lived code for setting up a TV
+func handleCreatePairingCode(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var displayID int
+	err := db.QueryRow("SELECT id FROM displays WHERE id = $1 AND ($2 = 0 OR venue_id = $2) AND deleted_at IS NULL", id, userVenueID(r)).Scan(&displayID)
+	if err == sql.ErrNoRows {
+		respondError(w, "Display not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Printf("❌ Error fetching display: %v", err)
+		respondError(w, "Failed to fetch display", http.StatusInternalServerError)
+		return
+	}
+
+	createdBy := ""
+	if user := getUserFromContext(r); user != nil {
+		createdBy = user.ActorEmail()
+	}
+
+	pc, err := issuePairingCode(displayID, createdBy)
+	if err != nil {
+		log.Printf("❌ Error creating pairing code: %v", err)
+		respondError(w, "Failed to create pairing code", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("🔑 Pairing code issued for display %d (expires %s)", displayID, pc.ExpiresAt.Format("15:04"))
+	respondJSON(w, APIResponse{Success: true, Data: pc})
+}
+
+// handlePairDisplay exchanges a pairing code for a freshly issued display token.
+// The code is single-use, and any device holding the old token is cut off.
+func handlePairDisplay(w http.ResponseWriter, r *http.Request) {
+	ip := clientIP(r)
+	if pairAttempts.blocked(ip) {
+		log.Printf("⚠️ Too many pairing attempts from %s", ip)
+		respondError(w, "Too many attempts, try again later", http.StatusTooManyRequests)
+		return
+	}
+
+	var req struct {
+		Code string `json:"code"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	code := strings.TrimSpace(req.Code)
+
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("❌ Error starting pairing transaction: %v", err)
+		respondError(w, "Failed to pair display", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	var displayID int
+	err = tx.QueryRow(`
+		DELETE FROM display_pairing_codes
+		WHERE code = $1 AND expires_at > CURRENT_TIMESTAMP
+		RETURNING display_id
+	`, code).Scan(&displayID)
+	if err == sql.ErrNoRows {
+		pairAttempts.fail(ip)
+		respondError(w, "Invalid or expired pairing code", http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Printf("❌ Error claiming pairing code: %v", err)
+		respondError(w, "Failed to pair display", http.StatusInternalServerError)
+		return
+	}
+
+	display, err := scanDisplay(tx.QueryRow(`
+		UPDATE displays
+		SET token = $1, token_rotated_at = CURRENT_TIMESTAMP, is_active = true, revoked_at = NULL
+		WHERE id = $2
+		RETURNING `+displayColumns,
+		uuid.New().String(), displayID))
+	if err != nil {
+		log.Printf("❌ Error issuing display token: %v", err)
+		respondError(w, "Failed to pair display", http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("❌ Error committing pairing: %v", err)
+		respondError(w, "Failed to pair display", http.StatusInternalServerError)
+		return
+	}
+
+	displayPush.disconnect(displayID)
+	log.Printf("✅ Paired display: %s", display.Name)
+	respondJSON(w, APIResponse{Success: true, Data: display})
+}
+
+// handleRotateDisplayToken issues a new token; the TV must pair again
+func handleRotateDisplayToken(w http.ResponseWriter, r *http.Request) {
+	replaceDisplayToken(w, r, false)
+}
+
+// handleRevokeDisplay cuts off a lost or stolen device immediately: the token
+// is replaced, the display deactivated and outstanding pairing codes dropped.
+// Pairing a new device reactivates it.
+func handleRevokeDisplay(w http.ResponseWriter, r *http.Request) {
+	replaceDisplayToken(w, r, true)
+}
+
+func replaceDisplayToken(w http.ResponseWriter, r *http.Request, revoke bool) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	display, err := resetDisplayToken(id, userVenueID(r), revoke)
+	if err == sql.ErrNoRows {
+		respondError(w, "Display not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Printf("❌ Error replacing display token: %v", err)
+		respondError(w, "Failed to update display token", http.StatusInternalServerError)
+		return
+	}
+
+	displayPush.disconnect(display.ID)
+
+	if revoke {
+		log.Printf("🔒 Revoked display: %s", display.Name)
+	} else {
+		log.Printf("🔑 Rotated token for display: %s", display.Name)
+	}
+	respondJSON(w, APIResponse{Success: true, Data: display})
+}
+
+// resetDisplayToken gives a display in venueID (0 for any) a new token. With
+// revoke the display is also deactivated and its pairing codes dropped.
+func resetDisplayToken(id string, venueID int, revoke bool) (Display, error) {
+	display, err := scanDisplay(db.QueryRow(`
+		UPDATE displays
+		SET token = $1, token_rotated_at = CURRENT_TIMESTAMP,
+		    is_active = CASE WHEN $2 THEN false ELSE is_active END,
+		    revoked_at = CASE WHEN $2 THEN CURRENT_TIMESTAMP ELSE revoked_at END
+		WHERE id = $3 AND ($4 = 0 OR venue_id = $4) AND deleted_at IS NULL
+		RETURNING `+displayColumns,
+		uuid.New().String(), revoke, id, venueID))
+	if err != nil {
+		return display, err
+	}
+
+	if revoke {
+		if _, err := db.Exec("DELETE FROM display_pairing_codes WHERE display_id = $1", display.ID); err != nil {
+			log.Printf("❌ Error dropping pairing codes for display %d: %v", display.ID, err)
+		}
+	}
+	return display, nil
+}
+
+// resetDisplayTokenCommand is the reset-display-token command, for a TV
+// whose token has leaked or a device that's gone missing, from the Pi's shell.
+// A device connected to the running server is cut off at its next request
+// rather than straight away.
+func resetDisplayTokenCommand() cli.Command {
+	var revoke bool
+	return cli.Command{
+		Name:  "reset-display-token",
+		Args:  "<display id>",
+		Short: "Give a display a new token; its TV must be paired again",
+		Flags: func(fs *flag.FlagSet) {
+			fs.BoolVar(&revoke, "revoke", false, "Also deactivate the display until a device is paired (for a lost or stolen device)")
+		},
+		Run: func(args []string) error {
+			if len(args) != 1 {
+				return cli.Usagef("expected a display ID")
+			}
+			if _, err := strconv.Atoi(args[0]); err != nil {
+				return cli.Usagef("display ID must be a number")
+			}
+
+			var err error
+			db, err = openAppDB()
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			display, err := resetDisplayToken(args[0], 0, revoke)
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("no display %s", args[0])
+			} else if err != nil {
+				return err
+			}
+
+			if revoke {
+				fmt.Printf("Revoked display %d (%s): pair a device to reactivate it\n", display.ID, display.Name)
+			} else {
+				fmt.Printf("Display %d (%s) has a new token: pair its TV again\n", display.ID, display.Name)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,459 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/achgithub/activity-hub-common/cache"
+	"github.com/achgithub/activity-hub-common/listquery"
+	"github.com/achgithub/activity-hub-common/upload"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// Photos stay on the wall for this many hours after approval
+// (PHOTO_EXPIRY_HOURS). Photos nobody reviews are deleted after the same time.
+const defaultPhotoExpiryHours = 48
+
+// maxPendingPhotos caps how many photos one user can have waiting for review
+const maxPendingPhotos = 5
+
+// photoWallLimit is how many of the newest approved photos a wall cycles through
+const photoWallLimit = 30
+
+// maxCaptionLength matches photo_submissions.caption
+const maxCaptionLength = 140
+
+// photoList is what the moderation queue can be sorted and filtered by
+var photoList = listquery.Spec{
+	Sorts: map[string]string{
+		"created": "created_at",
+		"expires": "expires_at",
+	},
+	DefaultSort: "created",
+	Tiebreak:    "id",
+	Filters: map[string]listquery.Filter{
+		"status": listquery.Equals("status"),
+		"q":      listquery.Search("caption"),
+	},
+}
+
+const photoColumns = `id, COALESCE(file_path, ''), COALESCE(caption, ''), submitted_by, COALESCE(venue_id, 0),
+	status, COALESCE(review_note, ''), COALESCE(reviewed_by, ''), reviewed_at, expires_at, created_at`
+
+func scanPhoto(row interface{ Scan(...interface{}) error }) (PhotoSubmission, error) {
+	var p PhotoSubmission
+	var reviewedAt sql.NullTime
+	err := row.Scan(&p.ID, &p.FilePath, &p.Caption, &p.SubmittedBy, &p.VenueID,
+		&p.Status, &p.ReviewNote, &p.ReviewedBy, &reviewedAt, &p.ExpiresAt, &p.CreatedAt)
+	if reviewedAt.Valid {
+		p.ReviewedAt = &reviewedAt.Time
+	}
+	return p, err
+}
+
+func photoExpiryHours() int {
+	hours, err := strconv.Atoi(getEnv("PHOTO_EXPIRY_HOURS", ""))
+	if err != nil || hours < 1 {
+		return defaultPhotoExpiryHours
+	}
+	return hours
+}
+
+// removePhotoFile deletes a photo's upload from disk
+func removePhotoFile(filePath string) {
+	if filePath == "" {
+		return
+	}
+	fsPath := filepath.Join("./uploads", strings.TrimPrefix(filePath, "/uploads/"))
+	if err := os.Remove(fsPath); err != nil && !os.IsNotExist(err) {
+		log.Printf("⚠️  Warning: Could not delete file: %s", fsPath)
+	}
+}
+
+// handleSubmitPhoto takes a photo from any signed-in user for the photo wall.
+// It waits in the moderation queue until a reviewer approves it. The image is
+// re-encoded on upload, which drops EXIF data such as GPS position.
+func handleSubmitPhoto(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var pending int
+	if err := db.QueryRow(`
+		SELECT COUNT(*) FROM photo_submissions WHERE submitted_by = $1 AND status = 'pending'
+	`, user.Email).Scan(&pending); err != nil {
+		log.Printf("❌ Error counting pending photos: %v", err)
+		respondError(w, "Failed to submit photo", http.StatusInternalServerError)
+		return
+	}
+	if pending >= maxPendingPhotos {
+		respondError(w, fmt.Sprintf("You already have %d photos waiting for review", pending), http.StatusTooManyRequests)
+		return
+	}
+
+	file, err := upload.Read(w, r, "photo", upload.Images)
+	if err != nil {
+		respondError(w, err.Error(), upload.StatusCode(err))
+		return
+	}
+
+	caption := strings.TrimSpace(r.FormValue("caption"))
+	if len([]rune(caption)) > maxCaptionLength {
+		respondError(w, fmt.Sprintf("Caption must be %d characters or fewer", maxCaptionLength), http.StatusBadRequest)
+		return
+	}
+
+	// The venue the photo was taken at, defaulting to the user's own
+	venueID := user.VenueID
+	if v := r.FormValue("venue_id"); v != "" {
+		if venueID, err = strconv.Atoi(v); err != nil || venueID < 0 {
+			respondError(w, "Invalid venue", http.StatusBadRequest)
+			return
+		}
+	}
+
+	// A full UUID, since the file is served before anyone has reviewed it
+	filename := fmt.Sprintf("%s%s", uuid.New().String(), file.Ext)
+	dir := filepath.Join("./uploads", "photos")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		log.Printf("❌ Error creating photos directory: %v", err)
+		respondError(w, "Failed to create uploads directory", http.StatusInternalServerError)
+		return
+	}
+	fsPath := filepath.Join(dir, filename)
+	if err := os.WriteFile(fsPath, file.Data, 0644); err != nil {
+		log.Printf("❌ Error writing photo: %v", err)
+		respondError(w, "Failed to save file", http.StatusInternalServerError)
+		return
+	}
+
+	photo, err := scanPhoto(db.QueryRow(`
+		INSERT INTO photo_submissions (file_path, caption, submitted_by, venue_id, expires_at)
+		VALUES ($1, NULLIF($2, ''), $3, NULLIF($4, 0), CURRENT_TIMESTAMP + make_interval(hours => $5))
+		RETURNING `+photoColumns,
+		"/uploads/photos/"+filename, caption, user.Email, venueID, photoExpiryHours()))
+	if err != nil {
+		log.Printf("❌ Error creating photo submission: %v", err)
+		os.Remove(fsPath)
+		respondError(w, "Failed to submit photo", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("✅ Photo %d submitted by %s", photo.ID, user.Email)
+	respondJSON(w, APIResponse{Success: true, Data: photo})
+}
+
+// handleGetMyPhotos returns the signed-in user's own submissions, newest first
+func handleGetMyPhotos(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+
+	rows, err := db.Query(`
+		SELECT `+photoColumns+` FROM photo_submissions
+		WHERE submitted_by = $1
+		ORDER BY created_at DESC
+	`, user.Email)
+	if err != nil {
+		log.Printf("❌ Error querying photos: %v", err)
+		respondError(w, "Failed to fetch photos", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	photos := []PhotoSubmission{}
+	for rows.Next() {
+		p, err := scanPhoto(rows)
+		if err != nil {
+			log.Printf("❌ Error scanning photo: %v", err)
+			continue
+		}
+		photos = append(photos, p)
+	}
+
+	respondJSON(w, APIResponse{Success: true, Data: photos})
+}
+
+// handleGetPhotos is the moderation queue: a page of submissions (oldest
+// first, status=pending for what's waiting) from the reviewer's venue
+func handleGetPhotos(w http.ResponseWriter, r *http.Request) {
+	q, err := listquery.Parse(r.URL.Query(), photoList)
+	if err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if venueID := userVenueID(r); venueID != 0 {
+		q.Where("venue_id = ?", venueID)
+	}
+
+	query, args := q.SQL(`SELECT ` + photoColumns + ` FROM photo_submissions`)
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		log.Printf("❌ Error querying photos: %v", err)
+		respondError(w, "Failed to fetch photos", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	photos := []PhotoSubmission{}
+	for rows.Next() {
+		p, err := scanPhoto(rows)
+		if err != nil {
+			log.Printf("❌ Error scanning photo: %v", err)
+			continue
+		}
+		photos = append(photos, p)
+	}
+	photos, page := listquery.Finish(q, photos)
+
+	respondJSON(w, APIResponse{Success: true, Data: photos, Page: &page})
+}
+
+// handleApprovePhoto puts a pending photo on the wall until it expires
+func handleApprovePhoto(w http.ResponseWriter, r *http.Request) {
+	reviewPhoto(w, r, "approved")
+}
+
+// handleRejectPhoto turns a photo down and deletes its file at once. The
+// submission is kept (without the file) so the user sees the decision.
+func handleRejectPhoto(w http.ResponseWriter, r *http.Request) {
+	reviewPhoto(w, r, "rejected")
+}
+
+// reviewPhoto records a decision on a pending photo from the reviewer's venue.
+// Approval restarts the expiry clock, so a photo gets its full time on the wall.
+func reviewPhoto(w http.ResponseWriter, r *http.Request, decision string) {
+	id := mux.Vars(r)["id"]
+	user := getUserFromContext(r)
+
+	var req struct {
+		Note string `json:"note"`
+	}
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	before := snapshot(photoSnapshot, id)
+	var filePath string
+	var venueID int
+	err := db.QueryRow(`
+		UPDATE photo_submissions p
+		SET status = $1, review_note = NULLIF($2, ''), reviewed_by = $3, reviewed_at = CURRENT_TIMESTAMP,
+		    expires_at = CASE WHEN $1 = 'approved' THEN CURRENT_TIMESTAMP + make_interval(hours => $4) ELSE p.expires_at END,
+		    file_path = CASE WHEN $1 = 'rejected' THEN NULL ELSE p.file_path END
+		FROM (SELECT id, file_path FROM photo_submissions WHERE id = $5) old
+		WHERE p.id = old.id AND p.status = 'pending' AND ($6 = 0 OR p.venue_id = $6)
+		RETURNING COALESCE(old.file_path, ''), COALESCE(p.venue_id, 0)
+	`, decision, req.Note, user.ActorEmail(), photoExpiryHours(), id, userVenueID(r)).Scan(&filePath, &venueID)
+	if err == sql.ErrNoRows {
+		respondError(w, "Photo not found or not pending review", http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Printf("❌ Error reviewing photo: %v", err)
+		respondError(w, "Failed to review photo", http.StatusInternalServerError)
+		return
+	}
+
+	if decision == "rejected" {
+		removePhotoFile(filePath)
+	} else {
+		invalidatePhotoWalls(venueID)
+	}
+
+	logAuditChange(r, "photo_"+decisionAction[decision], id, map[string]interface{}{
+		"note": req.Note,
+	}, before, snapshot(photoSnapshot, id))
+
+	log.Printf("✅ Photo %s %s by %s", id, decision, user.Email)
+	respondJSON(w, APIResponse{Success: true, Data: map[string]interface{}{
+		"id":     id,
+		"status": decision,
+	}})
+}
+
+// handleDeletePhoto takes a photo down for good. Reviewers can delete photos
+// from their venue; anyone can delete their own.
+func handleDeletePhoto(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	user := getUserFromContext(r)
+
+	venueID := userVenueID(r)
+	submitter := user.Email
+	if user.CanReviewContent() {
+		submitter = ""
+	}
+
+	before := snapshot(photoSnapshot, id)
+	var filePath string
+	var photoVenue int
+	err := db.QueryRow(`
+		DELETE FROM photo_submissions
+		WHERE id = $1 AND (submitted_by = $2 OR ($2 = '' AND ($3 = 0 OR venue_id = $3)))
+		RETURNING COALESCE(file_path, ''), COALESCE(venue_id, 0)
+	`, id, submitter, venueID).Scan(&filePath, &photoVenue)
+	if err == sql.ErrNoRows {
+		respondError(w, "Photo not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Printf("❌ Error deleting photo: %v", err)
+		respondError(w, "Failed to delete photo", http.StatusInternalServerError)
+		return
+	}
+
+	removePhotoFile(filePath)
+	invalidatePhotoWalls(photoVenue)
+	logAuditChange(r, "photo_delete", id, nil, before, nil)
+
+	log.Printf("🗑️  Photo %s deleted by %s", id, user.Email)
+	respondJSON(w, APIResponse{Success: true})
+}
+
+// wallPhotos returns the newest approved, unexpired photos for a venue's
+// walls (chain-wide displays show every venue's), through the cache
+func wallPhotos(ctx context.Context, venueID int) ([]WallPhoto, error) {
+	return cache.Fetch(ctx, readCache, "photos:"+strconv.Itoa(venueID), func() ([]WallPhoto, error) {
+		rows, err := db.Query(`
+			SELECT id, file_path, COALESCE(caption, ''), submitted_by
+			FROM photo_submissions
+			WHERE status = 'approved' AND file_path IS NOT NULL AND expires_at > CURRENT_TIMESTAMP
+			  AND ($1 = 0 OR venue_id = $1 OR venue_id IS NULL)
+			ORDER BY reviewed_at DESC, id DESC
+			LIMIT $2
+		`, venueID, photoWallLimit)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+
+		photos := []WallPhoto{}
+		var emails []string
+		for rows.Next() {
+			var p WallPhoto
+			if err := rows.Scan(&p.ID, &p.FilePath, &p.Caption, &p.Credit); err != nil {
+				return nil, err
+			}
+			emails = append(emails, p.Credit)
+			photos = append(photos, p)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+
+		// Public names only - the wall is on a TV in the bar
+		names := authlib.PublicNames(identityDB, emails)
+		for i := range photos {
+			photos[i].Credit = names[photos[i].Credit]
+		}
+		return photos, nil
+	})
+}
+
+// withPhotoWalls swaps each photo_wall item in a playlist for one "photo"
+// slide per wall photo, at the wall's duration. A wall with no photos yet is
+// left out. The playlist is cached without its photos, since they depend on
+// the display's venue.
+func withPhotoWalls(ctx context.Context, playlist PlaylistWithContent, venueID int) (PlaylistWithContent, error) {
+	hasWall := false
+	for _, item := range playlist.Items {
+		hasWall = hasWall || item.ContentType == "photo_wall"
+	}
+	if !hasWall {
+		return playlist, nil
+	}
+
+	photos, err := wallPhotos(ctx, venueID)
+	if err != nil {
+		return playlist, err
+	}
+
+	items := []ContentItem{}
+	for _, item := range playlist.Items {
+		if item.ContentType != "photo_wall" {
+			items = append(items, item)
+			continue
+		}
+		for _, p := range photos {
+			items = append(items, ContentItem{
+				ID:              item.ID,
+				Title:           p.Credit,
+				ContentType:     "photo",
+				DurationSeconds: item.DurationSeconds,
+				FilePath:        p.FilePath,
+				TextContent:     p.Caption,
+				BgColor:         item.BgColor,
+				TextColor:       item.TextColor,
+				IsActive:        true,
+			})
+		}
+	}
+	playlist.Items = items
+	return playlist, nil
+}
+
+// invalidatePhotoWalls drops the cached wall photos a venue's change shows up
+// in: the venue's own, and chain-wide displays'
+func invalidatePhotoWalls(venueID int) {
+	keys := []string{"photos:0"}
+	if venueID != 0 {
+		keys = append(keys, "photos:"+strconv.Itoa(venueID))
+	}
+	if err := readCache.Invalidate(context.Background(), keys...); err != nil {
+		log.Printf("⚠️  Failed to invalidate cached wall photos: %v", err)
+	}
+}
+
+// runPhotoExpiry deletes expired photos and their files, on startup and then
+// hourly. Walls stop showing a photo as soon as it expires; this is the cleanup.
+func runPhotoExpiry() {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		expirePhotos()
+		<-ticker.C
+	}
+}
+
+func expirePhotos() {
+	rows, err := db.Query(`
+		DELETE FROM photo_submissions WHERE expires_at < CURRENT_TIMESTAMP
+		RETURNING COALESCE(file_path, ''), COALESCE(venue_id, 0)
+	`)
+	if err != nil {
+		log.Printf("❌ Error expiring photos: %v", err)
+		return
+	}
+	defer rows.Close()
+
+	expired := 0
+	venues := map[int]bool{}
+	for rows.Next() {
+		var filePath string
+		var venueID int
+		if rows.Scan(&filePath, &venueID) != nil {
+			continue
+		}
+		expired++
+		venues[venueID] = true
+		removePhotoFile(filePath)
+	}
+	for venueID := range venues {
+		invalidatePhotoWalls(venueID)
+	}
+	if expired > 0 {
+		log.Printf("🗑️  Expired %d photo wall submissions", expired)
+	}
+}
@@ -12,8 +12,9 @@ import (
 // handleGetPlaylists returns all playlists
 func handleGetPlaylists(w http.ResponseWriter, r *http.Request) {
 	rows, err := db.Query(`
-		SELECT id, name, description, is_active, created_by, created_at, updated_at
+		SELECT id, name, description, is_active, created_by, created_at, updated_at, guid::text
 		FROM playlists
+		WHERE deleted_at IS NULL
 		ORDER BY created_at DESC
 	`)
 	if err != nil {
@@ -27,7 +28,7 @@ func handleGetPlaylists(w http.ResponseWriter, r *http.Request) {
 	for rows.Next() {
 		var p Playlist
 		var createdBy sql.NullString
-		err := rows.Scan(&p.ID, &p.Name, &p.Description, &p.IsActive, &createdBy, &p.CreatedAt, &p.UpdatedAt)
+		err := rows.Scan(&p.ID, &p.Name, &p.Description, &p.IsActive, &createdBy, &p.CreatedAt, &p.UpdatedAt, &p.Guid)
 		if err != nil {
 			log.Printf("❌ Error scanning playlist: %v", err)
 			continue
@@ -48,11 +49,11 @@ func handleGetPlaylist(w http.ResponseWriter, r *http.Request) {
 	var playlist Playlist
 	var createdBy sql.NullString
 	err := db.QueryRow(`
-		SELECT id, name, description, is_active, created_by, created_at, updated_at
+		SELECT id, name, description, is_active, created_by, created_at, updated_at, guid::text
 		FROM playlists
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`, id).Scan(&playlist.ID, &playlist.Name, &playlist.Description, &playlist.IsActive,
-		&createdBy, &playlist.CreatedAt, &playlist.UpdatedAt)
+		&createdBy, &playlist.CreatedAt, &playlist.UpdatedAt, &playlist.Guid)
 
 	if err == sql.ErrNoRows {
 		respondError(w, "Playlist not found", http.StatusNotFound)
@@ -69,10 +70,10 @@ func handleGetPlaylist(w http.ResponseWriter, r *http.Request) {
 	rows, err := db.Query(`
 		SELECT c.id, c.title, c.content_type, c.duration_seconds, c.file_path,
 		       c.url, c.text_content, c.bg_color, c.text_color, c.is_active,
-		       c.created_by, c.created_at, c.updated_at,
+		       c.created_by, c.created_at, c.updated_at, c.guid::text,
 		       pi.override_duration, pi.display_order
 		FROM playlist_items pi
-		JOIN content_items c ON pi.content_item_id = c.id
+		JOIN content_items c ON pi.content_item_id = c.id AND c.deleted_at IS NULL
 		WHERE pi.playlist_id = $1
 		ORDER BY pi.display_order ASC
 	`, id)
@@ -93,7 +94,7 @@ func handleGetPlaylist(w http.ResponseWriter, r *http.Request) {
 
 		err := rows.Scan(&c.ID, &c.Title, &c.ContentType, &c.DurationSeconds,
 			&filePath, &url, &textContent, &bgColor, &textColor, &c.IsActive,
-			&contentCreatedBy, &c.CreatedAt, &c.UpdatedAt,
+			&contentCreatedBy, &c.CreatedAt, &c.UpdatedAt, &c.Guid,
 			&overrideDuration, &displayOrder)
 
 		if err != nil {
@@ -152,10 +153,10 @@ func handleCreatePlaylist(w http.ResponseWriter, r *http.Request) {
 	err := db.QueryRow(`
 		INSERT INTO playlists (name, description, created_by, is_active)
 		VALUES ($1, $2, $3, true)
-		RETURNING id, name, description, is_active, created_by, created_at, updated_at
-	`, req.Name, req.Description, user.Email).Scan(
+		RETURNING id, name, description, is_active, created_by, created_at, updated_at, guid::text
+	`, req.Name, req.Description, user.ActorEmail()).Scan(
 		&playlist.ID, &playlist.Name, &playlist.Description, &playlist.IsActive,
-		&createdBy, &playlist.CreatedAt, &playlist.UpdatedAt,
+		&createdBy, &playlist.CreatedAt, &playlist.UpdatedAt, &playlist.Guid,
 	)
 
 	if err != nil {
@@ -186,6 +187,8 @@ func handleUpdatePlaylist(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	before := snapshot(playlistSnapshot, id)
+
 	var playlist Playlist
 	var createdBy sql.NullString
 	err := db.QueryRow(`
@@ -194,11 +197,11 @@ func handleUpdatePlaylist(w http.ResponseWriter, r *http.Request) {
 		    description = COALESCE(NULLIF($2, ''), description),
 		    is_active = COALESCE($3, is_active),
 		    updated_at = CURRENT_TIMESTAMP
-		WHERE id = $4
-		RETURNING id, name, description, is_active, created_by, created_at, updated_at
+		WHERE id = $4 AND deleted_at IS NULL
+		RETURNING id, name, description, is_active, created_by, created_at, updated_at, guid::text
 	`, req.Name, req.Description, req.IsActive, id).Scan(
 		&playlist.ID, &playlist.Name, &playlist.Description, &playlist.IsActive,
-		&createdBy, &playlist.CreatedAt, &playlist.UpdatedAt,
+		&createdBy, &playlist.CreatedAt, &playlist.UpdatedAt, &playlist.Guid,
 	)
 
 	if err == sql.ErrNoRows {
@@ -212,16 +215,24 @@ func handleUpdatePlaylist(w http.ResponseWriter, r *http.Request) {
 
 	playlist.CreatedBy = createdBy.String
 
+	invalidatePlaylist(id)
+	logAuditChange(r, "playlist_update", id, nil, before, snapshot(playlistSnapshot, id))
+
 	log.Printf("✅ Updated playlist: %s", playlist.Name)
 	respondJSON(w, APIResponse{Success: true, Data: playlist})
 }
 
-// handleDeletePlaylist deletes a playlist
+// handleDeletePlaylist moves a playlist to the trash; displays assigned to it
+// fall through to their next assignment until it is restored
 func handleDeletePlaylist(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	result, err := db.Exec("DELETE FROM playlists WHERE id = $1", id)
+	before := snapshot(playlistSnapshot, id)
+	result, err := db.Exec(`
+		UPDATE playlists SET deleted_at = CURRENT_TIMESTAMP, deleted_by = $2
+		WHERE id = $1 AND deleted_at IS NULL
+	`, id, deletedBy(r))
 	if err != nil {
 		log.Printf("❌ Error deleting playlist: %v", err)
 		respondError(w, "Failed to delete playlist", http.StatusInternalServerError)
@@ -234,8 +245,11 @@ func handleDeletePlaylist(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	log.Printf("✅ Deleted playlist ID: %s", id)
-	respondJSON(w, APIResponse{Success: true, Data: map[string]string{"message": "Playlist deleted"}})
+	invalidatePlaylist(id)
+	logAuditChange(r, "playlist_delete", id, nil, before, snapshot(playlistSnapshot, id))
+
+	log.Printf("🗑️  Moved playlist ID %s to trash", id)
+	respondJSON(w, APIResponse{Success: true, Data: map[string]string{"message": "Playlist moved to trash"}})
 }
 
 // handleAddPlaylistItem adds a content item to a playlist
@@ -258,6 +272,28 @@ func handleAddPlaylistItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Contributor uploads can't be shown until a reviewer approves them
+	var status, contentType string
+	err := db.QueryRow("SELECT status, content_type FROM content_items WHERE id = $1 AND deleted_at IS NULL", req.ContentItemID).Scan(&status, &contentType)
+	if err == sql.ErrNoRows {
+		respondError(w, "Content not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Printf("❌ Error fetching content status: %v", err)
+		respondError(w, "Failed to add item to playlist", http.StatusInternalServerError)
+		return
+	}
+	if status != "approved" {
+		respondError(w, "Content must be approved before it can be added to a playlist", http.StatusConflict)
+		return
+	}
+	if contentType == "ticker" {
+		respondError(w, "Tickers are set on a display, not added to a playlist", http.StatusBadRequest)
+		return
+	}
+
+	before := snapshot(playlistSnapshot, playlistID)
+
 	// Get next display_order
 	var maxOrder sql.NullInt32
 	db.QueryRow("SELECT MAX(display_order) FROM playlist_items WHERE playlist_id = $1", playlistID).Scan(&maxOrder)
@@ -266,7 +302,7 @@ func handleAddPlaylistItem(w http.ResponseWriter, r *http.Request) {
 		nextOrder = int(maxOrder.Int32) + 1
 	}
 
-	_, err := db.Exec(`
+	_, err = db.Exec(`
 		INSERT INTO playlist_items (playlist_id, content_item_id, display_order, override_duration)
 		VALUES ($1, $2, $3, $4)
 	`, playlistID, req.ContentItemID, nextOrder, req.OverrideDuration)
@@ -277,6 +313,11 @@ func handleAddPlaylistItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	invalidatePlaylist(playlistID)
+	logAuditChange(r, "playlist_items_add", playlistID, map[string]interface{}{
+		"content_item_ids": []int{req.ContentItemID},
+	}, before, snapshot(playlistSnapshot, playlistID))
+
 	log.Printf("✅ Added content %d to playlist %s", req.ContentItemID, playlistID)
 	respondJSON(w, APIResponse{Success: true, Data: map[string]string{"message": "Item added to playlist"}})
 }
@@ -296,6 +337,7 @@ func handleUpdatePlaylistItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	before := snapshot(playlistSnapshot, playlistID)
 	result, err := db.Exec(`
 		UPDATE playlist_items
 		SET override_duration = $1
@@ -314,6 +356,11 @@ func handleUpdatePlaylistItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	invalidatePlaylist(playlistID)
+	logAuditChange(r, "playlist_item_update", playlistID, map[string]interface{}{
+		"content_item_id": itemID,
+	}, before, snapshot(playlistSnapshot, playlistID))
+
 	log.Printf("✅ Updated playlist item: playlist %s, content %s", playlistID, itemID)
 	respondJSON(w, APIResponse{Success: true, Data: map[string]string{"message": "Playlist item updated"}})
 }
@@ -324,6 +371,7 @@ func handleRemovePlaylistItem(w http.ResponseWriter, r *http.Request) {
 	playlistID := vars["id"]
 	itemID := vars["itemId"]
 
+	before := snapshot(playlistSnapshot, playlistID)
 	result, err := db.Exec(`
 		DELETE FROM playlist_items
 		WHERE playlist_id = $1 AND content_item_id = $2
@@ -358,6 +406,11 @@ func handleRemovePlaylistItem(w http.ResponseWriter, r *http.Request) {
 		log.Printf("⚠️  Warning: Failed to reorder items after deletion: %v", err)
 	}
 
+	invalidatePlaylist(playlistID)
+	logAuditChange(r, "playlist_item_remove", playlistID, map[string]interface{}{
+		"content_item_id": itemID,
+	}, before, snapshot(playlistSnapshot, playlistID))
+
 	log.Printf("✅ Removed content %s from playlist %s", itemID, playlistID)
 	respondJSON(w, APIResponse{Success: true, Data: map[string]string{"message": "Item removed from playlist"}})
 }
@@ -381,6 +434,8 @@ func handleReorderPlaylist(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	before := snapshot(playlistSnapshot, playlistID)
+
 	// Update display_order for each item
 	tx, err := db.Begin()
 	if err != nil {
@@ -410,6 +465,9 @@ func handleReorderPlaylist(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	invalidatePlaylist(playlistID)
+	logAuditChange(r, "playlist_reorder", playlistID, nil, before, snapshot(playlistSnapshot, playlistID))
+
 	log.Printf("✅ Reordered playlist %s", playlistID)
 	respondJSON(w, APIResponse{Success: true, Data: map[string]string{"message": "Playlist reordered"}})
 }
@@ -1,15 +1,22 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"log"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/achgithub/activity-hub-common/cache"
+	"github.com/achgithub/activity-hub-common/hours"
 	"github.com/gorilla/mux"
 )
 
+// openingHours decides when displays switch to their after-hours playlist
+var openingHours *hours.Hours
+
 // handlePreviewPlaylist returns a preview of a playlist with all content
 func handlePreviewPlaylist(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -19,11 +26,11 @@ func handlePreviewPlaylist(w http.ResponseWriter, r *http.Request) {
 	var playlist Playlist
 	var createdBy sql.NullString
 	err := db.QueryRow(`
-		SELECT id, name, description, is_active, created_by, created_at, updated_at
+		SELECT id, name, description, is_active, created_by, created_at, updated_at, guid::text
 		FROM playlists
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`, playlistID).Scan(&playlist.ID, &playlist.Name, &playlist.Description, &playlist.IsActive,
-		&createdBy, &playlist.CreatedAt, &playlist.UpdatedAt)
+		&createdBy, &playlist.CreatedAt, &playlist.UpdatedAt, &playlist.Guid)
 
 	if err == sql.ErrNoRows {
 		respondError(w, "Playlist not found", http.StatusNotFound)
@@ -43,7 +50,7 @@ func handlePreviewPlaylist(w http.ResponseWriter, r *http.Request) {
 		       c.created_by, c.created_at, c.updated_at,
 		       pi.override_duration, pi.display_order
 		FROM playlist_items pi
-		JOIN content_items c ON pi.content_item_id = c.id
+		JOIN content_items c ON pi.content_item_id = c.id AND c.deleted_at IS NULL
 		WHERE pi.playlist_id = $1
 		ORDER BY pi.display_order ASC
 	`, playlistID)
@@ -99,11 +106,33 @@ func handlePreviewPlaylist(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, APIResponse{Success: true, Data: result})
 }
 
-// handlePreviewDisplay returns the current active playlist for a display
+// handlePreviewDisplay returns the current active playlist for a display (admin preview)
 func handlePreviewDisplay(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
-	displayID := vars["id"]
+	respondDisplayPlaylist(w, r, vars["id"])
+}
+
+// handleGetPlaylistByToken returns the display's current playlist to the TV.
+// Content is only served against a valid token, so a revoked device stops at once.
+func handleGetPlaylistByToken(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	token := vars["token"]
 
+	displayID, err := displayIDForToken(token)
+	if err == sql.ErrNoRows {
+		respondError(w, "Display not found or inactive", http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Printf("❌ Error fetching display by token: %v", err)
+		respondError(w, "Failed to fetch display", http.StatusInternalServerError)
+		return
+	}
+
+	respondDisplayPlaylist(w, r, strconv.Itoa(displayID))
+}
+
+// respondDisplayPlaylist writes the playlist active now for a display
+func respondDisplayPlaylist(w http.ResponseWriter, r *http.Request, displayID string) {
 	// Get active playlist based on current time and scheduling rules
 	playlistID := getActivePlaylistForDisplay(displayID)
 
@@ -115,20 +144,45 @@ func handlePreviewDisplay(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get playlist with content
+	result, err := cachedPlaylist(r.Context(), playlistID)
+	if err != nil {
+		log.Printf("❌ Error fetching playlist %d: %v", playlistID, err)
+		respondError(w, "Failed to fetch playlist", http.StatusInternalServerError)
+		return
+	}
+
+	// Photo walls show the display's venue's photos
+	var venueID int
+	db.QueryRow("SELECT COALESCE(venue_id, 0) FROM displays WHERE id = $1", displayID).Scan(&venueID)
+	if result, err = withPhotoWalls(r.Context(), result, venueID); err != nil {
+		log.Printf("❌ Error fetching wall photos: %v", err)
+		respondError(w, "Failed to fetch playlist", http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, APIResponse{Success: true, Data: result})
+}
+
+// cachedPlaylist is loadPlaylistWithContent through the playlist cache
+func cachedPlaylist(ctx context.Context, playlistID int) (PlaylistWithContent, error) {
+	return cache.Fetch(ctx, readCache, "playlist:"+strconv.Itoa(playlistID), func() (PlaylistWithContent, error) {
+		return loadPlaylistWithContent(playlistID)
+	})
+}
+
+// loadPlaylistWithContent reads a playlist and its live content in order, with
+// duration overrides applied
+func loadPlaylistWithContent(playlistID int) (PlaylistWithContent, error) {
 	var playlist Playlist
 	var createdBy sql.NullString
 	err := db.QueryRow(`
-		SELECT id, name, description, is_active, created_by, created_at, updated_at
+		SELECT id, name, description, is_active, created_by, created_at, updated_at, guid::text
 		FROM playlists
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`, playlistID).Scan(&playlist.ID, &playlist.Name, &playlist.Description, &playlist.IsActive,
-		&createdBy, &playlist.CreatedAt, &playlist.UpdatedAt)
-
+		&createdBy, &playlist.CreatedAt, &playlist.UpdatedAt, &playlist.Guid)
 	if err != nil {
-		log.Printf("❌ Error fetching playlist: %v", err)
-		respondError(w, "Failed to fetch playlist", http.StatusInternalServerError)
-		return
+		return PlaylistWithContent{}, err
 	}
 
 	playlist.CreatedBy = createdBy.String
@@ -140,15 +194,12 @@ func handlePreviewDisplay(w http.ResponseWriter, r *http.Request) {
 		       c.created_by, c.created_at, c.updated_at,
 		       pi.override_duration, pi.display_order
 		FROM playlist_items pi
-		JOIN content_items c ON pi.content_item_id = c.id
+		JOIN content_items c ON pi.content_item_id = c.id AND c.deleted_at IS NULL
 		WHERE pi.playlist_id = $1
 		ORDER BY pi.display_order ASC
 	`, playlistID)
-
 	if err != nil {
-		log.Printf("❌ Error fetching playlist items: %v", err)
-		respondError(w, "Failed to fetch playlist items", http.StatusInternalServerError)
-		return
+		return PlaylistWithContent{}, err
 	}
 	defer rows.Close()
 
@@ -184,18 +235,42 @@ func handlePreviewDisplay(w http.ResponseWriter, r *http.Request) {
 		items = append(items, c)
 	}
 
-	result := PlaylistWithContent{
-		Playlist: playlist,
-		Items:    items,
-	}
+	return PlaylistWithContent{Playlist: playlist, Items: items}, nil
+}
 
-	respondJSON(w, APIResponse{Success: true, Data: result})
+// afterHoursPlaylist returns the playlist a display shows while its venue is
+// closed, or 0 if the venue is open or the display has none
+func afterHoursPlaylist(displayID string, now time.Time) int {
+	var venueID, playlistID int
+	err := db.QueryRow(`
+		SELECT COALESCE(d.venue_id, 0), p.id
+		FROM displays d
+		JOIN playlists p ON p.id = d.after_hours_playlist_id AND p.deleted_at IS NULL
+		WHERE d.id = $1
+	`, displayID).Scan(&venueID, &playlistID)
+	if err == sql.ErrNoRows {
+		return 0
+	}
+	if err != nil {
+		log.Printf("❌ Error querying after-hours playlist: %v", err)
+		return 0
+	}
+	if openingHours.IsOpen(venueID, now) {
+		return 0
+	}
+	return playlistID
 }
 
 // getActivePlaylistForDisplay determines which playlist should be active now
-// based on scheduling rules and priority
+// based on scheduling rules and priority. While the display's venue is closed
+// its after-hours playlist, if it has one, takes over from the schedule.
 func getActivePlaylistForDisplay(displayID string) int {
 	now := time.Now()
+	if playlistID := afterHoursPlaylist(displayID, now); playlistID != 0 {
+		log.Printf("🌙 After-hours playlist for display %s: playlist %d", displayID, playlistID)
+		return playlistID
+	}
+
 	currentDate := now.Format("2006-01-02")
 	currentTime := now.Format("15:04:05")
 	currentDay := now.Weekday().String()[:3] // "Mon", "Tue", etc.
@@ -204,6 +279,7 @@ func getActivePlaylistForDisplay(displayID string) int {
 		SELECT playlist_id, priority, start_date, end_date, start_time, end_time, days_of_week
 		FROM display_assignments
 		WHERE display_id = $1
+		  AND playlist_id IN (SELECT id FROM playlists WHERE deleted_at IS NULL)
 		ORDER BY priority DESC
 	`, displayID)
 
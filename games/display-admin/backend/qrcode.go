@@ -10,27 +10,52 @@ import (
 	"github.com/skip2/go-qrcode"
 )
 
-// handleGetDisplayQR generates and returns a QR code PNG for a display
+// handleGetDisplayQR generates a QR code PNG that pairs a TV with the display.
+// Pass ?code= to encode a pairing code already shown to the admin; otherwise
+// a new one is issued.
 func handleGetDisplayQR(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	// Fetch display token
-	var token string
-	err := db.QueryRow("SELECT token FROM displays WHERE id = $1", id).Scan(&token)
+	var displayID int
+	err := db.QueryRow("SELECT id FROM displays WHERE id = $1 AND ($2 = 0 OR venue_id = $2) AND deleted_at IS NULL", id, userVenueID(r)).Scan(&displayID)
 	if err == sql.ErrNoRows {
 		respondError(w, "Display not found", http.StatusNotFound)
 		return
 	} else if err != nil {
-		log.Printf("❌ Error fetching display token: %v", err)
+		log.Printf("❌ Error fetching display: %v", err)
 		respondError(w, "Failed to fetch display", http.StatusInternalServerError)
 		return
 	}
 
-	// Build runtime URL
-	host := getEnv("RUNTIME_HOST", "192.168.1.45")
-	runtimePort := getEnv("RUNTIME_PORT", "5051")
-	url := fmt.Sprintf("http://%s:%s?token=%s", host, runtimePort, token)
+	code := r.URL.Query().Get("code")
+	if code != "" {
+		err = db.QueryRow(`
+			SELECT code FROM display_pairing_codes
+			WHERE code = $1 AND display_id = $2 AND expires_at > CURRENT_TIMESTAMP
+		`, code, displayID).Scan(&code)
+		if err == sql.ErrNoRows {
+			respondError(w, "Pairing code expired", http.StatusNotFound)
+			return
+		} else if err != nil {
+			log.Printf("❌ Error fetching pairing code: %v", err)
+			respondError(w, "Failed to generate QR code", http.StatusInternalServerError)
+			return
+		}
+	} else {
+		createdBy := ""
+		if user := getUserFromContext(r); user != nil {
+			createdBy = user.ActorEmail()
+		}
+		pc, err := issuePairingCode(displayID, createdBy)
+		if err != nil {
+			log.Printf("❌ Error creating pairing code: %v", err)
+			respondError(w, "Failed to create pairing code", http.StatusInternalServerError)
+			return
+		}
+		code = pc.Code
+	}
+	url := pairingURL(code)
 
 	// Generate QR code
 	png, err := qrcode.Encode(url, qrcode.Medium, 256)
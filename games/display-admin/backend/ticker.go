@@ -0,0 +1,348 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// A ticker is a content item (content_type 'ticker') whose messages scroll
+// along the bottom of a display, over whatever the playlist is showing. It is
+// set on the display (displays.ticker_id) rather than added to a playlist, and
+// can also scroll the venue's activity feed.
+
+// tickerExists reports whether id is a live ticker content item
+func tickerExists(id string) (bool, error) {
+	var exists bool
+	err := db.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM content_items WHERE id = $1 AND content_type = 'ticker' AND deleted_at IS NULL)
+	`, id).Scan(&exists)
+	return exists, err
+}
+
+// requireTicker writes a 404 and returns false unless id is a live ticker
+func requireTicker(w http.ResponseWriter, id string) bool {
+	exists, err := tickerExists(id)
+	if err != nil {
+		log.Printf("❌ Error checking ticker: %v", err)
+		respondError(w, "Failed to fetch ticker", http.StatusInternalServerError)
+		return false
+	}
+	if !exists {
+		respondError(w, "Ticker not found", http.StatusNotFound)
+		return false
+	}
+	return true
+}
+
+func scanTickerMessage(row interface{ Scan(...interface{}) error }) (TickerMessage, error) {
+	var m TickerMessage
+	var startsAt, endsAt sql.NullTime
+	var createdBy sql.NullString
+	err := row.Scan(&m.ID, &m.ContentItemID, &m.Text, &m.IsActive, &startsAt, &endsAt,
+		&createdBy, &m.CreatedAt, &m.UpdatedAt)
+	if startsAt.Valid {
+		m.StartsAt = &startsAt.Time
+	}
+	if endsAt.Valid {
+		m.EndsAt = &endsAt.Time
+	}
+	m.CreatedBy = createdBy.String
+	return m, err
+}
+
+const tickerMessageColumns = `id, content_item_id, text, is_active, starts_at, ends_at, created_by, created_at, updated_at`
+
+// handleGetTicker returns a ticker's settings and all its messages, including
+// inactive and scheduled ones
+func handleGetTicker(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	if !requireTicker(w, id) {
+		return
+	}
+
+	var activityFeed bool
+	err := db.QueryRow(`SELECT activity_feed FROM ticker_settings WHERE content_item_id = $1`, id).Scan(&activityFeed)
+	if err != nil && err != sql.ErrNoRows {
+		log.Printf("❌ Error fetching ticker settings: %v", err)
+		respondError(w, "Failed to fetch ticker", http.StatusInternalServerError)
+		return
+	}
+
+	rows, err := db.Query(`
+		SELECT `+tickerMessageColumns+`
+		FROM ticker_messages
+		WHERE content_item_id = $1
+		ORDER BY created_at, id
+	`, id)
+	if err != nil {
+		log.Printf("❌ Error querying ticker messages: %v", err)
+		respondError(w, "Failed to fetch ticker", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	messages := []TickerMessage{}
+	for rows.Next() {
+		m, err := scanTickerMessage(rows)
+		if err != nil {
+			log.Printf("❌ Error scanning ticker message: %v", err)
+			continue
+		}
+		messages = append(messages, m)
+	}
+
+	respondJSON(w, APIResponse{Success: true, Data: map[string]interface{}{
+		"activity_feed": activityFeed,
+		"messages":      messages,
+	}})
+}
+
+// handleUpdateTickerSettings turns the activity feed on or off for a ticker
+func handleUpdateTickerSettings(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	var req struct {
+		ActivityFeed bool `json:"activity_feed"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if !requireTicker(w, id) {
+		return
+	}
+
+	before := snapshot(tickerSnapshot, id)
+	_, err := db.Exec(`
+		INSERT INTO ticker_settings (content_item_id, activity_feed)
+		VALUES ($1, $2)
+		ON CONFLICT (content_item_id) DO UPDATE SET activity_feed = $2, updated_at = CURRENT_TIMESTAMP
+	`, id, req.ActivityFeed)
+	if err != nil {
+		log.Printf("❌ Error updating ticker settings: %v", err)
+		respondError(w, "Failed to update ticker", http.StatusInternalServerError)
+		return
+	}
+
+	logAuditChange(r, "ticker_update", id, nil, before, snapshot(tickerSnapshot, id))
+
+	log.Printf("✅ Updated ticker %s (activity feed: %v)", id, req.ActivityFeed)
+	respondJSON(w, APIResponse{Success: true, Data: map[string]bool{"activity_feed": req.ActivityFeed}})
+}
+
+// tickerMessageRequest is the body for creating or replacing a ticker message
+type tickerMessageRequest struct {
+	Text     string     `json:"text"`
+	IsActive *bool      `json:"is_active"`
+	StartsAt *time.Time `json:"starts_at"`
+	EndsAt   *time.Time `json:"ends_at"`
+}
+
+func (req *tickerMessageRequest) validate() string {
+	req.Text = strings.TrimSpace(req.Text)
+	if req.Text == "" {
+		return "Text is required"
+	}
+	if req.StartsAt != nil && req.EndsAt != nil && !req.EndsAt.After(*req.StartsAt) {
+		return "ends_at must be after starts_at"
+	}
+	return ""
+}
+
+// handleCreateTickerMessage adds a message to a ticker
+func handleCreateTickerMessage(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	var req tickerMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if msg := req.validate(); msg != "" {
+		respondError(w, msg, http.StatusBadRequest)
+		return
+	}
+	if !requireTicker(w, id) {
+		return
+	}
+	isActive := true
+	if req.IsActive != nil {
+		isActive = *req.IsActive
+	}
+
+	var createdBy string
+	if user := getUserFromContext(r); user != nil {
+		createdBy = user.ActorEmail()
+	}
+
+	before := snapshot(tickerSnapshot, id)
+	message, err := scanTickerMessage(db.QueryRow(`
+		INSERT INTO ticker_messages (content_item_id, text, is_active, starts_at, ends_at, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING `+tickerMessageColumns,
+		id, req.Text, isActive, req.StartsAt, req.EndsAt, createdBy))
+	if err != nil {
+		log.Printf("❌ Error creating ticker message: %v", err)
+		respondError(w, "Failed to add ticker message", http.StatusInternalServerError)
+		return
+	}
+
+	logAuditChange(r, "ticker_message_add", id, map[string]interface{}{"message_id": message.ID},
+		before, snapshot(tickerSnapshot, id))
+
+	log.Printf("✅ Added message %d to ticker %s", message.ID, id)
+	respondJSON(w, APIResponse{Success: true, Data: message})
+}
+
+// handleUpdateTickerMessage replaces a message's text, active flag and window
+func handleUpdateTickerMessage(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, messageID := vars["id"], vars["messageId"]
+
+	var req tickerMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if msg := req.validate(); msg != "" {
+		respondError(w, msg, http.StatusBadRequest)
+		return
+	}
+
+	before := snapshot(tickerSnapshot, id)
+	message, err := scanTickerMessage(db.QueryRow(`
+		UPDATE ticker_messages
+		SET text = $1, is_active = COALESCE($2, is_active), starts_at = $3, ends_at = $4,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $5 AND content_item_id = $6
+		RETURNING `+tickerMessageColumns,
+		req.Text, req.IsActive, req.StartsAt, req.EndsAt, messageID, id))
+	if err == sql.ErrNoRows {
+		respondError(w, "Ticker message not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Printf("❌ Error updating ticker message: %v", err)
+		respondError(w, "Failed to update ticker message", http.StatusInternalServerError)
+		return
+	}
+
+	mid, _ := strconv.Atoi(messageID)
+	logAuditChange(r, "ticker_message_update", id, map[string]interface{}{"message_id": mid},
+		before, snapshot(tickerSnapshot, id))
+
+	log.Printf("✅ Updated message %s on ticker %s", messageID, id)
+	respondJSON(w, APIResponse{Success: true, Data: message})
+}
+
+// handleDeleteTickerMessage removes a message from a ticker
+func handleDeleteTickerMessage(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, messageID := vars["id"], vars["messageId"]
+
+	before := snapshot(tickerSnapshot, id)
+	result, err := db.Exec(`DELETE FROM ticker_messages WHERE id = $1 AND content_item_id = $2`, messageID, id)
+	if err != nil {
+		log.Printf("❌ Error deleting ticker message: %v", err)
+		respondError(w, "Failed to delete ticker message", http.StatusInternalServerError)
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		respondError(w, "Ticker message not found", http.StatusNotFound)
+		return
+	}
+
+	mid, _ := strconv.Atoi(messageID)
+	logAuditChange(r, "ticker_message_remove", id, map[string]interface{}{"message_id": mid},
+		before, snapshot(tickerSnapshot, id))
+
+	log.Printf("🗑️  Removed message %s from ticker %s", messageID, id)
+	respondJSON(w, APIResponse{Success: true, Data: map[string]string{"message": "Ticker message removed"}})
+}
+
+// handleGetTickerByToken returns what a display's ticker should scroll right
+// now. Data is null when the display has no ticker, or its ticker was deleted
+// or is no longer approved.
+func handleGetTickerByToken(w http.ResponseWriter, r *http.Request) {
+	token := mux.Vars(r)["token"]
+
+	var tickerID, venueID int
+	err := db.QueryRow(`
+		SELECT COALESCE(ticker_id, 0), COALESCE(venue_id, 0) FROM displays
+		WHERE token = $1 AND is_active = true AND deleted_at IS NULL
+	`, token).Scan(&tickerID, &venueID)
+	if err == sql.ErrNoRows {
+		respondError(w, "Display not found or inactive", http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Printf("❌ Error fetching display by token: %v", err)
+		respondError(w, "Failed to fetch display", http.StatusInternalServerError)
+		return
+	}
+
+	feed, err := loadTickerFeed(tickerID, venueID)
+	if err != nil {
+		log.Printf("❌ Error fetching display ticker: %v", err)
+		respondError(w, "Failed to fetch ticker", http.StatusInternalServerError)
+		return
+	}
+	if feed == nil {
+		respondJSON(w, APIResponse{Success: true})
+		return
+	}
+	respondJSON(w, APIResponse{Success: true, Data: feed})
+}
+
+// loadTickerFeed reads a ticker's active messages inside their window, in the
+// order they were added, for a display at venueID. It returns nil if tickerID
+// is 0 or not a live, approved ticker.
+func loadTickerFeed(tickerID, venueID int) (*TickerFeed, error) {
+	if tickerID == 0 {
+		return nil, nil
+	}
+
+	feed := TickerFeed{TickerID: tickerID, VenueID: venueID}
+	var bgColor, textColor sql.NullString
+	err := db.QueryRow(`
+		SELECT c.title, c.bg_color, c.text_color, COALESCE(s.activity_feed, false)
+		FROM content_items c
+		LEFT JOIN ticker_settings s ON s.content_item_id = c.id
+		WHERE c.id = $1 AND c.content_type = 'ticker'
+		  AND c.status = 'approved' AND c.is_active AND c.deleted_at IS NULL
+	`, tickerID).Scan(&feed.Title, &bgColor, &textColor, &feed.ActivityFeed)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	feed.BgColor = bgColor.String
+	feed.TextColor = textColor.String
+
+	rows, err := db.Query(`
+		SELECT id, text FROM ticker_messages
+		WHERE content_item_id = $1 AND is_active
+		  AND (starts_at IS NULL OR starts_at <= CURRENT_TIMESTAMP)
+		  AND (ends_at IS NULL OR ends_at > CURRENT_TIMESTAMP)
+		ORDER BY created_at, id
+	`, tickerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	feed.Messages = []TickerFeedMessage{}
+	for rows.Next() {
+		var m TickerFeedMessage
+		if err := rows.Scan(&m.ID, &m.Text); err != nil {
+			continue
+		}
+		feed.Messages = append(feed.Messages, m)
+	}
+	return &feed, rows.Err()
+}
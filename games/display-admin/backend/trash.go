@@ -0,0 +1,174 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Deleted displays, content and playlists stay in the trash for this many days
+// (TRASH_RETENTION_DAYS) before the purge removes them for good
+const defaultTrashRetentionDays = 30
+
+// trashTables maps the trash item types to their tables
+var trashTables = map[string]string{
+	"display":  "displays",
+	"content":  "content_items",
+	"playlist": "playlists",
+}
+
+func trashRetentionDays() int {
+	days, err := strconv.Atoi(getEnv("TRASH_RETENTION_DAYS", ""))
+	if err != nil || days < 1 {
+		return defaultTrashRetentionDays
+	}
+	return days
+}
+
+// deletedBy is the email recorded against a delete
+func deletedBy(r *http.Request) interface{} {
+	if user := getUserFromContext(r); user != nil {
+		return user.ActorEmail()
+	}
+	return nil
+}
+
+// handleGetTrash lists deleted displays (in the admin's venue), content and
+// playlists, most recently deleted first
+func handleGetTrash(w http.ResponseWriter, r *http.Request) {
+	rows, err := db.Query(`
+		SELECT 'display', id, name, deleted_at, COALESCE(deleted_by, '')
+		FROM displays
+		WHERE deleted_at IS NOT NULL AND ($1 = 0 OR venue_id = $1)
+		UNION ALL
+		SELECT 'content', id, title, deleted_at, COALESCE(deleted_by, '')
+		FROM content_items WHERE deleted_at IS NOT NULL
+		UNION ALL
+		SELECT 'playlist', id, name, deleted_at, COALESCE(deleted_by, '')
+		FROM playlists WHERE deleted_at IS NOT NULL
+		ORDER BY 4 DESC
+	`, userVenueID(r))
+	if err != nil {
+		log.Printf("❌ Error querying trash: %v", err)
+		respondError(w, "Failed to fetch trash", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	retention := time.Duration(trashRetentionDays()) * 24 * time.Hour
+	items := []TrashItem{}
+	for rows.Next() {
+		var item TrashItem
+		if err := rows.Scan(&item.Type, &item.ID, &item.Name, &item.DeletedAt, &item.DeletedBy); err != nil {
+			log.Printf("❌ Error scanning trash item: %v", err)
+			continue
+		}
+		item.PurgeAt = item.DeletedAt.Add(retention)
+		items = append(items, item)
+	}
+
+	respondJSON(w, APIResponse{Success: true, Data: map[string]interface{}{
+		"items":          items,
+		"retention_days": trashRetentionDays(),
+	}})
+}
+
+// handleRestoreTrash takes a display, content item or playlist back out of the trash
+func handleRestoreTrash(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	table, ok := trashTables[vars["type"]]
+	if !ok {
+		respondError(w, "Unknown trash item type", http.StatusBadRequest)
+		return
+	}
+
+	query := "UPDATE " + table + " SET deleted_at = NULL, deleted_by = NULL WHERE id = $1 AND deleted_at IS NOT NULL"
+	args := []interface{}{vars["id"]}
+	if table == "displays" {
+		query += " AND ($2 = 0 OR venue_id = $2)"
+		args = append(args, userVenueID(r))
+	}
+
+	result, err := db.Exec(query, args...)
+	if err != nil {
+		log.Printf("❌ Error restoring %s %s: %v", vars["type"], vars["id"], err)
+		respondError(w, "Failed to restore item", http.StatusInternalServerError)
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		respondError(w, "Item not found in trash", http.StatusNotFound)
+		return
+	}
+
+	switch vars["type"] {
+	case "content":
+		invalidateContentPlaylists(vars["id"])
+	case "playlist":
+		invalidatePlaylist(vars["id"])
+	}
+
+	log.Printf("♻️  Restored %s ID %s from trash", vars["type"], vars["id"])
+	respondJSON(w, APIResponse{Success: true, Data: map[string]string{"message": "Restored"}})
+}
+
+// runTrashPurge permanently removes trash older than the retention period,
+// on startup and then hourly. While any venue is open the purge waits, so
+// the large deletes don't compete with players.
+func runTrashPurge() {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		if !openingHours.Peak(time.Now()) {
+			purgeTrash()
+		}
+		<-ticker.C
+	}
+}
+
+func purgeTrash() {
+	cutoff := time.Now().AddDate(0, 0, -trashRetentionDays())
+
+	// Uploaded images go with their content item
+	rows, err := db.Query(`
+		DELETE FROM content_items WHERE deleted_at < $1 RETURNING COALESCE(file_path, '')
+	`, cutoff)
+	if err != nil {
+		log.Printf("❌ Error purging content from trash: %v", err)
+	} else {
+		purged := 0
+		for rows.Next() {
+			var filePath string
+			if rows.Scan(&filePath) != nil {
+				continue
+			}
+			purged++
+			if filePath != "" {
+				fsPath := filepath.Join("./uploads", strings.TrimPrefix(filePath, "/uploads/"))
+				if err := os.Remove(fsPath); err != nil && !os.IsNotExist(err) {
+					log.Printf("⚠️  Warning: Could not delete file: %s", fsPath)
+				}
+			}
+		}
+		rows.Close()
+		if purged > 0 {
+			log.Printf("🗑️  Purged %d content items from trash", purged)
+		}
+	}
+
+	for _, table := range []string{"playlists", "displays"} {
+		result, err := db.Exec("DELETE FROM "+table+" WHERE deleted_at < $1", cutoff)
+		if err != nil {
+			log.Printf("❌ Error purging %s from trash: %v", table, err)
+			continue
+		}
+		if n, _ := result.RowsAffected(); n > 0 {
+			log.Printf("🗑️  Purged %d %s from trash", n, table)
+		}
+	}
+}
@@ -6,18 +6,46 @@ import React, { useState, useEffect, useCallback } from 'react';
 
 interface Display {
   id: number;
+  guid: string;
   name: string;
   location: string;
   description: string;
   token: string;
   is_active: boolean;
+  token_rotated_at?: string;
+  revoked_at?: string;
   created_at: string;
+  ticker_id: number; // 0 = no ticker
+  layout: string;
+}
+
+// A layout template's zone, placed in percent of the screen
+interface LayoutTemplate {
+  name: string;
+  label: string;
+  zones: { name: string; x: number; y: number; width: number; height: number }[];
+}
+
+// What a display's zone shows; both IDs 0 is the zone's default
+interface ZoneBinding {
+  zone: string;
+  playlist_id: number;
+  content_item_id: number;
+}
+
+// Short-lived code a TV enters (or scans) to receive its display token
+interface PairingCode {
+  code: string;
+  display_id: number;
+  url: string;
+  expires_at: string;
 }
 
 interface ContentItem {
   id: number;
+  guid: string;
   title: string;
-  content_type: 'image' | 'url' | 'social_feed' | 'leaderboard' | 'schedule' | 'announcement';
+  content_type: 'image' | 'url' | 'social_feed' | 'leaderboard' | 'schedule' | 'announcement' | 'activity_feed' | 'medal_table' | 'ticker' | 'photo_wall';
   duration_seconds: number;
   file_path?: string;
   url?: string;
@@ -28,6 +56,51 @@ interface ContentItem {
   created_by: string;
   created_at: string;
   updated_at: string;
+  status: 'approved' | 'pending' | 'rejected';
+  review_note?: string;
+  reviewed_by?: string;
+}
+
+// A line a ticker scrolls; the window is optional
+interface TickerMessage {
+  id: number;
+  text: string;
+  is_active: boolean;
+  starts_at?: string;
+  ends_at?: string;
+}
+
+// A photo sent in for the photo wall; file_path is gone once rejected
+interface PhotoSubmission {
+  id: number;
+  file_path?: string;
+  caption: string;
+  submitted_by: string;
+  venue_id: number;
+  status: 'approved' | 'pending' | 'rejected';
+  review_note?: string;
+  reviewed_by?: string;
+  expires_at: string;
+  created_at: string;
+}
+
+// Signed-in user's permissions (display_contributor users only submit content,
+// everyone else only photos)
+interface Me {
+  email: string;
+  is_admin: boolean;
+  can_review: boolean;
+  contributor: boolean;
+}
+
+interface ContentNotification {
+  id: number;
+  content_title: string;
+  decision: 'approved' | 'rejected';
+  note?: string;
+  decided_by: string;
+  created_at: string;
+  read_at?: string;
 }
 
 interface PlaylistItem {
@@ -42,6 +115,7 @@ interface PlaylistItem {
 
 interface Playlist {
   id: number;
+  guid: string;
   name: string;
   description: string;
   is_active: boolean;
@@ -67,7 +141,29 @@ interface DisplayAssignment {
   playlist?: Playlist;
 }
 
-type TabType = 'displays' | 'content' | 'playlists' | 'assignments';
+interface DisplayCommand {
+  id: number;
+  display_id: number;
+  command: DisplayCommandType;
+  status: 'pending' | 'delivered' | 'done' | 'failed' | 'expired';
+  message?: string;
+  created_by: string;
+  created_at: string;
+  acknowledged_at?: string;
+}
+
+type DisplayCommandType = 'refresh' | 'clear_cache' | 'reboot';
+
+interface TrashItem {
+  type: 'display' | 'content' | 'playlist';
+  id: number;
+  name: string;
+  deleted_at: string;
+  deleted_by: string;
+  purge_at: string;
+}
+
+type TabType = 'displays' | 'content' | 'playlists' | 'assignments' | 'trash' | 'photos';
 
 // ============================================================================
 // MAIN APP
@@ -76,14 +172,18 @@ type TabType = 'displays' | 'content' | 'playlists' | 'assignments';
 const App: React.FC = () => {
   // Get token from URL (passed by identity shell)
   const params = new URLSearchParams(window.location.search);
-  const token = params.get('token') || 'demo-token-admin@pubgames.local';
+  const token = params.get('token') || sessionStorage.getItem('token') || 'demo-token-admin@pubgames.local';
 
   // State
   const [activeTab, setActiveTab] = useState<TabType>('displays');
+  const [me, setMe] = useState<Me | null>(null);
+  const [notifications, setNotifications] = useState<ContentNotification[]>([]);
   const [displays, setDisplays] = useState<Display[]>([]);
   const [content, setContent] = useState<ContentItem[]>([]);
   const [playlists, setPlaylists] = useState<Playlist[]>([]);
   const [assignments, setAssignments] = useState<DisplayAssignment[]>([]);
+  const [trash, setTrash] = useState<TrashItem[]>([]);
+  const [retentionDays, setRetentionDays] = useState(30);
   const [loading, setLoading] = useState(false);
   const [error, setError] = useState<string>('');
   const [selectedQRDisplay, setSelectedQRDisplay] = useState<Display | null>(null);
@@ -126,10 +226,18 @@ const App: React.FC = () => {
     }
   }, [token]);
 
+  // The library, approvals count and playlist editor all work from the whole
+  // list, so every page is fetched
   const loadContent = useCallback(async () => {
     try {
-      const data = await apiCall('/api/content');
-      setContent(data.data || []);
+      let items: ContentItem[] = [];
+      let offset: number | undefined = 0;
+      while (offset !== undefined) {
+        const data = await apiCall(`/api/content?limit=500&offset=${offset}`);
+        items = items.concat(data.data || []);
+        offset = data.page?.hasMore ? data.page.nextOffset : undefined;
+      }
+      setContent(items);
     } catch (err: any) {
       setError(`Failed to load content: ${err.message}`);
     }
@@ -153,12 +261,45 @@ const App: React.FC = () => {
     }
   }, [token]);
 
+  const loadTrash = useCallback(async () => {
+    try {
+      const data = await apiCall('/api/trash');
+      setTrash(data.data?.items || []);
+      setRetentionDays(data.data?.retention_days || 30);
+    } catch (err: any) {
+      setError(`Failed to load trash: ${err.message}`);
+    }
+  }, [token]);
+
+  const loadNotifications = useCallback(async () => {
+    try {
+      const data = await apiCall('/api/notifications');
+      setNotifications((data.data || []).filter((n: ContentNotification) => !n.read_at));
+    } catch (err: any) {
+      setError(`Failed to load notifications: ${err.message}`);
+    }
+  }, [token]);
+
   useEffect(() => {
-    loadDisplays();
-    loadContent();
-    loadPlaylists();
-    loadAssignments();
-  }, [loadDisplays, loadContent, loadPlaylists, loadAssignments]);
+    apiCall('/api/me')
+      .then(data => setMe(data.data))
+      .catch((err: any) => setError(`Failed to load user: ${err.message}`));
+  }, [token]);
+
+  // Contributors only get the Content tab (their own submissions) and review
+  // decisions; everyone else only sends in photos
+  useEffect(() => {
+    if (!me) return;
+    if (me.is_admin || me.contributor) loadContent();
+    if (me.is_admin) {
+      loadDisplays();
+      loadPlaylists();
+      loadAssignments();
+    } else {
+      setActiveTab(me.contributor ? 'content' : 'photos');
+    }
+    if (me.is_admin || me.contributor) loadNotifications();
+  }, [me, loadDisplays, loadContent, loadPlaylists, loadAssignments, loadNotifications]);
 
   // ============================================================================
   // DISPLAY HANDLERS
@@ -181,7 +322,7 @@ const App: React.FC = () => {
   };
 
   const deleteDisplay = async (id: number) => {
-    if (!window.confirm('Delete this display?')) return;
+    if (!window.confirm('Move this display to the trash? Its TV stops showing content until it is restored.')) return;
     try {
       await apiCall(`/api/displays/${id}`, { method: 'DELETE' });
       await loadDisplays();
@@ -190,10 +331,67 @@ const App: React.FC = () => {
     }
   };
 
+  // Remote commands go out over the display's push channel; the TV acknowledges each one
+  const sendCommand = async (id: number, command: DisplayCommandType) => {
+    if (command === 'reboot' && !window.confirm('Reboot the player app on this display?')) return;
+    try {
+      await apiCall(`/api/displays/${id}/commands`, {
+        method: 'POST',
+        body: JSON.stringify({ command }),
+      });
+    } catch (err: any) {
+      setError(err.message);
+    }
+  };
+
+  const loadCommands = useCallback(async (id: number): Promise<{ connected: boolean; commands: DisplayCommand[] }> => {
+    const data = await apiCall(`/api/displays/${id}/commands`);
+    return data.data || { connected: false, commands: [] };
+  }, [token]);
+
+  // The TV picks up a new ticker on its next refresh
+  const setDisplayTicker = async (id: number, tickerId: number) => {
+    try {
+      await apiCall(`/api/displays/${id}`, {
+        method: 'PUT',
+        body: JSON.stringify({ ticker_id: tickerId }),
+      });
+      await loadDisplays();
+    } catch (err: any) {
+      setError(err.message);
+    }
+  };
+
   const showQRCode = (display: Display) => {
     setSelectedQRDisplay(display);
   };
 
+  const createPairingCode = async (id: number): Promise<PairingCode> => {
+    const data = await apiCall(`/api/displays/${id}/pairing-code`, { method: 'POST' });
+    return data.data;
+  };
+
+  // Rotating or revoking disconnects the TV at once; it has to pair again
+  const rotateToken = async (id: number) => {
+    if (!window.confirm('Issue a new token? The TV will need to be paired again.')) return;
+    try {
+      await apiCall(`/api/displays/${id}/rotate-token`, { method: 'POST' });
+      await loadDisplays();
+    } catch (err: any) {
+      setError(err.message);
+    }
+  };
+
+  const revokeDisplay = async (id: number) => {
+    if (!window.confirm('Revoke this display? The device stops showing content immediately and is deactivated until a new TV is paired.')) return;
+    try {
+      await apiCall(`/api/displays/${id}/revoke`, { method: 'POST' });
+      await loadDisplays();
+    } catch (err: any) {
+      setError(err.message);
+    }
+  };
+
   // ============================================================================
   // CONTENT HANDLERS
   // ============================================================================
@@ -244,7 +442,7 @@ const App: React.FC = () => {
   };
 
   const deleteContent = async (id: number) => {
-    if (!window.confirm('Delete this content?')) return;
+    if (!window.confirm('Move this content to the trash?')) return;
     try {
       await apiCall(`/api/content/${id}`, { method: 'DELETE' });
       await loadContent();
@@ -273,11 +471,11 @@ const App: React.FC = () => {
     }
   };
 
-  const addToPlaylist = async (playlistId: number, contentId: number) => {
+  const addToPlaylist = async (playlistId: number, contentIds: number[]) => {
     try {
-      await apiCall(`/api/playlists/${playlistId}/items`, {
+      await apiCall(`/api/playlists/${playlistId}/items/batch`, {
         method: 'POST',
-        body: JSON.stringify({ content_item_id: contentId }),
+        body: JSON.stringify({ content_item_ids: contentIds }),
       });
       await loadPlaylists();
     } catch (err: any) {
@@ -285,6 +483,15 @@ const App: React.FC = () => {
     }
   };
 
+  const clonePlaylist = async (id: number) => {
+    try {
+      await apiCall(`/api/playlists/${id}/clone`, { method: 'POST' });
+      await loadPlaylists();
+    } catch (err: any) {
+      setError(err.message);
+    }
+  };
+
   const removeFromPlaylist = async (playlistId: number, itemId: number) => {
     if (!window.confirm('Remove from playlist?')) return;
     try {
@@ -298,7 +505,7 @@ const App: React.FC = () => {
   };
 
   const deletePlaylist = async (id: number) => {
-    if (!window.confirm('Delete this playlist?')) return;
+    if (!window.confirm('Move this playlist to the trash?')) return;
     try {
       await apiCall(`/api/playlists/${id}`, { method: 'DELETE' });
       await loadPlaylists();
@@ -327,6 +534,71 @@ const App: React.FC = () => {
     }
   };
 
+  const copyAssignments = async (fromDisplayId: number, toDisplayId: number, replace: boolean) => {
+    if (replace && !window.confirm('Replace all assignments on the target display?')) return;
+    try {
+      setLoading(true);
+      await apiCall('/api/assignments/copy', {
+        method: 'POST',
+        body: JSON.stringify({ from_display_id: fromDisplayId, to_display_id: toDisplayId, replace }),
+      });
+      await loadAssignments();
+      setError('');
+    } catch (err: any) {
+      setError(err.message);
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  // ============================================================================
+  // TRASH HANDLERS
+  // ============================================================================
+
+  const restoreItem = async (item: TrashItem) => {
+    try {
+      await apiCall(`/api/trash/${item.type}/${item.id}/restore`, { method: 'POST' });
+      await loadTrash();
+      if (item.type === 'display') await loadDisplays();
+      if (item.type === 'content') await loadContent();
+      if (item.type === 'playlist') await loadPlaylists();
+      await loadAssignments();
+    } catch (err: any) {
+      setError(err.message);
+    }
+  };
+
+  // ============================================================================
+  // REVIEW HANDLERS
+  // ============================================================================
+
+  const reviewContent = async (id: number, decision: 'approve' | 'reject') => {
+    let note = '';
+    if (decision === 'reject') {
+      const reason = window.prompt('Reason for rejecting (shown to the contributor):');
+      if (reason === null) return;
+      note = reason;
+    }
+    try {
+      await apiCall(`/api/content/${id}/${decision}`, {
+        method: 'POST',
+        body: JSON.stringify({ note }),
+      });
+      await loadContent();
+    } catch (err: any) {
+      setError(err.message);
+    }
+  };
+
+  const dismissNotification = async (id: number) => {
+    try {
+      await apiCall(`/api/notifications/${id}/read`, { method: 'POST' });
+      setNotifications(prev => prev.filter(n => n.id !== id));
+    } catch (err: any) {
+      setError(err.message);
+    }
+  };
+
   const deleteAssignment = async (id: number) => {
     if (!window.confirm('Delete this assignment?')) return;
     try {
@@ -350,18 +622,26 @@ const App: React.FC = () => {
 
       {/* Tab Navigation */}
       <div style={styles.tabs}>
-        <button
-          style={activeTab === 'displays' ? styles.activeTab : styles.tab}
-          onClick={() => setActiveTab('displays')}
-        >
-          Displays
-        </button>
-        <button
-          style={activeTab === 'content' ? styles.activeTab : styles.tab}
-          onClick={() => setActiveTab('content')}
-        >
-          Content
-        </button>
+        {me?.is_admin && (
+          <button
+            style={activeTab === 'displays' ? styles.activeTab : styles.tab}
+            onClick={() => setActiveTab('displays')}
+          >
+            Displays
+          </button>
+        )}
+        {(me?.is_admin || me?.contributor) && (
+          <button
+            style={activeTab === 'content' ? styles.activeTab : styles.tab}
+            onClick={() => setActiveTab('content')}
+          >
+            Content
+            {me?.can_review && content.some(c => c.status === 'pending') && (
+              <span style={styles.pendingCount}>{content.filter(c => c.status === 'pending').length}</span>
+            )}
+          </button>
+        )}
+        {me?.is_admin && (<>
         <button
           style={activeTab === 'playlists' ? styles.activeTab : styles.tab}
           onClick={() => setActiveTab('playlists')}
@@ -374,8 +654,29 @@ const App: React.FC = () => {
         >
           Assignments
         </button>
+        <button
+          style={activeTab === 'trash' ? styles.activeTab : styles.tab}
+          onClick={() => { setActiveTab('trash'); loadTrash(); }}
+        >
+          Trash
+        </button>
+        </>)}
+        <button
+          style={activeTab === 'photos' ? styles.activeTab : styles.tab}
+          onClick={() => setActiveTab('photos')}
+        >
+          Photo Wall
+        </button>
       </div>
 
+      {/* Review decisions on the user's submissions */}
+      {notifications.map(n => (
+        <div key={n.id} style={n.decision === 'approved' ? styles.notice : styles.error}>
+          "{n.content_title}" was {n.decision} by {n.decided_by}{n.note && `: ${n.note}`}
+          <button onClick={() => dismissNotification(n.id)} style={styles.closeBtn}>×</button>
+        </div>
+      ))}
+
       {/* Error Display */}
       {error && (
         <div style={styles.error}>
@@ -392,6 +693,16 @@ const App: React.FC = () => {
             onCreate={createDisplay}
             onDelete={deleteDisplay}
             onShowQR={showQRCode}
+            onRotateToken={rotateToken}
+            onRevoke={revokeDisplay}
+            onSendCommand={sendCommand}
+            onLoadCommands={loadCommands}
+            tickers={content.filter(c => c.content_type === 'ticker' && c.status === 'approved')}
+            onSetTicker={setDisplayTicker}
+            playlists={playlists}
+            content={content.filter(c => c.status === 'approved')}
+            api={apiCall}
+            onLayoutSaved={loadDisplays}
             loading={loading}
           />
         )}
@@ -401,6 +712,10 @@ const App: React.FC = () => {
             onCreate={createContent}
             onUpload={uploadImage}
             onDelete={deleteContent}
+            onReview={reviewContent}
+            canReview={!!me?.can_review}
+            canDelete={!!me?.is_admin}
+            api={apiCall}
             loading={loading}
           />
         )}
@@ -411,6 +726,7 @@ const App: React.FC = () => {
             onCreate={createPlaylist}
             onAddItem={addToPlaylist}
             onRemoveItem={removeFromPlaylist}
+            onClone={clonePlaylist}
             onDelete={deletePlaylist}
             loading={loading}
           />
@@ -421,17 +737,25 @@ const App: React.FC = () => {
             displays={displays}
             playlists={playlists}
             onCreate={createAssignment}
+            onCopy={copyAssignments}
             onDelete={deleteAssignment}
             loading={loading}
           />
         )}
+        {activeTab === 'trash' && (
+          <TrashTab items={trash} retentionDays={retentionDays} onRestore={restoreItem} />
+        )}
+        {activeTab === 'photos' && (
+          <PhotosTab api={apiCall} apiBase={API_BASE} token={token} canReview={!!me?.can_review} />
+        )}
       </div>
 
       {/* QR Code Modal */}
       {selectedQRDisplay && (
         <QRModal
           display={selectedQRDisplay}
-          onClose={() => setSelectedQRDisplay(null)}
+          onCreateCode={createPairingCode}
+          onClose={() => { setSelectedQRDisplay(null); loadDisplays(); }}
           apiBase={API_BASE}
           token={token}
         />
@@ -449,8 +773,18 @@ const DisplaysTab: React.FC<{
   onCreate: (name: string, location: string, description: string) => void;
   onDelete: (id: number) => void;
   onShowQR: (display: Display) => void;
+  onRotateToken: (id: number) => void;
+  onRevoke: (id: number) => void;
+  onSendCommand: (id: number, command: DisplayCommandType) => Promise<void>;
+  onLoadCommands: (id: number) => Promise<{ connected: boolean; commands: DisplayCommand[] }>;
+  tickers: ContentItem[];
+  onSetTicker: (id: number, tickerId: number) => void;
+  playlists: Playlist[];
+  content: ContentItem[];
+  api: (endpoint: string, options?: RequestInit) => Promise<any>;
+  onLayoutSaved: () => void;
   loading: boolean;
-}> = ({ displays, onCreate, onDelete, onShowQR, loading }) => {
+}> = ({ displays, onCreate, onDelete, onShowQR, onRotateToken, onRevoke, onSendCommand, onLoadCommands, tickers, onSetTicker, playlists, content, api, onLayoutSaved, loading }) => {
   const [name, setName] = useState('');
   const [location, setLocation] = useState('');
   const [description, setDescription] = useState('');
@@ -509,8 +843,22 @@ const DisplaysTab: React.FC<{
                   onClick={() => onShowQR(display)}
                   style={styles.btnSecondary}
                 >
-                  QR Code
+                  Pair
                 </button>
+                <button
+                  onClick={() => onRotateToken(display.id)}
+                  style={styles.btnSecondary}
+                >
+                  Rotate Token
+                </button>
+                {!display.revoked_at && (
+                  <button
+                    onClick={() => onRevoke(display.id)}
+                    style={styles.btnDanger}
+                  >
+                    Revoke
+                  </button>
+                )}
                 <button
                   onClick={() => onDelete(display.id)}
                   style={styles.btnDanger}
@@ -520,8 +868,40 @@ const DisplaysTab: React.FC<{
               </div>
             </div>
             <p style={styles.cardText}><strong>Location:</strong> {display.location}</p>
-            <p style={styles.cardText}><strong>Status:</strong> {display.is_active ? 'Active' : 'Inactive'}</p>
-            <p style={styles.cardText}><strong>Token:</strong> <code style={styles.code}>{display.token}</code></p>
+            <p style={styles.cardText}>
+              <strong>Status:</strong>{' '}
+              {display.revoked_at
+                ? `Revoked ${new Date(display.revoked_at).toLocaleString()} — pair a new TV to reactivate`
+                : display.is_active ? 'Active' : 'Inactive'}
+            </p>
+            {display.token_rotated_at && (
+              <p style={styles.cardText}><strong>Token issued:</strong> {new Date(display.token_rotated_at).toLocaleString()}</p>
+            )}
+            <p style={styles.cardText}>
+              <strong>Ticker:</strong>{' '}
+              <select
+                value={display.ticker_id || 0}
+                onChange={(e) => onSetTicker(display.id, parseInt(e.target.value))}
+                style={styles.select}
+              >
+                <option value={0}>None</option>
+                {tickers.map(t => (
+                  <option key={t.id} value={t.id}>{t.title}</option>
+                ))}
+              </select>
+            </p>
+            <DisplayLayoutEditor
+              display={display}
+              playlists={playlists}
+              content={content}
+              api={api}
+              onSaved={onLayoutSaved}
+            />
+            <DisplayCommands
+              displayId={display.id}
+              onSend={onSendCommand}
+              onLoad={onLoadCommands}
+            />
           </div>
         ))}
       </div>
@@ -529,6 +909,203 @@ const DisplaysTab: React.FC<{
   );
 };
 
+// Zone defaults, shown as the first choice in each zone's select
+const ZONE_DEFAULTS: Record<string, string> = {
+  main: 'Scheduled playlists',
+  sidebar: 'Nothing',
+  ticker: "The display's ticker",
+};
+
+// Splits a display's screen into zones (main, sidebar, ticker), each showing
+// its own playlist or a single content item as a live widget
+const DisplayLayoutEditor: React.FC<{
+  display: Display;
+  playlists: Playlist[];
+  content: ContentItem[];
+  api: (endpoint: string, options?: RequestInit) => Promise<any>;
+  onSaved: () => void;
+}> = ({ display, playlists, content, api, onSaved }) => {
+  const [open, setOpen] = useState(false);
+  const [templates, setTemplates] = useState<LayoutTemplate[]>([]);
+  const [layout, setLayout] = useState(display.layout || 'fullscreen');
+  // Zone -> "p:<playlist id>", "c:<content id>" or "" for the default
+  const [choices, setChoices] = useState<Record<string, string>>({});
+  const [error, setError] = useState('');
+
+  useEffect(() => {
+    if (!open) return;
+    Promise.all([api('/api/layouts'), api(`/api/displays/${display.id}/layout`)])
+      .then(([layouts, current]) => {
+        setTemplates(layouts.data || []);
+        setLayout(current.data?.layout || 'fullscreen');
+        const next: Record<string, string> = {};
+        (current.data?.zones || []).forEach((b: ZoneBinding) => {
+          next[b.zone] = b.playlist_id ? `p:${b.playlist_id}` : b.content_item_id ? `c:${b.content_item_id}` : '';
+        });
+        setChoices(next);
+      })
+      .catch((err: any) => setError(err.message));
+  }, [open, display.id]);
+
+  const template = templates.find(t => t.name === layout);
+
+  const save = async () => {
+    const zones: ZoneBinding[] = (template?.zones || []).map(z => {
+      const [kind, id] = (choices[z.name] || '').split(':');
+      return {
+        zone: z.name,
+        playlist_id: kind === 'p' ? parseInt(id) : 0,
+        content_item_id: kind === 'c' ? parseInt(id) : 0,
+      };
+    });
+    try {
+      await api(`/api/displays/${display.id}/layout`, {
+        method: 'PUT',
+        body: JSON.stringify({ layout, zones }),
+      });
+      setError('');
+      setOpen(false);
+      onSaved();
+    } catch (err: any) {
+      setError(err.message);
+    }
+  };
+
+  if (!open) {
+    return (
+      <p style={styles.cardText}>
+        <strong>Layout:</strong> {display.layout || 'fullscreen'}{' '}
+        <button onClick={() => setOpen(true)} style={styles.btnSecondary}>
+          Edit Layout
+        </button>
+      </p>
+    );
+  }
+
+  return (
+    <div style={styles.commandPanel}>
+      {error && <p style={{ ...styles.cardText, color: '#dc3545' }}>{error}</p>}
+      <label style={styles.cardText}>
+        <strong>Layout:</strong>{' '}
+        <select value={layout} onChange={(e) => setLayout(e.target.value)} style={styles.select}>
+          {templates.map(t => (
+            <option key={t.name} value={t.name}>{t.label}</option>
+          ))}
+        </select>
+      </label>
+      {template?.zones.map(z => (
+        <p key={z.name} style={styles.cardText}>
+          <strong>{z.name}</strong> ({z.width}% × {z.height}%):{' '}
+          <select
+            value={choices[z.name] || ''}
+            onChange={(e) => setChoices({ ...choices, [z.name]: e.target.value })}
+            style={styles.select}
+          >
+            <option value="">{ZONE_DEFAULTS[z.name] || 'Nothing'}</option>
+            {z.name === 'ticker' ? (
+              content.filter(c => c.content_type === 'ticker').map(c => (
+                <option key={c.id} value={`c:${c.id}`}>{c.title}</option>
+              ))
+            ) : (
+              <>
+                <optgroup label="Playlists">
+                  {playlists.map(p => (
+                    <option key={p.id} value={`p:${p.id}`}>{p.name}</option>
+                  ))}
+                </optgroup>
+                <optgroup label="Live widgets">
+                  {content.filter(c => c.content_type !== 'ticker').map(c => (
+                    <option key={c.id} value={`c:${c.id}`}>{c.title} ({c.content_type})</option>
+                  ))}
+                </optgroup>
+              </>
+            )}
+          </select>
+        </p>
+      ))}
+      <div style={styles.cardActions}>
+        <button onClick={save} style={styles.button}>
+          Save Layout
+        </button>
+        <button onClick={() => setOpen(false)} style={styles.btnSecondary}>
+          Cancel
+        </button>
+      </div>
+    </div>
+  );
+};
+
+const COMMAND_LABELS: Record<DisplayCommandType, string> = {
+  refresh: 'Refresh',
+  clear_cache: 'Clear Cache',
+  reboot: 'Reboot',
+};
+
+const STATUS_COLORS: Record<DisplayCommand['status'], string> = {
+  pending: '#999',
+  delivered: '#1565C0',
+  done: '#2E7D32',
+  failed: '#dc3545',
+  expired: '#999',
+};
+
+// Remote controls for one display, with each command's acknowledgement status
+const DisplayCommands: React.FC<{
+  displayId: number;
+  onSend: (id: number, command: DisplayCommandType) => Promise<void>;
+  onLoad: (id: number) => Promise<{ connected: boolean; commands: DisplayCommand[] }>;
+}> = ({ displayId, onSend, onLoad }) => {
+  const [connected, setConnected] = useState(false);
+  const [commands, setCommands] = useState<DisplayCommand[]>([]);
+
+  const refresh = useCallback(() => {
+    onLoad(displayId)
+      .then(data => {
+        setConnected(data.connected);
+        setCommands(data.commands || []);
+      })
+      .catch(() => {});
+  }, [displayId, onLoad]);
+
+  // Poll while the card is shown so acknowledgements appear
+  useEffect(() => {
+    refresh();
+    const interval = setInterval(refresh, 5000);
+    return () => clearInterval(interval);
+  }, [refresh]);
+
+  const send = async (command: DisplayCommandType) => {
+    await onSend(displayId, command);
+    refresh();
+  };
+
+  return (
+    <div style={styles.commandPanel}>
+      <div style={styles.cardActions}>
+        <span style={{ ...styles.cardText, color: connected ? '#2E7D32' : '#999', margin: 0, alignSelf: 'center' }}>
+          {connected ? '● Online' : '○ Offline'}
+        </span>
+        {(Object.keys(COMMAND_LABELS) as DisplayCommandType[]).map(command => (
+          <button
+            key={command}
+            onClick={() => send(command)}
+            style={command === 'reboot' ? styles.btnDanger : styles.btnSecondary}
+          >
+            {COMMAND_LABELS[command]}
+          </button>
+        ))}
+      </div>
+      {commands.slice(0, 5).map(c => (
+        <p key={c.id} style={{ ...styles.cardText, fontSize: '12px' }}>
+          {COMMAND_LABELS[c.command]} · {new Date(c.created_at).toLocaleTimeString()} ·{' '}
+          <strong style={{ color: STATUS_COLORS[c.status] }}>{c.status}</strong>
+          {c.message && ` – ${c.message}`}
+        </p>
+      ))}
+    </div>
+  );
+};
+
 // ============================================================================
 // CONTENT TAB
 // ============================================================================
@@ -538,8 +1115,12 @@ const ContentTab: React.FC<{
   onCreate: (data: any) => void;
   onUpload: (file: File, title: string, duration: number) => void;
   onDelete: (id: number) => void;
+  onReview: (id: number, decision: 'approve' | 'reject') => void;
+  canReview: boolean;
+  canDelete: boolean;
+  api: (endpoint: string, options?: RequestInit) => Promise<any>;
   loading: boolean;
-}> = ({ content, onCreate, onUpload, onDelete, loading }) => {
+}> = ({ content, onCreate, onUpload, onDelete, onReview, canReview, canDelete, api, loading }) => {
   const [mode, setMode] = useState<'create' | 'upload'>('create');
   const [title, setTitle] = useState('');
   const [contentType, setContentType] = useState<string>('announcement');
@@ -566,6 +1147,9 @@ const ContentTab: React.FC<{
       data.text_content = textContent;
       data.bg_color = bgColor;
       data.text_color = textColor;
+    } else if (contentType === 'ticker' || contentType === 'photo_wall') {
+      data.bg_color = bgColor;
+      data.text_color = textColor;
     }
 
     onCreate(data);
@@ -628,6 +1212,10 @@ const ContentTab: React.FC<{
               <option value="social_feed">Social Feed</option>
               <option value="leaderboard">Leaderboard</option>
               <option value="schedule">Schedule</option>
+              <option value="activity_feed">Activity Feed</option>
+              <option value="medal_table">Pub Olympics Medal Table</option>
+              <option value="ticker">Ticker (scrolls over a display)</option>
+              <option value="photo_wall">Photo Wall (approved photos)</option>
             </select>
             <input
               type="number"
@@ -650,15 +1238,28 @@ const ContentTab: React.FC<{
             />
           )}
 
-          {contentType === 'announcement' && (
+          {contentType === 'ticker' && (
+            <p style={styles.cardText}>Add the ticker's messages once it's created, then pick it on a display.</p>
+          )}
+
+          {contentType === 'photo_wall' && (
+            <p style={styles.cardText}>
+              Shows the newest approved photos from the display's venue, one per slide, for the duration above.
+              Colours are for the caption.
+            </p>
+          )}
+
+          {(contentType === 'announcement' || contentType === 'ticker' || contentType === 'photo_wall') && (
             <>
-              <textarea
-                placeholder="Announcement Text *"
-                value={textContent}
-                onChange={(e) => setTextContent(e.target.value)}
-                style={styles.textarea}
-                required
-              />
+              {contentType === 'announcement' && (
+                <textarea
+                  placeholder="Announcement Text *"
+                  value={textContent}
+                  onChange={(e) => setTextContent(e.target.value)}
+                  style={styles.textarea}
+                  required
+                />
+              )}
               <div style={styles.formRow}>
                 <label style={styles.colorLabel}>
                   Background:
@@ -725,11 +1326,36 @@ const ContentTab: React.FC<{
         {content.map((item) => (
           <div key={item.id} style={styles.card}>
             <div style={styles.cardHeader}>
-              <h3 style={styles.cardTitle}>{item.title}</h3>
-              <button onClick={() => onDelete(item.id)} style={styles.btnDanger}>
-                Delete
-              </button>
+              <h3 style={styles.cardTitle}>
+                {item.title}
+                {item.status !== 'approved' && (
+                  <span style={item.status === 'pending' ? styles.pendingBadge : styles.rejectedBadge}>{item.status}</span>
+                )}
+              </h3>
+              <div style={styles.cardActions}>
+                {canReview && item.status === 'pending' && (
+                  <>
+                    <button onClick={() => onReview(item.id, 'approve')} style={styles.btnSecondary}>
+                      Approve
+                    </button>
+                    <button onClick={() => onReview(item.id, 'reject')} style={styles.btnDanger}>
+                      Reject
+                    </button>
+                  </>
+                )}
+                {canDelete && (
+                  <button onClick={() => onDelete(item.id)} style={styles.btnDanger}>
+                    Delete
+                  </button>
+                )}
+              </div>
             </div>
+            {item.status === 'pending' && item.created_by && (
+              <p style={styles.cardText}><strong>Submitted by:</strong> {item.created_by}</p>
+            )}
+            {item.status === 'rejected' && item.review_note && (
+              <p style={styles.cardText}><strong>Rejected:</strong> {item.review_note}</p>
+            )}
             <p style={styles.cardText}>
               <strong>Type:</strong> {item.content_type} | <strong>Duration:</strong> {item.duration_seconds}s
             </p>
@@ -742,6 +1368,7 @@ const ContentTab: React.FC<{
             )}
             {item.url && <p style={styles.cardText}><strong>URL:</strong> {item.url}</p>}
             {item.text_content && <p style={styles.cardText}><strong>Text:</strong> {item.text_content}</p>}
+            {item.content_type === 'ticker' && canDelete && <TickerEditor tickerId={item.id} api={api} />}
           </div>
         ))}
       </div>
@@ -749,6 +1376,141 @@ const ContentTab: React.FC<{
   );
 };
 
+// A ticker's messages and whether it also scrolls the venue's activity feed
+const TickerEditor: React.FC<{
+  tickerId: number;
+  api: (endpoint: string, options?: RequestInit) => Promise<any>;
+}> = ({ tickerId, api }) => {
+  const [open, setOpen] = useState(false);
+  const [activityFeed, setActivityFeed] = useState(false);
+  const [messages, setMessages] = useState<TickerMessage[]>([]);
+  const [text, setText] = useState('');
+  const [startsAt, setStartsAt] = useState('');
+  const [endsAt, setEndsAt] = useState('');
+  const [error, setError] = useState('');
+
+  const base = `/api/content/${tickerId}/ticker`;
+
+  const load = useCallback(() => {
+    api(base)
+      .then(data => {
+        setActivityFeed(!!data.data?.activity_feed);
+        setMessages(data.data?.messages || []);
+      })
+      .catch((err: any) => setError(err.message));
+  }, [base]);
+
+  useEffect(() => {
+    if (open) load();
+  }, [open, load]);
+
+  // Runs a change, then reloads the list
+  const run = async (endpoint: string, options: RequestInit) => {
+    try {
+      await api(endpoint, options);
+      setError('');
+      load();
+    } catch (err: any) {
+      setError(err.message);
+    }
+  };
+
+  const toggleActivityFeed = (on: boolean) =>
+    run(base, { method: 'PUT', body: JSON.stringify({ activity_feed: on }) });
+
+  // datetime-local values are local time; the API takes them as timestamps
+  const toISO = (value: string) => (value ? new Date(value).toISOString() : null);
+
+  const addMessage = async (e: React.FormEvent) => {
+    e.preventDefault();
+    if (!text.trim()) return;
+    await run(`${base}/messages`, {
+      method: 'POST',
+      body: JSON.stringify({ text, starts_at: toISO(startsAt), ends_at: toISO(endsAt) }),
+    });
+    setText('');
+    setStartsAt('');
+    setEndsAt('');
+  };
+
+  const setMessageActive = (m: TickerMessage, isActive: boolean) =>
+    run(`${base}/messages/${m.id}`, {
+      method: 'PUT',
+      body: JSON.stringify({ text: m.text, is_active: isActive, starts_at: m.starts_at || null, ends_at: m.ends_at || null }),
+    });
+
+  const removeMessage = (m: TickerMessage) => {
+    if (!window.confirm('Remove this message from the ticker?')) return;
+    run(`${base}/messages/${m.id}`, { method: 'DELETE' });
+  };
+
+  const windowLabel = (m: TickerMessage) => {
+    if (!m.starts_at && !m.ends_at) return '';
+    const from = m.starts_at ? new Date(m.starts_at).toLocaleString() : 'now';
+    const until = m.ends_at ? new Date(m.ends_at).toLocaleString() : 'removed';
+    return ` (${from} – ${until})`;
+  };
+
+  if (!open) {
+    return (
+      <button onClick={() => setOpen(true)} style={styles.btnSecondary}>
+        Edit Messages
+      </button>
+    );
+  }
+
+  return (
+    <div style={styles.commandPanel}>
+      {error && <p style={{ ...styles.cardText, color: '#dc3545' }}>{error}</p>}
+      <label style={styles.cardText}>
+        <input
+          type="checkbox"
+          checked={activityFeed}
+          onChange={(e) => toggleActivityFeed(e.target.checked)}
+        />{' '}
+        Also scroll the venue's activity feed (game results, new players, ...)
+      </label>
+      {messages.map(m => (
+        <p key={m.id} style={{ ...styles.cardText, opacity: m.is_active ? 1 : 0.5 }}>
+          {m.text}
+          <span style={{ fontSize: '12px', color: '#666' }}>{windowLabel(m)}</span>{' '}
+          <button onClick={() => setMessageActive(m, !m.is_active)} style={styles.btnSecondary}>
+            {m.is_active ? 'Pause' : 'Resume'}
+          </button>{' '}
+          <button onClick={() => removeMessage(m)} style={styles.btnDanger}>
+            Remove
+          </button>
+        </p>
+      ))}
+      {messages.length === 0 && <p style={styles.cardText}>No messages yet.</p>}
+      <form onSubmit={addMessage} style={styles.formRow}>
+        <input
+          type="text"
+          placeholder="Message *"
+          value={text}
+          onChange={(e) => setText(e.target.value)}
+          style={styles.input}
+          required
+        />
+        <label style={styles.colorLabel}>
+          From:
+          <input type="datetime-local" value={startsAt} onChange={(e) => setStartsAt(e.target.value)} style={styles.input} />
+        </label>
+        <label style={styles.colorLabel}>
+          Until:
+          <input type="datetime-local" value={endsAt} onChange={(e) => setEndsAt(e.target.value)} style={styles.input} />
+        </label>
+        <button type="submit" style={styles.button}>
+          Add
+        </button>
+      </form>
+      <button onClick={() => setOpen(false)} style={styles.btnSecondary}>
+        Close
+      </button>
+    </div>
+  );
+};
+
 // ============================================================================
 // PLAYLISTS TAB
 // ============================================================================
@@ -757,15 +1519,16 @@ const PlaylistsTab: React.FC<{
   playlists: Playlist[];
   content: ContentItem[];
   onCreate: (name: string, description: string) => void;
-  onAddItem: (playlistId: number, contentId: number) => void;
+  onAddItem: (playlistId: number, contentIds: number[]) => void;
   onRemoveItem: (playlistId: number, itemId: number) => void;
+  onClone: (id: number) => void;
   onDelete: (id: number) => void;
   loading: boolean;
-}> = ({ playlists, content, onCreate, onAddItem, onRemoveItem, onDelete, loading }) => {
+}> = ({ playlists, content, onCreate, onAddItem, onRemoveItem, onClone, onDelete, loading }) => {
   const [name, setName] = useState('');
   const [description, setDescription] = useState('');
   const [selectedPlaylist, setSelectedPlaylist] = useState<number | null>(null);
-  const [selectedContent, setSelectedContent] = useState<number>(0);
+  const [selectedContent, setSelectedContent] = useState<number[]>([]);
 
   const handleCreate = (e: React.FormEvent) => {
     e.preventDefault();
@@ -776,8 +1539,9 @@ const PlaylistsTab: React.FC<{
   };
 
   const handleAddItem = () => {
-    if (selectedPlaylist && selectedContent) {
+    if (selectedPlaylist && selectedContent.length > 0) {
       onAddItem(selectedPlaylist, selectedContent);
+      setSelectedContent([]);
     }
   };
 
@@ -819,6 +1583,9 @@ const PlaylistsTab: React.FC<{
                 >
                   {selectedPlaylist === playlist.id ? 'Close' : 'Edit'}
                 </button>
+                <button onClick={() => onClone(playlist.id)} style={styles.btnSecondary}>
+                  Duplicate
+                </button>
                 <button onClick={() => onDelete(playlist.id)} style={styles.btnDanger}>
                   Delete
                 </button>
@@ -832,20 +1599,21 @@ const PlaylistsTab: React.FC<{
               <div style={styles.playlistEdit}>
                 {/* Add Item */}
                 <div style={styles.addItem}>
+                  {/* Ctrl/Cmd-click to pick several; they're added in one go */}
                   <select
-                    value={selectedContent}
-                    onChange={(e) => setSelectedContent(parseInt(e.target.value))}
+                    multiple
+                    value={selectedContent.map(String)}
+                    onChange={(e) => setSelectedContent(Array.from(e.target.selectedOptions, (o) => parseInt(o.value)))}
                     style={styles.select}
                   >
-                    <option value={0}>Select content to add...</option>
-                    {content.map((item) => (
+                    {content.filter(item => item.status === 'approved' && item.content_type !== 'ticker').map((item) => (
                       <option key={item.id} value={item.id}>
                         {item.title} ({item.content_type})
                       </option>
                     ))}
                   </select>
-                  <button onClick={handleAddItem} style={styles.btnSecondary}>
-                    Add
+                  <button onClick={handleAddItem} disabled={selectedContent.length === 0} style={styles.btnSecondary}>
+                    Add{selectedContent.length > 1 ? ` ${selectedContent.length}` : ''}
                   </button>
                 </div>
 
@@ -890,10 +1658,14 @@ const AssignmentsTab: React.FC<{
   displays: Display[];
   playlists: Playlist[];
   onCreate: (data: any) => void;
+  onCopy: (fromDisplayId: number, toDisplayId: number, replace: boolean) => void;
   onDelete: (id: number) => void;
   loading: boolean;
-}> = ({ assignments, displays, playlists, onCreate, onDelete, loading }) => {
+}> = ({ assignments, displays, playlists, onCreate, onCopy, onDelete, loading }) => {
   const [displayId, setDisplayId] = useState<number>(0);
+  const [copyFrom, setCopyFrom] = useState<number>(0);
+  const [copyTo, setCopyTo] = useState<number>(0);
+  const [copyReplace, setCopyReplace] = useState(false);
   const [playlistId, setPlaylistId] = useState<number>(0);
   const [priority, setPriority] = useState(5);
   const [startDate, setStartDate] = useState('');
@@ -922,6 +1694,14 @@ const AssignmentsTab: React.FC<{
     resetForm();
   };
 
+  const handleCopy = (e: React.FormEvent) => {
+    e.preventDefault();
+    if (!copyFrom || !copyTo || copyFrom === copyTo) return;
+    onCopy(copyFrom, copyTo, copyReplace);
+    setCopyTo(0);
+    setCopyReplace(false);
+  };
+
   const resetForm = () => {
     setDisplayId(0);
     setPlaylistId(0);
@@ -1039,6 +1819,50 @@ const AssignmentsTab: React.FC<{
         </button>
       </form>
 
+      {/* Copy Form */}
+      <h4 style={styles.subsectionTitle}>Copy Assignments Between Displays</h4>
+      <form onSubmit={handleCopy} style={styles.form}>
+        <div style={styles.formRow}>
+          <select
+            value={copyFrom}
+            onChange={(e) => setCopyFrom(parseInt(e.target.value))}
+            style={styles.select}
+            required
+          >
+            <option value={0}>Copy from *</option>
+            {displays.map((d) => (
+              <option key={d.id} value={d.id}>
+                {d.name} ({d.location})
+              </option>
+            ))}
+          </select>
+          <select
+            value={copyTo}
+            onChange={(e) => setCopyTo(parseInt(e.target.value))}
+            style={styles.select}
+            required
+          >
+            <option value={0}>Copy to *</option>
+            {displays.filter((d) => d.id !== copyFrom).map((d) => (
+              <option key={d.id} value={d.id}>
+                {d.name} ({d.location})
+              </option>
+            ))}
+          </select>
+        </div>
+        <label style={styles.label}>
+          <input
+            type="checkbox"
+            checked={copyReplace}
+            onChange={(e) => setCopyReplace(e.target.checked)}
+          />
+          {' '}Replace the target display's existing assignments
+        </label>
+        <button type="submit" disabled={loading || !copyFrom || !copyTo} style={styles.button}>
+          Copy Assignments
+        </button>
+      </form>
+
       {/* Assignments List */}
       <div style={styles.list}>
         {assignments.map((assignment) => (
@@ -1069,34 +1893,313 @@ const AssignmentsTab: React.FC<{
 };
 
 // ============================================================================
-// QR CODE MODAL
+// TRASH TAB
 // ============================================================================
 
+const trashTypeLabels: Record<TrashItem['type'], string> = {
+  display: 'Display',
+  content: 'Content',
+  playlist: 'Playlist',
+};
+
+// Deleted items wait here for the retention period before being purged for good
+const TrashTab: React.FC<{
+  items: TrashItem[];
+  retentionDays: number;
+  onRestore: (item: TrashItem) => void;
+}> = ({ items, retentionDays, onRestore }) => (
+  <div>
+    <h2 style={styles.sectionTitle}>Trash</h2>
+    <p style={styles.cardText}>
+      Deleted displays, content and playlists are kept for {retentionDays} days, then removed permanently.
+    </p>
+
+    <div style={styles.list}>
+      {items.length === 0 && <p style={styles.emptyText}>The trash is empty.</p>}
+      {items.map((item) => (
+        <div key={`${item.type}-${item.id}`} style={styles.card}>
+          <div style={styles.cardHeader}>
+            <h3 style={styles.cardTitle}>{trashTypeLabels[item.type]}: {item.name}</h3>
+            <button onClick={() => onRestore(item)} style={styles.btnSecondary}>
+              Restore
+            </button>
+          </div>
+          <p style={styles.cardText}>
+            <strong>Deleted:</strong> {new Date(item.deleted_at).toLocaleString()}
+            {item.deleted_by && ` by ${item.deleted_by}`}
+          </p>
+          <p style={styles.cardText}><strong>Purged after:</strong> {new Date(item.purge_at).toLocaleDateString()}</p>
+        </div>
+      ))}
+    </div>
+  </div>
+);
+
+// ============================================================================
+// PHOTO WALL TAB
+// ============================================================================
+
+// Anyone signed in sends in photos and sees what happened to them; reviewers
+// also get the moderation queue and can take approved photos down early
+const PhotosTab: React.FC<{
+  api: (endpoint: string, options?: RequestInit) => Promise<any>;
+  apiBase: string;
+  token: string;
+  canReview: boolean;
+}> = ({ api, apiBase, token, canReview }) => {
+  const [mine, setMine] = useState<PhotoSubmission[]>([]);
+  const [queue, setQueue] = useState<PhotoSubmission[]>([]);
+  const [onWall, setOnWall] = useState<PhotoSubmission[]>([]);
+  const [file, setFile] = useState<File | null>(null);
+  const [caption, setCaption] = useState('');
+  const [sending, setSending] = useState(false);
+  const [error, setError] = useState('');
+
+  const load = useCallback(() => {
+    api('/api/photos/mine')
+      .then(data => setMine(data.data || []))
+      .catch((err: any) => setError(err.message));
+    if (!canReview) return;
+    api('/api/photos?status=pending&limit=100')
+      .then(data => setQueue(data.data || []))
+      .catch((err: any) => setError(err.message));
+    api('/api/photos?status=approved&sort=expires&limit=100')
+      .then(data => setOnWall(data.data || []))
+      .catch((err: any) => setError(err.message));
+  }, [canReview]);
+
+  useEffect(() => {
+    load();
+  }, [load]);
+
+  // Multipart, so not through api (which sends JSON)
+  const submit = async (e: React.FormEvent) => {
+    e.preventDefault();
+    if (!file) return;
+    setSending(true);
+    try {
+      const formData = new FormData();
+      formData.append('photo', file);
+      formData.append('caption', caption);
+      const res = await fetch(`${apiBase}/api/photos`, {
+        method: 'POST',
+        headers: { 'Authorization': `Bearer ${token}` },
+        body: formData,
+      });
+      if (!res.ok) {
+        const data = await res.json().catch(() => ({ error: 'Upload failed' }));
+        throw new Error(data.error || 'Upload failed');
+      }
+      setFile(null);
+      setCaption('');
+      setError('');
+      (e.target as HTMLFormElement).reset();
+      load();
+    } catch (err: any) {
+      setError(err.message);
+    } finally {
+      setSending(false);
+    }
+  };
+
+  // Runs a change, then reloads the lists
+  const run = async (endpoint: string, options: RequestInit) => {
+    try {
+      await api(endpoint, options);
+      setError('');
+      load();
+    } catch (err: any) {
+      setError(err.message);
+    }
+  };
+
+  const review = (photo: PhotoSubmission, decision: 'approve' | 'reject') => {
+    let note = '';
+    if (decision === 'reject') {
+      const reason = window.prompt('Reason for rejecting (shown to the sender):');
+      if (reason === null) return;
+      note = reason;
+    }
+    run(`/api/photos/${photo.id}/${decision}`, { method: 'POST', body: JSON.stringify({ note }) });
+  };
+
+  const remove = (photo: PhotoSubmission) => {
+    if (!window.confirm('Delete this photo? It comes off the wall straight away.')) return;
+    run(`/api/photos/${photo.id}`, { method: 'DELETE' });
+  };
+
+  const photoCard = (photo: PhotoSubmission, actions: React.ReactNode) => (
+    <div key={photo.id} style={styles.card}>
+      <div style={styles.cardHeader}>
+        <h3 style={styles.cardTitle}>
+          {photo.caption || 'No caption'}
+          {photo.status === 'pending' && <span style={styles.pendingBadge}>Pending review</span>}
+          {photo.status === 'rejected' && <span style={styles.rejectedBadge}>Rejected</span>}
+        </h3>
+        <div style={styles.cardActions}>{actions}</div>
+      </div>
+      {photo.file_path && <img src={`${apiBase}${photo.file_path}`} alt={photo.caption} style={styles.thumbnail} />}
+      {photo.status === 'rejected' && photo.review_note && (
+        <p style={styles.cardText}><strong>Rejected:</strong> {photo.review_note}</p>
+      )}
+      <p style={styles.cardText}>
+        <strong>Sent by:</strong> {photo.submitted_by} | <strong>{photo.status === 'approved' ? 'On the wall until' : 'Deleted after'}:</strong>{' '}
+        {new Date(photo.expires_at).toLocaleString()}
+      </p>
+    </div>
+  );
+
+  return (
+    <div>
+      <h2 style={styles.sectionTitle}>Photo Wall</h2>
+      {error && (
+        <div style={styles.error}>
+          {error}
+          <button onClick={() => setError('')} style={styles.closeBtn}>×</button>
+        </div>
+      )}
+
+      <form onSubmit={submit} style={styles.form}>
+        <p style={styles.cardText}>
+          Send in a photo for the TVs. It shows once it's been approved; location and camera details are removed.
+        </p>
+        <input
+          type="file"
+          accept="image/jpeg,image/png,image/gif,image/webp"
+          onChange={(e) => setFile(e.target.files?.[0] || null)}
+          style={styles.fileInput}
+          required
+        />
+        <input
+          type="text"
+          placeholder="Caption (optional)"
+          value={caption}
+          maxLength={140}
+          onChange={(e) => setCaption(e.target.value)}
+          style={styles.input}
+        />
+        <button type="submit" disabled={sending} style={styles.button}>
+          {sending ? 'Sending...' : 'Send Photo'}
+        </button>
+      </form>
+
+      {canReview && (
+        <>
+          <h3 style={styles.subsectionTitle}>Waiting for review ({queue.length})</h3>
+          <div style={styles.list}>
+            {queue.length === 0 && <p style={styles.emptyText}>Nothing to review.</p>}
+            {queue.map(photo => photoCard(photo, (
+              <>
+                <button onClick={() => review(photo, 'approve')} style={styles.btnSecondary}>Approve</button>
+                <button onClick={() => review(photo, 'reject')} style={styles.btnDanger}>Reject</button>
+              </>
+            )))}
+          </div>
+
+          <h3 style={styles.subsectionTitle}>On the wall ({onWall.length})</h3>
+          <div style={styles.list}>
+            {onWall.length === 0 && <p style={styles.emptyText}>No approved photos.</p>}
+            {onWall.map(photo => photoCard(photo, (
+              <button onClick={() => remove(photo)} style={styles.btnDanger}>Take Down</button>
+            )))}
+          </div>
+        </>
+      )}
+
+      <h3 style={styles.subsectionTitle}>Your photos</h3>
+      <div style={styles.list}>
+        {mine.length === 0 && <p style={styles.emptyText}>You haven't sent any photos yet.</p>}
+        {mine.map(photo => photoCard(photo, (
+          <button onClick={() => remove(photo)} style={styles.btnDanger}>Delete</button>
+        )))}
+      </div>
+    </div>
+  );
+};
+
+// ============================================================================
+// PAIRING MODAL
+// ============================================================================
+
+// Issues a pairing code on open; the TV enters it (or scans the QR code) to get its token
 const QRModal: React.FC<{
   display: Display;
+  onCreateCode: (id: number) => Promise<PairingCode>;
   onClose: () => void;
   apiBase: string;
   token: string;
-}> = ({ display, onClose, apiBase, token }) => {
+}> = ({ display, onCreateCode, onClose, apiBase, token }) => {
+  const [pairing, setPairing] = useState<PairingCode | null>(null);
+  const [qrSrc, setQrSrc] = useState('');
+  const [secondsLeft, setSecondsLeft] = useState(0);
+  const [error, setError] = useState('');
+
+  const newCode = useCallback(() => {
+    setError('');
+    onCreateCode(display.id)
+      .then(setPairing)
+      .catch((err: any) => setError(err.message));
+  }, [display.id, onCreateCode]);
+
+  useEffect(() => {
+    newCode();
+    // eslint-disable-next-line react-hooks/exhaustive-deps
+  }, [display.id]);
+
+  // QR image needs the auth header, so fetch it rather than using <img src>
+  useEffect(() => {
+    if (!pairing) return;
+    let objectUrl = '';
+    fetch(`${apiBase}/api/displays/${display.id}/qr?code=${pairing.code}`, {
+      headers: { 'Authorization': `Bearer ${token}` },
+    })
+      .then(res => (res.ok ? res.blob() : Promise.reject(new Error('QR code failed to load'))))
+      .then(blob => {
+        objectUrl = URL.createObjectURL(blob);
+        setQrSrc(objectUrl);
+      })
+      .catch(err => console.error(err));
+    return () => {
+      if (objectUrl) URL.revokeObjectURL(objectUrl);
+    };
+  }, [pairing, apiBase, display.id, token]);
+
+  useEffect(() => {
+    if (!pairing) return;
+    const tick = () => setSecondsLeft(Math.max(0, Math.round((new Date(pairing.expires_at).getTime() - Date.now()) / 1000)));
+    tick();
+    const interval = setInterval(tick, 1000);
+    return () => clearInterval(interval);
+  }, [pairing]);
+
+  const expired = pairing !== null && secondsLeft === 0;
+
   return (
     <div style={styles.modalOverlay} onClick={onClose}>
       <div style={styles.modal} onClick={(e) => e.stopPropagation()}>
         <div style={styles.modalHeader}>
-          <h2 style={styles.modalTitle}>QR Code: {display.name}</h2>
+          <h2 style={styles.modalTitle}>Pair: {display.name}</h2>
           <button onClick={onClose} style={styles.closeBtn}>×</button>
         </div>
         <div style={styles.modalBody}>
-          <img
-            src={`${apiBase}/api/displays/${display.id}/qr?t=${Date.now()}`}
-            alt="QR Code"
-            style={styles.qrImage}
-            onError={(e) => {
-              console.error('QR code failed to load');
-            }}
-          />
-          <p style={styles.modalText}>Scan this QR code on the TV to set up the display.</p>
-          <p style={styles.modalText}><strong>Token:</strong></p>
-          <code style={styles.codeBlock}>{display.token}</code>
+          {error && <p style={styles.modalText}>{error}</p>}
+          {pairing && !expired && (
+            <>
+              {qrSrc && <img src={qrSrc} alt="QR Code" style={styles.qrImage} />}
+              <p style={styles.modalText}>Enter this code on the TV, or scan the QR code:</p>
+              <div style={styles.pairingCode}>{pairing.code}</div>
+              <p style={styles.modalText}>
+                Expires in {Math.floor(secondsLeft / 60)}:{String(secondsLeft % 60).padStart(2, '0')}.
+                Pairing issues a new token, so any device already using this display is disconnected.
+              </p>
+            </>
+          )}
+          {expired && (
+            <>
+              <p style={styles.modalText}>This code has expired.</p>
+              <button onClick={newCode} style={styles.button}>New Code</button>
+            </>
+          )}
         </div>
       </div>
     </div>
@@ -1268,6 +2371,37 @@ const styles: { [key: string]: React.CSSProperties } = {
     display: 'flex',
     gap: '8px',
   },
+  pendingCount: {
+    marginLeft: '6px',
+    padding: '1px 7px',
+    background: '#ff9800',
+    color: 'white',
+    borderRadius: '10px',
+    fontSize: '12px',
+  },
+  pendingBadge: {
+    marginLeft: '10px',
+    padding: '2px 8px',
+    background: '#fff3e0',
+    color: '#e65100',
+    borderRadius: '10px',
+    fontSize: '12px',
+    fontWeight: 'normal',
+  },
+  rejectedBadge: {
+    marginLeft: '10px',
+    padding: '2px 8px',
+    background: '#fdecea',
+    color: '#dc3545',
+    borderRadius: '10px',
+    fontSize: '12px',
+    fontWeight: 'normal',
+  },
+  commandPanel: {
+    marginTop: '12px',
+    paddingTop: '12px',
+    borderTop: '1px solid #ddd',
+  },
   cardText: {
     margin: '8px 0',
     fontSize: '14px',
@@ -1306,6 +2440,16 @@ const styles: { [key: string]: React.CSSProperties } = {
     justifyContent: 'space-between',
     alignItems: 'center',
   },
+  notice: {
+    padding: '15px',
+    background: '#d4edda',
+    color: '#155724',
+    borderRadius: '4px',
+    marginBottom: '20px',
+    display: 'flex',
+    justifyContent: 'space-between',
+    alignItems: 'center',
+  },
   closeBtn: {
     background: 'none',
     border: 'none',
@@ -1435,6 +2579,15 @@ const styles: { [key: string]: React.CSSProperties } = {
   modalBody: {
     textAlign: 'center',
   },
+  pairingCode: {
+    fontSize: '48px',
+    fontFamily: 'monospace',
+    fontWeight: 'bold',
+    letterSpacing: '8px',
+    textAlign: 'center',
+    color: '#003366',
+    margin: '10px 0',
+  },
   qrImage: {
     maxWidth: '100%',
     height: 'auto',
@@ -3,8 +3,8 @@ module display-runtime
 go 1.25
 
 require (
-	github.com/gorilla/handlers v1.5.1
+	github.com/achgithub/activity-hub-common v0.0.0
 	github.com/gorilla/mux v1.8.0
 )
 
-require github.com/felixge/httpsnoop v1.0.1 // indirect
+replace github.com/achgithub/activity-hub-common => ../../../lib/activity-hub-common
@@ -5,7 +5,9 @@ import (
 	"net/http"
 	"os"
 
-	"github.com/gorilla/handlers"
+	"github.com/achgithub/activity-hub-common/database"
+	apphttp "github.com/achgithub/activity-hub-common/http"
+	"github.com/achgithub/activity-hub-common/maintenance"
 	"github.com/gorilla/mux"
 )
 
@@ -17,6 +19,13 @@ const (
 func main() {
 	log.Printf("📺 %s Backend Starting", APP_NAME)
 
+	// Identity database, for the CORS policy and maintenance notices
+	identityDB, err := database.InitIdentityDatabase()
+	if err != nil {
+		log.Fatal("Failed to connect to identity database:", err)
+	}
+	defer identityDB.Close()
+
 	// Setup router
 	r := mux.NewRouter()
 
@@ -28,17 +37,13 @@ func main() {
 	r.PathPrefix("/").Handler(spaHandler{staticPath: staticDir, indexPath: "index.html"})
 
 	// CORS configuration
-	corsHandler := handlers.CORS(
-		handlers.AllowedOrigins([]string{"*"}),
-		handlers.AllowedMethods([]string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}),
-		handlers.AllowedHeaders([]string{"Content-Type", "Authorization"}),
-		handlers.AllowCredentials(),
-	)
+	cors := apphttp.NewCORSPolicy(identityDB)
+	appMaintenance := maintenance.New(identityDB)
 
 	// Start server
 	port := getEnv("BACKEND_PORT", BACKEND_PORT)
 	log.Printf("🚀 %s backend listening on :%s", APP_NAME, port)
-	log.Fatal(http.ListenAndServe(":"+port, corsHandler(r)))
+	log.Fatal(http.ListenAndServe(":"+port, cors.Middleware(appMaintenance.Middleware("display-runtime")(r))))
 }
 
 // handleHealth - Health check endpoint
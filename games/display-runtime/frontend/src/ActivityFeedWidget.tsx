@@ -0,0 +1,82 @@
+import React, { useEffect, useState } from 'react';
+
+interface ActivityEvent {
+  id: string;
+  type: string;
+  text: string;
+  createdAt: string;
+}
+
+export const SHELL_API = 'http://192.168.1.45:3001/api';
+
+// Rows that fit on a TV without scrolling
+const MAX_EVENTS = 8;
+
+const ICONS: Record<string, string> = {
+  game_result: '🏆',
+  quiz_winner: '🧠',
+  lms_elimination: '⚽',
+  challenge: '⚔️',
+};
+
+function timeAgo(iso: string): string {
+  const minutes = Math.floor((Date.now() - new Date(iso).getTime()) / 60000);
+  if (minutes < 1) return 'just now';
+  if (minutes < 60) return `${minutes}m ago`;
+  if (minutes < 1440) return `${Math.floor(minutes / 60)}h ago`;
+  return new Date(iso).toLocaleDateString();
+}
+
+// Live platform activity from the identity shell: the latest events on load,
+// new ones pushed over SSE while the slide is showing
+const ActivityFeedWidget: React.FC = () => {
+  const [events, setEvents] = useState<ActivityEvent[]>([]);
+
+  useEffect(() => {
+    fetch(`${SHELL_API}/activity?limit=${MAX_EVENTS}`)
+      .then((res) => (res.ok ? res.json() : { events: [] }))
+      .then((data) => setEvents(data.events || []))
+      .catch((err) => console.error('Activity feed failed to load', err));
+
+    const eventSource = new EventSource(`${SHELL_API}/activity/stream`);
+    eventSource.onmessage = (event) => {
+      const data = JSON.parse(event.data);
+      if (!data.id) return; // connected
+      setEvents((prev) => [data, ...prev.filter((e) => e.id !== data.id)].slice(0, MAX_EVENTS));
+    };
+    return () => eventSource.close();
+  }, []);
+
+  return (
+    <div style={{
+      width: '100%',
+      height: '100%',
+      backgroundColor: '#1a1a1a',
+      color: '#ffffff',
+      padding: '60px 80px',
+      boxSizing: 'border-box'
+    }}>
+      <h1 style={{ fontSize: '56px', margin: '0 0 40px 0' }}>What's Happening</h1>
+      {events.length === 0 ? (
+        <p style={{ fontSize: '32px', color: '#999' }}>Nothing happening yet — fancy a game?</p>
+      ) : (
+        events.map((e) => (
+          <div key={e.id} style={{
+            display: 'flex',
+            alignItems: 'center',
+            gap: '24px',
+            fontSize: '32px',
+            padding: '16px 0',
+            borderBottom: '1px solid #333'
+          }}>
+            <span>{ICONS[e.type] || '•'}</span>
+            <span style={{ flex: 1 }}>{e.text}</span>
+            <span style={{ fontSize: '24px', color: '#999' }}>{timeAgo(e.createdAt)}</span>
+          </div>
+        ))
+      )}
+    </div>
+  );
+};
+
+export default ActivityFeedWidget;
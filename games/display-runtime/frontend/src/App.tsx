@@ -1,12 +1,33 @@
-import React, { useState, useEffect } from 'react';
+import React, { useState, useEffect, useCallback } from 'react';
 import SetupPage from './SetupPage';
-import SlideshowPage from './SlideshowPage';
+import SlideshowPage, { API_BASE } from './SlideshowPage';
 
 const App: React.FC = () => {
   const [token, setToken] = useState<string | null>(null);
   const [isLoading, setIsLoading] = useState(true);
 
   useEffect(() => {
+    // A pairing link (?pair=123456 from the admin QR code) takes precedence
+    const pairCode = new URLSearchParams(window.location.search).get('pair');
+    if (pairCode) {
+      window.history.replaceState(null, '', window.location.pathname);
+      fetch(`${API_BASE}/display/pair`, {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ code: pairCode }),
+      })
+        .then(res => res.json())
+        .then(data => {
+          if (data.success && data.data) {
+            localStorage.setItem('display_token', data.data.token);
+            setToken(data.data.token);
+          }
+        })
+        .catch(err => console.error('Pairing failed:', err))
+        .finally(() => setIsLoading(false));
+      return;
+    }
+
     // Check for token in localStorage on mount
     const savedToken = localStorage.getItem('display_token');
     if (savedToken) {
@@ -20,10 +41,10 @@ const App: React.FC = () => {
     setToken(newToken);
   };
 
-  const handleResetToken = () => {
+  const handleResetToken = useCallback(() => {
     localStorage.removeItem('display_token');
     setToken(null);
-  };
+  }, []);
 
   if (isLoading) {
     return (
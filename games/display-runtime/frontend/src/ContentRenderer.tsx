@@ -1,4 +1,5 @@
 import React from 'react';
+import ActivityFeedWidget from './ActivityFeedWidget';
 
 interface ContentItem {
   id: number;
@@ -14,9 +15,10 @@ interface ContentItem {
 
 interface ContentRendererProps {
   item: ContentItem;
+  cacheBust?: number; // Set after a remote clear_cache so images bypass the browser cache
 }
 
-const ContentRenderer: React.FC<ContentRendererProps> = ({ item }) => {
+const ContentRenderer: React.FC<ContentRendererProps> = ({ item, cacheBust }) => {
   const renderContent = () => {
     switch (item.content_type) {
       case 'image':
@@ -30,7 +32,7 @@ const ContentRenderer: React.FC<ContentRendererProps> = ({ item }) => {
             backgroundColor: '#000'
           }}>
             <img
-              src={`http://192.168.1.45:5050${item.file_path}`}
+              src={`http://192.168.1.45:5050${item.file_path}${cacheBust ? `?v=${cacheBust}` : ''}`}
               alt={item.title}
               style={{
                 maxWidth: '100%',
@@ -41,6 +43,44 @@ const ContentRenderer: React.FC<ContentRendererProps> = ({ item }) => {
           </div>
         );
 
+      // One photo from a photo wall: title is the sender's public name,
+      // text_content the caption
+      case 'photo':
+        return (
+          <div style={{
+            position: 'relative',
+            width: '100%',
+            height: '100%',
+            display: 'flex',
+            justifyContent: 'center',
+            alignItems: 'center',
+            backgroundColor: '#000'
+          }}>
+            <img
+              src={`http://192.168.1.45:5050${item.file_path}${cacheBust ? `?v=${cacheBust}` : ''}`}
+              alt={item.text_content || item.title}
+              style={{
+                maxWidth: '100%',
+                maxHeight: '100%',
+                objectFit: 'contain'
+              }}
+            />
+            <div style={{
+              position: 'absolute',
+              left: 0,
+              right: 0,
+              bottom: 0,
+              padding: '16px 32px',
+              backgroundColor: item.bg_color || 'rgba(0, 0, 0, 0.6)',
+              color: item.text_color || '#ffffff',
+              fontSize: '32px'
+            }}>
+              {item.text_content && <span>{item.text_content} </span>}
+              <span style={{ opacity: 0.7 }}>📸 {item.title}</span>
+            </div>
+          </div>
+        );
+
       case 'url':
         return (
           <iframe
@@ -101,6 +141,21 @@ const ContentRenderer: React.FC<ContentRendererProps> = ({ item }) => {
           />
         );
 
+      case 'medal_table':
+        return (
+          <iframe
+            src="http://192.168.1.45:5090/?view=tv"
+            title="Pub Olympics Medal Table"
+            style={{
+              width: '100%',
+              height: '100%',
+              border: 'none'
+            }}
+            sandbox="allow-scripts allow-same-origin"
+            onError={(e) => console.error('Medal table iframe failed to load', e)}
+          />
+        );
+
       case 'announcement':
         return (
           <div style={{
@@ -138,6 +193,9 @@ const ContentRenderer: React.FC<ContentRendererProps> = ({ item }) => {
           </div>
         );
 
+      case 'activity_feed':
+        return <ActivityFeedWidget />;
+
       default:
         return (
           <div style={{
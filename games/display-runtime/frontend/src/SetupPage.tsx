@@ -1,19 +1,20 @@
 import React, { useState } from 'react';
+import { API_BASE } from './SlideshowPage';
 
 interface SetupPageProps {
   onTokenSubmit: (token: string) => void;
 }
 
 const SetupPage: React.FC<SetupPageProps> = ({ onTokenSubmit }) => {
-  const [token, setToken] = useState('');
+  const [code, setCode] = useState('');
   const [error, setError] = useState('');
   const [isVerifying, setIsVerifying] = useState(false);
 
   const handleSubmit = async (e: React.FormEvent) => {
     e.preventDefault();
 
-    if (!token.trim()) {
-      setError('Please enter a token');
+    if (!/^\d{6}$/.test(code.trim())) {
+      setError('Please enter the 6-digit pairing code');
       return;
     }
 
@@ -21,24 +22,22 @@ const SetupPage: React.FC<SetupPageProps> = ({ onTokenSubmit }) => {
     setError('');
 
     try {
-      // Verify token with Display Admin API
-      const response = await fetch(`http://192.168.1.45:5050/api/display/by-token/${token.trim()}`);
-
-      if (!response.ok) {
-        throw new Error('Invalid token');
-      }
-
+      // Exchange the pairing code for this display's token
+      const response = await fetch(`${API_BASE}/display/pair`, {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ code: code.trim() }),
+      });
       const data = await response.json();
 
-      if (data.success && data.data) {
-        // Token is valid
-        onTokenSubmit(token.trim());
+      if (response.ok && data.success && data.data) {
+        onTokenSubmit(data.data.token);
       } else {
-        setError('Invalid token');
+        setError(data.error || 'Invalid or expired pairing code');
         setIsVerifying(false);
       }
     } catch (err) {
-      setError('Invalid token or connection error');
+      setError('Connection error, please try again');
       setIsVerifying(false);
     }
   };
@@ -72,7 +71,7 @@ const SetupPage: React.FC<SetupPageProps> = ({ onTokenSubmit }) => {
           marginBottom: '40px',
           color: '#cccccc'
         }}>
-          Enter your display token to begin
+          Enter the pairing code shown in Display Admin
         </p>
 
         <form onSubmit={handleSubmit} style={{
@@ -82,9 +81,11 @@ const SetupPage: React.FC<SetupPageProps> = ({ onTokenSubmit }) => {
         }}>
           <input
             type="text"
-            value={token}
-            onChange={(e) => setToken(e.target.value)}
-            placeholder="Enter display token"
+            inputMode="numeric"
+            maxLength={6}
+            value={code}
+            onChange={(e) => setCode(e.target.value.replace(/\D/g, ''))}
+            placeholder="000000"
             disabled={isVerifying}
             style={{
               fontSize: '24px',
@@ -125,7 +126,7 @@ const SetupPage: React.FC<SetupPageProps> = ({ onTokenSubmit }) => {
               transition: 'background-color 0.3s'
             }}
           >
-            {isVerifying ? 'Verifying...' : 'Start Display'}
+            {isVerifying ? 'Pairing...' : 'Start Display'}
           </button>
         </form>
 
@@ -134,7 +135,7 @@ const SetupPage: React.FC<SetupPageProps> = ({ onTokenSubmit }) => {
           fontSize: '16px',
           color: '#888888'
         }}>
-          Get your token from the Display Admin interface
+          Codes expire after 10 minutes — generate one with "Pair" on the display in Display Admin
         </p>
       </div>
     </div>
@@ -1,5 +1,7 @@
 import React, { useState, useEffect, useCallback } from 'react';
 import ContentRenderer from './ContentRenderer';
+import TickerOverlay, { TickerFeed } from './TickerOverlay';
+import ZoneView, { Zone, zoneBox } from './ZoneView';
 
 interface SlideshowPageProps {
   token: string;
@@ -35,8 +37,16 @@ interface PlaylistData {
   items: ContentItem[];
 }
 
-const API_BASE = 'http://192.168.1.45:5050/api';
-const REFRESH_INTERVAL = 60000; // Check for playlist changes every minute
+export const API_BASE = 'http://192.168.1.45:5050/api';
+const REFRESH_INTERVAL = 60000; // Check for layout and playlist changes every minute
+
+// Used until the layout loads: one zone filling the screen
+const FULLSCREEN: Zone = { name: 'main', x: 0, y: 0, width: 100, height: 100, source: 'none' };
+
+interface DisplayCommand {
+  id: number;
+  command: 'refresh' | 'clear_cache' | 'reboot';
+}
 
 const SlideshowPage: React.FC<SlideshowPageProps> = ({ token, onResetToken }) => {
   const [display, setDisplay] = useState<Display | null>(null);
@@ -45,11 +55,20 @@ const SlideshowPage: React.FC<SlideshowPageProps> = ({ token, onResetToken }) =>
   const [error, setError] = useState('');
   const [isFullscreen, setIsFullscreen] = useState(false);
   const [showControls, setShowControls] = useState(false);
+  const [cacheBust, setCacheBust] = useState(0);
+  const [ticker, setTicker] = useState<TickerFeed | null>(null);
+  const [mainZone, setMainZone] = useState<Zone>(FULLSCREEN);
+  const [zones, setZones] = useState<Zone[]>([]); // Zones besides main
 
   // Fetch display info
   const fetchDisplay = useCallback(async () => {
     try {
       const response = await fetch(`${API_BASE}/display/by-token/${token}`);
+      if (response.status === 404) {
+        // Token rotated or revoked: back to setup to pair again
+        onResetToken();
+        return null;
+      }
       if (!response.ok) {
         throw new Error('Failed to fetch display');
       }
@@ -65,52 +84,130 @@ const SlideshowPage: React.FC<SlideshowPageProps> = ({ token, onResetToken }) =>
       setError('Failed to load display information');
       return null;
     }
-  }, [token]);
+  }, [token, onResetToken]);
 
-  // Fetch active playlist for display
-  const fetchPlaylist = useCallback(async (displayId: number) => {
+  // Fetch the display's layout: every zone with what it shows now, in one
+  // document. The main zone drives the slideshow and its controls; a main zone
+  // showing a single widget plays it as a one-item playlist.
+  const fetchLayout = useCallback(async () => {
     try {
-      const response = await fetch(`${API_BASE}/preview/display/${displayId}`);
+      const response = await fetch(`${API_BASE}/display/by-token/${token}/layout`);
+      if (response.status === 404) {
+        onResetToken();
+        return;
+      }
       if (!response.ok) {
-        throw new Error('Failed to fetch playlist');
+        throw new Error('Failed to fetch layout');
       }
       const data = await response.json();
-      if (data.success && data.data) {
-        setPlaylist(data.data);
+      if (!data.success || !data.data) {
+        throw new Error('Invalid layout data');
+      }
+      const all: Zone[] = data.data.zones || [];
+      const main = all.find(z => z.name === 'main') || FULLSCREEN;
+      const others = all.filter(z => z.name !== 'main');
+      setMainZone(main);
+      setZones(others);
+      setTicker(data.data.ticker || null);
+
+      const mainPlaylist: PlaylistData | null = main.playlist
+        || (main.item ? { playlist: { id: 0, name: main.item.title, description: '' }, items: [main.item] } : null);
+      if (mainPlaylist && mainPlaylist.items && mainPlaylist.items.length > 0) {
+        setPlaylist(mainPlaylist);
         setCurrentIndex(0); // Reset to first item when playlist changes
         setError('');
       } else {
-        setError('No active playlist assigned');
         setPlaylist(null);
+        setError(others.some(z => z.source !== 'none') ? '' : 'No active playlist assigned');
       }
     } catch (err) {
-      console.error('Error fetching playlist:', err);
+      console.error('Error fetching layout:', err);
       setError('No content to display');
       setPlaylist(null);
     }
-  }, []);
+  }, [token, onResetToken]);
 
   // Initial load
   useEffect(() => {
     const init = async () => {
       const displayId = await fetchDisplay();
       if (displayId) {
-        await fetchPlaylist(displayId);
+        await fetchLayout();
       }
     };
     init();
-  }, [fetchDisplay, fetchPlaylist]);
+  }, [fetchDisplay, fetchLayout]);
 
-  // Periodic refresh of playlist
+  // Periodic refresh of the layout and what its zones show
   useEffect(() => {
     if (!display) return;
 
     const interval = setInterval(() => {
-      fetchPlaylist(display.id);
+      fetchLayout();
     }, REFRESH_INTERVAL);
 
     return () => clearInterval(interval);
-  }, [display, fetchPlaylist]);
+  }, [display, fetchLayout]);
+
+  // Push channel: remote commands from Display Admin, acknowledged once handled
+  useEffect(() => {
+    if (!display) return;
+
+    const ack = (id: number, status: 'done' | 'failed', message = '') =>
+      fetch(`${API_BASE}/display/by-token/${token}/commands/${id}/ack`, {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ status, message }),
+      });
+
+    const handleCommand = async (cmd: DisplayCommand) => {
+      console.log(`Received command ${cmd.id}: ${cmd.command}`);
+      try {
+        switch (cmd.command) {
+          case 'refresh':
+            await fetchLayout();
+            await ack(cmd.id, 'done');
+            break;
+          case 'clear_cache':
+            if ('caches' in window) {
+              const keys = await caches.keys();
+              await Promise.all(keys.map(k => caches.delete(k)));
+            }
+            setCacheBust(Date.now());
+            await fetchLayout();
+            await ack(cmd.id, 'done');
+            break;
+          case 'reboot':
+            // Acknowledge first: the reload ends this page
+            await ack(cmd.id, 'done');
+            window.location.reload();
+            break;
+          default:
+            await ack(cmd.id, 'failed', 'unknown command');
+        }
+      } catch (err) {
+        console.error('Command failed:', err);
+        ack(cmd.id, 'failed', err instanceof Error ? err.message : 'command failed').catch(() => {});
+      }
+    };
+
+    // EventSource reconnects on its own; pending commands are re-sent on reconnect
+    const es = new EventSource(`${API_BASE}/display/by-token/${token}/stream`);
+    es.addEventListener('command', (e) => {
+      try {
+        handleCommand(JSON.parse((e as MessageEvent).data));
+      } catch (err) {
+        console.error('Bad command event:', err);
+      }
+    });
+    // Token rotated or revoked in Display Admin
+    es.addEventListener('revoked', () => {
+      es.close();
+      onResetToken();
+    });
+
+    return () => es.close();
+  }, [display, token, fetchLayout, onResetToken]);
 
   // Auto-advance slideshow
   useEffect(() => {
@@ -157,7 +254,10 @@ const SlideshowPage: React.FC<SlideshowPageProps> = ({ token, onResetToken }) =>
     };
   }, []);
 
-  if (error && !playlist) {
+  // Other zones can have something to show while the main zone has nothing
+  const hasZones = zones.some(z => z.source !== 'none');
+
+  if (error && !playlist && !hasZones) {
     return (
       <div style={{
         display: 'flex',
@@ -195,7 +295,7 @@ const SlideshowPage: React.FC<SlideshowPageProps> = ({ token, onResetToken }) =>
     );
   }
 
-  if (!playlist || !playlist.items || playlist.items.length === 0) {
+  if ((!playlist || !playlist.items || playlist.items.length === 0) && !hasZones) {
     return (
       <div style={{
         display: 'flex',
@@ -212,25 +312,32 @@ const SlideshowPage: React.FC<SlideshowPageProps> = ({ token, onResetToken }) =>
     );
   }
 
-  const currentItem = playlist.items[currentIndex];
+  const currentItem = playlist?.items[currentIndex];
 
   return (
-    <div style={{ width: '100vw', height: '100vh', position: 'relative', overflow: 'hidden' }}>
-      {currentItem ? (
-        <ContentRenderer item={currentItem} />
-      ) : (
-        <div style={{
-          display: 'flex',
-          justifyContent: 'center',
-          alignItems: 'center',
-          width: '100%',
-          height: '100%',
-          backgroundColor: '#1a1a1a',
-          color: '#fff'
-        }}>
-          Loading content...
-        </div>
-      )}
+    <div style={{ width: '100vw', height: '100vh', position: 'relative', overflow: 'hidden', backgroundColor: '#1a1a1a' }}>
+      <div style={zoneBox(mainZone)}>
+        {currentItem ? (
+          <ContentRenderer key={`${currentItem.id}-${cacheBust}`} item={currentItem} cacheBust={cacheBust} />
+        ) : playlist && (
+          <div style={{
+            display: 'flex',
+            justifyContent: 'center',
+            alignItems: 'center',
+            width: '100%',
+            height: '100%',
+            backgroundColor: '#1a1a1a',
+            color: '#fff'
+          }}>
+            Loading content...
+          </div>
+        )}
+      </div>
+
+      {/* The layout's other zones (sidebar, ticker), each on its own */}
+      {zones.map(zone => (
+        <ZoneView key={zone.name} zone={zone} cacheBust={cacheBust} />
+      ))}
 
       {/* Control bar (shows on mouse move) */}
       {showControls && playlist && (
@@ -313,23 +420,28 @@ const SlideshowPage: React.FC<SlideshowPageProps> = ({ token, onResetToken }) =>
         </div>
       )}
 
+      {/* Ticker band, independent of the slides */}
+      {ticker && <TickerOverlay ticker={ticker} />}
+
       {/* Progress indicator */}
-      <div style={{
-        position: 'fixed',
-        bottom: 0,
-        left: 0,
-        right: 0,
-        height: '4px',
-        backgroundColor: 'rgba(255, 255, 255, 0.2)',
-        zIndex: 999
-      }}>
+      {playlist && (
         <div style={{
-          height: '100%',
-          backgroundColor: '#4CAF50',
-          width: `${((currentIndex + 1) / playlist.items.length) * 100}%`,
-          transition: 'width 0.3s'
-        }} />
-      </div>
+          position: 'fixed',
+          bottom: 0,
+          left: 0,
+          right: 0,
+          height: '4px',
+          backgroundColor: 'rgba(255, 255, 255, 0.2)',
+          zIndex: 999
+        }}>
+          <div style={{
+            height: '100%',
+            backgroundColor: '#4CAF50',
+            width: `${((currentIndex + 1) / playlist.items.length) * 100}%`,
+            transition: 'width 0.3s'
+          }} />
+        </div>
+      )}
     </div>
   );
 };
@@ -0,0 +1,88 @@
+import React, { useEffect, useState } from 'react';
+import { SHELL_API } from './ActivityFeedWidget';
+
+// The display's ticker as served by Display Admin: the messages to scroll
+// right now, and whether the venue's activity feed scrolls too
+export interface TickerFeed {
+  ticker_id: number;
+  title: string;
+  bg_color?: string;
+  text_color?: string;
+  activity_feed: boolean;
+  venue_id: number;
+  messages: { id: number; text: string }[];
+}
+
+interface ActivityEvent {
+  id: string;
+  text: string;
+}
+
+// Latest activity events mixed in after the ticker's own messages
+const MAX_EVENTS = 5;
+
+// Scroll speed, so long and short tickers move at the same pace
+const PIXELS_PER_SECOND = 120;
+
+// A band along the bottom of the screen, over whatever the playlist shows, or
+// filling a layout's ticker zone when inZone is set. It runs on its own, so
+// slides changing underneath don't restart it.
+const TickerOverlay: React.FC<{ ticker: TickerFeed; inZone?: boolean }> = ({ ticker, inZone }) => {
+  const [events, setEvents] = useState<ActivityEvent[]>([]);
+
+  useEffect(() => {
+    if (!ticker.activity_feed) {
+      setEvents([]);
+      return;
+    }
+    // Only the display's own venue; chain-wide displays get everything
+    const venue = ticker.venue_id ? `venue=${ticker.venue_id}` : '';
+    fetch(`${SHELL_API}/activity?limit=${MAX_EVENTS}&${venue}`)
+      .then((res) => (res.ok ? res.json() : { events: [] }))
+      .then((data) => setEvents(data.events || []))
+      .catch((err) => console.error('Ticker activity feed failed to load', err));
+
+    const eventSource = new EventSource(`${SHELL_API}/activity/stream?${venue}`);
+    eventSource.onmessage = (event) => {
+      const data = JSON.parse(event.data);
+      if (!data.id) return; // connected
+      setEvents((prev) => [data, ...prev.filter((e) => e.id !== data.id)].slice(0, MAX_EVENTS));
+    };
+    return () => eventSource.close();
+  }, [ticker.activity_feed, ticker.venue_id]);
+
+  const items = [...ticker.messages.map((m) => m.text), ...events.map((e) => e.text)];
+  if (items.length === 0) return null;
+
+  const text = items.join('   •   ');
+  // Roughly 20px per character at this font size, plus the screen width to cross
+  const seconds = Math.max(15, (text.length * 20 + window.innerWidth) / PIXELS_PER_SECOND);
+  const placement: React.CSSProperties = inZone
+    ? { width: '100%', height: '100%', display: 'flex', alignItems: 'center' }
+    : { position: 'fixed', bottom: '4px', left: 0, right: 0, height: '64px', zIndex: 998 };
+
+  return (
+    <div style={{
+      ...placement,
+      overflow: 'hidden',
+      backgroundColor: ticker.bg_color || 'rgba(0, 0, 0, 0.8)',
+      color: ticker.text_color || '#ffffff',
+      fontSize: '36px',
+      lineHeight: '64px',
+      whiteSpace: 'nowrap'
+    }}>
+      <style>{'@keyframes ticker-scroll { from { transform: translateX(100vw); } to { transform: translateX(-100%); } }'}</style>
+      <div
+        key={text}
+        style={{
+          display: 'inline-block',
+          animation: `ticker-scroll ${seconds}s linear infinite`
+        }}
+      >
+        {text}
+      </div>
+    </div>
+  );
+};
+
+export default TickerOverlay;
@@ -0,0 +1,90 @@
+import React, { useEffect, useState } from 'react';
+import ContentRenderer from './ContentRenderer';
+import TickerOverlay, { TickerFeed } from './TickerOverlay';
+
+interface ContentItem {
+  id: number;
+  title: string;
+  content_type: string;
+  duration_seconds: number;
+  file_path?: string;
+  url?: string;
+  text_content?: string;
+  bg_color?: string;
+  text_color?: string;
+}
+
+interface PlaylistData {
+  playlist: {
+    id: number;
+    name: string;
+    description: string;
+  };
+  items: ContentItem[];
+}
+
+// One zone of the display's layout, placed in percent of the screen, with
+// what it shows right now (see Display Admin's layouts.go)
+export interface Zone {
+  name: string;
+  x: number;
+  y: number;
+  width: number;
+  height: number;
+  source: 'schedule' | 'playlist' | 'widget' | 'ticker' | 'none';
+  playlist?: PlaylistData;
+  item?: ContentItem;
+  ticker?: TickerFeed;
+}
+
+export const zoneBox = (zone: Zone): React.CSSProperties => ({
+  position: 'absolute',
+  left: `${zone.x}%`,
+  top: `${zone.y}%`,
+  width: `${zone.width}%`,
+  height: `${zone.height}%`,
+  overflow: 'hidden'
+});
+
+// Cycles a zone's playlist on its own timer, independently of the main zone
+const ZoneSlideshow: React.FC<{ playlist: PlaylistData; cacheBust: number }> = ({ playlist, cacheBust }) => {
+  const [index, setIndex] = useState(0);
+
+  useEffect(() => {
+    setIndex(0);
+  }, [playlist]);
+
+  useEffect(() => {
+    if (playlist.items.length < 2) return;
+    const item = playlist.items[index % playlist.items.length];
+    const timer = setTimeout(
+      () => setIndex((prev) => (prev + 1) % playlist.items.length),
+      (item.duration_seconds || 10) * 1000
+    );
+    return () => clearTimeout(timer);
+  }, [playlist, index]);
+
+  const item = playlist.items[index % playlist.items.length];
+  if (!item) return null;
+  return <ContentRenderer key={`${item.id}-${cacheBust}`} item={item} cacheBust={cacheBust} />;
+};
+
+// A zone other than main: a playlist, a single live widget or a ticker
+const ZoneView: React.FC<{ zone: Zone; cacheBust: number }> = ({ zone, cacheBust }) => {
+  let content: React.ReactNode = null;
+  if (zone.playlist && zone.playlist.items.length > 0) {
+    content = <ZoneSlideshow playlist={zone.playlist} cacheBust={cacheBust} />;
+  } else if (zone.item) {
+    content = <ContentRenderer key={`${zone.item.id}-${cacheBust}`} item={zone.item} cacheBust={cacheBust} />;
+  } else if (zone.ticker) {
+    content = <TickerOverlay ticker={zone.ticker} inZone />;
+  }
+
+  return (
+    <div style={{ ...zoneBox(zone), backgroundColor: '#1a1a1a' }}>
+      {content}
+    </div>
+  );
+};
+
+export default ZoneView;
@@ -138,3 +138,45 @@ func GetPlayerStats(playerID string) (map[string]interface{}, error) {
 	}, nil
 
 }
+
+// RebuildPlayerStats recounts every player's statistics from the finished
+// games, replacing the running totals UpdatePlayerStats keeps. It returns
+// how many players have stats.
+func RebuildPlayerStats() (int64, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM player_stats`); err != nil {
+		return 0, err
+	}
+
+	// Each finished game counts once for each player; no winner is a draw
+	result, err := tx.Exec(`
+		INSERT INTO player_stats (player_id, player_name, wins, losses, draws, total_boxes, games_played, updated_at)
+		SELECT player_id,
+			(ARRAY_AGG(player_name ORDER BY completed_at DESC))[1],
+			COUNT(*) FILTER (WHERE winner_id = player_id),
+			COUNT(*) FILTER (WHERE winner_id <> player_id),
+			COUNT(*) FILTER (WHERE winner_id IS NULL),
+			SUM(boxes),
+			COUNT(*),
+			NOW()
+		FROM (
+			SELECT player1_id AS player_id, player1_name AS player_name, player1_score AS boxes, winner_id, completed_at
+			FROM games WHERE completed_at IS NOT NULL
+			UNION ALL
+			SELECT player2_id, player2_name, player2_score, winner_id, completed_at
+			FROM games WHERE completed_at IS NOT NULL
+		) played
+		GROUP BY player_id
+	`)
+	if err != nil {
+		return 0, err
+	}
+	players, _ := result.RowsAffected()
+
+	return players, tx.Commit()
+}
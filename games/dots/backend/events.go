@@ -0,0 +1,40 @@
+package main
+
+import (
+	"github.com/achgithub/activity-hub-common/events"
+)
+
+// Stream events. Payload field names are what the frontend reads.
+
+type StreamOpened struct {
+	GameID string `json:"gameId"`
+	UserID string `json:"userId"`
+}
+
+// GameUpdate carries the game after a line is drawn
+type GameUpdate struct {
+	Game           *Game  `json:"game"`
+	Message        string `json:"message"`
+	BoxesCompleted int    `json:"boxesCompleted"`
+}
+
+// GameEnd carries the finished game and why it ended
+type GameEnd struct {
+	Game    *Game  `json:"game"`
+	Message string `json:"message"`
+	Reason  string `json:"reason"` // game_complete, forfeit or disconnect
+}
+
+type OpponentPresence struct {
+	UserID string `json:"userId"`
+	Name   string `json:"name,omitempty"`
+}
+
+var (
+	evConnected            = events.Register[StreamOpened]("connected", 1, "Stream opened")
+	evGameState            = events.Register[*Game]("game_state", 1, "Current game state, sent after connected")
+	evMoveUpdate           = events.Register[GameUpdate]("move_update", 1, "A line was drawn")
+	evGameEnded            = events.Register[GameEnd]("game_ended", 1, "The game finished")
+	evOpponentConnected    = events.Register[OpponentPresence]("opponent_connected", 1, "The opponent's stream is open")
+	evOpponentDisconnected = events.Register[OpponentPresence]("opponent_disconnected", 1, "The opponent's stream closed")
+)
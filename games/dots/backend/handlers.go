@@ -1,15 +1,19 @@
 package main
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
-	"os"
 	"time"
 
+	"github.com/achgithub/activity-hub-common/analytics"
 	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/achgithub/activity-hub-common/events"
+	"github.com/achgithub/activity-hub-common/history"
+	apphttp "github.com/achgithub/activity-hub-common/http"
+	"github.com/achgithub/activity-hub-common/services"
 	"github.com/gorilla/mux"
 )
 
@@ -27,13 +31,10 @@ func getTokenFromRequest(r *http.Request) string {
 }
 
 // reportToLeaderboard sends game result to the leaderboard service
-// token parameter is the JWT token from the authenticated user making the request
-func reportToLeaderboard(game *Game, token string) {
-	leaderboardURL := os.Getenv("LEADERBOARD_URL")
-	if leaderboardURL == "" {
-		leaderboardURL = "http://127.0.0.1:5030"
-	}
-
+// token parameter is the JWT token from the authenticated user making the request.
+// selfReported marks results only the winner vouches for (claim-win after a
+// disconnect) so the leaderboard asks the opponent to confirm them.
+func reportToLeaderboard(game *Game, token string, selfReported bool) {
 	var winnerID, winnerName, loserID, loserName string
 	isDraw := game.WinnerID == nil
 
@@ -63,46 +64,107 @@ func reportToLeaderboard(game *Game, token string) {
 
 	score := fmt.Sprintf("%d-%d", game.Player1Score, game.Player2Score)
 
-	result := map[string]interface{}{
-		"gameType":   "dots",
-		"gameId":     game.ID,
-		"winnerId":   winnerID,
-		"winnerName": winnerName,
-		"loserId":    loserID,
-		"loserName":  loserName,
-		"isDraw":     isDraw,
-		"score":      score,
-		"duration":   duration,
+	result := services.Result{
+		GameType:     "dots",
+		GameID:       game.ID,
+		WinnerID:     winnerID,
+		WinnerName:   winnerName,
+		LoserID:      loserID,
+		LoserName:    loserName,
+		IsDraw:       isDraw,
+		Score:        score,
+		Duration:     duration,
+		SelfReported: selfReported,
+	}
+
+	// Self-reported results stay on HTTP: the leaderboard checks the
+	// reporter's token before asking the opponent to confirm
+	if !selfReported && publishResult(result) {
+		log.Printf("📊 Published game %s to the results stream", game.ID)
+		return
 	}
 
-	jsonBody, err := json.Marshal(result)
-	if err != nil {
-		log.Printf("Failed to marshal leaderboard result: %v", err)
+	if err := backends.ReportResult(context.Background(), token, result); err != nil {
+		log.Printf("Failed to report to leaderboard: %v", err)
 		return
 	}
+	log.Printf("📊 Reported game %s to leaderboard", game.ID)
+}
 
-	// Create request with Authorization header
-	req, err := http.NewRequest("POST", leaderboardURL+"/api/result", bytes.NewBuffer(jsonBody))
+// setLobbyPresence shows both players as in this game in the lobby, which
+// also stops them being challenged until it ends. Failures are logged only.
+func setLobbyPresence(game *Game) {
+	err := backends.SetInGame(context.Background(), "dots", game.ID, []string{game.Player1ID, game.Player2ID})
 	if err != nil {
-		log.Printf("Failed to create leaderboard request: %v", err)
-		return
+		log.Printf("Failed to set lobby presence for game %s: %v", game.ID, err)
 	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+}
+
+// clearLobbyPresence frees the players of a finished game in the lobby
+func clearLobbyPresence(gameID string) {
+	if err := backends.ClearInGame(context.Background(), "dots", gameID); err != nil {
+		log.Printf("Failed to clear lobby presence for game %s: %v", gameID, err)
+	}
+}
 
-	// Send request
-	client := &http.Client{}
-	resp, err := client.Do(req)
+// reportToHistory records the completed game in the cross-app game history
+// (shown on players' profiles in the shell)
+func reportToHistory(game *Game, token string) {
+	outcome := func(playerID string) string {
+		if game.WinnerID == nil {
+			return history.OutcomeDraw
+		}
+		if *game.WinnerID == playerID {
+			return history.OutcomeWin
+		}
+		return history.OutcomeLoss
+	}
+
+	duration := 0
+	completedAt := time.Now()
+	if game.CompletedAt != nil {
+		duration = int(*game.CompletedAt - game.CreatedAt)
+		completedAt = time.Unix(*game.CompletedAt, 0)
+		recordGameLength(duration, token)
+	}
+
+	err := history.Report(token, history.Record{
+		App:    "dots",
+		GameID: game.ID,
+		Players: []history.Player{
+			{ID: game.Player1ID, Name: game.Player1Name, Outcome: outcome(game.Player1ID)},
+			{ID: game.Player2ID, Name: game.Player2Name, Outcome: outcome(game.Player2ID)},
+		},
+		Result:   fmt.Sprintf("%d-%d", game.Player1Score, game.Player2Score),
+		Duration: duration,
+		Options: map[string]interface{}{
+			"gridWidth":  game.GridWidth,
+			"gridHeight": game.GridHeight,
+		},
+		CompletedAt: completedAt,
+	})
 	if err != nil {
-		log.Printf("Failed to report to leaderboard: %v", err)
+		log.Printf("Failed to report game history: %v", err)
 		return
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode == http.StatusOK {
-		log.Printf("📊 Reported game %s to leaderboard", game.ID)
-	} else {
-		log.Printf("Leaderboard returned status %d", resp.StatusCode)
+	log.Printf("📜 Game %s recorded in history", game.ID)
+}
+
+// recordGameLength logs how long a game lasted for the landlord's usage
+// dashboard, at the venue of the player who finished it
+func recordGameLength(seconds int, token string) {
+	venueID := 0
+	if user, err := authlib.ResolveToken(identityDB, token); err == nil {
+		venueID = user.VenueID
+	}
+	if err := analytics.Record(identityDB, analytics.Event{
+		Event:   analytics.EventGameLength,
+		App:     "dots",
+		Value:   float64(seconds),
+		VenueID: venueID,
+	}); err != nil {
+		log.Printf("Failed to record game length: %v", err)
 	}
 }
 
@@ -215,6 +277,8 @@ func handleCreateGame(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	go setLobbyPresence(game)
+
 	log.Printf("✅ Created dots game: %s (Challenge: %s, P1: %s, P2: %s, Grid: %dx%d)",
 		gameID, req.ChallengeID, req.Player1Name, req.Player2Name, gridWidth, gridHeight)
 
@@ -285,21 +349,15 @@ func handleMakeMove(w http.ResponseWriter, r *http.Request) {
 
 		// Report to leaderboard (use token from current request)
 		token := getTokenFromRequest(r)
-		go reportToLeaderboard(game, token)
+		go reportToLeaderboard(game, token, false)
+		go reportToHistory(game, token)
+		go clearLobbyPresence(game.ID)
 
 		// Publish game_ended event
-		PublishGameEvent(req.GameID, "game_ended", map[string]interface{}{
-			"game":    game,
-			"message": message,
-			"reason":  "game_complete",
-		})
+		PublishGameEvent(evGameEnded.New(req.GameID, GameEnd{Game: game, Message: message, Reason: "game_complete"}))
 	} else {
 		// Publish move_update event
-		PublishGameEvent(req.GameID, "move_update", map[string]interface{}{
-			"game":           game,
-			"message":        message,
-			"boxesCompleted": boxesCompleted,
-		})
+		PublishGameEvent(evMoveUpdate.New(req.GameID, GameUpdate{Game: game, Message: message, BoxesCompleted: boxesCompleted}))
 	}
 
 	respondJSON(w, map[string]interface{}{
@@ -314,12 +372,13 @@ func handleMakeMove(w http.ResponseWriter, r *http.Request) {
 // handleGetConfig returns game configuration and options schema
 // This allows the identity shell to dynamically render challenge options
 func handleGetConfig(w http.ResponseWriter, r *http.Request) {
-	config := map[string]interface{}{
-		"appId":       "dots",
-		"name":        "Dots & Boxes",
-		"icon":        "🔵",
-		"description": "Connect the dots, complete the boxes!",
-		"gameOptions": []map[string]interface{}{
+	apphttp.WriteConfig(w, apphttp.AppConfig{
+		AppID:        "dots",
+		Name:         "Dots & Boxes",
+		Icon:         "🔵",
+		Description:  "Connect the dots, complete the boxes!",
+		Capabilities: apphttp.Capabilities{Challenges: true, SSE: true},
+		GameOptions: []map[string]interface{}{
 			{
 				"id":      "gridSize",
 				"type":    "select",
@@ -333,8 +392,7 @@ func handleGetConfig(w http.ResponseWriter, r *http.Request) {
 				},
 			},
 		},
-	}
-	respondJSON(w, config)
+	})
 }
 
 // handleGetStats returns player statistics
@@ -366,7 +424,6 @@ func handleGameStream(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
 	w.Header().Set("Connection", "keep-alive")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
 
 	flusher, ok := w.(http.Flusher)
 	if !ok {
@@ -377,13 +434,13 @@ func handleGameStream(w http.ResponseWriter, r *http.Request) {
 	// Get game
 	game, err := GetGame(gameID)
 	if err != nil {
-		sendSSE(w, flusher, "error", map[string]string{"message": "Game not found"})
+		events.Send(w, events.Error.New(gameID, events.ErrorPayload{Message: "Game not found"}))
 		return
 	}
 
 	// Verify user is a player
 	if user.Email != game.Player1ID && user.Email != game.Player2ID {
-		sendSSE(w, flusher, "error", map[string]string{"message": "Not a player in this game"})
+		events.Send(w, events.Error.New(gameID, events.ErrorPayload{Message: "Not a player in this game"}))
 		return
 	}
 
@@ -392,11 +449,8 @@ func handleGameStream(w http.ResponseWriter, r *http.Request) {
 	log.Printf("🔵 Player %s connected to dots game %s via SSE", user.Email, gameID)
 
 	// Send initial state
-	sendSSE(w, flusher, "connected", map[string]interface{}{
-		"gameId": gameID,
-		"userId": user.Email,
-	})
-	sendSSE(w, flusher, "game_state", game)
+	events.Send(w, evConnected.New(gameID, StreamOpened{GameID: gameID, UserID: user.Email}))
+	events.Send(w, evGameState.New(gameID, game))
 
 	// Notify opponent
 	opponentID := game.Player1ID
@@ -416,20 +470,14 @@ func handleGameStream(w http.ResponseWriter, r *http.Request) {
 	if connectedPlayers, err := GetConnectedPlayers(gameID); err == nil {
 		for _, pid := range connectedPlayers {
 			if pid == opponentID {
-				sendSSE(w, flusher, "opponent_connected", map[string]interface{}{
-					"userId": opponentID,
-					"name":   opponentName,
-				})
+				events.Send(w, evOpponentConnected.New(gameID, OpponentPresence{UserID: opponentID, Name: opponentName}))
 				break
 			}
 		}
 	}
 
 	// Notify the opponent that this player has connected
-	PublishGameEvent(gameID, "opponent_connected", map[string]interface{}{
-		"userId": user.Email,
-		"name":   opponentName,
-	})
+	PublishGameEvent(evOpponentConnected.New(gameID, OpponentPresence{UserID: user.Email, Name: opponentName}))
 
 	// Subscribe to game updates
 	pubsub := SubscribeToGame(gameID)
@@ -459,8 +507,7 @@ func handleGameStream(w http.ResponseWriter, r *http.Request) {
 				if msg == nil {
 					continue
 				}
-				fmt.Fprintf(w, "data: %s\n\n", msg.Payload)
-				flusher.Flush()
+				events.Forward(w, msg.Payload)
 			case <-heartbeat.C:
 				fmt.Fprintf(w, ": heartbeat\n\n")
 				flusher.Flush()
@@ -479,9 +526,7 @@ func handleGameStream(w http.ResponseWriter, r *http.Request) {
 	log.Printf("🔴 Player %s disconnected from dots game %s", user.Email, gameID)
 
 	// Notify opponent of disconnect
-	PublishGameEvent(gameID, "opponent_disconnected", map[string]interface{}{
-		"userId": user.Email,
-	})
+	PublishGameEvent(evOpponentDisconnected.New(gameID, OpponentPresence{UserID: user.Email}))
 
 	// Check if opponent is still connected
 	if !IsPlayerConnected(gameID, opponentID) {
@@ -548,13 +593,11 @@ func handleForfeitHTTP(w http.ResponseWriter, r *http.Request) {
 
 	// Report to leaderboard (use token from current request)
 	token := getTokenFromRequest(r)
-	go reportToLeaderboard(game, token)
+	go reportToLeaderboard(game, token, false)
+	go reportToHistory(game, token)
+	go clearLobbyPresence(game.ID)
 
-	PublishGameEvent(gameID, "game_ended", map[string]interface{}{
-		"game":    game,
-		"message": "Opponent forfeited",
-		"reason":  "forfeit",
-	})
+	PublishGameEvent(evGameEnded.New(gameID, GameEnd{Game: game, Message: "Opponent forfeited", Reason: "forfeit"}))
 
 	respondJSON(w, map[string]interface{}{
 		"success": true,
@@ -625,13 +668,11 @@ func handleClaimWinHTTP(w http.ResponseWriter, r *http.Request) {
 
 	// Report to leaderboard (use token from current request)
 	token := getTokenFromRequest(r)
-	go reportToLeaderboard(game, token)
+	go reportToLeaderboard(game, token, true)
+	go reportToHistory(game, token)
+	go clearLobbyPresence(game.ID)
 
-	PublishGameEvent(gameID, "game_ended", map[string]interface{}{
-		"game":    game,
-		"message": "You won - opponent disconnected",
-		"reason":  "disconnect",
-	})
+	PublishGameEvent(evGameEnded.New(gameID, GameEnd{Game: game, Message: "You won - opponent disconnected", Reason: "disconnect"}))
 
 	respondJSON(w, map[string]interface{}{
 		"success": true,
@@ -650,13 +691,3 @@ func respondJSON(w http.ResponseWriter, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(data)
 }
-
-func sendSSE(w http.ResponseWriter, flusher http.Flusher, eventType string, data interface{}) {
-	event := map[string]interface{}{
-		"type":    eventType,
-		"payload": data,
-	}
-	jsonData, _ := json.Marshal(event)
-	fmt.Fprintf(w, "data: %s\n\n", jsonData)
-	flusher.Flush()
-}
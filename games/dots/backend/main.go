@@ -2,23 +2,73 @@ package main
 
 import (
 	"database/sql"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
 
 	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/achgithub/activity-hub-common/cli"
 	"github.com/achgithub/activity-hub-common/config"
 	"github.com/achgithub/activity-hub-common/database"
-	"github.com/gorilla/handlers"
+	"github.com/achgithub/activity-hub-common/events"
+	apphttp "github.com/achgithub/activity-hub-common/http"
+	"github.com/achgithub/activity-hub-common/maintenance"
+	"github.com/achgithub/activity-hub-common/services"
 	"github.com/gorilla/mux"
 	_ "github.com/lib/pq"
 )
 
 var db *sql.DB
 
+// identityDB is the shared identity database (auth, usage analytics)
+var identityDB *sql.DB
+
+// backends reports results to the leaderboard and who is playing to the lobby
+var backends *services.Registry
+
 const APP_NAME = "Dots"
 
 func main() {
+	cli.New("dots",
+		cli.Serve(serve),
+		cli.MigrateFunc(openAppDB, createTables),
+		cli.Command{
+			Name:  "reindex-stats",
+			Short: "Rebuild player stats from the finished games",
+			Run:   reindexStats,
+		},
+	).Main()
+}
+
+// openAppDB connects to dots_db for commands other than serve
+func openAppDB() (*sql.DB, error) {
+	return database.InitDatabase("dots")
+}
+
+// reindexStats is the reindex-stats command, for when player_stats has
+// drifted from the games (a stats update failed, or games were fixed by hand)
+func reindexStats(args []string) error {
+	if len(args) > 0 {
+		return cli.Usagef("reindex-stats takes no arguments")
+	}
+
+	var err error
+	db, err = openAppDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	players, err := RebuildPlayerStats()
+	if err != nil {
+		return fmt.Errorf("failed to rebuild player stats: %w", err)
+	}
+	fmt.Printf("Rebuilt stats for %d players\n", players)
+	return nil
+}
+
+func serve() {
 	log.Printf("🔵 %s Backend Starting", APP_NAME)
 
 	// Initialize Redis
@@ -26,6 +76,7 @@ func main() {
 		log.Fatal("Failed to connect to Redis:", err)
 	}
 	log.Println("✅ Connected to Redis")
+	initBus()
 
 	// Initialize app database
 	var err error
@@ -40,11 +91,12 @@ func main() {
 	}
 
 	// Initialize identity database (for authentication)
-	identityDB, err := database.InitIdentityDatabase()
+	identityDB, err = database.InitIdentityDatabase()
 	if err != nil {
 		log.Fatal("Failed to connect to identity database:", err)
 	}
 	defer identityDB.Close()
+	backends = services.NewRegistry(identityDB)
 
 	// Build per-route middleware
 	authMiddleware := authlib.Middleware(identityDB)
@@ -56,6 +108,7 @@ func main() {
 	// Public endpoints
 	r.HandleFunc("/api/health", handleHealth).Methods("GET")
 	r.HandleFunc("/api/config", handleGetConfig).Methods("GET")
+	r.HandleFunc("/api/events/schema", events.SchemaHandler).Methods("GET")
 
 	// SSE endpoint uses query-param auth (EventSource limitation)
 	r.Handle("/api/game/{gameId}/stream",
@@ -74,16 +127,12 @@ func main() {
 	r.PathPrefix("/").Handler(spaHandler{staticPath: staticDir, indexPath: "index.html"})
 
 	// CORS configuration
-	corsHandler := handlers.CORS(
-		handlers.AllowedOrigins([]string{"*"}),
-		handlers.AllowedMethods([]string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}),
-		handlers.AllowedHeaders([]string{"Content-Type", "Authorization"}),
-		handlers.AllowCredentials(),
-	)
+	cors := apphttp.NewCORSPolicy(identityDB)
+	appMaintenance := maintenance.New(identityDB)
 
 	port := config.GetEnv("PORT", "4011")
 	log.Printf("🚀 %s backend listening on :%s", APP_NAME, port)
-	log.Fatal(http.ListenAndServe(":"+port, corsHandler(r)))
+	log.Fatal(http.ListenAndServe(":"+port, cors.Middleware(appMaintenance.Middleware("dots")(apphttp.Versioned(r)))))
 }
 
 func handleHealth(w http.ResponseWriter, r *http.Request) {
@@ -8,6 +8,7 @@ import (
 	"os"
 	"time"
 
+	"github.com/achgithub/activity-hub-common/events"
 	"github.com/go-redis/redis/v8"
 )
 
@@ -90,21 +91,10 @@ func DeleteGame(gameID string) error {
 	return rdb.Del(ctx, key).Err()
 }
 
-// PublishGameEvent publishes an event to the game's channel
-func PublishGameEvent(gameID string, eventType string, payload interface{}) error {
-	channel := GameChannelPrefix + gameID + ":updates"
-
-	event := map[string]interface{}{
-		"type":    eventType,
-		"payload": payload,
-	}
-
-	data, err := json.Marshal(event)
-	if err != nil {
-		return err
-	}
-
-	return rdb.Publish(ctx, channel, data).Err()
+// PublishGameEvent publishes an event to its game's channel
+func PublishGameEvent(event events.Envelope) error {
+	channel := GameChannelPrefix + event.Session + ":updates"
+	return rdb.Publish(ctx, channel, event.Encode()).Err()
 }
 
 // SubscribeToGame subscribes to a game's update channel
@@ -36,6 +36,8 @@ interface Game {
 
 interface SSEMessage {
   type: string;
+  version: number; // Payload schema version; see GET /api/events/schema
+  session?: string;
   payload: {
     game?: Game;
     message?: string;
@@ -54,6 +56,21 @@ interface DotsGameProps {
   token: string;
 }
 
+// Single-use stream token from the shell, so the session token never goes in
+// the EventSource URL. Empty on failure: the stream then rejects the connection
+// and the normal reconnect path retries.
+async function fetchStreamToken(token: string): Promise<string> {
+  try {
+    const res = await fetch(`http://${window.location.hostname}:3001/api/auth/stream-token`, {
+      method: 'POST',
+      headers: { 'Authorization': `Bearer ${token}` },
+    });
+    return res.ok ? (await res.json()).token : '';
+  } catch {
+    return '';
+  }
+}
+
 const DotsGame: React.FC<DotsGameProps> = ({ gameId, user, token }) => {
   const userId = user.email;
 
@@ -82,14 +99,15 @@ const DotsGame: React.FC<DotsGameProps> = ({ gameId, user, token }) => {
   }, [game, getPlayerNum]);
 
   // Connect to SSE stream
-  const connectSSE = useCallback(() => {
+  const connectSSE = useCallback(async () => {
     if (!gameId || !userId) return;
 
     if (eventSourceRef.current) {
       eventSourceRef.current.close();
     }
 
-    const url = `${API_BASE}/game/${gameId}/stream?token=${encodeURIComponent(token)}`;
+    const streamToken = await fetchStreamToken(token);
+    const url = `${API_BASE}/game/${gameId}/stream?token=${encodeURIComponent(streamToken)}`;
     const es = new EventSource(url);
     eventSourceRef.current = es;
 
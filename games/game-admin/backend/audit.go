@@ -0,0 +1,87 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/achgithub/activity-hub-common/audit"
+)
+
+// auditLog is game_admin_db's audit_log, served at GET /api/audit
+var auditLog *audit.Log
+
+// Snapshot queries for rows whose changes are audited with before/after
+// copies. Each selects one row by $1.
+const (
+	// A pack with its rounds and their questions in order, so edits to any
+	// round show up as a change to the pack
+	quizPackSnapshot = `
+		SELECT p.id, p.guid, p.name, p.description, p.created_by, p.deleted_at, p.deleted_by,
+		       COALESCE((
+		           SELECT json_agg(json_build_object(
+		                      'id', r.id, 'roundNumber', r.round_number, 'name', r.name, 'type', r.type,
+		                      'timeLimitSeconds', r.time_limit_seconds,
+		                      'questionIds', COALESCE((
+		                          SELECT json_agg(rq.question_id ORDER BY rq.position)
+		                          FROM round_questions rq WHERE rq.round_id = r.id
+		                      ), '[]')
+		                  ) ORDER BY r.round_number, r.id)
+		           FROM rounds r WHERE r.pack_id = p.id
+		       ), '[]') AS rounds
+		FROM quiz_packs p
+		WHERE p.id = $1`
+	quizQuestionSnapshot = `SELECT * FROM questions WHERE id = $1`
+	lmsMatchSnapshot     = `SELECT * FROM matches WHERE id = $1`
+	pointsRuleSnapshot   = `SELECT * FROM points_rules WHERE activity = $1`
+)
+
+// snapshot copies a row for the audit log, or returns nil if it can't be
+// read - the change itself has already happened or is about to.
+func snapshot(db *sql.DB, query string, id interface{}) json.RawMessage {
+	row, err := audit.Snapshot(db, query, id)
+	if err != nil {
+		log.Printf("Warning: Failed to snapshot %v for audit log: %v", id, err)
+	}
+	return row
+}
+
+// logAudit logs an admin action. The admin and, for impersonated sessions, the
+// super_user actually behind the request are taken from headers set by requireGameAdmin.
+func logAudit(r *http.Request, actionType, targetID string, details map[string]interface{}) {
+	logAuditChange(r, actionType, targetID, details, nil, nil)
+}
+
+// logAuditChange logs an admin action with the changed row before and after,
+// from snapshot. before is nil for something created, after for something deleted.
+func logAuditChange(r *http.Request, actionType, targetID string, details map[string]interface{}, before, after json.RawMessage) {
+	recordAudit(audit.Entry{
+		AdminEmail:     r.Header.Get("X-Admin-Email"),
+		ImpersonatedBy: r.Header.Get("X-Impersonated-By"),
+		Action:         actionType,
+		TargetID:       targetID,
+		Before:         before,
+		After:          after,
+	}, details)
+}
+
+// writeAudit records an admin action for work that runs after its request has
+// finished, such as a background job.
+func writeAudit(adminEmail, impersonatedBy, actionType, targetID string, details map[string]interface{}) {
+	recordAudit(audit.Entry{
+		AdminEmail:     adminEmail,
+		ImpersonatedBy: impersonatedBy,
+		Action:         actionType,
+		TargetID:       targetID,
+	}, details)
+}
+
+func recordAudit(e audit.Entry, details map[string]interface{}) {
+	if details != nil {
+		e.Details, _ = json.Marshal(details)
+	}
+	if err := auditLog.Record(e); err != nil {
+		log.Printf("Warning: Failed to log audit action: %v", err)
+	}
+}
@@ -34,10 +34,26 @@ func requireGameAdmin(next http.Handler) http.Handler {
 		}
 
 		r.Header.Set("X-Admin-Email", user.Email)
+		if user.IsImpersonating {
+			// Real actor behind an impersonated session — recorded in the audit log
+			r.Header.Set("X-Impersonated-By", user.ImpersonatedBy)
+		} else {
+			r.Header.Del("X-Impersonated-By")
+		}
 		next.ServeHTTP(w, r)
 	})
 }
 
+// actorEmail is who really made the request, for the *_by columns: the
+// super_user behind an impersonated session, otherwise the admin. Taken from
+// the headers requireGameAdmin sets, never from the request body.
+func actorEmail(r *http.Request) string {
+	if by := r.Header.Get("X-Impersonated-By"); by != "" {
+		return by
+	}
+	return r.Header.Get("X-Admin-Email")
+}
+
 // requireWritePermission blocks the request when the caller has read-only access.
 func requireWritePermission(w http.ResponseWriter, r *http.Request) bool {
 	if r.Header.Get("X-Permission-Level") == "read-only" {
@@ -46,3 +62,22 @@ func requireWritePermission(w http.ResponseWriter, r *http.Request) bool {
 	}
 	return true
 }
+
+// requireQuestionContributor lets question_contributor users (and game admins)
+// submit quiz questions for review. Nothing else in Game Admin is open to them.
+// Must be used after authlib.Middleware.
+func requireQuestionContributor(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, ok := authlib.GetUserFromContext(r.Context())
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		if !user.HasRole("question_contributor") && !user.HasRole("game_admin") {
+			http.Error(w, "Forbidden - question_contributor role required", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
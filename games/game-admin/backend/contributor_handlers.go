@@ -0,0 +1,447 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/gorilla/mux"
+)
+
+// ============================================================
+// Quiz question contributions
+// ============================================================
+//
+// question_contributor users submit text questions into a moderation queue
+// (quiz_db question_submissions). Game admins edit, approve or reject them;
+// approved questions are copied into the question bank with contributed_by
+// set so the contributor is credited.
+
+// maxPendingSubmissions caps each contributor's queue so one person can't
+// bury the reviewers
+const maxPendingSubmissions = 50
+
+// QuestionSubmission is a contributed question and where it is in review
+type QuestionSubmission struct {
+	ID               int        `json:"id"`
+	ContributorEmail string     `json:"contributorEmail"`
+	ContributorName  string     `json:"contributorName,omitempty"`
+	Text             string     `json:"text"`
+	Answer           string     `json:"answer"`
+	Category         string     `json:"category"`
+	Difficulty       string     `json:"difficulty"`
+	Status           string     `json:"status"` // pending, approved, rejected
+	ReviewNote       string     `json:"reviewNote"`
+	ReviewedBy       string     `json:"reviewedBy,omitempty"`
+	ReviewedAt       *time.Time `json:"reviewedAt"`
+	QuestionID       *int       `json:"questionId"` // question bank ID once approved
+	CreatedAt        time.Time  `json:"createdAt"`
+}
+
+const submissionColumns = `
+	id, contributor_email, text, answer, COALESCE(category, ''), difficulty, status,
+	COALESCE(review_note, ''), COALESCE(reviewed_by, ''), reviewed_at, question_id, created_at`
+
+func scanSubmissions(rows *sql.Rows) ([]QuestionSubmission, error) {
+	defer rows.Close()
+	submissions := []QuestionSubmission{}
+	for rows.Next() {
+		var s QuestionSubmission
+		var reviewedAt sql.NullTime
+		var questionID sql.NullInt64
+		if err := rows.Scan(&s.ID, &s.ContributorEmail, &s.Text, &s.Answer, &s.Category, &s.Difficulty,
+			&s.Status, &s.ReviewNote, &s.ReviewedBy, &reviewedAt, &questionID, &s.CreatedAt); err != nil {
+			return nil, err
+		}
+		if reviewedAt.Valid {
+			s.ReviewedAt = &reviewedAt.Time
+		}
+		if questionID.Valid {
+			id := int(questionID.Int64)
+			s.QuestionID = &id
+		}
+		submissions = append(submissions, s)
+	}
+	return submissions, rows.Err()
+}
+
+// submissionFields is the editable part of a submission
+type submissionFields struct {
+	Text       string `json:"text"`
+	Answer     string `json:"answer"`
+	Category   string `json:"category"`
+	Difficulty string `json:"difficulty"`
+}
+
+// decodeSubmissionFields reads and validates a submission body. Contributions
+// are text questions only; pictures and music stay with the admins' media library.
+func decodeSubmissionFields(r *http.Request) (submissionFields, error) {
+	var f submissionFields
+	if err := json.NewDecoder(r.Body).Decode(&f); err != nil {
+		return f, fmt.Errorf("Invalid request")
+	}
+	f.Text = strings.TrimSpace(f.Text)
+	f.Answer = strings.TrimSpace(f.Answer)
+	f.Category = strings.TrimSpace(f.Category)
+	if f.Difficulty == "" {
+		f.Difficulty = "medium"
+	}
+
+	switch {
+	case f.Text == "" || f.Answer == "":
+		return f, fmt.Errorf("Question and answer are required")
+	case len(f.Text) > 500:
+		return f, fmt.Errorf("Question must be 500 characters or fewer")
+	case len(f.Answer) > 200:
+		return f, fmt.Errorf("Answer must be 200 characters or fewer")
+	case len(f.Category) > 100:
+		return f, fmt.Errorf("Category must be 100 characters or fewer")
+	case f.Difficulty != "easy" && f.Difficulty != "medium" && f.Difficulty != "hard":
+		return f, fmt.Errorf("Difficulty must be easy, medium or hard")
+	}
+	return f, nil
+}
+
+// questionInBank reports whether the bank already has a question with this text
+func questionInBank(text string) bool {
+	var exists bool
+	quizDB.QueryRow(`SELECT EXISTS (SELECT 1 FROM questions WHERE LOWER(text) = LOWER($1))`, text).Scan(&exists)
+	return exists
+}
+
+// --- Contributor endpoints (/api/contribute) ---
+
+// handleGetMySubmissions returns the contributor's own submissions and the
+// bank's existing categories to pick from.
+func handleGetMySubmissions(w http.ResponseWriter, r *http.Request) {
+	user, _ := authlib.GetUserFromContext(r.Context())
+
+	rows, err := quizDB.Query(`
+		SELECT `+submissionColumns+`
+		FROM question_submissions
+		WHERE contributor_email = $1
+		ORDER BY created_at DESC
+	`, user.Email)
+	if err != nil {
+		log.Printf("Failed to load submissions for %s: %v", user.Email, err)
+		sendError(w, "Failed to load submissions", http.StatusInternalServerError)
+		return
+	}
+	submissions, err := scanSubmissions(rows)
+	if err != nil {
+		sendError(w, "Failed to load submissions", http.StatusInternalServerError)
+		return
+	}
+	for i := range submissions {
+		submissions[i].ReviewedBy = "" // reviewers stay anonymous to contributors
+	}
+
+	categories := []string{}
+	if rows, err := quizDB.Query(`
+		SELECT DISTINCT category FROM questions
+		WHERE category IS NOT NULL AND category <> ''
+		ORDER BY category
+	`); err == nil {
+		for rows.Next() {
+			var c string
+			if rows.Scan(&c) == nil {
+				categories = append(categories, c)
+			}
+		}
+		rows.Close()
+	}
+
+	sendJSON(w, map[string]interface{}{
+		"submissions": submissions,
+		"categories":  categories,
+		"maxPending":  maxPendingSubmissions,
+	})
+}
+
+// handleSubmitQuestion adds a question to the moderation queue.
+func handleSubmitQuestion(w http.ResponseWriter, r *http.Request) {
+	user, _ := authlib.GetUserFromContext(r.Context())
+
+	f, err := decodeSubmissionFields(r)
+	if err != nil {
+		sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var pending int
+	quizDB.QueryRow(`
+		SELECT COUNT(*) FROM question_submissions WHERE contributor_email = $1 AND status = 'pending'
+	`, user.Email).Scan(&pending)
+	if pending >= maxPendingSubmissions {
+		sendError(w, fmt.Sprintf("You have %d questions waiting for review — try again once some are reviewed", pending), http.StatusTooManyRequests)
+		return
+	}
+	if questionInBank(f.Text) {
+		sendError(w, "That question is already in the bank", http.StatusConflict)
+		return
+	}
+
+	var id int
+	err = quizDB.QueryRow(`
+		INSERT INTO question_submissions (contributor_email, text, answer, category, difficulty)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`, user.Email, f.Text, f.Answer, nullableStr(f.Category), f.Difficulty).Scan(&id)
+	if err != nil {
+		log.Printf("Failed to save submission from %s: %v", user.Email, err)
+		sendError(w, "Failed to submit question", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("📝 Question submission %d from %s", id, user.Email)
+	sendJSON(w, map[string]interface{}{"id": id, "status": "pending"})
+}
+
+// handleUpdateMySubmission lets a contributor fix a question still waiting for review.
+func handleUpdateMySubmission(w http.ResponseWriter, r *http.Request) {
+	user, _ := authlib.GetUserFromContext(r.Context())
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		sendError(w, "Invalid submission ID", http.StatusBadRequest)
+		return
+	}
+	f, err := decodeSubmissionFields(r)
+	if err != nil {
+		sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	res, err := quizDB.Exec(`
+		UPDATE question_submissions
+		SET text = $3, answer = $4, category = $5, difficulty = $6, updated_at = NOW()
+		WHERE id = $1 AND contributor_email = $2 AND status = 'pending'
+	`, id, user.Email, f.Text, f.Answer, nullableStr(f.Category), f.Difficulty)
+	if err != nil {
+		sendError(w, "Failed to update submission", http.StatusInternalServerError)
+		return
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		sendError(w, "Pending submission not found", http.StatusNotFound)
+		return
+	}
+	sendJSON(w, map[string]interface{}{"success": true})
+}
+
+// handleWithdrawSubmission deletes a contributor's question still waiting for review.
+func handleWithdrawSubmission(w http.ResponseWriter, r *http.Request) {
+	user, _ := authlib.GetUserFromContext(r.Context())
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		sendError(w, "Invalid submission ID", http.StatusBadRequest)
+		return
+	}
+
+	res, err := quizDB.Exec(`
+		DELETE FROM question_submissions
+		WHERE id = $1 AND contributor_email = $2 AND status = 'pending'
+	`, id, user.Email)
+	if err != nil {
+		sendError(w, "Failed to withdraw submission", http.StatusInternalServerError)
+		return
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		sendError(w, "Pending submission not found", http.StatusNotFound)
+		return
+	}
+	sendJSON(w, map[string]interface{}{"success": true})
+}
+
+// --- Review endpoints (/api/quiz/submissions, game admins) ---
+
+// handleGetQuestionSubmissions returns the moderation queue.
+// ?status=pending (default), approved, rejected or all
+func handleGetQuestionSubmissions(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = "pending"
+	}
+	if status != "pending" && status != "approved" && status != "rejected" && status != "all" {
+		sendError(w, "status must be pending, approved, rejected or all", http.StatusBadRequest)
+		return
+	}
+
+	// Oldest first while waiting, newest first once reviewed
+	order := "created_at ASC"
+	if status != "pending" {
+		order = "COALESCE(reviewed_at, created_at) DESC"
+	}
+	rows, err := quizDB.Query(`
+		SELECT `+submissionColumns+`
+		FROM question_submissions
+		WHERE $1 = 'all' OR status = $1
+		ORDER BY `+order+`
+		LIMIT 200
+	`, status)
+	if err != nil {
+		log.Printf("Failed to load question submissions: %v", err)
+		sendError(w, "Failed to load submissions", http.StatusInternalServerError)
+		return
+	}
+	submissions, err := scanSubmissions(rows)
+	if err != nil {
+		sendError(w, "Failed to load submissions", http.StatusInternalServerError)
+		return
+	}
+
+	emails := make([]string, 0, len(submissions))
+	for _, s := range submissions {
+		emails = append(emails, s.ContributorEmail)
+	}
+	profiles, _ := authlib.LoadProfiles(identityDB, emails)
+	for i := range submissions {
+		if p, ok := profiles[submissions[i].ContributorEmail]; ok {
+			submissions[i].ContributorName = p.DisplayName()
+		}
+	}
+
+	sendJSON(w, map[string]interface{}{"submissions": submissions})
+}
+
+// handleUpdateQuestionSubmission lets a reviewer tidy up a pending question
+// before approving it.
+func handleUpdateQuestionSubmission(w http.ResponseWriter, r *http.Request) {
+	if !requireWritePermission(w, r) {
+		return
+	}
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		sendError(w, "Invalid submission ID", http.StatusBadRequest)
+		return
+	}
+	f, err := decodeSubmissionFields(r)
+	if err != nil {
+		sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	res, err := quizDB.Exec(`
+		UPDATE question_submissions
+		SET text = $2, answer = $3, category = $4, difficulty = $5, updated_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+	`, id, f.Text, f.Answer, nullableStr(f.Category), f.Difficulty)
+	if err != nil {
+		sendError(w, "Failed to update submission", http.StatusInternalServerError)
+		return
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		sendError(w, "Pending submission not found", http.StatusNotFound)
+		return
+	}
+
+	logAudit(r, "quiz_submission_edit", strconv.Itoa(id), nil)
+	sendJSON(w, map[string]interface{}{"success": true})
+}
+
+// handleApproveQuestionSubmission copies a pending question into the bank,
+// credited to its contributor.
+func handleApproveQuestionSubmission(w http.ResponseWriter, r *http.Request) {
+	if !requireWritePermission(w, r) {
+		return
+	}
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		sendError(w, "Invalid submission ID", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := quizDB.Begin()
+	if err != nil {
+		sendError(w, "Failed to approve submission", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	var f submissionFields
+	var contributor string
+	err = tx.QueryRow(`
+		SELECT contributor_email, text, answer, COALESCE(category, ''), difficulty
+		FROM question_submissions
+		WHERE id = $1 AND status = 'pending'
+		FOR UPDATE
+	`, id).Scan(&contributor, &f.Text, &f.Answer, &f.Category, &f.Difficulty)
+	if err == sql.ErrNoRows {
+		sendError(w, "Pending submission not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		sendError(w, "Failed to approve submission", http.StatusInternalServerError)
+		return
+	}
+
+	var questionID int
+	err = tx.QueryRow(`
+		INSERT INTO questions (text, answer, category, difficulty, type, contributed_by)
+		VALUES ($1, $2, $3, $4, 'text', $5)
+		RETURNING id
+	`, f.Text, f.Answer, nullableStr(f.Category), f.Difficulty, contributor).Scan(&questionID)
+	if err != nil {
+		log.Printf("Failed to add approved submission %d to the bank: %v", id, err)
+		sendError(w, "Failed to approve submission", http.StatusInternalServerError)
+		return
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE question_submissions
+		SET status = 'approved', question_id = $2, reviewed_by = $3, reviewed_at = NOW(), updated_at = NOW()
+		WHERE id = $1
+	`, id, questionID, actorEmail(r)); err != nil {
+		sendError(w, "Failed to approve submission", http.StatusInternalServerError)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		sendError(w, "Failed to approve submission", http.StatusInternalServerError)
+		return
+	}
+
+	logAudit(r, "quiz_submission_approve", strconv.Itoa(id), map[string]interface{}{
+		"questionId":  questionID,
+		"contributor": contributor,
+	})
+	sendJSON(w, map[string]interface{}{"success": true, "questionId": questionID})
+}
+
+// handleRejectQuestionSubmission turns a pending question down, with an
+// optional note the contributor sees.
+func handleRejectQuestionSubmission(w http.ResponseWriter, r *http.Request) {
+	if !requireWritePermission(w, r) {
+		return
+	}
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		sendError(w, "Invalid submission ID", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	json.NewDecoder(r.Body).Decode(&req)
+	req.Reason = strings.TrimSpace(req.Reason)
+
+	res, err := quizDB.Exec(`
+		UPDATE question_submissions
+		SET status = 'rejected', review_note = $2, reviewed_by = $3, reviewed_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+	`, id, nullableStr(req.Reason), actorEmail(r))
+	if err != nil {
+		sendError(w, "Failed to reject submission", http.StatusInternalServerError)
+		return
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		sendError(w, "Pending submission not found", http.StatusNotFound)
+		return
+	}
+
+	logAudit(r, "quiz_submission_reject", strconv.Itoa(id), map[string]interface{}{"reason": req.Reason})
+	sendJSON(w, map[string]interface{}{"success": true})
+}
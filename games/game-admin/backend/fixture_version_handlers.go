@@ -0,0 +1,467 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// --- Fixture File Versions ---
+//
+// Every fixture CSV upload is kept as a numbered version of its fixture file.
+// A version is applied (its matches upserted) straight away unless it would
+// change a match that an open round depends on; then it waits as 'pending'
+// until an admin has seen the diff and confirms. Matches missing from a newer
+// file are reported as removed but never deleted, as picks may reference them.
+
+// fixtureRow is one match as read from a fixture CSV
+type fixtureRow struct {
+	MatchNumber int    `json:"matchNumber"`
+	RoundNumber int    `json:"roundNumber"`
+	Date        string `json:"date"` // YYYY-MM-DD
+	Location    string `json:"location"`
+	HomeTeam    string `json:"homeTeam"`
+	AwayTeam    string `json:"awayTeam"`
+	Result      string `json:"result"`
+}
+
+// parseFixtureCSV reads the match rows of a fixture CSV (see handleUploadFixture
+// for the format), after the header row. Rows without a usable match or round
+// number are ignored; rows with an unreadable date are counted as skipped.
+func parseFixtureCSV(records [][]string) ([]fixtureRow, int) {
+	var rows []fixtureRow
+	skipped := 0
+	for _, record := range records[1:] {
+		if len(record) < 6 {
+			continue
+		}
+		matchNumber, err := strconv.Atoi(strings.TrimSpace(record[0]))
+		if err != nil {
+			continue
+		}
+		roundNumber, err := strconv.Atoi(strings.TrimSpace(record[1]))
+		if err != nil {
+			continue
+		}
+
+		// Parse date into DATE — try YYYY-MM-DD then DD/MM/YYYY
+		matchDate, err := parseMatchDate(strings.TrimSpace(record[2]))
+		if err != nil {
+			log.Printf("Skipping match %d: cannot parse date %q: %v", matchNumber, record[2], err)
+			skipped++
+			continue
+		}
+
+		row := fixtureRow{
+			MatchNumber: matchNumber,
+			RoundNumber: roundNumber,
+			Date:        matchDate.Format("2006-01-02"),
+			Location:    strings.TrimSpace(record[3]),
+			HomeTeam:    strings.TrimSpace(record[4]),
+			AwayTeam:    strings.TrimSpace(record[5]),
+		}
+		if len(record) > 6 {
+			row.Result = strings.TrimSpace(record[6])
+		}
+		rows = append(rows, row)
+	}
+	return rows, skipped
+}
+
+// matchChange is one match that a fixture version adds, changes or drops
+type matchChange struct {
+	MatchNumber int         `json:"matchNumber"`
+	Before      *fixtureRow `json:"before,omitempty"`
+	After       *fixtureRow `json:"after,omitempty"`
+	Fields      []string    `json:"fields,omitempty"`     // What changed
+	OpenRounds  []string    `json:"openRounds,omitempty"` // Open rounds whose window the match is (or was) in
+	Picks       int         `json:"picks"`                // Picks on the match in open rounds
+	Byes        int         `json:"byes"`                 // Picks that become byes as the match moves out of their round
+}
+
+// fixtureDiff is what applying a fixture version would change
+type fixtureDiff struct {
+	Added             []matchChange `json:"added"`
+	Changed           []matchChange `json:"changed"`
+	Removed           []matchChange `json:"removed"`
+	Unchanged         int           `json:"unchanged"`
+	Byes              int           `json:"byes"`
+	AffectsOpenRounds bool          `json:"affectsOpenRounds"`
+}
+
+// openRound is an open round of an active game using the fixture file
+type openRound struct {
+	id         int
+	name       string
+	start, end time.Time
+}
+
+func (o openRound) contains(date string) bool {
+	d, err := time.Parse("2006-01-02", date)
+	return err == nil && !d.Before(o.start) && !d.After(o.end)
+}
+
+// diffFixture compares a fixture version's matches with the fixture file's
+// current matches, and works out which open rounds the changes would touch
+func diffFixture(fixtureFileID int, rows []fixtureRow) (*fixtureDiff, error) {
+	current := map[int]fixtureRow{}
+	matchRows, err := lmsDB.Query(`
+		SELECT match_number, round_number, match_date, location, home_team, away_team, COALESCE(result, '')
+		FROM matches WHERE fixture_file_id = $1
+	`, fixtureFileID)
+	if err != nil {
+		return nil, err
+	}
+	for matchRows.Next() {
+		var m fixtureRow
+		var matchDate time.Time
+		if err := matchRows.Scan(&m.MatchNumber, &m.RoundNumber, &matchDate, &m.Location, &m.HomeTeam, &m.AwayTeam, &m.Result); err != nil {
+			matchRows.Close()
+			return nil, err
+		}
+		m.Date = matchDate.Format("2006-01-02")
+		current[m.MatchNumber] = m
+	}
+	matchRows.Close()
+
+	var rounds []openRound
+	roundRows, err := lmsDB.Query(`
+		SELECT r.id, g.name || ' round ' || r.label, r.start_date, r.end_date
+		FROM rounds r
+		JOIN games g ON g.id = r.game_id
+		WHERE g.fixture_file_id = $1 AND g.deleted_at IS NULL AND g.status = 'active' AND r.status = 'open'
+	`, fixtureFileID)
+	if err != nil {
+		return nil, err
+	}
+	for roundRows.Next() {
+		var o openRound
+		if err := roundRows.Scan(&o.id, &o.name, &o.start, &o.end); err != nil {
+			roundRows.Close()
+			return nil, err
+		}
+		rounds = append(rounds, o)
+	}
+	roundRows.Close()
+
+	// Picks per match number and open round
+	picks := map[int]map[int]int{}
+	pickRows, err := lmsDB.Query(`
+		SELECT m.match_number, p.round_id, COUNT(*)
+		FROM predictions p
+		JOIN matches m ON m.id = p.match_id
+		JOIN rounds r ON r.id = p.round_id
+		WHERE m.fixture_file_id = $1 AND r.status = 'open' AND NOT p.voided
+		GROUP BY m.match_number, p.round_id
+	`, fixtureFileID)
+	if err != nil {
+		return nil, err
+	}
+	for pickRows.Next() {
+		var matchNumber, roundID, count int
+		if err := pickRows.Scan(&matchNumber, &roundID, &count); err != nil {
+			pickRows.Close()
+			return nil, err
+		}
+		if picks[matchNumber] == nil {
+			picks[matchNumber] = map[int]int{}
+		}
+		picks[matchNumber][roundID] = count
+	}
+	pickRows.Close()
+
+	// touch fills in the open rounds a change reaches; a match counts if its
+	// old or new date is in the round's window
+	touch := func(c *matchChange) {
+		for _, o := range rounds {
+			before := c.Before != nil && o.contains(c.Before.Date)
+			after := c.After != nil && o.contains(c.After.Date)
+			if !before && !after {
+				continue
+			}
+			c.OpenRounds = append(c.OpenRounds, o.name)
+			n := picks[c.MatchNumber][o.id]
+			c.Picks += n
+			if before && !after {
+				c.Byes += n
+			}
+		}
+	}
+
+	diff := &fixtureDiff{Added: []matchChange{}, Changed: []matchChange{}, Removed: []matchChange{}}
+	seen := map[int]bool{}
+	for i := range rows {
+		after := rows[i]
+		seen[after.MatchNumber] = true
+		before, exists := current[after.MatchNumber]
+		if !exists {
+			c := matchChange{MatchNumber: after.MatchNumber, After: &after}
+			touch(&c)
+			diff.Added = append(diff.Added, c)
+			continue
+		}
+		fields := changedFields(before, after)
+		if len(fields) == 0 {
+			diff.Unchanged++
+			continue
+		}
+		c := matchChange{MatchNumber: after.MatchNumber, Before: &before, After: &after, Fields: fields}
+		touch(&c)
+		diff.Changed = append(diff.Changed, c)
+	}
+	for number, before := range current {
+		if !seen[number] {
+			before := before
+			diff.Removed = append(diff.Removed, matchChange{MatchNumber: number, Before: &before})
+		}
+	}
+	sort.Slice(diff.Removed, func(i, j int) bool { return diff.Removed[i].MatchNumber < diff.Removed[j].MatchNumber })
+
+	// Removed matches are kept, so only additions and changes reach open rounds
+	for _, list := range [][]matchChange{diff.Added, diff.Changed} {
+		for _, c := range list {
+			diff.Byes += c.Byes
+			if len(c.OpenRounds) > 0 {
+				diff.AffectsOpenRounds = true
+			}
+		}
+	}
+	return diff, nil
+}
+
+// changedFields names the fields that differ between two versions of a match
+func changedFields(before, after fixtureRow) []string {
+	var fields []string
+	if before.RoundNumber != after.RoundNumber {
+		fields = append(fields, "roundNumber")
+	}
+	if before.Date != after.Date {
+		fields = append(fields, "date")
+	}
+	if before.Location != after.Location {
+		fields = append(fields, "location")
+	}
+	if before.HomeTeam != after.HomeTeam {
+		fields = append(fields, "homeTeam")
+	}
+	if before.AwayTeam != after.AwayTeam {
+		fields = append(fields, "awayTeam")
+	}
+	if before.Result != after.Result {
+		fields = append(fields, "result")
+	}
+	return fields
+}
+
+// applyFixtureVersion upserts a version's matches on (fixture_file_id, match_number)
+// and marks it applied, all in one transaction. Returns the number of matches upserted.
+func applyFixtureVersion(fixtureFileID, versionID int, rows []fixtureRow, adminEmail string) (int, error) {
+	tx, err := lmsDB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	for _, m := range rows {
+		_, err := tx.Exec(`
+			INSERT INTO matches (fixture_file_id, match_number, round_number, match_date, location, home_team, away_team, result)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			ON CONFLICT (fixture_file_id, match_number) DO UPDATE
+			SET round_number=$3, match_date=$4, location=$5, home_team=$6, away_team=$7, result=$8
+		`, fixtureFileID, m.MatchNumber, m.RoundNumber, m.Date, m.Location, m.HomeTeam, m.AwayTeam, m.Result)
+		if err != nil {
+			return 0, fmt.Errorf("match %d: %w", m.MatchNumber, err)
+		}
+	}
+
+	if _, err := tx.Exec("UPDATE fixture_files SET updated_at = NOW() WHERE id = $1", fixtureFileID); err != nil {
+		return 0, err
+	}
+	_, err = tx.Exec(`
+		UPDATE fixture_versions SET status = 'applied', applied_by = $2, applied_at = NOW()
+		WHERE id = $1
+	`, versionID, adminEmail)
+	if err != nil {
+		return 0, err
+	}
+	return len(rows), tx.Commit()
+}
+
+// loadFixtureVersion reads a version of a fixture file by its number
+func loadFixtureVersion(fixtureFileID, version int) (id int, status string, rows []fixtureRow, err error) {
+	var matchesJSON []byte
+	err = lmsDB.QueryRow(`
+		SELECT id, status, matches FROM fixture_versions
+		WHERE fixture_file_id = $1 AND version = $2
+	`, fixtureFileID, version).Scan(&id, &status, &matchesJSON)
+	if err != nil {
+		return 0, "", nil, err
+	}
+	err = json.Unmarshal(matchesJSON, &rows)
+	return id, status, rows, err
+}
+
+// fixtureVersionVars reads the fixture file id and version number from the path
+func fixtureVersionVars(w http.ResponseWriter, r *http.Request) (int, int, bool) {
+	vars := mux.Vars(r)
+	fixtureFileID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		sendError(w, "Invalid fixture file ID", http.StatusBadRequest)
+		return 0, 0, false
+	}
+	version, err := strconv.Atoi(vars["version"])
+	if err != nil {
+		sendError(w, "Invalid version", http.StatusBadRequest)
+		return 0, 0, false
+	}
+	return fixtureFileID, version, true
+}
+
+// handleGetFixtureVersions lists a fixture file's uploads, newest first.
+func handleGetFixtureVersions(w http.ResponseWriter, r *http.Request) {
+	rows, err := lmsDB.Query(`
+		SELECT version, status, jsonb_array_length(matches), skipped,
+		       COALESCE(uploaded_by, ''), uploaded_at, COALESCE(applied_by, ''), applied_at
+		FROM fixture_versions
+		WHERE fixture_file_id = $1
+		ORDER BY version DESC
+	`, mux.Vars(r)["id"])
+	if err != nil {
+		log.Printf("Error getting fixture versions: %v", err)
+		sendError(w, "Failed to get fixture versions", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	versions := []map[string]interface{}{}
+	for rows.Next() {
+		var version, matchCount, skipped int
+		var status, uploadedBy, appliedBy string
+		var uploadedAt time.Time
+		var appliedAt sql.NullTime
+		if err := rows.Scan(&version, &status, &matchCount, &skipped, &uploadedBy, &uploadedAt, &appliedBy, &appliedAt); err != nil {
+			continue
+		}
+		v := map[string]interface{}{
+			"version":    version,
+			"status":     status,
+			"matchCount": matchCount,
+			"skipped":    skipped,
+			"uploadedBy": uploadedBy,
+			"uploadedAt": uploadedAt,
+		}
+		if appliedAt.Valid {
+			v["appliedBy"] = appliedBy
+			v["appliedAt"] = appliedAt.Time
+		}
+		versions = append(versions, v)
+	}
+	sendJSON(w, map[string]interface{}{"versions": versions})
+}
+
+// handleGetFixtureVersionDiff shows what applying a version would change in
+// the fixture file's matches as they are now.
+func handleGetFixtureVersionDiff(w http.ResponseWriter, r *http.Request) {
+	fixtureFileID, version, ok := fixtureVersionVars(w, r)
+	if !ok {
+		return
+	}
+	_, status, rows, err := loadFixtureVersion(fixtureFileID, version)
+	if err == sql.ErrNoRows {
+		sendError(w, "Fixture version not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error loading fixture version: %v", err)
+		sendError(w, "Failed to load fixture version", http.StatusInternalServerError)
+		return
+	}
+
+	diff, err := diffFixture(fixtureFileID, rows)
+	if err != nil {
+		log.Printf("Error diffing fixture version: %v", err)
+		sendError(w, "Failed to diff fixture version", http.StatusInternalServerError)
+		return
+	}
+	sendJSON(w, map[string]interface{}{"version": version, "status": status, "diff": diff})
+}
+
+// handleApplyFixtureVersion applies a pending version. Changes that affect open
+// rounds need {"confirm": true}; without it the diff comes back with a 409.
+func handleApplyFixtureVersion(w http.ResponseWriter, r *http.Request) {
+	if !requireWritePermission(w, r) {
+		return
+	}
+	fixtureFileID, version, ok := fixtureVersionVars(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Confirm bool `json:"confirm"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			sendError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	versionID, status, rows, err := loadFixtureVersion(fixtureFileID, version)
+	if err == sql.ErrNoRows {
+		sendError(w, "Fixture version not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error loading fixture version: %v", err)
+		sendError(w, "Failed to load fixture version", http.StatusInternalServerError)
+		return
+	}
+	if status != "pending" {
+		sendError(w, fmt.Sprintf("Version %d is %s, only pending versions can be applied", version, status), http.StatusConflict)
+		return
+	}
+
+	// Re-check against the matches as they are now, not as they were at upload
+	diff, err := diffFixture(fixtureFileID, rows)
+	if err != nil {
+		log.Printf("Error diffing fixture version: %v", err)
+		sendError(w, "Failed to diff fixture version", http.StatusInternalServerError)
+		return
+	}
+	if diff.AffectsOpenRounds && !req.Confirm {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error": "This version changes matches in open rounds; confirm to apply it",
+			"diff":  diff,
+		})
+		return
+	}
+
+	upserted, err := applyFixtureVersion(fixtureFileID, versionID, rows, actorEmail(r))
+	if err != nil {
+		log.Printf("Error applying fixture version %d of file %d: %v", version, fixtureFileID, err)
+		sendError(w, "Failed to apply fixture version", http.StatusInternalServerError)
+		return
+	}
+
+	logAudit(r, "lms_fixture_apply", strconv.Itoa(fixtureFileID), map[string]interface{}{
+		"version": version, "upserted": upserted, "added": len(diff.Added), "changed": len(diff.Changed),
+		"byes": diff.Byes, "confirmed": diff.AffectsOpenRounds,
+	})
+	sendJSON(w, map[string]interface{}{
+		"success":  true,
+		"version":  version,
+		"upserted": upserted,
+		"diff":     diff,
+	})
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"database/sql"
 	"encoding/csv"
 	"encoding/json"
@@ -12,7 +14,13 @@ import (
 	"strings"
 	"time"
 
+	"github.com/achgithub/activity-hub-common/config"
+	apphttp "github.com/achgithub/activity-hub-common/http"
+	"github.com/achgithub/activity-hub-common/jobs"
+	"github.com/achgithub/activity-hub-common/listquery"
+	"github.com/achgithub/activity-hub-common/upload"
 	"github.com/gorilla/mux"
+	"github.com/lib/pq"
 )
 
 // sendJSON sends a JSON response.
@@ -35,11 +43,15 @@ func handleConfig(w http.ResponseWriter, r *http.Request) {
 	var currentGameID string
 	lmsDB.QueryRow("SELECT value FROM settings WHERE key = 'current_game_id'").Scan(&currentGameID)
 
-	sendJSON(w, map[string]interface{}{
-		"appName":         "Game Admin",
-		"version":         "1.0.0",
-		"permissionLevel": permissionLevel,
-		"currentGameId":   currentGameID,
+	apphttp.WriteConfig(w, apphttp.AppConfig{
+		AppID:       "game-admin",
+		Name:        "Game Admin",
+		Icon:        "🎮",
+		Description: "Manage games, rounds, matches and results for Last Man Standing",
+		Extra: map[string]interface{}{
+			"permissionLevel": permissionLevel,
+			"currentGameId":   currentGameID,
+		},
 	})
 }
 
@@ -48,7 +60,7 @@ func handleConfig(w http.ResponseWriter, r *http.Request) {
 // handleGetFixtures returns all fixture files with match counts.
 func handleGetFixtures(w http.ResponseWriter, r *http.Request) {
 	rows, err := lmsDB.Query(`
-		SELECT f.id, f.name, COUNT(m.id) AS match_count, f.updated_at
+		SELECT f.id, f.guid::text, f.name, COUNT(m.id) AS match_count, f.updated_at
 		FROM fixture_files f
 		LEFT JOIN matches m ON m.fixture_file_id = f.id
 		GROUP BY f.id, f.name, f.updated_at
@@ -64,13 +76,14 @@ func handleGetFixtures(w http.ResponseWriter, r *http.Request) {
 	var fixtures []map[string]interface{}
 	for rows.Next() {
 		var id, matchCount int
-		var name string
+		var guid, name string
 		var updatedAt interface{}
-		if err := rows.Scan(&id, &name, &matchCount, &updatedAt); err != nil {
+		if err := rows.Scan(&id, &guid, &name, &matchCount, &updatedAt); err != nil {
 			continue
 		}
 		fixtures = append(fixtures, map[string]interface{}{
 			"id":         id,
+			"guid":       guid,
 			"name":       name,
 			"matchCount": matchCount,
 			"updatedAt":  updatedAt,
@@ -88,13 +101,18 @@ func handleGetFixtures(w http.ResponseWriter, r *http.Request) {
 // Date must be YYYY-MM-DD or DD/MM/YYYY. round_number is stored as metadata only.
 // The result column is optional. Status is only set to 'completed' via the set-result endpoint.
 // Matches are upserted on (fixture_file_id, match_number).
+//
+// Each upload is stored as a new version of the file along with its diff. If the
+// changes touch an open round the version is left pending (202) until confirmed
+// at POST /api/lms/fixtures/{id}/versions/{version}/apply.
 func handleUploadFixture(w http.ResponseWriter, r *http.Request) {
 	if !requireWritePermission(w, r) {
 		return
 	}
 
-	if err := r.ParseMultipartForm(10 << 20); err != nil {
-		sendError(w, "Failed to parse form", http.StatusBadRequest)
+	file, err := upload.Read(w, r, "file", upload.Sheets)
+	if err != nil {
+		sendError(w, err.Error(), upload.StatusCode(err))
 		return
 	}
 
@@ -104,12 +122,18 @@ func handleUploadFixture(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	file, _, err := r.FormFile("file")
+	reader := csv.NewReader(bytes.NewReader(file.Data))
+	records, err := reader.ReadAll()
 	if err != nil {
-		sendError(w, "file field is required", http.StatusBadRequest)
+		sendError(w, "Failed to parse CSV", http.StatusBadRequest)
+		return
+	}
+	if len(records) < 2 {
+		sendError(w, "CSV must have a header row and at least one match", http.StatusBadRequest)
 		return
 	}
-	defer file.Close()
+	rows, skipped := parseFixtureCSV(records)
+	matchesJSON, _ := json.Marshal(rows)
 
 	// Find or create fixture file by name
 	var fixtureFileID int
@@ -123,75 +147,85 @@ func handleUploadFixture(w http.ResponseWriter, r *http.Request) {
 			sendError(w, "Failed to create fixture file", http.StatusInternalServerError)
 			return
 		}
-	} else {
-		lmsDB.Exec("UPDATE fixture_files SET updated_at = NOW() WHERE id = $1", fixtureFileID)
 	}
 
-	reader := csv.NewReader(file)
-	records, err := reader.ReadAll()
+	// A new upload supersedes any version still waiting for confirmation
+	adminEmail := actorEmail(r)
+	var versionID, version int
+	tx, err := lmsDB.Begin()
 	if err != nil {
-		sendError(w, "Failed to parse CSV", http.StatusBadRequest)
+		log.Printf("Error starting fixture version transaction: %v", err)
+		sendError(w, "Failed to save fixture version", http.StatusInternalServerError)
 		return
 	}
-	if len(records) < 2 {
-		sendError(w, "CSV must have a header row and at least one match", http.StatusBadRequest)
+	defer tx.Rollback()
+	// Lock the file's row so concurrent uploads get consecutive version numbers
+	if _, err = tx.Exec("SELECT id FROM fixture_files WHERE id = $1 FOR UPDATE", fixtureFileID); err == nil {
+		_, err = tx.Exec(`
+			UPDATE fixture_versions SET status = 'superseded'
+			WHERE fixture_file_id = $1 AND status = 'pending'
+		`, fixtureFileID)
+	}
+	if err == nil {
+		err = tx.QueryRow(`
+			INSERT INTO fixture_versions (fixture_file_id, version, matches, skipped, uploaded_by)
+			SELECT $1, COALESCE(MAX(version), 0) + 1, $2, $3, $4
+			FROM fixture_versions WHERE fixture_file_id = $1
+			RETURNING id, version
+		`, fixtureFileID, matchesJSON, skipped, adminEmail).Scan(&versionID, &version)
+	}
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		log.Printf("Error saving fixture version: %v", err)
+		sendError(w, "Failed to save fixture version", http.StatusInternalServerError)
 		return
 	}
 
-	upserted := 0
-	skipped := 0
-	for _, row := range records[1:] {
-		if len(row) < 6 {
-			continue
-		}
-		matchNumber, err := strconv.Atoi(strings.TrimSpace(row[0]))
-		if err != nil {
-			continue
-		}
-		roundNumber, err := strconv.Atoi(strings.TrimSpace(row[1]))
-		if err != nil {
-			continue
-		}
-
-		// Parse date into DATE — try YYYY-MM-DD then DD/MM/YYYY
-		matchDate, err := parseMatchDate(strings.TrimSpace(row[2]))
-		if err != nil {
-			log.Printf("Skipping match %d: cannot parse date %q: %v", matchNumber, row[2], err)
-			skipped++
-			continue
-		}
-
-		location := strings.TrimSpace(row[3])
-		homeTeam := strings.TrimSpace(row[4])
-		awayTeam := strings.TrimSpace(row[5])
+	diff, err := diffFixture(fixtureFileID, rows)
+	if err != nil {
+		log.Printf("Error diffing fixture upload: %v", err)
+		sendError(w, "Failed to compare fixture file", http.StatusInternalServerError)
+		return
+	}
 
-		result := ""
-		if len(row) > 6 {
-			result = strings.TrimSpace(row[6])
-		}
+	if diff.AffectsOpenRounds {
+		logAudit(r, "lms_fixture_upload", strconv.Itoa(fixtureFileID), map[string]interface{}{
+			"name": name, "version": version, "pending": true, "skipped": skipped,
+		})
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":              false,
+			"requiresConfirmation": true,
+			"id":                   fixtureFileID,
+			"name":                 name,
+			"version":              version,
+			"skipped":              skipped,
+			"diff":                 diff,
+		})
+		return
+	}
 
-		_, err = lmsDB.Exec(`
-			INSERT INTO matches (fixture_file_id, match_number, round_number, match_date, location, home_team, away_team, result)
-			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-			ON CONFLICT (fixture_file_id, match_number) DO UPDATE
-			SET round_number=$3, match_date=$4, location=$5, home_team=$6, away_team=$7, result=$8
-		`, fixtureFileID, matchNumber, roundNumber, matchDate, location, homeTeam, awayTeam, result)
-		if err != nil {
-			log.Printf("Error upserting match %d: %v", matchNumber, err)
-			continue
-		}
-		upserted++
+	upserted, err := applyFixtureVersion(fixtureFileID, versionID, rows, adminEmail)
+	if err != nil {
+		log.Printf("Error applying fixture version %d of file %d: %v", version, fixtureFileID, err)
+		sendError(w, "Failed to save matches", http.StatusInternalServerError)
+		return
 	}
 
-	logAudit(r.Header.Get("X-Admin-Email"), "lms_fixture_upload", strconv.Itoa(fixtureFileID), map[string]interface{}{
-		"name": name, "upserted": upserted, "skipped": skipped,
+	logAudit(r, "lms_fixture_upload", strconv.Itoa(fixtureFileID), map[string]interface{}{
+		"name": name, "version": version, "upserted": upserted, "skipped": skipped,
 	})
 	sendJSON(w, map[string]interface{}{
 		"success":  true,
 		"id":       fixtureFileID,
 		"name":     name,
+		"version":  version,
 		"upserted": upserted,
 		"skipped":  skipped,
+		"diff":     diff,
 	})
 }
 
@@ -261,15 +295,37 @@ func handleGetFixtureMatches(w http.ResponseWriter, r *http.Request) {
 
 // --- LMS Game Management ---
 
-// handleGetLMSGames returns all LMS games with their fixture file names.
+// lmsGameList is what the LMS games list can be sorted and filtered by.
+var lmsGameList = listquery.Spec{
+	Sorts: map[string]string{
+		"id":    "g.id",
+		"name":  "g.name",
+		"start": "g.start_date",
+	},
+	DefaultSort: "-id",
+	Tiebreak:    "g.id",
+	Filters: map[string]listquery.Filter{
+		"status": listquery.Equals("g.status"),
+		"q":      listquery.Search("g.name"),
+	},
+}
+
+// handleGetLMSGames returns a page of LMS games with their fixture file names.
 func handleGetLMSGames(w http.ResponseWriter, r *http.Request) {
-	rows, err := lmsDB.Query(`
-		SELECT g.id, g.name, g.status, g.winner_count,
-		       g.start_date, COALESCE(g.fixture_file_id, 0), COALESCE(f.name, '')
+	q, err := listquery.Parse(r.URL.Query(), lmsGameList)
+	if err != nil {
+		sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	q.Where("g.deleted_at IS NULL")
+
+	query, args := q.SQL(`
+		SELECT g.id, g.guid::text, g.name, g.status, g.winner_count,
+		       g.start_date, COALESCE(g.fixture_file_id, 0), COALESCE(f.name, ''),
+		       g.is_private, COALESCE(g.join_code, '')
 		FROM games g
-		LEFT JOIN fixture_files f ON f.id = g.fixture_file_id
-		ORDER BY g.id DESC
-	`)
+		LEFT JOIN fixture_files f ON f.id = g.fixture_file_id`)
+	rows, err := lmsDB.Query(query, args...)
 	if err != nil {
 		log.Printf("Error getting games: %v", err)
 		sendError(w, "Failed to get games", http.StatusInternalServerError)
@@ -280,24 +336,27 @@ func handleGetLMSGames(w http.ResponseWriter, r *http.Request) {
 	var games []map[string]interface{}
 	for rows.Next() {
 		var id, winnerCount, fixtureFileID int
-		var name, status, fixtureName string
+		var guid, name, status, fixtureName, joinCode string
+		var isPrivate bool
 		var startDate interface{}
-		if err := rows.Scan(&id, &name, &status, &winnerCount, &startDate, &fixtureFileID, &fixtureName); err != nil {
+		if err := rows.Scan(&id, &guid, &name, &status, &winnerCount, &startDate, &fixtureFileID, &fixtureName,
+			&isPrivate, &joinCode); err != nil {
 			continue
 		}
 		games = append(games, map[string]interface{}{
 			"id":            id,
+			"guid":          guid,
 			"name":          name,
 			"status":        status,
 			"winnerCount":   winnerCount,
 			"startDate":     startDate,
 			"fixtureFileId": fixtureFileID,
 			"fixtureName":   fixtureName,
+			"isPrivate":     isPrivate,
+			"joinCode":      joinCode,
 		})
 	}
-	if games == nil {
-		games = []map[string]interface{}{}
-	}
+	games, page := listquery.Finish(q, games)
 
 	var currentGameID string
 	lmsDB.QueryRow("SELECT value FROM settings WHERE key = 'current_game_id'").Scan(&currentGameID)
@@ -305,10 +364,12 @@ func handleGetLMSGames(w http.ResponseWriter, r *http.Request) {
 	sendJSON(w, map[string]interface{}{
 		"games":         games,
 		"currentGameId": currentGameID,
+		"page":          page,
 	})
 }
 
 // handleCreateLMSGame creates a new LMS game linked to a fixture file.
+// Private games get a join code instead of being offered as the current game.
 func handleCreateLMSGame(w http.ResponseWriter, r *http.Request) {
 	if !requireWritePermission(w, r) {
 		return
@@ -317,6 +378,7 @@ func handleCreateLMSGame(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		Name          string `json:"name"`
 		FixtureFileID int    `json:"fixtureFileId"`
+		Private       bool   `json:"private"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Name == "" {
 		sendError(w, "name is required", http.StatusBadRequest)
@@ -337,18 +399,28 @@ func handleCreateLMSGame(w http.ResponseWriter, r *http.Request) {
 
 	var id int
 	err := lmsDB.QueryRow(`
-		INSERT INTO games (name, fixture_file_id) VALUES ($1, $2) RETURNING id
-	`, req.Name, req.FixtureFileID).Scan(&id)
+		INSERT INTO games (name, fixture_file_id, is_private) VALUES ($1, $2, $3) RETURNING id
+	`, req.Name, req.FixtureFileID, req.Private).Scan(&id)
 	if err != nil {
 		log.Printf("Error creating game: %v", err)
 		sendError(w, "Failed to create game", http.StatusInternalServerError)
 		return
 	}
 
-	logAudit(r.Header.Get("X-Admin-Email"), "lms_game_create", strconv.Itoa(id), map[string]interface{}{
-		"name": req.Name, "fixtureFileId": req.FixtureFileID,
+	joinCode := ""
+	if req.Private {
+		if joinCode, err = assignJoinCode(id); err != nil {
+			log.Printf("Error generating join code: %v", err)
+			lmsDB.Exec("DELETE FROM games WHERE id = $1", id)
+			sendError(w, "Failed to generate join code", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	logAudit(r, "lms_game_create", strconv.Itoa(id), map[string]interface{}{
+		"name": req.Name, "fixtureFileId": req.FixtureFileID, "private": req.Private,
 	})
-	sendJSON(w, map[string]interface{}{"success": true, "id": id})
+	sendJSON(w, map[string]interface{}{"success": true, "id": id, "joinCode": joinCode})
 }
 
 // handleSetCurrentGame sets the active game via settings.
@@ -360,12 +432,15 @@ func handleSetCurrentGame(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	gameID := vars["id"]
 
-	var exists bool
-	lmsDB.QueryRow("SELECT EXISTS(SELECT 1 FROM games WHERE id = $1)", gameID).Scan(&exists)
-	if !exists {
+	var isPrivate bool
+	if err := lmsDB.QueryRow("SELECT is_private FROM games WHERE id = $1 AND deleted_at IS NULL", gameID).Scan(&isPrivate); err != nil {
 		sendError(w, "Game not found", http.StatusNotFound)
 		return
 	}
+	if isPrivate {
+		sendError(w, "Private games can't be the current game — players join them by code", http.StatusBadRequest)
+		return
+	}
 
 	_, err := lmsDB.Exec(`
 		INSERT INTO settings (key, value) VALUES ('current_game_id', $1)
@@ -376,11 +451,13 @@ func handleSetCurrentGame(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	logAudit(r.Header.Get("X-Admin-Email"), "lms_game_set_current", gameID, nil)
+	logAudit(r, "lms_game_set_current", gameID, nil)
 	sendJSON(w, map[string]interface{}{"success": true})
 }
 
-// handleDeleteGame permanently deletes a game and all associated data (rounds, predictions, players).
+// handleDeleteGame moves a game to the trash. Its rounds, players and
+// predictions are kept so a restore brings it back exactly as it was; they go
+// when the trash is purged.
 func handleDeleteGame(w http.ResponseWriter, r *http.Request) {
 	if !requireWritePermission(w, r) {
 		return
@@ -389,34 +466,19 @@ func handleDeleteGame(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	gameID := vars["id"]
 
-	var exists bool
-	lmsDB.QueryRow("SELECT EXISTS(SELECT 1 FROM games WHERE id = $1)", gameID).Scan(&exists)
-	if !exists {
-		sendError(w, "Game not found", http.StatusNotFound)
-		return
-	}
-
-	tx, err := lmsDB.Begin()
+	result, err := lmsDB.Exec(`
+		UPDATE games SET deleted_at = NOW(), deleted_by = $2 WHERE id = $1 AND deleted_at IS NULL
+	`, gameID, actorEmail(r))
 	if err != nil {
-		sendError(w, "Failed to start transaction", http.StatusInternalServerError)
-		return
-	}
-	defer tx.Rollback()
-
-	tx.Exec("DELETE FROM predictions WHERE game_id = $1", gameID)
-	tx.Exec("DELETE FROM game_players WHERE game_id = $1", gameID)
-	tx.Exec("DELETE FROM rounds WHERE game_id = $1", gameID)
-	if _, err := tx.Exec("DELETE FROM games WHERE id = $1", gameID); err != nil {
 		sendError(w, "Failed to delete game", http.StatusInternalServerError)
 		return
 	}
-
-	if err := tx.Commit(); err != nil {
-		sendError(w, "Failed to commit delete", http.StatusInternalServerError)
+	if n, _ := result.RowsAffected(); n == 0 {
+		sendError(w, "Game not found", http.StatusNotFound)
 		return
 	}
 
-	logAudit(r.Header.Get("X-Admin-Email"), "lms_game_delete", gameID, nil)
+	logAudit(r, "lms_game_delete", gameID, nil)
 	sendJSON(w, map[string]interface{}{"success": true})
 }
 
@@ -435,7 +497,7 @@ func handleCompleteGame(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	logAudit(r.Header.Get("X-Admin-Email"), "lms_game_complete", gameID, nil)
+	logAudit(r, "lms_game_complete", gameID, nil)
 	sendJSON(w, map[string]interface{}{"success": true})
 }
 
@@ -446,9 +508,16 @@ func handleGetLMSRounds(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	gameID := vars["gameId"]
 
+	defer logQueryDuration("lms rounds", time.Now())
+
 	rows, err := lmsDB.Query(`
-		SELECT id, label, start_date, end_date, submission_deadline, status
-		FROM rounds WHERE game_id = $1 ORDER BY label
+		SELECT r.id, r.guid::text, r.label, r.start_date, r.end_date, r.submission_deadline, r.status,
+		       COUNT(p.id)
+		FROM rounds r
+		LEFT JOIN predictions p ON p.round_id = r.id
+		WHERE r.game_id = $1
+		GROUP BY r.id
+		ORDER BY r.label
 	`, gameID)
 	if err != nil {
 		sendError(w, "Failed to get rounds", http.StatusInternalServerError)
@@ -458,18 +527,13 @@ func handleGetLMSRounds(w http.ResponseWriter, r *http.Request) {
 
 	var rounds []map[string]interface{}
 	for rows.Next() {
-		var id, label int
+		var id, label, predCount int
 		var startDate, endDate time.Time
 		var deadline sql.NullTime
-		var status string
-		if err := rows.Scan(&id, &label, &startDate, &endDate, &deadline, &status); err != nil {
+		var guid, status string
+		if err := rows.Scan(&id, &guid, &label, &startDate, &endDate, &deadline, &status, &predCount); err != nil {
 			continue
 		}
-		var predCount int
-		lmsDB.QueryRow(
-			"SELECT COUNT(*) FROM predictions WHERE round_id = $1",
-			id,
-		).Scan(&predCount)
 
 		var deadlineStr interface{}
 		if deadline.Valid {
@@ -477,6 +541,7 @@ func handleGetLMSRounds(w http.ResponseWriter, r *http.Request) {
 		}
 		rounds = append(rounds, map[string]interface{}{
 			"id":                 id,
+			"guid":               guid,
 			"label":              label,
 			"startDate":          startDate.Format("2006-01-02"),
 			"endDate":            endDate.Format("2006-01-02"),
@@ -555,7 +620,7 @@ func handleCreateRound(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	logAudit(r.Header.Get("X-Admin-Email"), "lms_round_create", strconv.Itoa(id), map[string]interface{}{
+	logAudit(r, "lms_round_create", strconv.Itoa(id), map[string]interface{}{
 		"gameId": req.GameID, "label": req.Label, "startDate": req.StartDate, "endDate": req.EndDate,
 		"submissionDeadline": req.SubmissionDeadline,
 	})
@@ -597,7 +662,7 @@ func handleUpdateRoundStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	logAudit(r.Header.Get("X-Admin-Email"), "lms_round_status", gameID+"/"+label, map[string]interface{}{"status": req.Status})
+	logAudit(r, "lms_round_status", gameID+"/"+label, map[string]interface{}{"status": req.Status})
 	sendJSON(w, map[string]interface{}{"success": true})
 }
 
@@ -636,35 +701,30 @@ func handleDeleteRound(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	logAudit(r.Header.Get("X-Admin-Email"), "lms_round_delete", gameID+"/"+label, nil)
+	logAudit(r, "lms_round_delete", gameID+"/"+label, nil)
 	sendJSON(w, map[string]interface{}{"success": true})
 }
 
 // handleGetAdminRoundSummary returns round stats for admin (identified by gameId + label).
 func handleGetAdminRoundSummary(w http.ResponseWriter, r *http.Request) {
+	defer logQueryDuration("lms round summary", time.Now())
+
 	vars := mux.Vars(r)
 	gameID := vars["gameId"]
 	label := vars["label"]
 
-	var roundID int
-	var startDate, endDate time.Time
-	var status string
-	err := lmsDB.QueryRow(`
-		SELECT id, start_date, end_date, status FROM rounds WHERE game_id = $1 AND label = $2
-	`, gameID, label).Scan(&roundID, &startDate, &endDate, &status)
-	if err != nil {
-		sendError(w, "Round not found", http.StatusNotFound)
-		return
-	}
-
+	// One query for the round and its predictions; a round with no predictions
+	// comes back as a single row with NULL prediction columns
 	rows, err := lmsDB.Query(`
-		SELECT p.user_id, p.predicted_team, p.is_correct, p.voided, p.bye,
+		SELECT r.start_date, r.end_date, r.status,
+		       p.user_id, p.predicted_team, p.is_correct, p.voided, p.bye,
 		       m.home_team, m.away_team, m.result
-		FROM predictions p
-		JOIN matches m ON m.id = p.match_id
-		WHERE p.round_id = $1
+		FROM rounds r
+		LEFT JOIN predictions p ON p.round_id = r.id
+		LEFT JOIN matches m ON m.id = p.match_id
+		WHERE r.game_id = $1 AND r.label = $2
 		ORDER BY p.user_id
-	`, roundID)
+	`, gameID, label)
 	if err != nil {
 		sendError(w, "Failed to get predictions", http.StatusInternalServerError)
 		return
@@ -681,13 +741,28 @@ func handleGetAdminRoundSummary(w http.ResponseWriter, r *http.Request) {
 		AwayTeam      string `json:"awayTeam"`
 		Result        string `json:"result"`
 	}
-	var preds []PredRow
+	var startDate, endDate time.Time
+	var status string
+	found := false
+	preds := []PredRow{}
 	survived, eliminated := 0, 0
 	for rows.Next() {
-		var p PredRow
-		if err := rows.Scan(&p.UserID, &p.PredictedTeam, &p.IsCorrect, &p.Voided, &p.Bye, &p.HomeTeam, &p.AwayTeam, &p.Result); err != nil {
+		var userID, predictedTeam, homeTeam, awayTeam, result sql.NullString
+		var voided, bye sql.NullBool
+		var isCorrect *bool
+		if err := rows.Scan(&startDate, &endDate, &status, &userID, &predictedTeam, &isCorrect, &voided, &bye,
+			&homeTeam, &awayTeam, &result); err != nil {
+			continue
+		}
+		found = true
+		if !userID.Valid {
 			continue
 		}
+		p := PredRow{
+			UserID: userID.String, PredictedTeam: predictedTeam.String, IsCorrect: isCorrect,
+			Voided: voided.Bool, Bye: bye.Bool,
+			HomeTeam: homeTeam.String, AwayTeam: awayTeam.String, Result: result.String,
+		}
 		preds = append(preds, p)
 		if p.Bye || (p.IsCorrect != nil && *p.IsCorrect) {
 			survived++
@@ -695,8 +770,9 @@ func handleGetAdminRoundSummary(w http.ResponseWriter, r *http.Request) {
 			eliminated++
 		}
 	}
-	if preds == nil {
-		preds = []PredRow{}
+	if !found {
+		sendError(w, "Round not found", http.StatusNotFound)
+		return
 	}
 
 	sendJSON(w, map[string]interface{}{
@@ -818,13 +894,15 @@ func handleSetMatchResult(w http.ResponseWriter, r *http.Request) {
 		matchStatus = "postponed"
 	}
 
+	before := snapshot(lmsDB, lmsMatchSnapshot, matchIDStr)
 	if _, err := lmsDB.Exec(`UPDATE matches SET result = $1, status = $2 WHERE id = $3`,
 		req.Result, matchStatus, matchIDStr); err != nil {
 		sendError(w, "Failed to update match", http.StatusInternalServerError)
 		return
 	}
 
-	logAudit(r.Header.Get("X-Admin-Email"), "lms_match_result", matchIDStr, map[string]interface{}{"result": req.Result})
+	logAuditChange(r, "lms_match_result", matchIDStr, map[string]interface{}{"result": req.Result},
+		before, snapshot(lmsDB, lmsMatchSnapshot, matchIDStr))
 	sendJSON(w, map[string]interface{}{"success": true})
 }
 
@@ -835,6 +913,10 @@ func handleSetMatchResult(w http.ResponseWriter, r *http.Request) {
 //
 // Pre-flight: all matches currently within the round's date window must have a result
 // (status != 'upcoming'). Matches outside the window are automatically byes.
+//
+// The checks run in the request; the scoring itself runs as a background job
+// (see processRound). Responds 202 with the job ID to poll at GET /api/jobs/{id},
+// or 409 with the running job's ID if the round is already being processed.
 func handleProcessRound(w http.ResponseWriter, r *http.Request) {
 	if !requireWritePermission(w, r) {
 		return
@@ -876,6 +958,66 @@ func handleProcessRound(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if jobRunner == nil {
+		sendError(w, "Background jobs are unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	// The task outlives the request, so take the audit identity now
+	adminEmail := r.Header.Get("X-Admin-Email")
+	impersonatedBy := r.Header.Get("X-Impersonated-By")
+	target := gameIDStr + "/" + labelStr
+
+	jobID, err := jobRunner.Submit("lms-round", target, roundProcessTimeout,
+		func(ctx context.Context, p *jobs.Progress) (interface{}, error) {
+			summary, err := processRound(ctx, p, gameID, roundID, labelStr, fixtureFileID, startDate, endDate)
+			if err != nil {
+				return nil, err
+			}
+			writeAudit(adminEmail, impersonatedBy, "lms_round_process", target, map[string]interface{}{
+				"roundId": roundID, "survived": summary.Survived, "eliminated": summary.Eliminated,
+				"byes": summary.Byes, "autoPicked": summary.AutoPicked,
+			})
+			return summary, nil
+		})
+	if err == jobs.ErrJobRunning {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]string{"error": "This round is already being processed", "jobId": jobID})
+		return
+	} else if err != nil {
+		log.Printf("Error starting round processing: %v", err)
+		sendError(w, "Failed to start round processing", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{"jobId": jobID})
+}
+
+// roundProcessTimeout bounds one round's background processing
+const roundProcessTimeout = 10 * time.Minute
+
+// roundUpdateBatch is how many predictions are scored per UPDATE
+const roundUpdateBatch = 500
+
+// RoundSummary is the result of processing an LMS round
+type RoundSummary struct {
+	Success    bool `json:"success"`
+	Processed  int  `json:"processed"`
+	Survived   int  `json:"survived"`
+	Eliminated int  `json:"eliminated"`
+	Byes       int  `json:"byes"`
+	AutoPicked int  `json:"autoPicked"`
+}
+
+// processRound auto-picks for players who missed the deadline, then scores every
+// prediction in the round in one transaction. Predictions are sorted into byes,
+// correct and incorrect picks and written a batch at a time rather than row by row.
+func processRound(ctx context.Context, p *jobs.Progress, gameID, roundID int, label string, fixtureFileID int, startDate, endDate time.Time) (*RoundSummary, error) {
+	p.Update(0, 0, "Auto-picking for players without a prediction")
+
 	// Auto-pick: for every active player without a prediction, pick the first available
 	// team alphabetically (not yet used by that player this game).
 	// This covers players who missed the submission deadline.
@@ -885,97 +1027,107 @@ func handleProcessRound(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get all predictions for this round (including any just auto-picked)
-	rows, err := lmsDB.Query(`
-		SELECT p.id, p.user_id, p.predicted_team, p.match_id,
+	rows, err := lmsDB.QueryContext(ctx, `
+		SELECT p.id, p.user_id, p.predicted_team,
 		       m.home_team, m.away_team, m.result, m.status, m.match_date
 		FROM predictions p
 		JOIN matches m ON m.id = p.match_id
 		WHERE p.round_id = $1
 	`, roundID)
 	if err != nil {
-		sendError(w, "Failed to get predictions", http.StatusInternalServerError)
-		return
+		return nil, fmt.Errorf("failed to get predictions: %w", err)
 	}
 
-	type predInfo struct {
-		ID            int
-		UserID        string
-		PredictedTeam string
-		MatchID       int
-		HomeTeam      string
-		AwayTeam      string
-		Result        string
-		MatchStatus   string
-		MatchDate     time.Time
-	}
-	var preds []predInfo
+	summary := &RoundSummary{Success: true, AutoPicked: autoPicked}
+	var byeIDs, correctIDs, incorrectIDs []int64
+	var eliminatedUsers []string
 	for rows.Next() {
-		var p predInfo
-		if err := rows.Scan(&p.ID, &p.UserID, &p.PredictedTeam, &p.MatchID,
-			&p.HomeTeam, &p.AwayTeam, &p.Result, &p.MatchStatus, &p.MatchDate); err == nil {
-			preds = append(preds, p)
+		var id int64
+		var userID, predictedTeam, homeTeam, awayTeam, result, matchStatus string
+		var matchDate time.Time
+		if err := rows.Scan(&id, &userID, &predictedTeam, &homeTeam, &awayTeam, &result, &matchStatus, &matchDate); err != nil {
+			continue
+		}
+		summary.Processed++
+
+		// Determine if match is within this round's window
+		inWindow := !matchDate.Before(startDate) && !matchDate.After(endDate)
+
+		if !inWindow || matchStatus == "postponed" {
+			// Bye: player survives, team is consumed (voided stays FALSE)
+			byeIDs = append(byeIDs, id)
+			summary.Byes++
+			summary.Survived++
+			continue
+		}
+
+		// Match is in window and completed — evaluate normally.
+		// A draw has no winner, so all predictors are eliminated.
+		winnerTeam, _ := parseResult(result, homeTeam, awayTeam)
+		if winnerTeam != "" && predictedTeam == winnerTeam {
+			correctIDs = append(correctIDs, id)
+			summary.Survived++
+		} else {
+			incorrectIDs = append(incorrectIDs, id)
+			eliminatedUsers = append(eliminatedUsers, userID)
+			summary.Eliminated++
 		}
 	}
 	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read predictions: %w", err)
+	}
 
-	if len(preds) == 0 {
-		sendJSON(w, map[string]interface{}{"success": true, "processed": 0, "survived": 0, "eliminated": 0})
-		return
+	if summary.Processed == 0 {
+		return summary, nil
 	}
 
-	tx, err := lmsDB.Begin()
+	tx, err := lmsDB.BeginTx(ctx, nil)
 	if err != nil {
-		sendError(w, "Failed to start transaction", http.StatusInternalServerError)
-		return
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
 	}
 	defer tx.Rollback()
 
-	survived, eliminated, byes := 0, 0, 0
-	for _, p := range preds {
-		// Determine if match is within this round's window
-		inWindow := !p.MatchDate.Before(startDate) && !p.MatchDate.After(endDate)
-
-		if !inWindow || p.MatchStatus == "postponed" {
-			// Bye: player survives, team is consumed (voided stays FALSE)
-			tx.Exec("UPDATE predictions SET bye = TRUE, is_correct = NULL WHERE id = $1", p.ID)
-			byes++
-			survived++
-		} else {
-			// Match is in window and completed — evaluate normally
-			winnerTeam, _ := parseResult(p.Result, p.HomeTeam, p.AwayTeam)
-			var isCorrect bool
-			if winnerTeam == "" {
-				// Draw — all predictors eliminated
-				isCorrect = false
-			} else {
-				isCorrect = p.PredictedTeam == winnerTeam
-			}
-			tx.Exec("UPDATE predictions SET is_correct = $1 WHERE id = $2", isCorrect, p.ID)
-			if isCorrect {
-				survived++
-			} else {
-				tx.Exec("UPDATE game_players SET is_active = FALSE WHERE user_id = $1 AND game_id = $2", p.UserID, gameID)
-				eliminated++
+	updates := []struct {
+		query string
+		ids   []int64
+	}{
+		{"UPDATE predictions SET bye = TRUE, is_correct = NULL WHERE id = ANY($1)", byeIDs},
+		{"UPDATE predictions SET is_correct = TRUE WHERE id = ANY($1)", correctIDs},
+		{"UPDATE predictions SET is_correct = FALSE WHERE id = ANY($1)", incorrectIDs},
+	}
+	total := summary.Processed + len(eliminatedUsers)
+	done := 0
+	for _, u := range updates {
+		for start := 0; start < len(u.ids); start += roundUpdateBatch {
+			batch := u.ids[start:min(start+roundUpdateBatch, len(u.ids))]
+			if _, err := tx.ExecContext(ctx, u.query, pq.Array(batch)); err != nil {
+				return nil, fmt.Errorf("failed to score predictions: %w", err)
 			}
+			done += len(batch)
+			p.Update(done, total, "Scoring predictions")
+		}
+	}
+
+	for start := 0; start < len(eliminatedUsers); start += roundUpdateBatch {
+		batch := eliminatedUsers[start:min(start+roundUpdateBatch, len(eliminatedUsers))]
+		_, err := tx.ExecContext(ctx, `
+			UPDATE game_players SET is_active = FALSE WHERE game_id = $1 AND user_id = ANY($2)
+		`, gameID, pq.Array(batch))
+		if err != nil {
+			return nil, fmt.Errorf("failed to eliminate players: %w", err)
 		}
+		done += len(batch)
+		p.Update(done, total, "Eliminating players")
 	}
 
 	if err := tx.Commit(); err != nil {
-		sendError(w, "Failed to commit results", http.StatusInternalServerError)
-		return
+		return nil, fmt.Errorf("failed to commit results: %w", err)
 	}
 
-	logAudit(r.Header.Get("X-Admin-Email"), "lms_round_process", gameIDStr+"/"+labelStr, map[string]interface{}{
-		"roundId": roundID, "survived": survived, "eliminated": eliminated, "byes": byes, "autoPicked": autoPicked,
-	})
-	sendJSON(w, map[string]interface{}{
-		"success":    true,
-		"processed":  len(preds),
-		"survived":   survived,
-		"eliminated": eliminated,
-		"byes":       byes,
-		"autoPicked": autoPicked,
-	})
+	go publishLMSEliminations(gameID, label, eliminatedUsers)
+
+	return summary, nil
 }
 
 // --- LMS Predictions ---
@@ -1043,6 +1195,26 @@ func handleGetAllPredictions(w http.ResponseWriter, r *http.Request) {
 
 // --- Helpers ---
 
+// slowQueryThreshold is how long a handler's queries may take before it is
+// logged. SLOW_QUERY_MS overrides the default; 0 logs every call.
+var slowQueryThreshold = func() time.Duration {
+	ms, err := strconv.Atoi(config.GetEnv("SLOW_QUERY_MS", ""))
+	if err != nil || ms < 0 {
+		return 200 * time.Millisecond
+	}
+	return time.Duration(ms) * time.Millisecond
+}()
+
+// logQueryDuration logs how long a handler spent on its queries when it went
+// over slowQueryThreshold. Call it deferred at the top of the handler:
+//
+//	defer logQueryDuration("lms rounds", time.Now())
+func logQueryDuration(name string, start time.Time) {
+	if elapsed := time.Since(start); elapsed >= slowQueryThreshold {
+		log.Printf("Slow query: %s took %dms", name, elapsed.Milliseconds())
+	}
+}
+
 // applyAutoPicks inserts predictions for active players who haven't picked for a round.
 // For each such player, picks the first alphabetically available team (not yet used this game).
 // Returns the number of auto-picks inserted.
@@ -1192,18 +1364,6 @@ func parseResult(result, homeTeam, awayTeam string) (winnerTeam string, isPostpo
 	return "", false // draw
 }
 
-// logAudit logs an admin action.
-func logAudit(adminEmail, actionType, targetID string, details map[string]interface{}) {
-	detailsJSON, _ := json.Marshal(details)
-	_, err := gameAdminDB.Exec(`
-		INSERT INTO audit_log (admin_email, action_type, target_id, details)
-		VALUES ($1, $2, $3, $4)
-	`, adminEmail, actionType, targetID, sql.NullString{String: string(detailsJSON), Valid: details != nil})
-	if err != nil {
-		log.Printf("Warning: Failed to log audit action: %v", err)
-	}
-}
-
 // ============================================================
 // Sweepstakes admin handlers
 // ============================================================
@@ -1211,8 +1371,9 @@ func logAudit(adminEmail, actionType, targetID string, details map[string]interf
 // handleGetSweepCompetitions returns all sweepstakes competitions.
 func handleGetSweepCompetitions(w http.ResponseWriter, r *http.Request) {
 	rows, err := sweepstakesDB.Query(`
-		SELECT id, name, type, status, COALESCE(description, ''), created_at
+		SELECT id, guid::text, name, type, status, COALESCE(description, ''), created_at
 		FROM competitions
+		WHERE deleted_at IS NULL
 		ORDER BY created_at DESC
 	`)
 	if err != nil {
@@ -1223,6 +1384,7 @@ func handleGetSweepCompetitions(w http.ResponseWriter, r *http.Request) {
 
 	type Comp struct {
 		ID          int    `json:"id"`
+		Guid        string `json:"guid"`
 		Name        string `json:"name"`
 		Type        string `json:"type"`
 		Status      string `json:"status"`
@@ -1232,7 +1394,7 @@ func handleGetSweepCompetitions(w http.ResponseWriter, r *http.Request) {
 	comps := []Comp{}
 	for rows.Next() {
 		var c Comp
-		if err := rows.Scan(&c.ID, &c.Name, &c.Type, &c.Status, &c.Description, &c.CreatedAt); err != nil {
+		if err := rows.Scan(&c.ID, &c.Guid, &c.Name, &c.Type, &c.Status, &c.Description, &c.CreatedAt); err != nil {
 			continue
 		}
 		comps = append(comps, c)
@@ -1297,31 +1459,61 @@ func handleUpdateSweepCompetition(w http.ResponseWriter, r *http.Request) {
 		sendError(w, "Failed to update: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
+	publishSweepEvent(id, "status", map[string]string{"status": req.Status})
 	w.WriteHeader(http.StatusOK)
 }
 
-// handleDeleteSweepCompetition deletes a competition and all its data.
+// handleDeleteSweepCompetition moves a competition (with its entries and draws) to the trash.
 func handleDeleteSweepCompetition(w http.ResponseWriter, r *http.Request) {
 	if !requireWritePermission(w, r) {
 		return
 	}
 	id := mux.Vars(r)["id"]
-	_, err := sweepstakesDB.Exec(`DELETE FROM competitions WHERE id = $1`, id)
+	result, err := sweepstakesDB.Exec(`
+		UPDATE competitions SET deleted_at = NOW(), deleted_by = $2 WHERE id = $1 AND deleted_at IS NULL
+	`, id, actorEmail(r))
 	if err != nil {
 		sendError(w, "Failed to delete competition: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		sendError(w, "Competition not found", http.StatusNotFound)
+		return
+	}
+	logAudit(r, "sweep_competition_delete", id, nil)
 	w.WriteHeader(http.StatusOK)
 }
 
-// handleGetSweepEntries returns entries for a competition.
+// sweepEntryList is what a competition's entries can be sorted and filtered
+// by. "position" is finishing position, then seed, number and name.
+var sweepEntryList = listquery.Spec{
+	Sorts: map[string]string{
+		"position": "COALESCE(position, 999), COALESCE(seed, 999), COALESCE(number, 999), name",
+		"name":     "name",
+		"seed":     "COALESCE(seed, 999)",
+		"number":   "COALESCE(number, 999)",
+	},
+	DefaultSort: "position",
+	Tiebreak:    "id",
+	Filters: map[string]listquery.Filter{
+		"status": listquery.Equals("status"),
+		"q":      listquery.Search("name"),
+	},
+}
+
+// handleGetSweepEntries returns a page of a competition's entries.
 func handleGetSweepEntries(w http.ResponseWriter, r *http.Request) {
-	compID := mux.Vars(r)["id"]
-	rows, err := sweepstakesDB.Query(`
-		SELECT id, competition_id, name, seed, number, status, position, created_at
-		FROM entries WHERE competition_id = $1
-		ORDER BY COALESCE(position, 999), COALESCE(seed, 999), COALESCE(number, 999), name
-	`, compID)
+	q, err := listquery.Parse(r.URL.Query(), sweepEntryList)
+	if err != nil {
+		sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	q.Where("competition_id = ?", mux.Vars(r)["id"])
+
+	query, args := q.SQL(`
+		SELECT id, guid::text, competition_id, name, seed, number, status, position, created_at
+		FROM entries`)
+	rows, err := sweepstakesDB.Query(query, args...)
 	if err != nil {
 		sendError(w, "Database error", http.StatusInternalServerError)
 		return
@@ -1330,6 +1522,7 @@ func handleGetSweepEntries(w http.ResponseWriter, r *http.Request) {
 
 	type EntryRow struct {
 		ID            int     `json:"id"`
+		Guid          string  `json:"guid"`
 		CompetitionID int     `json:"competition_id"`
 		Name          string  `json:"name"`
 		Seed          *int    `json:"seed"`
@@ -1342,7 +1535,7 @@ func handleGetSweepEntries(w http.ResponseWriter, r *http.Request) {
 	for rows.Next() {
 		var e EntryRow
 		var seed, number, position sql.NullInt64
-		if err := rows.Scan(&e.ID, &e.CompetitionID, &e.Name, &seed, &number, &e.Status, &position, &e.CreatedAt); err != nil {
+		if err := rows.Scan(&e.ID, &e.Guid, &e.CompetitionID, &e.Name, &seed, &number, &e.Status, &position, &e.CreatedAt); err != nil {
 			continue
 		}
 		if seed.Valid {
@@ -1359,14 +1552,15 @@ func handleGetSweepEntries(w http.ResponseWriter, r *http.Request) {
 		}
 		entries = append(entries, e)
 	}
-	sendJSON(w, map[string]interface{}{"entries": entries})
+	entries, page := listquery.Finish(q, entries)
+	sendJSON(w, map[string]interface{}{"entries": entries, "page": page})
 }
 
 // handleGetSweepAllDraws returns all draws for a competition with entry and user info.
 func handleGetSweepAllDraws(w http.ResponseWriter, r *http.Request) {
 	compID := mux.Vars(r)["id"]
 	rows, err := sweepstakesDB.Query(`
-		SELECT d.id, d.user_id, d.entry_id, d.drawn_at,
+		SELECT d.id, d.user_id, d.entry_id, d.drawn_at, COALESCE(d.assigned_by, ''),
 		       e.name, e.status, e.seed, e.number, e.position
 		FROM draws d
 		JOIN entries e ON d.entry_id = e.id
@@ -1382,15 +1576,18 @@ func handleGetSweepAllDraws(w http.ResponseWriter, r *http.Request) {
 	draws := []map[string]interface{}{}
 	for rows.Next() {
 		var id, entryID int
-		var userID, entryName, entryStatus, drawnAt string
+		var userID, assignedBy, entryName, entryStatus, drawnAt string
 		var seed, number, position sql.NullInt64
-		if err := rows.Scan(&id, &userID, &entryID, &drawnAt, &entryName, &entryStatus, &seed, &number, &position); err != nil {
+		if err := rows.Scan(&id, &userID, &entryID, &drawnAt, &assignedBy, &entryName, &entryStatus, &seed, &number, &position); err != nil {
 			continue
 		}
 		d := map[string]interface{}{
 			"id": id, "user_id": userID, "entry_id": entryID,
 			"drawn_at": drawnAt, "entry_name": entryName, "entry_status": entryStatus,
 		}
+		if assignedBy != "" {
+			d["assigned_by"] = assignedBy
+		}
 		if seed.Valid {
 			d["seed"] = int(seed.Int64)
 		}
@@ -1406,11 +1603,18 @@ func handleGetSweepAllDraws(w http.ResponseWriter, r *http.Request) {
 }
 
 // handleUploadSweepEntries uploads entries for a competition from a CSV file.
-// CSV format: name[, seed_or_number]
+// CSV format: name[, seed_or_number[, guid]]
+// Rows with a guid (e.g. from the entries export) update that entry, so names
+// can be corrected on re-import; rows without one are added by name.
 func handleUploadSweepEntries(w http.ResponseWriter, r *http.Request) {
 	if !requireWritePermission(w, r) {
 		return
 	}
+	file, err := upload.Read(w, r, "file", upload.Sheets)
+	if err != nil {
+		sendError(w, err.Error(), upload.StatusCode(err))
+		return
+	}
 	compID := r.FormValue("competition_id")
 	if compID == "" {
 		sendError(w, "competition_id required", http.StatusBadRequest)
@@ -1423,21 +1627,14 @@ func handleUploadSweepEntries(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	file, _, err := r.FormFile("file")
-	if err != nil {
-		sendError(w, "No file uploaded", http.StatusBadRequest)
-		return
-	}
-	defer file.Close()
-
-	reader := csv.NewReader(file)
+	reader := csv.NewReader(bytes.NewReader(file.Data))
 	records, err := reader.ReadAll()
 	if err != nil {
 		sendError(w, "Invalid CSV", http.StatusBadRequest)
 		return
 	}
 
-	count, skipped := 0, 0
+	count, updated, skipped := 0, 0, 0
 	for i, record := range records {
 		if i == 0 {
 			continue // skip header
@@ -1459,17 +1656,83 @@ func handleUploadSweepEntries(w http.ResponseWriter, r *http.Request) {
 				}
 			}
 		}
+		var guid interface{}
+		if len(record) > 2 && strings.TrimSpace(record[2]) != "" {
+			guid = strings.TrimSpace(record[2])
+			res, err := sweepstakesDB.Exec(`
+				UPDATE entries SET name = $3, seed = $4, number = $5
+				WHERE competition_id = $1 AND guid::text = $2
+			`, compID, guid, name, seed, number)
+			if err != nil {
+				skipped++
+				continue
+			}
+			if n, _ := res.RowsAffected(); n > 0 {
+				updated++
+				continue
+			}
+		}
 		_, err := sweepstakesDB.Exec(`
-			INSERT INTO entries (competition_id, name, seed, number, status) VALUES ($1, $2, $3, $4, 'available')
+			INSERT INTO entries (competition_id, name, seed, number, status, guid)
+			VALUES ($1, $2, $3, $4, 'available', COALESCE($5::uuid, gen_random_uuid()))
 			ON CONFLICT (competition_id, name) DO NOTHING
-		`, compID, name, seed, number)
+		`, compID, name, seed, number, guid)
 		if err != nil {
 			skipped++
 		} else {
 			count++
 		}
 	}
-	sendJSON(w, map[string]interface{}{"uploaded": count, "skipped": skipped})
+	if count > 0 || updated > 0 {
+		publishSweepAvailability(compID)
+	}
+	sendJSON(w, map[string]interface{}{"uploaded": count, "updated": updated, "skipped": skipped})
+}
+
+// handleExportSweepEntries downloads a competition's entries as CSV in the
+// upload format, with each entry's GUID so a re-upload elsewhere matches it.
+func handleExportSweepEntries(w http.ResponseWriter, r *http.Request) {
+	compID := mux.Vars(r)["id"]
+	var compType string
+	if err := sweepstakesDB.QueryRow(`SELECT type FROM competitions WHERE id = $1`, compID).Scan(&compType); err != nil {
+		sendError(w, "Competition not found", http.StatusNotFound)
+		return
+	}
+
+	rows, err := sweepstakesDB.Query(`
+		SELECT guid::text, name, seed, number
+		FROM entries WHERE competition_id = $1
+		ORDER BY COALESCE(seed, 999), COALESCE(number, 999), name
+	`, compID)
+	if err != nil {
+		sendError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="sweepstakes-%s-entries.csv"`, compID))
+	cw := csv.NewWriter(w)
+	column := "number"
+	if compType == "knockout" {
+		column = "seed"
+	}
+	cw.Write([]string{"name", column, "guid"})
+	for rows.Next() {
+		var guid, name string
+		var seed, number sql.NullInt64
+		if err := rows.Scan(&guid, &name, &seed, &number); err != nil {
+			continue
+		}
+		n := ""
+		if compType == "knockout" && seed.Valid {
+			n = strconv.FormatInt(seed.Int64, 10)
+		} else if compType != "knockout" && number.Valid {
+			n = strconv.FormatInt(number.Int64, 10)
+		}
+		cw.Write([]string{name, n, guid})
+	}
+	cw.Flush()
 }
 
 // handleUpdateSweepEntry updates an entry's status or position.
@@ -1486,13 +1749,24 @@ func handleUpdateSweepEntry(w http.ResponseWriter, r *http.Request) {
 		sendError(w, "Invalid request", http.StatusBadRequest)
 		return
 	}
-	_, err := sweepstakesDB.Exec(`
+	var compID, status string
+	err := sweepstakesDB.QueryRow(`
 		UPDATE entries SET status = COALESCE(NULLIF($1, ''), status), position = $2 WHERE id = $3
-	`, req.Status, req.Position, id)
+		RETURNING competition_id, status
+	`, req.Status, req.Position, id).Scan(&compID, &status)
+	if err == sql.ErrNoRows {
+		sendError(w, "Entry not found", http.StatusNotFound)
+		return
+	}
 	if err != nil {
 		sendError(w, "Failed to update entry: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
+	entryID, _ := strconv.Atoi(id)
+	publishSweepEvent(compID, "position", map[string]interface{}{
+		"entry_id": entryID, "position": req.Position, "entry_status": status,
+	})
+	publishSweepAvailability(compID)
 	w.WriteHeader(http.StatusOK)
 }
 
@@ -1517,6 +1791,9 @@ func handleUpdateSweepPosition(w http.ResponseWriter, r *http.Request) {
 		sendError(w, "Failed to update position: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
+	publishSweepEvent(compID, "position", map[string]interface{}{
+		"entry_id": req.EntryID, "position": req.Position,
+	})
 	w.WriteHeader(http.StatusOK)
 }
 
@@ -1533,10 +1810,272 @@ func handleDeleteSweepEntry(w http.ResponseWriter, r *http.Request) {
 		sendError(w, "Cannot delete an entry that has been drawn", http.StatusBadRequest)
 		return
 	}
-	_, err := sweepstakesDB.Exec(`DELETE FROM entries WHERE id = $1`, id)
-	if err != nil {
+	var compID string
+	err := sweepstakesDB.QueryRow(`DELETE FROM entries WHERE id = $1 RETURNING competition_id`, id).Scan(&compID)
+	if err != nil && err != sql.ErrNoRows {
 		sendError(w, "Failed to delete entry: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
+	if compID != "" {
+		publishSweepAvailability(compID)
+	}
 	w.WriteHeader(http.StatusOK)
 }
+
+// sweepDrawPayload loads a draw in the shape the sweepstakes app streams to players.
+func sweepDrawPayload(drawID int) (map[string]interface{}, error) {
+	var compID, entryID int
+	var userID, drawnAt, entryName, entryStatus string
+	var assigned bool
+	var seed, number, position sql.NullInt64
+	err := sweepstakesDB.QueryRow(`
+		SELECT d.competition_id, d.user_id, d.entry_id, d.drawn_at, d.assigned_by IS NOT NULL,
+		       e.name, e.status, e.seed, e.number, e.position
+		FROM draws d
+		JOIN entries e ON d.entry_id = e.id
+		WHERE d.id = $1
+	`, drawID).Scan(&compID, &userID, &entryID, &drawnAt, &assigned, &entryName, &entryStatus, &seed, &number, &position)
+	if err != nil {
+		return nil, err
+	}
+	d := map[string]interface{}{
+		"id": drawID, "user_id": userID, "competition_id": compID, "entry_id": entryID,
+		"drawn_at": drawnAt, "assigned": assigned, "entry_name": entryName, "entry_status": entryStatus,
+	}
+	if seed.Valid {
+		d["seed"] = int(seed.Int64)
+	}
+	if number.Valid {
+		d["number"] = int(number.Int64)
+	}
+	if position.Valid {
+		d["position"] = int(position.Int64)
+	}
+	return d, nil
+}
+
+// publishSweepDraw streams a new or changed draw to players watching the competition.
+func publishSweepDraw(compID string, drawID int) {
+	d, err := sweepDrawPayload(drawID)
+	if err != nil {
+		log.Printf("Error loading sweepstakes draw %d for publish: %v", drawID, err)
+		return
+	}
+	publishSweepEvent(compID, "draw", d)
+}
+
+// handleVoidSweepDraw deletes a mistaken draw and returns its entry to the pool.
+// The player may pick again.
+func handleVoidSweepDraw(w http.ResponseWriter, r *http.Request) {
+	if !requireWritePermission(w, r) {
+		return
+	}
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if !decodeCorrectionReason(w, r, &req, &req.Reason) {
+		return
+	}
+	id := mux.Vars(r)["id"]
+
+	tx, err := sweepstakesDB.Begin()
+	if err != nil {
+		sendError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	var drawID, entryID int
+	var compID, userID, entryName string
+	err = tx.QueryRow(`
+		DELETE FROM draws d USING entries e
+		WHERE d.id = $1 AND e.id = d.entry_id
+		RETURNING d.id, d.competition_id, d.user_id, d.entry_id, e.name
+	`, id).Scan(&drawID, &compID, &userID, &entryID, &entryName)
+	if err == sql.ErrNoRows {
+		sendError(w, "Draw not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		sendError(w, "Failed to void draw: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if _, err := tx.Exec(`UPDATE entries SET status = 'available' WHERE id = $1`, entryID); err != nil {
+		sendError(w, "Failed to release entry: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		sendError(w, "Failed to void draw: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	publishSweepEvent(compID, "void", map[string]int{"id": drawID, "entry_id": entryID})
+	publishSweepAvailability(compID)
+	logAudit(r, "sweep_draw_void", id, map[string]interface{}{
+		"competition_id": compID, "user_id": userID, "entry_id": entryID, "entry_name": entryName, "reason": req.Reason,
+	})
+	log.Printf("🚫 Sweepstakes draw %s (%s → %s) voided by %s", id, userID, entryName, r.Header.Get("X-Admin-Email"))
+	sendJSON(w, map[string]bool{"success": true})
+}
+
+// handleAssignSweepDraw gives a player a specific available entry, e.g. for
+// someone who paid at the bar but couldn't pick on their phone.
+func handleAssignSweepDraw(w http.ResponseWriter, r *http.Request) {
+	if !requireWritePermission(w, r) {
+		return
+	}
+	var req struct {
+		UserID  string `json:"user_id"`
+		EntryID int    `json:"entry_id"`
+		Reason  string `json:"reason"`
+	}
+	if !decodeCorrectionReason(w, r, &req, &req.Reason) {
+		return
+	}
+	req.UserID = strings.TrimSpace(req.UserID)
+	if req.UserID == "" || req.EntryID == 0 {
+		sendError(w, "user_id and entry_id are required", http.StatusBadRequest)
+		return
+	}
+	compID := mux.Vars(r)["id"]
+
+	tx, err := sweepstakesDB.Begin()
+	if err != nil {
+		sendError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	var entryName, entryStatus string
+	err = tx.QueryRow(`
+		SELECT name, status FROM entries WHERE id = $1 AND competition_id = $2 FOR UPDATE
+	`, req.EntryID, compID).Scan(&entryName, &entryStatus)
+	if err == sql.ErrNoRows {
+		sendError(w, "Entry not found in this competition", http.StatusNotFound)
+		return
+	} else if err != nil {
+		sendError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if entryStatus != "available" {
+		sendError(w, "Entry has already been drawn — void that draw first", http.StatusConflict)
+		return
+	}
+
+	var existing int
+	tx.QueryRow(`SELECT COUNT(*) FROM draws WHERE user_id = $1 AND competition_id = $2`, req.UserID, compID).Scan(&existing)
+	if existing > 0 {
+		sendError(w, "Player already has a draw in this competition — void or redraw it instead", http.StatusConflict)
+		return
+	}
+
+	var drawID int
+	err = tx.QueryRow(`
+		INSERT INTO draws (user_id, competition_id, entry_id, assigned_by) VALUES ($1, $2, $3, $4) RETURNING id
+	`, req.UserID, compID, req.EntryID, actorEmail(r)).Scan(&drawID)
+	if err != nil {
+		sendError(w, "Failed to assign entry: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if _, err := tx.Exec(`UPDATE entries SET status = 'taken' WHERE id = $1`, req.EntryID); err != nil {
+		sendError(w, "Failed to assign entry: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		sendError(w, "Failed to assign entry: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	publishSweepDraw(compID, drawID)
+	publishSweepAvailability(compID)
+	logAudit(r, "sweep_draw_assign", strconv.Itoa(drawID), map[string]interface{}{
+		"competition_id": compID, "user_id": req.UserID, "entry_id": req.EntryID, "entry_name": entryName, "reason": req.Reason,
+	})
+	log.Printf("✏️ Sweepstakes entry %s assigned to %s by %s", entryName, req.UserID, r.Header.Get("X-Admin-Email"))
+	sendJSON(w, map[string]interface{}{"id": drawID, "entry_name": entryName})
+}
+
+// handleRedrawSweepDraw swaps a draw's entry for a random available one. The
+// old entry goes back into the pool.
+func handleRedrawSweepDraw(w http.ResponseWriter, r *http.Request) {
+	if !requireWritePermission(w, r) {
+		return
+	}
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if !decodeCorrectionReason(w, r, &req, &req.Reason) {
+		return
+	}
+	id := mux.Vars(r)["id"]
+
+	tx, err := sweepstakesDB.Begin()
+	if err != nil {
+		sendError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	var drawID, oldEntryID int
+	var compID, userID string
+	err = tx.QueryRow(`
+		SELECT id, competition_id, user_id, entry_id FROM draws WHERE id = $1 FOR UPDATE
+	`, id).Scan(&drawID, &compID, &userID, &oldEntryID)
+	if err == sql.ErrNoRows {
+		sendError(w, "Draw not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		sendError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	var newEntryID int
+	err = tx.QueryRow(`
+		SELECT id FROM entries
+		WHERE competition_id = $1 AND status = 'available'
+		ORDER BY RANDOM() LIMIT 1
+		FOR UPDATE
+	`, compID).Scan(&newEntryID)
+	if err == sql.ErrNoRows {
+		sendError(w, "No available entries to redraw from", http.StatusBadRequest)
+		return
+	} else if err != nil {
+		sendError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	if _, err := tx.Exec(`UPDATE entries SET status = 'available' WHERE id = $1`, oldEntryID); err != nil {
+		sendError(w, "Failed to redraw: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if _, err := tx.Exec(`UPDATE entries SET status = 'taken' WHERE id = $1`, newEntryID); err != nil {
+		sendError(w, "Failed to redraw: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if _, err := tx.Exec(`
+		UPDATE draws SET entry_id = $1, drawn_at = CURRENT_TIMESTAMP, assigned_by = $2 WHERE id = $3
+	`, newEntryID, actorEmail(r), drawID); err != nil {
+		sendError(w, "Failed to redraw: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		sendError(w, "Failed to redraw: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Players drop the old pairing, then see the new one
+	publishSweepEvent(compID, "void", map[string]int{"id": drawID, "entry_id": oldEntryID})
+	publishSweepDraw(compID, drawID)
+	publishSweepAvailability(compID)
+
+	var oldName, newName string
+	sweepstakesDB.QueryRow(`SELECT name FROM entries WHERE id = $1`, oldEntryID).Scan(&oldName)
+	sweepstakesDB.QueryRow(`SELECT name FROM entries WHERE id = $1`, newEntryID).Scan(&newName)
+	logAudit(r, "sweep_draw_redraw", id, map[string]interface{}{
+		"competition_id": compID, "user_id": userID,
+		"old_entry_id": oldEntryID, "old_entry_name": oldName,
+		"new_entry_id": newEntryID, "new_entry_name": newName,
+		"reason": req.Reason,
+	})
+	log.Printf("🔄 Sweepstakes draw %s redrawn for %s (%s → %s) by %s", id, userID, oldName, newName, r.Header.Get("X-Admin-Email"))
+	sendJSON(w, map[string]interface{}{"id": drawID, "entry_name": newName})
+}
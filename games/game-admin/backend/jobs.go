@@ -0,0 +1,49 @@
+package main
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/achgithub/activity-hub-common/jobs"
+	redislib "github.com/achgithub/activity-hub-common/redis"
+	"github.com/gorilla/mux"
+)
+
+// ============================================================
+// Background jobs
+// ============================================================
+//
+// Work too slow for a request (processing an LMS round) runs as a background
+// task on the shared job runner. The request returns a job ID at once and the
+// UI polls GET /api/jobs/{id} for progress and the result.
+
+// jobRunner is nil when Redis is unavailable; background work is refused
+var jobRunner *jobs.Scheduler
+
+func initJobs() {
+	client, err := redislib.InitRedis()
+	if err != nil {
+		log.Printf("Warning: background jobs unavailable: %v", err)
+		return
+	}
+	jobRunner = jobs.New(client, "game-admin")
+}
+
+// handleGetJob - GET /api/jobs/{id}
+func handleGetJob(w http.ResponseWriter, r *http.Request) {
+	if jobRunner == nil {
+		sendError(w, "Background jobs are unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	status, err := jobRunner.Task(r.Context(), mux.Vars(r)["id"])
+	if err == jobs.ErrTaskNotFound {
+		sendError(w, "Job not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Printf("Failed to load job status: %v", err)
+		sendError(w, "Failed to load job", http.StatusInternalServerError)
+		return
+	}
+	sendJSON(w, status)
+}
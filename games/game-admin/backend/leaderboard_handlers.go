@@ -0,0 +1,381 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// ============================================================
+// Leaderboard dispute review handlers
+// ============================================================
+//
+// Players flag results through the leaderboard app (result_disputes).
+// Standings are calculated from game_results on every read, so voiding or
+// correcting a row here is all that's needed to recalculate them. Each change
+// runs in one transaction with its before/after snapshot and dispute resolution.
+// Resolving the disputes on a disputed self-reported result confirms it.
+
+// handleGetLeaderboardDisputes returns the dispute review queue.
+// ?status=open (default) | upheld | rejected | all
+func handleGetLeaderboardDisputes(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = "open"
+	}
+
+	rows, err := leaderboardDB.Query(`
+		SELECT d.id, d.result_id, d.raised_by, d.reason, d.status, COALESCE(d.resolution, ''),
+		       COALESCE(d.resolved_by, ''), d.created_at,
+		       g.game_id, g.game_type, COALESCE(g.winner_name, ''), COALESCE(g.loser_name, ''),
+		       g.is_draw, COALESCE(g.score, ''), g.voided, g.played_at,
+		       (g.winner_team_id IS NOT NULL) AS is_team, g.confirmation
+		FROM result_disputes d
+		JOIN game_results g ON g.id = d.result_id
+		WHERE $1 = 'all' OR d.status = $1
+		ORDER BY d.created_at
+		LIMIT 200
+	`, status)
+	if err != nil {
+		sendError(w, "Failed to load disputes", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	type Dispute struct {
+		ID           int    `json:"id"`
+		ResultID     int    `json:"resultId"`
+		RaisedBy     string `json:"raisedBy"`
+		Reason       string `json:"reason"`
+		Status       string `json:"status"`
+		Resolution   string `json:"resolution"`
+		ResolvedBy   string `json:"resolvedBy"`
+		CreatedAt    string `json:"createdAt"`
+		GameID       string `json:"gameId"`
+		GameType     string `json:"gameType"`
+		WinnerName   string `json:"winnerName"`
+		LoserName    string `json:"loserName"`
+		IsDraw       bool   `json:"isDraw"`
+		Score        string `json:"score"`
+		Voided       bool   `json:"voided"`
+		PlayedAt     string `json:"playedAt"`
+		IsTeam       bool   `json:"isTeam"`
+		Confirmation string `json:"confirmation"` // confirmed, provisional or disputed
+	}
+	disputes := []Dispute{}
+	for rows.Next() {
+		var d Dispute
+		if err := rows.Scan(&d.ID, &d.ResultID, &d.RaisedBy, &d.Reason, &d.Status, &d.Resolution,
+			&d.ResolvedBy, &d.CreatedAt, &d.GameID, &d.GameType, &d.WinnerName, &d.LoserName,
+			&d.IsDraw, &d.Score, &d.Voided, &d.PlayedAt, &d.IsTeam, &d.Confirmation); err != nil {
+			continue
+		}
+		disputes = append(disputes, d)
+	}
+	sendJSON(w, map[string]interface{}{"disputes": disputes})
+}
+
+// handleGetLeaderboardCorrections returns the correction history for a result.
+func handleGetLeaderboardCorrections(w http.ResponseWriter, r *http.Request) {
+	rows, err := leaderboardDB.Query(`
+		SELECT id, action, before_state::text, after_state::text, COALESCE(reason, ''), corrected_by, created_at
+		FROM result_corrections
+		WHERE result_id = $1
+		ORDER BY created_at DESC
+	`, mux.Vars(r)["id"])
+	if err != nil {
+		sendError(w, "Failed to load corrections", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	type Correction struct {
+		ID          int             `json:"id"`
+		Action      string          `json:"action"`
+		Before      json.RawMessage `json:"before"`
+		After       json.RawMessage `json:"after"`
+		Reason      string          `json:"reason"`
+		CorrectedBy string          `json:"correctedBy"`
+		CreatedAt   string          `json:"createdAt"`
+	}
+	corrections := []Correction{}
+	for rows.Next() {
+		var c Correction
+		var before, after string
+		if err := rows.Scan(&c.ID, &c.Action, &before, &after, &c.Reason, &c.CorrectedBy, &c.CreatedAt); err != nil {
+			continue
+		}
+		c.Before = json.RawMessage(before)
+		c.After = json.RawMessage(after)
+		corrections = append(corrections, c)
+	}
+	sendJSON(w, map[string]interface{}{"corrections": corrections})
+}
+
+var errResultNotFound = errors.New("result not found")
+
+// applyResultChange runs an UPDATE on one game_results row, records the
+// before/after snapshot and upholds any open disputes — all in one transaction.
+func applyResultChange(resultID int, action, reason, adminEmail, update string, args ...interface{}) error {
+	tx, err := leaderboardDB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var before string
+	err = tx.QueryRow(`SELECT row_to_json(g)::text FROM game_results g WHERE g.id = $1 FOR UPDATE`, resultID).Scan(&before)
+	if err == sql.ErrNoRows {
+		return errResultNotFound
+	} else if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(update, append([]interface{}{resultID}, args...)...); err != nil {
+		return err
+	}
+	if err := confirmDisputedResult(tx, resultID); err != nil {
+		return err
+	}
+
+	var after string
+	if err := tx.QueryRow(`SELECT row_to_json(g)::text FROM game_results g WHERE g.id = $1`, resultID).Scan(&after); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO result_corrections (result_id, action, before_state, after_state, reason, corrected_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, resultID, action, before, after, reason, adminEmail); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE result_disputes
+		SET status = 'upheld', resolution = $2, resolved_by = $3, resolved_at = NOW()
+		WHERE result_id = $1 AND status = 'open'
+	`, resultID, action+": "+reason, adminEmail); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// confirmDisputedResult confirms a disputed self-reported result once an
+// admin has dealt with it.
+func confirmDisputedResult(tx *sql.Tx, resultID int) error {
+	_, err := tx.Exec(`
+		UPDATE game_results SET confirmation = 'confirmed', confirmed_at = NOW()
+		WHERE id = $1 AND confirmation = 'disputed'
+	`, resultID)
+	return err
+}
+
+// resultIDParam parses the {id} route variable.
+func resultIDParam(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		sendError(w, "Invalid result id", http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+// sendResultChangeError maps applyResultChange errors to responses.
+func sendResultChangeError(w http.ResponseWriter, action string, resultID int, err error) {
+	if err == errResultNotFound {
+		sendError(w, "Result not found", http.StatusNotFound)
+		return
+	}
+	log.Printf("Failed to %s result %d: %v", action, resultID, err)
+	sendError(w, "Failed to "+action+" result: "+err.Error(), http.StatusInternalServerError)
+}
+
+// decodeCorrectionReason reads a request body and requires a reason.
+func decodeCorrectionReason(w http.ResponseWriter, r *http.Request, req interface{}, reason *string) bool {
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		sendError(w, "Invalid request", http.StatusBadRequest)
+		return false
+	}
+	*reason = strings.TrimSpace(*reason)
+	if *reason == "" {
+		sendError(w, "reason is required", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// handleVoidLeaderboardResult removes a result from all standings.
+func handleVoidLeaderboardResult(w http.ResponseWriter, r *http.Request) {
+	if !requireWritePermission(w, r) {
+		return
+	}
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if !decodeCorrectionReason(w, r, &req, &req.Reason) {
+		return
+	}
+
+	resultID, ok := resultIDParam(w, r)
+	if !ok {
+		return
+	}
+	id := strconv.Itoa(resultID)
+
+	err := applyResultChange(resultID, "void", req.Reason, actorEmail(r),
+		`UPDATE game_results SET voided = TRUE, voided_reason = $2 WHERE id = $1`, req.Reason)
+	if err != nil {
+		sendResultChangeError(w, "void", resultID, err)
+		return
+	}
+
+	logAudit(r, "leaderboard_void_result", id, map[string]interface{}{"reason": req.Reason})
+	log.Printf("🚫 Leaderboard result %s voided by %s", id, r.Header.Get("X-Admin-Email"))
+	sendJSON(w, map[string]bool{"success": true})
+}
+
+// handleRestoreLeaderboardResult puts a voided result back into the standings.
+func handleRestoreLeaderboardResult(w http.ResponseWriter, r *http.Request) {
+	if !requireWritePermission(w, r) {
+		return
+	}
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if !decodeCorrectionReason(w, r, &req, &req.Reason) {
+		return
+	}
+
+	resultID, ok := resultIDParam(w, r)
+	if !ok {
+		return
+	}
+	id := strconv.Itoa(resultID)
+
+	err := applyResultChange(resultID, "restore", req.Reason, actorEmail(r),
+		`UPDATE game_results SET voided = FALSE, voided_reason = NULL WHERE id = $1`)
+	if err != nil {
+		sendResultChangeError(w, "restore", resultID, err)
+		return
+	}
+
+	logAudit(r, "leaderboard_restore_result", id, map[string]interface{}{"reason": req.Reason})
+	sendJSON(w, map[string]bool{"success": true})
+}
+
+// handleCorrectLeaderboardResult fixes a recorded outcome: swap winner and loser
+// (players or teams), change draw status and/or the score.
+func handleCorrectLeaderboardResult(w http.ResponseWriter, r *http.Request) {
+	if !requireWritePermission(w, r) {
+		return
+	}
+	var req struct {
+		SwapSides bool    `json:"swapSides"`
+		IsDraw    *bool   `json:"isDraw"`
+		Score     *string `json:"score"`
+		Reason    string  `json:"reason"`
+	}
+	if !decodeCorrectionReason(w, r, &req, &req.Reason) {
+		return
+	}
+	if !req.SwapSides && req.IsDraw == nil && req.Score == nil {
+		sendError(w, "Nothing to correct", http.StatusBadRequest)
+		return
+	}
+
+	resultID, ok := resultIDParam(w, r)
+	if !ok {
+		return
+	}
+	id := strconv.Itoa(resultID)
+
+	// Postgres evaluates every SET expression against the old row, so the swap is safe
+	err := applyResultChange(resultID, "correct", req.Reason, actorEmail(r), `
+		UPDATE game_results SET
+			winner_id      = CASE WHEN $2 THEN loser_id ELSE winner_id END,
+			winner_name    = CASE WHEN $2 THEN loser_name ELSE winner_name END,
+			loser_id       = CASE WHEN $2 THEN winner_id ELSE loser_id END,
+			loser_name     = CASE WHEN $2 THEN winner_name ELSE loser_name END,
+			winner_team_id = CASE WHEN $2 THEN loser_team_id ELSE winner_team_id END,
+			loser_team_id  = CASE WHEN $2 THEN winner_team_id ELSE loser_team_id END,
+			is_draw        = COALESCE($3, is_draw),
+			score          = COALESCE($4, score)
+		WHERE id = $1
+	`, req.SwapSides, req.IsDraw, req.Score)
+	if err != nil {
+		sendResultChangeError(w, "correct", resultID, err)
+		return
+	}
+
+	logAudit(r, "leaderboard_correct_result", id, map[string]interface{}{
+		"reason":    req.Reason,
+		"swapSides": req.SwapSides,
+		"isDraw":    req.IsDraw,
+		"score":     req.Score,
+	})
+	log.Printf("✏️ Leaderboard result %s corrected by %s", id, r.Header.Get("X-Admin-Email"))
+	sendJSON(w, map[string]bool{"success": true})
+}
+
+// handleRejectLeaderboardDispute closes a dispute without changing the result.
+func handleRejectLeaderboardDispute(w http.ResponseWriter, r *http.Request) {
+	if !requireWritePermission(w, r) {
+		return
+	}
+	var req struct {
+		Resolution string `json:"resolution"`
+	}
+	if !decodeCorrectionReason(w, r, &req, &req.Resolution) {
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+	tx, err := leaderboardDB.Begin()
+	if err != nil {
+		sendError(w, "Failed to reject dispute: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	var resultID int
+	err = tx.QueryRow(`
+		UPDATE result_disputes
+		SET status = 'rejected', resolution = $2, resolved_by = $3, resolved_at = NOW()
+		WHERE id = $1 AND status = 'open'
+		RETURNING result_id
+	`, id, req.Resolution, actorEmail(r)).Scan(&resultID)
+	if err == sql.ErrNoRows {
+		sendError(w, "Dispute not found or already resolved", http.StatusNotFound)
+		return
+	} else if err != nil {
+		sendError(w, "Failed to reject dispute: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// The result stands; confirm it once no other dispute is open
+	var stillOpen bool
+	if err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM result_disputes WHERE result_id = $1 AND status = 'open')`, resultID).Scan(&stillOpen); err != nil {
+		sendError(w, "Failed to reject dispute: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !stillOpen {
+		if err := confirmDisputedResult(tx, resultID); err != nil {
+			sendError(w, "Failed to reject dispute: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		sendError(w, "Failed to reject dispute: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	logAudit(r, "leaderboard_reject_dispute", id, map[string]interface{}{"resolution": req.Resolution})
+	sendJSON(w, map[string]bool{"success": true})
+}
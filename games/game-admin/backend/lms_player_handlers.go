@@ -0,0 +1,307 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/gorilla/mux"
+)
+
+// --- LMS Player Management ---
+//
+// game_players holds who is in each LMS game; user_id is the player's email.
+// Admins can take a player out of a game, put an eliminated player back in,
+// and move a player who signed up under the wrong email onto the right one.
+
+// lmsPlayer is a player in an LMS game as admins see them
+type lmsPlayer struct {
+	UserID            string    `json:"userId"`
+	Name              string    `json:"name,omitempty"`
+	IsActive          bool      `json:"isActive"`
+	JoinedAt          time.Time `json:"joinedAt"`
+	Picks             int       `json:"picks"`
+	EliminatedInRound *int      `json:"eliminatedInRound,omitempty"` // Label of the round of their last losing pick
+}
+
+// handleGetLMSPlayers lists a game's players, still-in players first.
+func handleGetLMSPlayers(w http.ResponseWriter, r *http.Request) {
+	rows, err := lmsDB.Query(`
+		SELECT gp.user_id, gp.is_active, gp.joined_at,
+		       (SELECT COUNT(*) FROM predictions p WHERE p.game_id = gp.game_id AND p.user_id = gp.user_id),
+		       (SELECT MAX(rnd.label) FROM predictions p JOIN rounds rnd ON rnd.id = p.round_id
+		        WHERE p.game_id = gp.game_id AND p.user_id = gp.user_id AND p.is_correct = FALSE)
+		FROM game_players gp
+		WHERE gp.game_id = $1
+		ORDER BY gp.is_active DESC, gp.joined_at
+	`, mux.Vars(r)["id"])
+	if err != nil {
+		log.Printf("Error getting LMS players: %v", err)
+		sendError(w, "Failed to get players", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	players := []lmsPlayer{}
+	emails := []string{}
+	for rows.Next() {
+		var p lmsPlayer
+		var eliminatedIn sql.NullInt64
+		if err := rows.Scan(&p.UserID, &p.IsActive, &p.JoinedAt, &p.Picks, &eliminatedIn); err != nil {
+			continue
+		}
+		if eliminatedIn.Valid && !p.IsActive {
+			v := int(eliminatedIn.Int64)
+			p.EliminatedInRound = &v
+		}
+		players = append(players, p)
+		emails = append(emails, p.UserID)
+	}
+
+	profiles, _ := authlib.LoadProfiles(identityDB, emails)
+	for i := range players {
+		players[i].Name = profiles[players[i].UserID].Name
+	}
+	sendJSON(w, map[string]interface{}{"players": players})
+}
+
+// handleRemoveLMSPlayer takes a player out of a game along with their picks,
+// e.g. someone who joined the wrong game. Optional ?reason= for the audit log.
+func handleRemoveLMSPlayer(w http.ResponseWriter, r *http.Request) {
+	if !requireWritePermission(w, r) {
+		return
+	}
+	vars := mux.Vars(r)
+	gameID, userID := vars["id"], vars["userId"]
+
+	tx, err := lmsDB.Begin()
+	if err != nil {
+		log.Printf("Error starting player removal: %v", err)
+		sendError(w, "Failed to remove player", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`DELETE FROM game_players WHERE game_id = $1 AND user_id = $2`, gameID, userID)
+	if err != nil {
+		log.Printf("Error removing LMS player: %v", err)
+		sendError(w, "Failed to remove player", http.StatusInternalServerError)
+		return
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		sendError(w, "Player not found in this game", http.StatusNotFound)
+		return
+	}
+	res, err = tx.Exec(`DELETE FROM predictions WHERE game_id = $1 AND user_id = $2`, gameID, userID)
+	if err != nil {
+		log.Printf("Error removing LMS player's picks: %v", err)
+		sendError(w, "Failed to remove player", http.StatusInternalServerError)
+		return
+	}
+	picks, _ := res.RowsAffected()
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing player removal: %v", err)
+		sendError(w, "Failed to remove player", http.StatusInternalServerError)
+		return
+	}
+
+	logAudit(r, "lms_player_remove", gameID+"/"+userID, map[string]interface{}{
+		"picksRemoved": picks, "reason": strings.TrimSpace(r.URL.Query().Get("reason")),
+	})
+	sendJSON(w, map[string]interface{}{"success": true, "picksRemoved": picks})
+}
+
+// handleReinstateLMSPlayer puts an eliminated player back in a game, e.g. after
+// a result was corrected. Their past picks are left as they are, so the team
+// they lost with stays used. A reason is required and audit-logged.
+func handleReinstateLMSPlayer(w http.ResponseWriter, r *http.Request) {
+	if !requireWritePermission(w, r) {
+		return
+	}
+	vars := mux.Vars(r)
+	gameID, userID := vars["id"], vars["userId"]
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" {
+		sendError(w, "reason is required", http.StatusBadRequest)
+		return
+	}
+
+	var isActive bool
+	var gameStatus string
+	err := lmsDB.QueryRow(`
+		SELECT gp.is_active, g.status
+		FROM game_players gp
+		JOIN games g ON g.id = gp.game_id
+		WHERE gp.game_id = $1 AND gp.user_id = $2 AND g.deleted_at IS NULL
+	`, gameID, userID).Scan(&isActive, &gameStatus)
+	if err == sql.ErrNoRows {
+		sendError(w, "Player not found in this game", http.StatusNotFound)
+		return
+	} else if err != nil {
+		sendError(w, "Failed to load player", http.StatusInternalServerError)
+		return
+	}
+	if isActive {
+		sendError(w, "Player is still in the game", http.StatusConflict)
+		return
+	}
+	if gameStatus != "active" {
+		sendError(w, "Players can only be reinstated in an active game", http.StatusConflict)
+		return
+	}
+
+	if _, err := lmsDB.Exec(`UPDATE game_players SET is_active = TRUE WHERE game_id = $1 AND user_id = $2`, gameID, userID); err != nil {
+		log.Printf("Error reinstating LMS player: %v", err)
+		sendError(w, "Failed to reinstate player", http.StatusInternalServerError)
+		return
+	}
+
+	logAudit(r, "lms_player_reinstate", gameID+"/"+userID, map[string]interface{}{"reason": req.Reason})
+	sendJSON(w, map[string]interface{}{"success": true})
+}
+
+// handleMergeLMSPlayers moves everything one user ID has in LMS onto another,
+// for a player who joined with the wrong email. In a game both IDs joined,
+// the target keeps its own picks and gains the source's picks for rounds it
+// has none in; it stays in the game if either ID was still in.
+// POST /api/lms/players/merge {"fromUserId": "...", "toUserId": "...", "reason": "..."}
+func handleMergeLMSPlayers(w http.ResponseWriter, r *http.Request) {
+	if !requireWritePermission(w, r) {
+		return
+	}
+
+	var req struct {
+		FromUserID string `json:"fromUserId"`
+		ToUserID   string `json:"toUserId"`
+		Reason     string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	from := strings.TrimSpace(req.FromUserID)
+	to := strings.TrimSpace(req.ToUserID)
+	if from == "" || to == "" {
+		sendError(w, "fromUserId and toUserId are required", http.StatusBadRequest)
+		return
+	}
+	if from == to {
+		sendError(w, "Cannot merge a user into themselves", http.StatusBadRequest)
+		return
+	}
+
+	// The target must be a real account; the source may not be (a mistyped email)
+	profiles, err := authlib.LoadProfiles(identityDB, []string{to})
+	if err != nil {
+		log.Printf("Error checking merge target: %v", err)
+		sendError(w, "Failed to check user", http.StatusInternalServerError)
+		return
+	}
+	if _, ok := profiles[to]; !ok {
+		sendError(w, "No account for "+to, http.StatusBadRequest)
+		return
+	}
+
+	res, err := mergeLMSPlayers(from, to)
+	if err != nil {
+		log.Printf("Error merging LMS player %s into %s: %v", from, to, err)
+		sendError(w, "Failed to merge players", http.StatusInternalServerError)
+		return
+	}
+	if res.empty() {
+		sendError(w, "No LMS games found for "+from, http.StatusNotFound)
+		return
+	}
+
+	details := res.details()
+	details["reason"] = strings.TrimSpace(req.Reason)
+	logAudit(r, "lms_player_merge", from+" -> "+to, details)
+
+	out := res.details()
+	out["success"] = true
+	sendJSON(w, out)
+}
+
+// lmsMergeResult counts what mergeLMSPlayers moved
+type lmsMergeResult struct {
+	Games, SharedGames, PicksMoved, PicksDropped int64
+}
+
+func (m lmsMergeResult) empty() bool {
+	return m.Games == 0 && m.PicksMoved+m.PicksDropped == 0
+}
+
+func (m lmsMergeResult) details() map[string]interface{} {
+	return map[string]interface{}{
+		"games": m.Games, "sharedGames": m.SharedGames, "picksMoved": m.PicksMoved, "picksDropped": m.PicksDropped,
+	}
+}
+
+// mergeLMSPlayers moves everything from has in LMS onto to in one
+// transaction. Running it again finds nothing left to move.
+func mergeLMSPlayers(from, to string) (lmsMergeResult, error) {
+	var m lmsMergeResult
+	tx, err := lmsDB.Begin()
+	if err != nil {
+		return m, err
+	}
+	defer tx.Rollback()
+
+	// Games both IDs are in: the target keeps its row, active if either was
+	res, err := tx.Exec(`
+		UPDATE game_players t
+		SET is_active = t.is_active OR f.is_active, joined_at = LEAST(t.joined_at, f.joined_at)
+		FROM game_players f
+		WHERE f.game_id = t.game_id AND f.user_id = $1 AND t.user_id = $2
+	`, from, to)
+	if err == nil {
+		m.SharedGames, _ = res.RowsAffected()
+		_, err = tx.Exec(`
+			DELETE FROM game_players f
+			USING game_players t
+			WHERE f.game_id = t.game_id AND f.user_id = $1 AND t.user_id = $2
+		`, from, to)
+	}
+	if err == nil {
+		res, err = tx.Exec(`UPDATE game_players SET user_id = $2 WHERE user_id = $1`, from, to)
+		if err == nil {
+			moved, _ := res.RowsAffected()
+			m.Games = m.SharedGames + moved
+		}
+	}
+
+	// Picks: the target's pick wins where both picked in the same round
+	if err == nil {
+		res, err = tx.Exec(`
+			DELETE FROM predictions f
+			USING predictions t
+			WHERE f.game_id = t.game_id AND f.round_id = t.round_id AND f.user_id = $1 AND t.user_id = $2
+		`, from, to)
+		if err == nil {
+			m.PicksDropped, _ = res.RowsAffected()
+		}
+	}
+	if err == nil {
+		res, err = tx.Exec(`UPDATE predictions SET user_id = $2 WHERE user_id = $1`, from, to)
+		if err == nil {
+			m.PicksMoved, _ = res.RowsAffected()
+		}
+	}
+	if err == nil {
+		err = tx.Commit()
+	}
+	return m, err
+}
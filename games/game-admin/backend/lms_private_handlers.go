@@ -0,0 +1,86 @@
+package main
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/lib/pq"
+)
+
+// Join codes for private LMS games: short enough to read out or paste into a
+// group chat, without look-alike characters (0/O, 1/I/L).
+const (
+	joinCodeAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+	joinCodeLength   = 6
+)
+
+func randomJoinCode() (string, error) {
+	code := make([]byte, joinCodeLength)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(joinCodeAlphabet))))
+		if err != nil {
+			return "", err
+		}
+		code[i] = joinCodeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
+
+// assignJoinCode gives a private game a new join code, replacing any old one.
+// Retries on the rare collision with another game's code.
+func assignJoinCode(gameID int) (string, error) {
+	for attempt := 0; attempt < 5; attempt++ {
+		code, err := randomJoinCode()
+		if err != nil {
+			return "", err
+		}
+		_, err = lmsDB.Exec(`UPDATE games SET join_code = $1 WHERE id = $2 AND is_private = TRUE`, code, gameID)
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		return code, nil
+	}
+	return "", fmt.Errorf("could not allocate a unique join code")
+}
+
+// handleRegenerateJoinCode replaces a private game's join code, e.g. after it
+// was shared too widely. Players already in the game are unaffected.
+func handleRegenerateJoinCode(w http.ResponseWriter, r *http.Request) {
+	if !requireWritePermission(w, r) {
+		return
+	}
+
+	gameID := mux.Vars(r)["id"]
+	var id int
+	var isPrivate bool
+	err := lmsDB.QueryRow(`SELECT id, is_private FROM games WHERE id = $1`, gameID).Scan(&id, &isPrivate)
+	if err == sql.ErrNoRows {
+		sendError(w, "Game not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		sendError(w, "Failed to load game", http.StatusInternalServerError)
+		return
+	}
+	if !isPrivate {
+		sendError(w, "Only private games have join codes", http.StatusBadRequest)
+		return
+	}
+
+	code, err := assignJoinCode(id)
+	if err != nil {
+		log.Printf("Error regenerating join code: %v", err)
+		sendError(w, "Failed to generate join code", http.StatusInternalServerError)
+		return
+	}
+
+	logAudit(r, "lms_game_join_code", gameID, nil)
+	sendJSON(w, map[string]interface{}{"success": true, "joinCode": code})
+}
@@ -5,22 +5,50 @@ import (
 	"log"
 	"net/http"
 
+	"github.com/achgithub/activity-hub-common/analytics"
+	"github.com/achgithub/activity-hub-common/audit"
 	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/achgithub/activity-hub-common/cli"
 	"github.com/achgithub/activity-hub-common/config"
 	"github.com/achgithub/activity-hub-common/database"
-	"github.com/gorilla/handlers"
+	"github.com/achgithub/activity-hub-common/hours"
+	apphttp "github.com/achgithub/activity-hub-common/http"
+	"github.com/achgithub/activity-hub-common/maintenance"
+	"github.com/achgithub/activity-hub-common/upload"
 	"github.com/gorilla/mux"
 )
 
 var (
+	identityDB    *sql.DB // activity_hub — public names for the activity feed, loyalty points, opening hours, usage analytics
 	lmsDB         *sql.DB // last_man_standing_db — used by handlers
 	gameAdminDB   *sql.DB // game_admin_db — used for audit log
 	sweepstakesDB *sql.DB // sweepstakes_db — used by sweepstakes admin handlers
 	quizDB        *sql.DB // quiz_db — used by quiz admin handlers
+	leaderboardDB *sql.DB // leaderboard_db — used by dispute review handlers
 )
 
 func main() {
-	identityDB, err := database.InitIdentityDatabase()
+	cli.New("game-admin",
+		cli.Serve(serve),
+		cli.Migrate(openAppDB, cli.Migrations{
+			Dir:    "../database",
+			Schema: []string{"schema.sql"},
+			Upgrades: []string{
+				"migrate_add_players_groups.sql",
+				"migrate_add_guids.sql",
+			},
+		}),
+	).Main()
+}
+
+// openAppDB connects to game_admin_db for commands other than serve
+func openAppDB() (*sql.DB, error) {
+	return database.InitDatabaseByName("game_admin_db")
+}
+
+func serve() {
+	var err error
+	identityDB, err = database.InitIdentityDatabase()
 	if err != nil {
 		log.Fatal("Failed to connect to identity database:", err)
 	}
@@ -37,6 +65,7 @@ func main() {
 		log.Fatal("Failed to connect to game admin database:", err)
 	}
 	defer gameAdminDB.Close()
+	auditLog = audit.New(gameAdminDB)
 
 	sweepstakesDB, err = database.InitDatabaseByName("sweepstakes_db")
 	if err != nil {
@@ -50,8 +79,33 @@ func main() {
 	}
 	defer quizDB.Close()
 
+	leaderboardDB, err = database.InitDatabaseByName("leaderboard_db")
+	if err != nil {
+		log.Fatal("Failed to connect to leaderboard database:", err)
+	}
+	defer leaderboardDB.Close()
+
+	// Permanently remove trash past the retention period, outside opening hours
+	go runTrashPurge(hours.New(identityDB))
+
+	initRedis()
+	initJobs()
+
+	// Keep LMS entries on one account when the identity shell merges duplicates
+	go runAccountMerges()
+
 	r := mux.NewRouter()
 
+	// Question contributors: submit quiz questions for review. Registered ahead
+	// of the game-admin routes, which would otherwise turn contributors away.
+	contribute := r.PathPrefix("/api/contribute").Subrouter()
+	contribute.Use(authlib.Middleware(identityDB))
+	contribute.Use(requireQuestionContributor)
+	contribute.HandleFunc("/questions", handleGetMySubmissions).Methods("GET")
+	contribute.HandleFunc("/questions", handleSubmitQuestion).Methods("POST")
+	contribute.HandleFunc("/questions/{id}", handleUpdateMySubmission).Methods("PUT")
+	contribute.HandleFunc("/questions/{id}", handleWithdrawSubmission).Methods("DELETE")
+
 	// All API routes: first resolve token, then check game_admin/super_user role
 	api := r.PathPrefix("/api").Subrouter()
 	api.Use(authlib.Middleware(identityDB))
@@ -77,8 +131,15 @@ func main() {
 	api.HandleFunc("/lms/games", handleCreateLMSGame).Methods("POST")
 	api.HandleFunc("/lms/games/{id}/set-current", handleSetCurrentGame).Methods("PUT")
 	api.HandleFunc("/lms/games/{id}/complete", handleCompleteGame).Methods("PUT")
+	api.HandleFunc("/lms/games/{id}/join-code", handleRegenerateJoinCode).Methods("PUT")
 	api.HandleFunc("/lms/games/{id}", handleDeleteGame).Methods("DELETE")
 
+	// LMS player management
+	api.HandleFunc("/lms/games/{id}/players", handleGetLMSPlayers).Methods("GET")
+	api.HandleFunc("/lms/games/{id}/players/{userId}", handleRemoveLMSPlayer).Methods("DELETE")
+	api.HandleFunc("/lms/games/{id}/players/{userId}/reinstate", handleReinstateLMSPlayer).Methods("POST")
+	api.HandleFunc("/lms/players/merge", handleMergeLMSPlayers).Methods("POST")
+
 	// LMS round management
 	api.HandleFunc("/lms/rounds/{gameId}", handleGetLMSRounds).Methods("GET")
 	api.HandleFunc("/lms/rounds", handleCreateRound).Methods("POST")
@@ -90,6 +151,9 @@ func main() {
 	api.HandleFunc("/lms/fixtures", handleGetFixtures).Methods("GET")
 	api.HandleFunc("/lms/fixtures/upload", handleUploadFixture).Methods("POST")
 	api.HandleFunc("/lms/fixtures/{id}/matches", handleGetFixtureMatches).Methods("GET")
+	api.HandleFunc("/lms/fixtures/{id}/versions", handleGetFixtureVersions).Methods("GET")
+	api.HandleFunc("/lms/fixtures/{id}/versions/{version}/diff", handleGetFixtureVersionDiff).Methods("GET")
+	api.HandleFunc("/lms/fixtures/{id}/versions/{version}/apply", handleApplyFixtureVersion).Methods("POST")
 
 	// LMS match management (queries via game → fixture file)
 	api.HandleFunc("/lms/matches/{gameId}", handleGetLMSMatchesForGame).Methods("GET")
@@ -99,6 +163,9 @@ func main() {
 	// LMS round processing (explicit batch evaluation — no auto-process on result entry)
 	api.HandleFunc("/lms/rounds/{gameId}/{label}/process", handleProcessRound).Methods("POST")
 
+	// Background job status (round processing runs as a job)
+	api.HandleFunc("/jobs/{id}", handleGetJob).Methods("GET")
+
 	// LMS predictions (read)
 	api.HandleFunc("/lms/predictions", handleGetAllPredictions).Methods("GET")
 
@@ -108,14 +175,27 @@ func main() {
 	api.HandleFunc("/sweepstakes/competitions/{id}", handleUpdateSweepCompetition).Methods("PUT")
 	api.HandleFunc("/sweepstakes/competitions/{id}", handleDeleteSweepCompetition).Methods("DELETE")
 	api.HandleFunc("/sweepstakes/competitions/{id}/entries", handleGetSweepEntries).Methods("GET")
+	api.HandleFunc("/sweepstakes/competitions/{id}/entries/export", handleExportSweepEntries).Methods("GET")
 	api.HandleFunc("/sweepstakes/competitions/{id}/all-draws", handleGetSweepAllDraws).Methods("GET")
 	api.HandleFunc("/sweepstakes/competitions/{id}/update-position", handleUpdateSweepPosition).Methods("POST")
+	api.HandleFunc("/sweepstakes/competitions/{id}/assign", handleAssignSweepDraw).Methods("POST")
+
+	// Sweepstakes draw administration (corrections, audit-logged)
+	api.HandleFunc("/sweepstakes/draws/{id}/void", handleVoidSweepDraw).Methods("POST")
+	api.HandleFunc("/sweepstakes/draws/{id}/redraw", handleRedrawSweepDraw).Methods("POST")
 
 	// Sweepstakes entry management
 	api.HandleFunc("/sweepstakes/entries/upload", handleUploadSweepEntries).Methods("POST")
 	api.HandleFunc("/sweepstakes/entries/{id}", handleUpdateSweepEntry).Methods("PUT")
 	api.HandleFunc("/sweepstakes/entries/{id}", handleDeleteSweepEntry).Methods("DELETE")
 
+	// Sweepstakes stages (group stage, knockout, ...)
+	api.HandleFunc("/sweepstakes/competitions/{id}/stages", handleGetSweepStages).Methods("GET")
+	api.HandleFunc("/sweepstakes/competitions/{id}/stages", handleCreateSweepStage).Methods("POST")
+	api.HandleFunc("/sweepstakes/stages/{id}/entries/{entryId}", handleUpdateSweepStageEntry).Methods("PUT")
+	api.HandleFunc("/sweepstakes/stages/{id}/complete", handleCompleteSweepStage).Methods("POST")
+	api.HandleFunc("/sweepstakes/stages/{id}", handleDeleteSweepStage).Methods("DELETE")
+
 	// Quiz media management
 	api.HandleFunc("/quiz/media/upload", handleQuizMediaUpload).Methods("POST")
 	api.HandleFunc("/quiz/media", handleGetQuizMedia).Methods("GET")
@@ -132,26 +212,64 @@ func main() {
 	api.HandleFunc("/quiz/questions", handleGetQuizQuestions).Methods("GET")
 	api.HandleFunc("/quiz/questions", handleCreateQuizQuestion).Methods("POST")
 	api.HandleFunc("/quiz/questions/import", handleImportQuizQuestions).Methods("POST")
+	api.HandleFunc("/quiz/questions/export", handleExportQuizQuestions).Methods("GET")
 	api.HandleFunc("/quiz/questions/{id}", handleUpdateQuizQuestion).Methods("PUT")
 	api.HandleFunc("/quiz/questions/{id}", handleDeleteQuizQuestion).Methods("DELETE")
 
+	// Contributed questions waiting for review
+	api.HandleFunc("/quiz/submissions", handleGetQuestionSubmissions).Methods("GET")
+	api.HandleFunc("/quiz/submissions/{id}", handleUpdateQuestionSubmission).Methods("PUT")
+	api.HandleFunc("/quiz/submissions/{id}/approve", handleApproveQuestionSubmission).Methods("POST")
+	api.HandleFunc("/quiz/submissions/{id}/reject", handleRejectQuestionSubmission).Methods("POST")
+
 	// Quiz pack management
 	api.HandleFunc("/quiz/packs", handleGetQuizPacks).Methods("GET")
 	api.HandleFunc("/quiz/packs", handleCreateQuizPack).Methods("POST")
+	api.HandleFunc("/quiz/packs/import", handleImportQuizPack).Methods("POST")
 	api.HandleFunc("/quiz/packs/{packId}", handleDeleteQuizPack).Methods("DELETE")
+	api.HandleFunc("/quiz/packs/{packId}/export", handleExportQuizPack).Methods("GET")
 
 	// Round management within a pack
 	api.HandleFunc("/quiz/packs/{packId}/rounds", handleGetPackRounds).Methods("GET")
 	api.HandleFunc("/quiz/packs/{packId}/rounds", handleCreatePackRound).Methods("POST")
+	api.HandleFunc("/quiz/packs/{packId}/rounds/generate", handleGeneratePackRound).Methods("POST")
 	api.HandleFunc("/quiz/packs/{packId}/rounds/{roundId}", handleDeletePackRound).Methods("DELETE")
 	api.HandleFunc("/quiz/packs/{packId}/rounds/{roundId}/questions", handleSetRoundQuestions).Methods("PUT")
 
+	// Leaderboard dispute review (players raise disputes in the leaderboard app)
+	api.HandleFunc("/leaderboard/disputes", handleGetLeaderboardDisputes).Methods("GET")
+	api.HandleFunc("/leaderboard/disputes/{id}/reject", handleRejectLeaderboardDispute).Methods("POST")
+	api.HandleFunc("/leaderboard/results/{id}/corrections", handleGetLeaderboardCorrections).Methods("GET")
+	api.HandleFunc("/leaderboard/results/{id}/void", handleVoidLeaderboardResult).Methods("POST")
+	api.HandleFunc("/leaderboard/results/{id}/restore", handleRestoreLeaderboardResult).Methods("POST")
+	api.HandleFunc("/leaderboard/results/{id}/correct", handleCorrectLeaderboardResult).Methods("POST")
+
+	// Loyalty points (activity_hub)
+	api.HandleFunc("/points/rules", handleGetPointsRules).Methods("GET")
+	api.HandleFunc("/points/rules/{activity}", handleUpdatePointsRule).Methods("PUT")
+	api.HandleFunc("/points/tallies", handleGetPointsTallies).Methods("GET")
+	api.HandleFunc("/points/redemptions", handleGetPointsRedemptions).Methods("GET")
+	api.HandleFunc("/points/redemptions", handleRedeemPoints).Methods("POST")
+
+	// Usage analytics per day (activity_hub), limited to the admin's venue
+	api.Handle("/analytics/daily", analytics.DailyHandler(identityDB)).Methods("GET")
+
+	// Audit log, with what each change did to the row (?id=)
+	api.Handle("/audit", auditLog.AdminHandler()).Methods("GET")
+
+	// Trash (deleted LMS games, sweepstakes competitions, quiz packs)
+	api.HandleFunc("/trash", handleGetTrash).Methods("GET")
+	api.HandleFunc("/trash/{type}/{id}/restore", handleRestoreTrash).Methods("POST")
+
 	// Export endpoints (no auth - read-only, used by LMS/Sweepstakes)
 	r.HandleFunc("/api/export/players", handleExportPlayers).Methods("GET")
 	r.HandleFunc("/api/export/groups", handleExportGroups).Methods("GET")
 
+	// Machine-readable API description (no auth, see openapi.go)
+	r.HandleFunc("/api/openapi.json", apiSpec().Handler).Methods("GET")
+
 	// Serve uploaded media files
-	r.PathPrefix("/uploads/").Handler(http.StripPrefix("/uploads/", http.FileServer(http.Dir("./uploads"))))
+	r.PathPrefix("/uploads/").Handler(http.StripPrefix("/uploads/", upload.FileServer("./uploads")))
 
 	// Serve React frontend
 	r.PathPrefix("/static/").Handler(http.FileServer(http.Dir("./static")))
@@ -159,13 +277,10 @@ func main() {
 		http.ServeFile(w, r, "./static/index.html")
 	})
 
-	corsHandler := handlers.CORS(
-		handlers.AllowedOrigins([]string{"*"}),
-		handlers.AllowedMethods([]string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}),
-		handlers.AllowedHeaders([]string{"Content-Type", "Authorization"}),
-	)
+	cors := apphttp.NewCORSPolicy(identityDB)
+	appMaintenance := maintenance.New(identityDB)
 
 	port := config.GetEnv("PORT", "5070")
 	log.Printf("🚀 Game Admin starting on :%s", port)
-	log.Fatal(http.ListenAndServe(":"+port, corsHandler(r)))
+	log.Fatal(http.ListenAndServe(":"+port, cors.Middleware(appMaintenance.Middleware("game-admin")(apphttp.Versioned(r)))))
 }
@@ -0,0 +1,440 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/achgithub/activity-hub-common/analytics"
+	"github.com/achgithub/activity-hub-common/audit"
+	apphttp "github.com/achgithub/activity-hub-common/http"
+	"github.com/achgithub/activity-hub-common/jobs"
+	"github.com/achgithub/activity-hub-common/listquery"
+	"github.com/achgithub/activity-hub-common/openapi"
+)
+
+// Response and request shapes shared by several routes. Handlers mostly build
+// their rows as maps or function-local structs, so they're described here.
+var (
+	success  = openapi.Fields{"success": true}
+	deleted  = openapi.Fields{"status": "deleted"}
+	updated  = openapi.Fields{"status": "updated"}
+	newID    = openapi.Fields{"id": 0}
+	reason   = openapi.Fields{"reason": ""}
+	lmsMatch = openapi.Fields{
+		"id": 0, "matchNumber": 0, "roundNumber": 0, "date": "", "location": "",
+		"homeTeam": "", "awayTeam": "", "result": "", "status": "",
+	}
+	lmsPrediction = openapi.Fields{
+		"userId": "", "predictedTeam": "", "isCorrect": (*bool)(nil), "voided": true, "bye": true,
+		"homeTeam": "", "awayTeam": "", "result": "",
+	}
+	sweepDraw = openapi.Fields{"id": 0, "entry_name": ""}
+	clipBody  = openapi.Fields{"mediaFileId": 0, "label": "", "audioStartSec": 0.0, "audioDurationSec": (*float64)(nil)}
+	question  = openapi.Fields{
+		"text": "", "answer": "", "category": "", "difficulty": "", "type": "",
+		"imageId": (*int)(nil), "audioId": (*int)(nil), "imageClipId": (*int)(nil), "audioClipId": (*int)(nil),
+		"requiresMedia": true, "isTestContent": true, "tiebreak": "",
+		"simplifiedText": "", "imageAlt": "", "readAloudText": "",
+	}
+)
+
+// apiSpec describes the routes registered in main.go for GET /api/openapi.json.
+// Add a route here whenever one is added there.
+func apiSpec() *openapi.Spec {
+	spec := openapi.New("game-admin", "1.0.0",
+		"Admin console for LMS games, sweepstakes, the quiz library, leaderboard disputes, loyalty points and usage analytics. "+
+			"Routes need the game_admin or super_user role (changes need game_admin); contribute routes need question_contributor.")
+
+	public := spec.Group("Public")
+	public.Route("GET", "/api/openapi.json", "This document").
+		Returns(http.StatusOK, nil)
+	public.Route("GET", "/api/export/players", "A manager's players, for LMS and sweepstakes").
+		Query("manager_email", "Manager whose players to export").
+		Returns(http.StatusOK, openapi.Fields{"players": []ManagedPlayer{}})
+	public.Route("GET", "/api/export/groups", "A manager's groups with their teams").
+		Query("manager_email", "Manager whose groups to export").
+		Returns(http.StatusOK, openapi.Fields{"groups": []openapi.Fields{{
+			"id": 0, "guid": "", "managerEmail": "", "name": "", "description": "", "createdAt": "",
+			"teams": []ManagedTeam{},
+		}}})
+
+	admin := spec.Group("Admin").Auth()
+	admin.Route("GET", "/api/config", "App configuration and the caller's permission level").
+		Returns(http.StatusOK, openapi.Fields{"appId": "", "name": "", "icon": "", "version": "", "description": "", "capabilities": apphttp.Capabilities{}, "gameOptions": []string{}, "permissionLevel": "", "currentGameId": "", "api": apphttp.APIVersionPolicy{}})
+	admin.Route("GET", "/api/jobs/{id}", "A background job's progress and result").
+		Returns(http.StatusOK, jobs.TaskStatus{})
+	admin.Route("GET", "/api/trash", "Deleted LMS games, sweepstakes competitions and quiz packs").
+		Returns(http.StatusOK, openapi.Fields{"items": []TrashItem{}, "retentionDays": 0})
+	admin.Route("POST", "/api/trash/{type}/{id}/restore", "Restore a deleted item").
+		Returns(http.StatusOK, success)
+	admin.Route("GET", "/api/audit", "Audit log, newest first; with ?id= one entry with its before/after snapshots and changes").
+		Paged().
+		Query("id", "One entry: {entry, changes}").
+		Query("action", "Only this action, e.g. quiz_pack_delete").
+		Query("target", "Only this target ID").
+		Query("admin", "Admin email contains").
+		Query("from", "At or after, e.g. 2026-10-17T18:00").
+		Query("to", "Before; a date alone includes the whole day").
+		Returns(http.StatusOK, openapi.Fields{"entries": []audit.Summary{}, "page": listquery.Page{}})
+
+	setup := spec.Group("Setup").Auth()
+	setup.Route("GET", "/api/setup/players", "The caller's players").
+		Query("impersonate", "Manager to act for (super_user only)").
+		Returns(http.StatusOK, openapi.Fields{"players": []ManagedPlayer{}})
+	setup.Route("POST", "/api/setup/players", "Add a player").
+		Body(openapi.Fields{"name": ""}).
+		Returns(http.StatusOK, ManagedPlayer{})
+	setup.Route("DELETE", "/api/setup/players/{id}", "Delete a player").
+		Returns(http.StatusOK, deleted)
+	setup.Route("GET", "/api/setup/groups", "The caller's groups").
+		Returns(http.StatusOK, openapi.Fields{"groups": []ManagedGroup{}})
+	setup.Route("POST", "/api/setup/groups", "Add a group").
+		Body(openapi.Fields{"name": "", "description": ""}).
+		Returns(http.StatusOK, ManagedGroup{})
+	setup.Route("DELETE", "/api/setup/groups/{id}", "Delete a group").
+		Returns(http.StatusOK, deleted)
+	setup.Route("GET", "/api/setup/groups/{groupId}/teams", "A group's teams").
+		Returns(http.StatusOK, openapi.Fields{"teams": []ManagedTeam{}})
+	setup.Route("POST", "/api/setup/groups/{groupId}/teams", "Add a team").
+		Body(openapi.Fields{"name": ""}).
+		Returns(http.StatusOK, ManagedTeam{})
+	setup.Route("DELETE", "/api/setup/teams/{id}", "Delete a team").
+		Returns(http.StatusOK, deleted)
+
+	lms := spec.Group("LMS").Auth()
+	lms.Route("GET", "/api/lms/games", "LMS games and the current game").
+		Paged().
+		Query("status", "Only games with this status").
+		Query("q", "Name contains").
+		Returns(http.StatusOK, openapi.Fields{
+			"games": []openapi.Fields{{
+				"id": 0, "guid": "", "name": "", "status": "", "winnerCount": 0, "startDate": "",
+				"fixtureFileId": 0, "fixtureName": "", "isPrivate": true, "joinCode": "",
+			}},
+			"currentGameId": 0,
+			"page":          listquery.Page{},
+		})
+	lms.Route("POST", "/api/lms/games", "Create a game").
+		Body(openapi.Fields{"name": "", "fixtureFileId": 0, "private": true}).
+		Returns(http.StatusOK, openapi.Fields{"success": true, "id": 0, "joinCode": ""})
+	lms.Route("PUT", "/api/lms/games/{id}/set-current", "Make a game the current one").
+		Returns(http.StatusOK, success)
+	lms.Route("PUT", "/api/lms/games/{id}/complete", "Mark a game completed").
+		Returns(http.StatusOK, success)
+	lms.Route("PUT", "/api/lms/games/{id}/join-code", "Give a private game a new join code").
+		Returns(http.StatusOK, openapi.Fields{"success": true, "joinCode": ""})
+	lms.Route("DELETE", "/api/lms/games/{id}", "Move a game to the trash").
+		Returns(http.StatusOK, success)
+	lms.Route("GET", "/api/lms/games/{id}/players", "A game's players, still-in players first").
+		Returns(http.StatusOK, openapi.Fields{"players": []lmsPlayer{}})
+	lms.Route("DELETE", "/api/lms/games/{id}/players/{userId}", "Remove a player and their picks from a game").
+		Query("reason", "Recorded in the audit log").
+		Returns(http.StatusOK, openapi.Fields{"success": true, "picksRemoved": 0})
+	lms.Route("POST", "/api/lms/games/{id}/players/{userId}/reinstate", "Put an eliminated player back in an active game").
+		Body(openapi.Fields{"reason": ""}).
+		Returns(http.StatusOK, success)
+	lms.Route("POST", "/api/lms/players/merge", "Move one user ID's LMS games and picks onto another").
+		Body(openapi.Fields{"fromUserId": "", "toUserId": "", "reason": ""}).
+		Returns(http.StatusOK, openapi.Fields{
+			"success": true, "games": 0, "sharedGames": 0, "picksMoved": 0, "picksDropped": 0,
+		})
+	lms.Route("GET", "/api/lms/rounds/{gameId}", "A game's rounds").
+		Returns(http.StatusOK, openapi.Fields{"rounds": []openapi.Fields{{
+			"id": 0, "guid": "", "label": 0, "startDate": "", "endDate": "",
+			"submissionDeadline": "", "status": "", "predCount": 0,
+		}}})
+	lms.Route("POST", "/api/lms/rounds", "Create a round over a date range").
+		Body(openapi.Fields{"gameId": 0, "label": 0, "startDate": "", "endDate": "", "submissionDeadline": ""}).
+		Returns(http.StatusOK, openapi.Fields{"success": true, "id": 0})
+	lms.Route("PUT", "/api/lms/rounds/{gameId}/{label}/status", "Open or close a round").
+		Body(openapi.Fields{"status": ""}).
+		Returns(http.StatusOK, success)
+	lms.Route("GET", "/api/lms/rounds/{gameId}/{label}/summary", "A round's picks and outcomes").
+		Returns(http.StatusOK, openapi.Fields{
+			"gameId": "", "label": "", "startDate": "", "endDate": "", "status": "",
+			"predictions": []openapi.Fields{lmsPrediction}, "survived": 0, "eliminated": 0,
+		})
+	lms.Route("DELETE", "/api/lms/rounds/{gameId}/{label}", "Delete a round and its picks").
+		Returns(http.StatusOK, success)
+	lms.Route("POST", "/api/lms/rounds/{gameId}/{label}/process", "Score a round in the background (poll /api/jobs/{id})").
+		Returns(http.StatusAccepted, openapi.Fields{"jobId": ""}).
+		Returns(http.StatusConflict, openapi.Fields{"error": "", "jobId": ""})
+	lms.Route("GET", "/api/lms/fixtures", "Fixture files").
+		Returns(http.StatusOK, openapi.Fields{"fixtures": []openapi.Fields{{
+			"id": 0, "guid": "", "name": "", "matchCount": 0, "updatedAt": "",
+		}}})
+	lms.Route("POST", "/api/lms/fixtures/upload", "Create or update a fixture file from CSV (form field name names it); changes to open rounds wait for confirmation").
+		Upload("file").
+		Returns(http.StatusOK, openapi.Fields{
+			"success": true, "id": 0, "name": "", "version": 0, "upserted": 0, "skipped": 0, "diff": fixtureDiff{},
+		}).
+		Returns(http.StatusAccepted, openapi.Fields{
+			"success": false, "requiresConfirmation": true, "id": 0, "name": "", "version": 0, "skipped": 0, "diff": fixtureDiff{},
+		})
+	lms.Route("GET", "/api/lms/fixtures/{id}/matches", "A fixture file's matches").
+		Returns(http.StatusOK, openapi.Fields{"matches": []openapi.Fields{lmsMatch}})
+	lms.Route("GET", "/api/lms/fixtures/{id}/versions", "A fixture file's uploads, newest first").
+		Returns(http.StatusOK, openapi.Fields{"versions": []openapi.Fields{{
+			"version": 0, "status": "", "matchCount": 0, "skipped": 0, "uploadedBy": "", "uploadedAt": "",
+			"appliedBy": "", "appliedAt": "",
+		}}})
+	lms.Route("GET", "/api/lms/fixtures/{id}/versions/{version}/diff", "What applying a version would change now").
+		Returns(http.StatusOK, openapi.Fields{"version": 0, "status": "", "diff": fixtureDiff{}})
+	lms.Route("POST", "/api/lms/fixtures/{id}/versions/{version}/apply", "Apply a pending version (confirm required if it changes open rounds)").
+		Body(openapi.Fields{"confirm": true}).
+		Returns(http.StatusOK, openapi.Fields{"success": true, "version": 0, "upserted": 0, "diff": fixtureDiff{}}).
+		Returns(http.StatusConflict, openapi.Fields{"error": "", "diff": fixtureDiff{}})
+	lms.Route("GET", "/api/lms/matches/{gameId}", "A game's matches").
+		Returns(http.StatusOK, openapi.Fields{"matches": []openapi.Fields{lmsMatch}})
+	lms.Route("GET", "/api/lms/matches/{gameId}/{label}", "Matches in a round's date window").
+		Returns(http.StatusOK, openapi.Fields{"matches": []openapi.Fields{lmsMatch}})
+	lms.Route("PUT", "/api/lms/matches/{id}/result", "Set a match result").
+		Body(openapi.Fields{"result": ""}).
+		Returns(http.StatusOK, success)
+	lms.Route("GET", "/api/lms/predictions", "All picks").
+		Query("gameId", "Only this game").
+		Query("round", "Only this round label").
+		Returns(http.StatusOK, openapi.Fields{"predictions": []openapi.Fields{lmsPrediction}})
+
+	sweeps := spec.Group("Sweepstakes").Auth()
+	sweeps.Route("GET", "/api/sweepstakes/competitions", "Competitions").
+		Returns(http.StatusOK, openapi.Fields{"competitions": []openapi.Fields{{
+			"id": 0, "guid": "", "name": "", "type": "", "status": "", "description": "", "createdAt": "",
+		}}})
+	sweeps.Route("POST", "/api/sweepstakes/competitions", "Create a competition").
+		Body(openapi.Fields{"name": "", "type": "", "description": ""}).
+		Returns(http.StatusOK, openapi.Fields{"id": 0, "name": "", "type": "", "status": ""})
+	sweeps.Route("PUT", "/api/sweepstakes/competitions/{id}", "Update a competition").
+		Body(openapi.Fields{"name": "", "type": "", "status": "", "description": ""}).
+		Returns(http.StatusOK, nil)
+	sweeps.Route("DELETE", "/api/sweepstakes/competitions/{id}", "Move a competition to the trash").
+		Returns(http.StatusOK, nil)
+	sweeps.Route("GET", "/api/sweepstakes/competitions/{id}/entries", "A competition's entries").
+		Paged().
+		Query("status", "Only entries with this status").
+		Query("q", "Name contains").
+		Returns(http.StatusOK, openapi.Fields{"entries": []openapi.Fields{{
+			"id": 0, "guid": "", "competition_id": 0, "name": "", "seed": (*int)(nil), "number": (*int)(nil),
+			"status": "", "position": (*int)(nil), "created_at": "",
+		}}, "page": listquery.Page{}})
+	sweeps.Route("GET", "/api/sweepstakes/competitions/{id}/entries/export", "Entries as CSV in the upload format").
+		Produces(http.StatusOK, "text/csv")
+	sweeps.Route("GET", "/api/sweepstakes/competitions/{id}/all-draws", "A competition's draws").
+		Returns(http.StatusOK, openapi.Fields{"draws": []openapi.Fields{{
+			"id": 0, "user_id": "", "entry_id": 0, "drawn_at": "", "entry_name": "", "entry_status": "",
+			"assigned_by": "", "seed": 0, "number": 0, "position": 0,
+		}}})
+	sweeps.Route("POST", "/api/sweepstakes/competitions/{id}/update-position", "Set an entry's finishing position").
+		Body(openapi.Fields{"entry_id": 0, "position": (*int)(nil)}).
+		Returns(http.StatusOK, nil)
+	sweeps.Route("POST", "/api/sweepstakes/competitions/{id}/assign", "Give a player a specific entry").
+		Body(openapi.Fields{"user_id": "", "entry_id": 0, "reason": ""}).
+		Returns(http.StatusOK, sweepDraw)
+	sweeps.Route("POST", "/api/sweepstakes/draws/{id}/void", "Void a draw").
+		Body(reason).
+		Returns(http.StatusOK, success)
+	sweeps.Route("POST", "/api/sweepstakes/draws/{id}/redraw", "Swap a draw's entry for a random available one").
+		Body(reason).
+		Returns(http.StatusOK, sweepDraw)
+	sweeps.Route("POST", "/api/sweepstakes/entries/upload", "Add or update entries from CSV (form field competition_id)").
+		Upload("file").
+		Returns(http.StatusOK, openapi.Fields{"uploaded": 0, "updated": 0, "skipped": 0})
+	sweeps.Route("PUT", "/api/sweepstakes/entries/{id}", "Update an entry's status or position").
+		Body(openapi.Fields{"status": "", "position": (*int)(nil)}).
+		Returns(http.StatusOK, nil)
+	sweeps.Route("DELETE", "/api/sweepstakes/entries/{id}", "Delete an entry").
+		Returns(http.StatusOK, nil)
+	sweeps.Route("GET", "/api/sweepstakes/competitions/{id}/stages", "A competition's stages, with each entry's part in them").
+		Returns(http.StatusOK, openapi.Fields{"stages": []SweepStage{}})
+	sweeps.Route("POST", "/api/sweepstakes/competitions/{id}/stages", "Start the next stage with the entries that went through the last").
+		Body(openapi.Fields{"name": ""}).
+		Returns(http.StatusOK, openapi.Fields{"id": 0, "stage_number": 0, "entries": 0})
+	sweeps.Route("PUT", "/api/sweepstakes/stages/{id}/entries/{entryId}", "Set an entry's group, position and outcome in an active stage").
+		Body(openapi.Fields{"group": "", "position": (*int)(nil), "outcome": "through"}).
+		Returns(http.StatusOK, nil)
+	sweeps.Route("POST", "/api/sweepstakes/stages/{id}/complete", "Complete a stage once every entry is through or out").
+		Returns(http.StatusOK, nil)
+	sweeps.Route("DELETE", "/api/sweepstakes/stages/{id}", "Delete a competition's latest stage").
+		Returns(http.StatusOK, nil)
+
+	media := spec.Group("Quiz media").Auth()
+	media.Route("POST", "/api/quiz/media/upload", "Upload an image or audio file (deduplicated by content)").
+		Upload("file").
+		Returns(http.StatusOK, openapi.Fields{
+			"id": 0, "guid": "", "filename": "", "originalName": "", "type": "", "filePath": "",
+			"sizeBytes": 0, "clip": openapi.Fields{"id": 0, "guid": "", "label": ""}, "deduplicated": true,
+		})
+	media.Route("GET", "/api/quiz/media", "Media files").
+		Query("type", "image or audio").
+		Returns(http.StatusOK, openapi.Fields{"files": []openapi.Fields{{
+			"id": 0, "guid": "", "filename": "", "originalName": "", "type": "", "filePath": "",
+			"sizeBytes": 0, "createdAt": "", "label": "",
+		}}})
+	media.Route("DELETE", "/api/quiz/media/{id}", "Delete a media file").
+		Returns(http.StatusOK, deleted)
+	media.Route("GET", "/api/quiz/clips", "Clips").
+		Query("media_file_id", "Only clips of this file").
+		Returns(http.StatusOK, openapi.Fields{"clips": []openapi.Fields{{
+			"id": 0, "guid": "", "mediaFileId": 0, "label": "", "audioStartSec": 0.0,
+			"audioDurationSec": (*float64)(nil), "mediaType": "", "filename": "", "filePath": "",
+		}}})
+	media.Route("GET", "/api/quiz/clips/export", "Clips as CSV").
+		Produces(http.StatusOK, "text/csv")
+	media.Route("POST", "/api/quiz/clips", "Create a clip").
+		Body(clipBody).
+		Returns(http.StatusOK, openapi.Fields{"id": 0, "guid": ""})
+	media.Route("PUT", "/api/quiz/clips/{id}", "Update a clip").
+		Body(openapi.Fields{"label": "", "audioStartSec": 0.0, "audioDurationSec": (*float64)(nil)}).
+		Returns(http.StatusOK, updated)
+	media.Route("DELETE", "/api/quiz/clips/{id}", "Delete a clip").
+		Returns(http.StatusOK, deleted)
+
+	questions := spec.Group("Quiz questions").Auth()
+	questions.Route("GET", "/api/quiz/questions", "The question bank").
+		Paged().
+		Query("type", "Only questions of this type").
+		Query("category", "Only questions in this category").
+		Query("tiebreak", "true for tiebreak questions only").
+		Query("q", "Question or answer contains").
+		Returns(http.StatusOK, openapi.Fields{"questions": []openapi.Fields{{
+			"id": 0, "guid": "", "text": "", "answer": "", "category": "", "difficulty": "", "type": "",
+			"imageId": (*int)(nil), "audioId": (*int)(nil), "imageClipId": (*int)(nil), "audioClipId": (*int)(nil),
+			"imagePath": "", "audioPath": "", "requiresMedia": true, "isTestContent": true,
+			"tiebreak": "", "contributedBy": "", "createdAt": "",
+			"simplifiedText": "", "imageAlt": "", "readAloudText": "",
+		}}, "page": listquery.Page{}})
+	questions.Route("POST", "/api/quiz/questions", "Add a question").
+		Body(question).
+		Returns(http.StatusOK, newID)
+	questions.Route("POST", "/api/quiz/questions/import", "Add or update questions from CSV").
+		Upload("file").
+		Returns(http.StatusOK, openapi.Fields{
+			"imported": 0, "updated": 0, "skipped": []openapi.Fields{{"row": 0, "reason": ""}},
+		})
+	questions.Route("GET", "/api/quiz/questions/export", "The question bank as CSV in the import format").
+		Produces(http.StatusOK, "text/csv")
+	questions.Route("PUT", "/api/quiz/questions/{id}", "Update a question").
+		Body(question).
+		Returns(http.StatusOK, updated)
+	questions.Route("DELETE", "/api/quiz/questions/{id}", "Delete a question").
+		Returns(http.StatusOK, deleted)
+
+	submissions := spec.Group("Question submissions").Auth()
+	submissions.Route("GET", "/api/quiz/submissions", "Contributed questions").
+		Query("status", "pending (default), approved, rejected or all").
+		Returns(http.StatusOK, openapi.Fields{"submissions": []QuestionSubmission{}})
+	submissions.Route("PUT", "/api/quiz/submissions/{id}", "Edit a submission before approving it").
+		Body(submissionFields{}).
+		Returns(http.StatusOK, success)
+	submissions.Route("POST", "/api/quiz/submissions/{id}/approve", "Add a submission to the question bank").
+		Returns(http.StatusOK, openapi.Fields{"success": true, "questionId": 0})
+	submissions.Route("POST", "/api/quiz/submissions/{id}/reject", "Reject a submission").
+		Body(reason).
+		Returns(http.StatusOK, success)
+
+	contribute := spec.Group("Contribute").Auth()
+	contribute.Route("GET", "/api/contribute/questions", "The caller's submissions and the bank's categories").
+		Returns(http.StatusOK, openapi.Fields{
+			"submissions": []QuestionSubmission{}, "categories": []string{}, "maxPending": 0,
+		})
+	contribute.Route("POST", "/api/contribute/questions", "Submit a question for review").
+		Body(submissionFields{}).
+		Returns(http.StatusOK, openapi.Fields{"id": 0, "status": ""})
+	contribute.Route("PUT", "/api/contribute/questions/{id}", "Edit a pending submission").
+		Body(submissionFields{}).
+		Returns(http.StatusOK, success)
+	contribute.Route("DELETE", "/api/contribute/questions/{id}", "Withdraw a pending submission").
+		Returns(http.StatusOK, success)
+
+	packs := spec.Group("Quiz packs").Auth()
+	packs.Route("GET", "/api/quiz/packs", "Quiz packs").
+		Returns(http.StatusOK, openapi.Fields{"packs": []openapi.Fields{{
+			"id": 0, "guid": "", "name": "", "description": "", "createdBy": "", "createdAt": "", "roundCount": 0,
+		}}})
+	packs.Route("POST", "/api/quiz/packs", "Create a pack").
+		Body(openapi.Fields{"name": "", "description": ""}).
+		Returns(http.StatusOK, newID)
+	packs.Route("POST", "/api/quiz/packs/import", "Import a pack exported from another system").
+		Body(PackExport{}).
+		Returns(http.StatusOK, openapi.Fields{"packId": 0, "created": true, "rounds": 0, "missingQuestions": []string{}})
+	packs.Route("DELETE", "/api/quiz/packs/{packId}", "Move a pack to the trash").
+		Returns(http.StatusOK, deleted)
+	packs.Route("GET", "/api/quiz/packs/{packId}/export", "A pack with its questions by GUID").
+		Returns(http.StatusOK, PackExport{})
+	packs.Route("GET", "/api/quiz/packs/{packId}/rounds", "A pack's rounds and their questions").
+		Returns(http.StatusOK, openapi.Fields{"rounds": []openapi.Fields{{
+			"id": 0, "guid": "", "roundNumber": 0, "name": "", "type": "", "timeLimitSeconds": 0, "questionCount": 0,
+			"questions": []openapi.Fields{{
+				"position": 0, "id": 0, "guid": "", "text": "", "answer": "", "type": "", "imagePath": "", "audioPath": "",
+			}},
+		}}})
+	packs.Route("POST", "/api/quiz/packs/{packId}/rounds", "Add a round").
+		Body(openapi.Fields{"roundNumber": 0, "name": "", "type": "", "timeLimitSeconds": (*int)(nil)}).
+		Returns(http.StatusOK, newID)
+	packs.Route("POST", "/api/quiz/packs/{packId}/rounds/generate", "Add a round picked from the question bank to a category mix, difficulty curve and media ratio").
+		Body(roundGenerateRequest{}).
+		Returns(http.StatusOK, openapi.Fields{"id": 0, "roundNumber": 0, "questionIds": []int{}, "relaxedDifficulty": 0})
+	packs.Route("DELETE", "/api/quiz/packs/{packId}/rounds/{roundId}", "Delete a round").
+		Returns(http.StatusOK, deleted)
+	packs.Route("PUT", "/api/quiz/packs/{packId}/rounds/{roundId}/questions", "Set a round's questions, in order").
+		Body(openapi.Fields{"questionIds": []int{}}).
+		Returns(http.StatusOK, openapi.Fields{"updated": 0})
+
+	leaderboard := spec.Group("Leaderboard").Auth()
+	leaderboard.Route("GET", "/api/leaderboard/disputes", "Disputed results").
+		Query("status", "Only disputes with this status").
+		Returns(http.StatusOK, openapi.Fields{"disputes": []openapi.Fields{{
+			"id": 0, "resultId": 0, "raisedBy": "", "reason": "", "status": "", "resolution": "",
+			"resolvedBy": "", "createdAt": "", "gameId": "", "gameType": "", "winnerName": "", "loserName": "",
+			"isDraw": true, "score": "", "voided": true, "playedAt": "", "isTeam": true, "confirmation": "",
+		}}})
+	leaderboard.Route("POST", "/api/leaderboard/disputes/{id}/reject", "Reject a dispute and keep the result").
+		Body(openapi.Fields{"resolution": ""}).
+		Returns(http.StatusOK, success)
+	leaderboard.Route("GET", "/api/leaderboard/results/{id}/corrections", "A result's correction history").
+		Returns(http.StatusOK, openapi.Fields{"corrections": []openapi.Fields{{
+			"id": 0, "action": "", "before": openapi.Fields{}, "after": openapi.Fields{},
+			"reason": "", "correctedBy": "", "createdAt": "",
+		}}})
+	leaderboard.Route("POST", "/api/leaderboard/results/{id}/void", "Void a result").
+		Body(reason).
+		Returns(http.StatusOK, success)
+	leaderboard.Route("POST", "/api/leaderboard/results/{id}/restore", "Restore a voided result").
+		Body(reason).
+		Returns(http.StatusOK, success)
+	leaderboard.Route("POST", "/api/leaderboard/results/{id}/correct", "Correct a result's winner, draw or score").
+		Body(openapi.Fields{"swapSides": true, "isDraw": (*bool)(nil), "score": (*string)(nil), "reason": ""}).
+		Returns(http.StatusOK, success)
+
+	loyalty := spec.Group("Points").Auth()
+	loyalty.Route("GET", "/api/points/rules", "What each activity is worth").
+		Returns(http.StatusOK, openapi.Fields{"rules": []openapi.Fields{{
+			"activity": "", "points": 0, "description": "", "updatedBy": "", "updatedAt": "",
+		}}})
+	loyalty.Route("PUT", "/api/points/rules/{activity}", "Change what an activity is worth").
+		Body(openapi.Fields{"points": 0}).
+		Returns(http.StatusOK, success)
+	loyalty.Route("GET", "/api/points/tallies", "Players' points for a month").
+		Query("month", "Month (YYYY-MM), default this month").
+		Returns(http.StatusOK, openapi.Fields{"month": "", "tallies": []openapi.Fields{{
+			"userEmail": "", "name": "", "earned": 0, "redeemed": 0, "balance": 0,
+		}}})
+	loyalty.Route("GET", "/api/points/redemptions", "Rewards redeemed in a month").
+		Query("month", "Month (YYYY-MM), default this month").
+		Returns(http.StatusOK, openapi.Fields{"redemptions": []openapi.Fields{{
+			"id": 0, "userEmail": "", "name": "", "points": 0, "reward": "", "redeemedBy": "", "createdAt": "",
+		}}})
+	loyalty.Route("POST", "/api/points/redemptions", "Redeem a player's points for a reward").
+		Body(openapi.Fields{"userEmail": "", "points": 0, "reward": ""}).
+		Returns(http.StatusOK, openapi.Fields{"success": true, "balance": 0})
+
+	usage := spec.Group("Analytics").Auth()
+	usage.Route("GET", "/api/analytics/daily", "Anonymous usage summed per day, app and event").
+		Query("from", "First day (YYYY-MM-DD), default 30 days before to").
+		Query("to", "Last day (YYYY-MM-DD), default today").
+		Query("venue", "Only this venue (admins tied to a venue always see theirs)").
+		Query("app", "Only this app").
+		Query("event", "Only this event (app_opened, game_length, quiz_participation)").
+		Returns(http.StatusOK, openapi.Fields{"from": "", "to": "", "aggregates": []analytics.Aggregate{}})
+
+	return spec
+}
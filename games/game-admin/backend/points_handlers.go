@@ -0,0 +1,231 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/achgithub/activity-hub-common/points"
+	"github.com/gorilla/mux"
+)
+
+// ============================================================
+// Loyalty points handlers
+// ============================================================
+//
+// Apps award points with activity-hub-common points (identity DB). Here the
+// landlord sets what each activity is worth, sees monthly tallies and records
+// rewards handed over behind the bar.
+
+// handleGetPointsRules returns the points each activity is worth.
+func handleGetPointsRules(w http.ResponseWriter, r *http.Request) {
+	rows, err := identityDB.Query(`
+		SELECT activity, points, COALESCE(description, ''), COALESCE(updated_by, ''), updated_at
+		FROM points_rules
+		ORDER BY activity
+	`)
+	if err != nil {
+		sendError(w, "Failed to load points rules", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	type Rule struct {
+		Activity    string    `json:"activity"`
+		Points      int       `json:"points"`
+		Description string    `json:"description"`
+		UpdatedBy   string    `json:"updatedBy"`
+		UpdatedAt   time.Time `json:"updatedAt"`
+	}
+	rules := []Rule{}
+	for rows.Next() {
+		var rule Rule
+		if err := rows.Scan(&rule.Activity, &rule.Points, &rule.Description, &rule.UpdatedBy, &rule.UpdatedAt); err != nil {
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	sendJSON(w, map[string]interface{}{"rules": rules})
+}
+
+// handleUpdatePointsRule sets the points an activity is worth (0 = none).
+func handleUpdatePointsRule(w http.ResponseWriter, r *http.Request) {
+	if !requireWritePermission(w, r) {
+		return
+	}
+	activity := mux.Vars(r)["activity"]
+
+	var req struct {
+		Points int `json:"points"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Points < 0 {
+		sendError(w, "points must be 0 or more", http.StatusBadRequest)
+		return
+	}
+
+	before := snapshot(identityDB, pointsRuleSnapshot, activity)
+	res, err := identityDB.Exec(`
+		UPDATE points_rules SET points = $2, updated_by = $3, updated_at = NOW()
+		WHERE activity = $1
+	`, activity, req.Points, actorEmail(r))
+	if err != nil {
+		sendError(w, "Failed to update points rule", http.StatusInternalServerError)
+		return
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		sendError(w, "Unknown activity", http.StatusNotFound)
+		return
+	}
+
+	logAuditChange(r, "points_rule_update", activity, map[string]interface{}{"points": req.Points},
+		before, snapshot(identityDB, pointsRuleSnapshot, activity))
+	sendJSON(w, map[string]interface{}{"success": true})
+}
+
+// handleGetPointsTallies returns every player's points for a month.
+// ?month=YYYY-MM (default this month). Balance is all-time earned minus redeemed.
+func handleGetPointsTallies(w http.ResponseWriter, r *http.Request) {
+	start, end, err := points.ParseMonth(r.URL.Query().Get("month"))
+	if err != nil {
+		sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rows, err := identityDB.Query(`
+		WITH month_earned AS (
+			SELECT user_email, SUM(points) AS points FROM points_ledger
+			WHERE created_at >= $1 AND created_at < $2 GROUP BY user_email
+		), month_redeemed AS (
+			SELECT user_email, SUM(points) AS points FROM points_redemptions
+			WHERE created_at >= $1 AND created_at < $2 GROUP BY user_email
+		), earned AS (
+			SELECT user_email, SUM(points) AS points FROM points_ledger GROUP BY user_email
+		), redeemed AS (
+			SELECT user_email, SUM(points) AS points FROM points_redemptions GROUP BY user_email
+		)
+		SELECT e.user_email, COALESCE(u.name, e.user_email),
+		       COALESCE(me.points, 0), COALESCE(mr.points, 0),
+		       e.points - COALESCE(rd.points, 0)
+		FROM earned e
+		LEFT JOIN users u ON u.email = e.user_email
+		LEFT JOIN month_earned me ON me.user_email = e.user_email
+		LEFT JOIN month_redeemed mr ON mr.user_email = e.user_email
+		LEFT JOIN redeemed rd ON rd.user_email = e.user_email
+		WHERE me.points IS NOT NULL OR mr.points IS NOT NULL
+		ORDER BY COALESCE(me.points, 0) DESC, e.user_email
+	`, start, end)
+	if err != nil {
+		log.Printf("Failed to load points tallies: %v", err)
+		sendError(w, "Failed to load points tallies", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	type Tally struct {
+		UserEmail string `json:"userEmail"`
+		Name      string `json:"name"`
+		Earned    int    `json:"earned"`
+		Redeemed  int    `json:"redeemed"`
+		Balance   int    `json:"balance"`
+	}
+	tallies := []Tally{}
+	for rows.Next() {
+		var t Tally
+		if err := rows.Scan(&t.UserEmail, &t.Name, &t.Earned, &t.Redeemed, &t.Balance); err != nil {
+			continue
+		}
+		tallies = append(tallies, t)
+	}
+	sendJSON(w, map[string]interface{}{"month": start.Format(points.MonthFormat), "tallies": tallies})
+}
+
+// handleGetPointsRedemptions returns the redemption log for a month.
+// ?month=YYYY-MM (default this month)
+func handleGetPointsRedemptions(w http.ResponseWriter, r *http.Request) {
+	start, end, err := points.ParseMonth(r.URL.Query().Get("month"))
+	if err != nil {
+		sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rows, err := identityDB.Query(`
+		SELECT p.id, p.user_email, COALESCE(u.name, p.user_email), p.points, p.reward, p.redeemed_by, p.created_at
+		FROM points_redemptions p
+		LEFT JOIN users u ON u.email = p.user_email
+		WHERE p.created_at >= $1 AND p.created_at < $2
+		ORDER BY p.created_at DESC
+	`, start, end)
+	if err != nil {
+		sendError(w, "Failed to load redemptions", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	type Redemption struct {
+		ID         int       `json:"id"`
+		UserEmail  string    `json:"userEmail"`
+		Name       string    `json:"name"`
+		Points     int       `json:"points"`
+		Reward     string    `json:"reward"`
+		RedeemedBy string    `json:"redeemedBy"`
+		CreatedAt  time.Time `json:"createdAt"`
+	}
+	redemptions := []Redemption{}
+	for rows.Next() {
+		var rd Redemption
+		if err := rows.Scan(&rd.ID, &rd.UserEmail, &rd.Name, &rd.Points, &rd.Reward, &rd.RedeemedBy, &rd.CreatedAt); err != nil {
+			continue
+		}
+		redemptions = append(redemptions, rd)
+	}
+	sendJSON(w, map[string]interface{}{"redemptions": redemptions})
+}
+
+// handleRedeemPoints records a reward handed over to a player.
+func handleRedeemPoints(w http.ResponseWriter, r *http.Request) {
+	if !requireWritePermission(w, r) {
+		return
+	}
+
+	var req struct {
+		UserEmail string `json:"userEmail"`
+		Points    int    `json:"points"`
+		Reward    string `json:"reward"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendError(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+	req.UserEmail = strings.TrimSpace(req.UserEmail)
+	if req.UserEmail == "" || req.Points <= 0 || strings.TrimSpace(req.Reward) == "" {
+		sendError(w, "userEmail, points and reward are required", http.StatusBadRequest)
+		return
+	}
+
+	venueID := 0
+	if user, ok := authlib.GetUserFromContext(r.Context()); ok {
+		venueID = user.VenueID
+	}
+
+	balance, err := points.Redeem(identityDB, points.Redemption{
+		UserEmail:  req.UserEmail,
+		Points:     req.Points,
+		Reward:     req.Reward,
+		RedeemedBy: actorEmail(r),
+		VenueID:    venueID,
+	})
+	if err == points.ErrInsufficientPoints {
+		sendError(w, "Not enough points", http.StatusConflict)
+		return
+	} else if err != nil {
+		log.Printf("Failed to redeem points for %s: %v", req.UserEmail, err)
+		sendError(w, "Failed to redeem points", http.StatusInternalServerError)
+		return
+	}
+
+	logAudit(r, "points_redeem", req.UserEmail, map[string]interface{}{"points": req.Points, "reward": req.Reward})
+	sendJSON(w, map[string]interface{}{"success": true, "balance": balance})
+}
@@ -8,7 +8,6 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"io"
 	"log"
 	"net/http"
 	"os"
@@ -17,54 +16,26 @@ import (
 	"strings"
 	"time"
 
+	"github.com/achgithub/activity-hub-common/listquery"
+	"github.com/achgithub/activity-hub-common/upload"
 	"github.com/gorilla/mux"
 )
 
-const (
-	maxImageSize = 10 << 20 // 10 MB
-	maxAudioSize = 20 << 20 // 20 MB
-	uploadsBase  = "./uploads/quiz"
-)
+const uploadsBase = "./uploads/quiz"
 
 // --- Media handlers ---
 
 func handleQuizMediaUpload(w http.ResponseWriter, r *http.Request) {
-	r.Body = http.MaxBytesReader(w, r.Body, maxAudioSize)
-	if err := r.ParseMultipartForm(maxAudioSize); err != nil {
-		http.Error(w, `{"error":"file too large or invalid form"}`, http.StatusBadRequest)
-		return
-	}
-
-	file, header, err := r.FormFile("file")
+	// Type and size limits come from the sniffed content, not the file extension
+	file, err := upload.Read(w, r, "file", upload.Media)
 	if err != nil {
-		http.Error(w, `{"error":"missing file field"}`, http.StatusBadRequest)
-		return
-	}
-	defer file.Close()
-
-	ext := strings.ToLower(filepath.Ext(header.Filename))
-	mediaType := ""
-	switch ext {
-	case ".jpg", ".jpeg", ".png", ".gif", ".webp":
-		mediaType = "image"
-		if header.Size > maxImageSize {
-			http.Error(w, `{"error":"image exceeds 10MB limit"}`, http.StatusBadRequest)
-			return
-		}
-	case ".mp3", ".ogg", ".wav", ".m4a":
-		mediaType = "audio"
-	default:
-		http.Error(w, `{"error":"unsupported file type"}`, http.StatusBadRequest)
-		return
-	}
-
-	// Buffer entire file for hashing
-	var buf bytes.Buffer
-	if _, err := io.Copy(&buf, file); err != nil {
-		http.Error(w, `{"error":"could not read file"}`, http.StatusInternalServerError)
+		http.Error(w, fmt.Sprintf(`{"error":%q}`, err.Error()), upload.StatusCode(err))
 		return
 	}
-	fileBytes := buf.Bytes()
+	mediaType := string(file.Kind)
+	fileBytes := file.Data
+	baseName := strings.TrimSuffix(file.Name, filepath.Ext(file.Name))
+	sizeBytes := int64(len(fileBytes))
 
 	// Compute SHA-256 hash for deduplication
 	hashBytes := sha256.Sum256(fileBytes)
@@ -107,7 +78,7 @@ func handleQuizMediaUpload(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(UploadResponse{
 			ID:           existingID,
 			Guid:         existingGuid,
-			OriginalName: header.Filename,
+			OriginalName: file.Name,
 			Type:         mediaType,
 			FilePath:     existingFilePath,
 			SizeBytes:    existingSizeBytes,
@@ -130,7 +101,7 @@ func handleQuizMediaUpload(w http.ResponseWriter, r *http.Request) {
 	}
 
 	timestamp := time.Now().UnixMilli()
-	storedName := fmt.Sprintf("%d-%s%s", timestamp, sanitizeFilename(strings.TrimSuffix(header.Filename, ext)), ext)
+	storedName := fmt.Sprintf("%d-%s%s", timestamp, sanitizeFilename(baseName), file.Ext)
 	destPath := filepath.Join(subdir, storedName)
 
 	if err := os.WriteFile(destPath, fileBytes, 0644); err != nil {
@@ -139,7 +110,7 @@ func handleQuizMediaUpload(w http.ResponseWriter, r *http.Request) {
 	}
 
 	urlPath := fmt.Sprintf("/uploads/quiz/%ss/%s", mediaType, storedName)
-	label := strings.TrimSuffix(header.Filename, ext)
+	label := baseName
 
 	// Insert media_file with hash, guid, label
 	var fileID int
@@ -147,7 +118,7 @@ func handleQuizMediaUpload(w http.ResponseWriter, r *http.Request) {
 	err = quizDB.QueryRow(
 		`INSERT INTO media_files (filename, original_name, type, file_path, size_bytes, content_hash, label)
 		 VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, guid::text`,
-		storedName, header.Filename, mediaType, urlPath, header.Size, contentHash, label,
+		storedName, file.Name, mediaType, urlPath, sizeBytes, contentHash, label,
 	).Scan(&fileID, &fileGuid)
 	if err != nil {
 		log.Printf("media insert error: %v", err)
@@ -171,10 +142,10 @@ func handleQuizMediaUpload(w http.ResponseWriter, r *http.Request) {
 		ID:           fileID,
 		Guid:         fileGuid,
 		Filename:     storedName,
-		OriginalName: header.Filename,
+		OriginalName: file.Name,
 		Type:         mediaType,
 		FilePath:     urlPath,
-		SizeBytes:    header.Size,
+		SizeBytes:    sizeBytes,
 		Clip:         clip,
 		Deduplicated: false,
 	})
@@ -447,34 +418,41 @@ func handleExportClipsCSV(w http.ResponseWriter, r *http.Request) {
 
 // --- Question handlers ---
 
+// questionList is what the question bank can be sorted and filtered by
+var questionList = listquery.Spec{
+	Sorts: map[string]string{
+		"id":       "q.id",
+		"category": "COALESCE(q.category,'')",
+		"type":     "q.type",
+		"text":     "q.text",
+	},
+	DefaultSort: "-id",
+	Tiebreak:    "q.id",
+	Filters: map[string]listquery.Filter{
+		"type":     listquery.Equals("q.type"),
+		"category": listquery.Equals("q.category"),
+		"tiebreak": listquery.Present("q.tiebreak"),
+		"q":        listquery.Search("q.text", "q.answer"),
+	},
+}
+
 func handleGetQuizQuestions(w http.ResponseWriter, r *http.Request) {
-	q := r.URL.Query()
-	qType := q.Get("type")
-	category := q.Get("category")
+	lq, err := listquery.Parse(r.URL.Query(), questionList)
+	if err != nil {
+		http.Error(w, fmt.Sprintf(`{"error":%q}`, err.Error()), http.StatusBadRequest)
+		return
+	}
 
-	query := `
+	query, args := lq.SQL(`
 		SELECT q.id, q.guid::text, q.text, q.answer, COALESCE(q.category,''), q.difficulty, q.type,
 		       q.image_id, q.audio_id, q.is_test_content, q.created_at,
 		       COALESCE(img.file_path,''), COALESCE(aud.file_path,''),
-		       q.requires_media, q.image_clip_id, q.audio_clip_id
+		       q.requires_media, q.image_clip_id, q.audio_clip_id, COALESCE(q.tiebreak,''),
+		       COALESCE(q.contributed_by,''),
+		       COALESCE(q.simplified_text,''), COALESCE(q.image_alt,''), COALESCE(q.read_aloud_text,'')
 		FROM questions q
 		LEFT JOIN media_files img ON img.id = q.image_id
-		LEFT JOIN media_files aud ON aud.id = q.audio_id
-		WHERE 1=1`
-	args := []interface{}{}
-	idx := 1
-	if qType != "" {
-		query += fmt.Sprintf(" AND q.type = $%d", idx)
-		args = append(args, qType)
-		idx++
-	}
-	if category != "" {
-		query += fmt.Sprintf(" AND q.category = $%d", idx)
-		args = append(args, category)
-		idx++
-	}
-	query += " ORDER BY q.id DESC"
-
+		LEFT JOIN media_files aud ON aud.id = q.audio_id`)
 	rows, err := quizDB.Query(query, args...)
 	if err != nil {
 		log.Printf("questions query error: %v", err)
@@ -500,6 +478,13 @@ func handleGetQuizQuestions(w http.ResponseWriter, r *http.Request) {
 		RequiresMedia bool   `json:"requiresMedia"`
 		ImageClipID   *int   `json:"imageClipId"`
 		AudioClipID   *int   `json:"audioClipId"`
+		Tiebreak      string `json:"tiebreak"`      // "" | nearest | sudden_death
+		ContributedBy string `json:"contributedBy"` // contributor's email for approved submissions
+
+		// Accessible variants, sent to screens with the question
+		SimplifiedText string `json:"simplifiedText"`
+		ImageAlt       string `json:"imageAlt"`
+		ReadAloudText  string `json:"readAloudText"` // "" reads out the text and image description
 	}
 
 	questions := []Question{}
@@ -510,7 +495,9 @@ func handleGetQuizQuestions(w http.ResponseWriter, r *http.Request) {
 			&q.ID, &q.Guid, &q.Text, &q.Answer, &q.Category, &q.Difficulty, &q.Type,
 			&imageID, &audioID, &q.IsTestContent, &q.CreatedAt,
 			&q.ImagePath, &q.AudioPath,
-			&q.RequiresMedia, &imageClipID, &audioClipID,
+			&q.RequiresMedia, &imageClipID, &audioClipID, &q.Tiebreak,
+			&q.ContributedBy,
+			&q.SimplifiedText, &q.ImageAlt, &q.ReadAloudText,
 		); err != nil {
 			continue
 		}
@@ -532,9 +519,10 @@ func handleGetQuizQuestions(w http.ResponseWriter, r *http.Request) {
 		}
 		questions = append(questions, q)
 	}
+	questions, page := listquery.Finish(lq, questions)
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{"questions": questions})
+	json.NewEncoder(w).Encode(map[string]interface{}{"questions": questions, "page": page})
 }
 
 func handleCreateQuizQuestion(w http.ResponseWriter, r *http.Request) {
@@ -550,11 +538,20 @@ func handleCreateQuizQuestion(w http.ResponseWriter, r *http.Request) {
 		AudioClipID   *int   `json:"audioClipId"`
 		RequiresMedia bool   `json:"requiresMedia"`
 		IsTestContent bool   `json:"isTestContent"`
+		Tiebreak      string `json:"tiebreak"`
+
+		SimplifiedText string `json:"simplifiedText"`
+		ImageAlt       string `json:"imageAlt"`
+		ReadAloudText  string `json:"readAloudText"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		http.Error(w, `{"error":"invalid JSON"}`, http.StatusBadRequest)
 		return
 	}
+	if err := validateTiebreak(body.Tiebreak, body.Answer); err != nil {
+		http.Error(w, fmt.Sprintf(`{"error":%q}`, err.Error()), http.StatusBadRequest)
+		return
+	}
 	if body.Text == "" || body.Answer == "" {
 		http.Error(w, `{"error":"text and answer required"}`, http.StatusBadRequest)
 		return
@@ -579,12 +576,14 @@ func handleCreateQuizQuestion(w http.ResponseWriter, r *http.Request) {
 	var id int
 	err := quizDB.QueryRow(
 		`INSERT INTO questions (text, answer, category, difficulty, type, image_id, audio_id,
-		                        image_clip_id, audio_clip_id, requires_media, is_test_content)
-		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id`,
+		                        image_clip_id, audio_clip_id, requires_media, is_test_content, tiebreak,
+		                        simplified_text, image_alt, read_aloud_text)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15) RETURNING id`,
 		body.Text, body.Answer, nullableStr(body.Category), body.Difficulty, body.Type,
 		nullableInt(imageID), nullableInt(audioID),
 		nullableInt(body.ImageClipID), nullableInt(body.AudioClipID),
-		body.RequiresMedia, body.IsTestContent,
+		body.RequiresMedia, body.IsTestContent, nullableStr(body.Tiebreak),
+		nullableStr(body.SimplifiedText), nullableStr(body.ImageAlt), nullableStr(body.ReadAloudText),
 	).Scan(&id)
 	if err != nil {
 		log.Printf("create question error: %v", err)
@@ -615,11 +614,20 @@ func handleUpdateQuizQuestion(w http.ResponseWriter, r *http.Request) {
 		AudioClipID   *int   `json:"audioClipId"`
 		RequiresMedia bool   `json:"requiresMedia"`
 		IsTestContent bool   `json:"isTestContent"`
+		Tiebreak      string `json:"tiebreak"`
+
+		SimplifiedText string `json:"simplifiedText"`
+		ImageAlt       string `json:"imageAlt"`
+		ReadAloudText  string `json:"readAloudText"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		http.Error(w, `{"error":"invalid JSON"}`, http.StatusBadRequest)
 		return
 	}
+	if err := validateTiebreak(body.Tiebreak, body.Answer); err != nil {
+		http.Error(w, fmt.Sprintf(`{"error":%q}`, err.Error()), http.StatusBadRequest)
+		return
+	}
 
 	// Resolve clip IDs → media_file IDs for backward compat
 	imageID, audioID := resolveClipToFileIDs(body.ImageClipID, body.AudioClipID)
@@ -630,24 +638,43 @@ func handleUpdateQuizQuestion(w http.ResponseWriter, r *http.Request) {
 		audioID = body.AudioID
 	}
 
+	before := snapshot(quizDB, quizQuestionSnapshot, id)
 	_, err = quizDB.Exec(
 		`UPDATE questions SET text=$1, answer=$2, category=$3, difficulty=$4, type=$5,
 		 image_id=$6, audio_id=$7, image_clip_id=$8, audio_clip_id=$9,
-		 requires_media=$10, is_test_content=$11 WHERE id=$12`,
+		 requires_media=$10, is_test_content=$11, tiebreak=$12,
+		 simplified_text=$13, image_alt=$14, read_aloud_text=$15 WHERE id=$16`,
 		body.Text, body.Answer, nullableStr(body.Category), body.Difficulty, body.Type,
 		nullableInt(imageID), nullableInt(audioID),
 		nullableInt(body.ImageClipID), nullableInt(body.AudioClipID),
-		body.RequiresMedia, body.IsTestContent, id,
+		body.RequiresMedia, body.IsTestContent, nullableStr(body.Tiebreak),
+		nullableStr(body.SimplifiedText), nullableStr(body.ImageAlt), nullableStr(body.ReadAloudText), id,
 	)
 	if err != nil {
 		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
 		return
 	}
 
+	logAuditChange(r, "quiz_question_update", strconv.Itoa(id), nil, before, snapshot(quizDB, quizQuestionSnapshot, id))
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{"status": "updated"})
 }
 
+// validateTiebreak checks a question's tie-break pool setting. Nearest-number
+// questions need a numeric answer so guesses can be ranked by distance.
+func validateTiebreak(tiebreak, answer string) error {
+	switch tiebreak {
+	case "", "sudden_death":
+		return nil
+	case "nearest":
+		if _, err := strconv.ParseFloat(strings.TrimSpace(answer), 64); err != nil {
+			return fmt.Errorf("nearest-number tie-break answers must be a number")
+		}
+		return nil
+	}
+	return fmt.Errorf("tiebreak must be nearest or sudden_death")
+}
+
 func handleDeleteQuizQuestion(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.Atoi(mux.Vars(r)["id"])
 	if err != nil {
@@ -655,34 +682,32 @@ func handleDeleteQuizQuestion(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	before := snapshot(quizDB, quizQuestionSnapshot, id)
 	_, err = quizDB.Exec(`DELETE FROM questions WHERE id = $1`, id)
 	if err != nil {
 		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
 		return
 	}
 
+	logAuditChange(r, "quiz_question_delete", strconv.Itoa(id), nil, before, nil)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{"status": "deleted"})
 }
 
 // handleImportQuizQuestions imports questions from a CSV file upload.
 // Required columns: text, answer
-// Optional columns: category, difficulty, type, image_guid, audio_guid, requires_media
+// Optional columns: guid, category, difficulty, type, image_guid, audio_guid, requires_media
+// A row whose guid is already in the bank updates that question (so an export
+// from another environment can be re-imported); other rows are added, keeping
+// their guid if they have one.
 func handleImportQuizQuestions(w http.ResponseWriter, r *http.Request) {
-	r.Body = http.MaxBytesReader(w, r.Body, 5<<20) // 5MB CSV limit
-	if err := r.ParseMultipartForm(5 << 20); err != nil {
-		http.Error(w, `{"error":"invalid form"}`, http.StatusBadRequest)
-		return
-	}
-
-	file, _, err := r.FormFile("file")
+	file, err := upload.Read(w, r, "file", upload.Sheets)
 	if err != nil {
-		http.Error(w, `{"error":"missing file field"}`, http.StatusBadRequest)
+		http.Error(w, fmt.Sprintf(`{"error":%q}`, err.Error()), upload.StatusCode(err))
 		return
 	}
-	defer file.Close()
 
-	reader := csv.NewReader(file)
+	reader := csv.NewReader(bytes.NewReader(file.Data))
 	records, err := reader.ReadAll()
 	if err != nil {
 		http.Error(w, `{"error":"invalid CSV"}`, http.StatusBadRequest)
@@ -720,7 +745,7 @@ func handleImportQuizQuestions(w http.ResponseWriter, r *http.Request) {
 		return strings.TrimSpace(record[idx])
 	}
 
-	imported := 0
+	imported, updated := 0, 0
 	var skipped []SkippedRow
 
 	for i, record := range records[1:] {
@@ -788,14 +813,39 @@ func handleImportQuizQuestions(w http.ResponseWriter, r *http.Request) {
 			audioID = &mfID
 		}
 
+		guid := getCol(record, "guid")
+		if guid != "" && !validGuid(guid) {
+			skipped = append(skipped, SkippedRow{Row: rowNum, Reason: fmt.Sprintf("invalid guid: %s", guid)})
+			continue
+		}
+		if guid != "" {
+			res, err := quizDB.Exec(
+				`UPDATE questions SET text=$2, answer=$3, category=$4, difficulty=$5, type=$6,
+				 image_id=$7, audio_id=$8, image_clip_id=$9, audio_clip_id=$10, requires_media=$11
+				 WHERE guid = $1::uuid`,
+				guid, text, answer, nullableStr(category), difficulty, qType,
+				nullableInt(imageID), nullableInt(audioID),
+				nullableInt(imageClipID), nullableInt(audioClipID),
+				requiresMedia,
+			)
+			if err != nil {
+				skipped = append(skipped, SkippedRow{Row: rowNum, Reason: "database error: " + err.Error()})
+				continue
+			}
+			if n, _ := res.RowsAffected(); n > 0 {
+				updated++
+				continue
+			}
+		}
+
 		_, err := quizDB.Exec(
 			`INSERT INTO questions (text, answer, category, difficulty, type,
-			                        image_id, audio_id, image_clip_id, audio_clip_id, requires_media)
-			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+			                        image_id, audio_id, image_clip_id, audio_clip_id, requires_media, guid)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, COALESCE($11::uuid, gen_random_uuid()))`,
 			text, answer, nullableStr(category), difficulty, qType,
 			nullableInt(imageID), nullableInt(audioID),
 			nullableInt(imageClipID), nullableInt(audioClipID),
-			requiresMedia,
+			requiresMedia, nullableStr(guid),
 		)
 		if err != nil {
 			skipped = append(skipped, SkippedRow{Row: rowNum, Reason: "database error: " + err.Error()})
@@ -811,18 +861,53 @@ func handleImportQuizQuestions(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"imported": imported,
+		"updated":  updated,
 		"skipped":  skipped,
 	})
 }
 
+// handleExportQuizQuestions downloads the question bank as CSV in the import
+// format. Media is referenced by clip GUID, so clips must exist (same GUIDs)
+// wherever the file is imported.
+func handleExportQuizQuestions(w http.ResponseWriter, r *http.Request) {
+	rows, err := quizDB.Query(`
+		SELECT q.guid::text, q.text, q.answer, COALESCE(q.category,''), q.difficulty, q.type,
+		       COALESCE(ic.guid::text,''), COALESCE(ac.guid::text,''), q.requires_media
+		FROM questions q
+		LEFT JOIN media_clips ic ON ic.id = q.image_clip_id
+		LEFT JOIN media_clips ac ON ac.id = q.audio_clip_id
+		WHERE NOT q.is_test_content
+		ORDER BY q.id`)
+	if err != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", `attachment; filename="quiz-questions.csv"`)
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"guid", "text", "answer", "category", "difficulty", "type", "image_guid", "audio_guid", "requires_media"})
+	for rows.Next() {
+		var guid, text, answer, category, difficulty, qType, imageGuid, audioGuid string
+		var requiresMedia bool
+		if err := rows.Scan(&guid, &text, &answer, &category, &difficulty, &qType, &imageGuid, &audioGuid, &requiresMedia); err != nil {
+			continue
+		}
+		_ = cw.Write([]string{guid, text, answer, category, difficulty, qType, imageGuid, audioGuid, strconv.FormatBool(requiresMedia)})
+	}
+	cw.Flush()
+}
+
 // --- Pack handlers ---
 
 func handleGetQuizPacks(w http.ResponseWriter, r *http.Request) {
 	rows, err := quizDB.Query(`
-		SELECT p.id, p.name, COALESCE(p.description,''), COALESCE(p.created_by,''), p.created_at,
+		SELECT p.id, p.guid::text, p.name, COALESCE(p.description,''), COALESCE(p.created_by,''), p.created_at,
 		       COUNT(r.id) as round_count
 		FROM quiz_packs p
 		LEFT JOIN rounds r ON r.pack_id = p.id
+		WHERE p.deleted_at IS NULL
 		GROUP BY p.id ORDER BY p.id DESC`)
 	if err != nil {
 		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
@@ -832,6 +917,7 @@ func handleGetQuizPacks(w http.ResponseWriter, r *http.Request) {
 
 	type Pack struct {
 		ID          int    `json:"id"`
+		Guid        string `json:"guid"`
 		Name        string `json:"name"`
 		Description string `json:"description"`
 		CreatedBy   string `json:"createdBy"`
@@ -842,7 +928,7 @@ func handleGetQuizPacks(w http.ResponseWriter, r *http.Request) {
 	packs := []Pack{}
 	for rows.Next() {
 		var p Pack
-		if err := rows.Scan(&p.ID, &p.Name, &p.Description, &p.CreatedBy, &p.CreatedAt, &p.RoundCount); err != nil {
+		if err := rows.Scan(&p.ID, &p.Guid, &p.Name, &p.Description, &p.CreatedBy, &p.CreatedAt, &p.RoundCount); err != nil {
 			continue
 		}
 		packs = append(packs, p)
@@ -856,7 +942,6 @@ func handleCreateQuizPack(w http.ResponseWriter, r *http.Request) {
 	var body struct {
 		Name        string `json:"name"`
 		Description string `json:"description"`
-		CreatedBy   string `json:"createdBy"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Name == "" {
 		http.Error(w, `{"error":"name required"}`, http.StatusBadRequest)
@@ -866,13 +951,14 @@ func handleCreateQuizPack(w http.ResponseWriter, r *http.Request) {
 	var id int
 	err := quizDB.QueryRow(
 		`INSERT INTO quiz_packs (name, description, created_by) VALUES ($1, $2, $3) RETURNING id`,
-		body.Name, nullableStr(body.Description), nullableStr(body.CreatedBy),
+		body.Name, nullableStr(body.Description), actorEmail(r),
 	).Scan(&id)
 	if err != nil {
 		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
 		return
 	}
 
+	logAuditChange(r, "quiz_pack_create", strconv.Itoa(id), nil, nil, snapshot(quizDB, quizPackSnapshot, id))
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{"id": id})
 }
@@ -884,12 +970,17 @@ func handleDeleteQuizPack(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_, err = quizDB.Exec(`DELETE FROM quiz_packs WHERE id = $1`, packID)
+	// Packs go to the trash; rounds and templates stay with them until the purge
+	before := snapshot(quizDB, quizPackSnapshot, packID)
+	_, err = quizDB.Exec(`
+		UPDATE quiz_packs SET deleted_at = NOW(), deleted_by = $2 WHERE id = $1 AND deleted_at IS NULL
+	`, packID, nullableStr(actorEmail(r)))
 	if err != nil {
 		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
 		return
 	}
 
+	logAuditChange(r, "quiz_pack_delete", strconv.Itoa(packID), nil, before, snapshot(quizDB, quizPackSnapshot, packID))
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{"status": "deleted"})
 }
@@ -903,13 +994,22 @@ func handleGetPackRounds(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	defer logQueryDuration("quiz pack rounds", time.Now())
+
+	// Rounds and their questions in one query: one row per question, or a
+	// single row with NULL question columns for an empty round
 	rows, err := quizDB.Query(`
-		SELECT r.id, r.round_number, r.name, r.type, COALESCE(r.time_limit_seconds, 0),
-		       COUNT(rq.id) as question_count
+		SELECT r.id, r.guid::text, r.round_number, r.name, r.type, COALESCE(r.time_limit_seconds, 0),
+		       COUNT(rq.id) OVER (PARTITION BY r.id) as question_count,
+		       rq.position, q.id, q.guid::text, q.text, q.answer, q.type,
+		       COALESCE(img.file_path,''), COALESCE(aud.file_path,'')
 		FROM rounds r
 		LEFT JOIN round_questions rq ON rq.round_id = r.id
+		LEFT JOIN questions q ON q.id = rq.question_id
+		LEFT JOIN media_files img ON img.id = q.image_id
+		LEFT JOIN media_files aud ON aud.id = q.audio_id
 		WHERE r.pack_id = $1
-		GROUP BY r.id ORDER BY r.round_number`, packID)
+		ORDER BY r.round_number, r.id, rq.position`, packID)
 	if err != nil {
 		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
 		return
@@ -918,58 +1018,45 @@ func handleGetPackRounds(w http.ResponseWriter, r *http.Request) {
 
 	type Round struct {
 		ID               int    `json:"id"`
+		Guid             string `json:"guid"`
 		RoundNumber      int    `json:"roundNumber"`
 		Name             string `json:"name"`
 		Type             string `json:"type"`
 		TimeLimitSeconds int    `json:"timeLimitSeconds"`
 		QuestionCount    int    `json:"questionCount"`
 	}
-
-	rounds := []Round{}
-	for rows.Next() {
-		var rd Round
-		if err := rows.Scan(&rd.ID, &rd.RoundNumber, &rd.Name, &rd.Type, &rd.TimeLimitSeconds, &rd.QuestionCount); err != nil {
-			continue
-		}
-		rounds = append(rounds, rd)
-	}
-
-	// Also fetch questions for each round
 	type RoundWithQuestions struct {
 		Round
 		Questions []map[string]interface{} `json:"questions"`
 	}
+
 	result := []RoundWithQuestions{}
-	for _, rd := range rounds {
-		qrows, err := quizDB.Query(`
-			SELECT rq.position, q.id, q.text, q.answer, q.type,
-			       COALESCE(img.file_path,''), COALESCE(aud.file_path,'')
-			FROM round_questions rq
-			JOIN questions q ON q.id = rq.question_id
-			LEFT JOIN media_files img ON img.id = q.image_id
-			LEFT JOIN media_files aud ON aud.id = q.audio_id
-			WHERE rq.round_id = $1
-			ORDER BY rq.position`, rd.ID)
-		questions := []map[string]interface{}{}
-		if err == nil {
-			for qrows.Next() {
-				var pos, qid int
-				var text, answer, qtype, imgPath, audPath string
-				if err := qrows.Scan(&pos, &qid, &text, &answer, &qtype, &imgPath, &audPath); err == nil {
-					questions = append(questions, map[string]interface{}{
-						"position":  pos,
-						"id":        qid,
-						"text":      text,
-						"answer":    answer,
-						"type":      qtype,
-						"imagePath": imgPath,
-						"audioPath": audPath,
-					})
-				}
-			}
-			qrows.Close()
+	for rows.Next() {
+		var rd Round
+		var pos, qid sql.NullInt64
+		var guid, text, answer, qtype sql.NullString
+		var imgPath, audPath string
+		if err := rows.Scan(&rd.ID, &rd.Guid, &rd.RoundNumber, &rd.Name, &rd.Type, &rd.TimeLimitSeconds, &rd.QuestionCount,
+			&pos, &qid, &guid, &text, &answer, &qtype, &imgPath, &audPath); err != nil {
+			continue
+		}
+		if len(result) == 0 || result[len(result)-1].ID != rd.ID {
+			result = append(result, RoundWithQuestions{Round: rd, Questions: []map[string]interface{}{}})
 		}
-		result = append(result, RoundWithQuestions{Round: rd, Questions: questions})
+		if !qid.Valid {
+			continue
+		}
+		last := &result[len(result)-1]
+		last.Questions = append(last.Questions, map[string]interface{}{
+			"position":  pos.Int64,
+			"id":        qid.Int64,
+			"guid":      guid.String,
+			"text":      text.String,
+			"answer":    answer.String,
+			"type":      qtype.String,
+			"imagePath": imgPath,
+			"audioPath": audPath,
+		})
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -998,6 +1085,7 @@ func handleCreatePackRound(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	before := snapshot(quizDB, quizPackSnapshot, packID)
 	var id int
 	err = quizDB.QueryRow(
 		`INSERT INTO rounds (pack_id, round_number, name, type, time_limit_seconds)
@@ -1010,6 +1098,8 @@ func handleCreatePackRound(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	logAuditChange(r, "quiz_round_create", strconv.Itoa(packID), map[string]interface{}{"roundId": id, "name": body.Name},
+		before, snapshot(quizDB, quizPackSnapshot, packID))
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{"id": id})
 }
@@ -1021,12 +1111,17 @@ func handleDeletePackRound(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	packID := mux.Vars(r)["packId"]
+	before := snapshot(quizDB, quizPackSnapshot, packID)
 	_, err = quizDB.Exec(`DELETE FROM rounds WHERE id = $1`, roundID)
 	if err != nil {
 		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
 		return
 	}
 
+	logAuditChange(r, "quiz_round_delete", packID, map[string]interface{}{"roundId": roundID},
+		before, snapshot(quizDB, quizPackSnapshot, packID))
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{"status": "deleted"})
 }
@@ -1068,6 +1163,9 @@ func handleSetRoundQuestions(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	packID := mux.Vars(r)["packId"]
+	before := snapshot(quizDB, quizPackSnapshot, packID)
+
 	tx, err := quizDB.Begin()
 	if err != nil {
 		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
@@ -1095,6 +1193,8 @@ func handleSetRoundQuestions(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	logAuditChange(r, "quiz_round_questions", packID, map[string]interface{}{"roundId": roundID, "questions": len(body.QuestionIDs)},
+		before, snapshot(quizDB, quizPackSnapshot, packID))
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{"updated": len(body.QuestionIDs)})
 }
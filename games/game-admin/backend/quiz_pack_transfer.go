@@ -0,0 +1,254 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/lib/pq"
+)
+
+// ============================================================
+// Quiz pack export / import
+// ============================================================
+//
+// Packs move between environments (dev → Pi) as JSON keyed by GUID rather
+// than serial ID: re-importing a pack updates the pack and rounds with the
+// same GUIDs instead of creating copies, and questions are found by their
+// GUID in the bank (import the questions CSV first).
+
+var guidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// validGuid reports whether s looks like a UUID
+func validGuid(s string) bool {
+	return guidPattern.MatchString(s)
+}
+
+// PackExport is a quiz pack as exported and imported
+type PackExport struct {
+	Guid        string        `json:"guid"`
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	Rounds      []RoundExport `json:"rounds"`
+}
+
+// RoundExport is a pack round with its questions, in order, by GUID
+type RoundExport struct {
+	Guid             string   `json:"guid"`
+	RoundNumber      int      `json:"roundNumber"`
+	Name             string   `json:"name"`
+	Type             string   `json:"type"`
+	TimeLimitSeconds *int     `json:"timeLimitSeconds"`
+	Questions        []string `json:"questions"`
+}
+
+// handleExportQuizPack - GET /api/quiz/packs/{packId}/export
+func handleExportQuizPack(w http.ResponseWriter, r *http.Request) {
+	defer logQueryDuration("quiz pack export", time.Now())
+
+	packID, err := strconv.Atoi(mux.Vars(r)["packId"])
+	if err != nil {
+		sendError(w, "Invalid pack ID", http.StatusBadRequest)
+		return
+	}
+
+	var pack PackExport
+	err = quizDB.QueryRow(`
+		SELECT guid::text, name, COALESCE(description, '') FROM quiz_packs WHERE id = $1
+	`, packID).Scan(&pack.Guid, &pack.Name, &pack.Description)
+	if err == sql.ErrNoRows {
+		sendError(w, "Pack not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		sendError(w, "Failed to export pack", http.StatusInternalServerError)
+		return
+	}
+
+	// Each round's question GUIDs come back as one ordered array
+	rows, err := quizDB.Query(`
+		SELECT r.guid::text, r.round_number, r.name, r.type, r.time_limit_seconds,
+		       COALESCE(array_agg(q.guid::text ORDER BY rq.position) FILTER (WHERE q.id IS NOT NULL), '{}')
+		FROM rounds r
+		LEFT JOIN round_questions rq ON rq.round_id = r.id
+		LEFT JOIN questions q ON q.id = rq.question_id
+		WHERE r.pack_id = $1
+		GROUP BY r.id
+		ORDER BY r.round_number
+	`, packID)
+	if err != nil {
+		sendError(w, "Failed to export pack", http.StatusInternalServerError)
+		return
+	}
+	pack.Rounds = []RoundExport{}
+	for rows.Next() {
+		rd := RoundExport{Questions: []string{}}
+		var timeLimit sql.NullInt64
+		if err := rows.Scan(&rd.Guid, &rd.RoundNumber, &rd.Name, &rd.Type, &timeLimit, pq.Array(&rd.Questions)); err != nil {
+			continue
+		}
+		if timeLimit.Valid {
+			v := int(timeLimit.Int64)
+			rd.TimeLimitSeconds = &v
+		}
+		pack.Rounds = append(pack.Rounds, rd)
+	}
+	rows.Close()
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="quiz-pack-%s.json"`, sanitizeFilename(pack.Name)))
+	sendJSON(w, pack)
+}
+
+// handleImportQuizPack - POST /api/quiz/packs/import
+// Body: a PackExport. Creates the pack, or updates the one with the same GUID
+// (its rounds are replaced by the imported ones, and it comes out of the trash
+// if it was deleted). Questions missing from the bank are left out of their
+// round and listed in the response.
+func handleImportQuizPack(w http.ResponseWriter, r *http.Request) {
+	if !requireWritePermission(w, r) {
+		return
+	}
+
+	var pack PackExport
+	if err := json.NewDecoder(r.Body).Decode(&pack); err != nil {
+		sendError(w, "Invalid pack file", http.StatusBadRequest)
+		return
+	}
+	pack.Name = strings.TrimSpace(pack.Name)
+	if !validGuid(pack.Guid) || pack.Name == "" {
+		sendError(w, "Pack needs a guid and a name", http.StatusBadRequest)
+		return
+	}
+	seen := map[int]bool{}
+	for _, rd := range pack.Rounds {
+		if !validGuid(rd.Guid) || rd.Name == "" {
+			sendError(w, "Every round needs a guid and a name", http.StatusBadRequest)
+			return
+		}
+		if rd.Type != "text" && rd.Type != "picture" && rd.Type != "music" && rd.Type != "photo" {
+			sendError(w, fmt.Sprintf("Round %q has an unknown type %q", rd.Name, rd.Type), http.StatusBadRequest)
+			return
+		}
+		if seen[rd.RoundNumber] {
+			sendError(w, fmt.Sprintf("Round number %d appears twice", rd.RoundNumber), http.StatusBadRequest)
+			return
+		}
+		seen[rd.RoundNumber] = true
+	}
+
+	// The pack as it was, when the import replaces one
+	var before json.RawMessage
+	var existingID int
+	if quizDB.QueryRow(`SELECT id FROM quiz_packs WHERE guid = $1::uuid`, pack.Guid).Scan(&existingID) == nil {
+		before = snapshot(quizDB, quizPackSnapshot, existingID)
+	}
+
+	tx, err := quizDB.Begin()
+	if err != nil {
+		sendError(w, "Failed to import pack", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	var packID int
+	created := false
+	err = tx.QueryRow(`
+		UPDATE quiz_packs SET name = $2, description = $3, deleted_at = NULL, deleted_by = NULL
+		WHERE guid = $1::uuid RETURNING id
+	`, pack.Guid, pack.Name, nullableStr(pack.Description)).Scan(&packID)
+	if err == sql.ErrNoRows {
+		created = true
+		err = tx.QueryRow(`
+			INSERT INTO quiz_packs (guid, name, description, created_by) VALUES ($1::uuid, $2, $3, $4) RETURNING id
+		`, pack.Guid, pack.Name, nullableStr(pack.Description), nullableStr(actorEmail(r))).Scan(&packID)
+	}
+	if err != nil {
+		log.Printf("Failed to import quiz pack %s: %v", pack.Guid, err)
+		sendError(w, "Failed to import pack", http.StatusInternalServerError)
+		return
+	}
+
+	// Rounds not in the file go; the rest move out of the way of the imported
+	// round numbers before being updated in place
+	keep := make([]string, 0, len(pack.Rounds))
+	for _, rd := range pack.Rounds {
+		keep = append(keep, rd.Guid)
+	}
+	if _, err := tx.Exec(`
+		DELETE FROM rounds WHERE pack_id = $1 AND NOT (guid::text = ANY($2))
+	`, packID, pq.Array(keep)); err != nil {
+		sendError(w, "Failed to import pack", http.StatusInternalServerError)
+		return
+	}
+	if _, err := tx.Exec(`UPDATE rounds SET round_number = -round_number - 1000 WHERE pack_id = $1`, packID); err != nil {
+		sendError(w, "Failed to import pack", http.StatusInternalServerError)
+		return
+	}
+
+	missing := []string{}
+	for _, rd := range pack.Rounds {
+		var roundID int
+		err := tx.QueryRow(`
+			UPDATE rounds SET round_number = $3, name = $4, type = $5, time_limit_seconds = $6
+			WHERE guid = $1::uuid AND pack_id = $2
+			RETURNING id
+		`, rd.Guid, packID, rd.RoundNumber, rd.Name, rd.Type, nullableInt(rd.TimeLimitSeconds)).Scan(&roundID)
+		if err == sql.ErrNoRows {
+			err = tx.QueryRow(`
+				INSERT INTO rounds (guid, pack_id, round_number, name, type, time_limit_seconds)
+				VALUES ($1::uuid, $2, $3, $4, $5, $6)
+				RETURNING id
+			`, rd.Guid, packID, rd.RoundNumber, rd.Name, rd.Type, nullableInt(rd.TimeLimitSeconds)).Scan(&roundID)
+		}
+		if err != nil {
+			log.Printf("Failed to import round %s of quiz pack %s: %v", rd.Guid, pack.Guid, err)
+			sendError(w, fmt.Sprintf("Failed to import round %q (is it already in another pack?)", rd.Name), http.StatusConflict)
+			return
+		}
+
+		if _, err := tx.Exec(`DELETE FROM round_questions WHERE round_id = $1`, roundID); err != nil {
+			sendError(w, "Failed to import pack", http.StatusInternalServerError)
+			return
+		}
+		position := 0
+		for _, questionGuid := range rd.Questions {
+			var questionID int
+			if !validGuid(questionGuid) ||
+				tx.QueryRow(`SELECT id FROM questions WHERE guid = $1::uuid`, questionGuid).Scan(&questionID) != nil {
+				missing = append(missing, questionGuid)
+				continue
+			}
+			position++
+			if _, err := tx.Exec(`
+				INSERT INTO round_questions (round_id, question_id, position) VALUES ($1, $2, $3)
+			`, roundID, questionID, position); err != nil {
+				sendError(w, "Failed to import pack", http.StatusInternalServerError)
+				return
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		sendError(w, "Failed to import pack", http.StatusInternalServerError)
+		return
+	}
+
+	logAuditChange(r, "quiz_pack_import", strconv.Itoa(packID), map[string]interface{}{
+		"guid":             pack.Guid,
+		"created":          created,
+		"rounds":           len(pack.Rounds),
+		"missingQuestions": len(missing),
+	}, before, snapshot(quizDB, quizPackSnapshot, packID))
+	sendJSON(w, map[string]interface{}{
+		"packId":           packID,
+		"created":          created,
+		"rounds":           len(pack.Rounds),
+		"missingQuestions": missing,
+	})
+}
@@ -0,0 +1,346 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// Round generator: builds a round from the question bank to a set of
+// constraints instead of the quiz master picking every question by hand.
+
+// difficultyCurves maps a curve name to the difficulty wanted at each point
+// of the round, as a fraction of the way through it. "any" has no preference.
+var difficultyCurves = map[string]func(progress float64) string{
+	"any":    func(float64) string { return "" },
+	"easy":   func(float64) string { return "easy" },
+	"medium": func(float64) string { return "medium" },
+	"hard":   func(float64) string { return "hard" },
+	"rising": func(p float64) string {
+		return []string{"easy", "medium", "hard"}[min(int(p*3), 2)]
+	},
+	"falling": func(p float64) string {
+		return []string{"hard", "medium", "easy"}[min(int(p*3), 2)]
+	},
+}
+
+type roundGenerateRequest struct {
+	Name               string         `json:"name"`
+	Type               string         `json:"type"`        // text | picture | music
+	RoundNumber        int            `json:"roundNumber"` // 0 = after the pack's last round
+	TimeLimitSeconds   *int           `json:"timeLimitSeconds"`
+	Count              int            `json:"count"`             // default 10
+	Categories         map[string]int `json:"categories"`        // questions per category; the rest come from any
+	DifficultyCurve    string         `json:"difficultyCurve"`   // any | easy | medium | hard | rising | falling
+	ExcludeRecentDays  int            `json:"excludeRecentDays"` // skip questions in packs played this recently
+	MediaRatio         *float64       `json:"mediaRatio"`        // share of questions with a picture or audio; null = don't mind
+	IncludeTestContent bool           `json:"includeTestContent"`
+}
+
+// bankQuestion is what the generator needs to know about a candidate
+type bankQuestion struct {
+	ID         int
+	Category   string
+	Difficulty string
+	HasMedia   bool
+}
+
+// roundSlot is what one position in the round calls for
+type roundSlot struct {
+	Category   string // "" = any
+	Difficulty string // "" = any
+	Media      *bool  // nil = either
+}
+
+func (s roundSlot) String() string {
+	desc := "any category"
+	if s.Category != "" {
+		desc = s.Category
+	}
+	if s.Media != nil {
+		if *s.Media {
+			desc += " with a picture or audio"
+		} else {
+			desc += " without media"
+		}
+	}
+	return desc
+}
+
+func (s roundSlot) matches(q bankQuestion, withDifficulty bool) bool {
+	if s.Category != "" && q.Category != s.Category {
+		return false
+	}
+	if s.Media != nil && q.HasMedia != *s.Media {
+		return false
+	}
+	return !withDifficulty || s.Difficulty == "" || q.Difficulty == s.Difficulty
+}
+
+// validate fills in defaults and checks the constraints can be met by a round of Count questions
+func (req *roundGenerateRequest) validate() error {
+	if req.Name == "" {
+		return fmt.Errorf("name required")
+	}
+	switch req.Type {
+	case "":
+		req.Type = "text"
+	case "text", "picture", "music":
+	case "photo":
+		return fmt.Errorf("photo rounds don't use the question bank")
+	default:
+		return fmt.Errorf("unknown round type %q", req.Type)
+	}
+	if req.Count == 0 {
+		req.Count = 10
+	}
+	if req.Count < 1 || req.Count > 50 {
+		return fmt.Errorf("count must be between 1 and 50")
+	}
+	if req.DifficultyCurve == "" {
+		req.DifficultyCurve = "any"
+	}
+	if _, ok := difficultyCurves[req.DifficultyCurve]; !ok {
+		return fmt.Errorf("unknown difficulty curve %q", req.DifficultyCurve)
+	}
+	total := 0
+	for category, n := range req.Categories {
+		if n < 0 {
+			return fmt.Errorf("category %s can't have a negative count", category)
+		}
+		total += n
+	}
+	if total > req.Count {
+		return fmt.Errorf("categories add up to %d but the round has %d questions", total, req.Count)
+	}
+	if req.MediaRatio != nil && (*req.MediaRatio < 0 || *req.MediaRatio > 1) {
+		return fmt.Errorf("mediaRatio must be between 0 and 1")
+	}
+	if req.ExcludeRecentDays < 0 {
+		return fmt.Errorf("excludeRecentDays can't be negative")
+	}
+	return nil
+}
+
+// roundSlots lays out what each position calls for. Categories and media are
+// spread randomly through the round; difficulty follows the curve.
+func roundSlots(req roundGenerateRequest) []roundSlot {
+	slots := make([]roundSlot, req.Count)
+
+	var categories []string
+	for category, n := range req.Categories {
+		for i := 0; i < n; i++ {
+			categories = append(categories, category)
+		}
+	}
+	for len(categories) < req.Count {
+		categories = append(categories, "")
+	}
+	rand.Shuffle(len(categories), func(i, j int) { categories[i], categories[j] = categories[j], categories[i] })
+
+	media := make([]*bool, req.Count)
+	if req.MediaRatio != nil {
+		withMedia := int(math.Round(*req.MediaRatio * float64(req.Count)))
+		for i, pos := range rand.Perm(req.Count) {
+			hasMedia := i < withMedia
+			media[pos] = &hasMedia
+		}
+	}
+
+	curve := difficultyCurves[req.DifficultyCurve]
+	for i := range slots {
+		slots[i] = roundSlot{
+			Category:   categories[i],
+			Difficulty: curve(float64(i) / float64(req.Count)),
+			Media:      media[i],
+		}
+	}
+	return slots
+}
+
+// pickRoundQuestions fills each slot from the pool. Slots with a category are
+// filled first as they're the hardest to satisfy. If no question has the
+// curve's difficulty the slot takes any difficulty (counted in relaxed);
+// category and media are never relaxed.
+func pickRoundQuestions(slots []roundSlot, pool []bankQuestion) (ids []int, relaxed int, err error) {
+	rand.Shuffle(len(pool), func(i, j int) { pool[i], pool[j] = pool[j], pool[i] })
+
+	order := make([]int, len(slots))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return slots[order[a]].Category != "" && slots[order[b]].Category == ""
+	})
+
+	used := make(map[int]bool)
+	ids = make([]int, len(slots))
+	for _, pos := range order {
+		slot := slots[pos]
+		found := false
+		for _, withDifficulty := range []bool{true, false} {
+			for _, q := range pool {
+				if !used[q.ID] && slot.matches(q, withDifficulty) {
+					used[q.ID] = true
+					ids[pos] = q.ID
+					found = true
+					break
+				}
+			}
+			if found {
+				if !withDifficulty && slot.Difficulty != "" {
+					relaxed++
+				}
+				break
+			}
+		}
+		if !found {
+			return nil, 0, fmt.Errorf("not enough questions in the bank for %s", slot)
+		}
+	}
+	return ids, relaxed, nil
+}
+
+// loadQuestionPool returns the questions a generated round may use: not
+// tie-breakers, not missing required media, not already in the pack, and not
+// in a pack played in the last excludeRecentDays days
+func loadQuestionPool(packID int, req roundGenerateRequest) ([]bankQuestion, error) {
+	rows, err := quizDB.Query(`
+		SELECT q.id, COALESCE(q.category,''), COALESCE(q.difficulty,'medium'),
+		       (q.image_id IS NOT NULL OR q.audio_id IS NOT NULL OR q.image_clip_id IS NOT NULL OR q.audio_clip_id IS NOT NULL)
+		FROM questions q
+		WHERE q.tiebreak IS NULL
+		  AND NOT (q.requires_media AND q.image_clip_id IS NULL AND q.audio_clip_id IS NULL)
+		  AND ($1 OR NOT COALESCE(q.is_test_content, false))
+		  AND ($2 = 'text' OR q.type = $2)
+		  AND NOT EXISTS (
+		      SELECT 1 FROM round_questions rq JOIN rounds r ON r.id = rq.round_id
+		      WHERE rq.question_id = q.id AND r.pack_id = $3)
+		  AND ($4 = 0 OR NOT EXISTS (
+		      SELECT 1 FROM sessions s
+		      JOIN rounds r ON r.pack_id = s.pack_id
+		      JOIN round_questions rq ON rq.round_id = r.id
+		      WHERE rq.question_id = q.id AND s.started_at > NOW() - make_interval(days => $4)))`,
+		req.IncludeTestContent, req.Type, packID, req.ExcludeRecentDays)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pool []bankQuestion
+	for rows.Next() {
+		var q bankQuestion
+		if err := rows.Scan(&q.ID, &q.Category, &q.Difficulty, &q.HasMedia); err != nil {
+			return nil, err
+		}
+		pool = append(pool, q)
+	}
+	return pool, rows.Err()
+}
+
+func handleGeneratePackRound(w http.ResponseWriter, r *http.Request) {
+	packID, err := strconv.Atoi(mux.Vars(r)["packId"])
+	if err != nil {
+		http.Error(w, `{"error":"invalid packId"}`, http.StatusBadRequest)
+		return
+	}
+
+	var body roundGenerateRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, `{"error":"invalid JSON"}`, http.StatusBadRequest)
+		return
+	}
+	if err := body.validate(); err != nil {
+		http.Error(w, fmt.Sprintf(`{"error":%q}`, err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	var exists bool
+	if err := quizDB.QueryRow(
+		`SELECT EXISTS(SELECT 1 FROM quiz_packs WHERE id = $1 AND deleted_at IS NULL)`, packID,
+	).Scan(&exists); err != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+	if !exists {
+		http.Error(w, `{"error":"pack not found"}`, http.StatusNotFound)
+		return
+	}
+
+	pool, err := loadQuestionPool(packID, body)
+	if err != nil {
+		log.Printf("generate round pool error: %v", err)
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	questionIDs, relaxed, err := pickRoundQuestions(roundSlots(body), pool)
+	if err != nil {
+		http.Error(w, fmt.Sprintf(`{"error":%q}`, err.Error()), http.StatusUnprocessableEntity)
+		return
+	}
+
+	before := snapshot(quizDB, quizPackSnapshot, packID)
+
+	tx, err := quizDB.Begin()
+	if err != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	roundNumber := body.RoundNumber
+	if roundNumber == 0 {
+		if err := tx.QueryRow(
+			`SELECT COALESCE(MAX(round_number), 0) + 1 FROM rounds WHERE pack_id = $1`, packID,
+		).Scan(&roundNumber); err != nil {
+			http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	var roundID int
+	err = tx.QueryRow(
+		`INSERT INTO rounds (pack_id, round_number, name, type, time_limit_seconds)
+		 VALUES ($1, $2, $3, $4, $5) RETURNING id`,
+		packID, roundNumber, body.Name, body.Type, nullableInt(body.TimeLimitSeconds),
+	).Scan(&roundID)
+	if err != nil {
+		log.Printf("generate round error: %v", err)
+		http.Error(w, `{"error":"database error (round number may be duplicate)"}`, http.StatusInternalServerError)
+		return
+	}
+
+	for pos, qid := range questionIDs {
+		if _, err := tx.Exec(
+			`INSERT INTO round_questions (round_id, question_id, position) VALUES ($1, $2, $3)`,
+			roundID, qid, pos+1,
+		); err != nil {
+			http.Error(w, `{"error":"database error inserting question"}`, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	logAuditChange(r, "quiz_round_generate", strconv.Itoa(packID),
+		map[string]interface{}{"roundId": roundID, "name": body.Name, "questions": len(questionIDs)},
+		before, snapshot(quizDB, quizPackSnapshot, packID))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":                roundID,
+		"roundNumber":       roundNumber,
+		"questionIds":       questionIDs,
+		"relaxedDifficulty": relaxed,
+	})
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/achgithub/activity-hub-common/accounts"
+	"github.com/achgithub/activity-hub-common/activity"
+	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/go-redis/redis/v8"
+)
+
+var redisClient *redis.Client
+
+func initRedis() {
+	redisClient = redis.NewClient(&redis.Options{
+		Addr: "127.0.0.1:6379",
+	})
+	ctx := context.Background()
+	if err := redisClient.Ping(ctx).Err(); err != nil {
+		log.Printf("Warning: Redis not available: %v", err)
+	}
+}
+
+// sweepCompetitionChannel matches the sweepstakes app's per-competition stream.
+func sweepCompetitionChannel(compID string) string {
+	return fmt.Sprintf("sweepstakes:competition:%s:events", compID)
+}
+
+// publishSweepEvent pushes an update to players watching a sweepstakes competition.
+// Failures are logged only: players still see the change on their next load.
+func publishSweepEvent(compID string, eventType string, payload interface{}) {
+	ctx := context.Background()
+	data, err := json.Marshal(map[string]interface{}{
+		"type":    eventType,
+		"payload": payload,
+	})
+	if err != nil {
+		return
+	}
+	if err := redisClient.Publish(ctx, sweepCompetitionChannel(compID), string(data)).Err(); err != nil {
+		log.Printf("Error publishing sweepstakes %s event: %v", eventType, err)
+	}
+}
+
+// publishSweepAvailability sends the competition's current available-entry count.
+func publishSweepAvailability(compID string) {
+	var count int
+	sweepstakesDB.QueryRow(`SELECT COUNT(*) FROM entries WHERE competition_id = $1 AND status = 'available'`, compID).Scan(&count)
+	publishSweepEvent(compID, "availability", map[string]int{"count": count})
+}
+
+// publishLMSEliminations puts a processed round's knockouts on the platform
+// activity feed. Private games stay off it. Failures are logged only.
+func publishLMSEliminations(gameID int, label string, userIDs []string) {
+	if len(userIDs) == 0 {
+		return
+	}
+	var gameName string
+	var isPrivate bool
+	if err := lmsDB.QueryRow(`SELECT name, COALESCE(is_private, FALSE) FROM games WHERE id = $1`, gameID).
+		Scan(&gameName, &isPrivate); err != nil || isPrivate {
+		return
+	}
+
+	names := authlib.PublicNames(identityDB, userIDs)
+	players := make([]string, 0, len(userIDs))
+	for _, id := range userIDs {
+		players = append(players, names[id])
+	}
+	sort.Strings(players)
+
+	text := fmt.Sprintf("%s went out of %s in round %s", players[0], gameName, label)
+	if len(players) > 1 {
+		text = fmt.Sprintf("%d players went out of %s in round %s", len(players), gameName, label)
+	}
+	msg, err := activity.Encode(activity.Event{
+		Type:    activity.TypeLMSElimination,
+		App:     "last-man-standing",
+		Text:    text,
+		Players: players,
+	})
+	if err != nil {
+		log.Printf("Invalid LMS activity event: %v", err)
+		return
+	}
+	if err := redisClient.Publish(context.Background(), activity.Channel, msg).Err(); err != nil {
+		log.Printf("Error publishing LMS activity event: %v", err)
+	}
+}
+
+// runAccountMerges moves a merged account's LMS games and picks onto the kept
+// account, the same as an admin merge, and audits it under the super user who
+// merged them. Runs for the life of the process.
+func runAccountMerges() {
+	pubsub := redisClient.Subscribe(context.Background(), accounts.MergedChannel)
+	defer pubsub.Close()
+
+	for msg := range pubsub.Channel() {
+		m, err := accounts.Decode(msg.Payload)
+		if err != nil {
+			log.Printf("Ignoring account merge: %v", err)
+			continue
+		}
+		res, err := mergeLMSPlayers(m.From, m.To)
+		if err != nil {
+			log.Printf("Error merging LMS player %s into %s: %v", m.From, m.To, err)
+			continue
+		}
+		if res.empty() {
+			continue
+		}
+		details := res.details()
+		details["identityMerge"] = m.ID
+		writeAudit(m.MergedBy, "", "lms_player_merge", m.From+" -> "+m.To, details)
+	}
+}
@@ -11,8 +11,11 @@ import (
 
 // --- Managed Players ---
 
+// Guid identifies a player, group or team across environments (IDs are
+// per-database serials); LMS/Sweepstakes match re-imports on it.
 type ManagedPlayer struct {
 	ID           int    `json:"id"`
+	Guid         string `json:"guid"`
 	ManagerEmail string `json:"managerEmail"`
 	Name         string `json:"name"`
 	CreatedAt    string `json:"createdAt"`
@@ -20,6 +23,7 @@ type ManagedPlayer struct {
 
 type ManagedGroup struct {
 	ID           int    `json:"id"`
+	Guid         string `json:"guid"`
 	ManagerEmail string `json:"managerEmail"`
 	Name         string `json:"name"`
 	Description  string `json:"description"`
@@ -28,6 +32,7 @@ type ManagedGroup struct {
 
 type ManagedTeam struct {
 	ID        int    `json:"id"`
+	Guid      string `json:"guid"`
 	GroupID   int    `json:"groupId"`
 	Name      string `json:"name"`
 	CreatedAt string `json:"createdAt"`
@@ -57,7 +62,7 @@ func handleGetPlayers(w http.ResponseWriter, r *http.Request) {
 	}
 
 	rows, err := gameAdminDB.Query(`
-		SELECT id, manager_email, name, created_at
+		SELECT id, guid::text, manager_email, name, created_at
 		FROM managed_players
 		WHERE manager_email = $1
 		ORDER BY created_at DESC
@@ -72,7 +77,7 @@ func handleGetPlayers(w http.ResponseWriter, r *http.Request) {
 	var players []ManagedPlayer
 	for rows.Next() {
 		var p ManagedPlayer
-		if err := rows.Scan(&p.ID, &p.ManagerEmail, &p.Name, &p.CreatedAt); err != nil {
+		if err := rows.Scan(&p.ID, &p.Guid, &p.ManagerEmail, &p.Name, &p.CreatedAt); err != nil {
 			continue
 		}
 		players = append(players, p)
@@ -114,8 +119,8 @@ func handleCreatePlayer(w http.ResponseWriter, r *http.Request) {
 	err := gameAdminDB.QueryRow(`
 		INSERT INTO managed_players (manager_email, name)
 		VALUES ($1, $2)
-		RETURNING id, manager_email, name, created_at
-	`, managerEmail, req.Name).Scan(&player.ID, &player.ManagerEmail, &player.Name, &player.CreatedAt)
+		RETURNING id, guid::text, manager_email, name, created_at
+	`, managerEmail, req.Name).Scan(&player.ID, &player.Guid, &player.ManagerEmail, &player.Name, &player.CreatedAt)
 
 	if err != nil {
 		log.Printf("Error creating player: %v", err)
@@ -176,7 +181,7 @@ func handleGetGroups(w http.ResponseWriter, r *http.Request) {
 	}
 
 	rows, err := gameAdminDB.Query(`
-		SELECT id, manager_email, name, description, created_at
+		SELECT id, guid::text, manager_email, name, description, created_at
 		FROM managed_groups
 		WHERE manager_email = $1
 		ORDER BY created_at DESC
@@ -191,7 +196,7 @@ func handleGetGroups(w http.ResponseWriter, r *http.Request) {
 	var groups []ManagedGroup
 	for rows.Next() {
 		var g ManagedGroup
-		if err := rows.Scan(&g.ID, &g.ManagerEmail, &g.Name, &g.Description, &g.CreatedAt); err != nil {
+		if err := rows.Scan(&g.ID, &g.Guid, &g.ManagerEmail, &g.Name, &g.Description, &g.CreatedAt); err != nil {
 			continue
 		}
 		groups = append(groups, g)
@@ -234,8 +239,8 @@ func handleCreateGroup(w http.ResponseWriter, r *http.Request) {
 	err := gameAdminDB.QueryRow(`
 		INSERT INTO managed_groups (manager_email, name, description)
 		VALUES ($1, $2, $3)
-		RETURNING id, manager_email, name, description, created_at
-	`, managerEmail, req.Name, req.Description).Scan(&group.ID, &group.ManagerEmail, &group.Name, &group.Description, &group.CreatedAt)
+		RETURNING id, guid::text, manager_email, name, description, created_at
+	`, managerEmail, req.Name, req.Description).Scan(&group.ID, &group.Guid, &group.ManagerEmail, &group.Name, &group.Description, &group.CreatedAt)
 
 	if err != nil {
 		log.Printf("Error creating group: %v", err)
@@ -315,7 +320,7 @@ func handleGetTeams(w http.ResponseWriter, r *http.Request) {
 	}
 
 	rows, err := gameAdminDB.Query(`
-		SELECT id, group_id, name, created_at
+		SELECT id, guid::text, group_id, name, created_at
 		FROM managed_teams
 		WHERE group_id = $1
 		ORDER BY created_at ASC
@@ -330,7 +335,7 @@ func handleGetTeams(w http.ResponseWriter, r *http.Request) {
 	var teams []ManagedTeam
 	for rows.Next() {
 		var t ManagedTeam
-		if err := rows.Scan(&t.ID, &t.GroupID, &t.Name, &t.CreatedAt); err != nil {
+		if err := rows.Scan(&t.ID, &t.Guid, &t.GroupID, &t.Name, &t.CreatedAt); err != nil {
 			continue
 		}
 		teams = append(teams, t)
@@ -391,8 +396,8 @@ func handleCreateTeam(w http.ResponseWriter, r *http.Request) {
 	err = gameAdminDB.QueryRow(`
 		INSERT INTO managed_teams (group_id, name)
 		VALUES ($1, $2)
-		RETURNING id, group_id, name, created_at
-	`, groupID, req.Name).Scan(&team.ID, &team.GroupID, &team.Name, &team.CreatedAt)
+		RETURNING id, guid::text, group_id, name, created_at
+	`, groupID, req.Name).Scan(&team.ID, &team.Guid, &team.GroupID, &team.Name, &team.CreatedAt)
 
 	if err != nil {
 		log.Printf("Error creating team: %v", err)
@@ -469,7 +474,7 @@ func handleExportPlayers(w http.ResponseWriter, r *http.Request) {
 	}
 
 	rows, err := gameAdminDB.Query(`
-		SELECT id, manager_email, name, created_at
+		SELECT id, guid::text, manager_email, name, created_at
 		FROM managed_players
 		WHERE manager_email = $1
 		ORDER BY name ASC
@@ -484,7 +489,7 @@ func handleExportPlayers(w http.ResponseWriter, r *http.Request) {
 	var players []ManagedPlayer
 	for rows.Next() {
 		var p ManagedPlayer
-		if err := rows.Scan(&p.ID, &p.ManagerEmail, &p.Name, &p.CreatedAt); err != nil {
+		if err := rows.Scan(&p.ID, &p.Guid, &p.ManagerEmail, &p.Name, &p.CreatedAt); err != nil {
 			continue
 		}
 		players = append(players, p)
@@ -507,7 +512,7 @@ func handleExportGroups(w http.ResponseWriter, r *http.Request) {
 	}
 
 	rows, err := gameAdminDB.Query(`
-		SELECT id, manager_email, name, description, created_at
+		SELECT id, guid::text, manager_email, name, description, created_at
 		FROM managed_groups
 		WHERE manager_email = $1
 		ORDER BY name ASC
@@ -527,13 +532,13 @@ func handleExportGroups(w http.ResponseWriter, r *http.Request) {
 	var groups []GroupWithTeams
 	for rows.Next() {
 		var g GroupWithTeams
-		if err := rows.Scan(&g.ID, &g.ManagerEmail, &g.Name, &g.Description, &g.CreatedAt); err != nil {
+		if err := rows.Scan(&g.ID, &g.Guid, &g.ManagerEmail, &g.Name, &g.Description, &g.CreatedAt); err != nil {
 			continue
 		}
 
 		// Fetch teams for this group
 		teamRows, err := gameAdminDB.Query(`
-			SELECT id, group_id, name, created_at
+			SELECT id, guid::text, group_id, name, created_at
 			FROM managed_teams
 			WHERE group_id = $1
 			ORDER BY name ASC
@@ -546,7 +551,7 @@ func handleExportGroups(w http.ResponseWriter, r *http.Request) {
 			var teams []ManagedTeam
 			for teamRows.Next() {
 				var t ManagedTeam
-				if err := teamRows.Scan(&t.ID, &t.GroupID, &t.Name, &t.CreatedAt); err != nil {
+				if err := teamRows.Scan(&t.ID, &t.Guid, &t.GroupID, &t.Name, &t.CreatedAt); err != nil {
 					continue
 				}
 				teams = append(teams, t)
@@ -0,0 +1,324 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// ============================================================
+// Sweepstakes stages (group stage, then knockout, ...)
+// ============================================================
+
+// A competition with stages runs them in order. The first stage takes every
+// entry; each later stage takes the entries that went through the one before,
+// carrying their position there. Entries' own position stays the final
+// finishing position, set as before.
+
+type SweepStageEntry struct {
+	EntryID         int    `json:"entry_id"`
+	EntryName       string `json:"entry_name"`
+	Group           string `json:"group,omitempty"`
+	CarriedPosition *int   `json:"carried_position"`
+	Position        *int   `json:"position"`
+	Outcome         string `json:"outcome"` // playing, through, out
+}
+
+type SweepStage struct {
+	ID          int               `json:"id"`
+	StageNumber int               `json:"stage_number"`
+	Name        string            `json:"name"`
+	Status      string            `json:"status"` // active, completed
+	Entries     []SweepStageEntry `json:"entries"`
+}
+
+var sweepStageOutcomes = map[string]bool{"playing": true, "through": true, "out": true}
+
+// handleGetSweepStages returns a competition's stages with each entry's part in them.
+func handleGetSweepStages(w http.ResponseWriter, r *http.Request) {
+	compID := mux.Vars(r)["id"]
+	stages := []SweepStage{}
+	rows, err := sweepstakesDB.Query(`
+		SELECT id, stage_number, name, status FROM stages
+		WHERE competition_id = $1 ORDER BY stage_number
+	`, compID)
+	if err != nil {
+		sendError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	index := map[int]int{}
+	for rows.Next() {
+		var s SweepStage
+		if err := rows.Scan(&s.ID, &s.StageNumber, &s.Name, &s.Status); err != nil {
+			continue
+		}
+		s.Entries = []SweepStageEntry{}
+		index[s.ID] = len(stages)
+		stages = append(stages, s)
+	}
+	rows.Close()
+
+	rows, err = sweepstakesDB.Query(`
+		SELECT se.stage_id, se.entry_id, e.name, COALESCE(se.group_name, ''),
+		       se.carried_position, se.position, se.outcome
+		FROM stage_entries se
+		JOIN stages s ON s.id = se.stage_id
+		JOIN entries e ON e.id = se.entry_id
+		WHERE s.competition_id = $1
+		ORDER BY se.stage_id, COALESCE(se.group_name, ''), COALESCE(se.position, 999),
+		         COALESCE(se.carried_position, 999), e.name
+	`, compID)
+	if err != nil {
+		sendError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var stageID int
+		var e SweepStageEntry
+		var carried, position sql.NullInt64
+		if err := rows.Scan(&stageID, &e.EntryID, &e.EntryName, &e.Group, &carried, &position, &e.Outcome); err != nil {
+			continue
+		}
+		e.CarriedPosition = nullIntPtr(carried)
+		e.Position = nullIntPtr(position)
+		if i, ok := index[stageID]; ok {
+			stages[i].Entries = append(stages[i].Entries, e)
+		}
+	}
+	sendJSON(w, map[string]interface{}{"stages": stages})
+}
+
+// handleCreateSweepStage starts a competition's next stage. The previous stage
+// must be completed; its entries that went through join the new one.
+func handleCreateSweepStage(w http.ResponseWriter, r *http.Request) {
+	if !requireWritePermission(w, r) {
+		return
+	}
+	compID := mux.Vars(r)["id"]
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || strings.TrimSpace(req.Name) == "" {
+		sendError(w, "name is required", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := sweepstakesDB.Begin()
+	if err != nil {
+		sendError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	// Lock the competition so two admins can't add the same stage number
+	var exists bool
+	if err := tx.QueryRow(`SELECT TRUE FROM competitions WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, compID).Scan(&exists); err != nil {
+		sendError(w, "Competition not found", http.StatusNotFound)
+		return
+	}
+
+	var prevID, prevNumber int
+	var prevStatus string
+	err = tx.QueryRow(`
+		SELECT id, stage_number, status FROM stages
+		WHERE competition_id = $1 ORDER BY stage_number DESC LIMIT 1
+	`, compID).Scan(&prevID, &prevNumber, &prevStatus)
+	if err != nil && err != sql.ErrNoRows {
+		sendError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if err == nil && prevStatus != "completed" {
+		sendError(w, "Complete the current stage before starting the next", http.StatusConflict)
+		return
+	}
+
+	var stageID int
+	err = tx.QueryRow(`
+		INSERT INTO stages (competition_id, stage_number, name) VALUES ($1, $2, $3) RETURNING id
+	`, compID, prevNumber+1, strings.TrimSpace(req.Name)).Scan(&stageID)
+	if err != nil {
+		sendError(w, "Failed to create stage: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var result sql.Result
+	if prevID == 0 {
+		result, err = tx.Exec(`
+			INSERT INTO stage_entries (stage_id, entry_id)
+			SELECT $1, id FROM entries WHERE competition_id = $2
+		`, stageID, compID)
+	} else {
+		result, err = tx.Exec(`
+			INSERT INTO stage_entries (stage_id, entry_id, carried_position)
+			SELECT $1, entry_id, position FROM stage_entries
+			WHERE stage_id = $2 AND outcome = 'through'
+		`, stageID, prevID)
+	}
+	if err != nil {
+		sendError(w, "Failed to add entries to stage: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		sendError(w, "Failed to create stage: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	added, _ := result.RowsAffected()
+	publishSweepEvent(compID, "stage", map[string]interface{}{
+		"stage_id": stageID, "stage_number": prevNumber + 1, "name": strings.TrimSpace(req.Name), "status": "active",
+	})
+	logAudit(r, "sweep_stage_create", strconv.Itoa(stageID), map[string]interface{}{
+		"competition_id": compID, "name": req.Name, "entries": added,
+	})
+	sendJSON(w, map[string]interface{}{"id": stageID, "stage_number": prevNumber + 1, "entries": added})
+}
+
+// handleUpdateSweepStageEntry records how an entry is doing in an active
+// stage: its group, its position and whether it's through or out.
+func handleUpdateSweepStageEntry(w http.ResponseWriter, r *http.Request) {
+	if !requireWritePermission(w, r) {
+		return
+	}
+	vars := mux.Vars(r)
+	stageID, entryID := vars["id"], vars["entryId"]
+	var req struct {
+		Group    string `json:"group"`
+		Position *int   `json:"position"`
+		Outcome  string `json:"outcome"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendError(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+	if req.Outcome == "" {
+		req.Outcome = "playing"
+	}
+	if !sweepStageOutcomes[req.Outcome] {
+		sendError(w, "outcome must be playing, through or out", http.StatusBadRequest)
+		return
+	}
+
+	var compID, status string
+	err := sweepstakesDB.QueryRow(`SELECT competition_id, status FROM stages WHERE id = $1`, stageID).Scan(&compID, &status)
+	if err == sql.ErrNoRows {
+		sendError(w, "Stage not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		sendError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if status != "active" {
+		sendError(w, "Stage is completed", http.StatusConflict)
+		return
+	}
+
+	group := strings.TrimSpace(req.Group)
+	result, err := sweepstakesDB.Exec(`
+		UPDATE stage_entries
+		SET group_name = NULLIF($1, ''), position = $2, outcome = $3, updated_at = CURRENT_TIMESTAMP
+		WHERE stage_id = $4 AND entry_id = $5
+	`, group, req.Position, req.Outcome, stageID, entryID)
+	if err != nil {
+		sendError(w, "Failed to update entry: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		sendError(w, "Entry is not in this stage", http.StatusNotFound)
+		return
+	}
+
+	sid, _ := strconv.Atoi(stageID)
+	eid, _ := strconv.Atoi(entryID)
+	publishSweepEvent(compID, "stage_entry", map[string]interface{}{
+		"stage_id": sid, "entry_id": eid, "group": group, "position": req.Position, "outcome": req.Outcome,
+	})
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleCompleteSweepStage closes an active stage once every entry in it is
+// through or out, so the next stage can start.
+func handleCompleteSweepStage(w http.ResponseWriter, r *http.Request) {
+	if !requireWritePermission(w, r) {
+		return
+	}
+	stageID := mux.Vars(r)["id"]
+
+	var compID, status string
+	var playing int
+	err := sweepstakesDB.QueryRow(`
+		SELECT s.competition_id, s.status,
+		       (SELECT COUNT(*) FROM stage_entries WHERE stage_id = s.id AND outcome = 'playing')
+		FROM stages s WHERE s.id = $1
+	`, stageID).Scan(&compID, &status, &playing)
+	if err == sql.ErrNoRows {
+		sendError(w, "Stage not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		sendError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if status != "active" {
+		sendError(w, "Stage is already completed", http.StatusConflict)
+		return
+	}
+	if playing > 0 {
+		sendError(w, fmt.Sprintf("%d entries are still playing - mark each one through or out first", playing), http.StatusBadRequest)
+		return
+	}
+
+	if _, err := sweepstakesDB.Exec(`
+		UPDATE stages SET status = 'completed', completed_at = CURRENT_TIMESTAMP WHERE id = $1
+	`, stageID); err != nil {
+		sendError(w, "Failed to complete stage: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	sid, _ := strconv.Atoi(stageID)
+	publishSweepEvent(compID, "stage", map[string]interface{}{"stage_id": sid, "status": "completed"})
+	logAudit(r, "sweep_stage_complete", stageID, map[string]interface{}{"competition_id": compID})
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleDeleteSweepStage removes a competition's latest stage, e.g. one
+// started too early. The stage before it stays completed.
+func handleDeleteSweepStage(w http.ResponseWriter, r *http.Request) {
+	if !requireWritePermission(w, r) {
+		return
+	}
+	stageID := mux.Vars(r)["id"]
+
+	var compID string
+	err := sweepstakesDB.QueryRow(`
+		DELETE FROM stages s
+		WHERE s.id = $1 AND s.stage_number = (
+		    SELECT MAX(stage_number) FROM stages WHERE competition_id = s.competition_id
+		)
+		RETURNING s.competition_id
+	`, stageID).Scan(&compID)
+	if err == sql.ErrNoRows {
+		sendError(w, "Stage not found, or not the latest stage", http.StatusNotFound)
+		return
+	} else if err != nil {
+		sendError(w, "Failed to delete stage: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	sid, _ := strconv.Atoi(stageID)
+	publishSweepEvent(compID, "stage", map[string]interface{}{"stage_id": sid, "status": "deleted"})
+	logAudit(r, "sweep_stage_delete", stageID, map[string]interface{}{"competition_id": compID})
+	w.WriteHeader(http.StatusOK)
+}
+
+func nullIntPtr(v sql.NullInt64) *int {
+	if !v.Valid {
+		return nil
+	}
+	n := int(v.Int64)
+	return &n
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/achgithub/activity-hub-common/config"
+	"github.com/achgithub/activity-hub-common/hours"
+	"github.com/gorilla/mux"
+)
+
+// ============================================================
+// Trash
+// ============================================================
+//
+// Deleting an LMS game, sweepstakes competition or quiz pack only sets its
+// deleted_at, so a mistake can be undone from the Trash tab. Anything left in
+// the trash longer than TRASH_RETENTION_DAYS (default 30) is purged for good.
+
+const defaultTrashRetentionDays = 30
+
+// trashKind is a type of record that goes to the trash when deleted
+type trashKind struct {
+	db    func() *sql.DB
+	table string
+	label string
+	purge []string // statements run, in order, for rows deleted before $1
+}
+
+var trashKinds = map[string]trashKind{
+	"lms-game": {
+		db:    func() *sql.DB { return lmsDB },
+		table: "games",
+		label: "LMS game",
+		purge: []string{
+			`DELETE FROM predictions WHERE game_id IN (SELECT id FROM games WHERE deleted_at < $1)`,
+			`DELETE FROM game_players WHERE game_id IN (SELECT id FROM games WHERE deleted_at < $1)`,
+			`DELETE FROM rounds WHERE game_id IN (SELECT id FROM games WHERE deleted_at < $1)`,
+			`DELETE FROM games WHERE deleted_at < $1`,
+		},
+	},
+	"sweep-competition": {
+		db:    func() *sql.DB { return sweepstakesDB },
+		table: "competitions",
+		label: "Sweepstakes competition",
+		purge: []string{`DELETE FROM competitions WHERE deleted_at < $1`},
+	},
+	"quiz-pack": {
+		db:    func() *sql.DB { return quizDB },
+		table: "quiz_packs",
+		label: "Quiz pack",
+		// Packs that sessions were run from stay; their history needs them
+		purge: []string{`
+			DELETE FROM quiz_packs p WHERE p.deleted_at < $1
+			AND NOT EXISTS (SELECT 1 FROM sessions s WHERE s.pack_id = p.id)
+		`},
+	},
+}
+
+// TrashItem is a deleted record awaiting restore or purge
+type TrashItem struct {
+	Type      string    `json:"type"`
+	Label     string    `json:"label"`
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	DeletedAt time.Time `json:"deletedAt"`
+	DeletedBy string    `json:"deletedBy"`
+	PurgeAt   time.Time `json:"purgeAt"`
+}
+
+func trashRetentionDays() int {
+	days, err := strconv.Atoi(config.GetEnv("TRASH_RETENTION_DAYS", ""))
+	if err != nil || days < 1 {
+		return defaultTrashRetentionDays
+	}
+	return days
+}
+
+// handleGetTrash - GET /api/trash
+func handleGetTrash(w http.ResponseWriter, r *http.Request) {
+	retentionDays := trashRetentionDays()
+	items := []TrashItem{}
+	for kind, k := range trashKinds {
+		rows, err := k.db().Query(`
+			SELECT id, name, deleted_at, COALESCE(deleted_by, '') FROM ` + k.table + `
+			WHERE deleted_at IS NOT NULL
+		`)
+		if err != nil {
+			log.Printf("Error getting trashed %s: %v", k.table, err)
+			sendError(w, "Failed to load trash", http.StatusInternalServerError)
+			return
+		}
+		for rows.Next() {
+			item := TrashItem{Type: kind, Label: k.label}
+			if err := rows.Scan(&item.ID, &item.Name, &item.DeletedAt, &item.DeletedBy); err != nil {
+				continue
+			}
+			item.PurgeAt = item.DeletedAt.AddDate(0, 0, retentionDays)
+			items = append(items, item)
+		}
+		rows.Close()
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].DeletedAt.After(items[j].DeletedAt) })
+
+	sendJSON(w, map[string]interface{}{"items": items, "retentionDays": retentionDays})
+}
+
+// handleRestoreTrash - POST /api/trash/{type}/{id}/restore
+func handleRestoreTrash(w http.ResponseWriter, r *http.Request) {
+	if !requireWritePermission(w, r) {
+		return
+	}
+
+	vars := mux.Vars(r)
+	k, ok := trashKinds[vars["type"]]
+	if !ok {
+		sendError(w, "Unknown trash item type", http.StatusBadRequest)
+		return
+	}
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		sendError(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	result, err := k.db().Exec(`
+		UPDATE `+k.table+` SET deleted_at = NULL, deleted_by = NULL WHERE id = $1 AND deleted_at IS NOT NULL
+	`, id)
+	if err != nil {
+		sendError(w, "Failed to restore", http.StatusInternalServerError)
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		sendError(w, "Not found in trash", http.StatusNotFound)
+		return
+	}
+
+	logAudit(r, "trash_restore", vars["id"], map[string]interface{}{"type": vars["type"]})
+	sendJSON(w, map[string]interface{}{"success": true})
+}
+
+// runTrashPurge permanently removes trash older than the retention period,
+// on startup and then hourly. While any venue is open the purge waits, so
+// the large deletes don't compete with players.
+func runTrashPurge(openingHours *hours.Hours) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		if !openingHours.Peak(time.Now()) {
+			purgeTrash()
+		}
+		<-ticker.C
+	}
+}
+
+func purgeTrash() {
+	cutoff := time.Now().AddDate(0, 0, -trashRetentionDays())
+	for kind, k := range trashKinds {
+		tx, err := k.db().Begin()
+		if err != nil {
+			log.Printf("Error purging %s trash: %v", kind, err)
+			continue
+		}
+		var purged int64
+		for _, stmt := range k.purge {
+			var result sql.Result
+			if result, err = tx.Exec(stmt, cutoff); err != nil {
+				break
+			}
+			purged, _ = result.RowsAffected()
+		}
+		if err != nil {
+			tx.Rollback()
+			log.Printf("Error purging %s trash: %v", kind, err)
+			continue
+		}
+		if err := tx.Commit(); err != nil {
+			log.Printf("Error purging %s trash: %v", kind, err)
+			continue
+		}
+		if purged > 0 {
+			log.Printf("🗑️ Purged %d %s records from trash", purged, kind)
+		}
+	}
+}
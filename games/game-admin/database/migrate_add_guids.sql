@@ -0,0 +1,8 @@
+-- Migration: Stable GUIDs for managed players, groups and teams
+-- Date: 2026-10-17
+-- Purpose: LMS/Sweepstakes import these through /api/export/*; GUIDs identify
+-- them across environments where serial IDs don't match
+
+ALTER TABLE managed_players ADD COLUMN IF NOT EXISTS guid UUID NOT NULL DEFAULT gen_random_uuid() UNIQUE;
+ALTER TABLE managed_groups ADD COLUMN IF NOT EXISTS guid UUID NOT NULL DEFAULT gen_random_uuid() UNIQUE;
+ALTER TABLE managed_teams ADD COLUMN IF NOT EXISTS guid UUID NOT NULL DEFAULT gen_random_uuid() UNIQUE;
@@ -9,3 +9,11 @@ CREATE TABLE IF NOT EXISTS audit_log (
     details JSONB,
     created_at TIMESTAMP DEFAULT NOW()
 );
+
+-- Real actor when the admin request was made through an impersonation session
+ALTER TABLE audit_log ADD COLUMN IF NOT EXISTS impersonated_by TEXT;
+
+-- The changed row before and after, for audited edits (NULL before a create, after a delete)
+ALTER TABLE audit_log ADD COLUMN IF NOT EXISTS before_snapshot JSONB;
+ALTER TABLE audit_log ADD COLUMN IF NOT EXISTS after_snapshot JSONB;
+CREATE INDEX IF NOT EXISTS idx_audit_log_target ON audit_log(target_id, created_at DESC);
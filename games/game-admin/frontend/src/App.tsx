@@ -15,12 +15,26 @@ interface FixtureFile {
   updatedAt: string;
 }
 
+// Paging info list endpoints return alongside their items
+interface Page {
+  limit: number;
+  offset: number;
+  sort?: string;
+  hasMore: boolean;
+  nextOffset?: number;
+}
+
+// Pickers need every row rather than a page; this is the most the API returns
+const ALL_ROWS = 'limit=500';
+
 interface LMSGame {
   id: number;
   name: string;
   status: string;
   fixtureFileId: number;
   fixtureName: string;
+  isPrivate: boolean;
+  joinCode: string;  // private games only
 }
 
 interface Round {
@@ -72,7 +86,7 @@ interface ManagedTeam {
 function useUrlParams() {
   return useMemo(() => {
     const params = new URLSearchParams(window.location.search);
-    return { userId: params.get('userId') || '', token: params.get('token') || '' };
+    return { userId: params.get('userId') || '', token: params.get('token') || sessionStorage.getItem('token') || '' };
   }, []);
 }
 
@@ -99,12 +113,13 @@ function useApi(token: string) {
 
 // --- Main App ---
 
-type Module = 'setup' | 'lms' | 'sweepstakes' | 'quiz' | 'sudoku';
+type Module = 'setup' | 'lms' | 'sweepstakes' | 'quiz' | 'sudoku' | 'leaderboard' | 'points' | 'analytics' | 'trash';
 type LMSTab = 'fixtures' | 'games' | 'rounds' | 'results' | 'predictions';
-type SweepTab = 'sw-competitions' | 'sw-entries';
-type QuizTab = 'quiz-media' | 'quiz-questions' | 'quiz-packs';
+type SweepTab = 'sw-competitions' | 'sw-entries' | 'sw-stages';
+type QuizTab = 'quiz-media' | 'quiz-questions' | 'quiz-submissions' | 'quiz-packs';
 type SudokuTab = 'sudoku-create' | 'sudoku-generate' | 'sudoku-library';
-type Tab = LMSTab | SweepTab | QuizTab | SudokuTab;
+type LeaderboardTab = 'lb-disputes';
+type Tab = LMSTab | SweepTab | QuizTab | SudokuTab | LeaderboardTab;
 
 function App() {
   const { userId, token } = useUrlParams();
@@ -113,6 +128,7 @@ function App() {
   const [config, setConfig] = useState<Config | null>(null);
   const [loading, setLoading] = useState(true);
   const [authError, setAuthError] = useState<string | null>(null);
+  const [isContributor, setIsContributor] = useState(false);
   const [activeModule, setActiveModule] = useState<Module>('lms');
   const [activeTab, setActiveTab] = useState<Tab>('fixtures');
   const [selectedGameId, setSelectedGameId] = useState<string>('');
@@ -121,7 +137,12 @@ function App() {
     if (!token || !userId) { setLoading(false); return; }
     api('/api/config')
       .then(data => { setConfig(data); setSelectedGameId(data.currentGameId || ''); })
-      .catch(err => setAuthError(err.message))
+      .catch(err =>
+        // Not an admin — question contributors get the submission portal instead
+        api('/api/contribute/questions')
+          .then(() => setIsContributor(true))
+          .catch(() => setAuthError(err.message))
+      )
       .finally(() => setLoading(false));
   }, [token, userId, api]);
 
@@ -138,6 +159,8 @@ function App() {
 
   if (loading) return <div className="ah-container"><p className="ah-meta">Loading...</p></div>;
 
+  if (isContributor) return <ContributorPortal api={api} onLobby={goToLobby} />;
+
   if (authError) {
     return (
       <div className="ah-container">
@@ -166,7 +189,7 @@ function App() {
       <div className="ah-container">
         {/* Module switcher */}
         <div className="ah-tabs">
-          {(['setup', 'lms', 'sweepstakes', 'quiz', 'sudoku'] as Module[]).map(mod => (
+          {(['setup', 'lms', 'sweepstakes', 'quiz', 'sudoku', 'leaderboard', 'points', 'analytics', 'trash'] as Module[]).map(mod => (
             <button
               key={mod}
               className={`ah-tab${activeModule === mod ? ' active' : ''}`}
@@ -176,9 +199,10 @@ function App() {
                 else if (mod === 'sweepstakes') setActiveTab('sw-competitions');
                 else if (mod === 'quiz') setActiveTab('quiz-media');
                 else if (mod === 'sudoku') setActiveTab('sudoku-create');
+                else if (mod === 'leaderboard') setActiveTab('lb-disputes');
               }}
             >
-              {mod === 'setup' ? '⚙️ Setup' : mod === 'lms' ? 'Last Man Standing' : mod === 'sweepstakes' ? 'Sweepstakes' : mod === 'quiz' ? 'Quiz' : 'Sudoku'}
+              {mod === 'setup' ? '⚙️ Setup' : mod === 'lms' ? 'Last Man Standing' : mod === 'sweepstakes' ? 'Sweepstakes' : mod === 'quiz' ? 'Quiz' : mod === 'sudoku' ? 'Sudoku' : mod === 'leaderboard' ? 'Leaderboard' : mod === 'points' ? 'Points' : mod === 'analytics' ? 'Analytics' : '🗑️ Trash'}
             </button>
           ))}
         </div>
@@ -222,7 +246,7 @@ function App() {
       {activeModule === 'sweepstakes' && (
         <>
           <div className="ah-tabs">
-            {([['sw-competitions', 'Competitions'], ['sw-entries', 'Entries']] as [SweepTab, string][]).map(([tab, label]) => (
+            {([['sw-competitions', 'Competitions'], ['sw-entries', 'Entries'], ['sw-stages', 'Stages']] as [SweepTab, string][]).map(([tab, label]) => (
               <button
                 key={tab}
                 className={`ah-tab${activeTab === tab ? ' active' : ''}`}
@@ -236,6 +260,7 @@ function App() {
           {activeTab === 'sw-competitions' && (
             <SweepCompetitionsTab
               api={api}
+              token={token}
               isReadOnly={isReadOnly}
               onSelectComp={() => setActiveTab('sw-entries')}
             />
@@ -243,6 +268,9 @@ function App() {
           {activeTab === 'sw-entries' && (
             <SweepEntriesTab api={api} isReadOnly={isReadOnly} />
           )}
+          {activeTab === 'sw-stages' && (
+            <SweepStagesTab api={api} isReadOnly={isReadOnly} />
+          )}
         </>
       )}
 
@@ -250,7 +278,7 @@ function App() {
       {activeModule === 'quiz' && (
         <>
           <div className="ah-tabs">
-            {([['quiz-media', 'Media'], ['quiz-questions', 'Questions'], ['quiz-packs', 'Packs']] as [QuizTab, string][]).map(([tab, label]) => (
+            {([['quiz-media', 'Media'], ['quiz-questions', 'Questions'], ['quiz-submissions', 'Submissions'], ['quiz-packs', 'Packs']] as [QuizTab, string][]).map(([tab, label]) => (
               <button
                 key={tab}
                 className={`ah-tab${activeTab === tab ? ' active' : ''}`}
@@ -263,6 +291,7 @@ function App() {
 
           {activeTab === 'quiz-media' && <QuizMediaTab api={api} isReadOnly={isReadOnly} />}
           {activeTab === 'quiz-questions' && <QuizQuestionsTab api={api} isReadOnly={isReadOnly} />}
+          {activeTab === 'quiz-submissions' && <QuizSubmissionsTab api={api} isReadOnly={isReadOnly} />}
           {activeTab === 'quiz-packs' && <QuizPacksTab api={api} isReadOnly={isReadOnly} />}
         </>
       )}
@@ -287,6 +316,20 @@ function App() {
           {activeTab === 'sudoku-library' && <SudokuLibraryTab api={api} isReadOnly={isReadOnly} />}
         </>
       )}
+
+      {/* Leaderboard module */}
+      {activeModule === 'leaderboard' && activeTab === 'lb-disputes' && (
+        <LeaderboardDisputesTab api={api} isReadOnly={isReadOnly} />
+      )}
+
+      {/* Loyalty points module */}
+      {activeModule === 'points' && <PointsTab api={api} isReadOnly={isReadOnly} />}
+
+      {/* Usage analytics module */}
+      {activeModule === 'analytics' && <AnalyticsTab api={api} />}
+
+      {/* Trash */}
+      {activeModule === 'trash' && <TrashTab api={api} isReadOnly={isReadOnly} />}
     </div>
     </>
   );
@@ -301,7 +344,7 @@ function GameSelector({ selectedGameId, onSelect, api }: {
 }) {
   const [games, setGames] = useState<LMSGame[]>([]);
   useEffect(() => {
-    api('/api/lms/games').then(data => setGames(data.games || [])).catch(() => {});
+    api(`/api/lms/games?${ALL_ROWS}`).then(data => setGames(data.games || [])).catch(() => {});
   }, [api]);
 
   return (
@@ -321,6 +364,43 @@ function GameSelector({ selectedGameId, onSelect, api }: {
 
 // --- FixturesTab ---
 
+
+interface FixtureMatchChange {
+  matchNumber: number;
+  before?: { date: string; homeTeam: string; awayTeam: string };
+  after?: { date: string; homeTeam: string; awayTeam: string };
+  fields?: string[];
+  openRounds?: string[];
+  picks: number;
+  byes: number;
+}
+
+interface FixtureDiff {
+  added: FixtureMatchChange[];
+  changed: FixtureMatchChange[];
+  removed: FixtureMatchChange[];
+  unchanged: number;
+  byes: number;
+  affectsOpenRounds: boolean;
+}
+
+// Summary of a fixture upload that touches open rounds, for the confirm prompt
+function describeFixtureDiff(diff: FixtureDiff): string {
+  const lines = [
+    `This upload changes matches in open rounds.`,
+    `${diff.added.length} added, ${diff.changed.length} changed, ${diff.removed.length} no longer in the file (kept), ${diff.unchanged} unchanged.`,
+  ];
+  for (const c of [...diff.added, ...diff.changed].filter(c => c.openRounds?.length)) {
+    const m = c.after || c.before;
+    const what = c.fields ? c.fields.join(', ') : 'new match';
+    const moved = c.fields?.includes('date') ? ` (${c.before?.date} → ${c.after?.date})` : '';
+    lines.push(`• #${c.matchNumber} ${m?.homeTeam} v ${m?.awayTeam}: ${what}${moved} — ${c.openRounds?.join(', ')}${c.picks ? `, ${c.picks} pick(s)` : ''}`);
+  }
+  if (diff.byes > 0) lines.push(`${diff.byes} pick(s) will become byes as their match moves out of the round.`);
+  lines.push('', 'Apply this version now?');
+  return lines.join('\n');
+}
+
 function FixturesTab({ api, isReadOnly }: { api: ReturnType<typeof useApi>; isReadOnly: boolean }) {
   const [fixtures, setFixtures] = useState<FixtureFile[]>([]);
   const [selectedFixture, setSelectedFixture] = useState<FixtureFile | null>(null);
@@ -349,8 +429,24 @@ function FixturesTab({ api, isReadOnly }: { api: ReturnType<typeof useApi>; isRe
     formData.append('name', uploadName.trim());
     formData.append('file', file);
     try {
-      const data = await api('/api/lms/fixtures/upload', { method: 'POST', body: formData });
-      setSuccess(`"${data.name}" uploaded — ${data.upserted} matches`);
+      let data = await api('/api/lms/fixtures/upload', { method: 'POST', body: formData });
+      if (data.requiresConfirmation) {
+        // The new version changes matches in open rounds — show what and ask first
+        if (!window.confirm(describeFixtureDiff(data.diff))) {
+          setSuccess(`"${data.name}" version ${data.version} saved but not applied`);
+          setTimeout(() => setSuccess(null), 5000);
+          e.target.value = '';
+          return;
+        }
+        data = {
+          ...data,
+          ...await api(`/api/lms/fixtures/${data.id}/versions/${data.version}/apply`, {
+            method: 'POST',
+            body: JSON.stringify({ confirm: true }),
+          }),
+        };
+      }
+      setSuccess(`"${data.name}" uploaded (version ${data.version}) — ${data.upserted} matches`);
       setUploadName('');
       loadFixtures();
       setTimeout(() => setSuccess(null), 5000);
@@ -376,7 +472,7 @@ function FixturesTab({ api, isReadOnly }: { api: ReturnType<typeof useApi>; isRe
         <div className="ah-card">
           <h3 className="ah-section-title">Upload Fixture File (CSV)</h3>
           <p className="ah-meta">Format: match_number, round_number, date, location, home_team, away_team[, result]</p>
-          <p className="ah-meta">Re-uploading with the same name updates existing matches. Results in the CSV are stored but status is only set to Completed when you confirm results in the Results tab.</p>
+          <p className="ah-meta">Re-uploading with the same name updates existing matches (changes to matches in open rounds ask for confirmation first). Results in the CSV are stored but status is only set to Completed when you confirm results in the Results tab.</p>
           <div className="ah-flex flex-wrap gap-2 mt-2">
             <input
               className="ah-input flex-1 min-w-[180px]"
@@ -444,16 +540,23 @@ function GamesTab({ api, isReadOnly, onGameSelect }: {
   onGameSelect: (id: string) => void;
 }) {
   const [games, setGames] = useState<LMSGame[]>([]);
+  const [gamesPage, setGamesPage] = useState<Page | null>(null);
   const [fixtures, setFixtures] = useState<FixtureFile[]>([]);
   const [currentGameId, setCurrentGameId] = useState<string>('');
   const [newName, setNewName] = useState('');
   const [newFixtureId, setNewFixtureId] = useState('');
+  const [newPrivate, setNewPrivate] = useState(false);
+  const [playersGameId, setPlayersGameId] = useState<number | null>(null);
+  const [mergeFrom, setMergeFrom] = useState('');
+  const [mergeTo, setMergeTo] = useState('');
+  const [mergeReason, setMergeReason] = useState('');
   const [error, setError] = useState<string | null>(null);
   const [success, setSuccess] = useState<string | null>(null);
 
   const load = useCallback(() => {
     api('/api/lms/games').then(data => {
       setGames(data.games || []);
+      setGamesPage(data.page || null);
       setCurrentGameId(data.currentGameId || '');
     }).catch(err => setError(err.message));
     api('/api/lms/fixtures').then(data => setFixtures(data.fixtures || [])).catch(() => {});
@@ -461,6 +564,14 @@ function GamesTab({ api, isReadOnly, onGameSelect }: {
 
   useEffect(() => { load(); }, [load]);
 
+  const loadMoreGames = () => {
+    if (!gamesPage?.hasMore) return;
+    api(`/api/lms/games?offset=${gamesPage.nextOffset}`).then(data => {
+      setGames(prev => [...prev, ...(data.games || [])]);
+      setGamesPage(data.page || null);
+    }).catch(err => setError(err.message));
+  };
+
   const createGame = async () => {
     if (!newName.trim() || !newFixtureId) return;
     try {
@@ -469,10 +580,12 @@ function GamesTab({ api, isReadOnly, onGameSelect }: {
         body: JSON.stringify({
           name: newName.trim(),
           fixtureFileId: parseInt(newFixtureId),
+          private: newPrivate,
         }),
       });
       setNewName('');
       setNewFixtureId('');
+      setNewPrivate(false);
       setSuccess('Game created');
       load();
       setTimeout(() => setSuccess(null), 3000);
@@ -492,6 +605,18 @@ function GamesTab({ api, isReadOnly, onGameSelect }: {
     }
   };
 
+  const regenerateCode = async (game: LMSGame) => {
+    if (!window.confirm(`Replace the join code for "${game.name}"? The old code stops working; players already in the game stay in.`)) return;
+    try {
+      await api(`/api/lms/games/${game.id}/join-code`, { method: 'PUT' });
+      setSuccess('New join code generated');
+      load();
+      setTimeout(() => setSuccess(null), 3000);
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed');
+    }
+  };
+
   const completeGame = async (id: number) => {
     if (!window.confirm('Mark this game as completed?')) return;
     try {
@@ -505,10 +630,10 @@ function GamesTab({ api, isReadOnly, onGameSelect }: {
   };
 
   const deleteGame = async (id: number, name: string) => {
-    if (!window.confirm(`Permanently delete "${name}" and ALL its rounds, predictions, and players? This cannot be undone.`)) return;
+    if (!window.confirm(`Delete "${name}"? It goes to the Trash with its rounds, predictions and players, and can be restored from there.`)) return;
     try {
       await api(`/api/lms/games/${id}`, { method: 'DELETE' });
-      setSuccess('Game deleted');
+      setSuccess('Game moved to Trash');
       load();
       setTimeout(() => setSuccess(null), 3000);
     } catch (err) {
@@ -516,6 +641,27 @@ function GamesTab({ api, isReadOnly, onGameSelect }: {
     }
   };
 
+  // For someone who joined with the wrong email: moves their games and picks across
+  const mergePlayers = async () => {
+    const from = mergeFrom.trim();
+    const to = mergeTo.trim();
+    if (!window.confirm(`Move all of ${from}'s LMS games and picks to ${to}? This can't be undone.`)) return;
+    try {
+      const data = await api('/api/lms/players/merge', {
+        method: 'POST',
+        body: JSON.stringify({ fromUserId: from, toUserId: to, reason: mergeReason.trim() }),
+      });
+      setSuccess(`Merged ${data.games} game(s), ${data.picksMoved} pick(s) moved`);
+      setMergeFrom('');
+      setMergeTo('');
+      setMergeReason('');
+      setPlayersGameId(null);
+      setTimeout(() => setSuccess(null), 5000);
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed');
+    }
+  };
+
   return (
     <div>
       {error && <div className="ah-banner ah-banner--error" onClick={() => setError(null)}>{error}</div>}
@@ -547,6 +693,10 @@ function GamesTab({ api, isReadOnly, onGameSelect }: {
                   ))}
                 </select>
               </div>
+              <label className="ah-label mt-2 block">
+                <input type="checkbox" checked={newPrivate} onChange={e => setNewPrivate(e.target.checked)} />
+                {' '}Private — players join with a code (e.g. a family group), alongside the current game
+              </label>
               <button
                 className="ah-btn-primary mt-3"
                 onClick={createGame}
@@ -571,13 +721,28 @@ function GamesTab({ api, isReadOnly, onGameSelect }: {
               <div>
                 <strong>{game.name}</strong>
                 {String(game.id) === currentGameId && <span className="ah-badge--info ml-2">CURRENT</span>}
+                {game.isPrivate && <span className="ah-badge--info ml-2">PRIVATE</span>}
                 <p className="ah-meta">Status: {game.status} · Fixture: {game.fixtureName || '—'}</p>
+                {game.isPrivate && (
+                  <p className="ah-meta">
+                    Join code: <strong style={{ letterSpacing: 2, fontFamily: 'monospace' }}>{game.joinCode || '—'}</strong>
+                  </p>
+                )}
               </div>
               {!isReadOnly && (
                 <div className="ah-flex flex-wrap flex-shrink-0 gap-2 justify-end">
-                  {String(game.id) !== currentGameId && (
+                  {game.isPrivate && (
+                    <button className="ah-btn-outline" onClick={() => regenerateCode(game)}>New Code</button>
+                  )}
+                  {!game.isPrivate && String(game.id) !== currentGameId && (
                     <button className="ah-btn-outline" onClick={() => setCurrent(game.id)}>Set Current</button>
                   )}
+                  <button
+                    className="ah-btn-outline"
+                    onClick={() => setPlayersGameId(playersGameId === game.id ? null : game.id)}
+                  >
+                    Players
+                  </button>
                   <button className="ah-btn-outline" onClick={() => onGameSelect(String(game.id))}>Rounds →</button>
                   {game.status === 'active' && (
                     <button className="ah-btn-danger" onClick={() => completeGame(game.id)}>Complete</button>
@@ -586,9 +751,108 @@ function GamesTab({ api, isReadOnly, onGameSelect }: {
                 </div>
               )}
             </div>
+            {playersGameId === game.id && (
+              <GamePlayers game={game} api={api} isReadOnly={isReadOnly} onError={setError} />
+            )}
           </div>
         ))
       )}
+      {gamesPage?.hasMore && (
+        <button className="ah-btn-outline mb-4" onClick={loadMoreGames}>Load more games</button>
+      )}
+
+      {!isReadOnly && (
+        <div className="ah-card">
+          <h3 className="ah-section-title">Merge Player Accounts</h3>
+          <p className="ah-meta">For a player who joined with the wrong email. Where both emails are in the same game, the correct email's picks are kept.</p>
+          <div className="ah-flex flex-wrap gap-2 mt-2">
+            <input className="ah-input flex-1 min-w-[180px]" placeholder="Wrong email" value={mergeFrom} onChange={e => setMergeFrom(e.target.value)} />
+            <input className="ah-input flex-1 min-w-[180px]" placeholder="Correct email" value={mergeTo} onChange={e => setMergeTo(e.target.value)} />
+          </div>
+          <input className="ah-input w-full mt-2" placeholder="Reason (for the audit log)" value={mergeReason} onChange={e => setMergeReason(e.target.value)} />
+          <button className="ah-btn-primary mt-3" onClick={mergePlayers} disabled={!mergeFrom.trim() || !mergeTo.trim()}>
+            Merge
+          </button>
+        </div>
+      )}
+    </div>
+  );
+}
+
+interface LMSPlayer {
+  userId: string;
+  name?: string;
+  isActive: boolean;
+  joinedAt: string;
+  picks: number;
+  eliminatedInRound?: number;
+}
+
+// A game's players, with remove and reinstate for admins
+function GamePlayers({ game, api, isReadOnly, onError }: {
+  game: LMSGame;
+  api: ReturnType<typeof useApi>;
+  isReadOnly: boolean;
+  onError: (message: string) => void;
+}) {
+  const [players, setPlayers] = useState<LMSPlayer[] | null>(null);
+
+  const load = useCallback(() => {
+    api(`/api/lms/games/${game.id}/players`)
+      .then(data => setPlayers(data.players || []))
+      .catch(err => onError(err.message));
+  }, [api, game.id, onError]);
+
+  useEffect(() => { load(); }, [load]);
+
+  const playerPath = (p: LMSPlayer) => `/api/lms/games/${game.id}/players/${encodeURIComponent(p.userId)}`;
+
+  const remove = async (p: LMSPlayer) => {
+    const reason = window.prompt(`Remove ${p.name || p.userId} from "${game.name}"? Their ${p.picks} pick(s) are deleted too.\n\nReason (optional):`);
+    if (reason === null) return;
+    try {
+      await api(`${playerPath(p)}?reason=${encodeURIComponent(reason)}`, { method: 'DELETE' });
+      load();
+    } catch (err) {
+      onError(err instanceof Error ? err.message : 'Failed');
+    }
+  };
+
+  const reinstate = async (p: LMSPlayer) => {
+    const reason = window.prompt(`Reinstate ${p.name || p.userId}? The team they lost with stays used.\n\nReason:`);
+    if (!reason?.trim()) return;
+    try {
+      await api(`${playerPath(p)}/reinstate`, { method: 'POST', body: JSON.stringify({ reason: reason.trim() }) });
+      load();
+    } catch (err) {
+      onError(err instanceof Error ? err.message : 'Failed');
+    }
+  };
+
+  if (!players) return <p className="ah-meta mt-3">Loading players...</p>;
+  if (players.length === 0) return <p className="ah-meta mt-3">No players have joined yet.</p>;
+
+  return (
+    <div className="mt-3">
+      {players.map(p => (
+        <div key={p.userId} className="flex justify-between items-center py-1" style={{ borderTop: '1px solid #eee' }}>
+          <div>
+            <span>{p.name || p.userId}</span>
+            {p.name && <span className="ah-meta"> · {p.userId}</span>}
+            <span className="ah-meta">
+              {' '}· {p.isActive ? 'Still in' : `Out${p.eliminatedInRound ? ` (round ${p.eliminatedInRound})` : ''}`} · {p.picks} pick(s)
+            </span>
+          </div>
+          {!isReadOnly && (
+            <div className="ah-flex gap-2">
+              {!p.isActive && game.status === 'active' && (
+                <button className="ah-btn-outline" onClick={() => reinstate(p)}>Reinstate</button>
+              )}
+              <button className="ah-btn-danger" onClick={() => remove(p)}>Remove</button>
+            </div>
+          )}
+        </div>
+      ))}
     </div>
   );
 }
@@ -827,6 +1091,7 @@ function ResultsTab({ gameId, api, isReadOnly }: {
   const [error, setError] = useState<string | null>(null);
   const [success, setSuccess] = useState<string | null>(null);
   const [processResult, setProcessResult] = useState<{ survived: number; eliminated: number; autoPicked: number } | null>(null);
+  const [processProgress, setProcessProgress] = useState<string | null>(null);
 
   // Load rounds for this game
   useEffect(() => {
@@ -867,14 +1132,29 @@ function ResultsTab({ gameId, api, isReadOnly }: {
     }
   };
 
+  // Processing runs as a background job: start it, then poll until it finishes
   const processRound = async () => {
+    setProcessResult(null);
+    setProcessProgress('Starting…');
     try {
-      const data = await api(`/api/lms/rounds/${gameId}/${selectedRound}/process`, { method: 'POST' });
+      const { jobId } = await api(`/api/lms/rounds/${gameId}/${selectedRound}/process`, { method: 'POST' });
+      let job = await api(`/api/jobs/${jobId}`);
+      while (job.state === 'running') {
+        setProcessProgress(job.total > 0 ? `${job.message} (${job.done}/${job.total})` : job.message || 'Processing…');
+        await new Promise(resolve => setTimeout(resolve, 1000));
+        job = await api(`/api/jobs/${jobId}`);
+      }
+      if (job.state === 'failed') {
+        throw new Error(job.error || 'Round processing failed');
+      }
+      const data = job.result;
       setProcessResult({ survived: data.survived, eliminated: data.eliminated, autoPicked: data.autoPicked || 0 });
       const autoMsg = data.autoPicked ? `, ${data.autoPicked} auto-picked` : '';
       setSuccess(`Round ${selectedRound} processed — ${data.survived} survived, ${data.eliminated} eliminated${autoMsg}`);
     } catch (err) {
       setError(err instanceof Error ? err.message : 'Failed to process round');
+    } finally {
+      setProcessProgress(null);
     }
   };
 
@@ -957,10 +1237,13 @@ function ResultsTab({ gameId, api, isReadOnly }: {
               <button
                 className="ah-btn-primary"
                 onClick={processRound}
-                disabled={!canProcess}
+                disabled={!canProcess || processProgress !== null}
               >
-                Process Round {selectedRound}
+                {processProgress !== null ? 'Processing…' : `Process Round ${selectedRound}`}
               </button>
+              {processProgress && (
+                <p className="ah-meta mt-2 text-gray-600">{processProgress}</p>
+              )}
               {processResult && (
                 <p className="ah-meta mt-2 text-gray-800">
                   ✅ {processResult.survived} survived · ❌ {processResult.eliminated} eliminated
@@ -1054,8 +1337,9 @@ interface SweepComp {
   createdAt: string;
 }
 
-function SweepCompetitionsTab({ api, isReadOnly, onSelectComp }: {
+function SweepCompetitionsTab({ api, token, isReadOnly, onSelectComp }: {
   api: ReturnType<typeof useApi>;
+  token: string;
   isReadOnly: boolean;
   onSelectComp: () => void;
 }) {
@@ -1104,10 +1388,10 @@ function SweepCompetitionsTab({ api, isReadOnly, onSelectComp }: {
   };
 
   const deleteComp = async (id: number, name: string) => {
-    if (!window.confirm(`Permanently delete "${name}" and all its data?`)) return;
+    if (!window.confirm(`Delete "${name}"? It goes to the Trash with its entries and draws, and can be restored from there.`)) return;
     try {
       await api(`/api/sweepstakes/competitions/${id}`, { method: 'DELETE' });
-      setSuccess('Competition deleted');
+      setSuccess('Competition moved to Trash');
       load();
       setTimeout(() => setSuccess(null), 3000);
     } catch (err) {
@@ -1115,6 +1399,21 @@ function SweepCompetitionsTab({ api, isReadOnly, onSelectComp }: {
     }
   };
 
+  // Printable result cards come from the sweepstakes app itself
+  const printCards = async (comp: SweepComp) => {
+    try {
+      const res = await fetch('http://' + window.location.hostname + `:4031/api/competitions/${comp.id}/cards.pdf`, {
+        headers: { Authorization: `Bearer ${token}` },
+      });
+      if (!res.ok) throw new Error((await res.text()) || `HTTP ${res.status}`);
+      const url = URL.createObjectURL(await res.blob());
+      window.open(url, '_blank');
+      setTimeout(() => URL.revokeObjectURL(url), 60000);
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to generate cards');
+    }
+  };
+
   const swStatusColor = (status: string) => {
     if (status === 'open') return '#4CAF50';
     if (status === 'draft') return '#FF9800';
@@ -1184,6 +1483,9 @@ function SweepCompetitionsTab({ api, isReadOnly, onSelectComp }: {
                   {comp.status === 'locked' && (
                     <button className="ah-btn-outline" onClick={() => updateStatus(comp, 'completed')}>Complete</button>
                   )}
+                  {comp.status !== 'draft' && (
+                    <button className="ah-btn-outline" onClick={() => printCards(comp)}>Print Cards</button>
+                  )}
                   <button className="ah-btn-outline" onClick={onSelectComp}>Entries →</button>
                   <button className="ah-btn-danger" onClick={() => deleteComp(comp.id, comp.name)}>Delete</button>
                 </div>
@@ -1218,6 +1520,7 @@ interface SweepDraw {
   seed?: number;
   number?: number;
   position?: number;
+  assigned_by?: string;
 }
 
 function SweepEntriesTab({ api, isReadOnly }: {
@@ -1229,6 +1532,8 @@ function SweepEntriesTab({ api, isReadOnly }: {
   const [entries, setEntries] = useState<SweepEntry[]>([]);
   const [draws, setDraws] = useState<SweepDraw[]>([]);
   const [showDraws, setShowDraws] = useState(false);
+  const [assignUser, setAssignUser] = useState('');
+  const [assignEntryId, setAssignEntryId] = useState('');
   const [error, setError] = useState<string | null>(null);
   const [success, setSuccess] = useState<string | null>(null);
 
@@ -1238,7 +1543,7 @@ function SweepEntriesTab({ api, isReadOnly }: {
 
   const loadEntries = useCallback((compId: string) => {
     if (!compId) return;
-    api(`/api/sweepstakes/competitions/${compId}/entries`)
+    api(`/api/sweepstakes/competitions/${compId}/entries?${ALL_ROWS}`)
       .then(d => setEntries(d.entries || []))
       .catch(err => setError(err.message));
   }, [api]);
@@ -1264,7 +1569,7 @@ function SweepEntriesTab({ api, isReadOnly }: {
     formData.append('file', file);
     try {
       const data = await api('/api/sweepstakes/entries/upload', { method: 'POST', body: formData });
-      setSuccess(`${data.uploaded} entries uploaded${data.skipped ? `, ${data.skipped} skipped` : ''}`);
+      setSuccess(`${data.uploaded} entries uploaded${data.updated ? `, ${data.updated} updated` : ''}${data.skipped ? `, ${data.skipped} skipped` : ''}`);
       loadEntries(selectedCompId);
       setTimeout(() => setSuccess(null), 4000);
     } catch (err) {
@@ -1298,6 +1603,40 @@ function SweepEntriesTab({ api, isReadOnly }: {
     }
   };
 
+  // Draw corrections: each asks for a reason, which goes in the audit log
+  const drawAction = async (path: string, body: Record<string, unknown>, done: string) => {
+    const reason = window.prompt('Reason (recorded in the audit log):');
+    if (!reason || !reason.trim()) return;
+    try {
+      await api(path, { method: 'POST', body: JSON.stringify({ ...body, reason }) });
+      setSuccess(done);
+      loadEntries(selectedCompId);
+      loadDraws(selectedCompId);
+      setTimeout(() => setSuccess(null), 3000);
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed');
+    }
+  };
+
+  const voidDraw = (d: SweepDraw) => {
+    if (!window.confirm(`Void ${d.user_id}'s draw of "${d.entry_name}"? The entry goes back in the pool.`)) return;
+    drawAction(`/api/sweepstakes/draws/${d.id}/void`, {}, 'Draw voided');
+  };
+
+  const redraw = (d: SweepDraw) => {
+    if (!window.confirm(`Redraw for ${d.user_id}? "${d.entry_name}" goes back in the pool and they get a random entry.`)) return;
+    drawAction(`/api/sweepstakes/draws/${d.id}/redraw`, {}, 'Redrawn');
+  };
+
+  const assignEntry = async () => {
+    if (!assignUser.trim() || !assignEntryId) return;
+    await drawAction(`/api/sweepstakes/competitions/${selectedCompId}/assign`,
+      { user_id: assignUser.trim(), entry_id: parseInt(assignEntryId) }, 'Entry assigned');
+    setAssignUser(''); setAssignEntryId('');
+  };
+
+  const availableEntries = entries.filter(e => e.status === 'available');
+
   return (
     <div>
       {error && <div className="ah-banner ah-banner--error" onClick={() => setError(null)}>{error}</div>}
@@ -1316,8 +1655,16 @@ function SweepEntriesTab({ api, isReadOnly }: {
       {selectedCompId && !isReadOnly && (
         <div className="ah-card">
           <h3 className="ah-section-title">Upload Entries (CSV)</h3>
-          <p className="ah-meta">Format: Name[, seed or number]. Re-uploading skips existing names.</p>
-          <input type="file" accept=".csv" onChange={uploadEntries} className="mt-2" />
+          <p className="ah-meta">Format: Name[, seed or number[, guid]]. Re-uploading skips existing names; rows with a guid update that entry.</p>
+          <div className="ah-flex-center flex-wrap gap-2 mt-2">
+            <input type="file" accept=".csv" onChange={uploadEntries} />
+            <button
+              className="ah-btn-outline text-xs"
+              onClick={() => window.open(`/api/sweepstakes/competitions/${selectedCompId}/entries/export`, '_blank')}
+            >
+              Export Entries (CSV)
+            </button>
+          </div>
         </div>
       )}
 
@@ -1378,6 +1725,30 @@ function SweepEntriesTab({ api, isReadOnly }: {
             )
           )}
 
+          {showDraws && !isReadOnly && (
+            <div className="ah-card">
+              <h3 className="ah-section-title">Assign Entry</h3>
+              <p className="ah-meta">Give a player a specific entry, e.g. someone who paid at the bar.</p>
+              <div className="ah-flex gap-2 mt-2">
+                <input
+                  className="ah-input flex-1"
+                  placeholder="Player email"
+                  value={assignUser}
+                  onChange={e => setAssignUser(e.target.value)}
+                />
+                <select value={assignEntryId} onChange={e => setAssignEntryId(e.target.value)} className="ah-select">
+                  <option value="">— entry —</option>
+                  {availableEntries.map(e => (
+                    <option key={e.id} value={String(e.id)}>{e.name}</option>
+                  ))}
+                </select>
+                <button className="ah-btn-primary" onClick={assignEntry} disabled={!assignUser.trim() || !assignEntryId}>
+                  Assign
+                </button>
+              </div>
+            </div>
+          )}
+
           {showDraws && (
             draws.length === 0 ? (
               <div className="ah-card"><p className="ah-meta">No draws yet.</p></div>
@@ -1388,13 +1759,23 @@ function SweepEntriesTab({ api, isReadOnly }: {
                   <span className="flex-2">Entry</span>
                   <span className="flex-1">Position</span>
                   <span className="flex-1">Drawn</span>
+                  {!isReadOnly && <span className="flex-1">Actions</span>}
                 </div>
                 {draws.map((d, idx) => (
                   <div key={idx} className="ah-table-row">
                     <span className="flex-[2] text-xs overflow-hidden text-ellipsis">{d.user_id}</span>
-                    <span className="flex-[2] text-sm font-medium">{d.entry_name}</span>
+                    <span className="flex-[2] text-sm font-medium">
+                      {d.entry_name}
+                      {d.assigned_by && <span className="ah-meta" title={`By ${d.assigned_by}`}> (assigned)</span>}
+                    </span>
                     <span className="flex-1 text-xs text-stone-500">{d.position ?? '—'}</span>
                     <span className="flex-1 text-xs text-stone-500">{new Date(d.drawn_at).toLocaleDateString()}</span>
+                    {!isReadOnly && (
+                      <span className="flex-1 ah-flex gap-1">
+                        <button className="ah-btn-outline py-1 px-2 text-xs" onClick={() => redraw(d)}>Redraw</button>
+                        <button className="ah-btn-danger py-1 px-2 text-xs" onClick={() => voidDraw(d)}>Void</button>
+                      </span>
+                    )}
                   </div>
                 ))}
               </div>
@@ -1406,6 +1787,248 @@ function SweepEntriesTab({ api, isReadOnly }: {
   );
 }
 
+// --- SweepStagesTab ---
+// Multi-stage competitions (group stage, then knockout). The first stage takes
+// every entry; each later one takes the entries that went through, carrying
+// their position.
+
+type StageOutcome = 'playing' | 'through' | 'out';
+
+interface SweepStageEntry {
+  entry_id: number;
+  entry_name: string;
+  group?: string;
+  carried_position: number | null;
+  position: number | null;
+  outcome: StageOutcome;
+}
+
+interface SweepStage {
+  id: number;
+  stage_number: number;
+  name: string;
+  status: 'active' | 'completed';
+  entries: SweepStageEntry[];
+}
+
+function SweepStagesTab({ api, isReadOnly }: {
+  api: ReturnType<typeof useApi>;
+  isReadOnly: boolean;
+}) {
+  const [comps, setComps] = useState<SweepComp[]>([]);
+  const [selectedCompId, setSelectedCompId] = useState('');
+  const [stages, setStages] = useState<SweepStage[]>([]);
+  const [newStageName, setNewStageName] = useState('');
+  const [error, setError] = useState<string | null>(null);
+  const [success, setSuccess] = useState<string | null>(null);
+
+  useEffect(() => {
+    api('/api/sweepstakes/competitions').then(d => setComps(d.competitions || [])).catch(() => {});
+  }, [api]);
+
+  const loadStages = useCallback((compId: string) => {
+    if (!compId) return;
+    api(`/api/sweepstakes/competitions/${compId}/stages`)
+      .then(d => setStages(d.stages || []))
+      .catch(err => setError(err.message));
+  }, [api]);
+
+  const selectComp = (id: string) => {
+    setSelectedCompId(id);
+    setStages([]);
+    if (id) loadStages(id);
+  };
+
+  const flash = (msg: string) => {
+    setSuccess(msg);
+    setTimeout(() => setSuccess(null), 3000);
+  };
+
+  const createStage = async () => {
+    if (!newStageName.trim()) return;
+    try {
+      const data = await api(`/api/sweepstakes/competitions/${selectedCompId}/stages`, {
+        method: 'POST',
+        body: JSON.stringify({ name: newStageName.trim() }),
+      });
+      setNewStageName('');
+      flash(`Stage started with ${data.entries} entries`);
+      loadStages(selectedCompId);
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to start stage');
+    }
+  };
+
+  const updateEntry = async (stage: SweepStage, entry: SweepStageEntry, change: Partial<SweepStageEntry>) => {
+    const next = { ...entry, ...change };
+    // Update in place so a run of changes doesn't reorder the list under the admin
+    setStages(prev => prev.map(s => s.id !== stage.id ? s : {
+      ...s, entries: s.entries.map(e => e.entry_id === entry.entry_id ? next : e),
+    }));
+    try {
+      await api(`/api/sweepstakes/stages/${stage.id}/entries/${entry.entry_id}`, {
+        method: 'PUT',
+        body: JSON.stringify({ group: next.group || '', position: next.position, outcome: next.outcome }),
+      });
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to update entry');
+      loadStages(selectedCompId);
+    }
+  };
+
+  const completeStage = async (stage: SweepStage) => {
+    if (!window.confirm(`Complete "${stage.name}"? Entries marked Through go into the next stage.`)) return;
+    try {
+      await api(`/api/sweepstakes/stages/${stage.id}/complete`, { method: 'POST' });
+      flash('Stage completed');
+      loadStages(selectedCompId);
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to complete stage');
+    }
+  };
+
+  const deleteStage = async (stage: SweepStage) => {
+    if (!window.confirm(`Delete "${stage.name}" and its results?`)) return;
+    try {
+      await api(`/api/sweepstakes/stages/${stage.id}`, { method: 'DELETE' });
+      flash('Stage deleted');
+      loadStages(selectedCompId);
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to delete stage');
+    }
+  };
+
+  const latest = stages[stages.length - 1];
+  const canStartStage = !isReadOnly && selectedCompId && (!latest || latest.status === 'completed');
+
+  return (
+    <div>
+      {error && <div className="ah-banner ah-banner--error" onClick={() => setError(null)}>{error}</div>}
+      <Toast message={success} />
+
+      <div className="mb-4">
+        <label className="ah-label">Competition: </label>
+        <select value={selectedCompId} onChange={e => selectComp(e.target.value)} className="ah-select">
+          <option value="">— select —</option>
+          {comps.map(c => (
+            <option key={c.id} value={String(c.id)}>{c.name} ({c.status})</option>
+          ))}
+        </select>
+      </div>
+
+      {selectedCompId && stages.length === 0 && (
+        <div className="ah-card">
+          <p className="ah-meta">
+            No stages: the competition is a single round. For a group stage then a knockout, start the first stage
+            below once the entries are uploaded.
+          </p>
+        </div>
+      )}
+
+      {canStartStage && (
+        <div className="ah-card">
+          <h3 className="ah-section-title">{latest ? 'Start Next Stage' : 'Start First Stage'}</h3>
+          <p className="ah-meta">
+            {latest ? `Entries marked Through in "${latest.name}" go into it.` : 'Every entry goes into it.'}
+          </p>
+          <div className="ah-flex gap-2 mt-2">
+            <input
+              className="ah-input flex-1"
+              placeholder={latest ? 'e.g. Round of 16' : 'e.g. Group Stage'}
+              value={newStageName}
+              onChange={e => setNewStageName(e.target.value)}
+            />
+            <button className="ah-btn-primary" onClick={createStage} disabled={!newStageName.trim()}>Start</button>
+          </div>
+        </div>
+      )}
+
+      {stages.map(stage => {
+        const editable = !isReadOnly && stage.status === 'active';
+        return (
+          <div key={stage.id} className="ah-card">
+            <div className="ah-flex-between">
+              <h3 className="ah-section-title">
+                {stage.stage_number}. {stage.name}{' '}
+                <span className="ah-meta">({stage.status}, {stage.entries.length} entries)</span>
+              </h3>
+              {!isReadOnly && (
+                <span className="ah-flex gap-1">
+                  {stage.status === 'active' && (
+                    <button className="ah-btn-primary py-1 px-2 text-xs" onClick={() => completeStage(stage)}>Complete</button>
+                  )}
+                  {stage === latest && (
+                    <button className="ah-btn-danger py-1 px-2 text-xs" onClick={() => deleteStage(stage)}>Delete</button>
+                  )}
+                </span>
+              )}
+            </div>
+            <div className="ah-table mt-2">
+              <div className="ah-table-header">
+                <span className="flex-grow">Entry</span>
+                <span className="flex-1">Carried</span>
+                <span className="flex-1">Group</span>
+                <span className="flex-1">Position</span>
+                <span className="flex-1">Outcome</span>
+              </div>
+              {stage.entries.map(entry => (
+                <div key={entry.entry_id} className="ah-table-row">
+                  <span className="flex-[3] text-sm">{entry.entry_name}</span>
+                  <span className="flex-1 text-xs text-stone-500">{entry.carried_position ?? '—'}</span>
+                  <span className="flex-1">
+                    {editable ? (
+                      <input
+                        className="ah-input py-1 px-1.5 text-xs"
+                        style={{ width: 48 }}
+                        defaultValue={entry.group || ''}
+                        onBlur={e => e.target.value !== (entry.group || '') && updateEntry(stage, entry, { group: e.target.value })}
+                      />
+                    ) : (
+                      <span className="text-xs text-stone-500">{entry.group || '—'}</span>
+                    )}
+                  </span>
+                  <span className="flex-1">
+                    {editable ? (
+                      <input
+                        type="number"
+                        min={1}
+                        className="ah-input py-1 px-1.5 text-xs"
+                        style={{ width: 56 }}
+                        defaultValue={entry.position ?? ''}
+                        onBlur={e => {
+                          const position = e.target.value ? parseInt(e.target.value) : null;
+                          if (position !== entry.position) updateEntry(stage, entry, { position });
+                        }}
+                      />
+                    ) : (
+                      <span className="text-xs text-stone-500">{entry.position ?? '—'}</span>
+                    )}
+                  </span>
+                  <span className="flex-1">
+                    {editable ? (
+                      <select
+                        value={entry.outcome}
+                        onChange={e => updateEntry(stage, entry, { outcome: e.target.value as StageOutcome })}
+                        className="py-1 px-1.5 text-xs"
+                      >
+                        <option value="playing">Playing</option>
+                        <option value="through">Through</option>
+                        <option value="out">Out</option>
+                      </select>
+                    ) : (
+                      <span style={{ fontSize: 12, color: entry.outcome === 'through' ? '#4CAF50' : '#666' }}>{entry.outcome}</span>
+                    )}
+                  </span>
+                </div>
+              ))}
+            </div>
+          </div>
+        );
+      })}
+    </div>
+  );
+}
+
 // --- Toast: fixed-position success message, no layout shift ---
 
 function Toast({ message }: { message: string | null }) {
@@ -1471,6 +2094,11 @@ interface QuizQuestion {
   audioClipId: number | null;
   requiresMedia: boolean;
   isTestContent: boolean;
+  tiebreak: '' | 'nearest' | 'sudden_death';
+  contributedBy: string;
+  simplifiedText: string;
+  imageAlt: string;
+  readAloudText: string;
   createdAt: string;
   imagePath: string;
   audioPath: string;
@@ -1478,6 +2106,7 @@ interface QuizQuestion {
 
 interface QuizPack {
   id: number;
+  guid: string;
   name: string;
   description: string;
   createdBy: string;
@@ -1722,28 +2351,52 @@ function QuizQuestionsTab({ api, isReadOnly }: { api: ReturnType<typeof useApi>;
   const [questions, setQuestions] = useState<QuizQuestion[]>([]);
   const [clips, setClips] = useState<MediaClip[]>([]);
   const [filterType, setFilterType] = useState('');
+  const [search, setSearch] = useState('');
+  const [appliedSearch, setAppliedSearch] = useState('');
+  const [questionsPage, setQuestionsPage] = useState<Page | null>(null);
   const [editingId, setEditingId] = useState<number | null>(null);
   const [form, setForm] = useState({
     text: '', answer: '', category: '', difficulty: 'medium', type: 'text',
-    imageClipId: '', audioClipId: '', requiresMedia: false, isTestContent: false,
+    imageClipId: '', audioClipId: '', requiresMedia: false, isTestContent: false, tiebreak: '',
+    simplifiedText: '', imageAlt: '', readAloudText: '',
   });
   const [error, setError] = useState<string | null>(null);
   const [success, setSuccess] = useState<string | null>(null);
   const [importResult, setImportResult] = useState<string | null>(null);
 
+  const questionsQuery = useCallback((offset: number) => {
+    const params = new URLSearchParams({ offset: String(offset) });
+    if (filterType) params.set('type', filterType);
+    if (appliedSearch) params.set('q', appliedSearch);
+    return `/api/quiz/questions?${params}`;
+  }, [filterType, appliedSearch]);
+
   const load = useCallback(() => {
-    const q = filterType ? `?type=${filterType}` : '';
-    api(`/api/quiz/questions${q}`).then(d => setQuestions(d.questions || [])).catch(err => setError(err.message));
+    api(questionsQuery(0)).then(d => {
+      setQuestions(d.questions || []);
+      setQuestionsPage(d.page || null);
+    }).catch(err => setError(err.message));
     api('/api/quiz/clips').then(d => setClips(d.clips || [])).catch(() => {});
-  }, [api, filterType]);
+  }, [api, questionsQuery]);
 
   useEffect(() => { load(); }, [load]);
 
+  const loadMoreQuestions = () => {
+    if (!questionsPage?.hasMore) return;
+    api(questionsQuery(questionsPage.nextOffset ?? 0)).then(d => {
+      setQuestions(prev => [...prev, ...(d.questions || [])]);
+      setQuestionsPage(d.page || null);
+    }).catch(err => setError(err.message));
+  };
+
   const imageClips = clips.filter(c => c.mediaType === 'image');
   const audioClips = clips.filter(c => c.mediaType === 'audio');
 
   const resetForm = () => {
-    setForm({ text: '', answer: '', category: '', difficulty: 'medium', type: 'text', imageClipId: '', audioClipId: '', requiresMedia: false, isTestContent: false });
+    setForm({
+      text: '', answer: '', category: '', difficulty: 'medium', type: 'text', imageClipId: '', audioClipId: '',
+      requiresMedia: false, isTestContent: false, tiebreak: '', simplifiedText: '', imageAlt: '', readAloudText: '',
+    });
     setEditingId(null);
   };
 
@@ -1755,6 +2408,10 @@ function QuizQuestionsTab({ api, isReadOnly }: { api: ReturnType<typeof useApi>;
       audioClipId: q.audioClipId ? String(q.audioClipId) : '',
       requiresMedia: q.requiresMedia,
       isTestContent: q.isTestContent,
+      tiebreak: q.tiebreak || '',
+      simplifiedText: q.simplifiedText || '',
+      imageAlt: q.imageAlt || '',
+      readAloudText: q.readAloudText || '',
     });
     setEditingId(q.id);
   };
@@ -1768,6 +2425,10 @@ function QuizQuestionsTab({ api, isReadOnly }: { api: ReturnType<typeof useApi>;
       audioClipId: form.audioClipId ? parseInt(form.audioClipId) : null,
       requiresMedia: form.requiresMedia,
       isTestContent: form.isTestContent,
+      tiebreak: form.tiebreak,
+      simplifiedText: form.simplifiedText.trim(),
+      imageAlt: form.imageAlt.trim(),
+      readAloudText: form.readAloudText.trim(),
     };
     try {
       if (editingId) {
@@ -1805,6 +2466,7 @@ function QuizQuestionsTab({ api, isReadOnly }: { api: ReturnType<typeof useApi>;
     try {
       const data = await api('/api/quiz/questions/import', { method: 'POST', body: formData });
       let msg = `Imported ${data.imported} question${data.imported !== 1 ? 's' : ''}`;
+      if (data.updated) msg += `, updated ${data.updated}`;
       if (data.skipped && data.skipped.length > 0) {
         msg += `. Skipped ${data.skipped.length}: ${data.skipped.map((s: {row: number; reason: string}) => `row ${s.row}: ${s.reason}`).join('; ')}`;
       }
@@ -1876,6 +2538,22 @@ function QuizQuestionsTab({ api, isReadOnly }: { api: ReturnType<typeof useApi>;
               <input type="checkbox" checked={form.isTestContent} onChange={e => setForm(f => ({ ...f, isTestContent: e.target.checked }))} />
               Test content
             </label>
+            <div>
+              <label className="ah-label">Tie-break pool: </label>
+              <select className="ah-select" value={form.tiebreak} onChange={e => setForm(f => ({ ...f, tiebreak: e.target.value }))}>
+                <option value="">— not a tie-breaker —</option>
+                <option value="nearest">Nearest number (numeric answer)</option>
+                <option value="sudden_death">Sudden death</option>
+              </select>
+            </div>
+          </div>
+          {/* Accessible variants, shown by quiz-player and quiz-display when the session or player asks for them */}
+          <div className="ah-flex flex-wrap gap-2 mt-2">
+            <input className="ah-input flex-1" placeholder="Simplified wording (optional)" value={form.simplifiedText} onChange={e => setForm(f => ({ ...f, simplifiedText: e.target.value }))} />
+            {(form.type === 'picture' || form.imageClipId) && (
+              <input className="ah-input flex-1" placeholder="Picture description (alt text)" value={form.imageAlt} onChange={e => setForm(f => ({ ...f, imageAlt: e.target.value }))} />
+            )}
+            <input className="ah-input flex-1" placeholder="Read-aloud text (default: question and picture description)" value={form.readAloudText} onChange={e => setForm(f => ({ ...f, readAloudText: e.target.value }))} />
           </div>
           <div className="ah-flex gap-2 mt-2">
             <button className="ah-btn-primary" onClick={save}>{editingId ? 'Update' : 'Add Question'}</button>
@@ -1890,14 +2568,17 @@ function QuizQuestionsTab({ api, isReadOnly }: { api: ReturnType<typeof useApi>;
           <h3 className="ah-section-title">Import Questions (CSV)</h3>
           <p className="ah-meta">
             Required columns: <code>text</code>, <code>answer</code>.
-            Optional: <code>category</code>, <code>difficulty</code>, <code>type</code>, <code>image_guid</code>, <code>audio_guid</code>, <code>requires_media</code>.
-            GUIDs come from the Media tab's Export Reference Sheet.
+            Optional: <code>guid</code>, <code>category</code>, <code>difficulty</code>, <code>type</code>, <code>image_guid</code>, <code>audio_guid</code>, <code>requires_media</code>.
+            Media GUIDs come from the Media tab's Export Reference Sheet. Rows whose <code>guid</code> is already in the bank update that question.
           </p>
           <div className="ah-flex-center flex-wrap gap-2 mt-2">
             <input type="file" accept=".csv" onChange={importCSV} />
             <a href={templateHref} download="quiz-questions-template.csv" className="ah-btn-outline text-xs no-underline py-1.5 px-2.5">
               Download Template
             </a>
+            <button className="ah-btn-outline text-xs" onClick={() => window.open('/api/quiz/questions/export', '_blank')}>
+              Export Questions (CSV)
+            </button>
           </div>
           {importResult && (
             <p className="ah-meta mt-2 text-gray-800">{importResult}</p>
@@ -1912,6 +2593,16 @@ function QuizQuestionsTab({ api, isReadOnly }: { api: ReturnType<typeof useApi>;
           </button>
         ))}
       </div>
+      <form className="ah-flex gap-2 mb-3" onSubmit={e => { e.preventDefault(); setAppliedSearch(search.trim()); }}>
+        <input
+          className="ah-input flex-1"
+          type="search"
+          placeholder="Search questions and answers"
+          value={search}
+          onChange={e => setSearch(e.target.value)}
+        />
+        <button type="submit" className="ah-btn-outline">Search</button>
+      </form>
 
       {questions.length === 0 ? (
         <div className="ah-card"><p className="ah-meta">No questions yet.</p></div>
@@ -1922,12 +2613,22 @@ function QuizQuestionsTab({ api, isReadOnly }: { api: ReturnType<typeof useApi>;
               <div className="flex-1">
                 <p className="font-medium text-sm">{q.text}</p>
                 <p className="ah-meta">Answer: <strong>{q.answer}</strong> · {q.type} · {q.difficulty} {q.category && `· ${q.category}`}</p>
-                {q.imagePath && <p className="ah-meta" style={{ color: '#1565C0' }}>Image attached</p>}
+                {q.imagePath && (
+                  <p className="ah-meta" style={{ color: '#1565C0' }}>
+                    Image attached{!q.imageAlt && <span className="text-orange-700"> · no description for screen readers</span>}
+                  </p>
+                )}
                 {q.audioPath && <p className="ah-meta text-orange-700">Audio attached</p>}
+                {q.contributedBy && <p className="ah-meta">Contributed by {q.contributedBy}</p>}
                 {q.requiresMedia && !q.imageClipId && !q.audioClipId && (
                   <span className="ah-badge" style={{ backgroundColor: '#FFEBEE', color: '#C62828' }}>NEEDS CLIP</span>
                 )}
                 {q.isTestContent && <span className="ah-badge ml-2" style={{ backgroundColor: '#E8F5E9', color: '#2E7D32' }}>TEST</span>}
+                {q.tiebreak && (
+                  <span className="ah-badge ml-2" style={{ backgroundColor: '#EDE7F6', color: '#4527A0' }}>
+                    TIE-BREAK · {q.tiebreak === 'nearest' ? 'NEAREST' : 'SUDDEN DEATH'}
+                  </span>
+                )}
               </div>
               {!isReadOnly && (
                 <div className="ah-flex gap-1 flex-shrink-0 ml-3">
@@ -1939,6 +2640,143 @@ function QuizQuestionsTab({ api, isReadOnly }: { api: ReturnType<typeof useApi>;
           </div>
         ))
       )}
+      {questionsPage?.hasMore && (
+        <button className="ah-btn-outline" onClick={loadMoreQuestions}>Load more questions</button>
+      )}
+    </div>
+  );
+}
+
+// --- QuizSubmissionsTab ---
+
+interface QuestionSubmission {
+  id: number;
+  contributorEmail: string;
+  contributorName?: string;
+  text: string;
+  answer: string;
+  category: string;
+  difficulty: string;
+  status: 'pending' | 'approved' | 'rejected';
+  reviewNote: string;
+  reviewedBy?: string;
+  reviewedAt: string | null;
+  questionId: number | null;
+  createdAt: string;
+}
+
+function QuizSubmissionsTab({ api, isReadOnly }: { api: ReturnType<typeof useApi>; isReadOnly: boolean }) {
+  const [status, setStatus] = useState('pending');
+  const [submissions, setSubmissions] = useState<QuestionSubmission[]>([]);
+  const [editingId, setEditingId] = useState<number | null>(null);
+  const [form, setForm] = useState({ text: '', answer: '', category: '', difficulty: 'medium' });
+  const [error, setError] = useState<string | null>(null);
+  const [success, setSuccess] = useState<string | null>(null);
+
+  const load = useCallback(() => {
+    api(`/api/quiz/submissions?status=${status}`)
+      .then(d => setSubmissions(d.submissions || []))
+      .catch(err => setError(err.message));
+  }, [api, status]);
+
+  useEffect(() => { load(); }, [load]);
+
+  const act = async (path: string, options: RequestInit, message: string) => {
+    try {
+      await api(path, options);
+      setSuccess(message);
+      setEditingId(null);
+      load();
+      setTimeout(() => setSuccess(null), 3000);
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed');
+    }
+  };
+
+  const startEdit = (s: QuestionSubmission) => {
+    setForm({ text: s.text, answer: s.answer, category: s.category, difficulty: s.difficulty });
+    setEditingId(s.id);
+  };
+
+  const saveEdit = () => act(`/api/quiz/submissions/${editingId}`, { method: 'PUT', body: JSON.stringify(form) }, 'Submission updated');
+
+  const approve = (s: QuestionSubmission) =>
+    act(`/api/quiz/submissions/${s.id}/approve`, { method: 'POST' }, 'Added to the question bank');
+
+  const reject = (s: QuestionSubmission) => {
+    const reason = window.prompt('Reject this question? Note for the contributor (optional):');
+    if (reason === null) return;
+    act(`/api/quiz/submissions/${s.id}/reject`, { method: 'POST', body: JSON.stringify({ reason }) }, 'Submission rejected');
+  };
+
+  return (
+    <div>
+      {error && <div className="ah-banner ah-banner--error" onClick={() => setError(null)}>{error}</div>}
+      <Toast message={success} />
+
+      <div className="ah-flex gap-2 mb-4">
+        <label className="ah-label">Status: </label>
+        <select value={status} onChange={e => setStatus(e.target.value)} className="ah-select">
+          <option value="pending">Pending</option>
+          <option value="approved">Approved</option>
+          <option value="rejected">Rejected</option>
+          <option value="all">All</option>
+        </select>
+      </div>
+
+      {submissions.length === 0 ? (
+        <div className="ah-card"><p className="ah-meta">No submissions.</p></div>
+      ) : (
+        submissions.map(s => (
+          <div key={s.id} className="ah-card">
+            {editingId === s.id ? (
+              <div>
+                <textarea
+                  className="ah-input w-full h-15 resize-y"
+                  value={form.text}
+                  onChange={e => setForm(f => ({ ...f, text: e.target.value }))}
+                />
+                <div className="ah-flex flex-wrap gap-2 mt-2">
+                  <input className="ah-input flex-2" placeholder="Answer" value={form.answer} onChange={e => setForm(f => ({ ...f, answer: e.target.value }))} />
+                  <input className="ah-input flex-1" placeholder="Category" value={form.category} onChange={e => setForm(f => ({ ...f, category: e.target.value }))} />
+                  <select className="ah-select" value={form.difficulty} onChange={e => setForm(f => ({ ...f, difficulty: e.target.value }))}>
+                    <option value="easy">Easy</option>
+                    <option value="medium">Medium</option>
+                    <option value="hard">Hard</option>
+                  </select>
+                </div>
+                <div className="ah-flex gap-2 mt-2">
+                  <button className="ah-btn-primary" onClick={saveEdit}>Save</button>
+                  <button className="ah-btn-outline" onClick={() => setEditingId(null)}>Cancel</button>
+                </div>
+              </div>
+            ) : (
+              <div className="flex justify-between items-start">
+                <div className="flex-1">
+                  <p className="font-medium text-sm">{s.text}</p>
+                  <p className="ah-meta">Answer: <strong>{s.answer}</strong> · {s.difficulty} {s.category && `· ${s.category}`}</p>
+                  <p className="ah-meta">
+                    From {s.contributorName || s.contributorEmail} · {new Date(s.createdAt).toLocaleString()}
+                  </p>
+                  {s.status !== 'pending' && (
+                    <p className="ah-meta">
+                      {s.status} by {s.reviewedBy}{s.reviewNote && ` — ${s.reviewNote}`}
+                      {s.questionId && ` · question #${s.questionId}`}
+                    </p>
+                  )}
+                </div>
+                {!isReadOnly && s.status === 'pending' && (
+                  <div className="ah-flex gap-1 flex-shrink-0 ml-3">
+                    <button className="ah-btn-primary" onClick={() => approve(s)}>Approve</button>
+                    <button className="ah-btn-outline" onClick={() => startEdit(s)}>Edit</button>
+                    <button className="ah-btn-danger" onClick={() => reject(s)}>Reject</button>
+                  </div>
+                )}
+              </div>
+            )}
+          </div>
+        ))
+      )}
     </div>
   );
 }
@@ -1957,12 +2795,13 @@ function QuizPacksTab({ api, isReadOnly }: { api: ReturnType<typeof useApi>; isR
   const [newRoundTimeLimit, setNewRoundTimeLimit] = useState('');
   const [editRoundId, setEditRoundId] = useState<number | null>(null);
   const [roundQuestionIds, setRoundQuestionIds] = useState<number[]>([]);
+  const [gen, setGen] = useState({ name: '', type: 'text', count: '10', categories: '', curve: 'rising', excludeDays: '90', mediaPercent: '' });
   const [error, setError] = useState<string | null>(null);
   const [success, setSuccess] = useState<string | null>(null);
 
   const loadPacks = useCallback(() => {
     api('/api/quiz/packs').then(d => setPacks(d.packs || [])).catch(err => setError(err.message));
-    api('/api/quiz/questions').then(d => setQuestions(d.questions || [])).catch(() => {});
+    api(`/api/quiz/questions?${ALL_ROWS}`).then(d => setQuestions(d.questions || [])).catch(() => {});
   }, [api]);
 
   useEffect(() => { loadPacks(); }, [loadPacks]);
@@ -1986,12 +2825,29 @@ function QuizPacksTab({ api, isReadOnly }: { api: ReturnType<typeof useApi>; isR
     }
   };
 
+  const importPack = async (e: React.ChangeEvent<HTMLInputElement>) => {
+    const file = e.target.files?.[0];
+    if (!file) return;
+    try {
+      const data = await api('/api/quiz/packs/import', { method: 'POST', body: await file.text() });
+      let msg = `Pack ${data.created ? 'imported' : 'updated'} — ${data.rounds} round${data.rounds !== 1 ? 's' : ''}`;
+      if (data.missingQuestions?.length) msg += `. ${data.missingQuestions.length} question(s) not in the bank were left out — import the questions CSV first`;
+      setSuccess(msg);
+      loadPacks();
+      if (selectedPackId === data.packId) loadRounds(data.packId);
+      setTimeout(() => setSuccess(null), 5000);
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Import failed');
+    }
+    e.target.value = '';
+  };
+
   const deletePack = async (id: number, name: string) => {
-    if (!window.confirm(`Delete pack "${name}" and all its rounds?`)) return;
+    if (!window.confirm(`Delete pack "${name}"? It goes to the Trash with its rounds, and can be restored from there.`)) return;
     try {
       await api(`/api/quiz/packs/${id}`, { method: 'DELETE' });
       if (selectedPackId === id) { setSelectedPackId(null); setRounds([]); }
-      setSuccess('Pack deleted');
+      setSuccess('Pack moved to Trash');
       loadPacks();
       setTimeout(() => setSuccess(null), 3000);
     } catch (err) {
@@ -2015,6 +2871,33 @@ function QuizPacksTab({ api, isReadOnly }: { api: ReturnType<typeof useApi>; isR
     }
   };
 
+  // Category mix is typed as "History: 3, Sport: 2"; the rest of the round comes from any category
+  const generateRound = async () => {
+    if (!selectedPackId || !gen.name.trim()) return;
+    const categories: Record<string, number> = {};
+    for (const part of gen.categories.split(',')) {
+      const [name, n] = part.split(':').map(s => s.trim());
+      if (name) categories[name] = parseInt(n) || 1;
+    }
+    const body: Record<string, unknown> = {
+      name: gen.name.trim(), type: gen.type, count: parseInt(gen.count) || 10, categories,
+      difficultyCurve: gen.curve, excludeRecentDays: parseInt(gen.excludeDays) || 0,
+    };
+    if (newRoundTimeLimit) body.timeLimitSeconds = parseInt(newRoundTimeLimit);
+    if (gen.mediaPercent !== '') body.mediaRatio = Math.min(100, Math.max(0, parseInt(gen.mediaPercent) || 0)) / 100;
+    try {
+      const data = await api(`/api/quiz/packs/${selectedPackId}/rounds/generate`, { method: 'POST', body: JSON.stringify(body) });
+      let msg = `Round ${data.roundNumber} generated with ${data.questionIds.length} questions`;
+      if (data.relaxedDifficulty > 0) msg += ` (${data.relaxedDifficulty} off the difficulty curve)`;
+      setSuccess(msg);
+      setGen({ ...gen, name: '' });
+      loadRounds(selectedPackId);
+      setTimeout(() => setSuccess(null), 5000);
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed');
+    }
+  };
+
   const deleteRound = async (roundId: number) => {
     if (!window.confirm('Delete this round?')) return;
     try {
@@ -2066,6 +2949,9 @@ function QuizPacksTab({ api, isReadOnly }: { api: ReturnType<typeof useApi>; isR
             <input className="ah-input flex-grow" placeholder="Description (optional)" value={newPackDesc} onChange={e => setNewPackDesc(e.target.value)} />
             <button className="ah-btn-primary" onClick={createPack} disabled={!newPackName.trim()}>Create</button>
           </div>
+          <p className="ah-meta mt-2">
+            Or import a pack exported from another environment: <input type="file" accept=".json" onChange={importPack} />
+          </p>
         </div>
       )}
 
@@ -2086,12 +2972,18 @@ function QuizPacksTab({ api, isReadOnly }: { api: ReturnType<typeof useApi>; isR
                     <p className="ah-meta">{p.roundCount} round{p.roundCount !== 1 ? 's' : ''}</p>
                     {p.description && <p className="ah-meta">{p.description}</p>}
                   </div>
-                  {!isReadOnly && (
-                    <button className="ah-btn-danger text-xs py-1 px-2"
-                      onClick={e => { e.stopPropagation(); deletePack(p.id, p.name); }}>
-                      Delete
+                  <div className="ah-flex gap-1">
+                    <button className="ah-btn-outline text-xs py-1 px-2"
+                      onClick={e => { e.stopPropagation(); window.open(`/api/quiz/packs/${p.id}/export`, '_blank'); }}>
+                      Export
                     </button>
-                  )}
+                    {!isReadOnly && (
+                      <button className="ah-btn-danger text-xs py-1 px-2"
+                        onClick={e => { e.stopPropagation(); deletePack(p.id, p.name); }}>
+                        Delete
+                      </button>
+                    )}
+                  </div>
                 </div>
               </div>
             ))
@@ -2112,6 +3004,7 @@ function QuizPacksTab({ api, isReadOnly }: { api: ReturnType<typeof useApi>; isR
                     <option value="text">Text</option>
                     <option value="picture">Picture</option>
                     <option value="music">Music</option>
+                    <option value="photo">Photo (players send a picture)</option>
                   </select>
                   <input className="ah-input w-[90px]" placeholder="Time (s)" type="number" value={newRoundTimeLimit} onChange={e => setNewRoundTimeLimit(e.target.value)} />
                   <button className="ah-btn-primary" onClick={createRound} disabled={!newRoundName.trim()}>Add</button>
@@ -2119,6 +3012,35 @@ function QuizPacksTab({ api, isReadOnly }: { api: ReturnType<typeof useApi>; isR
               </div>
             )}
 
+            {!isReadOnly && (
+              <div className="ah-card mb-3">
+                <h4 className="text-sm font-semibold mb-2">Generate Round from the Question Bank</h4>
+                <div className="ah-flex flex-wrap gap-2">
+                  <input className="ah-input flex-2" placeholder="Round name" value={gen.name} onChange={e => setGen({ ...gen, name: e.target.value })} />
+                  <select className="ah-select" value={gen.type} onChange={e => setGen({ ...gen, type: e.target.value })}>
+                    <option value="text">Text</option>
+                    <option value="picture">Picture</option>
+                    <option value="music">Music</option>
+                  </select>
+                  <input className="ah-input w-[80px]" placeholder="Questions" type="number" value={gen.count} onChange={e => setGen({ ...gen, count: e.target.value })} />
+                  <select className="ah-select" value={gen.curve} onChange={e => setGen({ ...gen, curve: e.target.value })}>
+                    <option value="any">Any difficulty</option>
+                    <option value="rising">Easy → hard</option>
+                    <option value="falling">Hard → easy</option>
+                    <option value="easy">All easy</option>
+                    <option value="medium">All medium</option>
+                    <option value="hard">All hard</option>
+                  </select>
+                </div>
+                <div className="ah-flex flex-wrap gap-2 mt-2">
+                  <input className="ah-input flex-2" placeholder="Category mix, e.g. History: 3, Sport: 2" value={gen.categories} onChange={e => setGen({ ...gen, categories: e.target.value })} />
+                  <input className="ah-input w-[120px]" placeholder="Media %" type="number" title="Share of questions with a picture or audio; blank = don't mind" value={gen.mediaPercent} onChange={e => setGen({ ...gen, mediaPercent: e.target.value })} />
+                  <input className="ah-input w-[150px]" placeholder="Skip used in (days)" type="number" title="Leave out questions from packs played this recently" value={gen.excludeDays} onChange={e => setGen({ ...gen, excludeDays: e.target.value })} />
+                  <button className="ah-btn-primary" onClick={generateRound} disabled={!gen.name.trim()}>Generate</button>
+                </div>
+              </div>
+            )}
+
             {rounds.length === 0 ? (
               <div className="ah-card"><p className="ah-meta">No rounds yet.</p></div>
             ) : (
@@ -2807,4 +3729,664 @@ function SudokuLibraryTab({ api, isReadOnly }: { api: ReturnType<typeof useApi>;
   );
 }
 
+// --- LeaderboardDisputesTab ---
+
+interface LeaderboardDispute {
+  id: number;
+  resultId: number;
+  raisedBy: string;
+  reason: string;
+  status: string;
+  resolution: string;
+  resolvedBy: string;
+  createdAt: string;
+  gameId: string;
+  gameType: string;
+  winnerName: string;
+  loserName: string;
+  isDraw: boolean;
+  score: string;
+  voided: boolean;
+  playedAt: string;
+  isTeam: boolean;
+  confirmation: string;
+}
+
+function LeaderboardDisputesTab({ api, isReadOnly }: {
+  api: ReturnType<typeof useApi>;
+  isReadOnly: boolean;
+}) {
+  const [status, setStatus] = useState('open');
+  const [disputes, setDisputes] = useState<LeaderboardDispute[]>([]);
+  const [error, setError] = useState<string | null>(null);
+  const [success, setSuccess] = useState<string | null>(null);
+
+  const load = useCallback(() => {
+    api(`/api/leaderboard/disputes?status=${status}`)
+      .then(d => setDisputes(d.disputes || []))
+      .catch(err => setError(err.message));
+  }, [api, status]);
+
+  useEffect(() => { load(); }, [load]);
+
+  const act = async (path: string, body: object, message: string) => {
+    try {
+      await api(path, { method: 'POST', body: JSON.stringify(body) });
+      setSuccess(message);
+      load();
+      setTimeout(() => setSuccess(null), 3000);
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed');
+    }
+  };
+
+  const askReason = (prompt: string) => {
+    const reason = window.prompt(prompt);
+    return reason && reason.trim() ? reason.trim() : null;
+  };
+
+  const voidResult = (d: LeaderboardDispute) => {
+    const reason = askReason(`Void ${d.gameType} result ${d.gameId}? Reason:`);
+    if (reason) act(`/api/leaderboard/results/${d.resultId}/void`, { reason }, 'Result voided');
+  };
+
+  const restoreResult = (d: LeaderboardDispute) => {
+    const reason = askReason('Restore this result to the standings? Reason:');
+    if (reason) act(`/api/leaderboard/results/${d.resultId}/restore`, { reason }, 'Result restored');
+  };
+
+  const swapResult = (d: LeaderboardDispute) => {
+    const reason = askReason(`Make ${d.loserName || 'the loser'} the winner? Reason:`);
+    if (reason) act(`/api/leaderboard/results/${d.resultId}/correct`, { swapSides: true, reason }, 'Result corrected');
+  };
+
+  const toggleDraw = (d: LeaderboardDispute) => {
+    const reason = askReason(d.isDraw ? 'Record as a win for the listed winner? Reason:' : 'Record as a draw? Reason:');
+    if (reason) act(`/api/leaderboard/results/${d.resultId}/correct`, { isDraw: !d.isDraw, reason }, 'Result corrected');
+  };
+
+  const editScore = (d: LeaderboardDispute) => {
+    const score = window.prompt('Corrected score:', d.score);
+    if (score === null) return;
+    const reason = askReason('Reason:');
+    if (reason) act(`/api/leaderboard/results/${d.resultId}/correct`, { score, reason }, 'Score corrected');
+  };
+
+  const rejectDispute = (d: LeaderboardDispute) => {
+    const resolution = askReason('Reject this dispute and keep the result? Note for the player:');
+    if (resolution) act(`/api/leaderboard/disputes/${d.id}/reject`, { resolution }, 'Dispute rejected');
+  };
+
+  return (
+    <div>
+      {error && <div className="ah-banner ah-banner--error" onClick={() => setError(null)}>{error}</div>}
+      <Toast message={success} />
+
+      <div className="ah-flex gap-2 mb-4">
+        <label className="ah-label">Status: </label>
+        <select value={status} onChange={e => setStatus(e.target.value)} className="ah-select">
+          <option value="open">Open</option>
+          <option value="upheld">Upheld</option>
+          <option value="rejected">Rejected</option>
+          <option value="all">All</option>
+        </select>
+      </div>
+
+      {disputes.length === 0 ? (
+        <div className="ah-card"><p className="ah-meta">No disputes.</p></div>
+      ) : (
+        disputes.map(d => (
+          <div key={d.id} className="ah-card">
+            <div className="flex justify-between items-start">
+              <div>
+                <strong>{d.gameType}</strong>{d.isTeam && <span className="ah-meta"> · doubles</span>}
+                <p className="ah-meta">
+                  {d.isDraw ? `${d.winnerName} drew with ${d.loserName}` : `${d.winnerName} beat ${d.loserName}`}
+                  {d.score && ` (${d.score})`} · {new Date(d.playedAt).toLocaleString()}
+                  {d.voided && <span className="ah-badge--warning ml-3">Voided</span>}
+                  {d.confirmation === 'disputed' && <span className="ah-badge--warning ml-3">Self-reported</span>}
+                </p>
+                <p><strong>{d.raisedBy}:</strong> {d.reason}</p>
+                {d.status !== 'open' && (
+                  <p className="ah-meta">{d.status} by {d.resolvedBy} — {d.resolution}</p>
+                )}
+              </div>
+              {!isReadOnly && d.status === 'open' && (
+                <div className="ah-flex flex-wrap flex-shrink-0 gap-1 justify-end ml-3">
+                  {!d.isDraw && <button className="ah-btn-outline" onClick={() => swapResult(d)}>Swap winner</button>}
+                  <button className="ah-btn-outline" onClick={() => toggleDraw(d)}>{d.isDraw ? 'Not a draw' : 'Make draw'}</button>
+                  <button className="ah-btn-outline" onClick={() => editScore(d)}>Edit score</button>
+                  {d.voided
+                    ? <button className="ah-btn-outline" onClick={() => restoreResult(d)}>Restore</button>
+                    : <button className="ah-btn-danger" onClick={() => voidResult(d)}>Void</button>}
+                  <button className="ah-btn-outline" onClick={() => rejectDispute(d)}>Reject</button>
+                </div>
+              )}
+            </div>
+          </div>
+        ))
+      )}
+    </div>
+  );
+}
+
+// --- PointsTab ---
+
+interface PointsRule {
+  activity: string;
+  points: number;
+  description: string;
+  updatedBy: string;
+  updatedAt: string;
+}
+
+interface PointsTally {
+  userEmail: string;
+  name: string;
+  earned: number;
+  redeemed: number;
+  balance: number;
+}
+
+interface PointsRedemption {
+  id: number;
+  userEmail: string;
+  name: string;
+  points: number;
+  reward: string;
+  redeemedBy: string;
+  createdAt: string;
+}
+
+function PointsTab({ api, isReadOnly }: {
+  api: ReturnType<typeof useApi>;
+  isReadOnly: boolean;
+}) {
+  const [month, setMonth] = useState(() => new Date().toISOString().slice(0, 7));
+  const [rules, setRules] = useState<PointsRule[]>([]);
+  const [tallies, setTallies] = useState<PointsTally[]>([]);
+  const [redemptions, setRedemptions] = useState<PointsRedemption[]>([]);
+  const [redeemEmail, setRedeemEmail] = useState('');
+  const [redeemPoints, setRedeemPoints] = useState('');
+  const [redeemReward, setRedeemReward] = useState('');
+  const [error, setError] = useState<string | null>(null);
+  const [success, setSuccess] = useState<string | null>(null);
+
+  const load = useCallback(() => {
+    api('/api/points/rules').then(d => setRules(d.rules || [])).catch(err => setError(err.message));
+    api(`/api/points/tallies?month=${month}`).then(d => setTallies(d.tallies || [])).catch(err => setError(err.message));
+    api(`/api/points/redemptions?month=${month}`).then(d => setRedemptions(d.redemptions || [])).catch(err => setError(err.message));
+  }, [api, month]);
+
+  useEffect(() => { load(); }, [load]);
+
+  const flash = (message: string) => {
+    setSuccess(message);
+    setTimeout(() => setSuccess(null), 3000);
+  };
+
+  const updateRule = async (rule: PointsRule) => {
+    const value = window.prompt(`Points for "${rule.description || rule.activity}" (0 = none):`, String(rule.points));
+    if (value === null) return;
+    const pts = parseInt(value, 10);
+    if (isNaN(pts) || pts < 0) { setError('Points must be 0 or more'); return; }
+    try {
+      await api(`/api/points/rules/${rule.activity}`, { method: 'PUT', body: JSON.stringify({ points: pts }) });
+      flash('Points updated');
+      load();
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed');
+    }
+  };
+
+  const redeem = async () => {
+    const pts = parseInt(redeemPoints, 10);
+    if (!redeemEmail.trim() || isNaN(pts) || pts <= 0 || !redeemReward.trim()) {
+      setError('Player, points and reward are required');
+      return;
+    }
+    try {
+      const d = await api('/api/points/redemptions', {
+        method: 'POST',
+        body: JSON.stringify({ userEmail: redeemEmail.trim(), points: pts, reward: redeemReward.trim() }),
+      });
+      flash(`Redeemed - ${d.balance} points left`);
+      setRedeemEmail('');
+      setRedeemPoints('');
+      setRedeemReward('');
+      load();
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed');
+    }
+  };
+
+  return (
+    <div>
+      {error && <div className="ah-banner ah-banner--error" onClick={() => setError(null)}>{error}</div>}
+      <Toast message={success} />
+
+      <div className="ah-card">
+        <h3 className="ah-section-title">Points per activity</h3>
+        {rules.map(rule => (
+          <div key={rule.activity} className="ah-flex justify-between items-center py-1">
+            <span>{rule.description || rule.activity}</span>
+            <span className="ah-flex gap-2 items-center">
+              <strong>{rule.points}</strong>
+              {!isReadOnly && <button className="ah-btn-outline" onClick={() => updateRule(rule)}>Change</button>}
+            </span>
+          </div>
+        ))}
+      </div>
+
+      {!isReadOnly && (
+        <div className="ah-card">
+          <h3 className="ah-section-title">Redeem points</h3>
+          <div className="ah-flex flex-wrap gap-2">
+            <input
+              className="ah-input flex-1 min-w-[180px]"
+              placeholder="Player email"
+              value={redeemEmail}
+              onChange={e => setRedeemEmail(e.target.value)}
+            />
+            <input
+              className="ah-input w-24"
+              type="number"
+              min={1}
+              placeholder="Points"
+              value={redeemPoints}
+              onChange={e => setRedeemPoints(e.target.value)}
+            />
+            <input
+              className="ah-input flex-1 min-w-[180px]"
+              placeholder="Reward (e.g. Free pint)"
+              value={redeemReward}
+              onChange={e => setRedeemReward(e.target.value)}
+            />
+            <button className="ah-btn-primary" onClick={redeem}>Redeem</button>
+          </div>
+        </div>
+      )}
+
+      <div className="ah-flex gap-2 mb-4">
+        <label className="ah-label">Month: </label>
+        <input type="month" className="ah-input" value={month} onChange={e => e.target.value && setMonth(e.target.value)} />
+      </div>
+
+      <h3 className="ah-section-title">Monthly tallies</h3>
+      {tallies.length === 0 ? (
+        <div className="ah-card"><p className="ah-meta">No points this month.</p></div>
+      ) : (
+        <div className="ah-table">
+          <div className="ah-table-header">
+            <span className="flex-[2]">Player</span>
+            <span className="flex-1">Earned</span>
+            <span className="flex-1">Redeemed</span>
+            <span className="flex-1">Balance</span>
+          </div>
+          {tallies.map(t => (
+            <div key={t.userEmail} className="ah-table-row">
+              <span className="flex-[2] text-sm">{t.name} <span className="text-xs text-stone-500">{t.userEmail}</span></span>
+              <span className="flex-1 text-sm">{t.earned}</span>
+              <span className="flex-1 text-sm">{t.redeemed}</span>
+              <span className="flex-1 text-sm font-medium">{t.balance}</span>
+            </div>
+          ))}
+        </div>
+      )}
+
+      <h3 className="ah-section-title mt-4">Redemption log</h3>
+      {redemptions.length === 0 ? (
+        <div className="ah-card"><p className="ah-meta">No redemptions this month.</p></div>
+      ) : (
+        <div className="ah-table">
+          <div className="ah-table-header">
+            <span className="flex-[2]">Player</span>
+            <span className="flex-[2]">Reward</span>
+            <span className="flex-1">Points</span>
+            <span className="flex-[2]">By</span>
+          </div>
+          {redemptions.map(rd => (
+            <div key={rd.id} className="ah-table-row">
+              <span className="flex-[2] text-sm">{rd.name}</span>
+              <span className="flex-[2] text-sm">{rd.reward}</span>
+              <span className="flex-1 text-sm">{rd.points}</span>
+              <span className="flex-[2] text-xs text-stone-500">{rd.redeemedBy} · {new Date(rd.createdAt).toLocaleString()}</span>
+            </div>
+          ))}
+        </div>
+      )}
+    </div>
+  );
+}
+
+// --- AnalyticsTab ---
+
+interface AnalyticsAggregate {
+  day: string;
+  app: string;
+  event: string;
+  count: number;
+  total: number;
+  average: number;
+}
+
+const ANALYTICS_EVENTS: Record<string, { label: string; unit?: string }> = {
+  app_opened: { label: 'Apps opened' },
+  game_length: { label: 'Games played', unit: 'min' },
+  quiz_participation: { label: 'Quizzes run', unit: 'players' },
+};
+
+// Averages are stored in seconds for game lengths; show them in minutes
+function analyticsAverage(a: { event: string; count: number; total: number }): string {
+  const info = ANALYTICS_EVENTS[a.event];
+  if (!info?.unit || a.count === 0) return '';
+  const avg = a.total / a.count;
+  const value = a.event === 'game_length' ? avg / 60 : avg;
+  return `${value.toFixed(1)} ${info.unit} avg`;
+}
+
+function AnalyticsTab({ api }: { api: ReturnType<typeof useApi> }) {
+  const [to, setTo] = useState(() => new Date().toISOString().slice(0, 10));
+  const [from, setFrom] = useState(() => new Date(Date.now() - 29 * 86400000).toISOString().slice(0, 10));
+  const [aggregates, setAggregates] = useState<AnalyticsAggregate[]>([]);
+  const [error, setError] = useState<string | null>(null);
+
+  useEffect(() => {
+    api(`/api/analytics/daily?from=${from}&to=${to}`)
+      .then(d => { setAggregates(d.aggregates || []); setError(null); })
+      .catch(err => setError(err.message));
+  }, [api, from, to]);
+
+  // Whole range per app and event, busiest first
+  const totals = Object.values(aggregates.reduce((acc, a) => {
+    const key = `${a.app}/${a.event}`;
+    const t = acc[key] || (acc[key] = { app: a.app, event: a.event, count: 0, total: 0 });
+    t.count += a.count;
+    t.total += a.total;
+    return acc;
+  }, {} as Record<string, { app: string; event: string; count: number; total: number }>))
+    .sort((a, b) => b.count - a.count);
+
+  const days = Array.from(new Set(aggregates.map(a => a.day))).sort().reverse();
+
+  return (
+    <div>
+      {error && <div className="ah-banner ah-banner--error" onClick={() => setError(null)}>{error}</div>}
+
+      <div className="ah-flex gap-2 mb-4 items-center">
+        <label className="ah-label">From: </label>
+        <input type="date" className="ah-input" value={from} onChange={e => e.target.value && setFrom(e.target.value)} />
+        <label className="ah-label">To: </label>
+        <input type="date" className="ah-input" value={to} onChange={e => e.target.value && setTo(e.target.value)} />
+      </div>
+
+      <h3 className="ah-section-title">Totals</h3>
+      {totals.length === 0 ? (
+        <div className="ah-card"><p className="ah-meta">No usage recorded in this range.</p></div>
+      ) : (
+        <div className="ah-table">
+          <div className="ah-table-header">
+            <span className="flex-[2]">App</span>
+            <span className="flex-[2]">Event</span>
+            <span className="flex-1">Count</span>
+            <span className="flex-[2]">Average</span>
+          </div>
+          {totals.map(t => (
+            <div key={`${t.app}/${t.event}`} className="ah-table-row">
+              <span className="flex-[2] text-sm">{t.app}</span>
+              <span className="flex-[2] text-sm">{ANALYTICS_EVENTS[t.event]?.label || t.event}</span>
+              <span className="flex-1 text-sm font-medium">{t.count}</span>
+              <span className="flex-[2] text-xs text-stone-500">{analyticsAverage(t)}</span>
+            </div>
+          ))}
+        </div>
+      )}
+
+      {days.length > 0 && (
+        <>
+          <h3 className="ah-section-title mt-4">By day</h3>
+          <div className="ah-table">
+            <div className="ah-table-header">
+              <span className="flex-1">Day</span>
+              <span className="flex-[2]">App</span>
+              <span className="flex-[2]">Event</span>
+              <span className="flex-1">Count</span>
+              <span className="flex-[2]">Average</span>
+            </div>
+            {days.flatMap(day => aggregates.filter(a => a.day === day).map(a => (
+              <div key={`${a.day}/${a.app}/${a.event}`} className="ah-table-row">
+                <span className="flex-1 text-sm">{new Date(`${a.day}T00:00`).toLocaleDateString(undefined, { weekday: 'short', day: 'numeric', month: 'short' })}</span>
+                <span className="flex-[2] text-sm">{a.app}</span>
+                <span className="flex-[2] text-sm">{ANALYTICS_EVENTS[a.event]?.label || a.event}</span>
+                <span className="flex-1 text-sm">{a.count}</span>
+                <span className="flex-[2] text-xs text-stone-500">{analyticsAverage(a)}</span>
+              </div>
+            )))}
+          </div>
+        </>
+      )}
+    </div>
+  );
+}
+
+// --- TrashTab ---
+// Deleted LMS games, sweepstakes competitions and quiz packs, restorable until
+// the retention period runs out.
+
+interface TrashItem {
+  type: string;
+  label: string;
+  id: number;
+  name: string;
+  deletedAt: string;
+  deletedBy: string;
+  purgeAt: string;
+}
+
+function TrashTab({ api, isReadOnly }: {
+  api: ReturnType<typeof useApi>;
+  isReadOnly: boolean;
+}) {
+  const [items, setItems] = useState<TrashItem[]>([]);
+  const [retentionDays, setRetentionDays] = useState(30);
+  const [error, setError] = useState<string | null>(null);
+  const [success, setSuccess] = useState<string | null>(null);
+
+  const load = useCallback(() => {
+    api('/api/trash')
+      .then(d => { setItems(d.items || []); setRetentionDays(d.retentionDays || 30); })
+      .catch(err => setError(err.message));
+  }, [api]);
+
+  useEffect(() => { load(); }, [load]);
+
+  const restore = async (item: TrashItem) => {
+    try {
+      await api(`/api/trash/${item.type}/${item.id}/restore`, { method: 'POST' });
+      setSuccess(`Restored "${item.name}"`);
+      load();
+      setTimeout(() => setSuccess(null), 3000);
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed');
+    }
+  };
+
+  return (
+    <div>
+      {error && <div className="ah-banner ah-banner--error" onClick={() => setError(null)}>{error}</div>}
+      <Toast message={success} />
+
+      <p className="ah-meta mb-4">
+        Deleted games, competitions and quiz packs are kept for {retentionDays} days, then removed permanently.
+      </p>
+
+      {items.length === 0 ? (
+        <div className="ah-card"><p className="ah-meta">The trash is empty.</p></div>
+      ) : (
+        <div className="ah-table">
+          <div className="ah-table-header">
+            <span className="flex-1">Type</span>
+            <span className="flex-[2]">Name</span>
+            <span className="flex-[2]">Deleted</span>
+            <span className="flex-1">Purged after</span>
+            <span className="w-24"></span>
+          </div>
+          {items.map(item => (
+            <div key={`${item.type}-${item.id}`} className="ah-table-row">
+              <span className="flex-1 text-sm">{item.label}</span>
+              <span className="flex-[2] text-sm font-medium">{item.name}</span>
+              <span className="flex-[2] text-xs text-stone-500">
+                {new Date(item.deletedAt).toLocaleString()}{item.deletedBy && ` · ${item.deletedBy}`}
+              </span>
+              <span className="flex-1 text-xs text-stone-500">{new Date(item.purgeAt).toLocaleDateString()}</span>
+              <span className="w-24">
+                {!isReadOnly && <button className="ah-btn-outline" onClick={() => restore(item)}>Restore</button>}
+              </span>
+            </div>
+          ))}
+        </div>
+      )}
+    </div>
+  );
+}
+
+// --- ContributorPortal ---
+// What question_contributor users see instead of the admin modules: submit
+// text questions and follow them through review.
+
+function ContributorPortal({ api, onLobby }: { api: ReturnType<typeof useApi>; onLobby: () => void }) {
+  const [submissions, setSubmissions] = useState<QuestionSubmission[]>([]);
+  const [categories, setCategories] = useState<string[]>([]);
+  const [editingId, setEditingId] = useState<number | null>(null);
+  const [form, setForm] = useState({ text: '', answer: '', category: '', difficulty: 'medium' });
+  const [error, setError] = useState<string | null>(null);
+  const [success, setSuccess] = useState<string | null>(null);
+
+  const load = useCallback(() => {
+    api('/api/contribute/questions')
+      .then(d => { setSubmissions(d.submissions || []); setCategories(d.categories || []); })
+      .catch(err => setError(err.message));
+  }, [api]);
+
+  useEffect(() => { load(); }, [load]);
+
+  const resetForm = () => {
+    setForm({ text: '', answer: '', category: '', difficulty: 'medium' });
+    setEditingId(null);
+  };
+
+  const save = async () => {
+    if (!form.text.trim() || !form.answer.trim()) { setError('Question and answer required'); return; }
+    try {
+      if (editingId) {
+        await api(`/api/contribute/questions/${editingId}`, { method: 'PUT', body: JSON.stringify(form) });
+        setSuccess('Question updated');
+      } else {
+        await api('/api/contribute/questions', { method: 'POST', body: JSON.stringify(form) });
+        setSuccess('Question sent for review');
+      }
+      resetForm();
+      load();
+      setTimeout(() => setSuccess(null), 3000);
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed');
+    }
+  };
+
+  const withdraw = async (id: number) => {
+    if (!window.confirm('Withdraw this question?')) return;
+    try {
+      await api(`/api/contribute/questions/${id}`, { method: 'DELETE' });
+      setSuccess('Question withdrawn');
+      load();
+      setTimeout(() => setSuccess(null), 3000);
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed');
+    }
+  };
+
+  const statusBadge = (status: QuestionSubmission['status']) => {
+    const colours = {
+      pending: { backgroundColor: '#FFF8E1', color: '#F57F17' },
+      approved: { backgroundColor: '#E8F5E9', color: '#2E7D32' },
+      rejected: { backgroundColor: '#FFEBEE', color: '#C62828' },
+    };
+    return <span className="ah-badge" style={colours[status]}>{status.toUpperCase()}</span>;
+  };
+
+  return (
+    <>
+      <div className="ah-app-header">
+        <div className="ah-app-header-left">
+          <h1 className="ah-app-title">Quiz Questions</h1>
+        </div>
+        <div className="ah-app-header-right">
+          <button className="ah-lobby-btn" onClick={onLobby}>← Lobby</button>
+        </div>
+      </div>
+
+      <div className="ah-container">
+        {error && <div className="ah-banner ah-banner--error" onClick={() => setError(null)}>{error}</div>}
+        <Toast message={success} />
+
+        <div className="ah-card">
+          <h3 className="ah-section-title">{editingId ? 'Edit Question' : 'Suggest a Question'}</h3>
+          <p className="ah-meta">Questions are checked by the quiz team before they go into the bank.</p>
+          <textarea
+            className="ah-input w-full h-15 resize-y mt-2"
+            placeholder="Question"
+            value={form.text}
+            onChange={e => setForm(f => ({ ...f, text: e.target.value }))}
+          />
+          <div className="ah-flex flex-wrap gap-2 mt-2">
+            <input className="ah-input flex-2" placeholder="Answer" value={form.answer} onChange={e => setForm(f => ({ ...f, answer: e.target.value }))} />
+            <input className="ah-input flex-1" placeholder="Category" list="contribute-categories" value={form.category} onChange={e => setForm(f => ({ ...f, category: e.target.value }))} />
+            <datalist id="contribute-categories">
+              {categories.map(c => <option key={c} value={c} />)}
+            </datalist>
+            <select className="ah-select" value={form.difficulty} onChange={e => setForm(f => ({ ...f, difficulty: e.target.value }))}>
+              <option value="easy">Easy</option>
+              <option value="medium">Medium</option>
+              <option value="hard">Hard</option>
+            </select>
+          </div>
+          <div className="ah-flex gap-2 mt-2">
+            <button className="ah-btn-primary" onClick={save}>{editingId ? 'Save' : 'Submit'}</button>
+            {editingId && <button className="ah-btn-outline" onClick={resetForm}>Cancel</button>}
+          </div>
+        </div>
+
+        <h3 className="ah-section-title">Your Questions</h3>
+        {submissions.length === 0 ? (
+          <div className="ah-card"><p className="ah-meta">Nothing submitted yet.</p></div>
+        ) : (
+          submissions.map(s => (
+            <div key={s.id} className="ah-card">
+              <div className="flex justify-between items-start">
+                <div className="flex-1">
+                  <p className="font-medium text-sm">{s.text}</p>
+                  <p className="ah-meta">Answer: <strong>{s.answer}</strong> · {s.difficulty} {s.category && `· ${s.category}`}</p>
+                  {statusBadge(s.status)}
+                  {s.reviewNote && <p className="ah-meta mt-1">Reviewer: {s.reviewNote}</p>}
+                </div>
+                {s.status === 'pending' && (
+                  <div className="ah-flex gap-1 flex-shrink-0 ml-3">
+                    <button
+                      className="ah-btn-outline"
+                      onClick={() => { setForm({ text: s.text, answer: s.answer, category: s.category, difficulty: s.difficulty }); setEditingId(s.id); }}
+                    >
+                      Edit
+                    </button>
+                    <button className="ah-btn-danger" onClick={() => withdraw(s.id)}>Withdraw</button>
+                  </div>
+                )}
+              </div>
+            </div>
+          ))
+        )}
+      </div>
+    </>
+  );
+}
+
 export default App;
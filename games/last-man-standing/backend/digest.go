@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/achgithub/activity-hub-common/services"
+	"github.com/lib/pq"
+)
+
+// handleDigest - POST /api/digest {emails, until}
+// Called by the identity shell (service token) for its email digest: each
+// surviving player's pick deadlines before until in rounds they haven't
+// picked for yet. Games in the trash are left out.
+func handleDigest(w http.ResponseWriter, r *http.Request) {
+	var req services.DigestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	digest := services.Digest{Items: map[string][]services.DigestItem{}, Everyone: []services.DigestItem{}}
+	if len(req.Emails) == 0 {
+		sendJSON(w, digest)
+		return
+	}
+
+	rows, err := appDB.Query(`
+		SELECT gp.user_id, g.name, r.label, r.submission_deadline
+		FROM game_players gp
+		JOIN games g ON g.id = gp.game_id AND g.deleted_at IS NULL AND g.status = 'active'
+		JOIN rounds r ON r.game_id = g.id AND r.status = 'open'
+		WHERE gp.user_id = ANY($1) AND gp.is_active = TRUE
+		  AND r.submission_deadline > NOW() AND r.submission_deadline <= $2
+		  AND NOT EXISTS (
+		      SELECT 1 FROM predictions p
+		      WHERE p.user_id = gp.user_id AND p.game_id = g.id AND p.round_id = r.id AND p.voided = FALSE
+		  )
+		ORDER BY r.submission_deadline
+	`, pq.Array(req.Emails), req.Until)
+	if err != nil {
+		log.Printf("Failed to load digest deadlines: %v", err)
+		sendError(w, "Failed to load deadlines", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var email, gameName string
+		var label int
+		var deadline time.Time
+		if err := rows.Scan(&email, &gameName, &label, &deadline); err != nil {
+			continue
+		}
+		digest.Items[email] = append(digest.Items[email], services.DigestItem{
+			Title:  fmt.Sprintf("Pick for Round %d of %s", label, gameName),
+			Detail: "You haven't made your pick yet",
+			At:     deadline,
+		})
+	}
+	sendJSON(w, digest)
+}
@@ -10,6 +10,8 @@ import (
 	"time"
 
 	authlib "github.com/achgithub/activity-hub-common/auth"
+	apphttp "github.com/achgithub/activity-hub-common/http"
+	"github.com/achgithub/activity-hub-common/points"
 	"github.com/gorilla/mux"
 )
 
@@ -29,12 +31,16 @@ func handleConfig(identityDB *sql.DB) http.HandlerFunc {
 			}
 		}
 
-		sendJSON(w, map[string]interface{}{
-			"appName":           "Last Man Standing",
-			"version":           "1.0.0",
-			"isImpersonating":   isImpersonating,
-			"impersonatedBy":    impersonatedBy,
-			"impersonatedEmail": impersonatedEmail,
+		apphttp.WriteConfig(w, apphttp.AppConfig{
+			AppID:       "last-man-standing",
+			Name:        "Last Man Standing",
+			Icon:        "🏆",
+			Description: "Football prediction - pick one team per round, last one standing wins",
+			Extra: map[string]interface{}{
+				"isImpersonating":   isImpersonating,
+				"impersonatedBy":    impersonatedBy,
+				"impersonatedEmail": impersonatedEmail,
+			},
 		})
 	}
 }
@@ -55,7 +61,7 @@ func handleGetCurrentGame(w http.ResponseWriter, r *http.Request) {
 	}
 	err = appDB.QueryRow(`
 		SELECT id, name, status, winner_count
-		FROM games WHERE id = $1
+		FROM games WHERE id = $1 AND deleted_at IS NULL
 	`, gameID).Scan(&game.ID, &game.Name, &game.Status, &game.WinnerCount)
 	if err != nil {
 		sendJSON(w, map[string]interface{}{"game": nil})
@@ -65,35 +71,49 @@ func handleGetCurrentGame(w http.ResponseWriter, r *http.Request) {
 	sendJSON(w, map[string]interface{}{"game": game})
 }
 
-// handleJoinGame joins the current game.
-func handleJoinGame(w http.ResponseWriter, r *http.Request) {
-	user, ok := authlib.GetUserFromContext(r.Context())
-	if !ok {
-		sendError(w, "Unauthorized", http.StatusUnauthorized)
-		return
-	}
+// handleJoinGame joins the current game. Entering the pub's game earns
+// lms_entry loyalty points (private games don't).
+func handleJoinGame(identityDB *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, ok := authlib.GetUserFromContext(r.Context())
+		if !ok {
+			sendError(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
 
-	gameID, err := getCurrentGameID()
-	if err != nil {
-		sendError(w, "No active game", http.StatusBadRequest)
-		return
-	}
+		gameID, err := getCurrentGameID()
+		if err != nil {
+			sendError(w, "No active game", http.StatusBadRequest)
+			return
+		}
 
-	_, err = appDB.Exec(`
-		INSERT INTO game_players (user_id, game_id)
-		VALUES ($1, $2)
-		ON CONFLICT (user_id, game_id) DO NOTHING
-	`, user.Email, gameID)
-	if err != nil {
-		log.Printf("Error joining game: %v", err)
-		sendError(w, "Failed to join game", http.StatusInternalServerError)
-		return
-	}
+		_, err = appDB.Exec(`
+			INSERT INTO game_players (user_id, game_id)
+			VALUES ($1, $2)
+			ON CONFLICT (user_id, game_id) DO NOTHING
+		`, user.Email, gameID)
+		if err != nil {
+			log.Printf("Error joining game: %v", err)
+			sendError(w, "Failed to join game", http.StatusInternalServerError)
+			return
+		}
 
-	sendJSON(w, map[string]interface{}{"success": true})
+		go func() {
+			if _, err := points.Record(identityDB, points.Award{
+				UserEmail: user.Email,
+				Activity:  points.ActivityLMSEntry,
+				App:       "last-man-standing",
+				Ref:       strconv.Itoa(gameID),
+			}); err != nil {
+				log.Printf("Failed to award LMS entry points to %s: %v", user.Email, err)
+			}
+		}()
+
+		sendJSON(w, map[string]interface{}{"success": true})
+	}
 }
 
-// handleGetGameStatus returns the player's status in the current game.
+// handleGetGameStatus returns the player's status in the game (?gameId=, default current).
 func handleGetGameStatus(w http.ResponseWriter, r *http.Request) {
 	user, ok := authlib.GetUserFromContext(r.Context())
 	if !ok {
@@ -101,7 +121,10 @@ func handleGetGameStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	gameID, err := getCurrentGameID()
+	gameID, err := requestGameID(r, user.Email)
+	if sendGameAccessError(w, err) {
+		return
+	}
 	if err != nil {
 		sendJSON(w, map[string]interface{}{"inGame": false, "gameID": nil})
 		return
@@ -133,7 +156,10 @@ func handleGetOpenRounds(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	gameID, err := getCurrentGameID()
+	gameID, err := requestGameID(r, user.Email)
+	if sendGameAccessError(w, err) {
+		return
+	}
 	if err != nil {
 		sendJSON(w, map[string]interface{}{"rounds": []interface{}{}})
 		return
@@ -204,6 +230,9 @@ func handleGetMatches(w http.ResponseWriter, r *http.Request) {
 
 	gameID, _ := strconv.Atoi(gameIDStr)
 	roundID, _ := strconv.Atoi(roundIDStr)
+	if err := checkGameAccess(gameID, user.Email); sendGameAccessError(w, err) {
+		return
+	}
 
 	// Look up round date range
 	var startDate, endDate time.Time
@@ -292,7 +321,10 @@ func handleSubmitPrediction(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	gameID, err := getCurrentGameID()
+	gameID, err := requestGameID(r, user.Email)
+	if sendGameAccessError(w, err) {
+		return
+	}
 	if err != nil {
 		sendError(w, "No active game", http.StatusBadRequest)
 		return
@@ -304,7 +336,7 @@ func handleSubmitPrediction(w http.ResponseWriter, r *http.Request) {
 		SELECT is_active FROM game_players WHERE user_id = $1 AND game_id = $2
 	`, user.Email, gameID).Scan(&isActive)
 	if err != nil {
-		sendError(w, "You are not in the current game", http.StatusBadRequest)
+		sendError(w, "You are not in this game", http.StatusBadRequest)
 		return
 	}
 	if !isActive {
@@ -368,7 +400,7 @@ func handleSubmitPrediction(w http.ResponseWriter, r *http.Request) {
 	sendJSON(w, map[string]interface{}{"success": true})
 }
 
-// handleGetPredictions returns the player's predictions for the current game.
+// handleGetPredictions returns the player's predictions for the game.
 func handleGetPredictions(w http.ResponseWriter, r *http.Request) {
 	user, ok := authlib.GetUserFromContext(r.Context())
 	if !ok {
@@ -376,7 +408,10 @@ func handleGetPredictions(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	gameID, err := getCurrentGameID()
+	gameID, err := requestGameID(r, user.Email)
+	if sendGameAccessError(w, err) {
+		return
+	}
 	if err != nil {
 		sendJSON(w, map[string]interface{}{"predictions": []interface{}{}})
 		return
@@ -438,7 +473,10 @@ func handleGetUsedTeams(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	gameID, err := getCurrentGameID()
+	gameID, err := requestGameID(r, user.Email)
+	if sendGameAccessError(w, err) {
+		return
+	}
 	if err != nil {
 		sendJSON(w, map[string]interface{}{"teams": []string{}})
 		return
@@ -467,16 +505,18 @@ func handleGetUsedTeams(w http.ResponseWriter, r *http.Request) {
 	sendJSON(w, map[string]interface{}{"teams": teams})
 }
 
-// handleGetStandings returns all players in the current game.
+// handleGetStandings returns all players in the game.
 func handleGetStandings(w http.ResponseWriter, r *http.Request) {
 	user, ok := authlib.GetUserFromContext(r.Context())
 	if !ok {
 		sendError(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
-	_ = user // standings are visible to all authenticated players
-
-	gameID, err := getCurrentGameID()
+	// Standings are visible to all players in the game
+	gameID, err := requestGameID(r, user.Email)
+	if sendGameAccessError(w, err) {
+		return
+	}
 	if err != nil {
 		sendJSON(w, map[string]interface{}{"players": []interface{}{}})
 		return
@@ -522,19 +562,23 @@ func handleGetRoundSummary(w http.ResponseWriter, r *http.Request) {
 		sendError(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
-	_ = user // visible to all authenticated players
-
 	vars := mux.Vars(r)
 	gameIDStr := vars["gameId"]
 	roundIDStr := vars["roundId"]
 
+	// Visible to all players in the game
+	gameID, _ := strconv.Atoi(gameIDStr)
+	if err := checkGameAccess(gameID, user.Email); sendGameAccessError(w, err) {
+		return
+	}
+
 	var label int
 	var startDate, endDate time.Time
 	var status string
 	err := appDB.QueryRow(`
 		SELECT label, start_date, end_date, status FROM rounds
 		WHERE id = $1 AND game_id = $2
-	`, roundIDStr, gameIDStr).Scan(&label, &startDate, &endDate, &status)
+	`, roundIDStr, gameID).Scan(&label, &startDate, &endDate, &status)
 	if err != nil {
 		sendError(w, "Round not found", http.StatusNotFound)
 		return
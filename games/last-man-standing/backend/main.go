@@ -6,15 +6,39 @@ import (
 	"net/http"
 
 	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/achgithub/activity-hub-common/cli"
 	"github.com/achgithub/activity-hub-common/config"
 	"github.com/achgithub/activity-hub-common/database"
-	"github.com/gorilla/handlers"
+	apphttp "github.com/achgithub/activity-hub-common/http"
+	"github.com/achgithub/activity-hub-common/maintenance"
+	"github.com/achgithub/activity-hub-common/services"
 	"github.com/gorilla/mux"
 )
 
 var appDB *sql.DB // last_man_standing_db — used by handlers
 
 func main() {
+	cli.New("last-man-standing",
+		cli.Serve(serve),
+		cli.Migrate(openAppDB, cli.Migrations{
+			Dir:    "../database",
+			Schema: []string{"schema.sql"},
+			Upgrades: []string{
+				"migrate_add_private_games.sql",
+				"migrate_add_guids.sql",
+				"migrate_add_trash.sql",
+				"migrate_add_fixture_versions.sql",
+			},
+		}),
+	).Main()
+}
+
+// openAppDB connects to last_man_standing_db for commands other than serve
+func openAppDB() (*sql.DB, error) {
+	return database.InitDatabaseByName("last_man_standing_db")
+}
+
+func serve() {
 	identityDB, err := database.InitIdentityDatabase()
 	if err != nil {
 		log.Fatal("Failed to connect to identity database:", err)
@@ -32,11 +56,18 @@ func main() {
 	// Public routes (no auth required)
 	r.HandleFunc("/api/config", handleConfig(identityDB)).Methods("GET")
 	r.HandleFunc("/api/games/current", handleGetCurrentGame).Methods("GET")
+	r.HandleFunc("/api/standings/projection", handleGetStandingsProjection(identityDB)).Methods("GET") // TV banter screen
+
+	// Called by the identity shell with a service token
+	r.Handle("/api/digest", authlib.ServiceMiddleware(services.ShellApp)(http.HandlerFunc(handleDigest))).Methods("POST")
 
-	// Auth-protected routes
+	// Auth-protected routes. Player endpoints take ?gameId= for private games
+	// (default: the current game).
 	protected := r.PathPrefix("/api").Subrouter()
 	protected.Use(authlib.Middleware(identityDB))
-	protected.HandleFunc("/games/join", handleJoinGame).Methods("POST")
+	protected.HandleFunc("/games/join", handleJoinGame(identityDB)).Methods("POST")
+	protected.HandleFunc("/games/join-code", handleJoinByCode).Methods("POST")
+	protected.HandleFunc("/games/mine", handleGetMyGames).Methods("GET")
 	protected.HandleFunc("/games/status", handleGetGameStatus).Methods("GET")
 	protected.HandleFunc("/rounds/open", handleGetOpenRounds).Methods("GET")
 	protected.HandleFunc("/matches/{gameId}/round/{roundId}", handleGetMatches).Methods("GET")
@@ -52,13 +83,10 @@ func main() {
 		http.ServeFile(w, r, "./static/index.html")
 	})
 
-	corsHandler := handlers.CORS(
-		handlers.AllowedOrigins([]string{"*"}),
-		handlers.AllowedMethods([]string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}),
-		handlers.AllowedHeaders([]string{"Content-Type", "Authorization"}),
-	)
+	cors := apphttp.NewCORSPolicy(identityDB)
+	appMaintenance := maintenance.New(identityDB)
 
 	port := config.GetEnv("PORT", "4021")
 	log.Printf("🚀 Last Man Standing starting on :%s", port)
-	log.Fatal(http.ListenAndServe(":"+port, corsHandler(r)))
+	log.Fatal(http.ListenAndServe(":"+port, cors.Middleware(appMaintenance.Middleware("last-man-standing")(apphttp.Versioned(r)))))
 }
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	authlib "github.com/achgithub/activity-hub-common/auth"
+)
+
+// Private games run alongside the pub's current game (e.g. a family WhatsApp
+// group). Players join them with a code from game-admin, then pass ?gameId=
+// on player endpoints; without it requests use the current game as before.
+
+var (
+	errUnknownGame = errors.New("game not found")
+	errNotMember   = errors.New("not a member of this private game")
+)
+
+// joinCodeAlphabet matches the codes game-admin generates
+const joinCodeAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+
+// normalizeJoinCode accepts codes as typed or pasted ("k7m-2qx") and returns
+// the canonical form, or "" if it can't be a valid code.
+func normalizeJoinCode(raw string) string {
+	code := strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(raw)))
+	if len(code) != 6 {
+		return ""
+	}
+	for _, c := range code {
+		if !strings.ContainsRune(joinCodeAlphabet, c) {
+			return ""
+		}
+	}
+	return code
+}
+
+// checkGameAccess allows any player into public games; private games are
+// members only.
+func checkGameAccess(gameID int, email string) error {
+	var isPrivate bool
+	if err := appDB.QueryRow(`SELECT is_private FROM games WHERE id = $1`, gameID).Scan(&isPrivate); err != nil {
+		return errUnknownGame
+	}
+	if !isPrivate {
+		return nil
+	}
+	var member bool
+	appDB.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM game_players WHERE game_id = $1 AND user_id = $2)
+	`, gameID, email).Scan(&member)
+	if !member {
+		return errNotMember
+	}
+	return nil
+}
+
+// requestGameID returns the game a player request is about: ?gameId= when
+// given, otherwise the current game.
+func requestGameID(r *http.Request, email string) (int, error) {
+	idStr := r.URL.Query().Get("gameId")
+	if idStr == "" {
+		return getCurrentGameID()
+	}
+	gameID, err := strconv.Atoi(idStr)
+	if err != nil {
+		return 0, errUnknownGame
+	}
+	if err := checkGameAccess(gameID, email); err != nil {
+		return 0, err
+	}
+	return gameID, nil
+}
+
+// sendGameAccessError rejects requests for a private game the player hasn't
+// joined. Other errors are left to the handler's usual "no game" handling.
+func sendGameAccessError(w http.ResponseWriter, err error) bool {
+	if err == errNotMember {
+		sendError(w, "You haven't joined this game", http.StatusForbidden)
+		return true
+	}
+	return false
+}
+
+// handleJoinByCode joins the private game with the given code.
+// Body: { code }
+func handleJoinByCode(w http.ResponseWriter, r *http.Request) {
+	user, ok := authlib.GetUserFromContext(r.Context())
+	if !ok {
+		sendError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req struct {
+		Code string `json:"code"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	code := normalizeJoinCode(req.Code)
+	if code == "" {
+		sendError(w, "Join codes are 6 letters and numbers", http.StatusBadRequest)
+		return
+	}
+
+	var gameID int
+	var name, status string
+	err := appDB.QueryRow(`
+		SELECT id, name, status FROM games WHERE join_code = $1 AND is_private = TRUE AND deleted_at IS NULL
+	`, code).Scan(&gameID, &name, &status)
+	if err == sql.ErrNoRows {
+		sendError(w, "No game found with that code", http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Printf("Error looking up join code: %v", err)
+		sendError(w, "Failed to join game", http.StatusInternalServerError)
+		return
+	}
+	if status != "active" {
+		sendError(w, "That game has finished", http.StatusBadRequest)
+		return
+	}
+
+	_, err = appDB.Exec(`
+		INSERT INTO game_players (user_id, game_id)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id, game_id) DO NOTHING
+	`, user.Email, gameID)
+	if err != nil {
+		log.Printf("Error joining game by code: %v", err)
+		sendError(w, "Failed to join game", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("🔑 %s joined private game %d (%s)", user.Email, gameID, name)
+	sendJSON(w, map[string]interface{}{
+		"success": true,
+		"game":    map[string]interface{}{"id": gameID, "name": name, "status": status, "isPrivate": true},
+	})
+}
+
+// handleGetMyGames lists the current game plus any private games the player
+// has joined, for switching between them.
+func handleGetMyGames(w http.ResponseWriter, r *http.Request) {
+	user, ok := authlib.GetUserFromContext(r.Context())
+	if !ok {
+		sendError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	currentID, _ := getCurrentGameID()
+	rows, err := appDB.Query(`
+		SELECT g.id, g.name, g.status, g.winner_count, g.is_private,
+		       gp.user_id IS NOT NULL, COALESCE(gp.is_active, FALSE)
+		FROM games g
+		LEFT JOIN game_players gp ON gp.game_id = g.id AND gp.user_id = $1
+		WHERE g.deleted_at IS NULL AND (g.id = $2 OR (g.is_private AND gp.user_id IS NOT NULL))
+		ORDER BY g.id = $2 DESC, g.created_at DESC
+	`, user.Email, currentID)
+	if err != nil {
+		log.Printf("Error getting player's games: %v", err)
+		sendError(w, "Failed to get games", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	var games []map[string]interface{}
+	for rows.Next() {
+		var id, winnerCount int
+		var name, status string
+		var isPrivate, inGame, isActive bool
+		if err := rows.Scan(&id, &name, &status, &winnerCount, &isPrivate, &inGame, &isActive); err != nil {
+			continue
+		}
+		games = append(games, map[string]interface{}{
+			"id":          id,
+			"name":        name,
+			"status":      status,
+			"winnerCount": winnerCount,
+			"isPrivate":   isPrivate,
+			"isCurrent":   id == currentID,
+			"inGame":      inGame,
+			"isActive":    isActive,
+		})
+	}
+	if games == nil {
+		games = []map[string]interface{}{}
+	}
+	sendJSON(w, map[string]interface{}{"games": games})
+}
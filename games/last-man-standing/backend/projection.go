@@ -0,0 +1,244 @@
+package main
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/lib/pq"
+)
+
+// projectionPlayer is a surviving player and the teams they can still pick.
+type projectionPlayer struct {
+	Name             string   `json:"name"` // public name — this goes on the TV
+	RemainingTeams   []string `json:"remainingTeams"`
+	RemainingCount   int      `json:"remainingCount"`
+	OptionsThisRound int      `json:"optionsThisRound"` // remaining teams playing in the open round
+	HasPicked        bool     `json:"hasPicked"`
+}
+
+type teamPickCount struct {
+	Team  string `json:"team"`
+	Count int    `json:"count"`
+}
+
+// handleGetStandingsProjection powers the banter screen on the TV: for each
+// surviving player, the teams they have left, plus how the open round's picks
+// split once the pick deadline has passed (never before, so nobody can copy).
+// Public, so the TV needs no login; private games are not shown.
+func handleGetStandingsProjection(identityDB *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var gameID int
+		var err error
+		if idStr := r.URL.Query().Get("gameId"); idStr != "" {
+			gameID, err = strconv.Atoi(idStr)
+		} else {
+			gameID, err = getCurrentGameID()
+		}
+		if err != nil {
+			sendJSON(w, map[string]interface{}{"game": nil})
+			return
+		}
+
+		var gameName, gameStatus string
+		var isPrivate bool
+		var fixtureFileID sql.NullInt64
+		err = appDB.QueryRow(`
+			SELECT name, status, is_private, fixture_file_id FROM games WHERE id = $1
+		`, gameID).Scan(&gameName, &gameStatus, &isPrivate, &fixtureFileID)
+		if err == sql.ErrNoRows {
+			sendError(w, "Game not found", http.StatusNotFound)
+			return
+		} else if err != nil {
+			log.Printf("Error loading game for projection: %v", err)
+			sendError(w, "Failed to load projection", http.StatusInternalServerError)
+			return
+		}
+		if isPrivate {
+			sendError(w, "Private games aren't shown publicly", http.StatusForbidden)
+			return
+		}
+
+		// Every team in the game's fixture file
+		teamRows, err := appDB.Query(`
+			SELECT home_team FROM matches WHERE fixture_file_id = $1
+			UNION
+			SELECT away_team FROM matches WHERE fixture_file_id = $1
+			ORDER BY 1
+		`, fixtureFileID)
+		if err != nil {
+			log.Printf("Error loading teams for projection: %v", err)
+			sendError(w, "Failed to load projection", http.StatusInternalServerError)
+			return
+		}
+		var allTeams []string
+		for teamRows.Next() {
+			var team string
+			if err := teamRows.Scan(&team); err == nil {
+				allTeams = append(allTeams, team)
+			}
+		}
+		teamRows.Close()
+
+		// Earliest open round, and the teams playing in it
+		var round map[string]interface{}
+		var roundID int
+		var startDate, endDate time.Time
+		var deadline sql.NullTime
+		var label int
+		roundTeams := map[string]bool{}
+		err = appDB.QueryRow(`
+			SELECT id, label, start_date, end_date, submission_deadline
+			FROM rounds WHERE game_id = $1 AND status = 'open'
+			ORDER BY label LIMIT 1
+		`, gameID).Scan(&roundID, &label, &startDate, &endDate, &deadline)
+		if err == nil {
+			rows, err := appDB.Query(`
+				SELECT home_team, away_team FROM matches
+				WHERE fixture_file_id = $1 AND match_date BETWEEN $2 AND $3
+			`, fixtureFileID, startDate, endDate)
+			if err == nil {
+				for rows.Next() {
+					var home, away string
+					if err := rows.Scan(&home, &away); err == nil {
+						roundTeams[home] = true
+						roundTeams[away] = true
+					}
+				}
+				rows.Close()
+			}
+		}
+
+		// Surviving players and the teams they've used (voided picks free the team)
+		rows, err := appDB.Query(`
+			SELECT gp.user_id, COALESCE(array_agg(p.predicted_team) FILTER (WHERE p.id IS NOT NULL), '{}'),
+			       COALESCE(bool_or(p.round_id = $2), FALSE)
+			FROM game_players gp
+			LEFT JOIN predictions p ON p.user_id = gp.user_id AND p.game_id = gp.game_id AND p.voided = FALSE
+			WHERE gp.game_id = $1 AND gp.is_active = TRUE
+			GROUP BY gp.user_id
+		`, gameID, roundID)
+		if err != nil {
+			log.Printf("Error loading players for projection: %v", err)
+			sendError(w, "Failed to load projection", http.StatusInternalServerError)
+			return
+		}
+		defer rows.Close()
+
+		type survivor struct {
+			email     string
+			used      map[string]bool
+			hasPicked bool
+		}
+		var survivors []survivor
+		var emails []string
+		for rows.Next() {
+			var email string
+			var used []string
+			var hasPicked bool
+			if err := rows.Scan(&email, pq.Array(&used), &hasPicked); err != nil {
+				continue
+			}
+			s := survivor{email: email, used: map[string]bool{}, hasPicked: hasPicked}
+			for _, team := range used {
+				s.used[team] = true
+			}
+			survivors = append(survivors, s)
+			emails = append(emails, email)
+		}
+
+		profiles, err := authlib.LoadProfiles(identityDB, emails)
+		if err != nil {
+			log.Printf("Error loading profiles for projection: %v", err)
+		}
+
+		players := []projectionPlayer{}
+		for _, s := range survivors {
+			p := projectionPlayer{Name: authlib.AnonymousAlias(s.email), RemainingTeams: []string{}, HasPicked: s.hasPicked}
+			if profile, ok := profiles[s.email]; ok {
+				p.Name = profile.PublicName()
+			}
+			for _, team := range allTeams {
+				if s.used[team] {
+					continue
+				}
+				p.RemainingTeams = append(p.RemainingTeams, team)
+				if roundTeams[team] {
+					p.OptionsThisRound++
+				}
+			}
+			p.RemainingCount = len(p.RemainingTeams)
+			players = append(players, p)
+		}
+		// Fewest options first: they're the ones in trouble
+		sort.Slice(players, func(i, j int) bool {
+			if players[i].RemainingCount != players[j].RemainingCount {
+				return players[i].RemainingCount < players[j].RemainingCount
+			}
+			return players[i].Name < players[j].Name
+		})
+
+		if roundID != 0 {
+			pickedCount := 0
+			for _, p := range players {
+				if p.HasPicked {
+					pickedCount++
+				}
+			}
+			deadlinePassed := deadline.Valid && time.Now().After(deadline.Time)
+			var deadlineStr interface{}
+			if deadline.Valid {
+				deadlineStr = deadline.Time.Format(time.RFC3339)
+			}
+			round = map[string]interface{}{
+				"id":                 roundID,
+				"label":              label,
+				"submissionDeadline": deadlineStr,
+				"deadlinePassed":     deadlinePassed,
+				"pickedCount":        pickedCount,
+				"picks":              nil, // hidden until the deadline
+			}
+			if deadlinePassed {
+				round["picks"] = openRoundPickCounts(roundID)
+			}
+		}
+
+		sendJSON(w, map[string]interface{}{
+			"game": map[string]interface{}{
+				"id":     gameID,
+				"name":   gameName,
+				"status": gameStatus,
+			},
+			"survivors": len(players),
+			"players":   players,
+			"openRound": round,
+		})
+	}
+}
+
+// openRoundPickCounts returns how many players picked each team in a round, most popular first.
+func openRoundPickCounts(roundID int) []teamPickCount {
+	counts := []teamPickCount{}
+	rows, err := appDB.Query(`
+		SELECT predicted_team, COUNT(*) FROM predictions
+		WHERE round_id = $1 AND voided = FALSE
+		GROUP BY predicted_team
+		ORDER BY COUNT(*) DESC, predicted_team
+	`, roundID)
+	if err != nil {
+		log.Printf("Error counting round picks: %v", err)
+		return counts
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var c teamPickCount
+		if err := rows.Scan(&c.Team, &c.Count); err == nil {
+			counts = append(counts, c)
+		}
+	}
+	return counts
+}
@@ -0,0 +1,19 @@
+-- Migration: Fixture file versions
+-- Date: 2026-10-17
+-- Purpose: Game Admin keeps every fixture CSV upload as a version and diffs it
+-- against the current matches; changes to matches in open rounds wait for
+-- admin confirmation instead of being upserted silently
+
+CREATE TABLE IF NOT EXISTS fixture_versions (
+    id              SERIAL PRIMARY KEY,
+    fixture_file_id INTEGER NOT NULL REFERENCES fixture_files(id) ON DELETE CASCADE,
+    version         INTEGER NOT NULL,
+    matches         JSONB NOT NULL,
+    skipped         INTEGER DEFAULT 0,
+    status          TEXT DEFAULT 'pending',
+    uploaded_by     TEXT,
+    uploaded_at     TIMESTAMP DEFAULT NOW(),
+    applied_by      TEXT,
+    applied_at      TIMESTAMP,
+    UNIQUE(fixture_file_id, version)
+);
@@ -0,0 +1,8 @@
+-- Migration: Stable GUIDs for admin-managed resources
+-- Date: 2026-10-17
+-- Purpose: Serial IDs differ between environments; GUIDs let exports and
+-- re-imports find the same fixture file, game or round again
+
+ALTER TABLE fixture_files ADD COLUMN IF NOT EXISTS guid UUID NOT NULL DEFAULT gen_random_uuid() UNIQUE;
+ALTER TABLE games ADD COLUMN IF NOT EXISTS guid UUID NOT NULL DEFAULT gen_random_uuid() UNIQUE;
+ALTER TABLE rounds ADD COLUMN IF NOT EXISTS guid UUID NOT NULL DEFAULT gen_random_uuid() UNIQUE;
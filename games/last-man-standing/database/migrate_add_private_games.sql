@@ -0,0 +1,6 @@
+-- Migration: Add private join-by-code games
+-- Date: 2026-10-17
+-- Purpose: Private games (e.g. a family group) run alongside the pub's current game
+
+ALTER TABLE games ADD COLUMN IF NOT EXISTS is_private BOOLEAN DEFAULT FALSE;
+ALTER TABLE games ADD COLUMN IF NOT EXISTS join_code TEXT UNIQUE;
@@ -0,0 +1,7 @@
+-- Migration: Trash for deleted games
+-- Date: 2026-10-17
+-- Purpose: Deleting a game in Game Admin now sets deleted_at so it can be
+-- restored; it is purged after TRASH_RETENTION_DAYS (default 30)
+
+ALTER TABLE games ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE games ADD COLUMN IF NOT EXISTS deleted_by TEXT;
@@ -7,6 +7,7 @@ DROP TABLE IF EXISTS game_players CASCADE;
 DROP TABLE IF EXISTS rounds CASCADE;
 DROP TABLE IF EXISTS games CASCADE;
 DROP TABLE IF EXISTS matches CASCADE;
+DROP TABLE IF EXISTS fixture_versions CASCADE;
 DROP TABLE IF EXISTS fixture_files CASCADE;
 DROP TABLE IF EXISTS settings CASCADE;
 
@@ -14,14 +15,32 @@ DROP TABLE IF EXISTS settings CASCADE;
 -- Multiple games can reference the same fixture file (e.g. "Andy's Friends" + "Julie's Friends").
 CREATE TABLE fixture_files (
     id         SERIAL PRIMARY KEY,
+    guid       UUID NOT NULL DEFAULT gen_random_uuid() UNIQUE, -- stable across environments
     name       TEXT NOT NULL UNIQUE,
     created_at TIMESTAMP DEFAULT NOW(),
     updated_at TIMESTAMP DEFAULT NOW()
 );
 
+-- Every CSV upload of a fixture file, newest version last. Game Admin applies a
+-- version straight away unless it changes matches in an open round; then it stays
+-- 'pending' until an admin confirms it, and a newer upload marks it 'superseded'.
+CREATE TABLE fixture_versions (
+    id              SERIAL PRIMARY KEY,
+    fixture_file_id INTEGER NOT NULL REFERENCES fixture_files(id) ON DELETE CASCADE,
+    version         INTEGER NOT NULL,
+    matches         JSONB NOT NULL,              -- parsed CSV rows
+    skipped         INTEGER DEFAULT 0,           -- rows with an unreadable date
+    status          TEXT DEFAULT 'pending',      -- 'pending', 'applied', 'superseded'
+    uploaded_by     TEXT,
+    uploaded_at     TIMESTAMP DEFAULT NOW(),
+    applied_by      TEXT,
+    applied_at      TIMESTAMP,
+    UNIQUE(fixture_file_id, version)
+);
+
 -- Matches belong to a fixture file, not to a game.
 -- Results are facts about the match and are shared across all games using this file.
--- Re-uploading the CSV updates matches via ON CONFLICT (fixture_file_id, match_number).
+-- Applying a fixture version updates matches via ON CONFLICT (fixture_file_id, match_number).
 -- match_date is a proper DATE for date-range round queries.
 -- round_number from CSV is stored as metadata only — not used for round grouping.
 -- CSV date column must be in YYYY-MM-DD format (or DD/MM/YYYY — backend tries both).
@@ -42,15 +61,22 @@ CREATE TABLE matches (
 
 -- Games reference a fixture file. Each game is an independent competition.
 -- A player can be in multiple games simultaneously; elimination is scoped per game.
+-- Private games (e.g. a family WhatsApp group) run alongside the pub's current game:
+-- they are never the current game and players join them with join_code.
 CREATE TABLE games (
     id                SERIAL PRIMARY KEY,
+    guid              UUID NOT NULL DEFAULT gen_random_uuid() UNIQUE,
     name              TEXT NOT NULL,
     fixture_file_id   INTEGER REFERENCES fixture_files(id),
     status            TEXT DEFAULT 'active',       -- 'active', 'completed'
     winner_count      INTEGER DEFAULT 0,
+    is_private        BOOLEAN DEFAULT FALSE,
+    join_code         TEXT UNIQUE,                 -- private games only, e.g. 'K7M2QX'
     start_date        TIMESTAMP DEFAULT NOW(),
     end_date          TIMESTAMP,
-    created_at        TIMESTAMP DEFAULT NOW()
+    created_at        TIMESTAMP DEFAULT NOW(),
+    deleted_at        TIMESTAMP,                   -- in Game Admin's trash until restored or purged
+    deleted_by        TEXT
 );
 
 -- Players in a game. A player can be in multiple games simultaneously.
@@ -70,6 +96,7 @@ CREATE TABLE game_players (
 -- auto-pick (first alphabetically available team) for players who haven't picked.
 CREATE TABLE rounds (
     id                  SERIAL PRIMARY KEY,
+    guid                UUID NOT NULL DEFAULT gen_random_uuid() UNIQUE,
     game_id             INTEGER NOT NULL REFERENCES games(id),
     label               INTEGER NOT NULL,               -- display number (Round 1, Round 2, ...)
     start_date          DATE NOT NULL,
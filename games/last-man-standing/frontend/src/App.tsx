@@ -15,6 +15,7 @@ interface Game {
   name: string;
   status: string;
   winnerCount: number;
+  isPrivate?: boolean;  // joined by code, runs alongside the pub's current game
 }
 
 interface Round {
@@ -63,6 +64,27 @@ interface Player {
   joinedAt: string;
 }
 
+// GET /api/standings/projection — the TV banter screen
+interface Projection {
+  game: { id: number; name: string; status: string } | null;
+  survivors: number;
+  players: {
+    name: string;
+    remainingTeams: string[];
+    remainingCount: number;
+    optionsThisRound: number;
+    hasPicked: boolean;
+  }[];
+  openRound: {
+    id: number;
+    label: number;
+    submissionDeadline: string | null;
+    deadlinePassed: boolean;
+    pickedCount: number;
+    picks: { team: string; count: number }[] | null;  // null until the deadline passes
+  } | null;
+}
+
 // --- Hooks ---
 
 function useUrlParams() {
@@ -71,7 +93,8 @@ function useUrlParams() {
     return {
       userId: params.get('userId') || '',
       userName: params.get('userName') || params.get('userId') || 'Player',
-      token: params.get('token') || '',
+      token: params.get('token') || sessionStorage.getItem('token') || '',
+      view: params.get('view') || '',
     };
   }, []);
 }
@@ -98,11 +121,13 @@ function useApi(token: string) {
 // --- Main App ---
 
 function App() {
-  const { userId, token } = useUrlParams();
+  const { userId, token, view } = useUrlParams();
   const api = useApi(token);
 
   const [config, setConfig] = useState<Config | null>(null);
   const [game, setGame] = useState<Game | null | undefined>(undefined); // undefined = not loaded
+  const [myGames, setMyGames] = useState<Game[]>([]);
+  const [joinCode, setJoinCode] = useState('');
   const [myStatus, setMyStatus] = useState<{ inGame: boolean; isActive: boolean; gameID: number | null } | null>(null);
   const [openRounds, setOpenRounds] = useState<Round[]>([]);
   const [predictions, setPredictions] = useState<Prediction[]>([]);
@@ -122,6 +147,9 @@ function App() {
     setTimeout(() => setSuccessMsg(null), 3000);
   }, []);
 
+  // Player endpoints are scoped to the selected game (current or private)
+  const gameQuery = game ? `?gameId=${game.id}` : '';
+
   // Load initial data
   useEffect(() => {
     if (!token || !userId) {
@@ -130,16 +158,15 @@ function App() {
     }
     (async () => {
       try {
-        const [configData, gameData] = await Promise.all([
+        const [configData, gameData, mineData] = await Promise.all([
           api('/api/config'),
           api('/api/games/current'),
+          api('/api/games/mine'),
         ]);
         setConfig(configData);
-        setGame(gameData.game);
-        if (gameData.game) {
-          const statusData = await api('/api/games/status');
-          setMyStatus(statusData);
-        }
+        setMyGames(mineData.games || []);
+        // Default to the pub's game; fall back to a private one
+        setGame(gameData.game || (mineData.games || [])[0] || null);
       } catch (err) {
         setError(err instanceof Error ? err.message : 'Failed to load');
       } finally {
@@ -148,6 +175,14 @@ function App() {
     })();
   }, [token, userId, api]);
 
+  // Load the player's status in the selected game
+  useEffect(() => {
+    if (!game) return;
+    api(`/api/games/status${gameQuery}`)
+      .then(setMyStatus)
+      .catch(err => setError(err instanceof Error ? err.message : 'Failed to load'));
+  }, [game, gameQuery, api]);
+
   // Load view-specific data
   useEffect(() => {
     if (!token || !myStatus?.inGame) return;
@@ -155,23 +190,33 @@ function App() {
       try {
         if (currentView === 'predict') {
           const [roundsData, teamsData] = await Promise.all([
-            api('/api/rounds/open'),
-            api('/api/predictions/used-teams'),
+            api(`/api/rounds/open${gameQuery}`),
+            api(`/api/predictions/used-teams${gameQuery}`),
           ]);
           setOpenRounds(roundsData.rounds || []);
           setUsedTeams(teamsData.teams || []);
         } else if (currentView === 'history') {
-          const data = await api('/api/predictions');
+          const data = await api(`/api/predictions${gameQuery}`);
           setPredictions(data.predictions || []);
         } else if (currentView === 'standings') {
-          const data = await api('/api/standings');
+          const data = await api(`/api/standings${gameQuery}`);
           setStandings(data.players || []);
         }
       } catch {
         // silently ignore view-data errors
       }
     })();
-  }, [currentView, token, myStatus?.inGame, api]);
+  }, [currentView, token, myStatus?.inGame, gameQuery, api]);
+
+  const switchGame = (id: number) => {
+    const next = myGames.find(g => g.id === id);
+    if (!next) return;
+    setMyStatus(null);
+    setSelectedRound(null);
+    setRoundMatches(null);
+    setCurrentView('predict');
+    setGame(next);
+  };
 
   // Load matches for selected round
   useEffect(() => {
@@ -185,8 +230,12 @@ function App() {
     setSubmitting(true);
     try {
       await api('/api/games/join', { method: 'POST' });
-      const statusData = await api('/api/games/status');
+      const [statusData, mineData] = await Promise.all([
+        api(`/api/games/status${gameQuery}`),
+        api('/api/games/mine'),
+      ]);
       setMyStatus(statusData);
+      setMyGames(mineData.games || []);
       showSuccess('You joined the game!');
     } catch (err) {
       setError(err instanceof Error ? err.message : 'Failed to join');
@@ -195,18 +244,42 @@ function App() {
     }
   };
 
+  const handleJoinByCode = async () => {
+    if (!joinCode.trim()) return;
+    setSubmitting(true);
+    try {
+      const data = await api('/api/games/join-code', {
+        method: 'POST',
+        body: JSON.stringify({ code: joinCode }),
+      });
+      const mineData = await api('/api/games/mine');
+      setMyGames(mineData.games || []);
+      setJoinCode('');
+      setMyStatus(null);
+      setSelectedRound(null);
+      setRoundMatches(null);
+      setCurrentView('predict');
+      setGame(data.game);
+      showSuccess(`You joined ${data.game.name}!`);
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to join');
+    } finally {
+      setSubmitting(false);
+    }
+  };
+
   const handlePredict = async (matchId: number, team: string, roundId: number) => {
     setSubmitting(true);
     try {
-      await api('/api/predictions', {
+      await api(`/api/predictions${gameQuery}`, {
         method: 'POST',
         body: JSON.stringify({ matchId, roundId, team }),
       });
       showSuccess(`Pick submitted: ${team}`);
       // Refresh predict view data
       const [roundsData, teamsData, matchData] = await Promise.all([
-        api('/api/rounds/open'),
-        api('/api/predictions/used-teams'),
+        api(`/api/rounds/open${gameQuery}`),
+        api(`/api/predictions/used-teams${gameQuery}`),
         game && selectedRound ? api(`/api/matches/${game.id}/round/${selectedRound.id}`) : Promise.resolve(null),
       ]);
       setOpenRounds(roundsData.rounds || []);
@@ -223,6 +296,11 @@ function App() {
 
   // --- Render ---
 
+  // Banter screen for the pub TV (display-admin "url" content): no login
+  if (view === 'tv') {
+    return <BanterScreen />;
+  }
+
   if (!userId || !token) {
     return (
       <div className="ah-container ah-container--narrow">
@@ -269,6 +347,21 @@ function App() {
         )}
         <Toast message={successMsg} />
 
+      {/* Game switcher: the pub's game plus any private games joined */}
+      {myGames.length > 1 && game && (
+        <div style={{ marginBottom: 12 }}>
+          <select
+            className="ah-select"
+            value={game.id}
+            onChange={e => switchGame(parseInt(e.target.value))}
+          >
+            {myGames.map(g => (
+              <option key={g.id} value={g.id}>{g.name}{g.isPrivate ? ' (private)' : ''}</option>
+            ))}
+          </select>
+        </div>
+      )}
+
       {/* No Game */}
       {game === null && (
         <div className="ah-card">
@@ -427,6 +520,27 @@ function App() {
           )}
         </>
       )}
+
+      {/* Join a private game (e.g. a family group's) */}
+      {selectedRound === null && (
+        <div className="ah-card">
+          <h3 className="ah-section-title" style={{ marginTop: 0 }}>Join a Private Game</h3>
+          <p className="ah-meta">Got a code from your group's organiser? Enter it here.</p>
+          <div style={{ display: 'flex', gap: 8, marginTop: 8 }}>
+            <input
+              className="ah-input"
+              style={{ flex: 1, textTransform: 'uppercase', letterSpacing: 2 }}
+              placeholder="e.g. K7M2QX"
+              maxLength={7}
+              value={joinCode}
+              onChange={e => setJoinCode(e.target.value)}
+            />
+            <button className="ah-btn-primary" onClick={handleJoinByCode} disabled={submitting || !joinCode.trim()}>
+              Join
+            </button>
+          </div>
+        </div>
+      )}
       </div>
     </>
   );
@@ -459,6 +573,73 @@ function Toast({ message }: { message: string | null }) {
 
 // --- Sub-components ---
 
+// BanterScreen: full-screen standings projection for the pub TV. Refreshes every minute.
+function BanterScreen() {
+  const [projection, setProjection] = useState<Projection | null>(null);
+
+  useEffect(() => {
+    const load = () => {
+      fetch('/api/standings/projection')
+        .then(res => res.ok ? res.json() : null)
+        .then(data => { if (data) setProjection(data); })
+        .catch(() => {});
+    };
+    load();
+    const interval = setInterval(load, 60000);
+    return () => clearInterval(interval);
+  }, []);
+
+  if (!projection?.game) {
+    return <div style={s.tvScreen}><h1 style={s.tvTitle}>🏆 Last Man Standing</h1></div>;
+  }
+
+  const round = projection.openRound;
+  return (
+    <div style={s.tvScreen}>
+      <h1 style={s.tvTitle}>🏆 {projection.game.name}</h1>
+      <p style={s.tvSubtitle}>
+        {projection.survivors} still standing
+        {round && ` · Round ${round.label}: ${round.pickedCount} picked`}
+      </p>
+
+      <div style={{ display: 'flex', gap: 40, marginTop: 32 }}>
+        <div style={{ flex: 2 }}>
+          <h2 style={s.tvHeading}>Teams left</h2>
+          {projection.players.map((p, idx) => (
+            <div key={idx} style={s.tvRow}>
+              <span style={{ fontWeight: 600 }}>{p.name}</span>
+              <span style={{ color: p.remainingCount <= 3 ? '#FF5252' : '#B0BEC5' }}>
+                {p.remainingCount} left
+                {round && ` · ${p.optionsThisRound} this round`}
+              </span>
+            </div>
+          ))}
+        </div>
+
+        {round && (
+          <div style={{ flex: 1 }}>
+            <h2 style={s.tvHeading}>Round {round.label} picks</h2>
+            {round.picks ? (
+              round.picks.map(pick => (
+                <div key={pick.team} style={s.tvRow}>
+                  <span>{pick.team}</span>
+                  <span style={{ fontWeight: 700 }}>{pick.count}</span>
+                </div>
+              ))
+            ) : (
+              <p style={{ color: '#B0BEC5', fontSize: 24 }}>
+                {round.submissionDeadline
+                  ? `Revealed after ${new Date(round.submissionDeadline).toLocaleString()}`
+                  : 'Revealed after the pick deadline'}
+              </p>
+            )}
+          </div>
+        )}
+      </div>
+    </div>
+  );
+}
+
 // PickView: team-centric pick UI. Shows each team once (deduplicated across all matches in the
 // round). When a team plays multiple games in the round, we always use the first match for that
 // team (lowest match_number) when recording the prediction — the player picks a team, not a game.
@@ -592,6 +773,23 @@ function PredStatus({ isCorrect, voided, bye }: { isCorrect: boolean | null; voi
 
 const s: Record<string, React.CSSProperties> = {
   title: { fontSize: 24, fontWeight: 700, margin: 0 },
+  tvScreen: {
+    minHeight: '100vh',
+    backgroundColor: '#102027',
+    color: 'white',
+    padding: '48px 64px',
+    boxSizing: 'border-box',
+  },
+  tvTitle: { fontSize: 56, fontWeight: 700, margin: 0 },
+  tvSubtitle: { fontSize: 28, color: '#B0BEC5', margin: '8px 0 0' },
+  tvHeading: { fontSize: 32, fontWeight: 600, margin: '0 0 16px' },
+  tvRow: {
+    display: 'flex',
+    justifyContent: 'space-between',
+    fontSize: 26,
+    padding: '10px 0',
+    borderBottom: '1px solid #37474F',
+  },
   gameHeader: {
     display: 'flex',
     alignItems: 'center',
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/achgithub/activity-hub-common/bus"
+	redislib "github.com/achgithub/activity-hub-common/redis"
+)
+
+// Games publish finished games to the results stream on the event bus, and
+// the leaderboard records them from there. Its consumer group remembers the
+// last result handled, so results sent while the leaderboard is restarting
+// are recorded when it comes back. POST /api/result stays for games that
+// report over HTTP.
+
+// runResultConsumer records results from the event bus for the life of the process
+func runResultConsumer() {
+	client, err := redislib.InitRedis()
+	if err != nil {
+		log.Printf("Warning: event bus unavailable, results are only taken over HTTP: %v", err)
+		return
+	}
+	bus.NewConsumer(client, "leaderboard", handleResultEvent, bus.StreamResults).Run(context.Background())
+}
+
+// handleResultEvent records one ResultReported event. Reports that can never
+// be recorded are logged and acknowledged; database errors are retried.
+func handleResultEvent(ctx context.Context, msg bus.Message) error {
+	result, err := bus.ResultReported.Payload(msg.Event)
+	if err != nil {
+		log.Printf("Ignoring bus event %s: %v", msg.ID, err)
+		return nil
+	}
+
+	req := ResultReport{
+		GameType: result.GameType, GameID: result.GameID,
+		WinnerID: result.WinnerID, WinnerName: result.WinnerName,
+		LoserID: result.LoserID, LoserName: result.LoserName,
+		IsDraw: result.IsDraw, Score: result.Score, Duration: result.Duration,
+	}
+	if err := req.validate(); err != nil {
+		log.Printf("Ignoring result %s from the bus: %v", result.GameID, err)
+		return nil
+	}
+	return recordResult(req)
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/achgithub/activity-hub-common/cache"
+	"github.com/achgithub/activity-hub-common/cli"
+	"github.com/go-redis/redis/v8"
+)
+
+// Standings and recent results are read by every screen in the pub and only
+// change when a result comes in, so they're served from Redis and dropped
+// when a new result is recorded. The TTL bounds staleness if Redis missed a delete.
+const cacheTTL = time.Minute
+
+var readCache *cache.Cache
+
+// redisStore adapts the go-redis client to cache.Store
+type redisStore struct {
+	client *redis.Client
+}
+
+func (s redisStore) Get(ctx context.Context, key string) (string, error) {
+	v, err := s.client.Get(ctx, key).Result()
+	if err == redis.Nil {
+		return "", cache.ErrMiss
+	}
+	return v, err
+}
+
+func (s redisStore) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	return s.client.Set(ctx, key, value, ttl).Err()
+}
+
+func (s redisStore) Del(ctx context.Context, keys ...string) error {
+	return s.client.Del(ctx, keys...).Err()
+}
+
+func initCache() {
+	readCache = cache.New(redisStore{redisClient}, "cache:leaderboard:", cacheTTL)
+}
+
+// invalidateResults drops the cached reads a new result for gameType changes
+func invalidateResults(gameType string) {
+	err := readCache.Invalidate(context.Background(),
+		"standings:"+gameType, "standings:all", "recent:"+gameType, "recent:all")
+	if err != nil {
+		log.Printf("Failed to invalidate cached standings for %s: %v", gameType, err)
+	}
+}
+
+// reindexStats is the reindex-stats command: it drops every cached standings
+// and recent-games read and loads the standings again from game_results, for
+// after results have been corrected in the database by hand
+func reindexStats(args []string) error {
+	if len(args) > 0 {
+		return cli.Usagef("reindex-stats takes no arguments")
+	}
+
+	var err error
+	db, err = openAppDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	initRedis()
+	initCache()
+
+	gameTypes, err := loadGameTypes()
+	if err != nil {
+		return fmt.Errorf("failed to list game types: %w", err)
+	}
+	keys := []string{"standings:all", "recent:all"}
+	for _, gameType := range gameTypes {
+		keys = append(keys, "standings:"+gameType, "recent:"+gameType)
+	}
+	ctx := context.Background()
+	if err := readCache.Invalidate(ctx, keys...); err != nil {
+		return fmt.Errorf("failed to drop cached standings: %w", err)
+	}
+
+	for _, gameType := range gameTypes {
+		standings, err := cache.Fetch(ctx, readCache, "standings:"+gameType, func() ([]Standing, error) {
+			return loadStandings(gameType)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to rebuild standings for %s: %w", gameType, err)
+		}
+		fmt.Printf("%s: %d players\n", gameType, len(standings))
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/achgithub/activity-hub-common/events"
+)
+
+// The results stream (GET /api/recent/stream) sends each result as it's
+// recorded, so scoreboards on pub screens update when someone wins instead of
+// polling /api/recent. Envelopes carry the game type as their session.
+
+const resultsChannel = "leaderboard:results"
+
+type StreamOpened struct {
+	GameType string `json:"gameType"` // "all" for every game
+}
+
+var (
+	evConnected      = events.Register[StreamOpened]("connected", 1, "Stream opened")
+	evResultRecorded = events.Register[GameResult]("result_recorded", 1, "A game result was recorded, as listed by /api/recent")
+)
+
+// publishResultRecorded sends a newly recorded result to the results stream.
+// Failures are logged only.
+func publishResultRecorded(res GameResult) {
+	msg := evResultRecorded.New(res.GameType, res).Encode()
+	if err := redisClient.Publish(context.Background(), resultsChannel, msg).Err(); err != nil {
+		log.Printf("Failed to publish result for game %s: %v", res.GameID, err)
+	}
+}
@@ -1,74 +1,98 @@
 package main
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	"time"
 
+	"github.com/achgithub/activity-hub-common/cache"
+	"github.com/achgithub/activity-hub-common/events"
+	apphttp "github.com/achgithub/activity-hub-common/http"
 	"github.com/gorilla/mux"
 )
 
 // HandleConfig returns app configuration
 func HandleConfig(w http.ResponseWriter, r *http.Request) {
-	config := Config{
-		AppName: "Leaderboard",
-		AppIcon: "🏆",
-		Version: "1.0.0",
-	}
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(config)
+	apphttp.WriteConfig(w, apphttp.AppConfig{
+		AppID:        "leaderboard",
+		Name:         APP_NAME,
+		Icon:         "🏆",
+		Description:  "View standings and recent games across all Activity Hub games",
+		Capabilities: apphttp.Capabilities{SSE: true, Displays: true},
+	})
 }
 
 // HandleReportResult - POST /api/result
 // Called by games when a game ends (authentication required)
 func HandleReportResult(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		GameType   string `json:"gameType"`
-		GameID     string `json:"gameId"`
-		WinnerID   string `json:"winnerId"`
-		WinnerName string `json:"winnerName"`
-		LoserID    string `json:"loserId"`
-		LoserName  string `json:"loserName"`
-		IsDraw     bool   `json:"isDraw"`
-		Score      string `json:"score"`
-		Duration   int    `json:"duration"`
-	}
+	var req ResultReport
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
-	// Validate required fields
-	if req.GameType == "" || req.GameID == "" {
-		http.Error(w, "gameType and gameId are required", http.StatusBadRequest)
+	if err := req.validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	// For non-draw games, winner is required
-	if !req.IsDraw && req.WinnerID == "" {
-		http.Error(w, "winnerId required for non-draw games", http.StatusBadRequest)
+	if err := recordResult(req); err != nil {
+		log.Printf("Failed to insert game result: %v", err)
+		http.Error(w, "Failed to save result", http.StatusInternalServerError)
 		return
 	}
 
-	// Insert result
-	_, err := db.Exec(`
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]bool{"success": true})
+}
+
+// validate checks the fields every report needs
+func (r ResultReport) validate() error {
+	if r.GameType == "" || r.GameID == "" {
+		return errors.New("gameType and gameId are required")
+	}
+	// For non-draw games, winner is required
+	if !r.IsDraw && r.WinnerID == "" {
+		return errors.New("winnerId required for non-draw games")
+	}
+	return nil
+}
+
+// recordResult saves a reported result, from POST /api/result or the event
+// bus. Both players may report the same game, and the bus may deliver it
+// again; only the first insert returns a row, and only that one reaches the
+// feed and stream.
+func recordResult(req ResultReport) error {
+	res := GameResult{
+		GameType: req.GameType, GameID: req.GameID,
+		WinnerID: req.WinnerID, WinnerName: req.WinnerName,
+		LoserID: req.LoserID, LoserName: req.LoserName,
+		IsDraw: req.IsDraw, Score: req.Score, Duration: req.Duration,
+		PlayedAt: time.Now(),
+	}
+	err := db.QueryRow(`
 		INSERT INTO game_results (game_type, game_id, winner_id, winner_name, loser_id, loser_name, is_draw, score, duration, played_at)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 		ON CONFLICT (game_id) DO NOTHING
-	`, req.GameType, req.GameID, req.WinnerID, req.WinnerName, req.LoserID, req.LoserName, req.IsDraw, req.Score, req.Duration, time.Now())
-
+		RETURNING id
+	`, res.GameType, res.GameID, res.WinnerID, res.WinnerName, res.LoserID, res.LoserName, res.IsDraw, res.Score, res.Duration, res.PlayedAt).Scan(&res.ID)
+	if err == sql.ErrNoRows {
+		return nil
+	}
 	if err != nil {
-		log.Printf("Failed to insert game result: %v", err)
-		http.Error(w, "Failed to save result", http.StatusInternalServerError)
-		return
+		return err
 	}
 
 	log.Printf("📊 Recorded result: %s game %s - Winner: %s", req.GameType, req.GameID, req.WinnerName)
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]bool{"success": true})
+	invalidateResults(req.GameType)
+	go publishGameResult(res)
+	go publishResultRecorded(res)
+	return nil
 }
 
 // HandleGetStandings - GET /api/standings/{gameType}
@@ -82,8 +106,22 @@ func HandleGetStandings(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Query to calculate standings
-	// Points: 3 for win, 1 for draw, 0 for loss
+	standings, err := cache.Fetch(r.Context(), readCache, "standings:"+gameType, func() ([]Standing, error) {
+		return loadStandings(gameType)
+	})
+	if err != nil {
+		log.Printf("Failed to query standings: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(standings)
+}
+
+// loadStandings calculates the top 50 for a game type.
+// Points: 3 for win, 1 for draw, 0 for loss
+func loadStandings(gameType string) ([]Standing, error) {
 	rows, err := db.Query(`
 		WITH player_stats AS (
 			-- Get wins
@@ -134,11 +172,8 @@ func HandleGetStandings(w http.ResponseWriter, r *http.Request) {
 		ORDER BY points DESC, wins DESC, total_games DESC
 		LIMIT 50
 	`, gameType)
-
 	if err != nil {
-		log.Printf("Failed to query standings: %v", err)
-		http.Error(w, "Database error", http.StatusInternalServerError)
-		return
+		return nil, err
 	}
 	defer rows.Close()
 
@@ -160,20 +195,30 @@ func HandleGetStandings(w http.ResponseWriter, r *http.Request) {
 		standings = append(standings, s)
 		rank++
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(standings)
+	return standings, nil
 }
 
 // HandleGetAllStandings - GET /api/standings
 // Returns standings for all game types (public)
 func HandleGetAllStandings(w http.ResponseWriter, r *http.Request) {
-	// Get list of game types
-	rows, err := db.Query(`SELECT DISTINCT game_type FROM game_results ORDER BY game_type`)
+	gameTypes, err := cache.Fetch(r.Context(), readCache, "standings:all", loadGameTypes)
 	if err != nil {
 		http.Error(w, "Database error", http.StatusInternalServerError)
 		return
 	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"gameTypes": gameTypes,
+	})
+}
+
+// loadGameTypes lists the game types with recorded results
+func loadGameTypes() ([]string, error) {
+	rows, err := db.Query(`SELECT DISTINCT game_type FROM game_results ORDER BY game_type`)
+	if err != nil {
+		return nil, err
+	}
 	defer rows.Close()
 
 	gameTypes := []string{}
@@ -182,11 +227,7 @@ func HandleGetAllStandings(w http.ResponseWriter, r *http.Request) {
 		rows.Scan(&gt)
 		gameTypes = append(gameTypes, gt)
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"gameTypes": gameTypes,
-	})
+	return gameTypes, nil
 }
 
 // HandleGetRecentGames - GET /api/recent/{gameType}
@@ -194,14 +235,32 @@ func HandleGetAllStandings(w http.ResponseWriter, r *http.Request) {
 func HandleGetRecentGames(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	gameType := vars["gameType"]
+	if gameType == "" {
+		gameType = "all"
+	}
 
+	results, err := cache.Fetch(r.Context(), readCache, "recent:"+gameType, func() ([]GameResult, error) {
+		return loadRecentGames(gameType)
+	})
+	if err != nil {
+		log.Printf("Failed to query recent games: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
+}
+
+// loadRecentGames returns the last 20 results for a game type ("all" for every game)
+func loadRecentGames(gameType string) ([]GameResult, error) {
 	query := `
 		SELECT id, game_type, game_id, winner_id, winner_name, loser_id, loser_name, is_draw, score, duration, played_at
 		FROM game_results
 	`
 	args := []interface{}{}
 
-	if gameType != "" && gameType != "all" {
+	if gameType != "all" {
 		query += " WHERE game_type = $1"
 		args = append(args, gameType)
 	}
@@ -210,9 +269,7 @@ func HandleGetRecentGames(w http.ResponseWriter, r *http.Request) {
 
 	rows, err := db.Query(query, args...)
 	if err != nil {
-		log.Printf("Failed to query recent games: %v", err)
-		http.Error(w, "Database error", http.StatusInternalServerError)
-		return
+		return nil, err
 	}
 	defer rows.Close()
 
@@ -241,9 +298,52 @@ func HandleGetRecentGames(w http.ResponseWriter, r *http.Request) {
 		}
 		results = append(results, r)
 	}
+	return results, nil
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(results)
+// HandleRecentStream - GET /api/recent/stream?gameType=dots
+// SSE stream of results as they're recorded, for every game unless gameType
+// is given (public)
+func HandleRecentStream(w http.ResponseWriter, r *http.Request) {
+	gameType := r.URL.Query().Get("gameType")
+	if gameType == "" {
+		gameType = "all"
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+
+	if _, ok := w.(http.Flusher); !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	pubsub := redisClient.Subscribe(r.Context(), resultsChannel)
+	defer pubsub.Close()
+
+	events.Send(w, evConnected.New(gameType, StreamOpened{GameType: gameType}))
+
+	ch := pubsub.Channel()
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case msg := <-ch:
+			if gameType != "all" {
+				if e, err := events.Decode(msg.Payload); err != nil || e.Session != gameType {
+					continue
+				}
+			}
+			events.Forward(w, msg.Payload)
+		case <-ticker.C:
+			events.Send(w, events.Ping.New(gameType, events.NoPayload{}))
+		case <-r.Context().Done():
+			return
+		}
+	}
 }
 
 // HandleGetPlayerStats - GET /api/player/{playerId}
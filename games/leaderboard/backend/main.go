@@ -6,17 +6,41 @@ import (
 	"net/http"
 
 	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/achgithub/activity-hub-common/cli"
 	"github.com/achgithub/activity-hub-common/database"
-	"github.com/gorilla/handlers"
+	"github.com/achgithub/activity-hub-common/events"
+	apphttp "github.com/achgithub/activity-hub-common/http"
+	"github.com/achgithub/activity-hub-common/maintenance"
 	"github.com/gorilla/mux"
 	_ "github.com/lib/pq"
 )
 
 var db *sql.DB
+var identityDB *sql.DB
 
 const APP_NAME = "Leaderboard"
 
 func main() {
+	cli.New("leaderboard",
+		cli.Serve(serve),
+		cli.Migrate(openAppDB, cli.Migrations{
+			Dir:    "../database",
+			Schema: []string{"schema.sql"},
+		}),
+		cli.Command{
+			Name:  "reindex-stats",
+			Short: "Rebuild the cached standings from the recorded results",
+			Run:   reindexStats,
+		},
+	).Main()
+}
+
+// openAppDB connects to leaderboard_db for commands other than serve
+func openAppDB() (*sql.DB, error) {
+	return database.InitDatabase("leaderboard")
+}
+
+func serve() {
 	log.Printf("🏆 %s Backend Starting", APP_NAME)
 
 	// Initialize app database
@@ -28,14 +52,28 @@ func main() {
 	defer db.Close()
 
 	// Initialize identity database (for authentication)
-	identityDB, err := database.InitIdentityDatabase()
+	identityDB, err = database.InitIdentityDatabase()
 	if err != nil {
 		log.Fatal("Failed to connect to identity database:", err)
 	}
 	defer identityDB.Close()
 
-	// Build auth middleware (only needed for result reporting)
+	// Redis carries new results to the platform activity feed and results stream,
+	// and caches the public reads
+	initRedis()
+	initCache()
+
+	// Follow identity account merges so a player's results stay on one account
+	go runAccountMerges()
+
+	// Record results games publish to the event bus (see bus.go)
+	go runResultConsumer()
+
+	// Build auth middleware: signed-in players, or a kiosk issued leaderboard:view
 	authMiddleware := authlib.Middleware(identityDB)
+	sseMiddleware := authlib.SSEMiddleware(identityDB)
+	kiosk := authlib.RequireKioskCapability("leaderboard:view")
+	board := func(h http.HandlerFunc) http.Handler { return authMiddleware(kiosk(h)) }
 
 	// Setup router
 	r := mux.NewRouter()
@@ -44,17 +82,27 @@ func main() {
 	r.HandleFunc("/api/health", handleHealth).Methods("GET")
 	r.HandleFunc("/api/config", HandleConfig).Methods("GET")
 
-	// Standings queries (public - it's a public scoreboard)
-	r.HandleFunc("/api/standings", HandleGetAllStandings).Methods("GET")
-	r.HandleFunc("/api/standings/{gameType}", HandleGetStandings).Methods("GET")
+	// Standings queries (players, or the leaderboard TV's kiosk token)
+	r.Handle("/api/standings", board(HandleGetAllStandings)).Methods("GET")
+	r.Handle("/api/standings/{gameType}", board(HandleGetStandings)).Methods("GET")
 
-	// Recent games (public)
-	r.HandleFunc("/api/recent", HandleGetRecentGames).Methods("GET")
-	r.HandleFunc("/api/recent/{gameType}", HandleGetRecentGames).Methods("GET")
+	// Recent games (the same)
+	r.Handle("/api/recent", board(HandleGetRecentGames)).Methods("GET")
+	r.Handle("/api/recent/stream", sseMiddleware(kiosk(http.HandlerFunc(HandleRecentStream)))).Methods("GET") // Before {gameType}, which would match it
+	r.Handle("/api/recent/{gameType}", board(HandleGetRecentGames)).Methods("GET")
 
 	// Player stats (public)
 	r.HandleFunc("/api/player/{playerId}", HandleGetPlayerStats).Methods("GET")
 
+	// JSON Schema of the results stream events
+	r.HandleFunc("/api/events/schema", events.SchemaHandler).Methods("GET")
+
+	// Machine-readable API description (see openapi.go)
+	r.HandleFunc("/api/openapi.json", apiSpec().Handler).Methods("GET")
+
+	// Cache hit/miss counters for the standings and recent-games reads
+	r.HandleFunc("/api/cache/stats", readCache.StatsHandler).Methods("GET")
+
 	// Result reporting (authentication required - prevents fake results)
 	// Games report results using a player's token to prove legitimacy
 	r.Handle("/api/result", authMiddleware(http.HandlerFunc(HandleReportResult))).Methods("POST")
@@ -64,17 +112,13 @@ func main() {
 	r.PathPrefix("/").Handler(http.FileServer(http.Dir(staticDir)))
 
 	// CORS configuration
-	corsHandler := handlers.CORS(
-		handlers.AllowedOrigins([]string{"*"}),
-		handlers.AllowedMethods([]string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}),
-		handlers.AllowedHeaders([]string{"Content-Type", "Authorization"}),
-		handlers.AllowCredentials(),
-	)
+	cors := apphttp.NewCORSPolicy(identityDB)
+	appMaintenance := maintenance.New(identityDB)
 
 	// Start server
 	port := "5030"
 	log.Printf("🚀 %s backend listening on :%s", APP_NAME, port)
-	log.Fatal(http.ListenAndServe(":"+port, corsHandler(r)))
+	log.Fatal(http.ListenAndServe(":"+port, cors.Middleware(appMaintenance.Middleware("leaderboard")(apphttp.Versioned(r)))))
 }
 
 // handleHealth - Health check endpoint
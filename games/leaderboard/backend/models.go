@@ -17,6 +17,19 @@ type GameResult struct {
 	PlayedAt   time.Time `json:"playedAt"`
 }
 
+// ResultReport is the body games POST to /api/result when a game ends
+type ResultReport struct {
+	GameType   string `json:"gameType"`
+	GameID     string `json:"gameId"`
+	WinnerID   string `json:"winnerId"`
+	WinnerName string `json:"winnerName"`
+	LoserID    string `json:"loserId"`
+	LoserName  string `json:"loserName"`
+	IsDraw     bool   `json:"isDraw"`
+	Score      string `json:"score"`
+	Duration   int    `json:"duration"`
+}
+
 // PlayerStats represents a player's stats for a specific game type
 type PlayerStats struct {
 	PlayerID   string  `json:"playerId"`
@@ -41,10 +54,3 @@ type Standing struct {
 	WinRate    float64 `json:"winRate"`
 	Points     int     `json:"points"` // 3 for win, 1 for draw, 0 for loss
 }
-
-// Config holds app configuration
-type Config struct {
-	AppName string `json:"app_name"`
-	AppIcon string `json:"app_icon"`
-	Version string `json:"version"`
-}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/achgithub/activity-hub-common/cache"
+	apphttp "github.com/achgithub/activity-hub-common/http"
+	"github.com/achgithub/activity-hub-common/openapi"
+)
+
+// apiSpec describes the routes registered in main.go for GET /api/openapi.json.
+// Add a route here whenever one is added there.
+func apiSpec() *openapi.Spec {
+	spec := openapi.New("leaderboard", "1.0.0",
+		"Standings and recent results for two-player games, for players and leaderboard kiosks, and result reporting for the games themselves").
+		TextErrors()
+
+	public := spec.Group("Public")
+	public.Route("GET", "/api/health", "Health check").
+		Returns(http.StatusOK, openapi.Fields{"status": "", "service": ""})
+	public.Route("GET", "/api/config", "App configuration").
+		Returns(http.StatusOK, apphttp.AppConfig{})
+	public.Route("GET", "/api/openapi.json", "This document").
+		Returns(http.StatusOK, nil)
+	public.Route("GET", "/api/events/schema", "JSON Schema of the results stream events").
+		Produces(http.StatusOK, "application/schema+json")
+	public.Route("GET", "/api/cache/stats", "Read cache hit/miss counters").
+		Returns(http.StatusOK, cache.StatsReport{})
+	public.Route("GET", "/api/player/{playerId}", "A player's record per game type").
+		Returns(http.StatusOK, []PlayerStats{})
+
+	standings := spec.Group("Standings").Auth()
+	standings.Route("GET", "/api/standings", "Game types with recorded results").
+		Returns(http.StatusOK, openapi.Fields{"gameTypes": []string{}})
+	standings.Route("GET", "/api/standings/{gameType}", "Top 50 for a game type (3 points a win, 1 a draw)").
+		Returns(http.StatusOK, []Standing{})
+	standings.Route("GET", "/api/recent", "Most recent results across all games").
+		Returns(http.StatusOK, []GameResult{})
+	standings.Route("GET", "/api/recent/stream", "Results as they're recorded (?gameType= for one game)").
+		Query("token", "Stream token, or the kiosk token").
+		Stream()
+	standings.Route("GET", "/api/recent/{gameType}", "Most recent results for a game type").
+		Returns(http.StatusOK, []GameResult{})
+
+	results := spec.Group("Results").Auth()
+	results.Route("POST", "/api/result", "Report a finished game (either player's token; duplicates are ignored)").
+		Body(ResultReport{}).
+		Returns(http.StatusOK, openapi.Fields{"success": true})
+
+	return spec
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/achgithub/activity-hub-common/accounts"
+	"github.com/achgithub/activity-hub-common/activity"
+	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/go-redis/redis/v8"
+)
+
+var redisClient *redis.Client
+
+func initRedis() {
+	redisClient = redis.NewClient(&redis.Options{
+		Addr: "127.0.0.1:6379",
+	})
+	ctx := context.Background()
+	if err := redisClient.Ping(ctx).Err(); err != nil {
+		log.Printf("Warning: Redis not available: %v", err)
+	}
+}
+
+// gameTitle turns a game type ID into a readable name ("tic-tac-toe" -> "Tic Tac Toe").
+func gameTitle(gameType string) string {
+	words := strings.Split(gameType, "-")
+	for i, w := range words {
+		if w != "" {
+			words[i] = strings.ToUpper(w[:1]) + w[1:]
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// publishGameResult posts a newly recorded result to the platform activity feed
+// under the players' public names. Failures are logged only.
+func publishGameResult(res GameResult) {
+	names := authlib.PublicNames(identityDB, []string{res.WinnerID, res.LoserID})
+	winner, loser := names[res.WinnerID], names[res.LoserID]
+
+	text := fmt.Sprintf("%s beat %s at %s", winner, loser, gameTitle(res.GameType))
+	if res.IsDraw {
+		text = fmt.Sprintf("%s and %s drew at %s", winner, loser, gameTitle(res.GameType))
+	}
+	if res.Score != "" {
+		text += " (" + res.Score + ")"
+	}
+
+	msg, err := activity.Encode(activity.Event{
+		Type:    activity.TypeGameResult,
+		App:     res.GameType,
+		Text:    text,
+		Players: []string{winner, loser},
+	})
+	if err != nil {
+		log.Printf("Invalid activity event for game %s: %v", res.GameID, err)
+		return
+	}
+	if err := redisClient.Publish(context.Background(), activity.Channel, msg).Err(); err != nil {
+		log.Printf("Failed to publish activity for game %s: %v", res.GameID, err)
+	}
+}
+
+// runAccountMerges moves results onto the kept account when the identity shell
+// merges a duplicate. Runs for the life of the process; re-sent merges find
+// nothing left to move.
+func runAccountMerges() {
+	pubsub := redisClient.Subscribe(context.Background(), accounts.MergedChannel)
+	defer pubsub.Close()
+
+	for msg := range pubsub.Channel() {
+		m, err := accounts.Decode(msg.Payload)
+		if err != nil {
+			log.Printf("Ignoring account merge: %v", err)
+			continue
+		}
+		if err := mergePlayerResults(m.From, m.To); err != nil {
+			log.Printf("Failed to merge results of %s into %s: %v", m.From, m.To, err)
+		}
+	}
+}
+
+// mergePlayerResults re-keys from's results to to and drops the cached reads they were in
+func mergePlayerResults(from, to string) error {
+	rows, err := db.Query(`
+		UPDATE game_results
+		SET winner_id = CASE WHEN winner_id = $1 THEN $2 ELSE winner_id END,
+		    loser_id = CASE WHEN loser_id = $1 THEN $2 ELSE loser_id END
+		WHERE winner_id = $1 OR loser_id = $1
+		RETURNING game_type
+	`, from, to)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	gameTypes := map[string]bool{}
+	moved := 0
+	for rows.Next() {
+		var gameType string
+		if rows.Scan(&gameType) == nil {
+			gameTypes[gameType] = true
+			moved++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for gameType := range gameTypes {
+		invalidateResults(gameType)
+	}
+	if moved > 0 {
+		log.Printf("Merged %d results of %s into %s", moved, from, to)
+	}
+	return nil
+}
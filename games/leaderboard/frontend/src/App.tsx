@@ -1,4 +1,4 @@
-import React, { useState, useEffect } from 'react';
+import React, { useState, useEffect, useMemo } from 'react';
 import './App.css';
 
 // API is served from same origin (single port architecture)
@@ -39,11 +39,27 @@ interface GameResult {
 }
 
 interface Config {
-  app_name: string;
-  app_icon: string;
+  name: string;
+  icon: string;
   version: string;
 }
 
+// Token for the results stream. A kiosk passes its own - it's only good for
+// this board - and a player swaps theirs for a single-use stream token from the
+// shell, so the session token never goes in the EventSource URL.
+async function streamToken(token: string): Promise<string> {
+  if (token.startsWith('kiosk-')) return token;
+  try {
+    const res = await fetch(`http://${window.location.hostname}:3001/api/auth/stream-token`, {
+      method: 'POST',
+      headers: { 'Authorization': `Bearer ${token}` },
+    });
+    return res.ok ? (await res.json()).token : '';
+  } catch {
+    return '';
+  }
+}
+
 function App() {
   // Parse URL parameters
   const params = new URLSearchParams(window.location.search);
@@ -52,8 +68,9 @@ function App() {
   const userId = params.get('userId'); // Future: for "My Stats" view
   // eslint-disable-next-line @typescript-eslint/no-unused-vars
   const userName = params.get('userName'); // Future: for personalization
-  // eslint-disable-next-line @typescript-eslint/no-unused-vars
-  const token = params.get('token'); // Future: for authenticated features
+  // A player's token from the shell, or a kiosk token on a screen in the pub
+  const token = params.get('token') || sessionStorage.getItem('token') || '';
+  const authHeaders = useMemo(() => ({ 'Authorization': `Bearer ${token}` }), [token]);
 
   const [view, setView] = useState<'standings' | 'recent'>('standings');
   const [gameTypes, setGameTypes] = useState<string[]>([]);
@@ -62,8 +79,8 @@ function App() {
   const [recentGames, setRecentGames] = useState<GameResult[]>([]);
   const [loading, setLoading] = useState(true);
   const [config, setConfig] = useState<Config>({
-    app_name: 'Leaderboard',
-    app_icon: '🏆',
+    name: 'Leaderboard',
+    icon: '🏆',
     version: '1.0.0',
   });
 
@@ -77,7 +94,7 @@ function App() {
 
   // Load game types
   useEffect(() => {
-    fetch(`${API_BASE}/standings`)
+    fetch(`${API_BASE}/standings`, { headers: authHeaders })
       .then(res => res.json())
       .then(data => {
         const types = data.gameTypes || [];
@@ -98,22 +115,49 @@ function App() {
         console.error('Failed to load game types:', err);
         setLoading(false);
       });
-  }, [filterGame, selectedGame]);
+  }, [filterGame, selectedGame, authHeaders]);
 
   // Load standings when game type changes
   useEffect(() => {
     if (!selectedGame) return;
 
-    fetch(`${API_BASE}/standings/${selectedGame}`)
+    fetch(`${API_BASE}/standings/${selectedGame}`, { headers: authHeaders })
       .then(res => res.json())
       .then(data => setStandings(data || []))
       .catch(err => console.error('Failed to load standings:', err));
 
-    fetch(`${API_BASE}/recent/${selectedGame}`)
+    fetch(`${API_BASE}/recent/${selectedGame}`, { headers: authHeaders })
       .then(res => res.json())
       .then(data => setRecentGames(data || []))
       .catch(err => console.error('Failed to load recent games:', err));
-  }, [selectedGame]);
+  }, [selectedGame, authHeaders]);
+
+  // Live results: a new one goes to the top of recent games and the standings
+  // are fetched again, so screens showing the board update when someone wins
+  useEffect(() => {
+    if (!selectedGame) return;
+
+    let source: EventSource | null = null;
+    let closed = false;
+    streamToken(token).then(streamTok => {
+      if (closed) return;
+      source = new EventSource(`${API_BASE}/recent/stream?gameType=${encodeURIComponent(selectedGame)}&token=${encodeURIComponent(streamTok)}`);
+      source.onmessage = (e) => {
+        const event = JSON.parse(e.data);
+        if (event.type !== 'result_recorded') return;
+        const result: GameResult = event.payload;
+        setRecentGames(prev => [result, ...prev.filter(g => g.id !== result.id)].slice(0, 20));
+        fetch(`${API_BASE}/standings/${selectedGame}`, { headers: authHeaders })
+          .then(res => res.json())
+          .then(data => setStandings(data || []))
+          .catch(err => console.error('Failed to load standings:', err));
+      };
+    });
+    return () => {
+      closed = true;
+      source?.close();
+    };
+  }, [selectedGame, token, authHeaders]);
 
   const getGameName = (gameType: string): string => {
     return GAME_NAMES[gameType] || gameType;
@@ -148,7 +192,7 @@ function App() {
       <div className="ah-app-header">
         <div className="ah-app-header-left">
           <h1 className="ah-app-title">
-            {config.app_icon} {isFilteredMode ? `${getGameName(selectedGame)} Leaderboard` : config.app_name}
+            {config.icon} {isFilteredMode ? `${getGameName(selectedGame)} Leaderboard` : config.name}
           </h1>
         </div>
         <div className="ah-app-header-right">
@@ -10,17 +10,18 @@ import (
 	"strconv"
 
 	authlib "github.com/achgithub/activity-hub-common/auth"
+	apphttp "github.com/achgithub/activity-hub-common/http"
 	"github.com/gorilla/mux"
 )
 
 // HandleConfig returns app configuration
 func HandleConfig(w http.ResponseWriter, r *http.Request) {
-	config := map[string]interface{}{
-		"appName": APP_NAME,
-		"appIcon": "🎯",
-	}
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(config)
+	apphttp.WriteConfig(w, apphttp.AppConfig{
+		AppID:       "lms-manager",
+		Name:        APP_NAME,
+		Icon:        "🎯",
+		Description: "Admin tool for managing Last Man Standing competitions (groups, teams, players, picks, results)",
+	})
 }
 
 // getManagerEmail extracts manager email from context or impersonation
@@ -64,7 +65,7 @@ func HandleListGroups(w http.ResponseWriter, r *http.Request) {
 			COALESCE(COUNT(t.id), 0) as team_count
 		FROM managed_groups g
 		LEFT JOIN managed_teams t ON t.group_id = g.id
-		WHERE g.manager_email = $1
+		WHERE g.manager_email = $1 AND g.deleted_at IS NULL
 		GROUP BY g.id, g.manager_email, g.name, g.created_at
 		ORDER BY g.created_at DESC
 	`, managerEmail)
@@ -128,7 +129,8 @@ func HandleCreateGroup(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// HandleDeleteGroup deletes a group
+// HandleDeleteGroup moves a group to the trash. Games already using it keep
+// their teams; it just stops being offered for new games.
 func HandleDeleteGroup(w http.ResponseWriter, r *http.Request) {
 	managerEmail, ok := getManagerEmail(r)
 	if !ok {
@@ -144,9 +146,9 @@ func HandleDeleteGroup(w http.ResponseWriter, r *http.Request) {
 	}
 
 	result, err := db.Exec(`
-		DELETE FROM managed_groups
-		WHERE id = $1 AND manager_email = $2
-	`, groupID, managerEmail)
+		UPDATE managed_groups SET deleted_at = NOW(), deleted_by = $3
+		WHERE id = $1 AND manager_email = $2 AND deleted_at IS NULL
+	`, groupID, managerEmail, deletedBy(r))
 	if err != nil {
 		log.Printf("Failed to delete group: %v", err)
 		http.Error(w, "Failed to delete group", http.StatusInternalServerError)
@@ -237,7 +239,7 @@ func HandleImportGroups(w http.ResponseWriter, r *http.Request) {
 	for _, g := range groupsToImport {
 		// Check if group already exists
 		var existingID int
-		err := tx.QueryRow(`SELECT id FROM managed_groups WHERE manager_email=$1 AND name=$2`,
+		err := tx.QueryRow(`SELECT id FROM managed_groups WHERE manager_email=$1 AND name=$2 AND deleted_at IS NULL`,
 			managerEmail, g.Name).Scan(&existingID)
 
 		if err == sql.ErrNoRows {
@@ -307,7 +309,7 @@ func HandleListTeams(w http.ResponseWriter, r *http.Request) {
 
 	// Verify group belongs to manager
 	var count int
-	err = db.QueryRow(`SELECT COUNT(*) FROM managed_groups WHERE id = $1 AND manager_email = $2`, groupID, managerEmail).Scan(&count)
+	err = db.QueryRow(`SELECT COUNT(*) FROM managed_groups WHERE id = $1 AND manager_email = $2 AND deleted_at IS NULL`, groupID, managerEmail).Scan(&count)
 	if err != nil || count == 0 {
 		http.Error(w, "Group not found", http.StatusNotFound)
 		return
@@ -359,7 +361,7 @@ func HandleCreateTeam(w http.ResponseWriter, r *http.Request) {
 
 	// Verify group belongs to manager
 	var count int
-	err = db.QueryRow(`SELECT COUNT(*) FROM managed_groups WHERE id = $1 AND manager_email = $2`, groupID, managerEmail).Scan(&count)
+	err = db.QueryRow(`SELECT COUNT(*) FROM managed_groups WHERE id = $1 AND manager_email = $2 AND deleted_at IS NULL`, groupID, managerEmail).Scan(&count)
 	if err != nil || count == 0 {
 		http.Error(w, "Group not found", http.StatusNotFound)
 		return
@@ -420,7 +422,7 @@ func HandleUpdateTeam(w http.ResponseWriter, r *http.Request) {
 		UPDATE managed_teams
 		SET name = $1
 		WHERE id = $2
-		AND group_id IN (SELECT id FROM managed_groups WHERE manager_email = $3)
+		AND group_id IN (SELECT id FROM managed_groups WHERE manager_email = $3 AND deleted_at IS NULL)
 	`, req.Name, teamID, managerEmail)
 	if err != nil {
 		log.Printf("Failed to update team: %v", err)
@@ -456,7 +458,7 @@ func HandleDeleteTeam(w http.ResponseWriter, r *http.Request) {
 	result, err := db.Exec(`
 		DELETE FROM managed_teams
 		WHERE id = $1
-		AND group_id IN (SELECT id FROM managed_groups WHERE manager_email = $2)
+		AND group_id IN (SELECT id FROM managed_groups WHERE manager_email = $2 AND deleted_at IS NULL)
 	`, teamID, managerEmail)
 	if err != nil {
 		log.Printf("Failed to delete team: %v", err)
@@ -607,7 +609,7 @@ func HandleListGames(w http.ResponseWriter, r *http.Request) {
 		LEFT JOIN managed_groups gr ON gr.id = g.group_id
 		LEFT JOIN managed_participants p ON p.game_id = g.id
 		LEFT JOIN managed_rounds r ON r.game_id = g.id
-		WHERE g.manager_email = $1
+		WHERE g.manager_email = $1 AND g.deleted_at IS NULL
 		GROUP BY g.id, gr.name
 		ORDER BY g.created_at DESC
 	`, managerEmail)
@@ -672,7 +674,7 @@ func HandleCreateGame(w http.ResponseWriter, r *http.Request) {
 
 	// Verify group belongs to manager
 	var count int
-	err := db.QueryRow(`SELECT COUNT(*) FROM managed_groups WHERE id = $1 AND manager_email = $2`, req.GroupID, managerEmail).Scan(&count)
+	err := db.QueryRow(`SELECT COUNT(*) FROM managed_groups WHERE id = $1 AND manager_email = $2 AND deleted_at IS NULL`, req.GroupID, managerEmail).Scan(&count)
 	if err != nil || count == 0 {
 		http.Error(w, "Group not found", http.StatusNotFound)
 		return
@@ -764,7 +766,7 @@ func HandleGetGame(w http.ResponseWriter, r *http.Request) {
 		LEFT JOIN managed_groups gr ON gr.id = g.group_id
 		LEFT JOIN managed_participants p ON p.game_id = g.id
 		LEFT JOIN managed_rounds r ON r.game_id = g.id
-		WHERE g.id = $1 AND g.manager_email = $2
+		WHERE g.id = $1 AND g.manager_email = $2 AND g.deleted_at IS NULL
 		GROUP BY g.id, gr.name
 	`, gameID, managerEmail).Scan(
 		&game.ID, &game.ManagerEmail, &game.Name, &game.GroupID, &game.Status, &winnerName, &game.PostponeAsWin, &game.WinnerMode, &game.RolloverMode, &game.MaxWinners, &game.CreatedAt,
@@ -844,6 +846,8 @@ func HandleGetGame(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// HandleDeleteGame moves a game to the trash; restoring it brings back its
+// participants, rounds and picks untouched
 func HandleDeleteGame(w http.ResponseWriter, r *http.Request) {
 	managerEmail, ok := getManagerEmail(r)
 	if !ok {
@@ -858,26 +862,19 @@ func HandleDeleteGame(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Verify ownership
-	var exists bool
-	err = db.QueryRow(`
-		SELECT EXISTS(SELECT 1 FROM managed_games WHERE id = $1 AND manager_email = $2)
-	`, gameID, managerEmail).Scan(&exists)
+	result, err := db.Exec(`
+		UPDATE managed_games SET deleted_at = NOW(), deleted_by = $3
+		WHERE id = $1 AND manager_email = $2 AND deleted_at IS NULL
+	`, gameID, managerEmail, deletedBy(r))
 	if err != nil {
-		log.Printf("Failed to verify game ownership: %v", err)
-		http.Error(w, "Failed to verify game", http.StatusInternalServerError)
-		return
-	}
-	if !exists {
-		http.Error(w, "Game not found", http.StatusNotFound)
+		log.Printf("Failed to delete game: %v", err)
+		http.Error(w, "Failed to delete game", http.StatusInternalServerError)
 		return
 	}
 
-	// Delete game (cascades will handle related data)
-	_, err = db.Exec(`DELETE FROM managed_games WHERE id = $1`, gameID)
-	if err != nil {
-		log.Printf("Failed to delete game: %v", err)
-		http.Error(w, "Failed to delete game", http.StatusInternalServerError)
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		http.Error(w, "Game not found", http.StatusNotFound)
 		return
 	}
 
@@ -909,7 +906,7 @@ func HandleGetRoundPicks(w http.ResponseWriter, r *http.Request) {
 		SELECT COUNT(*)
 		FROM managed_rounds r
 		JOIN managed_games g ON g.id = r.game_id
-		WHERE r.id = $1 AND g.manager_email = $2
+		WHERE r.id = $1 AND g.manager_email = $2 AND g.deleted_at IS NULL
 	`, roundID, managerEmail).Scan(&count)
 	if err != nil || count == 0 {
 		http.Error(w, "Round not found", http.StatusNotFound)
@@ -983,7 +980,7 @@ func HandleSavePicks(w http.ResponseWriter, r *http.Request) {
 		SELECT r.game_id, r.status
 		FROM managed_rounds r
 		JOIN managed_games g ON g.id = r.game_id
-		WHERE r.id = $1 AND g.manager_email = $2
+		WHERE r.id = $1 AND g.manager_email = $2 AND g.deleted_at IS NULL
 	`, roundID, managerEmail).Scan(&gameID, &roundStatus)
 	if err == sql.ErrNoRows {
 		http.Error(w, "Round not found", http.StatusNotFound)
@@ -1059,7 +1056,7 @@ func HandleSaveResults(w http.ResponseWriter, r *http.Request) {
 		SELECT r.game_id, r.round_number, g.postpone_as_win
 		FROM managed_rounds r
 		JOIN managed_games g ON g.id = r.game_id
-		WHERE r.id = $1 AND g.manager_email = $2
+		WHERE r.id = $1 AND g.manager_email = $2 AND g.deleted_at IS NULL
 	`, roundID, managerEmail).Scan(&gameID, &roundNumber, &postponeAsWin)
 	if err == sql.ErrNoRows {
 		http.Error(w, "Round not found", http.StatusNotFound)
@@ -1099,16 +1096,8 @@ func HandleSaveResults(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		// Determine if player should be eliminated
-		shouldEliminate := false
-		if result.Result == "loss" || result.Result == "draw" {
-			shouldEliminate = true
-		} else if result.Result == "postponed" && !postponeAsWin {
-			// If postpone counts as loss, eliminate
-			shouldEliminate = true
-		}
-
-		if shouldEliminate {
+		// Losses and draws eliminate, as do postponements unless they count as wins
+		if eliminates(result.Result, postponeAsWin) {
 			var playerName string
 			err = tx.QueryRow(`SELECT player_name FROM managed_picks WHERE id = $1`, result.PickID).Scan(&playerName)
 			if err != nil {
@@ -1160,7 +1149,7 @@ func HandleCloseRound(w http.ResponseWriter, r *http.Request) {
 		SELECT r.game_id
 		FROM managed_rounds r
 		JOIN managed_games g ON g.id = r.game_id
-		WHERE r.id = $1 AND g.manager_email = $2
+		WHERE r.id = $1 AND g.manager_email = $2 AND g.deleted_at IS NULL
 	`, roundID, managerEmail).Scan(&gameID)
 	if err == sql.ErrNoRows {
 		http.Error(w, "Round not found", http.StatusNotFound)
@@ -1204,7 +1193,7 @@ func HandleAdvanceRound(w http.ResponseWriter, r *http.Request) {
 	err = db.QueryRow(`
 		SELECT winner_mode, rollover_mode, max_winners
 		FROM managed_games
-		WHERE id = $1 AND manager_email = $2
+		WHERE id = $1 AND manager_email = $2 AND deleted_at IS NULL
 	`, gameID, managerEmail).Scan(&winnerMode, &rolloverMode, &maxWinners)
 	if err == sql.ErrNoRows {
 		http.Error(w, "Game not found", http.StatusNotFound)
@@ -1570,7 +1559,7 @@ func HandleGetUsedTeams(w http.ResponseWriter, r *http.Request) {
 
 	// Verify game belongs to manager
 	var count int
-	err = db.QueryRow(`SELECT COUNT(*) FROM managed_games WHERE id = $1 AND manager_email = $2`, gameID, managerEmail).Scan(&count)
+	err = db.QueryRow(`SELECT COUNT(*) FROM managed_games WHERE id = $1 AND manager_email = $2 AND deleted_at IS NULL`, gameID, managerEmail).Scan(&count)
 	if err != nil || count == 0 {
 		http.Error(w, "Game not found", http.StatusNotFound)
 		return
@@ -1626,7 +1615,7 @@ func HandleAddParticipants(w http.ResponseWriter, r *http.Request) {
 
 	// Verify game belongs to manager and is active
 	var status string
-	err = db.QueryRow(`SELECT status FROM managed_games WHERE id = $1 AND manager_email = $2`, gameID, managerEmail).Scan(&status)
+	err = db.QueryRow(`SELECT status FROM managed_games WHERE id = $1 AND manager_email = $2 AND deleted_at IS NULL`, gameID, managerEmail).Scan(&status)
 	if err == sql.ErrNoRows {
 		http.Error(w, "Game not found", http.StatusNotFound)
 		return
@@ -1708,7 +1697,7 @@ func HandleReopenRound(w http.ResponseWriter, r *http.Request) {
 		SELECT r.game_id, r.round_number
 		FROM managed_rounds r
 		JOIN managed_games g ON g.id = r.game_id
-		WHERE r.id = $1 AND g.manager_email = $2
+		WHERE r.id = $1 AND g.manager_email = $2 AND g.deleted_at IS NULL
 	`, roundID, managerEmail).Scan(&gameID, &roundNumber)
 	if err == sql.ErrNoRows {
 		http.Error(w, "Round not found", http.StatusNotFound)
@@ -1792,7 +1781,7 @@ func HandleFinalizePicks(w http.ResponseWriter, r *http.Request) {
 		SELECT r.game_id, g.group_id
 		FROM managed_rounds r
 		JOIN managed_games g ON g.id = r.game_id
-		WHERE r.id = $1 AND g.manager_email = $2 AND r.status = 'open'
+		WHERE r.id = $1 AND g.manager_email = $2 AND g.deleted_at IS NULL AND r.status = 'open'
 	`, roundID, managerEmail).Scan(&gameID, &groupID)
 	if err == sql.ErrNoRows {
 		http.Error(w, "Round not found or already closed", http.StatusNotFound)
@@ -1991,7 +1980,7 @@ func HandleGetReport(w http.ResponseWriter, r *http.Request) {
 	err = db.QueryRow(`
 		SELECT name, status, winner_name, postpone_as_win, winner_mode, rollover_mode, max_winners
 		FROM managed_games
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`, gameID).Scan(&gameName, &gameStatus, &winnerName, &postponeAsWin, &winnerMode, &rolloverMode, &maxWinners)
 	if err == sql.ErrNoRows {
 		http.Error(w, "Game not found", http.StatusNotFound)
@@ -6,8 +6,11 @@ import (
 	"net/http"
 
 	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/achgithub/activity-hub-common/cli"
 	"github.com/achgithub/activity-hub-common/database"
-	"github.com/gorilla/handlers"
+	"github.com/achgithub/activity-hub-common/hours"
+	apphttp "github.com/achgithub/activity-hub-common/http"
+	"github.com/achgithub/activity-hub-common/maintenance"
 	"github.com/gorilla/mux"
 	_ "github.com/lib/pq"
 )
@@ -17,6 +20,24 @@ var db *sql.DB
 const APP_NAME = "LMS Manager"
 
 func main() {
+	cli.New("lms-manager",
+		cli.Serve(serve),
+		cli.Migrate(openAppDB, cli.Migrations{
+			Dir:    "../database",
+			Schema: []string{"schema.sql"},
+			Upgrades: []string{
+				"migrate_add_trash.sql",
+			},
+		}),
+	).Main()
+}
+
+// openAppDB connects to lms_manager_db for commands other than serve
+func openAppDB() (*sql.DB, error) {
+	return database.InitDatabase("lms_manager")
+}
+
+func serve() {
 	log.Printf("🎯 %s Backend Starting", APP_NAME)
 
 	// Initialize app database
@@ -34,6 +55,9 @@ func main() {
 	}
 	defer identityDB.Close()
 
+	// Permanently remove trash past the retention period, outside opening hours
+	go runTrashPurge(hours.New(identityDB))
+
 	// Build authentication middleware
 	authMiddleware := authlib.Middleware(identityDB)
 
@@ -68,11 +92,16 @@ func main() {
 	r.Handle("/api/games/{id}/used-teams", authMiddleware(http.HandlerFunc(HandleGetUsedTeams))).Methods("GET")
 	r.Handle("/api/games/{id}/participants", authMiddleware(http.HandlerFunc(HandleAddParticipants))).Methods("POST")
 
+	// Trash (deleted games and groups)
+	r.Handle("/api/trash", authMiddleware(http.HandlerFunc(HandleListTrash))).Methods("GET")
+	r.Handle("/api/trash/{type}/{id}/restore", authMiddleware(http.HandlerFunc(HandleRestoreTrash))).Methods("POST")
+
 	// Round/Pick endpoints
 	r.Handle("/api/rounds/{roundId}/picks", authMiddleware(http.HandlerFunc(HandleGetRoundPicks))).Methods("GET")
 	r.Handle("/api/rounds/{roundId}/picks", authMiddleware(http.HandlerFunc(HandleSavePicks))).Methods("POST")
 	r.Handle("/api/rounds/{roundId}/finalize-picks", authMiddleware(http.HandlerFunc(HandleFinalizePicks))).Methods("POST")
 	r.Handle("/api/rounds/{roundId}/results", authMiddleware(http.HandlerFunc(HandleSaveResults))).Methods("POST")
+	r.Handle("/api/rounds/{roundId}/simulate", authMiddleware(http.HandlerFunc(HandleSimulateRound))).Methods("POST")
 	r.Handle("/api/rounds/{roundId}/close", authMiddleware(http.HandlerFunc(HandleCloseRound))).Methods("POST")
 	r.Handle("/api/rounds/{roundId}/reopen", authMiddleware(http.HandlerFunc(HandleReopenRound))).Methods("POST")
 
@@ -81,15 +110,11 @@ func main() {
 	r.PathPrefix("/").Handler(http.FileServer(http.Dir(staticDir)))
 
 	// CORS configuration
-	corsHandler := handlers.CORS(
-		handlers.AllowedOrigins([]string{"*"}),
-		handlers.AllowedMethods([]string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}),
-		handlers.AllowedHeaders([]string{"Content-Type", "Authorization"}),
-		handlers.AllowCredentials(),
-	)
+	cors := apphttp.NewCORSPolicy(identityDB)
+	appMaintenance := maintenance.New(identityDB)
 
 	// Start server
 	port := "4022"
 	log.Printf("🚀 %s backend listening on :%s", APP_NAME, port)
-	log.Fatal(http.ListenAndServe(":"+port, corsHandler(r)))
+	log.Fatal(http.ListenAndServe(":"+port, cors.Middleware(appMaintenance.Middleware("lms-manager")(apphttp.Versioned(r)))))
 }
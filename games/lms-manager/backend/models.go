@@ -139,3 +139,40 @@ type ParticipantStatus struct {
 	EliminatedInRound *int    `json:"eliminatedInRound,omitempty"`
 	EliminationReason *string `json:"eliminationReason,omitempty"`
 }
+
+// TrashItem is a deleted game or group awaiting restore or purge
+type TrashItem struct {
+	Type      string    `json:"type"` // 'game', 'group'
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	DeletedAt time.Time `json:"deletedAt"`
+	DeletedBy string    `json:"deletedBy"`
+	PurgeAt   time.Time `json:"purgeAt"`
+}
+
+// SimulateRoundRequest holds hypothetical results for a round's picks.
+// Picks left out keep their saved result, if any.
+type SimulateRoundRequest struct {
+	Results []struct {
+		PickID int    `json:"pickId"`
+		Result string `json:"result"` // 'win', 'loss', 'draw', 'postponed'
+	} `json:"results"`
+}
+
+// SimulatedPick is one player's fate under the hypothetical results
+type SimulatedPick struct {
+	PlayerName string  `json:"playerName"`
+	TeamName   string  `json:"teamName"`
+	Result     *string `json:"result,omitempty"` // nil = no result yet (survives for now)
+}
+
+// SimulationResult is what closing the round and advancing would do
+type SimulationResult struct {
+	RoundNumber int             `json:"roundNumber"`
+	Survivors   []SimulatedPick `json:"survivors"`
+	Eliminated  []SimulatedPick `json:"eliminated"`
+	Undecided   int             `json:"undecided"`
+	Outcome     string          `json:"outcome"` // 'next_round', 'completed', 'rollover_round', 'rollover_game'
+	NextRound   int             `json:"nextRound,omitempty"`
+	Winners     []string        `json:"winners,omitempty"`
+}
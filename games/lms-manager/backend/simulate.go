@@ -0,0 +1,185 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// validResults are the results a pick can be given
+var validResults = map[string]bool{"win": true, "loss": true, "draw": true, "postponed": true}
+
+// eliminates reports whether a pick result knocks its player out
+func eliminates(result string, postponeAsWin bool) bool {
+	switch result {
+	case "loss", "draw":
+		return true
+	case "postponed":
+		return !postponeAsWin
+	}
+	return false
+}
+
+// HandleSimulateRound projects what the given results would do to the game's
+// latest round - who survives, who goes out, and whether the game would be
+// won or roll over - without saving anything
+func HandleSimulateRound(w http.ResponseWriter, r *http.Request) {
+	managerEmail, ok := getManagerEmail(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	vars := mux.Vars(r)
+	roundID, err := strconv.Atoi(vars["roundId"])
+	if err != nil {
+		http.Error(w, "Invalid round ID", http.StatusBadRequest)
+		return
+	}
+
+	// Verify round belongs to manager's game and get the game settings
+	var gameID, roundNumber, latestRound, maxWinners int
+	var gameStatus, winnerMode, rolloverMode string
+	var postponeAsWin bool
+	err = db.QueryRow(`
+		SELECT r.game_id, r.round_number, g.status, g.postpone_as_win, g.winner_mode, g.rollover_mode, g.max_winners,
+		       (SELECT MAX(round_number) FROM managed_rounds WHERE game_id = g.id)
+		FROM managed_rounds r
+		JOIN managed_games g ON g.id = r.game_id
+		WHERE r.id = $1 AND g.manager_email = $2 AND g.deleted_at IS NULL
+	`, roundID, managerEmail).Scan(&gameID, &roundNumber, &gameStatus, &postponeAsWin,
+		&winnerMode, &rolloverMode, &maxWinners, &latestRound)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Round not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to query round: %v", err)
+		http.Error(w, "Failed to verify round", http.StatusInternalServerError)
+		return
+	}
+	if gameStatus != "active" || roundNumber != latestRound {
+		http.Error(w, "Only the current round of an active game can be simulated", http.StatusConflict)
+		return
+	}
+
+	var req SimulateRoundRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+	hypothetical := make(map[int]string, len(req.Results))
+	for _, result := range req.Results {
+		if !validResults[result.Result] {
+			http.Error(w, "Invalid result: "+result.Result, http.StatusBadRequest)
+			return
+		}
+		hypothetical[result.PickID] = result.Result
+	}
+
+	// Everyone still in at the start of this round, with their pick (if any).
+	// Players already knocked out by saved results for this round count as in.
+	rows, err := db.Query(`
+		SELECT pt.player_name, p.id, COALESCE(t.name, ''), p.result
+		FROM managed_participants pt
+		LEFT JOIN managed_picks p ON p.round_id = $2 AND p.player_name = pt.player_name
+		LEFT JOIN managed_teams t ON t.id = p.team_id
+		WHERE pt.game_id = $1 AND (pt.is_active = TRUE OR pt.eliminated_in_round = $3)
+		ORDER BY pt.player_name ASC
+	`, gameID, roundID, roundNumber)
+	if err != nil {
+		log.Printf("Failed to query participants: %v", err)
+		http.Error(w, "Failed to simulate round", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	sim := SimulationResult{
+		RoundNumber: roundNumber,
+		Survivors:   []SimulatedPick{},
+		Eliminated:  []SimulatedPick{},
+	}
+	for rows.Next() {
+		var sp SimulatedPick
+		var pickID sql.NullInt64
+		var saved sql.NullString
+		if err := rows.Scan(&sp.PlayerName, &pickID, &sp.TeamName, &saved); err != nil {
+			log.Printf("Failed to scan participant: %v", err)
+			continue
+		}
+		if result, ok := hypothetical[int(pickID.Int64)]; ok && pickID.Valid {
+			sp.Result = &result
+		} else if saved.Valid {
+			sp.Result = &saved.String
+		}
+
+		if sp.Result != nil && eliminates(*sp.Result, postponeAsWin) {
+			sim.Eliminated = append(sim.Eliminated, sp)
+			continue
+		}
+		if sp.Result == nil {
+			sim.Undecided++
+		}
+		sim.Survivors = append(sim.Survivors, sp)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Failed to read participants: %v", err)
+		http.Error(w, "Failed to simulate round", http.StatusInternalServerError)
+		return
+	}
+
+	projectOutcome(&sim, winnerMode, rolloverMode, maxWinners)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sim)
+}
+
+// projectOutcome fills in what advancing after the round would do. It follows
+// the same rules as HandleAdvanceRound; keep the two in step.
+func projectOutcome(sim *SimulationResult, winnerMode, rolloverMode string, maxWinners int) {
+	names := func(picks []SimulatedPick) []string {
+		out := make([]string, 0, len(picks))
+		for _, p := range picks {
+			out = append(out, p.PlayerName)
+		}
+		sort.Strings(out)
+		return out
+	}
+	rollover := func() {
+		if rolloverMode == "round" {
+			sim.Outcome = "rollover_round"
+			sim.NextRound = sim.RoundNumber + 1
+		} else {
+			sim.Outcome = "rollover_game"
+			sim.NextRound = 1
+		}
+	}
+
+	survivors := len(sim.Survivors)
+	switch {
+	case winnerMode == "single" && survivors == 1:
+		sim.Outcome = "completed"
+		sim.Winners = names(sim.Survivors)
+	case winnerMode == "single" && survivors == 0:
+		rollover()
+	case winnerMode != "single" && survivors > 0 && survivors <= maxWinners:
+		sim.Outcome = "completed"
+		sim.Winners = names(sim.Survivors)
+	case winnerMode != "single" && survivors == 0:
+		// Everyone went out together: they share it if few enough, else it rolls over
+		if len(sim.Eliminated) <= maxWinners {
+			sim.Outcome = "completed"
+			sim.Winners = names(sim.Eliminated)
+		} else {
+			rollover()
+		}
+	default:
+		sim.Outcome = "next_round"
+		sim.NextRound = sim.RoundNumber + 1
+	}
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/achgithub/activity-hub-common/hours"
+	"github.com/gorilla/mux"
+)
+
+// ============================================
+// TRASH
+// ============================================
+//
+// Deleted games and groups keep their rows with deleted_at set, so a game
+// deleted by mistake can be restored with everything in it. They're purged
+// for good after TRASH_RETENTION_DAYS (default 30).
+
+const defaultTrashRetentionDays = 30
+
+// trashTables maps the trash item types to their tables
+var trashTables = map[string]string{
+	"game":  "managed_games",
+	"group": "managed_groups",
+}
+
+func trashRetentionDays() int {
+	days, err := strconv.Atoi(os.Getenv("TRASH_RETENTION_DAYS"))
+	if err != nil || days < 1 {
+		return defaultTrashRetentionDays
+	}
+	return days
+}
+
+// deletedBy records who actually deleted something (the admin, when impersonating)
+func deletedBy(r *http.Request) string {
+	user, ok := authlib.GetUserFromContext(r.Context())
+	if !ok {
+		return ""
+	}
+	return user.ActorEmail()
+}
+
+// HandleListTrash returns the manager's deleted games and groups, most recent first
+func HandleListTrash(w http.ResponseWriter, r *http.Request) {
+	managerEmail, ok := getManagerEmail(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	rows, err := db.Query(`
+		SELECT 'game', id, name, deleted_at, COALESCE(deleted_by, '')
+		FROM managed_games
+		WHERE manager_email = $1 AND deleted_at IS NOT NULL
+		UNION ALL
+		SELECT 'group', id, name, deleted_at, COALESCE(deleted_by, '')
+		FROM managed_groups
+		WHERE manager_email = $1 AND deleted_at IS NOT NULL
+		ORDER BY 4 DESC
+	`, managerEmail)
+	if err != nil {
+		log.Printf("Failed to query trash: %v", err)
+		http.Error(w, "Failed to fetch trash", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	retentionDays := trashRetentionDays()
+	items := []TrashItem{}
+	for rows.Next() {
+		var item TrashItem
+		if err := rows.Scan(&item.Type, &item.ID, &item.Name, &item.DeletedAt, &item.DeletedBy); err != nil {
+			log.Printf("Failed to scan trash item: %v", err)
+			continue
+		}
+		item.PurgeAt = item.DeletedAt.AddDate(0, 0, retentionDays)
+		items = append(items, item)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"items":         items,
+		"retentionDays": retentionDays,
+	})
+}
+
+// HandleRestoreTrash takes a game or group back out of the trash
+func HandleRestoreTrash(w http.ResponseWriter, r *http.Request) {
+	managerEmail, ok := getManagerEmail(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	vars := mux.Vars(r)
+	table, ok := trashTables[vars["type"]]
+	if !ok {
+		http.Error(w, "Unknown trash item type", http.StatusBadRequest)
+		return
+	}
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	result, err := db.Exec(`
+		UPDATE `+table+` SET deleted_at = NULL, deleted_by = NULL
+		WHERE id = $1 AND manager_email = $2 AND deleted_at IS NOT NULL
+	`, id, managerEmail)
+	if err != nil {
+		log.Printf("Failed to restore %s %d: %v", vars["type"], id, err)
+		http.Error(w, "Failed to restore", http.StatusInternalServerError)
+		return
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		http.Error(w, "Not found in trash", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// runTrashPurge permanently removes trash older than the retention period,
+// on startup and then hourly. While any venue is open the purge waits, so
+// the large deletes don't compete with players.
+func runTrashPurge(openingHours *hours.Hours) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		if !openingHours.Peak(time.Now()) {
+			purgeTrash()
+		}
+		<-ticker.C
+	}
+}
+
+func purgeTrash() {
+	cutoff := time.Now().AddDate(0, 0, -trashRetentionDays())
+
+	// Games first (participants, rounds and picks cascade); a group still
+	// referenced by a game stays until that game is gone
+	result, err := db.Exec(`DELETE FROM managed_games WHERE deleted_at < $1`, cutoff)
+	if err != nil {
+		log.Printf("Failed to purge games from trash: %v", err)
+	} else if n, _ := result.RowsAffected(); n > 0 {
+		log.Printf("🗑️ Purged %d games from trash", n)
+	}
+
+	result, err = db.Exec(`
+		DELETE FROM managed_groups g
+		WHERE g.deleted_at < $1
+		AND NOT EXISTS (SELECT 1 FROM managed_games WHERE group_id = g.id)
+	`, cutoff)
+	if err != nil {
+		log.Printf("Failed to purge groups from trash: %v", err)
+	} else if n, _ := result.RowsAffected(); n > 0 {
+		log.Printf("🗑️ Purged %d groups from trash", n)
+	}
+}
@@ -0,0 +1,9 @@
+-- Migration: Trash for deleted games and groups
+-- Date: 2026-10-17
+-- Purpose: Deleting a game or group now sets deleted_at instead of removing it,
+-- so it can be restored; rows are purged after TRASH_RETENTION_DAYS (default 30)
+
+ALTER TABLE managed_groups ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE managed_groups ADD COLUMN IF NOT EXISTS deleted_by TEXT;
+ALTER TABLE managed_games ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE managed_games ADD COLUMN IF NOT EXISTS deleted_by TEXT;
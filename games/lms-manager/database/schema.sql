@@ -13,11 +13,15 @@ DROP TABLE IF EXISTS managed_groups CASCADE;
 
 -- Groups contain teams (e.g., "Premier League 25/26")
 -- Manager creates groups and populates with teams manually
+-- deleted_at/deleted_by: set when deleted; the group sits in the trash until
+-- restored or purged after TRASH_RETENTION_DAYS
 CREATE TABLE managed_groups (
   id SERIAL PRIMARY KEY,
   manager_email TEXT NOT NULL,
   name TEXT NOT NULL,
-  created_at TIMESTAMP DEFAULT NOW()
+  created_at TIMESTAMP DEFAULT NOW(),
+  deleted_at TIMESTAMP,
+  deleted_by TEXT
 );
 
 -- Teams belong to a group
@@ -46,6 +50,7 @@ CREATE TABLE managed_players (
 -- winner_mode: 'single' = only 1 winner, 'multiple' = allow multiple winners
 -- rollover_mode: 'round' = un-eliminate current round only, 'game' = void entire game and restart
 -- max_winners: maximum winners allowed (only applies when winner_mode = 'multiple')
+-- deleted_at/deleted_by: trash, as for groups
 CREATE TABLE managed_games (
   id SERIAL PRIMARY KEY,
   manager_email TEXT NOT NULL,
@@ -57,7 +62,9 @@ CREATE TABLE managed_games (
   winner_mode TEXT DEFAULT 'single', -- 'single' or 'multiple'
   rollover_mode TEXT DEFAULT 'round', -- 'round' or 'game'
   max_winners INTEGER DEFAULT 4,
-  created_at TIMESTAMP DEFAULT NOW()
+  created_at TIMESTAMP DEFAULT NOW(),
+  deleted_at TIMESTAMP,
+  deleted_by TEXT
 );
 
 -- Participants in a game
@@ -9,7 +9,7 @@ function useQueryParams() {
     return {
       userId: params.get('userId'),
       userName: params.get('userName') || 'Unknown',
-      token: params.get('token'),
+      token: params.get('token') || sessionStorage.getItem('token'),
     };
   }, []);
 }
@@ -82,6 +82,15 @@ interface Pick {
   createdAt: string;
 }
 
+interface TrashItem {
+  type: 'game' | 'group';
+  id: number;
+  name: string;
+  deletedAt: string;
+  deletedBy: string;
+  purgeAt: string;
+}
+
 interface GameDetail {
   game: Game;
   participants: Participant[];
@@ -90,7 +99,7 @@ interface GameDetail {
 
 function App() {
   const { userId, token } = useQueryParams();
-  const [activeTab, setActiveTab] = useState<'setup' | 'games' | 'reports'>('games');
+  const [activeTab, setActiveTab] = useState<'setup' | 'games' | 'reports' | 'trash'>('games');
   const [selectedGameId, setSelectedGameId] = useState<number | null>(null);
 
   // Report state
@@ -152,6 +161,10 @@ function App() {
 
   const [loading, setLoading] = useState(true);
 
+  // Trash
+  const [trashItems, setTrashItems] = useState<TrashItem[]>([]);
+  const [trashRetentionDays, setTrashRetentionDays] = useState(30);
+
   const toggleCard = (cardName: string) => {
     setCollapsedCards({
       ...collapsedCards,
@@ -245,6 +258,25 @@ function App() {
     fetchGames();
   }, [token, activeTab]);
 
+  // Fetch trash when the Trash tab is active
+  const fetchTrash = async () => {
+    try {
+      const res = await fetch(`${API_BASE}/api/trash`, {
+        headers: { Authorization: `Bearer ${token}` },
+      });
+      const data = await res.json();
+      setTrashItems(data.items || []);
+      setTrashRetentionDays(data.retentionDays || 30);
+    } catch (err) {
+      console.error('Failed to fetch trash:', err);
+    }
+  };
+
+  useEffect(() => {
+    if (!token || activeTab !== 'trash') return;
+    fetchTrash();
+  }, [token, activeTab]);
+
   // Fetch game details when a game is selected
   useEffect(() => {
     if (!token || !selectedGameId) return;
@@ -487,7 +519,7 @@ function App() {
   };
 
   const handleDeleteGroup = async (groupId: number) => {
-    if (!window.confirm('Delete this group? This will also delete all its teams.')) return;
+    if (!window.confirm('Move this group to the trash? It can be restored from the Trash tab.')) return;
 
     try {
       const res = await fetch(`${API_BASE}/api/groups/${groupId}`, {
@@ -960,6 +992,59 @@ function App() {
     }
   };
 
+  // Preview the round with the results entered so far, without saving them
+  const handleSimulateRound = async () => {
+    if (!gameDetail || !token) return;
+
+    const latestRound = gameDetail.rounds[gameDetail.rounds.length - 1];
+    if (!latestRound) return;
+
+    const results = Object.entries(pickResults).map(([pickId, result]) => ({
+      pickId: Number(pickId),
+      result,
+    }));
+
+    try {
+      const res = await fetch(`${API_BASE}/api/rounds/${latestRound.id}/simulate`, {
+        method: 'POST',
+        headers: {
+          'Content-Type': 'application/json',
+          Authorization: `Bearer ${token}`,
+        },
+        body: JSON.stringify({ results }),
+      });
+      if (!res.ok) {
+        const error = await res.text();
+        alert(`Failed to simulate round: ${error}`);
+        return;
+      }
+
+      const sim = await res.json();
+      const lines = [
+        `Survivors: ${sim.survivors.length}${sim.undecided > 0 ? ` (${sim.undecided} without a result yet)` : ''}`,
+        `Eliminated: ${sim.eliminated.length}${sim.eliminated.length > 0 ? ` - ${sim.eliminated.map((p: { playerName: string }) => p.playerName).join(', ')}` : ''}`,
+        '',
+      ];
+      switch (sim.outcome) {
+        case 'completed':
+          lines.push(sim.winners?.length ? `Game over - pot goes to ${sim.winners.join(', ')}` : 'Game over - no winners');
+          break;
+        case 'rollover_round':
+          lines.push(`Everyone out - round rolls over, eliminated players carry on into round ${sim.nextRound}`);
+          break;
+        case 'rollover_game':
+          lines.push('Everyone out - game rolls over and restarts from round 1');
+          break;
+        default:
+          lines.push(`Game continues to round ${sim.nextRound}`);
+      }
+      alert(`What if (round ${sim.roundNumber}) - nothing has been saved\n\n${lines.join('\n')}`);
+    } catch (err) {
+      console.error('Failed to simulate round:', err);
+      alert('Failed to simulate round');
+    }
+  };
+
   const handleCloseRound = async () => {
     if (!gameDetail || !token) return;
 
@@ -1120,7 +1205,7 @@ function App() {
   const handleDeleteGame = async () => {
     if (!gameDetail || !token || !selectedGameId) return;
 
-    if (!window.confirm(`Are you sure you want to delete "${gameDetail.game.name}"? This action cannot be undone.`)) {
+    if (!window.confirm(`Move "${gameDetail.game.name}" to the trash? It can be restored from the Trash tab for ${trashRetentionDays} days.`)) {
       return;
     }
 
@@ -1131,7 +1216,7 @@ function App() {
       });
 
       if (res.ok || res.status === 204) {
-        alert('Game deleted successfully');
+        alert('Game moved to the trash');
         // Refresh games list
         const gamesRes = await fetch(`${API_BASE}/api/games`, {
           headers: { Authorization: `Bearer ${token}` },
@@ -1150,6 +1235,29 @@ function App() {
     }
   };
 
+  const handleRestore = async (item: TrashItem) => {
+    try {
+      const res = await fetch(`${API_BASE}/api/trash/${item.type}/${item.id}/restore`, {
+        method: 'POST',
+        headers: { Authorization: `Bearer ${token}` },
+      });
+      if (!res.ok) {
+        alert(`Failed to restore: ${await res.text()}`);
+        return;
+      }
+      setTrashItems(trashItems.filter((t) => !(t.type === item.type && t.id === item.id)));
+      if (item.type === 'group') {
+        const groupsRes = await fetch(`${API_BASE}/api/groups`, {
+          headers: { Authorization: `Bearer ${token}` },
+        });
+        const groupsData = await groupsRes.json();
+        setGroups(groupsData.groups || []);
+      }
+    } catch (err) {
+      console.error('Failed to restore:', err);
+    }
+  };
+
   return (
     <>
       <header className="ah-app-header">
@@ -1189,6 +1297,12 @@ function App() {
           >
             Reports
           </button>
+          <button
+            className={`ah-tab ${activeTab === 'trash' ? 'active' : ''}`}
+            onClick={() => setActiveTab('trash')}
+          >
+            Trash
+          </button>
         </div>
 
         {/* Setup Tab */}
@@ -2024,6 +2138,12 @@ function App() {
                                   >
                                     Save Results
                                   </button>
+                                  <button
+                                    className="ah-btn-outline"
+                                    onClick={handleSimulateRound}
+                                  >
+                                    What If?
+                                  </button>
                                   <button
                                     className="ah-btn-primary"
                                     onClick={handleCloseRound}
@@ -2214,6 +2334,36 @@ function App() {
             )}
           </div>
         )}
+
+        {/* Trash Tab */}
+        {activeTab === 'trash' && (
+          <div className="ah-card">
+            <h3 className="ah-section-title">Trash</h3>
+            <p className="ah-meta">
+              Deleted games and groups are kept for {trashRetentionDays} days, then removed permanently.
+            </p>
+
+            <div className="ah-list mt-4">
+              {trashItems.length === 0 && <p className="ah-meta">The trash is empty.</p>}
+
+              {trashItems.map((item) => (
+                <div key={`${item.type}-${item.id}`} className="ah-list-item">
+                  <div>
+                    <strong>{item.type === 'game' ? 'Game' : 'Group'}: {item.name}</strong>
+                    <p className="ah-meta">
+                      Deleted {new Date(item.deletedAt).toLocaleString()}
+                      {item.deletedBy && ` by ${item.deletedBy}`}
+                      {' · '}purged after {new Date(item.purgeAt).toLocaleDateString()}
+                    </p>
+                  </div>
+                  <button className="ah-btn-primary" onClick={() => handleRestore(item)}>
+                    Restore
+                  </button>
+                </div>
+              ))}
+            </div>
+          </div>
+        )}
       </div>
     </>
   );
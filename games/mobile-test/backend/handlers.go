@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"net/http"
 	"time"
+
+	apphttp "github.com/achgithub/activity-hub-common/http"
 )
 
 func handlePing(w http.ResponseWriter, r *http.Request) {
@@ -16,11 +18,16 @@ func handlePing(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// handleHealth answers the shell's status dashboard probe
+func handleHealth(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"status":"ok","service":"mobile-test"}`))
+}
+
 func handleTestSSE(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
 	w.Header().Set("Connection", "keep-alive")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
 
 	flusher, ok := w.(http.Flusher)
 	if !ok {
@@ -40,10 +47,12 @@ func handleTestSSE(w http.ResponseWriter, r *http.Request) {
 }
 
 func handleConfig(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"appName": "Mobile Test",
-		"port":    4061,
+	apphttp.WriteConfig(w, apphttp.AppConfig{
+		AppID:        "mobile-test",
+		Name:         "Mobile Test",
+		Icon:         "📱",
+		Description:  "Verify media works on your device",
+		Capabilities: apphttp.Capabilities{SSE: true},
 	})
 }
 
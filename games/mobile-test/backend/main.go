@@ -6,18 +6,37 @@ import (
 	"net/http"
 
 	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/achgithub/activity-hub-common/cli"
 	"github.com/achgithub/activity-hub-common/config"
 	"github.com/achgithub/activity-hub-common/database"
-	"github.com/gorilla/handlers"
+	apphttp "github.com/achgithub/activity-hub-common/http"
+	"github.com/achgithub/activity-hub-common/maintenance"
+	"github.com/achgithub/activity-hub-common/upload"
 	"github.com/gorilla/mux"
 )
 
 var (
+	db         *sql.DB // mobile_test_db: network probe results
 	quizDB     *sql.DB
 	identityDB *sql.DB
 )
 
 func main() {
+	cli.New("mobile-test",
+		cli.Serve(serve),
+		cli.Migrate(openAppDB, cli.Migrations{
+			Dir:    "../database",
+			Schema: []string{"schema.sql"},
+		}),
+	).Main()
+}
+
+// openAppDB connects to mobile_test_db for commands other than serve
+func openAppDB() (*sql.DB, error) {
+	return database.InitDatabase("mobile_test")
+}
+
+func serve() {
 	var err error
 	identityDB, err = database.InitIdentityDatabase()
 	if err != nil {
@@ -31,19 +50,35 @@ func main() {
 	}
 	defer quizDB.Close()
 
+	db, err = database.InitDatabase("mobile_test")
+	if err != nil {
+		log.Fatal("Failed to connect to app database:", err)
+	}
+	defer db.Close()
+
 	r := mux.NewRouter()
 
 	r.HandleFunc("/api/config", handleConfig).Methods("GET")
 	r.HandleFunc("/api/ping", handlePing).Methods("GET")
+	r.HandleFunc("/api/health", handleHealth).Methods("GET")
 	r.HandleFunc("/api/test-sse", handleTestSSE).Methods("GET")
 
+	// Network quality probes (unauthenticated so they measure the network, not auth)
+	r.HandleFunc("/api/probe/echo", handleProbeEcho).Methods("GET")
+	r.HandleFunc("/api/probe/sse", handleProbeSSE).Methods("GET")
+
 	// Auth required but any user can access
 	api := r.PathPrefix("/api").Subrouter()
 	api.Use(authlib.Middleware(identityDB))
 	api.HandleFunc("/test-content", handleGetTestContent).Methods("GET")
+	api.HandleFunc("/probe/simulation", handleGetSimulation).Methods("GET")
+	api.HandleFunc("/probe/simulation", handleUpdateSimulation).Methods("PUT")
+	api.HandleFunc("/probe/results", handleSaveProbeResult).Methods("POST")
+	api.HandleFunc("/probe/results", handleGetProbeResults).Methods("GET")
+	api.HandleFunc("/probe/summary", handleGetProbeSummary).Methods("GET")
 
 	// Serve media from shared uploads directory
-	r.PathPrefix("/uploads/").Handler(http.StripPrefix("/uploads/", http.FileServer(http.Dir("./uploads"))))
+	r.PathPrefix("/uploads/").Handler(http.StripPrefix("/uploads/", upload.FileServer("./uploads")))
 
 	// Serve React frontend
 	r.PathPrefix("/static/").Handler(http.FileServer(http.Dir("./static")))
@@ -51,13 +86,10 @@ func main() {
 		http.ServeFile(w, r, "./static/index.html")
 	})
 
-	corsHandler := handlers.CORS(
-		handlers.AllowedOrigins([]string{"*"}),
-		handlers.AllowedMethods([]string{"GET", "OPTIONS"}),
-		handlers.AllowedHeaders([]string{"Content-Type", "Authorization"}),
-	)
+	cors := apphttp.NewCORSPolicy(identityDB)
+	appMaintenance := maintenance.New(identityDB)
 
 	port := config.GetEnv("PORT", "4061")
 	log.Printf("Mobile Test starting on :%s", port)
-	log.Fatal(http.ListenAndServe(":"+port, corsHandler(r)))
+	log.Fatal(http.ListenAndServe(":"+port, cors.Middleware(appMaintenance.Middleware("mobile-test")(apphttp.Versioned(r)))))
 }
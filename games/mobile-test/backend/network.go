@@ -0,0 +1,372 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	authlib "github.com/achgithub/activity-hub-common/auth"
+)
+
+// Quality thresholds for a device to be trusted with a live quiz.
+// Quiz answers are small HTTP posts and questions arrive over SSE, so
+// loss and SSE delay matter more than raw bandwidth.
+const (
+	goodLossPercent = 1.0
+	poorLossPercent = 5.0
+	goodRTTP95Ms    = 150
+	poorRTTP95Ms    = 500
+	goodSSEDelayMs  = 300
+	poorSSEDelayMs  = 1000
+
+	maxEchoPadding = 64 * 1024
+	maxSSEEvents   = 100
+)
+
+// simulation degrades probe traffic so operators can see what a poor network looks like
+type simulation struct {
+	mu           sync.RWMutex
+	DropPercent  int
+	ExtraDelayMs int
+}
+
+var probeSim = &simulation{}
+
+// degrade applies the current simulation. Returns true if this message should be dropped.
+func (s *simulation) degrade() bool {
+	s.mu.RLock()
+	drop, delay := s.DropPercent, s.ExtraDelayMs
+	s.mu.RUnlock()
+
+	if delay > 0 {
+		time.Sleep(time.Duration(delay) * time.Millisecond)
+	}
+	return drop > 0 && rand.Intn(100) < drop
+}
+
+// isOperator reports whether the user may change simulation settings and see every device
+func isOperator(user *authlib.AuthUser) bool {
+	return user.HasRole("quiz_master") || user.HasRole("game_admin") || user.HasRole("super_user")
+}
+
+// handleProbeEcho - GET /api/probe/echo?seq=N&pad=bytes
+// Round-trip probe. Dropped requests have their connection closed without a
+// response, which the client sees the same way as a lost packet.
+func handleProbeEcho(w http.ResponseWriter, r *http.Request) {
+	if probeSim.degrade() {
+		if hj, ok := w.(http.Hijacker); ok {
+			if conn, _, err := hj.Hijack(); err == nil {
+				conn.Close()
+				return
+			}
+		}
+		http.Error(w, "Simulated packet loss", http.StatusServiceUnavailable)
+		return
+	}
+
+	seq, _ := strconv.Atoi(r.URL.Query().Get("seq"))
+	pad, _ := strconv.Atoi(r.URL.Query().Get("pad"))
+	if pad < 0 {
+		pad = 0
+	} else if pad > maxEchoPadding {
+		pad = maxEchoPadding
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"seq":        seq,
+		"serverTime": time.Now().UnixMilli(),
+		"padding":    strings.Repeat("x", pad),
+	})
+}
+
+// handleProbeSSE - GET /api/probe/sse?count=N&interval=ms
+// Sends numbered events stamped with the server send time so the client can
+// measure delivery delay and spot gaps from dropped events.
+func handleProbeSSE(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "SSE not supported", http.StatusInternalServerError)
+		return
+	}
+
+	count, _ := strconv.Atoi(r.URL.Query().Get("count"))
+	if count <= 0 || count > maxSSEEvents {
+		count = 20
+	}
+	interval, _ := strconv.Atoi(r.URL.Query().Get("interval"))
+	if interval < 50 || interval > 5000 {
+		interval = 250
+	}
+
+	for seq := 1; seq <= count; seq++ {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-time.After(time.Duration(interval) * time.Millisecond):
+		}
+
+		if probeSim.degrade() {
+			continue
+		}
+
+		data, _ := json.Marshal(map[string]int64{"seq": int64(seq), "total": int64(count), "sentAt": time.Now().UnixMilli()})
+		fmt.Fprintf(w, "event: probe\ndata: %s\n\n", data)
+		flusher.Flush()
+	}
+
+	fmt.Fprintf(w, "event: done\ndata: {\"total\":%d}\n\n", count)
+	flusher.Flush()
+}
+
+// handleGetSimulation - GET /api/probe/simulation
+func handleGetSimulation(w http.ResponseWriter, r *http.Request) {
+	user, _ := authlib.GetUserFromContext(r.Context())
+
+	probeSim.mu.RLock()
+	drop, delay := probeSim.DropPercent, probeSim.ExtraDelayMs
+	probeSim.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"dropPercent":  drop,
+		"extraDelayMs": delay,
+		"canEdit":      user != nil && isOperator(user),
+	})
+}
+
+// handleUpdateSimulation - PUT /api/probe/simulation  {dropPercent, extraDelayMs}
+// Affects only probe endpoints, never real game traffic
+func handleUpdateSimulation(w http.ResponseWriter, r *http.Request) {
+	user, ok := authlib.GetUserFromContext(r.Context())
+	if !ok || !isOperator(user) {
+		http.Error(w, `{"error":"quiz_master role required"}`, http.StatusForbidden)
+		return
+	}
+
+	var req struct {
+		DropPercent  int `json:"dropPercent"`
+		ExtraDelayMs int `json:"extraDelayMs"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error":"invalid request"}`, http.StatusBadRequest)
+		return
+	}
+	if req.DropPercent < 0 || req.DropPercent > 90 || req.ExtraDelayMs < 0 || req.ExtraDelayMs > 5000 {
+		http.Error(w, `{"error":"dropPercent must be 0-90 and extraDelayMs 0-5000"}`, http.StatusBadRequest)
+		return
+	}
+
+	probeSim.mu.Lock()
+	probeSim.DropPercent = req.DropPercent
+	probeSim.ExtraDelayMs = req.ExtraDelayMs
+	probeSim.mu.Unlock()
+
+	log.Printf("📶 Probe simulation set by %s: drop=%d%% delay=%dms", user.ActorEmail(), req.DropPercent, req.ExtraDelayMs)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"dropPercent":  req.DropPercent,
+		"extraDelayMs": req.ExtraDelayMs,
+		"canEdit":      true,
+	})
+}
+
+// ProbeResult is one completed network probe from a device
+type ProbeResult struct {
+	ID            int       `json:"id"`
+	DeviceID      string    `json:"deviceId"`
+	DeviceLabel   string    `json:"deviceLabel"`
+	UserEmail     string    `json:"userEmail"`
+	UserAgent     string    `json:"userAgent"`
+	Samples       int       `json:"samples"`
+	Lost          int       `json:"lost"`
+	RTTMinMs      int       `json:"rttMinMs"`
+	RTTAvgMs      int       `json:"rttAvgMs"`
+	RTTP95Ms      int       `json:"rttP95Ms"`
+	RTTMaxMs      int       `json:"rttMaxMs"`
+	JitterMs      int       `json:"jitterMs"`
+	SSEExpected   int       `json:"sseExpected"`
+	SSEReceived   int       `json:"sseReceived"`
+	SSEDelayAvgMs int       `json:"sseDelayAvgMs"`
+	SSEDelayMaxMs int       `json:"sseDelayMaxMs"`
+	Simulated     bool      `json:"simulated"`
+	Rating        string    `json:"rating"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+// rateProbe grades a result as good, fair or poor using the worst of its metrics
+func rateProbe(p ProbeResult) string {
+	loss := 0.0
+	if total := p.Samples + p.SSEExpected; total > 0 {
+		loss = float64(p.Lost+(p.SSEExpected-p.SSEReceived)) * 100 / float64(total)
+	}
+
+	switch {
+	case loss >= poorLossPercent || p.RTTP95Ms >= poorRTTP95Ms || p.SSEDelayAvgMs >= poorSSEDelayMs:
+		return "poor"
+	case loss > goodLossPercent || p.RTTP95Ms > goodRTTP95Ms || p.SSEDelayAvgMs > goodSSEDelayMs:
+		return "fair"
+	default:
+		return "good"
+	}
+}
+
+// handleSaveProbeResult - POST /api/probe/results
+func handleSaveProbeResult(w http.ResponseWriter, r *http.Request) {
+	user, ok := authlib.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+		return
+	}
+
+	var p ProbeResult
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil || p.DeviceID == "" || p.Samples <= 0 {
+		http.Error(w, `{"error":"deviceId and samples are required"}`, http.StatusBadRequest)
+		return
+	}
+	if p.Lost < 0 || p.Lost > p.Samples || p.SSEReceived < 0 || p.SSEReceived > p.SSEExpected {
+		http.Error(w, `{"error":"inconsistent counts"}`, http.StatusBadRequest)
+		return
+	}
+
+	if len(p.DeviceID) > 64 {
+		http.Error(w, `{"error":"deviceId too long"}`, http.StatusBadRequest)
+		return
+	}
+
+	// Results taken while a simulation is active never count towards the venue summary
+	probeSim.mu.RLock()
+	if probeSim.DropPercent > 0 || probeSim.ExtraDelayMs > 0 {
+		p.Simulated = true
+	}
+	probeSim.mu.RUnlock()
+
+	if len(p.DeviceLabel) > 100 {
+		p.DeviceLabel = p.DeviceLabel[:100]
+	}
+	p.UserEmail = user.Email
+	p.UserAgent = r.UserAgent()
+	p.Rating = rateProbe(p)
+
+	err := db.QueryRow(`
+		INSERT INTO probe_results (device_id, device_label, user_email, user_agent,
+			samples, lost, rtt_min_ms, rtt_avg_ms, rtt_p95_ms, rtt_max_ms, jitter_ms,
+			sse_expected, sse_received, sse_delay_avg_ms, sse_delay_max_ms, simulated, rating)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+		RETURNING id, created_at
+	`, p.DeviceID, p.DeviceLabel, p.UserEmail, p.UserAgent,
+		p.Samples, p.Lost, p.RTTMinMs, p.RTTAvgMs, p.RTTP95Ms, p.RTTMaxMs, p.JitterMs,
+		p.SSEExpected, p.SSEReceived, p.SSEDelayAvgMs, p.SSEDelayMaxMs, p.Simulated, p.Rating,
+	).Scan(&p.ID, &p.CreatedAt)
+	if err != nil {
+		log.Printf("Failed to save probe result: %v", err)
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p)
+}
+
+const probeResultColumns = `id, device_id, COALESCE(device_label, ''), user_email, COALESCE(user_agent, ''),
+	samples, lost, rtt_min_ms, rtt_avg_ms, rtt_p95_ms, rtt_max_ms, jitter_ms,
+	sse_expected, sse_received, sse_delay_avg_ms, sse_delay_max_ms, simulated, rating, created_at`
+
+func scanProbeResults(rows *sql.Rows) []ProbeResult {
+	results := []ProbeResult{}
+	for rows.Next() {
+		var p ProbeResult
+		if err := rows.Scan(&p.ID, &p.DeviceID, &p.DeviceLabel, &p.UserEmail, &p.UserAgent,
+			&p.Samples, &p.Lost, &p.RTTMinMs, &p.RTTAvgMs, &p.RTTP95Ms, &p.RTTMaxMs, &p.JitterMs,
+			&p.SSEExpected, &p.SSEReceived, &p.SSEDelayAvgMs, &p.SSEDelayMaxMs, &p.Simulated, &p.Rating, &p.CreatedAt); err != nil {
+			continue
+		}
+		results = append(results, p)
+	}
+	return results
+}
+
+// handleGetProbeResults - GET /api/probe/results?deviceId=...
+// Players see their own history; operators can see any device
+func handleGetProbeResults(w http.ResponseWriter, r *http.Request) {
+	user, ok := authlib.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+		return
+	}
+
+	deviceID := r.URL.Query().Get("deviceId")
+	query := "SELECT " + probeResultColumns + " FROM probe_results WHERE ($1 = '' OR device_id = $1)"
+	args := []interface{}{deviceID}
+	if !isOperator(user) {
+		query += " AND user_email = $2"
+		args = append(args, user.Email)
+	}
+	query += " ORDER BY created_at DESC LIMIT 50"
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"results": scanProbeResults(rows),
+	})
+}
+
+// handleGetProbeSummary - GET /api/probe/summary?hours=3
+// Latest real (non-simulated) result per device, for the operator's "is the Wi-Fi ready?" check
+func handleGetProbeSummary(w http.ResponseWriter, r *http.Request) {
+	user, ok := authlib.GetUserFromContext(r.Context())
+	if !ok || !isOperator(user) {
+		http.Error(w, `{"error":"quiz_master role required"}`, http.StatusForbidden)
+		return
+	}
+
+	hours, _ := strconv.Atoi(r.URL.Query().Get("hours"))
+	if hours <= 0 || hours > 72 {
+		hours = 3
+	}
+
+	rows, err := db.Query(`
+		SELECT DISTINCT ON (device_id) `+probeResultColumns+`
+		FROM probe_results
+		WHERE NOT simulated AND created_at > NOW() - make_interval(hours => $1)
+		ORDER BY device_id, created_at DESC
+	`, hours)
+	if err != nil {
+		log.Printf("Failed to load probe summary: %v", err)
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	devices := scanProbeResults(rows)
+	counts := map[string]int{"good": 0, "fair": 0, "poor": 0}
+	for _, d := range devices {
+		counts[d.Rating]++
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"hours":   hours,
+		"devices": devices,
+		"counts":  counts,
+	})
+}
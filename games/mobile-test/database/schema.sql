@@ -0,0 +1,33 @@
+-- Mobile Test Database Schema
+-- Database: mobile_test_db
+-- Purpose: Network quality probe results per device
+
+CREATE TABLE IF NOT EXISTS probe_results (
+    id SERIAL PRIMARY KEY,
+    device_id VARCHAR(64) NOT NULL,
+    device_label VARCHAR(100),
+    user_email VARCHAR(255) NOT NULL,
+    user_agent TEXT,
+    samples INTEGER NOT NULL,
+    lost INTEGER NOT NULL DEFAULT 0,
+    rtt_min_ms INTEGER NOT NULL DEFAULT 0,
+    rtt_avg_ms INTEGER NOT NULL DEFAULT 0,
+    rtt_p95_ms INTEGER NOT NULL DEFAULT 0,
+    rtt_max_ms INTEGER NOT NULL DEFAULT 0,
+    jitter_ms INTEGER NOT NULL DEFAULT 0,
+    sse_expected INTEGER NOT NULL DEFAULT 0,
+    sse_received INTEGER NOT NULL DEFAULT 0,
+    sse_delay_avg_ms INTEGER NOT NULL DEFAULT 0,
+    sse_delay_max_ms INTEGER NOT NULL DEFAULT 0,
+    simulated BOOLEAN NOT NULL DEFAULT FALSE,
+    rating VARCHAR(10) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_probe_results_device ON probe_results(device_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_probe_results_created_at ON probe_results(created_at DESC);
+
+-- Note: Run this schema after creating the database:
+-- CREATE DATABASE mobile_test_db;
+-- \c mobile_test_db
+-- \i games/mobile-test/database/schema.sql
@@ -1,4 +1,5 @@
 import React, { useState, useRef, useMemo } from 'react';
+import NetworkProbe from './NetworkProbe';
 
 // --- Types ---
 
@@ -30,7 +31,7 @@ const INITIAL_STEPS: Step[] = [
 
 function App() {
   const params = useMemo(() => new URLSearchParams(window.location.search), []);
-  const token = params.get('token') || '';
+  const token = params.get('token') || sessionStorage.getItem('token') || '';
   const userId = params.get('userId') || '';
 
   const [runnerState, setRunnerState] = useState<RunnerState>('idle');
@@ -349,6 +350,10 @@ function App() {
           Run Again
         </button>
       )}
+
+      {/* Network quality probe */}
+      <div style={{ height: 24 }} />
+      <NetworkProbe token={token} />
       </div>
     </>
   );
@@ -0,0 +1,424 @@
+import React, { useState, useEffect, useCallback } from 'react';
+
+// --- Types ---
+
+interface ProbeResult {
+  id?: number;
+  deviceId: string;
+  deviceLabel: string;
+  userEmail?: string;
+  samples: number;
+  lost: number;
+  rttMinMs: number;
+  rttAvgMs: number;
+  rttP95Ms: number;
+  rttMaxMs: number;
+  jitterMs: number;
+  sseExpected: number;
+  sseReceived: number;
+  sseDelayAvgMs: number;
+  sseDelayMaxMs: number;
+  simulated: boolean;
+  rating?: 'good' | 'fair' | 'poor';
+  createdAt?: string;
+}
+
+interface Simulation {
+  dropPercent: number;
+  extraDelayMs: number;
+  canEdit: boolean;
+}
+
+interface Summary {
+  devices: ProbeResult[];
+  counts: { good: number; fair: number; poor: number };
+}
+
+const ECHO_SAMPLES = 30;
+const ECHO_TIMEOUT_MS = 2000;
+const SSE_EVENTS = 20;
+const SSE_INTERVAL_MS = 250;
+
+const RATING_COLOR: Record<string, string> = {
+  good: '#2E7D32',
+  fair: '#E65100',
+  poor: '#C62828',
+};
+
+// Stable per-browser ID so results can be grouped by device
+function getDeviceId(): string {
+  let id = localStorage.getItem('mobileTestDeviceId');
+  if (!id) {
+    id = `${Date.now().toString(36)}-${Math.random().toString(36).slice(2, 10)}`;
+    localStorage.setItem('mobileTestDeviceId', id);
+  }
+  return id;
+}
+
+function percentile(sorted: number[], p: number): number {
+  if (sorted.length === 0) return 0;
+  const idx = Math.min(sorted.length - 1, Math.ceil((p / 100) * sorted.length) - 1);
+  return sorted[Math.max(0, idx)];
+}
+
+// --- Component ---
+
+function NetworkProbe({ token }: { token: string }) {
+  const authHeaders = { Authorization: `Bearer ${token}`, 'Content-Type': 'application/json' };
+  const deviceId = getDeviceId();
+
+  const [deviceLabel, setDeviceLabel] = useState(localStorage.getItem('mobileTestDeviceLabel') || '');
+  const [running, setRunning] = useState(false);
+  const [progress, setProgress] = useState('');
+  const [latest, setLatest] = useState<ProbeResult | null>(null);
+  const [history, setHistory] = useState<ProbeResult[]>([]);
+  const [sim, setSim] = useState<Simulation>({ dropPercent: 0, extraDelayMs: 0, canEdit: false });
+  const [summary, setSummary] = useState<Summary | null>(null);
+
+  const loadHistory = useCallback(() => {
+    fetch(`/api/probe/results?deviceId=${encodeURIComponent(deviceId)}`, {
+      headers: { Authorization: `Bearer ${token}` },
+    })
+      .then(res => res.json())
+      .then(data => setHistory(data.results || []))
+      .catch(() => {});
+  }, [token, deviceId]);
+
+  const loadSummary = useCallback(() => {
+    fetch('/api/probe/summary', { headers: { Authorization: `Bearer ${token}` } })
+      .then(res => (res.ok ? res.json() : null))
+      .then(data => data && setSummary(data))
+      .catch(() => {});
+  }, [token]);
+
+  useEffect(() => {
+    loadHistory();
+    fetch('/api/probe/simulation', { headers: { Authorization: `Bearer ${token}` } })
+      .then(res => res.json())
+      .then((data: Simulation) => {
+        setSim(data);
+        if (data.canEdit) loadSummary();
+      })
+      .catch(() => {});
+  }, [token, loadHistory, loadSummary]);
+
+  // Round-trip probe: sequential requests so each sample measures one exchange
+  const runEcho = async () => {
+    const rtts: number[] = [];
+    let lost = 0;
+    let offsetSum = 0;
+
+    for (let seq = 1; seq <= ECHO_SAMPLES; seq++) {
+      setProgress(`Latency ${seq}/${ECHO_SAMPLES}`);
+      const controller = new AbortController();
+      const timeoutId = setTimeout(() => controller.abort(), ECHO_TIMEOUT_MS);
+      const t0 = Date.now();
+      try {
+        const res = await fetch(`/api/probe/echo?seq=${seq}&pad=512&t=${t0}`, { signal: controller.signal });
+        if (!res.ok) throw new Error(`HTTP ${res.status}`);
+        const data = await res.json();
+        const t1 = Date.now();
+        rtts.push(t1 - t0);
+        // Server clock offset estimate, used to correct SSE delivery delay
+        offsetSum += data.serverTime - (t0 + t1) / 2;
+      } catch {
+        lost++;
+      } finally {
+        clearTimeout(timeoutId);
+      }
+    }
+
+    const sorted = [...rtts].sort((a, b) => a - b);
+    const avg = rtts.length ? rtts.reduce((a, b) => a + b, 0) / rtts.length : 0;
+    let jitter = 0;
+    for (let i = 1; i < rtts.length; i++) jitter += Math.abs(rtts[i] - rtts[i - 1]);
+
+    return {
+      lost,
+      clockOffset: rtts.length ? offsetSum / rtts.length : 0,
+      rttMinMs: sorted[0] || 0,
+      rttAvgMs: Math.round(avg),
+      rttP95Ms: percentile(sorted, 95),
+      rttMaxMs: sorted[sorted.length - 1] || 0,
+      jitterMs: rtts.length > 1 ? Math.round(jitter / (rtts.length - 1)) : 0,
+    };
+  };
+
+  // SSE delivery probe: count events and measure send→receive delay
+  const runSSE = (clockOffset: number) =>
+    new Promise<{ received: number; delayAvg: number; delayMax: number }>(resolve => {
+      const es = new EventSource(`/api/probe/sse?count=${SSE_EVENTS}&interval=${SSE_INTERVAL_MS}`);
+      const delays: number[] = [];
+
+      const finish = () => {
+        es.close();
+        resolve({
+          received: delays.length,
+          delayAvg: delays.length ? Math.round(delays.reduce((a, b) => a + b, 0) / delays.length) : 0,
+          delayMax: delays.length ? Math.max(...delays) : 0,
+        });
+      };
+
+      es.addEventListener('probe', (e: MessageEvent) => {
+        const data = JSON.parse(e.data);
+        delays.push(Math.max(0, Math.round(Date.now() + clockOffset - data.sentAt)));
+        setProgress(`SSE ${data.seq}/${SSE_EVENTS}`);
+      });
+      es.addEventListener('done', finish);
+      es.onerror = finish;
+      setTimeout(finish, SSE_EVENTS * SSE_INTERVAL_MS + 10000);
+    });
+
+  const runProbe = async () => {
+    setRunning(true);
+    setLatest(null);
+    localStorage.setItem('mobileTestDeviceLabel', deviceLabel);
+
+    const echo = await runEcho();
+    const sse = await runSSE(echo.clockOffset);
+    setProgress('Saving...');
+
+    const result: ProbeResult = {
+      deviceId,
+      deviceLabel,
+      samples: ECHO_SAMPLES,
+      lost: echo.lost,
+      rttMinMs: echo.rttMinMs,
+      rttAvgMs: echo.rttAvgMs,
+      rttP95Ms: echo.rttP95Ms,
+      rttMaxMs: echo.rttMaxMs,
+      jitterMs: echo.jitterMs,
+      sseExpected: SSE_EVENTS,
+      sseReceived: sse.received,
+      sseDelayAvgMs: sse.delayAvg,
+      sseDelayMaxMs: sse.delayMax,
+      simulated: sim.dropPercent > 0 || sim.extraDelayMs > 0,
+    };
+
+    try {
+      const res = await fetch('/api/probe/results', {
+        method: 'POST',
+        headers: authHeaders,
+        body: JSON.stringify(result),
+      });
+      setLatest(res.ok ? await res.json() : result);
+    } catch {
+      setLatest(result);
+    }
+
+    setProgress('');
+    setRunning(false);
+    loadHistory();
+    if (sim.canEdit) loadSummary();
+  };
+
+  const updateSim = async (patch: Partial<Simulation>) => {
+    const next = { ...sim, ...patch };
+    const res = await fetch('/api/probe/simulation', {
+      method: 'PUT',
+      headers: authHeaders,
+      body: JSON.stringify({ dropPercent: next.dropPercent, extraDelayMs: next.extraDelayMs }),
+    });
+    if (res.ok) setSim(await res.json());
+  };
+
+  return (
+    <>
+      <div style={ps.card}>
+        <div style={ps.sectionLabel}>Network Quality</div>
+        <p style={ps.notice}>
+          Measures round-trip latency, packet loss and SSE delivery delay from this device.
+          Run it on a few phones around the pub before a big quiz.
+        </p>
+        <input
+          style={ps.input}
+          placeholder="Device label (e.g. Table 6 - iPhone)"
+          value={deviceLabel}
+          onChange={e => setDeviceLabel(e.target.value)}
+          maxLength={100}
+        />
+        {(sim.dropPercent > 0 || sim.extraDelayMs > 0) && (
+          <p style={{ ...ps.notice, color: '#E65100', marginTop: 8 }}>
+            ⚠️ Simulation active: {sim.dropPercent}% loss, +{sim.extraDelayMs}ms
+          </p>
+        )}
+        <button style={{ ...ps.btn, ...(running ? ps.btnDisabled : {}) }} onClick={runProbe} disabled={running}>
+          {running ? progress || 'Running...' : 'Run Network Probe'}
+        </button>
+      </div>
+
+      {latest && <ResultCard result={latest} />}
+
+      {/* Operator tools */}
+      {sim.canEdit && (
+        <div style={ps.card}>
+          <div style={ps.sectionLabel}>Simulation (probe traffic only)</div>
+          <div style={ps.row}>
+            <span style={ps.metricLabel}>Packet loss</span>
+            <select style={ps.select} value={sim.dropPercent} onChange={e => updateSim({ dropPercent: Number(e.target.value) })}>
+              {[0, 2, 5, 10, 25, 50].map(v => <option key={v} value={v}>{v}%</option>)}
+            </select>
+          </div>
+          <div style={ps.row}>
+            <span style={ps.metricLabel}>Extra delay</span>
+            <select style={ps.select} value={sim.extraDelayMs} onChange={e => updateSim({ extraDelayMs: Number(e.target.value) })}>
+              {[0, 50, 200, 500, 1000, 2000].map(v => <option key={v} value={v}>{v}ms</option>)}
+            </select>
+          </div>
+        </div>
+      )}
+
+      {sim.canEdit && summary && (
+        <div style={ps.card}>
+          <div style={ps.sectionLabel}>Venue Summary (last 3 hours)</div>
+          <p style={ps.notice}>
+            {summary.devices.length} device(s):{' '}
+            <span style={{ color: RATING_COLOR.good }}>{summary.counts.good} good</span> ·{' '}
+            <span style={{ color: RATING_COLOR.fair }}>{summary.counts.fair} fair</span> ·{' '}
+            <span style={{ color: RATING_COLOR.poor }}>{summary.counts.poor} poor</span>
+          </p>
+          {summary.devices.map(d => (
+            <div key={d.deviceId} style={ps.row}>
+              <span style={ps.metricLabel}>{d.deviceLabel || d.userEmail}</span>
+              <span style={{ ...ps.metricValue, color: RATING_COLOR[d.rating || 'good'] }}>
+                {d.rttP95Ms}ms · {d.lost}/{d.samples} lost · {(d.rating || '').toUpperCase()}
+              </span>
+            </div>
+          ))}
+        </div>
+      )}
+
+      {history.length > 0 && (
+        <div style={ps.card}>
+          <div style={ps.sectionLabel}>This Device's History</div>
+          {history.slice(0, 10).map(h => (
+            <div key={h.id} style={ps.row}>
+              <span style={ps.metricLabel}>
+                {h.createdAt ? new Date(h.createdAt).toLocaleString() : ''}
+                {h.simulated ? ' (sim)' : ''}
+              </span>
+              <span style={{ ...ps.metricValue, color: RATING_COLOR[h.rating || 'good'] }}>
+                {h.rttAvgMs}ms · {(h.rating || '').toUpperCase()}
+              </span>
+            </div>
+          ))}
+        </div>
+      )}
+    </>
+  );
+}
+
+function ResultCard({ result }: { result: ProbeResult }) {
+  const lossPct = Math.round((result.lost / result.samples) * 100);
+  const rows: [string, string][] = [
+    ['Round trip (avg / p95)', `${result.rttAvgMs}ms / ${result.rttP95Ms}ms`],
+    ['Round trip (min / max)', `${result.rttMinMs}ms / ${result.rttMaxMs}ms`],
+    ['Jitter', `${result.jitterMs}ms`],
+    ['Requests lost', `${result.lost}/${result.samples} (${lossPct}%)`],
+    ['SSE received', `${result.sseReceived}/${result.sseExpected}`],
+    ['SSE delay (avg / max)', `${result.sseDelayAvgMs}ms / ${result.sseDelayMaxMs}ms`],
+  ];
+
+  return (
+    <div style={ps.card}>
+      <div style={ps.sectionLabel}>Result</div>
+      {result.rating && (
+        <div style={{ ...ps.rating, color: RATING_COLOR[result.rating] }}>
+          {result.rating === 'good' ? 'Good — ready for a live quiz' :
+           result.rating === 'fair' ? 'Fair — expect occasional lag' :
+           'Poor — players will miss questions'}
+        </div>
+      )}
+      {rows.map(([label, value]) => (
+        <div key={label} style={ps.row}>
+          <span style={ps.metricLabel}>{label}</span>
+          <span style={ps.metricValue}>{value}</span>
+        </div>
+      ))}
+    </div>
+  );
+}
+
+// --- Styles ---
+
+const ps: Record<string, React.CSSProperties> = {
+  card: {
+    backgroundColor: '#FFFFFF',
+    borderRadius: 12,
+    padding: 16,
+    marginBottom: 12,
+    boxShadow: '0 1px 4px rgba(0,0,0,0.08)',
+  },
+  sectionLabel: {
+    fontSize: 11,
+    fontWeight: 600,
+    color: '#AAA',
+    textTransform: 'uppercase' as const,
+    letterSpacing: 0.8,
+    marginBottom: 8,
+  },
+  notice: {
+    fontSize: 13,
+    color: '#666',
+    lineHeight: 1.5,
+    margin: 0,
+  },
+  input: {
+    display: 'block',
+    width: '100%',
+    boxSizing: 'border-box' as const,
+    marginTop: 12,
+    padding: '10px 12px',
+    borderRadius: 8,
+    border: '1px solid #DDD',
+    fontSize: 14,
+  },
+  select: {
+    padding: '6px 8px',
+    borderRadius: 6,
+    border: '1px solid #DDD',
+    fontSize: 13,
+  },
+  btn: {
+    display: 'block',
+    width: '100%',
+    marginTop: 12,
+    padding: 14,
+    borderRadius: 10,
+    border: 'none',
+    backgroundColor: '#1565C0',
+    color: '#FFFFFF',
+    fontSize: 15,
+    fontWeight: 700,
+    cursor: 'pointer',
+  },
+  btnDisabled: {
+    backgroundColor: '#90A4AE',
+    cursor: 'not-allowed',
+  },
+  row: {
+    display: 'flex',
+    justifyContent: 'space-between',
+    alignItems: 'center',
+    gap: 12,
+    padding: '8px 0',
+    borderTop: '1px solid #F5F5F5',
+  },
+  metricLabel: {
+    fontSize: 13,
+    color: '#666',
+  },
+  metricValue: {
+    fontSize: 13,
+    fontWeight: 600,
+    color: '#222',
+    textAlign: 'right' as const,
+  },
+  rating: {
+    fontSize: 15,
+    fontWeight: 700,
+    marginBottom: 8,
+  },
+};
+
+export default NetworkProbe;
@@ -0,0 +1,11 @@
+module github.com/achgithub/activity-hub/operator-dashboard
+
+go 1.25
+
+require (
+	github.com/achgithub/activity-hub-common v0.1.1
+	github.com/gorilla/mux v1.8.1
+	github.com/lib/pq v1.10.9
+)
+
+replace github.com/achgithub/activity-hub-common => ../../../lib/activity-hub-common
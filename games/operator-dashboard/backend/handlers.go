@@ -0,0 +1,580 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/achgithub/activity-hub-common/analytics"
+	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/lib/pq"
+)
+
+// A pub's night runs past midnight: anything before 6am counts towards the
+// evening before.
+const nightOffset = 6 * time.Hour
+
+// A TV still connected sends a keepalive every 30s; one not heard from for
+// longer than this dropped without closing its stream.
+const keepaliveGrace = 90 * time.Second
+
+// Period is the date range and venue a report covers.
+type Period struct {
+	From    time.Time // First instant of the first day
+	To      time.Time // First instant of the day after the last
+	VenueID int       // 0 = every venue
+}
+
+// parsePeriod reads ?from=&to=&venue=. Users tied to a venue only see their own.
+func parsePeriod(r *http.Request) (Period, int, error) {
+	q := r.URL.Query()
+	from, to, err := analytics.ParseDays(q.Get("from"), q.Get("to"))
+	if err != nil {
+		return Period{}, http.StatusBadRequest, err
+	}
+	p := Period{From: from, To: to}
+	if v := q.Get("venue"); v != "" {
+		if p.VenueID, err = strconv.Atoi(v); err != nil || p.VenueID < 0 {
+			return Period{}, http.StatusBadRequest, fmt.Errorf("invalid venue")
+		}
+	}
+	if user, ok := authlib.GetUserFromContext(r.Context()); ok {
+		if p.VenueID == 0 && !user.CanAccessVenue(0) {
+			p.VenueID = user.VenueID
+		}
+		if !user.CanAccessVenue(p.VenueID) {
+			return Period{}, http.StatusForbidden, fmt.Errorf("not your venue")
+		}
+	}
+	return p, http.StatusOK, nil
+}
+
+// venuePlayers returns the emails of a venue's players. LMS entries don't
+// record a venue, so that report counts a venue's players instead. nil when
+// the period covers every venue.
+func venuePlayers(p Period) ([]string, error) {
+	if p.VenueID == 0 {
+		return nil, nil
+	}
+	rows, err := identityDB.Query(`SELECT email FROM users WHERE venue_id = $1`, p.VenueID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	emails := []string{}
+	for rows.Next() {
+		var email string
+		if err := rows.Scan(&email); err != nil {
+			return nil, err
+		}
+		emails = append(emails, email)
+	}
+	return emails, rows.Err()
+}
+
+// ==================== BUSIEST NIGHTS ====================
+
+// Night is one evening's trade, counted from 6am to 6am.
+type Night struct {
+	Night       string `json:"night"` // YYYY-MM-DD the evening started
+	Weekday     string `json:"weekday"`
+	AppsOpened  int    `json:"appsOpened"`
+	GamesPlayed int    `json:"gamesPlayed"`
+	QuizPlayers int    `json:"quizPlayers"`
+	Activity    int    `json:"activity"` // Sum of the three; what "busiest" ranks by
+}
+
+// WeekdayAverage is the average night for one day of the week.
+type WeekdayAverage struct {
+	Weekday     string  `json:"weekday"`
+	Nights      int     `json:"nights"`
+	AvgActivity float64 `json:"avgActivity"`
+}
+
+func handleGetNights(w http.ResponseWriter, r *http.Request) {
+	p, code, err := parsePeriod(r)
+	if err != nil {
+		sendError(w, err.Error(), code)
+		return
+	}
+
+	nights := map[string]*Night{}
+	night := func(day string) *Night {
+		if nights[day] == nil {
+			nights[day] = &Night{Night: day}
+		}
+		return nights[day]
+	}
+	from, to := p.From.Add(nightOffset), p.To.Add(nightOffset)
+
+	rows, err := identityDB.Query(`
+		SELECT to_char(occurred_at - INTERVAL '6 hours', 'YYYY-MM-DD'), COUNT(*)
+		FROM analytics_events
+		WHERE event = $1 AND occurred_at >= $2 AND occurred_at < $3
+		  AND ($4 = 0 OR venue_id = $4)
+		GROUP BY 1
+	`, analytics.EventAppOpened, from, to, p.VenueID)
+	if err != nil {
+		log.Printf("Failed to count apps opened: %v", err)
+		sendError(w, "Failed to load nights", http.StatusInternalServerError)
+		return
+	}
+	err = scanNightCounts(rows, func(day string, n int) { night(day).AppsOpened = n })
+	if err != nil {
+		log.Printf("Failed to scan apps opened: %v", err)
+		sendError(w, "Failed to load nights", http.StatusInternalServerError)
+		return
+	}
+
+	rows, err = leaderboardDB.Query(`
+		SELECT to_char(played_at - INTERVAL '6 hours', 'YYYY-MM-DD'), COUNT(*)
+		FROM game_results
+		WHERE NOT COALESCE(voided, FALSE) AND confirmation = 'confirmed'
+		  AND played_at >= $1 AND played_at < $2
+		  AND ($3 = 0 OR venue_id = $3)
+		GROUP BY 1
+	`, from, to, p.VenueID)
+	if err != nil {
+		log.Printf("Failed to count games played: %v", err)
+		sendError(w, "Failed to load nights", http.StatusInternalServerError)
+		return
+	}
+	err = scanNightCounts(rows, func(day string, n int) { night(day).GamesPlayed = n })
+	if err != nil {
+		log.Printf("Failed to scan games played: %v", err)
+		sendError(w, "Failed to load nights", http.StatusInternalServerError)
+		return
+	}
+
+	rows, err = quizDB.Query(`
+		SELECT to_char(s.started_at - INTERVAL '6 hours', 'YYYY-MM-DD'), COUNT(sp.*)
+		FROM sessions s
+		JOIN session_players sp ON sp.session_id = s.id
+		WHERE s.started_at >= $1 AND s.started_at < $2
+		  AND ($3 = 0 OR s.venue_id = $3)
+		GROUP BY 1
+	`, from, to, p.VenueID)
+	if err != nil {
+		log.Printf("Failed to count quiz players: %v", err)
+		sendError(w, "Failed to load nights", http.StatusInternalServerError)
+		return
+	}
+	err = scanNightCounts(rows, func(day string, n int) { night(day).QuizPlayers = n })
+	if err != nil {
+		log.Printf("Failed to scan quiz players: %v", err)
+		sendError(w, "Failed to load nights", http.StatusInternalServerError)
+		return
+	}
+
+	// Busiest first, and the average for each day of the week
+	result := []Night{}
+	type weekdayTotal struct{ nights, activity int }
+	totals := map[time.Weekday]*weekdayTotal{}
+	for _, n := range nights {
+		day, err := time.Parse(analytics.DayFormat, n.Night)
+		if err != nil {
+			continue
+		}
+		n.Weekday = day.Weekday().String()
+		n.Activity = n.AppsOpened + n.GamesPlayed + n.QuizPlayers
+		result = append(result, *n)
+		if totals[day.Weekday()] == nil {
+			totals[day.Weekday()] = &weekdayTotal{}
+		}
+		totals[day.Weekday()].nights++
+		totals[day.Weekday()].activity += n.Activity
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Activity != result[j].Activity {
+			return result[i].Activity > result[j].Activity
+		}
+		return result[i].Night > result[j].Night
+	})
+	weekdays := []WeekdayAverage{}
+	for d := time.Monday; ; d = (d + 1) % 7 {
+		if t := totals[d]; t != nil {
+			weekdays = append(weekdays, WeekdayAverage{
+				Weekday:     d.String(),
+				Nights:      t.nights,
+				AvgActivity: float64(t.activity) / float64(t.nights),
+			})
+		}
+		if d == time.Sunday {
+			break
+		}
+	}
+
+	if wantCSV(r) {
+		csvRows := [][]string{}
+		for _, n := range result {
+			csvRows = append(csvRows, []string{n.Night, n.Weekday,
+				strconv.Itoa(n.AppsOpened), strconv.Itoa(n.GamesPlayed), strconv.Itoa(n.QuizPlayers), strconv.Itoa(n.Activity)})
+		}
+		sendCSV(w, "nights", p, []string{"Night", "Weekday", "Apps Opened", "Games Played", "Quiz Players", "Activity"}, csvRows)
+		return
+	}
+	sendJSON(w, map[string]interface{}{
+		"from":     p.From.Format(analytics.DayFormat),
+		"to":       p.To.AddDate(0, 0, -1).Format(analytics.DayFormat),
+		"nights":   result,
+		"weekdays": weekdays,
+	})
+}
+
+// scanNightCounts reads (night, count) rows and closes them.
+func scanNightCounts(rows *sql.Rows, set func(day string, n int)) error {
+	defer rows.Close()
+	for rows.Next() {
+		var day string
+		var n int
+		if err := rows.Scan(&day, &n); err != nil {
+			return err
+		}
+		set(day, n)
+	}
+	return rows.Err()
+}
+
+// ==================== MOST-PLAYED GAMES ====================
+
+// GameStats is how much one game was played.
+type GameStats struct {
+	GameType    string  `json:"gameType"`
+	Played      int     `json:"played"`
+	Draws       int     `json:"draws"`
+	Players     int     `json:"players"`     // Different people who played it
+	AvgDuration float64 `json:"avgDuration"` // Seconds; 0 when no durations were recorded
+}
+
+func handleGetGames(w http.ResponseWriter, r *http.Request) {
+	p, code, err := parsePeriod(r)
+	if err != nil {
+		sendError(w, err.Error(), code)
+		return
+	}
+	rows, err := leaderboardDB.Query(`
+		WITH results AS (
+			SELECT game_type, winner_id, loser_id, is_draw, duration
+			FROM game_results
+			WHERE NOT COALESCE(voided, FALSE) AND confirmation = 'confirmed'
+			  AND played_at >= $1 AND played_at < $2
+			  AND ($3 = 0 OR venue_id = $3)
+		), players AS (
+			SELECT game_type, winner_id AS email FROM results
+			UNION
+			SELECT game_type, loser_id FROM results
+		)
+		SELECT r.game_type, COUNT(*), COUNT(*) FILTER (WHERE r.is_draw),
+		       (SELECT COUNT(*) FROM players p WHERE p.game_type = r.game_type AND COALESCE(p.email, '') <> ''),
+		       COALESCE(AVG(r.duration) FILTER (WHERE r.duration > 0), 0)
+		FROM results r
+		GROUP BY r.game_type
+		ORDER BY 2 DESC, r.game_type
+	`, p.From, p.To, p.VenueID)
+	if err != nil {
+		log.Printf("Failed to load game stats: %v", err)
+		sendError(w, "Failed to load games", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	games := []GameStats{}
+	for rows.Next() {
+		var g GameStats
+		if err := rows.Scan(&g.GameType, &g.Played, &g.Draws, &g.Players, &g.AvgDuration); err != nil {
+			log.Printf("Failed to scan game stats: %v", err)
+			sendError(w, "Failed to load games", http.StatusInternalServerError)
+			return
+		}
+		games = append(games, g)
+	}
+
+	if wantCSV(r) {
+		csvRows := [][]string{}
+		for _, g := range games {
+			csvRows = append(csvRows, []string{g.GameType, strconv.Itoa(g.Played), strconv.Itoa(g.Draws),
+				strconv.Itoa(g.Players), strconv.FormatFloat(g.AvgDuration, 'f', 0, 64)})
+		}
+		sendCSV(w, "games", p, []string{"Game", "Played", "Draws", "Players", "Avg Duration (s)"}, csvRows)
+		return
+	}
+	sendJSON(w, map[string]interface{}{"games": games})
+}
+
+// ==================== QUIZ ATTENDANCE ====================
+
+// QuizWeek is quiz turnout for one week.
+type QuizWeek struct {
+	Week       string  `json:"week"` // YYYY-MM-DD of the Monday
+	Sessions   int     `json:"sessions"`
+	Players    int     `json:"players"`
+	AvgPlayers float64 `json:"avgPlayers"` // Per session
+}
+
+func handleGetQuizAttendance(w http.ResponseWriter, r *http.Request) {
+	p, code, err := parsePeriod(r)
+	if err != nil {
+		sendError(w, err.Error(), code)
+		return
+	}
+
+	rows, err := quizDB.Query(`
+		SELECT to_char(date_trunc('week', s.started_at - INTERVAL '6 hours'), 'YYYY-MM-DD'),
+		       COUNT(DISTINCT s.id), COUNT(sp.*)
+		FROM sessions s
+		LEFT JOIN session_players sp ON sp.session_id = s.id
+		WHERE s.started_at >= $1 AND s.started_at < $2
+		  AND ($3 = 0 OR s.venue_id = $3)
+		GROUP BY 1
+		ORDER BY 1
+	`, p.From.Add(nightOffset), p.To.Add(nightOffset), p.VenueID)
+	if err != nil {
+		log.Printf("Failed to load quiz attendance: %v", err)
+		sendError(w, "Failed to load quiz attendance", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	weeks := []QuizWeek{}
+	for rows.Next() {
+		var q QuizWeek
+		if err := rows.Scan(&q.Week, &q.Sessions, &q.Players); err != nil {
+			log.Printf("Failed to scan quiz attendance: %v", err)
+			sendError(w, "Failed to load quiz attendance", http.StatusInternalServerError)
+			return
+		}
+		if q.Sessions > 0 {
+			q.AvgPlayers = float64(q.Players) / float64(q.Sessions)
+		}
+		weeks = append(weeks, q)
+	}
+
+	if wantCSV(r) {
+		csvRows := [][]string{}
+		for _, q := range weeks {
+			csvRows = append(csvRows, []string{q.Week, strconv.Itoa(q.Sessions), strconv.Itoa(q.Players),
+				strconv.FormatFloat(q.AvgPlayers, 'f', 1, 64)})
+		}
+		sendCSV(w, "quiz-attendance", p, []string{"Week", "Sessions", "Players", "Avg Players"}, csvRows)
+		return
+	}
+	sendJSON(w, map[string]interface{}{"weeks": weeks})
+}
+
+// ==================== LMS PARTICIPATION ====================
+
+// LMSGame is the turnout for one Last Man Standing game running in the period.
+type LMSGame struct {
+	ID       int     `json:"id"`
+	Name     string  `json:"name"`
+	Status   string  `json:"status"`
+	Private  bool    `json:"private"`
+	Started  string  `json:"started"`         // YYYY-MM-DD
+	Ended    *string `json:"ended,omitempty"` // YYYY-MM-DD; nil while running
+	Entrants int     `json:"entrants"`
+	StillIn  int     `json:"stillIn"`
+	Joined   int     `json:"joined"` // Entrants who joined during the period
+}
+
+func handleGetLMSParticipation(w http.ResponseWriter, r *http.Request) {
+	p, code, err := parsePeriod(r)
+	if err != nil {
+		sendError(w, err.Error(), code)
+		return
+	}
+	players, err := venuePlayers(p)
+	if err != nil {
+		log.Printf("Failed to load venue players: %v", err)
+		sendError(w, "Failed to load LMS participation", http.StatusInternalServerError)
+		return
+	}
+
+	// Games running at any point in the period, with their entrants
+	rows, err := lmsDB.Query(`
+		SELECT g.id, g.name, COALESCE(g.status, 'active'), COALESCE(g.is_private, FALSE),
+		       to_char(COALESCE(g.start_date, g.created_at), 'YYYY-MM-DD'), to_char(g.end_date, 'YYYY-MM-DD'),
+		       COUNT(gp.id), COUNT(gp.id) FILTER (WHERE gp.is_active),
+		       COUNT(gp.id) FILTER (WHERE gp.joined_at >= $1 AND gp.joined_at < $2)
+		FROM games g
+		LEFT JOIN game_players gp ON gp.game_id = g.id
+		     AND ($3::text[] IS NULL OR gp.user_id = ANY($3))
+		WHERE g.deleted_at IS NULL
+		  AND COALESCE(g.start_date, g.created_at) < $2
+		  AND (g.end_date IS NULL OR g.end_date >= $1)
+		GROUP BY g.id
+		HAVING $3::text[] IS NULL OR COUNT(gp.id) > 0
+		ORDER BY COALESCE(g.start_date, g.created_at) DESC
+	`, p.From, p.To, pq.Array(players))
+	if err != nil {
+		log.Printf("Failed to load LMS participation: %v", err)
+		sendError(w, "Failed to load LMS participation", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	games := []LMSGame{}
+	for rows.Next() {
+		var g LMSGame
+		var ended sql.NullString
+		if err := rows.Scan(&g.ID, &g.Name, &g.Status, &g.Private, &g.Started, &ended,
+			&g.Entrants, &g.StillIn, &g.Joined); err != nil {
+			log.Printf("Failed to scan LMS participation: %v", err)
+			sendError(w, "Failed to load LMS participation", http.StatusInternalServerError)
+			return
+		}
+		if ended.Valid {
+			g.Ended = &ended.String
+		}
+		games = append(games, g)
+	}
+
+	if wantCSV(r) {
+		csvRows := [][]string{}
+		for _, g := range games {
+			ended := ""
+			if g.Ended != nil {
+				ended = *g.Ended
+			}
+			csvRows = append(csvRows, []string{g.Name, g.Status, strconv.FormatBool(g.Private), g.Started, ended,
+				strconv.Itoa(g.Entrants), strconv.Itoa(g.StillIn), strconv.Itoa(g.Joined)})
+		}
+		sendCSV(w, "lms-participation", p, []string{"Game", "Status", "Private", "Started", "Ended", "Entrants", "Still In", "Joined In Period"}, csvRows)
+		return
+	}
+	sendJSON(w, map[string]interface{}{"games": games})
+}
+
+// ==================== DISPLAY UPTIME ====================
+
+// DisplayUptime is how much of the period a TV had its push stream open.
+type DisplayUptime struct {
+	ID            int        `json:"id"`
+	Name          string     `json:"name"`
+	Location      string     `json:"location"`
+	OnlineSeconds int        `json:"onlineSeconds"`
+	UptimePercent float64    `json:"uptimePercent"`
+	Connections   int        `json:"connections"` // Times it (re)connected
+	LastSeen      *time.Time `json:"lastSeen,omitempty"`
+}
+
+func handleGetDisplayUptime(w http.ResponseWriter, r *http.Request) {
+	p, code, err := parsePeriod(r)
+	if err != nil {
+		sendError(w, err.Error(), code)
+		return
+	}
+
+	// Uptime is measured against the part of the period that has happened
+	to := p.To
+	if now := time.Now(); now.Before(to) {
+		to = now
+	}
+	if !p.From.Before(to) {
+		sendError(w, "Period hasn't started yet", http.StatusBadRequest)
+		return
+	}
+
+	rows, err := displayDB.Query(`
+		SELECT d.id, d.name, COALESCE(d.location, ''),
+		       COALESCE(SUM(GREATEST(EXTRACT(EPOCH FROM LEAST(c.ended_at, $2) - GREATEST(c.connected_at, $1)), 0)), 0),
+		       COUNT(c.id),
+		       (SELECT MAX(last_seen_at) FROM display_connections WHERE display_id = d.id)
+		FROM displays d
+		LEFT JOIN (
+			SELECT id, display_id, connected_at,
+			       COALESCE(disconnected_at,
+			                CASE WHEN last_seen_at > NOW() - $4 * INTERVAL '1 second' THEN NOW() ELSE last_seen_at END) AS ended_at
+			FROM display_connections
+		) c ON c.display_id = d.id AND c.connected_at < $2 AND c.ended_at > $1
+		WHERE d.is_active AND d.revoked_at IS NULL
+		  AND ($3 = 0 OR d.venue_id = $3)
+		GROUP BY d.id
+		ORDER BY d.name
+	`, p.From, to, p.VenueID, int(keepaliveGrace.Seconds()))
+	if err != nil {
+		log.Printf("Failed to load display uptime: %v", err)
+		sendError(w, "Failed to load display uptime", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	period := to.Sub(p.From).Seconds()
+	displays := []DisplayUptime{}
+	for rows.Next() {
+		var d DisplayUptime
+		var online float64
+		var lastSeen sql.NullTime
+		if err := rows.Scan(&d.ID, &d.Name, &d.Location, &online, &d.Connections, &lastSeen); err != nil {
+			log.Printf("Failed to scan display uptime: %v", err)
+			sendError(w, "Failed to load display uptime", http.StatusInternalServerError)
+			return
+		}
+		// A TV open in two browser tabs overlaps itself; never more than all of it
+		if online > period {
+			online = period
+		}
+		d.OnlineSeconds = int(online)
+		d.UptimePercent = online * 100 / period
+		if lastSeen.Valid {
+			d.LastSeen = &lastSeen.Time
+		}
+		displays = append(displays, d)
+	}
+
+	if wantCSV(r) {
+		csvRows := [][]string{}
+		for _, d := range displays {
+			lastSeen := ""
+			if d.LastSeen != nil {
+				lastSeen = d.LastSeen.Format("2006-01-02 15:04")
+			}
+			csvRows = append(csvRows, []string{d.Name, d.Location, strconv.FormatFloat(d.UptimePercent, 'f', 1, 64),
+				strconv.Itoa(d.OnlineSeconds), strconv.Itoa(d.Connections), lastSeen})
+		}
+		sendCSV(w, "display-uptime", p, []string{"Display", "Location", "Uptime %", "Online (s)", "Connections", "Last Seen"}, csvRows)
+		return
+	}
+	sendJSON(w, map[string]interface{}{"displays": displays})
+}
+
+// ==================== HELPERS ====================
+
+// wantCSV reports whether the caller asked for ?format=csv.
+func wantCSV(r *http.Request) bool {
+	return r.URL.Query().Get("format") == "csv"
+}
+
+// sendCSV sends a report as a spreadsheet download named after it and its period.
+func sendCSV(w http.ResponseWriter, report string, p Period, header []string, rows [][]string) {
+	filename := fmt.Sprintf("%s-%s-to-%s.csv", report,
+		p.From.Format(analytics.DayFormat), p.To.AddDate(0, 0, -1).Format(analytics.DayFormat))
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+
+	cw := csv.NewWriter(w)
+	cw.Write(header)
+	for _, row := range rows {
+		cw.Write(row)
+	}
+	cw.Flush()
+}
+
+// sendError sends a JSON error response.
+func sendError(w http.ResponseWriter, message string, code int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
+
+// sendJSON sends a JSON success response.
+func sendJSON(w http.ResponseWriter, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(data)
+}
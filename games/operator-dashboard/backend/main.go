@@ -0,0 +1,121 @@
+package main
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+
+	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/achgithub/activity-hub-common/cli"
+	"github.com/achgithub/activity-hub-common/config"
+	"github.com/achgithub/activity-hub-common/database"
+	apphttp "github.com/achgithub/activity-hub-common/http"
+	"github.com/achgithub/activity-hub-common/maintenance"
+	"github.com/gorilla/mux"
+)
+
+// Every database is only read. Reports are worked out from the apps' own
+// tables on each request, so corrections made elsewhere show up straight away.
+var (
+	identityDB    *sql.DB // activity_hub — auth, usage analytics, players' venues
+	leaderboardDB *sql.DB // leaderboard_db — games played
+	quizDB        *sql.DB // quiz_db — quiz sessions and players
+	lmsDB         *sql.DB // last_man_standing_db — LMS games and entrants
+	displayDB     *sql.DB // display_admin_db — TVs and their connections
+)
+
+func main() {
+	cli.New("operator-dashboard",
+		cli.Serve(serve),
+	).Main()
+}
+
+func serve() {
+	var err error
+
+	identityDB, err = database.InitIdentityDatabase()
+	if err != nil {
+		log.Fatal("Failed to connect to identity database:", err)
+	}
+	defer identityDB.Close()
+
+	leaderboardDB, err = database.InitDatabaseByName("leaderboard_db")
+	if err != nil {
+		log.Fatal("Failed to connect to leaderboard database:", err)
+	}
+	defer leaderboardDB.Close()
+
+	quizDB, err = database.InitDatabaseByName("quiz_db")
+	if err != nil {
+		log.Fatal("Failed to connect to quiz database:", err)
+	}
+	defer quizDB.Close()
+
+	lmsDB, err = database.InitDatabaseByName("last_man_standing_db")
+	if err != nil {
+		log.Fatal("Failed to connect to LMS database:", err)
+	}
+	defer lmsDB.Close()
+
+	displayDB, err = database.InitDatabaseByName("display_admin_db")
+	if err != nil {
+		log.Fatal("Failed to connect to display admin database:", err)
+	}
+	defer displayDB.Close()
+
+	r := mux.NewRouter()
+
+	// Public routes
+	r.HandleFunc("/api/config", handleConfig).Methods("GET")
+	r.HandleFunc("/api/openapi.json", apiSpec().Handler).Methods("GET")
+
+	// Reports - require game_admin or super_user. Each takes ?from=&to=&venue=
+	// and ?format=csv for a spreadsheet.
+	api := r.PathPrefix("/api").Subrouter()
+	api.Use(authlib.Middleware(identityDB))
+	api.Use(requireOperator)
+	api.HandleFunc("/metrics/nights", handleGetNights).Methods("GET")
+	api.HandleFunc("/metrics/games", handleGetGames).Methods("GET")
+	api.HandleFunc("/metrics/quiz", handleGetQuizAttendance).Methods("GET")
+	api.HandleFunc("/metrics/lms", handleGetLMSParticipation).Methods("GET")
+	api.HandleFunc("/metrics/displays", handleGetDisplayUptime).Methods("GET")
+
+	// Serve React frontend
+	r.PathPrefix("/static/").Handler(http.FileServer(http.Dir("./static")))
+	r.PathPrefix("/").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "./static/index.html")
+	})
+
+	cors := apphttp.NewCORSPolicy(identityDB)
+	appMaintenance := maintenance.New(identityDB)
+
+	port := config.GetEnv("PORT", "5100")
+	log.Printf("🚀 Operator Dashboard starting on :%s", port)
+	log.Fatal(http.ListenAndServe(":"+port, cors.Middleware(appMaintenance.Middleware("operator-dashboard")(apphttp.Versioned(r)))))
+}
+
+func handleConfig(w http.ResponseWriter, r *http.Request) {
+	apphttp.WriteConfig(w, apphttp.AppConfig{
+		AppID:       "operator-dashboard",
+		Name:        "Operator Dashboard",
+		Icon:        "📈",
+		Description: "Busiest nights, most-played games, quiz and LMS turnout and TV uptime",
+	})
+}
+
+// requireOperator lets game_admin and super_user users see the reports.
+// Must be used after authlib.Middleware.
+func requireOperator(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, ok := authlib.GetUserFromContext(r.Context())
+		if !ok {
+			sendError(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if !user.HasRole("game_admin") && !user.HasRole("super_user") {
+			sendError(w, "Forbidden - game_admin or super_user role required", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"net/http"
+
+	apphttp "github.com/achgithub/activity-hub-common/http"
+	"github.com/achgithub/activity-hub-common/openapi"
+)
+
+// apiSpec describes the routes registered in main.go for GET /api/openapi.json.
+// Add a route here whenever one is added there.
+func apiSpec() *openapi.Spec {
+	spec := openapi.New("operator-dashboard", "1.0.0",
+		"Business metrics across the apps: busiest nights, most-played games, quiz attendance, LMS participation and TV uptime. "+
+			"Reports need the game_admin or super_user role; users tied to a venue only see their own.")
+
+	public := spec.Group("Public")
+	public.Route("GET", "/api/config", "App configuration").
+		Returns(http.StatusOK, apphttp.AppConfig{})
+	public.Route("GET", "/api/openapi.json", "This document").
+		Returns(http.StatusOK, nil)
+
+	reports := spec.Group("Reports").Auth()
+	report := func(path, summary string, example interface{}) {
+		reports.Route("GET", path, summary).
+			Query("from", "First day, YYYY-MM-DD (default: 30 days before to)").
+			Query("to", "Last day, YYYY-MM-DD (default: today)").
+			Query("venue", "Only this venue (chain-wide users)").
+			Query("format", "csv to download the rows as a spreadsheet (text/csv) instead").
+			Returns(http.StatusOK, example)
+	}
+	report("/api/metrics/nights", "Nights busiest first (6am to 6am), with the average for each weekday",
+		openapi.Fields{"from": "", "to": "", "nights": []Night{}, "weekdays": []WeekdayAverage{}})
+	report("/api/metrics/games", "Games most played first",
+		openapi.Fields{"games": []GameStats{}})
+	report("/api/metrics/quiz", "Quiz sessions and players per week",
+		openapi.Fields{"weeks": []QuizWeek{}})
+	report("/api/metrics/lms", "LMS games running in the period with their entrants",
+		openapi.Fields{"games": []LMSGame{}})
+	report("/api/metrics/displays", "How much of the period each TV was connected",
+		openapi.Fields{"displays": []DisplayUptime{}})
+
+	return spec
+}
@@ -0,0 +1,411 @@
+import React, { useState, useEffect, useMemo, useCallback } from 'react';
+import './App.css';
+
+// --- Types ---
+
+interface Night {
+  night: string;
+  weekday: string;
+  appsOpened: number;
+  gamesPlayed: number;
+  quizPlayers: number;
+  activity: number;
+}
+
+interface WeekdayAverage {
+  weekday: string;
+  nights: number;
+  avgActivity: number;
+}
+
+interface GameStats {
+  gameType: string;
+  played: number;
+  draws: number;
+  players: number;
+  avgDuration: number;
+}
+
+interface QuizWeek {
+  week: string;
+  sessions: number;
+  players: number;
+  avgPlayers: number;
+}
+
+interface LMSGame {
+  id: number;
+  name: string;
+  status: string;
+  private: boolean;
+  started: string;
+  ended?: string;
+  entrants: number;
+  stillIn: number;
+  joined: number;
+}
+
+interface DisplayUptime {
+  id: number;
+  name: string;
+  location: string;
+  onlineSeconds: number;
+  uptimePercent: number;
+  connections: number;
+  lastSeen?: string;
+}
+
+type Report = 'nights' | 'games' | 'quiz' | 'lms' | 'displays';
+
+const REPORTS: { id: Report; label: string }[] = [
+  { id: 'nights', label: '🌙 Busiest Nights' },
+  { id: 'games', label: '🎲 Games' },
+  { id: 'quiz', label: '❓ Quiz' },
+  { id: 'lms', label: '⚽ LMS' },
+  { id: 'displays', label: '📺 Displays' },
+];
+
+// Date → YYYY-MM-DD in local time
+function toDay(d: Date): string {
+  const pad = (n: number) => String(n).padStart(2, '0');
+  return `${d.getFullYear()}-${pad(d.getMonth() + 1)}-${pad(d.getDate())}`;
+}
+
+function formatDuration(seconds: number): string {
+  if (seconds <= 0) return '–';
+  const hours = Math.floor(seconds / 3600);
+  const minutes = Math.round((seconds % 3600) / 60);
+  return hours > 0 ? `${hours}h ${minutes}m` : `${minutes}m`;
+}
+
+// --- Hooks ---
+
+function useUrlParams() {
+  return useMemo(() => {
+    const params = new URLSearchParams(window.location.search);
+    return {
+      token: params.get('token') || sessionStorage.getItem('token') || '',
+    };
+  }, []);
+}
+
+function useApi(token: string) {
+  return useCallback(
+    async (path: string, options: RequestInit = {}) => {
+      const headers: Record<string, string> = {
+        'Content-Type': 'application/json',
+        ...(token ? { Authorization: `Bearer ${token}` } : {}),
+        ...(options.headers as Record<string, string> || {}),
+      };
+      const res = await fetch(path, { ...options, headers });
+      if (!res.ok) {
+        const err = await res.json().catch(() => ({ error: 'Request failed' }));
+        throw new Error(err.error || 'Request failed');
+      }
+      return res;
+    },
+    [token]
+  );
+}
+
+// --- Main App ---
+
+function App() {
+  const { token } = useUrlParams();
+  const api = useApi(token);
+
+  const [report, setReport] = useState<Report>('nights');
+  const [from, setFrom] = useState(() => {
+    const d = new Date();
+    d.setDate(d.getDate() - 29);
+    return toDay(d);
+  });
+  const [to, setTo] = useState(() => toDay(new Date()));
+  const [data, setData] = useState<any>(null);
+  const [loading, setLoading] = useState(false);
+  const [error, setError] = useState<string | null>(null);
+
+  const goToLobby = () => { window.location.href = `http://${window.location.hostname}:3001`; };
+
+  const query = `from=${from}&to=${to}`;
+
+  useEffect(() => {
+    if (!token || !from || !to) return;
+    setLoading(true);
+    setData(null);
+    api(`/api/metrics/${report}?${query}`)
+      .then(res => res.json())
+      .then(setData)
+      .catch(err => setError(err.message))
+      .finally(() => setLoading(false));
+  }, [token, report, query, from, to, api]);
+
+  // The download needs the auth header, so fetch it and save the blob
+  const downloadCSV = async () => {
+    try {
+      const res = await api(`/api/metrics/${report}?${query}&format=csv`);
+      const blob = await res.blob();
+      const url = URL.createObjectURL(blob);
+      const a = document.createElement('a');
+      a.href = url;
+      a.download = `${report}-${from}-to-${to}.csv`;
+      a.click();
+      URL.revokeObjectURL(url);
+    } catch (err: any) {
+      setError(err.message);
+    }
+  };
+
+  // --- Render ---
+
+  if (!token) {
+    return (
+      <div className="ah-container ah-container--narrow">
+        <h2>Operator Dashboard</h2>
+        <p style={{ color: '#666', marginTop: 20 }}>
+          Access this app through the lobby.
+        </p>
+        <button className="ah-btn-primary" onClick={goToLobby}>
+          Go to Lobby
+        </button>
+      </div>
+    );
+  }
+
+  return (
+    <>
+      <div className="ah-app-header">
+        <div className="ah-app-header-left">
+          <h1 className="ah-app-title">📈 Operator Dashboard</h1>
+        </div>
+        <div className="ah-app-header-right">
+          <button className="ah-lobby-btn" onClick={goToLobby}>← Lobby</button>
+        </div>
+      </div>
+
+      <div className="ah-container ah-container--wide">
+        {error && (
+          <div className="ah-banner ah-banner--error" onClick={() => setError(null)}>
+            {error} — click to dismiss
+          </div>
+        )}
+
+        <div className="ah-card">
+          <div className="ah-flex-between" style={{ flexWrap: 'wrap', gap: 12 }}>
+            <div className="ah-flex" style={{ gap: 12, alignItems: 'flex-end' }}>
+              <div>
+                <label className="ah-label">From</label>
+                <input className="ah-input" type="date" value={from} max={to} onChange={e => setFrom(e.target.value)} />
+              </div>
+              <div>
+                <label className="ah-label">To</label>
+                <input className="ah-input" type="date" value={to} min={from} onChange={e => setTo(e.target.value)} />
+              </div>
+            </div>
+            <button className="ah-btn-outline" onClick={downloadCSV} disabled={!data}>
+              ⬇ Download CSV
+            </button>
+          </div>
+        </div>
+
+        <div className="ah-tabs">
+          {REPORTS.map(r => (
+            <button
+              key={r.id}
+              className={`ah-tab ${report === r.id ? 'active' : ''}`}
+              onClick={() => setReport(r.id)}
+            >
+              {r.label}
+            </button>
+          ))}
+        </div>
+
+        <div className="ah-card">
+          {loading || !data ? (
+            <p style={{ color: '#666' }}>Loading...</p>
+          ) : (
+            <>
+              {report === 'nights' && <NightsReport nights={data.nights || []} weekdays={data.weekdays || []} />}
+              {report === 'games' && <GamesReport games={data.games || []} />}
+              {report === 'quiz' && <QuizReport weeks={data.weeks || []} />}
+              {report === 'lms' && <LMSReport games={data.games || []} />}
+              {report === 'displays' && <DisplaysReport displays={data.displays || []} />}
+            </>
+          )}
+        </div>
+      </div>
+    </>
+  );
+}
+
+// --- Reports ---
+
+function Empty() {
+  return <p style={{ color: '#666' }}>Nothing in this period.</p>;
+}
+
+function NightsReport({ nights, weekdays }: { nights: Night[]; weekdays: WeekdayAverage[] }) {
+  if (nights.length === 0) return <Empty />;
+  return (
+    <>
+      <p className="ah-meta">Nights run 6am to 6am. Activity is apps opened, games played and quiz players added up.</p>
+      <h3>By day of the week</h3>
+      <div className="ah-table">
+        <div className="ah-table-header">
+          <span style={{ flex: 2 }}>Day</span>
+          <span style={{ flex: 1 }}>Nights</span>
+          <span style={{ flex: 1 }}>Avg Activity</span>
+        </div>
+        {weekdays.map(w => (
+          <div key={w.weekday} className="ah-table-row">
+            <span style={{ flex: 2 }}>{w.weekday}</span>
+            <span style={{ flex: 1 }}>{w.nights}</span>
+            <span style={{ flex: 1, fontWeight: 600 }}>{w.avgActivity.toFixed(1)}</span>
+          </div>
+        ))}
+      </div>
+
+      <h3>Busiest nights</h3>
+      <div className="ah-table">
+        <div className="ah-table-header">
+          <span style={{ flex: 2 }}>Night</span>
+          <span style={{ flex: 1 }}>Apps Opened</span>
+          <span style={{ flex: 1 }}>Games</span>
+          <span style={{ flex: 1 }}>Quiz Players</span>
+          <span style={{ flex: 1 }}>Activity</span>
+        </div>
+        {nights.map(n => (
+          <div key={n.night} className="ah-table-row">
+            <span style={{ flex: 2 }}>{n.weekday} {n.night}</span>
+            <span style={{ flex: 1 }}>{n.appsOpened}</span>
+            <span style={{ flex: 1 }}>{n.gamesPlayed}</span>
+            <span style={{ flex: 1 }}>{n.quizPlayers}</span>
+            <span style={{ flex: 1, fontWeight: 600 }}>{n.activity}</span>
+          </div>
+        ))}
+      </div>
+    </>
+  );
+}
+
+function GamesReport({ games }: { games: GameStats[] }) {
+  if (games.length === 0) return <Empty />;
+  return (
+    <div className="ah-table">
+      <div className="ah-table-header">
+        <span style={{ flex: 2 }}>Game</span>
+        <span style={{ flex: 1 }}>Played</span>
+        <span style={{ flex: 1 }}>Draws</span>
+        <span style={{ flex: 1 }}>Players</span>
+        <span style={{ flex: 1 }}>Avg Length</span>
+      </div>
+      {games.map(g => (
+        <div key={g.gameType} className="ah-table-row">
+          <span style={{ flex: 2 }}>{g.gameType}</span>
+          <span style={{ flex: 1, fontWeight: 600 }}>{g.played}</span>
+          <span style={{ flex: 1 }}>{g.draws}</span>
+          <span style={{ flex: 1 }}>{g.players}</span>
+          <span style={{ flex: 1 }}>{formatDuration(g.avgDuration)}</span>
+        </div>
+      ))}
+    </div>
+  );
+}
+
+function QuizReport({ weeks }: { weeks: QuizWeek[] }) {
+  if (weeks.length === 0) return <Empty />;
+  const most = Math.max(...weeks.map(w => w.players), 1);
+  return (
+    <div className="ah-table">
+      <div className="ah-table-header">
+        <span style={{ flex: 2 }}>Week of</span>
+        <span style={{ flex: 1 }}>Quizzes</span>
+        <span style={{ flex: 1 }}>Players</span>
+        <span style={{ flex: 1 }}>Per Quiz</span>
+        <span style={{ flex: 3 }}></span>
+      </div>
+      {weeks.map(w => (
+        <div key={w.week} className="ah-table-row">
+          <span style={{ flex: 2 }}>{w.week}</span>
+          <span style={{ flex: 1 }}>{w.sessions}</span>
+          <span style={{ flex: 1, fontWeight: 600 }}>{w.players}</span>
+          <span style={{ flex: 1 }}>{w.avgPlayers.toFixed(1)}</span>
+          <span style={{ flex: 3 }}>
+            <span style={{ ...s.bar, width: `${(w.players / most) * 100}%` }} />
+          </span>
+        </div>
+      ))}
+    </div>
+  );
+}
+
+function LMSReport({ games }: { games: LMSGame[] }) {
+  if (games.length === 0) return <Empty />;
+  return (
+    <div className="ah-table">
+      <div className="ah-table-header">
+        <span style={{ flex: 3 }}>Game</span>
+        <span style={{ flex: 2 }}>Dates</span>
+        <span style={{ flex: 1 }}>Entrants</span>
+        <span style={{ flex: 1 }}>Still In</span>
+        <span style={{ flex: 1 }}>Joined</span>
+      </div>
+      {games.map(g => (
+        <div key={g.id} className="ah-table-row">
+          <span style={{ flex: 3 }}>
+            {g.name}{' '}
+            <span className={g.status === 'active' ? 'ah-badge ah-badge--success' : 'ah-badge ah-badge--neutral'}>{g.status}</span>
+            {g.private && <span className="ah-badge ah-badge--info" style={{ marginLeft: 4 }}>private</span>}
+          </span>
+          <span style={{ flex: 2 }}>{g.started} – {g.ended || 'now'}</span>
+          <span style={{ flex: 1, fontWeight: 600 }}>{g.entrants}</span>
+          <span style={{ flex: 1 }}>{g.stillIn}</span>
+          <span style={{ flex: 1 }}>{g.joined}</span>
+        </div>
+      ))}
+    </div>
+  );
+}
+
+function DisplaysReport({ displays }: { displays: DisplayUptime[] }) {
+  if (displays.length === 0) return <p style={{ color: '#666' }}>No displays set up.</p>;
+  return (
+    <>
+      <p className="ah-meta">Uptime is how much of the period each TV had Display Runtime connected.</p>
+      <div className="ah-table">
+        <div className="ah-table-header">
+          <span style={{ flex: 3 }}>Display</span>
+          <span style={{ flex: 1 }}>Uptime</span>
+          <span style={{ flex: 1 }}>Online</span>
+          <span style={{ flex: 1 }}>Reconnects</span>
+          <span style={{ flex: 2 }}>Last Seen</span>
+        </div>
+        {displays.map(d => (
+          <div key={d.id} className="ah-table-row">
+            <span style={{ flex: 3 }}>
+              {d.name}
+              {d.location && <span className="ah-meta"> · {d.location}</span>}
+            </span>
+            <span style={{ flex: 1, fontWeight: 600 }}>{d.uptimePercent.toFixed(1)}%</span>
+            <span style={{ flex: 1 }}>{formatDuration(d.onlineSeconds)}</span>
+            <span style={{ flex: 1 }}>{d.connections}</span>
+            <span style={{ flex: 2 }}>
+              {d.lastSeen ? new Date(d.lastSeen).toLocaleString([], { dateStyle: 'medium', timeStyle: 'short' }) : 'Never'}
+            </span>
+          </div>
+        ))}
+      </div>
+    </>
+  );
+}
+
+const s: Record<string, React.CSSProperties> = {
+  bar: {
+    display: 'inline-block',
+    height: 10,
+    borderRadius: 5,
+    backgroundColor: '#2196F3',
+  },
+};
+
+export default App;
@@ -0,0 +1,503 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/gorilla/mux"
+	"github.com/lib/pq"
+)
+
+// Event is a Pub Olympics: a window of time in which results from the
+// included apps count towards one medal table.
+type Event struct {
+	ID               int       `json:"id"`
+	Name             string    `json:"name"`
+	StartsAt         time.Time `json:"startsAt"`
+	EndsAt           time.Time `json:"endsAt"`
+	Apps             []string  `json:"apps"` // leaderboard game types
+	Scoring          Scoring   `json:"scoring"`
+	Status           string    `json:"status"` // upcoming, live, finished
+	ParticipantCount int       `json:"participantCount"`
+	CreatedBy        string    `json:"createdBy,omitempty"`
+}
+
+// Participant is a single player or a team competing in an event.
+type Participant struct {
+	ID      int      `json:"id"`
+	Name    string   `json:"name"`
+	IsTeam  bool     `json:"isTeam"`
+	Members []Member `json:"members"`
+}
+
+// Member is a player competing for a participant.
+type Member struct {
+	Email string `json:"email"`
+	Name  string `json:"name"`
+}
+
+// Times in requests are either RFC 3339 or what a datetime-local input sends
+const localTimeFormat = "2006-01-02T15:04"
+
+const eventColumns = `
+	e.id, e.name, e.starts_at, e.ends_at, e.apps, e.scoring, e.created_by,
+	(SELECT COUNT(*) FROM participants p WHERE p.event_id = e.id)`
+
+func scanEvent(row interface{ Scan(...interface{}) error }) (Event, error) {
+	var e Event
+	var scoring []byte
+	if err := row.Scan(&e.ID, &e.Name, &e.StartsAt, &e.EndsAt, pq.Array(&e.Apps), &scoring,
+		&e.CreatedBy, &e.ParticipantCount); err != nil {
+		return e, err
+	}
+	if err := json.Unmarshal(scoring, &e.Scoring); err != nil {
+		return e, fmt.Errorf("bad scoring for event %d: %w", e.ID, err)
+	}
+	e.Status = eventStatus(e, time.Now())
+	return e, nil
+}
+
+func eventStatus(e Event, now time.Time) string {
+	switch {
+	case now.Before(e.StartsAt):
+		return "upcoming"
+	case now.Before(e.EndsAt):
+		return "live"
+	default:
+		return "finished"
+	}
+}
+
+func loadEvent(id int) (Event, error) {
+	return scanEvent(appDB.QueryRow(`SELECT `+eventColumns+` FROM events e WHERE e.id = $1`, id))
+}
+
+// loadCurrentEvent picks the event to show on the TV: the live one, otherwise
+// the one that finished most recently, otherwise the next one coming up.
+func loadCurrentEvent() (Event, error) {
+	now := time.Now()
+	return scanEvent(appDB.QueryRow(`
+		SELECT `+eventColumns+` FROM events e
+		ORDER BY
+			CASE WHEN e.starts_at <= $1 AND e.ends_at > $1 THEN 0
+			     WHEN e.ends_at <= $1 THEN 1
+			     ELSE 2 END,
+			CASE WHEN e.starts_at > $1 THEN e.starts_at END ASC,
+			e.ends_at DESC
+		LIMIT 1
+	`, now))
+}
+
+func parseRequestTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation(localTimeFormat, value, time.Local)
+}
+
+// decodeEventRequest reads and validates the body of a create or update.
+func decodeEventRequest(r *http.Request) (Event, error) {
+	var req struct {
+		Name     string   `json:"name"`
+		StartsAt string   `json:"startsAt"`
+		EndsAt   string   `json:"endsAt"`
+		Apps     []string `json:"apps"`
+		Scoring  *Scoring `json:"scoring"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return Event{}, fmt.Errorf("Invalid request body")
+	}
+
+	e := Event{Name: strings.TrimSpace(req.Name), Scoring: defaultScoring}
+	if e.Name == "" {
+		return e, fmt.Errorf("Name is required")
+	}
+	var err error
+	if e.StartsAt, err = parseRequestTime(req.StartsAt); err != nil {
+		return e, fmt.Errorf("startsAt must be a date and time")
+	}
+	if e.EndsAt, err = parseRequestTime(req.EndsAt); err != nil {
+		return e, fmt.Errorf("endsAt must be a date and time")
+	}
+	if !e.EndsAt.After(e.StartsAt) {
+		return e, fmt.Errorf("The event must end after it starts")
+	}
+
+	seen := map[string]bool{}
+	for _, app := range req.Apps {
+		app = strings.TrimSpace(app)
+		if app != "" && !seen[app] {
+			seen[app] = true
+			e.Apps = append(e.Apps, app)
+		}
+	}
+	if len(e.Apps) == 0 {
+		return e, fmt.Errorf("Pick at least one app")
+	}
+
+	if req.Scoring != nil {
+		if err := req.Scoring.Validate(); err != nil {
+			return e, err
+		}
+		e.Scoring = *req.Scoring
+	}
+	return e, nil
+}
+
+func eventIDFromRequest(r *http.Request) (int, error) {
+	return strconv.Atoi(mux.Vars(r)["id"])
+}
+
+// ============================================================
+// Events
+// ============================================================
+
+// handleGetEvents lists every event, newest first.
+func handleGetEvents(w http.ResponseWriter, r *http.Request) {
+	rows, err := appDB.Query(`SELECT ` + eventColumns + ` FROM events e ORDER BY e.starts_at DESC`)
+	if err != nil {
+		log.Printf("Error loading events: %v", err)
+		sendError(w, "Failed to load events", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	events := []Event{}
+	for rows.Next() {
+		e, err := scanEvent(rows)
+		if err != nil {
+			log.Printf("Error scanning event: %v", err)
+			continue
+		}
+		events = append(events, e)
+	}
+	sendJSON(w, map[string]interface{}{"events": events})
+}
+
+// handleCreateEvent sets up a new event.
+func handleCreateEvent(w http.ResponseWriter, r *http.Request) {
+	user, _ := authlib.GetUserFromContext(r.Context())
+
+	e, err := decodeEventRequest(r)
+	if err != nil {
+		sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	scoring, _ := json.Marshal(e.Scoring)
+
+	err = appDB.QueryRow(`
+		INSERT INTO events (name, starts_at, ends_at, apps, scoring, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`, e.Name, e.StartsAt, e.EndsAt, pq.Array(e.Apps), string(scoring), user.ActorEmail()).Scan(&e.ID)
+	if err != nil {
+		log.Printf("Error creating event: %v", err)
+		sendError(w, "Failed to create event", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("🏅 Pub Olympics event %d (%s) created by %s", e.ID, e.Name, user.ActorEmail())
+	created, err := loadEvent(e.ID)
+	if err != nil {
+		sendError(w, "Failed to load event", http.StatusInternalServerError)
+		return
+	}
+	sendJSON(w, created)
+}
+
+// handleUpdateEvent changes an event's name, window, apps or scoring.
+func handleUpdateEvent(w http.ResponseWriter, r *http.Request) {
+	id, err := eventIDFromRequest(r)
+	if err != nil {
+		sendError(w, "Invalid event ID", http.StatusBadRequest)
+		return
+	}
+	e, err := decodeEventRequest(r)
+	if err != nil {
+		sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	scoring, _ := json.Marshal(e.Scoring)
+
+	res, err := appDB.Exec(`
+		UPDATE events SET name = $2, starts_at = $3, ends_at = $4, apps = $5, scoring = $6
+		WHERE id = $1
+	`, id, e.Name, e.StartsAt, e.EndsAt, pq.Array(e.Apps), string(scoring))
+	if err != nil {
+		log.Printf("Error updating event %d: %v", id, err)
+		sendError(w, "Failed to update event", http.StatusInternalServerError)
+		return
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		sendError(w, "Event not found", http.StatusNotFound)
+		return
+	}
+
+	updated, err := loadEvent(id)
+	if err != nil {
+		sendError(w, "Failed to load event", http.StatusInternalServerError)
+		return
+	}
+	sendJSON(w, updated)
+}
+
+// handleDeleteEvent removes an event and its participants. Leaderboard
+// results are untouched.
+func handleDeleteEvent(w http.ResponseWriter, r *http.Request) {
+	id, err := eventIDFromRequest(r)
+	if err != nil {
+		sendError(w, "Invalid event ID", http.StatusBadRequest)
+		return
+	}
+
+	res, err := appDB.Exec(`DELETE FROM events WHERE id = $1`, id)
+	if err != nil {
+		log.Printf("Error deleting event %d: %v", id, err)
+		sendError(w, "Failed to delete event", http.StatusInternalServerError)
+		return
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		sendError(w, "Event not found", http.StatusNotFound)
+		return
+	}
+	sendJSON(w, map[string]interface{}{"success": true})
+}
+
+// handleGetGameTypes lists the game types the leaderboard has results for,
+// for picking an event's apps.
+func handleGetGameTypes(w http.ResponseWriter, r *http.Request) {
+	rows, err := leaderboardDB.Query(`SELECT DISTINCT game_type FROM game_results ORDER BY game_type`)
+	if err != nil {
+		log.Printf("Error loading game types: %v", err)
+		sendError(w, "Failed to load game types", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	gameTypes := []string{}
+	for rows.Next() {
+		var gameType string
+		if rows.Scan(&gameType) == nil {
+			gameTypes = append(gameTypes, gameType)
+		}
+	}
+	sendJSON(w, map[string]interface{}{"gameTypes": gameTypes})
+}
+
+// ============================================================
+// Participants
+// ============================================================
+
+// loadParticipants returns an event's participants and their members.
+func loadParticipants(eventID int) ([]Participant, error) {
+	rows, err := appDB.Query(`
+		SELECT p.id, p.name, p.is_team, COALESCE(array_agg(m.user_email ORDER BY m.user_email) FILTER (WHERE m.user_email IS NOT NULL), '{}')
+		FROM participants p
+		LEFT JOIN participant_members m ON m.participant_id = p.id
+		WHERE p.event_id = $1
+		GROUP BY p.id
+		ORDER BY p.name
+	`, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	participants := []Participant{}
+	var emails []string
+	for rows.Next() {
+		var p Participant
+		var memberEmails []string
+		if err := rows.Scan(&p.ID, &p.Name, &p.IsTeam, pq.Array(&memberEmails)); err != nil {
+			return nil, err
+		}
+		for _, email := range memberEmails {
+			p.Members = append(p.Members, Member{Email: email})
+		}
+		emails = append(emails, memberEmails...)
+		participants = append(participants, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	names := authlib.PublicNames(identityDB, emails)
+	for i := range participants {
+		for j := range participants[i].Members {
+			participants[i].Members[j].Name = names[participants[i].Members[j].Email]
+		}
+	}
+	return participants, nil
+}
+
+// handleGetParticipants lists an event's participants.
+func handleGetParticipants(w http.ResponseWriter, r *http.Request) {
+	id, err := eventIDFromRequest(r)
+	if err != nil {
+		sendError(w, "Invalid event ID", http.StatusBadRequest)
+		return
+	}
+	participants, err := loadParticipants(id)
+	if err != nil {
+		log.Printf("Error loading participants for event %d: %v", id, err)
+		sendError(w, "Failed to load participants", http.StatusInternalServerError)
+		return
+	}
+	sendJSON(w, map[string]interface{}{"participants": participants})
+}
+
+// handleAddParticipant registers a player or a team. A player needs exactly
+// one member and is named after them unless a name is given; a team needs a
+// name and at least one member.
+func handleAddParticipant(w http.ResponseWriter, r *http.Request) {
+	eventID, err := eventIDFromRequest(r)
+	if err != nil {
+		sendError(w, "Invalid event ID", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		Name    string   `json:"name"`
+		IsTeam  bool     `json:"isTeam"`
+		Members []string `json:"members"` // emails
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+
+	var members []string
+	seen := map[string]bool{}
+	for _, email := range req.Members {
+		email = strings.ToLower(strings.TrimSpace(email))
+		if email != "" && !seen[email] {
+			seen[email] = true
+			members = append(members, email)
+		}
+	}
+	switch {
+	case len(members) == 0:
+		sendError(w, "At least one member is required", http.StatusBadRequest)
+		return
+	case !req.IsTeam && len(members) > 1:
+		sendError(w, "A player has one member; register a team instead", http.StatusBadRequest)
+		return
+	case req.IsTeam && req.Name == "":
+		sendError(w, "Team name is required", http.StatusBadRequest)
+		return
+	}
+
+	profiles, err := authlib.LoadProfiles(identityDB, members)
+	if err != nil {
+		log.Printf("Error loading profiles: %v", err)
+		sendError(w, "Failed to look up members", http.StatusInternalServerError)
+		return
+	}
+	for _, email := range members {
+		if _, ok := profiles[email]; !ok {
+			sendError(w, "No account for "+email, http.StatusBadRequest)
+			return
+		}
+	}
+	if req.Name == "" {
+		req.Name = profiles[members[0]].PublicName()
+	}
+
+	tx, err := appDB.Begin()
+	if err != nil {
+		sendError(w, "Failed to add participant", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	var participantID int
+	err = tx.QueryRow(`
+		INSERT INTO participants (event_id, name, is_team) VALUES ($1, $2, $3)
+		RETURNING id
+	`, eventID, req.Name, req.IsTeam).Scan(&participantID)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code.Name() {
+			case "unique_violation":
+				sendError(w, "There is already a participant called "+req.Name, http.StatusConflict)
+				return
+			case "foreign_key_violation":
+				sendError(w, "Event not found", http.StatusNotFound)
+				return
+			}
+		}
+		log.Printf("Error adding participant to event %d: %v", eventID, err)
+		sendError(w, "Failed to add participant", http.StatusInternalServerError)
+		return
+	}
+
+	for _, email := range members {
+		if _, err := tx.Exec(`
+			INSERT INTO participant_members (participant_id, event_id, user_email) VALUES ($1, $2, $3)
+		`, participantID, eventID, email); err != nil {
+			if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+				sendError(w, email+" is already competing in this event", http.StatusConflict)
+				return
+			}
+			log.Printf("Error adding member to participant %d: %v", participantID, err)
+			sendError(w, "Failed to add participant", http.StatusInternalServerError)
+			return
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		sendError(w, "Failed to add participant", http.StatusInternalServerError)
+		return
+	}
+
+	sendJSON(w, map[string]interface{}{"id": participantID, "name": req.Name})
+}
+
+// handleRemoveParticipant withdraws a participant from an event.
+func handleRemoveParticipant(w http.ResponseWriter, r *http.Request) {
+	eventID, err := eventIDFromRequest(r)
+	if err != nil {
+		sendError(w, "Invalid event ID", http.StatusBadRequest)
+		return
+	}
+	participantID, err := strconv.Atoi(mux.Vars(r)["participantId"])
+	if err != nil {
+		sendError(w, "Invalid participant ID", http.StatusBadRequest)
+		return
+	}
+
+	res, err := appDB.Exec(`DELETE FROM participants WHERE id = $1 AND event_id = $2`, participantID, eventID)
+	if err != nil {
+		log.Printf("Error removing participant %d: %v", participantID, err)
+		sendError(w, "Failed to remove participant", http.StatusInternalServerError)
+		return
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		sendError(w, "Participant not found", http.StatusNotFound)
+		return
+	}
+	sendJSON(w, map[string]interface{}{"success": true})
+}
+
+// ============================================================
+// Helpers
+// ============================================================
+
+// sendError sends a JSON error response.
+func sendError(w http.ResponseWriter, message string, code int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
+
+// sendJSON sends a JSON success response.
+func sendJSON(w http.ResponseWriter, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(data)
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+
+	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/achgithub/activity-hub-common/cli"
+	"github.com/achgithub/activity-hub-common/config"
+	"github.com/achgithub/activity-hub-common/database"
+	apphttp "github.com/achgithub/activity-hub-common/http"
+	"github.com/achgithub/activity-hub-common/maintenance"
+	"github.com/gorilla/mux"
+)
+
+var (
+	identityDB    *sql.DB
+	appDB         *sql.DB // pub_olympics_db — events and participants
+	leaderboardDB *sql.DB // leaderboard_db — read-only, results for the medal table
+)
+
+func main() {
+	cli.New("pub-olympics",
+		cli.Serve(serve),
+		cli.Migrate(openAppDB, cli.Migrations{
+			Dir:    "../database",
+			Schema: []string{"schema.sql"},
+		}),
+	).Main()
+}
+
+// openAppDB connects to pub_olympics_db for commands other than serve
+func openAppDB() (*sql.DB, error) {
+	return database.InitDatabaseByName("pub_olympics_db")
+}
+
+func serve() {
+	var err error
+
+	identityDB, err = database.InitIdentityDatabase()
+	if err != nil {
+		log.Fatal("Failed to connect to identity database:", err)
+	}
+	defer identityDB.Close()
+
+	appDB, err = database.InitDatabaseByName("pub_olympics_db")
+	if err != nil {
+		log.Fatal("Failed to connect to Pub Olympics database:", err)
+	}
+	defer appDB.Close()
+
+	leaderboardDB, err = database.InitDatabaseByName("leaderboard_db")
+	if err != nil {
+		log.Fatal("Failed to connect to leaderboard database:", err)
+	}
+	defer leaderboardDB.Close()
+
+	r := mux.NewRouter()
+
+	// Public routes (no auth required) — the medal table goes on the TV
+	r.HandleFunc("/api/config", handleConfig).Methods("GET")
+	r.HandleFunc("/api/medals", handleGetCurrentMedalTable).Methods("GET")
+	r.HandleFunc("/api/medals/{id}", handleGetMedalTable).Methods("GET")
+
+	// Event setup - require game_manager role
+	api := r.PathPrefix("/api").Subrouter()
+	api.Use(authlib.Middleware(identityDB))
+	api.Use(authlib.RequireRole("game_manager"))
+	api.HandleFunc("/game-types", handleGetGameTypes).Methods("GET")
+	api.HandleFunc("/olympics", handleGetEvents).Methods("GET")
+	api.HandleFunc("/olympics", handleCreateEvent).Methods("POST")
+	api.HandleFunc("/olympics/{id}", handleUpdateEvent).Methods("PUT")
+	api.HandleFunc("/olympics/{id}", handleDeleteEvent).Methods("DELETE")
+	api.HandleFunc("/olympics/{id}/participants", handleGetParticipants).Methods("GET")
+	api.HandleFunc("/olympics/{id}/participants", handleAddParticipant).Methods("POST")
+	api.HandleFunc("/olympics/{id}/participants/{participantId}", handleRemoveParticipant).Methods("DELETE")
+
+	// Serve React frontend
+	r.PathPrefix("/static/").Handler(http.FileServer(http.Dir("./static")))
+	r.PathPrefix("/").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "./static/index.html")
+	})
+
+	cors := apphttp.NewCORSPolicy(identityDB)
+	appMaintenance := maintenance.New(identityDB)
+
+	port := config.GetEnv("PORT", "5090")
+	log.Printf("🚀 Pub Olympics starting on :%s", port)
+	log.Fatal(http.ListenAndServe(":"+port, cors.Middleware(appMaintenance.Middleware("pub-olympics")(apphttp.Versioned(r)))))
+}
+
+func handleConfig(w http.ResponseWriter, r *http.Request) {
+	apphttp.WriteConfig(w, apphttp.AppConfig{
+		AppID:        "pub-olympics",
+		Name:         "Pub Olympics",
+		Icon:         "🏅",
+		Description:  "Run a Pub Olympics across several games with a combined medal table",
+		Capabilities: apphttp.Capabilities{Displays: true, Teams: true},
+	})
+}
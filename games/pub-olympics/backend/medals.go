@@ -0,0 +1,317 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/lib/pq"
+)
+
+// Scoring is how an event turns results into medals and medals into the
+// combined table. In each app participants are ranked on standing points;
+// the top places win medals, and each medal is worth table points.
+type Scoring struct {
+	Win    int   `json:"win"`    // Standing points per win
+	Draw   int   `json:"draw"`   // Standing points per draw
+	Loss   int   `json:"loss"`   // Standing points per loss (turning up counts)
+	Medals []int `json:"medals"` // Table points for gold, silver, bronze
+}
+
+var defaultScoring = Scoring{Win: 3, Draw: 1, Loss: 0, Medals: []int{3, 2, 1}}
+
+var medalNames = []string{"gold", "silver", "bronze"}
+
+// Validate checks a scoring schema from a request.
+func (s Scoring) Validate() error {
+	if s.Win < 0 || s.Draw < 0 || s.Loss < 0 {
+		return fmt.Errorf("Scoring points can't be negative")
+	}
+	if len(s.Medals) == 0 || len(s.Medals) > len(medalNames) {
+		return fmt.Errorf("Scoring needs table points for 1 to %d medals", len(medalNames))
+	}
+	for _, points := range s.Medals {
+		if points < 0 {
+			return fmt.Errorf("Medal points can't be negative")
+		}
+	}
+	return nil
+}
+
+// Result is a leaderboard result between two participants. For a draw it
+// doesn't matter which side is which.
+type Result struct {
+	App    string
+	Winner int // participant IDs
+	Loser  int
+	IsDraw bool
+}
+
+// AppStanding is a participant's record in one app during the event.
+type AppStanding struct {
+	ParticipantID int    `json:"participantId"`
+	Name          string `json:"name"`
+	Played        int    `json:"played"`
+	Wins          int    `json:"wins"`
+	Draws         int    `json:"draws"`
+	Losses        int    `json:"losses"`
+	Points        int    `json:"points"`
+	Rank          int    `json:"rank"`
+	Medal         string `json:"medal,omitempty"` // gold, silver, bronze
+}
+
+// AppTable is the standings for one app.
+type AppTable struct {
+	App       string        `json:"app"`
+	Standings []AppStanding `json:"standings"`
+}
+
+// MedalRow is a participant's line in the combined medal table.
+type MedalRow struct {
+	Rank          int    `json:"rank"`
+	ParticipantID int    `json:"participantId"`
+	Name          string `json:"name"`
+	IsTeam        bool   `json:"isTeam"`
+	Gold          int    `json:"gold"`
+	Silver        int    `json:"silver"`
+	Bronze        int    `json:"bronze"`
+	Points        int    `json:"points"`
+}
+
+// MedalTable is what displays show for an event.
+type MedalTable struct {
+	Event     *Event     `json:"event"`
+	Table     []MedalRow `json:"table"`
+	Apps      []AppTable `json:"apps"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+}
+
+// computeMedalTable ranks participants in each app, awards medals to the top
+// places (ties share a medal) and combines them into one table ordered by
+// table points, then golds, silvers and bronzes.
+func computeMedalTable(scoring Scoring, apps []string, participants []Participant, results []Result) ([]MedalRow, []AppTable) {
+	names := map[int]string{}
+	rows := make([]MedalRow, 0, len(participants))
+	rowIndex := map[int]int{}
+	for _, p := range participants {
+		names[p.ID] = p.Name
+		rowIndex[p.ID] = len(rows)
+		rows = append(rows, MedalRow{ParticipantID: p.ID, Name: p.Name, IsTeam: p.IsTeam})
+	}
+
+	byApp := map[string]map[int]*AppStanding{}
+	for _, app := range apps {
+		byApp[app] = map[int]*AppStanding{}
+	}
+	standing := func(app string, id int) *AppStanding {
+		s := byApp[app][id]
+		if s == nil {
+			s = &AppStanding{ParticipantID: id, Name: names[id]}
+			byApp[app][id] = s
+		}
+		return s
+	}
+	for _, res := range results {
+		if byApp[res.App] == nil || res.Winner == res.Loser {
+			continue
+		}
+		winner, loser := standing(res.App, res.Winner), standing(res.App, res.Loser)
+		winner.Played++
+		loser.Played++
+		if res.IsDraw {
+			winner.Draws++
+			loser.Draws++
+			winner.Points += scoring.Draw
+			loser.Points += scoring.Draw
+		} else {
+			winner.Wins++
+			loser.Losses++
+			winner.Points += scoring.Win
+			loser.Points += scoring.Loss
+		}
+	}
+
+	tables := make([]AppTable, 0, len(apps))
+	for _, app := range apps {
+		standings := make([]AppStanding, 0, len(byApp[app]))
+		for _, s := range byApp[app] {
+			standings = append(standings, *s)
+		}
+		sort.Slice(standings, func(i, j int) bool {
+			a, b := standings[i], standings[j]
+			if a.Points != b.Points {
+				return a.Points > b.Points
+			}
+			if a.Wins != b.Wins {
+				return a.Wins > b.Wins
+			}
+			return a.Name < b.Name
+		})
+		for i := range standings {
+			s := &standings[i]
+			if i > 0 && s.Points == standings[i-1].Points && s.Wins == standings[i-1].Wins {
+				s.Rank = standings[i-1].Rank
+			} else {
+				s.Rank = i + 1
+			}
+			if s.Rank > len(scoring.Medals) {
+				continue
+			}
+			s.Medal = medalNames[s.Rank-1]
+			row := &rows[rowIndex[s.ParticipantID]]
+			row.Points += scoring.Medals[s.Rank-1]
+			switch s.Rank {
+			case 1:
+				row.Gold++
+			case 2:
+				row.Silver++
+			case 3:
+				row.Bronze++
+			}
+		}
+		tables = append(tables, AppTable{App: app, Standings: standings})
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		if a.Points != b.Points {
+			return a.Points > b.Points
+		}
+		if a.Gold != b.Gold {
+			return a.Gold > b.Gold
+		}
+		if a.Silver != b.Silver {
+			return a.Silver > b.Silver
+		}
+		if a.Bronze != b.Bronze {
+			return a.Bronze > b.Bronze
+		}
+		return a.Name < b.Name
+	})
+	for i := range rows {
+		r := &rows[i]
+		if i > 0 && r.Points == rows[i-1].Points && r.Gold == rows[i-1].Gold &&
+			r.Silver == rows[i-1].Silver && r.Bronze == rows[i-1].Bronze {
+			r.Rank = rows[i-1].Rank
+		} else {
+			r.Rank = i + 1
+		}
+	}
+	return rows, tables
+}
+
+// collectResults reads the event's results from the leaderboard: games in the
+// included apps, played during the window, between two registered players.
+// Voided results don't count, and neither do self-reported results until the
+// opponent has confirmed them.
+func collectResults(e Event, participants []Participant) ([]Result, error) {
+	memberOf := map[string]int{}
+	var emails []string
+	for _, p := range participants {
+		for _, m := range p.Members {
+			memberOf[m.Email] = p.ID
+			emails = append(emails, m.Email)
+		}
+	}
+	if len(emails) == 0 {
+		return nil, nil
+	}
+
+	rows, err := leaderboardDB.Query(`
+		SELECT game_type, winner_id, loser_id, COALESCE(is_draw, FALSE)
+		FROM game_results
+		WHERE game_type = ANY($1)
+		  AND played_at >= $2 AND played_at < $3
+		  AND winner_id = ANY($4) AND loser_id = ANY($4)
+		  AND NOT COALESCE(voided, FALSE)
+		  AND confirmation = 'confirmed'
+	`, pq.Array(e.Apps), e.StartsAt, e.EndsAt, pq.Array(emails))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []Result
+	for rows.Next() {
+		var app, winnerID, loserID string
+		var isDraw bool
+		if err := rows.Scan(&app, &winnerID, &loserID, &isDraw); err != nil {
+			return nil, err
+		}
+		results = append(results, Result{
+			App:    app,
+			Winner: memberOf[winnerID],
+			Loser:  memberOf[loserID],
+			IsDraw: isDraw,
+		})
+	}
+	return results, rows.Err()
+}
+
+// buildMedalTable works out the medal table for an event from the leaderboard.
+func buildMedalTable(e Event) (MedalTable, error) {
+	participants, err := loadParticipants(e.ID)
+	if err != nil {
+		return MedalTable{}, fmt.Errorf("failed to load participants: %w", err)
+	}
+	var results []Result
+	if e.Status != "upcoming" {
+		if results, err = collectResults(e, participants); err != nil {
+			return MedalTable{}, fmt.Errorf("failed to load results: %w", err)
+		}
+	}
+
+	table, apps := computeMedalTable(e.Scoring, e.Apps, participants, results)
+	e.CreatedBy = "" // public
+	return MedalTable{Event: &e, Table: table, Apps: apps, UpdatedAt: time.Now()}, nil
+}
+
+// handleGetCurrentMedalTable - GET /api/medals
+// The medal table for the live event (or the last finished, or the next one).
+// Public, so the TV needs no login.
+func handleGetCurrentMedalTable(w http.ResponseWriter, r *http.Request) {
+	e, err := loadCurrentEvent()
+	if err == sql.ErrNoRows {
+		sendJSON(w, map[string]interface{}{"event": nil})
+		return
+	} else if err != nil {
+		log.Printf("Error loading current event: %v", err)
+		sendError(w, "Failed to load medal table", http.StatusInternalServerError)
+		return
+	}
+	sendMedalTable(w, e)
+}
+
+// handleGetMedalTable - GET /api/medals/{id}
+func handleGetMedalTable(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		sendError(w, "Invalid event ID", http.StatusBadRequest)
+		return
+	}
+	e, err := loadEvent(id)
+	if err == sql.ErrNoRows {
+		sendError(w, "Event not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Printf("Error loading event %d: %v", id, err)
+		sendError(w, "Failed to load medal table", http.StatusInternalServerError)
+		return
+	}
+	sendMedalTable(w, e)
+}
+
+func sendMedalTable(w http.ResponseWriter, e Event) {
+	table, err := buildMedalTable(e)
+	if err != nil {
+		log.Printf("Error building medal table for event %d: %v", e.ID, err)
+		sendError(w, "Failed to load medal table", http.StatusInternalServerError)
+		return
+	}
+	sendJSON(w, table)
+}
@@ -0,0 +1,41 @@
+-- Pub Olympics Database Schema
+-- Database: pub_olympics_db
+--
+-- An event runs for a window of time across several apps. Results come from
+-- leaderboard_db (game_results) and are never copied here: the medal table is
+-- worked out from the leaderboard whenever it is asked for.
+
+-- Events
+CREATE TABLE IF NOT EXISTS events (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL,
+    apps TEXT[] NOT NULL DEFAULT '{}',  -- leaderboard game types that count, e.g. {tic-tac-toe,dots}
+    scoring JSONB NOT NULL,             -- {"win":3,"draw":1,"loss":0,"medals":[3,2,1]}
+    created_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_events_window ON events(starts_at, ends_at);
+
+-- Participants: a single player or a team competing in an event
+CREATE TABLE IF NOT EXISTS participants (
+    id SERIAL PRIMARY KEY,
+    event_id INTEGER NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    is_team BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(event_id, name)
+);
+
+-- Players competing for each participant. A player competes for one
+-- participant per event.
+CREATE TABLE IF NOT EXISTS participant_members (
+    participant_id INTEGER NOT NULL REFERENCES participants(id) ON DELETE CASCADE,
+    event_id INTEGER NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    user_email VARCHAR(255) NOT NULL,
+    PRIMARY KEY (participant_id, user_email),
+    UNIQUE(event_id, user_email)
+);
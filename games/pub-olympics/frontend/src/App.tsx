@@ -0,0 +1,660 @@
+import React, { useState, useEffect, useMemo, useCallback } from 'react';
+import './App.css';
+
+// --- Types ---
+
+interface Scoring {
+  win: number;
+  draw: number;
+  loss: number;
+  medals: number[];  // table points for gold, silver, bronze
+}
+
+interface OlympicsEvent {
+  id: number;
+  name: string;
+  startsAt: string;
+  endsAt: string;
+  apps: string[];
+  scoring: Scoring;
+  status: 'upcoming' | 'live' | 'finished';
+  participantCount: number;
+}
+
+interface Participant {
+  id: number;
+  name: string;
+  isTeam: boolean;
+  members: { email: string; name: string }[];
+}
+
+interface MedalRow {
+  rank: number;
+  participantId: number;
+  name: string;
+  isTeam: boolean;
+  gold: number;
+  silver: number;
+  bronze: number;
+  points: number;
+}
+
+interface AppStanding {
+  participantId: number;
+  name: string;
+  played: number;
+  wins: number;
+  draws: number;
+  losses: number;
+  points: number;
+  rank: number;
+  medal?: 'gold' | 'silver' | 'bronze';
+}
+
+// GET /api/medals — also what the TV shows
+interface MedalTable {
+  event: OlympicsEvent | null;
+  table?: MedalRow[];
+  apps?: { app: string; standings: AppStanding[] }[];
+  updatedAt?: string;
+}
+
+interface EventForm {
+  name: string;
+  startsAt: string;  // datetime-local
+  endsAt: string;
+  apps: string[];
+  win: number;
+  draw: number;
+  loss: number;
+  medals: string;    // comma-separated table points, e.g. "3,2,1"
+}
+
+const MEDAL_ICONS: Record<string, string> = { gold: '🥇', silver: '🥈', bronze: '🥉' };
+
+const STATUS_BADGES: Record<string, string> = {
+  upcoming: 'ah-badge ah-badge--info',
+  live: 'ah-badge ah-badge--success',
+  finished: 'ah-badge ah-badge--neutral',
+};
+
+const emptyForm: EventForm = {
+  name: '', startsAt: '', endsAt: '', apps: [],
+  win: 3, draw: 1, loss: 0, medals: '3,2,1',
+};
+
+// ISO timestamp → value for a datetime-local input
+function toLocalInput(iso: string): string {
+  const d = new Date(iso);
+  const pad = (n: number) => String(n).padStart(2, '0');
+  return `${d.getFullYear()}-${pad(d.getMonth() + 1)}-${pad(d.getDate())}T${pad(d.getHours())}:${pad(d.getMinutes())}`;
+}
+
+function formatWindow(e: OlympicsEvent): string {
+  const start = new Date(e.startsAt);
+  const end = new Date(e.endsAt);
+  return `${start.toLocaleString([], { dateStyle: 'medium', timeStyle: 'short' })} – ${end.toLocaleString([], { dateStyle: 'medium', timeStyle: 'short' })}`;
+}
+
+// --- Hooks ---
+
+function useUrlParams() {
+  return useMemo(() => {
+    const params = new URLSearchParams(window.location.search);
+    return {
+      token: params.get('token') || sessionStorage.getItem('token') || '',
+      view: params.get('view') || '',
+    };
+  }, []);
+}
+
+function useApi(token: string) {
+  return useCallback(
+    async (path: string, options: RequestInit = {}) => {
+      const headers: Record<string, string> = {
+        'Content-Type': 'application/json',
+        ...(token ? { Authorization: `Bearer ${token}` } : {}),
+        ...(options.headers as Record<string, string> || {}),
+      };
+      const res = await fetch(path, { ...options, headers });
+      if (!res.ok) {
+        const err = await res.json().catch(() => ({ error: 'Request failed' }));
+        throw new Error(err.error || 'Request failed');
+      }
+      return res.json();
+    },
+    [token]
+  );
+}
+
+// --- Main App ---
+
+function App() {
+  const { token, view } = useUrlParams();
+  const api = useApi(token);
+
+  const [events, setEvents] = useState<OlympicsEvent[]>([]);
+  const [gameTypes, setGameTypes] = useState<string[]>([]);
+  const [selected, setSelected] = useState<OlympicsEvent | null>(null);
+  const [participants, setParticipants] = useState<Participant[]>([]);
+  const [medals, setMedals] = useState<MedalTable | null>(null);
+  const [form, setForm] = useState<EventForm>(emptyForm);
+  const [editingId, setEditingId] = useState<number | null>(null);
+  const [showForm, setShowForm] = useState(false);
+  const [newParticipant, setNewParticipant] = useState({ name: '', isTeam: false, members: '' });
+  const [loading, setLoading] = useState(true);
+  const [error, setError] = useState<string | null>(null);
+  const [successMsg, setSuccessMsg] = useState<string | null>(null);
+
+  const goToLobby = () => { window.location.href = `http://${window.location.hostname}:3001`; };
+
+  const flash = (msg: string) => {
+    setSuccessMsg(msg);
+    setTimeout(() => setSuccessMsg(null), 3000);
+  };
+
+  const loadEvents = useCallback(async () => {
+    const data = await api('/api/olympics');
+    setEvents(data.events || []);
+    return (data.events || []) as OlympicsEvent[];
+  }, [api]);
+
+  const loadEventDetail = useCallback(async (event: OlympicsEvent) => {
+    const [p, m] = await Promise.all([
+      api(`/api/olympics/${event.id}/participants`),
+      api(`/api/medals/${event.id}`),
+    ]);
+    setParticipants(p.participants || []);
+    setMedals(m);
+  }, [api]);
+
+  useEffect(() => {
+    if (!token || view === 'tv') return;
+    Promise.all([loadEvents(), api('/api/game-types')])
+      .then(([, types]) => setGameTypes(types.gameTypes || []))
+      .catch(err => setError(err.message))
+      .finally(() => setLoading(false));
+  }, [token, view, api, loadEvents]);
+
+  useEffect(() => {
+    if (!selected) return;
+    loadEventDetail(selected).catch(err => setError(err.message));
+  }, [selected, loadEventDetail]);
+
+  const openCreate = () => {
+    setForm(emptyForm);
+    setEditingId(null);
+    setShowForm(true);
+  };
+
+  const openEdit = (e: OlympicsEvent) => {
+    setForm({
+      name: e.name,
+      startsAt: toLocalInput(e.startsAt),
+      endsAt: toLocalInput(e.endsAt),
+      apps: e.apps,
+      win: e.scoring.win,
+      draw: e.scoring.draw,
+      loss: e.scoring.loss,
+      medals: e.scoring.medals.join(','),
+    });
+    setEditingId(e.id);
+    setShowForm(true);
+  };
+
+  const toggleApp = (app: string) => {
+    setForm(f => ({
+      ...f,
+      apps: f.apps.includes(app) ? f.apps.filter(a => a !== app) : [...f.apps, app],
+    }));
+  };
+
+  const handleSaveEvent = async () => {
+    const body = {
+      name: form.name,
+      startsAt: new Date(form.startsAt).toISOString(),
+      endsAt: new Date(form.endsAt).toISOString(),
+      apps: form.apps,
+      scoring: {
+        win: form.win,
+        draw: form.draw,
+        loss: form.loss,
+        medals: form.medals.split(',').map(m => parseInt(m.trim(), 10)).filter(m => !isNaN(m)),
+      },
+    };
+    try {
+      const saved: OlympicsEvent = await api(editingId ? `/api/olympics/${editingId}` : '/api/olympics', {
+        method: editingId ? 'PUT' : 'POST',
+        body: JSON.stringify(body),
+      });
+      await loadEvents();
+      setShowForm(false);
+      setSelected(saved);
+      flash(editingId ? 'Event updated' : 'Event created');
+    } catch (err: any) {
+      setError(err.message);
+    }
+  };
+
+  const handleDeleteEvent = async (e: OlympicsEvent) => {
+    if (!window.confirm(`Delete ${e.name}? Leaderboard results are kept.`)) return;
+    try {
+      await api(`/api/olympics/${e.id}`, { method: 'DELETE' });
+      setSelected(null);
+      await loadEvents();
+      flash('Event deleted');
+    } catch (err: any) {
+      setError(err.message);
+    }
+  };
+
+  const handleAddParticipant = async () => {
+    if (!selected) return;
+    const members = newParticipant.members.split(',').map(m => m.trim()).filter(Boolean);
+    try {
+      await api(`/api/olympics/${selected.id}/participants`, {
+        method: 'POST',
+        body: JSON.stringify({ name: newParticipant.name, isTeam: newParticipant.isTeam, members }),
+      });
+      setNewParticipant({ name: '', isTeam: newParticipant.isTeam, members: '' });
+      await loadEventDetail(selected);
+      await loadEvents();
+      flash('Participant registered');
+    } catch (err: any) {
+      setError(err.message);
+    }
+  };
+
+  const handleRemoveParticipant = async (p: Participant) => {
+    if (!selected || !window.confirm(`Withdraw ${p.name}?`)) return;
+    try {
+      await api(`/api/olympics/${selected.id}/participants/${p.id}`, { method: 'DELETE' });
+      await loadEventDetail(selected);
+      await loadEvents();
+    } catch (err: any) {
+      setError(err.message);
+    }
+  };
+
+  // --- Render ---
+
+  if (view === 'tv') {
+    return <MedalScreen />;
+  }
+
+  if (!token) {
+    return (
+      <div className="ah-container ah-container--narrow">
+        <h2>Pub Olympics</h2>
+        <p style={{ color: '#666', marginTop: 20 }}>
+          Access this app through the lobby.
+        </p>
+        <button className="ah-btn-primary" onClick={goToLobby}>
+          Go to Lobby
+        </button>
+      </div>
+    );
+  }
+
+  if (loading) {
+    return <div className="ah-container"><p style={{ color: '#666' }}>Loading...</p></div>;
+  }
+
+  // All known game types, plus any an event already uses that have no results yet
+  const appChoices = Array.from(new Set([...gameTypes, ...form.apps])).sort();
+
+  return (
+    <>
+      <div className="ah-app-header">
+        <div className="ah-app-header-left">
+          <h1 className="ah-app-title">🏅 Pub Olympics</h1>
+        </div>
+        <div className="ah-app-header-right">
+          <button className="ah-lobby-btn" onClick={goToLobby}>← Lobby</button>
+        </div>
+      </div>
+
+      <div className="ah-container">
+        {error && (
+          <div className="ah-banner ah-banner--error" onClick={() => setError(null)}>
+            {error} — click to dismiss
+          </div>
+        )}
+        <Toast message={successMsg} />
+
+        {/* Events */}
+        <div className="ah-card">
+          <div className="ah-flex-between">
+            <h3 style={{ margin: 0 }}>Events</h3>
+            <button className="ah-btn-primary" onClick={openCreate}>+ New Event</button>
+          </div>
+          {events.length === 0 ? (
+            <p style={{ color: '#666' }}>No events yet.</p>
+          ) : (
+            <div className="ah-list" style={{ marginTop: 12 }}>
+              {events.map(e => (
+                <div
+                  key={e.id}
+                  className="ah-list-item"
+                  style={{ cursor: 'pointer', fontWeight: selected?.id === e.id ? 600 : 400 }}
+                  onClick={() => setSelected(e)}
+                >
+                  <div className="ah-flex-between">
+                    <span>{e.name}</span>
+                    <span className={STATUS_BADGES[e.status]}>{e.status}</span>
+                  </div>
+                  <div className="ah-meta">
+                    {formatWindow(e)} · {e.apps.join(', ')} · {e.participantCount} registered
+                  </div>
+                </div>
+              ))}
+            </div>
+          )}
+        </div>
+
+        {/* Create / edit */}
+        {showForm && (
+          <div className="ah-card">
+            <h3 style={{ marginTop: 0 }}>{editingId ? 'Edit Event' : 'New Event'}</h3>
+            <label className="ah-label">Name</label>
+            <input
+              className="ah-input"
+              value={form.name}
+              onChange={e => setForm({ ...form, name: e.target.value })}
+              placeholder="Summer Pub Olympics"
+            />
+            <div className="ah-flex" style={{ gap: 12 }}>
+              <div style={{ flex: 1 }}>
+                <label className="ah-label">Starts</label>
+                <input
+                  className="ah-input"
+                  type="datetime-local"
+                  value={form.startsAt}
+                  onChange={e => setForm({ ...form, startsAt: e.target.value })}
+                />
+              </div>
+              <div style={{ flex: 1 }}>
+                <label className="ah-label">Ends</label>
+                <input
+                  className="ah-input"
+                  type="datetime-local"
+                  value={form.endsAt}
+                  onChange={e => setForm({ ...form, endsAt: e.target.value })}
+                />
+              </div>
+            </div>
+
+            <label className="ah-label">Apps that count</label>
+            {appChoices.length === 0 ? (
+              <p className="ah-meta">No results on the leaderboard yet.</p>
+            ) : (
+              <div className="ah-flex-wrap" style={{ gap: 12 }}>
+                {appChoices.map(app => (
+                  <label key={app} style={{ display: 'flex', gap: 4, alignItems: 'center' }}>
+                    <input type="checkbox" checked={form.apps.includes(app)} onChange={() => toggleApp(app)} />
+                    {app}
+                  </label>
+                ))}
+              </div>
+            )}
+
+            <label className="ah-label">Scoring</label>
+            <div className="ah-flex" style={{ gap: 12 }}>
+              {(['win', 'draw', 'loss'] as const).map(field => (
+                <div key={field} style={{ flex: 1 }}>
+                  <span className="ah-meta">Points per {field}</span>
+                  <input
+                    className="ah-input"
+                    type="number"
+                    min={0}
+                    value={form[field]}
+                    onChange={e => setForm({ ...form, [field]: parseInt(e.target.value, 10) || 0 })}
+                  />
+                </div>
+              ))}
+              <div style={{ flex: 2 }}>
+                <span className="ah-meta">Medal table points (gold, silver, bronze)</span>
+                <input
+                  className="ah-input"
+                  value={form.medals}
+                  onChange={e => setForm({ ...form, medals: e.target.value })}
+                  placeholder="3,2,1"
+                />
+              </div>
+            </div>
+
+            <div className="ah-flex" style={{ gap: 8, marginTop: 12 }}>
+              <button
+                className="ah-btn-primary"
+                onClick={handleSaveEvent}
+                disabled={!form.name || !form.startsAt || !form.endsAt || form.apps.length === 0}
+              >
+                {editingId ? 'Save' : 'Create'}
+              </button>
+              <button className="ah-btn-outline" onClick={() => setShowForm(false)}>Cancel</button>
+            </div>
+          </div>
+        )}
+
+        {/* Selected event */}
+        {selected && (
+          <>
+            <div className="ah-card">
+              <div className="ah-flex-between">
+                <h3 style={{ margin: 0 }}>{selected.name}</h3>
+                <div className="ah-flex" style={{ gap: 8 }}>
+                  <button className="ah-btn-outline ah-btn-sm" onClick={() => openEdit(selected)}>Edit</button>
+                  <button className="ah-btn-danger-sm" onClick={() => handleDeleteEvent(selected)}>Delete</button>
+                </div>
+              </div>
+              <p className="ah-meta">
+                {formatWindow(selected)} · win {selected.scoring.win} / draw {selected.scoring.draw} / loss {selected.scoring.loss}
+                {' '}· medals worth {selected.scoring.medals.join(', ')}
+              </p>
+
+              <h4>Participants</h4>
+              {participants.length === 0 ? (
+                <p style={{ color: '#666' }}>Nobody registered yet.</p>
+              ) : (
+                participants.map(p => (
+                  <div key={p.id} className="ah-flex-between" style={{ padding: '6px 0', borderBottom: '1px solid #E7E5E4' }}>
+                    <span>
+                      {p.isTeam ? '👥' : '👤'} <strong>{p.name}</strong>
+                      {p.isTeam && <span className="ah-meta"> — {p.members.map(m => m.name).join(', ')}</span>}
+                    </span>
+                    <button className="ah-btn-danger-sm" onClick={() => handleRemoveParticipant(p)}>Withdraw</button>
+                  </div>
+                ))
+              )}
+
+              <div className="ah-inline-form" style={{ marginTop: 12 }}>
+                <select
+                  className="ah-select"
+                  value={newParticipant.isTeam ? 'team' : 'player'}
+                  onChange={e => setNewParticipant({ ...newParticipant, isTeam: e.target.value === 'team' })}
+                >
+                  <option value="player">Player</option>
+                  <option value="team">Team</option>
+                </select>
+                <input
+                  className="ah-input"
+                  value={newParticipant.name}
+                  onChange={e => setNewParticipant({ ...newParticipant, name: e.target.value })}
+                  placeholder={newParticipant.isTeam ? 'Team name' : 'Name (optional)'}
+                />
+                <input
+                  className="ah-input"
+                  value={newParticipant.members}
+                  onChange={e => setNewParticipant({ ...newParticipant, members: e.target.value })}
+                  placeholder={newParticipant.isTeam ? 'Member emails, comma-separated' : 'Player email'}
+                />
+                <button className="ah-btn-primary" onClick={handleAddParticipant} disabled={!newParticipant.members}>
+                  Register
+                </button>
+              </div>
+            </div>
+
+            {medals?.table && (
+              <div className="ah-card">
+                <div className="ah-flex-between">
+                  <h3 style={{ margin: 0 }}>Medal Table</h3>
+                  <a href="/?view=tv" target="_blank" rel="noreferrer" className="ah-meta">Open TV view ↗</a>
+                </div>
+                <MedalTableRows rows={medals.table} />
+                {medals.apps?.map(a => (
+                  <div key={a.app} style={{ marginTop: 16 }}>
+                    <h4 style={{ marginBottom: 4 }}>{a.app}</h4>
+                    {a.standings.length === 0 ? (
+                      <p className="ah-meta">No results yet.</p>
+                    ) : (
+                      a.standings.map(st => (
+                        <div key={st.participantId} className="ah-flex-between" style={{ padding: '4px 0' }}>
+                          <span>{st.rank}. {st.name} {st.medal && MEDAL_ICONS[st.medal]}</span>
+                          <span className="ah-meta">
+                            P{st.played} W{st.wins} D{st.draws} L{st.losses} · {st.points} pts
+                          </span>
+                        </div>
+                      ))
+                    )}
+                  </div>
+                ))}
+              </div>
+            )}
+          </>
+        )}
+      </div>
+    </>
+  );
+}
+
+// --- Toast: fixed-position success message, no layout shift ---
+
+function Toast({ message }: { message: string | null }) {
+  if (!message) return null;
+  return (
+    <div style={{
+      position: 'fixed',
+      bottom: 24,
+      right: 24,
+      backgroundColor: '#323232',
+      color: 'white',
+      padding: '12px 20px',
+      borderRadius: 8,
+      fontSize: 13,
+      fontWeight: 500,
+      zIndex: 9999,
+      boxShadow: '0 2px 8px rgba(0,0,0,0.25)',
+      pointerEvents: 'none',
+      maxWidth: 320,
+    }}>
+      {message}
+    </div>
+  );
+}
+
+// --- Sub-components ---
+
+function MedalTableRows({ rows }: { rows: MedalRow[] }) {
+  if (rows.length === 0) return <p style={{ color: '#666' }}>Nobody registered yet.</p>;
+  return (
+    <div className="ah-table">
+      <div className="ah-table-header">
+        <span style={{ flex: 1 }}>#</span>
+        <span style={{ flex: 4 }}>Name</span>
+        <span style={{ flex: 1 }}>🥇</span>
+        <span style={{ flex: 1 }}>🥈</span>
+        <span style={{ flex: 1 }}>🥉</span>
+        <span style={{ flex: 1 }}>Pts</span>
+      </div>
+      {rows.map(r => (
+        <div key={r.participantId} className="ah-table-row">
+          <span style={{ flex: 1 }}>{r.rank}</span>
+          <span style={{ flex: 4 }}>{r.isTeam ? '👥 ' : ''}{r.name}</span>
+          <span style={{ flex: 1 }}>{r.gold}</span>
+          <span style={{ flex: 1 }}>{r.silver}</span>
+          <span style={{ flex: 1 }}>{r.bronze}</span>
+          <span style={{ flex: 1, fontWeight: 600 }}>{r.points}</span>
+        </div>
+      ))}
+    </div>
+  );
+}
+
+// MedalScreen: full-screen medal table for the pub TV (and the display
+// runtime's medal_table slide). Refreshes every 30 seconds.
+function MedalScreen() {
+  const [medals, setMedals] = useState<MedalTable | null>(null);
+
+  useEffect(() => {
+    const params = new URLSearchParams(window.location.search);
+    const eventId = params.get('event');
+    const load = () => {
+      fetch(eventId ? `/api/medals/${eventId}` : '/api/medals')
+        .then(res => res.ok ? res.json() : null)
+        .then(data => { if (data) setMedals(data); })
+        .catch(() => {});
+    };
+    load();
+    const interval = setInterval(load, 30000);
+    return () => clearInterval(interval);
+  }, []);
+
+  if (!medals?.event) {
+    return <div style={s.tvScreen}><h1 style={s.tvTitle}>🏅 Pub Olympics</h1></div>;
+  }
+
+  const event = medals.event;
+  const table = medals.table || [];
+  return (
+    <div style={s.tvScreen}>
+      <h1 style={s.tvTitle}>🏅 {event.name}</h1>
+      <p style={s.tvSubtitle}>
+        {event.status === 'live' && `Live until ${new Date(event.endsAt).toLocaleTimeString([], { timeStyle: 'short' })}`}
+        {event.status === 'upcoming' && `Starts ${new Date(event.startsAt).toLocaleString([], { dateStyle: 'medium', timeStyle: 'short' })}`}
+        {event.status === 'finished' && 'Final standings'}
+        {' · '}{event.apps.join(' · ')}
+      </p>
+
+      <div style={{ marginTop: 32 }}>
+        <div style={{ ...s.tvRow, color: '#B0BEC5', fontSize: 22 }}>
+          <span style={{ flex: 5 }}>Competitor</span>
+          <span style={s.tvCell}>🥇</span>
+          <span style={s.tvCell}>🥈</span>
+          <span style={s.tvCell}>🥉</span>
+          <span style={s.tvCell}>Pts</span>
+        </div>
+        {table.map(r => (
+          <div key={r.participantId} style={s.tvRow}>
+            <span style={{ flex: 5, fontWeight: 600 }}>{r.rank}. {r.name}</span>
+            <span style={s.tvCell}>{r.gold}</span>
+            <span style={s.tvCell}>{r.silver}</span>
+            <span style={s.tvCell}>{r.bronze}</span>
+            <span style={{ ...s.tvCell, fontWeight: 700 }}>{r.points}</span>
+          </div>
+        ))}
+      </div>
+    </div>
+  );
+}
+
+const s: Record<string, React.CSSProperties> = {
+  tvScreen: {
+    minHeight: '100vh',
+    backgroundColor: '#102027',
+    color: 'white',
+    padding: '48px 64px',
+    boxSizing: 'border-box',
+  },
+  tvTitle: { fontSize: 56, fontWeight: 700, margin: 0 },
+  tvSubtitle: { fontSize: 28, color: '#B0BEC5', margin: '8px 0 0' },
+  tvRow: {
+    display: 'flex',
+    justifyContent: 'space-between',
+    fontSize: 26,
+    padding: '10px 0',
+    borderBottom: '1px solid #37474F',
+  },
+  tvCell: { flex: 1, textAlign: 'center' },
+};
+
+export default App;
@@ -0,0 +1 @@
+/// <reference types="react-scripts" />
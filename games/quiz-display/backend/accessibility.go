@@ -0,0 +1,38 @@
+package main
+
+import "strings"
+
+// Accessibility is a session's accessibility defaults (sessions.settings
+// accessibility, set by the quiz master). The main screen applies large text
+// and high contrast; read-aloud and simplified wording are for phones, but
+// are passed on so every screen sees the same settings.
+type Accessibility struct {
+	LargeText      bool `json:"largeText"`
+	HighContrast   bool `json:"highContrast"`
+	ReadAloud      bool `json:"readAloud"`
+	SimplifiedText bool `json:"simplifiedText"`
+}
+
+// QuestionAccessibility matches the accessibility in quiz-master's
+// question_precache, for a display that reconnects mid-question
+type QuestionAccessibility struct {
+	SimplifiedText string `json:"simplifiedText,omitempty"`
+	ImageAlt       string `json:"imageAlt,omitempty"`
+	ReadAloud      string `json:"readAloud"`
+}
+
+// questionAccessibility fills in the read-aloud default the way quiz-master does
+func questionAccessibility(text, simplified, imageAlt, readAloud string) QuestionAccessibility {
+	a := QuestionAccessibility{
+		SimplifiedText: strings.TrimSpace(simplified),
+		ImageAlt:       strings.TrimSpace(imageAlt),
+		ReadAloud:      strings.TrimSpace(readAloud),
+	}
+	if a.ReadAloud == "" {
+		a.ReadAloud = text
+		if a.ImageAlt != "" {
+			a.ReadAloud += " Picture: " + a.ImageAlt
+		}
+	}
+	return a
+}
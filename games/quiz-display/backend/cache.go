@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/achgithub/activity-hub-common/cache"
+	"github.com/go-redis/redis/v8"
+)
+
+// Every screen in the room polls the display state when it (re)connects, so
+// it's served from Redis. quiz-master drops a session's entry whenever it
+// changes the phase or status (same "cache:quiz-display:" prefix); the TTL
+// covers edits made elsewhere, such as a question fixed in game-admin.
+const cacheTTL = 30 * time.Second
+
+var readCache *cache.Cache
+
+// redisStore adapts the go-redis client to cache.Store
+type redisStore struct {
+	client *redis.Client
+}
+
+func (s redisStore) Get(ctx context.Context, key string) (string, error) {
+	v, err := s.client.Get(ctx, key).Result()
+	if err == redis.Nil {
+		return "", cache.ErrMiss
+	}
+	return v, err
+}
+
+func (s redisStore) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	return s.client.Set(ctx, key, value, ttl).Err()
+}
+
+func (s redisStore) Del(ctx context.Context, keys ...string) error {
+	return s.client.Del(ctx, keys...).Err()
+}
+
+func initCache() {
+	readCache = cache.New(redisStore{redisClient}, "cache:quiz-display:", cacheTTL)
+}
+
+// displaySessionKey is the cache key for a session's display state
+func displaySessionKey(sessionID int) string {
+	return "session:" + strconv.Itoa(sessionID)
+}
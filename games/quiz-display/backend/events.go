@@ -0,0 +1,23 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/achgithub/activity-hub-common/events"
+)
+
+// The session stream forwards quiz-master's events as is; their payloads are
+// registered there. Only the stream's own opening event is registered here.
+
+type StreamOpened struct {
+	SessionID int    `json:"sessionId"`
+	Code      string `json:"code"`
+	Role      string `json:"role"` // Screen role: main, scores or lobby
+}
+
+var evConnected = events.Register[StreamOpened]("connected", 1, "Stream opened")
+
+// sessionKey is a quiz session's envelope session
+func sessionKey(sessionID int) string {
+	return strconv.Itoa(sessionID)
+}
@@ -1,33 +1,37 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
-	"fmt"
 	"net/http"
 	"time"
 
+	"github.com/achgithub/activity-hub-common/cache"
+	"github.com/achgithub/activity-hub-common/events"
+	apphttp "github.com/achgithub/activity-hub-common/http"
 	"github.com/gorilla/mux"
 )
 
 func handleConfig(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"appName": "Quiz Display",
-		"port":    5081,
+	apphttp.WriteConfig(w, apphttp.AppConfig{
+		AppID:        "quiz-display",
+		Name:         "Quiz Display",
+		Icon:         "📺",
+		Description:  "Full-screen TV display for quiz sessions",
+		Capabilities: apphttp.Capabilities{SSE: true, Displays: true, Teams: true},
 	})
 }
 
 func handleGetDisplaySession(w http.ResponseWriter, r *http.Request) {
 	code := mux.Vars(r)["code"]
+	role, ok := screenRole(r)
+	if !ok {
+		http.Error(w, `{"error":"unknown screen role"}`, http.StatusBadRequest)
+		return
+	}
 
-	var id, packID int
-	var name, mode, status string
-	var createdAt time.Time
-	err := quizDB.QueryRow(`
-		SELECT id, pack_id, name, mode, status, created_at
-		FROM sessions WHERE join_code = $1`, code).
-		Scan(&id, &packID, &name, &mode, &status, &createdAt)
+	id, err := sessionIDForCode(r.Context(), code)
 	if err == sql.ErrNoRows {
 		http.Error(w, `{"error":"session not found"}`, http.StatusNotFound)
 		return
@@ -37,27 +41,104 @@ func handleGetDisplaySession(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get pack name
-	var packName string
-	quizDB.QueryRow(`SELECT name FROM quiz_packs WHERE id = $1`, packID).Scan(&packName)
+	session, err := cache.Fetch(r.Context(), readCache, displaySessionKey(id), func() (DisplaySession, error) {
+		return loadDisplaySession(id)
+	})
+	if err == sql.ErrNoRows {
+		http.Error(w, `{"error":"session not found"}`, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	// Dedicated screens get their own payload instead of the current question
+	session.Role = role
+	switch role {
+	case screenScores:
+		session.Phase, session.Question = nil, nil
+		session.Standings, err = cache.Fetch(r.Context(), readCache, displaySessionKey(id)+":scores", func() ([]ScoreEntry, error) {
+			return loadScreenStandings(id)
+		})
+	case screenLobby:
+		// Not cached: teams join through quiz-player, which doesn't invalidate
+		session.Phase, session.Question = nil, nil
+		session.Teams, session.Players, err = loadLobbyTeams(id)
+	}
+	if err != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	// Server time is never cached: displays correct their countdown against it
+	if session.Phase != nil {
+		session.Phase["serverTime"] = time.Now().UTC()
+	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"sessionId":  id,
-		"name":       name,
-		"packName":   packName,
-		"mode":       mode,
-		"status":     status,
-		"createdAt":  createdAt,
+	json.NewEncoder(w).Encode(session)
+}
+
+// DisplaySession is what a display loads when it (re)connects. Phase and
+// Question are for the main screen; dedicated screens get Standings or Teams.
+type DisplaySession struct {
+	SessionID int                    `json:"sessionId"`
+	Name      string                 `json:"name"`
+	PackName  string                 `json:"packName"`
+	Mode      string                 `json:"mode"`
+	Status    string                 `json:"status"`
+	CreatedAt time.Time              `json:"createdAt"`
+	Role      string                 `json:"role"`
+	Phase     map[string]interface{} `json:"phase"`
+	Question  map[string]interface{} `json:"question"`
+	Standings []ScoreEntry           `json:"standings,omitempty"` // Scores screen
+	Teams     []LobbyTeam            `json:"teams,omitempty"`     // Lobby screen
+	Players   int                    `json:"players,omitempty"`   // Lobby screen
+
+	Accessibility Accessibility `json:"accessibility"` // The session's defaults, set by the quiz master
+}
+
+// sessionIDForCode looks up a session by join code. Codes never change, so
+// the lookup is cached.
+func sessionIDForCode(ctx context.Context, code string) (int, error) {
+	return cache.Fetch(ctx, readCache, "code:"+code, func() (int, error) {
+		var id int
+		err := quizDB.QueryRow(`SELECT id FROM sessions WHERE join_code = $1`, code).Scan(&id)
+		return id, err
 	})
 }
 
+func loadDisplaySession(id int) (DisplaySession, error) {
+	s := DisplaySession{SessionID: id}
+	var packID int
+	var accessibility []byte
+	err := quizDB.QueryRow(`
+		SELECT pack_id, name, mode, status, created_at, COALESCE(settings->'accessibility', '{}')
+		FROM sessions WHERE id = $1`, id).
+		Scan(&packID, &s.Name, &s.Mode, &s.Status, &s.CreatedAt, &accessibility)
+	if err != nil {
+		return s, err
+	}
+	json.Unmarshal(accessibility, &s.Accessibility)
+
+	// Get pack name
+	quizDB.QueryRow(`SELECT name FROM quiz_packs WHERE id = $1`, packID).Scan(&s.PackName)
+
+	// Current phase and question, so a display that (re)connects mid-question catches up
+	s.Phase, s.Question = getDisplayPhase(id)
+	return s, nil
+}
+
 func handleDisplayStream(w http.ResponseWriter, r *http.Request) {
 	code := mux.Vars(r)["code"]
+	role, ok := screenRole(r)
+	if !ok {
+		http.Error(w, "unknown screen role", http.StatusBadRequest)
+		return
+	}
 
-	// Look up session ID from code
-	var sessionID int
-	err := quizDB.QueryRow(`SELECT id FROM sessions WHERE join_code = $1`, code).Scan(&sessionID)
+	sessionID, err := sessionIDForCode(r.Context(), code)
 	if err == sql.ErrNoRows {
 		http.Error(w, "session not found", http.StatusNotFound)
 		return
@@ -67,22 +148,24 @@ func handleDisplayStream(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	sub, err := subscribeToSession(r.Context(), sessionID)
+	if err != nil {
+		http.Error(w, "live updates unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	defer sub.Close()
+
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
 	w.Header().Set("Connection", "keep-alive")
 	w.Header().Set("X-Accel-Buffering", "no")
 
-	flusher, ok := w.(http.Flusher)
-	if !ok {
+	if _, ok := w.(http.Flusher); !ok {
 		http.Error(w, "streaming not supported", http.StatusInternalServerError)
 		return
 	}
 
-	fmt.Fprintf(w, "event: connected\ndata: {\"sessionId\":%d,\"code\":\"%s\"}\n\n", sessionID, code)
-	flusher.Flush()
-
-	pubsub, msgChan := subscribeToSession(sessionID)
-	defer pubsub.Close()
+	events.Send(w, evConnected.New(sessionKey(sessionID), StreamOpened{SessionID: sessionID, Code: code, Role: role}))
 
 	ticker := time.NewTicker(30 * time.Second)
 	defer ticker.Stop()
@@ -92,12 +175,78 @@ func handleDisplayStream(w http.ResponseWriter, r *http.Request) {
 		select {
 		case <-ctx.Done():
 			return
-		case msg := <-msgChan:
-			fmt.Fprintf(w, "data: %s\n\n", msg.Payload)
-			flusher.Flush()
+		case payload := <-sub.C:
+			if role != screenMain {
+				e, err := events.Decode(payload)
+				if err != nil || !screenWants(role, e.Type) {
+					continue
+				}
+			}
+			events.Forward(w, payload)
 		case <-ticker.C:
-			fmt.Fprintf(w, "event: ping\ndata: {}\n\n")
-			flusher.Flush()
+			events.Send(w, events.Ping.New(sessionKey(sessionID), events.NoPayload{}))
 		}
 	}
 }
+
+// getDisplayPhase returns the session's question phase (written by quiz-master)
+// and, once a question is loaded, the question in the question_precache shape
+func getDisplayPhase(sessionID int) (map[string]interface{}, map[string]interface{}) {
+	var phase string
+	var roundID, questionID, roundNumber, questionNumber int
+	var changedAt time.Time
+	var deadline sql.NullTime
+	err := quizDB.QueryRow(`
+		SELECT phase, COALESCE(round_id,0), COALESCE(question_id,0), round_number, question_number, changed_at, deadline
+		FROM session_phase WHERE session_id = $1`, sessionID).
+		Scan(&phase, &roundID, &questionID, &roundNumber, &questionNumber, &changedAt, &deadline)
+	if err != nil {
+		return nil, nil
+	}
+
+	state := map[string]interface{}{
+		"phase":          phase,
+		"roundId":        roundID,
+		"questionId":     questionID,
+		"roundNumber":    roundNumber,
+		"questionNumber": questionNumber,
+		"changedAt":      changedAt,
+	}
+	if deadline.Valid {
+		state["deadline"] = deadline.Time
+	}
+	if questionID == 0 {
+		return state, nil
+	}
+
+	var text, imagePath, audioPath, simplified, imageAlt, readAloud string
+	var timeLimit sql.NullInt64
+	err = quizDB.QueryRow(`
+		SELECT q.text, COALESCE(img.file_path,''), COALESCE(aud.file_path,''), r.time_limit_seconds,
+		       COALESCE(q.simplified_text,''), COALESCE(q.image_alt,''), COALESCE(q.read_aloud_text,'')
+		FROM questions q
+		LEFT JOIN media_files img ON img.id = q.image_id
+		LEFT JOIN media_files aud ON aud.id = q.audio_id
+		LEFT JOIN rounds r ON r.id = $2
+		WHERE q.id = $1`, questionID, roundID).
+		Scan(&text, &imagePath, &audioPath, &timeLimit, &simplified, &imageAlt, &readAloud)
+	if err != nil {
+		return state, nil
+	}
+
+	question := map[string]interface{}{
+		"roundId":        roundID,
+		"questionId":     questionID,
+		"questionNumber": questionNumber,
+		"roundNumber":    roundNumber,
+		"questionText":   text,
+		"imageUrl":       imagePath,
+		"audioUrl":       audioPath,
+		"timeLimit":      nil,
+		"accessibility":  questionAccessibility(text, simplified, imageAlt, readAloud),
+	}
+	if timeLimit.Valid {
+		question["timeLimit"] = timeLimit.Int64
+	}
+	return state, question
+}
@@ -5,15 +5,26 @@ import (
 	"log"
 	"net/http"
 
+	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/achgithub/activity-hub-common/cli"
 	"github.com/achgithub/activity-hub-common/config"
 	"github.com/achgithub/activity-hub-common/database"
-	"github.com/gorilla/handlers"
+	"github.com/achgithub/activity-hub-common/events"
+	apphttp "github.com/achgithub/activity-hub-common/http"
+	"github.com/achgithub/activity-hub-common/maintenance"
+	"github.com/achgithub/activity-hub-common/upload"
 	"github.com/gorilla/mux"
 )
 
 var quizDB *sql.DB
 
 func main() {
+	cli.New("quiz-display",
+		cli.Serve(serve),
+	).Main()
+}
+
+func serve() {
 	var err error
 	quizDB, err = database.InitDatabaseByName("quiz_db")
 	if err != nil {
@@ -21,17 +32,31 @@ func main() {
 	}
 	defer quizDB.Close()
 
+	// Identity DB for auth (the host, or a quiz:display kiosk), the CORS policy and maintenance
+	identityDB, err := database.InitIdentityDatabase()
+	if err != nil {
+		log.Fatal("Failed to connect to identity database:", err)
+	}
+	defer identityDB.Close()
+
 	initRedis()
+	initSSEBroker()
+	initCache()
 
 	r := mux.NewRouter()
 
-	// No auth — session code in URL is sufficient for display
 	r.HandleFunc("/api/config", handleConfig).Methods("GET")
-	r.HandleFunc("/api/display/session/{code}", handleGetDisplaySession).Methods("GET")
-	r.HandleFunc("/api/display/stream/{code}", handleDisplayStream).Methods("GET")
+	r.HandleFunc("/api/events/schema", events.SchemaHandler).Methods("GET")
+	r.HandleFunc("/api/openapi.json", apiSpec().Handler).Methods("GET")
+	r.HandleFunc("/api/cache/stats", readCache.StatsHandler).Methods("GET")
+
+	// The screen: a signed-in user (the host), or a kiosk token issued quiz:display
+	kiosk := authlib.RequireKioskCapability("quiz:display")
+	r.Handle("/api/display/session/{code}", authlib.Middleware(identityDB)(kiosk(http.HandlerFunc(handleGetDisplaySession)))).Methods("GET")
+	r.Handle("/api/display/stream/{code}", authlib.SSEMiddleware(identityDB)(kiosk(http.HandlerFunc(handleDisplayStream)))).Methods("GET")
 
 	// Serve shared media uploads (same directory as game-admin)
-	r.PathPrefix("/uploads/").Handler(http.StripPrefix("/uploads/", http.FileServer(http.Dir("./uploads"))))
+	r.PathPrefix("/uploads/").Handler(http.StripPrefix("/uploads/", upload.FileServer("./uploads")))
 
 	// Serve React frontend
 	r.PathPrefix("/static/").Handler(http.FileServer(http.Dir("./static")))
@@ -39,13 +64,10 @@ func main() {
 		http.ServeFile(w, r, "./static/index.html")
 	})
 
-	corsHandler := handlers.CORS(
-		handlers.AllowedOrigins([]string{"*"}),
-		handlers.AllowedMethods([]string{"GET", "OPTIONS"}),
-		handlers.AllowedHeaders([]string{"Content-Type"}),
-	)
+	cors := apphttp.NewCORSPolicy(identityDB)
+	appMaintenance := maintenance.New(identityDB)
 
 	port := config.GetEnv("PORT", "5081")
 	log.Printf("Quiz Display starting on :%s", port)
-	log.Fatal(http.ListenAndServe(":"+port, corsHandler(r)))
+	log.Fatal(http.ListenAndServe(":"+port, cors.Middleware(appMaintenance.Middleware("quiz-display")(apphttp.Versioned(r)))))
 }
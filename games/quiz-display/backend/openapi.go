@@ -0,0 +1,37 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/achgithub/activity-hub-common/cache"
+	apphttp "github.com/achgithub/activity-hub-common/http"
+	"github.com/achgithub/activity-hub-common/openapi"
+)
+
+// apiSpec describes the routes registered in main.go for GET /api/openapi.json.
+// Add a route here whenever one is added there.
+func apiSpec() *openapi.Spec {
+	spec := openapi.New("quiz-display", "1.0.0",
+		"Big-screen view of a pub quiz, for the host or a kiosk token issued quiz:display.")
+
+	public := spec.Group("Public")
+	public.Route("GET", "/api/config", "App configuration").
+		Returns(http.StatusOK, apphttp.AppConfig{})
+	public.Route("GET", "/api/events/schema", "JSON Schema of the session stream events").
+		Produces(http.StatusOK, "application/schema+json")
+	public.Route("GET", "/api/openapi.json", "This document").
+		Returns(http.StatusOK, nil)
+	public.Route("GET", "/api/cache/stats", "Read cache hit/miss counters").
+		Returns(http.StatusOK, cache.StatsReport{})
+
+	display := spec.Group("Display").Auth()
+	display.Route("GET", "/api/display/session/{code}", "Session and the screen's payload: current phase (with serverTime) and question, standings or teams").
+		Query("role", "Screen role: main (default), scores or lobby").
+		Returns(http.StatusOK, DisplaySession{})
+	display.Route("GET", "/api/display/stream/{code}", "Session events for the screen's role").
+		Query("role", "Screen role: main (default), scores or lobby").
+		Query("token", "Stream token, or the kiosk token").
+		Stream()
+
+	return spec
+}
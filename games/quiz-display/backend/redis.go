@@ -2,9 +2,12 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 
+	redislib "github.com/achgithub/activity-hub-common/redis"
+	"github.com/achgithub/activity-hub-common/sse"
 	"github.com/go-redis/redis/v8"
 )
 
@@ -24,9 +27,23 @@ func sessionChannel(sessionID int) string {
 	return fmt.Sprintf("quiz:session:%d:events", sessionID)
 }
 
-func subscribeToSession(sessionID int) (*redis.PubSub, <-chan *redis.Message) {
-	ctx := context.Background()
-	pubsub := redisClient.Subscribe(ctx, sessionChannel(sessionID))
-	ch := pubsub.Channel()
-	return pubsub, ch
+// sseBroker carries session events to this replica's streams over one shared
+// subscription. nil when Redis is unavailable; streams are refused then.
+var sseBroker *sse.Broker
+
+func initSSEBroker() {
+	client, err := redislib.InitRedis()
+	if err != nil {
+		log.Printf("Warning: live updates unavailable: %v", err)
+		return
+	}
+	sseBroker = sse.NewBroker(client)
+}
+
+// subscribeToSession opens a stream on the session's events
+func subscribeToSession(ctx context.Context, sessionID int) (*sse.Subscription, error) {
+	if sseBroker == nil {
+		return nil, errors.New("live updates unavailable")
+	}
+	return sseBroker.Subscribe(ctx, "quiz-display", sessionChannel(sessionID))
 }
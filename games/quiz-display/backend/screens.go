@@ -0,0 +1,149 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+)
+
+// Screen roles let a venue with several TVs point each at the same session
+// code and dedicate it: ?role=scores keeps the standings up all night,
+// ?role=lobby keeps the join code and teams up for late arrivals. The main
+// screen (no role) runs the quiz as before.
+const (
+	screenMain   = "main"
+	screenScores = "scores"
+	screenLobby  = "lobby"
+)
+
+// screenEvents lists the session events sent to each dedicated screen; the
+// main screen gets them all
+var screenEvents = map[string]map[string]bool{
+	screenScores: {
+		"quiz_started":      true,
+		"scores_revealed":   true,
+		"tiebreak_resolved": true,
+		"content_moderated": true,
+		"quiz_ended":        true,
+		// Every screen restyles for large text and high contrast
+		"accessibility_changed": true,
+	},
+	screenLobby: {
+		"quiz_started":      true,
+		"content_moderated": true,
+		"quiz_ended":        true,
+
+		"accessibility_changed": true,
+	},
+}
+
+// screenRole reads ?role=, defaulting to the main screen
+func screenRole(r *http.Request) (string, bool) {
+	switch role := r.URL.Query().Get("role"); role {
+	case "", screenMain:
+		return screenMain, true
+	case screenScores, screenLobby:
+		return role, true
+	default:
+		return "", false
+	}
+}
+
+// screenWants reports whether a screen is sent an event type
+func screenWants(role, eventType string) bool {
+	wanted, dedicated := screenEvents[role]
+	return !dedicated || wanted[eventType]
+}
+
+// publicTeamNameSQL matches quiz-master and quiz-player: flagged names are
+// masked and rejected names replaced
+const publicTeamNameSQL = `CASE t.moderation
+	WHEN 'flagged' THEN COALESCE(t.masked_name, t.name)
+	WHEN 'rejected' THEN 'Team ' || t.id
+	ELSE t.name END`
+
+// ScoreEntry is one team's place in the standings, as quiz-master sends them
+type ScoreEntry struct {
+	TeamID      int    `json:"teamId"`
+	Name        string `json:"name"`
+	Total       int    `json:"total"`
+	RoundPoints int    `json:"roundPoints"`
+	Position    int    `json:"position"`
+}
+
+// LobbyTeam is a team on the lobby screen
+type LobbyTeam struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	Players int    `json:"players"`
+}
+
+// loadScreenStandings returns the final standings of a finished session, or
+// the standings last shown to the room
+func loadScreenStandings(sessionID int) ([]ScoreEntry, error) {
+	rows, err := quizDB.Query(`
+		SELECT entity_id, name, total, position
+		FROM session_results WHERE session_id = $1
+		ORDER BY position, name`, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	standings := []ScoreEntry{}
+	for rows.Next() {
+		var e ScoreEntry
+		if err := rows.Scan(&e.TeamID, &e.Name, &e.Total, &e.Position); err != nil {
+			return nil, err
+		}
+		standings = append(standings, e)
+	}
+	if err := rows.Err(); err != nil || len(standings) > 0 {
+		return standings, err
+	}
+
+	var shown []byte
+	err = quizDB.QueryRow(`
+		SELECT standings FROM score_reveals
+		WHERE session_id = $1 AND standings IS NOT NULL
+		ORDER BY revealed_at DESC, id DESC LIMIT 1`, sessionID).Scan(&shown)
+	if err == sql.ErrNoRows {
+		return standings, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return standings, json.Unmarshal(shown, &standings)
+}
+
+// loadLobbyTeams lists the session's teams under their public names, and
+// counts everyone who has joined (individual quizzes have no teams)
+func loadLobbyTeams(sessionID int) ([]LobbyTeam, int, error) {
+	var players int
+	err := quizDB.QueryRow(`SELECT COUNT(*) FROM session_players WHERE session_id = $1`, sessionID).Scan(&players)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := quizDB.Query(`
+		SELECT t.id, `+publicTeamNameSQL+`, COUNT(sp.id)
+		FROM teams t
+		LEFT JOIN session_players sp ON sp.team_id = t.id
+		WHERE t.session_id = $1
+		GROUP BY t.id
+		ORDER BY t.id`, sessionID)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	teams := []LobbyTeam{}
+	for rows.Next() {
+		var t LobbyTeam
+		if err := rows.Scan(&t.ID, &t.Name, &t.Players); err != nil {
+			return nil, 0, err
+		}
+		teams = append(teams, t)
+	}
+	return teams, players, rows.Err()
+}
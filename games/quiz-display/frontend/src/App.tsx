@@ -8,6 +8,23 @@ interface SessionMeta {
   packName: string;
   mode: string;
   status: string;
+  accessibility?: Accessibility;
+}
+
+// The session's accessibility defaults, set by the Quiz Master. The big screen
+// applies large text, high contrast and simplified wording; reading aloud is for phones.
+interface Accessibility {
+  largeText: boolean;
+  highContrast: boolean;
+  readAloud: boolean;
+  simplifiedText: boolean;
+}
+
+// A question's accessible variants, the same on every screen
+interface QuestionAccessibility {
+  simplifiedText?: string;
+  imageAlt?: string;
+  readAloud: string;
 }
 
 interface CachedQuestion {
@@ -19,6 +36,16 @@ interface CachedQuestion {
   imageUrl: string;
   audioUrl: string;
   timeLimit: number | null;
+  accessibility?: QuestionAccessibility;
+}
+
+// Server-side question phase (quiz-master state machine)
+interface PhaseState {
+  phase: 'idle' | 'loaded' | 'revealed' | 'answers_open' | 'closed' | 'marked' | 'scores_pushed';
+  questionId?: number;
+  changedAt: string;
+  deadline?: string;
+  serverTime: string;
 }
 
 interface ScoreEntry {
@@ -26,18 +53,67 @@ interface ScoreEntry {
   name: string;
   total: number;
   roundPoints: number;
+  position?: number; // Ties share a position until a tie-break splits them
+}
+
+// Tie-break between tied teams; answer and results arrive when it's resolved
+interface TiebreakInfo {
+  kind: 'nearest' | 'sudden_death';
+  question: string;
+  closed: boolean;
+  answer?: string;
+  results?: Array<{ entityId: number; name: string; answerText: string; rank: number | null }>;
 }
 
+// Team on the lobby screen
+interface LobbyTeam {
+  id: number;
+  name: string;
+  players: number;
+}
+
+// Applause meter from player reactions, sent every second while it's moving
+interface Applause {
+  level: number; // 0-100
+  reactions: Record<string, number>; // since the last update
+  total: number;
+}
+
+// main runs the quiz; scores and lobby are dedicated screens (?role=)
+type ScreenRole = 'main' | 'scores' | 'lobby';
+
 type DisplayState =
   | 'idle'
   | 'waiting'
+  | 'lobby'
   | 'question-loading'
   | 'question-reveal'
   | 'music-round'
   | 'answers-closed'
   | 'scores'
+  | 'tiebreak'
   | 'ended';
 
+// The host's token from the shell, or the kiosk token a screen in the pub is
+// opened with (?token=kiosk-...)
+const authToken = () => new URLSearchParams(window.location.search).get('token') || sessionStorage.getItem('token') || '';
+
+// Token for the session stream. A kiosk passes its own - it's only good for
+// the display - and the host swaps theirs for a single-use stream token from
+// the shell, so the session token never goes in the EventSource URL.
+async function streamToken(token: string): Promise<string> {
+  if (token.startsWith('kiosk-')) return token;
+  try {
+    const res = await fetch(`http://${window.location.hostname}:3001/api/auth/stream-token`, {
+      method: 'POST',
+      headers: { 'Authorization': `Bearer ${token}` },
+    });
+    return res.ok ? (await res.json()).token : '';
+  } catch {
+    return '';
+  }
+}
+
 // --- Main App ---
 
 function App() {
@@ -45,40 +121,113 @@ function App() {
     const params = new URLSearchParams(window.location.search);
     return params.get('session') || '';
   }, []);
+  const screenRole = useMemo<ScreenRole>(() => {
+    const role = new URLSearchParams(window.location.search).get('role');
+    return role === 'scores' || role === 'lobby' ? role : 'main';
+  }, []);
 
   const [meta, setMeta] = useState<SessionMeta | null>(null);
   const [displayState, setDisplayState] = useState<DisplayState>('idle');
   const [cachedQuestion, setCachedQuestion] = useState<CachedQuestion | null>(null);
   const [revealedQuestion, setRevealedQuestion] = useState<CachedQuestion | null>(null);
   const [scores, setScores] = useState<ScoreEntry[]>([]);
+  const [tiebreak, setTiebreak] = useState<TiebreakInfo | null>(null);
+  const [lobby, setLobby] = useState<{ teams: LobbyTeam[]; players: number }>({ teams: [], players: 0 });
   const [timeLeft, setTimeLeft] = useState<number | null>(null);
   const [audioSrc, setAudioSrc] = useState<string | null>(null);
+  const [phaseKey, setPhaseKey] = useState('');
+  const [accessibility, setAccessibility] = useState<Accessibility | null>(null);
+  const [applause, setApplause] = useState<Applause | null>(null);
+  const s = useMemo(() => displayStyles(accessibility), [accessibility]);
+  const cachedRef = useRef<CachedQuestion | null>(null);
   const audioRef = useRef<HTMLAudioElement | null>(null);
   const timerRef = useRef<ReturnType<typeof setInterval> | null>(null);
   const sseRef = useRef<EventSource | null>(null);
 
-  useEffect(() => {
-    if (!sessionCode) return;
-    // Load session metadata
-    fetch(`/api/display/session/${sessionCode}`)
+  // Load session metadata and catch up with the current phase (on every (re)connect)
+  const loadSession = (code: string) => {
+    fetch(`/api/display/session/${code}?role=${screenRole}`, {
+      headers: { 'Authorization': `Bearer ${authToken()}` },
+    })
       .then(r => r.json())
-      .then(d => { setMeta(d); setDisplayState('waiting'); })
+      .then(d => {
+        setMeta(d);
+        setAccessibility(d.accessibility || null);
+        if (screenRole !== 'main') {
+          if (d.standings) setScores(d.standings);
+          setLobby({ teams: d.teams || [], players: d.players || 0 });
+          setDisplayState(d.status === 'completed' ? 'ended' : screenRole === 'scores' ? 'scores' : 'lobby');
+          return;
+        }
+        setDisplayState(prev => (prev === 'idle' ? 'waiting' : prev));
+        if (d.phase) resumePhase(d.phase, d.question);
+      })
       .catch(() => {});
-  }, [sessionCode]);
+  };
+
+  const cacheQuestion = (q: CachedQuestion | null) => {
+    cachedRef.current = q;
+    setCachedQuestion(q);
+  };
+
+  // Counts down to the server's deadline, corrected for this screen's clock skew
+  const startCountdown = (phase: Pick<PhaseState, 'deadline' | 'serverTime'>) => {
+    if (timerRef.current) clearInterval(timerRef.current);
+    if (!phase.deadline) {
+      setTimeLeft(null);
+      return;
+    }
+    const skew = Date.parse(phase.serverTime) - Date.now();
+    const deadline = Date.parse(phase.deadline);
+    const tick = () => {
+      const left = Math.max(0, Math.ceil((deadline - (Date.now() + skew)) / 1000));
+      setTimeLeft(left);
+      if (left === 0 && timerRef.current) clearInterval(timerRef.current);
+    };
+    tick();
+    timerRef.current = setInterval(tick, 250);
+  };
+
+  // Restore the current question after a (re)connect
+  const resumePhase = (phase: PhaseState, question: CachedQuestion | null) => {
+    setPhaseKey(phase.changedAt);
+    if (!question) return;
+    cacheQuestion(question);
+    switch (phase.phase) {
+      case 'loaded':
+        setDisplayState('question-loading');
+        break;
+      case 'revealed':
+      case 'answers_open':
+        setRevealedQuestion(question);
+        setDisplayState(question.audioUrl ? 'music-round' : 'question-reveal');
+        if (phase.phase === 'answers_open') startCountdown(phase);
+        break;
+      case 'closed':
+      case 'marked':
+        setDisplayState('answers-closed');
+        break;
+      default:
+        break;
+    }
+  };
 
-  const connectSSE = (code: string) => {
+  const connectSSE = async (code: string) => {
     if (sseRef.current) sseRef.current.close();
-    const es = new EventSource(`/api/display/stream/${code}`);
+    const token = await streamToken(authToken());
+    const es = new EventSource(`/api/display/stream/${code}?role=${screenRole}&token=${encodeURIComponent(token)}`);
     sseRef.current = es;
 
-    es.addEventListener('connected', () => {
-      setDisplayState('waiting');
-    });
-
     es.onmessage = (e) => {
       try {
         const event = JSON.parse(e.data);
-        handleSSEEvent(event);
+        // Sent on every (re)connect, so the display catches up on missed events
+        if (event.type === 'connected') {
+          loadSession(code);
+          return;
+        }
+        if (screenRole === 'main') handleSSEEvent(event);
+        else handleScreenEvent(event, code);
       } catch {}
     };
 
@@ -94,9 +243,22 @@ function App() {
         setDisplayState('waiting');
         break;
       }
+      case 'phase_changed': {
+        const p = event.payload as PhaseState;
+        // Phases that can repeat (question reloaded, scores pushed again)
+        // re-key the view so its entry animation replays
+        if (p.phase === 'loaded' || p.phase === 'scores_pushed') setPhaseKey(p.changedAt);
+        if (p.phase === 'answers_open') startCountdown(p);
+        break;
+      }
+      case 'timer_tick': {
+        // Sent every second by the server while a timed question is open
+        startCountdown(event.payload as { deadline: string; serverTime: string });
+        break;
+      }
       case 'question_precache': {
         const p = event.payload as CachedQuestion;
-        setCachedQuestion(p);
+        cacheQuestion(p);
         setRevealedQuestion(null);
         setDisplayState('question-loading');
         if (timerRef.current) clearInterval(timerRef.current);
@@ -104,35 +266,31 @@ function App() {
         break;
       }
       case 'question_reveal': {
-        setRevealedQuestion(cachedQuestion);
-        if (cachedQuestion?.audioUrl) {
+        const cached = cachedRef.current;
+        setRevealedQuestion(cached);
+        if (cached?.audioUrl) {
           setDisplayState('music-round');
         } else {
           setDisplayState('question-reveal');
         }
         break;
       }
+      case 'applause': {
+        const p = event.payload as Applause;
+        setApplause(p.level > 0 ? p : null);
+        break;
+      }
+      case 'accessibility_changed': {
+        const p = event.payload as { accessibility: Accessibility };
+        setAccessibility(p.accessibility);
+        break;
+      }
       case 'audio_play': {
         const p = event.payload as { audioUrl: string };
         setAudioSrc(p.audioUrl);
         setDisplayState('music-round');
         break;
       }
-      case 'timer_start': {
-        const p = event.payload as { questionId: number; durationSeconds: number };
-        if (timerRef.current) clearInterval(timerRef.current);
-        setTimeLeft(p.durationSeconds);
-        timerRef.current = setInterval(() => {
-          setTimeLeft(prev => {
-            if (prev === null || prev <= 1) {
-              clearInterval(timerRef.current!);
-              return 0;
-            }
-            return prev - 1;
-          });
-        }, 1000);
-        break;
-      }
       case 'answers_closed': {
         if (timerRef.current) clearInterval(timerRef.current);
         setTimeLeft(null);
@@ -145,7 +303,26 @@ function App() {
         setDisplayState('scores');
         break;
       }
+      case 'tiebreak_started': {
+        const p = event.payload as { kind: TiebreakInfo['kind']; question: string };
+        setTiebreak({ kind: p.kind, question: p.question, closed: false });
+        setDisplayState('tiebreak');
+        break;
+      }
+      case 'tiebreak_closed': {
+        setTiebreak(prev => prev ? { ...prev, closed: true } : prev);
+        break;
+      }
+      case 'tiebreak_resolved': {
+        const p = event.payload as { answer: string; results: TiebreakInfo['results']; scores: ScoreEntry[] };
+        setTiebreak(prev => prev ? { ...prev, closed: true, answer: p.answer, results: p.results } : prev);
+        setScores(p.scores);
+        setDisplayState('tiebreak');
+        break;
+      }
       case 'quiz_ended': {
+        const p = event.payload as { standings?: ScoreEntry[] };
+        if (p.standings) setScores(p.standings);
         setDisplayState('ended');
         if (sseRef.current) sseRef.current.close();
         break;
@@ -155,6 +332,38 @@ function App() {
     }
   };
 
+  // Dedicated screens are only sent the events that change what they show
+  const handleScreenEvent = (event: { type: string; payload: unknown }, code: string) => {
+    switch (event.type) {
+      case 'scores_revealed':
+      case 'tiebreak_resolved': {
+        const p = event.payload as { scores: ScoreEntry[] };
+        setScores(p.scores);
+        break;
+      }
+      case 'quiz_ended': {
+        const p = event.payload as { standings?: ScoreEntry[] };
+        if (p.standings) setScores(p.standings);
+        setDisplayState('ended');
+        if (sseRef.current) sseRef.current.close();
+        break;
+      }
+      case 'ping':
+        break;
+      default:
+        // Quiz started, a team renamed by moderation or new accessibility defaults
+        loadSession(code);
+        break;
+    }
+  };
+
+  // Teams join through quiz-player without an event, so the lobby screen polls
+  useEffect(() => {
+    if (screenRole !== 'lobby' || displayState !== 'lobby') return;
+    const id = setInterval(() => loadSession(sessionCode), 10000);
+    return () => clearInterval(id);
+  }, [screenRole, displayState, sessionCode]); // eslint-disable-line react-hooks/exhaustive-deps
+
   useEffect(() => {
     if (sessionCode) connectSSE(sessionCode);
     return () => {
@@ -171,6 +380,9 @@ function App() {
     }
   }, [audioSrc]);
 
+  // Teams still level after any tie-breaks share first place
+  const winners = scores.filter((e, idx) => (e.position ?? idx + 1) === 1);
+
   if (!sessionCode) {
     return (
       <div style={s.fullscreen}>
@@ -187,136 +399,211 @@ function App() {
       {/* Hidden audio element */}
       <audio ref={audioRef} style={{ display: 'none' }} />
 
-      {/* Idle / not yet connected */}
-      {displayState === 'idle' && (
-        <div style={s.center}>
-          <div style={s.spinner} />
-          <p style={s.subtitle}>Connecting to session {sessionCode}...</p>
-        </div>
-      )}
-
-      {/* Waiting for quiz to start */}
-      {displayState === 'waiting' && meta && (
-        <div style={s.center}>
-          <p style={s.packName}>{meta.packName}</p>
-          <h1 style={s.quizTitle}>{meta.name}</h1>
-          <div style={s.joinCodeBox}>
-            <p style={s.joinCodeLabel}>Join the quiz</p>
-            <p style={s.joinCode}>{sessionCode}</p>
+      {/* Applause meter, over whatever is showing between questions */}
+      {applause && (
+        <div style={s.applause}>
+          <div style={s.applauseEmojis}>
+            {Object.entries(applause.reactions).map(([emoji, n]) => (
+              <span key={emoji}>{emoji}{n > 1 && <span style={s.applauseCount}>×{n}</span>}</span>
+            ))}
           </div>
-          <p style={s.subtitle}>Waiting for the Quiz Master to start...</p>
-        </div>
-      )}
-
-      {/* Question loading (pre-cache) */}
-      {displayState === 'question-loading' && cachedQuestion && (
-        <div style={s.center}>
-          <p style={s.roundLabel}>Round {cachedQuestion.roundNumber}</p>
-          <div style={s.questionNumberBox}>
-            <p style={s.questionNumberLabel}>Question</p>
-            <p style={s.questionNumber}>{cachedQuestion.questionNumber}</p>
+          <div style={s.applauseTrack}>
+            <div style={{ ...s.applauseFill, height: `${applause.level}%` }} />
           </div>
-          <p style={s.getReady}>Get Ready...</p>
         </div>
       )}
 
-      {/* Question revealed */}
-      {displayState === 'question-reveal' && revealedQuestion && (
-        <div style={{ ...s.fullscreen, display: 'flex', flexDirection: 'column', padding: '5vh 8vw' }}>
-          <div style={s.questionHeader}>
-            <span style={s.roundBadge}>Round {revealedQuestion.roundNumber}</span>
-            <span style={s.qBadge}>Q{revealedQuestion.questionNumber}</span>
-            {timeLeft !== null && timeLeft > 0 && (
-              <span style={{ ...s.timerBadge, color: timeLeft <= 10 ? '#ff6b6b' : '#ffd700' }}>
-                {timeLeft}s
-              </span>
-            )}
+      <div key={`${displayState}-${phaseKey}`} style={displayState === 'answers-closed' ? s.phasePop : s.phaseIn}>
+        {/* Idle / not yet connected */}
+        {displayState === 'idle' && (
+          <div style={s.center}>
+            <div style={s.spinner} />
+            <p style={s.subtitle}>Connecting to session {sessionCode}...</p>
           </div>
-
-          <div style={s.questionBody}>
-            {revealedQuestion.imageUrl && (
-              <img
-                src={revealedQuestion.imageUrl}
-                alt="Question"
-                style={s.questionImage}
-              />
+        )}
+
+        {/* Waiting for quiz to start */}
+        {displayState === 'waiting' && meta && (
+          <div style={s.center}>
+            <p style={s.packName}>{meta.packName}</p>
+            <h1 style={s.quizTitle}>{meta.name}</h1>
+            <div style={s.joinCodeBox}>
+              <p style={s.joinCodeLabel}>Join the quiz</p>
+              <p style={s.joinCode}>{sessionCode}</p>
+            </div>
+            <p style={s.subtitle}>Waiting for the Quiz Master to start...</p>
+          </div>
+        )}
+
+        {/* Lobby screen: join code and teams, kept up for late arrivals */}
+        {displayState === 'lobby' && meta && (
+          <div style={s.center}>
+            <p style={s.packName}>{meta.packName}</p>
+            <h1 style={s.quizTitle}>{meta.name}</h1>
+            <div style={s.joinCodeBox}>
+              <p style={s.joinCodeLabel}>Join the quiz</p>
+              <p style={s.joinCode}>{sessionCode}</p>
+            </div>
+            {lobby.teams.length > 0 ? (
+              <div style={s.teamGrid}>
+                {lobby.teams.map(t => (
+                  <span key={t.id} style={s.teamChip}>{t.name} · {t.players}</span>
+                ))}
+              </div>
+            ) : (
+              <p style={s.subtitle}>{lobby.players} {lobby.players === 1 ? 'player has' : 'players have'} joined</p>
             )}
-            <p style={s.questionText}>{revealedQuestion.questionText}</p>
           </div>
-
-          {timeLeft === 0 && (
-            <div style={s.timeUpBanner}>Time's up!</div>
-          )}
-        </div>
-      )}
-
-      {/* Music round */}
-      {displayState === 'music-round' && (
-        <div style={s.center}>
-          {revealedQuestion && (
-            <>
-              <p style={s.roundLabel}>Round {revealedQuestion.roundNumber} · Q{revealedQuestion.questionNumber}</p>
-              <p style={s.musicRoundLabel}>Music Round</p>
-            </>
-          )}
-          <div style={s.musicWave}>
-            {[...Array(7)].map((_, i) => (
-              <div key={i} style={{ ...s.wavebar, animationDelay: `${i * 0.1}s` }} />
-            ))}
+        )}
+
+        {/* Question loading (pre-cache) */}
+        {displayState === 'question-loading' && cachedQuestion && (
+          <div style={s.center}>
+            <p style={s.roundLabel}>Round {cachedQuestion.roundNumber}</p>
+            <div style={s.questionNumberBox}>
+              <p style={s.questionNumberLabel}>Question</p>
+              <p style={s.questionNumber}>{cachedQuestion.questionNumber}</p>
+            </div>
+            <p style={s.getReady}>Get Ready...</p>
           </div>
-          <p style={s.subtitle}>Listen carefully...</p>
-          {timeLeft !== null && timeLeft > 0 && (
-            <p style={{ ...s.timerBadge, fontSize: 40, marginTop: 20 }}>{timeLeft}s</p>
-          )}
-        </div>
-      )}
-
-      {/* Answers closed */}
-      {displayState === 'answers-closed' && (
-        <div style={s.center}>
-          <p style={s.pencilsDown}>Pencils Down!</p>
-          <p style={s.subtitle}>Submit your final answers</p>
-        </div>
-      )}
-
-      {/* Scores */}
-      {displayState === 'scores' && (
-        <div style={{ ...s.fullscreen, display: 'flex', flexDirection: 'column', padding: '5vh 8vw' }}>
-          <h2 style={s.scoresTitle}>Leaderboard</h2>
-          <div style={s.scoresList}>
-            {scores.map((entry, idx) => (
-              <div key={entry.teamId} style={{ ...s.scoreRow, opacity: 1 - idx * 0.05 }}>
-                <span style={s.scoreRank}>
-                  {idx === 0 ? '🥇' : idx === 1 ? '🥈' : idx === 2 ? '🥉' : `#${idx + 1}`}
+        )}
+
+        {/* Question revealed */}
+        {displayState === 'question-reveal' && revealedQuestion && (
+          <div style={{ ...s.fullscreen, display: 'flex', flexDirection: 'column', padding: '5vh 8vw' }}>
+            <div style={s.questionHeader}>
+              <span style={s.roundBadge}>Round {revealedQuestion.roundNumber}</span>
+              <span style={s.qBadge}>Q{revealedQuestion.questionNumber}</span>
+              {timeLeft !== null && timeLeft > 0 && (
+                <span style={{ ...s.timerBadge, color: timeLeft <= 10 ? '#ff6b6b' : '#ffd700' }}>
+                  {timeLeft}s
                 </span>
-                <span style={s.scoreName}>{entry.name}</span>
-                <span style={s.scorePoints}>{entry.total}</span>
-              </div>
-            ))}
+              )}
+            </div>
+
+            <div style={s.questionBody}>
+              {revealedQuestion.imageUrl && (
+                <img
+                  src={revealedQuestion.imageUrl}
+                  alt={revealedQuestion.accessibility?.imageAlt || 'Question picture'}
+                  style={s.questionImage}
+                />
+              )}
+              {accessibility?.simplifiedText && revealedQuestion.accessibility?.simplifiedText ? (
+                <>
+                  <p style={s.questionText}>{revealedQuestion.accessibility.simplifiedText}</p>
+                  <p style={s.questionAsAsked}>{revealedQuestion.questionText}</p>
+                </>
+              ) : (
+                <p style={s.questionText}>{revealedQuestion.questionText}</p>
+              )}
+            </div>
+
+            {timeLeft === 0 && (
+              <div style={s.timeUpBanner}>Time's up!</div>
+            )}
           </div>
-        </div>
-      )}
+        )}
+
+        {/* Music round */}
+        {displayState === 'music-round' && (
+          <div style={s.center}>
+            {revealedQuestion && (
+              <>
+                <p style={s.roundLabel}>Round {revealedQuestion.roundNumber} · Q{revealedQuestion.questionNumber}</p>
+                <p style={s.musicRoundLabel}>Music Round</p>
+              </>
+            )}
+            <div style={s.musicWave}>
+              {[...Array(7)].map((_, i) => (
+                <div key={i} style={{ ...s.wavebar, animationDelay: `${i * 0.1}s` }} />
+              ))}
+            </div>
+            <p style={s.subtitle}>Listen carefully...</p>
+            {timeLeft !== null && timeLeft > 0 && (
+              <p style={{ ...s.timerBadge, fontSize: 40, marginTop: 20 }}>{timeLeft}s</p>
+            )}
+          </div>
+        )}
 
-      {/* Quiz ended */}
-      {displayState === 'ended' && (
-        <div style={s.center}>
-          <p style={{ fontSize: '6vw' }}>🏆</p>
-          <h1 style={s.endTitle}>Quiz Complete!</h1>
-          {scores.length > 0 && (
-            <>
-              <p style={s.winnerLabel}>Winner</p>
-              <p style={s.winnerName}>{scores[0]?.name}</p>
-            </>
-          )}
-        </div>
-      )}
+        {/* Answers closed */}
+        {displayState === 'answers-closed' && (
+          <div style={s.center}>
+            <p style={s.pencilsDown}>Pencils Down!</p>
+            <p style={s.subtitle}>Submit your final answers</p>
+          </div>
+        )}
+
+        {/* Scores */}
+        {displayState === 'scores' && (
+          <div style={{ ...s.fullscreen, display: 'flex', flexDirection: 'column', padding: '5vh 8vw' }}>
+            <h2 style={s.scoresTitle}>Leaderboard</h2>
+            <div style={s.scoresList}>
+              {scores.length === 0 && (
+                <p style={{ ...s.subtitle, textAlign: 'center' }}>Scores appear here after each round</p>
+              )}
+              {scores.map((entry, idx) => (
+                <div key={entry.teamId} style={{ ...s.scoreRow, opacity: 1 - idx * 0.05 }}>
+                  <span style={s.scoreRank}>
+                    {medal(entry.position ?? idx + 1)}
+                  </span>
+                  <span style={s.scoreName}>{entry.name}</span>
+                  <span style={s.scorePoints}>{entry.total}</span>
+                </div>
+              ))}
+            </div>
+          </div>
+        )}
+
+        {/* Tie-break */}
+        {displayState === 'tiebreak' && tiebreak && (
+          <div style={s.center}>
+            <p style={s.roundLabel}>Tie-break · {tiebreak.kind === 'nearest' ? 'Nearest wins' : 'Sudden death'}</p>
+            <p style={s.questionText}>{tiebreak.question}</p>
+            {tiebreak.answer === undefined ? (
+              <p style={s.subtitle}>{tiebreak.closed ? 'Answers are in...' : 'Tied teams, answer now!'}</p>
+            ) : (
+              <>
+                <p style={{ ...s.winnerLabel, marginTop: '4vh' }}>Answer</p>
+                <p style={s.winnerName}>{tiebreak.answer}</p>
+                <div style={{ ...s.scoresList, marginTop: '3vh' }}>
+                  {(tiebreak.results || []).map(r => (
+                    <div key={r.entityId} style={s.scoreRow}>
+                      <span style={s.scoreRank}>{r.rank === 1 ? '🏅' : `#${r.rank}`}</span>
+                      <span style={s.scoreName}>{r.name}</span>
+                      <span style={s.scorePoints}>{r.answerText || '–'}</span>
+                    </div>
+                  ))}
+                </div>
+              </>
+            )}
+          </div>
+        )}
+
+        {/* Quiz ended */}
+        {displayState === 'ended' && (
+          <div style={s.center}>
+            <p style={{ fontSize: '6vw' }}>🏆</p>
+            <h1 style={s.endTitle}>Quiz Complete!</h1>
+            {winners.length > 0 && (
+              <>
+                <p style={s.winnerLabel}>{winners.length > 1 ? 'Joint winners' : 'Winner'}</p>
+                <p style={s.winnerName}>{winners.map(e => e.name).join(' & ')}</p>
+              </>
+            )}
+          </div>
+        )}
+      </div>
     </div>
   );
 }
 
+const medal = (position: number) =>
+  position === 1 ? '🥇' : position === 2 ? '🥈' : position === 3 ? '🥉' : `#${position}`;
+
 // --- Styles ---
 
-const s: Record<string, React.CSSProperties> = {
+const baseStyles: Record<string, React.CSSProperties> = {
   fullscreen: { width: '100vw', height: '100vh', backgroundColor: '#0a0a2e', color: 'white', position: 'relative' },
   center: { display: 'flex', flexDirection: 'column', alignItems: 'center', justifyContent: 'center', height: '100%', textAlign: 'center', padding: '0 8vw' },
   title: { fontSize: '4vw', fontWeight: 800, color: '#ffd700', marginBottom: '2vh' },
@@ -338,6 +625,7 @@ const s: Record<string, React.CSSProperties> = {
   questionBody: { display: 'flex', flexDirection: 'column', alignItems: 'center', flex: 1, justifyContent: 'center' },
   questionImage: { maxWidth: '60vw', maxHeight: '40vh', objectFit: 'contain', borderRadius: 12, marginBottom: '3vh' },
   questionText: { fontSize: '3.5vw', fontWeight: 700, color: 'white', lineHeight: 1.4, textAlign: 'center', maxWidth: '80vw' },
+  questionAsAsked: { fontSize: '1.8vw', color: '#aaa', textAlign: 'center', maxWidth: '80vw', marginTop: '2vh' },
   timeUpBanner: { fontSize: '3vw', fontWeight: 900, color: '#ff6b6b', textAlign: 'center', padding: '2vh', backgroundColor: 'rgba(255,107,107,0.1)', borderRadius: 12 },
   musicRoundLabel: { fontSize: '4vw', fontWeight: 800, color: '#ffd700', marginBottom: '4vh' },
   musicWave: { display: 'flex', alignItems: 'flex-end', gap: '0.8vw', height: '15vh', marginBottom: '4vh' },
@@ -352,9 +640,61 @@ const s: Record<string, React.CSSProperties> = {
   endTitle: { fontSize: '6vw', fontWeight: 900, color: '#ffd700', marginBottom: '3vh' },
   winnerLabel: { fontSize: '2vw', color: '#888', marginBottom: '1vh' },
   winnerName: { fontSize: '5vw', fontWeight: 800, color: 'white' },
+  teamGrid: { display: 'flex', flexWrap: 'wrap', justifyContent: 'center', gap: '1vw', maxWidth: '80vw' },
+  teamChip: { fontSize: '1.8vw', backgroundColor: 'rgba(255,255,255,0.08)', padding: '1vh 2vw', borderRadius: 999 },
+  phaseIn: { height: '100%', animation: 'phaseIn 0.6s ease-out' },
+  phasePop: { height: '100%', animation: 'phasePop 0.5s cubic-bezier(0.34, 1.56, 0.64, 1)' },
   spinner: { width: '5vw', height: '5vw', border: '4px solid rgba(255,255,255,0.1)', borderTop: '4px solid #ffd700', borderRadius: '50%', animation: 'spin 1s linear infinite' },
+  applause: { position: 'absolute', right: '2vw', bottom: '4vh', display: 'flex', alignItems: 'flex-end', gap: '1vw', zIndex: 10 },
+  applauseEmojis: { display: 'flex', flexDirection: 'column', alignItems: 'flex-end', gap: '0.5vh', fontSize: '2.5vw' },
+  applauseCount: { fontSize: '1.4vw', color: '#ffd700', marginLeft: '0.3vw' },
+  applauseTrack: { width: '1.5vw', height: '30vh', backgroundColor: 'rgba(255,255,255,0.1)', borderRadius: 999, display: 'flex', alignItems: 'flex-end', overflow: 'hidden' },
+  applauseFill: { width: '100%', backgroundColor: '#ffd700', borderRadius: 999, transition: 'height 0.8s ease-out' },
+};
+
+// Bigger question, standings and join code for the back of the room
+const largeTextStyles: Record<string, React.CSSProperties> = {
+  subtitle: { fontSize: '2.6vw' },
+  questionText: { fontSize: '5vw' },
+  questionAsAsked: { fontSize: '2.6vw' },
+  roundBadge: { fontSize: '2.2vw' },
+  qBadge: { fontSize: '2.2vw' },
+  timerBadge: { fontSize: '3.5vw' },
+  scoreRank: { fontSize: '3.4vw' },
+  scoreName: { fontSize: '3.4vw' },
+  scorePoints: { fontSize: '4vw' },
+  teamChip: { fontSize: '2.6vw' },
 };
 
+// Pure black with white and yellow, no dimmed greys or see-through panels
+const highContrastStyles: Record<string, React.CSSProperties> = {
+  fullscreen: { backgroundColor: '#000' },
+  subtitle: { color: '#fff' },
+  packName: { color: '#fff' },
+  roundLabel: { color: '#fff' },
+  questionNumberLabel: { color: '#fff' },
+  getReady: { color: '#fff' },
+  joinCodeBox: { backgroundColor: '#000', border: '4px solid #ffeb3b' },
+  roundBadge: { backgroundColor: '#ffeb3b', color: '#000' },
+  qBadge: { backgroundColor: '#fff', color: '#000' },
+  questionAsAsked: { color: '#fff' },
+  scoreRow: { backgroundColor: '#000', border: '2px solid #fff' },
+  scorePoints: { color: '#ffeb3b' },
+  winnerLabel: { color: '#fff' },
+  teamChip: { backgroundColor: '#000', border: '2px solid #fff' },
+};
+
+// displayStyles layers the session's large text and high contrast over the base styles
+function displayStyles(a: Accessibility | null): Record<string, React.CSSProperties> {
+  const styles: Record<string, React.CSSProperties> = { ...baseStyles };
+  const layers = [a?.largeText && largeTextStyles, a?.highContrast && highContrastStyles];
+  layers.forEach(layer => {
+    if (!layer) return;
+    Object.entries(layer).forEach(([key, style]) => { styles[key] = { ...styles[key], ...style }; });
+  });
+  return styles;
+}
+
 // Inject keyframe animations
 const styleSheet = document.styleSheets[0];
 if (styleSheet) {
@@ -362,6 +702,8 @@ if (styleSheet) {
     styleSheet.insertRule('@keyframes wave { from { transform: scaleY(0.3); } to { transform: scaleY(1); } }', styleSheet.cssRules.length);
     styleSheet.insertRule('@keyframes spin { from { transform: rotate(0deg); } to { transform: rotate(360deg); } }', styleSheet.cssRules.length);
     styleSheet.insertRule('@keyframes pulse { 0%,100% { opacity: 1; } 50% { opacity: 0.4; } }', styleSheet.cssRules.length);
+    styleSheet.insertRule('@keyframes phaseIn { from { opacity: 0; transform: translateY(3vh); } to { opacity: 1; transform: none; } }', styleSheet.cssRules.length);
+    styleSheet.insertRule('@keyframes phasePop { from { opacity: 0; transform: scale(0.6); } to { opacity: 1; transform: scale(1); } }', styleSheet.cssRules.length);
   } catch {}
 }
 
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// AccessibilityOptions are a session's accessibility defaults
+// (sessions.settings accessibility). quiz-display applies them to the big
+// screen; each player starts from them and can switch more on for their own
+// phone in quiz-player.
+type AccessibilityOptions struct {
+	LargeText      bool `json:"largeText"`
+	HighContrast   bool `json:"highContrast"`
+	ReadAloud      bool `json:"readAloud"`      // Phones read each question out when it's revealed
+	SimplifiedText bool `json:"simplifiedText"` // Show the plainer wording where a question has one
+}
+
+// QuestionAccessibility is a question's accessible variants. They go out with
+// the precache so the display and every phone render the same ones.
+type QuestionAccessibility struct {
+	SimplifiedText string `json:"simplifiedText,omitempty"`
+	ImageAlt       string `json:"imageAlt,omitempty"`
+	ReadAloud      string `json:"readAloud"`
+}
+
+// questionAccessibility fills in a question's variants. Without its own
+// read-aloud text a question is read as its text, then the picture description.
+func questionAccessibility(text, simplified, imageAlt, readAloud string) QuestionAccessibility {
+	a := QuestionAccessibility{
+		SimplifiedText: strings.TrimSpace(simplified),
+		ImageAlt:       strings.TrimSpace(imageAlt),
+		ReadAloud:      strings.TrimSpace(readAloud),
+	}
+	if a.ReadAloud == "" {
+		a.ReadAloud = text
+		if a.ImageAlt != "" {
+			a.ReadAloud += " Picture: " + a.ImageAlt
+		}
+	}
+	return a
+}
+
+// handleSetAccessibility - PUT /sessions/{id}/accessibility
+// Replaces the session's accessibility defaults, including mid-quiz, and
+// tells the screens so they restyle straight away.
+func handleSetAccessibility(w http.ResponseWriter, r *http.Request) {
+	sessionID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, `{"error":"invalid id"}`, http.StatusBadRequest)
+		return
+	}
+
+	var opts AccessibilityOptions
+	if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+		http.Error(w, `{"error":"invalid body"}`, http.StatusBadRequest)
+		return
+	}
+	raw, _ := json.Marshal(opts)
+
+	res, err := quizDB.Exec(`
+		UPDATE sessions SET settings = jsonb_set(settings, '{accessibility}', $1::jsonb)
+		WHERE id = $2 AND status <> 'completed'`, string(raw), sessionID)
+	if err != nil {
+		log.Printf("Failed to update accessibility for session %d: %v", sessionID, err)
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		http.Error(w, `{"error":"session has finished"}`, http.StatusConflict)
+		return
+	}
+
+	invalidateDisplay(sessionID)
+	_ = publishEvent(evAccessibilityChanged.New(sessionKey(sessionID), AccessibilityChanged{Accessibility: opts}))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"accessibility": opts})
+}
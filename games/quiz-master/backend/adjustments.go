@@ -0,0 +1,105 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/gorilla/mux"
+)
+
+// ScoreAdjustment is points given outside marking, e.g. the late-join backfill
+// quiz-player records when a team arrives mid-quiz (score_adjustments)
+type ScoreAdjustment struct {
+	ID        int       `json:"id"`
+	EntityID  int       `json:"entityId"` // Team ID, or player ID in individual mode (matches scores' teamId)
+	Name      string    `json:"name"`
+	Reason    string    `json:"reason"`
+	Policy    string    `json:"policy"`
+	Points    int       `json:"points"`
+	UpdatedBy string    `json:"updatedBy,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func getSessionAdjustments(sessionID int) ([]ScoreAdjustment, error) {
+	rows, err := quizDB.Query(`
+		SELECT a.id, COALESCE(a.team_id, a.player_id),
+		       COALESCE(`+publicTeamNameSQL+`, sp.user_name, sp.user_email, ''),
+		       a.reason, COALESCE(a.policy,''), a.points, COALESCE(a.updated_by,''), a.created_at
+		FROM score_adjustments a
+		LEFT JOIN teams t ON t.id = a.team_id
+		LEFT JOIN session_players sp ON sp.id = a.player_id
+		WHERE a.session_id = $1
+		ORDER BY a.created_at`, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	adjustments := []ScoreAdjustment{}
+	for rows.Next() {
+		var a ScoreAdjustment
+		if err := rows.Scan(&a.ID, &a.EntityID, &a.Name, &a.Reason, &a.Policy, &a.Points, &a.UpdatedBy, &a.CreatedAt); err != nil {
+			continue
+		}
+		adjustments = append(adjustments, a)
+	}
+	return adjustments, nil
+}
+
+// handleGetAdjustments - GET /api/sessions/{id}/adjustments
+func handleGetAdjustments(w http.ResponseWriter, r *http.Request) {
+	sessionID, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	adjustments, err := getSessionAdjustments(sessionID)
+	if err != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"adjustments": adjustments})
+}
+
+// handleUpdateAdjustment - PUT /api/sessions/{id}/adjustments/{adjustmentId} {"points": 12}
+// Host only; sets the points for a late joiner (the admin policy starts from lateJoinPoints)
+func handleUpdateAdjustment(w http.ResponseWriter, r *http.Request) {
+	user, _ := authlib.GetUserFromContext(r.Context())
+	sessionID, _ := strconv.Atoi(mux.Vars(r)["id"])
+	adjustmentID, err := strconv.Atoi(mux.Vars(r)["adjustmentId"])
+	if err != nil {
+		http.Error(w, `{"error":"invalid adjustment id"}`, http.StatusBadRequest)
+		return
+	}
+
+	var body struct {
+		Points *int `json:"points"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Points == nil {
+		http.Error(w, `{"error":"points required"}`, http.StatusBadRequest)
+		return
+	}
+
+	var a ScoreAdjustment
+	err = quizDB.QueryRow(`
+		UPDATE score_adjustments SET points = $1, updated_by = $2, updated_at = NOW()
+		WHERE id = $3 AND session_id = $4
+		RETURNING id, COALESCE(team_id, player_id), reason, COALESCE(policy,''), points, created_at`,
+		*body.Points, user.ActorEmail(), adjustmentID, sessionID,
+	).Scan(&a.ID, &a.EntityID, &a.Reason, &a.Policy, &a.Points, &a.CreatedAt)
+	if err == sql.ErrNoRows {
+		http.Error(w, `{"error":"adjustment not found"}`, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+	a.UpdatedBy = user.ActorEmail()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(a)
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/achgithub/activity-hub-common/cache"
+	"github.com/go-redis/redis/v8"
+)
+
+// quiz-display caches each session's display state under this prefix; the
+// entries are dropped here whenever the session's phase, status, standings or
+// accessibility defaults change
+var displayCache *cache.Cache
+
+// redisStore adapts the go-redis client to cache.Store
+type redisStore struct {
+	client *redis.Client
+}
+
+func (s redisStore) Get(ctx context.Context, key string) (string, error) {
+	v, err := s.client.Get(ctx, key).Result()
+	if err == redis.Nil {
+		return "", cache.ErrMiss
+	}
+	return v, err
+}
+
+func (s redisStore) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	return s.client.Set(ctx, key, value, ttl).Err()
+}
+
+func (s redisStore) Del(ctx context.Context, keys ...string) error {
+	return s.client.Del(ctx, keys...).Err()
+}
+
+func initCache() {
+	displayCache = cache.New(redisStore{redisClient}, "cache:quiz-display:", 0)
+}
+
+// invalidateDisplay drops quiz-display's cached state for a session, including
+// its scores screen's standings
+func invalidateDisplay(sessionID int) {
+	key := "session:" + strconv.Itoa(sessionID)
+	if err := displayCache.Invalidate(context.Background(), key, key+":scores"); err != nil {
+		log.Printf("Session %d: failed to invalidate display cache: %v", sessionID, err)
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// Each team answers from its captain's device (quiz-player locks it on their
+// first answer). When the captain's phone dies or they leave, the host makes
+// someone else captain here; their first answer locks the team to their device.
+
+// handleSetTeamCaptain - POST /api/sessions/{id}/teams/{teamId}/captain {"playerId": 12}
+func handleSetTeamCaptain(w http.ResponseWriter, r *http.Request) {
+	sessionID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, `{"error":"invalid id"}`, http.StatusBadRequest)
+		return
+	}
+	teamID, err := strconv.Atoi(mux.Vars(r)["teamId"])
+	if err != nil {
+		http.Error(w, `{"error":"invalid teamId"}`, http.StatusBadRequest)
+		return
+	}
+
+	var body struct {
+		PlayerID int `json:"playerId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, `{"error":"invalid JSON"}`, http.StatusBadRequest)
+		return
+	}
+
+	var playerName string
+	err = quizDB.QueryRow(`
+		SELECT COALESCE(sp.user_name, sp.user_email) FROM session_players sp
+		JOIN teams t ON t.id = sp.team_id
+		WHERE sp.id = $1 AND t.id = $2 AND t.session_id = $3`, body.PlayerID, teamID, sessionID).Scan(&playerName)
+	if err == sql.ErrNoRows {
+		http.Error(w, `{"error":"player isn't on that team"}`, http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	tx, err := quizDB.Begin()
+	if err != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	var oldCaptain sql.NullInt64
+	err = tx.QueryRow(`SELECT captain_player_id FROM teams WHERE id = $1 FOR UPDATE`, teamID).Scan(&oldCaptain)
+	if err == nil {
+		_, err = tx.Exec(`UPDATE teams SET captain_player_id = $2, captain_device = NULL WHERE id = $1`, teamID, body.PlayerID)
+	}
+	// The team's answer to the open question moves to the new captain, so
+	// they edit it rather than adding a second one
+	if err == nil && oldCaptain.Valid && int(oldCaptain.Int64) != body.PlayerID {
+		_, err = tx.Exec(`
+			UPDATE answers a SET player_id = $3
+			FROM session_phase p
+			WHERE p.session_id = a.session_id AND p.phase = 'answers_open' AND p.question_id = a.question_id
+			  AND a.session_id = $1 AND a.player_id = $2
+			  AND NOT EXISTS (SELECT 1 FROM answers b
+			                  WHERE b.session_id = a.session_id AND b.question_id = a.question_id AND b.player_id = $3)`,
+			sessionID, oldCaptain.Int64, body.PlayerID)
+	}
+	if err != nil || tx.Commit() != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	_ = publishEvent(evCaptainChanged.New(sessionKey(sessionID), CaptainChanged{
+		TeamID: teamID, PlayerID: body.PlayerID, PlayerName: playerName,
+	}))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "ok", "teamId": teamID, "captainPlayerId": body.PlayerID})
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/gorilla/mux"
+)
+
+// Per-session roles (session_hosts). The host runs the quiz; a scorekeeper
+// co-host can only mark answers and push scores.
+const (
+	sessionRoleHost        = "host"
+	sessionRoleScorekeeper = "scorekeeper"
+)
+
+type sessionRoleKey struct{}
+
+type CoHost struct {
+	Email     string    `json:"email"`
+	Name      string    `json:"name"`
+	Role      string    `json:"role"`
+	InvitedBy string    `json:"invitedBy"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func hasQuizRole(user *authlib.AuthUser) bool {
+	return user.HasRole("quiz_master") || user.HasRole("game_admin") || user.HasRole("super_user")
+}
+
+// getSessionRole returns the user's role in a session ("" = none).
+// Admins are always hosts; quiz masters host sessions they haven't been given a role in.
+func getSessionRole(sessionID int, user *authlib.AuthUser) (string, error) {
+	if user.HasRole("game_admin") || user.HasRole("super_user") {
+		return sessionRoleHost, nil
+	}
+
+	var role string
+	err := quizDB.QueryRow(`
+		SELECT role FROM session_hosts WHERE session_id = $1 AND user_email = $2`,
+		sessionID, user.Email,
+	).Scan(&role)
+	if err == nil {
+		return role, nil
+	}
+	if err != sql.ErrNoRows {
+		return "", err
+	}
+
+	if user.HasRole("quiz_master") {
+		return sessionRoleHost, nil
+	}
+	return "", nil
+}
+
+// requireSessionRole only lets users holding one of roles in the session ({id}) through
+func requireSessionRole(roles ...string) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			user, ok := authlib.GetUserFromContext(r.Context())
+			if !ok {
+				http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+				return
+			}
+
+			sessionID, err := strconv.Atoi(mux.Vars(r)["id"])
+			if err != nil {
+				http.Error(w, `{"error":"invalid id"}`, http.StatusBadRequest)
+				return
+			}
+
+			role, err := getSessionRole(sessionID, user)
+			if err != nil {
+				http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+				return
+			}
+
+			for _, allowed := range roles {
+				if role == allowed {
+					next(w, r.WithContext(context.WithValue(r.Context(), sessionRoleKey{}, role)))
+					return
+				}
+			}
+
+			if role == "" {
+				http.Error(w, `{"error":"not a host of this session"}`, http.StatusForbidden)
+			} else {
+				http.Error(w, `{"error":"not allowed for `+role+`"}`, http.StatusForbidden)
+			}
+		}
+	}
+}
+
+// sessionRoleFromContext returns the role set by requireSessionRole
+func sessionRoleFromContext(r *http.Request) string {
+	role, _ := r.Context().Value(sessionRoleKey{}).(string)
+	return role
+}
+
+// handleGetCoHosts - GET /api/sessions/{id}/cohosts
+func handleGetCoHosts(w http.ResponseWriter, r *http.Request) {
+	sessionID, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	rows, err := quizDB.Query(`
+		SELECT user_email, role, COALESCE(invited_by,''), created_at
+		FROM session_hosts WHERE session_id = $1
+		ORDER BY role, created_at`, sessionID)
+	if err != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	cohosts := []CoHost{}
+	emails := []string{}
+	for rows.Next() {
+		var c CoHost
+		if err := rows.Scan(&c.Email, &c.Role, &c.InvitedBy, &c.CreatedAt); err != nil {
+			continue
+		}
+		cohosts = append(cohosts, c)
+		emails = append(emails, c.Email)
+	}
+
+	if profiles, err := authlib.LoadProfiles(identityDB, emails); err == nil {
+		for i := range cohosts {
+			if p, ok := profiles[cohosts[i].Email]; ok {
+				cohosts[i].Name = p.DisplayName()
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"cohosts": cohosts})
+}
+
+// handleInviteCoHost - POST /api/sessions/{id}/cohosts {"email": "..."}
+// Host only. The invitee becomes a scorekeeper for this session.
+func handleInviteCoHost(w http.ResponseWriter, r *http.Request) {
+	user, _ := authlib.GetUserFromContext(r.Context())
+	sessionID, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	var body struct {
+		Email string `json:"email"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || strings.TrimSpace(body.Email) == "" {
+		http.Error(w, `{"error":"email required"}`, http.StatusBadRequest)
+		return
+	}
+	email := strings.ToLower(strings.TrimSpace(body.Email))
+	if strings.EqualFold(email, user.Email) {
+		http.Error(w, `{"error":"you are already hosting this session"}`, http.StatusBadRequest)
+		return
+	}
+
+	var exists bool
+	if err := identityDB.QueryRow(`SELECT EXISTS(SELECT 1 FROM users WHERE email = $1)`, email).Scan(&exists); err != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+	if !exists {
+		http.Error(w, `{"error":"user not found"}`, http.StatusNotFound)
+		return
+	}
+
+	// Never downgrades an existing host
+	_, err := quizDB.Exec(`
+		INSERT INTO session_hosts (session_id, user_email, role, invited_by)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (session_id, user_email) DO NOTHING`,
+		sessionID, email, sessionRoleScorekeeper, user.ActorEmail(),
+	)
+	if err != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Session %d: %s invited %s as scorekeeper", sessionID, user.Email, email)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "invited"})
+}
+
+// handleRemoveCoHost - DELETE /api/sessions/{id}/cohosts/{email}
+// Host only. Hosts themselves can't be removed this way.
+func handleRemoveCoHost(w http.ResponseWriter, r *http.Request) {
+	sessionID, _ := strconv.Atoi(mux.Vars(r)["id"])
+	email := mux.Vars(r)["email"]
+
+	res, err := quizDB.Exec(`
+		DELETE FROM session_hosts
+		WHERE session_id = $1 AND user_email = $2 AND role = $3`,
+		sessionID, email, sessionRoleScorekeeper,
+	)
+	if err != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		http.Error(w, `{"error":"co-host not found"}`, http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "removed"})
+}
+
+// handleGetCoHosting - GET /api/cohosting
+// Sessions the current user has been invited to as a scorekeeper (not yet completed)
+func handleGetCoHosting(w http.ResponseWriter, r *http.Request) {
+	user, ok := authlib.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+		return
+	}
+
+	rows, err := quizDB.Query(`
+		SELECT s.id, s.pack_id, s.name, s.mode, s.status, s.join_code, COALESCE(s.created_by,''), s.created_at
+		FROM session_hosts h
+		JOIN sessions s ON s.id = h.session_id
+		WHERE h.user_email = $1 AND h.role = $2 AND s.status <> 'completed'
+		ORDER BY s.created_at DESC`, user.Email, sessionRoleScorekeeper)
+	if err != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	sessions := []Session{}
+	for rows.Next() {
+		var s Session
+		if err := rows.Scan(&s.ID, &s.PackID, &s.Name, &s.Mode, &s.Status, &s.JoinCode, &s.CreatedBy, &s.CreatedAt); err != nil {
+			continue
+		}
+		sessions = append(sessions, s)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"sessions": sessions})
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/achgithub/activity-hub-common/events"
+)
+
+// Session stream events, published by the quiz master and forwarded as is by
+// the player and display streams. Payload field names are what the frontends
+// read.
+
+type SessionRef struct {
+	SessionID int `json:"sessionId"`
+}
+
+// QuestionPrecache lets screens load media before the question is revealed
+type QuestionPrecache struct {
+	RoundID        int    `json:"roundId"`
+	QuestionID     int    `json:"questionId"`
+	QuestionNumber int    `json:"questionNumber"`
+	RoundNumber    int    `json:"roundNumber"`
+	QuestionText   string `json:"questionText"`
+	ImageURL       string `json:"imageUrl"`
+	AudioURL       string `json:"audioUrl"`
+	TimeLimit      *int64 `json:"timeLimit"`   // Seconds; null for no limit
+	PhotoAnswer    bool   `json:"photoAnswer"` // Photo round: players answer with a picture
+
+	Accessibility QuestionAccessibility `json:"accessibility"`
+}
+
+// AccessibilityChanged carries the session's new accessibility defaults
+type AccessibilityChanged struct {
+	Accessibility AccessibilityOptions `json:"accessibility"`
+}
+
+type QuestionRef struct {
+	QuestionID int `json:"questionId"`
+}
+
+type AudioPlay struct {
+	AudioURL string `json:"audioUrl"`
+}
+
+type ScoresRevealed struct {
+	Scores []ScoreEntry `json:"scores"`
+}
+
+type QuizEnded struct {
+	SessionID int          `json:"sessionId"`
+	Standings []ScoreEntry `json:"standings"`
+}
+
+// ContentModerated tells screens to refresh a team name or answer
+type ContentModerated struct {
+	Kind       string `json:"kind"` // team, answer or tiebreak_answer
+	ID         int    `json:"id"`
+	Moderation string `json:"moderation"`
+}
+
+// TiebreakStarted leaves out the answer, which stays with the quiz master
+type TiebreakStarted struct {
+	TiebreakID int    `json:"tiebreakId"`
+	Kind       string `json:"kind"`
+	Question   string `json:"question"`
+	EntityIDs  []int  `json:"entityIds"`
+}
+
+// CaptainChanged says who now answers for a team (quiz-player sends it too,
+// when a captain hands over)
+type CaptainChanged struct {
+	TeamID     int    `json:"teamId"`
+	PlayerID   int    `json:"playerId"`
+	PlayerName string `json:"playerName"`
+}
+
+type TiebreakRef struct {
+	TiebreakID int `json:"tiebreakId"`
+}
+
+type TiebreakResolved struct {
+	TiebreakID int              `json:"tiebreakId"`
+	Answer     string           `json:"answer"`
+	Results    []TiebreakAnswer `json:"results"`
+	Scores     []ScoreEntry     `json:"scores"`
+}
+
+// Stream-only events sent by the quiz master, player and display backends
+type StreamOpened struct {
+	SessionID int    `json:"sessionId"`
+	Code      string `json:"code,omitempty"` // Display stream only
+}
+
+var (
+	evConnected        = events.Register[StreamOpened]("connected", 1, "Stream opened")
+	evQuizStarted      = events.Register[SessionRef]("quiz_started", 1, "The quiz started")
+	evPhaseChanged     = events.Register[PhaseState]("phase_changed", 1, "The session moved to a new phase")
+	evQuestionPrecache = events.Register[QuestionPrecache]("question_precache", 1, "The next question was loaded")
+	evQuestionReveal   = events.Register[QuestionRef]("question_reveal", 1, "The loaded question was revealed")
+	evAudioPlay        = events.Register[AudioPlay]("audio_play", 1, "The quiz master played a clip")
+	evAnswersClosed    = events.Register[QuestionRef]("answers_closed", 1, "Answers closed for a question")
+	evTimerTick        = events.Register[TimerTick]("timer_tick", 1, "Seconds left on the open timed question")
+	evScoresRevealed   = events.Register[ScoresRevealed]("scores_revealed", 1, "Standings were pushed to screens")
+	evQuizEnded        = events.Register[QuizEnded]("quiz_ended", 1, "The quiz finished")
+	evContentModerated = events.Register[ContentModerated]("content_moderated", 1, "A flagged name or answer was approved or rejected")
+	evTiebreakStarted  = events.Register[TiebreakStarted]("tiebreak_started", 1, "A tie-break question was asked")
+	evTiebreakClosed   = events.Register[TiebreakRef]("tiebreak_closed", 1, "Tie-break answers closed")
+	evTiebreakResolved = events.Register[TiebreakResolved]("tiebreak_resolved", 1, "A tie-break was decided")
+	evCaptainChanged   = events.Register[CaptainChanged]("captain_changed", 1, "A team has a new captain")
+
+	evAccessibilityChanged = events.Register[AccessibilityChanged]("accessibility_changed", 1, "The session's accessibility defaults changed")
+)
+
+// sessionKey is a quiz session's envelope session
+func sessionKey(sessionID int) string {
+	return strconv.Itoa(sessionID)
+}
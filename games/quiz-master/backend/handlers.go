@@ -4,7 +4,7 @@ import (
 	"crypto/rand"
 	"database/sql"
 	"encoding/json"
-	"fmt"
+	"log"
 	"math/big"
 	"net/http"
 	"strconv"
@@ -12,15 +12,22 @@ import (
 	"time"
 	"unicode/utf8"
 
+	"github.com/achgithub/activity-hub-common/analytics"
 	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/achgithub/activity-hub-common/events"
+	apphttp "github.com/achgithub/activity-hub-common/http"
+	"github.com/achgithub/activity-hub-common/points"
 	"github.com/gorilla/mux"
+	"github.com/lib/pq"
 )
 
 func handleConfig(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"appName": "Quiz Master",
-		"port":    5080,
+	apphttp.WriteConfig(w, apphttp.AppConfig{
+		AppID:        "quiz-master",
+		Name:         "Quiz Master",
+		Icon:         "🎤",
+		Description:  "Run and control a live quiz session",
+		Capabilities: apphttp.Capabilities{SSE: true, Displays: true, Teams: true},
 	})
 }
 
@@ -29,6 +36,7 @@ func handleGetPacks(w http.ResponseWriter, r *http.Request) {
 		SELECT p.id, p.name, COALESCE(p.description,''), COUNT(r.id) as round_count
 		FROM quiz_packs p
 		LEFT JOIN rounds r ON r.pack_id = p.id
+		WHERE p.deleted_at IS NULL
 		GROUP BY p.id ORDER BY p.id DESC`)
 	if err != nil {
 		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
@@ -63,9 +71,10 @@ func handleCreateSession(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var body struct {
-		PackID int    `json:"packId"`
-		Name   string `json:"name"`
-		Mode   string `json:"mode"` // team | individual
+		PackID      int    `json:"packId"`
+		Name        string `json:"name"`
+		Mode        string `json:"mode"`        // team | individual
+		ScheduledAt string `json:"scheduledAt"` // Optional: when the quiz night starts
 	}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Name == "" {
 		http.Error(w, `{"error":"name and packId required"}`, http.StatusBadRequest)
@@ -74,25 +83,19 @@ func handleCreateSession(w http.ResponseWriter, r *http.Request) {
 	if body.Mode == "" {
 		body.Mode = "team"
 	}
-
-	joinCode, err := generateCode(6)
+	scheduledAt, err := parseScheduledAt(body.ScheduledAt)
 	if err != nil {
-		http.Error(w, `{"error":"could not generate code"}`, http.StatusInternalServerError)
+		http.Error(w, `{"error":"invalid scheduledAt"}`, http.StatusBadRequest)
 		return
 	}
 
-	var sessionID int
-	err = quizDB.QueryRow(`
-		INSERT INTO sessions (pack_id, name, mode, join_code, created_by)
-		VALUES ($1, $2, $3, $4, $5) RETURNING id`,
-		body.PackID, body.Name, body.Mode, joinCode, user.Email,
-	).Scan(&sessionID)
+	sessionID, joinCode, err := createSession(user.Email, user.ActorEmail(), user.VenueID, body.PackID, body.Name, body.Mode, nil, SessionSettings{}, nil, scheduledAt)
 	if err != nil {
 		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
 		return
 	}
 
-	// In team mode, create initial teams if desired. Return session info.
+	// Teams are added separately (POST /sessions/{id}/teams). Return session info.
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"sessionId": sessionID,
@@ -101,6 +104,156 @@ func handleCreateSession(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// createSession creates a session hosted by host, with its teams. createdBy
+// is who really asked: the super_user when one is impersonating the host.
+// venueID picks the content filter word list (0 = chain-wide only).
+// templateID and settings are set when created from a template. scheduledAt,
+// when set, puts the quiz night in players' email digests.
+func createSession(host, createdBy string, venueID, packID int, name, mode string, templateID *int, settings SessionSettings, teamNames []string, scheduledAt *time.Time) (int, string, error) {
+	joinCode, err := generateCode(6)
+	if err != nil {
+		return 0, "", err
+	}
+	settingsJSON, err := json.Marshal(settings)
+	if err != nil {
+		return 0, "", err
+	}
+
+	tx, err := quizDB.Begin()
+	if err != nil {
+		return 0, "", err
+	}
+	defer tx.Rollback()
+
+	var sessionID int
+	err = tx.QueryRow(`
+		INSERT INTO sessions (pack_id, name, mode, join_code, created_by, template_id, settings, venue_id, scheduled_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id`,
+		packID, name, mode, joinCode, createdBy, templateID, settingsJSON, venueID, scheduledAt,
+	).Scan(&sessionID)
+	if err != nil {
+		return 0, "", err
+	}
+
+	// The creator hosts the session and can invite scorekeeper co-hosts
+	_, err = tx.Exec(`
+		INSERT INTO session_hosts (session_id, user_email, role, invited_by)
+		VALUES ($1, $2, $3, $4)`, sessionID, host, sessionRoleHost, createdBy)
+	if err != nil {
+		return 0, "", err
+	}
+
+	for _, teamName := range teamNames {
+		if _, err := createTeam(tx, sessionID, teamName); err != nil {
+			return 0, "", err
+		}
+	}
+
+	return sessionID, joinCode, tx.Commit()
+}
+
+// parseScheduledAt reads an optional start time: RFC 3339, or the local
+// 2006-01-02T15:04 a datetime-local input sends. Empty means unscheduled.
+func parseScheduledAt(s string) (*time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		t, err = time.ParseInLocation("2006-01-02T15:04", s, time.Local)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// handleScheduleSession - PUT /api/sessions/{id}/schedule {scheduledAt}
+// Sets or (with an empty scheduledAt) clears when a session in the lobby starts.
+func handleScheduleSession(w http.ResponseWriter, r *http.Request) {
+	sessionID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, `{"error":"invalid id"}`, http.StatusBadRequest)
+		return
+	}
+
+	var body struct {
+		ScheduledAt string `json:"scheduledAt"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, `{"error":"invalid body"}`, http.StatusBadRequest)
+		return
+	}
+	scheduledAt, err := parseScheduledAt(body.ScheduledAt)
+	if err != nil {
+		http.Error(w, `{"error":"invalid scheduledAt"}`, http.StatusBadRequest)
+		return
+	}
+
+	res, err := quizDB.Exec(`UPDATE sessions SET scheduled_at = $1 WHERE id = $2 AND status = 'lobby'`, scheduledAt, sessionID)
+	if err != nil {
+		log.Printf("Failed to schedule session %d: %v", sessionID, err)
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		http.Error(w, `{"error":"only sessions in the lobby can be scheduled"}`, http.StatusConflict)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"scheduledAt": scheduledAt})
+}
+
+// createTeam adds a team with its own join code
+func createTeam(tx *sql.Tx, sessionID int, name string) (Team, error) {
+	t := Team{SessionID: sessionID, Name: name}
+	code, err := generateCode(4)
+	if err != nil {
+		return t, err
+	}
+	t.JoinCode = code
+	err = tx.QueryRow(`
+		INSERT INTO teams (session_id, name, join_code) VALUES ($1, $2, $3) RETURNING id`,
+		sessionID, name, code,
+	).Scan(&t.ID)
+	return t, err
+}
+
+// handleCreateTeam - POST /api/sessions/{id}/teams {"name": "..."}
+func handleCreateTeam(w http.ResponseWriter, r *http.Request) {
+	sessionID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, `{"error":"invalid id"}`, http.StatusBadRequest)
+		return
+	}
+
+	var body struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || strings.TrimSpace(body.Name) == "" {
+		http.Error(w, `{"error":"name required"}`, http.StatusBadRequest)
+		return
+	}
+
+	tx, err := quizDB.Begin()
+	if err != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	team, err := createTeam(tx, sessionID, strings.TrimSpace(body.Name))
+	if err != nil || tx.Commit() != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(team)
+}
+
 func handleGetSession(w http.ResponseWriter, r *http.Request) {
 	sessionID, err := strconv.Atoi(mux.Vars(r)["id"])
 	if err != nil {
@@ -109,13 +262,16 @@ func handleGetSession(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var s Session
-	var startedAt, completedAt sql.NullTime
+	var startedAt, completedAt, scheduledAt sql.NullTime
 	var createdBy sql.NullString
+	var templateID sql.NullInt64
+	var settings []byte
 	err = quizDB.QueryRow(`
-		SELECT id, pack_id, name, mode, status, join_code, COALESCE(created_by,''), created_at, started_at, completed_at
+		SELECT id, pack_id, name, mode, status, join_code, COALESCE(created_by,''), created_at, started_at, completed_at,
+		       template_id, settings, scheduled_at
 		FROM sessions WHERE id = $1`, sessionID).
 		Scan(&s.ID, &s.PackID, &s.Name, &s.Mode, &s.Status, &s.JoinCode,
-			&createdBy, &s.CreatedAt, &startedAt, &completedAt)
+			&createdBy, &s.CreatedAt, &startedAt, &completedAt, &templateID, &settings, &scheduledAt)
 	if err == sql.ErrNoRows {
 		http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
 		return
@@ -131,6 +287,14 @@ func handleGetSession(w http.ResponseWriter, r *http.Request) {
 	if completedAt.Valid {
 		s.CompletedAt = &completedAt.Time
 	}
+	if scheduledAt.Valid {
+		s.ScheduledAt = &scheduledAt.Time
+	}
+	if templateID.Valid {
+		v := int(templateID.Int64)
+		s.TemplateID = &v
+	}
+	json.Unmarshal(settings, &s.Settings)
 
 	// Get players
 	players, _ := getSessionPlayers(sessionID)
@@ -139,7 +303,10 @@ func handleGetSession(w http.ResponseWriter, r *http.Request) {
 	teams, _ := getSessionTeams(sessionID)
 
 	// Get rounds with questions
-	rounds, _ := getSessionRounds(s.PackID)
+	rounds, _ := getSessionRounds(s.PackID, s.Settings)
+
+	// Current question phase (lets a reopened control panel resume)
+	phase, _ := getSessionPhase(sessionID)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
@@ -147,6 +314,8 @@ func handleGetSession(w http.ResponseWriter, r *http.Request) {
 		"players": players,
 		"teams":   teams,
 		"rounds":  rounds,
+		"myRole":  sessionRoleFromContext(r),
+		"phase":   phase,
 	})
 }
 
@@ -162,9 +331,13 @@ func handleStartSession(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
 		return
 	}
+	if err := resetPhase(sessionID); err != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
 
 	// Notify players
-	_ = publishEvent(sessionID, "quiz_started", map[string]interface{}{"sessionId": sessionID})
+	_ = publishEvent(evQuizStarted.New(sessionKey(sessionID), SessionRef{SessionID: sessionID}))
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{"status": "started"})
@@ -189,43 +362,62 @@ func handleLoadQuestion(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Fetch question details
-	var text, qtype, imagePath, audioPath string
+	var text, qtype, imagePath, audioPath, simplified, imageAlt, readAloud string
 	var imageID, audioID sql.NullInt64
 	err = quizDB.QueryRow(`
 		SELECT q.text, q.type, q.image_id, q.audio_id,
-		       COALESCE(img.file_path,''), COALESCE(aud.file_path,'')
+		       COALESCE(img.file_path,''), COALESCE(aud.file_path,''),
+		       COALESCE(q.simplified_text,''), COALESCE(q.image_alt,''), COALESCE(q.read_aloud_text,'')
 		FROM questions q
 		LEFT JOIN media_files img ON img.id = q.image_id
 		LEFT JOIN media_files aud ON aud.id = q.audio_id
 		WHERE q.id = $1`, body.QuestionID).
-		Scan(&text, &qtype, &imageID, &audioID, &imagePath, &audioPath)
+		Scan(&text, &qtype, &imageID, &audioID, &imagePath, &audioPath, &simplified, &imageAlt, &readAloud)
 	if err != nil {
 		http.Error(w, `{"error":"question not found"}`, http.StatusNotFound)
 		return
 	}
 
-	// Get time limit from round
+	// Get time limit from round, falling back to the session's default
 	var timeLimit sql.NullInt64
-	quizDB.QueryRow(`SELECT time_limit_seconds FROM rounds WHERE id = $1`, body.RoundID).Scan(&timeLimit)
-
-	payload := map[string]interface{}{
-		"roundId":        body.RoundID,
-		"questionId":     body.QuestionID,
-		"questionNumber": body.QuestionNumber,
-		"roundNumber":    body.RoundNumber,
-		"questionText":   text,
-		"imageUrl":       imagePath,
-		"audioUrl":       audioPath,
-		"timeLimit":      nil,
+	var roundType string
+	quizDB.QueryRow(`SELECT time_limit_seconds, type FROM rounds WHERE id = $1`, body.RoundID).Scan(&timeLimit, &roundType)
+	if !timeLimit.Valid || timeLimit.Int64 == 0 {
+		if settings, err := getSessionSettings(sessionID); err == nil && settings.DefaultTimeLimitSeconds > 0 {
+			timeLimit = sql.NullInt64{Int64: int64(settings.DefaultTimeLimitSeconds), Valid: true}
+		}
+	}
+
+	payload := QuestionPrecache{
+		RoundID:        body.RoundID,
+		QuestionID:     body.QuestionID,
+		QuestionNumber: body.QuestionNumber,
+		RoundNumber:    body.RoundNumber,
+		QuestionText:   text,
+		ImageURL:       imagePath,
+		AudioURL:       audioPath,
+		PhotoAnswer:    roundType == "photo",
+		Accessibility:  questionAccessibility(text, simplified, imageAlt, readAloud),
 	}
 	if timeLimit.Valid {
-		payload["timeLimit"] = timeLimit.Int64
+		payload.TimeLimit = &timeLimit.Int64
+	}
+
+	phase, err := advancePhase(sessionID, phaseLoaded, body.QuestionID, func(p *PhaseState) {
+		p.RoundID = body.RoundID
+		p.QuestionID = body.QuestionID
+		p.RoundNumber = body.RoundNumber
+		p.QuestionNumber = body.QuestionNumber
+	})
+	if err != nil {
+		writePhaseError(w, err)
+		return
 	}
 
-	_ = publishEvent(sessionID, "question_precache", payload)
+	_ = publishEvent(evQuestionPrecache.New(sessionKey(sessionID), payload))
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"status": "loaded"})
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "loaded", "phase": phase})
 }
 
 func handleRevealQuestion(w http.ResponseWriter, r *http.Request) {
@@ -243,10 +435,16 @@ func handleRevealQuestion(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_ = publishEvent(sessionID, "question_reveal", map[string]interface{}{"questionId": body.QuestionID})
+	phase, err := advancePhase(sessionID, phaseRevealed, body.QuestionID, nil)
+	if err != nil {
+		writePhaseError(w, err)
+		return
+	}
+
+	_ = publishEvent(evQuestionReveal.New(sessionKey(sessionID), QuestionRef{QuestionID: body.QuestionID}))
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"status": "revealed"})
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "revealed", "phase": phase})
 }
 
 func handleAudioPlay(w http.ResponseWriter, r *http.Request) {
@@ -264,7 +462,17 @@ func handleAudioPlay(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_ = publishEvent(sessionID, "audio_play", map[string]interface{}{"audioUrl": body.AudioURL})
+	phase, err := getSessionPhase(sessionID)
+	if err != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+	if phase.Phase != phaseRevealed && phase.Phase != phaseAnswersOpen {
+		http.Error(w, `{"error":"audio can only play while a question is revealed"}`, http.StatusConflict)
+		return
+	}
+
+	_ = publishEvent(evAudioPlay.New(sessionKey(sessionID), AudioPlay{AudioURL: body.AudioURL}))
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{"status": "playing"})
@@ -282,10 +490,21 @@ func handleCloseAnswers(w http.ResponseWriter, r *http.Request) {
 	}
 	json.NewDecoder(r.Body).Decode(&body)
 
-	_ = publishEvent(sessionID, "answers_closed", map[string]interface{}{"questionId": body.QuestionID})
+	phase, err := closeAnswers(sessionID, body.QuestionID)
+	if _, ok := err.(*phaseError); ok {
+		// The timer may have closed this question already
+		if current, _ := getSessionPhase(sessionID); current != nil && current.Phase == phaseClosed &&
+			(body.QuestionID == 0 || body.QuestionID == current.QuestionID) {
+			phase, err = current, nil
+		}
+	}
+	if err != nil {
+		writePhaseError(w, err)
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"status": "closed"})
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "closed", "phase": phase})
 }
 
 func handleStartTimer(w http.ResponseWriter, r *http.Request) {
@@ -304,13 +523,20 @@ func handleStartTimer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_ = publishEvent(sessionID, "timer_start", map[string]interface{}{
-		"questionId":      body.QuestionID,
-		"durationSeconds": body.DurationSeconds,
+	// Opens answers; the deadline in phase_changed drives client countdowns
+	phase, err := advancePhase(sessionID, phaseAnswersOpen, body.QuestionID, func(p *PhaseState) {
+		if body.DurationSeconds > 0 {
+			deadline := p.ChangedAt.Add(time.Duration(body.DurationSeconds) * time.Second)
+			p.Deadline = &deadline
+		}
 	})
+	if err != nil {
+		writePhaseError(w, err)
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"status": "timer_started"})
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "answers_open", "phase": phase})
 }
 
 func handleGetAnswers(w http.ResponseWriter, r *http.Request) {
@@ -331,7 +557,8 @@ func handleGetAnswers(w http.ResponseWriter, r *http.Request) {
 
 	rows, err := quizDB.Query(`
 		SELECT a.id, a.player_id, a.team_id, sp.user_email, COALESCE(sp.user_name,''),
-		       COALESCE(t.name,''), COALESCE(a.answer_text,''), a.is_correct, a.points
+		       COALESCE(t.name,''), COALESCE(a.answer_text,''), a.is_correct, a.points,
+		       a.flagged_words, COALESCE(a.moderation,''), a.photo_path IS NOT NULL, a.photo_purged_at IS NOT NULL
 		FROM answers a
 		JOIN session_players sp ON sp.id = a.player_id
 		LEFT JOIN teams t ON t.id = a.team_id
@@ -349,7 +576,8 @@ func handleGetAnswers(w http.ResponseWriter, r *http.Request) {
 		var isCorrect sql.NullBool
 		var teamID sql.NullInt64
 		if err := rows.Scan(&a.ID, &a.PlayerID, &teamID, &a.PlayerEmail, &a.PlayerName,
-			&a.TeamName, &a.AnswerText, &isCorrect, &a.Points); err != nil {
+			&a.TeamName, &a.AnswerText, &isCorrect, &a.Points,
+			pq.Array(&a.FlaggedWords), &a.Moderation, &a.HasPhoto, &a.PhotoPurged); err != nil {
 			continue
 		}
 		if teamID.Valid {
@@ -384,8 +612,37 @@ func handleMarkAnswer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if body.Points == 0 && body.IsCorrect {
-		body.Points = 1
+	// Points not given explicitly come from the session's scoring rules
+	if body.Points == 0 {
+		settings, err := getSessionSettings(sessionID)
+		if err != nil {
+			http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+			return
+		}
+		body.Points = settings.pointsFor(body.IsCorrect)
+	}
+
+	var questionID int
+	err = quizDB.QueryRow(`SELECT question_id FROM answers WHERE id=$1 AND session_id=$2`, body.AnswerID, sessionID).Scan(&questionID)
+	if err == sql.ErrNoRows {
+		http.Error(w, `{"error":"answer not found"}`, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	// Earlier questions can be marked at any time; the current one only once answers close
+	phase, err := getSessionPhase(sessionID)
+	if err != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+	current := phase.QuestionID == questionID
+	if current && (phase.Phase == phaseLoaded || phase.Phase == phaseRevealed || phase.Phase == phaseAnswersOpen) {
+		http.Error(w, `{"error":"answers are still open"}`, http.StatusConflict)
+		return
 	}
 
 	_, err = quizDB.Exec(`
@@ -398,8 +655,16 @@ func handleMarkAnswer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if current && phase.Phase == phaseClosed {
+		if next, err := advancePhase(sessionID, phaseMarked, questionID, nil); err != nil {
+			log.Printf("Session %d: could not move to marked: %v", sessionID, err)
+		} else {
+			phase = next
+		}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"status": "marked"})
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "marked", "points": body.Points, "phase": phase})
 }
 
 func handlePushScores(w http.ResponseWriter, r *http.Request) {
@@ -414,37 +679,16 @@ func handlePushScores(w http.ResponseWriter, r *http.Request) {
 	}
 	json.NewDecoder(r.Body).Decode(&body)
 
-	// Calculate scores per team (or per player in individual mode)
-	rows, err := quizDB.Query(`
-		SELECT COALESCE(t.id, sp.id) as entity_id,
-		       COALESCE(t.name, sp.user_name, sp.user_email) as entity_name,
-		       COALESCE(SUM(a.points), 0) as total_points
-		FROM session_players sp
-		LEFT JOIN teams t ON t.id = sp.team_id
-		LEFT JOIN answers a ON a.player_id = sp.id AND a.session_id = sp.session_id AND a.is_correct = TRUE
-		WHERE sp.session_id = $1
-		GROUP BY COALESCE(t.id, sp.id), COALESCE(t.name, sp.user_name, sp.user_email)
-		ORDER BY total_points DESC`, sessionID)
+	phase, err := advancePhase(sessionID, phaseScoresPushed, 0, nil)
 	if err != nil {
-		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		writePhaseError(w, err)
 		return
 	}
-	defer rows.Close()
-
-	type ScoreEntry struct {
-		TeamID      int    `json:"teamId"`
-		Name        string `json:"name"`
-		Total       int    `json:"total"`
-		RoundPoints int    `json:"roundPoints"`
-	}
 
-	scores := []ScoreEntry{}
-	for rows.Next() {
-		var s ScoreEntry
-		if err := rows.Scan(&s.TeamID, &s.Name, &s.Total); err != nil {
-			continue
-		}
-		scores = append(scores, s)
+	scores, err := getStandings(sessionID)
+	if err != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
 	}
 
 	// Record the push
@@ -452,13 +696,13 @@ func handlePushScores(w http.ResponseWriter, r *http.Request) {
 	if body.RoundID != nil {
 		roundIDVal = *body.RoundID
 	}
-	quizDB.Exec(`INSERT INTO score_reveals (session_id, round_id) VALUES ($1, $2)`, sessionID, roundIDVal)
+	recordScoreReveal(sessionID, roundIDVal, scores)
 
 	// Publish to players and display
-	_ = publishEvent(sessionID, "scores_revealed", map[string]interface{}{"scores": scores})
+	_ = publishEvent(evScoresRevealed.New(sessionKey(sessionID), ScoresRevealed{Scores: scores}))
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{"scores": scores})
+	json.NewEncoder(w).Encode(map[string]interface{}{"scores": scores, "phase": phase})
 }
 
 func handleEndSession(w http.ResponseWriter, r *http.Request) {
@@ -474,10 +718,69 @@ func handleEndSession(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_ = publishEvent(sessionID, "quiz_ended", map[string]interface{}{"sessionId": sessionID})
+	// Final positions, with ties split by any tie-breaks played
+	standings, err := saveFinalPositions(sessionID)
+	if err != nil {
+		log.Printf("Session %d: failed to save final positions: %v", sessionID, err)
+	}
+	invalidateDisplay(sessionID)
+
+	_ = publishEvent(evQuizEnded.New(sessionKey(sessionID), QuizEnded{SessionID: sessionID, Standings: standings}))
+	go publishQuizWinners(sessionID, standings)
+	go awardQuizAttendance(sessionID)
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"status": "ended"})
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "ended", "standings": standings})
+}
+
+// awardQuizAttendance gives every player in a finished session their
+// quiz_attendance loyalty points, and logs the turnout for the landlord's
+// usage dashboard
+func awardQuizAttendance(sessionID int) {
+	var venueID int
+	if err := quizDB.QueryRow(`SELECT COALESCE(venue_id, 0) FROM sessions WHERE id = $1`, sessionID).Scan(&venueID); err != nil {
+		log.Printf("Session %d: failed to load session for points: %v", sessionID, err)
+		return
+	}
+
+	rows, err := quizDB.Query(`SELECT user_email FROM session_players WHERE session_id = $1`, sessionID)
+	if err != nil {
+		log.Printf("Session %d: failed to load players for points: %v", sessionID, err)
+		return
+	}
+	var players []string
+	for rows.Next() {
+		var email string
+		if rows.Scan(&email) == nil {
+			players = append(players, email)
+		}
+	}
+	rows.Close()
+
+	if err := analytics.Record(identityDB, analytics.Event{
+		Event:   analytics.EventQuizParticipation,
+		App:     "quiz-player",
+		Value:   float64(len(players)),
+		VenueID: venueID,
+	}); err != nil {
+		log.Printf("Session %d: failed to record quiz participation: %v", sessionID, err)
+	}
+
+	awarded := 0
+	for _, email := range players {
+		if _, err := points.Record(identityDB, points.Award{
+			UserEmail: email,
+			Activity:  points.ActivityQuizAttendance,
+			App:       "quiz-player",
+			Ref:       strconv.Itoa(sessionID),
+			VenueID:   venueID,
+		}); err != nil {
+			log.Printf("Session %d: failed to award points to %s: %v", sessionID, email, err)
+			continue
+		}
+		awarded++
+	}
+	log.Printf("⭐ Session %d: attendance points recorded for %d player(s)", sessionID, awarded)
 }
 
 func handleLobbyStream(w http.ResponseWriter, r *http.Request) {
@@ -492,14 +795,12 @@ func handleLobbyStream(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Connection", "keep-alive")
 	w.Header().Set("X-Accel-Buffering", "no")
 
-	flusher, ok := w.(http.Flusher)
-	if !ok {
+	if _, ok := w.(http.Flusher); !ok {
 		http.Error(w, "streaming not supported", http.StatusInternalServerError)
 		return
 	}
 
-	fmt.Fprintf(w, "event: connected\ndata: {\"sessionId\":%d}\n\n", sessionID)
-	flusher.Flush()
+	events.Send(w, evConnected.New(sessionKey(sessionID), StreamOpened{SessionID: sessionID}))
 
 	pubsub, msgChan := subscribeToLobby(sessionID)
 	defer pubsub.Close()
@@ -513,11 +814,9 @@ func handleLobbyStream(w http.ResponseWriter, r *http.Request) {
 		case <-ctx.Done():
 			return
 		case msg := <-msgChan:
-			fmt.Fprintf(w, "data: %s\n\n", msg.Payload)
-			flusher.Flush()
+			events.Forward(w, msg.Payload)
 		case <-ticker.C:
-			fmt.Fprintf(w, "event: ping\ndata: {}\n\n")
-			flusher.Flush()
+			events.Send(w, events.Ping.New(sessionKey(sessionID), events.NoPayload{}))
 		}
 	}
 }
@@ -548,11 +847,30 @@ func getSessionPlayers(sessionID int) ([]Player, error) {
 		}
 		players = append(players, p)
 	}
+
+	// Decorate with profile flair/avatar (best effort)
+	emails := make([]string, 0, len(players))
+	for _, p := range players {
+		emails = append(emails, p.UserEmail)
+	}
+	if profiles, err := authlib.LoadProfiles(identityDB, emails); err == nil {
+		for i := range players {
+			if p, ok := profiles[players[i].UserEmail]; ok {
+				public := p.Public()
+				players[i].Flair = public.Flair
+				players[i].AvatarURL = public.AvatarURL
+			}
+		}
+	}
+
 	return players, nil
 }
 
 func getSessionTeams(sessionID int) ([]Team, error) {
-	rows, err := quizDB.Query(`SELECT id, session_id, name, COALESCE(join_code,'') FROM teams WHERE session_id = $1 ORDER BY id`, sessionID)
+	rows, err := quizDB.Query(`
+		SELECT id, session_id, name, COALESCE(join_code,''), COALESCE(created_by,''),
+		       flagged_words, COALESCE(masked_name,''), COALESCE(moderation,''), captain_player_id
+		FROM teams WHERE session_id = $1 ORDER BY id`, sessionID)
 	if err != nil {
 		return nil, err
 	}
@@ -561,15 +879,23 @@ func getSessionTeams(sessionID int) ([]Team, error) {
 	teams := []Team{}
 	for rows.Next() {
 		var t Team
-		if err := rows.Scan(&t.ID, &t.SessionID, &t.Name, &t.JoinCode); err != nil {
+		var captainID sql.NullInt64
+		if err := rows.Scan(&t.ID, &t.SessionID, &t.Name, &t.JoinCode, &t.CreatedBy,
+			pq.Array(&t.FlaggedWords), &t.MaskedName, &t.Moderation, &captainID); err != nil {
 			continue
 		}
+		if captainID.Valid {
+			v := int(captainID.Int64)
+			t.CaptainPlayerID = &v
+		}
 		teams = append(teams, t)
 	}
 	return teams, nil
 }
 
-func getSessionRounds(packID int) ([]map[string]interface{}, error) {
+// getSessionRounds returns the pack's rounds with questions, in the session's
+// round order (if set) and with its default time limit applied
+func getSessionRounds(packID int, settings SessionSettings) ([]map[string]interface{}, error) {
 	rows, err := quizDB.Query(`
 		SELECT r.id, r.round_number, r.name, r.type, COALESCE(r.time_limit_seconds, 0),
 		       COUNT(rq.id) as question_count
@@ -589,6 +915,9 @@ func getSessionRounds(packID int) ([]map[string]interface{}, error) {
 		if err := rows.Scan(&id, &roundNum, &name, &rtype, &timeLimit, &qcount); err != nil {
 			continue
 		}
+		if timeLimit == 0 {
+			timeLimit = settings.DefaultTimeLimitSeconds
+		}
 		// Fetch questions for this round
 		qrows, _ := quizDB.Query(`
 			SELECT rq.position, q.id, q.text, q.answer, q.type,
@@ -617,7 +946,21 @@ func getSessionRounds(packID int) ([]map[string]interface{}, error) {
 			"timeLimitSeconds": timeLimit, "questionCount": qcount, "questions": questions,
 		})
 	}
-	return rounds, nil
+
+	if len(settings.RoundOrder) == 0 {
+		return rounds, nil
+	}
+	byID := map[int]map[string]interface{}{}
+	for _, rd := range rounds {
+		byID[rd["id"].(int)] = rd
+	}
+	ordered := []map[string]interface{}{}
+	for _, id := range settings.RoundOrder {
+		if rd, ok := byID[id]; ok {
+			ordered = append(ordered, rd)
+		}
+	}
+	return ordered, nil
 }
 
 func generateCode(length int) (string, error) {
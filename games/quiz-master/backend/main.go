@@ -6,9 +6,13 @@ import (
 	"net/http"
 
 	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/achgithub/activity-hub-common/cli"
 	"github.com/achgithub/activity-hub-common/config"
 	"github.com/achgithub/activity-hub-common/database"
-	"github.com/gorilla/handlers"
+	"github.com/achgithub/activity-hub-common/events"
+	apphttp "github.com/achgithub/activity-hub-common/http"
+	"github.com/achgithub/activity-hub-common/maintenance"
+	"github.com/achgithub/activity-hub-common/upload"
 	"github.com/gorilla/mux"
 )
 
@@ -18,6 +22,12 @@ var (
 )
 
 func main() {
+	cli.New("quiz-master",
+		cli.Serve(serve),
+	).Main()
+}
+
+func serve() {
 	var err error
 	identityDB, err = database.InitIdentityDatabase()
 	if err != nil {
@@ -32,46 +42,92 @@ func main() {
 	defer quizDB.Close()
 
 	initRedis()
+	initCache()
+
+	// Timed questions close themselves at the deadline
+	go runQuestionTimers()
 
 	r := mux.NewRouter()
 
 	// Public config
 	r.HandleFunc("/api/config", handleConfig).Methods("GET")
+	r.HandleFunc("/api/events/schema", events.SchemaHandler).Methods("GET")
+	r.HandleFunc("/api/openapi.json", apiSpec().Handler).Methods("GET")
 
 	// Serve media uploaded by game-admin (shared uploads directory)
-	r.PathPrefix("/uploads/").Handler(http.StripPrefix("/uploads/", http.FileServer(http.Dir("./uploads"))))
+	r.PathPrefix("/uploads/").Handler(http.StripPrefix("/uploads/", upload.FileServer("./uploads")))
 
-	// Authenticated + role-checked routes
+	// Authenticated routes. Session routes are checked against the user's
+	// role in that session: hosts run the quiz, scorekeeper co-hosts mark.
 	api := r.PathPrefix("/api").Subrouter()
 	api.Use(authlib.Middleware(identityDB))
-	api.Use(requireQuizRole)
+
+	hostOnly := requireSessionRole(sessionRoleHost)
+	staff := requireSessionRole(sessionRoleHost, sessionRoleScorekeeper)
 
 	// Pack listing for session creation
-	api.HandleFunc("/packs", handleGetPacks).Methods("GET")
+	api.Handle("/packs", requireQuizRole(http.HandlerFunc(handleGetPacks))).Methods("GET")
 
 	// Session management
-	api.HandleFunc("/sessions", handleCreateSession).Methods("POST")
-	api.HandleFunc("/sessions/{id}", handleGetSession).Methods("GET")
-	api.HandleFunc("/sessions/{id}/start", handleStartSession).Methods("POST")
+	api.Handle("/sessions", requireQuizRole(http.HandlerFunc(handleCreateSession))).Methods("POST")
+	api.HandleFunc("/sessions/{id}", staff(handleGetSession)).Methods("GET")
+	api.HandleFunc("/sessions/{id}/start", hostOnly(handleStartSession)).Methods("POST")
+	api.HandleFunc("/sessions/{id}/schedule", hostOnly(handleScheduleSession)).Methods("PUT")
+	api.HandleFunc("/sessions/{id}/accessibility", hostOnly(handleSetAccessibility)).Methods("PUT")
+	api.HandleFunc("/sessions/{id}/teams", hostOnly(handleCreateTeam)).Methods("POST")
+	api.HandleFunc("/sessions/{id}/teams/{teamId}/captain", hostOnly(handleSetTeamCaptain)).Methods("POST")
+
+	// Session templates (pack, round order, timers, scoring, team size)
+	api.Handle("/templates", requireQuizRole(http.HandlerFunc(handleGetTemplates))).Methods("GET")
+	api.Handle("/templates", requireQuizRole(http.HandlerFunc(handleCreateTemplate))).Methods("POST")
+	api.Handle("/templates/{id}", requireQuizRole(http.HandlerFunc(handleGetTemplate))).Methods("GET")
+	api.Handle("/templates/{id}", requireQuizRole(http.HandlerFunc(handleUpdateTemplate))).Methods("PUT")
+	api.Handle("/templates/{id}", requireQuizRole(http.HandlerFunc(handleDeleteTemplate))).Methods("DELETE")
+	api.Handle("/templates/{id}/sessions", requireQuizRole(http.HandlerFunc(handleCreateSessionFromTemplate))).Methods("POST")
+
+	// Co-hosts
+	api.HandleFunc("/cohosting", handleGetCoHosting).Methods("GET")
+	api.HandleFunc("/sessions/{id}/cohosts", staff(handleGetCoHosts)).Methods("GET")
+	api.HandleFunc("/sessions/{id}/cohosts", hostOnly(handleInviteCoHost)).Methods("POST")
+	api.HandleFunc("/sessions/{id}/cohosts/{email}", hostOnly(handleRemoveCoHost)).Methods("DELETE")
 
 	// Quiz control
-	api.HandleFunc("/sessions/{id}/load-question", handleLoadQuestion).Methods("POST")
-	api.HandleFunc("/sessions/{id}/reveal", handleRevealQuestion).Methods("POST")
-	api.HandleFunc("/sessions/{id}/audio-play", handleAudioPlay).Methods("POST")
-	api.HandleFunc("/sessions/{id}/close-answers", handleCloseAnswers).Methods("POST")
-	api.HandleFunc("/sessions/{id}/start-timer", handleStartTimer).Methods("POST")
-
-	// Marking
-	api.HandleFunc("/sessions/{id}/answers/{questionId}", handleGetAnswers).Methods("GET")
-	api.HandleFunc("/sessions/{id}/mark", handleMarkAnswer).Methods("POST")
-	api.HandleFunc("/sessions/{id}/push-scores", handlePushScores).Methods("POST")
+	api.HandleFunc("/sessions/{id}/load-question", hostOnly(handleLoadQuestion)).Methods("POST")
+	api.HandleFunc("/sessions/{id}/reveal", hostOnly(handleRevealQuestion)).Methods("POST")
+	api.HandleFunc("/sessions/{id}/audio-play", hostOnly(handleAudioPlay)).Methods("POST")
+	api.HandleFunc("/sessions/{id}/close-answers", hostOnly(handleCloseAnswers)).Methods("POST")
+	api.HandleFunc("/sessions/{id}/start-timer", hostOnly(handleStartTimer)).Methods("POST")
+
+	// Marking (co-hosts too)
+	api.HandleFunc("/sessions/{id}/answers/{questionId}", staff(handleGetAnswers)).Methods("GET")
+	api.HandleFunc("/sessions/{id}/photos", staff(handleGetPhotos)).Methods("GET")
+	api.HandleFunc("/sessions/{id}/photos/{answerId}", staff(handleGetPhoto)).Methods("GET")
+	api.HandleFunc("/sessions/{id}/mark", staff(handleMarkAnswer)).Methods("POST")
+	api.HandleFunc("/sessions/{id}/push-scores", staff(handlePushScores)).Methods("POST")
+	api.HandleFunc("/sessions/{id}/adjustments", staff(handleGetAdjustments)).Methods("GET")
+	api.HandleFunc("/sessions/{id}/adjustments/{adjustmentId}", hostOnly(handleUpdateAdjustment)).Methods("PUT")
+
+	// Tie-breaks between tied teams
+	api.HandleFunc("/sessions/{id}/tiebreaks", staff(handleGetTiebreaks)).Methods("GET")
+	api.HandleFunc("/sessions/{id}/tiebreaks", hostOnly(handleStartTiebreak)).Methods("POST")
+	api.HandleFunc("/sessions/{id}/tiebreaks/{tiebreakId}/close", hostOnly(handleCloseTiebreak)).Methods("POST")
+	api.HandleFunc("/sessions/{id}/tiebreaks/{tiebreakId}/mark", staff(handleMarkTiebreakAnswer)).Methods("POST")
+	api.HandleFunc("/sessions/{id}/tiebreaks/{tiebreakId}/resolve", staff(handleResolveTiebreak)).Methods("POST")
+
+	// Content filter: flagged team names/answers, and the venue word lists
+	api.HandleFunc("/sessions/{id}/flagged", staff(handleGetFlagged)).Methods("GET")
+	api.HandleFunc("/sessions/{id}/moderate", staff(handleModerate)).Methods("POST")
+	api.Handle("/filter", requireQuizRole(http.HandlerFunc(handleGetFilter))).Methods("GET")
+	api.Handle("/filter/words", requireQuizRole(http.HandlerFunc(handleAddFilterWord))).Methods("POST")
+	api.Handle("/filter/words/{id}", requireQuizRole(http.HandlerFunc(handleDeleteFilterWord))).Methods("DELETE")
+	api.Handle("/filter/settings", requireQuizRole(http.HandlerFunc(handleUpdateFilterSettings))).Methods("PUT")
 
 	// Session end
-	api.HandleFunc("/sessions/{id}/end", handleEndSession).Methods("POST")
+	api.HandleFunc("/sessions/{id}/end", hostOnly(handleEndSession)).Methods("POST")
 
 	// Lobby SSE for player join events (separate channel)
 	r.Handle("/api/sessions/{id}/lobby-stream",
-		authlib.SSEMiddleware(identityDB)(requireQuizRoleSSE(http.HandlerFunc(handleLobbyStream)))).Methods("GET")
+		authlib.SSEMiddleware(identityDB)(staff(handleLobbyStream))).Methods("GET")
 
 	// Serve React frontend
 	r.PathPrefix("/static/").Handler(http.FileServer(http.Dir("./static")))
@@ -79,15 +135,12 @@ func main() {
 		http.ServeFile(w, r, "./static/index.html")
 	})
 
-	corsHandler := handlers.CORS(
-		handlers.AllowedOrigins([]string{"*"}),
-		handlers.AllowedMethods([]string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}),
-		handlers.AllowedHeaders([]string{"Content-Type", "Authorization"}),
-	)
+	cors := apphttp.NewCORSPolicy(identityDB)
+	appMaintenance := maintenance.New(identityDB)
 
 	port := config.GetEnv("PORT", "5080")
 	log.Printf("Quiz Master starting on :%s", port)
-	log.Fatal(http.ListenAndServe(":"+port, corsHandler(r)))
+	log.Fatal(http.ListenAndServe(":"+port, cors.Middleware(appMaintenance.Middleware("quiz-master")(apphttp.Versioned(r)))))
 }
 
 func requireQuizRole(next http.Handler) http.Handler {
@@ -97,25 +150,10 @@ func requireQuizRole(next http.Handler) http.Handler {
 			http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
 			return
 		}
-		if !user.HasRole("quiz_master") && !user.HasRole("game_admin") && !user.HasRole("super_user") {
+		if !hasQuizRole(user) {
 			http.Error(w, `{"error":"quiz_master role required"}`, http.StatusForbidden)
 			return
 		}
 		next.ServeHTTP(w, r)
 	})
 }
-
-func requireQuizRoleSSE(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		user, ok := authlib.GetUserFromContext(r.Context())
-		if !ok {
-			http.Error(w, "unauthorized", http.StatusUnauthorized)
-			return
-		}
-		if !user.HasRole("quiz_master") && !user.HasRole("game_admin") && !user.HasRole("super_user") {
-			http.Error(w, "forbidden", http.StatusForbidden)
-			return
-		}
-		next.ServeHTTP(w, r)
-	})
-}
@@ -3,23 +3,31 @@ package main
 import "time"
 
 type Session struct {
-	ID          int        `json:"id"`
-	PackID      int        `json:"packId"`
-	Name        string     `json:"name"`
-	Mode        string     `json:"mode"`
-	Status      string     `json:"status"`
-	JoinCode    string     `json:"joinCode"`
-	CreatedBy   string     `json:"createdBy"`
-	CreatedAt   time.Time  `json:"createdAt"`
-	StartedAt   *time.Time `json:"startedAt"`
-	CompletedAt *time.Time `json:"completedAt"`
+	ID          int             `json:"id"`
+	PackID      int             `json:"packId"`
+	Name        string          `json:"name"`
+	Mode        string          `json:"mode"`
+	Status      string          `json:"status"`
+	JoinCode    string          `json:"joinCode"`
+	CreatedBy   string          `json:"createdBy"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	StartedAt   *time.Time      `json:"startedAt"`
+	CompletedAt *time.Time      `json:"completedAt"`
+	ScheduledAt *time.Time      `json:"scheduledAt,omitempty"` // Quiz night start, for email digests
+	TemplateID  *int            `json:"templateId,omitempty"`
+	Settings    SessionSettings `json:"settings"`
 }
 
 type Team struct {
-	ID        int    `json:"id"`
-	SessionID int    `json:"sessionId"`
-	Name      string `json:"name"`
-	JoinCode  string `json:"joinCode"`
+	ID              int      `json:"id"`
+	SessionID       int      `json:"sessionId"`
+	Name            string   `json:"name"`
+	JoinCode        string   `json:"joinCode"`
+	CreatedBy       string   `json:"createdBy,omitempty"`    // Set when a player started the team
+	FlaggedWords    []string `json:"flaggedWords,omitempty"` // Content filter hits in the name
+	MaskedName      string   `json:"maskedName,omitempty"`   // Shown publicly while flagged
+	Moderation      string   `json:"moderation,omitempty"`   // flagged | approved | rejected
+	CaptainPlayerID *int     `json:"captainPlayerId"`        // The player whose device answers for the team
 }
 
 type Player struct {
@@ -28,6 +36,8 @@ type Player struct {
 	UserName  string `json:"userName"`
 	TeamID    *int   `json:"teamId"`
 	TeamName  string `json:"teamName"`
+	Flair     string `json:"flair,omitempty"`
+	AvatarURL string `json:"avatarUrl,omitempty"`
 }
 
 type Round struct {
@@ -50,14 +60,18 @@ type Question struct {
 }
 
 type AnswerWithLikely struct {
-	ID             int    `json:"id"`
-	PlayerID       int    `json:"playerId"`
-	TeamID         *int   `json:"teamId"`
-	PlayerEmail    string `json:"playerEmail"`
-	PlayerName     string `json:"playerName"`
-	TeamName       string `json:"teamName"`
-	AnswerText     string `json:"answerText"`
-	IsCorrect      *bool  `json:"isCorrect"`
-	Points         int    `json:"points"`
-	IsLikelyCorrect bool  `json:"isLikelyCorrect"`
+	ID              int      `json:"id"`
+	PlayerID        int      `json:"playerId"`
+	TeamID          *int     `json:"teamId"`
+	PlayerEmail     string   `json:"playerEmail"`
+	PlayerName      string   `json:"playerName"`
+	TeamName        string   `json:"teamName"`
+	AnswerText      string   `json:"answerText"`
+	IsCorrect       *bool    `json:"isCorrect"`
+	Points          int      `json:"points"`
+	IsLikelyCorrect bool     `json:"isLikelyCorrect"`
+	FlaggedWords    []string `json:"flaggedWords,omitempty"`
+	Moderation      string   `json:"moderation,omitempty"`  // flagged | approved | rejected
+	HasPhoto        bool     `json:"hasPhoto"`              // Photo round answer: GET /api/sessions/{id}/photos/{answerId}
+	PhotoPurged     bool     `json:"photoPurged,omitempty"` // The photo was deleted after the retention period
 }
@@ -0,0 +1,301 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/achgithub/activity-hub-common/contentfilter"
+	"github.com/gorilla/mux"
+	"github.com/lib/pq"
+)
+
+// publicTeamNameSQL is a team's name (alias t) as shown to players and on the
+// big screen: masked while flagged, a placeholder once a quiz master rejects it
+const publicTeamNameSQL = `CASE t.moderation
+	WHEN 'flagged' THEN COALESCE(t.masked_name, t.name)
+	WHEN 'rejected' THEN 'Team ' || t.id
+	ELSE t.name END`
+
+// FlaggedItem is a team name or answer the content filter caught
+// (quiz-player screens them against the venue's word list)
+type FlaggedItem struct {
+	Kind         string   `json:"kind"` // team | answer | tiebreak_answer
+	ID           int      `json:"id"`
+	Text         string   `json:"text"`
+	MaskedText   string   `json:"maskedText"`
+	FlaggedWords []string `json:"flaggedWords"`
+	Moderation   string   `json:"moderation"`  // flagged | approved | rejected
+	SubmittedBy  string   `json:"submittedBy"` // Team creator or answering player
+	Question     string   `json:"question,omitempty"`
+}
+
+// FilterWord is one entry in a venue's (or the chain-wide) word list
+type FilterWord struct {
+	ID        int       `json:"id"`
+	VenueID   int       `json:"venueId"` // 0 = every venue
+	Word      string    `json:"word"`
+	CreatedBy string    `json:"createdBy"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// moderationTables holds the update for each kind, scoped to the session
+var moderationTables = map[string]string{
+	"team":            `UPDATE teams SET moderation = $1 WHERE id = $2 AND session_id = $3 AND moderation IS NOT NULL`,
+	"answer":          `UPDATE answers SET moderation = $1 WHERE id = $2 AND session_id = $3 AND moderation IS NOT NULL`,
+	"tiebreak_answer": `UPDATE tiebreak_answers SET moderation = $1 WHERE id = $2 AND moderation IS NOT NULL AND tiebreak_id IN (SELECT id FROM tiebreaks WHERE session_id = $3)`,
+}
+
+// handleGetFlagged - GET /api/sessions/{id}/flagged
+// Everything the filter caught this session, including items already moderated.
+func handleGetFlagged(w http.ResponseWriter, r *http.Request) {
+	sessionID, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	rows, err := quizDB.Query(`
+		SELECT 'team', t.id, t.name, COALESCE(t.masked_name,''), t.flagged_words, t.moderation,
+		       COALESCE(t.created_by,''), '', t.created_at
+		FROM teams t
+		WHERE t.session_id = $1 AND t.moderation IS NOT NULL
+		UNION ALL
+		SELECT 'answer', a.id, COALESCE(a.answer_text,''), COALESCE(a.masked_text,''), a.flagged_words, a.moderation,
+		       COALESCE(sp.user_name, sp.user_email), q.text, a.submitted_at
+		FROM answers a
+		JOIN session_players sp ON sp.id = a.player_id
+		JOIN questions q ON q.id = a.question_id
+		WHERE a.session_id = $1 AND a.moderation IS NOT NULL
+		UNION ALL
+		SELECT 'tiebreak_answer', ta.id, COALESCE(ta.answer_text,''), COALESCE(ta.masked_text,''), ta.flagged_words, ta.moderation,
+		       COALESCE(sp.user_name, sp.user_email, ''), q.text, ta.submitted_at
+		FROM tiebreak_answers ta
+		JOIN tiebreaks tb ON tb.id = ta.tiebreak_id
+		JOIN questions q ON q.id = tb.question_id
+		LEFT JOIN session_players sp ON sp.id = ta.player_id
+		WHERE tb.session_id = $1 AND ta.moderation IS NOT NULL
+		ORDER BY 9`, sessionID)
+	if err != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	items := []FlaggedItem{}
+	for rows.Next() {
+		var item FlaggedItem
+		var at time.Time
+		if err := rows.Scan(&item.Kind, &item.ID, &item.Text, &item.MaskedText, pq.Array(&item.FlaggedWords),
+			&item.Moderation, &item.SubmittedBy, &item.Question, &at); err != nil {
+			continue
+		}
+		items = append(items, item)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"items": items})
+}
+
+// handleModerate - POST /api/sessions/{id}/moderate {"kind": "team", "id": 3, "action": "approve"}
+// Approving shows the text as typed; rejecting hides it (teams become "Team <id>").
+func handleModerate(w http.ResponseWriter, r *http.Request) {
+	sessionID, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	var body struct {
+		Kind   string `json:"kind"`
+		ID     int    `json:"id"`
+		Action string `json:"action"` // approve | reject
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, `{"error":"invalid JSON"}`, http.StatusBadRequest)
+		return
+	}
+	query, ok := moderationTables[body.Kind]
+	if !ok {
+		http.Error(w, `{"error":"kind must be team, answer or tiebreak_answer"}`, http.StatusBadRequest)
+		return
+	}
+	var moderation string
+	switch body.Action {
+	case "approve":
+		moderation = "approved"
+	case "reject":
+		moderation = "rejected"
+	default:
+		http.Error(w, `{"error":"action must be approve or reject"}`, http.StatusBadRequest)
+		return
+	}
+
+	res, err := quizDB.Exec(query, moderation, body.ID, sessionID)
+	if err != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		http.Error(w, `{"error":"nothing flagged with that id"}`, http.StatusNotFound)
+		return
+	}
+
+	// Screens showing the old text refresh names/answers
+	_ = publishEvent(evContentModerated.New(sessionKey(sessionID), ContentModerated{Kind: body.Kind, ID: body.ID, Moderation: moderation}))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"kind": body.Kind, "id": body.ID, "moderation": moderation})
+}
+
+// filterVenue checks the user may manage venueID's filter (0 = chain-wide), writing a 403 if not
+func filterVenue(w http.ResponseWriter, user *authlib.AuthUser, venueID int) bool {
+	if venueID < 0 || !user.CanAccessVenue(venueID) {
+		http.Error(w, `{"error":"not allowed to manage this venue's filter"}`, http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// handleGetFilter - GET /api/filter?venue=2
+// The venue's word list and action, plus the chain-wide words it inherits.
+// Defaults to the user's own venue.
+func handleGetFilter(w http.ResponseWriter, r *http.Request) {
+	user, _ := authlib.GetUserFromContext(r.Context())
+	venueID := user.VenueID
+	if v := r.URL.Query().Get("venue"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, `{"error":"invalid venue"}`, http.StatusBadRequest)
+			return
+		}
+		venueID = id
+	}
+	if !filterVenue(w, user, venueID) {
+		return
+	}
+
+	rows, err := quizDB.Query(`
+		SELECT id, venue_id, word, COALESCE(created_by,''), created_at
+		FROM content_filter_words WHERE venue_id IN (0, $1)
+		ORDER BY venue_id, word`, venueID)
+	if err != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	words := []FilterWord{}
+	for rows.Next() {
+		var fw FilterWord
+		if err := rows.Scan(&fw.ID, &fw.VenueID, &fw.Word, &fw.CreatedBy, &fw.CreatedAt); err != nil {
+			continue
+		}
+		words = append(words, fw)
+	}
+
+	action := contentfilter.ActionFlag
+	quizDB.QueryRow(`
+		SELECT action FROM content_filter_settings WHERE venue_id IN (0, $1)
+		ORDER BY venue_id DESC LIMIT 1`, venueID).Scan(&action)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"venueId": venueID,
+		"action":  action,
+		"words":   words,
+	})
+}
+
+// handleAddFilterWord - POST /api/filter/words {"venueId": 2, "word": "..."}
+func handleAddFilterWord(w http.ResponseWriter, r *http.Request) {
+	user, _ := authlib.GetUserFromContext(r.Context())
+
+	var body struct {
+		VenueID int    `json:"venueId"`
+		Word    string `json:"word"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || strings.TrimSpace(body.Word) == "" {
+		http.Error(w, `{"error":"word required"}`, http.StatusBadRequest)
+		return
+	}
+	if !filterVenue(w, user, body.VenueID) {
+		return
+	}
+
+	fw := FilterWord{VenueID: body.VenueID, Word: strings.ToLower(strings.TrimSpace(body.Word)), CreatedBy: user.ActorEmail()}
+	err := quizDB.QueryRow(`
+		INSERT INTO content_filter_words (venue_id, word, created_by) VALUES ($1, $2, $3)
+		ON CONFLICT (venue_id, word) DO UPDATE SET word = EXCLUDED.word
+		RETURNING id, created_at`,
+		fw.VenueID, fw.Word, fw.CreatedBy,
+	).Scan(&fw.ID, &fw.CreatedAt)
+	if err != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(fw)
+}
+
+// handleDeleteFilterWord - DELETE /api/filter/words/{id}
+func handleDeleteFilterWord(w http.ResponseWriter, r *http.Request) {
+	user, _ := authlib.GetUserFromContext(r.Context())
+	wordID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, `{"error":"invalid id"}`, http.StatusBadRequest)
+		return
+	}
+
+	var venueID int
+	err = quizDB.QueryRow(`SELECT venue_id FROM content_filter_words WHERE id = $1`, wordID).Scan(&venueID)
+	if err == sql.ErrNoRows {
+		http.Error(w, `{"error":"word not found"}`, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+	if !filterVenue(w, user, venueID) {
+		return
+	}
+
+	if _, err := quizDB.Exec(`DELETE FROM content_filter_words WHERE id = $1`, wordID); err != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "deleted"})
+}
+
+// handleUpdateFilterSettings - PUT /api/filter/settings {"venueId": 2, "action": "block"}
+func handleUpdateFilterSettings(w http.ResponseWriter, r *http.Request) {
+	user, _ := authlib.GetUserFromContext(r.Context())
+
+	var body struct {
+		VenueID int    `json:"venueId"`
+		Action  string `json:"action"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, `{"error":"invalid JSON"}`, http.StatusBadRequest)
+		return
+	}
+	if body.Action != contentfilter.ActionFlag && body.Action != contentfilter.ActionBlock {
+		http.Error(w, `{"error":"action must be flag or block"}`, http.StatusBadRequest)
+		return
+	}
+	if !filterVenue(w, user, body.VenueID) {
+		return
+	}
+
+	_, err := quizDB.Exec(`
+		INSERT INTO content_filter_settings (venue_id, action, updated_by) VALUES ($1, $2, $3)
+		ON CONFLICT (venue_id) DO UPDATE SET action = EXCLUDED.action, updated_by = EXCLUDED.updated_by, updated_at = NOW()`,
+		body.VenueID, body.Action, user.ActorEmail())
+	if err != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"venueId": body.VenueID, "action": body.Action})
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"net/http"
+
+	apphttp "github.com/achgithub/activity-hub-common/http"
+	"github.com/achgithub/activity-hub-common/openapi"
+)
+
+// Response shapes shared by several routes
+var (
+	statusOnly   = openapi.Fields{"status": ""}
+	phaseStatus  = openapi.Fields{"status": "", "phase": &PhaseState{}}
+	sessionRound = openapi.Fields{
+		"id": 0, "roundNumber": 0, "name": "", "type": "",
+		"timeLimitSeconds": 0, "questionCount": 0, "questions": []Question{},
+	}
+)
+
+// apiSpec describes the routes registered in main.go for GET /api/openapi.json.
+// Add a route here whenever one is added there.
+func apiSpec() *openapi.Spec {
+	spec := openapi.New("quiz-master", "1.0.0",
+		"Host console for pub quizzes: sessions, question control, marking, tie-breaks and moderation. "+
+			"Session routes need the host role in that session, or scorekeeper co-host for marking.")
+
+	public := spec.Group("Public")
+	public.Route("GET", "/api/config", "App configuration").
+		Returns(http.StatusOK, apphttp.AppConfig{})
+	public.Route("GET", "/api/events/schema", "JSON Schema of the session stream events").
+		Produces(http.StatusOK, "application/schema+json")
+	public.Route("GET", "/api/openapi.json", "This document").
+		Returns(http.StatusOK, nil)
+
+	sessions := spec.Group("Sessions").Auth()
+	sessions.Route("GET", "/api/packs", "Quiz packs available for a new session").
+		Returns(http.StatusOK, openapi.Fields{"packs": []openapi.Fields{{"id": 0, "name": "", "description": "", "roundCount": 0}}})
+	sessions.Route("POST", "/api/sessions", "Create a session from a pack").
+		Body(openapi.Fields{"packId": 0, "name": "", "mode": "team | individual", "scheduledAt": "2026-03-05T19:30"}).
+		Returns(http.StatusOK, openapi.Fields{"sessionId": 0, "joinCode": "", "mode": ""})
+	sessions.Route("GET", "/api/sessions/{id}", "Session with its players, teams, rounds and current phase").
+		Returns(http.StatusOK, openapi.Fields{
+			"session": Session{}, "players": []Player{}, "teams": []Team{},
+			"rounds": []openapi.Fields{sessionRound}, "myRole": "", "phase": &PhaseState{},
+		})
+	sessions.Route("POST", "/api/sessions/{id}/start", "Start the session").
+		Returns(http.StatusOK, statusOnly)
+	sessions.Route("PUT", "/api/sessions/{id}/schedule", "Set or clear when a session in the lobby starts").
+		Body(openapi.Fields{"scheduledAt": "2026-03-05T19:30"}).
+		Returns(http.StatusOK, openapi.Fields{"scheduledAt": ""})
+	sessions.Route("PUT", "/api/sessions/{id}/accessibility", "Set the session's accessibility defaults (large text, high contrast, read-aloud, simplified text)").
+		Body(AccessibilityOptions{}).
+		Returns(http.StatusOK, openapi.Fields{"accessibility": AccessibilityOptions{}})
+	sessions.Route("POST", "/api/sessions/{id}/teams", "Add a team").
+		Body(openapi.Fields{"name": ""}).
+		Returns(http.StatusOK, Team{})
+	sessions.Route("POST", "/api/sessions/{id}/teams/{teamId}/captain", "Make a player the team captain, unlocking the team from the old captain's device").
+		Body(openapi.Fields{"playerId": 0}).
+		Returns(http.StatusOK, openapi.Fields{"status": "", "teamId": 0, "captainPlayerId": 0})
+	sessions.Route("POST", "/api/sessions/{id}/end", "End the session and record final positions").
+		Returns(http.StatusOK, openapi.Fields{"status": "", "standings": []ScoreEntry{}})
+	sessions.Route("GET", "/api/sessions/{id}/lobby-stream", "Player join events (token in the query string)").
+		Stream()
+
+	templates := spec.Group("Templates").Auth()
+	templates.Route("GET", "/api/templates", "Session templates").
+		Returns(http.StatusOK, openapi.Fields{"templates": []SessionTemplate{}})
+	templates.Route("POST", "/api/templates", "Create a template").
+		Body(SessionTemplate{}).
+		Returns(http.StatusOK, openapi.Fields{"id": 0})
+	templates.Route("GET", "/api/templates/{id}", "A template").
+		Returns(http.StatusOK, SessionTemplate{})
+	templates.Route("PUT", "/api/templates/{id}", "Update a template").
+		Body(SessionTemplate{}).
+		Returns(http.StatusOK, statusOnly)
+	templates.Route("DELETE", "/api/templates/{id}", "Delete a template").
+		Returns(http.StatusOK, statusOnly)
+	templates.Route("POST", "/api/templates/{id}/sessions", "Create a session from a template").
+		Body(openapi.Fields{"name": "", "scheduledAt": ""}).
+		Returns(http.StatusOK, openapi.Fields{"sessionId": 0, "joinCode": "", "mode": "", "templateId": 0})
+
+	cohosts := spec.Group("Co-hosts").Auth()
+	cohosts.Route("GET", "/api/cohosting", "Open sessions the caller co-hosts").
+		Returns(http.StatusOK, openapi.Fields{"sessions": []Session{}})
+	cohosts.Route("GET", "/api/sessions/{id}/cohosts", "A session's co-hosts").
+		Returns(http.StatusOK, openapi.Fields{"cohosts": []CoHost{}})
+	cohosts.Route("POST", "/api/sessions/{id}/cohosts", "Invite a scorekeeper co-host").
+		Body(openapi.Fields{"email": ""}).
+		Returns(http.StatusOK, statusOnly)
+	cohosts.Route("DELETE", "/api/sessions/{id}/cohosts/{email}", "Remove a co-host").
+		Returns(http.StatusOK, statusOnly)
+
+	control := spec.Group("Quiz control").Auth()
+	control.Route("POST", "/api/sessions/{id}/load-question", "Load a question for the display and players").
+		Body(openapi.Fields{"roundId": 0, "questionId": 0, "questionNumber": 0, "roundNumber": 0}).
+		Returns(http.StatusOK, phaseStatus)
+	control.Route("POST", "/api/sessions/{id}/reveal", "Reveal the loaded question").
+		Body(openapi.Fields{"questionId": 0}).
+		Returns(http.StatusOK, phaseStatus)
+	control.Route("POST", "/api/sessions/{id}/audio-play", "Play a question's audio on the display").
+		Body(openapi.Fields{"audioUrl": ""}).
+		Returns(http.StatusOK, statusOnly)
+	control.Route("POST", "/api/sessions/{id}/start-timer", "Open answers for a number of seconds; the server closes them at the deadline").
+		Body(openapi.Fields{"questionId": 0, "durationSeconds": 0}).
+		Returns(http.StatusOK, phaseStatus)
+	control.Route("POST", "/api/sessions/{id}/close-answers", "Close answers (also succeeds if the timer already closed them)").
+		Body(openapi.Fields{"questionId": 0}).
+		Returns(http.StatusOK, phaseStatus)
+
+	marking := spec.Group("Marking").Auth()
+	marking.Route("GET", "/api/sessions/{id}/answers/{questionId}", "Answers to a question, with likely-correct hints").
+		Returns(http.StatusOK, openapi.Fields{"answers": []AnswerWithLikely{}, "correctAnswer": ""})
+	marking.Route("GET", "/api/sessions/{id}/photos", "Photo round answers for the marking gallery (?questionId= for one question)").
+		Returns(http.StatusOK, openapi.Fields{"photos": []PhotoAnswer{}})
+	marking.Route("GET", "/api/sessions/{id}/photos/{answerId}", "The image of a photo answer").
+		Produces(http.StatusOK, "image/*")
+	marking.Route("POST", "/api/sessions/{id}/mark", "Mark an answer").
+		Body(openapi.Fields{"answerId": 0, "isCorrect": true, "points": 0}).
+		Returns(http.StatusOK, openapi.Fields{"status": "", "points": 0, "phase": &PhaseState{}})
+	marking.Route("POST", "/api/sessions/{id}/push-scores", "Reveal scores to players and the display").
+		Body(openapi.Fields{"roundId": (*int)(nil)}).
+		Returns(http.StatusOK, openapi.Fields{"scores": []ScoreEntry{}, "phase": &PhaseState{}})
+	marking.Route("GET", "/api/sessions/{id}/adjustments", "Score adjustments").
+		Returns(http.StatusOK, openapi.Fields{"adjustments": []ScoreAdjustment{}})
+	marking.Route("PUT", "/api/sessions/{id}/adjustments/{adjustmentId}", "Change a score adjustment").
+		Body(openapi.Fields{"points": 0}).
+		Returns(http.StatusOK, ScoreAdjustment{})
+
+	tiebreaks := spec.Group("Tie-breaks").Auth()
+	tiebreaks.Route("GET", "/api/sessions/{id}/tiebreaks", "Tie-breaks played, current ties and scores").
+		Returns(http.StatusOK, openapi.Fields{"tiebreaks": []Tiebreak{}, "ties": [][]ScoreEntry{}, "scores": []ScoreEntry{}})
+	tiebreaks.Route("POST", "/api/sessions/{id}/tiebreaks", "Start a tie-break (the highest-placed tie if entityIds is empty)").
+		Body(openapi.Fields{"kind": "", "entityIds": []int{}}).
+		Returns(http.StatusOK, Tiebreak{})
+	tiebreaks.Route("POST", "/api/sessions/{id}/tiebreaks/{tiebreakId}/close", "Close tie-break answers").
+		Returns(http.StatusOK, statusOnly)
+	tiebreaks.Route("POST", "/api/sessions/{id}/tiebreaks/{tiebreakId}/mark", "Mark a tie-break answer").
+		Body(openapi.Fields{"answerId": 0, "isCorrect": true}).
+		Returns(http.StatusOK, statusOnly)
+	tiebreaks.Route("POST", "/api/sessions/{id}/tiebreaks/{tiebreakId}/resolve", "Settle a tie-break").
+		Returns(http.StatusOK, openapi.Fields{"tiebreak": Tiebreak{}, "scores": []ScoreEntry{}, "ties": [][]ScoreEntry{}})
+
+	moderation := spec.Group("Moderation").Auth()
+	moderation.Route("GET", "/api/sessions/{id}/flagged", "Team names and answers caught by the content filter").
+		Returns(http.StatusOK, openapi.Fields{"items": []FlaggedItem{}})
+	moderation.Route("POST", "/api/sessions/{id}/moderate", "Approve or reject a flagged item").
+		Body(openapi.Fields{"kind": "", "id": 0, "action": "approve | reject"}).
+		Returns(http.StatusOK, openapi.Fields{"kind": "", "id": 0, "moderation": ""})
+	moderation.Route("GET", "/api/filter", "A venue's filter words and action").
+		Query("venue", "Venue ID (defaults to the caller's venue)").
+		Returns(http.StatusOK, openapi.Fields{"venueId": 0, "action": "", "words": []FilterWord{}})
+	moderation.Route("POST", "/api/filter/words", "Add a filter word").
+		Body(openapi.Fields{"venueId": 0, "word": ""}).
+		Returns(http.StatusOK, FilterWord{})
+	moderation.Route("DELETE", "/api/filter/words/{id}", "Remove a filter word").
+		Returns(http.StatusOK, statusOnly)
+	moderation.Route("PUT", "/api/filter/settings", "Set what happens to flagged content at a venue").
+		Body(openapi.Fields{"venueId": 0, "action": ""}).
+		Returns(http.StatusOK, openapi.Fields{"venueId": 0, "action": ""})
+
+	return spec
+}
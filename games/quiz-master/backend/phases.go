@@ -0,0 +1,185 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Question lifecycle, persisted per session in session_phase:
+// idle → loaded → revealed → answers_open → closed → marked → scores_pushed
+const (
+	phaseIdle         = "idle"
+	phaseLoaded       = "loaded"
+	phaseRevealed     = "revealed"
+	phaseAnswersOpen  = "answers_open"
+	phaseClosed       = "closed"
+	phaseMarked       = "marked"
+	phaseScoresPushed = "scores_pushed"
+)
+
+// phaseTransitions lists the phases each phase may move to. Loading the next
+// question is allowed once answers are closed, so marking can wait until the
+// end of a round.
+var phaseTransitions = map[string][]string{
+	phaseIdle:         {phaseLoaded, phaseScoresPushed},
+	phaseLoaded:       {phaseLoaded, phaseRevealed},
+	phaseRevealed:     {phaseAnswersOpen},
+	phaseAnswersOpen:  {phaseClosed},
+	phaseClosed:       {phaseMarked, phaseScoresPushed, phaseLoaded},
+	phaseMarked:       {phaseScoresPushed, phaseLoaded},
+	phaseScoresPushed: {phaseScoresPushed, phaseLoaded},
+}
+
+// PhaseState is a session's current phase, broadcast as phase_changed.
+// Clients count down against Deadline using ServerTime to correct for clock skew.
+type PhaseState struct {
+	Phase          string     `json:"phase"`
+	RoundID        int        `json:"roundId,omitempty"`
+	QuestionID     int        `json:"questionId,omitempty"`
+	RoundNumber    int        `json:"roundNumber,omitempty"`
+	QuestionNumber int        `json:"questionNumber,omitempty"`
+	ChangedAt      time.Time  `json:"changedAt"`
+	Deadline       *time.Time `json:"deadline,omitempty"` // answers_open with a time limit
+	ServerTime     time.Time  `json:"serverTime"`
+}
+
+// phaseError is a rejected transition (reported as 409 Conflict)
+type phaseError struct {
+	msg string
+}
+
+func (e *phaseError) Error() string { return e.msg }
+
+func canTransition(from, to string) bool {
+	for _, allowed := range phaseTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// getSessionPhase returns the session's phase (idle if the quiz hasn't loaded a question yet)
+func getSessionPhase(sessionID int) (*PhaseState, error) {
+	return scanPhase(quizDB.QueryRow(`
+		SELECT phase, COALESCE(round_id,0), COALESCE(question_id,0), round_number, question_number, changed_at, deadline
+		FROM session_phase WHERE session_id = $1`, sessionID))
+}
+
+func scanPhase(row *sql.Row) (*PhaseState, error) {
+	var p PhaseState
+	var deadline sql.NullTime
+	err := row.Scan(&p.Phase, &p.RoundID, &p.QuestionID, &p.RoundNumber, &p.QuestionNumber, &p.ChangedAt, &deadline)
+	if err == sql.ErrNoRows {
+		return &PhaseState{Phase: phaseIdle, ServerTime: time.Now().UTC()}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if deadline.Valid {
+		p.Deadline = &deadline.Time
+	}
+	p.ServerTime = time.Now().UTC()
+	return &p, nil
+}
+
+// advancePhase moves an active session to phase `to` if allowed from its current
+// phase, then broadcasts phase_changed. questionID (if non-zero) must be the
+// current question unless a new question is being loaded; update fills in the
+// new state's question fields and deadline.
+func advancePhase(sessionID int, to string, questionID int, update func(*PhaseState)) (*PhaseState, error) {
+	tx, err := quizDB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var status string
+	err = tx.QueryRow(`SELECT status FROM sessions WHERE id = $1 FOR UPDATE`, sessionID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return nil, &phaseError{"session not found"}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if status != "active" {
+		return nil, &phaseError{fmt.Sprintf("session is %s", status)}
+	}
+
+	// Sessions started before phases were tracked begin idle
+	if _, err := tx.Exec(`
+		INSERT INTO session_phase (session_id, phase, changed_at) VALUES ($1, $2, $3)
+		ON CONFLICT (session_id) DO NOTHING`, sessionID, phaseIdle, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+
+	current, err := scanPhase(tx.QueryRow(`
+		SELECT phase, COALESCE(round_id,0), COALESCE(question_id,0), round_number, question_number, changed_at, deadline
+		FROM session_phase WHERE session_id = $1`, sessionID))
+	if err != nil {
+		return nil, err
+	}
+
+	if !canTransition(current.Phase, to) {
+		return nil, &phaseError{fmt.Sprintf("cannot move from %s to %s", current.Phase, to)}
+	}
+	if to != phaseLoaded && questionID != 0 && questionID != current.QuestionID {
+		return nil, &phaseError{fmt.Sprintf("question %d is not the current question", questionID)}
+	}
+
+	next := *current
+	next.Phase = to
+	next.ChangedAt = time.Now().UTC()
+	next.Deadline = nil
+	if update != nil {
+		update(&next)
+	}
+
+	_, err = tx.Exec(`
+		UPDATE session_phase
+		SET phase = $2, round_id = NULLIF($3,0), question_id = NULLIF($4,0),
+		    round_number = $5, question_number = $6, changed_at = $7, deadline = $8
+		WHERE session_id = $1`,
+		sessionID, next.Phase, next.RoundID, next.QuestionID,
+		next.RoundNumber, next.QuestionNumber, next.ChangedAt, next.Deadline,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	invalidateDisplay(sessionID)
+
+	next.ServerTime = time.Now().UTC()
+	_ = publishEvent(evPhaseChanged.New(sessionKey(sessionID), next))
+	return &next, nil
+}
+
+// resetPhase puts a session back to idle when it starts
+func resetPhase(sessionID int) error {
+	now := time.Now().UTC()
+	_, err := quizDB.Exec(`
+		INSERT INTO session_phase (session_id, phase, changed_at) VALUES ($1, $2, $3)
+		ON CONFLICT (session_id) DO UPDATE
+		SET phase = $2, round_id = NULL, question_id = NULL, round_number = 0,
+		    question_number = 0, changed_at = $3, deadline = NULL`,
+		sessionID, phaseIdle, now)
+	if err != nil {
+		return err
+	}
+	invalidateDisplay(sessionID)
+	_ = publishEvent(evPhaseChanged.New(sessionKey(sessionID), PhaseState{Phase: phaseIdle, ChangedAt: now, ServerTime: now}))
+	return nil
+}
+
+// writePhaseError reports a failed transition
+func writePhaseError(w http.ResponseWriter, err error) {
+	if pe, ok := err.(*phaseError); ok {
+		http.Error(w, fmt.Sprintf(`{"error":%q}`, pe.msg), http.StatusConflict)
+		return
+	}
+	http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/achgithub/activity-hub-common/config"
+	"github.com/achgithub/activity-hub-common/upload"
+	"github.com/gorilla/mux"
+)
+
+// Photo round answers are written by quiz-player under ANSWER_MEDIA_DIR; this
+// must be the same directory (see docs/DEPLOYMENT.md). Unlike pack media they
+// are players' own pictures, so they're only served to the session's staff.
+var answerMediaDir = config.GetEnv("ANSWER_MEDIA_DIR", "./answer-media")
+
+// PhotoAnswer is a photo in the marking gallery
+type PhotoAnswer struct {
+	AnswerID    int       `json:"answerId"`
+	QuestionID  int       `json:"questionId"`
+	PlayerName  string    `json:"playerName"`
+	TeamName    string    `json:"teamName"`
+	IsCorrect   *bool     `json:"isCorrect"`
+	Points      int       `json:"points"`
+	Revision    int       `json:"revision"`
+	SubmittedAt time.Time `json:"submittedAt"`
+	URL         string    `json:"url"`
+}
+
+// handleGetPhotos - GET /api/sessions/{id}/photos?questionId=2
+// The session's photo answers, by question then submission time. Without
+// questionId it covers the whole session.
+func handleGetPhotos(w http.ResponseWriter, r *http.Request) {
+	sessionID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, `{"error":"invalid id"}`, http.StatusBadRequest)
+		return
+	}
+	questionID := 0
+	if q := r.URL.Query().Get("questionId"); q != "" {
+		if questionID, err = strconv.Atoi(q); err != nil {
+			http.Error(w, `{"error":"invalid questionId"}`, http.StatusBadRequest)
+			return
+		}
+	}
+
+	rows, err := quizDB.Query(`
+		SELECT a.id, a.question_id, COALESCE(sp.user_name,''), COALESCE(t.name,''),
+		       a.is_correct, a.points, a.revision, a.submitted_at
+		FROM answers a
+		JOIN session_players sp ON sp.id = a.player_id
+		LEFT JOIN teams t ON t.id = a.team_id
+		WHERE a.session_id = $1 AND a.photo_path IS NOT NULL AND ($2 = 0 OR a.question_id = $2)
+		ORDER BY a.question_id, a.submitted_at`, sessionID, questionID)
+	if err != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	photos := []PhotoAnswer{}
+	for rows.Next() {
+		var p PhotoAnswer
+		var isCorrect sql.NullBool
+		if err := rows.Scan(&p.AnswerID, &p.QuestionID, &p.PlayerName, &p.TeamName,
+			&isCorrect, &p.Points, &p.Revision, &p.SubmittedAt); err != nil {
+			continue
+		}
+		if isCorrect.Valid {
+			p.IsCorrect = &isCorrect.Bool
+		}
+		p.URL = "/api/sessions/" + strconv.Itoa(sessionID) + "/photos/" + strconv.Itoa(p.AnswerID)
+		photos = append(photos, p)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"photos": photos})
+}
+
+// handleGetPhoto - GET /api/sessions/{id}/photos/{answerId}
+// The image of a photo answer. The frontend fetches it with the bearer token.
+func handleGetPhoto(w http.ResponseWriter, r *http.Request) {
+	sessionID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, `{"error":"invalid id"}`, http.StatusBadRequest)
+		return
+	}
+	answerID, err := strconv.Atoi(mux.Vars(r)["answerId"])
+	if err != nil {
+		http.Error(w, `{"error":"invalid answerId"}`, http.StatusBadRequest)
+		return
+	}
+
+	var relPath string
+	err = quizDB.QueryRow(`SELECT photo_path FROM answers WHERE id = $1 AND session_id = $2 AND photo_path IS NOT NULL`,
+		answerID, sessionID).Scan(&relPath)
+	if err == sql.ErrNoRows {
+		http.Error(w, `{"error":"photo not found"}`, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	// quiz-player writes {sessionId}/{file}; anything else isn't one of ours
+	clean := filepath.Clean(relPath)
+	if !strings.HasPrefix(clean, strconv.Itoa(sessionID)+string(filepath.Separator)) {
+		http.Error(w, `{"error":"photo not found"}`, http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "private, max-age=3600")
+	upload.ServeFile(w, r, filepath.Join(answerMediaDir, clean))
+}
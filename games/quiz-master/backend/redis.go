@@ -2,10 +2,13 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
+	"strconv"
+	"strings"
 
+	"github.com/achgithub/activity-hub-common/activity"
+	"github.com/achgithub/activity-hub-common/events"
 	"github.com/go-redis/redis/v8"
 )
 
@@ -29,28 +32,15 @@ func lobbyChannel(sessionID int) string {
 	return fmt.Sprintf("quiz:session:%d:lobby", sessionID)
 }
 
-func publishEvent(sessionID int, eventType string, payload interface{}) error {
-	ctx := context.Background()
-	data, err := json.Marshal(map[string]interface{}{
-		"type":    eventType,
-		"payload": payload,
-	})
-	if err != nil {
-		return err
-	}
-	return redisClient.Publish(ctx, sessionChannel(sessionID), string(data)).Err()
+// publishEvent publishes to the session's players, display and quiz master
+func publishEvent(event events.Envelope) error {
+	sessionID, _ := strconv.Atoi(event.Session)
+	return redisClient.Publish(context.Background(), sessionChannel(sessionID), event.Encode()).Err()
 }
 
-func publishLobbyEvent(sessionID int, eventType string, payload interface{}) error {
-	ctx := context.Background()
-	data, err := json.Marshal(map[string]interface{}{
-		"type":    eventType,
-		"payload": payload,
-	})
-	if err != nil {
-		return err
-	}
-	return redisClient.Publish(ctx, lobbyChannel(sessionID), string(data)).Err()
+func publishLobbyEvent(event events.Envelope) error {
+	sessionID, _ := strconv.Atoi(event.Session)
+	return redisClient.Publish(context.Background(), lobbyChannel(sessionID), event.Encode()).Err()
 }
 
 func subscribeToLobby(sessionID int) (*redis.PubSub, <-chan *redis.Message) {
@@ -59,3 +49,45 @@ func subscribeToLobby(sessionID int) (*redis.PubSub, <-chan *redis.Message) {
 	ch := pubsub.Channel()
 	return pubsub, ch
 }
+
+// publishQuizWinners announces a finished quiz's winners (tied teams share
+// first place) on the platform activity feed. Names are the public standings
+// names, so flagged team names stay masked.
+func publishQuizWinners(sessionID int, standings []ScoreEntry) {
+	var winners []string
+	for _, s := range standings {
+		if s.Position == 1 {
+			winners = append(winners, s.Name)
+		}
+	}
+	if len(winners) == 0 {
+		return
+	}
+
+	var quizName string
+	var venueID int
+	if err := quizDB.QueryRow(`SELECT name, COALESCE(venue_id, 0) FROM sessions WHERE id = $1`, sessionID).
+		Scan(&quizName, &venueID); err != nil {
+		log.Printf("Session %d: failed to load session for activity feed: %v", sessionID, err)
+		return
+	}
+
+	text := fmt.Sprintf("%s won %s", strings.Join(winners, " & "), quizName)
+	if len(winners) > 1 {
+		text = fmt.Sprintf("%s shared first place in %s", strings.Join(winners, " & "), quizName)
+	}
+	msg, err := activity.Encode(activity.Event{
+		Type:    activity.TypeQuizWinner,
+		App:     "quiz-player",
+		Text:    text,
+		Players: winners,
+		VenueID: venueID,
+	})
+	if err != nil {
+		log.Printf("Session %d: invalid activity event: %v", sessionID, err)
+		return
+	}
+	if err := redisClient.Publish(context.Background(), activity.Channel, msg).Err(); err != nil {
+		log.Printf("Session %d: failed to publish activity event: %v", sessionID, err)
+	}
+}
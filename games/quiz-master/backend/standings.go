@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"sort"
+)
+
+// ScoreEntry is one team's (or, in individual mode, player's) place in the standings
+type ScoreEntry struct {
+	TeamID       int               `json:"teamId"`
+	Name         string            `json:"name"`
+	Total        int               `json:"total"`
+	RoundPoints  int               `json:"roundPoints"`
+	AnswerPoints int               `json:"answerPoints"`
+	Adjustments  []ScoreAdjustment `json:"adjustments,omitempty"` // e.g. late-join backfill
+	Position     int               `json:"position"`              // Shared by teams still tied after tie-breaks
+}
+
+// getStandings totals marked answers plus adjustments per team (or player in
+// individual mode) and orders them, splitting equal totals by resolved tie-breaks
+func getStandings(sessionID int) ([]ScoreEntry, error) {
+	rows, err := quizDB.Query(`
+		SELECT COALESCE(t.id, sp.id) as entity_id,
+		       COALESCE(`+publicTeamNameSQL+`, sp.user_name, sp.user_email) as entity_name,
+		       COALESCE(SUM(a.points), 0) as total_points
+		FROM session_players sp
+		LEFT JOIN teams t ON t.id = sp.team_id
+		LEFT JOIN answers a ON a.player_id = sp.id AND a.session_id = sp.session_id AND a.is_correct IS NOT NULL
+		WHERE sp.session_id = $1
+		GROUP BY COALESCE(t.id, sp.id), entity_name
+		ORDER BY total_points DESC`, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Score breakdown: marked answers plus adjustments
+	adjustments, err := getSessionAdjustments(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	byEntity := map[int][]ScoreAdjustment{}
+	for _, a := range adjustments {
+		byEntity[a.EntityID] = append(byEntity[a.EntityID], a)
+	}
+
+	scores := []ScoreEntry{}
+	for rows.Next() {
+		var s ScoreEntry
+		if err := rows.Scan(&s.TeamID, &s.Name, &s.AnswerPoints); err != nil {
+			continue
+		}
+		s.Total = s.AnswerPoints
+		for _, a := range byEntity[s.TeamID] {
+			s.Total += a.Points
+		}
+		s.Adjustments = byEntity[s.TeamID]
+		scores = append(scores, s)
+	}
+
+	results, err := getTiebreakRanks(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Equal totals are split by the latest resolved tie-break both took part in
+	ahead := func(a, b ScoreEntry) bool {
+		if a.Total != b.Total {
+			return a.Total > b.Total
+		}
+		for i := len(results) - 1; i >= 0; i-- {
+			ra, okA := results[i][a.TeamID]
+			rb, okB := results[i][b.TeamID]
+			if okA && okB {
+				return ra < rb
+			}
+		}
+		return false
+	}
+	sort.SliceStable(scores, func(i, j int) bool { return ahead(scores[i], scores[j]) })
+
+	// Standard competition ranking: 1, 2, 2, 4
+	for i := range scores {
+		if i > 0 && !ahead(scores[i-1], scores[i]) {
+			scores[i].Position = scores[i-1].Position
+		} else {
+			scores[i].Position = i + 1
+		}
+	}
+	return scores, nil
+}
+
+// recordScoreReveal records standings shown to the room (nil roundID =
+// overall), so quiz-display's scores screen can show them when it (re)connects
+func recordScoreReveal(sessionID int, roundID interface{}, scores []ScoreEntry) {
+	standings, _ := json.Marshal(scores)
+	_, err := quizDB.Exec(`INSERT INTO score_reveals (session_id, round_id, standings) VALUES ($1, $2, $3)`,
+		sessionID, roundID, standings)
+	if err != nil {
+		log.Printf("Session %d: failed to record score reveal: %v", sessionID, err)
+	}
+	invalidateDisplay(sessionID)
+}
+
+// tiedGroups returns the sets of entries sharing a position, best first
+func tiedGroups(scores []ScoreEntry) [][]ScoreEntry {
+	groups := [][]ScoreEntry{}
+	for i := 0; i < len(scores); {
+		j := i + 1
+		for j < len(scores) && scores[j].Position == scores[i].Position {
+			j++
+		}
+		if j-i > 1 {
+			groups = append(groups, scores[i:j])
+		}
+		i = j
+	}
+	return groups
+}
@@ -0,0 +1,345 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/gorilla/mux"
+	"github.com/lib/pq"
+)
+
+// SessionSettings are per-session quiz rules, copied from a template (sessions.settings).
+// Zero values mean the defaults: pack round order, round time limits, 1 point per
+// correct answer, no penalty, no team size limit, late joiners starting on 0
+// and no accessibility options switched on.
+type SessionSettings struct {
+	RoundOrder              []int                `json:"roundOrder,omitempty"`              // Round IDs to play, in order
+	DefaultTimeLimitSeconds int                  `json:"defaultTimeLimitSeconds,omitempty"` // For rounds without their own limit
+	PointsPerCorrect        int                  `json:"pointsPerCorrect,omitempty"`
+	PointsPerWrong          int                  `json:"pointsPerWrong,omitempty"` // 0 or negative
+	MaxTeamSize             int                  `json:"maxTeamSize,omitempty"`
+	LateJoinPolicy          string               `json:"lateJoinPolicy,omitempty"` // zero | average | admin (applied by quiz-player)
+	LateJoinPoints          int                  `json:"lateJoinPoints,omitempty"` // Starting points under the admin policy
+	Accessibility           AccessibilityOptions `json:"accessibility"`
+}
+
+// SessionTemplate is a saved session setup, e.g. the weekly quiz
+type SessionTemplate struct {
+	ID        int             `json:"id"`
+	Name      string          `json:"name"`
+	PackID    int             `json:"packId"`
+	PackName  string          `json:"packName"`
+	Mode      string          `json:"mode"`
+	TeamNames []string        `json:"teamNames"`
+	Settings  SessionSettings `json:"settings"`
+	CreatedBy string          `json:"createdBy"`
+	CreatedAt time.Time       `json:"createdAt"`
+	UpdatedAt time.Time       `json:"updatedAt"`
+}
+
+func (s SessionSettings) pointsFor(correct bool) int {
+	if !correct {
+		return s.PointsPerWrong
+	}
+	if s.PointsPerCorrect > 0 {
+		return s.PointsPerCorrect
+	}
+	return 1
+}
+
+// getSessionSettings loads a session's settings (defaults for sessions created without a template)
+func getSessionSettings(sessionID int) (SessionSettings, error) {
+	var settings SessionSettings
+	var raw []byte
+	err := quizDB.QueryRow(`SELECT settings FROM sessions WHERE id = $1`, sessionID).Scan(&raw)
+	if err != nil {
+		return settings, err
+	}
+	err = json.Unmarshal(raw, &settings)
+	return settings, err
+}
+
+// validateTemplate checks a template's fields against its pack
+func validateTemplate(t *SessionTemplate) error {
+	t.Name = strings.TrimSpace(t.Name)
+	if t.Name == "" || t.PackID == 0 {
+		return fmt.Errorf("name and packId required")
+	}
+	if t.Mode == "" {
+		t.Mode = "team"
+	}
+	if t.Mode != "team" && t.Mode != "individual" {
+		return fmt.Errorf("mode must be team or individual")
+	}
+
+	names := []string{}
+	for _, n := range t.TeamNames {
+		if n = strings.TrimSpace(n); n != "" {
+			names = append(names, n)
+		}
+	}
+	t.TeamNames = names
+	if t.Mode == "individual" {
+		t.TeamNames = []string{}
+	}
+
+	s := t.Settings
+	if s.DefaultTimeLimitSeconds < 0 || s.PointsPerCorrect < 0 || s.PointsPerWrong > 0 || s.MaxTeamSize < 0 {
+		return fmt.Errorf("time limit, points per correct and team size can't be negative; points per wrong can't be positive")
+	}
+	switch s.LateJoinPolicy {
+	case "", "zero", "average", "admin":
+	default:
+		return fmt.Errorf("lateJoinPolicy must be zero, average or admin")
+	}
+	if s.LateJoinPoints < 0 {
+		return fmt.Errorf("lateJoinPoints can't be negative")
+	}
+
+	rows, err := quizDB.Query(`SELECT id FROM rounds WHERE pack_id = $1`, t.PackID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	inPack := map[int]bool{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err == nil {
+			inPack[id] = true
+		}
+	}
+	if len(inPack) == 0 {
+		return fmt.Errorf("pack not found or has no rounds")
+	}
+
+	seen := map[int]bool{}
+	for _, id := range s.RoundOrder {
+		if !inPack[id] {
+			return fmt.Errorf("round %d is not in this pack", id)
+		}
+		if seen[id] {
+			return fmt.Errorf("round %d is listed twice", id)
+		}
+		seen[id] = true
+	}
+	return nil
+}
+
+func scanTemplate(scan func(dest ...interface{}) error) (SessionTemplate, error) {
+	var t SessionTemplate
+	var raw []byte
+	err := scan(&t.ID, &t.Name, &t.PackID, &t.PackName, &t.Mode, pq.Array(&t.TeamNames), &raw,
+		&t.CreatedBy, &t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		return t, err
+	}
+	if t.TeamNames == nil {
+		t.TeamNames = []string{}
+	}
+	err = json.Unmarshal(raw, &t.Settings)
+	return t, err
+}
+
+const templateColumns = `
+	t.id, t.name, t.pack_id, COALESCE(p.name,''), t.mode, t.team_names, t.settings,
+	COALESCE(t.created_by,''), t.created_at, t.updated_at
+	FROM session_templates t
+	LEFT JOIN quiz_packs p ON p.id = t.pack_id`
+
+func getTemplate(id int) (SessionTemplate, error) {
+	return scanTemplate(quizDB.QueryRow(`SELECT `+templateColumns+` WHERE t.id = $1`, id).Scan)
+}
+
+// handleGetTemplates - GET /api/templates
+func handleGetTemplates(w http.ResponseWriter, r *http.Request) {
+	rows, err := quizDB.Query(`SELECT ` + templateColumns + ` ORDER BY t.name`)
+	if err != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	templates := []SessionTemplate{}
+	for rows.Next() {
+		t, err := scanTemplate(rows.Scan)
+		if err != nil {
+			continue
+		}
+		templates = append(templates, t)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"templates": templates})
+}
+
+// handleGetTemplate - GET /api/templates/{id}
+func handleGetTemplate(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, `{"error":"invalid id"}`, http.StatusBadRequest)
+		return
+	}
+
+	t, err := getTemplate(id)
+	if err == sql.ErrNoRows {
+		http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(t)
+}
+
+// handleCreateTemplate - POST /api/templates
+func handleCreateTemplate(w http.ResponseWriter, r *http.Request) {
+	user, _ := authlib.GetUserFromContext(r.Context())
+
+	var t SessionTemplate
+	if err := json.NewDecoder(r.Body).Decode(&t); err != nil {
+		http.Error(w, `{"error":"invalid JSON"}`, http.StatusBadRequest)
+		return
+	}
+	if err := validateTemplate(&t); err != nil {
+		http.Error(w, fmt.Sprintf(`{"error":%q}`, err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	settings, _ := json.Marshal(t.Settings)
+	err := quizDB.QueryRow(`
+		INSERT INTO session_templates (name, pack_id, mode, team_names, settings, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`,
+		t.Name, t.PackID, t.Mode, pq.Array(t.TeamNames), settings, user.ActorEmail(),
+	).Scan(&t.ID)
+	if err != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{"id": t.ID})
+}
+
+// handleUpdateTemplate - PUT /api/templates/{id}
+func handleUpdateTemplate(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, `{"error":"invalid id"}`, http.StatusBadRequest)
+		return
+	}
+
+	var t SessionTemplate
+	if err := json.NewDecoder(r.Body).Decode(&t); err != nil {
+		http.Error(w, `{"error":"invalid JSON"}`, http.StatusBadRequest)
+		return
+	}
+	if err := validateTemplate(&t); err != nil {
+		http.Error(w, fmt.Sprintf(`{"error":%q}`, err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	settings, _ := json.Marshal(t.Settings)
+	res, err := quizDB.Exec(`
+		UPDATE session_templates
+		SET name=$1, pack_id=$2, mode=$3, team_names=$4, settings=$5, updated_at=NOW()
+		WHERE id=$6`,
+		t.Name, t.PackID, t.Mode, pq.Array(t.TeamNames), settings, id,
+	)
+	if err != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "updated"})
+}
+
+// handleDeleteTemplate - DELETE /api/templates/{id}
+// Sessions already created from the template keep their settings.
+func handleDeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, `{"error":"invalid id"}`, http.StatusBadRequest)
+		return
+	}
+
+	res, err := quizDB.Exec(`DELETE FROM session_templates WHERE id = $1`, id)
+	if err != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "deleted"})
+}
+
+// handleCreateSessionFromTemplate - POST /api/templates/{id}/sessions {"name": "..."}
+// Creates a session (and its teams) from a template in one call.
+// The name defaults to the template name plus today's date.
+func handleCreateSessionFromTemplate(w http.ResponseWriter, r *http.Request) {
+	user, _ := authlib.GetUserFromContext(r.Context())
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, `{"error":"invalid id"}`, http.StatusBadRequest)
+		return
+	}
+
+	var body struct {
+		Name        string `json:"name"`
+		ScheduledAt string `json:"scheduledAt"`
+	}
+	json.NewDecoder(r.Body).Decode(&body)
+	scheduledAt, err := parseScheduledAt(body.ScheduledAt)
+	if err != nil {
+		http.Error(w, `{"error":"invalid scheduledAt"}`, http.StatusBadRequest)
+		return
+	}
+
+	t, err := getTemplate(id)
+	if err == sql.ErrNoRows {
+		http.Error(w, `{"error":"template not found"}`, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	name := strings.TrimSpace(body.Name)
+	if name == "" {
+		name = fmt.Sprintf("%s – %s", t.Name, time.Now().Format("2 Jan 2006"))
+	}
+
+	sessionID, joinCode, err := createSession(user.Email, user.ActorEmail(), user.VenueID, t.PackID, name, t.Mode, &t.ID, t.Settings, t.TeamNames, scheduledAt)
+	if err != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"sessionId":  sessionID,
+		"joinCode":   joinCode,
+		"mode":       t.Mode,
+		"templateId": t.ID,
+	})
+}
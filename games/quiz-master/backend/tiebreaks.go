@@ -0,0 +1,549 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/lib/pq"
+)
+
+// Tie-break kinds. Questions are drawn from the pool marked in game-admin
+// (questions.tiebreak) and only the tied teams can answer.
+const (
+	tiebreakNearest     = "nearest"      // Closest numeric guess wins, ranked automatically
+	tiebreakSuddenDeath = "sudden_death" // Marked right/wrong; right answers finish ahead
+)
+
+// Tiebreak is a one-question mini-round between tied teams: open → closed → resolved
+type Tiebreak struct {
+	ID         int              `json:"id"`
+	Kind       string           `json:"kind"`
+	QuestionID int              `json:"questionId"`
+	Question   string           `json:"question"`
+	Answer     string           `json:"answer"`
+	EntityIDs  []int            `json:"entityIds"` // Teams (players in individual mode), as in the standings
+	Status     string           `json:"status"`
+	Answers    []TiebreakAnswer `json:"answers"`
+	CreatedAt  time.Time        `json:"createdAt"`
+}
+
+type TiebreakAnswer struct {
+	ID         int    `json:"id"`
+	EntityID   int    `json:"entityId"`
+	Name       string `json:"name"`
+	AnswerText string `json:"answerText"`
+	IsCorrect  *bool  `json:"isCorrect"`
+	Rank       *int   `json:"rank"` // 1 = best; set when resolved
+
+	FlaggedWords []string `json:"flaggedWords,omitempty"`
+	MaskedText   string   `json:"maskedText,omitempty"`
+	Moderation   string   `json:"moderation,omitempty"` // flagged | approved | rejected
+}
+
+// publicTiebreakAnswers strips content filter details for the big screen,
+// showing flagged answers masked and rejected ones not at all
+func publicTiebreakAnswers(answers []TiebreakAnswer) []TiebreakAnswer {
+	public := make([]TiebreakAnswer, len(answers))
+	for i, a := range answers {
+		switch a.Moderation {
+		case "flagged":
+			a.AnswerText = a.MaskedText
+		case "rejected":
+			a.AnswerText = ""
+		}
+		a.FlaggedWords, a.MaskedText, a.Moderation = nil, "", ""
+		public[i] = a
+	}
+	return public
+}
+
+// getTiebreakRanks returns each resolved tie-break's ranks by entity, oldest first
+func getTiebreakRanks(sessionID int) ([]map[int]int, error) {
+	rows, err := quizDB.Query(`
+		SELECT tb.id, ta.entity_id, ta.rank
+		FROM tiebreaks tb
+		JOIN tiebreak_answers ta ON ta.tiebreak_id = tb.id
+		WHERE tb.session_id = $1 AND tb.status = 'resolved' AND ta.rank IS NOT NULL
+		ORDER BY tb.id`, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := []map[int]int{}
+	lastID := 0
+	for rows.Next() {
+		var id, entityID, rank int
+		if err := rows.Scan(&id, &entityID, &rank); err != nil {
+			continue
+		}
+		if id != lastID {
+			results = append(results, map[int]int{})
+			lastID = id
+		}
+		results[len(results)-1][entityID] = rank
+	}
+	return results, nil
+}
+
+// getTiebreak loads a session's tie-break with its answers, named from names (entity ID → name)
+func getTiebreak(sessionID, tiebreakID int, names map[int]string) (*Tiebreak, error) {
+	var tb Tiebreak
+	var entityIDs pq.Int64Array
+	err := quizDB.QueryRow(`
+		SELECT tb.id, tb.kind, tb.question_id, q.text, q.answer, tb.entity_ids, tb.status, tb.created_at
+		FROM tiebreaks tb
+		JOIN questions q ON q.id = tb.question_id
+		WHERE tb.id = $1 AND tb.session_id = $2`, tiebreakID, sessionID,
+	).Scan(&tb.ID, &tb.Kind, &tb.QuestionID, &tb.Question, &tb.Answer, &entityIDs, &tb.Status, &tb.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range entityIDs {
+		tb.EntityIDs = append(tb.EntityIDs, int(id))
+	}
+
+	rows, err := quizDB.Query(`
+		SELECT id, entity_id, COALESCE(answer_text,''), is_correct, rank,
+		       flagged_words, COALESCE(masked_text,''), COALESCE(moderation,'')
+		FROM tiebreak_answers WHERE tiebreak_id = $1
+		ORDER BY rank NULLS LAST, submitted_at`, tiebreakID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tb.Answers = []TiebreakAnswer{}
+	for rows.Next() {
+		var a TiebreakAnswer
+		var isCorrect sql.NullBool
+		var rank sql.NullInt64
+		if err := rows.Scan(&a.ID, &a.EntityID, &a.AnswerText, &isCorrect, &rank,
+			pq.Array(&a.FlaggedWords), &a.MaskedText, &a.Moderation); err != nil {
+			continue
+		}
+		if isCorrect.Valid {
+			a.IsCorrect = &isCorrect.Bool
+		}
+		if rank.Valid {
+			v := int(rank.Int64)
+			a.Rank = &v
+		}
+		a.Name = names[a.EntityID]
+		tb.Answers = append(tb.Answers, a)
+	}
+	return &tb, nil
+}
+
+func standingNames(scores []ScoreEntry) map[int]string {
+	names := map[int]string{}
+	for _, s := range scores {
+		names[s.TeamID] = s.Name
+	}
+	return names
+}
+
+func tiebreakIDFromRequest(w http.ResponseWriter, r *http.Request) (int, int, bool) {
+	sessionID, _ := strconv.Atoi(mux.Vars(r)["id"])
+	tiebreakID, err := strconv.Atoi(mux.Vars(r)["tiebreakId"])
+	if err != nil {
+		http.Error(w, `{"error":"invalid tiebreak id"}`, http.StatusBadRequest)
+		return 0, 0, false
+	}
+	return sessionID, tiebreakID, true
+}
+
+// handleGetTiebreaks - GET /api/sessions/{id}/tiebreaks
+// Tie-breaks played so far plus the groups still tied in the standings
+func handleGetTiebreaks(w http.ResponseWriter, r *http.Request) {
+	sessionID, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	scores, err := getStandings(sessionID)
+	if err != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+	names := standingNames(scores)
+
+	rows, err := quizDB.Query(`SELECT id FROM tiebreaks WHERE session_id = $1 ORDER BY id`, sessionID)
+	if err != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+	ids := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	rows.Close()
+
+	tiebreaks := []Tiebreak{}
+	for _, id := range ids {
+		tb, err := getTiebreak(sessionID, id, names)
+		if err != nil {
+			continue
+		}
+		tiebreaks = append(tiebreaks, *tb)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"tiebreaks": tiebreaks,
+		"ties":      tiedGroups(scores),
+		"scores":    scores,
+	})
+}
+
+// handleStartTiebreak - POST /api/sessions/{id}/tiebreaks {"kind": "nearest", "entityIds": [3, 7]}
+// Draws an unused question from the tie-break pool for the tied teams.
+// Without entityIds the highest-placed tie is broken.
+func handleStartTiebreak(w http.ResponseWriter, r *http.Request) {
+	sessionID, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	var body struct {
+		Kind      string `json:"kind"`
+		EntityIDs []int  `json:"entityIds"`
+	}
+	json.NewDecoder(r.Body).Decode(&body)
+	if body.Kind == "" {
+		body.Kind = tiebreakNearest
+	}
+	if body.Kind != tiebreakNearest && body.Kind != tiebreakSuddenDeath {
+		http.Error(w, `{"error":"kind must be nearest or sudden_death"}`, http.StatusBadRequest)
+		return
+	}
+
+	var status string
+	var pending bool
+	err := quizDB.QueryRow(`
+		SELECT status, EXISTS (SELECT 1 FROM tiebreaks WHERE session_id = $1 AND status <> 'resolved')
+		FROM sessions WHERE id = $1`, sessionID).Scan(&status, &pending)
+	if err != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+	if status != "active" {
+		http.Error(w, `{"error":"tie-breaks can only be played in an active session"}`, http.StatusConflict)
+		return
+	}
+	if pending {
+		http.Error(w, `{"error":"resolve the current tie-break first"}`, http.StatusConflict)
+		return
+	}
+
+	phase, err := getSessionPhase(sessionID)
+	if err != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+	switch phase.Phase {
+	case phaseLoaded, phaseRevealed, phaseAnswersOpen:
+		http.Error(w, `{"error":"finish the current question first"}`, http.StatusConflict)
+		return
+	}
+
+	scores, err := getStandings(sessionID)
+	if err != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	// Only teams that are actually tied with each other can take part
+	positions := map[int]int{}
+	for _, s := range scores {
+		positions[s.TeamID] = s.Position
+	}
+	if len(body.EntityIDs) == 0 {
+		ties := tiedGroups(scores)
+		if len(ties) == 0 {
+			http.Error(w, `{"error":"no ties to break"}`, http.StatusConflict)
+			return
+		}
+		for _, s := range ties[0] {
+			body.EntityIDs = append(body.EntityIDs, s.TeamID)
+		}
+	}
+	if len(body.EntityIDs) < 2 {
+		http.Error(w, `{"error":"a tie-break needs at least two teams"}`, http.StatusBadRequest)
+		return
+	}
+	for _, id := range body.EntityIDs {
+		pos, ok := positions[id]
+		if !ok {
+			http.Error(w, fmt.Sprintf(`{"error":"team %d is not in this session"}`, id), http.StatusBadRequest)
+			return
+		}
+		if pos != positions[body.EntityIDs[0]] {
+			http.Error(w, `{"error":"those teams are not tied"}`, http.StatusBadRequest)
+			return
+		}
+	}
+
+	var questionID int
+	err = quizDB.QueryRow(`
+		SELECT id FROM questions
+		WHERE tiebreak = $1
+		  AND id NOT IN (SELECT question_id FROM tiebreaks WHERE session_id = $2)
+		ORDER BY random() LIMIT 1`, body.Kind, sessionID).Scan(&questionID)
+	if err == sql.ErrNoRows {
+		http.Error(w, `{"error":"no unused tie-break questions of that kind"}`, http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	entityIDs := make(pq.Int64Array, len(body.EntityIDs))
+	for i, id := range body.EntityIDs {
+		entityIDs[i] = int64(id)
+	}
+	var tiebreakID int
+	err = quizDB.QueryRow(`
+		INSERT INTO tiebreaks (session_id, question_id, kind, entity_ids)
+		VALUES ($1, $2, $3, $4) RETURNING id`,
+		sessionID, questionID, body.Kind, entityIDs,
+	).Scan(&tiebreakID)
+	if err != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	tb, err := getTiebreak(sessionID, tiebreakID, standingNames(scores))
+	if err != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Session %d: tie-break %d (%s) between %v", sessionID, tiebreakID, body.Kind, body.EntityIDs)
+
+	// The answer stays with the quiz master until the tie-break is resolved
+	_ = publishEvent(evTiebreakStarted.New(sessionKey(sessionID), TiebreakStarted{
+		TiebreakID: tb.ID,
+		Kind:       tb.Kind,
+		Question:   tb.Question,
+		EntityIDs:  tb.EntityIDs,
+	}))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(tb)
+}
+
+// handleCloseTiebreak - POST /api/sessions/{id}/tiebreaks/{tiebreakId}/close
+func handleCloseTiebreak(w http.ResponseWriter, r *http.Request) {
+	sessionID, tiebreakID, ok := tiebreakIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	res, err := quizDB.Exec(`
+		UPDATE tiebreaks SET status = 'closed', closed_at = NOW()
+		WHERE id = $1 AND session_id = $2 AND status = 'open'`, tiebreakID, sessionID)
+	if err != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		http.Error(w, `{"error":"tie-break is not open"}`, http.StatusConflict)
+		return
+	}
+
+	_ = publishEvent(evTiebreakClosed.New(sessionKey(sessionID), TiebreakRef{TiebreakID: tiebreakID}))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "closed"})
+}
+
+// handleMarkTiebreakAnswer - POST /api/sessions/{id}/tiebreaks/{tiebreakId}/mark {"answerId": 1, "isCorrect": true}
+// Sudden-death tie-breaks only; nearest-number answers are ranked automatically.
+func handleMarkTiebreakAnswer(w http.ResponseWriter, r *http.Request) {
+	sessionID, tiebreakID, ok := tiebreakIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		AnswerID  int  `json:"answerId"`
+		IsCorrect bool `json:"isCorrect"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.AnswerID == 0 {
+		http.Error(w, `{"error":"answerId required"}`, http.StatusBadRequest)
+		return
+	}
+
+	res, err := quizDB.Exec(`
+		UPDATE tiebreak_answers SET is_correct = $1
+		WHERE id = $2 AND tiebreak_id = (
+			SELECT id FROM tiebreaks
+			WHERE id = $3 AND session_id = $4 AND kind = $5 AND status = 'closed')`,
+		body.IsCorrect, body.AnswerID, tiebreakID, sessionID, tiebreakSuddenDeath)
+	if err != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		http.Error(w, `{"error":"answer not found, or the tie-break isn't a closed sudden-death"}`, http.StatusConflict)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "marked"})
+}
+
+// handleResolveTiebreak - POST /api/sessions/{id}/tiebreaks/{tiebreakId}/resolve
+// Ranks the tied teams and re-orders the standings. Teams that didn't answer
+// finish behind those that did; teams ranked equal stay tied for another tie-break.
+func handleResolveTiebreak(w http.ResponseWriter, r *http.Request) {
+	sessionID, tiebreakID, ok := tiebreakIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	tb, err := getTiebreak(sessionID, tiebreakID, nil)
+	if err == sql.ErrNoRows {
+		http.Error(w, `{"error":"tie-break not found"}`, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+	if tb.Status != "closed" {
+		http.Error(w, `{"error":"close the tie-break first"}`, http.StatusConflict)
+		return
+	}
+
+	// Lower score is better: distance from the answer, or 0/1 for right/wrong
+	score := map[int]float64{}
+	answered := map[int]bool{}
+	target, _ := strconv.ParseFloat(strings.TrimSpace(tb.Answer), 64)
+	for _, a := range tb.Answers {
+		answered[a.EntityID] = true
+		switch tb.Kind {
+		case tiebreakNearest:
+			guess, err := strconv.ParseFloat(strings.TrimSpace(a.AnswerText), 64)
+			if err != nil {
+				score[a.EntityID] = math.Inf(1)
+			} else {
+				score[a.EntityID] = math.Abs(guess - target)
+			}
+		case tiebreakSuddenDeath:
+			if a.IsCorrect == nil {
+				http.Error(w, `{"error":"mark every answer first"}`, http.StatusConflict)
+				return
+			}
+			if !*a.IsCorrect {
+				score[a.EntityID] = 1
+			}
+		}
+	}
+	for _, id := range tb.EntityIDs {
+		if !answered[id] {
+			score[id] = math.Inf(1)
+		}
+	}
+
+	order := append([]int{}, tb.EntityIDs...)
+	sort.SliceStable(order, func(i, j int) bool { return score[order[i]] < score[order[j]] })
+	ranks := map[int]int{}
+	for i, id := range order {
+		if i > 0 && score[id] == score[order[i-1]] {
+			ranks[id] = ranks[order[i-1]]
+		} else {
+			ranks[id] = i + 1
+		}
+	}
+
+	tx, err := quizDB.Begin()
+	if err != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	for _, id := range order {
+		_, err := tx.Exec(`
+			INSERT INTO tiebreak_answers (tiebreak_id, entity_id, rank)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (tiebreak_id, entity_id) DO UPDATE SET rank = EXCLUDED.rank`,
+			tiebreakID, id, ranks[id])
+		if err != nil {
+			http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+			return
+		}
+	}
+	if _, err := tx.Exec(`UPDATE tiebreaks SET status = 'resolved', resolved_at = NOW() WHERE id = $1`, tiebreakID); err != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	scores, err := getStandings(sessionID)
+	if err != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+	tb, err = getTiebreak(sessionID, tiebreakID, standingNames(scores))
+	if err != nil {
+		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	recordScoreReveal(sessionID, nil, scores)
+	_ = publishEvent(evTiebreakResolved.New(sessionKey(sessionID), TiebreakResolved{
+		TiebreakID: tb.ID,
+		Answer:     tb.Answer,
+		Results:    publicTiebreakAnswers(tb.Answers),
+		Scores:     scores,
+	}))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"tiebreak": tb,
+		"scores":   scores,
+		"ties":     tiedGroups(scores),
+	})
+}
+
+// saveFinalPositions records the final standings when a session ends
+func saveFinalPositions(sessionID int) ([]ScoreEntry, error) {
+	scores, err := getStandings(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := quizDB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM session_results WHERE session_id = $1`, sessionID); err != nil {
+		return nil, err
+	}
+	for _, s := range scores {
+		_, err := tx.Exec(`
+			INSERT INTO session_results (session_id, entity_id, name, total, position)
+			VALUES ($1, $2, $3, $4, $5)`,
+			sessionID, s.TeamID, s.Name, s.Total, s.Position)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return scores, tx.Commit()
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"log"
+	"math"
+	"time"
+)
+
+// Question timers are run by the server, not the quiz master's browser.
+// start-timer stores the deadline in session_phase; the timer loop broadcasts
+// the time left every second and closes answers once the deadline (plus
+// quiz-player's grace period for answers in flight) has passed, so a timed
+// question closes on time even if the host's tablet has gone to sleep.
+
+const (
+	timerTickInterval = time.Second
+
+	// answerGracePeriod matches quiz-player, which accepts answers this long
+	// after the deadline to allow for network delay
+	answerGracePeriod = 2 * time.Second
+)
+
+// TimerTick is the time left on the open question, sent every second while a
+// timed question is open. Screens resync their countdown to it.
+type TimerTick struct {
+	QuestionID int       `json:"questionId"`
+	Remaining  int       `json:"remaining"` // Whole seconds, rounded up; 0 once time is up
+	Deadline   time.Time `json:"deadline"`
+	ServerTime time.Time `json:"serverTime"`
+}
+
+// runQuestionTimers ticks every open timed question until the process exits
+func runQuestionTimers() {
+	ticker := time.NewTicker(timerTickInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		tickQuestionTimers(time.Now().UTC())
+	}
+}
+
+// tickQuestionTimers broadcasts the time left on every open timed question and
+// closes those whose time (and grace period) has run out
+func tickQuestionTimers(now time.Time) {
+	rows, err := quizDB.Query(`
+		SELECT sp.session_id, sp.question_id, sp.deadline
+		FROM session_phase sp
+		JOIN sessions s ON s.id = sp.session_id AND s.status = 'active'
+		WHERE sp.phase = $1 AND sp.deadline IS NOT NULL`, phaseAnswersOpen)
+	if err != nil {
+		log.Printf("Failed to load question timers: %v", err)
+		return
+	}
+
+	type timer struct {
+		sessionID, questionID int
+		deadline              time.Time
+	}
+	var timers []timer
+	for rows.Next() {
+		var t timer
+		if err := rows.Scan(&t.sessionID, &t.questionID, &t.deadline); err != nil {
+			log.Printf("Failed to scan question timer: %v", err)
+			continue
+		}
+		timers = append(timers, t)
+	}
+	rows.Close()
+
+	for _, t := range timers {
+		if now.After(t.deadline.Add(answerGracePeriod)) {
+			if _, err := closeAnswers(t.sessionID, t.questionID); err != nil {
+				// The host closing answers at the same moment is not a failure
+				if _, ok := err.(*phaseError); !ok {
+					log.Printf("Session %d: failed to close answers at the deadline: %v", t.sessionID, err)
+				}
+				continue
+			}
+			log.Printf("⏱️ Session %d: answers closed for question %d at the deadline", t.sessionID, t.questionID)
+			continue
+		}
+
+		remaining := int(math.Ceil(t.deadline.Sub(now).Seconds()))
+		if remaining < 0 {
+			remaining = 0
+		}
+		_ = publishEvent(evTimerTick.New(sessionKey(t.sessionID), TimerTick{
+			QuestionID: t.questionID,
+			Remaining:  remaining,
+			Deadline:   t.deadline,
+			ServerTime: now,
+		}))
+	}
+}
+
+// closeAnswers closes the current question and tells the screens
+func closeAnswers(sessionID, questionID int) (*PhaseState, error) {
+	phase, err := advancePhase(sessionID, phaseClosed, questionID, nil)
+	if err != nil {
+		return nil, err
+	}
+	_ = publishEvent(evAnswersClosed.New(sessionKey(sessionID), QuestionRef{QuestionID: phase.QuestionID}))
+	return phase, nil
+}
@@ -41,6 +41,11 @@ interface Team {
   id: number;
   name: string;
   joinCode: string;
+  createdBy?: string;
+  flaggedWords?: string[];
+  maskedName?: string;
+  moderation?: Moderation;
+  captainPlayerId: number | null; // Whose device answers for the team
 }
 
 interface AnswerEntry {
@@ -54,6 +59,10 @@ interface AnswerEntry {
   isCorrect: boolean | null;
   points: number;
   isLikelyCorrect: boolean;
+  flaggedWords?: string[];
+  moderation?: Moderation;
+  hasPhoto: boolean;      // Photo round answer
+  photoPurged?: boolean;  // Deleted after the retention period
 }
 
 interface SessionInfo {
@@ -63,16 +72,123 @@ interface SessionInfo {
   status: string;
   joinCode: string;
   packId: number;
+  settings?: SessionSettings;
 }
 
+// Session defaults for the big screen and players' phones; players can add their own
+interface AccessibilityOptions {
+  largeText: boolean;
+  highContrast: boolean;
+  readAloud: boolean;
+  simplifiedText: boolean;
+}
+
+interface SessionSettings {
+  roundOrder?: number[];
+  defaultTimeLimitSeconds?: number;
+  pointsPerCorrect?: number;
+  pointsPerWrong?: number;
+  maxTeamSize?: number;
+  lateJoinPolicy?: 'zero' | 'average' | 'admin';
+  lateJoinPoints?: number;
+  accessibility?: AccessibilityOptions;
+}
+
+// Points given outside marking, e.g. a late joiner's starting score
+interface ScoreAdjustment {
+  id: number;
+  entityId: number;
+  name: string;
+  reason: string;
+  policy: string;
+  points: number;
+  updatedBy?: string;
+}
+
+interface ScoreEntry {
+  teamId: number;
+  name: string;
+  total: number;
+  roundPoints: number;
+  answerPoints: number;
+  adjustments?: ScoreAdjustment[];
+  position: number; // Shared while teams are still tied
+}
+
+// Tie-break mini-round between tied teams: open → closed → resolved
+interface Tiebreak {
+  id: number;
+  kind: 'nearest' | 'sudden_death';
+  question: string;
+  answer: string;
+  entityIds: number[];
+  status: 'open' | 'closed' | 'resolved';
+  answers: Array<{ id: number; entityId: number; name: string; answerText: string; isCorrect: boolean | null; rank: number | null }>;
+}
+
+// Content filter hit (word list per venue); masked on the big screen until approved
+interface FlaggedItem {
+  kind: 'team' | 'answer' | 'tiebreak_answer';
+  id: number;
+  text: string;
+  maskedText: string;
+  flaggedWords: string[];
+  moderation: Moderation;
+  submittedBy: string;
+  question?: string;
+}
+
+interface FilterWord {
+  id: number;
+  venueId: number;
+  word: string;
+}
+
+interface SessionTemplate {
+  id: number;
+  name: string;
+  packId: number;
+  packName: string;
+  mode: string;
+  teamNames: string[];
+  settings: SessionSettings;
+}
+
+interface CoHost {
+  email: string;
+  name: string;
+  role: SessionRole;
+  invitedBy: string;
+}
+
+// host runs the quiz; a scorekeeper co-host can only mark and push scores
+type SessionRole = 'host' | 'scorekeeper';
+
+type Moderation = 'flagged' | 'approved' | 'rejected';
+
 type View = 'setup' | 'lobby' | 'control' | 'marking' | 'scores';
 
 // --- Hooks ---
 
+// Single-use stream token from the shell, so the session token never goes in
+// the EventSource URL. Empty on failure: the stream then rejects the connection
+// and the normal reconnect path retries.
+async function fetchStreamToken(token: string): Promise<string> {
+  try {
+    const res = await fetch(`http://${window.location.hostname}:3001/api/auth/stream-token`, {
+      method: 'POST',
+      headers: { 'Authorization': `Bearer ${token}` },
+    });
+    return res.ok ? (await res.json()).token : '';
+  } catch {
+    return '';
+  }
+}
+
 function useUrlParams() {
   return useMemo(() => {
     const params = new URLSearchParams(window.location.search);
-    return { userId: params.get('userId') || '', token: params.get('token') || '' };
+    return { userId: params.get('userId') || '', token: params.get('token') || sessionStorage.getItem('token') || '' };
   }, []);
 }
 
@@ -97,6 +213,38 @@ function useApi(token: string) {
   );
 }
 
+// Photo answers are only served with the bearer token, so an <img> can't
+// load them directly; fetch the image and show it from a blob URL.
+function AnswerPhoto({ sessionId, answerId, token }: { sessionId: number; answerId: number; token: string }) {
+  const [src, setSrc] = useState<string | null>(null);
+  const [failed, setFailed] = useState(false);
+
+  useEffect(() => {
+    let url: string | null = null;
+    let cancelled = false;
+    fetch(`/api/sessions/${sessionId}/photos/${answerId}`, { headers: { Authorization: `Bearer ${token}` } })
+      .then(res => (res.ok ? res.blob() : Promise.reject()))
+      .then(blob => {
+        if (cancelled) return;
+        url = URL.createObjectURL(blob);
+        setSrc(url);
+      })
+      .catch(() => { if (!cancelled) setFailed(true); });
+    return () => {
+      cancelled = true;
+      if (url) URL.revokeObjectURL(url);
+    };
+  }, [sessionId, answerId, token]);
+
+  if (failed) return <p style={{ color: '#999', fontStyle: 'italic' }}>Photo unavailable</p>;
+  if (!src) return <p style={{ color: '#999' }}>Loading photo...</p>;
+  return (
+    <a href={src} target="_blank" rel="noreferrer">
+      <img src={src} alt="Photo answer" style={{ maxWidth: '100%', maxHeight: 320, borderRadius: 8, margin: '6px 0' }} />
+    </a>
+  );
+}
+
 // --- Main App ---
 
 function App() {
@@ -109,6 +257,8 @@ function App() {
   const [rounds, setRounds] = useState<Round[]>([]);
   const [players, setPlayers] = useState<Player[]>([]);
   const [teams, setTeams] = useState<Team[]>([]);
+  const [myRole, setMyRole] = useState<SessionRole>('host');
+  const [phase, setPhase] = useState<string>('idle');
   const [error, setError] = useState<string | null>(null);
   const [success, setSuccess] = useState<string | null>(null);
 
@@ -117,6 +267,17 @@ function App() {
   const [newPackId, setNewPackId] = useState('');
   const [newMode, setNewMode] = useState('team');
   const [newTeamNames, setNewTeamNames] = useState('Team A, Team B');
+  const [newScheduledAt, setNewScheduledAt] = useState(''); // Puts the quiz night in players' email digests
+
+  // Templates
+  const [templates, setTemplates] = useState<SessionTemplate[]>([]);
+  const [showRules, setShowRules] = useState(false);
+  const [tplTimeLimit, setTplTimeLimit] = useState('');
+  const [tplPointsCorrect, setTplPointsCorrect] = useState('1');
+  const [tplPointsWrong, setTplPointsWrong] = useState('0');
+  const [tplMaxTeamSize, setTplMaxTeamSize] = useState('');
+  const [tplLateJoinPolicy, setTplLateJoinPolicy] = useState('zero');
+  const [tplLateJoinPoints, setTplLateJoinPoints] = useState('0');
 
   // Quiz control state
   const [currentRoundIdx, setCurrentRoundIdx] = useState(0);
@@ -129,20 +290,54 @@ function App() {
   const [markingAnswers, setMarkingAnswers] = useState<AnswerEntry[]>([]);
   const [correctAnswer, setCorrectAnswer] = useState('');
 
+  // Co-hosts
+  const [cohosts, setCohosts] = useState<CoHost[]>([]);
+  const [cohostEmail, setCohostEmail] = useState('');
+  const [cohosting, setCohosting] = useState<SessionInfo[]>([]);
+
   // Scores
-  const [scores, setScores] = useState<Array<{teamId: number; name: string; total: number; roundPoints: number}>>([]);
+  const [scores, setScores] = useState<ScoreEntry[]>([]);
+  const [adjustmentEdits, setAdjustmentEdits] = useState<Record<number, string>>({});
+  const [tiebreaks, setTiebreaks] = useState<Tiebreak[]>([]);
+  const [ties, setTies] = useState<ScoreEntry[][]>([]);
+  const [tiebreakKind, setTiebreakKind] = useState<'nearest' | 'sudden_death'>('nearest');
+
+  // Content filter
+  const [flagged, setFlagged] = useState<FlaggedItem[]>([]);
+  const [filterVenueId, setFilterVenueId] = useState(0);
+  const [filterWords, setFilterWords] = useState<FilterWord[]>([]);
+  const [filterAction, setFilterAction] = useState<'flag' | 'block'>('flag');
+  const [newFilterWord, setNewFilterWord] = useState('');
 
   const lobbySSE = useRef<EventSource | null>(null);
 
+  const loadTemplates = useCallback(() => {
+    api('/api/templates').then(d => setTemplates(d.templates || [])).catch(() => {});
+  }, [api]);
+
+  const loadFilter = useCallback(() => {
+    api('/api/filter').then(d => {
+      setFilterVenueId(d.venueId);
+      setFilterWords(d.words || []);
+      setFilterAction(d.action);
+    }).catch(() => {});
+  }, [api]);
+
   useEffect(() => {
     if (token) {
       api('/api/packs').then(d => setPacks(d.packs || [])).catch(() => {});
+      api('/api/cohosting').then(d => setCohosting(d.sessions || [])).catch(() => {});
+      loadTemplates();
+      loadFilter();
     }
-  }, [api, token]);
+  }, [api, token, loadTemplates, loadFilter]);
+
+  const isHost = myRole === 'host';
 
-  const connectLobbySSE = useCallback((sid: number) => {
+  const connectLobbySSE = useCallback(async (sid: number) => {
     if (lobbySSE.current) lobbySSE.current.close();
-    const es = new EventSource(`/api/sessions/${sid}/lobby-stream?token=${encodeURIComponent(token)}`);
+    const streamToken = await fetchStreamToken(token);
+    const es = new EventSource(`/api/sessions/${sid}/lobby-stream?token=${encodeURIComponent(streamToken)}`);
     lobbySSE.current = es;
     es.onmessage = (e) => {
       try {
@@ -157,26 +352,40 @@ function App() {
 
   useEffect(() => () => { lobbySSE.current?.close(); }, []);
 
+  // Loads a session into the control panel and resumes at the right view
+  const openSession = async (sessionId: number, fallbackRole: SessionRole) => {
+    const detail = await api(`/api/sessions/${sessionId}`);
+    setSession(detail.session);
+    setRounds(detail.rounds || []);
+    setPlayers(detail.players || []);
+    setTeams(detail.teams || []);
+    setMyRole(detail.myRole || fallbackRole);
+    setPhase(detail.phase?.phase || 'idle');
+    setCohosts([]);
+    setCurrentRoundIdx(0);
+    setCurrentQuestionIdx(0);
+    if (detail.session.status === 'lobby') {
+      connectLobbySSE(sessionId);
+      setView('lobby');
+    } else {
+      setView('control');
+    }
+  };
+
+  const teamNameList = () => newTeamNames.split(',').map((t: string) => t.trim()).filter(Boolean);
+
   const createSession = async () => {
     if (!newSessionName.trim() || !newPackId) return;
     setError(null);
     try {
       const data = await api('/api/sessions', {
         method: 'POST',
-        body: JSON.stringify({ name: newSessionName.trim(), packId: parseInt(newPackId), mode: newMode }),
+        body: JSON.stringify({ name: newSessionName.trim(), packId: parseInt(newPackId), mode: newMode, scheduledAt: newScheduledAt }),
       });
 
-      // Fetch full session details
-      const detail = await api(`/api/sessions/${data.sessionId}`);
-      setSession(detail.session);
-      setRounds(detail.rounds || []);
-      setPlayers(detail.players || []);
-      setTeams(detail.teams || []);
-
       // Create teams if specified
-      if (newMode === 'team' && newTeamNames.trim()) {
-        const teamList = newTeamNames.split(',').map((t: string) => t.trim()).filter(Boolean);
-        for (const teamName of teamList) {
+      if (newMode === 'team') {
+        for (const teamName of teamNameList()) {
           try {
             await api(`/api/sessions/${data.sessionId}/teams`, {
               method: 'POST',
@@ -186,13 +395,106 @@ function App() {
         }
       }
 
-      connectLobbySSE(data.sessionId);
-      setView('lobby');
+      await openSession(data.sessionId, 'host');
     } catch (err) {
       setError(err instanceof Error ? err.message : 'Failed to create session');
     }
   };
 
+  const startFromTemplate = async (template: SessionTemplate) => {
+    setError(null);
+    try {
+      const data = await api(`/api/templates/${template.id}/sessions`, { method: 'POST', body: '{}' });
+      await openSession(data.sessionId, 'host');
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to start from template');
+    }
+  };
+
+  // Saves the setup form (plus timer/scoring/team size rules) as a reusable template
+  const saveTemplate = async () => {
+    if (!newSessionName.trim() || !newPackId) return;
+    setError(null);
+    try {
+      await api('/api/templates', {
+        method: 'POST',
+        body: JSON.stringify({
+          name: newSessionName.trim(),
+          packId: parseInt(newPackId),
+          mode: newMode,
+          teamNames: newMode === 'team' ? teamNameList() : [],
+          settings: {
+            defaultTimeLimitSeconds: parseInt(tplTimeLimit) || 0,
+            pointsPerCorrect: parseInt(tplPointsCorrect) || 0,
+            pointsPerWrong: -Math.abs(parseInt(tplPointsWrong) || 0),
+            maxTeamSize: parseInt(tplMaxTeamSize) || 0,
+            lateJoinPolicy: tplLateJoinPolicy,
+            lateJoinPoints: tplLateJoinPolicy === 'admin' ? parseInt(tplLateJoinPoints) || 0 : 0,
+          },
+        }),
+      });
+      setSuccess('Template saved');
+      loadTemplates();
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to save template');
+    }
+  };
+
+  const deleteTemplate = async (template: SessionTemplate) => {
+    if (!window.confirm(`Delete template "${template.name}"?`)) return;
+    try {
+      await api(`/api/templates/${template.id}`, { method: 'DELETE' });
+      setTemplates(prev => prev.filter(t => t.id !== template.id));
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to delete template');
+    }
+  };
+
+  const joinAsCohost = async (sessionId: number) => {
+    setError(null);
+    try {
+      await openSession(sessionId, 'scorekeeper');
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to open session');
+    }
+  };
+
+  const loadCohosts = useCallback(async (sessionId: number) => {
+    try {
+      const data = await api(`/api/sessions/${sessionId}/cohosts`);
+      setCohosts(data.cohosts || []);
+    } catch {}
+  }, [api]);
+
+  useEffect(() => {
+    if (session && isHost) loadCohosts(session.id);
+  }, [session?.id, isHost, loadCohosts]); // eslint-disable-line react-hooks/exhaustive-deps
+
+  const inviteCohost = async () => {
+    if (!session || !cohostEmail.trim()) return;
+    setError(null);
+    try {
+      await api(`/api/sessions/${session.id}/cohosts`, {
+        method: 'POST',
+        body: JSON.stringify({ email: cohostEmail.trim() }),
+      });
+      setCohostEmail('');
+      loadCohosts(session.id);
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to invite co-host');
+    }
+  };
+
+  const removeCohost = async (email: string) => {
+    if (!session) return;
+    try {
+      await api(`/api/sessions/${session.id}/cohosts/${encodeURIComponent(email)}`, { method: 'DELETE' });
+      setCohosts(prev => prev.filter(c => c.email !== email));
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to remove co-host');
+    }
+  };
+
   const startQuiz = async () => {
     if (!session) return;
     try {
@@ -212,7 +514,7 @@ function App() {
     if (!session || !currentRound || !currentQuestion) return;
     setError(null);
     try {
-      await api(`/api/sessions/${session.id}/load-question`, {
+      const data = await api(`/api/sessions/${session.id}/load-question`, {
         method: 'POST',
         body: JSON.stringify({
           roundId: currentRound.id,
@@ -221,6 +523,7 @@ function App() {
           roundNumber: currentRound.roundNumber,
         }),
       });
+      setPhase(data.phase?.phase || 'loaded');
       setQuestionLoaded(true);
       setQuestionRevealed(false);
       setAnswersClosed(false);
@@ -237,13 +540,12 @@ function App() {
         body: JSON.stringify({ questionId: currentQuestion.id }),
       });
       setQuestionRevealed(true);
-      // Auto-start timer if round has time limit
-      if (currentRound?.timeLimitSeconds) {
-        await api(`/api/sessions/${session.id}/start-timer`, {
-          method: 'POST',
-          body: JSON.stringify({ questionId: currentQuestion.id, durationSeconds: currentRound.timeLimitSeconds }),
-        });
-      }
+      // Open answers straight away (with the round's time limit, if any)
+      const data = await api(`/api/sessions/${session.id}/start-timer`, {
+        method: 'POST',
+        body: JSON.stringify({ questionId: currentQuestion.id, durationSeconds: currentRound?.timeLimitSeconds || 0 }),
+      });
+      setPhase(data.phase?.phase || 'answers_open');
     } catch (err) {
       setError(err instanceof Error ? err.message : 'Failed');
     }
@@ -264,10 +566,11 @@ function App() {
   const closeAnswers = async () => {
     if (!session || !currentQuestion) return;
     try {
-      await api(`/api/sessions/${session.id}/close-answers`, {
+      const data = await api(`/api/sessions/${session.id}/close-answers`, {
         method: 'POST',
         body: JSON.stringify({ questionId: currentQuestion.id }),
       });
+      setPhase(data.phase?.phase || 'closed');
       setAnswersClosed(true);
     } catch (err) {
       setError(err instanceof Error ? err.message : 'Failed');
@@ -289,11 +592,12 @@ function App() {
   const markAnswer = async (answerId: number, isCorrect: boolean) => {
     if (!session) return;
     try {
-      await api(`/api/sessions/${session.id}/mark`, {
+      const data = await api(`/api/sessions/${session.id}/mark`, {
         method: 'POST',
-        body: JSON.stringify({ answerId, isCorrect, points: isCorrect ? 1 : 0 }),
+        body: JSON.stringify({ answerId, isCorrect }), // points come from the session's scoring rules
       });
-      setMarkingAnswers(prev => prev.map(a => a.id === answerId ? { ...a, isCorrect, points: isCorrect ? 1 : 0 } : a));
+      if (data.phase) setPhase(data.phase.phase);
+      setMarkingAnswers(prev => prev.map(a => a.id === answerId ? { ...a, isCorrect, points: data.points ?? a.points } : a));
     } catch (err) {
       setError(err instanceof Error ? err.message : 'Failed to mark');
     }
@@ -307,12 +611,84 @@ function App() {
         body: JSON.stringify({ roundId: roundId ?? null }),
       });
       setScores(data.scores || []);
+      if (data.phase) setPhase(data.phase.phase);
       setView('scores');
     } catch (err) {
       setError(err instanceof Error ? err.message : 'Failed to push scores');
     }
   };
 
+  // Host sets a late joiner's starting points; re-push scores to show the new totals
+  const updateAdjustment = async (adjustmentId: number) => {
+    if (!session) return;
+    const points = parseInt(adjustmentEdits[adjustmentId]);
+    if (isNaN(points)) return;
+    try {
+      await api(`/api/sessions/${session.id}/adjustments/${adjustmentId}`, {
+        method: 'PUT',
+        body: JSON.stringify({ points }),
+      });
+      setAdjustmentEdits(prev => {
+        const next = { ...prev };
+        delete next[adjustmentId];
+        return next;
+      });
+      setSuccess('Adjustment saved – push scores to update the leaderboard');
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to update adjustment');
+    }
+  };
+
+  const loadTiebreaks = useCallback(async (sid: number) => {
+    try {
+      const data = await api(`/api/sessions/${sid}/tiebreaks`);
+      setTiebreaks(data.tiebreaks || []);
+      setTies(data.ties || []);
+    } catch {}
+  }, [api]);
+
+  useEffect(() => {
+    if (view === 'scores' && session) loadTiebreaks(session.id);
+  }, [view, session, loadTiebreaks]);
+
+  const startTiebreak = async (group: ScoreEntry[]) => {
+    if (!session) return;
+    try {
+      await api(`/api/sessions/${session.id}/tiebreaks`, {
+        method: 'POST',
+        body: JSON.stringify({ kind: tiebreakKind, entityIds: group.map(e => e.teamId) }),
+      });
+      loadTiebreaks(session.id);
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to start tie-break');
+    }
+  };
+
+  // action: close (stop answers), resolve (rank the tied teams)
+  const tiebreakAction = async (tiebreakId: number, action: 'close' | 'resolve') => {
+    if (!session) return;
+    try {
+      const data = await api(`/api/sessions/${session.id}/tiebreaks/${tiebreakId}/${action}`, { method: 'POST' });
+      if (data.scores) setScores(data.scores);
+      loadTiebreaks(session.id);
+    } catch (err) {
+      setError(err instanceof Error ? err.message : `Failed to ${action} tie-break`);
+    }
+  };
+
+  const markTiebreakAnswer = async (tiebreakId: number, answerId: number, isCorrect: boolean) => {
+    if (!session) return;
+    try {
+      await api(`/api/sessions/${session.id}/tiebreaks/${tiebreakId}/mark`, {
+        method: 'POST',
+        body: JSON.stringify({ answerId, isCorrect }),
+      });
+      loadTiebreaks(session.id);
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to mark');
+    }
+  };
+
   const nextQuestion = () => {
     if (!currentRound) return;
     setQuestionLoaded(false);
@@ -330,13 +706,215 @@ function App() {
   const endQuiz = async () => {
     if (!session || !window.confirm('End the quiz?')) return;
     try {
-      await api(`/api/sessions/${session.id}/end`, { method: 'POST' });
+      const data = await api(`/api/sessions/${session.id}/end`, { method: 'POST' });
+      if (data.standings) {
+        setScores(data.standings);
+        setView('scores');
+      }
       setSuccess('Quiz ended');
     } catch (err) {
       setError(err instanceof Error ? err.message : 'Failed');
     }
   };
 
+  const loadFlagged = useCallback(async (sid: number) => {
+    try {
+      const data = await api(`/api/sessions/${sid}/flagged`);
+      setFlagged(data.items || []);
+    } catch {}
+  }, [api]);
+
+  useEffect(() => {
+    if (session && (view === 'lobby' || view === 'scores')) loadFlagged(session.id);
+  }, [view, session, loadFlagged]);
+
+  // Quiz-master override for filtered text: approve shows it as typed, reject hides it
+  const moderate = async (kind: FlaggedItem['kind'], id: number, action: 'approve' | 'reject') => {
+    if (!session) return;
+    try {
+      const data = await api(`/api/sessions/${session.id}/moderate`, {
+        method: 'POST',
+        body: JSON.stringify({ kind, id, action }),
+      });
+      const moderation: Moderation = data.moderation;
+      setFlagged(prev => prev.map(f => f.kind === kind && f.id === id ? { ...f, moderation } : f));
+      if (kind === 'team') setTeams(prev => prev.map(t => t.id === id ? { ...t, moderation } : t));
+      if (kind === 'answer') setMarkingAnswers(prev => prev.map(a => a.id === id ? { ...a, moderation } : a));
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to moderate');
+    }
+  };
+
+  const addFilterWord = async () => {
+    if (!newFilterWord.trim()) return;
+    try {
+      await api('/api/filter/words', {
+        method: 'POST',
+        body: JSON.stringify({ venueId: filterVenueId, word: newFilterWord.trim() }),
+      });
+      setNewFilterWord('');
+      loadFilter();
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to add word');
+    }
+  };
+
+  const deleteFilterWord = async (id: number) => {
+    try {
+      await api(`/api/filter/words/${id}`, { method: 'DELETE' });
+      loadFilter();
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to remove word');
+    }
+  };
+
+  const updateFilterAction = async (action: 'flag' | 'block') => {
+    try {
+      await api('/api/filter/settings', {
+        method: 'PUT',
+        body: JSON.stringify({ venueId: filterVenueId, action }),
+      });
+      setFilterAction(action);
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to update filter');
+    }
+  };
+
+  const moderationControls = (kind: FlaggedItem['kind'], id: number, words: string[] | undefined, moderation: Moderation | undefined) => {
+    if (!moderation) return null;
+    return (
+      <span style={{ display: 'inline-flex', gap: 6, alignItems: 'center', marginLeft: 8 }}>
+        <span style={moderation === 'flagged' ? s.flagBadge : s.teamBadge} title={(words || []).join(', ')}>
+          {moderation === 'flagged' ? `flagged: ${(words || []).join(', ')}` : moderation}
+        </span>
+        {moderation !== 'approved' && (
+          <button style={{ ...s.btnOutline, padding: '2px 8px' }} onClick={() => moderate(kind, id, 'approve')}>Approve</button>
+        )}
+        {moderation !== 'rejected' && (
+          <button style={{ ...s.btnOutline, padding: '2px 8px', color: '#C62828', borderColor: '#C62828' }} onClick={() => moderate(kind, id, 'reject')}>Reject</button>
+        )}
+      </span>
+    );
+  };
+
+  // Each team answers from its captain's phone; hosts reassign the captain when
+  // that phone dies or they leave
+  const setCaptain = async (teamId: number, playerId: number) => {
+    if (!session) return;
+    setError(null);
+    try {
+      await api(`/api/sessions/${session.id}/teams/${teamId}/captain`, {
+        method: 'POST',
+        body: JSON.stringify({ playerId }),
+      });
+      setTeams(prev => prev.map(t => (t.id === teamId ? { ...t, captainPlayerId: playerId } : t)));
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to change captain');
+    }
+  };
+
+  const refreshTeams = async () => {
+    if (!session) return;
+    try {
+      const detail = await api(`/api/sessions/${session.id}`);
+      setPlayers(detail.players || []);
+      setTeams(detail.teams || []);
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to load teams');
+    }
+  };
+
+  const captainsCard = isHost && session?.mode === 'team' && teams.length > 0 && (
+    <div style={s.card}>
+      <div style={{ display: 'flex', justifyContent: 'space-between', alignItems: 'center' }}>
+        <h3 style={s.cardTitle}>Team Captains</h3>
+        <button style={{ ...s.btnOutline, padding: '4px 10px' }} onClick={refreshTeams}>Refresh</button>
+      </div>
+      <p style={{ ...s.muted, marginBottom: 8 }}>Only the captain's phone can answer. The first to answer becomes captain.</p>
+      {teams.map(t => {
+        const members = players.filter(p => p.teamId === t.id);
+        return (
+          <div key={t.id} style={s.playerRow}>
+            <span style={{ flex: 1 }}>{t.name}</span>
+            <select
+              style={{ ...s.input, flex: 'none', width: 'auto' }}
+              value={t.captainPlayerId ?? ''}
+              disabled={members.length === 0}
+              onChange={e => setCaptain(t.id, Number(e.target.value))}
+            >
+              <option value="" disabled>{members.length === 0 ? 'No players' : 'No captain yet'}</option>
+              {members.map(p => (
+                <option key={p.id} value={p.id}>{p.userName || p.userEmail}</option>
+              ))}
+            </select>
+          </div>
+        );
+      })}
+    </div>
+  );
+
+  // Applied straight away: the display restyles and phones pick up the new defaults
+  const setAccessibility = async (change: Partial<AccessibilityOptions>) => {
+    if (!session) return;
+    const current: AccessibilityOptions = {
+      largeText: false, highContrast: false, readAloud: false, simplifiedText: false,
+      ...session.settings?.accessibility,
+    };
+    setError(null);
+    try {
+      const data = await api(`/api/sessions/${session.id}/accessibility`, {
+        method: 'PUT',
+        body: JSON.stringify({ ...current, ...change }),
+      });
+      setSession(prev => prev && { ...prev, settings: { ...prev.settings, accessibility: data.accessibility } });
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to update accessibility');
+    }
+  };
+
+  const accessibilityOptions: { key: keyof AccessibilityOptions; label: string }[] = [
+    { key: 'largeText', label: 'Large text' },
+    { key: 'highContrast', label: 'High contrast' },
+    { key: 'readAloud', label: 'Read questions aloud on phones' },
+    { key: 'simplifiedText', label: 'Simplified wording where available' },
+  ];
+
+  const accessibilityCard = isHost && session && (
+    <div style={s.card}>
+      <h3 style={s.cardTitle}>Accessibility</h3>
+      <p style={{ ...s.muted, marginBottom: 8 }}>Defaults for the big screen and everyone's phone. Players can switch on more for themselves.</p>
+      {accessibilityOptions.map(o => (
+        <label key={o.key} style={{ ...s.playerRow, cursor: 'pointer' }}>
+          <input
+            type="checkbox"
+            checked={!!session.settings?.accessibility?.[o.key]}
+            onChange={e => setAccessibility({ [o.key]: e.target.checked })}
+          />
+          <span>{o.label}</span>
+        </label>
+      ))}
+    </div>
+  );
+
+  const flaggedCard = flagged.length > 0 && (
+    <div style={s.card}>
+      <h3 style={s.cardTitle}>Flagged Content</h3>
+      <p style={{ ...s.muted, marginBottom: 8 }}>Shown masked on the big screen until you approve it. Rejected team names show as "Team &lt;number&gt;".</p>
+      {flagged.map(f => (
+        <div key={`${f.kind}-${f.id}`} style={s.playerRow}>
+          <div style={{ flex: 1 }}>
+            <strong>{f.text}</strong>
+            <span style={s.muted}> · {f.kind === 'team' ? 'team name' : f.kind === 'answer' ? 'answer' : 'tie-break answer'} by {f.submittedBy || 'host'}</span>
+            {f.question && <div style={s.muted}>{f.question}</div>}
+          </div>
+          {moderationControls(f.kind, f.id, f.flaggedWords, f.moderation)}
+        </div>
+      ))}
+    </div>
+  );
+
+  const activeTiebreak = tiebreaks.find(t => t.status !== 'resolved') || null;
+
   if (!userId || !token) {
     return (
       <div style={s.container}>
@@ -354,6 +932,7 @@ function App() {
           <div style={{ display: 'flex', gap: 8, alignItems: 'center' }}>
             <span style={s.joinCodeBadge}>{session.joinCode}</span>
             <span style={statusBadge(session.status)}>{session.status}</span>
+            {!isHost && <span style={s.teamBadge}>scorekeeper</span>}
           </div>
         )}
       </div>
@@ -372,6 +951,27 @@ function App() {
         </div>
       )}
 
+      {/* Templates */}
+      {view === 'setup' && templates.length > 0 && (
+        <div style={s.card}>
+          <h3 style={s.cardTitle}>Start from Template</h3>
+          {templates.map(t => (
+            <div key={t.id} style={s.playerRow}>
+              <div style={{ flex: 1 }}>
+                <div>{t.name}</div>
+                <div style={s.muted}>
+                  {t.packName} · {t.mode}
+                  {t.settings.defaultTimeLimitSeconds ? ` · ${t.settings.defaultTimeLimitSeconds}s` : ''}
+                  {t.settings.maxTeamSize ? ` · max ${t.settings.maxTeamSize}/team` : ''}
+                </div>
+              </div>
+              <button style={s.btnOutline} onClick={() => startFromTemplate(t)}>Start</button>
+              <button style={{ ...s.btnOutline, color: '#C62828', borderColor: '#C62828' }} onClick={() => deleteTemplate(t)}>Delete</button>
+            </div>
+          ))}
+        </div>
+      )}
+
       {/* Setup view */}
       {view === 'setup' && (
         <div style={s.card}>
@@ -394,12 +994,58 @@ function App() {
               <option value="individual">Individual</option>
             </select>
           </div>
+          <div style={s.field}>
+            <label style={s.label}>Quiz night starts (optional, shown in players' email digests)</label>
+            <input style={s.input} type="datetime-local" value={newScheduledAt} onChange={e => setNewScheduledAt(e.target.value)} />
+          </div>
           {newMode === 'team' && (
             <div style={s.field}>
               <label style={s.label}>Team names (comma-separated)</label>
               <input style={s.input} value={newTeamNames} onChange={e => setNewTeamNames(e.target.value)} placeholder="Team A, Team B, Team C" />
             </div>
           )}
+          <button style={{ ...s.btnOutline, marginBottom: 12 }} onClick={() => setShowRules(v => !v)}>
+            {showRules ? 'Hide template rules' : 'Save as template…'}
+          </button>
+          {showRules && (
+            <div style={{ marginBottom: 12 }}>
+              <div style={s.field}>
+                <label style={s.label}>Default time limit (seconds, for rounds without one)</label>
+                <input style={s.input} type="number" min={0} value={tplTimeLimit} onChange={e => setTplTimeLimit(e.target.value)} placeholder="none" />
+              </div>
+              <div style={s.field}>
+                <label style={s.label}>Points per correct answer</label>
+                <input style={s.input} type="number" min={1} value={tplPointsCorrect} onChange={e => setTplPointsCorrect(e.target.value)} />
+              </div>
+              <div style={s.field}>
+                <label style={s.label}>Penalty per wrong answer</label>
+                <input style={s.input} type="number" min={0} value={tplPointsWrong} onChange={e => setTplPointsWrong(e.target.value)} />
+              </div>
+              {newMode === 'team' && (
+                <div style={s.field}>
+                  <label style={s.label}>Max players per team</label>
+                  <input style={s.input} type="number" min={0} value={tplMaxTeamSize} onChange={e => setTplMaxTeamSize(e.target.value)} placeholder="no limit" />
+                </div>
+              )}
+              <div style={s.field}>
+                <label style={s.label}>Late joiners start with</label>
+                <select style={s.select} value={tplLateJoinPolicy} onChange={e => setTplLateJoinPolicy(e.target.value)}>
+                  <option value="zero">Zero</option>
+                  <option value="average">Average of the other {newMode === 'team' ? 'teams' : 'players'}</option>
+                  <option value="admin">Points I set</option>
+                </select>
+              </div>
+              {tplLateJoinPolicy === 'admin' && (
+                <div style={s.field}>
+                  <label style={s.label}>Late-join points (editable per {newMode === 'team' ? 'team' : 'player'} during the quiz)</label>
+                  <input style={s.input} type="number" min={0} value={tplLateJoinPoints} onChange={e => setTplLateJoinPoints(e.target.value)} />
+                </div>
+              )}
+              <button style={s.btnOutline} onClick={saveTemplate} disabled={!newSessionName.trim() || !newPackId}>
+                Save Template
+              </button>
+            </div>
+          )}
           <button style={s.btnPrimary} onClick={createSession} disabled={!newSessionName.trim() || !newPackId}>
             Create Session
           </button>
@@ -409,6 +1055,48 @@ function App() {
         </div>
       )}
 
+      {view === 'setup' && cohosting.length > 0 && (
+        <div style={s.card}>
+          <h3 style={s.cardTitle}>Co-hosting</h3>
+          <p style={{ ...s.muted, marginBottom: 8 }}>You've been invited to keep score for these sessions.</p>
+          {cohosting.map(cs => (
+            <div key={cs.id} style={s.playerRow}>
+              <span style={{ flex: 1 }}>{cs.name}</span>
+              <span style={statusBadge(cs.status)}>{cs.status}</span>
+              <button style={s.btnOutline} onClick={() => joinAsCohost(cs.id)}>Open</button>
+            </div>
+          ))}
+        </div>
+      )}
+
+      {view === 'setup' && (
+        <div style={s.card}>
+          <h3 style={s.cardTitle}>Content Filter {filterVenueId === 0 ? '(all venues)' : '(this venue)'}</h3>
+          <p style={{ ...s.muted, marginBottom: 8 }}>Checked against player team names and typed answers.</p>
+          <div style={s.field}>
+            <label style={s.label}>When a word matches</label>
+            <select style={s.select} value={filterAction} onChange={e => updateFilterAction(e.target.value as 'flag' | 'block')}>
+              <option value="flag">Flag it for me, masked on the big screen</option>
+              <option value="block">Refuse it</option>
+            </select>
+          </div>
+          {filterWords.map(fw => (
+            <div key={fw.id} style={s.playerRow}>
+              <span style={{ flex: 1 }}>{fw.word}</span>
+              {fw.venueId !== filterVenueId ? (
+                <span style={s.teamBadge}>all venues</span>
+              ) : (
+                <button style={{ ...s.btnOutline, padding: '4px 10px' }} onClick={() => deleteFilterWord(fw.id)}>Remove</button>
+              )}
+            </div>
+          ))}
+          <div style={{ display: 'flex', gap: 8, marginTop: 8 }}>
+            <input style={s.input} placeholder="Word or phrase" value={newFilterWord} onChange={e => setNewFilterWord(e.target.value)} />
+            <button style={s.btnOutline} onClick={addFilterWord} disabled={!newFilterWord.trim()}>Add</button>
+          </div>
+        </div>
+      )}
+
       {/* Lobby view */}
       {view === 'lobby' && session && (
         <div>
@@ -419,11 +1107,33 @@ function App() {
               <p style={{ fontSize: 32, fontWeight: 800, color: '#1565C0', letterSpacing: 4 }}>{session.joinCode}</p>
               <p style={s.muted}>Quiz Player app → Join code above</p>
             </div>
-            <button style={s.btnPrimary} onClick={startQuiz} disabled={players.length === 0}>
-              Start Quiz ({players.length} player{players.length !== 1 ? 's' : ''})
-            </button>
+            {isHost ? (
+              <button style={s.btnPrimary} onClick={startQuiz} disabled={players.length === 0}>
+                Start Quiz ({players.length} player{players.length !== 1 ? 's' : ''})
+              </button>
+            ) : (
+              <p style={{ ...s.muted, textAlign: 'center' }}>Waiting for the host to start the quiz.</p>
+            )}
           </div>
 
+          {isHost && (
+            <div style={s.card}>
+              <h3 style={s.cardTitle}>Co-hosts</h3>
+              <p style={{ ...s.muted, marginBottom: 8 }}>Scorekeepers can mark answers and push scores, but can't run questions or end the quiz.</p>
+              {cohosts.filter(c => c.role === 'scorekeeper').map(c => (
+                <div key={c.email} style={s.playerRow}>
+                  <span style={{ flex: 1 }}>{c.name || c.email}</span>
+                  <span style={s.teamBadge}>{c.role}</span>
+                  <button style={{ ...s.btnOutline, padding: '4px 10px' }} onClick={() => removeCohost(c.email)}>Remove</button>
+                </div>
+              ))}
+              <div style={{ display: 'flex', gap: 8, marginTop: 8 }}>
+                <input style={s.input} placeholder="Co-host email" value={cohostEmail} onChange={e => setCohostEmail(e.target.value)} />
+                <button style={s.btnOutline} onClick={inviteCohost} disabled={!cohostEmail.trim()}>Invite</button>
+              </div>
+            </div>
+          )}
+
           {teams.length > 0 && (
             <div style={s.card}>
               <h3 style={s.cardTitle}>Teams</h3>
@@ -431,11 +1141,19 @@ function App() {
                 <div key={t.id} style={s.teamRow}>
                   <strong>{t.name}</strong>
                   <span style={s.muted}> · Code: <strong>{t.joinCode}</strong></span>
+                  {t.createdBy && <span style={s.muted}> · started by {t.createdBy}</span>}
+                  {moderationControls('team', t.id, t.flaggedWords, t.moderation)}
                 </div>
               ))}
             </div>
           )}
 
+          {captainsCard}
+
+          {accessibilityCard}
+
+          {flaggedCard}
+
           <div style={s.card}>
             <h3 style={s.cardTitle}>Players ({players.length})</h3>
             {players.length === 0 ? (
@@ -489,9 +1207,12 @@ function App() {
                   <span style={s.roundBadge}>{currentRound.name} · Q{currentQuestionIdx + 1}/{currentRound.questions.length}</span>
                   <span style={{ ...s.roundBadge, marginLeft: 8, backgroundColor: '#FFF3E0', color: '#E65100' }}>{currentRound.type}</span>
                 </div>
-                {currentRound.timeLimitSeconds > 0 && (
-                  <span style={s.muted}>{currentRound.timeLimitSeconds}s limit</span>
-                )}
+                <div style={{ display: 'flex', gap: 8, alignItems: 'center' }}>
+                  {currentRound.timeLimitSeconds > 0 && (
+                    <span style={s.muted}>{currentRound.timeLimitSeconds}s limit</span>
+                  )}
+                  <span style={s.teamBadge}>{phase.replace('_', ' ')}</span>
+                </div>
               </div>
 
               <p style={s.questionText}>{currentQuestion.text}</p>
@@ -509,7 +1230,14 @@ function App() {
                 Answer: <strong style={{ color: '#2E7D32' }}>{currentQuestion.answer}</strong>
               </p>
 
-              {/* Control buttons flow */}
+              {/* Control buttons flow (scorekeepers only mark) */}
+              {!isHost ? (
+                <div style={s.controlButtons}>
+                  <button style={s.btnOutline} onClick={openMarking}>
+                    Mark Answers
+                  </button>
+                </div>
+              ) : (
               <div style={s.controlButtons}>
                 <button
                   style={questionLoaded ? s.btnDone : s.btnPrimary}
@@ -553,6 +1281,7 @@ function App() {
                   Next →
                 </button>
               </div>
+              )}
             </div>
           )}
 
@@ -563,8 +1292,12 @@ function App() {
             <button style={s.btnOutline} onClick={() => pushScores(undefined)}>
               Push Overall Scores
             </button>
-            <button style={{ ...s.btnDanger, flex: 'none' }} onClick={endQuiz}>End Quiz</button>
+            {isHost && <button style={{ ...s.btnDanger, flex: 'none' }} onClick={endQuiz}>End Quiz</button>}
           </div>
+
+          {captainsCard}
+
+          {accessibilityCard}
         </div>
       )}
 
@@ -587,7 +1320,12 @@ function App() {
                 <div style={{ display: 'flex', justifyContent: 'space-between', alignItems: 'flex-start' }}>
                   <div style={{ flex: 1 }}>
                     <p style={{ fontWeight: 500 }}>{a.teamName || a.playerName || a.playerEmail}</p>
-                    <p style={{ fontSize: 18, margin: '4px 0', color: '#222' }}>{a.answerText || <em style={{ color: '#999' }}>no answer</em>}</p>
+                    {a.hasPhoto && <AnswerPhoto sessionId={session.id} answerId={a.id} token={token} />}
+                    {a.photoPurged && <p style={{ color: '#999', fontStyle: 'italic' }}>Photo deleted after the retention period</p>}
+                    {(a.answerText || (!a.hasPhoto && !a.photoPurged)) && (
+                      <p style={{ fontSize: 18, margin: '4px 0', color: '#222' }}>{a.answerText || <em style={{ color: '#999' }}>no answer</em>}</p>
+                    )}
+                    {moderationControls('answer', a.id, a.flaggedWords, a.moderation)}
                     {a.isLikelyCorrect && a.isCorrect === null && (
                       <span style={{ fontSize: 11, backgroundColor: '#E8F5E9', color: '#2E7D32', padding: '2px 6px', borderRadius: 10 }}>
                         likely correct
@@ -622,6 +1360,8 @@ function App() {
       {/* Scores view */}
       {view === 'scores' && (
         <div>
+          {flaggedCard}
+
           <div style={s.card}>
             <h3 style={s.cardTitle}>Leaderboard</h3>
             {scores.length === 0 ? (
@@ -629,13 +1369,96 @@ function App() {
             ) : (
               scores.map((entry, idx) => (
                 <div key={entry.teamId} style={s.scoreRow}>
-                  <span style={s.scoreRank}>#{idx + 1}</span>
-                  <span style={{ flex: 1, fontWeight: 500 }}>{entry.name}</span>
+                  <span style={s.scoreRank}>#{entry.position || idx + 1}</span>
+                  <span style={{ flex: 1, fontWeight: 500 }}>
+                    {entry.name}
+                    {(entry.adjustments || []).map(a => (
+                      <div key={a.id} style={{ ...s.muted, fontSize: 12, fontWeight: 400 }}>
+                        {entry.answerPoints} from answers · {a.points >= 0 ? '+' : ''}{a.points} late join ({a.policy})
+                        {isHost && (
+                          <span style={{ marginLeft: 8 }}>
+                            <input
+                              style={{ ...s.input, width: 60, padding: '2px 6px', display: 'inline-block' }}
+                              type="number"
+                              value={adjustmentEdits[a.id] ?? String(a.points)}
+                              onChange={e => setAdjustmentEdits(prev => ({ ...prev, [a.id]: e.target.value }))}
+                            />
+                            {adjustmentEdits[a.id] !== undefined && (
+                              <button style={{ ...s.btnOutline, padding: '2px 8px', marginLeft: 4 }} onClick={() => updateAdjustment(a.id)}>Save</button>
+                            )}
+                          </span>
+                        )}
+                      </div>
+                    ))}
+                  </span>
                   <span style={s.scorePoints}>{entry.total} pts</span>
                 </div>
               ))
             )}
           </div>
+          {(activeTiebreak || ties.length > 0) && (
+            <div style={s.card}>
+              <h3 style={s.cardTitle}>Tie-break</h3>
+              {activeTiebreak ? (
+                <div>
+                  <div style={s.roundBadge}>{activeTiebreak.kind === 'nearest' ? 'Nearest number' : 'Sudden death'} · {activeTiebreak.status}</div>
+                  <p style={s.questionText}>{activeTiebreak.question}</p>
+                  <p style={s.muted}>Answer: <strong>{activeTiebreak.answer}</strong></p>
+                  {activeTiebreak.answers.length === 0 ? (
+                    <p style={{ ...s.muted, marginTop: 8 }}>No answers yet.</p>
+                  ) : activeTiebreak.answers.map(a => (
+                    <div key={a.id} style={s.playerRow}>
+                      <span style={{ flex: 1 }}>{a.name}</span>
+                      <span style={{ fontWeight: 600 }}>{a.answerText || <em style={{ color: '#999' }}>no answer</em>}</span>
+                      {activeTiebreak.kind === 'sudden_death' && activeTiebreak.status === 'closed' && (
+                        <>
+                          <button
+                            style={{ ...s.btnOutline, color: '#4CAF50', borderColor: a.isCorrect === true ? '#4CAF50' : '#ddd', padding: '4px 10px' }}
+                            onClick={() => markTiebreakAnswer(activeTiebreak.id, a.id, true)}
+                          >✓</button>
+                          <button
+                            style={{ ...s.btnOutline, color: '#F44336', borderColor: a.isCorrect === false ? '#F44336' : '#ddd', padding: '4px 10px' }}
+                            onClick={() => markTiebreakAnswer(activeTiebreak.id, a.id, false)}
+                          >✗</button>
+                        </>
+                      )}
+                    </div>
+                  ))}
+                  <div style={{ display: 'flex', gap: 8, marginTop: 12 }}>
+                    <button style={s.btnOutline} onClick={() => session && loadTiebreaks(session.id)}>Refresh Answers</button>
+                    {activeTiebreak.status === 'open' && isHost && (
+                      <button style={s.btnOutline} onClick={() => tiebreakAction(activeTiebreak.id, 'close')}>Close Answers</button>
+                    )}
+                    {activeTiebreak.status === 'closed' && (
+                      <button style={s.btnOutline} onClick={() => tiebreakAction(activeTiebreak.id, 'resolve')}>Resolve</button>
+                    )}
+                  </div>
+                </div>
+              ) : (
+                <div>
+                  {isHost && (
+                    <div style={s.field}>
+                      <label style={s.label}>Question type</label>
+                      <select style={s.select} value={tiebreakKind} onChange={e => setTiebreakKind(e.target.value as 'nearest' | 'sudden_death')}>
+                        <option value="nearest">Nearest number</option>
+                        <option value="sudden_death">Sudden death</option>
+                      </select>
+                    </div>
+                  )}
+                  {ties.map(group => (
+                    <div key={group.map(e => e.teamId).join('-')} style={s.playerRow}>
+                      <span style={{ flex: 1 }}>
+                        #{group[0].position} – {group.map(e => e.name).join(', ')} ({group[0].total} pts)
+                      </span>
+                      {isHost && (
+                        <button style={s.btnOutline} onClick={() => startTiebreak(group)}>Start Tie-break</button>
+                      )}
+                    </div>
+                  ))}
+                </div>
+              )}
+            </div>
+          )}
           <button style={s.btnOutline} onClick={() => setView('control')}>← Back to Control</button>
         </div>
       )}
@@ -680,6 +1503,7 @@ const s: Record<string, React.CSSProperties> = {
   teamRow: { padding: '8px 0', borderBottom: '1px solid #f0f0f0', fontSize: 14 },
   playerRow: { display: 'flex', alignItems: 'center', gap: 8, padding: '6px 0', fontSize: 14, borderBottom: '1px solid #f5f5f5' },
   teamBadge: { fontSize: 11, backgroundColor: '#E3F2FD', color: '#1565C0', padding: '2px 8px', borderRadius: 10 },
+  flagBadge: { fontSize: 11, backgroundColor: '#FFF3E0', color: '#E65100', padding: '2px 8px', borderRadius: 10 },
   scoreRow: { display: 'flex', alignItems: 'center', padding: '10px 0', borderBottom: '1px solid #f5f5f5' },
   scoreRank: { fontSize: 14, fontWeight: 700, color: '#999', width: 36 },
   scorePoints: { fontSize: 16, fontWeight: 700, color: '#1565C0' },
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/achgithub/activity-hub-common/i18n"
+)
+
+// Accessibility is what a player's phone applies. The session's defaults come
+// from quiz-master (sessions.settings accessibility); the player's own
+// settings (player_accessibility) override them where set.
+type Accessibility struct {
+	LargeText      bool `json:"largeText"`
+	HighContrast   bool `json:"highContrast"`
+	ReadAloud      bool `json:"readAloud"`
+	SimplifiedText bool `json:"simplifiedText"`
+}
+
+// PlayerAccessibility is a player's own settings. Nil follows the session.
+type PlayerAccessibility struct {
+	LargeText      *bool `json:"largeText"`
+	HighContrast   *bool `json:"highContrast"`
+	ReadAloud      *bool `json:"readAloud"`
+	SimplifiedText *bool `json:"simplifiedText"`
+}
+
+// getPlayerAccessibility loads a player's settings (all nil if they have none)
+func getPlayerAccessibility(email string) (PlayerAccessibility, error) {
+	var p PlayerAccessibility
+	var large, contrast, readAloud, simplified sql.NullBool
+	err := quizDB.QueryRow(`
+		SELECT large_text, high_contrast, read_aloud, simplified_text
+		FROM player_accessibility WHERE user_email = $1`, email).
+		Scan(&large, &contrast, &readAloud, &simplified)
+	if err == sql.ErrNoRows {
+		return p, nil
+	}
+	if err != nil {
+		return p, err
+	}
+	p.LargeText = nullableBool(large)
+	p.HighContrast = nullableBool(contrast)
+	p.ReadAloud = nullableBool(readAloud)
+	p.SimplifiedText = nullableBool(simplified)
+	return p, nil
+}
+
+// effectiveAccessibility merges a session's defaults with the player's own settings
+func effectiveAccessibility(sessionID int, email string) (Accessibility, error) {
+	var a Accessibility
+	var raw []byte
+	err := quizDB.QueryRow(`SELECT COALESCE(settings->'accessibility', '{}') FROM sessions WHERE id = $1`, sessionID).Scan(&raw)
+	if err != nil {
+		return a, err
+	}
+	if err := json.Unmarshal(raw, &a); err != nil {
+		return a, err
+	}
+
+	p, err := getPlayerAccessibility(email)
+	if err != nil {
+		return a, err
+	}
+	override := func(dst *bool, v *bool) {
+		if v != nil {
+			*dst = *v
+		}
+	}
+	override(&a.LargeText, p.LargeText)
+	override(&a.HighContrast, p.HighContrast)
+	override(&a.ReadAloud, p.ReadAloud)
+	override(&a.SimplifiedText, p.SimplifiedText)
+	return a, nil
+}
+
+// handleGetAccessibility - GET /api/accessibility
+func handleGetAccessibility(w http.ResponseWriter, r *http.Request) {
+	user, ok := authlib.GetUserFromContext(r.Context())
+	if !ok {
+		i18n.Error(w, r, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	p, err := getPlayerAccessibility(user.Email)
+	if err != nil {
+		log.Printf("Failed to load accessibility for %s: %v", user.Email, err)
+		i18n.Error(w, r, "database_error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"accessibility": p})
+}
+
+// handleSetAccessibility - PUT /api/accessibility
+// Replaces the player's own settings; null goes back to the session's default.
+// They apply to every quiz the player joins.
+func handleSetAccessibility(w http.ResponseWriter, r *http.Request) {
+	user, ok := authlib.GetUserFromContext(r.Context())
+	if !ok {
+		i18n.Error(w, r, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var p PlayerAccessibility
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		i18n.Error(w, r, "invalid_request", http.StatusBadRequest)
+		return
+	}
+
+	_, err := quizDB.Exec(`
+		INSERT INTO player_accessibility (user_email, large_text, high_contrast, read_aloud, simplified_text)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_email) DO UPDATE SET
+		  large_text = EXCLUDED.large_text, high_contrast = EXCLUDED.high_contrast,
+		  read_aloud = EXCLUDED.read_aloud, simplified_text = EXCLUDED.simplified_text,
+		  updated_at = NOW()`,
+		user.Email, p.LargeText, p.HighContrast, p.ReadAloud, p.SimplifiedText,
+	)
+	if err != nil {
+		log.Printf("Failed to save accessibility for %s: %v", user.Email, err)
+		i18n.Error(w, r, "database_error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"accessibility": p})
+}
+
+func nullableBool(v sql.NullBool) *bool {
+	if !v.Valid {
+		return nil
+	}
+	return &v.Bool
+}
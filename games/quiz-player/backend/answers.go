@@ -0,0 +1,275 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/achgithub/activity-hub-common/i18n"
+	"github.com/gorilla/mux"
+)
+
+// Each player has one answer per question, which they can change until
+// answers close. The frontend autosaves a draft as the player types, and
+// submitting (or editing) updates the same row, so markers only ever see the
+// latest text. Every change is kept in answer_revisions; saving the text that
+// is already stored changes nothing, so retried requests are harmless.
+
+// MyAnswer is the player's current answer to a question
+type MyAnswer struct {
+	QuestionID int    `json:"questionId"`
+	AnswerText string `json:"answerText"`
+	Revision   int    `json:"revision"`
+	Draft      bool   `json:"draft"`    // Autosaved but not yet submitted; still marked if answers close
+	HasPhoto   bool   `json:"hasPhoto"` // Photo rounds: the answer is a photo
+}
+
+// How an answer is being saved
+const (
+	saveSubmit = "submit" // Submit, creating the answer if needed
+	saveEdit   = "edit"   // Change an existing answer
+	saveDraft  = "draft"  // Autosave; never downgrades a submitted answer
+)
+
+// handleSubmitAnswer - POST /api/sessions/{id}/answer {"roundId": 1, "questionId": 2, "answerText": "Paris"}
+// Submitting again replaces the player's answer while answers are open.
+func handleSubmitAnswer(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		RoundID    int    `json:"roundId"`
+		QuestionID int    `json:"questionId"`
+		AnswerText string `json:"answerText"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		i18n.Error(w, r, "invalid_request", http.StatusBadRequest)
+		return
+	}
+	saveAnswer(w, r, body.RoundID, body.QuestionID, body.AnswerText, saveSubmit)
+}
+
+// handleEditAnswer - PATCH /api/sessions/{id}/answers/{questionId} {"answerText": "Lyon"}
+func handleEditAnswer(w http.ResponseWriter, r *http.Request) {
+	saveAnswerForQuestion(w, r, saveEdit)
+}
+
+// handleSaveDraft - PUT /api/sessions/{id}/answers/{questionId}/draft {"answerText": "Par"}
+func handleSaveDraft(w http.ResponseWriter, r *http.Request) {
+	saveAnswerForQuestion(w, r, saveDraft)
+}
+
+// saveAnswerForQuestion saves an answer to the question in the path
+func saveAnswerForQuestion(w http.ResponseWriter, r *http.Request, mode string) {
+	questionID, err := strconv.Atoi(mux.Vars(r)["questionId"])
+	if err != nil {
+		i18n.Error(w, r, "invalid_question_id", http.StatusBadRequest)
+		return
+	}
+	var body struct {
+		RoundID    int    `json:"roundId"`
+		AnswerText string `json:"answerText"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		i18n.Error(w, r, "invalid_request", http.StatusBadRequest)
+		return
+	}
+	saveAnswer(w, r, body.RoundID, questionID, body.AnswerText, mode)
+}
+
+// answerSlot is the caller's place to answer an open question
+type answerSlot struct {
+	SessionID  int
+	RoundID    int
+	QuestionID int
+	PlayerID   int
+	TeamID     *int
+}
+
+// openAnswerSlot checks the caller is playing in the session, that the
+// question is open for answers and that they answer for their team, writing
+// the error response if not. roundID is
+// only used for sessions quiz-master isn't tracking the phase of.
+func openAnswerSlot(w http.ResponseWriter, r *http.Request, roundID, questionID int) (*answerSlot, bool) {
+	user, ok := authlib.GetUserFromContext(r.Context())
+	if !ok {
+		i18n.Error(w, r, "unauthorized", http.StatusUnauthorized)
+		return nil, false
+	}
+
+	sessionID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		i18n.Error(w, r, "invalid_session_id", http.StatusBadRequest)
+		return nil, false
+	}
+
+	// Get player record
+	slot := answerSlot{SessionID: sessionID, RoundID: roundID, QuestionID: questionID}
+	var teamID sql.NullInt64
+	err = quizDB.QueryRow(`SELECT id, team_id FROM session_players WHERE session_id = $1 AND user_email = $2`,
+		sessionID, user.Email).Scan(&slot.PlayerID, &teamID)
+	if err == sql.ErrNoRows {
+		i18n.Error(w, r, "not_in_session", http.StatusForbidden)
+		return nil, false
+	}
+	if err != nil {
+		i18n.Error(w, r, "database_error", http.StatusInternalServerError)
+		return nil, false
+	}
+	if teamID.Valid {
+		v := int(teamID.Int64)
+		slot.TeamID = &v
+	}
+
+	// Answers are only accepted while quiz-master has this question open
+	phase, err := getSessionPhase(sessionID)
+	if err != nil {
+		i18n.Error(w, r, "database_error", http.StatusInternalServerError)
+		return nil, false
+	}
+	if phase != nil {
+		if phase.Phase != "answers_open" || phase.QuestionID != questionID {
+			i18n.Error(w, r, "answers_closed", http.StatusConflict)
+			return nil, false
+		}
+		if phase.Deadline != nil && time.Now().After(phase.Deadline.Add(answerGracePeriod)) {
+			i18n.Error(w, r, "times_up", http.StatusConflict)
+			return nil, false
+		}
+		slot.RoundID = phase.RoundID
+	}
+
+	// Only the team captain's device answers (see captains.go)
+	if slot.TeamID != nil {
+		if err := lockTeamAnswerer(*slot.TeamID, slot.PlayerID, deviceID(r)); err != nil {
+			writeAnswererError(w, r, err)
+			return nil, false
+		}
+	}
+	return &slot, true
+}
+
+// saveAnswer stores the caller's answer to an open question and responds with it
+func saveAnswer(w http.ResponseWriter, r *http.Request, roundID, questionID int, text, mode string) {
+	if strings.TrimSpace(text) == "" {
+		i18n.Error(w, r, "answer_empty", http.StatusBadRequest)
+		return
+	}
+
+	slot, ok := openAnswerSlot(w, r, roundID, questionID)
+	if !ok {
+		return
+	}
+	sessionID, playerID := slot.SessionID, slot.PlayerID
+
+	// Answers can end up on the big screen, so they go through the venue's content filter
+	screened, err := screenText(sessionID, text)
+	if err != nil {
+		i18n.Error(w, r, "database_error", http.StatusInternalServerError)
+		return
+	}
+	if screened.Blocked {
+		i18n.Error(w, r, "answer_words_blocked", http.StatusUnprocessableEntity)
+		return
+	}
+
+	tx, err := quizDB.Begin()
+	if err != nil {
+		i18n.Error(w, r, "database_error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	if mode == saveEdit {
+		var exists bool
+		err = tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM answers WHERE session_id = $1 AND question_id = $2 AND player_id = $3)`,
+			sessionID, questionID, playerID).Scan(&exists)
+		if err != nil {
+			i18n.Error(w, r, "database_error", http.StatusInternalServerError)
+			return
+		}
+		if !exists {
+			i18n.Error(w, r, "no_answer_to_edit", http.StatusNotFound)
+			return
+		}
+	}
+
+	// Only a change of text, or submitting a draft, makes a new revision. A
+	// changed answer loses any mark it had (a question reloaded after marking).
+	a := MyAnswer{QuestionID: questionID, AnswerText: text}
+	var answerID int
+	err = tx.QueryRow(`
+		INSERT INTO answers (session_id, round_id, question_id, team_id, player_id, answer_text,
+		                     flagged_words, masked_text, moderation, is_draft)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (session_id, question_id, player_id) DO UPDATE
+		SET answer_text = EXCLUDED.answer_text, round_id = EXCLUDED.round_id, team_id = EXCLUDED.team_id,
+		    flagged_words = EXCLUDED.flagged_words, masked_text = EXCLUDED.masked_text,
+		    moderation = EXCLUDED.moderation,
+		    is_draft = answers.is_draft AND EXCLUDED.is_draft,
+		    revision = answers.revision + 1, submitted_at = NOW(),
+		    is_correct = NULL, points = 0, marked_at = NULL
+		WHERE answers.answer_text IS DISTINCT FROM EXCLUDED.answer_text
+		   OR (answers.is_draft AND NOT EXCLUDED.is_draft)
+		RETURNING id, revision, is_draft, photo_path IS NOT NULL`,
+		sessionID, slot.RoundID, questionID,
+		nullableIntVal(slot.TeamID), playerID, text,
+		screened.flaggedWords(), screened.maskedText(), screened.moderation(), mode == saveDraft,
+	).Scan(&answerID, &a.Revision, &a.Draft, &a.HasPhoto)
+	changed := err == nil
+	if err == sql.ErrNoRows {
+		// Nothing to change: a repeated save or an autosave after submitting
+		err = tx.QueryRow(`
+			SELECT id, revision, is_draft, photo_path IS NOT NULL FROM answers
+			WHERE session_id = $1 AND question_id = $2 AND player_id = $3`,
+			sessionID, questionID, playerID).Scan(&answerID, &a.Revision, &a.Draft, &a.HasPhoto)
+	}
+	if err != nil {
+		i18n.Error(w, r, "database_error", http.StatusInternalServerError)
+		return
+	}
+
+	if changed {
+		_, err = tx.Exec(`
+			INSERT INTO answer_revisions (answer_id, revision, answer_text, is_draft)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT DO NOTHING`,
+			answerID, a.Revision, text, a.Draft)
+		if err != nil {
+			i18n.Error(w, r, "database_error", http.StatusInternalServerError)
+			return
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		i18n.Error(w, r, "database_error", http.StatusInternalServerError)
+		return
+	}
+
+	status := "submitted"
+	if a.Draft {
+		status = "draft"
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  status,
+		"changed": changed,
+		"answer":  a,
+	})
+}
+
+// getMyAnswer returns the player's answer to a question, or nil if they have none
+func getMyAnswer(sessionID, questionID, playerID int) (*MyAnswer, error) {
+	a := MyAnswer{QuestionID: questionID}
+	err := quizDB.QueryRow(`
+		SELECT COALESCE(answer_text, ''), revision, is_draft, photo_path IS NOT NULL FROM answers
+		WHERE session_id = $1 AND question_id = $2 AND player_id = $3`,
+		sessionID, questionID, playerID).Scan(&a.AnswerText, &a.Revision, &a.Draft, &a.HasPhoto)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/achgithub/activity-hub-common/i18n"
+	"github.com/gorilla/mux"
+)
+
+// Each team answers from one device: its captain's. The first player to answer
+// becomes captain if the team has none, and the device they answer from (the
+// X-Device-ID header the frontend keeps in localStorage) is locked to the team,
+// so teammates' phones - or the captain signed in on a second phone - can't
+// send conflicting answers. The captain can hand over to a teammate or move to
+// another device; quiz-master can reassign the captain if their phone dies.
+// In individual mode every player is their own team's captain.
+
+var (
+	errNotCaptain  = errors.New("not the team captain")
+	errOtherDevice = errors.New("team is answering on another device")
+)
+
+const maxDeviceIDLength = 64
+
+// TeamCaptain is who answers for the caller's team
+type TeamCaptain struct {
+	TeamID          int          `json:"teamId"`
+	CaptainPlayerID *int         `json:"captainPlayerId"` // Null until someone answers or is made captain
+	CaptainName     string       `json:"captainName"`
+	IsMe            bool         `json:"isMe"`
+	ThisDevice      bool         `json:"thisDevice"` // The team's answers are locked to this device (or not to any yet)
+	Members         []TeamMember `json:"members"`
+}
+
+type TeamMember struct {
+	PlayerID int    `json:"playerId"`
+	Name     string `json:"name"`
+}
+
+// deviceID is the caller's device from the X-Device-ID header; empty for
+// clients that don't send one, which are only held to the captain check
+func deviceID(r *http.Request) string {
+	id := strings.TrimSpace(r.Header.Get("X-Device-ID"))
+	if len(id) > maxDeviceIDLength {
+		return ""
+	}
+	return id
+}
+
+// lockTeamAnswerer checks the player may answer for the team from the device,
+// making them captain (and the device theirs) if the team has none yet. The
+// update locks the team row, so of two first answers at once only one wins.
+func lockTeamAnswerer(teamID, playerID int, device string) error {
+	var captainID int
+	var captainDevice string
+	err := quizDB.QueryRow(`
+		UPDATE teams
+		SET captain_player_id = COALESCE(captain_player_id, $2),
+		    captain_device = CASE WHEN captain_player_id IS NULL OR captain_player_id = $2
+		                          THEN COALESCE(captain_device, NULLIF($3, ''))
+		                          ELSE captain_device END
+		WHERE id = $1
+		RETURNING captain_player_id, COALESCE(captain_device, '')`,
+		teamID, playerID, device).Scan(&captainID, &captainDevice)
+	if err != nil {
+		return err
+	}
+	if captainID != playerID {
+		return errNotCaptain
+	}
+	if device != "" && captainDevice != "" && captainDevice != device {
+		return errOtherDevice
+	}
+	return nil
+}
+
+// writeAnswererError responds to a failed lockTeamAnswerer
+func writeAnswererError(w http.ResponseWriter, r *http.Request, err error) {
+	switch err {
+	case errNotCaptain:
+		i18n.Error(w, r, "not_captain", http.StatusForbidden)
+	case errOtherDevice:
+		i18n.Error(w, r, "other_device", http.StatusConflict)
+	default:
+		i18n.Error(w, r, "database_error", http.StatusInternalServerError)
+	}
+}
+
+// getTeamCaptain returns the team's captain as seen by the player on the device
+func getTeamCaptain(teamID, playerID int, device string) (*TeamCaptain, error) {
+	c := TeamCaptain{TeamID: teamID, Members: []TeamMember{}}
+	var captainID sql.NullInt64
+	var captainDevice string
+	err := quizDB.QueryRow(`
+		SELECT t.captain_player_id, COALESCE(t.captain_device, ''), COALESCE(sp.user_name, sp.user_email, '')
+		FROM teams t
+		LEFT JOIN session_players sp ON sp.id = t.captain_player_id
+		WHERE t.id = $1`, teamID).Scan(&captainID, &captainDevice, &c.CaptainName)
+	if err != nil {
+		return nil, err
+	}
+	if captainID.Valid {
+		v := int(captainID.Int64)
+		c.CaptainPlayerID = &v
+		c.IsMe = v == playerID
+	}
+	c.ThisDevice = captainDevice == "" || captainDevice == device
+
+	rows, err := quizDB.Query(`
+		SELECT id, COALESCE(user_name, user_email) FROM session_players
+		WHERE team_id = $1 ORDER BY joined_at`, teamID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var m TeamMember
+		if err := rows.Scan(&m.PlayerID, &m.Name); err != nil {
+			continue
+		}
+		c.Members = append(c.Members, m)
+	}
+	return &c, nil
+}
+
+// handleSetCaptain - POST /api/sessions/{id}/captain {"playerId": 12}
+// The captain hands over to a teammate, or names themselves to move the
+// team's answering to the device they're calling from. A team with no captain
+// yet can be claimed by any member.
+func handleSetCaptain(w http.ResponseWriter, r *http.Request) {
+	user, ok := authlib.GetUserFromContext(r.Context())
+	if !ok {
+		i18n.Error(w, r, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	sessionID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		i18n.Error(w, r, "invalid_session_id", http.StatusBadRequest)
+		return
+	}
+
+	var body struct {
+		PlayerID int `json:"playerId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		i18n.Error(w, r, "invalid_request", http.StatusBadRequest)
+		return
+	}
+
+	var playerID int
+	var teamID, captainID sql.NullInt64
+	err = quizDB.QueryRow(`
+		SELECT sp.id, sp.team_id, t.captain_player_id
+		FROM session_players sp
+		LEFT JOIN teams t ON t.id = sp.team_id
+		WHERE sp.session_id = $1 AND sp.user_email = $2`, sessionID, user.Email).Scan(&playerID, &teamID, &captainID)
+	if err == sql.ErrNoRows {
+		i18n.Error(w, r, "not_in_session", http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		i18n.Error(w, r, "database_error", http.StatusInternalServerError)
+		return
+	}
+	if !teamID.Valid {
+		i18n.Error(w, r, "join_team_first", http.StatusConflict)
+		return
+	}
+	if captainID.Valid && int(captainID.Int64) != playerID {
+		i18n.Error(w, r, "not_captain_handover", http.StatusForbidden)
+		return
+	}
+
+	var newCaptainName string
+	err = quizDB.QueryRow(`SELECT COALESCE(user_name, user_email) FROM session_players WHERE id = $1 AND team_id = $2`,
+		body.PlayerID, teamID.Int64).Scan(&newCaptainName)
+	if err == sql.ErrNoRows {
+		i18n.Error(w, r, "not_your_teammate", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		i18n.Error(w, r, "database_error", http.StatusInternalServerError)
+		return
+	}
+
+	// Naming yourself locks the team to this device; a new captain's device is
+	// locked by their first answer
+	device := ""
+	if body.PlayerID == playerID {
+		device = deviceID(r)
+	}
+	if err := setTeamCaptain(sessionID, int(teamID.Int64), body.PlayerID, device); err != nil {
+		i18n.Error(w, r, "database_error", http.StatusInternalServerError)
+		return
+	}
+	_ = publishEvent(evCaptainChanged.New(sessionKey(sessionID), CaptainChanged{
+		TeamID: int(teamID.Int64), PlayerID: body.PlayerID, PlayerName: newCaptainName,
+	}))
+
+	captain, err := getTeamCaptain(int(teamID.Int64), playerID, deviceID(r))
+	if err != nil {
+		i18n.Error(w, r, "database_error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"captain": captain})
+}
+
+// setTeamCaptain makes the player the team's captain on the device ("" until
+// they answer). An answer the team already has to the open question moves to
+// them, so the team still has just the one answer to edit.
+func setTeamCaptain(sessionID, teamID, playerID int, device string) error {
+	tx, err := quizDB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var oldCaptain sql.NullInt64
+	err = tx.QueryRow(`SELECT captain_player_id FROM teams WHERE id = $1 FOR UPDATE`, teamID).Scan(&oldCaptain)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`UPDATE teams SET captain_player_id = $2, captain_device = NULLIF($3, '') WHERE id = $1`,
+		teamID, playerID, device)
+	if err != nil {
+		return err
+	}
+
+	if oldCaptain.Valid && int(oldCaptain.Int64) != playerID {
+		_, err = tx.Exec(`
+			UPDATE answers a SET player_id = $3
+			FROM session_phase p
+			WHERE p.session_id = a.session_id AND p.phase = 'answers_open' AND p.question_id = a.question_id
+			  AND a.session_id = $1 AND a.player_id = $2
+			  AND NOT EXISTS (SELECT 1 FROM answers b
+			                  WHERE b.session_id = a.session_id AND b.question_id = a.question_id AND b.player_id = $3)`,
+			sessionID, oldCaptain.Int64, playerID)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/achgithub/activity-hub-common/i18n"
+	"github.com/achgithub/activity-hub-common/services"
+)
+
+// handleDigest - POST /api/digest {emails, until}
+// Called by the identity shell (service token) for its email digest. Quiz
+// nights aren't per player, so every scheduled session still in the lobby
+// goes to everyone at its venue.
+func handleDigest(w http.ResponseWriter, r *http.Request) {
+	var req services.DigestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		i18n.Error(w, r, "invalid_request", http.StatusBadRequest)
+		return
+	}
+
+	rows, err := quizDB.Query(`
+		SELECT name, mode, scheduled_at, venue_id
+		FROM sessions
+		WHERE status = 'lobby' AND scheduled_at > NOW() AND scheduled_at <= $1
+		ORDER BY scheduled_at`, req.Until)
+	if err != nil {
+		log.Printf("Failed to load scheduled sessions: %v", err)
+		i18n.Error(w, r, "database_error", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	digest := services.Digest{Items: map[string][]services.DigestItem{}, Everyone: []services.DigestItem{}}
+	for rows.Next() {
+		var name, mode string
+		var scheduledAt time.Time
+		var venueID int
+		if err := rows.Scan(&name, &mode, &scheduledAt, &venueID); err != nil {
+			continue
+		}
+		detail := "Team quiz"
+		if mode == "individual" {
+			detail = "Individual quiz"
+		}
+		digest.Everyone = append(digest.Everyone, services.DigestItem{
+			Title:   name,
+			Detail:  detail,
+			At:      scheduledAt,
+			VenueID: venueID,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(digest)
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/achgithub/activity-hub-common/events"
+)
+
+// The session stream forwards quiz-master's events as is; their payloads are
+// registered there. Only the stream's own opening event, and the events
+// players cause (captain changes, the applause meter), are registered here.
+
+type StreamOpened struct {
+	SessionID int `json:"sessionId"`
+}
+
+// CaptainChanged says who now answers for a team
+type CaptainChanged struct {
+	TeamID     int    `json:"teamId"`
+	PlayerID   int    `json:"playerId"`
+	PlayerName string `json:"playerName"`
+}
+
+// Applause is the applause meter, sent every second while players are reacting
+type Applause struct {
+	Level     int            `json:"level"`     // 0-100
+	Reactions map[string]int `json:"reactions"` // Emoji counts in the last second
+	Total     int            `json:"total"`
+}
+
+var (
+	evConnected      = events.Register[StreamOpened]("connected", 1, "Stream opened")
+	evCaptainChanged = events.Register[CaptainChanged]("captain_changed", 1, "A team has a new captain")
+	evApplause       = events.Register[Applause]("applause", 1, "Applause meter from player reactions between questions")
+)
+
+// sessionKey is a quiz session's envelope session
+func sessionKey(sessionID int) string {
+	return strconv.Itoa(sessionID)
+}
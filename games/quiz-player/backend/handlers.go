@@ -1,22 +1,29 @@
 package main
 
 import (
+	"crypto/rand"
 	"database/sql"
 	"encoding/json"
-	"fmt"
+	"math/big"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/achgithub/activity-hub-common/events"
+	"github.com/achgithub/activity-hub-common/i18n"
+	apphttp "github.com/achgithub/activity-hub-common/http"
 	"github.com/gorilla/mux"
 )
 
 func handleConfig(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"appName": "Quiz Player",
-		"port":    4041,
+	apphttp.WriteConfig(w, apphttp.AppConfig{
+		AppID:        "quiz-player",
+		Name:         "Quiz Player",
+		Icon:         "🧠",
+		Description:  "Join and play live pub quizzes",
+		Capabilities: apphttp.Capabilities{SSE: true, Teams: true},
 	})
 }
 
@@ -27,7 +34,7 @@ func handleGetActiveSessions(w http.ResponseWriter, r *http.Request) {
 		WHERE status IN ('lobby', 'active')
 		ORDER BY created_at DESC`)
 	if err != nil {
-		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		i18n.Error(w, r, "database_error", http.StatusInternalServerError)
 		return
 	}
 	defer rows.Close()
@@ -53,7 +60,7 @@ func handleGetActiveSessions(w http.ResponseWriter, r *http.Request) {
 func handleJoinSession(w http.ResponseWriter, r *http.Request) {
 	user, ok := authlib.GetUserFromContext(r.Context())
 	if !ok {
-		http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+		i18n.Error(w, r, "unauthorized", http.StatusUnauthorized)
 		return
 	}
 
@@ -61,7 +68,7 @@ func handleJoinSession(w http.ResponseWriter, r *http.Request) {
 		JoinCode string `json:"joinCode"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.JoinCode == "" {
-		http.Error(w, `{"error":"joinCode required"}`, http.StatusBadRequest)
+		i18n.Error(w, r, "join_code_required", http.StatusBadRequest)
 		return
 	}
 
@@ -70,36 +77,50 @@ func handleJoinSession(w http.ResponseWriter, r *http.Request) {
 	err := quizDB.QueryRow(`SELECT id, name, mode, status FROM sessions WHERE join_code = $1`, body.JoinCode).
 		Scan(&sessionID, &sessionName, &mode, &status)
 	if err == sql.ErrNoRows {
-		http.Error(w, `{"error":"session not found"}`, http.StatusNotFound)
+		i18n.Error(w, r, "session_not_found", http.StatusNotFound)
 		return
 	}
 	if err != nil {
-		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		i18n.Error(w, r, "database_error", http.StatusInternalServerError)
 		return
 	}
 	if status == "completed" {
-		http.Error(w, `{"error":"quiz has ended"}`, http.StatusGone)
+		i18n.Error(w, r, "quiz_ended", http.StatusGone)
 		return
 	}
 
-	// Upsert player record
+	// Players appear under their profile nickname (or privacy alias) since names show on the TV display
+	displayName := user.Name
+	if profiles, err := authlib.LoadProfiles(identityDB, []string{user.Email}); err == nil {
+		if p, ok := profiles[user.Email]; ok {
+			displayName = p.PublicName()
+		}
+	}
+
+	// Upsert player record (xmax = 0 only for a fresh insert)
 	var playerID int
+	var newPlayer bool
 	err = quizDB.QueryRow(`
 		INSERT INTO session_players (session_id, user_email, user_name)
 		VALUES ($1, $2, $3)
 		ON CONFLICT (session_id, user_email) DO UPDATE SET user_name = EXCLUDED.user_name
-		RETURNING id`,
-		sessionID, user.Email, user.Name,
-	).Scan(&playerID)
+		RETURNING id, (xmax = 0)`,
+		sessionID, user.Email, displayName,
+	).Scan(&playerID, &newPlayer)
 	if err != nil {
-		http.Error(w, `{"error":"database error joining session"}`, http.StatusInternalServerError)
+		i18n.Error(w, r, "database_error", http.StatusInternalServerError)
 		return
 	}
 
+	// In individual mode each player scores alone, so a new player mid-quiz is a late join
+	if newPlayer && mode == "individual" {
+		applyLateJoin(sessionID, nil, &playerID)
+	}
+
 	// Get teams for this session
 	teams, err := getSessionTeams(sessionID)
 	if err != nil {
-		http.Error(w, `{"error":"database error loading teams"}`, http.StatusInternalServerError)
+		i18n.Error(w, r, "database_error", http.StatusInternalServerError)
 		return
 	}
 
@@ -117,30 +138,64 @@ func handleJoinSession(w http.ResponseWriter, r *http.Request) {
 func handleJoinTeam(w http.ResponseWriter, r *http.Request) {
 	user, ok := authlib.GetUserFromContext(r.Context())
 	if !ok {
-		http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+		i18n.Error(w, r, "unauthorized", http.StatusUnauthorized)
 		return
 	}
 
 	var body struct {
 		SessionID int    `json:"sessionId"`
 		TeamCode  string `json:"teamCode"`
+		TeamName  string `json:"teamName"` // Start a new team instead of joining one by code
 	}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		http.Error(w, `{"error":"invalid JSON"}`, http.StatusBadRequest)
+		i18n.Error(w, r, "invalid_request", http.StatusBadRequest)
 		return
 	}
 
 	var teamID int
-	err := quizDB.QueryRow(`SELECT id FROM teams WHERE session_id = $1 AND join_code = $2`,
-		body.SessionID, body.TeamCode).Scan(&teamID)
-	if err == sql.ErrNoRows {
-		http.Error(w, `{"error":"team not found"}`, http.StatusNotFound)
-		return
+	var err error
+	if body.TeamCode == "" && strings.TrimSpace(body.TeamName) != "" {
+		teamID, err = createPlayerTeam(body.SessionID, strings.TrimSpace(body.TeamName), user.Email)
+		if err == errTeamNameBlocked {
+			i18n.Error(w, r, "team_name_blocked", http.StatusUnprocessableEntity)
+			return
+		}
+		if err == errNoNewTeams {
+			i18n.Error(w, r, "no_new_teams", http.StatusConflict)
+			return
+		}
+		if err != nil {
+			i18n.Error(w, r, "database_error", http.StatusInternalServerError)
+			return
+		}
+	} else {
+		err = quizDB.QueryRow(`SELECT id FROM teams WHERE session_id = $1 AND join_code = $2`,
+			body.SessionID, body.TeamCode).Scan(&teamID)
+		if err == sql.ErrNoRows {
+			i18n.Error(w, r, "team_not_found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			i18n.Error(w, r, "database_error", http.StatusInternalServerError)
+			return
+		}
 	}
+
+	// Enforce the session's team size limit (from its template)
+	var maxTeamSize, teamSize int
+	err = quizDB.QueryRow(`
+		SELECT COALESCE((s.settings->>'maxTeamSize')::int, 0),
+		       (SELECT COUNT(*) FROM session_players WHERE team_id = $2 AND user_email <> $3)
+		FROM sessions s WHERE s.id = $1`, body.SessionID, teamID, user.Email).Scan(&maxTeamSize, &teamSize)
 	if err != nil {
-		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		i18n.Error(w, r, "database_error", http.StatusInternalServerError)
 		return
 	}
+	if maxTeamSize > 0 && teamSize >= maxTeamSize {
+		i18n.Error(w, r, "team_full", http.StatusConflict)
+		return
+	}
+	firstMember := teamSize == 0
 
 	_, err = quizDB.Exec(`
 		UPDATE session_players SET team_id = $1
@@ -148,10 +203,21 @@ func handleJoinTeam(w http.ResponseWriter, r *http.Request) {
 		teamID, body.SessionID, user.Email,
 	)
 	if err != nil {
-		http.Error(w, `{"error":"database error updating team"}`, http.StatusInternalServerError)
+		i18n.Error(w, r, "database_error", http.StatusInternalServerError)
 		return
 	}
 
+	// A captain moving team leaves their old team free to answer again
+	quizDB.Exec(`
+		UPDATE teams SET captain_player_id = NULL, captain_device = NULL
+		WHERE id <> $1 AND captain_player_id = (SELECT id FROM session_players WHERE session_id = $2 AND user_email = $3)`,
+		teamID, body.SessionID, user.Email)
+
+	// A team's first player arriving mid-quiz brings the team in late
+	if firstMember {
+		applyLateJoin(body.SessionID, &teamID, nil)
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{"teamId": teamID})
 }
@@ -159,13 +225,13 @@ func handleJoinTeam(w http.ResponseWriter, r *http.Request) {
 func handleGetSessionState(w http.ResponseWriter, r *http.Request) {
 	user, ok := authlib.GetUserFromContext(r.Context())
 	if !ok {
-		http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+		i18n.Error(w, r, "unauthorized", http.StatusUnauthorized)
 		return
 	}
 
 	sessionID, err := strconv.Atoi(mux.Vars(r)["id"])
 	if err != nil {
-		http.Error(w, `{"error":"invalid session id"}`, http.StatusBadRequest)
+		i18n.Error(w, r, "invalid_session_id", http.StatusBadRequest)
 		return
 	}
 
@@ -174,11 +240,11 @@ func handleGetSessionState(w http.ResponseWriter, r *http.Request) {
 	err = quizDB.QueryRow(`SELECT id, pack_id, name, mode, status, join_code, created_at, started_at, completed_at FROM sessions WHERE id = $1`, sessionID).
 		Scan(&s.ID, &s.PackID, &s.Name, &s.Mode, &s.Status, &s.JoinCode, &s.CreatedAt, &startedAt, &completedAt)
 	if err == sql.ErrNoRows {
-		http.Error(w, `{"error":"session not found"}`, http.StatusNotFound)
+		i18n.Error(w, r, "session_not_found", http.StatusNotFound)
 		return
 	}
 	if err != nil {
-		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		i18n.Error(w, r, "database_error", http.StatusInternalServerError)
 		return
 	}
 	if startedAt.Valid {
@@ -189,6 +255,7 @@ func handleGetSessionState(w http.ResponseWriter, r *http.Request) {
 	}
 
 	teams, _ := getSessionTeams(sessionID)
+	phase, _ := getSessionPhase(sessionID)
 
 	// Get this player's record
 	var player SessionPlayer
@@ -197,12 +264,23 @@ func handleGetSessionState(w http.ResponseWriter, r *http.Request) {
 		sessionID, user.Email).Scan(&player.ID, &player.SessionID, &player.UserEmail, &player.UserName, &teamID)
 	var myPlayer *SessionPlayer
 	var myTeamID *int
+	var tiebreak *Tiebreak
+	var myAnswer *MyAnswer
+	var captain *TeamCaptain
+	accessibility, _ := effectiveAccessibility(sessionID, user.Email)
 	if err == nil {
 		myPlayer = &player
+		entityID := player.ID
 		if teamID.Valid {
 			v := int(teamID.Int64)
 			myTeamID = &v
 			myPlayer.TeamID = &v
+			entityID = v
+			captain, _ = getTeamCaptain(v, player.ID, deviceID(r))
+		}
+		tiebreak, _ = getCurrentTiebreak(sessionID, entityID)
+		if phase != nil && phase.Phase == "answers_open" {
+			myAnswer, _ = getMyAnswer(sessionID, phase.QuestionID, player.ID)
 		}
 	}
 
@@ -212,102 +290,40 @@ func handleGetSessionState(w http.ResponseWriter, r *http.Request) {
 		"teams":    teams,
 		"myTeamId": myTeamID,
 		"myPlayer": myPlayer,
+		"phase":    phase,
+		"tiebreak": tiebreak,
+		"myAnswer": myAnswer,
+		"captain":  captain,
+		// Session defaults merged with the player's own settings
+		"accessibility": accessibility,
 	})
 }
 
-func handleSubmitAnswer(w http.ResponseWriter, r *http.Request) {
-	user, ok := authlib.GetUserFromContext(r.Context())
-	if !ok {
-		http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
-		return
-	}
-
+func handleSessionStream(w http.ResponseWriter, r *http.Request) {
 	sessionID, err := strconv.Atoi(mux.Vars(r)["id"])
 	if err != nil {
-		http.Error(w, `{"error":"invalid session id"}`, http.StatusBadRequest)
-		return
-	}
-
-	var body struct {
-		RoundID    int    `json:"roundId"`
-		QuestionID int    `json:"questionId"`
-		AnswerText string `json:"answerText"`
-	}
-	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		http.Error(w, `{"error":"invalid JSON"}`, http.StatusBadRequest)
-		return
-	}
-
-	// Get player record
-	var playerID int
-	var teamID sql.NullInt64
-	err = quizDB.QueryRow(`SELECT id, team_id FROM session_players WHERE session_id = $1 AND user_email = $2`,
-		sessionID, user.Email).Scan(&playerID, &teamID)
-	if err == sql.ErrNoRows {
-		http.Error(w, `{"error":"not in this session"}`, http.StatusForbidden)
-		return
-	}
-	if err != nil {
-		http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
+		http.Error(w, "invalid session id", http.StatusBadRequest)
 		return
 	}
 
-	var teamIDVal *int
-	if teamID.Valid {
-		v := int(teamID.Int64)
-		teamIDVal = &v
-	}
-
-	var answerID int
-	err = quizDB.QueryRow(`
-		INSERT INTO answers (session_id, round_id, question_id, team_id, player_id, answer_text)
-		VALUES ($1, $2, $3, $4, $5, $6)
-		ON CONFLICT DO NOTHING
-		RETURNING id`,
-		sessionID, body.RoundID, body.QuestionID,
-		nullableIntVal(teamIDVal), playerID, body.AnswerText,
-	).Scan(&answerID)
-	if err != nil && err != sql.ErrNoRows {
-		// Try update if already exists
-		_, err = quizDB.Exec(`
-			UPDATE answers SET answer_text = $1, submitted_at = NOW()
-			WHERE session_id = $2 AND round_id = $3 AND question_id = $4 AND player_id = $5`,
-			body.AnswerText, sessionID, body.RoundID, body.QuestionID, playerID,
-		)
-		if err != nil {
-			http.Error(w, `{"error":"database error"}`, http.StatusInternalServerError)
-			return
-		}
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"status": "submitted"})
-}
-
-func handleSessionStream(w http.ResponseWriter, r *http.Request) {
-	sessionID, err := strconv.Atoi(mux.Vars(r)["id"])
+	sub, err := subscribeToSession(r.Context(), sessionID)
 	if err != nil {
-		http.Error(w, "invalid session id", http.StatusBadRequest)
+		http.Error(w, "live updates unavailable", http.StatusServiceUnavailable)
 		return
 	}
+	defer sub.Close()
 
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
 	w.Header().Set("Connection", "keep-alive")
 	w.Header().Set("X-Accel-Buffering", "no")
 
-	flusher, ok := w.(http.Flusher)
-	if !ok {
+	if _, ok := w.(http.Flusher); !ok {
 		http.Error(w, "streaming not supported", http.StatusInternalServerError)
 		return
 	}
 
-	// Send initial connected event
-	fmt.Fprintf(w, "event: connected\ndata: {\"sessionId\":%d}\n\n", sessionID)
-	flusher.Flush()
-
-	pubsub, msgChan := subscribeToSession(sessionID)
-	defer pubsub.Close()
+	events.Send(w, evConnected.New(sessionKey(sessionID), StreamOpened{SessionID: sessionID}))
 
 	ticker := time.NewTicker(30 * time.Second)
 	defer ticker.Stop()
@@ -317,20 +333,47 @@ func handleSessionStream(w http.ResponseWriter, r *http.Request) {
 		select {
 		case <-ctx.Done():
 			return
-		case msg := <-msgChan:
-			fmt.Fprintf(w, "data: %s\n\n", msg.Payload)
-			flusher.Flush()
+		case payload := <-sub.C:
+			events.Forward(w, payload)
 		case <-ticker.C:
-			fmt.Fprintf(w, "event: ping\ndata: {}\n\n")
-			flusher.Flush()
+			events.Send(w, events.Ping.New(sessionKey(sessionID), events.NoPayload{}))
 		}
 	}
 }
 
 // --- Helpers ---
 
+// answerGracePeriod allows for network delay on answers sent as the timer runs
+// out. quiz-master closes timed questions once it has passed.
+const answerGracePeriod = 2 * time.Second
+
+// getSessionPhase returns the session's question phase, or nil for sessions
+// quiz-master hasn't started tracking
+func getSessionPhase(sessionID int) (*SessionPhase, error) {
+	var p SessionPhase
+	var deadline sql.NullTime
+	err := quizDB.QueryRow(`
+		SELECT phase, COALESCE(round_id,0), COALESCE(question_id,0), round_number, question_number, changed_at, deadline
+		FROM session_phase WHERE session_id = $1`, sessionID).
+		Scan(&p.Phase, &p.RoundID, &p.QuestionID, &p.RoundNumber, &p.QuestionNumber, &p.ChangedAt, &deadline)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if deadline.Valid {
+		p.Deadline = &deadline.Time
+	}
+	p.ServerTime = time.Now().UTC()
+	return &p, nil
+}
+
+// getSessionTeams lists teams under their public names (see publicTeamNameSQL)
 func getSessionTeams(sessionID int) ([]map[string]interface{}, error) {
-	rows, err := quizDB.Query(`SELECT id, name, COALESCE(join_code,'') FROM teams WHERE session_id = $1 ORDER BY id`, sessionID)
+	rows, err := quizDB.Query(`
+		SELECT t.id, `+publicTeamNameSQL+`, COALESCE(t.join_code,'')
+		FROM teams t WHERE t.session_id = $1 ORDER BY t.id`, sessionID)
 	if err != nil {
 		return nil, err
 	}
@@ -354,3 +397,16 @@ func nullableIntVal(i *int) interface{} {
 	}
 	return *i
 }
+
+func generateCode(length int) (string, error) {
+	const charset = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	code := make([]byte, length)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(charset))))
+		if err != nil {
+			return "", err
+		}
+		code[i] = charset[n.Int64()]
+	}
+	return string(code), nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"database/sql"
+	"log"
+	"math"
+)
+
+// Late-join policies (sessions.settings lateJoinPolicy). A team (or player in
+// individual mode) arriving after scoring has started gets a one-off
+// score_adjustments row so it can compete for the rest of the quiz.
+const (
+	lateJoinZero    = "zero"    // Start from 0 (default)
+	lateJoinAverage = "average" // Average total of the other teams
+	lateJoinAdmin   = "admin"   // lateJoinPoints, editable by the quiz master
+)
+
+// applyLateJoin records the late-join backfill for a team (teamID) or, in
+// individual mode, a player (playerID). It does nothing before any answer has
+// been marked, and at most once per team/player.
+func applyLateJoin(sessionID int, teamID, playerID *int) {
+	var status, policy string
+	var adminPoints int
+	var scoring bool
+	err := quizDB.QueryRow(`
+		SELECT s.status,
+		       COALESCE(s.settings->>'lateJoinPolicy', ''),
+		       COALESCE((s.settings->>'lateJoinPoints')::int, 0),
+		       EXISTS (SELECT 1 FROM answers WHERE session_id = s.id AND is_correct IS NOT NULL)
+		FROM sessions s WHERE s.id = $1`, sessionID).Scan(&status, &policy, &adminPoints, &scoring)
+	if err != nil {
+		log.Printf("Late join: failed to load session %d: %v", sessionID, err)
+		return
+	}
+	if status != "active" || !scoring {
+		return
+	}
+
+	points := 0
+	switch policy {
+	case lateJoinAverage:
+		points, err = averageOtherTotals(sessionID, teamID, playerID)
+		if err != nil {
+			log.Printf("Late join: failed to average scores for session %d: %v", sessionID, err)
+			return
+		}
+	case lateJoinAdmin:
+		points = adminPoints
+	default:
+		policy = lateJoinZero
+	}
+
+	_, err = quizDB.Exec(`
+		INSERT INTO score_adjustments (session_id, team_id, player_id, reason, policy, points)
+		VALUES ($1, $2, $3, 'late_join', $4, $5)
+		ON CONFLICT DO NOTHING`,
+		sessionID, nullableIntVal(teamID), nullableIntVal(playerID), policy, points,
+	)
+	if err != nil {
+		log.Printf("Late join: failed to record adjustment for session %d: %v", sessionID, err)
+	}
+}
+
+// averageOtherTotals is the rounded average score (marked answers plus
+// adjustments) of the other teams, or other players in individual mode
+func averageOtherTotals(sessionID int, teamID, playerID *int) (int, error) {
+	var avg sql.NullFloat64
+	var err error
+	if teamID != nil {
+		err = quizDB.QueryRow(`
+			SELECT AVG(
+			         COALESCE((SELECT SUM(a.points) FROM answers a
+			                   JOIN session_players sp ON sp.id = a.player_id
+			                   WHERE sp.team_id = t.id AND a.session_id = $1 AND a.is_correct IS NOT NULL), 0)
+			       + COALESCE((SELECT SUM(points) FROM score_adjustments WHERE team_id = t.id), 0))
+			FROM teams t
+			WHERE t.session_id = $1 AND t.id <> $2
+			  AND EXISTS (SELECT 1 FROM session_players WHERE team_id = t.id)`,
+			sessionID, *teamID).Scan(&avg)
+	} else {
+		err = quizDB.QueryRow(`
+			SELECT AVG(
+			         COALESCE((SELECT SUM(points) FROM answers
+			                   WHERE player_id = sp.id AND is_correct IS NOT NULL), 0)
+			       + COALESCE((SELECT SUM(points) FROM score_adjustments WHERE player_id = sp.id), 0))
+			FROM session_players sp
+			WHERE sp.session_id = $1 AND sp.id <> $2`,
+			sessionID, *playerID).Scan(&avg)
+	}
+	if err != nil || !avg.Valid {
+		return 0, err
+	}
+	return int(math.Round(avg.Float64)), nil
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/achgithub/activity-hub-common/config"
+	"github.com/achgithub/activity-hub-common/events"
+	"github.com/achgithub/activity-hub-common/loadtest"
+	"github.com/gorilla/mux"
+)
+
+// Load test mode (LOAD_TEST=true) lets an operator fill a rehearsal quiz with
+// synthetic players before the big night. Each bot joins by the session's
+// join code as a guest, keeps the session stream open, answers every question
+// after a few seconds' thought and sometimes reacts between questions: what
+// a phone does, through the same routes. Run the quiz from quiz-master as
+// usual and watch the latencies here.
+//
+// Bots stay in the session afterwards, so use one set up for the test.
+
+const (
+	botThinkMin      = 2 * time.Second
+	botThinkMax      = 15 * time.Second
+	botReactChance   = 0.3
+	botReconnectWait = 3 * time.Second // What the player frontend waits too
+)
+
+var loadTests = loadtest.NewRunner()
+
+// loadTestURL is where bots send their requests: this backend directly, or
+// the gateway (LOAD_TEST_URL) to test the whole path from the pub's wifi
+func loadTestURL() string {
+	if u := config.GetEnv("LOAD_TEST_URL", ""); u != "" {
+		return strings.TrimRight(u, "/")
+	}
+	return "http://127.0.0.1:" + config.GetEnv("PORT", "4041")
+}
+
+// requireLoadTestAccess restricts load tests to setup_admin and super_user
+func requireLoadTestAccess(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, ok := authlib.GetUserFromContext(r.Context())
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if !user.HasRole("setup_admin") && !user.HasRole("super_user") {
+			http.Error(w, "Forbidden - setup_admin or super_user role required", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handleStartLoadTest - POST /api/loadtest
+// {"joinCode": "QUIZ42", "bots": 80, "rampUpSeconds": 30, "durationSeconds": 1800}
+func handleStartLoadTest(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		JoinCode        string `json:"joinCode"`
+		Bots            int    `json:"bots"`
+		RampUpSeconds   int    `json:"rampUpSeconds"`
+		DurationSeconds int    `json:"durationSeconds"` // 0 = until the quiz ends or it's stopped
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.JoinCode == "" {
+		http.Error(w, "joinCode and bots required", http.StatusBadRequest)
+		return
+	}
+
+	var status string
+	err := quizDB.QueryRow(`SELECT status FROM sessions WHERE join_code = $1`, body.JoinCode).Scan(&status)
+	if err != nil || status == "completed" {
+		http.Error(w, "No open session with that join code", http.StatusNotFound)
+		return
+	}
+
+	run, err := loadTests.Start(loadtest.Config{
+		Kind:     "quiz-player",
+		Bots:     body.Bots,
+		RampUp:   time.Duration(body.RampUpSeconds) * time.Second,
+		Duration: time.Duration(body.DurationSeconds) * time.Second,
+	}, quizBot(loadTestURL(), body.JoinCode))
+	if err == loadtest.ErrRunning {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, _ := authlib.GetUserFromContext(r.Context())
+	log.Printf("Load test %s: %d bots joining %s, started by %s", run.ID, body.Bots, body.JoinCode, user.ActorEmail())
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(run.Summary())
+}
+
+// handleListLoadTests - GET /api/loadtest
+func handleListLoadTests(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"runs": loadTests.List()})
+}
+
+// handleGetLoadTest - GET /api/loadtest/{id}
+func handleGetLoadTest(w http.ResponseWriter, r *http.Request) {
+	run, ok := loadTests.Get(mux.Vars(r)["id"])
+	if !ok {
+		http.Error(w, "Load test not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(run.Summary())
+}
+
+// handleStopLoadTest - POST /api/loadtest/{id}/stop
+func handleStopLoadTest(w http.ResponseWriter, r *http.Request) {
+	run, ok := loadTests.Get(mux.Vars(r)["id"])
+	if !ok {
+		http.Error(w, "Load test not found", http.StatusNotFound)
+		return
+	}
+	run.Stop()
+	<-run.Done()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(run.Summary())
+}
+
+// botPhase is the part of a phase_changed payload a bot needs
+type botPhase struct {
+	Phase      string     `json:"phase"`
+	RoundID    int        `json:"roundId"`
+	QuestionID int        `json:"questionId"`
+	Deadline   *time.Time `json:"deadline"`
+}
+
+// quizBot plays one synthetic player in the session with joinCode
+func quizBot(baseURL, joinCode string) loadtest.Bot {
+	return func(ctx context.Context, g loadtest.Guest, rec *loadtest.Recorder) error {
+		c := loadtest.NewClient(baseURL, g.Token, rec)
+
+		var joined struct {
+			SessionID int    `json:"sessionId"`
+			Mode      string `json:"mode"`
+		}
+		if err := c.Do(ctx, "join", "POST", "/api/sessions/join", map[string]string{"joinCode": joinCode}, &joined); err != nil {
+			return err
+		}
+		if joined.Mode == "team" {
+			// A team each, so every bot answers
+			team := map[string]interface{}{"sessionId": joined.SessionID, "teamName": g.Name}
+			if err := c.Do(ctx, "join team", "POST", "/api/sessions/join-team", team, nil); err != nil {
+				return err
+			}
+		}
+
+		session := fmt.Sprintf("/api/sessions/%d", joined.SessionID)
+		photoQuestions := map[int]bool{}
+		ended := false
+		for ctx.Err() == nil && !ended {
+			if err := c.Do(ctx, "state", "GET", session+"/state", nil, nil); err != nil {
+				return err
+			}
+
+			token, err := authlib.MintSignedToken(&authlib.AuthUser{Email: g.Email}, authlib.PurposeStream, authlib.StreamTokenTTL)
+			if err != nil {
+				token = g.Token
+			}
+			c.Stream(ctx, "stream", session+"/stream?token="+url.QueryEscape(token), func(e events.Envelope) bool {
+				switch e.Type {
+				case "question_precache":
+					var q struct {
+						QuestionID  int  `json:"questionId"`
+						PhotoAnswer bool `json:"photoAnswer"`
+					}
+					if json.Unmarshal(e.Payload, &q) == nil && q.PhotoAnswer {
+						photoQuestions[q.QuestionID] = true
+					}
+				case "phase_changed":
+					var p botPhase
+					if json.Unmarshal(e.Payload, &p) != nil {
+						return true
+					}
+					if p.Phase == "answers_open" && !photoQuestions[p.QuestionID] {
+						go botAnswer(ctx, c, session, p)
+					} else if p.Phase != "revealed" && p.Phase != "answers_open" && rand.Float64() < botReactChance {
+						go botReact(ctx, c, session)
+					}
+				case "quiz_ended":
+					ended = true
+					return false
+				}
+				return true
+			})
+
+			if !ended {
+				sleepCtx(ctx, botReconnectWait)
+			}
+		}
+		return nil
+	}
+}
+
+// botAnswer answers an open question after thinking about it, inside any
+// time limit
+func botAnswer(ctx context.Context, c *loadtest.Client, session string, p botPhase) {
+	think := botThinkMin + time.Duration(rand.Int63n(int64(botThinkMax-botThinkMin)))
+	if p.Deadline != nil {
+		if left := time.Until(*p.Deadline) - time.Second; left < think {
+			think = left
+		}
+	}
+	if think > 0 && !sleepCtx(ctx, think) {
+		return
+	}
+	c.Do(ctx, "answer", "POST", session+"/answer", map[string]interface{}{
+		"roundId":    p.RoundID,
+		"questionId": p.QuestionID,
+		"answerText": fmt.Sprintf("Bot answer %d", rand.Intn(1000)),
+	}, nil)
+}
+
+// botReact sends a reaction a moment after the question moves on
+func botReact(ctx context.Context, c *loadtest.Client, session string) {
+	if !sleepCtx(ctx, time.Duration(rand.Int63n(int64(3*time.Second)))) {
+		return
+	}
+	emojis := []string{"👏", "🎉", "😂", "😮", "🔥", "😬"}
+	c.Do(ctx, "react", "POST", session+"/react", map[string]string{"emoji": emojis[rand.Intn(len(emojis))]}, nil)
+}
+
+// sleepCtx waits for d, reporting false if ctx ended first
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
@@ -2,13 +2,21 @@ package main
 
 import (
 	"database/sql"
+	"embed"
 	"log"
 	"net/http"
 
 	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/achgithub/activity-hub-common/cli"
 	"github.com/achgithub/activity-hub-common/config"
 	"github.com/achgithub/activity-hub-common/database"
-	"github.com/gorilla/handlers"
+	"github.com/achgithub/activity-hub-common/events"
+	"github.com/achgithub/activity-hub-common/hours"
+	apphttp "github.com/achgithub/activity-hub-common/http"
+	"github.com/achgithub/activity-hub-common/i18n"
+	"github.com/achgithub/activity-hub-common/loadtest"
+	"github.com/achgithub/activity-hub-common/maintenance"
+	"github.com/achgithub/activity-hub-common/services"
 	"github.com/gorilla/mux"
 )
 
@@ -17,7 +25,27 @@ var (
 	identityDB *sql.DB
 )
 
+// Player-facing messages, in addition to the platform's built into i18n
+//
+//go:embed i18n/*.json
+var messages embed.FS
+
 func main() {
+	cli.New("quiz-player",
+		cli.Serve(serve),
+		cli.Migrate(openAppDB, cli.Migrations{
+			Dir:    "../database",
+			Schema: []string{"schema.sql"},
+		}),
+	).Main()
+}
+
+// openAppDB connects to quiz_db for commands other than serve
+func openAppDB() (*sql.DB, error) {
+	return database.InitDatabaseByName("quiz_db")
+}
+
+func serve() {
 	var err error
 	identityDB, err = database.InitIdentityDatabase()
 	if err != nil {
@@ -32,21 +60,56 @@ func main() {
 	defer quizDB.Close()
 
 	initRedis()
+	initSSEBroker()
+
+	if err := i18n.LoadFS(messages, "i18n"); err != nil {
+		log.Fatal("Failed to load message catalogs:", err)
+	}
+
+	// Delete answer photos past the retention period, outside opening hours
+	go runPhotoPurge(hours.New(identityDB))
+
+	// Player reactions reach the big screen as an applause meter
+	go runApplauseMeter()
 
 	r := mux.NewRouter()
 
 	// Public config
 	r.HandleFunc("/api/config", handleConfig).Methods("GET")
+	r.HandleFunc("/api/events/schema", events.SchemaHandler).Methods("GET")
+	r.HandleFunc("/api/openapi.json", apiSpec().Handler).Methods("GET")
+
+	// Quiz nights for the identity shell's email digest (service token)
+	r.Handle("/api/digest", authlib.ServiceMiddleware(services.ShellApp)(http.HandlerFunc(handleDigest))).Methods("POST")
 
 	// Authenticated routes
 	api := r.PathPrefix("/api").Subrouter()
 	api.Use(authlib.Middleware(identityDB))
 
 	api.HandleFunc("/sessions/active", handleGetActiveSessions).Methods("GET")
+	api.HandleFunc("/accessibility", handleGetAccessibility).Methods("GET")
+	api.HandleFunc("/accessibility", handleSetAccessibility).Methods("PUT")
 	api.HandleFunc("/sessions/join", handleJoinSession).Methods("POST")
 	api.HandleFunc("/sessions/join-team", handleJoinTeam).Methods("POST")
 	api.HandleFunc("/sessions/{id}/state", handleGetSessionState).Methods("GET")
+	api.HandleFunc("/sessions/{id}/captain", handleSetCaptain).Methods("POST")
 	api.HandleFunc("/sessions/{id}/answer", handleSubmitAnswer).Methods("POST")
+	api.HandleFunc("/sessions/{id}/answers/{questionId}", handleEditAnswer).Methods("PATCH")
+	api.HandleFunc("/sessions/{id}/answers/{questionId}/draft", handleSaveDraft).Methods("PUT")
+	api.HandleFunc("/sessions/{id}/answers/{questionId}/photo", handleSubmitPhotoAnswer).Methods("POST")
+	api.HandleFunc("/sessions/{id}/tiebreak-answer", handleSubmitTiebreakAnswer).Methods("POST")
+	api.HandleFunc("/sessions/{id}/react", handleReact).Methods("POST")
+
+	// Synthetic players for rehearsing a busy night (setup_admin / super_user only)
+	if loadtest.Enabled() {
+		lt := api.PathPrefix("/loadtest").Subrouter()
+		lt.Use(requireLoadTestAccess)
+		lt.HandleFunc("", handleStartLoadTest).Methods("POST")
+		lt.HandleFunc("", handleListLoadTests).Methods("GET")
+		lt.HandleFunc("/{id}", handleGetLoadTest).Methods("GET")
+		lt.HandleFunc("/{id}/stop", handleStopLoadTest).Methods("POST")
+		log.Printf("Load test mode on: POST /api/loadtest starts synthetic players")
+	}
 
 	// SSE stream uses query-param auth
 	r.Handle("/api/sessions/{id}/stream",
@@ -58,13 +121,10 @@ func main() {
 		http.ServeFile(w, r, "./static/index.html")
 	})
 
-	corsHandler := handlers.CORS(
-		handlers.AllowedOrigins([]string{"*"}),
-		handlers.AllowedMethods([]string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}),
-		handlers.AllowedHeaders([]string{"Content-Type", "Authorization"}),
-	)
+	cors := apphttp.NewCORSPolicy(identityDB)
+	appMaintenance := maintenance.New(identityDB)
 
 	port := config.GetEnv("PORT", "4041")
 	log.Printf("Quiz Player starting on :%s", port)
-	log.Fatal(http.ListenAndServe(":"+port, corsHandler(r)))
+	log.Fatal(http.ListenAndServe(":"+port, cors.Middleware(appMaintenance.Middleware("quiz-player")(apphttp.Versioned(r)))))
 }
@@ -40,3 +40,15 @@ type SSEEvent struct {
 	Type    string      `json:"type"`
 	Payload interface{} `json:"payload"`
 }
+
+// SessionPhase is the current question phase written by quiz-master (session_phase)
+type SessionPhase struct {
+	Phase          string     `json:"phase"`
+	RoundID        int        `json:"roundId,omitempty"`
+	QuestionID     int        `json:"questionId,omitempty"`
+	RoundNumber    int        `json:"roundNumber,omitempty"`
+	QuestionNumber int        `json:"questionNumber,omitempty"`
+	ChangedAt      time.Time  `json:"changedAt"`
+	Deadline       *time.Time `json:"deadline,omitempty"`
+	ServerTime     time.Time  `json:"serverTime"`
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/achgithub/activity-hub-common/contentfilter"
+	"github.com/lib/pq"
+)
+
+// publicTeamNameSQL is a team's name (alias t) as shown to players and on the
+// big screen: masked while flagged, a placeholder once a quiz master rejects it
+const publicTeamNameSQL = `CASE t.moderation
+	WHEN 'flagged' THEN COALESCE(t.masked_name, t.name)
+	WHEN 'rejected' THEN 'Team ' || t.id
+	ELSE t.name END`
+
+var (
+	errTeamNameBlocked = errors.New("team name blocked")
+	errNoNewTeams      = errors.New("session doesn't take new teams")
+)
+
+// screenedText is player-entered text (a team name or answer) after the
+// content filter for the session's venue
+type screenedText struct {
+	Words   []string // Word-list hits; nil when clean
+	Masked  string   // Shown publicly until a quiz master approves it
+	Blocked bool     // The venue refuses matching text instead of flagging it
+}
+
+// Column values for flagged_words, masked text and moderation (all NULL when clean)
+func (s screenedText) flaggedWords() interface{} {
+	if len(s.Words) == 0 {
+		return nil
+	}
+	return pq.Array(s.Words)
+}
+
+func (s screenedText) maskedText() interface{} {
+	if len(s.Words) == 0 {
+		return nil
+	}
+	return s.Masked
+}
+
+func (s screenedText) moderation() interface{} {
+	if len(s.Words) == 0 {
+		return nil
+	}
+	return "flagged"
+}
+
+// screenText checks text against the chain-wide word list plus the session's
+// venue list. The venue's action (falling back to the chain-wide one) decides
+// between flagging (default) and blocking.
+func screenText(sessionID int, text string) (screenedText, error) {
+	var venueID int
+	var action string
+	err := quizDB.QueryRow(`
+		SELECT s.venue_id,
+		       COALESCE((SELECT action FROM content_filter_settings WHERE venue_id = s.venue_id),
+		                (SELECT action FROM content_filter_settings WHERE venue_id = 0),
+		                'flag')
+		FROM sessions s WHERE s.id = $1`, sessionID).Scan(&venueID, &action)
+	if err != nil {
+		return screenedText{}, err
+	}
+
+	rows, err := quizDB.Query(`SELECT word FROM content_filter_words WHERE venue_id IN (0, $1)`, venueID)
+	if err != nil {
+		return screenedText{}, err
+	}
+	defer rows.Close()
+	words := []string{}
+	for rows.Next() {
+		var w string
+		if err := rows.Scan(&w); err == nil {
+			words = append(words, w)
+		}
+	}
+
+	f := contentfilter.New(words)
+	hits := f.Words(text)
+	if len(hits) == 0 {
+		return screenedText{Masked: text}, nil
+	}
+	return screenedText{
+		Words:   hits,
+		Masked:  f.Mask(text),
+		Blocked: action == contentfilter.ActionBlock,
+	}, nil
+}
+
+// createPlayerTeam starts a new team named by a player (team mode only)
+func createPlayerTeam(sessionID int, name, createdBy string) (int, error) {
+	var mode, status string
+	if err := quizDB.QueryRow(`SELECT mode, status FROM sessions WHERE id = $1`, sessionID).Scan(&mode, &status); err != nil {
+		return 0, err
+	}
+	if mode != "team" || status == "completed" {
+		return 0, errNoNewTeams
+	}
+
+	screened, err := screenText(sessionID, name)
+	if err != nil {
+		return 0, err
+	}
+	if screened.Blocked {
+		return 0, errTeamNameBlocked
+	}
+
+	code, err := generateCode(4)
+	if err != nil {
+		return 0, err
+	}
+	var teamID int
+	err = quizDB.QueryRow(`
+		INSERT INTO teams (session_id, name, join_code, created_by, flagged_words, masked_name, moderation)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`,
+		sessionID, name, code, createdBy,
+		screened.flaggedWords(), screened.maskedText(), screened.moderation(),
+	).Scan(&teamID)
+	return teamID, err
+}
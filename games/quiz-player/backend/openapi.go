@@ -0,0 +1,96 @@
+package main
+
+import (
+	"net/http"
+
+	apphttp "github.com/achgithub/activity-hub-common/http"
+	"github.com/achgithub/activity-hub-common/loadtest"
+	"github.com/achgithub/activity-hub-common/openapi"
+	"github.com/achgithub/activity-hub-common/services"
+)
+
+// apiSpec describes the routes registered in main.go for GET /api/openapi.json.
+// Add a route here whenever one is added there.
+func apiSpec() *openapi.Spec {
+	spec := openapi.New("quiz-player", "1.0.0",
+		"Player side of pub quizzes: joining a session and team, answering, and the live session stream")
+
+	public := spec.Group("Public")
+	public.Route("GET", "/api/config", "App configuration").
+		Returns(http.StatusOK, apphttp.AppConfig{})
+	public.Route("GET", "/api/events/schema", "JSON Schema of the session stream events").
+		Produces(http.StatusOK, "application/schema+json")
+	public.Route("GET", "/api/openapi.json", "This document").
+		Returns(http.StatusOK, nil)
+
+	// Called by the identity shell with a service token
+	digest := spec.Group("Digest").Auth()
+	digest.Route("POST", "/api/digest", "Scheduled quiz nights for the email digest").
+		Body(services.DigestRequest{}).
+		Returns(http.StatusOK, services.Digest{})
+
+	sessions := spec.Group("Sessions").Auth()
+	sessions.Route("GET", "/api/sessions/active", "Sessions open to join").
+		Returns(http.StatusOK, openapi.Fields{"sessions": []Session{}})
+	sessions.Route("POST", "/api/sessions/join", "Join a session by its code").
+		Body(openapi.Fields{"joinCode": ""}).
+		Returns(http.StatusOK, openapi.Fields{
+			"sessionId": 0, "sessionName": "", "mode": "", "status": "", "playerId": 0, "teams": []Team{},
+		})
+	sessions.Route("POST", "/api/sessions/join-team", "Join a team by code, or start one by name").
+		Body(openapi.Fields{"sessionId": 0, "teamCode": "", "teamName": ""}).
+		Returns(http.StatusOK, openapi.Fields{"teamId": 0})
+	sessions.Route("GET", "/api/sessions/{id}/state", "The caller's view of a session, for resuming after a reload").
+		Returns(http.StatusOK, openapi.Fields{
+			"session": Session{}, "teams": []Team{}, "myTeamId": (*int)(nil), "myPlayer": &SessionPlayer{},
+			"phase": &SessionPhase{}, "tiebreak": &Tiebreak{}, "myAnswer": &MyAnswer{}, "captain": &TeamCaptain{},
+			"accessibility": Accessibility{},
+		})
+	sessions.Route("POST", "/api/sessions/{id}/captain", "Hand the team captaincy to a teammate, or to yourself on this device (X-Device-ID)").
+		Body(openapi.Fields{"playerId": 0}).
+		Returns(http.StatusOK, openapi.Fields{"captain": TeamCaptain{}})
+	sessions.Route("GET", "/api/sessions/{id}/stream", "Session events (token in the query string)").
+		Stream()
+
+	accessibility := spec.Group("Accessibility").Auth()
+	accessibility.Route("GET", "/api/accessibility", "The caller's own accessibility settings (null follows the session)").
+		Returns(http.StatusOK, openapi.Fields{"accessibility": PlayerAccessibility{}})
+	accessibility.Route("PUT", "/api/accessibility", "Replace the caller's accessibility settings, for every quiz they join").
+		Body(PlayerAccessibility{}).
+		Returns(http.StatusOK, openapi.Fields{"accessibility": PlayerAccessibility{}})
+
+	answers := spec.Group("Answers").Auth()
+	answers.Route("POST", "/api/sessions/{id}/answer", "Answer the open question; submitting again replaces the answer").
+		Body(openapi.Fields{"roundId": 0, "questionId": 0, "answerText": ""}).
+		Returns(http.StatusOK, openapi.Fields{"status": "", "changed": false, "answer": MyAnswer{}})
+	answers.Route("PATCH", "/api/sessions/{id}/answers/{questionId}", "Change the caller's answer until answers close").
+		Body(openapi.Fields{"answerText": ""}).
+		Returns(http.StatusOK, openapi.Fields{"status": "", "changed": false, "answer": MyAnswer{}})
+	answers.Route("PUT", "/api/sessions/{id}/answers/{questionId}/draft", "Autosave a draft answer (marked as-is if answers close first)").
+		Body(openapi.Fields{"answerText": ""}).
+		Returns(http.StatusOK, openapi.Fields{"status": "", "changed": false, "answer": MyAnswer{}})
+	answers.Route("POST", "/api/sessions/{id}/answers/{questionId}/photo", "Answer a photo round question with an image (multipart \"file\", ?roundId=)").
+		Returns(http.StatusOK, openapi.Fields{"status": "", "changed": false, "answer": MyAnswer{}})
+	answers.Route("POST", "/api/sessions/{id}/tiebreak-answer", "Answer the open tie-break").
+		Body(openapi.Fields{"tiebreakId": 0, "answerText": ""}).
+		Returns(http.StatusOK, openapi.Fields{"status": ""})
+	answers.Route("POST", "/api/sessions/{id}/react", "Send an emoji reaction between questions (👏 🎉 😂 😮 🔥 😬; 5 per 5 seconds)").
+		Body(openapi.Fields{"emoji": ""}).
+		Returns(http.StatusOK, openapi.Fields{"status": ""})
+
+	// Only registered with LOAD_TEST=true; setup_admin or super_user
+	if loadtest.Enabled() {
+		lt := spec.Group("Load test").Auth()
+		lt.Route("POST", "/api/loadtest", "Fill a session with synthetic players (409 while another run is going)").
+			Body(openapi.Fields{"joinCode": "", "bots": 0, "rampUpSeconds": 0, "durationSeconds": 0}).
+			Returns(http.StatusAccepted, loadtest.Summary{})
+		lt.Route("GET", "/api/loadtest", "Recent load test runs, newest first").
+			Returns(http.StatusOK, openapi.Fields{"runs": []loadtest.Summary{}})
+		lt.Route("GET", "/api/loadtest/{id}", "A run's latency and error summary so far").
+			Returns(http.StatusOK, loadtest.Summary{})
+		lt.Route("POST", "/api/loadtest/{id}/stop", "Stop a run and wait for its bots").
+			Returns(http.StatusOK, loadtest.Summary{})
+	}
+
+	return spec
+}
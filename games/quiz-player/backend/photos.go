@@ -0,0 +1,232 @@
+package main
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/achgithub/activity-hub-common/config"
+	"github.com/achgithub/activity-hub-common/hours"
+	"github.com/achgithub/activity-hub-common/i18n"
+	"github.com/achgithub/activity-hub-common/upload"
+	"github.com/gorilla/mux"
+)
+
+// In photo rounds (rounds.type = 'photo') players answer with a picture, e.g.
+// "draw the logo". Photos are stored under ANSWER_MEDIA_DIR/{sessionId}/,
+// outside the public uploads directory; quiz-master reads the same directory
+// to show them to markers. Every photo a player sends is kept with its answer
+// revision until the session's photos are purged, ANSWER_PHOTO_RETENTION_DAYS
+// (default 14) after it ends.
+
+const (
+	defaultPhotoRetentionDays = 14
+	maxSessionPhotoBytes      = 500 << 20
+)
+
+// photoAnswerPolicy is what a photo answer may be. The frontend shrinks photos
+// before sending them, so the cap only bites on unshrunk uploads.
+var photoAnswerPolicy = upload.Policy{Kinds: []upload.Kind{upload.Image}, MaxBytes: 4 << 20}
+
+var answerMediaDir = config.GetEnv("ANSWER_MEDIA_DIR", "./answer-media")
+
+func photoRetentionDays() int {
+	days, err := strconv.Atoi(os.Getenv("ANSWER_PHOTO_RETENTION_DAYS"))
+	if err != nil || days < 1 {
+		return defaultPhotoRetentionDays
+	}
+	return days
+}
+
+// handleSubmitPhotoAnswer - POST /api/sessions/{id}/answers/{questionId}/photo?roundId=1
+// Multipart form with the image in "file". Sending another photo replaces the
+// answer, like editing a text answer.
+func handleSubmitPhotoAnswer(w http.ResponseWriter, r *http.Request) {
+	questionID, err := strconv.Atoi(mux.Vars(r)["questionId"])
+	if err != nil {
+		i18n.Error(w, r, "invalid_question_id", http.StatusBadRequest)
+		return
+	}
+	roundID, _ := strconv.Atoi(r.URL.Query().Get("roundId"))
+
+	slot, ok := openAnswerSlot(w, r, roundID, questionID)
+	if !ok {
+		return
+	}
+
+	var roundType string
+	err = quizDB.QueryRow(`SELECT type FROM rounds WHERE id = $1`, slot.RoundID).Scan(&roundType)
+	if err != nil && err != sql.ErrNoRows {
+		i18n.Error(w, r, "database_error", http.StatusInternalServerError)
+		return
+	}
+	if roundType != "photo" {
+		i18n.Error(w, r, "answer_written_required", http.StatusBadRequest)
+		return
+	}
+
+	var used int64
+	err = quizDB.QueryRow(`
+		SELECT COALESCE(SUM(ar.photo_bytes), 0) FROM answer_revisions ar
+		JOIN answers a ON a.id = ar.answer_id
+		WHERE a.session_id = $1 AND ar.photo_path IS NOT NULL`, slot.SessionID).Scan(&used)
+	if err != nil {
+		i18n.Error(w, r, "database_error", http.StatusInternalServerError)
+		return
+	}
+	if used >= maxSessionPhotoBytes {
+		i18n.Error(w, r, "photo_wall_full", http.StatusInsufficientStorage)
+		return
+	}
+
+	file, err := upload.Read(w, r, "file", photoAnswerPolicy)
+	if err != nil {
+		http.Error(w, fmt.Sprintf(`{"error":%q}`, err.Error()), upload.StatusCode(err))
+		return
+	}
+
+	relPath, err := storeAnswerPhoto(slot, file)
+	if err != nil {
+		log.Printf("Failed to store answer photo: %v", err)
+		i18n.Error(w, r, "photo_save_failed", http.StatusInternalServerError)
+		return
+	}
+
+	a, err := savePhotoAnswer(slot, relPath, len(file.Data))
+	if err != nil {
+		os.Remove(filepath.Join(answerMediaDir, relPath))
+		i18n.Error(w, r, "database_error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  "submitted",
+		"changed": true,
+		"answer":  a,
+	})
+}
+
+// storeAnswerPhoto writes the photo under the session's directory and returns
+// its path relative to answerMediaDir
+func storeAnswerPhoto(slot *answerSlot, file *upload.File) (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	dir := strconv.Itoa(slot.SessionID)
+	if err := os.MkdirAll(filepath.Join(answerMediaDir, dir), 0750); err != nil {
+		return "", err
+	}
+	relPath := filepath.Join(dir, fmt.Sprintf("%d-%s%s", slot.PlayerID, hex.EncodeToString(b), file.Ext))
+	if err := os.WriteFile(filepath.Join(answerMediaDir, relPath), file.Data, 0640); err != nil {
+		return "", err
+	}
+	return relPath, nil
+}
+
+// savePhotoAnswer makes the photo the player's answer. It's a new revision
+// and clears any mark, like a changed text answer.
+func savePhotoAnswer(slot *answerSlot, relPath string, size int) (*MyAnswer, error) {
+	tx, err := quizDB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	a := MyAnswer{QuestionID: slot.QuestionID, HasPhoto: true}
+	var answerID int
+	err = tx.QueryRow(`
+		INSERT INTO answers (session_id, round_id, question_id, team_id, player_id, photo_path, photo_bytes, is_draft)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, FALSE)
+		ON CONFLICT (session_id, question_id, player_id) DO UPDATE
+		SET photo_path = EXCLUDED.photo_path, photo_bytes = EXCLUDED.photo_bytes,
+		    round_id = EXCLUDED.round_id, team_id = EXCLUDED.team_id, is_draft = FALSE,
+		    revision = answers.revision + 1, submitted_at = NOW(),
+		    is_correct = NULL, points = 0, marked_at = NULL
+		RETURNING id, revision, COALESCE(answer_text, '')`,
+		slot.SessionID, slot.RoundID, slot.QuestionID, nullableIntVal(slot.TeamID), slot.PlayerID, relPath, size,
+	).Scan(&answerID, &a.Revision, &a.AnswerText)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO answer_revisions (answer_id, revision, answer_text, is_draft, photo_path, photo_bytes)
+		VALUES ($1, $2, NULLIF($3, ''), FALSE, $4, $5)
+		ON CONFLICT DO NOTHING`,
+		answerID, a.Revision, a.AnswerText, relPath, size)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// runPhotoPurge deletes answer photos past the retention period, on startup
+// and then hourly, waiting while any venue is open.
+func runPhotoPurge(openingHours *hours.Hours) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		if !openingHours.Peak(time.Now()) {
+			purgeAnswerPhotos()
+		}
+		<-ticker.C
+	}
+}
+
+// purgeAnswerPhotos removes the photo directories of sessions that ended
+// before the cutoff (or never ended, counting from when they were created).
+// The answers stay, marked with when their photo went.
+func purgeAnswerPhotos() {
+	cutoff := time.Now().AddDate(0, 0, -photoRetentionDays())
+
+	rows, err := quizDB.Query(`
+		SELECT DISTINCT s.id FROM sessions s
+		JOIN answers a ON a.session_id = s.id
+		JOIN answer_revisions ar ON ar.answer_id = a.id
+		WHERE ar.photo_path IS NOT NULL AND COALESCE(s.completed_at, s.created_at) < $1`, cutoff)
+	if err != nil {
+		log.Printf("Failed to find answer photos to purge: %v", err)
+		return
+	}
+	var sessionIDs []int
+	for rows.Next() {
+		var id int
+		if rows.Scan(&id) == nil {
+			sessionIDs = append(sessionIDs, id)
+		}
+	}
+	rows.Close()
+
+	for _, id := range sessionIDs {
+		if err := os.RemoveAll(filepath.Join(answerMediaDir, strconv.Itoa(id))); err != nil {
+			log.Printf("Failed to delete answer photos for session %d: %v", id, err)
+			continue
+		}
+		_, err := quizDB.Exec(`
+			UPDATE answer_revisions SET photo_path = NULL
+			WHERE photo_path IS NOT NULL AND answer_id IN (SELECT id FROM answers WHERE session_id = $1)`, id)
+		if err == nil {
+			_, err = quizDB.Exec(`
+				UPDATE answers SET photo_path = NULL, photo_purged_at = NOW()
+				WHERE session_id = $1 AND photo_path IS NOT NULL`, id)
+		}
+		if err != nil {
+			log.Printf("Failed to clear purged answer photos for session %d: %v", id, err)
+			continue
+		}
+		log.Printf("🗑️ Purged answer photos for session %d", id)
+	}
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/achgithub/activity-hub-common/i18n"
+	"github.com/go-redis/redis/v8"
+	"github.com/gorilla/mux"
+)
+
+// Players can send emoji reactions between questions. They're counted in
+// Redis and sent to the session stream once a second as an applause meter for
+// the big screen: each reaction adds to the meter's heat, which halves every
+// second, so a burst from the whole pub fills it and it dies away after.
+// Reactions can reach any replica; each second one replica (whichever claims
+// the tick) folds them into the meters.
+
+// reactionEmojis are the reactions a player can send
+var reactionEmojis = map[string]bool{
+	"👏": true, "🎉": true, "😂": true, "😮": true, "🔥": true, "😬": true,
+}
+
+const (
+	reactionHeat     = 10.0 // meter points per reaction
+	applauseDecay    = 0.5  // share of the heat left after each second
+	maxReactions     = 5    // reactions per player...
+	reactionWindow   = 5 * time.Second
+	applauseInterval = time.Second
+	applauseKeyTTL   = time.Minute // meters of sessions nobody ticks any more
+)
+
+// applauseSessionsKey is the set of sessions with a live meter
+const applauseSessionsKey = "quiz:applause:sessions"
+
+func applauseCountsKey(sessionID int) string {
+	return fmt.Sprintf("quiz:session:%d:applause:counts", sessionID)
+}
+
+func applauseHeatKey(sessionID int) string {
+	return fmt.Sprintf("quiz:session:%d:applause:heat", sessionID)
+}
+
+// addApplause counts a reaction towards the session's next tick
+func addApplause(sessionID int, emoji string) error {
+	ctx := context.Background()
+	pipe := redisClient.TxPipeline()
+	pipe.HIncrBy(ctx, applauseCountsKey(sessionID), emoji, 1)
+	pipe.Expire(ctx, applauseCountsKey(sessionID), applauseKeyTTL)
+	pipe.SAdd(ctx, applauseSessionsKey, sessionID)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// tickApplause folds the last second's reactions into a session's meter and
+// returns what to send. A meter that has died down is sent once more at zero,
+// then dropped.
+func tickApplause(sessionID int) (Applause, error) {
+	ctx := context.Background()
+	pipe := redisClient.TxPipeline()
+	countsCmd := pipe.HGetAll(ctx, applauseCountsKey(sessionID))
+	pipe.Del(ctx, applauseCountsKey(sessionID))
+	heatCmd := pipe.Get(ctx, applauseHeatKey(sessionID))
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return Applause{}, err
+	}
+
+	counts := map[string]int{}
+	total := 0
+	for emoji, v := range countsCmd.Val() {
+		n, _ := strconv.Atoi(v)
+		counts[emoji] = n
+		total += n
+	}
+	heat, _ := strconv.ParseFloat(heatCmd.Val(), 64)
+	heat = heat*applauseDecay + float64(total)*reactionHeat
+	level := int(math.Round(math.Min(heat, 100)))
+
+	if level == 0 {
+		pipe := redisClient.TxPipeline()
+		pipe.Del(ctx, applauseHeatKey(sessionID))
+		pipe.SRem(ctx, applauseSessionsKey, sessionID)
+		_, err := pipe.Exec(ctx)
+		return Applause{Level: 0, Reactions: counts, Total: total}, err
+	}
+	err := redisClient.Set(ctx, applauseHeatKey(sessionID), heat, applauseKeyTTL).Err()
+	return Applause{Level: level, Reactions: counts, Total: total}, err
+}
+
+// claimApplauseTick reports whether this replica runs the meters this second
+func claimApplauseTick(now time.Time) bool {
+	key := fmt.Sprintf("quiz:applause:tick:%d", now.Unix())
+	ok, err := redisClient.SetNX(context.Background(), key, 1, 5*applauseInterval).Result()
+	return err == nil && ok
+}
+
+// runApplauseMeter publishes every active meter once a second
+func runApplauseMeter() {
+	ticker := time.NewTicker(applauseInterval)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		if !claimApplauseTick(now) {
+			continue
+		}
+		sessions, err := redisClient.SMembers(context.Background(), applauseSessionsKey).Result()
+		if err != nil {
+			continue
+		}
+		for _, s := range sessions {
+			sessionID, _ := strconv.Atoi(s)
+			a, err := tickApplause(sessionID)
+			if err != nil {
+				log.Printf("Failed to update applause for session %d: %v", sessionID, err)
+				continue
+			}
+			if err := publishEvent(evApplause.New(sessionKey(sessionID), a)); err != nil {
+				log.Printf("Failed to publish applause for session %d: %v", sessionID, err)
+			}
+		}
+	}
+}
+
+// allowReaction counts a player's reaction in Redis and reports whether it's
+// within maxReactions per reactionWindow. If Redis is down reactions are let
+// through; the meter caps at 100 whatever happens.
+func allowReaction(sessionID, playerID int) bool {
+	ctx := context.Background()
+	key := fmt.Sprintf("quiz:session:%d:reactions:%d", sessionID, playerID)
+	n, err := redisClient.Incr(ctx, key).Result()
+	if err != nil {
+		return true
+	}
+	if n == 1 {
+		redisClient.Expire(ctx, key, reactionWindow)
+	}
+	return n <= maxReactions
+}
+
+// handleReact - POST /api/sessions/{id}/react {"emoji": "👏"}
+// Reactions are taken between questions, not while one is on screen.
+func handleReact(w http.ResponseWriter, r *http.Request) {
+	user, ok := authlib.GetUserFromContext(r.Context())
+	if !ok {
+		i18n.Error(w, r, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	sessionID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		i18n.Error(w, r, "invalid_session_id", http.StatusBadRequest)
+		return
+	}
+
+	var body struct {
+		Emoji string `json:"emoji"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		i18n.Error(w, r, "invalid_request", http.StatusBadRequest)
+		return
+	}
+	if !reactionEmojis[body.Emoji] {
+		i18n.Error(w, r, "reaction_unknown", http.StatusBadRequest)
+		return
+	}
+
+	var playerID int
+	err = quizDB.QueryRow(`
+		SELECT sp.id FROM session_players sp
+		JOIN sessions s ON s.id = sp.session_id
+		WHERE sp.session_id = $1 AND sp.user_email = $2 AND s.status = 'active'`,
+		sessionID, user.Email).Scan(&playerID)
+	if err == sql.ErrNoRows {
+		i18n.Error(w, r, "not_in_session", http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		i18n.Error(w, r, "database_error", http.StatusInternalServerError)
+		return
+	}
+
+	phase, err := getSessionPhase(sessionID)
+	if err != nil {
+		i18n.Error(w, r, "database_error", http.StatusInternalServerError)
+		return
+	}
+	if phase != nil && (phase.Phase == "revealed" || phase.Phase == "answers_open") {
+		i18n.Error(w, r, "reactions_closed", http.StatusConflict)
+		return
+	}
+
+	if !allowReaction(sessionID, playerID) {
+		i18n.Error(w, r, "reaction_too_fast", http.StatusTooManyRequests)
+		return
+	}
+
+	if err := addApplause(sessionID, body.Emoji); err != nil {
+		log.Printf("Failed to count reaction for session %d: %v", sessionID, err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
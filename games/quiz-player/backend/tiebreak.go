@@ -0,0 +1,147 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/achgithub/activity-hub-common/i18n"
+	"github.com/gorilla/mux"
+	"github.com/lib/pq"
+)
+
+// Tiebreak is a quiz-master tie-break mini-round as seen by a player.
+// Only the tied teams (entityIds) can answer; everyone else watches.
+type Tiebreak struct {
+	ID        int    `json:"id"`
+	Kind      string `json:"kind"` // nearest | sudden_death
+	Question  string `json:"question"`
+	EntityIDs []int  `json:"entityIds"`
+	Status    string `json:"status"` // open | closed
+	CanAnswer bool   `json:"canAnswer"`
+	MyAnswer  string `json:"myAnswer,omitempty"`
+}
+
+// getCurrentTiebreak returns the session's unresolved tie-break, if any, for
+// the player's team (or the player, in individual mode) entityID
+func getCurrentTiebreak(sessionID, entityID int) (*Tiebreak, error) {
+	var tb Tiebreak
+	var entityIDs pq.Int64Array
+	err := quizDB.QueryRow(`
+		SELECT tb.id, tb.kind, q.text, tb.entity_ids, tb.status,
+		       COALESCE((SELECT answer_text FROM tiebreak_answers WHERE tiebreak_id = tb.id AND entity_id = $2), '')
+		FROM tiebreaks tb
+		JOIN questions q ON q.id = tb.question_id
+		WHERE tb.session_id = $1 AND tb.status <> 'resolved'
+		ORDER BY tb.id DESC LIMIT 1`, sessionID, entityID,
+	).Scan(&tb.ID, &tb.Kind, &tb.Question, &entityIDs, &tb.Status, &tb.MyAnswer)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range entityIDs {
+		tb.EntityIDs = append(tb.EntityIDs, int(id))
+		if int(id) == entityID && tb.Status == "open" {
+			tb.CanAnswer = true
+		}
+	}
+	return &tb, nil
+}
+
+// handleSubmitTiebreakAnswer - POST /api/sessions/{id}/tiebreak-answer {"tiebreakId": 1, "answerText": "1969"}
+// One answer per team; a later answer from a teammate replaces it while the tie-break is open.
+func handleSubmitTiebreakAnswer(w http.ResponseWriter, r *http.Request) {
+	user, ok := authlib.GetUserFromContext(r.Context())
+	if !ok {
+		i18n.Error(w, r, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	sessionID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		i18n.Error(w, r, "invalid_session_id", http.StatusBadRequest)
+		return
+	}
+
+	var body struct {
+		TiebreakID int    `json:"tiebreakId"`
+		AnswerText string `json:"answerText"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		i18n.Error(w, r, "invalid_request", http.StatusBadRequest)
+		return
+	}
+	body.AnswerText = strings.TrimSpace(body.AnswerText)
+
+	var playerID, entityID int
+	var teamID sql.NullInt64
+	err = quizDB.QueryRow(`
+		SELECT id, COALESCE(team_id, id), team_id FROM session_players WHERE session_id = $1 AND user_email = $2`,
+		sessionID, user.Email).Scan(&playerID, &entityID, &teamID)
+	if err == sql.ErrNoRows {
+		i18n.Error(w, r, "not_in_session", http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		i18n.Error(w, r, "database_error", http.StatusInternalServerError)
+		return
+	}
+
+	tb, err := getCurrentTiebreak(sessionID, entityID)
+	if err != nil {
+		i18n.Error(w, r, "database_error", http.StatusInternalServerError)
+		return
+	}
+	if tb == nil || tb.ID != body.TiebreakID || tb.Status != "open" {
+		i18n.Error(w, r, "tiebreak_closed", http.StatusConflict)
+		return
+	}
+	if !tb.CanAnswer {
+		i18n.Error(w, r, "tiebreak_not_your_team", http.StatusForbidden)
+		return
+	}
+	if teamID.Valid {
+		if err := lockTeamAnswerer(int(teamID.Int64), playerID, deviceID(r)); err != nil {
+			writeAnswererError(w, r, err)
+			return
+		}
+	}
+	if tb.Kind == "nearest" {
+		if _, err := strconv.ParseFloat(body.AnswerText, 64); err != nil {
+			i18n.Error(w, r, "answer_number_required", http.StatusBadRequest)
+			return
+		}
+	}
+
+	screened, err := screenText(sessionID, body.AnswerText)
+	if err != nil {
+		i18n.Error(w, r, "database_error", http.StatusInternalServerError)
+		return
+	}
+	if screened.Blocked {
+		i18n.Error(w, r, "answer_blocked", http.StatusUnprocessableEntity)
+		return
+	}
+
+	_, err = quizDB.Exec(`
+		INSERT INTO tiebreak_answers (tiebreak_id, entity_id, player_id, answer_text, flagged_words, masked_text, moderation)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (tiebreak_id, entity_id)
+		DO UPDATE SET player_id = EXCLUDED.player_id, answer_text = EXCLUDED.answer_text,
+		              flagged_words = EXCLUDED.flagged_words, masked_text = EXCLUDED.masked_text,
+		              moderation = EXCLUDED.moderation, submitted_at = NOW()`,
+		tb.ID, entityID, playerID, body.AnswerText,
+		screened.flaggedWords(), screened.maskedText(), screened.moderation())
+	if err != nil {
+		i18n.Error(w, r, "database_error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "submitted"})
+}
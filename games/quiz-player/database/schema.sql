@@ -52,6 +52,42 @@ CREATE TABLE IF NOT EXISTS questions (
   created_at     TIMESTAMP    DEFAULT NOW()
 );
 
+-- Tie-break pool (quiz-master draws from it to split tied teams):
+-- nearest = closest numeric guess wins, sudden_death = marked right/wrong
+ALTER TABLE questions ADD COLUMN IF NOT EXISTS tiebreak VARCHAR(20)
+  CHECK (tiebreak IN ('nearest', 'sudden_death'));
+
+-- Contributor credit for questions that came in through the contributor portal
+ALTER TABLE questions ADD COLUMN IF NOT EXISTS contributed_by VARCHAR(255);
+
+-- Accessible variants, sent with the question to every screen: plainer
+-- wording, a description of the picture, and what phones read out (defaults
+-- to the text and picture description)
+ALTER TABLE questions ADD COLUMN IF NOT EXISTS simplified_text TEXT;
+ALTER TABLE questions ADD COLUMN IF NOT EXISTS image_alt TEXT;
+ALTER TABLE questions ADD COLUMN IF NOT EXISTS read_aloud_text TEXT;
+
+-- Questions submitted by question_contributor users. They wait here until a
+-- game admin approves them (copied into questions) or rejects them.
+CREATE TABLE IF NOT EXISTS question_submissions (
+  id                SERIAL PRIMARY KEY,
+  contributor_email VARCHAR(255) NOT NULL,
+  text              TEXT         NOT NULL,
+  answer            TEXT         NOT NULL,
+  category          VARCHAR(100),
+  difficulty        VARCHAR(20)  NOT NULL DEFAULT 'medium' CHECK (difficulty IN ('easy', 'medium', 'hard')),
+  status            VARCHAR(20)  NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+  review_note       TEXT,
+  reviewed_by       VARCHAR(255),
+  reviewed_at       TIMESTAMP,
+  question_id       INTEGER      REFERENCES questions(id) ON DELETE SET NULL,
+  created_at        TIMESTAMP    DEFAULT NOW(),
+  updated_at        TIMESTAMP    DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_question_submissions_status ON question_submissions(status, created_at);
+CREATE INDEX IF NOT EXISTS idx_question_submissions_contributor ON question_submissions(contributor_email);
+
 -- Quiz packs (collections of rounds)
 CREATE TABLE IF NOT EXISTS quiz_packs (
   id          SERIAL PRIMARY KEY,
@@ -72,6 +108,14 @@ CREATE TABLE IF NOT EXISTS rounds (
   UNIQUE(pack_id, round_number)
 );
 
+-- Stable IDs for pack export/import between environments
+ALTER TABLE quiz_packs ADD COLUMN IF NOT EXISTS guid UUID NOT NULL DEFAULT gen_random_uuid() UNIQUE;
+ALTER TABLE rounds ADD COLUMN IF NOT EXISTS guid UUID NOT NULL DEFAULT gen_random_uuid() UNIQUE;
+
+-- Deleted packs sit in Game Admin's trash until restored or purged
+ALTER TABLE quiz_packs ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE quiz_packs ADD COLUMN IF NOT EXISTS deleted_by VARCHAR(255);
+
 -- Ordered questions within a round
 CREATE TABLE IF NOT EXISTS round_questions (
   id          SERIAL PRIMARY KEY,
@@ -95,6 +139,25 @@ CREATE TABLE IF NOT EXISTS sessions (
   completed_at TIMESTAMP
 );
 
+-- Saved session setups (quiz-master), e.g. the weekly quiz. settings holds
+-- roundOrder, defaultTimeLimitSeconds, pointsPerCorrect, pointsPerWrong, maxTeamSize,
+-- lateJoinPolicy (zero | average | admin) and lateJoinPoints
+CREATE TABLE IF NOT EXISTS session_templates (
+  id         SERIAL PRIMARY KEY,
+  name       VARCHAR(255) NOT NULL,
+  pack_id    INTEGER REFERENCES quiz_packs(id) ON DELETE CASCADE,
+  mode       VARCHAR(20)  NOT NULL DEFAULT 'team' CHECK (mode IN ('team', 'individual')),
+  team_names TEXT[]       NOT NULL DEFAULT '{}',
+  settings   JSONB        NOT NULL DEFAULT '{}',
+  created_by VARCHAR(255),
+  created_at TIMESTAMP    DEFAULT NOW(),
+  updated_at TIMESTAMP    DEFAULT NOW()
+);
+
+-- Sessions keep a copy of their template's settings
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS template_id INTEGER REFERENCES session_templates(id) ON DELETE SET NULL;
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS settings JSONB NOT NULL DEFAULT '{}';
+
 -- Teams (for team mode; auto-created 1:1 in individual mode)
 CREATE TABLE IF NOT EXISTS teams (
   id         SERIAL PRIMARY KEY,
@@ -104,6 +167,40 @@ CREATE TABLE IF NOT EXISTS teams (
   created_at TIMESTAMP    DEFAULT NOW()
 );
 
+-- Content filter (quiz-player checks team names and answers; quiz-master edits
+-- the lists). venue_id 0 = every venue. Matches are flagged by default: kept,
+-- masked on the big screen until a host approves them, or refused with 'block'.
+CREATE TABLE IF NOT EXISTS content_filter_words (
+  id         SERIAL PRIMARY KEY,
+  venue_id   INTEGER      NOT NULL DEFAULT 0,
+  word       VARCHAR(100) NOT NULL,
+  created_by VARCHAR(255),
+  created_at TIMESTAMP    DEFAULT NOW(),
+  UNIQUE(venue_id, word)
+);
+
+CREATE TABLE IF NOT EXISTS content_filter_settings (
+  venue_id   INTEGER     PRIMARY KEY,
+  action     VARCHAR(10) NOT NULL DEFAULT 'flag' CHECK (action IN ('flag', 'block')),
+  updated_by VARCHAR(255),
+  updated_at TIMESTAMP   DEFAULT NOW()
+);
+
+-- Sessions run at their creator's venue (0 = chain-wide)
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS venue_id INTEGER NOT NULL DEFAULT 0;
+
+-- When a quiz night is due to start, set by the quiz master; scheduled
+-- sessions still in the lobby go in the identity shell's email digest
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS scheduled_at TIMESTAMP;
+
+-- Flagged text: moderation is NULL when clean, else flagged (masked publicly),
+-- approved (quiz master override, shown as typed) or rejected (hidden)
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS created_by VARCHAR(255);
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS flagged_words TEXT[];
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS masked_name VARCHAR(255);
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS moderation VARCHAR(10)
+  CHECK (moderation IN ('flagged', 'approved', 'rejected'));
+
 -- Players in a session
 CREATE TABLE IF NOT EXISTS session_players (
   id         SERIAL PRIMARY KEY,
@@ -115,6 +212,33 @@ CREATE TABLE IF NOT EXISTS session_players (
   UNIQUE(session_id, user_email)
 );
 
+-- Quiz masters running a session: the host has full control,
+-- scorekeeper co-hosts can only mark answers and push scores
+CREATE TABLE IF NOT EXISTS session_hosts (
+  session_id INTEGER REFERENCES sessions(id) ON DELETE CASCADE,
+  user_email VARCHAR(255) NOT NULL,
+  role       VARCHAR(20)  NOT NULL DEFAULT 'scorekeeper' CHECK (role IN ('host', 'scorekeeper')),
+  invited_by VARCHAR(255),
+  created_at TIMESTAMP    DEFAULT NOW(),
+  PRIMARY KEY (session_id, user_email)
+);
+
+CREATE INDEX IF NOT EXISTS idx_session_hosts_user ON session_hosts(user_email);
+
+-- Current question phase per session (state machine owned by quiz-master):
+-- idle → loaded → revealed → answers_open → closed → marked → scores_pushed
+CREATE TABLE IF NOT EXISTS session_phase (
+  session_id      INTEGER PRIMARY KEY REFERENCES sessions(id) ON DELETE CASCADE,
+  phase           VARCHAR(20) NOT NULL DEFAULT 'idle'
+                  CHECK (phase IN ('idle', 'loaded', 'revealed', 'answers_open', 'closed', 'marked', 'scores_pushed')),
+  round_id        INTEGER REFERENCES rounds(id),
+  question_id     INTEGER REFERENCES questions(id),
+  round_number    INTEGER NOT NULL DEFAULT 0,
+  question_number INTEGER NOT NULL DEFAULT 0,
+  changed_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  deadline        TIMESTAMPTZ
+);
+
 -- Submitted answers
 CREATE TABLE IF NOT EXISTS answers (
   id           SERIAL PRIMARY KEY,
@@ -130,6 +254,117 @@ CREATE TABLE IF NOT EXISTS answers (
   marked_at    TIMESTAMP
 );
 
+ALTER TABLE answers ADD COLUMN IF NOT EXISTS flagged_words TEXT[];
+ALTER TABLE answers ADD COLUMN IF NOT EXISTS masked_text TEXT;
+ALTER TABLE answers ADD COLUMN IF NOT EXISTS moderation VARCHAR(10)
+  CHECK (moderation IN ('flagged', 'approved', 'rejected'));
+
+-- One answer per player per question: players edit it (and autosave drafts)
+-- until answers close. revision counts the changes; a draft still open when
+-- answers close is marked like a submitted answer.
+ALTER TABLE answers ADD COLUMN IF NOT EXISTS revision INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE answers ADD COLUMN IF NOT EXISTS is_draft BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Resubmissions used to add rows; keep each player's latest before enforcing one
+DELETE FROM answers a USING answers b
+WHERE a.session_id = b.session_id AND a.question_id = b.question_id AND a.player_id = b.player_id
+  AND a.id < b.id;
+CREATE UNIQUE INDEX IF NOT EXISTS answers_player_question
+  ON answers (session_id, question_id, player_id);
+
+-- Every revision of an answer, for settling "but I changed it!" disputes
+CREATE TABLE IF NOT EXISTS answer_revisions (
+  id          SERIAL PRIMARY KEY,
+  answer_id   INTEGER NOT NULL REFERENCES answers(id) ON DELETE CASCADE,
+  revision    INTEGER NOT NULL,
+  answer_text TEXT,
+  is_draft    BOOLEAN NOT NULL DEFAULT FALSE,
+  saved_at    TIMESTAMP DEFAULT NOW(),
+  UNIQUE (answer_id, revision)
+);
+
+-- Photo rounds: players answer with a picture ("draw the logo"). Photos live
+-- in quiz-player's ANSWER_MEDIA_DIR under the session's ID; photo_path is
+-- relative to it. They're deleted after the retention period, leaving the
+-- answer with photo_purged_at set.
+ALTER TABLE rounds DROP CONSTRAINT IF EXISTS rounds_type_check;
+ALTER TABLE rounds ADD CONSTRAINT rounds_type_check CHECK (type IN ('text', 'picture', 'music', 'photo'));
+ALTER TABLE answers ADD COLUMN IF NOT EXISTS photo_path VARCHAR(255);
+ALTER TABLE answers ADD COLUMN IF NOT EXISTS photo_bytes INTEGER;
+ALTER TABLE answers ADD COLUMN IF NOT EXISTS photo_purged_at TIMESTAMP;
+ALTER TABLE answer_revisions ADD COLUMN IF NOT EXISTS photo_path VARCHAR(255);
+ALTER TABLE answer_revisions ADD COLUMN IF NOT EXISTS photo_bytes INTEGER;
+
+-- One device answers for each team: the captain's. The first player to answer
+-- becomes captain if none is set, and captain_device (the X-Device-ID their
+-- answers come from) locks the team's answers to that device. The captain
+-- can hand over; quiz-master can reassign.
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS captain_player_id INTEGER REFERENCES session_players(id) ON DELETE SET NULL;
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS captain_device VARCHAR(64);
+
+-- Points awarded outside marking, shown in the score breakdown. late_join rows
+-- backfill a team (or individual player) that arrived after scoring started.
+CREATE TABLE IF NOT EXISTS score_adjustments (
+  id         SERIAL PRIMARY KEY,
+  session_id INTEGER REFERENCES sessions(id) ON DELETE CASCADE,
+  team_id    INTEGER REFERENCES teams(id) ON DELETE CASCADE,
+  player_id  INTEGER REFERENCES session_players(id) ON DELETE CASCADE,
+  reason     VARCHAR(20) NOT NULL CHECK (reason IN ('late_join')),
+  policy     VARCHAR(20),
+  points     INTEGER   NOT NULL DEFAULT 0,
+  updated_by VARCHAR(255),
+  created_at TIMESTAMP DEFAULT NOW(),
+  updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_score_adjustments_late_join
+  ON score_adjustments(session_id, COALESCE(team_id, 0), COALESCE(player_id, 0))
+  WHERE reason = 'late_join';
+
+-- Tie-break mini-rounds between teams level on points (entity_ids are team
+-- IDs, or player IDs in individual mode). Ranks split the tie in the standings.
+CREATE TABLE IF NOT EXISTS tiebreaks (
+  id          SERIAL PRIMARY KEY,
+  session_id  INTEGER REFERENCES sessions(id) ON DELETE CASCADE,
+  question_id INTEGER REFERENCES questions(id),
+  kind        VARCHAR(20) NOT NULL CHECK (kind IN ('nearest', 'sudden_death')),
+  entity_ids  INTEGER[]   NOT NULL,
+  status      VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'closed', 'resolved')),
+  created_at  TIMESTAMP   DEFAULT NOW(),
+  closed_at   TIMESTAMP,
+  resolved_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_tiebreaks_session ON tiebreaks(session_id);
+
+-- One answer per tied team; rank (1 = best) is set when the tie-break is resolved
+CREATE TABLE IF NOT EXISTS tiebreak_answers (
+  id           SERIAL PRIMARY KEY,
+  tiebreak_id  INTEGER REFERENCES tiebreaks(id) ON DELETE CASCADE,
+  entity_id    INTEGER NOT NULL,
+  player_id    INTEGER REFERENCES session_players(id) ON DELETE SET NULL,
+  answer_text  TEXT,
+  is_correct   BOOLEAN,
+  rank         INTEGER,
+  submitted_at TIMESTAMP DEFAULT NOW(),
+  UNIQUE(tiebreak_id, entity_id)
+);
+
+ALTER TABLE tiebreak_answers ADD COLUMN IF NOT EXISTS flagged_words TEXT[];
+ALTER TABLE tiebreak_answers ADD COLUMN IF NOT EXISTS masked_text TEXT;
+ALTER TABLE tiebreak_answers ADD COLUMN IF NOT EXISTS moderation VARCHAR(10)
+  CHECK (moderation IN ('flagged', 'approved', 'rejected'));
+
+-- Final positions, saved when the session ends (ties share a position)
+CREATE TABLE IF NOT EXISTS session_results (
+  session_id INTEGER REFERENCES sessions(id) ON DELETE CASCADE,
+  entity_id  INTEGER NOT NULL,
+  name       VARCHAR(255) NOT NULL,
+  total      INTEGER NOT NULL,
+  position   INTEGER NOT NULL,
+  PRIMARY KEY (session_id, entity_id)
+);
+
 -- Score push history (when QM reveals scores)
 CREATE TABLE IF NOT EXISTS score_reveals (
   id         SERIAL PRIMARY KEY,
@@ -137,3 +372,17 @@ CREATE TABLE IF NOT EXISTS score_reveals (
   round_id   INTEGER REFERENCES rounds(id),
   revealed_at TIMESTAMP DEFAULT NOW()
 );
+
+-- Standings as shown, for quiz-display's dedicated scores screen
+ALTER TABLE score_reveals ADD COLUMN IF NOT EXISTS standings JSONB;
+
+-- Players' own accessibility settings, kept across quiz nights. NULL follows
+-- the session's default (sessions.settings accessibility).
+CREATE TABLE IF NOT EXISTS player_accessibility (
+  user_email      VARCHAR(255) PRIMARY KEY,
+  large_text      BOOLEAN,
+  high_contrast   BOOLEAN,
+  read_aloud      BOOLEAN,
+  simplified_text BOOLEAN,
+  updated_at      TIMESTAMP NOT NULL DEFAULT NOW()
+);
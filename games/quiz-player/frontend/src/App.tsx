@@ -25,6 +25,44 @@ interface CachedQuestion {
   questionText: string;
   imageUrl: string;
   timeLimit: number | null;
+  photoAnswer?: boolean; // Photo round: answer with a picture
+  accessibility?: QuestionAccessibility;
+}
+
+// A question's accessible variants, the same on the big screen and every phone
+interface QuestionAccessibility {
+  simplifiedText?: string;
+  imageAlt?: string;
+  readAloud: string;
+}
+
+// What this phone applies: the session's defaults merged with the player's own settings
+interface Accessibility {
+  largeText: boolean;
+  highContrast: boolean;
+  readAloud: boolean;
+  simplifiedText: boolean;
+}
+
+// The player's own settings, kept for every quiz; null follows the quiz's default
+type PlayerAccessibility = Record<keyof Accessibility, boolean | null>;
+
+const NO_ACCESSIBILITY: Accessibility = { largeText: false, highContrast: false, readAloud: false, simplifiedText: false };
+
+const ACCESSIBILITY_OPTIONS: { key: keyof Accessibility; label: string }[] = [
+  { key: 'largeText', label: 'Large text' },
+  { key: 'highContrast', label: 'High contrast' },
+  { key: 'readAloud', label: 'Read questions aloud' },
+  { key: 'simplifiedText', label: 'Simpler wording' },
+];
+
+// Server-side question phase (quiz-master state machine)
+interface PhaseState {
+  phase: 'idle' | 'loaded' | 'revealed' | 'answers_open' | 'closed' | 'marked' | 'scores_pushed';
+  questionId?: number;
+  changedAt: string;
+  deadline?: string;
+  serverTime: string;
 }
 
 interface ScoreEntry {
@@ -32,6 +70,60 @@ interface ScoreEntry {
   name: string;
   total: number;
   roundPoints: number;
+  position?: number; // Ties share a position until a tie-break splits them
+}
+
+// Tie-break mini-round; only the tied teams can answer
+interface Tiebreak {
+  id: number;
+  kind: 'nearest' | 'sudden_death';
+  question: string;
+  entityIds: number[];
+  status: 'open' | 'closed';
+  canAnswer: boolean;
+  myAnswer?: string;
+}
+
+// The player's answer to the open question; drafts are autosaved as they type
+interface MyAnswer {
+  questionId: number;
+  answerText: string;
+  revision: number;
+  draft: boolean;
+}
+
+// Who answers for the player's team: only the captain, from one device
+interface TeamCaptain {
+  teamId: number;
+  captainPlayerId: number | null;
+  captainName: string;
+  isMe: boolean;
+  thisDevice: boolean;
+  members: { playerId: number; name: string }[];
+}
+
+// Typing pause before a draft is autosaved
+const AUTOSAVE_DELAY_MS = 800;
+
+// Photo answers are shrunk on the phone before upload (the server caps them at 4 MB)
+const PHOTO_MAX_DIMENSION = 1600;
+const PHOTO_JPEG_QUALITY = 0.85;
+
+// Reactions a player can send between questions (the server accepts the same set)
+const REACTION_EMOJIS = ['👏', '🎉', '😂', '😮', '🔥', '😬'];
+
+// shrinkPhoto scales a picture down to PHOTO_MAX_DIMENSION and re-encodes it as JPEG
+async function shrinkPhoto(file: File): Promise<Blob> {
+  const bitmap = await createImageBitmap(file);
+  const scale = Math.min(1, PHOTO_MAX_DIMENSION / Math.max(bitmap.width, bitmap.height));
+  const canvas = document.createElement('canvas');
+  canvas.width = Math.round(bitmap.width * scale);
+  canvas.height = Math.round(bitmap.height * scale);
+  canvas.getContext('2d')?.drawImage(bitmap, 0, 0, canvas.width, canvas.height);
+  bitmap.close();
+  return new Promise((resolve, reject) => {
+    canvas.toBlob(b => (b ? resolve(b) : reject(new Error('Could not read that photo'))), 'image/jpeg', PHOTO_JPEG_QUALITY);
+  });
 }
 
 type ViewState =
@@ -43,26 +135,61 @@ type ViewState =
   | 'question'
   | 'answer-submitted'
   | 'scores'
+  | 'tiebreak'
   | 'ended';
 
+// speak reads text out with the phone's own voice, cutting off anything still being read
+function speak(text: string) {
+  if (!text || !('speechSynthesis' in window)) return;
+  window.speechSynthesis.cancel();
+  window.speechSynthesis.speak(new SpeechSynthesisUtterance(text));
+}
+
 // --- Hooks ---
 
+// Single-use stream token from the shell, so the session token never goes in
+// the EventSource URL. Empty on failure: the stream then rejects the connection
+// and the normal reconnect path retries.
+async function fetchStreamToken(token: string): Promise<string> {
+  try {
+    const res = await fetch(`http://${window.location.hostname}:3001/api/auth/stream-token`, {
+      method: 'POST',
+      headers: { 'Authorization': `Bearer ${token}` },
+    });
+    return res.ok ? (await res.json()).token : '';
+  } catch {
+    return '';
+  }
+}
+
 function useUrlParams() {
   return useMemo(() => {
     const params = new URLSearchParams(window.location.search);
     return {
       userId: params.get('userId') || '',
       userName: params.get('userName') || '',
-      token: params.get('token') || '',
+      token: params.get('token') || sessionStorage.getItem('token') || '',
     };
   }, []);
 }
 
+// This browser's device ID, sent with every request so the server can keep a
+// team's answers to one device
+function getDeviceId(): string {
+  let id = localStorage.getItem('quizDeviceId');
+  if (!id) {
+    id = window.crypto?.randomUUID?.() || `${Date.now()}-${Math.random().toString(36).slice(2)}`;
+    localStorage.setItem('quizDeviceId', id);
+  }
+  return id;
+}
+
 function useApi(token: string) {
   return useCallback(
     async (path: string, options: RequestInit = {}) => {
       const headers: Record<string, string> = {
         ...(token ? { Authorization: `Bearer ${token}` } : {}),
+        'X-Device-ID': getDeviceId(),
         ...(options.headers as Record<string, string> || {}),
       };
       if (!(options.body instanceof FormData)) {
@@ -89,6 +216,7 @@ function App() {
   const [session, setSession] = useState<SessionInfo | null>(null);
   const [joinCode, setJoinCode] = useState('');
   const [teamJoinCode, setTeamJoinCode] = useState('');
+  const [newTeamName, setNewTeamName] = useState('');
   const [error, setError] = useState<string | null>(null);
 
   // Quiz state
@@ -96,25 +224,34 @@ function App() {
   const [revealedQuestionId, setRevealedQuestionId] = useState<number | null>(null);
   const [answerText, setAnswerText] = useState('');
   const [answerSubmitted, setAnswerSubmitted] = useState(false);
+  const [answersOpen, setAnswersOpen] = useState(false);
+  const [draftStatus, setDraftStatus] = useState<'saving' | 'saved' | null>(null);
+  const [photoPreview, setPhotoPreview] = useState<string | null>(null);
+  const [photoUploading, setPhotoUploading] = useState(false);
+  const savedTextRef = useRef('');
   const [scores, setScores] = useState<ScoreEntry[]>([]);
   const [timeLeft, setTimeLeft] = useState<number | null>(null);
   const timerRef = useRef<ReturnType<typeof setInterval> | null>(null);
+  const [tiebreak, setTiebreak] = useState<Tiebreak | null>(null);
+  const [captain, setCaptain] = useState<TeamCaptain | null>(null);
+  const [tiebreakAnswer, setTiebreakAnswer] = useState('');
+  const [accessibility, setAccessibility] = useState<Accessibility>(NO_ACCESSIBILITY);
+  const [myAccessibility, setMyAccessibility] = useState<PlayerAccessibility | null>(null);
+  const [showAccessibility, setShowAccessibility] = useState(false);
+  const s = useMemo(() => accessibleStyles(accessibility), [accessibility]);
 
   const sseRef = useRef<EventSource | null>(null);
 
-  const connectSSE = useCallback((sid: number) => {
+  const connectSSE = useCallback(async (sid: number) => {
     if (sseRef.current) sseRef.current.close();
-    const es = new EventSource(`/api/sessions/${sid}/stream?token=${encodeURIComponent(token)}`);
+    const streamToken = await fetchStreamToken(token);
+    const es = new EventSource(`/api/sessions/${sid}/stream?token=${encodeURIComponent(streamToken)}`);
     sseRef.current = es;
 
-    es.addEventListener('connected', () => {
-      // connected
-    });
-
     es.onmessage = (e) => {
       try {
         const event = JSON.parse(e.data);
-        handleSSEEvent(event);
+        handleSSEEvent(event, sid);
       } catch {}
     };
 
@@ -123,14 +260,76 @@ function App() {
     };
   }, [token]); // eslint-disable-line react-hooks/exhaustive-deps
 
-  const handleSSEEvent = (event: { type: string; payload: unknown }) => {
+  // Counts down to the server's deadline, corrected for this device's clock skew
+  const startCountdown = (phase: Pick<PhaseState, 'deadline' | 'serverTime'>) => {
+    if (timerRef.current) clearInterval(timerRef.current);
+    if (!phase.deadline) {
+      setTimeLeft(null);
+      return;
+    }
+    const skew = Date.parse(phase.serverTime) - Date.now();
+    const deadline = Date.parse(phase.deadline);
+    const tick = () => {
+      const left = Math.max(0, Math.ceil((deadline - (Date.now() + skew)) / 1000));
+      setTimeLeft(left);
+      if (left === 0 && timerRef.current) clearInterval(timerRef.current);
+    };
+    tick();
+    timerRef.current = setInterval(tick, 250);
+  };
+
+  // The state endpoint says whether this player's team is one of the tied teams
+  const loadTiebreak = async (sid: number) => {
+    try {
+      const data = await api(`/api/sessions/${sid}/state`);
+      setTiebreak(data.tiebreak || null);
+      setTiebreakAnswer(data.tiebreak?.myAnswer || '');
+      if (data.tiebreak) setView('tiebreak');
+    } catch {}
+  };
+
+  // The state endpoint merges the quiz's accessibility defaults with the player's own
+  const loadAccessibility = async (sid: number) => {
+    try {
+      const data = await api(`/api/sessions/${sid}/state`);
+      if (data.accessibility) setAccessibility(data.accessibility);
+    } catch {}
+  };
+
+  const loadCaptain = async (sid: number) => {
+    try {
+      const data = await api(`/api/sessions/${sid}/state`);
+      setCaptain(data.captain || null);
+    } catch {}
+  };
+
+  const handleSSEEvent = (event: { type: string; payload: unknown }, sid: number) => {
     switch (event.type) {
+      case 'phase_changed': {
+        const p = event.payload as PhaseState;
+        if (p.phase === 'answers_open') startCountdown(p);
+        setAnswersOpen(p.phase === 'answers_open');
+        break;
+      }
+      case 'timer_tick': {
+        // Sent every second by the server while a timed question is open
+        startCountdown(event.payload as { deadline: string; serverTime: string });
+        break;
+      }
       case 'question_precache': {
         const p = event.payload as CachedQuestion;
         setCachedQuestion(p);
         setRevealedQuestionId(null);
         setAnswerText('');
         setAnswerSubmitted(false);
+        setAnswersOpen(false);
+        setDraftStatus(null);
+        setPhotoPreview(prev => {
+          if (prev) URL.revokeObjectURL(prev);
+          return null;
+        });
+        savedTextRef.current = '';
+        loadCaptain(sid);
         setView('question-ready');
         break;
       }
@@ -140,24 +339,10 @@ function App() {
         setView('question');
         break;
       }
-      case 'timer_start': {
-        const p = event.payload as { questionId: number; durationSeconds: number };
-        if (timerRef.current) clearInterval(timerRef.current);
-        setTimeLeft(p.durationSeconds);
-        timerRef.current = setInterval(() => {
-          setTimeLeft(prev => {
-            if (prev === null || prev <= 1) {
-              clearInterval(timerRef.current!);
-              return 0;
-            }
-            return prev - 1;
-          });
-        }, 1000);
-        break;
-      }
       case 'answers_closed': {
         if (timerRef.current) clearInterval(timerRef.current);
         setTimeLeft(null);
+        setAnswersOpen(false);
         if (!answerSubmitted) setView('waiting');
         break;
       }
@@ -167,7 +352,32 @@ function App() {
         setView('scores');
         break;
       }
+      case 'captain_changed': {
+        loadCaptain(sid);
+        break;
+      }
+      case 'accessibility_changed': {
+        loadAccessibility(sid);
+        break;
+      }
+      case 'tiebreak_started': {
+        loadTiebreak(sid);
+        break;
+      }
+      case 'tiebreak_closed': {
+        setTiebreak(prev => prev ? { ...prev, status: 'closed', canAnswer: false } : prev);
+        break;
+      }
+      case 'tiebreak_resolved': {
+        const p = event.payload as { scores: ScoreEntry[] };
+        setTiebreak(null);
+        setScores(p.scores);
+        setView('scores');
+        break;
+      }
       case 'quiz_ended': {
+        const p = event.payload as { standings?: ScoreEntry[] };
+        if (p.standings) setScores(p.standings);
         setView('ended');
         if (sseRef.current) sseRef.current.close();
         break;
@@ -181,9 +391,47 @@ function App() {
     return () => {
       if (sseRef.current) sseRef.current.close();
       if (timerRef.current) clearInterval(timerRef.current);
+      if ('speechSynthesis' in window) window.speechSynthesis.cancel();
     };
   }, []);
 
+  // Before joining a quiz, the player's own settings are all there is to apply
+  useEffect(() => {
+    if (!token) return;
+    api('/api/accessibility').then(d => {
+      const mine: PlayerAccessibility = d.accessibility;
+      setMyAccessibility(mine);
+      setAccessibility(prev => {
+        const next = { ...prev };
+        ACCESSIBILITY_OPTIONS.forEach(o => { next[o.key] = mine[o.key] ?? prev[o.key]; });
+        return next;
+      });
+    }).catch(() => {});
+  }, [api, token]);
+
+  // Read each question out as it's revealed
+  useEffect(() => {
+    if (view === 'question' && accessibility.readAloud && cachedQuestion && revealedQuestionId === cachedQuestion.questionId) {
+      speak(cachedQuestion.accessibility?.readAloud || cachedQuestion.questionText);
+    }
+  }, [revealedQuestionId]); // eslint-disable-line react-hooks/exhaustive-deps
+
+  const saveMyAccessibility = async (key: keyof Accessibility, value: boolean | null) => {
+    const mine = { ...(myAccessibility || { largeText: null, highContrast: null, readAloud: null, simplifiedText: null }), [key]: value };
+    setError(null);
+    try {
+      const data = await api('/api/accessibility', { method: 'PUT', body: JSON.stringify(mine) });
+      setMyAccessibility(data.accessibility);
+      if (session) {
+        loadAccessibility(session.sessionId);
+      } else {
+        setAccessibility(prev => ({ ...prev, [key]: value ?? false }));
+      }
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to save accessibility settings');
+    }
+  };
+
   const joinSession = async () => {
     if (!joinCode.trim()) return;
     setError(null);
@@ -194,6 +442,7 @@ function App() {
       });
       setSession(data);
       connectSSE(data.sessionId);
+      loadAccessibility(data.sessionId);
       if (data.mode === 'team' && data.teams.length > 0) {
         setView('team-join');
       } else {
@@ -212,31 +461,199 @@ function App() {
         method: 'POST',
         body: JSON.stringify({ sessionId: session.sessionId, teamCode }),
       });
+      loadCaptain(session.sessionId);
       setView('lobby');
     } catch (err) {
       setError(err instanceof Error ? err.message : 'Failed to join team');
     }
   };
 
+  // Names go through the venue's content filter; a flagged name is masked on the big screen
+  const startTeam = async () => {
+    if (!session || !newTeamName.trim()) return;
+    setError(null);
+    try {
+      await api('/api/sessions/join-team', {
+        method: 'POST',
+        body: JSON.stringify({ sessionId: session.sessionId, teamName: newTeamName.trim() }),
+      });
+      setNewTeamName('');
+      loadCaptain(session.sessionId);
+      setView('lobby');
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to start team');
+    }
+  };
+
+  // The captain hands over to a teammate, or names themselves to answer from this phone
+  const changeCaptain = async (playerId: number) => {
+    if (!session) return;
+    setError(null);
+    try {
+      const data = await api(`/api/sessions/${session.sessionId}/captain`, {
+        method: 'POST',
+        body: JSON.stringify({ playerId }),
+      });
+      setCaptain(data.captain);
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to change captain');
+    }
+  };
+
+  // Answers only go from the captain's phone; no captain yet means the first to answer
+  const canAnswer = !captain || captain.captainPlayerId === null || (captain.isMe && captain.thisDevice);
+
+  const captainPanel = captain && (
+    <div style={{ ...s.muted, marginTop: 12 }}>
+      {captain.captainPlayerId === null && captain.members.length > 1 && (
+        <p>No captain yet – whoever answers first answers for the team.</p>
+      )}
+      {captain.isMe && captain.thisDevice && captain.members.length > 1 && (
+        <p>
+          You're the captain.{' '}
+          <select
+            value=""
+            onChange={e => e.target.value && changeCaptain(Number(e.target.value))}
+            style={{ fontSize: 13 }}
+          >
+            <option value="">Hand over to...</option>
+            {captain.members.filter(m => m.playerId !== captain.captainPlayerId).map(m => (
+              <option key={m.playerId} value={m.playerId}>{m.name}</option>
+            ))}
+          </select>
+        </p>
+      )}
+      {captain.isMe && !captain.thisDevice && captain.captainPlayerId !== null && (
+        <>
+          <p>You're answering on another device.</p>
+          <button style={{ ...s.btnOutline, marginTop: 8 }} onClick={() => changeCaptain(captain.captainPlayerId as number)}>
+            Answer from this phone instead
+          </button>
+        </>
+      )}
+      {!captain.isMe && captain.captainPlayerId !== null && (
+        <p>{captain.captainName} is answering for your team.</p>
+      )}
+    </div>
+  );
+
+  // Submitting again after changing your mind edits the same answer
   const submitAnswer = async () => {
     if (!session || !cachedQuestion || !answerText.trim()) return;
     setError(null);
     try {
-      await api(`/api/sessions/${session.sessionId}/answer`, {
+      const data: { answer: MyAnswer } = answerSubmitted
+        ? await api(`/api/sessions/${session.sessionId}/answers/${cachedQuestion.questionId}`, {
+            method: 'PATCH',
+            body: JSON.stringify({ answerText: answerText.trim() }),
+          })
+        : await api(`/api/sessions/${session.sessionId}/answer`, {
+            method: 'POST',
+            body: JSON.stringify({
+              roundId: cachedQuestion.roundId,
+              questionId: cachedQuestion.questionId,
+              answerText: answerText.trim(),
+            }),
+          });
+      savedTextRef.current = data.answer.answerText;
+      setAnswerSubmitted(true);
+      setView('answer-submitted');
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to submit');
+      loadCaptain(session.sessionId);
+    }
+  };
+
+  // Photo rounds: sending another photo replaces the answer, like editing text
+  const submitPhoto = async (file: File) => {
+    if (!session || !cachedQuestion) return;
+    setError(null);
+    setPhotoUploading(true);
+    try {
+      const photo = await shrinkPhoto(file);
+      const form = new FormData();
+      form.append('file', photo, 'answer.jpg');
+      await api(`/api/sessions/${session.sessionId}/answers/${cachedQuestion.questionId}/photo?roundId=${cachedQuestion.roundId}`, {
         method: 'POST',
-        body: JSON.stringify({
-          roundId: cachedQuestion.roundId,
-          questionId: cachedQuestion.questionId,
-          answerText: answerText.trim(),
-        }),
+        body: form,
+      });
+      setPhotoPreview(prev => {
+        if (prev) URL.revokeObjectURL(prev);
+        return URL.createObjectURL(photo);
       });
       setAnswerSubmitted(true);
       setView('answer-submitted');
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to send photo');
+      loadCaptain(session.sessionId);
+    } finally {
+      setPhotoUploading(false);
+    }
+  };
+
+  // Autosave a draft once the player stops typing, until they first submit.
+  // A draft still saved when answers close is marked like a submitted answer.
+  useEffect(() => {
+    if (view !== 'question' || answerSubmitted || !answersOpen || !session || !cachedQuestion) return;
+    const text = answerText.trim();
+    if (!text || text === savedTextRef.current) return;
+    const timer = setTimeout(async () => {
+      setDraftStatus('saving');
+      try {
+        await api(`/api/sessions/${session.sessionId}/answers/${cachedQuestion.questionId}/draft`, {
+          method: 'PUT',
+          body: JSON.stringify({ answerText: text }),
+        });
+        savedTextRef.current = text;
+        setDraftStatus('saved');
+      } catch {
+        // A failed autosave is retried on the next keystroke; submitting reports errors
+        setDraftStatus(null);
+      }
+    }, AUTOSAVE_DELAY_MS);
+    return () => clearTimeout(timer);
+  }, [answerText, view, answerSubmitted, answersOpen, session, cachedQuestion, api]);
+
+  const submitTiebreakAnswer = async () => {
+    if (!session || !tiebreak || !tiebreakAnswer.trim()) return;
+    setError(null);
+    try {
+      await api(`/api/sessions/${session.sessionId}/tiebreak-answer`, {
+        method: 'POST',
+        body: JSON.stringify({ tiebreakId: tiebreak.id, answerText: tiebreakAnswer.trim() }),
+      });
+      setTiebreak({ ...tiebreak, myAnswer: tiebreakAnswer.trim() });
     } catch (err) {
       setError(err instanceof Error ? err.message : 'Failed to submit');
     }
   };
 
+  // Reactions feed the applause meter on the big screen; too-fast taps are just dropped
+  const react = async (emoji: string) => {
+    if (!session) return;
+    try {
+      await api(`/api/sessions/${session.sessionId}/react`, {
+        method: 'POST',
+        body: JSON.stringify({ emoji }),
+      });
+    } catch {
+      // Not worth interrupting the player for
+    }
+  };
+
+  const reactionBar = (
+    <div style={s.reactionBar}>
+      {REACTION_EMOJIS.map(emoji => (
+        <button key={emoji} style={s.reactionBtn} onClick={() => react(emoji)} aria-label={`React ${emoji}`}>
+          {emoji}
+        </button>
+      ))}
+    </div>
+  );
+
+  // Teams still level after any tie-breaks share first place
+  const winners = scores.filter((e, idx) => (e.position ?? idx + 1) === 1);
+
   if (!userId || !token) {
     return (
       <div style={s.container}>
@@ -251,8 +668,41 @@ function App() {
       <div style={s.header}>
         <h2 style={s.title}>Quiz Player</h2>
         {session && <span style={s.sessionName}>{session.sessionName}</span>}
+        <button
+          style={s.accessibilityToggle}
+          onClick={() => setShowAccessibility(v => !v)}
+          aria-expanded={showAccessibility}
+          aria-label="Accessibility settings"
+        >
+          Aa
+        </button>
       </div>
 
+      {showAccessibility && (
+        <div style={s.card}>
+          <h3 style={s.cardTitle}>Accessibility</h3>
+          <p style={{ ...s.muted, marginBottom: 8 }}>Saved for every quiz you join. "Quiz default" follows the Quiz Master's settings.</p>
+          {ACCESSIBILITY_OPTIONS.map(o => {
+            const mine = myAccessibility?.[o.key] ?? null;
+            return (
+              <div key={o.key} style={s.accessibilityRow}>
+                <span style={{ flex: 1 }}>{o.label}</span>
+                <select
+                  style={s.accessibilitySelect}
+                  value={mine === null ? '' : mine ? 'on' : 'off'}
+                  onChange={e => saveMyAccessibility(o.key, e.target.value === '' ? null : e.target.value === 'on')}
+                  aria-label={o.label}
+                >
+                  <option value="">Quiz default</option>
+                  <option value="on">On</option>
+                  <option value="off">Off</option>
+                </select>
+              </div>
+            );
+          })}
+        </div>
+      )}
+
       {error && (
         <div style={s.error} onClick={() => setError(null)}>{error}</div>
       )}
@@ -301,6 +751,17 @@ function App() {
               Join
             </button>
           </div>
+          <div style={{ display: 'flex', gap: 8, marginTop: 8 }}>
+            <input
+              style={{ ...s.input, flex: 1 }}
+              placeholder="Or start a new team"
+              value={newTeamName}
+              onChange={e => setNewTeamName(e.target.value)}
+            />
+            <button style={s.btnPrimary} onClick={startTeam} disabled={!newTeamName.trim()}>
+              Start
+            </button>
+          </div>
           <button style={{ ...s.btnOutline, marginTop: 8 }} onClick={() => setView('lobby')}>
             Skip (no team)
           </button>
@@ -315,6 +776,7 @@ function App() {
             <span style={{ fontSize: 48 }}>🎯</span>
             <p style={{ ...s.muted, marginTop: 12 }}>The Quiz Master will start the quiz shortly...</p>
           </div>
+          {captainPanel}
           {session?.mode === 'team' && (
             <button style={{ ...s.btnOutline, marginTop: 12 }} onClick={() => setView('team-join')}>
               Change Team
@@ -349,25 +811,70 @@ function App() {
           </div>
 
           {cachedQuestion.imageUrl && (
-            <img src={cachedQuestion.imageUrl} alt="Question" style={s.questionImage} />
+            <img src={cachedQuestion.imageUrl} alt={cachedQuestion.accessibility?.imageAlt || 'Question picture'} style={s.questionImage} />
           )}
 
-          <p style={s.questionText}>{cachedQuestion.questionText}</p>
+          {accessibility.simplifiedText && cachedQuestion.accessibility?.simplifiedText ? (
+            <>
+              <p style={s.questionText}>{cachedQuestion.accessibility.simplifiedText}</p>
+              <p style={{ ...s.muted, marginBottom: 12 }}>As asked: {cachedQuestion.questionText}</p>
+            </>
+          ) : (
+            <p style={s.questionText}>{cachedQuestion.questionText}</p>
+          )}
 
-          <textarea
-            style={s.answerInput}
-            placeholder="Your answer..."
-            value={answerText}
-            onChange={e => setAnswerText(e.target.value)}
-            rows={3}
-          />
-          <button
-            style={s.btnPrimary}
-            onClick={submitAnswer}
-            disabled={!answerText.trim()}
-          >
-            Submit Answer
-          </button>
+          {accessibility.readAloud && (
+            <button
+              style={{ ...s.btnOutline, marginBottom: 12 }}
+              onClick={() => speak(cachedQuestion.accessibility?.readAloud || cachedQuestion.questionText)}
+            >
+              🔊 Read it again
+            </button>
+          )}
+
+          {!canAnswer ? captainPanel : cachedQuestion.photoAnswer ? (
+            <>
+              {photoPreview && <img src={photoPreview} alt="Your answer" style={s.questionImage} />}
+              <label style={{ ...s.btnPrimary, display: 'block', textAlign: 'center', opacity: photoUploading ? 0.6 : 1 }}>
+                {photoUploading ? 'Sending photo...' : answerSubmitted ? '📷 Send a different photo' : '📷 Take or choose a photo'}
+                <input
+                  type="file"
+                  accept="image/*"
+                  capture="environment"
+                  style={{ display: 'none' }}
+                  disabled={photoUploading}
+                  onChange={e => {
+                    const file = e.target.files?.[0];
+                    e.target.value = '';
+                    if (file) submitPhoto(file);
+                  }}
+                />
+              </label>
+            </>
+          ) : (
+            <>
+              <textarea
+                style={s.answerInput}
+                placeholder="Your answer..."
+                value={answerText}
+                onChange={e => setAnswerText(e.target.value)}
+                rows={3}
+              />
+              {!answerSubmitted && draftStatus && (
+                <p style={{ ...s.muted, fontSize: 12, marginBottom: 8 }}>
+                  {draftStatus === 'saving' ? 'Saving draft...' : 'Draft saved'}
+                </p>
+              )}
+              <button
+                style={s.btnPrimary}
+                onClick={submitAnswer}
+                disabled={!answerText.trim()}
+              >
+                {answerSubmitted ? 'Update Answer' : 'Submit Answer'}
+              </button>
+            </>
+          )}
+          {canAnswer && captainPanel}
         </div>
       )}
 
@@ -379,6 +886,12 @@ function App() {
             <p style={{ fontSize: 16, fontWeight: 600, marginTop: 12 }}>Answer submitted!</p>
             <p style={s.muted}>Waiting for other players...</p>
             {answerText && <p style={{ marginTop: 12, color: '#555', fontStyle: 'italic' }}>"{answerText}"</p>}
+            {photoPreview && <img src={photoPreview} alt="Your answer" style={{ ...s.questionImage, marginTop: 12 }} />}
+            {answersOpen && (
+              <button style={{ ...s.btnOutline, marginTop: 12 }} onClick={() => setView('question')}>
+                Change Answer
+              </button>
+            )}
           </div>
         </div>
       )}
@@ -390,6 +903,7 @@ function App() {
             <span style={{ fontSize: 48 }}>⏳</span>
             <p style={{ ...s.muted, marginTop: 12 }}>Waiting for next question...</p>
           </div>
+          {reactionBar}
         </div>
       )}
 
@@ -403,13 +917,44 @@ function App() {
             <div>
               {scores.map((entry, idx) => (
                 <div key={entry.teamId} style={{ ...s.scoreRow, borderLeft: idx === 0 ? '4px solid gold' : undefined }}>
-                  <span style={s.scoreRank}>#{idx + 1}</span>
+                  <span style={s.scoreRank}>#{entry.position ?? idx + 1}</span>
                   <span style={s.scoreName}>{entry.name}</span>
                   <span style={s.scorePoints}>{entry.total} pts</span>
                 </div>
               ))}
             </div>
           )}
+          {reactionBar}
+        </div>
+      )}
+
+      {/* Tie-break */}
+      {view === 'tiebreak' && tiebreak && (
+        <div style={s.card}>
+          <div style={s.roundBadge}>Tie-break · {tiebreak.kind === 'nearest' ? 'Nearest wins' : 'Sudden death'}</div>
+          <p style={s.questionText}>{tiebreak.question}</p>
+          {tiebreak.canAnswer ? (
+            <>
+              <input
+                style={s.input}
+                type={tiebreak.kind === 'nearest' ? 'number' : 'text'}
+                placeholder={tiebreak.kind === 'nearest' ? 'Your number...' : 'Your answer...'}
+                value={tiebreakAnswer}
+                onChange={e => setTiebreakAnswer(e.target.value)}
+                onKeyDown={e => e.key === 'Enter' && submitTiebreakAnswer()}
+              />
+              <button style={s.btnPrimary} onClick={submitTiebreakAnswer} disabled={!tiebreakAnswer.trim()}>
+                {tiebreak.myAnswer ? 'Change Answer' : 'Submit Answer'}
+              </button>
+              {tiebreak.myAnswer && <p style={{ ...s.muted, marginTop: 8 }}>Your team answered "{tiebreak.myAnswer}"</p>}
+            </>
+          ) : (
+            <p style={s.muted}>
+              {tiebreak.status === 'closed'
+                ? (tiebreak.myAnswer ? `Answers closed – your team said "${tiebreak.myAnswer}"` : 'Answers closed – waiting for the result...')
+                : 'The tied teams are answering...'}
+            </p>
+          )}
         </div>
       )}
 
@@ -421,7 +966,7 @@ function App() {
             <h3 style={{ ...s.cardTitle, marginTop: 12 }}>Quiz Complete!</h3>
             {scores.length > 0 && (
               <p style={{ fontWeight: 600, marginTop: 8, color: '#1565C0' }}>
-                Winner: {scores[0]?.name}
+                {winners.length > 1 ? 'Joint winners' : 'Winner'}: {winners.map(e => e.name).join(', ')}
               </p>
             )}
           </div>
@@ -433,7 +978,7 @@ function App() {
 
 // --- Styles ---
 
-const s: Record<string, React.CSSProperties> = {
+const baseStyles: Record<string, React.CSSProperties> = {
   container: { maxWidth: 480, margin: '0 auto', padding: 16, fontFamily: 'inherit' },
   header: { display: 'flex', alignItems: 'center', justifyContent: 'space-between', marginBottom: 16 },
   title: { fontSize: 20, fontWeight: 700, color: '#1565C0' },
@@ -456,6 +1001,61 @@ const s: Record<string, React.CSSProperties> = {
   scoreRank: { fontSize: 14, fontWeight: 700, color: '#999', width: 32 },
   scoreName: { flex: 1, fontSize: 15, fontWeight: 500 },
   scorePoints: { fontSize: 16, fontWeight: 700, color: '#1565C0' },
+  accessibilityToggle: { padding: '4px 10px', borderRadius: 8, border: '1px solid #1565C0', backgroundColor: 'transparent', color: '#1565C0', fontSize: 15, fontWeight: 700, cursor: 'pointer' },
+  accessibilityRow: { display: 'flex', alignItems: 'center', gap: 8, padding: '6px 0', fontSize: 15 },
+  accessibilitySelect: { padding: '6px 8px', borderRadius: 6, border: '1px solid #ddd', fontSize: 14 },
+  reactionBar: { display: 'flex', justifyContent: 'space-between', gap: 6, marginTop: 16 },
+  reactionBtn: { flex: 1, padding: '10px 0', borderRadius: 8, border: '1px solid #ddd', backgroundColor: '#fafafa', fontSize: 24, cursor: 'pointer' },
 };
 
+// Larger type for the question, answer box and buttons
+const largeTextStyles: Record<string, React.CSSProperties> = {
+  title: { fontSize: 26 },
+  cardTitle: { fontSize: 20 },
+  muted: { fontSize: 17 },
+  input: { fontSize: 22 },
+  btnPrimary: { fontSize: 22 },
+  btnOutline: { fontSize: 19 },
+  questionText: { fontSize: 30 },
+  answerInput: { fontSize: 22 },
+  timer: { fontSize: 32 },
+  scoreName: { fontSize: 20 },
+  scorePoints: { fontSize: 20 },
+  accessibilityRow: { fontSize: 19 },
+};
+
+// White and yellow on black, with no pale greys
+const highContrastStyles: Record<string, React.CSSProperties> = {
+  container: { backgroundColor: '#000', color: '#fff', minHeight: '100vh' },
+  title: { color: '#FFEB3B' },
+  sessionName: { color: '#fff' },
+  card: { backgroundColor: '#000', border: '2px solid #fff', boxShadow: 'none' },
+  cardTitle: { color: '#fff' },
+  muted: { color: '#fff' },
+  input: { backgroundColor: '#000', color: '#fff', border: '2px solid #fff' },
+  btnPrimary: { backgroundColor: '#FFEB3B', color: '#000' },
+  btnOutline: { border: '2px solid #FFEB3B', color: '#FFEB3B' },
+  teamCard: { backgroundColor: '#000', border: '2px solid #fff' },
+  roundBadge: { backgroundColor: '#FFEB3B', color: '#000' },
+  questionText: { color: '#fff' },
+  answerInput: { backgroundColor: '#000', color: '#fff', border: '2px solid #fff' },
+  scoreRow: { backgroundColor: '#000', border: '1px solid #fff' },
+  scoreRank: { color: '#fff' },
+  scorePoints: { color: '#FFEB3B' },
+  accessibilityToggle: { border: '2px solid #FFEB3B', color: '#FFEB3B' },
+  accessibilitySelect: { backgroundColor: '#000', color: '#fff', border: '2px solid #fff' },
+  reactionBtn: { backgroundColor: '#000', border: '1px solid #fff' },
+};
+
+// accessibleStyles layers the large text and high contrast overrides over the base styles
+function accessibleStyles(a: Accessibility): Record<string, React.CSSProperties> {
+  const styles: Record<string, React.CSSProperties> = { ...baseStyles };
+  const layers = [a.largeText && largeTextStyles, a.highContrast && highContrastStyles];
+  layers.forEach(layer => {
+    if (!layer) return;
+    Object.entries(layer).forEach(([key, style]) => { styles[key] = { ...styles[key], ...style }; });
+  });
+  return styles;
+}
+
 export default App;
@@ -3,8 +3,9 @@ module rrroll-the-dice
 go 1.25
 
 require (
-	github.com/gorilla/handlers v1.5.2
+	github.com/achgithub/activity-hub-common v0.0.0
 	github.com/gorilla/mux v1.8.1
+	github.com/lib/pq v1.10.9
 )
 
-require github.com/felixge/httpsnoop v1.0.4 // indirect
+replace github.com/achgithub/activity-hub-common => ../../../lib/activity-hub-common
@@ -5,42 +5,95 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"strings"
 
-	"github.com/gorilla/handlers"
+	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/achgithub/activity-hub-common/cli"
+	"github.com/achgithub/activity-hub-common/database"
+	apphttp "github.com/achgithub/activity-hub-common/http"
+	"github.com/achgithub/activity-hub-common/maintenance"
 	"github.com/gorilla/mux"
+	_ "github.com/lib/pq"
 )
 
+const (
+	APP_ID   = "rrroll-the-dice"
+	APP_NAME = "Rrroll the Dice"
+)
+
+// maxDice is how many dice can be rolled at once
+const maxDice = 6
+
+// appConfig is the app's registry entry as the shell reads it from
+// /api/config. There are no challenge options; maxDice is the roller's own setting.
+var appConfig = apphttp.AppConfig{
+	AppID:       APP_ID,
+	Name:        APP_NAME,
+	Icon:        "🎲",
+	Description: "Roll up to 6 dice with style",
+	Extra:       map[string]interface{}{"maxDice": maxDice},
+}
+
 func main() {
+	cli.New("rrroll-the-dice",
+		cli.Serve(serve),
+	).Main()
+}
+
+func serve() {
+	log.Printf("🎲 %s Backend Starting", APP_NAME)
+
+	// Identity database, to recognise signed-in players (the roller works signed out too),
+	// and for the CORS policy and maintenance notices
+	identityDB, err := database.InitIdentityDatabase()
+	if err != nil {
+		log.Fatal("Failed to connect to identity database:", err)
+	}
+	defer identityDB.Close()
+
 	r := mux.NewRouter()
 
-	// API routes
-	r.HandleFunc("/api/config", handleConfig).Methods("GET")
+	// Public endpoints
+	r.HandleFunc("/api/config", apphttp.ConfigHandler(appConfig)).Methods("GET")
+	r.HandleFunc("/api/health", handleHealth).Methods("GET")
+
+	// Optional auth: players and guests opened from the shell are recognised,
+	// as is the dice room screen's kiosk token (dice:room); anyone else rolls anonymously
+	kiosk := authlib.RequireKioskCapability("dice:room")
+	r.Handle("/api/me", authlib.OptionalMiddleware(identityDB)(kiosk(http.HandlerFunc(handleMe)))).Methods("GET")
 
-	// Static files
+	// Serve static frontend files
 	staticDir := "./static"
 	r.PathPrefix("/").Handler(http.FileServer(http.Dir(staticDir)))
 
 	// CORS
-	corsHandler := handlers.CORS(
-		handlers.AllowedOrigins([]string{"*"}),
-		handlers.AllowedMethods([]string{"GET", "POST", "OPTIONS"}),
-		handlers.AllowedHeaders([]string{"Content-Type", "Authorization"}),
-	)(r)
+	cors := apphttp.NewCORSPolicy(identityDB)
+	appMaintenance := maintenance.New(identityDB)
 
 	port := getEnv("PORT", "4071")
-	log.Printf("Rrroll the Dice server starting on port %s", port)
-	log.Fatal(http.ListenAndServe(":"+port, corsHandler))
+	log.Printf("✅ %s server running on port %s", APP_NAME, port)
+	log.Fatal(http.ListenAndServe(":"+port, cors.Middleware(appMaintenance.Middleware(APP_ID)(apphttp.Versioned(r)))))
+}
+
+func handleHealth(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"status":"ok","service":"rrroll-the-dice"}`))
 }
 
-func handleConfig(w http.ResponseWriter, r *http.Request) {
-	config := map[string]interface{}{
-		"appName":     "Rrroll the Dice",
-		"description": "Roll dice with style",
-		"maxDice":     6,
+// handleMe says who is rolling: signedIn is false without a valid token
+func handleMe(w http.ResponseWriter, r *http.Request) {
+	me := map[string]interface{}{"signedIn": false}
+	if user, ok := authlib.GetUserFromContext(r.Context()); ok {
+		me = map[string]interface{}{
+			"signedIn": true,
+			"guest":    strings.HasPrefix(user.Email, "guest-"),
+			"kiosk":    user.Kiosk != nil, // The dice room screen; name is its label
+			"name":     user.Name,
+		}
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(config)
+	json.NewEncoder(w).Encode(me)
 }
 
 func getEnv(key, defaultValue string) string {
@@ -3,6 +3,8 @@
 
 -- Register app in activity_hub.applications table
 -- Run on activity_hub database
+-- required_roles gates the app in the shell grid; leave it empty for everyone,
+-- or e.g. '{"game_manager"}' to limit it to staff
 INSERT INTO applications (
     id,
     name,
@@ -40,7 +42,11 @@ ON CONFLICT (id) DO UPDATE SET
     name = EXCLUDED.name,
     icon = EXCLUDED.icon,
     description = EXCLUDED.description,
+    category = EXCLUDED.category,
     url = EXCLUDED.url,
     backend_port = EXCLUDED.backend_port,
+    realtime = EXCLUDED.realtime,
+    required_roles = EXCLUDED.required_roles,
+    display_order = EXCLUDED.display_order,
     guest_accessible = EXCLUDED.guest_accessible,
     updated_at = CURRENT_TIMESTAMP;
@@ -1,8 +1,30 @@
-import React from 'react';
+import React, { useEffect, useState } from 'react';
 import DiceRoller from './DiceRoller';
 
 function App() {
-  return <DiceRoller maxDice={6} />;
+  const [maxDice, setMaxDice] = useState(6);
+  const [playerName, setPlayerName] = useState<string | undefined>();
+
+  useEffect(() => {
+    fetch('/api/v1/config')
+      .then(res => (res.ok ? res.json() : null))
+      .then(config => {
+        if (config?.maxDice) setMaxDice(config.maxDice);
+      })
+      .catch(err => console.error('Failed to load config:', err));
+
+    // Signing in is optional; the name is only shown when the shell opened the app
+    const token = new URLSearchParams(window.location.search).get('token') || sessionStorage.getItem('token');
+    if (!token) return;
+    fetch('/api/v1/me', { headers: { 'Authorization': `Bearer ${token}` } })
+      .then(res => (res.ok ? res.json() : null))
+      .then(me => {
+        if (me?.signedIn) setPlayerName(me.name);
+      })
+      .catch(err => console.error('Failed to load player:', err));
+  }, []);
+
+  return <DiceRoller maxDice={maxDice} playerName={playerName} />;
 }
 
 export default App;
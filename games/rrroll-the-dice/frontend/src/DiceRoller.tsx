@@ -2,9 +2,10 @@ import React, { useState } from 'react';
 
 interface DiceRollerProps {
   maxDice?: number;
+  playerName?: string; // Set when signed in (or a guest) from the shell
 }
 
-const DiceRoller: React.FC<DiceRollerProps> = ({ maxDice = 6 }) => {
+const DiceRoller: React.FC<DiceRollerProps> = ({ maxDice = 6, playerName }) => {
   const [numDice, setNumDice] = useState(2);
   const [diceValues, setDiceValues] = useState<number[]>([1, 1]);
   const [isRolling, setIsRolling] = useState(false);
@@ -56,6 +57,7 @@ const DiceRoller: React.FC<DiceRollerProps> = ({ maxDice = 6 }) => {
           <h1 className="ah-app-title">🎲 Rrroll the Dice</h1>
         </div>
         <div className="ah-app-header-right">
+          {playerName && <span className="ah-meta">Rolling as {playerName}</span>}
           <button
             className="ah-lobby-btn"
             onClick={() => {
@@ -4,6 +4,8 @@ import (
 	"database/sql"
 	"fmt"
 	"time"
+
+	"github.com/lib/pq"
 )
 
 // InitDatabase initializes the PostgreSQL connection
@@ -54,10 +56,34 @@ func InitIdentityDatabase() (*sql.DB, error) {
 
 // Team represents a pub team
 type Team struct {
+	ID         int       `json:"id"`
+	UserID     string    `json:"userId"`
+	Sport      string    `json:"sport"`
+	Name       string    `json:"name"`
+	DivisionID *int      `json:"divisionId"` // NULL when not in a division
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// Division represents one tier of a league, with its pool of teams
+type Division struct {
+	ID        int       `json:"id"`
+	UserID    string    `json:"userId"`
+	Sport     string    `json:"sport"`
+	Name      string    `json:"name"`
+	Rank      int       `json:"rank"` // 1 = top division
+	CreatedAt time.Time `json:"createdAt"`
+	Teams     []string  `json:"teams"`
+}
+
+// Resource is a dartboard or pool table that fixtures are played on
+type Resource struct {
 	ID        int       `json:"id"`
 	UserID    string    `json:"userId"`
 	Sport     string    `json:"sport"`
 	Name      string    `json:"name"`
+	Venue     string    `json:"venue"`    // Home team whose pub it's in; empty = central venue
+	Slots     []string  `json:"slots"`    // Start times each night; empty = one slot
+	Capacity  int       `json:"capacity"` // Fixtures per slot
 	CreatedAt time.Time `json:"createdAt"`
 }
 
@@ -82,6 +108,9 @@ type Match struct {
 	MatchDate  time.Time `json:"matchDate"`
 	HomeTeam   string    `json:"homeTeam"`
 	AwayTeam   *string   `json:"awayTeam"` // NULL for bye weeks
+	Division   string    `json:"division,omitempty"`
+	Resource   *string   `json:"resource,omitempty"` // NULL when not allocated
+	Slot       string    `json:"slot,omitempty"`
 	MatchOrder int       `json:"matchOrder"`
 	CreatedAt  time.Time `json:"createdAt"`
 }
@@ -98,7 +127,7 @@ type ScheduleDate struct {
 // GetTeams retrieves all teams for a user and sport
 func GetTeams(userID, sport string) ([]Team, error) {
 	query := `
-		SELECT id, user_id, sport, name, created_at
+		SELECT id, user_id, sport, name, division_id, created_at
 		FROM teams
 		WHERE user_id = $1 AND sport = $2
 		ORDER BY name
@@ -113,7 +142,7 @@ func GetTeams(userID, sport string) ([]Team, error) {
 	var teams []Team
 	for rows.Next() {
 		var t Team
-		if err := rows.Scan(&t.ID, &t.UserID, &t.Sport, &t.Name, &t.CreatedAt); err != nil {
+		if err := rows.Scan(&t.ID, &t.UserID, &t.Sport, &t.Name, &t.DivisionID, &t.CreatedAt); err != nil {
 			return nil, fmt.Errorf("failed to scan team: %w", err)
 		}
 		teams = append(teams, t)
@@ -127,12 +156,12 @@ func AddTeam(userID, sport, name string) (*Team, error) {
 	query := `
 		INSERT INTO teams (user_id, sport, name)
 		VALUES ($1, $2, $3)
-		RETURNING id, user_id, sport, name, created_at
+		RETURNING id, user_id, sport, name, division_id, created_at
 	`
 
 	var t Team
 	err := db.QueryRow(query, userID, sport, name).Scan(
-		&t.ID, &t.UserID, &t.Sport, &t.Name, &t.CreatedAt,
+		&t.ID, &t.UserID, &t.Sport, &t.Name, &t.DivisionID, &t.CreatedAt,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to add team: %w", err)
@@ -161,6 +190,174 @@ func DeleteTeam(teamID int, userID string) error {
 	return nil
 }
 
+// SetTeamDivision moves a team into a division, or out of all divisions when divisionID is nil
+func SetTeamDivision(teamID int, divisionID *int, userID string) error {
+	// The division must belong to the same user and sport as the team
+	query := `
+		UPDATE teams t SET division_id = $2
+		WHERE t.id = $1 AND t.user_id = $3
+		  AND ($2::int IS NULL OR EXISTS (
+			SELECT 1 FROM divisions d
+			WHERE d.id = $2 AND d.user_id = t.user_id AND d.sport = t.sport
+		  ))
+	`
+	result, err := db.Exec(query, teamID, divisionID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to set team division: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("team or division not found")
+	}
+
+	return nil
+}
+
+// GetDivisions retrieves a user's divisions for a sport, top division first, with their teams
+func GetDivisions(userID, sport string) ([]Division, error) {
+	query := `
+		SELECT d.id, d.user_id, d.sport, d.name, d.rank, d.created_at,
+		       COALESCE(array_agg(t.name ORDER BY t.name) FILTER (WHERE t.id IS NOT NULL), '{}')
+		FROM divisions d
+		LEFT JOIN teams t ON t.division_id = d.id
+		WHERE d.user_id = $1 AND d.sport = $2
+		GROUP BY d.id
+		ORDER BY d.rank, d.name
+	`
+
+	rows, err := db.Query(query, userID, sport)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query divisions: %w", err)
+	}
+	defer rows.Close()
+
+	divisions := []Division{}
+	for rows.Next() {
+		var d Division
+		var teams pq.StringArray
+		if err := rows.Scan(&d.ID, &d.UserID, &d.Sport, &d.Name, &d.Rank, &d.CreatedAt, &teams); err != nil {
+			return nil, fmt.Errorf("failed to scan division: %w", err)
+		}
+		d.Teams = []string(teams)
+		divisions = append(divisions, d)
+	}
+
+	return divisions, nil
+}
+
+// AddDivision adds a new division below the user's existing ones for that sport
+func AddDivision(userID, sport, name string) (*Division, error) {
+	query := `
+		INSERT INTO divisions (user_id, sport, name, rank)
+		VALUES ($1, $2, $3, (
+			SELECT COALESCE(MAX(rank), 0) + 1 FROM divisions WHERE user_id = $1 AND sport = $2
+		))
+		RETURNING id, user_id, sport, name, rank, created_at
+	`
+
+	d := Division{Teams: []string{}}
+	err := db.QueryRow(query, userID, sport, name).Scan(
+		&d.ID, &d.UserID, &d.Sport, &d.Name, &d.Rank, &d.CreatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add division: %w", err)
+	}
+
+	return &d, nil
+}
+
+// DeleteDivision removes a division; its teams stay but leave the division
+func DeleteDivision(divisionID int, userID string) error {
+	query := `DELETE FROM divisions WHERE id = $1 AND user_id = $2`
+	result, err := db.Exec(query, divisionID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete division: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("division not found or unauthorized")
+	}
+
+	return nil
+}
+
+// GetResources retrieves a user's resources for a sport
+func GetResources(userID, sport string) ([]Resource, error) {
+	query := `
+		SELECT id, user_id, sport, name, venue, slots, capacity, created_at
+		FROM resources
+		WHERE user_id = $1 AND sport = $2
+		ORDER BY id
+	`
+
+	rows, err := db.Query(query, userID, sport)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query resources: %w", err)
+	}
+	defer rows.Close()
+
+	resources := []Resource{}
+	for rows.Next() {
+		var res Resource
+		var slots pq.StringArray
+		if err := rows.Scan(&res.ID, &res.UserID, &res.Sport, &res.Name, &res.Venue, &slots, &res.Capacity, &res.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan resource: %w", err)
+		}
+		res.Slots = []string(slots)
+		resources = append(resources, res)
+	}
+
+	return resources, nil
+}
+
+// AddResource adds a new resource
+func AddResource(res *Resource) error {
+	query := `
+		INSERT INTO resources (user_id, sport, name, venue, slots, capacity)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
+
+	err := db.QueryRow(query, res.UserID, res.Sport, res.Name, res.Venue, pq.Array(res.Slots), res.Capacity).Scan(
+		&res.ID, &res.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to add resource: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteResource removes a resource
+func DeleteResource(resourceID int, userID string) error {
+	query := `DELETE FROM resources WHERE id = $1 AND user_id = $2`
+	result, err := db.Exec(query, resourceID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete resource: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("resource not found or unauthorized")
+	}
+
+	return nil
+}
+
 // SaveSchedule saves a complete schedule with all matches
 func SaveSchedule(sched *Schedule, matches []Match, dates []ScheduleDate) error {
 	tx, err := db.Begin()
@@ -191,11 +388,11 @@ func SaveSchedule(sched *Schedule, matches []Match, dates []ScheduleDate) error
 
 	// Insert matches
 	matchQuery := `
-		INSERT INTO schedule_matches (schedule_id, match_date, home_team, away_team, match_order)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO schedule_matches (schedule_id, match_date, home_team, away_team, division, resource, slot, match_order)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 	`
 	for _, match := range matches {
-		_, err := tx.Exec(matchQuery, sched.ID, match.MatchDate, match.HomeTeam, match.AwayTeam, match.MatchOrder)
+		_, err := tx.Exec(matchQuery, sched.ID, match.MatchDate, match.HomeTeam, match.AwayTeam, match.Division, match.Resource, match.Slot, match.MatchOrder)
 		if err != nil {
 			return fmt.Errorf("failed to insert match: %w", err)
 		}
@@ -269,7 +466,7 @@ func GetSchedule(scheduleID int, userID string) (*Schedule, error) {
 
 	// Get matches
 	matchQuery := `
-		SELECT id, schedule_id, match_date, home_team, away_team, match_order, created_at
+		SELECT id, schedule_id, match_date, home_team, away_team, division, resource, slot, match_order, created_at
 		FROM schedule_matches
 		WHERE schedule_id = $1
 		ORDER BY match_order
@@ -283,7 +480,7 @@ func GetSchedule(scheduleID int, userID string) (*Schedule, error) {
 
 	for rows.Next() {
 		var m Match
-		if err := rows.Scan(&m.ID, &m.ScheduleID, &m.MatchDate, &m.HomeTeam, &m.AwayTeam, &m.MatchOrder, &m.CreatedAt); err != nil {
+		if err := rows.Scan(&m.ID, &m.ScheduleID, &m.MatchDate, &m.HomeTeam, &m.AwayTeam, &m.Division, &m.Resource, &m.Slot, &m.MatchOrder, &m.CreatedAt); err != nil {
 			return nil, fmt.Errorf("failed to scan match: %w", err)
 		}
 		s.Matches = append(s.Matches, m)
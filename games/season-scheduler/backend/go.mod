@@ -3,7 +3,9 @@ module pubgames/season-scheduler
 go 1.25
 
 require (
-	github.com/gorilla/handlers v1.5.2
+	github.com/achgithub/activity-hub-common v0.1.1
 	github.com/gorilla/mux v1.8.1
 	github.com/lib/pq v1.10.9
 )
+
+replace github.com/achgithub/activity-hub-common => ../../../lib/activity-hub-common
@@ -10,26 +10,33 @@ import (
 	"strings"
 	"time"
 
+	apphttp "github.com/achgithub/activity-hub-common/http"
 	"github.com/gorilla/mux"
 )
 
 // handleGetConfig returns app configuration
 func handleGetConfig(w http.ResponseWriter, r *http.Request) {
-	config := map[string]interface{}{
-		"appName": APP_NAME,
-		"sports":  []string{"darts", "pool", "crib"},
-		"features": map[string]bool{
-			"teamManagement":    true,
-			"holidayDetection":  true,
-			"scheduleGeneration": true,
-			"manualReordering":  true,
-			"downloadSchedule":  true,
-			"emailSchedule":     false, // Not implemented yet
+	apphttp.WriteConfig(w, apphttp.AppConfig{
+		AppID:        "season-scheduler",
+		Name:         APP_NAME,
+		Icon:         "🗓️",
+		Description:  "Schedule pub league seasons for Darts, Pool, and Crib",
+		Capabilities: apphttp.Capabilities{Displays: true, Teams: true},
+		Extra: map[string]interface{}{
+			"sports": []string{"darts", "pool", "crib"},
+			"features": map[string]bool{
+				"teamManagement":     true,
+				"holidayDetection":   true,
+				"scheduleGeneration": true,
+				"manualReordering":   true,
+				"downloadSchedule":   true,
+				"emailSchedule":      false, // Not implemented yet
+				"divisions":          true,
+				"promotion":          true,
+				"resources":          true,
+			},
 		},
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(config)
+	})
 }
 
 // handleGetTeams returns all teams for a user and sport
@@ -116,6 +123,246 @@ func handleDeleteTeam(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// handleSetTeamDivision moves a team into a division, or out of one with a null divisionId
+func handleSetTeamDivision(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	vars := mux.Vars(r)
+	teamID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid team ID", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		DivisionID *int `json:"divisionId"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := SetTeamDivision(teamID, req.DivisionID, user.Email); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleGetDivisions returns a user's divisions for a sport with their teams
+func handleGetDivisions(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	sport := r.URL.Query().Get("sport")
+	if sport == "" {
+		http.Error(w, "sport is required", http.StatusBadRequest)
+		return
+	}
+
+	divisions, err := GetDivisions(user.Email, sport)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(divisions)
+}
+
+// handleAddDivision adds a new division at the bottom of the league
+func handleAddDivision(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req struct {
+		Sport string `json:"sport"`
+		Name  string `json:"name"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.Sport == "" || req.Name == "" {
+		http.Error(w, "sport and name are required", http.StatusBadRequest)
+		return
+	}
+
+	division, err := AddDivision(user.Email, req.Sport, req.Name)
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate") {
+			http.Error(w, "Division name already exists", http.StatusConflict)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(division)
+}
+
+// handleDeleteDivision deletes a division, leaving its teams unassigned
+func handleDeleteDivision(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	vars := mux.Vars(r)
+	divisionID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid division ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := DeleteDivision(divisionID, user.Email); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handlePromotion works out next season's draft divisions from final standings
+func handlePromotion(w http.ResponseWriter, r *http.Request) {
+	var req PromotionRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	drafts, err := PromoteRelegate(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"divisions": drafts,
+	})
+}
+
+// handleGetResources returns a user's dartboards/tables for a sport
+func handleGetResources(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	sport := r.URL.Query().Get("sport")
+	if sport == "" {
+		http.Error(w, "sport is required", http.StatusBadRequest)
+		return
+	}
+
+	resources, err := GetResources(user.Email, sport)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resources)
+}
+
+// handleAddResource adds a dartboard/table
+func handleAddResource(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req Resource
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.Sport == "" || req.Name == "" {
+		http.Error(w, "sport and name are required", http.StatusBadRequest)
+		return
+	}
+	if req.Capacity == 0 {
+		req.Capacity = 1
+	}
+	if req.Capacity < 0 {
+		http.Error(w, "capacity must be at least 1", http.StatusBadRequest)
+		return
+	}
+
+	// Drop blank and repeated slots
+	seen := make(map[string]bool)
+	slots := []string{}
+	for _, slot := range req.Slots {
+		slot = strings.TrimSpace(slot)
+		if slot != "" && !seen[slot] {
+			seen[slot] = true
+			slots = append(slots, slot)
+		}
+	}
+	req.Slots = slots
+	req.UserID = user.Email
+
+	if err := AddResource(&req); err != nil {
+		if strings.Contains(err.Error(), "duplicate") {
+			http.Error(w, "Resource name already exists", http.StatusConflict)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(req)
+}
+
+// handleDeleteResource deletes a dartboard/table
+func handleDeleteResource(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	vars := mux.Vars(r)
+	resourceID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid resource ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := DeleteResource(resourceID, user.Email); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 // handleGetHolidays fetches UK bank holidays
 func handleGetHolidays(w http.ResponseWriter, r *http.Request) {
 	startStr := r.URL.Query().Get("start")
@@ -203,6 +450,16 @@ func handleGenerateSchedule(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Fixtures go on the user's dartboards/tables for this sport, if any
+	if user := getUserFromContext(r); user != nil && req.Sport != "" {
+		resources, err := GetResources(user.Email, req.Sport)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		req.Resources = resources
+	}
+
 	response, err := GenerateSchedule(req)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -338,6 +595,37 @@ func handleGetSchedule(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(schedule)
 }
 
+// handleScheduleResources returns the per-night resource usage report for a saved schedule
+func handleScheduleResources(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	vars := mux.Vars(r)
+	scheduleID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid schedule ID", http.StatusBadRequest)
+		return
+	}
+
+	schedule, err := GetSchedule(scheduleID, user.Email)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resources, err := GetResources(user.Email, schedule.Sport)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ResourceUsageReport(schedule.Matches, resources))
+}
+
 // handleDownloadSchedule generates a CSV download
 func handleDownloadSchedule(w http.ResponseWriter, r *http.Request) {
 	user := getUserFromContext(r)
@@ -366,8 +654,22 @@ func handleDownloadSchedule(w http.ResponseWriter, r *http.Request) {
 	csvWriter := csv.NewWriter(w)
 	defer csvWriter.Flush()
 
+	// Multi-division schedules get a Division column, allocated ones Resource and Slot
+	hasDivisions, hasResources := false, false
+	for _, match := range schedule.Matches {
+		hasDivisions = hasDivisions || match.Division != ""
+		hasResources = hasResources || match.Resource != nil
+	}
+
 	// Write header
-	csvWriter.Write([]string{"Date", "Home Team", "Away Team"})
+	header := []string{"Date", "Home Team", "Away Team"}
+	if hasDivisions {
+		header = append([]string{"Division"}, header...)
+	}
+	if hasResources {
+		header = append(header, "Resource", "Slot")
+	}
+	csvWriter.Write(header)
 
 	// Write matches
 	for _, match := range schedule.Matches {
@@ -376,11 +678,22 @@ func handleDownloadSchedule(w http.ResponseWriter, r *http.Request) {
 			awayTeam = *match.AwayTeam
 		}
 
-		csvWriter.Write([]string{
+		record := []string{
 			match.MatchDate.Format("2006-01-02"),
 			match.HomeTeam,
 			awayTeam,
-		})
+		}
+		if hasDivisions {
+			record = append([]string{match.Division}, record...)
+		}
+		if hasResources {
+			resource := ""
+			if match.Resource != nil {
+				resource = *match.Resource
+			}
+			record = append(record, resource, match.Slot)
+		}
+		csvWriter.Write(record)
 	}
 }
 
@@ -6,7 +6,9 @@ import (
 	"net/http"
 	"os"
 
-	"github.com/gorilla/handlers"
+	"github.com/achgithub/activity-hub-common/cli"
+	apphttp "github.com/achgithub/activity-hub-common/http"
+	"github.com/achgithub/activity-hub-common/maintenance"
 	"github.com/gorilla/mux"
 	_ "github.com/lib/pq"
 )
@@ -20,6 +22,16 @@ const (
 )
 
 func main() {
+	cli.New("season-scheduler",
+		cli.Serve(serve),
+		cli.Migrate(InitDatabase, cli.Migrations{
+			Dir:    "../database",
+			Schema: []string{"schema.sql"},
+		}),
+	).Main()
+}
+
+func serve() {
 	log.Printf("🗓️  %s Backend Starting", APP_NAME)
 
 	// Initialize PostgreSQL
@@ -51,6 +63,14 @@ func main() {
 	r.HandleFunc("/api/teams", AuthMiddleware(handleGetTeams)).Methods("GET")
 	r.HandleFunc("/api/teams", AuthMiddleware(handleAddTeam)).Methods("POST")
 	r.HandleFunc("/api/teams/{id}", AuthMiddleware(handleDeleteTeam)).Methods("DELETE")
+	r.HandleFunc("/api/teams/{id}/division", AuthMiddleware(handleSetTeamDivision)).Methods("PUT")
+	r.HandleFunc("/api/divisions", AuthMiddleware(handleGetDivisions)).Methods("GET")
+	r.HandleFunc("/api/divisions", AuthMiddleware(handleAddDivision)).Methods("POST")
+	r.HandleFunc("/api/divisions/{id}", AuthMiddleware(handleDeleteDivision)).Methods("DELETE")
+	r.HandleFunc("/api/resources", AuthMiddleware(handleGetResources)).Methods("GET")
+	r.HandleFunc("/api/resources", AuthMiddleware(handleAddResource)).Methods("POST")
+	r.HandleFunc("/api/resources/{id}", AuthMiddleware(handleDeleteResource)).Methods("DELETE")
+	r.HandleFunc("/api/divisions/promotion", AuthMiddleware(handlePromotion)).Methods("POST")
 	r.HandleFunc("/api/dates/validate", AuthMiddleware(handleValidateDates)).Methods("POST")
 	r.HandleFunc("/api/schedule/generate", AuthMiddleware(handleGenerateSchedule)).Methods("POST")
 	r.HandleFunc("/api/schedule/validate", AuthMiddleware(handleValidateSchedule)).Methods("POST")
@@ -58,6 +78,7 @@ func main() {
 	r.HandleFunc("/api/schedule/{id}", AuthMiddleware(handleSaveSchedule)).Methods("POST")
 	r.HandleFunc("/api/schedules", AuthMiddleware(handleGetSchedules)).Methods("GET")
 	r.HandleFunc("/api/schedules/{id}", AuthMiddleware(handleGetSchedule)).Methods("GET")
+	r.HandleFunc("/api/schedules/{id}/resources", AuthMiddleware(handleScheduleResources)).Methods("GET")
 	r.HandleFunc("/api/schedules/{id}/download", AuthMiddleware(handleDownloadSchedule)).Methods("GET")
 	r.HandleFunc("/api/schedules/{id}/email", AuthMiddleware(handleEmailSchedule)).Methods("POST")
 
@@ -66,17 +87,13 @@ func main() {
 	r.PathPrefix("/").Handler(spaHandler{staticPath: staticDir, indexPath: "index.html"})
 
 	// CORS configuration
-	corsHandler := handlers.CORS(
-		handlers.AllowedOrigins([]string{"*"}),
-		handlers.AllowedMethods([]string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}),
-		handlers.AllowedHeaders([]string{"Content-Type", "Authorization"}),
-		handlers.AllowCredentials(),
-	)
+	cors := apphttp.NewCORSPolicy(identityDB)
+	appMaintenance := maintenance.New(identityDB)
 
 	// Start server
 	port := getEnv("BACKEND_PORT", BACKEND_PORT)
 	log.Printf("🚀 %s backend listening on :%s", APP_NAME, port)
-	log.Fatal(http.ListenAndServe(":"+port, corsHandler(r)))
+	log.Fatal(http.ListenAndServe(":"+port, cors.Middleware(appMaintenance.Middleware("season-scheduler")(r))))
 }
 
 // handleHealth - Health check endpoint
@@ -0,0 +1,91 @@
+package main
+
+import "fmt"
+
+// FinalStandings is a division's end-of-season table, top team first
+type FinalStandings struct {
+	Name      string   `json:"name"`
+	Standings []string `json:"standings"`
+}
+
+// PromotionRequest asks for next season's divisions from this season's tables
+// Divisions are listed top division first
+type PromotionRequest struct {
+	Divisions []FinalStandings `json:"divisions"`
+	Promote   int              `json:"promote"`  // Teams going up from each division below the top
+	Relegate  int              `json:"relegate"` // Teams going down from each division above the bottom
+}
+
+// DraftDivision is one of next season's divisions
+// Teams can be passed straight back to schedule generation as a DivisionRequest
+type DraftDivision struct {
+	Name      string   `json:"name"`
+	Teams     []string `json:"teams"`
+	Promoted  []string `json:"promoted"`  // Came up from the division below
+	Relegated []string `json:"relegated"` // Came down from the division above
+}
+
+// PromoteRelegate swaps the bottom teams of each division with the top teams
+// of the one below it. Teams keep their finishing order within the draft,
+// arrivals from above first and arrivals from below last.
+func PromoteRelegate(req PromotionRequest) ([]DraftDivision, error) {
+	if len(req.Divisions) < 2 {
+		return nil, fmt.Errorf("need at least 2 divisions")
+	}
+	if req.Promote < 0 || req.Relegate < 0 {
+		return nil, fmt.Errorf("promote and relegate can't be negative")
+	}
+
+	seen := make(map[string]string)
+	last := len(req.Divisions) - 1
+	for i, div := range req.Divisions {
+		if div.Name == "" {
+			return nil, fmt.Errorf("every division needs a name")
+		}
+		for _, team := range div.Standings {
+			if other, ok := seen[team]; ok {
+				return nil, fmt.Errorf("team %s is in both %s and %s", team, other, div.Name)
+			}
+			seen[team] = div.Name
+		}
+
+		// A team can't be both promoted and relegated
+		moving := 0
+		if i > 0 {
+			moving += req.Promote
+		}
+		if i < last {
+			moving += req.Relegate
+		}
+		if moving > len(div.Standings) {
+			return nil, fmt.Errorf("division %s has %d teams, too few to move %d", div.Name, len(div.Standings), moving)
+		}
+	}
+
+	drafts := make([]DraftDivision, len(req.Divisions))
+	for i, div := range req.Divisions {
+		top, bottom := 0, len(div.Standings)
+		if i > 0 {
+			top = req.Promote
+		}
+		if i < last {
+			bottom -= req.Relegate
+		}
+
+		draft := DraftDivision{Name: div.Name, Promoted: []string{}, Relegated: []string{}}
+		if i > 0 {
+			above := req.Divisions[i-1].Standings
+			draft.Relegated = append(draft.Relegated, above[len(above)-req.Relegate:]...)
+		}
+		if i < last {
+			draft.Promoted = append(draft.Promoted, req.Divisions[i+1].Standings[:req.Promote]...)
+		}
+
+		draft.Teams = append(draft.Teams, draft.Relegated...)
+		draft.Teams = append(draft.Teams, div.Standings[top:bottom]...)
+		draft.Teams = append(draft.Teams, draft.Promoted...)
+		drafts[i] = draft
+	}
+
+	return drafts, nil
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"sort"
+	"time"
+)
+
+// ResourceUsage is how many fixtures one resource slot has on a night
+type ResourceUsage struct {
+	Resource string `json:"resource"`
+	Slot     string `json:"slot,omitempty"`
+	Used     int    `json:"used"`
+	Capacity int    `json:"capacity"`
+}
+
+// NightUsage is the resource report for one match night
+type NightUsage struct {
+	Date        time.Time       `json:"date"`
+	Usage       []ResourceUsage `json:"usage"`
+	Unallocated int             `json:"unallocated"` // Fixtures with no resource
+	Overbooked  bool            `json:"overbooked"`  // A slot has more fixtures than capacity
+}
+
+// resourceSlots lists a resource's slots, with one unnamed slot when none are set
+func resourceSlots(res Resource) []string {
+	if len(res.Slots) == 0 {
+		return []string{""}
+	}
+	return res.Slots
+}
+
+// slotKey identifies one slot of one resource on one night
+func slotKey(date time.Time, resource, slot string) string {
+	return date.Format("2006-01-02") + "|" + resource + "|" + slot
+}
+
+// AllocateResources gives each fixture a resource and slot without going over
+// any slot's capacity. Resources at the home team's pub are tried first, then
+// central venues. Byes need nothing; fixtures that don't fit are left
+// unallocated and returned.
+func AllocateResources(matches []Match, resources []Resource) []Match {
+	used := make(map[string]int)
+	var unallocated []Match
+
+	for i := range matches {
+		match := &matches[i]
+		match.Resource = nil
+		match.Slot = ""
+		if match.AwayTeam == nil {
+			continue
+		}
+
+		var candidates []Resource
+		for _, res := range resources {
+			if res.Venue == match.HomeTeam {
+				candidates = append(candidates, res)
+			}
+		}
+		for _, res := range resources {
+			if res.Venue == "" {
+				candidates = append(candidates, res)
+			}
+		}
+
+	allocate:
+		for _, res := range candidates {
+			for _, slot := range resourceSlots(res) {
+				key := slotKey(match.MatchDate, res.Name, slot)
+				if used[key] < res.Capacity {
+					used[key]++
+					name := res.Name
+					match.Resource = &name
+					match.Slot = slot
+					break allocate
+				}
+			}
+		}
+
+		if match.Resource == nil {
+			unallocated = append(unallocated, *match)
+		}
+	}
+
+	return unallocated
+}
+
+// ResourceUsageReport summarises resource use on every night with fixtures.
+// It works from the matches as they are, so a schedule that has been
+// reordered since allocation shows any double-booking as overbooked.
+func ResourceUsageReport(matches []Match, resources []Resource) []NightUsage {
+	used := make(map[string]int)
+	nights := make(map[string]*NightUsage)
+	var dates []string
+
+	for _, match := range matches {
+		if match.AwayTeam == nil {
+			continue
+		}
+
+		dateStr := match.MatchDate.Format("2006-01-02")
+		night, ok := nights[dateStr]
+		if !ok {
+			night = &NightUsage{Date: match.MatchDate}
+			nights[dateStr] = night
+			dates = append(dates, dateStr)
+		}
+
+		if match.Resource == nil {
+			night.Unallocated++
+			continue
+		}
+		used[slotKey(match.MatchDate, *match.Resource, match.Slot)]++
+	}
+
+	sort.Strings(dates)
+	report := make([]NightUsage, 0, len(dates))
+	for _, dateStr := range dates {
+		night := nights[dateStr]
+		for _, res := range resources {
+			for _, slot := range resourceSlots(res) {
+				usage := ResourceUsage{
+					Resource: res.Name,
+					Slot:     slot,
+					Used:     used[slotKey(night.Date, res.Name, slot)],
+					Capacity: res.Capacity,
+				}
+				if usage.Used > usage.Capacity {
+					night.Overbooked = true
+				}
+				night.Usage = append(night.Usage, usage)
+			}
+		}
+		report = append(report, *night)
+	}
+
+	return report
+}
@@ -12,15 +12,25 @@ type ExcludedDateRequest struct {
 	Notes string `json:"notes"` // For special events
 }
 
+// DivisionRequest is one division's team pool within a multi-division season
+type DivisionRequest struct {
+	Name  string   `json:"name"`
+	Teams []string `json:"teams"`
+}
+
 // ScheduleRequest represents a request to generate a schedule
+// When Divisions is set, Teams is ignored and each division plays its own
+// round-robin on the same match nights
 type ScheduleRequest struct {
 	UserID       string                 `json:"userId"`
 	Sport        string                 `json:"sport"`
 	Teams        []string               `json:"teams"`
+	Divisions    []DivisionRequest      `json:"divisions,omitempty"`
 	DayOfWeek    string                 `json:"dayOfWeek"`
 	SeasonStart  string                 `json:"seasonStart"`  // Date string in YYYY-MM-DD format
 	SeasonEnd    string                 `json:"seasonEnd"`    // Date string in YYYY-MM-DD format
 	ExcludeDates []ExcludedDateRequest `json:"excludeDates"` // Array of excluded dates with metadata
+	Resources    []Resource             `json:"-"`            // Loaded from the user's resources, not the request
 }
 
 // ScheduleResponse represents the generated schedule
@@ -29,6 +39,7 @@ type ScheduleResponse struct {
 	RequiredDates int          `json:"requiredDates"`
 	Status       string        `json:"status"` // "ok", "too_few_dates", "too_many_dates"
 	Message      string        `json:"message"`
+	ResourceUsage []NightUsage `json:"resourceUsage,omitempty"` // Per-night report when resources are set up
 }
 
 // ScheduleRow represents a single week/date in the schedule
@@ -37,6 +48,9 @@ type ScheduleRow struct {
 	RowType        string    `json:"rowType"` // "match", "catchup", "free", "special", "bye"
 	HomeTeam       string    `json:"homeTeam,omitempty"`
 	AwayTeam       *string   `json:"awayTeam,omitempty"`   // NULL for bye weeks
+	Division       string    `json:"division,omitempty"`   // Set for multi-division seasons
+	Resource       *string   `json:"resource,omitempty"`   // Dartboard/table allocated to the fixture
+	Slot           string    `json:"slot,omitempty"`       // Start time on the resource
 	Notes          string    `json:"notes,omitempty"`      // For special events
 	RowOrder       int       `json:"rowOrder"`             // Order in schedule
 	HolidayWarning string    `json:"holidayWarning,omitempty"` // Warning if near UK bank holiday
@@ -79,22 +93,20 @@ func GenerateSchedule(req ScheduleRequest) (*ScheduleResponse, error) {
 		}
 	}
 
-	// Calculate required matches
-	numTeams := len(req.Teams)
-	if numTeams < 2 {
-		return nil, fmt.Errorf("need at least 2 teams")
+	// A single-division season is one unnamed division
+	divisions := req.Divisions
+	if len(divisions) == 0 {
+		divisions = []DivisionRequest{{Teams: req.Teams}}
 	}
-
-	// Handle odd number of teams
-	hasbye := numTeams%2 == 1
-	adjustedTeams := numTeams
-	if hasbye {
-		adjustedTeams++ // Add phantom team for bye
+	if err := validateDivisions(divisions); err != nil {
+		return nil, err
 	}
 
-	// Each team plays every other team twice (home and away)
-	totalRounds := (adjustedTeams - 1) * 2
-	requiredDates := totalRounds
+	// The biggest division sets how many dates the season needs
+	requiredDates := 0
+	for _, div := range divisions {
+		requiredDates = max(requiredDates, divisionRounds(div.Teams))
+	}
 
 	// Validate date count
 	status := "ok"
@@ -111,14 +123,31 @@ func GenerateSchedule(req ScheduleRequest) (*ScheduleResponse, error) {
 	}
 
 	// Generate matches using round-robin algorithm for available dates
+	// Each division runs over the first dates it needs (a partial schedule if
+	// there are too few), so smaller divisions finish early
 	var matches []Match
-	if len(availableDates) >= requiredDates {
-		fmt.Printf("DEBUG: Generating schedule with %d teams, %d dates, hasbye=%v\n", len(req.Teams), requiredDates, hasbye)
-		matches = generateRoundRobin(req.Teams, availableDates[:requiredDates], hasbye)
-		fmt.Printf("DEBUG: Generated %d matches\n", len(matches))
-	} else if status == "too_few_dates" {
-		// Generate partial schedule
-		matches = generateRoundRobin(req.Teams, availableDates, hasbye)
+	for _, div := range divisions {
+		hasbye := len(div.Teams)%2 == 1
+		divDates := availableDates[:min(divisionRounds(div.Teams), len(availableDates))]
+		for _, match := range generateRoundRobin(div.Teams, divDates, hasbye) {
+			match.Division = div.Name
+			match.MatchOrder = len(matches)
+			matches = append(matches, match)
+		}
+	}
+
+	// Put fixtures on dartboards/tables when the league has them set up
+	var resourceUsage []NightUsage
+	if len(req.Resources) > 0 {
+		unallocated := AllocateResources(matches, req.Resources)
+		if len(unallocated) > 0 {
+			if message != "" {
+				message += "\n\n"
+			}
+			message += fmt.Sprintf("⚠️ %d fixture(s) couldn't be given a resource (first: %s vs %s on %s). Add resources or slots.",
+				len(unallocated), unallocated[0].HomeTeam, *unallocated[0].AwayTeam, unallocated[0].MatchDate.Format("Jan 2"))
+		}
+		resourceUsage = ResourceUsageReport(matches, req.Resources)
 	}
 
 	// Fetch UK bank holidays for warning checks
@@ -180,6 +209,9 @@ func GenerateSchedule(req ScheduleRequest) (*ScheduleResponse, error) {
 					RowType:        "match",
 					HomeTeam:       match.HomeTeam,
 					AwayTeam:       match.AwayTeam,
+					Division:       match.Division,
+					Resource:       match.Resource,
+					Slot:           match.Slot,
 					RowOrder:       rowOrder,
 					HolidayWarning: holidayWarning,
 				})
@@ -200,8 +232,22 @@ func GenerateSchedule(req ScheduleRequest) (*ScheduleResponse, error) {
 
 	fmt.Printf("DEBUG: Created %d total rows from %d dates\n", len(rows), len(allDates))
 
-	// Validate schedule - check every team plays every other team twice
-	validationErrors := validateScheduleBalance(req.Teams, matches)
+	// Validate schedule - check every team plays every other team in its division twice
+	var validationErrors []string
+	for _, div := range divisions {
+		var divMatches []Match
+		for _, match := range matches {
+			if match.Division == div.Name {
+				divMatches = append(divMatches, match)
+			}
+		}
+		for _, err := range validateScheduleBalance(div.Teams, divMatches) {
+			if div.Name != "" {
+				err = div.Name + ": " + err
+			}
+			validationErrors = append(validationErrors, err)
+		}
+	}
 	if len(validationErrors) > 0 {
 		fmt.Printf("WARNING: Schedule validation errors:\n")
 		for _, err := range validationErrors {
@@ -221,9 +267,54 @@ func GenerateSchedule(req ScheduleRequest) (*ScheduleResponse, error) {
 		RequiredDates: requiredDates,
 		Status:        status,
 		Message:       message,
+		ResourceUsage: resourceUsage,
 	}, nil
 }
 
+// validateDivisions checks each division can play a round-robin and that
+// no team is entered in more than one division
+func validateDivisions(divisions []DivisionRequest) error {
+	names := make(map[string]bool)
+	teamDivision := make(map[string]string)
+	for _, div := range divisions {
+		if len(divisions) > 1 {
+			if div.Name == "" {
+				return fmt.Errorf("every division needs a name")
+			}
+			if names[div.Name] {
+				return fmt.Errorf("division %s is listed twice", div.Name)
+			}
+			names[div.Name] = true
+		}
+		if len(div.Teams) < 2 {
+			if div.Name != "" {
+				return fmt.Errorf("division %s needs at least 2 teams", div.Name)
+			}
+			return fmt.Errorf("need at least 2 teams")
+		}
+		for _, team := range div.Teams {
+			if other, seen := teamDivision[team]; seen {
+				if other == div.Name {
+					return fmt.Errorf("team %s is listed twice", team)
+				}
+				return fmt.Errorf("team %s is in both %s and %s", team, other, div.Name)
+			}
+			teamDivision[team] = div.Name
+		}
+	}
+	return nil
+}
+
+// divisionRounds is the number of match nights a double round-robin needs
+// Odd-sized divisions get a phantom team, so one team has a bye each round
+func divisionRounds(teams []string) int {
+	numTeams := len(teams)
+	if numTeams%2 == 1 {
+		numTeams++
+	}
+	return (numTeams - 1) * 2
+}
+
 // validateScheduleBalance checks that every team plays every other team exactly twice (once home, once away)
 func validateScheduleBalance(teams []string, matches []Match) []string {
 	var errors []string
@@ -6,6 +6,19 @@ DROP TABLE IF EXISTS schedule_matches CASCADE;
 DROP TABLE IF EXISTS schedule_dates CASCADE;
 DROP TABLE IF EXISTS schedules CASCADE;
 DROP TABLE IF EXISTS teams CASCADE;
+DROP TABLE IF EXISTS divisions CASCADE;
+DROP TABLE IF EXISTS resources CASCADE;
+
+-- Divisions table (rank 1 is the top division)
+CREATE TABLE divisions (
+    id SERIAL PRIMARY KEY,
+    user_id VARCHAR(255) NOT NULL,
+    sport VARCHAR(50) NOT NULL CHECK (sport IN ('darts', 'pool', 'crib')),
+    name VARCHAR(100) NOT NULL,
+    rank INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(user_id, sport, name)
+);
 
 -- Teams table
 CREATE TABLE teams (
@@ -13,6 +26,20 @@ CREATE TABLE teams (
     user_id VARCHAR(255) NOT NULL,
     sport VARCHAR(50) NOT NULL CHECK (sport IN ('darts', 'pool', 'crib')),
     name VARCHAR(255) NOT NULL,
+    division_id INTEGER REFERENCES divisions(id) ON DELETE SET NULL,  -- NULL = not in a division
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(user_id, sport, name)
+);
+
+-- Resources table (dartboards, pool tables) fixtures are played on
+CREATE TABLE resources (
+    id SERIAL PRIMARY KEY,
+    user_id VARCHAR(255) NOT NULL,
+    sport VARCHAR(50) NOT NULL CHECK (sport IN ('darts', 'pool', 'crib')),
+    name VARCHAR(255) NOT NULL,
+    venue VARCHAR(255) NOT NULL DEFAULT '',  -- Home team whose pub it's in; empty = central venue
+    slots TEXT[] NOT NULL DEFAULT '{}',  -- Start times each night; empty = one slot
+    capacity INTEGER NOT NULL DEFAULT 1 CHECK (capacity > 0),  -- Fixtures per slot
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     UNIQUE(user_id, sport, name)
 );
@@ -48,13 +75,18 @@ CREATE TABLE schedule_matches (
     match_date DATE NOT NULL,
     home_team VARCHAR(255) NOT NULL,
     away_team VARCHAR(255),  -- NULL for bye weeks
+    division VARCHAR(100) NOT NULL DEFAULT '',  -- Empty for single-division seasons
+    resource VARCHAR(255),  -- NULL when not allocated
+    slot VARCHAR(20) NOT NULL DEFAULT '',
     match_order INTEGER NOT NULL,  -- For manual rearrangement
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     CONSTRAINT unique_match_per_schedule UNIQUE(schedule_id, match_order)
 );
 
 -- Indexes for performance
+CREATE INDEX idx_divisions_user_sport ON divisions(user_id, sport);
 CREATE INDEX idx_teams_user_sport ON teams(user_id, sport);
+CREATE INDEX idx_resources_user_sport ON resources(user_id, sport);
 CREATE INDEX idx_schedules_user ON schedules(user_id);
 CREATE INDEX idx_schedules_created ON schedules(created_at);
 CREATE INDEX idx_schedule_matches_schedule ON schedule_matches(schedule_id);
@@ -6,9 +6,32 @@ interface Team {
   userId: string;
   sport: string;
   name: string;
+  divisionId: number | null;
   createdAt: string;
 }
 
+interface Division {
+  id: number;
+  name: string;
+  rank: number;
+  teams: string[];
+}
+
+interface Resource {
+  id: number;
+  name: string;
+  venue: string; // Home team whose pub it's in; empty = central venue
+  slots: string[];
+  capacity: number;
+}
+
+interface NightUsage {
+  date: string;
+  usage: { resource: string; slot?: string; used: number; capacity: number }[];
+  unallocated: number;
+  overbooked: boolean;
+}
+
 interface ExcludedDate {
   date: string;
   type: 'catchup' | 'free' | 'special';
@@ -20,6 +43,9 @@ interface ScheduleRow {
   rowType: 'match' | 'catchup' | 'free' | 'special' | 'bye';
   homeTeam?: string;
   awayTeam?: string | null;
+  division?: string; // Set for multi-division seasons
+  resource?: string | null; // Dartboard/table allocated to the fixture
+  slot?: string;
   notes?: string;
   rowOrder: number;
   holidayWarning?: string; // Warning if near UK bank holiday
@@ -33,6 +59,9 @@ interface Match {
   matchDate: string;
   homeTeam: string;
   awayTeam: string | null;
+  division?: string;
+  resource?: string | null;
+  slot?: string;
   matchOrder: number;
   createdAt?: string;
 }
@@ -57,7 +86,7 @@ const App: React.FC = () => {
   const params = new URLSearchParams(window.location.search);
   const userId = params.get('userId');
   const userName = params.get('userName') || 'User';
-  const token = params.get('token');
+  const token = params.get('token') || sessionStorage.getItem('token');
 
   // All hooks must be called before any conditional returns
   // State
@@ -65,6 +94,11 @@ const App: React.FC = () => {
   const [sport, setSport] = useState<string>('darts');
   const [teams, setTeams] = useState<Team[]>([]);
   const [newTeamName, setNewTeamName] = useState('');
+  const [divisions, setDivisions] = useState<Division[]>([]);
+  const [newDivisionName, setNewDivisionName] = useState('');
+  const [resources, setResources] = useState<Resource[]>([]);
+  const [newResource, setNewResource] = useState({ name: '', venue: '', slots: '', capacity: 1 });
+  const [resourceUsage, setResourceUsage] = useState<NightUsage[]>([]);
   const [dayOfWeek, setDayOfWeek] = useState('wednesday');
   const [seasonStart, setSeasonStart] = useState('');
   const [seasonEnd, setSeasonEnd] = useState('');
@@ -106,6 +140,40 @@ const App: React.FC = () => {
     }
   }, [API_BASE, token, sport]);
 
+  const loadDivisions = useCallback(async () => {
+    try {
+      const res = await fetch(`${API_BASE}/api/divisions?sport=${sport}`, {
+        headers: {
+          'Authorization': `Bearer ${token}`,
+        },
+      });
+      if (!res.ok) {
+        throw new Error('Failed to load divisions');
+      }
+      const data = await res.json();
+      setDivisions(data || []);
+    } catch (err) {
+      console.error('Failed to load divisions:', err);
+    }
+  }, [API_BASE, token, sport]);
+
+  const loadResources = useCallback(async () => {
+    try {
+      const res = await fetch(`${API_BASE}/api/resources?sport=${sport}`, {
+        headers: {
+          'Authorization': `Bearer ${token}`,
+        },
+      });
+      if (!res.ok) {
+        throw new Error('Failed to load resources');
+      }
+      const data = await res.json();
+      setResources(data || []);
+    } catch (err) {
+      console.error('Failed to load resources:', err);
+    }
+  }, [API_BASE, token, sport]);
+
   const loadSavedSchedules = useCallback(async () => {
     try {
       const res = await fetch(`${API_BASE}/api/schedules`, {
@@ -128,12 +196,14 @@ const App: React.FC = () => {
     }
   }, [API_BASE, token]);
 
-  // Load teams when sport changes
+  // Load teams, divisions and resources when sport changes
   useEffect(() => {
     if (userId) {
       loadTeams();
+      loadDivisions();
+      loadResources();
     }
-  }, [loadTeams, userId]);
+  }, [loadTeams, loadDivisions, loadResources, userId]);
 
   // Load saved schedules on mount
   useEffect(() => {
@@ -193,6 +263,114 @@ const App: React.FC = () => {
     }
   };
 
+  const addDivision = async () => {
+    if (!newDivisionName.trim()) return;
+
+    try {
+      const res = await fetch(`${API_BASE}/api/divisions`, {
+        method: 'POST',
+        headers: {
+          'Content-Type': 'application/json',
+          'Authorization': `Bearer ${token}`,
+        },
+        body: JSON.stringify({ sport, name: newDivisionName }),
+      });
+
+      if (res.ok) {
+        setNewDivisionName('');
+        loadDivisions();
+      } else {
+        const errorText = await res.text();
+        alert(`Failed to add division: ${errorText}`);
+      }
+    } catch (err) {
+      console.error('Failed to add division:', err);
+      alert('Failed to add division');
+    }
+  };
+
+  const deleteDivision = async (divisionId: number) => {
+    try {
+      await fetch(`${API_BASE}/api/divisions/${divisionId}`, {
+        method: 'DELETE',
+        headers: {
+          'Authorization': `Bearer ${token}`,
+        },
+      });
+      loadDivisions();
+      loadTeams();
+    } catch (err) {
+      console.error('Failed to delete division:', err);
+    }
+  };
+
+  const setTeamDivision = async (teamId: number, divisionId: number | null) => {
+    try {
+      const res = await fetch(`${API_BASE}/api/teams/${teamId}/division`, {
+        method: 'PUT',
+        headers: {
+          'Content-Type': 'application/json',
+          'Authorization': `Bearer ${token}`,
+        },
+        body: JSON.stringify({ divisionId }),
+      });
+      if (!res.ok) {
+        const errorText = await res.text();
+        alert(`Failed to move team: ${errorText}`);
+      }
+      loadDivisions();
+      loadTeams();
+    } catch (err) {
+      console.error('Failed to move team:', err);
+    }
+  };
+
+  const addResource = async () => {
+    if (!newResource.name.trim()) return;
+
+    try {
+      const res = await fetch(`${API_BASE}/api/resources`, {
+        method: 'POST',
+        headers: {
+          'Content-Type': 'application/json',
+          'Authorization': `Bearer ${token}`,
+        },
+        body: JSON.stringify({
+          sport,
+          name: newResource.name,
+          venue: newResource.venue,
+          slots: newResource.slots.split(','),
+          capacity: newResource.capacity,
+        }),
+      });
+
+      if (res.ok) {
+        setNewResource({ name: '', venue: '', slots: '', capacity: 1 });
+        loadResources();
+      } else {
+        const errorText = await res.text();
+        alert(`Failed to add resource: ${errorText}`);
+      }
+    } catch (err) {
+      console.error('Failed to add resource:', err);
+      alert('Failed to add resource');
+    }
+  };
+
+  const deleteResource = async (resourceId: number) => {
+    try {
+      await fetch(`${API_BASE}/api/resources/${resourceId}`, {
+        method: 'DELETE',
+        headers: {
+          'Authorization': `Bearer ${token}`,
+        },
+      });
+      loadResources();
+    } catch (err) {
+      console.error('Failed to delete resource:', err);
+    }
+  };
+
   const addExcludeDateWithType = (type: 'catchup' | 'free' | 'special') => {
     if (!newExcludeDate) {
       alert('Please select a date first');
@@ -247,10 +425,20 @@ const App: React.FC = () => {
       return;
     }
 
+    // Once any team is in a division, each division plays its own round-robin
+    const usesDivisions = teams.some(t => t.divisionId !== null);
+    if (usesDivisions && teams.some(t => t.divisionId === null)) {
+      alert('Put every team in a division, or take them all out');
+      return;
+    }
+
     setLoading(true);
     setError('');
     try {
       const teamNames = teams.map(t => t.name);
+      const seasonDivisions = usesDivisions
+        ? divisions.filter(d => d.teams.length > 0).map(d => ({ name: d.name, teams: d.teams }))
+        : undefined;
 
       const res = await fetch(`${API_BASE}/api/schedule/generate`, {
         method: 'POST',
@@ -261,6 +449,7 @@ const App: React.FC = () => {
         body: JSON.stringify({
           sport,
           teams: teamNames,
+          divisions: seasonDivisions,
           dayOfWeek,
           seasonStart,
           seasonEnd,
@@ -289,6 +478,7 @@ const App: React.FC = () => {
       const rowsWithConflicts = detectConflicts(data.rows || []);
       setScheduleRows(rowsWithConflicts);
       setScheduleMessage(data.message || '');
+      setResourceUsage(data.resourceUsage || []);
 
       if (data.status === 'ok' || data.status === 'too_many_dates') {
         setActiveTab('schedule');
@@ -599,6 +789,9 @@ const App: React.FC = () => {
             matchDate: toRFC3339(row.date),
             homeTeam: row.homeTeam!,
             awayTeam: row.awayTeam || null,
+            division: row.division,
+            resource: row.resource || null,
+            slot: row.slot,
             matchOrder: matchOrder++,
           });
           // Only add to schedule_dates if this date isn't already recorded
@@ -809,6 +1002,18 @@ const App: React.FC = () => {
               {teams.map((team) => (
                 <li key={team.id} style={{ display: 'flex', justifyContent: 'space-between', alignItems: 'center', padding: '10px', backgroundColor: '#f0f0f0', marginBottom: '5px', borderRadius: '4px' }}>
                   <span>{team.name}</span>
+                  {divisions.length > 0 && (
+                    <select
+                      value={team.divisionId ?? ''}
+                      onChange={(e) => setTeamDivision(team.id, e.target.value ? Number(e.target.value) : null)}
+                      style={{ marginLeft: 'auto', marginRight: '10px', padding: '4px', border: '1px solid #ddd', borderRadius: '4px' }}
+                    >
+                      <option value="">No division</option>
+                      {divisions.map((d) => (
+                        <option key={d.id} value={d.id}>{d.name}</option>
+                      ))}
+                    </select>
+                  )}
                   <button onClick={() => deleteTeam(team.id)} style={{ padding: '5px 10px', backgroundColor: '#f44336', color: '#fff', border: 'none', borderRadius: '4px', cursor: 'pointer', fontSize: '12px' }}>
                     Delete
                   </button>
@@ -817,6 +1022,96 @@ const App: React.FC = () => {
             </ul>
           </div>
 
+          {/* Divisions (optional) */}
+          <div style={{ marginBottom: '20px' }}>
+            <h3>Divisions</h3>
+            <p style={{ fontSize: '13px', color: '#666', marginTop: 0 }}>
+              Optional. Top division first; each division plays its own fixtures on the same nights.
+            </p>
+            <div style={{ display: 'flex', gap: '10px', marginBottom: '10px' }}>
+              <input
+                type="text"
+                placeholder="Division name"
+                value={newDivisionName}
+                onChange={(e) => setNewDivisionName(e.target.value)}
+                onKeyPress={(e) => e.key === 'Enter' && addDivision()}
+                style={{ flex: 1, padding: '8px', border: '1px solid #ddd', borderRadius: '4px' }}
+              />
+              <button onClick={addDivision} style={{ padding: '8px 16px', backgroundColor: '#4CAF50', color: '#fff', border: 'none', borderRadius: '4px', cursor: 'pointer' }}>
+                Add Division
+              </button>
+            </div>
+            <ul style={{ listStyle: 'none', padding: 0 }}>
+              {divisions.map((division) => (
+                <li key={division.id} style={{ display: 'flex', justifyContent: 'space-between', alignItems: 'center', padding: '10px', backgroundColor: '#f0f0f0', marginBottom: '5px', borderRadius: '4px' }}>
+                  <span>{division.name} ({division.teams.length} teams)</span>
+                  <button onClick={() => deleteDivision(division.id)} style={{ padding: '5px 10px', backgroundColor: '#f44336', color: '#fff', border: 'none', borderRadius: '4px', cursor: 'pointer', fontSize: '12px' }}>
+                    Delete
+                  </button>
+                </li>
+              ))}
+            </ul>
+          </div>
+
+          {/* Resources (optional) */}
+          <div style={{ marginBottom: '20px' }}>
+            <h3>Boards &amp; Tables</h3>
+            <p style={{ fontSize: '13px', color: '#666', marginTop: 0 }}>
+              Optional. Fixtures are put on the home pub's boards/tables first, then central venues, never double-booked.
+            </p>
+            <div style={{ display: 'flex', gap: '10px', marginBottom: '10px', flexWrap: 'wrap' }}>
+              <input
+                type="text"
+                placeholder="Name (e.g. Board 1)"
+                value={newResource.name}
+                onChange={(e) => setNewResource({ ...newResource, name: e.target.value })}
+                style={{ flex: 1, padding: '8px', border: '1px solid #ddd', borderRadius: '4px' }}
+              />
+              <select
+                value={newResource.venue}
+                onChange={(e) => setNewResource({ ...newResource, venue: e.target.value })}
+                style={{ padding: '8px', border: '1px solid #ddd', borderRadius: '4px' }}
+              >
+                <option value="">Central venue</option>
+                {teams.map((team) => (
+                  <option key={team.id} value={team.name}>At {team.name}</option>
+                ))}
+              </select>
+              <input
+                type="text"
+                placeholder="Slots (e.g. 19:30, 21:00)"
+                value={newResource.slots}
+                onChange={(e) => setNewResource({ ...newResource, slots: e.target.value })}
+                style={{ flex: 1, padding: '8px', border: '1px solid #ddd', borderRadius: '4px' }}
+              />
+              <input
+                type="number"
+                min={1}
+                title="Fixtures per slot"
+                value={newResource.capacity}
+                onChange={(e) => setNewResource({ ...newResource, capacity: Number(e.target.value) || 1 })}
+                style={{ width: '60px', padding: '8px', border: '1px solid #ddd', borderRadius: '4px' }}
+              />
+              <button onClick={addResource} style={{ padding: '8px 16px', backgroundColor: '#4CAF50', color: '#fff', border: 'none', borderRadius: '4px', cursor: 'pointer' }}>
+                Add
+              </button>
+            </div>
+            <ul style={{ listStyle: 'none', padding: 0 }}>
+              {resources.map((resource) => (
+                <li key={resource.id} style={{ display: 'flex', justifyContent: 'space-between', alignItems: 'center', padding: '10px', backgroundColor: '#f0f0f0', marginBottom: '5px', borderRadius: '4px' }}>
+                  <span>
+                    {resource.name} ({resource.venue ? `at ${resource.venue}` : 'central'}
+                    {resource.slots.length > 0 && `, ${resource.slots.join(' / ')}`}
+                    {resource.capacity > 1 && `, ${resource.capacity} per slot`})
+                  </span>
+                  <button onClick={() => deleteResource(resource.id)} style={{ padding: '5px 10px', backgroundColor: '#f44336', color: '#fff', border: 'none', borderRadius: '4px', cursor: 'pointer', fontSize: '12px' }}>
+                    Delete
+                  </button>
+                </li>
+              ))}
+            </ul>
+          </div>
+
           {/* Season Settings */}
           <div style={{ marginBottom: '20px' }}>
             <h3>Season Settings</h3>
@@ -996,6 +1291,25 @@ const App: React.FC = () => {
             </div>
           )}
 
+          {resourceUsage.length > 0 && (
+            <details style={{ marginBottom: '20px' }}>
+              <summary style={{ cursor: 'pointer', fontWeight: 'bold' }}>🎯 Board/table usage per night</summary>
+              <table style={{ width: '100%', borderCollapse: 'collapse', marginTop: '10px', fontSize: '13px' }}>
+                <tbody>
+                  {resourceUsage.map((night) => (
+                    <tr key={night.date} style={{ borderBottom: '1px solid #eee', color: night.overbooked || night.unallocated > 0 ? '#d32f2f' : undefined }}>
+                      <td style={{ padding: '5px', fontWeight: 'bold' }}>{new Date(night.date).toLocaleDateString()}</td>
+                      <td style={{ padding: '5px' }}>
+                        {night.usage.map((u) => `${u.resource}${u.slot ? ` @ ${u.slot}` : ''}: ${u.used}/${u.capacity}`).join(' · ')}
+                      </td>
+                      <td style={{ padding: '5px' }}>{night.unallocated > 0 && `${night.unallocated} unallocated`}</td>
+                    </tr>
+                  ))}
+                </tbody>
+              </table>
+            </details>
+          )}
+
           {scheduleRows.length === 0 ? (
             <p>No schedule generated yet. Go to Setup tab to create one.</p>
           ) : (
@@ -1149,7 +1463,7 @@ const App: React.FC = () => {
                         </div>
                       )}
                       {row.rowType === 'match' && (
-                        <>{row.homeTeam} vs {row.awayTeam || 'BYE'}</>
+                        <>{row.division && <strong>{row.division}: </strong>}{row.homeTeam} vs {row.awayTeam || 'BYE'}{row.resource && <span style={{ color: '#666' }}> · 🎯 {row.resource}{row.slot && ` @ ${row.slot}`}</span>}</>
                       )}
                       {(row.rowType === 'catchup' || row.rowType === 'free' || row.rowType === 'special') && (
                         <span style={{ fontStyle: 'italic', color: '#666' }}>{row.notes || ''}</span>
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/achgithub/activity-hub-common/audit"
+)
+
+// auditLog is setup_admin_db's audit_log, served at GET /api/audit
+var auditLog *audit.Log
+
+// Snapshot queries for identity rows whose changes are audited with
+// before/after copies. Each selects one row by $1.
+const (
+	// Never the code hash - the audit log is readable by every setup admin
+	userSnapshot       = `SELECT email, name, is_admin, roles, is_active, venue_id FROM users WHERE email = $1`
+	appSnapshot        = `SELECT * FROM applications WHERE id = $1`
+	flagSnapshot       = `SELECT * FROM feature_flags WHERE key = $1`
+	venueSnapshot      = `SELECT * FROM venues WHERE id = $1`
+	venueHoursSnapshot = `
+		SELECT v.id AS venue_id,
+		       COALESCE(s.lobby_closed, FALSE) AS lobby_closed,
+		       COALESCE((
+		           SELECT json_agg(json_build_object('day', h.day_of_week, 'opens', h.opens, 'closes', h.closes)
+		                           ORDER BY h.day_of_week, h.opens)
+		           FROM venue_hours h WHERE h.venue_id = v.id
+		       ), '[]') AS hours
+		FROM venues v
+		LEFT JOIN venue_hours_settings s ON s.venue_id = v.id
+		WHERE v.id = $1`
+)
+
+// snapshot copies an identity row for the audit log, or returns nil if it
+// can't be read - the change itself has already happened or is about to.
+func snapshot(query string, id interface{}) json.RawMessage {
+	row, err := audit.Snapshot(identityDB, query, id)
+	if err != nil {
+		log.Printf("Warning: Failed to snapshot %v for audit log: %v", id, err)
+	}
+	return row
+}
+
+// logAudit logs an admin action to the audit log
+// Admin email and impersonating super_user (if any) come from headers set by requireSetupAdmin
+func logAudit(r *http.Request, actionType, targetID string, details map[string]interface{}) {
+	logAuditChange(r, actionType, targetID, details, nil, nil)
+}
+
+// logAuditChange logs an admin action with the changed row before and after,
+// from snapshot. before is nil for something created, after for something deleted.
+func logAuditChange(r *http.Request, actionType, targetID string, details map[string]interface{}, before, after json.RawMessage) {
+	e := audit.Entry{
+		AdminEmail:     r.Header.Get("X-Admin-Email"),
+		ImpersonatedBy: r.Header.Get("X-Impersonated-By"),
+		Action:         actionType,
+		TargetID:       targetID,
+		Before:         before,
+		After:          after,
+	}
+	if details != nil {
+		e.Details, _ = json.Marshal(details)
+	}
+	if err := auditLog.Record(e); err != nil {
+		log.Printf("Warning: Failed to log audit action: %v", err)
+	}
+}
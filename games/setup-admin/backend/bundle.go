@@ -0,0 +1,787 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	apphttp "github.com/achgithub/activity-hub-common/http"
+	"github.com/lib/pq"
+)
+
+// Configuration bundles copy a working setup to a second Pi, or put it back
+// after reimaging: venues, apps, users and their roles, feature flags and the
+// display roster, exported as one signed JSON file. Login codes and display
+// tokens never leave the box - imported users get new codes and imported TVs
+// pair again. Venues are referred to by slug, since IDs differ between boxes.
+//
+// Import merges: rows in the bundle are created or updated, nothing else is
+// touched or deleted.
+
+const (
+	bundleFormat  = "activity-hub-config"
+	bundleVersion = 1
+)
+
+// maxBundleSize caps an uploaded bundle; a busy pub's is well under 1MB
+const maxBundleSize = 10 << 20
+
+// devBundleKey is only for local development (ACTIVITY_HUB_ENV=development).
+// Both boxes must be given the same CONFIG_BUNDLE_KEY to move a bundle
+// between them.
+const devBundleKey = "activity-hub-dev-bundle-key"
+
+var errNoBundleKey = errors.New("CONFIG_BUNDLE_KEY is not set")
+
+var warnDevBundleKey sync.Once
+
+// displayLayouts are the layouts Display Admin knows (display-admin layouts.go)
+var displayLayouts = map[string]bool{"fullscreen": true, "sidebar": true, "ticker": true, "sidebar_ticker": true}
+
+// configBundle is the exported file. Signature covers Config byte for byte.
+type configBundle struct {
+	Format     string          `json:"format"`
+	Version    int             `json:"version"`
+	ExportedAt time.Time       `json:"exportedAt"`
+	ExportedBy string          `json:"exportedBy"`
+	Config     json.RawMessage `json:"config"`
+	Signature  string          `json:"signature"`
+}
+
+// platformConfig is what a bundle carries.
+type platformConfig struct {
+	Venues   []bundleVenue   `json:"venues"`
+	Apps     []bundleApp     `json:"apps"`
+	Roles    []string        `json:"roles"` // Every role granted by users, apps and flags
+	Users    []bundleUser    `json:"users"`
+	Flags    []bundleFlag    `json:"flags"`
+	Displays []bundleDisplay `json:"displays"`
+}
+
+type bundleVenue struct {
+	Slug    string `json:"slug"`
+	Name    string `json:"name"`
+	Address string `json:"address"`
+	Active  bool   `json:"active"`
+}
+
+type bundleApp struct {
+	ID              string   `json:"id"`
+	Name            string   `json:"name"`
+	Icon            string   `json:"icon"`
+	Type            string   `json:"type"`
+	Description     string   `json:"description"`
+	Category        string   `json:"category"`
+	URL             string   `json:"url"`
+	BackendPort     *int     `json:"backendPort"`
+	Realtime        string   `json:"realtime"`
+	MinPlayers      *int     `json:"minPlayers"`
+	MaxPlayers      *int     `json:"maxPlayers"`
+	RequiredRoles   []string `json:"requiredRoles"`
+	Enabled         bool     `json:"enabled"`
+	DisplayOrder    int      `json:"displayOrder"`
+	GuestAccessible bool     `json:"guestAccessible"`
+	Venues          []string `json:"venues"` // Slugs; empty = every venue
+}
+
+// bundleUser is a user without their login code.
+type bundleUser struct {
+	Email  string   `json:"email"`
+	Name   string   `json:"name"`
+	Roles  []string `json:"roles"`
+	Active bool     `json:"active"`
+	Venue  string   `json:"venue"` // Slug; empty = chain-wide
+}
+
+type bundleFlag struct {
+	Key         string   `json:"key"`
+	Description string   `json:"description"`
+	Enabled     bool     `json:"enabled"`
+	Venues      []string `json:"venues"` // Slugs; empty = every venue
+	Roles       []string `json:"roles"`
+}
+
+// bundleDisplay is a TV without its token.
+type bundleDisplay struct {
+	GUID        string `json:"guid"`
+	Name        string `json:"name"`
+	Location    string `json:"location"`
+	Description string `json:"description"`
+	Venue       string `json:"venue"` // Slug; empty = chain-wide
+	Layout      string `json:"layout"`
+	Active      bool   `json:"active"`
+}
+
+// changeCount is how many rows of one kind an import created and updated.
+type changeCount struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+}
+
+func (c *changeCount) add(created bool) {
+	if created {
+		c.Created++
+	} else {
+		c.Updated++
+	}
+}
+
+// importSummary is the response to an import (or a dry run of one).
+type importSummary struct {
+	DryRun     bool           `json:"dryRun"`
+	ExportedAt time.Time      `json:"exportedAt"`
+	ExportedBy string         `json:"exportedBy"`
+	Venues     changeCount    `json:"venues"`
+	Apps       changeCount    `json:"apps"`
+	Users      changeCount    `json:"users"`
+	Flags      changeCount    `json:"flags"`
+	Displays   changeCount    `json:"displays"`
+	Roles      []string       `json:"roles"`
+	NewUsers   []importedUser `json:"newUsers"` // With their new login codes; empty on a dry run
+	Skipped    []string       `json:"skipped"`
+}
+
+// importedUser is a user created by an import with their one-time login code.
+type importedUser struct {
+	Email string `json:"email"`
+	Name  string `json:"name"`
+	Code  string `json:"code"`
+}
+
+// bundleKey returns CONFIG_BUNDLE_KEY. Only a development box falls back to
+// devBundleKey: it's public, so anywhere else a bundle signed with it could
+// be forged.
+func bundleKey() ([]byte, error) {
+	if key := getEnv("CONFIG_BUNDLE_KEY", ""); key != "" {
+		return []byte(key), nil
+	}
+	if apphttp.Environment() != "development" {
+		return nil, errNoBundleKey
+	}
+	warnDevBundleKey.Do(func() {
+		log.Printf("⚠️  CONFIG_BUNDLE_KEY not set - signing configuration bundles with the development key")
+	})
+	return []byte(devBundleKey), nil
+}
+
+func signBundle(key, config []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(config)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// requireBundleKey writes a 500 and returns false if bundles can't be signed
+func requireBundleKey(w http.ResponseWriter) ([]byte, bool) {
+	key, err := bundleKey()
+	if err != nil {
+		log.Printf("Error: %v - set it for setup-admin to export or import configuration", err)
+		http.Error(w, "Configuration bundles are disabled - CONFIG_BUNDLE_KEY is not set for setup-admin", http.StatusInternalServerError)
+		return nil, false
+	}
+	return key, true
+}
+
+// handleExportConfig downloads the platform configuration as a signed bundle
+// GET /api/config/export
+func handleExportConfig(w http.ResponseWriter, r *http.Request) {
+	if !requireChainWide(w, r) {
+		return
+	}
+	key, ok := requireBundleKey(w)
+	if !ok {
+		return
+	}
+
+	cfg, err := loadPlatformConfig()
+	if err != nil {
+		log.Printf("Error exporting configuration: %v", err)
+		http.Error(w, "Failed to export configuration", http.StatusInternalServerError)
+		return
+	}
+	config, err := json.Marshal(cfg)
+	if err != nil {
+		log.Printf("Error encoding configuration: %v", err)
+		http.Error(w, "Failed to export configuration", http.StatusInternalServerError)
+		return
+	}
+
+	bundle := configBundle{
+		Format:     bundleFormat,
+		Version:    bundleVersion,
+		ExportedAt: time.Now().UTC(),
+		ExportedBy: r.Header.Get("X-Admin-Email"),
+		Config:     config,
+		Signature:  signBundle(key, config),
+	}
+
+	logAudit(r, "config_export", "bundle", map[string]interface{}{
+		"venues":   len(cfg.Venues),
+		"apps":     len(cfg.Apps),
+		"users":    len(cfg.Users),
+		"flags":    len(cfg.Flags),
+		"displays": len(cfg.Displays),
+	})
+
+	filename := fmt.Sprintf("activity-hub-config-%s.json", bundle.ExportedAt.Format("2006-01-02"))
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(bundle)
+}
+
+// loadPlatformConfig reads everything a bundle carries.
+func loadPlatformConfig() (platformConfig, error) {
+	cfg := platformConfig{
+		Venues: []bundleVenue{}, Apps: []bundleApp{}, Users: []bundleUser{},
+		Flags: []bundleFlag{}, Displays: []bundleDisplay{},
+	}
+
+	slugs := map[int64]string{}
+	rows, err := identityDB.Query(`SELECT id, slug, name, COALESCE(address, ''), COALESCE(is_active, TRUE) FROM venues ORDER BY id`)
+	if err != nil {
+		return cfg, fmt.Errorf("venues: %w", err)
+	}
+	for rows.Next() {
+		var id int64
+		var v bundleVenue
+		if err := rows.Scan(&id, &v.Slug, &v.Name, &v.Address, &v.Active); err != nil {
+			rows.Close()
+			return cfg, fmt.Errorf("venues: %w", err)
+		}
+		slugs[id] = v.Slug
+		cfg.Venues = append(cfg.Venues, v)
+	}
+	rows.Close()
+	venueSlugs := func(ids []int64) []string {
+		out := []string{}
+		for _, id := range ids {
+			if slug, ok := slugs[id]; ok {
+				out = append(out, slug)
+			}
+		}
+		return out
+	}
+
+	rows, err = identityDB.Query(`
+		SELECT id, name, icon, type, COALESCE(description, ''), category, COALESCE(url, ''),
+		       backend_port, COALESCE(realtime, 'none'), min_players, max_players,
+		       COALESCE(required_roles, '{}'), enabled, COALESCE(display_order, 0),
+		       COALESCE(guest_accessible, FALSE), COALESCE(venue_ids, '{}')
+		FROM applications
+		ORDER BY display_order, id
+	`)
+	if err != nil {
+		return cfg, fmt.Errorf("apps: %w", err)
+	}
+	for rows.Next() {
+		var a bundleApp
+		var backendPort, minPlayers, maxPlayers sql.NullInt64
+		var roles pq.StringArray
+		var venueIDs pq.Int64Array
+		if err := rows.Scan(&a.ID, &a.Name, &a.Icon, &a.Type, &a.Description, &a.Category, &a.URL,
+			&backendPort, &a.Realtime, &minPlayers, &maxPlayers,
+			&roles, &a.Enabled, &a.DisplayOrder, &a.GuestAccessible, &venueIDs); err != nil {
+			rows.Close()
+			return cfg, fmt.Errorf("apps: %w", err)
+		}
+		a.BackendPort, a.MinPlayers, a.MaxPlayers = intPtr(backendPort), intPtr(minPlayers), intPtr(maxPlayers)
+		a.RequiredRoles = roles
+		a.Venues = venueSlugs(venueIDs)
+		cfg.Apps = append(cfg.Apps, a)
+	}
+	rows.Close()
+
+	// Never the code hash
+	rows, err = identityDB.Query(`
+		SELECT email, name, COALESCE(roles, '{}'), COALESCE(is_active, TRUE), COALESCE(venue_id, 0)
+		FROM users
+		ORDER BY email
+	`)
+	if err != nil {
+		return cfg, fmt.Errorf("users: %w", err)
+	}
+	for rows.Next() {
+		var u bundleUser
+		var roles pq.StringArray
+		var venueID int64
+		if err := rows.Scan(&u.Email, &u.Name, &roles, &u.Active, &venueID); err != nil {
+			rows.Close()
+			return cfg, fmt.Errorf("users: %w", err)
+		}
+		u.Roles = roles
+		u.Venue = slugs[venueID]
+		cfg.Users = append(cfg.Users, u)
+	}
+	rows.Close()
+
+	rows, err = identityDB.Query(`
+		SELECT key, COALESCE(description, ''), enabled, COALESCE(venue_ids, '{}'), COALESCE(roles, '{}')
+		FROM feature_flags
+		ORDER BY key
+	`)
+	if err != nil {
+		return cfg, fmt.Errorf("flags: %w", err)
+	}
+	for rows.Next() {
+		var f bundleFlag
+		var venueIDs pq.Int64Array
+		var roles pq.StringArray
+		if err := rows.Scan(&f.Key, &f.Description, &f.Enabled, &venueIDs, &roles); err != nil {
+			rows.Close()
+			return cfg, fmt.Errorf("flags: %w", err)
+		}
+		f.Venues = venueSlugs(venueIDs)
+		f.Roles = roles
+		cfg.Flags = append(cfg.Flags, f)
+	}
+	rows.Close()
+
+	// Lost (revoked) and deleted TVs stay behind
+	if displayDB != nil {
+		rows, err = displayDB.Query(`
+			SELECT guid::text, name, COALESCE(location, ''), COALESCE(description, ''),
+			       COALESCE(venue_id, 0), layout, COALESCE(is_active, TRUE)
+			FROM displays
+			WHERE revoked_at IS NULL AND deleted_at IS NULL
+			ORDER BY name
+		`)
+		if err != nil {
+			return cfg, fmt.Errorf("displays: %w", err)
+		}
+		for rows.Next() {
+			var d bundleDisplay
+			var venueID int64
+			if err := rows.Scan(&d.GUID, &d.Name, &d.Location, &d.Description, &venueID, &d.Layout, &d.Active); err != nil {
+				rows.Close()
+				return cfg, fmt.Errorf("displays: %w", err)
+			}
+			d.Venue = slugs[venueID]
+			cfg.Displays = append(cfg.Displays, d)
+		}
+		rows.Close()
+	}
+
+	cfg.Roles = bundleRoles(cfg)
+	return cfg, nil
+}
+
+// bundleRoles lists every role the configuration grants or asks for.
+func bundleRoles(cfg platformConfig) []string {
+	seen := map[string]bool{}
+	for _, u := range cfg.Users {
+		for _, role := range u.Roles {
+			seen[role] = true
+		}
+	}
+	for _, a := range cfg.Apps {
+		for _, role := range a.RequiredRoles {
+			seen[role] = true
+		}
+	}
+	for _, f := range cfg.Flags {
+		for _, role := range f.Roles {
+			seen[role] = true
+		}
+	}
+	roles := []string{}
+	for role := range seen {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+// handleImportConfig applies a signed bundle. With ?dryRun=true nothing is
+// saved; the summary shows what would change.
+// POST /api/config/import  (body: the exported file)
+func handleImportConfig(w http.ResponseWriter, r *http.Request) {
+	if !requireWritePermission(w, r) || !requireChainWide(w, r) {
+		return
+	}
+	key, ok := requireBundleKey(w)
+	if !ok {
+		return
+	}
+	dryRun := r.URL.Query().Get("dryRun") == "true"
+
+	var bundle configBundle
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBundleSize)).Decode(&bundle); err != nil {
+		http.Error(w, "Invalid bundle - expected an exported configuration file", http.StatusBadRequest)
+		return
+	}
+	if bundle.Format != bundleFormat || len(bundle.Config) == 0 {
+		http.Error(w, "Invalid bundle - expected an exported configuration file", http.StatusBadRequest)
+		return
+	}
+	if bundle.Version != bundleVersion {
+		http.Error(w, fmt.Sprintf("Unsupported bundle version %d", bundle.Version), http.StatusBadRequest)
+		return
+	}
+	if !hmac.Equal([]byte(bundle.Signature), []byte(signBundle(key, bundle.Config))) {
+		http.Error(w, "Bundle signature doesn't match - it was changed after export, or exported with a different CONFIG_BUNDLE_KEY", http.StatusBadRequest)
+		return
+	}
+
+	var cfg platformConfig
+	if err := json.Unmarshal(bundle.Config, &cfg); err != nil {
+		http.Error(w, "Invalid bundle configuration", http.StatusBadRequest)
+		return
+	}
+	if err := validatePlatformConfig(cfg); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	summary := importSummary{
+		DryRun:     dryRun,
+		ExportedAt: bundle.ExportedAt,
+		ExportedBy: bundle.ExportedBy,
+		Roles:      bundleRoles(cfg),
+		NewUsers:   []importedUser{},
+		Skipped:    []string{},
+	}
+
+	tx, err := identityDB.Begin()
+	if err != nil {
+		log.Printf("Error starting import: %v", err)
+		http.Error(w, "Failed to import configuration", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	venueIDs, err := importIdentityConfig(tx, cfg, r.Header.Get("X-Admin-Email"), dryRun, &summary)
+	if err != nil {
+		if bad, ok := err.(badBundleError); ok {
+			http.Error(w, bad.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("Error importing configuration: %v", err)
+		http.Error(w, "Failed to import configuration", http.StatusInternalServerError)
+		return
+	}
+
+	// Displays live in Display Admin's database, so they're saved after the
+	// identity changes they refer to
+	var displayTx *sql.Tx
+	if len(cfg.Displays) > 0 {
+		if displayDB == nil {
+			summary.Skipped = append(summary.Skipped, fmt.Sprintf("%d displays - Display Admin's database isn't available", len(cfg.Displays)))
+		} else {
+			if displayTx, err = displayDB.Begin(); err != nil {
+				log.Printf("Error starting display import: %v", err)
+				http.Error(w, "Failed to import configuration", http.StatusInternalServerError)
+				return
+			}
+			defer displayTx.Rollback()
+			if err := importDisplays(displayTx, cfg.Displays, venueIDs, &summary); err != nil {
+				log.Printf("Error importing displays: %v", err)
+				http.Error(w, "Failed to import configuration", http.StatusInternalServerError)
+				return
+			}
+		}
+	}
+
+	if !dryRun {
+		if err := tx.Commit(); err != nil {
+			log.Printf("Error committing import: %v", err)
+			http.Error(w, "Failed to import configuration", http.StatusInternalServerError)
+			return
+		}
+		if displayTx != nil {
+			if err := displayTx.Commit(); err != nil {
+				log.Printf("Error committing display import: %v", err)
+				summary.Skipped = append(summary.Skipped, "displays - failed to save, import the bundle again to retry")
+				summary.Displays = changeCount{}
+			}
+		}
+
+		logAudit(r, "config_import", "bundle", map[string]interface{}{
+			"exported_at": bundle.ExportedAt,
+			"exported_by": bundle.ExportedBy,
+			"venues":      summary.Venues,
+			"apps":        summary.Apps,
+			"users":       summary.Users,
+			"flags":       summary.Flags,
+			"displays":    summary.Displays,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summary)
+}
+
+// badBundleError is a bundle that can't be applied to this box as it stands.
+type badBundleError string
+
+func (e badBundleError) Error() string { return string(e) }
+
+// validatePlatformConfig checks a bundle's rows before anything is saved.
+func validatePlatformConfig(cfg platformConfig) error {
+	for _, v := range cfg.Venues {
+		if strings.TrimSpace(v.Slug) == "" || strings.TrimSpace(v.Name) == "" {
+			return fmt.Errorf("every venue needs a slug and a name")
+		}
+	}
+	for _, a := range cfg.Apps {
+		if a.ID == "" || a.Name == "" || a.Icon == "" {
+			return fmt.Errorf("every app needs an id, name and icon")
+		}
+		if a.Type != "internal" && a.Type != "iframe" {
+			return fmt.Errorf("app %s: type must be internal or iframe", a.ID)
+		}
+		if a.Category != "game" && a.Category != "utility" && a.Category != "admin" {
+			return fmt.Errorf("app %s: category must be game, utility or admin", a.ID)
+		}
+	}
+	for _, u := range cfg.Users {
+		if !strings.Contains(u.Email, "@") || strings.TrimSpace(u.Name) == "" {
+			return fmt.Errorf("user %q: invalid email or name", u.Email)
+		}
+	}
+	for _, f := range cfg.Flags {
+		if !flagKeyPattern.MatchString(f.Key) {
+			return fmt.Errorf("flag %q: invalid key", f.Key)
+		}
+	}
+	for _, d := range cfg.Displays {
+		if d.GUID == "" || d.Name == "" {
+			return fmt.Errorf("every display needs a guid and a name")
+		}
+		if !displayLayouts[d.Layout] {
+			return fmt.Errorf("display %s: unknown layout %q", d.Name, d.Layout)
+		}
+	}
+	return nil
+}
+
+// importIdentityConfig saves venues, apps, users and flags in tx, and returns
+// this box's venue IDs by slug for the displays.
+func importIdentityConfig(tx *sql.Tx, cfg platformConfig, adminEmail string, dryRun bool, summary *importSummary) (map[string]int64, error) {
+	for _, v := range cfg.Venues {
+		var created bool
+		err := tx.QueryRow(`
+			INSERT INTO venues (slug, name, address, is_active)
+			VALUES ($1, $2, NULLIF($3, ''), $4)
+			ON CONFLICT (slug) DO UPDATE SET name = EXCLUDED.name, address = EXCLUDED.address, is_active = EXCLUDED.is_active
+			RETURNING (xmax = 0)
+		`, strings.ToLower(strings.TrimSpace(v.Slug)), strings.TrimSpace(v.Name), v.Address, v.Active).Scan(&created)
+		if err != nil {
+			return nil, fmt.Errorf("venue %s: %w", v.Slug, err)
+		}
+		summary.Venues.add(created)
+	}
+
+	venueIDs := map[string]int64{}
+	rows, err := tx.Query(`SELECT id, slug FROM venues`)
+	if err != nil {
+		return nil, fmt.Errorf("venues: %w", err)
+	}
+	for rows.Next() {
+		var id int64
+		var slug string
+		if err := rows.Scan(&id, &slug); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("venues: %w", err)
+		}
+		venueIDs[slug] = id
+	}
+	rows.Close()
+
+	venueID := func(slug string) (interface{}, error) {
+		if slug == "" {
+			return nil, nil
+		}
+		id, ok := venueIDs[slug]
+		if !ok {
+			return nil, badBundleError(fmt.Sprintf("unknown venue %q", slug))
+		}
+		return id, nil
+	}
+	venueList := func(slugs []string) (interface{}, error) {
+		if len(slugs) == 0 {
+			return nil, nil
+		}
+		ids := []int64{}
+		for _, slug := range slugs {
+			id, ok := venueIDs[slug]
+			if !ok {
+				return nil, badBundleError(fmt.Sprintf("unknown venue %q", slug))
+			}
+			ids = append(ids, id)
+		}
+		return pq.Array(ids), nil
+	}
+
+	for _, a := range cfg.Apps {
+		venues, err := venueList(a.Venues)
+		if err != nil {
+			return nil, err
+		}
+		var created bool
+		err = tx.QueryRow(`
+			INSERT INTO applications (id, name, icon, type, description, category, url, backend_port, realtime,
+			                          min_players, max_players, required_roles, enabled, display_order, guest_accessible, venue_ids)
+			VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), $8, $9, $10, $11, $12, $13, $14, $15, $16)
+			ON CONFLICT (id) DO UPDATE SET
+			    name = EXCLUDED.name, icon = EXCLUDED.icon, type = EXCLUDED.type,
+			    description = EXCLUDED.description, category = EXCLUDED.category, url = EXCLUDED.url,
+			    backend_port = EXCLUDED.backend_port, realtime = EXCLUDED.realtime,
+			    min_players = EXCLUDED.min_players, max_players = EXCLUDED.max_players,
+			    required_roles = EXCLUDED.required_roles, enabled = EXCLUDED.enabled,
+			    display_order = EXCLUDED.display_order, guest_accessible = EXCLUDED.guest_accessible,
+			    venue_ids = EXCLUDED.venue_ids
+			RETURNING (xmax = 0)
+		`, a.ID, a.Name, a.Icon, a.Type, a.Description, a.Category, a.URL, a.BackendPort, a.Realtime,
+			a.MinPlayers, a.MaxPlayers, pq.Array(nonNil(a.RequiredRoles)), a.Enabled, a.DisplayOrder, a.GuestAccessible, venues).Scan(&created)
+		if err != nil {
+			return nil, fmt.Errorf("app %s: %w", a.ID, err)
+		}
+		summary.Apps.add(created)
+	}
+
+	for _, u := range cfg.Users {
+		email := strings.ToLower(strings.TrimSpace(u.Email))
+		venue, err := venueID(u.Venue)
+		if err != nil {
+			return nil, err
+		}
+		roles := nonNil(u.Roles)
+
+		// The importing admin keeps their own roles, so they can't lock themselves out
+		if email == adminEmail {
+			summary.Skipped = append(summary.Skipped, email+" - your own account is left as it is")
+			continue
+		}
+
+		var exists bool
+		if err := tx.QueryRow(`SELECT EXISTS(SELECT 1 FROM users WHERE email = $1)`, email).Scan(&exists); err != nil {
+			return nil, fmt.Errorf("user %s: %w", email, err)
+		}
+		if exists {
+			_, err = tx.Exec(`
+				UPDATE users
+				SET name = $2, roles = $3, is_admin = $4, venue_id = $5,
+				    deactivated_at = CASE WHEN $6 THEN NULL WHEN COALESCE(is_active, TRUE) THEN CURRENT_TIMESTAMP ELSE deactivated_at END,
+				    is_active = $6
+				WHERE email = $1
+			`, email, strings.TrimSpace(u.Name), pq.Array(roles), len(roles) > 0, venue, u.Active)
+			if err == nil && !u.Active {
+				_, err = tx.Exec(`
+					UPDATE impersonation_sessions
+					SET is_active = FALSE, ended_at = CURRENT_TIMESTAMP
+					WHERE impersonated_email = $1 AND is_active = TRUE
+				`, email)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("user %s: %w", email, err)
+			}
+			summary.Users.add(false)
+			continue
+		}
+
+		// New users get a login code of their own; a dry run doesn't hand any out
+		code, err := generateLoginCode()
+		if err != nil {
+			return nil, fmt.Errorf("user %s: %w", email, err)
+		}
+		hash := "dry-run"
+		if !dryRun {
+			if hash, err = hashLoginCode(code); err != nil {
+				return nil, fmt.Errorf("user %s: %w", email, err)
+			}
+		}
+		_, err = tx.Exec(`
+			INSERT INTO users (email, name, code_hash, is_admin, roles, is_active, venue_id)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`, email, strings.TrimSpace(u.Name), hash, len(roles) > 0, pq.Array(roles), u.Active, venue)
+		if err != nil {
+			return nil, fmt.Errorf("user %s: %w", email, err)
+		}
+		summary.Users.add(true)
+		if !dryRun {
+			summary.NewUsers = append(summary.NewUsers, importedUser{Email: email, Name: strings.TrimSpace(u.Name), Code: code})
+		}
+	}
+
+	for _, f := range cfg.Flags {
+		venues, err := venueList(f.Venues)
+		if err != nil {
+			return nil, err
+		}
+		var created bool
+		err = tx.QueryRow(`
+			INSERT INTO feature_flags (key, description, enabled, venue_ids, roles, updated_by)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (key) DO UPDATE SET
+			    description = EXCLUDED.description, enabled = EXCLUDED.enabled,
+			    venue_ids = EXCLUDED.venue_ids, roles = EXCLUDED.roles,
+			    updated_by = EXCLUDED.updated_by, updated_at = CURRENT_TIMESTAMP
+			RETURNING (xmax = 0)
+		`, f.Key, f.Description, f.Enabled, venues, pq.Array(nonNil(f.Roles)), adminEmail).Scan(&created)
+		if err != nil {
+			return nil, fmt.Errorf("flag %s: %w", f.Key, err)
+		}
+		summary.Flags.add(created)
+	}
+
+	return venueIDs, nil
+}
+
+// importDisplays saves the display roster in tx. New TVs get a fresh token and
+// pair as usual; a deleted TV that's in the bundle comes back.
+func importDisplays(tx *sql.Tx, displays []bundleDisplay, venueIDs map[string]int64, summary *importSummary) error {
+	for _, d := range displays {
+		var venue interface{}
+		if d.Venue != "" {
+			id, ok := venueIDs[d.Venue]
+			if !ok {
+				summary.Skipped = append(summary.Skipped, fmt.Sprintf("display %s - unknown venue %q", d.Name, d.Venue))
+				continue
+			}
+			venue = id
+		}
+		var created bool
+		err := tx.QueryRow(`
+			INSERT INTO displays (guid, name, location, description, venue_id, layout, is_active, token)
+			VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), $5, $6, $7, gen_random_uuid())
+			ON CONFLICT (guid) DO UPDATE SET
+			    name = EXCLUDED.name, location = EXCLUDED.location, description = EXCLUDED.description,
+			    venue_id = EXCLUDED.venue_id, layout = EXCLUDED.layout, is_active = EXCLUDED.is_active,
+			    deleted_at = NULL, deleted_by = NULL
+			RETURNING (xmax = 0)
+		`, d.GUID, d.Name, d.Location, d.Description, venue, d.Layout, d.Active).Scan(&created)
+		if err != nil {
+			return fmt.Errorf("display %s: %w", d.Name, err)
+		}
+		summary.Displays.add(created)
+	}
+	return nil
+}
+
+// intPtr turns a nullable column into an optional JSON number
+func intPtr(v sql.NullInt64) *int {
+	if !v.Valid {
+		return nil
+	}
+	n := int(v.Int64)
+	return &n
+}
+
+// nonNil keeps empty role lists as '{}' rather than NULL
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
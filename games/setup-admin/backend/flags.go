@@ -0,0 +1,220 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/lib/pq"
+)
+
+// Feature flags are read by every backend through activity-hub-common/flags,
+// which picks up changes made here within 30 seconds.
+
+// flagKeyPattern keeps keys usable as identifiers in code, e.g. quiz_auto_mark
+var flagKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{1,99}$`)
+
+// flagRequest is the body of flag create and update requests.
+// Empty venue_ids means every venue; empty roles means everyone.
+type flagRequest struct {
+	Key         string   `json:"key"`
+	Description string   `json:"description"`
+	Enabled     bool     `json:"enabled"`
+	VenueIDs    []int64  `json:"venue_ids"`
+	Roles       []string `json:"roles"`
+}
+
+// handleGetFlags returns every feature flag
+// GET /api/flags
+func handleGetFlags(w http.ResponseWriter, r *http.Request) {
+	rows, err := identityDB.Query(`
+		SELECT key, COALESCE(description, ''), enabled,
+		       COALESCE(venue_ids, '{}'), COALESCE(roles, '{}'),
+		       COALESCE(updated_by, ''), updated_at
+		FROM feature_flags
+		ORDER BY key
+	`)
+	if err != nil {
+		log.Printf("Error querying feature flags: %v", err)
+		http.Error(w, "Failed to fetch feature flags", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	flags := []map[string]interface{}{}
+	for rows.Next() {
+		var key, description, updatedBy string
+		var enabled bool
+		var venueIDs pq.Int64Array
+		var roles pq.StringArray
+		var updatedAt interface{}
+
+		if err := rows.Scan(&key, &description, &enabled, &venueIDs, &roles, &updatedBy, &updatedAt); err != nil {
+			log.Printf("Error scanning feature flag: %v", err)
+			continue
+		}
+
+		flags = append(flags, map[string]interface{}{
+			"key":         key,
+			"description": description,
+			"enabled":     enabled,
+			"venue_ids":   venueIDs,
+			"roles":       roles,
+			"updated_by":  updatedBy,
+			"updatedAt":   updatedAt,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"flags": flags,
+	})
+}
+
+// decodeFlagRequest reads and tidies a flag request, answering 400 itself
+func decodeFlagRequest(w http.ResponseWriter, r *http.Request) (flagRequest, bool) {
+	var req flagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return req, false
+	}
+
+	req.Description = strings.TrimSpace(req.Description)
+	roles := []string{}
+	for _, role := range req.Roles {
+		if role = strings.TrimSpace(role); role != "" {
+			roles = append(roles, role)
+		}
+	}
+	req.Roles = roles
+	if req.VenueIDs == nil {
+		req.VenueIDs = []int64{}
+	}
+	return req, true
+}
+
+// handleCreateFlag creates a feature flag
+// POST /api/flags  {key, description, enabled, venue_ids, roles}
+func handleCreateFlag(w http.ResponseWriter, r *http.Request) {
+	if !requireWritePermission(w, r) || !requireChainWide(w, r) {
+		return
+	}
+
+	req, ok := decodeFlagRequest(w, r)
+	if !ok {
+		return
+	}
+	req.Key = strings.ToLower(strings.TrimSpace(req.Key))
+	if !flagKeyPattern.MatchString(req.Key) {
+		http.Error(w, "key must be lower case letters, digits and underscores", http.StatusBadRequest)
+		return
+	}
+
+	_, err := identityDB.Exec(`
+		INSERT INTO feature_flags (key, description, enabled, venue_ids, roles, updated_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, req.Key, req.Description, req.Enabled, pq.Array(req.VenueIDs), pq.Array(req.Roles), r.Header.Get("X-Admin-Email"))
+	if isUniqueViolation(err) {
+		http.Error(w, "Feature flag already exists", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Printf("Error creating feature flag: %v", err)
+		http.Error(w, "Failed to create feature flag", http.StatusInternalServerError)
+		return
+	}
+
+	logAuditChange(r, "flag_create", req.Key, map[string]interface{}{
+		"enabled":   req.Enabled,
+		"venue_ids": req.VenueIDs,
+		"roles":     req.Roles,
+	}, nil, snapshot(flagSnapshot, req.Key))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"key":     req.Key,
+	})
+}
+
+// handleUpdateFlag changes a feature flag's description, state and targeting
+// PUT /api/flags/{key}  {description, enabled, venue_ids, roles}
+func handleUpdateFlag(w http.ResponseWriter, r *http.Request) {
+	if !requireWritePermission(w, r) || !requireChainWide(w, r) {
+		return
+	}
+
+	key := mux.Vars(r)["key"]
+	req, ok := decodeFlagRequest(w, r)
+	if !ok {
+		return
+	}
+
+	before := snapshot(flagSnapshot, key)
+	var previous bool
+	err := identityDB.QueryRow(`
+		UPDATE feature_flags f
+		SET description = $1, enabled = $2, venue_ids = $3, roles = $4,
+		    updated_by = $5, updated_at = CURRENT_TIMESTAMP
+		FROM feature_flags old
+		WHERE f.key = $6 AND old.key = f.key
+		RETURNING old.enabled
+	`, req.Description, req.Enabled, pq.Array(req.VenueIDs), pq.Array(req.Roles), r.Header.Get("X-Admin-Email"), key).Scan(&previous)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Feature flag not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error updating feature flag: %v", err)
+		http.Error(w, "Failed to update feature flag", http.StatusInternalServerError)
+		return
+	}
+
+	logAuditChange(r, "flag_update", key, map[string]interface{}{
+		"was_enabled": previous,
+		"enabled":     req.Enabled,
+		"venue_ids":   req.VenueIDs,
+		"roles":       req.Roles,
+	}, before, snapshot(flagSnapshot, key))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": "Feature flag updated successfully",
+	})
+}
+
+// handleDeleteFlag removes a feature flag; backends treat unknown flags as off
+// DELETE /api/flags/{key}
+func handleDeleteFlag(w http.ResponseWriter, r *http.Request) {
+	if !requireWritePermission(w, r) || !requireChainWide(w, r) {
+		return
+	}
+
+	key := mux.Vars(r)["key"]
+	before := snapshot(flagSnapshot, key)
+	result, err := identityDB.Exec("DELETE FROM feature_flags WHERE key = $1", key)
+	if err != nil {
+		log.Printf("Error deleting feature flag: %v", err)
+		http.Error(w, "Failed to delete feature flag", http.StatusInternalServerError)
+		return
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		http.Error(w, "Feature flag not found", http.StatusNotFound)
+		return
+	}
+
+	logAuditChange(r, "flag_delete", key, nil, before, nil)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": "Feature flag deleted successfully",
+	})
+}
@@ -3,9 +3,10 @@ module github.com/achgithub/activity-hub/setup-admin
 go 1.25
 
 require (
-	github.com/gorilla/handlers v1.5.2
+	github.com/achgithub/activity-hub-common v0.0.0
 	github.com/gorilla/mux v1.8.1
 	github.com/lib/pq v1.10.9
+	golang.org/x/crypto v0.31.0
 )
 
-require github.com/felixge/httpsnoop v1.0.4 // indirect
+replace github.com/achgithub/activity-hub-common => ../../../lib/activity-hub-common
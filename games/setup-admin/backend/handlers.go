@@ -1,10 +1,13 @@
 package main
 
 import (
+	"database/sql"
 	"encoding/json"
 	"log"
 	"net/http"
+	"strings"
 
+	"github.com/achgithub/activity-hub-common/listquery"
 	"github.com/gorilla/mux"
 	"github.com/lib/pq"
 )
@@ -19,13 +22,40 @@ func requireWritePermission(w http.ResponseWriter, r *http.Request) bool {
 	return true
 }
 
-// handleGetUsers returns all users with their roles
+// userList is what the users list can be sorted and filtered by. "admins"
+// lists admins first, then by name.
+var userList = listquery.Spec{
+	Sorts: map[string]string{
+		"admins":  "NOT is_admin, name",
+		"name":    "name",
+		"email":   "email",
+		"created": "created_at",
+	},
+	DefaultSort: "admins",
+	Tiebreak:    "email",
+	Filters: map[string]listquery.Filter{
+		"q":      listquery.Search("name", "email"),
+		"active": listquery.Bool("COALESCE(is_active, TRUE)"),
+		"role": func(role string) (string, []any, error) {
+			return "? = ANY(roles)", []any{role}, nil
+		},
+	},
+}
+
+// handleGetUsers returns a page of users with their roles
 func handleGetUsers(w http.ResponseWriter, r *http.Request) {
-	rows, err := identityDB.Query(`
-		SELECT email, name, is_admin, COALESCE(roles, '{}'), created_at
-		FROM users
-		ORDER BY is_admin DESC, name
-	`)
+	q, err := listquery.Parse(r.URL.Query(), userList)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	venueID := adminVenueID(r)
+	q.Where("? = 0 OR venue_id = ?", venueID, venueID)
+
+	query, args := q.SQL(`
+		SELECT email, name, is_admin, COALESCE(roles, '{}'), COALESCE(is_active, TRUE), venue_id, created_at
+		FROM users`)
+	rows, err := identityDB.Query(query, args...)
 	if err != nil {
 		log.Printf("Error querying users: %v", err)
 		http.Error(w, "Failed to fetch users", http.StatusInternalServerError)
@@ -36,11 +66,12 @@ func handleGetUsers(w http.ResponseWriter, r *http.Request) {
 	var users []map[string]interface{}
 	for rows.Next() {
 		var email, name string
-		var isAdmin bool
+		var isAdmin, isActive bool
 		var roles pq.StringArray
+		var venueID sql.NullInt64
 		var createdAt interface{}
 
-		err := rows.Scan(&email, &name, &isAdmin, &roles, &createdAt)
+		err := rows.Scan(&email, &name, &isAdmin, &roles, &isActive, &venueID, &createdAt)
 		if err != nil {
 			log.Printf("Error scanning user: %v", err)
 			continue
@@ -51,13 +82,17 @@ func handleGetUsers(w http.ResponseWriter, r *http.Request) {
 			"name":      name,
 			"is_admin":  isAdmin,
 			"roles":     roles,
+			"is_active": isActive,
+			"venue_id":  nullableInt(venueID),
 			"createdAt": createdAt,
 		})
 	}
+	users, page := listquery.Finish(q, users)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"users": users,
+		"page":  page,
 	})
 }
 
@@ -71,6 +106,10 @@ func handleUpdateUserRoles(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	email := vars["email"]
 
+	if !requireUserInVenue(w, r, email) {
+		return
+	}
+
 	var req struct {
 		Roles []string `json:"roles"`
 	}
@@ -80,6 +119,18 @@ func handleUpdateUserRoles(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var current pq.StringArray
+	if err := identityDB.QueryRow("SELECT COALESCE(roles, '{}') FROM users WHERE email = $1", email).Scan(&current); err != nil {
+		log.Printf("Error reading user roles: %v", err)
+		http.Error(w, "Failed to update roles", http.StatusInternalServerError)
+		return
+	}
+	if !requireRoleChangeAllowed(w, r, current, req.Roles) {
+		return
+	}
+
+	before := snapshot(userSnapshot, email)
+
 	// Update roles in database
 	_, err := identityDB.Exec(`
 		UPDATE users
@@ -94,10 +145,9 @@ func handleUpdateUserRoles(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Log audit action
-	adminEmail := r.Header.Get("X-Admin-Email")
-	logAudit(adminEmail, "user_role_change", email, map[string]interface{}{
+	logAuditChange(r, "user_role_change", email, map[string]interface{}{
 		"new_roles": req.Roles,
-	})
+	}, before, snapshot(userSnapshot, email))
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
@@ -111,7 +161,9 @@ func handleGetApps(w http.ResponseWriter, r *http.Request) {
 	rows, err := identityDB.Query(`
 		SELECT id, name, icon, type, description, category,
 		       COALESCE(url, ''), COALESCE(backend_port, 0), COALESCE(realtime, 'none'),
-		       COALESCE(required_roles, '{}'), enabled, display_order
+		       COALESCE(required_roles, '{}'), enabled, display_order,
+		       COALESCE(venue_ids, '{}'),
+		       maintenance, COALESCE(maintenance_message, ''), maintenance_since, COALESCE(maintenance_by, '')
 		FROM applications
 		ORDER BY display_order, name
 	`)
@@ -128,12 +180,17 @@ func handleGetApps(w http.ResponseWriter, r *http.Request) {
 		var url, realtime string
 		var backendPort, displayOrder int
 		var requiredRoles pq.StringArray
-		var enabled bool
+		var venueIDs pq.Int64Array
+		var enabled, maintenance bool
+		var maintenanceMessage, maintenanceBy string
+		var maintenanceSince sql.NullTime
 
 		err := rows.Scan(
 			&id, &name, &icon, &appType, &description, &category,
 			&url, &backendPort, &realtime,
 			&requiredRoles, &enabled, &displayOrder,
+			&venueIDs,
+			&maintenance, &maintenanceMessage, &maintenanceSince, &maintenanceBy,
 		)
 		if err != nil {
 			log.Printf("Error scanning app: %v", err)
@@ -141,18 +198,23 @@ func handleGetApps(w http.ResponseWriter, r *http.Request) {
 		}
 
 		apps = append(apps, map[string]interface{}{
-			"id":            id,
-			"name":          name,
-			"icon":          icon,
-			"type":          appType,
-			"description":   description,
-			"category":      category,
-			"url":           url,
-			"backendPort":   backendPort,
-			"realtime":      realtime,
-			"requiredRoles": requiredRoles,
-			"enabled":       enabled,
-			"displayOrder":  displayOrder,
+			"id":                 id,
+			"name":               name,
+			"icon":               icon,
+			"type":               appType,
+			"description":        description,
+			"category":           category,
+			"url":                url,
+			"backendPort":        backendPort,
+			"realtime":           realtime,
+			"requiredRoles":      requiredRoles,
+			"enabled":            enabled,
+			"displayOrder":       displayOrder,
+			"venueIds":           venueIDs,
+			"maintenance":        maintenance,
+			"maintenanceMessage": maintenanceMessage,
+			"maintenanceSince":   nullableTime(maintenanceSince),
+			"maintenanceBy":      maintenanceBy,
 		})
 	}
 
@@ -165,7 +227,7 @@ func handleGetApps(w http.ResponseWriter, r *http.Request) {
 // handleUpdateApp updates an app's details
 func handleUpdateApp(w http.ResponseWriter, r *http.Request) {
 	// Check write permission
-	if !requireWritePermission(w, r) {
+	if !requireWritePermission(w, r) || !requireChainWide(w, r) {
 		return
 	}
 
@@ -190,6 +252,7 @@ func handleUpdateApp(w http.ResponseWriter, r *http.Request) {
 		RequiredRoles []string `json:"requiredRoles"`
 		Enabled       bool     `json:"enabled"`
 		DisplayOrder  int      `json:"displayOrder"`
+		VenueIDs      []int64  `json:"venueIds"` // empty = offered at all venues
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -197,16 +260,18 @@ func handleUpdateApp(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	before := snapshot(appSnapshot, appID)
+
 	// Update app in database
 	_, err := identityDB.Exec(`
 		UPDATE applications
 		SET name = $1, icon = $2, type = $3, description = $4, category = $5,
 		    url = $6, backend_port = $7, realtime = $8,
-		    required_roles = $9, enabled = $10, display_order = $11
-		WHERE id = $12
+		    required_roles = $9, enabled = $10, display_order = $11, venue_ids = $12
+		WHERE id = $13
 	`, req.Name, req.Icon, req.Type, req.Description, req.Category,
 		req.URL, req.BackendPort, req.Realtime,
-		pq.Array(req.RequiredRoles), req.Enabled, req.DisplayOrder, appID)
+		pq.Array(req.RequiredRoles), req.Enabled, req.DisplayOrder, pq.Array(req.VenueIDs), appID)
 
 	if err != nil {
 		log.Printf("Error updating app: %v", err)
@@ -215,10 +280,9 @@ func handleUpdateApp(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Log audit action
-	adminEmail := r.Header.Get("X-Admin-Email")
-	logAudit(adminEmail, "app_update", appID, map[string]interface{}{
+	logAuditChange(r, "app_update", appID, map[string]interface{}{
 		"name": req.Name,
-	})
+	}, before, snapshot(appSnapshot, appID))
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
@@ -230,7 +294,7 @@ func handleUpdateApp(w http.ResponseWriter, r *http.Request) {
 // handleToggleApp enables or disables an app
 func handleToggleApp(w http.ResponseWriter, r *http.Request) {
 	// Check write permission
-	if !requireWritePermission(w, r) {
+	if !requireWritePermission(w, r) || !requireChainWide(w, r) {
 		return
 	}
 
@@ -246,6 +310,7 @@ func handleToggleApp(w http.ResponseWriter, r *http.Request) {
 
 	enabled := action == "enable"
 
+	before := snapshot(appSnapshot, appID)
 	_, err := identityDB.Exec("UPDATE applications SET enabled = $1 WHERE id = $2", enabled, appID)
 	if err != nil {
 		log.Printf("Error toggling app: %v", err)
@@ -254,10 +319,9 @@ func handleToggleApp(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Log audit action
-	adminEmail := r.Header.Get("X-Admin-Email")
-	logAudit(adminEmail, "app_toggle", appID, map[string]interface{}{
+	logAuditChange(r, "app_toggle", appID, map[string]interface{}{
 		"enabled": enabled,
-	})
+	}, before, snapshot(appSnapshot, appID))
 
 	status := "disabled"
 	if enabled {
@@ -271,16 +335,86 @@ func handleToggleApp(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// logAudit logs an admin action to the audit log
-func logAudit(adminEmail, actionType, targetID string, details map[string]interface{}) {
-	detailsJSON, _ := json.Marshal(details)
+const maxMaintenanceMessageLength = 500
+
+// handleSetAppMaintenance puts an app into or out of maintenance mode. While
+// it's on, the shell hides the app from players and its backend answers them
+// with 503 and the message (activity-hub-common maintenance); admins can
+// still use it.
+// POST /api/apps/{id}/maintenance  {enabled, message}
+func handleSetAppMaintenance(w http.ResponseWriter, r *http.Request) {
+	if !requireWritePermission(w, r) || !requireChainWide(w, r) {
+		return
+	}
+
+	appID := mux.Vars(r)["id"]
+	if appID == "lobby" || appID == "identity-shell" || appID == "setup-admin" {
+		http.Error(w, "Cannot put "+appID+" into maintenance - admins need it to turn maintenance off", http.StatusForbidden)
+		return
+	}
 
-	_, err := appDB.Exec(`
-		INSERT INTO audit_log (admin_email, action_type, target_id, details)
-		VALUES ($1, $2, $3, $4)
-	`, adminEmail, actionType, targetID, detailsJSON)
+	var req struct {
+		Enabled bool   `json:"enabled"`
+		Message string `json:"message"` // Shown to players; empty for the standard message
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	req.Message = strings.TrimSpace(req.Message)
+	if len(req.Message) > maxMaintenanceMessageLength {
+		http.Error(w, "Message must be at most 500 characters", http.StatusBadRequest)
+		return
+	}
 
+	before := snapshot(appSnapshot, appID)
+	var result sql.Result
+	var err error
+	if req.Enabled {
+		result, err = identityDB.Exec(`
+			UPDATE applications
+			SET maintenance = TRUE, maintenance_message = NULLIF($2, ''),
+			    maintenance_since = CASE WHEN maintenance THEN maintenance_since ELSE CURRENT_TIMESTAMP END,
+			    maintenance_by = $3
+			WHERE id = $1
+		`, appID, req.Message, r.Header.Get("X-Admin-Email"))
+	} else {
+		result, err = identityDB.Exec(`
+			UPDATE applications
+			SET maintenance = FALSE, maintenance_message = NULL, maintenance_since = NULL, maintenance_by = NULL
+			WHERE id = $1
+		`, appID)
+	}
 	if err != nil {
-		log.Printf("Warning: Failed to log audit action: %v", err)
+		log.Printf("Error setting app maintenance: %v", err)
+		http.Error(w, "Failed to update maintenance", http.StatusInternalServerError)
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		http.Error(w, "App not found", http.StatusNotFound)
+		return
+	}
+
+	logAuditChange(r, "app_maintenance", appID, map[string]interface{}{
+		"enabled": req.Enabled,
+		"message": req.Message,
+	}, before, snapshot(appSnapshot, appID))
+
+	status := "off"
+	if req.Enabled {
+		status = "on"
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": "Maintenance " + status + " - apps pick this up within a minute",
+	})
+}
+
+// nullableInt converts a nullable integer column to a JSON-friendly value
+func nullableInt(v sql.NullInt64) interface{} {
+	if !v.Valid {
+		return nil
 	}
+	return v.Int64
 }
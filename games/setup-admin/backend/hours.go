@@ -0,0 +1,194 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Opening hours are read by every backend through activity-hub-common/hours,
+// which picks up changes made here within 30 seconds. Venue 0 holds the
+// default hours for venues without their own.
+
+// hoursWindow is one opening period; closes at or before opens runs past midnight
+type hoursWindow struct {
+	Day    int    `json:"day"` // 0 = Sunday ... 6 = Saturday
+	Opens  string `json:"opens"`
+	Closes string `json:"closes"`
+}
+
+// validate checks the day and "HH:MM" times
+func (hw hoursWindow) validate() error {
+	if hw.Day < 0 || hw.Day > 6 {
+		return fmt.Errorf("invalid day %d, want 0 (Sunday) to 6", hw.Day)
+	}
+	for _, t := range []string{hw.Opens, hw.Closes} {
+		if _, err := time.Parse("15:04", t); err != nil {
+			return fmt.Errorf("invalid time %q, want HH:MM", t)
+		}
+	}
+	return nil
+}
+
+// hoursVenueID reads the venue id from the path and checks the admin may
+// manage it: chain-wide admins any venue and the defaults, venue admins their
+// own venue. Answers the request itself on failure.
+func hoursVenueID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	venueID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil || venueID < 0 {
+		http.Error(w, "Invalid venue id", http.StatusBadRequest)
+		return 0, false
+	}
+	if admin := adminVenueID(r); admin != 0 && admin != venueID {
+		http.Error(w, "Forbidden - Venue admins can only manage their own venue's hours", http.StatusForbidden)
+		return 0, false
+	}
+	if venueID == 0 {
+		return 0, true
+	}
+
+	var exists bool
+	if err := identityDB.QueryRow("SELECT EXISTS(SELECT 1 FROM venues WHERE id = $1)", venueID).Scan(&exists); err != nil {
+		log.Printf("Error checking venue: %v", err)
+		http.Error(w, "Failed to check venue", http.StatusInternalServerError)
+		return 0, false
+	}
+	if !exists {
+		http.Error(w, "Venue not found", http.StatusNotFound)
+		return 0, false
+	}
+	return venueID, true
+}
+
+// handleGetVenueHours returns a venue's opening hours (venue 0 = defaults)
+// GET /api/venues/{id}/hours
+func handleGetVenueHours(w http.ResponseWriter, r *http.Request) {
+	venueID, ok := hoursVenueID(w, r)
+	if !ok {
+		return
+	}
+
+	rows, err := identityDB.Query(`
+		SELECT day_of_week, to_char(opens, 'HH24:MI'), to_char(closes, 'HH24:MI')
+		FROM venue_hours
+		WHERE venue_id = $1
+		ORDER BY day_of_week, opens
+	`, venueID)
+	if err != nil {
+		log.Printf("Error querying venue hours: %v", err)
+		http.Error(w, "Failed to fetch opening hours", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	windows := []hoursWindow{}
+	for rows.Next() {
+		var hw hoursWindow
+		if err := rows.Scan(&hw.Day, &hw.Opens, &hw.Closes); err != nil {
+			log.Printf("Error scanning venue hours: %v", err)
+			continue
+		}
+		windows = append(windows, hw)
+	}
+
+	var lobbyClosed bool
+	identityDB.QueryRow("SELECT lobby_closed FROM venue_hours_settings WHERE venue_id = $1", venueID).Scan(&lobbyClosed)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"venue_id":     venueID,
+		"hours":        windows,
+		"lobby_closed": lobbyClosed,
+	})
+}
+
+// handleUpdateVenueHours replaces a venue's opening hours. Empty hours means
+// the venue falls back to the defaults (or, for venue 0, is always open).
+// PUT /api/venues/{id}/hours  {hours: [{day, opens, closes}], lobby_closed}
+func handleUpdateVenueHours(w http.ResponseWriter, r *http.Request) {
+	if !requireWritePermission(w, r) {
+		return
+	}
+	venueID, ok := hoursVenueID(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Hours       []hoursWindow `json:"hours"`
+		LobbyClosed bool          `json:"lobby_closed"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	for _, hw := range req.Hours {
+		if err := hw.validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	before := snapshot(venueHoursSnapshot, venueID)
+	tx, err := identityDB.Begin()
+	if err != nil {
+		log.Printf("Error starting hours transaction: %v", err)
+		http.Error(w, "Failed to update opening hours", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM venue_hours WHERE venue_id = $1", venueID); err != nil {
+		log.Printf("Error clearing venue hours: %v", err)
+		http.Error(w, "Failed to update opening hours", http.StatusInternalServerError)
+		return
+	}
+	for _, hw := range req.Hours {
+		_, err := tx.Exec(`
+			INSERT INTO venue_hours (venue_id, day_of_week, opens, closes)
+			VALUES ($1, $2, $3, $4)
+		`, venueID, hw.Day, hw.Opens, hw.Closes)
+		if isUniqueViolation(err) {
+			http.Error(w, "Two periods open at the same time on the same day", http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			log.Printf("Error inserting venue hours: %v", err)
+			http.Error(w, "Failed to update opening hours", http.StatusInternalServerError)
+			return
+		}
+	}
+	_, err = tx.Exec(`
+		INSERT INTO venue_hours_settings (venue_id, lobby_closed, updated_by, updated_at)
+		VALUES ($1, $2, $3, CURRENT_TIMESTAMP)
+		ON CONFLICT (venue_id) DO UPDATE
+		SET lobby_closed = EXCLUDED.lobby_closed, updated_by = EXCLUDED.updated_by, updated_at = CURRENT_TIMESTAMP
+	`, venueID, req.LobbyClosed, r.Header.Get("X-Admin-Email"))
+	if err != nil {
+		log.Printf("Error saving venue hours settings: %v", err)
+		http.Error(w, "Failed to update opening hours", http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing venue hours: %v", err)
+		http.Error(w, "Failed to update opening hours", http.StatusInternalServerError)
+		return
+	}
+
+	logAuditChange(r, "venue_hours_update", strconv.Itoa(venueID), map[string]interface{}{
+		"hours":        req.Hours,
+		"lobby_closed": req.LobbyClosed,
+	}, before, snapshot(venueHoursSnapshot, venueID))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": "Opening hours updated successfully",
+	})
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/lib/pq"
+)
+
+// Kiosk tokens are long-lived tokens for unattended devices. Each is issued
+// with capabilities, which expand to the endpoints the token may call; the
+// shared auth middleware (activity-hub-common auth) refuses it anywhere else.
+// The token is shown once - only its SHA-256 is stored, as the middleware
+// looks it up.
+
+// kioskCapability is something a kiosk can be allowed to do
+type kioskCapability struct {
+	Label     string   `json:"label"`
+	App       string   `json:"app"`
+	Endpoints []string `json:"endpoints"`
+}
+
+var kioskCapabilities = map[string]kioskCapability{
+	"leaderboard:view": {
+		Label: "View leaderboard",
+		App:   "leaderboard",
+		Endpoints: []string{
+			"GET /api/config",
+			"GET /api/standings", "GET /api/standings/*",
+			"GET /api/recent", "GET /api/recent/*",
+		},
+	},
+	"quiz:display": {
+		Label: "Join quiz as display",
+		App:   "quiz-display",
+		Endpoints: []string{
+			"GET /api/config",
+			"GET /api/display/session/*", "GET /api/display/stream/*",
+		},
+	},
+	"dice:room": {
+		Label:     "Run dice room",
+		App:       "rrroll-the-dice",
+		Endpoints: []string{"GET /api/config", "GET /api/me"},
+	},
+}
+
+const maxKioskLabelLength = 100
+
+// kioskEndpointMethods are the methods an extra endpoint may name
+var kioskEndpointMethods = map[string]bool{"GET": true, "POST": true, "PUT": true, "PATCH": true, "DELETE": true}
+
+// validKioskEndpoint checks an extra endpoint is "METHOD /api/path", with *
+// only as the last character
+func validKioskEndpoint(e string) bool {
+	method, path, ok := strings.Cut(e, " ")
+	if !ok || !kioskEndpointMethods[method] || !strings.HasPrefix(path, "/api/") || strings.ContainsAny(path, " \t") {
+		return false
+	}
+	return !strings.Contains(strings.TrimSuffix(path, "*"), "*")
+}
+
+// kioskEndpoints expands capabilities plus any extra endpoints into the
+// token's sorted, de-duplicated endpoint list
+func kioskEndpoints(capabilities, extra []string) []string {
+	seen := map[string]bool{}
+	for _, c := range capabilities {
+		for _, e := range kioskCapabilities[c].Endpoints {
+			seen[e] = true
+		}
+	}
+	for _, e := range extra {
+		seen[e] = true
+	}
+	endpoints := make([]string, 0, len(seen))
+	for e := range seen {
+		endpoints = append(endpoints, e)
+	}
+	sort.Strings(endpoints)
+	return endpoints
+}
+
+// generateKioskToken returns a new kiosk token and its stored hash
+func generateKioskToken() (string, string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token := "kiosk-" + base64.RawURLEncoding.EncodeToString(b)
+	sum := sha256.Sum256([]byte(token))
+	return token, hex.EncodeToString(sum[:]), nil
+}
+
+// handleGetKioskCapabilities returns the capabilities a kiosk token can be issued with
+// GET /api/kiosk-capabilities
+func handleGetKioskCapabilities(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"capabilities": kioskCapabilities,
+	})
+}
+
+// handleGetKioskTokens returns kiosk tokens visible to the current admin, newest first
+// GET /api/kiosk-tokens
+func handleGetKioskTokens(w http.ResponseWriter, r *http.Request) {
+	rows, err := identityDB.Query(`
+		SELECT k.id, k.label, k.token_hint, k.venue_id, COALESCE(v.name, ''), k.capabilities, k.endpoints,
+		       COALESCE(k.created_by, ''), k.created_at, k.expires_at, k.last_used_at, k.revoked_at
+		FROM kiosk_tokens k
+		LEFT JOIN venues v ON v.id = k.venue_id
+		WHERE $1 = 0 OR k.venue_id = $1
+		ORDER BY k.created_at DESC
+	`, adminVenueID(r))
+	if err != nil {
+		log.Printf("Error querying kiosk tokens: %v", err)
+		http.Error(w, "Failed to fetch kiosk tokens", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	tokens := []map[string]interface{}{}
+	for rows.Next() {
+		var id int
+		var label, hint, venueName, createdBy string
+		var venueID sql.NullInt64
+		var capabilities, endpoints []string
+		var createdAt time.Time
+		var expiresAt, lastUsedAt, revokedAt sql.NullTime
+
+		if err := rows.Scan(&id, &label, &hint, &venueID, &venueName, pq.Array(&capabilities), pq.Array(&endpoints),
+			&createdBy, &createdAt, &expiresAt, &lastUsedAt, &revokedAt); err != nil {
+			log.Printf("Error scanning kiosk token: %v", err)
+			continue
+		}
+
+		tokens = append(tokens, map[string]interface{}{
+			"id":           id,
+			"label":        label,
+			"hint":         hint,
+			"venueId":      nullableInt(venueID),
+			"venueName":    venueName,
+			"capabilities": capabilities,
+			"endpoints":    endpoints,
+			"createdBy":    createdBy,
+			"createdAt":    createdAt,
+			"expiresAt":    nullableTime(expiresAt),
+			"lastUsedAt":   nullableTime(lastUsedAt),
+			"revokedAt":    nullableTime(revokedAt),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"tokens": tokens,
+	})
+}
+
+func nullableTime(v sql.NullTime) interface{} {
+	if !v.Valid {
+		return nil
+	}
+	return v.Time
+}
+
+// handleCreateKioskToken issues a kiosk token. The token is only ever returned here.
+// Venue admins can only issue tokens for their own venue.
+// POST /api/kiosk-tokens  {label, venueId, capabilities, endpoints, expiresInDays}
+func handleCreateKioskToken(w http.ResponseWriter, r *http.Request) {
+	if !requireWritePermission(w, r) {
+		return
+	}
+
+	var req struct {
+		Label         string   `json:"label"`
+		VenueID       int      `json:"venueId"`
+		Capabilities  []string `json:"capabilities"`
+		Endpoints     []string `json:"endpoints"`     // Extra endpoints beyond the capabilities'
+		ExpiresInDays int      `json:"expiresInDays"` // 0 = never
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	req.Label = strings.TrimSpace(req.Label)
+	if req.Label == "" || len(req.Label) > maxKioskLabelLength {
+		http.Error(w, "Label must be 1-100 characters", http.StatusBadRequest)
+		return
+	}
+	if len(req.Capabilities) == 0 {
+		http.Error(w, "Choose at least one capability", http.StatusBadRequest)
+		return
+	}
+	for _, c := range req.Capabilities {
+		if _, ok := kioskCapabilities[c]; !ok {
+			http.Error(w, "Unknown capability: "+c, http.StatusBadRequest)
+			return
+		}
+	}
+	extra := []string{}
+	for _, e := range req.Endpoints {
+		e = strings.TrimSpace(e)
+		if e == "" {
+			continue
+		}
+		if !validKioskEndpoint(e) {
+			http.Error(w, "Invalid endpoint (use \"METHOD /api/path\", * only at the end): "+e, http.StatusBadRequest)
+			return
+		}
+		extra = append(extra, e)
+	}
+	if req.ExpiresInDays < 0 {
+		http.Error(w, "expiresInDays must be 0 (never) or more", http.StatusBadRequest)
+		return
+	}
+
+	// Venue admins issue tokens for their own venue only
+	if venueID := adminVenueID(r); venueID != 0 {
+		req.VenueID = venueID
+	}
+	var venue interface{}
+	if req.VenueID != 0 {
+		venue = req.VenueID
+	}
+	var expiresAt interface{}
+	if req.ExpiresInDays > 0 {
+		expiresAt = time.Now().AddDate(0, 0, req.ExpiresInDays)
+	}
+
+	token, hash, err := generateKioskToken()
+	if err != nil {
+		log.Printf("Error generating kiosk token: %v", err)
+		http.Error(w, "Failed to create kiosk token", http.StatusInternalServerError)
+		return
+	}
+	endpoints := kioskEndpoints(req.Capabilities, extra)
+
+	var id int
+	err = identityDB.QueryRow(`
+		INSERT INTO kiosk_tokens (label, token_hash, token_hint, venue_id, capabilities, endpoints, created_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id
+	`, req.Label, hash, token[len(token)-4:], venue, pq.Array(req.Capabilities), pq.Array(endpoints),
+		r.Header.Get("X-Admin-Email"), expiresAt).Scan(&id)
+	if err != nil {
+		log.Printf("Error creating kiosk token: %v", err)
+		http.Error(w, "Failed to create kiosk token", http.StatusInternalServerError)
+		return
+	}
+
+	logAudit(r, "kiosk_token_create", strconv.Itoa(id), map[string]interface{}{
+		"label":        req.Label,
+		"venueId":      venue,
+		"capabilities": req.Capabilities,
+		"endpoints":    endpoints,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":   true,
+		"id":        id,
+		"token":     token,
+		"endpoints": endpoints,
+	})
+}
+
+// handleRevokeKioskToken revokes a kiosk token; the device stops working at once
+// POST /api/kiosk-tokens/{id}/revoke
+func handleRevokeKioskToken(w http.ResponseWriter, r *http.Request) {
+	if !requireWritePermission(w, r) {
+		return
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid kiosk token ID", http.StatusBadRequest)
+		return
+	}
+
+	result, err := identityDB.Exec(`
+		UPDATE kiosk_tokens SET revoked_at = CURRENT_TIMESTAMP, revoked_by = $1
+		WHERE id = $2 AND revoked_at IS NULL AND ($3 = 0 OR venue_id = $3)
+	`, r.Header.Get("X-Admin-Email"), id, adminVenueID(r))
+	if err != nil {
+		log.Printf("Error revoking kiosk token: %v", err)
+		http.Error(w, "Failed to revoke kiosk token", http.StatusInternalServerError)
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		http.Error(w, "Kiosk token not found or already revoked", http.StatusNotFound)
+		return
+	}
+
+	logAudit(r, "kiosk_token_revoke", strconv.Itoa(id), nil)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+	})
+}
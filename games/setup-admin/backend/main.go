@@ -7,8 +7,11 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 
-	"github.com/gorilla/handlers"
+	"github.com/achgithub/activity-hub-common/audit"
+	"github.com/achgithub/activity-hub-common/cli"
+	apphttp "github.com/achgithub/activity-hub-common/http"
 	"github.com/gorilla/mux"
 	"github.com/lib/pq"
 	_ "github.com/lib/pq"
@@ -17,9 +20,21 @@ import (
 var (
 	appDB      *sql.DB
 	identityDB *sql.DB
+	displayDB  *sql.DB // nil when Display Admin hasn't been set up
 )
 
 func main() {
+	cli.New("setup-admin",
+		cli.Serve(serve),
+		cli.Migrate(initAppDatabase, cli.Migrations{
+			Dir:    "../database",
+			Schema: []string{"schema.sql"},
+		}),
+		createAdminCommand(),
+	).Main()
+}
+
+func serve() {
 	var err error
 
 	// Connect to identity database (activity_hub)
@@ -38,6 +53,19 @@ func main() {
 	defer appDB.Close()
 	log.Println("✅ Connected to app database")
 
+	// Connect to display database (display_admin_db) for the display roster in
+	// configuration bundles. Optional - it only exists once Display Admin has run
+	displayDB, err = openDatabase("display_admin_db")
+	if err != nil {
+		log.Printf("⚠️  Display database unavailable, configuration bundles will leave displays out: %v", err)
+		displayDB = nil
+	} else {
+		defer displayDB.Close()
+		log.Println("✅ Connected to display database")
+	}
+
+	auditLog = audit.New(appDB)
+
 	// Setup router
 	r := mux.NewRouter()
 
@@ -50,12 +78,44 @@ func main() {
 
 	// User management
 	api.HandleFunc("/users", handleGetUsers).Methods("GET")
+	api.HandleFunc("/users", handleCreateUser).Methods("POST")
+	api.HandleFunc("/users/import", handleImportUsers).Methods("POST")
 	api.HandleFunc("/users/{email}/roles", handleUpdateUserRoles).Methods("PUT")
+	api.HandleFunc("/users/{email}/{action:deactivate|reactivate}", handleSetUserActive).Methods("POST")
+	api.HandleFunc("/users/{email}/rotate-code", handleRotateUserCode).Methods("POST")
+	api.HandleFunc("/users/{email}/venue", handleUpdateUserVenue).Methods("PUT")
+
+	// Venue management
+	api.HandleFunc("/venues", handleGetVenues).Methods("GET")
+	api.HandleFunc("/venues", handleCreateVenue).Methods("POST")
+	api.HandleFunc("/venues/{id}", handleUpdateVenue).Methods("PUT")
+	api.HandleFunc("/venues/{id}/hours", handleGetVenueHours).Methods("GET")
+	api.HandleFunc("/venues/{id}/hours", handleUpdateVenueHours).Methods("PUT")
+
+	// Feature flags
+	api.HandleFunc("/flags", handleGetFlags).Methods("GET")
+	api.HandleFunc("/flags", handleCreateFlag).Methods("POST")
+	api.HandleFunc("/flags/{key}", handleUpdateFlag).Methods("PUT")
+	api.HandleFunc("/flags/{key}", handleDeleteFlag).Methods("DELETE")
+
+	// Kiosk tokens for unattended devices
+	api.HandleFunc("/kiosk-capabilities", handleGetKioskCapabilities).Methods("GET")
+	api.HandleFunc("/kiosk-tokens", handleGetKioskTokens).Methods("GET")
+	api.HandleFunc("/kiosk-tokens", handleCreateKioskToken).Methods("POST")
+	api.HandleFunc("/kiosk-tokens/{id:[0-9]+}/revoke", handleRevokeKioskToken).Methods("POST")
 
 	// App management (proxies to identity-shell admin endpoints)
 	api.HandleFunc("/apps", handleGetApps).Methods("GET")
 	api.HandleFunc("/apps/{id}", handleUpdateApp).Methods("PUT")
 	api.HandleFunc("/apps/{id}/{action:enable|disable}", handleToggleApp).Methods("POST")
+	api.HandleFunc("/apps/{id}/maintenance", handleSetAppMaintenance).Methods("POST")
+
+	// Configuration bundles: clone a setup to another box or restore after reimaging
+	api.HandleFunc("/config/export", handleExportConfig).Methods("GET")
+	api.HandleFunc("/config/import", handleImportConfig).Methods("POST")
+
+	// Audit log, with before/after snapshots of changed rows
+	api.Handle("/audit", auditLog.AdminHandler()).Methods("GET")
 
 	// Serve frontend static files
 	r.PathPrefix("/static/").Handler(http.FileServer(http.Dir("./static")))
@@ -64,46 +124,27 @@ func main() {
 	})
 
 	// CORS configuration
-	corsHandler := handlers.CORS(
-		handlers.AllowedOrigins([]string{"*"}),
-		handlers.AllowedMethods([]string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}),
-		handlers.AllowedHeaders([]string{"Content-Type", "Authorization"}),
-	)
+	cors := apphttp.NewCORSPolicy(identityDB)
 
 	// Start server
 	port := getEnv("PORT", "5020")
 	log.Printf("🚀 Setup Admin starting on :%s", port)
-	log.Fatal(http.ListenAndServe(":"+port, corsHandler(r)))
+	log.Fatal(http.ListenAndServe(":"+port, cors.Middleware(r)))
 }
 
 func initIdentityDatabase() (*sql.DB, error) {
-	dbHost := getEnv("DB_HOST", "127.0.0.1")
-	dbPort := getEnv("DB_PORT", "5555")
-	dbUser := getEnv("DB_USER", "activityhub")
-	dbPass := getEnv("DB_PASS", "pubgames")
-	dbName := "activity_hub"
-
-	connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		dbHost, dbPort, dbUser, dbPass, dbName)
-
-	db, err := sql.Open("postgres", connStr)
-	if err != nil {
-		return nil, err
-	}
-
-	if err := db.Ping(); err != nil {
-		return nil, err
-	}
-
-	return db, nil
+	return openDatabase("activity_hub")
 }
 
 func initAppDatabase() (*sql.DB, error) {
+	return openDatabase("setup_admin_db")
+}
+
+func openDatabase(dbName string) (*sql.DB, error) {
 	dbHost := getEnv("DB_HOST", "127.0.0.1")
 	dbPort := getEnv("DB_PORT", "5555")
 	dbUser := getEnv("DB_USER", "activityhub")
 	dbPass := getEnv("DB_PASS", "pubgames")
-	dbName := "setup_admin_db"
 
 	connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
 		dbHost, dbPort, dbUser, dbPass, dbName)
@@ -114,6 +155,7 @@ func initAppDatabase() (*sql.DB, error) {
 	}
 
 	if err := db.Ping(); err != nil {
+		db.Close()
 		return nil, err
 	}
 
@@ -135,16 +177,16 @@ func requireSetupAdmin(next http.Handler) http.Handler {
 			token = token[7:]
 		}
 
-		var email string
+		var email, superUserEmail string
 
 		// Check for impersonation token
 		if len(token) > 12 && token[:12] == "impersonate-" {
 			var impersonatedEmail string
 			err := identityDB.QueryRow(`
-				SELECT impersonated_email
+				SELECT impersonated_email, super_user_email
 				FROM impersonation_sessions
 				WHERE impersonation_token = $1 AND is_active = TRUE
-			`, token).Scan(&impersonatedEmail)
+			`, token).Scan(&impersonatedEmail, &superUserEmail)
 
 			if err != nil {
 				http.Error(w, "Unauthorized", http.StatusUnauthorized)
@@ -161,7 +203,8 @@ func requireSetupAdmin(next http.Handler) http.Handler {
 
 		// Query user roles from identity database
 		var roles pq.StringArray
-		err := identityDB.QueryRow("SELECT COALESCE(roles, '{}') FROM users WHERE email = $1", email).Scan(&roles)
+		var venueID int
+		err := identityDB.QueryRow("SELECT COALESCE(roles, '{}'), COALESCE(venue_id, 0) FROM users WHERE email = $1 AND COALESCE(is_active, TRUE)", email).Scan(&roles, &venueID)
 		if err != nil {
 			http.Error(w, "Unauthorized", http.StatusUnauthorized)
 			return
@@ -194,6 +237,19 @@ func requireSetupAdmin(next http.Handler) http.Handler {
 		// Store email in context for audit logging
 		r.Header.Set("X-Admin-Email", email)
 
+		// Venue scope for multi-pub deployments (super_users are always chain-wide)
+		if hasSuperUser {
+			venueID = 0
+		}
+		r.Header.Set("X-Venue-ID", strconv.Itoa(venueID))
+
+		// Record the real actor behind an impersonated session
+		if superUserEmail != "" {
+			r.Header.Set("X-Impersonated-By", superUserEmail)
+		} else {
+			r.Header.Del("X-Impersonated-By")
+		}
+
 		next.ServeHTTP(w, r)
 	})
 }
@@ -207,10 +263,11 @@ func handleHealth(w http.ResponseWriter, r *http.Request) {
 }
 
 func handleConfig(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"appName": "Setup Admin",
-		"version": "1.0.0",
+	apphttp.WriteConfig(w, apphttp.AppConfig{
+		AppID:       "setup-admin",
+		Name:        "Setup Admin",
+		Icon:        "⚙️",
+		Description: "System configuration and user management",
 	})
 }
 
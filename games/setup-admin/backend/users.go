@@ -0,0 +1,474 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"strings"
+
+	"github.com/achgithub/activity-hub-common/audit"
+	"github.com/achgithub/activity-hub-common/cli"
+	"github.com/gorilla/mux"
+	"github.com/lib/pq"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// loginCodeLength is the number of digits in generated login codes
+const loginCodeLength = 6
+
+// generateLoginCode returns a random numeric login code
+func generateLoginCode() (string, error) {
+	digits := make([]byte, loginCodeLength)
+	for i := range digits {
+		n, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		digits[i] = byte('0' + n.Int64())
+	}
+	return string(digits), nil
+}
+
+// hashLoginCode bcrypt-hashes a login code for storage in users.code_hash
+func hashLoginCode(code string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(code), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// createUser inserts a new active user with a freshly generated login code.
+// venueID 0 creates a chain-wide user. Returns the plain-text code (only ever shown once) or an error.
+func createUser(email, name string, roles []string, venueID int) (string, error) {
+	code, err := generateLoginCode()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate code: %w", err)
+	}
+
+	hash, err := hashLoginCode(code)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash code: %w", err)
+	}
+
+	if roles == nil {
+		roles = []string{}
+	}
+
+	var venue interface{}
+	if venueID != 0 {
+		venue = venueID
+	}
+
+	_, err = identityDB.Exec(`
+		INSERT INTO users (email, name, code_hash, is_admin, roles, is_active, venue_id)
+		VALUES ($1, $2, $3, $4, $5, TRUE, $6)
+	`, email, name, hash, len(roles) > 0, pq.Array(roles), venue)
+	if err != nil {
+		return "", err
+	}
+
+	return code, nil
+}
+
+// isUniqueViolation reports whether err is a PostgreSQL unique constraint violation
+func isUniqueViolation(err error) bool {
+	if pqErr, ok := err.(*pq.Error); ok {
+		return pqErr.Code == "23505"
+	}
+	return false
+}
+
+// handleCreateUser creates a user with a generated login code
+// Venue-scoped admins always create users in their own venue
+// POST /api/users  {email, name, roles, venue_id}
+func handleCreateUser(w http.ResponseWriter, r *http.Request) {
+	if !requireWritePermission(w, r) {
+		return
+	}
+
+	var req struct {
+		Email   string   `json:"email"`
+		Name    string   `json:"name"`
+		Roles   []string `json:"roles"`
+		VenueID int      `json:"venue_id"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	req.Email = strings.ToLower(strings.TrimSpace(req.Email))
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Email == "" || req.Name == "" {
+		http.Error(w, "email and name are required", http.StatusBadRequest)
+		return
+	}
+
+	if venueID := adminVenueID(r); venueID != 0 {
+		req.VenueID = venueID
+	}
+	if !requireRoleChangeAllowed(w, r, nil, req.Roles) {
+		return
+	}
+
+	code, err := createUser(req.Email, req.Name, req.Roles, req.VenueID)
+	if isUniqueViolation(err) {
+		http.Error(w, "User already exists", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Printf("Error creating user: %v", err)
+		http.Error(w, "Failed to create user", http.StatusInternalServerError)
+		return
+	}
+
+	logAudit(r, "user_create", req.Email, map[string]interface{}{
+		"name":     req.Name,
+		"roles":    req.Roles,
+		"venue_id": req.VenueID,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"email":   req.Email,
+		"name":    req.Name,
+		"code":    code,
+	})
+}
+
+// handleSetUserActive deactivates or reactivates a user account.
+// Deactivated users cannot log in and their existing tokens stop validating.
+// POST /api/users/{email}/{action:deactivate|reactivate}
+func handleSetUserActive(w http.ResponseWriter, r *http.Request) {
+	if !requireWritePermission(w, r) {
+		return
+	}
+
+	vars := mux.Vars(r)
+	email := vars["email"]
+	active := vars["action"] == "reactivate"
+
+	// Prevent admins locking themselves out
+	if !active && email == r.Header.Get("X-Admin-Email") {
+		http.Error(w, "Cannot deactivate your own account", http.StatusBadRequest)
+		return
+	}
+
+	if !requireUserInVenue(w, r, email) {
+		return
+	}
+
+	before := snapshot(userSnapshot, email)
+	result, err := identityDB.Exec(`
+		UPDATE users
+		SET is_active = $1,
+		    deactivated_at = CASE WHEN $1 THEN NULL ELSE CURRENT_TIMESTAMP END
+		WHERE email = $2
+	`, active, email)
+	if err != nil {
+		log.Printf("Error updating user active state: %v", err)
+		http.Error(w, "Failed to update user", http.StatusInternalServerError)
+		return
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+
+	// End any impersonation sessions targeting a deactivated user
+	if !active {
+		identityDB.Exec(`
+			UPDATE impersonation_sessions
+			SET is_active = FALSE, ended_at = CURRENT_TIMESTAMP
+			WHERE impersonated_email = $1 AND is_active = TRUE
+		`, email)
+	}
+
+	actionType := "user_deactivate"
+	status := "deactivated"
+	if active {
+		actionType = "user_reactivate"
+		status = "reactivated"
+	}
+	logAuditChange(r, actionType, email, nil, before, snapshot(userSnapshot, email))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": "User " + status + " successfully",
+	})
+}
+
+// handleRotateUserCode generates a new login code for a user, invalidating the old one
+// POST /api/users/{email}/rotate-code
+func handleRotateUserCode(w http.ResponseWriter, r *http.Request) {
+	if !requireWritePermission(w, r) {
+		return
+	}
+
+	email := mux.Vars(r)["email"]
+
+	if !requireUserInVenue(w, r, email) {
+		return
+	}
+
+	code, err := generateLoginCode()
+	if err != nil {
+		log.Printf("Error generating code: %v", err)
+		http.Error(w, "Failed to rotate code", http.StatusInternalServerError)
+		return
+	}
+
+	hash, err := hashLoginCode(code)
+	if err != nil {
+		log.Printf("Error hashing code: %v", err)
+		http.Error(w, "Failed to rotate code", http.StatusInternalServerError)
+		return
+	}
+
+	result, err := identityDB.Exec(`
+		UPDATE users SET code_hash = $1, code_rotated_at = CURRENT_TIMESTAMP
+		WHERE email = $2
+	`, hash, email)
+	if err != nil {
+		log.Printf("Error rotating code: %v", err)
+		http.Error(w, "Failed to rotate code", http.StatusInternalServerError)
+		return
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+
+	logAudit(r, "user_code_rotate", email, nil)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"email":   email,
+		"code":    code,
+	})
+}
+
+// handleImportUsers bulk-creates users from a CSV upload.
+// Form field: file (CSV). Columns: email, name[, roles] where roles is ';'-separated.
+// A header row is detected and skipped. Existing users are reported and left untouched.
+// POST /api/users/import
+func handleImportUsers(w http.ResponseWriter, r *http.Request) {
+	if !requireWritePermission(w, r) {
+		return
+	}
+
+	if err := r.ParseMultipartForm(5 << 20); err != nil {
+		http.Error(w, "Failed to parse form", http.StatusBadRequest)
+		return
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "file field is required", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	type importedUser struct {
+		Email string `json:"email"`
+		Name  string `json:"name"`
+		Code  string `json:"code"`
+	}
+
+	// Imported users join the admin's venue (chain-wide admins import chain-wide users)
+	venueID := adminVenueID(r)
+
+	created := []importedUser{}
+	existing := []string{}
+	invalid := []string{}
+
+	line := 0
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			invalid = append(invalid, fmt.Sprintf("line %d: %v", line, err))
+			continue
+		}
+
+		if len(row) < 2 {
+			invalid = append(invalid, fmt.Sprintf("line %d: expected email,name", line))
+			continue
+		}
+
+		email := strings.ToLower(strings.TrimSpace(row[0]))
+		name := strings.TrimSpace(row[1])
+
+		// Skip header row
+		if line == 1 && email == "email" {
+			continue
+		}
+
+		if email == "" || name == "" || !strings.Contains(email, "@") {
+			invalid = append(invalid, fmt.Sprintf("line %d: invalid email or name", line))
+			continue
+		}
+
+		var roles []string
+		if len(row) > 2 && strings.TrimSpace(row[2]) != "" {
+			for _, role := range strings.Split(row[2], ";") {
+				if role = strings.TrimSpace(role); role != "" {
+					roles = append(roles, role)
+				}
+			}
+		}
+
+		if venueID != 0 && changesChainWideRoles(nil, roles) {
+			invalid = append(invalid, fmt.Sprintf("line %d: only a chain-wide admin can grant super_user or setup_admin", line))
+			continue
+		}
+
+		code, err := createUser(email, name, roles, venueID)
+		if isUniqueViolation(err) {
+			existing = append(existing, email)
+			continue
+		}
+		if err != nil {
+			log.Printf("Error importing user %s: %v", email, err)
+			invalid = append(invalid, fmt.Sprintf("line %d: failed to create %s", line, email))
+			continue
+		}
+
+		created = append(created, importedUser{Email: email, Name: name, Code: code})
+	}
+
+	logAudit(r, "user_import", "bulk", map[string]interface{}{
+		"created":  len(created),
+		"existing": len(existing),
+		"invalid":  len(invalid),
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":  true,
+		"created":  created,
+		"existing": existing,
+		"invalid":  invalid,
+	})
+}
+
+// restoreAdmin adds roles to an existing user's, reactivates them and issues
+// a new login code, returning it
+func restoreAdmin(email string, roles []string) (string, error) {
+	code, err := generateLoginCode()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate code: %w", err)
+	}
+
+	hash, err := hashLoginCode(code)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash code: %w", err)
+	}
+
+	_, err = identityDB.Exec(`
+		UPDATE users
+		SET roles = ARRAY(SELECT DISTINCT unnest(COALESCE(roles, '{}') || $1::text[])),
+		    is_admin = TRUE, is_active = TRUE, deactivated_at = NULL,
+		    code_hash = $2, code_rotated_at = CURRENT_TIMESTAMP
+		WHERE email = $3
+	`, pq.Array(roles), hash, email)
+	if err != nil {
+		return "", err
+	}
+
+	return code, nil
+}
+
+// createAdminCommand is the create-admin command: the first admin on a new
+// box, or a way back in for an admin who has lost their code. An existing
+// account keeps its roles and venue, gains the new roles, is reactivated and
+// gets a new code.
+func createAdminCommand() cli.Command {
+	var roleList string
+	var venueID int
+	return cli.Command{
+		Name:  "create-admin",
+		Args:  "<email> <name>",
+		Short: "Create an admin account, or restore one, and print its login code",
+		Flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&roleList, "roles", "setup_admin,super_user", "Comma-separated roles to grant")
+			fs.IntVar(&venueID, "venue", 0, "Venue ID of a new account (0 = chain-wide)")
+		},
+		Run: func(args []string) error {
+			if len(args) != 2 {
+				return cli.Usagef("expected an email and a name")
+			}
+			email := strings.ToLower(strings.TrimSpace(args[0]))
+			name := strings.TrimSpace(args[1])
+			if !strings.Contains(email, "@") || name == "" {
+				return cli.Usagef("invalid email or name")
+			}
+			var roles []string
+			for _, role := range strings.Split(roleList, ",") {
+				if role = strings.TrimSpace(role); role != "" {
+					roles = append(roles, role)
+				}
+			}
+			if len(roles) == 0 {
+				return cli.Usagef("an admin needs at least one role")
+			}
+
+			var err error
+			identityDB, err = initIdentityDatabase()
+			if err != nil {
+				return err
+			}
+			defer identityDB.Close()
+			appDB, err = initAppDatabase()
+			if err != nil {
+				return err
+			}
+			defer appDB.Close()
+			auditLog = audit.New(appDB)
+
+			action := "user_create"
+			code, err := createUser(email, name, roles, venueID)
+			if isUniqueViolation(err) {
+				action = "user_admin_restore"
+				code, err = restoreAdmin(email, roles)
+			}
+			if err != nil {
+				return err
+			}
+
+			details, _ := json.Marshal(map[string]interface{}{"name": name, "roles": roles, "venue_id": venueID})
+			if err := auditLog.Record(audit.Entry{AdminEmail: "cli", Action: action, TargetID: email, Details: details}); err != nil {
+				log.Printf("Warning: Failed to log audit action: %v", err)
+			}
+
+			if action == "user_create" {
+				fmt.Printf("Created %s (%s) with roles %s\n", email, name, strings.Join(roles, ", "))
+			} else {
+				fmt.Printf("%s already exists: granted %s, reactivated and issued a new code\n", email, strings.Join(roles, ", "))
+			}
+			fmt.Printf("Login code: %s\n", code)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/lib/pq"
+)
+
+// adminVenueID returns the venue the current admin is scoped to (0 = chain-wide)
+// Set by requireSetupAdmin via the X-Venue-ID header
+func adminVenueID(r *http.Request) int {
+	venueID, _ := strconv.Atoi(r.Header.Get("X-Venue-ID"))
+	return venueID
+}
+
+// requireChainWide checks the admin is not restricted to a single venue
+func requireChainWide(w http.ResponseWriter, r *http.Request) bool {
+	if adminVenueID(r) != 0 {
+		http.Error(w, "Forbidden - Chain-wide admin required. Venue admins can only manage their own venue.", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// requireUserInVenue checks a venue-scoped admin is only touching users in their venue
+func requireUserInVenue(w http.ResponseWriter, r *http.Request, email string) bool {
+	venueID := adminVenueID(r)
+	if venueID == 0 {
+		return true
+	}
+
+	var userVenueID int
+	err := identityDB.QueryRow("SELECT COALESCE(venue_id, 0) FROM users WHERE email = $1", email).Scan(&userVenueID)
+	if err == sql.ErrNoRows {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return false
+	}
+	if err != nil {
+		log.Printf("Error checking user venue: %v", err)
+		http.Error(w, "Failed to check user venue", http.StatusInternalServerError)
+		return false
+	}
+
+	if userVenueID != venueID {
+		http.Error(w, "Forbidden - User belongs to another venue", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// chainWideRoles reach past a venue: super_user sees every venue and a
+// setup_admin can hand out roles, so only chain-wide admins grant or remove them
+var chainWideRoles = []string{"super_user", "setup_admin"}
+
+// changesChainWideRoles reports whether going from current to requested roles
+// grants or removes a chain-wide role
+func changesChainWideRoles(current, requested []string) bool {
+	for _, role := range chainWideRoles {
+		if containsString(current, role) != containsString(requested, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// requireRoleChangeAllowed checks a venue-scoped admin isn't granting or
+// removing a chain-wide role, which would let them out of their venue
+func requireRoleChangeAllowed(w http.ResponseWriter, r *http.Request, current, requested []string) bool {
+	if changesChainWideRoles(current, requested) && adminVenueID(r) != 0 {
+		http.Error(w, "Forbidden - Only a chain-wide admin can grant or remove super_user and setup_admin.", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// handleGetVenues returns venues visible to the current admin
+// GET /api/venues
+func handleGetVenues(w http.ResponseWriter, r *http.Request) {
+	rows, err := identityDB.Query(`
+		SELECT v.id, v.slug, v.name, COALESCE(v.address, ''), v.is_active, v.created_at,
+		       (SELECT COUNT(*) FROM users u WHERE u.venue_id = v.id)
+		FROM venues v
+		WHERE $1 = 0 OR v.id = $1
+		ORDER BY v.name
+	`, adminVenueID(r))
+	if err != nil {
+		log.Printf("Error querying venues: %v", err)
+		http.Error(w, "Failed to fetch venues", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	venues := []map[string]interface{}{}
+	for rows.Next() {
+		var id, userCount int
+		var slug, name, address string
+		var isActive bool
+		var createdAt interface{}
+
+		if err := rows.Scan(&id, &slug, &name, &address, &isActive, &createdAt, &userCount); err != nil {
+			log.Printf("Error scanning venue: %v", err)
+			continue
+		}
+
+		venues = append(venues, map[string]interface{}{
+			"id":        id,
+			"slug":      slug,
+			"name":      name,
+			"address":   address,
+			"is_active": isActive,
+			"userCount": userCount,
+			"createdAt": createdAt,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"venues": venues,
+	})
+}
+
+// handleCreateVenue creates a new venue
+// POST /api/venues  {slug, name, address}
+func handleCreateVenue(w http.ResponseWriter, r *http.Request) {
+	if !requireWritePermission(w, r) || !requireChainWide(w, r) {
+		return
+	}
+
+	var req struct {
+		Slug    string `json:"slug"`
+		Name    string `json:"name"`
+		Address string `json:"address"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	req.Slug = strings.ToLower(strings.TrimSpace(req.Slug))
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Slug == "" || req.Name == "" {
+		http.Error(w, "slug and name are required", http.StatusBadRequest)
+		return
+	}
+
+	var id int
+	err := identityDB.QueryRow(`
+		INSERT INTO venues (slug, name, address)
+		VALUES ($1, $2, $3)
+		RETURNING id
+	`, req.Slug, req.Name, req.Address).Scan(&id)
+	if isUniqueViolation(err) {
+		http.Error(w, "Venue slug already exists", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Printf("Error creating venue: %v", err)
+		http.Error(w, "Failed to create venue", http.StatusInternalServerError)
+		return
+	}
+
+	logAudit(r, "venue_create", strconv.Itoa(id), map[string]interface{}{
+		"slug": req.Slug,
+		"name": req.Name,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"id":      id,
+	})
+}
+
+// handleUpdateVenue updates a venue's name, address and active flag
+// PUT /api/venues/{id}
+func handleUpdateVenue(w http.ResponseWriter, r *http.Request) {
+	if !requireWritePermission(w, r) || !requireChainWide(w, r) {
+		return
+	}
+
+	venueID := mux.Vars(r)["id"]
+
+	var req struct {
+		Name     string `json:"name"`
+		Address  string `json:"address"`
+		IsActive bool   `json:"is_active"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if strings.TrimSpace(req.Name) == "" {
+		http.Error(w, "name is required", http.StatusBadRequest)
+		return
+	}
+
+	before := snapshot(venueSnapshot, venueID)
+	result, err := identityDB.Exec(`
+		UPDATE venues SET name = $1, address = $2, is_active = $3
+		WHERE id = $4
+	`, strings.TrimSpace(req.Name), req.Address, req.IsActive, venueID)
+	if err != nil {
+		log.Printf("Error updating venue: %v", err)
+		http.Error(w, "Failed to update venue", http.StatusInternalServerError)
+		return
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		http.Error(w, "Venue not found", http.StatusNotFound)
+		return
+	}
+
+	logAuditChange(r, "venue_update", venueID, map[string]interface{}{
+		"name":      req.Name,
+		"is_active": req.IsActive,
+	}, before, snapshot(venueSnapshot, venueID))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": "Venue updated successfully",
+	})
+}
+
+// handleUpdateUserVenue moves a user to a venue (null = chain-wide)
+// PUT /api/users/{email}/venue  {venue_id}
+func handleUpdateUserVenue(w http.ResponseWriter, r *http.Request) {
+	if !requireWritePermission(w, r) || !requireChainWide(w, r) {
+		return
+	}
+
+	email := mux.Vars(r)["email"]
+
+	var req struct {
+		VenueID *int `json:"venue_id"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	var venueID interface{}
+	if req.VenueID != nil && *req.VenueID != 0 {
+		venueID = *req.VenueID
+	}
+
+	before := snapshot(userSnapshot, email)
+	result, err := identityDB.Exec("UPDATE users SET venue_id = $1 WHERE email = $2", venueID, email)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+			http.Error(w, "Venue not found", http.StatusBadRequest)
+			return
+		}
+		log.Printf("Error updating user venue: %v", err)
+		http.Error(w, "Failed to update user venue", http.StatusInternalServerError)
+		return
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+
+	logAuditChange(r, "user_venue_change", email, map[string]interface{}{
+		"venue_id": venueID,
+	}, before, snapshot(userSnapshot, email))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": "User venue updated successfully",
+	})
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestVenueAdminCannotPromoteThemselves(t *testing.T) {
+	check := func(venueID string, current, requested []string) int {
+		r := httptest.NewRequest("PUT", "/api/users/admin@venue.local/roles", nil)
+		r.Header.Set("X-Venue-ID", venueID)
+		w := httptest.NewRecorder()
+		if requireRoleChangeAllowed(w, r, current, requested) {
+			return http.StatusOK
+		}
+		return w.Code
+	}
+
+	venueAdmin := []string{"setup_admin"}
+
+	// Granting super_user would make them chain-wide
+	if code := check("3", venueAdmin, []string{"setup_admin", "super_user"}); code != http.StatusForbidden {
+		t.Errorf("venue admin granting super_user = %d, want 403", code)
+	}
+	// Nor can they hand out or take away setup_admin
+	if code := check("3", []string{"quiz_master"}, []string{"quiz_master", "setup_admin"}); code != http.StatusForbidden {
+		t.Errorf("venue admin granting setup_admin = %d, want 403", code)
+	}
+	if code := check("3", venueAdmin, nil); code != http.StatusForbidden {
+		t.Errorf("venue admin removing setup_admin = %d, want 403", code)
+	}
+
+	// Venue roles are theirs to manage
+	if code := check("3", []string{"quiz_master"}, []string{"quiz_master", "game_admin"}); code != http.StatusOK {
+		t.Errorf("venue admin granting game_admin = %d, want allowed", code)
+	}
+	// A chain-wide admin can do either
+	if code := check("0", venueAdmin, []string{"setup_admin", "super_user"}); code != http.StatusOK {
+		t.Errorf("chain-wide admin granting super_user = %d, want allowed", code)
+	}
+}
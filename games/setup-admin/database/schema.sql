@@ -15,5 +15,13 @@ CREATE TABLE IF NOT EXISTS audit_log (
 CREATE INDEX IF NOT EXISTS idx_audit_log_admin ON audit_log(admin_email);
 CREATE INDEX IF NOT EXISTS idx_audit_log_created ON audit_log(created_at DESC);
 
+-- Real actor when the change was made through an impersonation session
+ALTER TABLE audit_log ADD COLUMN IF NOT EXISTS impersonated_by VARCHAR(255);
+
+-- The changed row before and after, for audited edits (NULL before a create, after a delete)
+ALTER TABLE audit_log ADD COLUMN IF NOT EXISTS before_snapshot JSONB;
+ALTER TABLE audit_log ADD COLUMN IF NOT EXISTS after_snapshot JSONB;
+CREATE INDEX IF NOT EXISTS idx_audit_log_target ON audit_log(target_id, created_at DESC);
+
 -- Grant permissions
 -- (Will be granted to activityhub user during setup)
@@ -14,6 +14,7 @@ const AVAILABLE_ROLES = [
   { id: 'super_user', label: 'Super User', color: '#FF9800' },
   { id: 'game_manager', label: 'Game Manager', color: '#4CAF50' },
   { id: 'quiz_master', label: 'Quiz Master', color: '#E91E63' },
+  { id: 'question_contributor', label: 'Question Contributor', color: '#00897B' },
   { id: 'admin', label: 'Admin', color: '#F44336' },
 ];
 
@@ -25,6 +26,15 @@ interface User {
   createdAt: string;
 }
 
+// Paging info list endpoints return alongside their items
+interface Page {
+  limit: number;
+  offset: number;
+  sort?: string;
+  hasMore: boolean;
+  nextOffset?: number;
+}
+
 interface AppRecord {
   id: string;
   name: string;
@@ -38,6 +48,10 @@ interface AppRecord {
   requiredRoles: string[];
   enabled: boolean;
   displayOrder: number;
+  maintenance: boolean;
+  maintenanceMessage: string;
+  maintenanceSince: string | null;
+  maintenanceBy: string;
 }
 
 interface RoleChipsProps {
@@ -67,9 +81,425 @@ function RoleChips({ userRoles, onToggleRole, disabled }: RoleChipsProps) {
   );
 }
 
+interface KioskCapability {
+  label: string;
+  app: string;
+  endpoints: string[];
+}
+
+interface KioskToken {
+  id: number;
+  label: string;
+  hint: string;
+  venueName: string;
+  capabilities: string[];
+  endpoints: string[];
+  createdBy: string;
+  createdAt: string;
+  expiresAt: string | null;
+  lastUsedAt: string | null;
+  revokedAt: string | null;
+}
+
+interface KioskTokensProps {
+  token: string;
+  readOnly: boolean;
+}
+
+// Long-lived tokens for unattended devices (leaderboard TVs, quiz displays,
+// the dice screen). Each only works on the endpoints of its capabilities.
+function KioskTokens({ token, readOnly }: KioskTokensProps) {
+  const [capabilities, setCapabilities] = useState<Record<string, KioskCapability>>({});
+  const [kiosks, setKiosks] = useState<KioskToken[]>([]);
+  const [label, setLabel] = useState('');
+  const [selected, setSelected] = useState<string[]>([]);
+  const [extraEndpoints, setExtraEndpoints] = useState('');
+  const [expiresInDays, setExpiresInDays] = useState(0);
+  const [issued, setIssued] = useState<{ label: string; token: string } | null>(null);
+
+  const authHeaders = { 'Authorization': `Bearer ${token}` };
+
+  const fetchKiosks = async () => {
+    try {
+      const [capsRes, tokensRes] = await Promise.all([
+        fetch(`${API_BASE}/api/kiosk-capabilities`, { headers: authHeaders }),
+        fetch(`${API_BASE}/api/kiosk-tokens`, { headers: authHeaders }),
+      ]);
+      setCapabilities((await capsRes.json()).capabilities || {});
+      setKiosks((await tokensRes.json()).tokens || []);
+    } catch (error) {
+      console.error('Failed to fetch kiosk tokens:', error);
+    }
+  };
+
+  useEffect(() => {
+    fetchKiosks();
+    // eslint-disable-next-line react-hooks/exhaustive-deps
+  }, [token]);
+
+  const toggleCapability = (id: string) => {
+    setSelected(selected.includes(id) ? selected.filter(c => c !== id) : [...selected, id]);
+  };
+
+  const issueToken = async () => {
+    try {
+      const response = await fetch(`${API_BASE}/api/kiosk-tokens`, {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json', ...authHeaders },
+        body: JSON.stringify({
+          label,
+          capabilities: selected,
+          endpoints: extraEndpoints.split('\n').map(e => e.trim()).filter(Boolean),
+          expiresInDays,
+        }),
+      });
+      if (!response.ok) {
+        alert(await response.text());
+        return;
+      }
+      const data = await response.json();
+      setIssued({ label, token: data.token });
+      setLabel('');
+      setSelected([]);
+      setExtraEndpoints('');
+      fetchKiosks();
+    } catch (error) {
+      console.error('Failed to issue kiosk token:', error);
+      alert('Failed to issue kiosk token');
+    }
+  };
+
+  const revokeToken = async (kiosk: KioskToken) => {
+    if (!window.confirm(`Revoke "${kiosk.label}"? The device will stop working straight away.`)) return;
+    try {
+      const response = await fetch(`${API_BASE}/api/kiosk-tokens/${kiosk.id}/revoke`, {
+        method: 'POST',
+        headers: authHeaders,
+      });
+      if (!response.ok) {
+        alert(await response.text());
+      }
+      fetchKiosks();
+    } catch (error) {
+      console.error('Failed to revoke kiosk token:', error);
+    }
+  };
+
+  return (
+    <div className="ah-card">
+      <h3 className="ah-section-title">Kiosk Tokens</h3>
+      <p className="ah-meta mb-3">
+        Long-lived tokens for unattended devices. A kiosk token only works on the endpoints its capabilities allow.
+      </p>
+
+      {issued && (
+        <div className="ah-banner ah-banner--warning mb-3">
+          🔑 Token for <strong>{issued.label}</strong> - copy it now, it won't be shown again:
+          <div className="mt-2"><code>{issued.token}</code></div>
+          <button className="ah-btn-outline text-xs mt-2" onClick={() => setIssued(null)}>Done</button>
+        </div>
+      )}
+
+      {!readOnly && (
+        <div className="mb-5">
+          <div className="ah-flex ah-flex-wrap gap-2 mb-2">
+            <input
+              className="ah-input"
+              type="text"
+              value={label}
+              onChange={(e) => setLabel(e.target.value)}
+              placeholder="Label, e.g. Bar TV"
+              maxLength={100}
+            />
+            <select
+              className="ah-select"
+              value={expiresInDays}
+              onChange={(e) => setExpiresInDays(Number(e.target.value))}
+            >
+              <option value={0}>Never expires</option>
+              <option value={30}>Expires in 30 days</option>
+              <option value={90}>Expires in 90 days</option>
+              <option value={365}>Expires in a year</option>
+            </select>
+          </div>
+          <div className="ah-role-chips mb-2">
+            {Object.entries(capabilities).map(([id, cap]) => (
+              <button
+                key={id}
+                className={`ah-role-chip ${selected.includes(id) ? 'active' : 'inactive'}`}
+                onClick={() => toggleCapability(id)}
+                title={cap.endpoints.join('\n')}
+              >
+                {selected.includes(id) ? '✓' : '+'} {cap.label}
+              </button>
+            ))}
+          </div>
+          <textarea
+            className="ah-input mb-2"
+            value={extraEndpoints}
+            onChange={(e) => setExtraEndpoints(e.target.value)}
+            placeholder={'Extra endpoints, one per line (optional), e.g. GET /api/standings/*'}
+            rows={2}
+          />
+          <button
+            className="ah-btn-primary"
+            onClick={issueToken}
+            disabled={!label.trim() || selected.length === 0}
+          >
+            Issue Token
+          </button>
+        </div>
+      )}
+
+      <table className="ah-html-table">
+        <thead>
+          <tr>
+            <th>Label</th>
+            <th>Venue</th>
+            <th>Capabilities</th>
+            <th>Last Used</th>
+            <th>Status</th>
+            <th>Actions</th>
+          </tr>
+        </thead>
+        <tbody>
+          {kiosks.map(kiosk => {
+            const expired = kiosk.expiresAt !== null && new Date(kiosk.expiresAt) < new Date();
+            return (
+              <tr key={kiosk.id} className={kiosk.revokedAt || expired ? 'opacity-60' : ''}>
+                <td>
+                  {kiosk.label}
+                  <div className="ah-meta text-xs">…{kiosk.hint} by {kiosk.createdBy || 'unknown'}</div>
+                </td>
+                <td>{kiosk.venueName || <span className="ah-meta">All venues</span>}</td>
+                <td>
+                  <div className="ah-flex ah-flex-wrap gap-1">
+                    {kiosk.capabilities.map(c => (
+                      <span key={c} className="ah-badge ah-badge--info" title={kiosk.endpoints.join('\n')}>
+                        {capabilities[c]?.label || c}
+                      </span>
+                    ))}
+                  </div>
+                </td>
+                <td>{kiosk.lastUsedAt ? new Date(kiosk.lastUsedAt).toLocaleString() : <span className="ah-meta">Never</span>}</td>
+                <td>
+                  {kiosk.revokedAt ? (
+                    <span className="ah-badge ah-badge--neutral text-xs">Revoked</span>
+                  ) : expired ? (
+                    <span className="ah-badge ah-badge--neutral text-xs">Expired</span>
+                  ) : (
+                    <span className="ah-badge ah-badge--info text-xs">
+                      {kiosk.expiresAt ? `Until ${new Date(kiosk.expiresAt).toLocaleDateString()}` : 'Active'}
+                    </span>
+                  )}
+                </td>
+                <td>
+                  {!readOnly && !kiosk.revokedAt && (
+                    <button className="ah-btn-outline text-xs" onClick={() => revokeToken(kiosk)}>
+                      Revoke
+                    </button>
+                  )}
+                </td>
+              </tr>
+            );
+          })}
+        </tbody>
+      </table>
+    </div>
+  );
+}
+
+interface ChangeCount {
+  created: number;
+  updated: number;
+}
+
+// POST /api/config/import response
+interface ImportSummary {
+  dryRun: boolean;
+  exportedAt: string;
+  exportedBy: string;
+  venues: ChangeCount;
+  apps: ChangeCount;
+  users: ChangeCount;
+  flags: ChangeCount;
+  displays: ChangeCount;
+  roles: string[];
+  newUsers: { email: string; name: string; code: string }[];
+  skipped: string[];
+}
+
+interface ConfigBackupProps {
+  token: string;
+  readOnly: boolean;
+}
+
+const BUNDLE_SECTIONS: { key: 'venues' | 'apps' | 'users' | 'flags' | 'displays'; label: string }[] = [
+  { key: 'venues', label: 'Venues' },
+  { key: 'apps', label: 'Apps' },
+  { key: 'users', label: 'Users' },
+  { key: 'flags', label: 'Feature flags' },
+  { key: 'displays', label: 'Displays' },
+];
+
+// Export the platform configuration as a signed file, and import one to clone
+// a setup onto this box or restore it after reimaging. Imports are previewed
+// (a dry run) before anything is saved.
+function ConfigBackup({ token, readOnly }: ConfigBackupProps) {
+  const [bundle, setBundle] = useState<{ name: string; text: string } | null>(null);
+  const [summary, setSummary] = useState<ImportSummary | null>(null);
+  const [busy, setBusy] = useState(false);
+
+  const authHeaders = { 'Authorization': `Bearer ${token}` };
+
+  const exportConfig = async () => {
+    try {
+      const response = await fetch(`${API_BASE}/api/config/export`, { headers: authHeaders });
+      if (!response.ok) {
+        alert(await response.text());
+        return;
+      }
+      const blob = await response.blob();
+      const url = URL.createObjectURL(blob);
+      const a = document.createElement('a');
+      a.href = url;
+      a.download = `activity-hub-config-${new Date().toISOString().slice(0, 10)}.json`;
+      a.click();
+      URL.revokeObjectURL(url);
+    } catch (error) {
+      console.error('Failed to export configuration:', error);
+      alert('Failed to export configuration');
+    }
+  };
+
+  const runImport = async (text: string, dryRun: boolean) => {
+    setBusy(true);
+    try {
+      const response = await fetch(`${API_BASE}/api/config/import${dryRun ? '?dryRun=true' : ''}`, {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json', ...authHeaders },
+        body: text,
+      });
+      if (!response.ok) {
+        alert(await response.text());
+        setSummary(null);
+        return;
+      }
+      setSummary(await response.json());
+      if (!dryRun) setBundle(null);
+    } catch (error) {
+      console.error('Failed to import configuration:', error);
+      alert('Failed to import configuration');
+    } finally {
+      setBusy(false);
+    }
+  };
+
+  const chooseFile = async (file: File | undefined) => {
+    if (!file) return;
+    const text = await file.text();
+    setBundle({ name: file.name, text });
+    runImport(text, true);
+  };
+
+  return (
+    <div className="ah-card">
+      <h3 className="ah-section-title">Backup &amp; Clone</h3>
+      <p className="ah-meta mb-3">
+        Venues, apps, users and their roles, feature flags and displays in one signed file. Login codes and
+        display tokens are never included - imported users get new codes and TVs pair again.
+      </p>
+
+      <button className="ah-btn-primary mb-5" onClick={exportConfig}>
+        ⬇ Export Configuration
+      </button>
+
+      {!readOnly && (
+        <div className="mb-3">
+          <label className="ah-label">Import a configuration file</label>
+          <input
+            className="ah-input"
+            type="file"
+            accept="application/json,.json"
+            disabled={busy}
+            onChange={(e) => { chooseFile(e.target.files?.[0]); e.target.value = ''; }}
+          />
+        </div>
+      )}
+
+      {summary && (
+        <div className={`ah-banner ${summary.dryRun ? 'ah-banner--info' : 'ah-banner--success'} mb-3`}>
+          <strong>{summary.dryRun ? `Preview of ${bundle?.name || 'import'}` : 'Imported'}</strong>
+          {' '}- exported {new Date(summary.exportedAt).toLocaleString()} by {summary.exportedBy || 'unknown'}
+          <table className="ah-html-table mt-2">
+            <thead>
+              <tr>
+                <th></th>
+                <th>{summary.dryRun ? 'Would create' : 'Created'}</th>
+                <th>{summary.dryRun ? 'Would update' : 'Updated'}</th>
+              </tr>
+            </thead>
+            <tbody>
+              {BUNDLE_SECTIONS.map(section => (
+                <tr key={section.key}>
+                  <td>{section.label}</td>
+                  <td>{summary[section.key].created}</td>
+                  <td>{summary[section.key].updated}</td>
+                </tr>
+              ))}
+            </tbody>
+          </table>
+          {summary.roles.length > 0 && (
+            <div className="ah-flex ah-flex-wrap gap-1 mt-2">
+              <span className="ah-meta">Roles:</span>
+              {summary.roles.map(role => (
+                <span key={role} className="ah-badge ah-badge--info">{role}</span>
+              ))}
+            </div>
+          )}
+          {summary.skipped.length > 0 && (
+            <ul className="mt-2">
+              {summary.skipped.map(s => <li key={s} className="ah-meta">Skipped: {s}</li>)}
+            </ul>
+          )}
+          {summary.dryRun && bundle && (
+            <div className="ah-flex gap-2 mt-2">
+              <button className="ah-btn-primary" onClick={() => runImport(bundle.text, false)} disabled={busy}>
+                Import
+              </button>
+              <button className="ah-btn-outline" onClick={() => { setBundle(null); setSummary(null); }} disabled={busy}>
+                Cancel
+              </button>
+            </div>
+          )}
+        </div>
+      )}
+
+      {summary && !summary.dryRun && summary.newUsers.length > 0 && (
+        <div className="ah-banner ah-banner--warning">
+          🔑 New users' login codes - copy them now, they won't be shown again:
+          <table className="ah-html-table mt-2">
+            <tbody>
+              {summary.newUsers.map(u => (
+                <tr key={u.email}>
+                  <td>{u.name}</td>
+                  <td>{u.email}</td>
+                  <td><code>{u.code}</code></td>
+                </tr>
+              ))}
+            </tbody>
+          </table>
+        </div>
+      )}
+    </div>
+  );
+}
+
 function App() {
-  const [activeTab, setActiveTab] = useState<'users' | 'apps' | 'registry'>('users');
+  const [activeTab, setActiveTab] = useState<'users' | 'apps' | 'registry' | 'kiosks' | 'backup'>('users');
   const [users, setUsers] = useState<User[]>([]);
+  const [usersPage, setUsersPage] = useState<Page | null>(null);
+  const [userSearch, setUserSearch] = useState('');
   const [apps, setApps] = useState<AppRecord[]>([]);
   const [loading, setLoading] = useState(true);
   const [token, setToken] = useState<string>('');
@@ -82,7 +512,7 @@ function App() {
   // Get token and user info from URL parameters (passed from identity-shell)
   useEffect(() => {
     const params = new URLSearchParams(window.location.search);
-    const urlToken = params.get('token');
+    const urlToken = params.get('token') || sessionStorage.getItem('token');
     if (urlToken) {
       setToken(urlToken);
       fetchCurrentUser(urlToken);
@@ -122,14 +552,18 @@ function App() {
     }
   }, [activeTab, token]);
 
-  const fetchUsers = async () => {
-    setLoading(true);
+  // Fetches the first page of users, or appends the next one
+  const fetchUsers = async (offset = 0, search = userSearch) => {
+    if (offset === 0) setLoading(true);
     try {
-      const response = await fetch(`${API_BASE}/api/users`, {
+      const params = new URLSearchParams({ offset: String(offset) });
+      if (search.trim()) params.set('q', search.trim());
+      const response = await fetch(`${API_BASE}/api/users?${params}`, {
         headers: { 'Authorization': `Bearer ${token}` }
       });
       const data = await response.json();
-      setUsers(data.users || []);
+      setUsers(prev => offset === 0 ? (data.users || []) : [...prev, ...(data.users || [])]);
+      setUsersPage(data.page || null);
     } catch (error) {
       console.error('Failed to fetch users:', error);
     }
@@ -167,7 +601,8 @@ function App() {
       });
 
       if (response.ok) {
-        fetchUsers();
+        // Update in place so pages already loaded stay loaded
+        setUsers(prev => prev.map(u => u.email === email ? { ...u, roles: newRoles } : u));
       }
     } catch (error) {
       console.error('Failed to update role:', error);
@@ -196,6 +631,38 @@ function App() {
     setTogglingAppId(null);
   };
 
+  // Maintenance hides the app from players and its backend turns them away
+  // with the message; admins can still use it
+  const setMaintenance = async (app: AppRecord, on: boolean) => {
+    let message = '';
+    if (on) {
+      const entered = window.prompt(`Message for players while ${app.name} is in maintenance (blank for the standard one):`, '');
+      if (entered === null) return;
+      message = entered;
+    }
+    setTogglingAppId(app.id);
+    try {
+      const response = await fetch(`${API_BASE}/api/apps/${app.id}/maintenance`, {
+        method: 'POST',
+        headers: {
+          'Content-Type': 'application/json',
+          'Authorization': `Bearer ${token}`
+        },
+        body: JSON.stringify({ enabled: on, message })
+      });
+
+      if (response.ok) {
+        await fetchApps();
+      } else {
+        alert(await response.text() || 'Failed to update maintenance');
+      }
+    } catch (error) {
+      console.error('Failed to update maintenance:', error);
+      alert('Failed to update maintenance');
+    }
+    setTogglingAppId(null);
+  };
+
   const saveApp = async (app: AppRecord) => {
     setSaving(true);
     try {
@@ -237,8 +704,14 @@ function App() {
       if (response.ok) {
         const data = await response.json();
         if (data.success) {
-          // Redirect to identity-shell with impersonation token
-          window.location.href = `http://${window.location.hostname}:3001/?token=${data.token}`;
+          // Redirect to identity-shell with a single-use launch token for the
+          // impersonation session, so the session token stays out of the URL
+          const launchResponse = await fetch(`http://${window.location.hostname}:3001/api/auth/launch-token`, {
+            method: 'POST',
+            headers: { 'Authorization': `Bearer ${data.token}` }
+          });
+          const launch = launchResponse.ok ? (await launchResponse.json()).token : '';
+          window.location.href = `http://${window.location.hostname}:3001/${launch ? `?launch=${launch}` : ''}`;
         }
       } else {
         const errorData = await response.json();
@@ -311,6 +784,18 @@ function App() {
           >
             ⚙️ Registry Editor
           </button>
+          <button
+            className={`ah-tab ${activeTab === 'kiosks' ? 'active' : ''}`}
+            onClick={() => setActiveTab('kiosks')}
+          >
+            🔑 Kiosks
+          </button>
+          <button
+            className={`ah-tab ${activeTab === 'backup' ? 'active' : ''}`}
+            onClick={() => setActiveTab('backup')}
+          >
+            💾 Backup
+          </button>
         </div>
 
       {/* Read-only notice */}
@@ -321,7 +806,11 @@ function App() {
       )}
 
       {/* Content */}
-      {loading ? (
+      {activeTab === 'kiosks' ? (
+        <KioskTokens token={token} readOnly={readOnly} />
+      ) : activeTab === 'backup' ? (
+        <ConfigBackup token={token} readOnly={readOnly} />
+      ) : loading ? (
         <div className="ah-card">
           <p className="ah-meta">Loading...</p>
         </div>
@@ -545,6 +1034,18 @@ function App() {
       ) : activeTab === 'users' ? (
         <div className="ah-card">
           <h3 className="ah-section-title">User Management</h3>
+          <form
+            className="mb-2"
+            onSubmit={e => { e.preventDefault(); fetchUsers(0); }}
+          >
+            <input
+              className="ah-input"
+              type="search"
+              placeholder="Search name or email"
+              value={userSearch}
+              onChange={e => setUserSearch(e.target.value)}
+            />
+          </form>
           <table className="ah-html-table">
             <thead>
               <tr>
@@ -591,6 +1092,14 @@ function App() {
               ))}
             </tbody>
           </table>
+          {usersPage?.hasMore && (
+            <button
+              className="ah-btn-outline text-xs mt-2"
+              onClick={() => fetchUsers(usersPage.nextOffset)}
+            >
+              Load more
+            </button>
+          )}
         </div>
       ) : (
         <div className="ah-card">
@@ -601,6 +1110,7 @@ function App() {
                 <th>Icon</th>
                 <th>Name</th>
                 <th>Enabled</th>
+                <th>Maintenance</th>
                 <th>Roles Required</th>
                 <th>Order</th>
               </tr>
@@ -628,6 +1138,27 @@ function App() {
                       </button>
                     )}
                   </td>
+                  <td>
+                    {isCore || app.id === 'setup-admin' ? (
+                      <span className="ah-meta">-</span>
+                    ) : (
+                      <>
+                        <button
+                          className={`${app.maintenance ? 'ah-btn-primary' : 'ah-btn-outline'} text-xs`}
+                          onClick={() => setMaintenance(app, !app.maintenance)}
+                          disabled={readOnly || togglingAppId === app.id}
+                        >
+                          {app.maintenance ? '🔧 On' : 'Off'}
+                        </button>
+                        {app.maintenance && (
+                          <div className="ah-meta text-xs" title={app.maintenanceBy}>
+                            {app.maintenanceMessage || 'Standard message'}
+                            {app.maintenanceSince && ` · since ${new Date(app.maintenanceSince).toLocaleString()}`}
+                          </div>
+                        )}
+                      </>
+                    )}
+                  </td>
                   <td>
                     {app.requiredRoles.length > 0 ? (
                       <div className="ah-flex ah-flex-wrap gap-1">
@@ -0,0 +1,481 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/achgithub/activity-hub-common/database"
+)
+
+// checkTimeout bounds each individual diagnostic so one hung dependency can't stall the run
+const checkTimeout = 5 * time.Second
+
+// CheckResult is one cell of the pass/fail matrix
+type CheckResult struct {
+	Component  string `json:"component"` // identity, redis, sse, database
+	Name       string `json:"name"`
+	Status     string `json:"status"` // pass, fail, skip
+	Detail     string `json:"detail,omitempty"`
+	DurationMs int64  `json:"durationMs"`
+}
+
+// DiagnosticReport is the consolidated result of a full run
+type DiagnosticReport struct {
+	ID         int            `json:"id,omitempty"`
+	RunBy      string         `json:"runBy"`
+	Passed     bool           `json:"passed"`
+	Counts     map[string]int `json:"counts"`
+	Checks     []CheckResult  `json:"checks"`
+	StartedAt  time.Time      `json:"startedAt"`
+	DurationMs int64          `json:"durationMs"`
+}
+
+// identityShellURL is where the identity-shell backend listens (override for non-Pi setups)
+func identityShellURL() string {
+	if u := os.Getenv("IDENTITY_SHELL_URL"); u != "" {
+		return strings.TrimRight(u, "/")
+	}
+	return "http://127.0.0.1:3001"
+}
+
+// selfURL is this backend's own address, used to test the SSE endpoint end to end
+func selfURL() string {
+	if u := os.Getenv("SMOKE_TEST_SELF_URL"); u != "" {
+		return strings.TrimRight(u, "/")
+	}
+	return "http://127.0.0.1:5010"
+}
+
+// requireDiagnosticsAccess restricts diagnostics to setup_admin and super_user
+func requireDiagnosticsAccess(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, ok := authlib.GetUserFromContext(r.Context())
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if !user.HasRole("setup_admin") && !user.HasRole("super_user") {
+			http.Error(w, "Forbidden - setup_admin or super_user role required", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// errSkipped marks a check that isn't configured on this deployment
+var errSkipped = fmt.Errorf("skipped")
+
+// runCheck times a single check and converts its error into a matrix cell
+func runCheck(component, name string, fn func(ctx context.Context) (string, error)) CheckResult {
+	ctx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+	defer cancel()
+
+	start := time.Now()
+	detail, err := fn(ctx)
+	result := CheckResult{
+		Component:  component,
+		Name:       name,
+		Status:     "pass",
+		Detail:     detail,
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+	if err == errSkipped {
+		result.Status = "skip"
+	} else if err != nil {
+		result.Status = "fail"
+		result.Detail = err.Error()
+	}
+	return result
+}
+
+// postJSON sends a JSON body to the identity shell and decodes the JSON response
+func postJSON(ctx context.Context, endpoint string, body interface{}, out interface{}) error {
+	data, _ := json.Marshal(body)
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %d from %s", resp.StatusCode, endpoint)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+type loginResponse struct {
+	Success bool   `json:"success"`
+	Token   string `json:"token"`
+}
+
+// checkGuestLogin exercises the login path without needing credentials
+func checkGuestLogin(ctx context.Context) (string, error) {
+	var resp loginResponse
+	if err := postJSON(ctx, identityShellURL()+"/api/login/guest", map[string]string{}, &resp); err != nil {
+		return "", err
+	}
+	if !resp.Success || resp.Token == "" {
+		return "", fmt.Errorf("guest login returned no token")
+	}
+	return "guest token issued", nil
+}
+
+// checkAccountLogin logs in with a dedicated diagnostics account when one is configured
+func checkAccountLogin(ctx context.Context) (string, error) {
+	email, code := os.Getenv("SMOKE_TEST_EMAIL"), os.Getenv("SMOKE_TEST_CODE")
+	if email == "" || code == "" {
+		return "SMOKE_TEST_EMAIL / SMOKE_TEST_CODE not set", errSkipped
+	}
+
+	var resp loginResponse
+	if err := postJSON(ctx, identityShellURL()+"/api/login", map[string]string{"email": email, "code": code}, &resp); err != nil {
+		return "", err
+	}
+	if !resp.Success || resp.Token == "" {
+		return "", fmt.Errorf("login returned no token")
+	}
+	return "logged in as " + email, nil
+}
+
+// checkTokenValidation asks the identity shell to validate the admin's own token
+func checkTokenValidation(token string) func(ctx context.Context) (string, error) {
+	return func(ctx context.Context) (string, error) {
+		var resp struct {
+			Valid bool `json:"valid"`
+		}
+		if err := postJSON(ctx, identityShellURL()+"/api/validate", map[string]string{"token": token}, &resp); err != nil {
+			return "", err
+		}
+		if !resp.Valid {
+			return "", fmt.Errorf("identity shell rejected a known-good token")
+		}
+		return "token accepted", nil
+	}
+}
+
+// checkSharedAuth resolves the token through activity-hub-common, as every app backend does
+func checkSharedAuth(identityDB *sql.DB, token string) func(ctx context.Context) (string, error) {
+	return func(ctx context.Context) (string, error) {
+		user, err := authlib.ResolveToken(identityDB, token)
+		if err != nil {
+			return "", err
+		}
+		return "resolved " + user.Email, nil
+	}
+}
+
+// checkRedisPubSub publishes on a private channel and waits for the message to come back
+func checkRedisPubSub(ctx context.Context) (string, error) {
+	channel := fmt.Sprintf("smoke_test:diagnostics:%d", time.Now().UnixNano())
+	pubsub := redisClient.Subscribe(ctx, channel)
+	defer pubsub.Close()
+
+	// Wait for the subscription to be confirmed before publishing
+	if _, err := pubsub.Receive(ctx); err != nil {
+		return "", fmt.Errorf("subscribe failed: %w", err)
+	}
+
+	start := time.Now()
+	if err := redisClient.Publish(ctx, channel, "ping").Err(); err != nil {
+		return "", fmt.Errorf("publish failed: %w", err)
+	}
+
+	select {
+	case msg := <-pubsub.Channel():
+		if msg.Payload != "ping" {
+			return "", fmt.Errorf("unexpected payload %q", msg.Payload)
+		}
+		return fmt.Sprintf("round trip %dms", time.Since(start).Milliseconds()), nil
+	case <-ctx.Done():
+		return "", fmt.Errorf("message not received within %s", checkTimeout)
+	}
+}
+
+// mintStreamToken asks the identity shell for a single-use stream token, as the
+// frontends do before opening an EventSource
+func mintStreamToken(ctx context.Context, token string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", identityShellURL()+"/api/auth/stream-token", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("HTTP %d minting stream token", resp.StatusCode)
+	}
+	var out struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", err
+	}
+	return out.Token, nil
+}
+
+// checkSSEStream connects to this app's real SSE endpoint and waits for a published
+// ping to be delivered - covering stream tokens, auth, Redis pub/sub and streaming
+// in one path
+func checkSSEStream(token string) func(ctx context.Context) (string, error) {
+	return func(ctx context.Context) (string, error) {
+		streamToken, err := mintStreamToken(ctx, token)
+		if err != nil {
+			return "", err
+		}
+
+		streamURL := selfURL() + "/api/events?token=" + url.QueryEscape(streamToken)
+		req, err := http.NewRequestWithContext(ctx, "GET", streamURL, nil)
+		if err != nil {
+			return "", err
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("HTTP %d from SSE endpoint", resp.StatusCode)
+		}
+
+		nonce := fmt.Sprint(time.Now().UnixNano())
+		scanner := bufio.NewScanner(resp.Body)
+		connected := false
+		for scanner.Scan() {
+			line := scanner.Text()
+			if !strings.HasPrefix(line, "data: ") {
+				continue
+			}
+			payload := strings.TrimPrefix(line, "data: ")
+
+			if !connected && strings.Contains(payload, `"connected"`) {
+				connected = true
+				// Clients ignore event types they don't know, so live players are unaffected
+				ping := fmt.Sprintf(`{"type":"diagnostic_ping","nonce":"%s"}`, nonce)
+				if err := redisClient.Publish(ctx, REDIS_PUBSUB_CHANNEL, ping).Err(); err != nil {
+					return "", fmt.Errorf("publish failed: %w", err)
+				}
+				continue
+			}
+
+			if strings.Contains(payload, nonce) {
+				return "connected and received broadcast", nil
+			}
+		}
+
+		if !connected {
+			return "", fmt.Errorf("stream closed before connected event")
+		}
+		return "", fmt.Errorf("broadcast not received within %s", checkTimeout)
+	}
+}
+
+// appDatabases lists every app database ({app}_db) on the server plus the identity database
+func appDatabases(identityDB *sql.DB) ([]string, error) {
+	rows, err := identityDB.Query(`
+		SELECT datname FROM pg_database
+		WHERE NOT datistemplate AND datname LIKE '%\_db'
+		ORDER BY datname
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names := []string{"activity_hub"}
+	for rows.Next() {
+		var name string
+		if rows.Scan(&name) == nil {
+			names = append(names, name)
+		}
+	}
+	return names, rows.Err()
+}
+
+// checkDatabaseWrite opens the database with the app credentials and performs a write
+// inside a transaction that is always rolled back, so no data is left behind
+func checkDatabaseWrite(dbName string, identityDB *sql.DB) func(ctx context.Context) (string, error) {
+	return func(ctx context.Context) (string, error) {
+		conn := identityDB
+		if dbName != "activity_hub" {
+			var err error
+			conn, err = database.InitDatabaseByName(dbName)
+			if err != nil {
+				return "", err
+			}
+			defer conn.Close()
+		}
+
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return "", fmt.Errorf("begin failed: %w", err)
+		}
+		defer tx.Rollback()
+
+		if _, err := tx.ExecContext(ctx, "CREATE TEMP TABLE smoke_check (id INTEGER) ON COMMIT DROP"); err != nil {
+			return "", fmt.Errorf("create failed: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, "INSERT INTO smoke_check (id) VALUES (1)"); err != nil {
+			return "", fmt.Errorf("insert failed: %w", err)
+		}
+
+		var count int
+		if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM smoke_check").Scan(&count); err != nil || count != 1 {
+			return "", fmt.Errorf("read back failed: %v", err)
+		}
+		return "write + read back ok", nil
+	}
+}
+
+// runDiagnostics executes the full suite and builds the report
+func runDiagnostics(identityDB *sql.DB, user *authlib.AuthUser, token string) DiagnosticReport {
+	report := DiagnosticReport{
+		RunBy:     user.ActorEmail(),
+		StartedAt: time.Now(),
+		Counts:    map[string]int{"pass": 0, "fail": 0, "skip": 0},
+	}
+
+	checks := []CheckResult{
+		runCheck("identity", "Guest login", checkGuestLogin),
+		runCheck("identity", "Account login", checkAccountLogin),
+		runCheck("identity", "Token validation (identity-shell)", checkTokenValidation(token)),
+		runCheck("identity", "Token validation (shared library)", checkSharedAuth(identityDB, token)),
+		runCheck("redis", "Pub/sub round trip", checkRedisPubSub),
+		runCheck("sse", "Stream delivers broadcast", checkSSEStream(token)),
+	}
+
+	dbNames, err := appDatabases(identityDB)
+	if err != nil {
+		checks = append(checks, CheckResult{Component: "database", Name: "List app databases", Status: "fail", Detail: err.Error()})
+	}
+	for _, name := range dbNames {
+		checks = append(checks, runCheck("database", name, checkDatabaseWrite(name, identityDB)))
+	}
+
+	report.Checks = checks
+	report.Passed = true
+	for _, c := range checks {
+		report.Counts[c.Status]++
+		if c.Status == "fail" {
+			report.Passed = false
+		}
+	}
+	report.DurationMs = time.Since(report.StartedAt).Milliseconds()
+	return report
+}
+
+// HandleRunDiagnostics - POST /api/diagnostics/run
+// Runs the full platform self-test and stores the report
+func HandleRunDiagnostics(identityDB *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, _ := authlib.GetUserFromContext(r.Context())
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+		log.Printf("🧪 Diagnostics run started by %s", user.ActorEmail())
+		report := runDiagnostics(identityDB, user, token)
+
+		results, _ := json.Marshal(report.Checks)
+		err := db.QueryRow(`
+			INSERT INTO diagnostic_runs (run_by, passed, pass_count, fail_count, skip_count, results, started_at, duration_ms)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			RETURNING id
+		`, report.RunBy, report.Passed, report.Counts["pass"], report.Counts["fail"], report.Counts["skip"],
+			results, report.StartedAt, report.DurationMs).Scan(&report.ID)
+		if err != nil {
+			log.Printf("Failed to save diagnostic run: %v", err)
+		}
+
+		if report.Passed {
+			log.Printf("✅ Diagnostics passed (%d checks, %dms)", len(report.Checks), report.DurationMs)
+		} else {
+			log.Printf("❌ Diagnostics failed: %d of %d checks", report.Counts["fail"], len(report.Checks))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(report)
+	}
+}
+
+// HandleGetLatestDiagnostics - GET /api/diagnostics/latest
+func HandleGetLatestDiagnostics(w http.ResponseWriter, r *http.Request) {
+	var report DiagnosticReport
+	var results []byte
+	report.Counts = map[string]int{}
+	var pass, fail, skip int
+
+	err := db.QueryRow(`
+		SELECT id, run_by, passed, pass_count, fail_count, skip_count, results, started_at, duration_ms
+		FROM diagnostic_runs
+		ORDER BY started_at DESC
+		LIMIT 1
+	`).Scan(&report.ID, &report.RunBy, &report.Passed, &pass, &fail, &skip, &results, &report.StartedAt, &report.DurationMs)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err == sql.ErrNoRows {
+		json.NewEncoder(w).Encode(map[string]interface{}{"report": nil})
+		return
+	} else if err != nil {
+		log.Printf("Failed to load diagnostic run: %v", err)
+		http.Error(w, "Failed to load diagnostics", http.StatusInternalServerError)
+		return
+	}
+
+	report.Counts = map[string]int{"pass": pass, "fail": fail, "skip": skip}
+	json.Unmarshal(results, &report.Checks)
+	json.NewEncoder(w).Encode(map[string]interface{}{"report": report})
+}
+
+// HandleGetDiagnosticsHistory - GET /api/diagnostics/history
+// Summary of the last 20 runs (without per-check detail)
+func HandleGetDiagnosticsHistory(w http.ResponseWriter, r *http.Request) {
+	rows, err := db.Query(`
+		SELECT id, run_by, passed, pass_count, fail_count, skip_count, started_at, duration_ms
+		FROM diagnostic_runs
+		ORDER BY started_at DESC
+		LIMIT 20
+	`)
+	if err != nil {
+		http.Error(w, "Failed to load diagnostics history", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	runs := []DiagnosticReport{}
+	for rows.Next() {
+		var run DiagnosticReport
+		var pass, fail, skip int
+		if err := rows.Scan(&run.ID, &run.RunBy, &run.Passed, &pass, &fail, &skip, &run.StartedAt, &run.DurationMs); err != nil {
+			continue
+		}
+		run.Counts = map[string]int{"pass": pass, "fail": fail, "skip": skip}
+		runs = append(runs, run)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"runs": runs,
+	})
+}
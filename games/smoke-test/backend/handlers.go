@@ -9,6 +9,7 @@ import (
 	"time"
 
 	authlib "github.com/achgithub/activity-hub-common/auth"
+	apphttp "github.com/achgithub/activity-hub-common/http"
 	"github.com/go-redis/redis/v8"
 )
 
@@ -17,12 +18,19 @@ const REDIS_PUBSUB_CHANNEL = "smoke_test:updates"
 
 // HandleConfig returns app configuration
 func HandleConfig(w http.ResponseWriter, r *http.Request) {
-	config := map[string]interface{}{
-		"appName": APP_NAME,
-		"appIcon": "🧪",
-	}
+	apphttp.WriteConfig(w, apphttp.AppConfig{
+		AppID:        "smoke-test",
+		Name:         APP_NAME,
+		Icon:         "🧪",
+		Description:  "Reference implementation — demonstrates full Activity Hub stack (PostgreSQL + Redis + SSE + shared CSS)",
+		Capabilities: apphttp.Capabilities{SSE: true},
+	})
+}
+
+// HandleHealth answers the shell's status dashboard probe
+func HandleHealth(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(config)
+	w.Write([]byte(`{"status":"ok","service":"smoke-test"}`))
 }
 
 // HandleGetCounter returns the current counter value from Redis
@@ -6,8 +6,10 @@ import (
 	"net/http"
 
 	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/achgithub/activity-hub-common/cli"
 	"github.com/achgithub/activity-hub-common/database"
-	"github.com/gorilla/handlers"
+	apphttp "github.com/achgithub/activity-hub-common/http"
+	"github.com/achgithub/activity-hub-common/maintenance"
 	"github.com/gorilla/mux"
 	_ "github.com/lib/pq"
 )
@@ -17,6 +19,24 @@ var db *sql.DB
 const APP_NAME = "Smoke Test"
 
 func main() {
+	cli.New("smoke-test",
+		cli.Serve(serve),
+		cli.Migrate(openAppDB, cli.Migrations{
+			Dir:    "../database",
+			Schema: []string{"schema.sql"},
+			Upgrades: []string{
+				"migrate_add_diagnostics.sql",
+			},
+		}),
+	).Main()
+}
+
+// openAppDB connects to smoke_test_db for commands other than serve
+func openAppDB() (*sql.DB, error) {
+	return database.InitDatabase("smoke_test")
+}
+
+func serve() {
 	log.Printf("🧪 %s Backend Starting", APP_NAME)
 
 	// Initialize Redis
@@ -47,14 +67,21 @@ func main() {
 	// Setup router
 	r := mux.NewRouter()
 
-	// Public endpoint
+	// Public endpoints
 	r.HandleFunc("/api/config", HandleConfig).Methods("GET")
+	r.HandleFunc("/api/health", HandleHealth).Methods("GET")
 
 	// Protected endpoints (require authentication)
 	r.Handle("/api/counter", authMiddleware(http.HandlerFunc(HandleGetCounter))).Methods("GET")
 	r.Handle("/api/counter/increment", authMiddleware(http.HandlerFunc(HandleIncrementCounter))).Methods("POST")
 	r.Handle("/api/activity", authMiddleware(http.HandlerFunc(HandleGetActivity))).Methods("GET")
 
+	// Platform diagnostics (setup_admin / super_user only)
+	diagnostics := func(h http.Handler) http.Handler { return authMiddleware(requireDiagnosticsAccess(h)) }
+	r.Handle("/api/diagnostics/run", diagnostics(HandleRunDiagnostics(identityDB))).Methods("POST")
+	r.Handle("/api/diagnostics/latest", diagnostics(http.HandlerFunc(HandleGetLatestDiagnostics))).Methods("GET")
+	r.Handle("/api/diagnostics/history", diagnostics(http.HandlerFunc(HandleGetDiagnosticsHistory))).Methods("GET")
+
 	// SSE endpoint for real-time counter updates
 	r.Handle("/api/events", sseMiddleware(http.HandlerFunc(HandleSSE))).Methods("GET")
 
@@ -63,15 +90,11 @@ func main() {
 	r.PathPrefix("/").Handler(http.FileServer(http.Dir(staticDir)))
 
 	// CORS configuration
-	corsHandler := handlers.CORS(
-		handlers.AllowedOrigins([]string{"*"}),
-		handlers.AllowedMethods([]string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}),
-		handlers.AllowedHeaders([]string{"Content-Type", "Authorization"}),
-		handlers.AllowCredentials(),
-	)
+	cors := apphttp.NewCORSPolicy(identityDB)
+	appMaintenance := maintenance.New(identityDB)
 
 	// Start server
 	port := "5010"
 	log.Printf("🚀 %s backend listening on :%s", APP_NAME, port)
-	log.Fatal(http.ListenAndServe(":"+port, corsHandler(r)))
+	log.Fatal(http.ListenAndServe(":"+port, cors.Middleware(appMaintenance.Middleware("smoke-test")(apphttp.Versioned(r)))))
 }
@@ -0,0 +1,17 @@
+-- Migration: Add diagnostic_runs table for the platform self-test
+-- Run against smoke_test_db:
+--   psql -U activityhub -h localhost -p 5555 -d smoke_test_db -f games/smoke-test/database/migrate_add_diagnostics.sql
+
+CREATE TABLE IF NOT EXISTS diagnostic_runs (
+    id SERIAL PRIMARY KEY,
+    run_by VARCHAR(255) NOT NULL,
+    passed BOOLEAN NOT NULL,
+    pass_count INTEGER NOT NULL DEFAULT 0,
+    fail_count INTEGER NOT NULL DEFAULT 0,
+    skip_count INTEGER NOT NULL DEFAULT 0,
+    results JSONB NOT NULL DEFAULT '[]',
+    started_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    duration_ms INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_diagnostic_runs_started_at ON diagnostic_runs(started_at DESC);
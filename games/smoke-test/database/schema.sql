@@ -13,3 +13,18 @@ CREATE TABLE IF NOT EXISTS activity_log (
 
 CREATE INDEX idx_activity_log_created_at ON activity_log(created_at DESC);
 CREATE INDEX idx_activity_log_user ON activity_log(user_email);
+
+-- Diagnostic runs - platform self-test reports (pass/fail matrix per run)
+CREATE TABLE IF NOT EXISTS diagnostic_runs (
+    id SERIAL PRIMARY KEY,
+    run_by VARCHAR(255) NOT NULL,
+    passed BOOLEAN NOT NULL,
+    pass_count INTEGER NOT NULL DEFAULT 0,
+    fail_count INTEGER NOT NULL DEFAULT 0,
+    skip_count INTEGER NOT NULL DEFAULT 0,
+    results JSONB NOT NULL DEFAULT '[]',
+    started_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    duration_ms INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_diagnostic_runs_started_at ON diagnostic_runs(started_at DESC);
@@ -3,6 +3,20 @@ import './App.css';
 
 const API_BASE = window.location.origin;
 
+// Single-use stream token from the shell, so the session token never goes in
+// the EventSource URL. Empty on failure, which the stream rejects.
+async function fetchStreamToken(token: string): Promise<string> {
+  try {
+    const res = await fetch(`http://${window.location.hostname}:3001/api/auth/stream-token`, {
+      method: 'POST',
+      headers: { 'Authorization': `Bearer ${token}` },
+    });
+    return res.ok ? (await res.json()).token : '';
+  } catch {
+    return '';
+  }
+}
+
 interface Activity {
   userEmail: string;
   userName: string;
@@ -11,6 +25,30 @@ interface Activity {
   createdAt: string;
 }
 
+interface CheckResult {
+  component: string;
+  name: string;
+  status: 'pass' | 'fail' | 'skip';
+  detail?: string;
+  durationMs: number;
+}
+
+interface DiagnosticReport {
+  id?: number;
+  runBy: string;
+  passed: boolean;
+  counts: { pass: number; fail: number; skip: number };
+  checks: CheckResult[];
+  startedAt: string;
+  durationMs: number;
+}
+
+const STATUS_ICON: Record<CheckResult['status'], string> = {
+  pass: '✅',
+  fail: '❌',
+  skip: '⏭️',
+};
+
 // Parse query params from URL
 function useQueryParams() {
   return useMemo(() => {
@@ -18,7 +56,7 @@ function useQueryParams() {
     return {
       userId: params.get('userId'),
       userName: params.get('userName') || 'Unknown',
-      token: params.get('token'),
+      token: params.get('token') || sessionStorage.getItem('token'),
     };
   }, []);
 }
@@ -31,6 +69,11 @@ function App() {
   const [loading, setLoading] = useState(true);
   const [incrementing, setIncrementing] = useState(false);
 
+  // Diagnostics are only available to setup_admin / super_user (403 otherwise)
+  const [canRunDiagnostics, setCanRunDiagnostics] = useState(false);
+  const [report, setReport] = useState<DiagnosticReport | null>(null);
+  const [runningDiagnostics, setRunningDiagnostics] = useState(false);
+
   // Fetch initial counter and activity
   useEffect(() => {
     if (!token) return;
@@ -60,43 +103,68 @@ function App() {
     fetchData();
   }, [token]);
 
+  // Load the latest diagnostics report (also tells us whether this user may run them)
+  useEffect(() => {
+    if (!token) return;
+    fetch(`${API_BASE}/api/diagnostics/latest`, {
+      headers: { Authorization: `Bearer ${token}` },
+    })
+      .then((res) => {
+        if (!res.ok) return null;
+        setCanRunDiagnostics(true);
+        return res.json();
+      })
+      .then((data) => {
+        if (data?.report) setReport(data.report);
+      })
+      .catch((err) => console.error('Failed to load diagnostics:', err));
+  }, [token]);
+
   // Setup SSE connection for real-time updates
   useEffect(() => {
     if (!token) return;
 
-    const eventSource = new EventSource(
-      `${API_BASE}/api/events?token=${encodeURIComponent(token)}`
-    );
+    let current: EventSource | null = null;
+    let cancelled = false;
 
-    eventSource.onmessage = (event) => {
-      try {
-        const data = JSON.parse(event.data);
-
-        if (data.type === 'counter_update') {
-          setCounter(data.counter);
-
-          // Add to activity log (optimistic update)
-          const newActivity: Activity = {
-            userEmail: '',
-            userName: data.user,
-            action: 'increment',
-            counterValue: data.counter,
-            createdAt: new Date().toISOString(),
-          };
-          setActivities((prev) => [newActivity, ...prev].slice(0, 20));
+    fetchStreamToken(token).then((streamToken) => {
+      if (cancelled) return;
+      const eventSource = new EventSource(
+        `${API_BASE}/api/events?token=${encodeURIComponent(streamToken)}`
+      );
+      current = eventSource;
+
+      eventSource.onmessage = (event) => {
+        try {
+          const data = JSON.parse(event.data);
+
+          if (data.type === 'counter_update') {
+            setCounter(data.counter);
+
+            // Add to activity log (optimistic update)
+            const newActivity: Activity = {
+              userEmail: '',
+              userName: data.user,
+              action: 'increment',
+              counterValue: data.counter,
+              createdAt: new Date().toISOString(),
+            };
+            setActivities((prev) => [newActivity, ...prev].slice(0, 20));
+          }
+        } catch (err) {
+          console.error('SSE parse error:', err);
         }
-      } catch (err) {
-        console.error('SSE parse error:', err);
-      }
-    };
+      };
 
-    eventSource.onerror = () => {
-      console.error('SSE connection error');
-      eventSource.close();
-    };
+      eventSource.onerror = () => {
+        console.error('SSE connection error');
+        eventSource.close();
+      };
+    });
 
     return () => {
-      eventSource.close();
+      cancelled = true;
+      current?.close();
     };
   }, [token]);
 
@@ -117,6 +185,23 @@ function App() {
     setIncrementing(false);
   };
 
+  // Run the full platform self-test
+  const handleRunDiagnostics = async () => {
+    setRunningDiagnostics(true);
+    try {
+      const res = await fetch(`${API_BASE}/api/diagnostics/run`, {
+        method: 'POST',
+        headers: { Authorization: `Bearer ${token}` },
+      });
+      if (res.ok) {
+        setReport(await res.json());
+      }
+    } catch (err) {
+      console.error('Failed to run diagnostics:', err);
+    }
+    setRunningDiagnostics(false);
+  };
+
   // Auth check - after all hooks
   if (!userId || !token) {
     return (
@@ -228,6 +313,58 @@ function App() {
         )}
       </div>
 
+        {/* Platform Diagnostics (setup_admin / super_user only) */}
+        {canRunDiagnostics && (
+          <div className="ah-card">
+            <h3 className="ah-section-title">Platform Diagnostics</h3>
+            <p className="ah-meta">
+              Checks identity login, token validation, Redis pub/sub, SSE and writes to every app database.
+            </p>
+            <button
+              className="ah-btn-primary full-width"
+              onClick={handleRunDiagnostics}
+              disabled={runningDiagnostics}
+            >
+              {runningDiagnostics ? 'Running checks...' : 'Run Diagnostics'}
+            </button>
+
+            {report && (
+              <>
+                <div className={`ah-banner ${report.passed ? 'ah-banner--success' : 'ah-banner--error'} diagnostics-summary`}>
+                  {report.passed ? 'All systems go' : `${report.counts.fail} check(s) failed`}
+                  {' · '}
+                  {report.counts.pass} passed, {report.counts.skip} skipped
+                  {' · '}
+                  {new Date(report.startedAt).toLocaleString()} by {report.runBy}
+                </div>
+                <table className="ah-table">
+                  <thead>
+                    <tr>
+                      <th>Component</th>
+                      <th>Check</th>
+                      <th>Result</th>
+                      <th>Time</th>
+                    </tr>
+                  </thead>
+                  <tbody>
+                    {report.checks.map((check, idx) => (
+                      <tr key={idx}>
+                        <td>{check.component}</td>
+                        <td>
+                          {check.name}
+                          {check.detail && <div className="ah-meta">{check.detail}</div>}
+                        </td>
+                        <td>{STATUS_ICON[check.status]}</td>
+                        <td>{check.durationMs}ms</td>
+                      </tr>
+                    ))}
+                  </tbody>
+                </table>
+              </>
+            )}
+          </div>
+        )}
+
         {/* Tech Stack Info */}
         <div className="ah-card">
           <h3 className="ah-section-title">Tech Stack</h3>
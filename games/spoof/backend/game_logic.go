@@ -32,9 +32,13 @@ func StartNextRound(game *SpoofGame) {
 
 	// Create new round data
 	activePlayers := game.GetActivePlayers()
+	firstGuesser := 0
+	if game.Options.GuessOrder == GuessOrderRotate && len(activePlayers) > 0 {
+		firstGuesser = (game.CurrentRound - 1) % len(activePlayers)
+	}
 	game.RoundData = &RoundData{
 		RoundNumber:         game.CurrentRound,
-		GuessingPlayerIndex: 0,
+		GuessingPlayerIndex: firstGuesser,
 		GuessesThisRound:    make(map[string]int),
 		UsedGuesses:         []int{},
 	}
@@ -62,14 +66,15 @@ func CheckGameRules(game *SpoofGame) error {
 		if len(activePlayers) == 1 {
 			game.Status = "finished"
 			game.WinnerID = activePlayers[0].ID
+			SetBuyer(game)
 			UpdateGameInDB(game)
 		}
 		return nil
 	}
 
-	// Each player must have 0-3 coins remaining
+	// Each player must have between 0 and their starting coins remaining
 	for _, p := range activePlayers {
-		if p.CoinsRemaining < 0 || p.CoinsRemaining > 3 {
+		if p.CoinsRemaining < 0 || p.CoinsRemaining > game.Options.CoinsPerPlayer {
 			log.Printf("Warning: Player %s has invalid coins remaining: %d", p.Name, p.CoinsRemaining)
 		}
 	}
@@ -103,3 +108,12 @@ func IsGuessAvailable(game *SpoofGame, guess int) bool {
 	}
 	return true
 }
+
+// SetBuyer records who buys the round once a game is finished, if the game
+// is played with LoserBuysRound. The first player eliminated is the loser.
+func SetBuyer(game *SpoofGame) {
+	if !game.Options.LoserBuysRound || len(game.EliminatedIDs) == 0 {
+		return
+	}
+	game.BuyerID = game.EliminatedIDs[0]
+}
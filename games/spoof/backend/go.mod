@@ -3,8 +3,8 @@ module spoof
 go 1.25
 
 require (
+	github.com/achgithub/activity-hub-common v0.1.1
 	github.com/go-redis/redis/v8 v8.11.5
-	github.com/gorilla/handlers v1.5.1
 	github.com/gorilla/mux v1.8.0
 	github.com/lib/pq v1.10.9
 )
@@ -12,5 +12,6 @@ require (
 require (
 	github.com/cespare/xxhash/v2 v2.1.2 // indirect
 	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
-	github.com/felixge/httpsnoop v1.0.1 // indirect
 )
+
+replace github.com/achgithub/activity-hub-common => ../../../lib/activity-hub-common
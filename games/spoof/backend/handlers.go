@@ -32,6 +32,16 @@ func handleCreateGame(w http.ResponseWriter, r *http.Request) {
 			guessingMode = mode
 		}
 	}
+	if guessingMode != "fastest" && guessingMode != "roundrobin" {
+		respondError(w, "guessingMode must be fastest or roundrobin", http.StatusBadRequest)
+		return
+	}
+
+	options, err := ParseGameOptions(req.Options)
+	if err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	// Convert players from map to PlayerInfo
 	players := make([]PlayerInfo, len(req.Players))
@@ -42,7 +52,7 @@ func handleCreateGame(w http.ResponseWriter, r *http.Request) {
 		players[i] = PlayerInfo{
 			ID:             id,
 			Name:           name,
-			CoinsRemaining: 3, // Each player starts with 3 coins
+			CoinsRemaining: options.CoinsPerPlayer,
 			Order:          i,
 		}
 	}
@@ -68,7 +78,7 @@ func handleCreateGame(w http.ResponseWriter, r *http.Request) {
 	}())
 
 	// Create new game
-	game := NewSpoofGame(req.ChallengeID, players, guessingMode)
+	game := NewSpoofGame(req.ChallengeID, players, guessingMode, options)
 
 	// Store in Redis with 2-hour TTL
 	if err := SaveGame(game); err != nil {
@@ -119,7 +129,7 @@ func handleGetGame(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// handleSelectCoins handles a player selecting their coins (0-3)
+// handleSelectCoins handles a player selecting their coins (0 to their coins remaining)
 func handleSelectCoins(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	gameID := vars["gameId"]
@@ -130,9 +140,9 @@ func handleSelectCoins(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Validate coins
-	if req.CoinsInHand < 0 || req.CoinsInHand > 3 {
-		respondError(w, "Coins must be between 0 and 3", http.StatusBadRequest)
+	// Validate coins (the upper limit is checked against the player's coins below)
+	if req.CoinsInHand < 0 {
+		respondError(w, "Coins cannot be negative", http.StatusBadRequest)
 		return
 	}
 
@@ -253,9 +263,8 @@ func handleMakeGuess(w http.ResponseWriter, r *http.Request) {
 		log.Printf("Fastest finger mode: %s can guess", req.PlayerID)
 	}
 
-	// Validate guess range (0 to numActivePlayers * 3)
-	activePlayers := game.GetActivePlayers()
-	maxGuess := len(activePlayers) * 3
+	// Validate guess range (0 to the coins the active players have left)
+	maxGuess := GetMaxPossibleGuess(game)
 	if req.Guess < 0 || req.Guess > maxGuess {
 		respondError(w, fmt.Sprintf("Guess must be between 0 and %d", maxGuess), http.StatusBadRequest)
 		return
@@ -323,6 +332,7 @@ func handleMakeGuess(w http.ResponseWriter, r *http.Request) {
 		if activeCount == 1 {
 			game.Status = "finished"
 			game.WinnerID = game.GetActivePlayers()[0].ID
+			SetBuyer(game)
 			log.Printf("Game finished! Winner: %s", game.WinnerID)
 		}
 	}
@@ -408,7 +418,6 @@ func handleGameStream(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
 	w.Header().Set("Connection", "keep-alive")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
 
 	// Subscribe to game updates
 	pubsub := SubscribeToGameUpdates(gameID)
@@ -10,8 +10,11 @@ import (
 	"os"
 	"time"
 
+	"github.com/achgithub/activity-hub-common/cli"
+	"github.com/achgithub/activity-hub-common/database"
+	apphttp "github.com/achgithub/activity-hub-common/http"
+	"github.com/achgithub/activity-hub-common/maintenance"
 	"github.com/go-redis/redis/v8"
-	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
 	_ "github.com/lib/pq"
 )
@@ -21,9 +24,14 @@ var redisClient *redis.Client
 var ctx = context.Background()
 
 func main() {
-	var err error
+	cli.New("spoof",
+		cli.Serve(serve),
+		cli.Migrate(openDatabase, cli.Migrations{Dir: "../database", Schema: []string{"schema.sql"}}),
+	).Main()
+}
 
-	// Database connection
+// openDatabase connects to spoof_db
+func openDatabase() (*sql.DB, error) {
 	dbHost := getEnv("DB_HOST", "127.0.0.1")
 	dbPort := getEnv("DB_PORT", "5555")
 	dbUser := getEnv("DB_USER", "pubgames")
@@ -33,18 +41,35 @@ func main() {
 	connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
 		dbHost, dbPort, dbUser, dbPass, dbName)
 
-	db, err = sql.Open("postgres", connStr)
+	conn, err := sql.Open("postgres", connStr)
 	if err != nil {
-		log.Fatal("Failed to open database:", err)
+		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
-	defer db.Close()
+	if err := conn.Ping(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+func serve() {
+	var err error
 
-	if err := db.Ping(); err != nil {
+	db, err = openDatabase()
+	if err != nil {
 		log.Fatal("Failed to connect to database:", err)
 	}
+	defer db.Close()
 
 	log.Println("✅ Connected to PostgreSQL (spoof_db)")
 
+	// Identity database, for the CORS policy and maintenance notices
+	identityDB, err := database.InitIdentityDatabase()
+	if err != nil {
+		log.Fatal("Failed to connect to identity database:", err)
+	}
+	defer identityDB.Close()
+
 	// Redis connection
 	redisHost := getEnv("REDIS_HOST", "127.0.0.1")
 	redisPort := getEnv("REDIS_PORT", "6379")
@@ -79,15 +104,12 @@ func main() {
 	r.PathPrefix("/").Handler(spaHandler{staticPath: staticDir, indexPath: "index.html"})
 
 	// CORS
-	corsHandler := handlers.CORS(
-		handlers.AllowedOrigins([]string{"*"}),
-		handlers.AllowedMethods([]string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}),
-		handlers.AllowedHeaders([]string{"Content-Type", "Authorization"}),
-	)
+	cors := apphttp.NewCORSPolicy(identityDB)
+	appMaintenance := maintenance.New(identityDB)
 
 	port := getEnv("PORT", "4051")
 	log.Printf("🚀 Spoof backend listening on port %s", port)
-	log.Fatal(http.ListenAndServe(":"+port, corsHandler(r)))
+	log.Fatal(http.ListenAndServe(":"+port, cors.Middleware(appMaintenance.Middleware("spoof")(r))))
 }
 
 func handleHealth(w http.ResponseWriter, r *http.Request) {
@@ -96,13 +118,13 @@ func handleHealth(w http.ResponseWriter, r *http.Request) {
 }
 
 func handleConfig(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	config := map[string]interface{}{
-		"appId":       "spoof",
-		"name":        "Spoof",
-		"icon":        "🪙",
-		"description": "Guess the total number of coins hidden in all players' hands",
-		"gameOptions": []map[string]interface{}{
+	apphttp.WriteConfig(w, apphttp.AppConfig{
+		AppID:        "spoof",
+		Name:         "Spoof",
+		Icon:         "🪙",
+		Description:  "Guess the total number of coins hidden in all players' hands",
+		Capabilities: apphttp.Capabilities{Challenges: true, SSE: true},
+		GameOptions: []map[string]interface{}{
 			{
 				"id":      "guessingMode",
 				"type":    "select",
@@ -113,9 +135,32 @@ func handleConfig(w http.ResponseWriter, r *http.Request) {
 					{"value": "roundrobin", "label": "Round Robin (take turns in order)"},
 				},
 			},
+			{
+				"id":      "guessOrder",
+				"type":    "select",
+				"label":   "Round Robin Order",
+				"default": GuessOrderFixed,
+				"options": []map[string]interface{}{
+					{"value": GuessOrderFixed, "label": "Same player guesses first each round"},
+					{"value": GuessOrderRotate, "label": "First guess moves round the table"},
+				},
+			},
+			{
+				"id":      "coinsPerPlayer",
+				"type":    "number",
+				"label":   "Coins Per Player",
+				"default": 3,
+				"min":     3,
+				"max":     5,
+			},
+			{
+				"id":      "loserBuysRound",
+				"type":    "checkbox",
+				"label":   "First player out buys the round",
+				"default": false,
+			},
 		},
-	}
-	respondJSON(w, config)
+	})
 }
 
 func getEnv(key, defaultValue string) string {
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"fmt"
 	"time"
 )
 
@@ -8,13 +9,13 @@ import (
 type PlayerInfo struct {
 	ID              string `json:"id"`              // User email
 	Name            string `json:"name"`            // Display name
-	CoinsInHand     int    `json:"coinsInHand"`     // 0-3, hidden from others until reveal
+	CoinsInHand     int    `json:"coinsInHand"`     // 0-coinsRemaining, hidden from others until reveal
 	HasSelected     bool   `json:"hasSelected"`     // Has chosen their coins
 	HasGuessed      bool   `json:"hasGuessed"`      // Has made a guess
 	Guess           int    `json:"guess,omitempty"` // Their guess (0-18 for 6 players)
 	IsEliminated    bool   `json:"isEliminated"`    // Out of the game
 	Order           int    `json:"order"`           // Turn order (0-based)
-	CoinsRemaining  int    `json:"coinsRemaining"`  // How many coins they have left (starts at 3)
+	CoinsRemaining  int    `json:"coinsRemaining"`  // How many coins they have left (starts at Options.CoinsPerPlayer)
 }
 
 // RoundData represents the current round state
@@ -39,6 +40,8 @@ type SpoofGame struct {
 	EliminatedIDs []string      `json:"eliminatedIds"`
 	WinnerID      string        `json:"winnerId,omitempty"`
 	GuessingMode  string        `json:"guessingMode"` // "fastest" or "roundrobin"
+	Options       GameOptions   `json:"options"`
+	BuyerID       string        `json:"buyerId,omitempty"` // Who buys the round, if Options.LoserBuysRound
 	StartedAt     int64         `json:"startedAt"`
 	UpdatedAt     int64         `json:"updatedAt"`
 }
@@ -80,6 +83,7 @@ func (g *SpoofGame) PlayerView(playerID string) map[string]interface{} {
 		"currentRound":  g.CurrentRound,
 		"eliminatedIds": g.EliminatedIDs,
 		"guessingMode":  g.GuessingMode,
+		"options":       g.Options,
 		"startedAt":     g.StartedAt,
 		"updatedAt":     g.UpdatedAt,
 	}
@@ -92,6 +96,10 @@ func (g *SpoofGame) PlayerView(playerID string) map[string]interface{} {
 		result["winnerId"] = g.WinnerID
 	}
 
+	if g.BuyerID != "" {
+		result["buyerId"] = g.BuyerID
+	}
+
 	return result
 }
 
@@ -198,18 +206,85 @@ type CreateGameRequest struct {
 	Options     map[string]interface{}   `json:"options"`
 }
 
+// Guess order rules for round robin guessing
+const (
+	GuessOrderFixed  = "fixed"  // The same player guesses first every round
+	GuessOrderRotate = "rotate" // The first guesser moves one seat on each round
+)
+
+// GameOptions are the rules chosen when the game is created
+type GameOptions struct {
+	CoinsPerPlayer int    `json:"coinsPerPlayer"` // 3-5
+	LoserBuysRound bool   `json:"loserBuysRound"` // The first player out buys the round
+	GuessOrder     string `json:"guessOrder"`     // GuessOrderFixed or GuessOrderRotate
+}
+
+// DefaultGameOptions are the rules games were played with before options existed
+func DefaultGameOptions() GameOptions {
+	return GameOptions{CoinsPerPlayer: 3, GuessOrder: GuessOrderFixed}
+}
+
+// ParseGameOptions reads and validates the options sent with a create request.
+// Missing options take their defaults.
+func ParseGameOptions(raw map[string]interface{}) (GameOptions, error) {
+	opts := DefaultGameOptions()
+
+	if v, ok := raw["coinsPerPlayer"]; ok && v != nil {
+		// JSON numbers decode as float64; the challenge modal may send strings
+		var n int
+		switch c := v.(type) {
+		case float64:
+			n = int(c)
+			if float64(n) != c {
+				return opts, fmt.Errorf("coinsPerPlayer must be a whole number")
+			}
+		case string:
+			if _, err := fmt.Sscanf(c, "%d", &n); err != nil {
+				return opts, fmt.Errorf("coinsPerPlayer must be a number")
+			}
+		default:
+			return opts, fmt.Errorf("coinsPerPlayer must be a number")
+		}
+		if n < 3 || n > 5 {
+			return opts, fmt.Errorf("coinsPerPlayer must be between 3 and 5")
+		}
+		opts.CoinsPerPlayer = n
+	}
+
+	if v, ok := raw["loserBuysRound"]; ok && v != nil {
+		switch b := v.(type) {
+		case bool:
+			opts.LoserBuysRound = b
+		case string:
+			opts.LoserBuysRound = b == "true"
+		default:
+			return opts, fmt.Errorf("loserBuysRound must be true or false")
+		}
+	}
+
+	if v, ok := raw["guessOrder"]; ok && v != nil {
+		order, _ := v.(string)
+		if order != GuessOrderFixed && order != GuessOrderRotate {
+			return opts, fmt.Errorf("guessOrder must be %q or %q", GuessOrderFixed, GuessOrderRotate)
+		}
+		opts.GuessOrder = order
+	}
+
+	return opts, nil
+}
+
 // SelectCoinsRequest represents a player selecting their coins
 type SelectCoinsRequest struct {
 	GameID      string `json:"gameId"`
 	PlayerID    string `json:"playerId"`
-	CoinsInHand int    `json:"coinsInHand"` // 0-3
+	CoinsInHand int    `json:"coinsInHand"` // 0 to the player's coinsRemaining
 }
 
 // MakeGuessRequest represents a player making a guess
 type MakeGuessRequest struct {
 	GameID   string `json:"gameId"`
 	PlayerID string `json:"playerId"`
-	Guess    int    `json:"guess"` // 0 to (numPlayers * 3)
+	Guess    int    `json:"guess"` // 0 to the active players' coins remaining
 }
 
 // GameResponse is the standard API response
@@ -222,7 +297,7 @@ type GameResponse struct {
 }
 
 // Helper function to create a new game
-func NewSpoofGame(challengeID string, players []PlayerInfo, guessingMode string) *SpoofGame {
+func NewSpoofGame(challengeID string, players []PlayerInfo, guessingMode string, options GameOptions) *SpoofGame {
 	now := time.Now().Unix()
 
 	// Default to fastest if not specified
@@ -237,6 +312,7 @@ func NewSpoofGame(challengeID string, players []PlayerInfo, guessingMode string)
 		Status:       "coin_selection",
 		CurrentRound: 1,
 		GuessingMode: guessingMode,
+		Options:      options,
 		StartedAt:    now,
 		UpdatedAt:    now,
 		RoundData: &RoundData{
@@ -40,6 +40,11 @@ func GetGame(gameID string) (*SpoofGame, error) {
 		return nil, fmt.Errorf("failed to unmarshal game: %w", err)
 	}
 
+	// Games created before options existed
+	if game.Options.CoinsPerPlayer == 0 {
+		game.Options = DefaultGameOptions()
+	}
+
 	return &game, nil
 }
 
@@ -23,6 +23,12 @@ interface RoundData {
   eliminatedThisRound?: string;
 }
 
+interface GameOptions {
+  coinsPerPlayer: number;
+  loserBuysRound: boolean;
+  guessOrder: 'fixed' | 'rotate';
+}
+
 interface GameState {
   id: string;
   challengeId: string;
@@ -33,6 +39,8 @@ interface GameState {
   eliminatedIds: string[];
   winnerId?: string;
   guessingMode: string;
+  options: GameOptions;
+  buyerId?: string;
   startedAt: number;
   updatedAt: number;
 }
@@ -253,7 +261,7 @@ function App() {
       <div className="game-container">
         {/* Game Status */}
         <div className={`status-banner status-${gameState.status}`}>
-          {gameState.status === 'coin_selection' && 'Select your coins (0-3)'}
+          {gameState.status === 'coin_selection' && `Select your coins (0-${currentPlayer.coinsRemaining})`}
           {gameState.status === 'guessing' && 'Make your guesses!'}
           {gameState.status === 'reveal' && 'Round Complete!'}
           {gameState.status === 'finished' && 'Game Over!'}
@@ -495,6 +503,11 @@ function App() {
             <div className="winner-announcement">
               <strong>{gameState.players.find(p => p.id === gameState.winnerId)?.name}</strong> wins!
             </div>
+            {gameState.options?.loserBuysRound && gameState.buyerId && (
+              <div className="buyer-announcement">
+                🍺 <strong>{gameState.players.find(p => p.id === gameState.buyerId)?.name}</strong> went out first and buys the round
+              </div>
+            )}
             <button className="action-btn secondary" onClick={handleExitGame}>
               Return to Lobby
             </button>
@@ -8,8 +8,10 @@ import (
 	"os"
 
 	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/achgithub/activity-hub-common/cli"
 	"github.com/achgithub/activity-hub-common/database"
-	"github.com/gorilla/handlers"
+	apphttp "github.com/achgithub/activity-hub-common/http"
+	"github.com/achgithub/activity-hub-common/maintenance"
 	"github.com/gorilla/mux"
 	_ "github.com/lib/pq"
 )
@@ -18,12 +20,31 @@ var db *sql.DB
 
 const APP_NAME = "Sudoku"
 
-type Config struct {
-	AppName string `json:"appName"`
-	Port    int    `json:"port"`
+// appConfig is served at /api/config. Puzzles are solo, so there is nothing
+// to challenge with.
+var appConfig = apphttp.AppConfig{
+	AppID:       "sudoku",
+	Name:        APP_NAME,
+	Icon:        "🔢",
+	Description: "Classic puzzle game - fill the 9x9 grid",
 }
 
 func main() {
+	cli.New("sudoku",
+		cli.Serve(serve),
+		cli.Migrate(openAppDB, cli.Migrations{
+			Dir:    "../database",
+			Schema: []string{"schema.sql", "schema_v2.sql"},
+		}),
+	).Main()
+}
+
+// openAppDB connects to sudoku_db for commands other than serve
+func openAppDB() (*sql.DB, error) {
+	return database.InitDatabase("sudoku")
+}
+
+func serve() {
 	log.Printf("🎯 %s Backend Starting", APP_NAME)
 
 	// Initialize app database
@@ -51,8 +72,9 @@ func main() {
 	r := mux.NewRouter()
 
 	// Public endpoints
-	r.HandleFunc("/api/config", handleConfig).Methods("GET")
+	r.HandleFunc("/api/config", apphttp.ConfigHandler(appConfig)).Methods("GET")
 	r.HandleFunc("/api/ping", handlePing).Methods("GET")
+	r.HandleFunc("/api/health", handleHealth).Methods("GET")
 	r.HandleFunc("/api/puzzles", handleListPuzzles).Methods("GET")
 	r.HandleFunc("/api/puzzles/{id}", handleGetPuzzle).Methods("GET")
 
@@ -68,26 +90,19 @@ func main() {
 	staticDir := "./static"
 	r.PathPrefix("/").Handler(http.FileServer(http.Dir(staticDir)))
 
-	// CORS for local development
-	corsHandler := handlers.CORS(
-		handlers.AllowedOrigins([]string{"*"}),
-		handlers.AllowedMethods([]string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}),
-		handlers.AllowedHeaders([]string{"Content-Type", "Authorization"}),
-	)(r)
+	// CORS
+	cors := apphttp.NewCORSPolicy(identityDB)
+	appMaintenance := maintenance.New(identityDB)
 
 	port := getEnv("PORT", "4081")
 	log.Printf("✅ %s server running on port %s", APP_NAME, port)
-	log.Fatal(http.ListenAndServe(":"+port, corsHandler))
+	log.Fatal(http.ListenAndServe(":"+port, cors.Middleware(appMaintenance.Middleware("sudoku")(apphttp.Versioned(r)))))
 }
 
-func handleConfig(w http.ResponseWriter, r *http.Request) {
-	config := Config{
-		AppName: "sudoku",
-		Port:    4081,
-	}
-
+// handleHealth answers the shell's status dashboard probe
+func handleHealth(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(config)
+	w.Write([]byte(`{"status":"ok","service":"sudoku"}`))
 }
 
 func handlePing(w http.ResponseWriter, r *http.Request) {
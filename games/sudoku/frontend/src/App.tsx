@@ -11,7 +11,7 @@ function App() {
     const params = new URLSearchParams(window.location.search);
     const userIdParam = params.get('userId') || 'guest';
     const userNameParam = params.get('userName') || 'Guest';
-    const tokenParam = params.get('token') || '';
+    const tokenParam = params.get('token') || sessionStorage.getItem('token') || '';
 
     setUserId(userIdParam);
     setUserName(userNameParam);
@@ -10,7 +10,7 @@ import (
 	"strings"
 
 	"github.com/gorilla/mux"
-	authlib "pub-games-v3/lib/activity-hub-common/auth"
+	authlib "github.com/achgithub/activity-hub-common/auth"
 )
 
 // ========== SETUP TAB HANDLERS ==========
@@ -5,12 +5,14 @@ import (
 	"log"
 	"net/http"
 
-	"github.com/gorilla/handlers"
+	"github.com/achgithub/activity-hub-common/cli"
+	apphttp "github.com/achgithub/activity-hub-common/http"
 	"github.com/gorilla/mux"
 	_ "github.com/lib/pq"
 
-	authlib "pub-games-v3/lib/activity-hub-common/auth"
-	"pub-games-v3/lib/activity-hub-common/database"
+	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/achgithub/activity-hub-common/database"
+	"github.com/achgithub/activity-hub-common/maintenance"
 )
 
 var (
@@ -19,6 +21,21 @@ var (
 )
 
 func main() {
+	cli.New("sweepstakes-knockout",
+		cli.Serve(serve),
+		cli.Migrate(openAppDB, cli.Migrations{
+			Dir:    "../database",
+			Schema: []string{"schema_v3.sql"},
+		}),
+	).Main()
+}
+
+// openAppDB connects to sweepstakes_knockout_db for commands other than serve
+func openAppDB() (*sql.DB, error) {
+	return database.InitDatabaseByName("sweepstakes_knockout_db")
+}
+
+func serve() {
 	var err error
 
 	// Connect to identity database
@@ -40,11 +57,8 @@ func main() {
 	r := mux.NewRouter()
 
 	// CORS
-	corsHandler := handlers.CORS(
-		handlers.AllowedOrigins([]string{"*"}),
-		handlers.AllowedMethods([]string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}),
-		handlers.AllowedHeaders([]string{"Content-Type", "Authorization"}),
-	)
+	cors := apphttp.NewCORSPolicy(identityDB)
+	appMaintenance := maintenance.New(identityDB)
 
 	// Public routes
 	r.HandleFunc("/api/config", handleConfig).Methods("GET")
@@ -94,12 +108,14 @@ func main() {
 
 	port := "4032"
 	log.Printf("Sweepstakes Knockout server starting on port %s...", port)
-	log.Fatal(http.ListenAndServe(":"+port, corsHandler(r)))
+	log.Fatal(http.ListenAndServe(":"+port, cors.Middleware(appMaintenance.Middleware("sweepstakes-knockout")(apphttp.Versioned(r)))))
 }
 
 func handleConfig(w http.ResponseWriter, r *http.Request) {
-	respondJSON(w, map[string]interface{}{
-		"app_name": "Sweepstakes Knockout",
-		"version":  "1.0.0",
+	apphttp.WriteConfig(w, apphttp.AppConfig{
+		AppID:       "sweepstakes-knockout",
+		Name:        "Sweepstakes Knockout",
+		Icon:        "🏇",
+		Description: "Manage sweepstakes for horse races, greyhounds, athletics, and more",
 	})
 }
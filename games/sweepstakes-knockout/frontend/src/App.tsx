@@ -7,7 +7,7 @@ const API_BASE = '/api';
 function useQueryParams() {
   const params = new URLSearchParams(window.location.search);
   return {
-    token: params.get('token') || '',
+    token: params.get('token') || sessionStorage.getItem('token') || '',
   };
 }
 
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/achgithub/activity-hub-common/config"
+	"github.com/gorilla/mux"
+	"github.com/skip2/go-qrcode"
+)
+
+// Card grid on an A4 page: 2 columns × 4 rows
+const (
+	cardCols    = 2
+	cardRows    = 4
+	cardMargin  = 24.0
+	cardGap     = 12.0
+	cardPadding = 12.0
+	cardQRSize  = 96.0
+)
+
+// drawCard is one printable card: who drew what, and where to see the result.
+type drawCard struct {
+	DrawID    int
+	Player    string
+	EntryName string
+	Seed      sql.NullInt64
+	Number    sql.NullInt64
+	Position  sql.NullInt64
+}
+
+// resultURL is the public result page a card's QR code opens.
+// SWEEPSTAKES_PUBLIC_URL overrides the host the request came in on.
+func resultURL(r *http.Request, drawID int) string {
+	base := config.GetEnv("SWEEPSTAKES_PUBLIC_URL", "http://"+r.Host)
+	return fmt.Sprintf("%s/?draw=%d", base, drawID)
+}
+
+// handleGetCompetitionCards renders a PDF with one card per draw for pinning
+// up behind the bar. Players appear under their public names.
+func handleGetCompetitionCards(w http.ResponseWriter, r *http.Request) {
+	user, ok := authlib.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if !user.HasRole("game_admin") && !user.HasRole("super_user") {
+		http.Error(w, "Forbidden - game_admin or super_user role required", http.StatusForbidden)
+		return
+	}
+
+	compID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid competition ID", http.StatusBadRequest)
+		return
+	}
+
+	var compName string
+	if err := appDB.QueryRow(`SELECT name FROM competitions WHERE id = $1`, compID).Scan(&compName); err != nil {
+		http.Error(w, "Competition not found", http.StatusNotFound)
+		return
+	}
+
+	rows, err := appDB.Query(`
+		SELECT d.id, d.user_id, e.name, e.seed, e.number, e.position
+		FROM draws d
+		JOIN entries e ON d.entry_id = e.id
+		WHERE d.competition_id = $1
+		ORDER BY COALESCE(e.position, 999), e.name
+	`, compID)
+	if err != nil {
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	cards := []drawCard{}
+	emails := []string{}
+	for rows.Next() {
+		var c drawCard
+		if err := rows.Scan(&c.DrawID, &c.Player, &c.EntryName, &c.Seed, &c.Number, &c.Position); err != nil {
+			continue
+		}
+		cards = append(cards, c)
+		emails = append(emails, c.Player)
+	}
+
+	profiles, err := authlib.LoadProfiles(identityDB, emails)
+	if err != nil {
+		log.Printf("Error loading profiles for cards: %v", err)
+	}
+	for i := range cards {
+		if p, ok := profiles[cards[i].Player]; ok {
+			cards[i].Player = p.PublicName()
+		} else {
+			cards[i].Player = authlib.AnonymousAlias(cards[i].Player)
+		}
+	}
+
+	doc := renderCards(r, compName, cards)
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=\"sweepstakes-%d-cards.pdf\"", compID))
+	w.Write(doc.bytes())
+}
+
+func renderCards(r *http.Request, compName string, cards []drawCard) *pdfDoc {
+	doc := &pdfDoc{}
+	cardW := (pdfPageWidth - 2*cardMargin - float64(cardCols-1)*cardGap) / cardCols
+	cardH := (pdfPageHeight - 2*cardMargin - float64(cardRows-1)*cardGap) / cardRows
+	textW := cardW - 3*cardPadding - cardQRSize
+
+	if len(cards) == 0 {
+		page := doc.addPage()
+		pdfText(page, fontBold, 16, cardMargin, pdfPageHeight-cardMargin-16, pdfFit(compName, 16, pdfPageWidth-2*cardMargin))
+		pdfText(page, fontRegular, 12, cardMargin, pdfPageHeight-cardMargin-40, "No draws yet.")
+		return doc
+	}
+
+	perPage := cardCols * cardRows
+	var page = doc.addPage()
+	for i, c := range cards {
+		if i > 0 && i%perPage == 0 {
+			page = doc.addPage()
+		}
+		slot := i % perPage
+		x := cardMargin + float64(slot%cardCols)*(cardW+cardGap)
+		y := pdfPageHeight - cardMargin - float64(slot/cardCols+1)*cardH - float64(slot/cardCols)*cardGap
+
+		pdfStrokeRect(page, x, y, cardW, cardH)
+
+		tx := x + cardPadding
+		ty := y + cardH - cardPadding - 10
+		pdfText(page, fontRegular, 10, tx, ty, pdfFit(compName, 10, textW))
+		pdfText(page, fontBold, 18, tx, ty-30, pdfFit(c.Player, 18, textW))
+		pdfText(page, fontRegular, 10, tx, ty-52, "drew")
+		pdfText(page, fontBold, 14, tx, ty-72, pdfFit(c.EntryName, 14, textW))
+
+		detail := ""
+		if c.Seed.Valid {
+			detail = fmt.Sprintf("Seed #%d", c.Seed.Int64)
+		} else if c.Number.Valid {
+			detail = fmt.Sprintf("#%d", c.Number.Int64)
+		}
+		if detail != "" {
+			pdfText(page, fontRegular, 10, tx, ty-90, detail)
+		}
+		if c.Position.Valid && c.Position.Int64 != 999 {
+			pdfText(page, fontBold, 12, tx, y+cardPadding, positionLabel(int(c.Position.Int64)))
+		}
+
+		q, err := qrcode.New(resultURL(r, c.DrawID), qrcode.Medium)
+		if err != nil {
+			log.Printf("Error generating QR for draw %d: %v", c.DrawID, err)
+			continue
+		}
+		q.DisableBorder = true
+		qx := x + cardW - cardPadding - cardQRSize
+		qy := y + (cardH-cardQRSize)/2
+		pdfQR(page, q.Bitmap(), qx, qy, cardQRSize)
+		pdfText(page, fontRegular, 7, qx, qy-9, "Scan for live result")
+	}
+	return doc
+}
+
+// positionLabel matches the player app's place labels.
+func positionLabel(pos int) string {
+	switch pos {
+	case 1:
+		return "1st Place"
+	case 2:
+		return "2nd Place"
+	case 3:
+		return "3rd Place"
+	}
+	return fmt.Sprintf("%dth Place", pos)
+}
+
+// handleGetDrawResult is the public result page behind a card's QR code. It
+// shows the entry and its standing, never the player's email.
+func handleGetDrawResult(w http.ResponseWriter, r *http.Request) {
+	drawID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid draw ID", http.StatusBadRequest)
+		return
+	}
+
+	var userID, entryName, entryStatus, compName, compStatus string
+	var compID, entryID int
+	var seed, number, position sql.NullInt64
+	err = appDB.QueryRow(`
+		SELECT d.user_id, d.competition_id, d.entry_id, e.name, e.status, e.seed, e.number, e.position, c.name, c.status
+		FROM draws d
+		JOIN entries e ON d.entry_id = e.id
+		JOIN competitions c ON d.competition_id = c.id
+		WHERE d.id = $1
+	`, drawID).Scan(&userID, &compID, &entryID, &entryName, &entryStatus, &seed, &number, &position, &compName, &compStatus)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Draw not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	player := authlib.AnonymousAlias(userID)
+	if profiles, err := authlib.LoadProfiles(identityDB, []string{userID}); err == nil {
+		if p, ok := profiles[userID]; ok {
+			player = p.PublicName()
+		}
+	}
+
+	result := map[string]interface{}{
+		"id":             drawID,
+		"competition_id": compID,
+		"comp_name":      compName,
+		"comp_status":    compStatus,
+		"player":         player,
+		"entry_name":     entryName,
+		"entry_status":   entryStatus,
+	}
+	if seed.Valid {
+		result["seed"] = int(seed.Int64)
+	}
+	if number.Valid {
+		result["number"] = int(number.Int64)
+	}
+	if position.Valid {
+		result["position"] = int(position.Int64)
+	}
+	if stages, err := entryStages(entryID); err != nil {
+		log.Printf("Error loading stages for draw %d: %v", drawID, err)
+	} else if len(stages) > 0 {
+		result["stages"] = stages
+	}
+	respondJSON(w, http.StatusOK, result)
+}
@@ -4,8 +4,9 @@ go 1.25
 
 require (
 	github.com/achgithub/activity-hub-common v0.1.1
-	github.com/gorilla/handlers v1.5.2
+	github.com/go-redis/redis/v8 v8.11.5
 	github.com/gorilla/mux v1.8.1
+	github.com/skip2/go-qrcode v0.0.0-20200617195104-da1b6568686e
 )
 
 replace github.com/achgithub/activity-hub-common => ../../../lib/activity-hub-common
@@ -3,11 +3,14 @@ package main
 import (
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
 	"strconv"
+	"time"
 
 	authlib "github.com/achgithub/activity-hub-common/auth"
+	apphttp "github.com/achgithub/activity-hub-common/http"
 	"github.com/gorilla/mux"
 )
 
@@ -19,7 +22,13 @@ func respondJSON(w http.ResponseWriter, status int, data interface{}) {
 
 // handleConfig returns app configuration.
 func handleConfig(w http.ResponseWriter, r *http.Request) {
-	respondJSON(w, http.StatusOK, map[string]string{"appId": "sweepstakes"})
+	apphttp.WriteConfig(w, apphttp.AppConfig{
+		AppID:        "sweepstakes",
+		Name:         "Sweepstakes",
+		Icon:         "🎁",
+		Description:  "Blind box sweepstakes — pick your entry and see who gets what",
+		Capabilities: apphttp.Capabilities{SSE: true},
+	})
 }
 
 // handleGetCompetitions returns open, locked, and completed competitions for players.
@@ -27,7 +36,7 @@ func handleGetCompetitions(w http.ResponseWriter, r *http.Request) {
 	rows, err := appDB.Query(`
 		SELECT id, name, type, status, COALESCE(description, ''), created_at
 		FROM competitions
-		WHERE status IN ('open', 'locked', 'completed')
+		WHERE status IN ('open', 'locked', 'completed') AND deleted_at IS NULL
 		ORDER BY created_at DESC
 	`)
 	if err != nil {
@@ -95,37 +104,116 @@ func handleGetEntries(w http.ResponseWriter, r *http.Request) {
 // handleGetAvailableCount returns the count of available entries for a competition.
 func handleGetAvailableCount(w http.ResponseWriter, r *http.Request) {
 	compID := mux.Vars(r)["id"]
-	var count int
-	appDB.QueryRow(`SELECT COUNT(*) FROM entries WHERE competition_id = $1 AND status = 'available'`, compID).Scan(&count)
-	respondJSON(w, http.StatusOK, map[string]int{"count": count})
+	respondJSON(w, http.StatusOK, map[string]int{"count": availableCount(compID)})
 }
 
 // handleGetCompetitionDraws returns all draws for a competition with entry details.
 func handleGetCompetitionDraws(w http.ResponseWriter, r *http.Request) {
 	compID := mux.Vars(r)["id"]
 
-	rows, err := appDB.Query(`
-		SELECT d.id, d.user_id, d.competition_id, d.entry_id, d.drawn_at,
-		       e.name, e.status, e.seed, e.number, e.position
-		FROM draws d
-		JOIN entries e ON d.entry_id = e.id
+	draws, err := competitionDraws(compID)
+	if err != nil {
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	respondJSON(w, http.StatusOK, draws)
+}
+
+// handleCompetitionStream pushes availability, draw, queue and position updates for a
+// competition so player phones don't have to poll. The first event is a
+// snapshot of the current available count and draws.
+func handleCompetitionStream(w http.ResponseWriter, r *http.Request) {
+	compID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid competition ID", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	// Subscribe before taking the snapshot so nothing published in between is lost
+	pubsub, msgChan := subscribeToCompetition(compID)
+	defer pubsub.Close()
+
+	draws, err := competitionDraws(strconv.Itoa(compID))
+	if err != nil {
+		log.Printf("Error loading draws for stream: %v", err)
+		draws = []map[string]interface{}{}
+	}
+	queue, err := getQueueState(r.Context(), compID)
+	if err != nil {
+		log.Printf("Error loading queue for stream: %v", err)
+	}
+	snapshot, _ := json.Marshal(map[string]interface{}{
+		"available_count": availableCount(strconv.Itoa(compID)),
+		"draws":           draws,
+		"queue":           queue,
+	})
+	fmt.Fprintf(w, "event: snapshot\ndata: %s\n\n", snapshot)
+	flusher.Flush()
+
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+
+	ctx := r.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg := <-msgChan:
+			fmt.Fprintf(w, "data: %s\n\n", msg.Payload)
+			flusher.Flush()
+		case <-ticker.C:
+			fmt.Fprintf(w, "event: ping\ndata: {}\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+// drawSelect lists draws with their entry details; callers add WHERE/ORDER BY.
+const drawSelect = `
+	SELECT d.id, d.user_id, d.competition_id, d.entry_id, d.drawn_at, d.assigned_by IS NOT NULL,
+	       e.name, e.status, e.seed, e.number, e.position
+	FROM draws d
+	JOIN entries e ON d.entry_id = e.id`
+
+func availableCount(compID string) int {
+	var count int
+	appDB.QueryRow(`SELECT COUNT(*) FROM entries WHERE competition_id = $1 AND status = 'available'`, compID).Scan(&count)
+	return count
+}
+
+func competitionDraws(compID string) ([]map[string]interface{}, error) {
+	rows, err := appDB.Query(drawSelect+`
 		WHERE d.competition_id = $1
 		ORDER BY COALESCE(e.position, 999), d.drawn_at
 	`, compID)
 	if err != nil {
-		http.Error(w, "Database error", http.StatusInternalServerError)
-		return
+		return nil, err
 	}
 	defer rows.Close()
+	return scanDraws(rows), nil
+}
 
+func scanDraws(rows *sql.Rows) []map[string]interface{} {
 	draws := []map[string]interface{}{}
 	for rows.Next() {
 		var id, competitionID, entryID int
 		var userID, entryName, entryStatus string
 		var drawnAt string
+		var assigned bool
 		var seed, number, position sql.NullInt64
 
-		if err := rows.Scan(&id, &userID, &competitionID, &entryID, &drawnAt,
+		if err := rows.Scan(&id, &userID, &competitionID, &entryID, &drawnAt, &assigned,
 			&entryName, &entryStatus, &seed, &number, &position); err != nil {
 			continue
 		}
@@ -136,6 +224,7 @@ func handleGetCompetitionDraws(w http.ResponseWriter, r *http.Request) {
 			"competition_id": competitionID,
 			"entry_id":       entryID,
 			"drawn_at":       drawnAt,
+			"assigned":       assigned, // made by an admin rather than picked
 			"entry_name":     entryName,
 			"entry_status":   entryStatus,
 		}
@@ -150,7 +239,33 @@ func handleGetCompetitionDraws(w http.ResponseWriter, r *http.Request) {
 		}
 		draws = append(draws, d)
 	}
-	respondJSON(w, http.StatusOK, draws)
+	return draws
+}
+
+// publishDraw tells competition streams about a new draw and the reduced availability.
+func publishDraw(compID string, entryID int) {
+	id, err := strconv.Atoi(compID)
+	if err != nil {
+		return
+	}
+	rows, err := appDB.Query(drawSelect+`
+		WHERE d.competition_id = $1 AND d.entry_id = $2
+	`, id, entryID)
+	if err != nil {
+		log.Printf("Error loading draw for publish: %v", err)
+		return
+	}
+	draws := scanDraws(rows)
+	rows.Close()
+
+	if len(draws) > 0 {
+		if err := publishCompetitionEvent(id, "draw", draws[0]); err != nil {
+			log.Printf("Error publishing draw: %v", err)
+		}
+	}
+	if err := publishCompetitionEvent(id, "availability", map[string]int{"count": availableCount(compID)}); err != nil {
+		log.Printf("Error publishing availability: %v", err)
+	}
 }
 
 // handleGetBlindBoxes returns anonymous boxes for the blind selection UI.
@@ -171,8 +286,7 @@ func handleGetBlindBoxes(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var totalAvailable int
-	appDB.QueryRow(`SELECT COUNT(*) FROM entries WHERE competition_id = $1 AND status = 'available'`, compID).Scan(&totalAvailable)
+	totalAvailable := availableCount(compID)
 
 	boxes := make([]map[string]int, totalAvailable)
 	for i := range boxes {
@@ -182,7 +296,8 @@ func handleGetBlindBoxes(w http.ResponseWriter, r *http.Request) {
 }
 
 // handleChooseBlindBox assigns the Nth available entry to the authenticated user.
-// DB UNIQUE constraints prevent duplicate draws; no Redis lock needed.
+// Only the holder of the selection turn may pick (see queue.go), so box numbers
+// don't shift underneath them; DB UNIQUE constraints still prevent duplicate draws.
 func handleChooseBlindBox(w http.ResponseWriter, r *http.Request) {
 	user, ok := authlib.GetUserFromContext(r.Context())
 	if !ok {
@@ -191,6 +306,14 @@ func handleChooseBlindBox(w http.ResponseWriter, r *http.Request) {
 	}
 
 	compID := mux.Vars(r)["id"]
+	compNum, err := strconv.Atoi(compID)
+	if err != nil {
+		http.Error(w, "Invalid competition ID", http.StatusBadRequest)
+		return
+	}
+	if !requireTurn(w, r, compNum, user.Email) {
+		return
+	}
 
 	var req struct {
 		BoxNumber int `json:"box_number"`
@@ -253,6 +376,10 @@ func handleChooseBlindBox(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Failed to complete selection", http.StatusInternalServerError)
 		return
 	}
+	publishDraw(compID, selectedEntryID)
+	if err := endTurn(r.Context(), compNum, user.Email); err != nil {
+		log.Printf("Error ending selection turn: %v", err)
+	}
 
 	var entryName string
 	var seed, number sql.NullInt64
@@ -268,7 +395,8 @@ func handleChooseBlindBox(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, result)
 }
 
-// handleRandomPick assigns a random available entry to the authenticated user.
+// handleRandomPick assigns a random available entry to the authenticated user
+// (selection turn holder only).
 func handleRandomPick(w http.ResponseWriter, r *http.Request) {
 	user, ok := authlib.GetUserFromContext(r.Context())
 	if !ok {
@@ -277,6 +405,14 @@ func handleRandomPick(w http.ResponseWriter, r *http.Request) {
 	}
 
 	compID := mux.Vars(r)["id"]
+	compNum, err := strconv.Atoi(compID)
+	if err != nil {
+		http.Error(w, "Invalid competition ID", http.StatusBadRequest)
+		return
+	}
+	if !requireTurn(w, r, compNum, user.Email) {
+		return
+	}
 
 	tx, err := appDB.Begin()
 	if err != nil {
@@ -318,6 +454,10 @@ func handleRandomPick(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Failed to complete selection", http.StatusInternalServerError)
 		return
 	}
+	publishDraw(compID, selectedEntryID)
+	if err := endTurn(r.Context(), compNum, user.Email); err != nil {
+		log.Printf("Error ending selection turn: %v", err)
+	}
 
 	var entryName string
 	var seed, number sql.NullInt64
@@ -342,7 +482,7 @@ func handleGetUserDraws(w http.ResponseWriter, r *http.Request) {
 	}
 
 	rows, err := appDB.Query(`
-		SELECT d.id, d.user_id, d.competition_id, d.entry_id, d.drawn_at,
+		SELECT d.id, d.user_id, d.competition_id, d.entry_id, d.drawn_at, d.assigned_by IS NOT NULL,
 		       e.name, e.status, e.seed, e.number, e.position,
 		       c.name, c.status
 		FROM draws d
@@ -361,9 +501,10 @@ func handleGetUserDraws(w http.ResponseWriter, r *http.Request) {
 	for rows.Next() {
 		var id, competitionID, entryID int
 		var userID, entryName, entryStatus, compName, compStatus, drawnAt string
+		var assigned bool
 		var seed, number, position sql.NullInt64
 
-		if err := rows.Scan(&id, &userID, &competitionID, &entryID, &drawnAt,
+		if err := rows.Scan(&id, &userID, &competitionID, &entryID, &drawnAt, &assigned,
 			&entryName, &entryStatus, &seed, &number, &position,
 			&compName, &compStatus); err != nil {
 			continue
@@ -375,6 +516,7 @@ func handleGetUserDraws(w http.ResponseWriter, r *http.Request) {
 			"competition_id": competitionID,
 			"entry_id":       entryID,
 			"drawn_at":       drawnAt,
+			"assigned":       assigned,
 			"entry_name":     entryName,
 			"entry_status":   entryStatus,
 			"comp_name":      compName,
@@ -6,16 +6,43 @@ import (
 	"net/http"
 
 	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/achgithub/activity-hub-common/cli"
 	"github.com/achgithub/activity-hub-common/config"
 	"github.com/achgithub/activity-hub-common/database"
-	"github.com/gorilla/handlers"
+	apphttp "github.com/achgithub/activity-hub-common/http"
+	"github.com/achgithub/activity-hub-common/maintenance"
 	"github.com/gorilla/mux"
 )
 
-var appDB *sql.DB // sweepstakes_db
+var (
+	appDB      *sql.DB // sweepstakes_db
+	identityDB *sql.DB // activity_hub (profiles for public names)
+)
 
 func main() {
-	identityDB, err := database.InitIdentityDatabase()
+	cli.New("sweepstakes",
+		cli.Serve(serve),
+		cli.Migrate(openAppDB, cli.Migrations{
+			Dir:    "../database",
+			Schema: []string{"schema.sql"},
+			Upgrades: []string{
+				"migrate_add_draw_assigned_by.sql",
+				"migrate_add_guids.sql",
+				"migrate_add_trash.sql",
+				"migrate_add_stages.sql",
+			},
+		}),
+	).Main()
+}
+
+// openAppDB connects to sweepstakes_db for commands other than serve
+func openAppDB() (*sql.DB, error) {
+	return database.InitDatabaseByName("sweepstakes_db")
+}
+
+func serve() {
+	var err error
+	identityDB, err = database.InitIdentityDatabase()
 	if err != nil {
 		log.Fatal("Failed to connect to identity database:", err)
 	}
@@ -27,6 +54,9 @@ func main() {
 	}
 	defer appDB.Close()
 
+	initRedis()
+	go runQueueSweeper()
+
 	r := mux.NewRouter()
 
 	// Public routes (no auth required)
@@ -35,13 +65,20 @@ func main() {
 	r.HandleFunc("/api/competitions/{id}/entries", handleGetEntries).Methods("GET")
 	r.HandleFunc("/api/competitions/{id}/available-count", handleGetAvailableCount).Methods("GET")
 	r.HandleFunc("/api/competitions/{id}/all-draws", handleGetCompetitionDraws).Methods("GET")
+	r.HandleFunc("/api/competitions/{id}/timeline", handleGetTimeline).Methods("GET")
+	r.HandleFunc("/api/competitions/{id}/stream", handleCompetitionStream).Methods("GET")
+	r.HandleFunc("/api/draws/{id}/result", handleGetDrawResult).Methods("GET")
 
 	// Auth-required routes
 	protected := r.PathPrefix("/api").Subrouter()
 	protected.Use(authlib.Middleware(identityDB))
 	protected.HandleFunc("/competitions/{id}/blind-boxes", handleGetBlindBoxes).Methods("GET")
+	protected.HandleFunc("/competitions/{id}/queue", handleGetQueueStatus).Methods("GET")
+	protected.HandleFunc("/competitions/{id}/queue", handleJoinQueue).Methods("POST")
+	protected.HandleFunc("/competitions/{id}/queue", handleLeaveQueue).Methods("DELETE")
 	protected.HandleFunc("/competitions/{id}/choose-blind-box", handleChooseBlindBox).Methods("POST")
 	protected.HandleFunc("/competitions/{id}/random-pick", handleRandomPick).Methods("POST")
+	protected.HandleFunc("/competitions/{id}/cards.pdf", handleGetCompetitionCards).Methods("GET")
 	protected.HandleFunc("/draws", handleGetUserDraws).Methods("GET")
 
 	// Serve React frontend
@@ -50,13 +87,10 @@ func main() {
 		http.ServeFile(w, r, "./static/index.html")
 	})
 
-	corsHandler := handlers.CORS(
-		handlers.AllowedOrigins([]string{"*"}),
-		handlers.AllowedMethods([]string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}),
-		handlers.AllowedHeaders([]string{"Content-Type", "Authorization"}),
-	)
+	cors := apphttp.NewCORSPolicy(identityDB)
+	appMaintenance := maintenance.New(identityDB)
 
 	port := config.GetEnv("PORT", "4031")
 	log.Printf("🎁 Sweepstakes starting on :%s", port)
-	log.Fatal(http.ListenAndServe(":"+port, corsHandler(r)))
+	log.Fatal(http.ListenAndServe(":"+port, cors.Middleware(appMaintenance.Middleware("sweepstakes")(apphttp.Versioned(r)))))
 }
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Minimal PDF writer for the printable cards: A4 pages, the two standard
+// Helvetica fonts (no embedding) and filled/stroked rectangles. Enough for
+// text and QR codes without pulling in a PDF library.
+
+const (
+	pdfPageWidth  = 595.0 // A4 in points
+	pdfPageHeight = 842.0
+)
+
+const (
+	fontRegular = "F1" // Helvetica
+	fontBold    = "F2" // Helvetica-Bold
+)
+
+type pdfDoc struct {
+	pages []*bytes.Buffer
+}
+
+// addPage starts a new page and returns its content stream.
+func (d *pdfDoc) addPage() *bytes.Buffer {
+	page := &bytes.Buffer{}
+	d.pages = append(d.pages, page)
+	return page
+}
+
+// pdfText draws a single line of text with its baseline at (x, y).
+func pdfText(page *bytes.Buffer, font string, size, x, y float64, s string) {
+	fmt.Fprintf(page, "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, pdfEscape(s))
+}
+
+// pdfStrokeRect outlines a rectangle; (x, y) is the bottom-left corner.
+func pdfStrokeRect(page *bytes.Buffer, x, y, w, h float64) {
+	fmt.Fprintf(page, "%.2f %.2f %.2f %.2f re S\n", x, y, w, h)
+}
+
+// pdfQR draws a QR bitmap (true = dark module) as a size×size square with
+// its bottom-left corner at (x, y). Runs of dark modules are merged per row.
+func pdfQR(page *bytes.Buffer, bitmap [][]bool, x, y, size float64) {
+	if len(bitmap) == 0 {
+		return
+	}
+	cell := size / float64(len(bitmap))
+	for row, modules := range bitmap {
+		top := y + size - float64(row+1)*cell
+		for col := 0; col < len(modules); col++ {
+			if !modules[col] {
+				continue
+			}
+			start := col
+			for col+1 < len(modules) && modules[col+1] {
+				col++
+			}
+			fmt.Fprintf(page, "%.2f %.2f %.2f %.2f re\n", x+float64(start)*cell, top, float64(col-start+1)*cell, cell)
+		}
+	}
+	page.WriteString("f\n")
+}
+
+// pdfFit shortens s so it roughly fits width points at the given font size.
+// Helvetica averages about half an em per character.
+func pdfFit(s string, size, width float64) string {
+	max := int(width / (size * 0.5))
+	runes := []rune(s)
+	if len(runes) <= max || max < 2 {
+		return s
+	}
+	return string(runes[:max-1]) + "…"
+}
+
+// pdfEscape converts to the fonts' WinAnsi encoding and escapes string delimiters.
+// Characters outside Latin-1 (emoji flair etc.) are dropped.
+func pdfEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '…':
+			b.WriteByte(0x85) // WinAnsi ellipsis
+		case r == '–' || r == '—':
+			b.WriteByte('-')
+		case r >= 0x20 && r < 0x7f:
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			b.WriteByte(byte(r))
+		}
+	}
+	return b.String()
+}
+
+// bytes serialises the document.
+func (d *pdfDoc) bytes() []byte {
+	var out bytes.Buffer
+	offsets := []int{}
+	obj := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// 1: catalog, 2: page tree, 3-4: fonts, then a page + content pair per page
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+i*2)
+	}
+	obj("<< /Type /Catalog /Pages 2 0 R >>")
+	obj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	for i, page := range d.pages {
+		obj(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] "+
+			"/Resources << /Font << /%s 3 0 R /%s 4 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, fontRegular, fontBold, 6+i*2))
+		obj(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.Len(), page.String()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return out.Bytes()
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/go-redis/redis/v8"
+	"github.com/gorilla/mux"
+)
+
+// Selection turns: on a busy draw, players join a FIFO queue per competition
+// and pick one at a time. A turn lasts turnDuration; if the holder doesn't pick
+// (phone locked, walked off) it expires and the next player is promoted, so
+// nobody has to release anything.
+const turnDuration = 45 * time.Second
+
+// activeQueuesKey lists competitions with a turn held or players waiting,
+// so the sweeper knows where to look for expired turns.
+const activeQueuesKey = "sweepstakes:queues:active"
+
+func queueKey(compID int) string {
+	return fmt.Sprintf("sweepstakes:competition:%d:queue", compID)
+}
+
+func turnKey(compID int) string {
+	return fmt.Sprintf("sweepstakes:competition:%d:turn", compID)
+}
+
+// joinQueueScript appends a user unless they already hold the turn or are waiting.
+var joinQueueScript = redis.NewScript(`
+if redis.call('GET', KEYS[2]) == ARGV[1] then return 0 end
+for _, u in ipairs(redis.call('LRANGE', KEYS[1], 0, -1)) do
+	if u == ARGV[1] then return 0 end
+end
+redis.call('RPUSH', KEYS[1], ARGV[1])
+redis.call('SADD', KEYS[3], ARGV[2])
+return 1
+`)
+
+// advanceQueueScript grants the turn to the next waiting user if nobody holds it.
+// Returns {holder, changed}; holder is "" when the queue has emptied.
+var advanceQueueScript = redis.NewScript(`
+local holder = redis.call('GET', KEYS[2])
+if holder then return {holder, 0} end
+local nxt = redis.call('LPOP', KEYS[1])
+if not nxt then
+	if redis.call('SREM', KEYS[3], ARGV[2]) == 1 then return {'', 1} end
+	return {'', 0}
+end
+redis.call('SET', KEYS[2], nxt, 'PX', ARGV[1])
+return {nxt, 1}
+`)
+
+// leaveQueueScript ends the user's turn (if they hold it) and removes them from the queue.
+var leaveQueueScript = redis.NewScript(`
+if redis.call('GET', KEYS[2]) == ARGV[1] then redis.call('DEL', KEYS[2]) end
+redis.call('LREM', KEYS[1], 0, ARGV[1])
+return 1
+`)
+
+// QueueState is the public view of a competition's selection queue.
+type QueueState struct {
+	Turn          string     `json:"turn"` // user holding the current turn ("" = none)
+	TurnExpiresAt *time.Time `json:"turn_expires_at"`
+	Waiting       []string   `json:"waiting"` // in order; position = index + 1
+}
+
+func getQueueState(ctx context.Context, compID int) (QueueState, error) {
+	state := QueueState{Waiting: []string{}}
+
+	pipe := redisClient.Pipeline()
+	turnCmd := pipe.Get(ctx, turnKey(compID))
+	ttlCmd := pipe.PTTL(ctx, turnKey(compID))
+	waitingCmd := pipe.LRange(ctx, queueKey(compID), 0, -1)
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return state, err
+	}
+
+	state.Turn = turnCmd.Val()
+	if ttl := ttlCmd.Val(); state.Turn != "" && ttl > 0 {
+		expires := time.Now().Add(ttl).UTC()
+		state.TurnExpiresAt = &expires
+	}
+	state.Waiting = append(state.Waiting, waitingCmd.Val()...)
+	return state, nil
+}
+
+// advanceQueue promotes the next user when the turn is free, publishing the
+// new queue state to competition streams if anything changed.
+func advanceQueue(ctx context.Context, compID int) error {
+	res, err := advanceQueueScript.Run(ctx, redisClient,
+		[]string{queueKey(compID), turnKey(compID), activeQueuesKey},
+		turnDuration.Milliseconds(), compID).Slice()
+	if err != nil {
+		return err
+	}
+	if changed, _ := res[1].(int64); changed == 1 {
+		publishQueueState(ctx, compID)
+	}
+	return nil
+}
+
+func publishQueueState(ctx context.Context, compID int) {
+	state, err := getQueueState(ctx, compID)
+	if err != nil {
+		log.Printf("Error reading queue state for competition %d: %v", compID, err)
+		return
+	}
+	if err := publishCompetitionEvent(compID, "queue", state); err != nil {
+		log.Printf("Error publishing queue state: %v", err)
+	}
+}
+
+// endTurn releases the user's turn (or queue place) and moves the queue on.
+func endTurn(ctx context.Context, compID int, userID string) error {
+	if err := leaveQueueScript.Run(ctx, redisClient,
+		[]string{queueKey(compID), turnKey(compID)}, userID).Err(); err != nil {
+		return err
+	}
+	publishQueueState(ctx, compID)
+	return advanceQueue(ctx, compID)
+}
+
+// requireTurn rejects a selection unless the user currently holds the competition's turn.
+func requireTurn(w http.ResponseWriter, r *http.Request, compID int, userID string) bool {
+	holder, err := redisClient.Get(r.Context(), turnKey(compID)).Result()
+	if err != nil && err != redis.Nil {
+		log.Printf("Error checking selection turn: %v", err)
+		http.Error(w, "Selection queue unavailable — try again shortly", http.StatusServiceUnavailable)
+		return false
+	}
+	if holder != userID {
+		http.Error(w, "It's not your turn to pick — join the queue and wait for your turn", http.StatusConflict)
+		return false
+	}
+	return true
+}
+
+// runQueueSweeper promotes the next player when a turn expires without a pick.
+func runQueueSweeper() {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for range ticker.C {
+		ctx := context.Background()
+		ids, err := redisClient.SMembers(ctx, activeQueuesKey).Result()
+		if err != nil {
+			continue
+		}
+		for _, id := range ids {
+			compID, err := strconv.Atoi(id)
+			if err != nil {
+				redisClient.SRem(ctx, activeQueuesKey, id)
+				continue
+			}
+			if err := advanceQueue(ctx, compID); err != nil {
+				log.Printf("Error advancing queue for competition %d: %v", compID, err)
+			}
+		}
+	}
+}
+
+// queueStatus is a user's own view of the queue.
+func queueStatus(state QueueState, userID string) map[string]interface{} {
+	position := 0
+	for i, u := range state.Waiting {
+		if u == userID {
+			position = i + 1
+			break
+		}
+	}
+	return map[string]interface{}{
+		"your_turn":       state.Turn == userID,
+		"position":        position, // 0 = not waiting
+		"waiting":         len(state.Waiting),
+		"turn_expires_at": state.TurnExpiresAt,
+	}
+}
+
+// handleJoinQueue puts the user in line for a selection turn. With nobody
+// ahead, the turn is granted straight away.
+func handleJoinQueue(w http.ResponseWriter, r *http.Request) {
+	user, ok := authlib.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	compID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid competition ID", http.StatusBadRequest)
+		return
+	}
+
+	var status string
+	if err := appDB.QueryRow(`SELECT status FROM competitions WHERE id = $1`, compID).Scan(&status); err != nil {
+		http.Error(w, "Competition not found", http.StatusNotFound)
+		return
+	}
+	if status != "open" {
+		http.Error(w, "Competition is not open for picks", http.StatusBadRequest)
+		return
+	}
+
+	var existingCount int
+	appDB.QueryRow(`SELECT COUNT(*) FROM draws WHERE user_id = $1 AND competition_id = $2`, user.Email, compID).Scan(&existingCount)
+	if existingCount > 0 {
+		http.Error(w, "You already have an entry in this competition", http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	added, err := joinQueueScript.Run(ctx, redisClient,
+		[]string{queueKey(compID), turnKey(compID), activeQueuesKey}, user.Email, compID).Int()
+	if err != nil {
+		log.Printf("Error joining selection queue: %v", err)
+		http.Error(w, "Selection queue unavailable — try again shortly", http.StatusServiceUnavailable)
+		return
+	}
+	if added == 1 {
+		publishQueueState(ctx, compID)
+	}
+	if err := advanceQueue(ctx, compID); err != nil {
+		log.Printf("Error advancing selection queue: %v", err)
+	}
+
+	state, err := getQueueState(ctx, compID)
+	if err != nil {
+		http.Error(w, "Selection queue unavailable — try again shortly", http.StatusServiceUnavailable)
+		return
+	}
+	respondJSON(w, http.StatusOK, queueStatus(state, user.Email))
+}
+
+// handleGetQueueStatus returns the user's place in the selection queue.
+func handleGetQueueStatus(w http.ResponseWriter, r *http.Request) {
+	user, ok := authlib.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	compID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid competition ID", http.StatusBadRequest)
+		return
+	}
+
+	state, err := getQueueState(r.Context(), compID)
+	if err != nil {
+		http.Error(w, "Selection queue unavailable — try again shortly", http.StatusServiceUnavailable)
+		return
+	}
+	respondJSON(w, http.StatusOK, queueStatus(state, user.Email))
+}
+
+// handleLeaveQueue gives up the user's turn or place in line.
+func handleLeaveQueue(w http.ResponseWriter, r *http.Request) {
+	user, ok := authlib.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	compID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid competition ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := endTurn(r.Context(), compID, user.Email); err != nil {
+		log.Printf("Error leaving selection queue: %v", err)
+		http.Error(w, "Selection queue unavailable — try again shortly", http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -1 +1,47 @@
 package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/go-redis/redis/v8"
+)
+
+var redisClient *redis.Client
+
+func initRedis() {
+	redisClient = redis.NewClient(&redis.Options{
+		Addr: "127.0.0.1:6379",
+	})
+	ctx := context.Background()
+	if err := redisClient.Ping(ctx).Err(); err != nil {
+		log.Printf("Warning: Redis not available: %v", err)
+	}
+}
+
+// competitionChannel carries availability, draw and position updates for a
+// competition. game-admin publishes position and status changes here too.
+func competitionChannel(compID int) string {
+	return fmt.Sprintf("sweepstakes:competition:%d:events", compID)
+}
+
+func publishCompetitionEvent(compID int, eventType string, payload interface{}) error {
+	ctx := context.Background()
+	data, err := json.Marshal(map[string]interface{}{
+		"type":    eventType,
+		"payload": payload,
+	})
+	if err != nil {
+		return err
+	}
+	return redisClient.Publish(ctx, competitionChannel(compID), string(data)).Err()
+}
+
+func subscribeToCompetition(compID int) (*redis.PubSub, <-chan *redis.Message) {
+	ctx := context.Background()
+	pubsub := redisClient.Subscribe(ctx, competitionChannel(compID))
+	ch := pubsub.Channel()
+	return pubsub, ch
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/gorilla/mux"
+)
+
+// Multi-stage competitions (group stage, then knockout) are run from Game
+// Admin: each stage lists the entries in it, and the entries that go through
+// carry their position into the next. A competition without stages is a
+// single round.
+
+type TimelineStage struct {
+	ID          int    `json:"id"`
+	StageNumber int    `json:"stage_number"`
+	Name        string `json:"name"`
+	Status      string `json:"status"` // active, completed
+}
+
+// TimelineStep is an entry's part in one stage.
+type TimelineStep struct {
+	StageID         int    `json:"stage_id"`
+	Group           string `json:"group,omitempty"`
+	CarriedPosition *int   `json:"carried_position"`
+	Position        *int   `json:"position"`
+	Outcome         string `json:"outcome"` // playing, through, out
+}
+
+// Journey is a drawn entry's way through the stages, furthest stage last.
+type Journey struct {
+	Player    string         `json:"player"` // public name, never the email
+	EntryID   int            `json:"entry_id"`
+	EntryName string         `json:"entry_name"`
+	Seed      *int           `json:"seed"`
+	Number    *int           `json:"number"`
+	Position  *int           `json:"position"` // final finishing position
+	Steps     []TimelineStep `json:"steps"`
+}
+
+// handleGetTimeline returns a competition's stages and each player's entry
+// journey through them, for display screens. Public, so players appear by
+// their public names only.
+func handleGetTimeline(w http.ResponseWriter, r *http.Request) {
+	compID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid competition ID", http.StatusBadRequest)
+		return
+	}
+
+	var comp Competition
+	err = appDB.QueryRow(`
+		SELECT id, name, type, status, COALESCE(description, ''), created_at
+		FROM competitions
+		WHERE id = $1 AND status IN ('open', 'locked', 'completed') AND deleted_at IS NULL
+	`, compID).Scan(&comp.ID, &comp.Name, &comp.Type, &comp.Status, &comp.Description, &comp.CreatedAt)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Competition not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	stages := []TimelineStage{}
+	rows, err := appDB.Query(`
+		SELECT id, stage_number, name, status FROM stages
+		WHERE competition_id = $1 ORDER BY stage_number
+	`, compID)
+	if err != nil {
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	for rows.Next() {
+		var s TimelineStage
+		if err := rows.Scan(&s.ID, &s.StageNumber, &s.Name, &s.Status); err != nil {
+			continue
+		}
+		stages = append(stages, s)
+	}
+	rows.Close()
+
+	// Finishing position first, then the entries that got furthest
+	rows, err = appDB.Query(`
+		SELECT d.user_id, e.id, e.name, e.seed, e.number, e.position
+		FROM draws d
+		JOIN entries e ON e.id = d.entry_id
+		WHERE d.competition_id = $1
+		ORDER BY COALESCE(e.position, 999),
+		         (SELECT MAX(s.stage_number) FROM stage_entries se JOIN stages s ON s.id = se.stage_id
+		          WHERE se.entry_id = e.id) DESC NULLS LAST,
+		         COALESCE(e.seed, 999), COALESCE(e.number, 999), e.name
+	`, compID)
+	if err != nil {
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	journeys := []Journey{}
+	index := map[int]int{}
+	emails := []string{}
+	for rows.Next() {
+		var j Journey
+		var seed, number, position sql.NullInt64
+		if err := rows.Scan(&j.Player, &j.EntryID, &j.EntryName, &seed, &number, &position); err != nil {
+			continue
+		}
+		j.Seed, j.Number, j.Position = nullInt(seed), nullInt(number), nullInt(position)
+		j.Steps = []TimelineStep{}
+		emails = append(emails, j.Player)
+		index[j.EntryID] = len(journeys)
+		journeys = append(journeys, j)
+	}
+	rows.Close()
+
+	rows, err = appDB.Query(`
+		SELECT se.entry_id, se.stage_id, COALESCE(se.group_name, ''), se.carried_position, se.position, se.outcome
+		FROM stage_entries se
+		JOIN stages s ON s.id = se.stage_id
+		WHERE s.competition_id = $1
+		ORDER BY s.stage_number
+	`, compID)
+	if err != nil {
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var entryID int
+		var step TimelineStep
+		var carried, position sql.NullInt64
+		if err := rows.Scan(&entryID, &step.StageID, &step.Group, &carried, &position, &step.Outcome); err != nil {
+			continue
+		}
+		step.CarriedPosition, step.Position = nullInt(carried), nullInt(position)
+		if i, ok := index[entryID]; ok {
+			journeys[i].Steps = append(journeys[i].Steps, step)
+		}
+	}
+
+	names := authlib.PublicNames(identityDB, emails)
+	for i := range journeys {
+		journeys[i].Player = names[journeys[i].Player]
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"competition": comp,
+		"stages":      stages,
+		"journeys":    journeys,
+	})
+}
+
+// EntryStage is an entry's part in one stage, named, for its result page.
+type EntryStage struct {
+	Name string `json:"name"`
+	TimelineStep
+}
+
+// entryStages returns the stages an entry has played, in order.
+func entryStages(entryID int) ([]EntryStage, error) {
+	rows, err := appDB.Query(`
+		SELECT s.name, se.stage_id, COALESCE(se.group_name, ''), se.carried_position, se.position, se.outcome
+		FROM stage_entries se
+		JOIN stages s ON s.id = se.stage_id
+		WHERE se.entry_id = $1
+		ORDER BY s.stage_number
+	`, entryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	stages := []EntryStage{}
+	for rows.Next() {
+		var s EntryStage
+		var carried, position sql.NullInt64
+		if err := rows.Scan(&s.Name, &s.StageID, &s.Group, &carried, &position, &s.Outcome); err != nil {
+			return nil, err
+		}
+		s.CarriedPosition, s.Position = nullInt(carried), nullInt(position)
+		stages = append(stages, s)
+	}
+	return stages, rows.Err()
+}
+
+func nullInt(v sql.NullInt64) *int {
+	if !v.Valid {
+		return nil
+	}
+	n := int(v.Int64)
+	return &n
+}
@@ -0,0 +1,5 @@
+-- Migration: Draw administration
+-- Date: 2026-10-17
+-- Purpose: Record draws made by an admin (manual assign / redraw) rather than the player's own pick
+
+ALTER TABLE draws ADD COLUMN IF NOT EXISTS assigned_by TEXT;
@@ -0,0 +1,7 @@
+-- Migration: Stable GUIDs for admin-managed resources
+-- Date: 2026-10-17
+-- Purpose: Serial IDs differ between environments; GUIDs let entry CSVs
+-- exported from one environment update the same entries in another
+
+ALTER TABLE competitions ADD COLUMN IF NOT EXISTS guid UUID NOT NULL DEFAULT gen_random_uuid() UNIQUE;
+ALTER TABLE entries ADD COLUMN IF NOT EXISTS guid UUID NOT NULL DEFAULT gen_random_uuid() UNIQUE;
@@ -0,0 +1,28 @@
+-- Migration: Multi-stage competitions
+-- Date: 2026-10-17
+-- Purpose: Competitions such as the World Cup run a group stage then a
+-- knockout; entries that go through carry their position into the next stage
+
+CREATE TABLE IF NOT EXISTS stages (
+    id SERIAL PRIMARY KEY,
+    competition_id INTEGER NOT NULL REFERENCES competitions(id) ON DELETE CASCADE,
+    stage_number INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'active' CHECK(status IN ('active', 'completed')),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP,
+    UNIQUE(competition_id, stage_number)
+);
+
+CREATE TABLE IF NOT EXISTS stage_entries (
+    stage_id INTEGER NOT NULL REFERENCES stages(id) ON DELETE CASCADE,
+    entry_id INTEGER NOT NULL REFERENCES entries(id) ON DELETE CASCADE,
+    group_name TEXT,
+    carried_position INTEGER,
+    position INTEGER,
+    outcome TEXT NOT NULL DEFAULT 'playing' CHECK(outcome IN ('playing', 'through', 'out')),
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY(stage_id, entry_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stage_entries_entry ON stage_entries(entry_id);
@@ -0,0 +1,7 @@
+-- Migration: Trash for deleted competitions
+-- Date: 2026-10-17
+-- Purpose: Deleting a competition in Game Admin now sets deleted_at so it can
+-- be restored; it is purged after TRASH_RETENTION_DAYS (default 30)
+
+ALTER TABLE competitions ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE competitions ADD COLUMN IF NOT EXISTS deleted_by TEXT;
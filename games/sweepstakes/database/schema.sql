@@ -1,19 +1,25 @@
 -- Sweepstakes Database Schema
+DROP TABLE IF EXISTS stage_entries CASCADE;
+DROP TABLE IF EXISTS stages CASCADE;
 DROP TABLE IF EXISTS draws CASCADE;
 DROP TABLE IF EXISTS entries CASCADE;
 DROP TABLE IF EXISTS competitions CASCADE;
 
 CREATE TABLE competitions (
     id SERIAL PRIMARY KEY,
+    guid UUID NOT NULL DEFAULT gen_random_uuid() UNIQUE, -- stable across environments
     name TEXT NOT NULL,
     type TEXT NOT NULL CHECK(type IN ('knockout', 'race')),
     status TEXT NOT NULL DEFAULT 'draft' CHECK(status IN ('draft', 'open', 'locked', 'completed', 'archived')),
     description TEXT,
-    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP, -- in Game Admin's trash until restored or purged
+    deleted_by TEXT
 );
 
 CREATE TABLE entries (
     id SERIAL PRIMARY KEY,
+    guid UUID NOT NULL DEFAULT gen_random_uuid() UNIQUE,
     competition_id INTEGER NOT NULL REFERENCES competitions(id) ON DELETE CASCADE,
     name TEXT NOT NULL,
     seed INTEGER,
@@ -33,10 +39,38 @@ CREATE TABLE draws (
     competition_id INTEGER NOT NULL REFERENCES competitions(id) ON DELETE CASCADE,
     entry_id INTEGER NOT NULL REFERENCES entries(id) ON DELETE CASCADE,
     drawn_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    assigned_by TEXT, -- admin email for manual assignments and redraws; NULL = player's own pick
     UNIQUE(competition_id, entry_id),
     UNIQUE(user_id, competition_id)
 );
 
+-- Stages of a multi-round competition (e.g. group stage, then knockout).
+-- Competitions without stages are a single round, as before.
+CREATE TABLE stages (
+    id SERIAL PRIMARY KEY,
+    competition_id INTEGER NOT NULL REFERENCES competitions(id) ON DELETE CASCADE,
+    stage_number INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'active' CHECK(status IN ('active', 'completed')),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP,
+    UNIQUE(competition_id, stage_number)
+);
+
+-- Each entry's part in a stage. The first stage takes every entry; later
+-- stages take the entries that went through, carrying their position.
+CREATE TABLE stage_entries (
+    stage_id INTEGER NOT NULL REFERENCES stages(id) ON DELETE CASCADE,
+    entry_id INTEGER NOT NULL REFERENCES entries(id) ON DELETE CASCADE,
+    group_name TEXT,           -- e.g. 'A' in a group stage
+    carried_position INTEGER,  -- position in the previous stage
+    position INTEGER,          -- position in this stage (or group)
+    outcome TEXT NOT NULL DEFAULT 'playing' CHECK(outcome IN ('playing', 'through', 'out')),
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY(stage_id, entry_id)
+);
+
 CREATE INDEX idx_draws_user_comp ON draws(user_id, competition_id);
 CREATE INDEX idx_entries_comp ON entries(competition_id);
 CREATE INDEX idx_competitions_status ON competitions(status);
+CREATE INDEX idx_stage_entries_entry ON stage_entries(entry_id);
@@ -17,6 +17,7 @@ interface Draw {
   competition_id: number;
   entry_id: number;
   drawn_at: string;
+  assigned?: boolean;  // made by an admin (manual assign / redraw)
   entry_name: string;
   entry_status: string;
   comp_name: string;
@@ -26,9 +27,35 @@ interface Draw {
   position?: number;
 }
 
+// Public result behind a printed card's QR code
+interface DrawResult {
+  id: number;
+  competition_id: number;
+  comp_name: string;
+  comp_status: string;
+  player: string;
+  entry_name: string;
+  entry_status: string;
+  seed?: number;
+  number?: number;
+  position?: number;
+  stages?: EntryStage[]; // Multi-stage competitions: the entry's way through them
+}
+
+interface EntryStage {
+  name: string;
+  stage_id: number;
+  group?: string;
+  carried_position: number | null;
+  position: number | null;
+  outcome: 'playing' | 'through' | 'out';
+}
+
 interface CompDraw {
   id: number;
   user_id: string;
+  entry_id: number;
+  assigned?: boolean;
   entry_name: string;
   entry_status: string;
   seed?: number;
@@ -44,8 +71,9 @@ function useUrlParams() {
     return {
       userId: params.get('userId') || '',
       userName: params.get('userName') || params.get('userId') || 'Player',
-      token: params.get('token') || '',
+      token: params.get('token') || sessionStorage.getItem('token') || '',
       impersonatedBy: params.get('impersonatedBy') || '',
+      drawId: params.get('draw') || '',
     };
   }, []);
 }
@@ -72,6 +100,64 @@ function useApi(token: string) {
   );
 }
 
+// Live competition updates (SSE) so phones don't poll: a snapshot on connect,
+// then availability, draw, position and status events.
+interface QueueState {
+  turn: string;
+  turn_expires_at: string | null;
+  waiting: string[];
+}
+
+interface CompetitionSnapshot {
+  available_count: number;
+  draws: CompDraw[];
+  queue: QueueState;
+}
+
+type CompetitionEvent =
+  | { type: 'availability'; payload: { count: number } }
+  | { type: 'draw'; payload: CompDraw }
+  | { type: 'void'; payload: { id: number; entry_id: number } }
+  | { type: 'position'; payload: { entry_id: number; position: number | null; entry_status?: string } }
+  | { type: 'status'; payload: { status: Competition['status'] } }
+  | { type: 'queue'; payload: QueueState }
+  | { type: 'stage'; payload: { stage_id: number; status: string } }
+  | { type: 'stage_entry'; payload: { stage_id: number; entry_id: number; outcome: EntryStage['outcome'] } };
+
+function useCompetitionStream(
+  compId: number | null,
+  onSnapshot: (snapshot: CompetitionSnapshot) => void,
+  onEvent: (event: CompetitionEvent) => void,
+) {
+  useEffect(() => {
+    if (compId == null) return;
+    // EventSource reconnects on its own; each reconnect starts with a fresh snapshot
+    const es = new EventSource(`/api/competitions/${compId}/stream`);
+    es.addEventListener('snapshot', (e) => {
+      try {
+        onSnapshot(JSON.parse((e as MessageEvent).data));
+      } catch (err) {
+        console.error('Bad snapshot event', err);
+      }
+    });
+    es.onmessage = (e) => {
+      try {
+        onEvent(JSON.parse(e.data));
+      } catch (err) {
+        console.error('Bad competition event', err);
+      }
+    };
+    return () => es.close();
+  }, [compId, onSnapshot, onEvent]);
+}
+
+// applyPosition updates a drawn entry's finishing position in place
+function applyPosition(draws: CompDraw[], p: { entry_id: number; position: number | null; entry_status?: string }): CompDraw[] {
+  return draws.map(d => d.entry_id === p.entry_id
+    ? { ...d, position: p.position ?? undefined, entry_status: p.entry_status ?? d.entry_status }
+    : d);
+}
+
 // --- Toast ---
 
 function Toast({ message }: { message: string | null }) {
@@ -95,9 +181,14 @@ function Toast({ message }: { message: string | null }) {
 type Tab = 'competitions' | 'my-picks';
 
 function App() {
-  const { userId, userName, token, impersonatedBy } = useUrlParams();
+  const { userId, userName, token, impersonatedBy, drawId } = useUrlParams();
   const api = useApi(token);
 
+  // Scanned from a printed card: no login needed
+  if (drawId && (!userId || !token)) {
+    return <DrawResultView drawId={drawId} />;
+  }
+
   if (!userId || !token) {
     return (
       <div className="ah-container">
@@ -176,6 +267,12 @@ function SweepstakesApp({ userId, userName, api }: {
     loadUserDraws();
   }, [loadCompetitions, loadUserDraws]);
 
+  // Positions or status changed on a competition being watched
+  const reloadResults = useCallback(() => {
+    loadCompetitions();
+    loadUserDraws();
+  }, [loadCompetitions, loadUserDraws]);
+
   // Auto-dismiss success toast
   useEffect(() => {
     if (!success) return;
@@ -268,8 +365,8 @@ function SweepstakesApp({ userId, userName, api }: {
             <CompetitionsTab
               competitions={competitions}
               userDraws={userDraws}
-              api={api}
               onPickBox={openPickView}
+              onResultsChanged={reloadResults}
             />
           )}
           {activeTab === 'my-picks' && (
@@ -283,19 +380,45 @@ function SweepstakesApp({ userId, userName, api }: {
 
 // --- CompetitionsTab ---
 
-function CompetitionsTab({ competitions, userDraws, api, onPickBox }: {
+function CompetitionsTab({ competitions, userDraws, onPickBox, onResultsChanged }: {
   competitions: Competition[];
   userDraws: Draw[];
-  api: ReturnType<typeof useApi>;
   onPickBox: (comp: Competition) => void;
+  onResultsChanged: () => void;
 }) {
   const [viewDrawsFor, setViewDrawsFor] = useState<number | null>(null);
   const [compDraws, setCompDraws] = useState<CompDraw[]>([]);
 
-  const loadCompDraws = async (compId: number) => {
+  // Results stay live while open: new draws and position updates stream in
+  const onSnapshot = useCallback((snap: CompetitionSnapshot) => setCompDraws(snap.draws || []), []);
+  const onEvent = useCallback((event: CompetitionEvent) => {
+    switch (event.type) {
+      case 'draw':
+        setCompDraws(prev => prev.some(d => d.id === event.payload.id)
+          ? prev.map(d => d.id === event.payload.id ? event.payload : d)
+          : [...prev, event.payload]);
+        // Admin assignments may be for this player; their own picks already refresh
+        if (event.payload.assigned) onResultsChanged();
+        break;
+      case 'void':
+        // Admin voided or redrew a draw; a redraw follows with a fresh 'draw' event
+        setCompDraws(prev => prev.filter(d => d.id !== event.payload.id));
+        onResultsChanged();
+        break;
+      case 'position':
+        setCompDraws(prev => applyPosition(prev, event.payload));
+        onResultsChanged();
+        break;
+      case 'status':
+        onResultsChanged();
+        break;
+    }
+  }, [onResultsChanged]);
+  useCompetitionStream(viewDrawsFor, onSnapshot, onEvent);
+
+  const loadCompDraws = (compId: number) => {
     if (viewDrawsFor === compId) { setViewDrawsFor(null); return; }
-    const data = await api(`/api/competitions/${compId}/all-draws`).catch(() => []);
-    setCompDraws(data || []);
+    setCompDraws([]);
     setViewDrawsFor(compId);
   };
 
@@ -381,12 +504,81 @@ function PickBoxView({ comp, api, userId, onChooseBox, onRandomPick, onBack }: {
   onBack: () => void;
 }) {
   const [boxCount, setBoxCount] = useState(0);
+  const [queue, setQueue] = useState<QueueState | null>(null);
+  const [queueError, setQueueError] = useState<string | null>(null);
+  const [hadTurn, setHadTurn] = useState(false);
+  const [secondsLeft, setSecondsLeft] = useState(0);
+
+  // Box count and queue move live as other players pick
+  const onSnapshot = useCallback((snap: CompetitionSnapshot) => {
+    setBoxCount(snap.available_count || 0);
+    if (snap.queue) setQueue(snap.queue);
+  }, []);
+  const onEvent = useCallback((event: CompetitionEvent) => {
+    if (event.type === 'availability') setBoxCount(event.payload.count);
+    if (event.type === 'queue') setQueue(event.payload);
+  }, []);
+  useCompetitionStream(comp.id, onSnapshot, onEvent);
+
+  // Players pick one at a time: join the queue on open, leave it on the way out
+  const joinQueue = useCallback(() => {
+    setQueueError(null);
+    setHadTurn(false);
+    api(`/api/competitions/${comp.id}/queue`, { method: 'POST' })
+      .catch(err => setQueueError(err instanceof Error ? err.message : 'Could not join the queue'));
+  }, [api, comp.id]);
 
   useEffect(() => {
-    api(`/api/competitions/${comp.id}/available-count`)
-      .then(data => setBoxCount(data?.count || 0))
-      .catch(() => {});
-  }, [api, comp.id]);
+    joinQueue();
+    return () => {
+      api(`/api/competitions/${comp.id}/queue`, { method: 'DELETE' }).catch(() => {});
+    };
+  }, [api, comp.id, joinQueue]);
+
+  const yourTurn = queue?.turn === userId;
+  const position = queue ? queue.waiting.indexOf(userId) + 1 : 0;
+  const turnExpired = hadTurn && !yourTurn && position === 0;
+
+  useEffect(() => {
+    if (yourTurn) setHadTurn(true);
+  }, [yourTurn]);
+
+  useEffect(() => {
+    if (!yourTurn || !queue?.turn_expires_at) return;
+    const expires = new Date(queue.turn_expires_at).getTime();
+    const tick = () => setSecondsLeft(Math.max(0, Math.round((expires - Date.now()) / 1000)));
+    tick();
+    const t = setInterval(tick, 1000);
+    return () => clearInterval(t);
+  }, [yourTurn, queue?.turn_expires_at]);
+
+  if (!yourTurn) {
+    return (
+      <div>
+        <button className="ah-btn-outline" style={{ marginBottom: 12 }} onClick={onBack}>← Back</button>
+        <div className="ah-card" style={{ textAlign: 'center', padding: 32 }}>
+          <h3 className="ah-section-title">{comp.name}</h3>
+          <p className="ah-meta">{boxCount} boxes remaining</p>
+          {queueError ? (
+            <div className="ah-banner ah-banner--error" style={{ marginTop: 12 }}>{queueError}</div>
+          ) : turnExpired ? (
+            <>
+              <p style={{ marginTop: 12 }}>Your turn ran out before you picked.</p>
+              <button className="ah-btn-primary" style={{ marginTop: 8 }} onClick={joinQueue}>
+                Join the Queue Again
+              </button>
+            </>
+          ) : (
+            <>
+              <p style={{ fontSize: 40, margin: '12px 0 0' }}>⏳</p>
+              <h2 style={{ marginTop: 8 }}>{position > 0 ? `You're #${position} in line` : 'Joining the queue…'}</h2>
+              <p className="ah-meta">Players pick one at a time. Keep this page open — your boxes appear when it's your turn.</p>
+            </>
+          )}
+        </div>
+      </div>
+    );
+  }
 
   return (
     <div>
@@ -395,6 +587,9 @@ function PickBoxView({ comp, api, userId, onChooseBox, onRandomPick, onBack }: {
       <div className="ah-card" style={{ marginBottom: 16 }}>
         <h3 className="ah-section-title">{comp.name}</h3>
         <p className="ah-meta">{boxCount} boxes remaining</p>
+        <p style={{ fontWeight: 600, color: secondsLeft <= 10 ? '#D32F2F' : '#F57C00', marginTop: 4 }}>
+          Your turn — {secondsLeft}s to pick
+        </p>
         <button
           className="ah-btn-primary"
           style={{ marginTop: 8 }}
@@ -445,6 +640,7 @@ function MyPicksTab({ userDraws }: { userDraws: Draw[] }) {
               <strong style={{ fontSize: 16 }}>{draw.entry_name}</strong>
               {draw.seed != null && <span className="ah-meta" style={{ marginLeft: 8 }}>Seed #{draw.seed}</span>}
               {draw.number != null && <span className="ah-meta" style={{ marginLeft: 8 }}>#{draw.number}</span>}
+              {draw.assigned && <p className="ah-meta" style={{ marginTop: 2 }}>Assigned by the organiser</p>}
               {draw.position != null && draw.position !== 999 && (
                 <p style={{ color: '#F57C00', fontWeight: 600, marginTop: 4 }}>{posLabel(draw.position)}</p>
               )}
@@ -460,6 +656,70 @@ function MyPicksTab({ userDraws }: { userDraws: Draw[] }) {
   );
 }
 
+// --- DrawResultView ---
+
+function DrawResultView({ drawId }: { drawId: string }) {
+  const [result, setResult] = useState<DrawResult | null>(null);
+  const [error, setError] = useState<string | null>(null);
+
+  const load = useCallback(() => {
+    fetch(`/api/draws/${drawId}/result`)
+      .then(res => { if (!res.ok) throw new Error('Result not found'); return res.json(); })
+      .then(data => { setResult(data); setError(null); })
+      .catch(err => { setResult(null); setError(err.message); });
+  }, [drawId]);
+
+  useEffect(() => { load(); }, [load]);
+
+  // Positions update live while the competition runs
+  const onSnapshot = useCallback(() => {}, []);
+  const onEvent = useCallback((event: CompetitionEvent) => {
+    if (event.type === 'position' || event.type === 'status' || event.type === 'void' ||
+        event.type === 'stage' || event.type === 'stage_entry' ||
+        (event.type === 'draw' && event.payload.id === Number(drawId))) {
+      load();
+    }
+  }, [load, drawId]);
+  useCompetitionStream(result ? result.competition_id : null, onSnapshot, onEvent);
+
+  return (
+    <div className="ah-container">
+      <h2>Sweepstakes</h2>
+      {error && <div className="ah-card"><p style={{ color: '#666' }}>{error}</p></div>}
+      {result && (
+        <div className="ah-card" style={{ textAlign: 'center', padding: 32 }}>
+          <p className="ah-meta">{result.comp_name}</p>
+          <h2 style={{ margin: '8px 0' }}>{result.player}</h2>
+          <p className="ah-meta">drew</p>
+          <h3 style={{ margin: '8px 0' }}>{result.entry_name}</h3>
+          {result.seed != null && <p className="ah-meta">Seed #{result.seed}</p>}
+          {result.number != null && <p className="ah-meta">#{result.number}</p>}
+          {result.position != null && result.position !== 999 && (
+            <p style={{ color: '#F57C00', fontWeight: 600, fontSize: 18, marginTop: 12 }}>{posLabel(result.position)}</p>
+          )}
+          {result.stages && result.stages.length > 0 && (
+            <div style={{ margin: '16px auto', maxWidth: 320, textAlign: 'left' }}>
+              {result.stages.map(s => (
+                <div key={s.stage_id} style={{ display: 'flex', justifyContent: 'space-between', padding: '6px 0', borderBottom: '1px solid #eee' }}>
+                  <span>
+                    {s.name}
+                    {s.group && <span className="ah-meta"> · Group {s.group}</span>}
+                    {s.position != null && <span className="ah-meta"> · {ordinal(s.position)}</span>}
+                  </span>
+                  <span style={{ fontWeight: 600, color: stageOutcomeColor(s.outcome) }}>
+                    {s.outcome === 'through' ? 'Through' : s.outcome === 'out' ? 'Out' : 'Playing'}
+                  </span>
+                </div>
+              ))}
+            </div>
+          )}
+          <span style={{ ...badge, backgroundColor: statusColor(result.comp_status), marginTop: 12 }}>{result.comp_status}</span>
+        </div>
+      )}
+    </div>
+  );
+}
+
 // --- Helpers ---
 
 function statusColor(status: string): string {
@@ -472,6 +732,19 @@ function statusColor(status: string): string {
   }
 }
 
+function stageOutcomeColor(outcome: EntryStage['outcome']): string {
+  switch (outcome) {
+    case 'through': return '#4CAF50';
+    case 'out': return '#9E9E9E';
+    default: return '#FF9800';
+  }
+}
+
+function ordinal(n: number): string {
+  const suffix = n % 100 >= 11 && n % 100 <= 13 ? 'th' : ['th', 'st', 'nd', 'rd'][n % 10] || 'th';
+  return `${n}${suffix}`;
+}
+
 function posLabel(pos: number): string {
   if (pos === 1) return '1st Place';
   if (pos === 2) return '2nd Place';
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/achgithub/activity-hub-common/bus"
+	redislib "github.com/achgithub/activity-hub-common/redis"
+	"github.com/achgithub/activity-hub-common/services"
+	redisv9 "github.com/redis/go-redis/v9"
+)
+
+// busClient publishes finished games to the event bus, which the leaderboard
+// reads even if it was restarting when the game ended. nil when Redis is
+// unavailable; results then go over HTTP only.
+var busClient *redisv9.Client
+
+func initBus() {
+	client, err := redislib.InitRedis()
+	if err != nil {
+		log.Printf("Warning: event bus unavailable, results will be posted to the leaderboard: %v", err)
+		return
+	}
+	busClient = client
+}
+
+// publishResult puts a result on the bus, reporting whether it got there
+func publishResult(result services.Result) bool {
+	if busClient == nil {
+		return false
+	}
+	_, err := bus.Publish(context.Background(), busClient, bus.StreamResults, bus.ResultReported.New(result.GameID, result))
+	if err != nil {
+		log.Printf("Failed to publish result for game %s: %v", result.GameID, err)
+		return false
+	}
+	return true
+}
@@ -8,20 +8,28 @@ import (
 	_ "github.com/lib/pq"
 )
 
-// SaveCompletedGame saves a completed game to PostgreSQL
+// SaveCompletedGame saves a completed game to PostgreSQL and adds it to both
+// players' stats. Saving the same game twice is a no-op.
 func SaveCompletedGame(game *Game) error {
 	query := `
 		INSERT INTO games (
 			challenge_id, player1_id, player1_name, player2_id, player2_name,
 			mode, status, winner_id, move_time_limit, first_to,
-			player1_score, player2_score, total_rounds, created_at, completed_at
+			player1_score, player2_score, total_rounds, created_at, completed_at, game_key
 		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13,
-			to_timestamp($14), to_timestamp($15))
+			to_timestamp($14), to_timestamp($15), $16)
+		ON CONFLICT (game_key) DO NOTHING
 		RETURNING id
 	`
 
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
 	var dbID int
-	err := db.QueryRow(
+	err = tx.QueryRow(
 		query,
 		game.ChallengeID,
 		game.Player1ID,
@@ -38,101 +46,86 @@ func SaveCompletedGame(game *Game) error {
 		game.CurrentRound,
 		game.CreatedAt,
 		game.CompletedAt,
+		game.ID,
 	).Scan(&dbID)
 
+	if err == sql.ErrNoRows {
+		// Already saved, and counted in the stats
+		return nil
+	}
 	if err != nil {
 		return fmt.Errorf("failed to save game: %w", err)
 	}
 
+	if err := recordGameStats(tx, game); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to save game: %w", err)
+	}
+
 	log.Printf("✅ Saved completed game to PostgreSQL: ID=%d, Game=%s", dbID, game.ID)
 	return nil
 }
 
-// SaveMove saves a move to PostgreSQL for history/replay
+// SaveMove saves a move to PostgreSQL for history/replay. The move number
+// comes from the game, so saving the same move twice (a write-behind retry)
+// leaves one row.
 func SaveMove(move *Move) error {
-	return nil
-}
-
-// UpdatePlayerStats updates player statistics
-func UpdatePlayerStats(userID string, userName string, won bool, lost bool, draw bool, moves int) error {
-	query := `
-		INSERT INTO player_stats (user_id, user_name, games_played, games_won, games_lost, games_draw, total_moves, last_played)
-		VALUES ($1, $2, 1, $3, $4, $5, $6, CURRENT_TIMESTAMP)
-		ON CONFLICT (user_id) DO UPDATE SET
-			games_played = player_stats.games_played + 1,
-			games_won = player_stats.games_won + $3,
-			games_lost = player_stats.games_lost + $4,
-			games_draw = player_stats.games_draw + $5,
-			total_moves = player_stats.total_moves + $6,
-			last_played = CURRENT_TIMESTAMP,
-			updated_at = CURRENT_TIMESTAMP
-	`
-
-	wonInt := 0
-	if won {
-		wonInt = 1
-	}
-	lostInt := 0
-	if lost {
-		lostInt = 1
-	}
-	drawInt := 0
-	if draw {
-		drawInt = 1
-	}
-
-	_, err := db.Exec(query, userID, userName, wonInt, lostInt, drawInt, moves)
+	_, err := db.Exec(`
+		INSERT INTO moves (game_id, round, player_id, position, symbol, move_number, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (game_id, move_number) DO NOTHING
+	`, move.GameID, move.Round, move.PlayerID, move.Position, move.Symbol, move.MoveNumber, move.PlayedAt)
 	if err != nil {
-		return fmt.Errorf("failed to update player stats: %w", err)
+		return fmt.Errorf("failed to save move: %w", err)
 	}
-
 	return nil
 }
 
-// GetPlayerStats retrieves player statistics
-func GetPlayerStats(userID string) (*PlayerStats, error) {
-	query := `
-		SELECT user_id, user_name, games_played, games_won, games_lost, games_draw, total_moves, fastest_win_moves
-		FROM player_stats
-		WHERE user_id = $1
-	`
-
-	var stats PlayerStats
-	var fastestWin sql.NullInt64
-
-	err := db.QueryRow(query, userID).Scan(
-		&stats.UserID,
-		&stats.UserName,
-		&stats.GamesPlayed,
-		&stats.GamesWon,
-		&stats.GamesLost,
-		&stats.GamesDraw,
-		&stats.TotalMoves,
-		&fastestWin,
-	)
-
-	if err == sql.ErrNoRows {
-		return &PlayerStats{
-			UserID:      userID,
-			GamesPlayed: 0,
-			GamesWon:    0,
-			GamesLost:   0,
-			GamesDraw:   0,
-		}, nil
-	}
-
+// GetMoves returns a game's moves in the order they were played
+func GetMoves(gameID string) ([]Move, error) {
+	rows, err := db.Query(`
+		SELECT game_id, player_id, position, symbol, move_number, round, created_at
+		FROM moves
+		WHERE game_id = $1
+		ORDER BY move_number
+	`, gameID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get player stats: %w", err)
+		return nil, fmt.Errorf("failed to get moves: %w", err)
 	}
-
-	if fastestWin.Valid {
-		fw := int(fastestWin.Int64)
-		stats.FastestWinMove = &fw
+	defer rows.Close()
+
+	moves := []Move{}
+	for rows.Next() {
+		var m Move
+		if err := rows.Scan(&m.GameID, &m.PlayerID, &m.Position, &m.Symbol, &m.MoveNumber, &m.Round, &m.PlayedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan move: %w", err)
+		}
+		moves = append(moves, m)
 	}
+	return moves, rows.Err()
+}
 
-	if stats.GamesPlayed > 0 {
-		stats.WinRate = float64(stats.GamesWon) / float64(stats.GamesPlayed) * 100
+// GetCompletedGame loads a finished game by its Redis game ID (for history once Redis has expired it)
+func GetCompletedGame(gameID string) (*Game, error) {
+	var game Game
+	var winnerID sql.NullString
+	err := db.QueryRow(`
+		SELECT game_key, player1_id, player1_name, player2_id, player2_name, status,
+		       winner_id, first_to, player1_score, player2_score
+		FROM games
+		WHERE game_key = $1
+	`, gameID).Scan(&game.ID, &game.Player1ID, &game.Player1Name, &game.Player2ID, &game.Player2Name,
+		&game.Status, &winnerID, &game.FirstTo, &game.Player1Score, &game.Player2Score)
+	if err != nil {
+		return nil, err
 	}
-
-	return &stats, nil
+	if winnerID.Valid {
+		game.WinnerID = &winnerID.String
+	}
+	// Player 1 always plays X (see handleCreateGame)
+	game.Player1Symbol = "X"
+	game.Player2Symbol = "O"
+	return &game, nil
 }
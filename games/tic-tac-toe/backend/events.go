@@ -0,0 +1,75 @@
+package main
+
+import (
+	"github.com/achgithub/activity-hub-common/events"
+)
+
+// Stream events. Payload field names are what the frontend reads.
+
+// GameUpdate carries the game after a move
+type GameUpdate struct {
+	Game    *Game  `json:"game"`
+	Message string `json:"message"`
+}
+
+// GameEnd carries the finished game and why it ended
+type GameEnd struct {
+	Game    *Game  `json:"game"`
+	Message string `json:"message"`
+	Reason  string `json:"reason"` // game_complete, forfeit or disconnect
+}
+
+type OpponentReconnect struct {
+	ReconnectedUserID string `json:"reconnectedUserId"`
+}
+
+type OpponentDisconnect struct {
+	DisconnectedUserID string `json:"disconnectedUserId"`
+	ClaimWinAfter      int    `json:"claimWinAfter"` // Seconds until the other player may claim the win
+}
+
+// Replay events - public, so names only
+type ReplayStart struct {
+	Player1Name   string  `json:"player1Name"`
+	Player1Symbol string  `json:"player1Symbol"`
+	Player2Name   string  `json:"player2Name"`
+	Player2Symbol string  `json:"player2Symbol"`
+	FirstTo       int     `json:"firstTo"`
+	TotalMoves    int     `json:"totalMoves"`
+	Speed         float64 `json:"speed"`
+}
+
+type ReplayMove struct {
+	MoveNumber int      `json:"moveNumber"`
+	Round      int      `json:"round"`
+	Position   int      `json:"position"`
+	Symbol     string   `json:"symbol"`
+	Board      []string `json:"board"`
+}
+
+type ReplayRoundEnd struct {
+	Round        int    `json:"round"`
+	WinnerSymbol string `json:"winnerSymbol"`
+	IsDraw       bool   `json:"isDraw"`
+	Player1Score int    `json:"player1Score"`
+	Player2Score int    `json:"player2Score"`
+}
+
+type ReplayEnd struct {
+	Status       GameStatus `json:"status"`
+	WinnerName   string     `json:"winnerName"`
+	Player1Score int        `json:"player1Score"`
+	Player2Score int        `json:"player2Score"`
+}
+
+var (
+	evConnected            = events.Register[*Game]("connected", 1, "Stream opened; current game state")
+	evMoveUpdate           = events.Register[GameUpdate]("move_update", 1, "A move was played")
+	evGameEnded            = events.Register[GameEnd]("game_ended", 1, "The game finished")
+	evOpponentReconnected  = events.Register[OpponentReconnect]("opponent_reconnected", 1, "The opponent's stream reconnected")
+	evOpponentDisconnected = events.Register[OpponentDisconnect]("opponent_disconnected", 1, "The opponent's stream dropped and hasn't come back")
+	evReplayStart          = events.Register[ReplayStart]("replay_start", 1, "Replay header")
+	evReplayMove           = events.Register[ReplayMove]("replay_move", 1, "One replayed move")
+	evReplayRoundEnd       = events.Register[ReplayRoundEnd]("replay_round_end", 1, "A replayed round finished")
+	evReplayEnd            = events.Register[ReplayEnd]("replay_end", 1, "Replay finished")
+)
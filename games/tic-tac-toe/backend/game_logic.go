@@ -1,6 +1,6 @@
 package main
 
-import "time"
+import "github.com/achgithub/activity-hub-common/turns"
 
 // checkWinner checks if there's a winner on the board
 // Returns: winnerSymbol ("X" or "O"), isWinner (true/false), isDraw (true/false)
@@ -39,64 +39,30 @@ func checkWinner(board []string) (string, bool, bool) {
 	return "", false, isDraw
 }
 
-// validateMove checks if a move is valid
-func validateMove(game *Game, playerID string, position int) error {
-	// Check if game is active
-	if game.Status != GameStatusActive {
-		return &GameError{Code: 400, Message: "Game is not active"}
-	}
-
-	// Check if it's the player's turn
-	if (game.CurrentTurn == 1 && playerID != game.Player1ID) ||
-		(game.CurrentTurn == 2 && playerID != game.Player2ID) {
-		return &GameError{Code: 400, Message: "Not your turn"}
-	}
-
+// placeSymbol is the rules for one move: the player in seat puts their symbol
+// on position. Returns the symbol placed.
+func placeSymbol(game *Game, seat int, position int) (string, error) {
 	// Check if position is valid (0-8)
 	if position < 0 || position > 8 {
-		return &GameError{Code: 400, Message: "Invalid position"}
+		return "", &turns.Error{Code: 400, Message: "Invalid position"}
 	}
 
 	// Check if cell is empty
 	if game.Board[position] != "" {
-		return &GameError{Code: 400, Message: "Cell already occupied"}
-	}
-
-	return nil
-}
-
-// applyMove applies a move to the game board
-func applyMove(game *Game, playerID string, position int) (*Game, error) {
-	// Validate the move
-	if err := validateMove(game, playerID, position); err != nil {
-		return nil, err
+		return "", &turns.Error{Code: 400, Message: "Cell already occupied"}
 	}
 
-	// Determine symbol
 	symbol := game.Player1Symbol
-	if playerID == game.Player2ID {
+	if seat == 1 {
 		symbol = game.Player2Symbol
 	}
-
-	// Apply move
 	game.Board[position] = symbol
-
-	// Switch turn
-	if game.CurrentTurn == 1 {
-		game.CurrentTurn = 2
-	} else {
-		game.CurrentTurn = 1
-	}
-
-	// Update last move time
-	game.LastMoveAt = getCurrentTimestamp()
-
-	return game, nil
+	return symbol, nil
 }
 
-// processGameResult checks for win/draw and updates game state
-// Returns: gameEnded (bool), message (string)
-func processGameResult(game *Game) (bool, string) {
+// processGameResult checks for win/draw after a move. A finished round starts
+// the next one with player 1 to move; a won series ends the game.
+func processGameResult(game *Game) turns.Outcome {
 	winnerSymbol, hasWinner, isDraw := checkWinner(game.Board)
 
 	if hasWinner {
@@ -109,51 +75,28 @@ func processGameResult(game *Game) (bool, string) {
 
 		// Check if series is complete
 		if game.Player1Score >= game.FirstTo {
-			// Player 1 wins the series
-			game.Status = GameStatusCompleted
-			game.WinnerID = &game.Player1ID
-			now := getCurrentTimestamp()
-			game.CompletedAt = &now
-			return true, "Player 1 wins the series!"
+			return turns.Outcome{Over: true, WinnerID: game.Player1ID, Message: "Player 1 wins the series!"}
 		} else if game.Player2Score >= game.FirstTo {
-			// Player 2 wins the series
-			game.Status = GameStatusCompleted
-			game.WinnerID = &game.Player2ID
-			now := getCurrentTimestamp()
-			game.CompletedAt = &now
-			return true, "Player 2 wins the series!"
-		} else {
-			// Round won, continue series
-			game.CurrentRound++
-			game.Board = []string{"", "", "", "", "", "", "", "", ""}
-			game.CurrentTurn = 1 // Reset to player 1
-			return false, "Round won! Next round starting..."
+			return turns.Outcome{Over: true, WinnerID: game.Player2ID, Message: "Player 2 wins the series!"}
 		}
+
+		// Round won, continue series
+		startNextRound(game)
+		return turns.Outcome{HoldTurn: true, Message: "Round won! Next round starting..."}
 	}
 
 	if isDraw {
-		// Round is a draw
-		game.CurrentRound++
-		game.Board = []string{"", "", "", "", "", "", "", "", ""}
-		game.CurrentTurn = 1 // Reset to player 1
-		return false, "Round is a draw! Next round starting..."
+		startNextRound(game)
+		return turns.Outcome{HoldTurn: true, Message: "Round is a draw! Next round starting..."}
 	}
 
 	// Game continues
-	return false, ""
-}
-
-// GameError represents a game logic error
-type GameError struct {
-	Code    int
-	Message string
-}
-
-func (e *GameError) Error() string {
-	return e.Message
+	return turns.Outcome{}
 }
 
-// getCurrentTimestamp returns current Unix timestamp
-func getCurrentTimestamp() int64 {
-	return time.Now().Unix()
+// startNextRound clears the board for the next round of the series
+func startNextRound(game *Game) {
+	game.CurrentRound++
+	game.Board = []string{"", "", "", "", "", "", "", "", ""}
+	game.CurrentTurn = 1 // Reset to player 1
 }
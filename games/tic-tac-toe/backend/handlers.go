@@ -1,15 +1,20 @@
 package main
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
-	"os"
 	"time"
 
+	"github.com/achgithub/activity-hub-common/analytics"
 	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/achgithub/activity-hub-common/events"
+	"github.com/achgithub/activity-hub-common/history"
+	apphttp "github.com/achgithub/activity-hub-common/http"
+	"github.com/achgithub/activity-hub-common/services"
+	"github.com/achgithub/activity-hub-common/turns"
 	"github.com/gorilla/mux"
 )
 
@@ -27,13 +32,10 @@ func getTokenFromRequest(r *http.Request) string {
 }
 
 // reportToLeaderboard sends game result to the leaderboard service
-// token parameter is the JWT token from the authenticated user making the request
-func reportToLeaderboard(game *Game, token string) {
-	leaderboardURL := os.Getenv("LEADERBOARD_URL")
-	if leaderboardURL == "" {
-		leaderboardURL = "http://127.0.0.1:5030"
-	}
-
+// token parameter is the JWT token from the authenticated user making the request.
+// selfReported marks results only the winner vouches for (claim-win after a
+// disconnect) so the leaderboard asks the opponent to confirm them.
+func reportToLeaderboard(game *Game, token string, selfReported bool) {
 	// Determine winner/loser
 	var winnerID, winnerName, loserID, loserName string
 	isDraw := game.WinnerID == nil
@@ -67,49 +69,119 @@ func reportToLeaderboard(game *Game, token string) {
 	// Format score
 	score := fmt.Sprintf("%d-%d", game.Player1Score, game.Player2Score)
 
-	result := map[string]interface{}{
-		"gameType":   "tic-tac-toe",
-		"gameId":     game.ID,
-		"winnerId":   winnerID,
-		"winnerName": winnerName,
-		"loserId":    loserID,
-		"loserName":  loserName,
-		"isDraw":     isDraw,
-		"score":      score,
-		"duration":   duration,
+	result := services.Result{
+		GameType:     "tic-tac-toe",
+		GameID:       game.ID,
+		WinnerID:     winnerID,
+		WinnerName:   winnerName,
+		LoserID:      loserID,
+		LoserName:    loserName,
+		IsDraw:       isDraw,
+		Score:        score,
+		Duration:     duration,
+		SelfReported: selfReported,
 	}
 
-	jsonBody, err := json.Marshal(result)
-	if err != nil {
-		log.Printf("Failed to marshal leaderboard result: %v", err)
+	// Self-reported results stay on HTTP: the leaderboard checks the
+	// reporter's token before asking the opponent to confirm
+	if !selfReported && publishResult(result) {
+		log.Printf("📊 Published game %s to the results stream", game.ID)
 		return
 	}
 
-	// Create request with Authorization header
-	req, err := http.NewRequest("POST", leaderboardURL+"/api/result", bytes.NewBuffer(jsonBody))
-	if err != nil {
-		log.Printf("Failed to create leaderboard request: %v", err)
+	if err := backends.ReportResult(context.Background(), token, result); err != nil {
+		log.Printf("Failed to report to leaderboard: %v", err)
 		return
 	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	log.Printf("📊 Reported game %s to leaderboard", game.ID)
+}
 
-	// Send request
-	client := &http.Client{}
-	resp, err := client.Do(req)
+// setLobbyPresence shows both players as in this game in the lobby, which
+// also stops them being challenged until it ends. Failures are logged only.
+func setLobbyPresence(game *Game) {
+	err := backends.SetInGame(context.Background(), "tic-tac-toe", game.ID, []string{game.Player1ID, game.Player2ID})
 	if err != nil {
-		log.Printf("Failed to report to leaderboard: %v", err)
+		log.Printf("Failed to set lobby presence for game %s: %v", game.ID, err)
+	}
+}
+
+// clearLobbyPresence frees the players of a finished game in the lobby
+func clearLobbyPresence(gameID string) {
+	if err := backends.ClearInGame(context.Background(), "tic-tac-toe", gameID); err != nil {
+		log.Printf("Failed to clear lobby presence for game %s: %v", gameID, err)
+	}
+}
+
+// reportToHistory records the completed game in the cross-app game history
+// (shown on players' profiles in the shell)
+func reportToHistory(game *Game, token string) {
+	outcome := func(playerID string) string {
+		if game.WinnerID == nil {
+			return history.OutcomeDraw
+		}
+		if *game.WinnerID == playerID {
+			return history.OutcomeWin
+		}
+		return history.OutcomeLoss
+	}
+
+	duration := 0
+	completedAt := time.Now()
+	if game.CompletedAt != nil {
+		duration = int(*game.CompletedAt - game.CreatedAt)
+		completedAt = time.Unix(*game.CompletedAt, 0)
+		recordGameLength(duration, token)
+	}
+
+	err := history.Report(token, history.Record{
+		App:    "tic-tac-toe",
+		GameID: game.ID,
+		Players: []history.Player{
+			{ID: game.Player1ID, Name: game.Player1Name, Outcome: outcome(game.Player1ID)},
+			{ID: game.Player2ID, Name: game.Player2Name, Outcome: outcome(game.Player2ID)},
+		},
+		Result:   fmt.Sprintf("%d-%d", game.Player1Score, game.Player2Score),
+		Duration: duration,
+		Options: map[string]interface{}{
+			"mode":          game.Mode,
+			"firstTo":       game.FirstTo,
+			"moveTimeLimit": game.MoveTimeLimit,
+		},
+		CompletedAt: completedAt,
+	})
+	if err != nil {
+		log.Printf("Failed to report game history: %v", err)
 		return
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode == http.StatusOK {
-		log.Printf("📊 Reported game %s to leaderboard", game.ID)
-	} else {
-		log.Printf("Leaderboard returned status %d", resp.StatusCode)
+	log.Printf("📜 Game %s recorded in history", game.ID)
+}
+
+// recordGameLength logs how long a game lasted for the landlord's usage
+// dashboard, at the venue of the player who finished it
+func recordGameLength(seconds int, token string) {
+	venueID := 0
+	if user, err := authlib.ResolveToken(identityDB, token); err == nil {
+		venueID = user.VenueID
+	}
+	if err := analytics.Record(identityDB, analytics.Event{
+		Event:   analytics.EventGameLength,
+		App:     "tic-tac-toe",
+		Value:   float64(seconds),
+		VenueID: venueID,
+	}); err != nil {
+		log.Printf("Failed to record game length: %v", err)
 	}
 }
 
+// reportFinished reports a finished game to the leaderboard and the game
+// history, using the token from the request that finished it
+func reportFinished(r *http.Request, game *Game, selfReported bool) {
+	token := getTokenFromRequest(r)
+	go reportToLeaderboard(game, token, selfReported)
+	go reportToHistory(game, token)
+}
+
 // handleGetGame retrieves game state
 func handleGetGame(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -194,7 +266,7 @@ func handleCreateGame(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Save to Redis
-	if err := CreateGame(game); err != nil {
+	if err := engine.Create(ctx, game); err != nil {
 		log.Printf("Failed to create game in Redis: %v", err)
 		sendError(w, "Failed to create game", 500)
 		return
@@ -231,84 +303,57 @@ func handleMakeMove(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get game from Redis
-	game, err := GetGame(req.GameID)
-	if err != nil {
-		sendError(w, "Game not found", 404)
-		return
-	}
-
-	// Apply move
-	game, err = applyMove(game, req.PlayerID, req.Position)
-	if err != nil {
-		if gameErr, ok := err.(*GameError); ok {
-			sendError(w, gameErr.Message, gameErr.Code)
-		} else {
-			sendError(w, err.Error(), 400)
+	game, outcome, err := engine.Play(ctx, req.GameID, req.PlayerID, func(game *Game, seat int) (turns.Outcome, error) {
+		symbol, err := placeSymbol(game, seat, req.Position)
+		if err != nil {
+			return turns.Outcome{}, err
 		}
-		return
-	}
 
-	// Check for win/draw
-	gameEnded, message := processGameResult(game)
+		// Queue the move for history/replay before a finished round resets the
+		// board (a failure here doesn't affect play)
+		game.MoveCount++
+		move := Move{
+			GameID:     game.ID,
+			PlayerID:   req.PlayerID,
+			Position:   req.Position,
+			Symbol:     symbol,
+			MoveNumber: game.MoveCount,
+			Round:      game.CurrentRound,
+			PlayedAt:   time.Now(),
+		}
+		if err := writes.Enqueue(ctx, writeMove, move); err != nil {
+			log.Printf("Warning: %v", err)
+		}
 
-	// Update game in Redis
-	if err := UpdateGame(game); err != nil {
-		log.Printf("Failed to update game in Redis: %v", err)
-		sendError(w, "Failed to update game", 500)
+		return processGameResult(game), nil
+	})
+	if err != nil {
+		sendGameError(w, err)
 		return
 	}
 
-	// Publish update to connected players via SSE (through Redis pub/sub)
-	if gameEnded {
-		// Save to PostgreSQL and update stats
-		if err := SaveCompletedGame(game); err != nil {
-			log.Printf("Warning: Failed to save completed game to PostgreSQL: %v", err)
-		}
-
-		// Update player stats
-		player1Won := game.WinnerID != nil && *game.WinnerID == game.Player1ID
-		player2Won := game.WinnerID != nil && *game.WinnerID == game.Player2ID
-		isDraw := game.WinnerID == nil
-
-		UpdatePlayerStats(game.Player1ID, game.Player1Name, player1Won, player2Won, isDraw, 0)
-		UpdatePlayerStats(game.Player2ID, game.Player2Name, player2Won, player1Won, isDraw, 0)
-
-		// Report to leaderboard service (use token from current request)
-		token := getTokenFromRequest(r)
-		go reportToLeaderboard(game, token)
-
-		// Publish game_ended event
-		PublishGameEvent(req.GameID, "game_ended", map[string]interface{}{
-			"game":    game,
-			"message": message,
-			"reason":  "game_complete",
-		})
-	} else {
-		// Publish move_update event
-		PublishGameEvent(req.GameID, "move_update", map[string]interface{}{
-			"game":    game,
-			"message": message,
-		})
+	if outcome.Over {
+		reportFinished(r, game, false)
 	}
 
 	respondJSON(w, map[string]interface{}{
 		"success":   true,
 		"game":      game,
-		"gameEnded": gameEnded,
-		"message":   message,
+		"gameEnded": outcome.Over,
+		"message":   outcome.Message,
 	})
 }
 
 // handleGetConfig returns game configuration and options schema
 // This allows the identity shell to dynamically render challenge options
 func handleGetConfig(w http.ResponseWriter, r *http.Request) {
-	config := map[string]interface{}{
-		"appId":       "tic-tac-toe",
-		"name":        "Tic-Tac-Toe",
-		"icon":        "⭕",
-		"description": "Classic 3x3 grid game. Get three in a row to win!",
-		"gameOptions": []map[string]interface{}{
+	apphttp.WriteConfig(w, apphttp.AppConfig{
+		AppID:        "tic-tac-toe",
+		Name:         "Tic-Tac-Toe",
+		Icon:         "⭕",
+		Description:  "Classic 3x3 grid game. Get three in a row to win!",
+		Capabilities: apphttp.Capabilities{Challenges: true, SSE: true},
+		GameOptions: []map[string]interface{}{
 			{
 				"id":      "firstTo",
 				"type":    "select",
@@ -332,12 +377,12 @@ func handleGetConfig(w http.ResponseWriter, r *http.Request) {
 				},
 			},
 		},
-	}
-
-	respondJSON(w, config)
+	})
 }
 
-// handleGetStats retrieves player statistics
+// handleGetStats retrieves player statistics: totals, streaks, X/O win rates,
+// average game length and head-to-head records.
+// Optional ?opponent={userId} returns just the record against that player.
 func handleGetStats(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	userID := vars["userId"]
@@ -347,6 +392,21 @@ func handleGetStats(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if opponentID := r.URL.Query().Get("opponent"); opponentID != "" {
+		records, err := GetOpponentStats(userID, opponentID, 1)
+		if err != nil {
+			log.Printf("Failed to get opponent stats: %v", err)
+			sendError(w, "Failed to get stats", 500)
+			return
+		}
+		record := OpponentRecord{OpponentID: opponentID}
+		if len(records) > 0 {
+			record = records[0]
+		}
+		respondJSON(w, record)
+		return
+	}
+
 	stats, err := GetPlayerStats(userID)
 	if err != nil {
 		log.Printf("Failed to get player stats: %v", err)
@@ -410,38 +470,31 @@ func handleGameStream(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
 	w.Header().Set("Connection", "keep-alive")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.Header().Set("X-Accel-Buffering", "no") // Disable nginx buffering
 
 	// Make sure we can flush
-	flusher, ok := w.(http.Flusher)
-	if !ok {
+	if _, ok := w.(http.Flusher); !ok {
 		log.Printf("❌ SSE: Streaming not supported")
 		sendError(w, "Streaming not supported", 500)
 		return
 	}
 
-	// Check if this is a reconnection (cancel pending disconnect timer)
-	wasReconnecting := CancelDisconnectTimer(gameID, user.Email)
+	// Check if this is a reconnection (cancels the pending disconnect)
+	wasReconnecting := presence.Connect(gameID, user.Email)
 	if wasReconnecting {
 		log.Printf("✅ SSE: Player %s reconnected to game %s", user.Email, gameID)
 		// Notify opponent they reconnected
-		PublishGameEvent(gameID, "opponent_reconnected", map[string]interface{}{
-			"reconnectedUserId": user.Email,
-		})
+		PublishGameEvent(evOpponentReconnected.New(gameID, OpponentReconnect{ReconnectedUserID: user.Email}))
 	}
 
-	// Register connection
-	AddSSEConnection(gameID, user.Email)
-
 	// Subscribe to game events
 	pubsub, msgChan := SubscribeToGame(gameID)
 	defer func() {
 		pubsub.Close()
 		// Only handle disconnect if game is still active
 		currentGame, err := GetGame(gameID)
-		if err == nil && currentGame.Status == GameStatusActive {
-			RemoveSSEConnection(gameID, user.Email, currentGame)
+		if err == nil && !currentGame.Over() {
+			presence.Disconnect(gameID, user.Email)
 		}
 		log.Printf("📡 SSE disconnected: game=%s, user=%s", gameID, user.Email)
 	}()
@@ -449,13 +502,7 @@ func handleGameStream(w http.ResponseWriter, r *http.Request) {
 	log.Printf("✅ SSE connected: game=%s, user=%s, reconnecting=%v", gameID, user.Email, wasReconnecting)
 
 	// Send initial connected event with current game state
-	initialEvent := SSEEvent{
-		Type:    "connected",
-		Payload: game,
-	}
-	initialData, _ := json.Marshal(initialEvent)
-	fmt.Fprintf(w, "data: %s\n\n", initialData)
-	flusher.Flush()
+	events.Send(w, evConnected.New(gameID, game))
 
 	// Set up ping ticker for keepalive (every 30 seconds)
 	ticker := time.NewTicker(30 * time.Second)
@@ -471,15 +518,11 @@ func handleGameStream(w http.ResponseWriter, r *http.Request) {
 
 		case msg := <-msgChan:
 			// Forward Redis message to SSE stream
-			fmt.Fprintf(w, "data: %s\n\n", msg.Payload)
-			flusher.Flush()
+			events.Forward(w, msg.Payload)
 
 		case <-ticker.C:
 			// Send keepalive ping
-			pingEvent := SSEEvent{Type: "ping"}
-			pingData, _ := json.Marshal(pingEvent)
-			fmt.Fprintf(w, "data: %s\n\n", pingData)
-			flusher.Flush()
+			events.Send(w, events.Ping.New(gameID, events.NoPayload{}))
 		}
 	}
 }
@@ -495,68 +538,15 @@ func handleForfeitHTTP(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	gameID := vars["gameId"]
 
-	game, err := GetGame(gameID)
+	game, err := engine.Forfeit(ctx, gameID, user.Email)
 	if err != nil {
-		sendError(w, "Game not found", 404)
+		sendGameError(w, err)
 		return
 	}
 
-	// Validate user is a player
-	if user.Email != game.Player1ID && user.Email != game.Player2ID {
-		sendError(w, "Not a player in this game", 403)
-		return
-	}
-
-	if game.Status == GameStatusCompleted {
-		sendError(w, "Game already ended", 400)
-		return
-	}
-
-	// Determine winner (opponent of the forfeiting player)
-	var winnerID string
-	if user.Email == game.Player1ID {
-		winnerID = game.Player2ID
-	} else {
-		winnerID = game.Player1ID
-	}
-
-	log.Printf("🏳️ Player %s forfeited game %s, winner: %s", user.Email, gameID, winnerID)
-
-	// Update game state
-	game.Status = GameStatusCompleted
-	game.WinnerID = &winnerID
-	now := time.Now().Unix()
-	game.CompletedAt = &now
-
-	// Save to Redis
-	if err := UpdateGame(game); err != nil {
-		log.Printf("Failed to update game after forfeit: %v", err)
-		sendError(w, "Failed to update game", 500)
-		return
-	}
-
-	// Save to PostgreSQL and update stats
-	if err := SaveCompletedGame(game); err != nil {
-		log.Printf("Warning: Failed to save forfeited game to PostgreSQL: %v", err)
-	}
+	log.Printf("🏳️ Player %s forfeited game %s, winner: %s", user.Email, gameID, *game.WinnerID)
 
-	// Update player stats
-	player1Won := winnerID == game.Player1ID
-	player2Won := winnerID == game.Player2ID
-
-	UpdatePlayerStats(game.Player1ID, game.Player1Name, player1Won, player2Won, false, 0)
-	UpdatePlayerStats(game.Player2ID, game.Player2Name, player2Won, player1Won, false, 0)
-
-	// Report to leaderboard service (use token from current request)
-	token := getTokenFromRequest(r)
-	go reportToLeaderboard(game, token)
-
-	// Publish game_ended event
-	PublishGameEvent(gameID, "game_ended", map[string]interface{}{
-		"game":    game,
-		"message": "Opponent forfeited",
-		"reason":  "forfeit",
-	})
+	reportFinished(r, game, false)
 
 	respondJSON(w, map[string]interface{}{
 		"success": true,
@@ -582,68 +572,41 @@ func handleClaimWinHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Validate user is a player
-	if user.Email != game.Player1ID && user.Email != game.Player2ID {
+	if turns.SeatOf(game, user.Email) < 0 {
 		sendError(w, "Not a player in this game", 403)
 		return
 	}
 
-	if game.Status == GameStatusCompleted {
+	if game.Over() {
 		sendError(w, "Game already ended", 400)
 		return
 	}
 
-	// Get opponent ID
-	var opponentID string
-	if user.Email == game.Player1ID {
-		opponentID = game.Player2ID
-	} else {
-		opponentID = game.Player1ID
-	}
+	opponentID := turns.Opponents(game, user.Email)[0]
 
-	// Check if opponent is disconnected
-	if !WasPlayerDisconnected(gameID, opponentID) {
-		sendError(w, "Cannot claim win - opponent is still connected or may reconnect", 400)
-		return
+	// The win goes to a player whose opponent left and didn't come back, or
+	// (in timed mode) ran out of time to move
+	disconnected := presence.Gone(gameID, opponentID)
+	if disconnected {
+		log.Printf("🏆 Player %s claiming win after %s disconnected in game %s", user.Email, opponentID, gameID)
+		game, err = engine.ClaimWin(ctx, gameID, user.Email, turns.ReasonDisconnect)
+	} else {
+		game, err = engine.ClaimTimeout(ctx, gameID, user.Email)
+		if err == turns.ErrNoTimeout {
+			sendError(w, "Cannot claim win - opponent is still connected or may reconnect", 400)
+			return
+		}
+		if err == nil {
+			log.Printf("🏆 Player %s claimed win after %s ran out of time in game %s", user.Email, opponentID, gameID)
+		}
 	}
-
-	log.Printf("🏆 Player %s claiming win after %s disconnected in game %s", user.Email, opponentID, gameID)
-
-	// Update game state
-	game.Status = GameStatusCompleted
-	winnerEmail := user.Email
-	game.WinnerID = &winnerEmail
-	now := time.Now().Unix()
-	game.CompletedAt = &now
-
-	// Save to Redis
-	if err := UpdateGame(game); err != nil {
-		log.Printf("Failed to update game after claim win: %v", err)
-		sendError(w, "Failed to update game", 500)
+	if err != nil {
+		sendGameError(w, err)
 		return
 	}
 
-	// Save to PostgreSQL and update stats
-	if err := SaveCompletedGame(game); err != nil {
-		log.Printf("Warning: Failed to save claimed game to PostgreSQL: %v", err)
-	}
-
-	// Update player stats
-	player1Won := user.Email == game.Player1ID
-	player2Won := user.Email == game.Player2ID
-
-	UpdatePlayerStats(game.Player1ID, game.Player1Name, player1Won, player2Won, false, 0)
-	UpdatePlayerStats(game.Player2ID, game.Player2Name, player2Won, player1Won, false, 0)
-
-	// Report to leaderboard service (use token from current request)
-	token := getTokenFromRequest(r)
-	go reportToLeaderboard(game, token)
-
-	// Publish game_ended event
-	PublishGameEvent(gameID, "game_ended", map[string]interface{}{
-		"game":    game,
-		"message": "You won - opponent disconnected",
-		"reason":  "disconnect",
-	})
+	// Only a disconnect win rests on the claimant's word
+	reportFinished(r, game, disconnected)
 
 	respondJSON(w, map[string]interface{}{
 		"success": true,
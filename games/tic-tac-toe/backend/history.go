@@ -0,0 +1,195 @@
+package main
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/achgithub/activity-hub-common/events"
+	"github.com/gorilla/mux"
+)
+
+// Replay pacing: long thinking pauses are capped so replays stay watchable
+const (
+	maxReplaySpeed = 20.0
+	maxReplayGap   = 5 * time.Second
+	roundEndPause  = 2 * time.Second
+)
+
+// loadMatchHistory builds a game's history from Redis (recent/active games)
+// or PostgreSQL (completed games that have expired from Redis)
+func loadMatchHistory(gameID string) (*MatchHistory, error) {
+	game, err := GetGame(gameID)
+	if err != nil {
+		game, err = GetCompletedGame(gameID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	moves, err := GetMoves(gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MatchHistory{
+		GameID:        game.ID,
+		Player1ID:     game.Player1ID,
+		Player1Name:   game.Player1Name,
+		Player1Symbol: game.Player1Symbol,
+		Player2ID:     game.Player2ID,
+		Player2Name:   game.Player2Name,
+		Player2Symbol: game.Player2Symbol,
+		Status:        game.Status,
+		FirstTo:       game.FirstTo,
+		Player1Score:  game.Player1Score,
+		Player2Score:  game.Player2Score,
+		WinnerID:      game.WinnerID,
+		Moves:         moves,
+	}, nil
+}
+
+// handleGetHistory returns the full move list with timestamps
+// GET /api/game/{gameId}/history
+func handleGetHistory(w http.ResponseWriter, r *http.Request) {
+	if _, ok := authlib.GetUserFromContext(r.Context()); !ok {
+		sendError(w, "Unauthorized", 401)
+		return
+	}
+
+	history, err := loadMatchHistory(mux.Vars(r)["gameId"])
+	if err == sql.ErrNoRows {
+		sendError(w, "Game not found", 404)
+		return
+	} else if err != nil {
+		log.Printf("Failed to load history: %v", err)
+		sendError(w, "Failed to load history", 500)
+		return
+	}
+
+	respondJSON(w, history)
+}
+
+// handleReplay streams a game's moves over SSE at the original pace or faster.
+// Public (read-only) so display screens can show it without a player token.
+// GET /api/game/{gameId}/replay?speed=2  (1 = original pace, up to 20)
+func handleReplay(w http.ResponseWriter, r *http.Request) {
+	history, err := loadMatchHistory(mux.Vars(r)["gameId"])
+	if err == sql.ErrNoRows {
+		sendError(w, "Game not found", 404)
+		return
+	} else if err != nil {
+		log.Printf("Failed to load replay: %v", err)
+		sendError(w, "Failed to load replay", 500)
+		return
+	}
+
+	speed := 1.0
+	if s, err := strconv.ParseFloat(r.URL.Query().Get("speed"), 64); err == nil && s > 0 {
+		speed = s
+	}
+	if speed > maxReplaySpeed {
+		speed = maxReplaySpeed
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+
+	if _, ok := w.(http.Flusher); !ok {
+		sendError(w, "Streaming not supported", 500)
+		return
+	}
+
+	session := mux.Vars(r)["gameId"]
+
+	// Names only - replays are public, so player emails are left out
+	events.Send(w, evReplayStart.New(session, ReplayStart{
+		Player1Name:   history.Player1Name,
+		Player1Symbol: history.Player1Symbol,
+		Player2Name:   history.Player2Name,
+		Player2Symbol: history.Player2Symbol,
+		FirstTo:       history.FirstTo,
+		TotalMoves:    len(history.Moves),
+		Speed:         speed,
+	}))
+
+	ctx := r.Context()
+	wait := func(d time.Duration) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(d):
+			return true
+		}
+	}
+
+	board := make([]string, 9)
+	player1Score, player2Score := 0, 0
+	round := 1
+
+	for i, move := range history.Moves {
+		if i > 0 {
+			gap := move.PlayedAt.Sub(history.Moves[i-1].PlayedAt)
+			if gap > maxReplayGap {
+				gap = maxReplayGap
+			}
+			if !wait(time.Duration(float64(gap) / speed)) {
+				return
+			}
+		}
+
+		// A new round starts on a fresh board
+		if move.Round != round {
+			board = make([]string, 9)
+			round = move.Round
+		}
+		board[move.Position] = move.Symbol
+
+		events.Send(w, evReplayMove.New(session, ReplayMove{
+			MoveNumber: move.MoveNumber,
+			Round:      move.Round,
+			Position:   move.Position,
+			Symbol:     move.Symbol,
+			Board:      board,
+		}))
+
+		winnerSymbol, hasWinner, isDraw := checkWinner(board)
+		if hasWinner || isDraw {
+			if hasWinner && winnerSymbol == history.Player1Symbol {
+				player1Score++
+			} else if hasWinner {
+				player2Score++
+			}
+			events.Send(w, evReplayRoundEnd.New(session, ReplayRoundEnd{
+				Round:        move.Round,
+				WinnerSymbol: winnerSymbol,
+				IsDraw:       isDraw,
+				Player1Score: player1Score,
+				Player2Score: player2Score,
+			}))
+			if i < len(history.Moves)-1 && !wait(time.Duration(float64(roundEndPause)/speed)) {
+				return
+			}
+		}
+	}
+
+	winnerName := ""
+	if history.WinnerID != nil {
+		if *history.WinnerID == history.Player1ID {
+			winnerName = history.Player1Name
+		} else {
+			winnerName = history.Player2Name
+		}
+	}
+	events.Send(w, evReplayEnd.New(session, ReplayEnd{
+		Status:       history.Status,
+		WinnerName:   winnerName,
+		Player1Score: history.Player1Score,
+		Player2Score: history.Player2Score,
+	}))
+}
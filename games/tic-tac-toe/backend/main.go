@@ -7,18 +7,47 @@ import (
 	"os"
 
 	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/achgithub/activity-hub-common/cli"
 	"github.com/achgithub/activity-hub-common/config"
 	"github.com/achgithub/activity-hub-common/database"
-	"github.com/gorilla/handlers"
+	"github.com/achgithub/activity-hub-common/events"
+	apphttp "github.com/achgithub/activity-hub-common/http"
+	"github.com/achgithub/activity-hub-common/maintenance"
+	"github.com/achgithub/activity-hub-common/services"
 	"github.com/gorilla/mux"
 	_ "github.com/lib/pq"
 )
 
 var db *sql.DB
 
+// identityDB is the shared identity database (auth, usage analytics)
+var identityDB *sql.DB
+
+// backends reports results to the leaderboard and who is playing to the lobby
+var backends *services.Registry
+
 const APP_NAME = "Tic-Tac-Toe"
 
 func main() {
+	cli.New("tic-tac-toe",
+		cli.Serve(serve),
+		cli.Migrate(openAppDB, cli.Migrations{
+			Dir:    "../database",
+			Schema: []string{"schema.sql"},
+			Upgrades: []string{
+				"migrate_add_move_history.sql",
+				"migrate_add_player_stats_detail.sql",
+			},
+		}),
+	).Main()
+}
+
+// openAppDB connects to tictactoe_db for commands other than serve
+func openAppDB() (*sql.DB, error) {
+	return database.InitDatabase("tictactoe")
+}
+
+func serve() {
 	log.Printf("🎮 %s Backend Starting", APP_NAME)
 
 	// Initialize Redis
@@ -26,6 +55,8 @@ func main() {
 		log.Fatal("Failed to connect to Redis:", err)
 	}
 	log.Println("✅ Connected to Redis")
+	initBus()
+	initEngine()
 
 	// Initialize app database
 	var err error
@@ -34,13 +65,15 @@ func main() {
 		log.Fatal("Failed to connect to app database:", err)
 	}
 	defer db.Close()
+	initWrites()
 
 	// Initialize identity database (for authentication)
-	identityDB, err := database.InitIdentityDatabase()
+	identityDB, err = database.InitIdentityDatabase()
 	if err != nil {
 		log.Fatal("Failed to connect to identity database:", err)
 	}
 	defer identityDB.Close()
+	backends = services.NewRegistry(identityDB)
 
 	// Build per-route middleware
 	authMiddleware := authlib.Middleware(identityDB)
@@ -52,13 +85,18 @@ func main() {
 	// Public endpoints
 	r.HandleFunc("/api/health", handleHealth).Methods("GET")
 	r.HandleFunc("/api/config", handleGetConfig).Methods("GET")
+	r.HandleFunc("/api/events/schema", events.SchemaHandler).Methods("GET")
 
 	// SSE endpoint uses query-param auth (EventSource limitation)
 	r.Handle("/api/game/{gameId}/stream",
 		sseMiddleware(http.HandlerFunc(handleGameStream))).Methods("GET")
 
+	// Replay is public (read-only, names only) so display screens can stream it
+	r.HandleFunc("/api/game/{gameId}/replay", handleReplay).Methods("GET")
+
 	// Authenticated endpoints
 	r.Handle("/api/game/{gameId}", authMiddleware(http.HandlerFunc(handleGetGame))).Methods("GET")
+	r.Handle("/api/game/{gameId}/history", authMiddleware(http.HandlerFunc(handleGetHistory))).Methods("GET")
 	r.Handle("/api/game", authMiddleware(http.HandlerFunc(handleCreateGame))).Methods("POST")
 	r.Handle("/api/move", authMiddleware(http.HandlerFunc(handleMakeMove))).Methods("POST")
 	r.Handle("/api/game/{gameId}/forfeit", authMiddleware(http.HandlerFunc(handleForfeitHTTP))).Methods("POST")
@@ -70,21 +108,20 @@ func main() {
 	r.PathPrefix("/").Handler(spaHandler{staticPath: staticDir, indexPath: "index.html"})
 
 	// CORS configuration
-	corsHandler := handlers.CORS(
-		handlers.AllowedOrigins([]string{"*"}),
-		handlers.AllowedMethods([]string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}),
-		handlers.AllowedHeaders([]string{"Content-Type", "Authorization"}),
-		handlers.AllowCredentials(),
-	)
+	cors := apphttp.NewCORSPolicy(identityDB)
+	appMaintenance := maintenance.New(identityDB)
 
 	port := config.GetEnv("PORT", "4001")
 	log.Printf("🚀 %s backend listening on :%s", APP_NAME, port)
-	log.Fatal(http.ListenAndServe(":"+port, corsHandler(r)))
+	log.Fatal(http.ListenAndServe(":"+port, cors.Middleware(appMaintenance.Middleware("tic-tac-toe")(apphttp.Versioned(r)))))
 }
 
 func handleHealth(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.Write([]byte(`{"status":"ok","service":"tic-tac-toe"}`))
+	respondJSON(w, map[string]interface{}{
+		"status":  "ok",
+		"service": "tic-tac-toe",
+		"writes":  writes.Stats(),
+	})
 }
 
 func getEnv(key, fallback string) string {
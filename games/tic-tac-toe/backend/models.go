@@ -1,5 +1,7 @@
 package main
 
+import "time"
+
 // GameMode represents the type of game
 type GameMode string
 
@@ -36,6 +38,7 @@ type Game struct {
 	Player1Score  int        `json:"player1Score"`  // Wins in this series
 	Player2Score  int        `json:"player2Score"`  // Wins in this series
 	CurrentRound  int        `json:"currentRound"`  // Which round in the series
+	MoveCount     int        `json:"moveCount"`     // Moves played across the series
 	WinnerID      *string    `json:"winnerId"`      // NULL during play
 	LastMoveAt    int64      `json:"lastMoveAt"`    // Unix timestamp
 	CreatedAt     int64      `json:"createdAt"`     // Unix timestamp
@@ -44,11 +47,30 @@ type Game struct {
 
 // Move represents a single move in a game
 type Move struct {
-	GameID     string `json:"gameId"`
-	PlayerID   string `json:"playerId"`
-	Position   int    `json:"position"`   // 0-8
-	Symbol     string `json:"symbol"`     // "X" or "O"
-	MoveNumber int    `json:"moveNumber"` // 1, 2, 3, etc.
+	GameID     string    `json:"gameId"`
+	PlayerID   string    `json:"playerId"`
+	Position   int       `json:"position"`   // 0-8
+	Symbol     string    `json:"symbol"`     // "X" or "O"
+	MoveNumber int       `json:"moveNumber"` // 1, 2, 3, etc. across the whole series
+	Round      int       `json:"round"`      // Round of the series the move was played in
+	PlayedAt   time.Time `json:"playedAt"`
+}
+
+// MatchHistory is a game's full move list for history and replay
+type MatchHistory struct {
+	GameID        string     `json:"gameId"`
+	Player1ID     string     `json:"player1Id"`
+	Player1Name   string     `json:"player1Name"`
+	Player1Symbol string     `json:"player1Symbol"`
+	Player2ID     string     `json:"player2Id"`
+	Player2Name   string     `json:"player2Name"`
+	Player2Symbol string     `json:"player2Symbol"`
+	Status        GameStatus `json:"status"`
+	FirstTo       int        `json:"firstTo"`
+	Player1Score  int        `json:"player1Score"`
+	Player2Score  int        `json:"player2Score"`
+	WinnerID      *string    `json:"winnerId"`
+	Moves         []Move     `json:"moves"`
 }
 
 // MoveRequest represents a move request from client
@@ -69,6 +91,38 @@ type PlayerStats struct {
 	TotalMoves     int     `json:"totalMoves"`
 	FastestWinMove *int    `json:"fastestWinMove,omitempty"`
 	WinRate        float64 `json:"winRate"`
+
+	CurrentStreak  Streak           `json:"currentStreak"`
+	BestWinStreak  int              `json:"bestWinStreak"`
+	AsX            SideStats        `json:"asX"` // X moves first in round 1
+	AsO            SideStats        `json:"asO"`
+	AvgGameSeconds float64          `json:"avgGameSeconds"`
+	AvgMoves       float64          `json:"avgMoves"`
+	Opponents      []OpponentRecord `json:"opponents"`
+}
+
+// Streak is a run of consecutive results of the same kind
+type Streak struct {
+	Result string `json:"result,omitempty"` // win, loss or draw
+	Length int    `json:"length"`
+}
+
+// SideStats is a player's record playing one symbol
+type SideStats struct {
+	GamesPlayed int     `json:"gamesPlayed"`
+	GamesWon    int     `json:"gamesWon"`
+	WinRate     float64 `json:"winRate"`
+}
+
+// OpponentRecord is a player's head-to-head record against one opponent
+type OpponentRecord struct {
+	OpponentID   string    `json:"opponentId"`
+	OpponentName string    `json:"opponentName"`
+	GamesPlayed  int       `json:"gamesPlayed"`
+	GamesWon     int       `json:"gamesWon"`
+	GamesLost    int       `json:"gamesLost"`
+	GamesDraw    int       `json:"gamesDraw"`
+	LastPlayed   time.Time `json:"lastPlayed"`
 }
 
 // WSMessage represents a WebSocket message
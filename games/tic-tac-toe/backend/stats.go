@@ -0,0 +1,238 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Player stats are kept materialised: player_stats holds each player's
+// totals, streaks and X/O split, and opponent_stats their head-to-head
+// records. Both are updated in the transaction that saves a completed game
+// (see SaveCompletedGame), so reading stats never scans the games table.
+// A "game" here is a whole series, as in the games table.
+
+// maxStatsOpponents bounds the head-to-head records returned with a player's stats
+const maxStatsOpponents = 20
+
+// statsSide is one player's view of a completed game
+type statsSide struct {
+	userID, userName    string
+	opponentID, oppName string
+	symbol              string
+	result              string // win, loss or draw
+	moves               int
+}
+
+// recordGameStats adds a completed game to both players' stats
+func recordGameStats(tx *sql.Tx, game *Game) error {
+	// Moves each player made across the series
+	moves := map[string]int{}
+	rows, err := tx.Query("SELECT player_id, COUNT(*) FROM moves WHERE game_id = $1 GROUP BY player_id", game.ID)
+	if err != nil {
+		return fmt.Errorf("failed to count moves: %w", err)
+	}
+	for rows.Next() {
+		var playerID string
+		var n int
+		if err := rows.Scan(&playerID, &n); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to count moves: %w", err)
+		}
+		moves[playerID] = n
+	}
+	rows.Close()
+
+	var duration int64
+	if game.CompletedAt != nil && *game.CompletedAt > game.CreatedAt {
+		duration = *game.CompletedAt - game.CreatedAt
+	}
+
+	// Player 1 always plays X (see handleCreateGame)
+	sides := []statsSide{
+		{game.Player1ID, game.Player1Name, game.Player2ID, game.Player2Name, "X", gameResult(game, game.Player1ID), moves[game.Player1ID]},
+		{game.Player2ID, game.Player2Name, game.Player1ID, game.Player1Name, "O", gameResult(game, game.Player2ID), moves[game.Player2ID]},
+	}
+	for _, s := range sides {
+		if err := updatePlayerStats(tx, s, duration); err != nil {
+			return err
+		}
+		if err := updateOpponentStats(tx, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// gameResult is how a completed game went for one player
+func gameResult(game *Game, userID string) string {
+	switch {
+	case game.WinnerID == nil:
+		return "draw"
+	case *game.WinnerID == userID:
+		return "win"
+	default:
+		return "loss"
+	}
+}
+
+func updatePlayerStats(tx *sql.Tx, s statsSide, duration int64) error {
+	won, lost, drawn := resultCounts(s.result)
+	asX, asO := 0, 0
+	if s.symbol == "X" {
+		asX = 1
+	} else {
+		asO = 1
+	}
+
+	// The streak carries on if this result matches the last one, otherwise restarts at 1
+	_, err := tx.Exec(`
+		INSERT INTO player_stats (
+			user_id, user_name, games_played, games_won, games_lost, games_draw, total_moves,
+			fastest_win_moves, games_as_x, wins_as_x, games_as_o, wins_as_o, total_seconds,
+			current_streak_result, current_streak, best_win_streak, last_played
+		) VALUES ($1, $2, 1, $3, $4, $5, $6, CASE WHEN $3 = 1 AND $6 > 0 THEN $6 END,
+			$7, $3 * $7, $8, $3 * $8, $9, $10, 1, $3, CURRENT_TIMESTAMP)
+		ON CONFLICT (user_id) DO UPDATE SET
+			user_name = EXCLUDED.user_name,
+			games_played = player_stats.games_played + 1,
+			games_won = player_stats.games_won + $3,
+			games_lost = player_stats.games_lost + $4,
+			games_draw = player_stats.games_draw + $5,
+			total_moves = player_stats.total_moves + $6,
+			fastest_win_moves = CASE WHEN $3 = 1 AND $6 > 0
+				THEN LEAST(player_stats.fastest_win_moves, $6)
+				ELSE player_stats.fastest_win_moves END,
+			games_as_x = player_stats.games_as_x + $7,
+			wins_as_x = player_stats.wins_as_x + $3 * $7,
+			games_as_o = player_stats.games_as_o + $8,
+			wins_as_o = player_stats.wins_as_o + $3 * $8,
+			total_seconds = player_stats.total_seconds + $9,
+			current_streak_result = $10,
+			current_streak = CASE WHEN player_stats.current_streak_result = $10
+				THEN player_stats.current_streak + 1 ELSE 1 END,
+			best_win_streak = GREATEST(player_stats.best_win_streak, CASE
+				WHEN $3 = 0 THEN 0
+				WHEN player_stats.current_streak_result = $10 THEN player_stats.current_streak + 1
+				ELSE 1 END),
+			last_played = CURRENT_TIMESTAMP,
+			updated_at = CURRENT_TIMESTAMP
+	`, s.userID, s.userName, won, lost, drawn, s.moves, asX, asO, duration, s.result)
+	if err != nil {
+		return fmt.Errorf("failed to update player stats: %w", err)
+	}
+	return nil
+}
+
+func updateOpponentStats(tx *sql.Tx, s statsSide) error {
+	won, lost, drawn := resultCounts(s.result)
+	_, err := tx.Exec(`
+		INSERT INTO opponent_stats (user_id, opponent_id, opponent_name, games_played, games_won, games_lost, games_draw, last_played)
+		VALUES ($1, $2, $3, 1, $4, $5, $6, CURRENT_TIMESTAMP)
+		ON CONFLICT (user_id, opponent_id) DO UPDATE SET
+			opponent_name = EXCLUDED.opponent_name,
+			games_played = opponent_stats.games_played + 1,
+			games_won = opponent_stats.games_won + $4,
+			games_lost = opponent_stats.games_lost + $5,
+			games_draw = opponent_stats.games_draw + $6,
+			last_played = CURRENT_TIMESTAMP
+	`, s.userID, s.opponentID, s.oppName, won, lost, drawn)
+	if err != nil {
+		return fmt.Errorf("failed to update opponent stats: %w", err)
+	}
+	return nil
+}
+
+func resultCounts(result string) (won, lost, drawn int) {
+	switch result {
+	case "win":
+		return 1, 0, 0
+	case "loss":
+		return 0, 1, 0
+	default:
+		return 0, 0, 1
+	}
+}
+
+// GetPlayerStats retrieves player statistics, with their most-played opponents
+func GetPlayerStats(userID string) (*PlayerStats, error) {
+	stats := PlayerStats{UserID: userID, Opponents: []OpponentRecord{}}
+	var fastestWin sql.NullInt64
+	var streakResult sql.NullString
+	var totalSeconds int64
+
+	err := db.QueryRow(`
+		SELECT user_id, user_name, games_played, games_won, games_lost, games_draw, total_moves, fastest_win_moves,
+		       games_as_x, wins_as_x, games_as_o, wins_as_o, total_seconds,
+		       current_streak_result, current_streak, best_win_streak
+		FROM player_stats
+		WHERE user_id = $1
+	`, userID).Scan(
+		&stats.UserID, &stats.UserName, &stats.GamesPlayed, &stats.GamesWon, &stats.GamesLost, &stats.GamesDraw,
+		&stats.TotalMoves, &fastestWin,
+		&stats.AsX.GamesPlayed, &stats.AsX.GamesWon, &stats.AsO.GamesPlayed, &stats.AsO.GamesWon, &totalSeconds,
+		&streakResult, &stats.CurrentStreak.Length, &stats.BestWinStreak,
+	)
+	if err == sql.ErrNoRows {
+		return &stats, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get player stats: %w", err)
+	}
+
+	if fastestWin.Valid {
+		fw := int(fastestWin.Int64)
+		stats.FastestWinMove = &fw
+	}
+	if streakResult.Valid {
+		stats.CurrentStreak.Result = streakResult.String
+	} else {
+		stats.CurrentStreak.Length = 0
+	}
+
+	stats.WinRate = winRate(stats.GamesWon, stats.GamesPlayed)
+	stats.AsX.WinRate = winRate(stats.AsX.GamesWon, stats.AsX.GamesPlayed)
+	stats.AsO.WinRate = winRate(stats.AsO.GamesWon, stats.AsO.GamesPlayed)
+	if stats.GamesPlayed > 0 {
+		stats.AvgGameSeconds = float64(totalSeconds) / float64(stats.GamesPlayed)
+		stats.AvgMoves = float64(stats.TotalMoves) / float64(stats.GamesPlayed)
+	}
+
+	stats.Opponents, err = GetOpponentStats(userID, "", maxStatsOpponents)
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// GetOpponentStats returns a player's head-to-head records, most played first.
+// If opponentID is set only the record against them is returned.
+func GetOpponentStats(userID, opponentID string, limit int) ([]OpponentRecord, error) {
+	rows, err := db.Query(`
+		SELECT opponent_id, opponent_name, games_played, games_won, games_lost, games_draw, last_played
+		FROM opponent_stats
+		WHERE user_id = $1 AND ($2 = '' OR opponent_id = $2)
+		ORDER BY games_played DESC, last_played DESC
+		LIMIT $3
+	`, userID, opponentID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get opponent stats: %w", err)
+	}
+	defer rows.Close()
+
+	records := []OpponentRecord{}
+	for rows.Next() {
+		var o OpponentRecord
+		if err := rows.Scan(&o.OpponentID, &o.OpponentName, &o.GamesPlayed, &o.GamesWon, &o.GamesLost, &o.GamesDraw, &o.LastPlayed); err != nil {
+			return nil, fmt.Errorf("failed to scan opponent stats: %w", err)
+		}
+		records = append(records, o)
+	}
+	return records, rows.Err()
+}
+
+func winRate(won, played int) float64 {
+	if played == 0 {
+		return 0
+	}
+	return float64(won) / float64(played) * 100
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/achgithub/activity-hub-common/turns"
+	"github.com/go-redis/redis/v8"
+)
+
+// Turn order, storing games between moves, forfeits and claimed wins come from
+// the shared turns engine. This file plugs the game into it: Game implements
+// turns.Game, games are kept in Redis, and the lifecycle hooks save finished
+// games and tell the players what happened.
+
+var engine *turns.Engine[*Game]
+
+// writes saves moves and finished games to PostgreSQL behind the requests
+// that make them, so a move costs a Redis round trip rather than a database
+// one. Games themselves are held in memory in front of Redis.
+var writes *turns.WriteBehind
+
+// Kinds of write-behind writes
+const (
+	writeMove = "move"
+	writeGame = "game"
+)
+
+// presence tracks the players' streams so a player can claim the win when
+// their opponent leaves
+var presence *turns.Presence
+
+// How long a player whose stream dropped has to come back
+const disconnectGrace = 15 * time.Second
+
+// Seconds per move in timed mode when the challenge didn't set a limit
+const defaultMoveTimeLimit = 30
+
+// redisStore adapts the Redis client to turns.Store
+type redisStore struct {
+	client *redis.Client
+}
+
+func (s redisStore) Get(ctx context.Context, key string) (string, error) {
+	v, err := s.client.Get(ctx, key).Result()
+	if err == redis.Nil {
+		return "", turns.ErrNotFound
+	}
+	return v, err
+}
+
+func (s redisStore) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	return s.client.Set(ctx, key, value, ttl).Err()
+}
+
+// redisQueue adapts the Redis client to turns.Queue
+type redisQueue struct {
+	client *redis.Client
+}
+
+func (q redisQueue) Push(ctx context.Context, list, value string) error {
+	return q.client.LPush(ctx, list, value).Err()
+}
+
+func (q redisQueue) Take(ctx context.Context, from, to string) (string, error) {
+	v, err := q.client.RPopLPush(ctx, from, to).Result()
+	if err == redis.Nil {
+		return "", turns.ErrNotFound
+	}
+	return v, err
+}
+
+func (q redisQueue) Range(ctx context.Context, list string) ([]string, error) {
+	return q.client.LRange(ctx, list, 0, -1).Result()
+}
+
+func (q redisQueue) Remove(ctx context.Context, list, value string) error {
+	return q.client.LRem(ctx, list, 1, value).Err()
+}
+
+// initEngine sets up the engine once Redis is connected
+func initEngine() {
+	engine = turns.New[*Game](turns.NewHybridStore(redisStore{redisClient}), "game:")
+	engine.ActiveTTL = GAME_TTL_ACTIVE * time.Second
+	engine.FinishedTTL = GAME_TTL_COMPLETED * time.Second
+
+	engine.Hooks.OnStart = func(ctx context.Context, game *Game) {
+		go setLobbyPresence(game)
+	}
+
+	engine.Hooks.OnMove = func(ctx context.Context, game *Game, m turns.Move) {
+		// The last move is announced by OnEnd
+		if !m.Outcome.Over {
+			PublishGameEvent(evMoveUpdate.New(game.ID, GameUpdate{Game: game, Message: m.Outcome.Message}))
+		}
+	}
+
+	engine.Hooks.OnEnd = func(ctx context.Context, game *Game, end turns.End) {
+		// Save to PostgreSQL and update stats, after the game's moves
+		if err := writes.Enqueue(ctx, writeGame, game); err != nil {
+			log.Printf("Warning: Failed to save completed game to PostgreSQL: %v", err)
+		}
+		presence.Forget(game.ID)
+		go clearLobbyPresence(game.ID)
+
+		PublishGameEvent(evGameEnded.New(game.ID, GameEnd{Game: game, Message: endMessage(end), Reason: end.Reason}))
+	}
+
+	presence = turns.NewPresence(disconnectGrace, func(gameID, userID string) {
+		// Notify opponent that player disconnected
+		PublishGameEvent(evOpponentDisconnected.New(gameID, OpponentDisconnect{DisconnectedUserID: userID, ClaimWinAfter: int(disconnectGrace.Seconds())}))
+	})
+}
+
+// initWrites starts saving queued writes once the database is connected,
+// first finishing any the last run left in progress
+func initWrites() {
+	writes = turns.NewWriteBehind(redisQueue{redisClient}, "tic-tac-toe")
+	turns.Handle(writes, writeMove, func(ctx context.Context, move Move) error {
+		return SaveMove(&move)
+	})
+	turns.Handle(writes, writeGame, func(ctx context.Context, game Game) error {
+		return SaveCompletedGame(&game)
+	})
+	writes.Start(ctx)
+}
+
+// endMessage is what the players are told when a game ends
+func endMessage(end turns.End) string {
+	switch end.Reason {
+	case turns.ReasonForfeit:
+		return "Opponent forfeited"
+	case turns.ReasonDisconnect:
+		return "You won - opponent disconnected"
+	case turns.ReasonTimeout:
+		return "You won - opponent ran out of time"
+	default:
+		return end.Message
+	}
+}
+
+// sendGameError answers with the status of a move the engine or the rules
+// refused; anything else is a failure to load or save the game.
+func sendGameError(w http.ResponseWriter, err error) {
+	var refused *turns.Error
+	if errors.As(err, &refused) {
+		sendError(w, refused.Message, refused.Code)
+		return
+	}
+	log.Printf("Failed to update game: %v", err)
+	sendError(w, "Failed to update game", 500)
+}
+
+// turns.Game. Player 1 sits in seat 0 and player 2 in seat 1.
+
+func (g *Game) GameID() string {
+	return g.ID
+}
+
+func (g *Game) Seats() []string {
+	return []string{g.Player1ID, g.Player2ID}
+}
+
+func (g *Game) Turn() int {
+	return g.CurrentTurn - 1
+}
+
+func (g *Game) SetTurn(seat int) {
+	g.CurrentTurn = seat + 1
+}
+
+func (g *Game) Over() bool {
+	return g.Status != GameStatusActive
+}
+
+func (g *Game) Finish(winnerID string, at time.Time) {
+	g.Status = GameStatusCompleted
+	g.WinnerID = nil
+	if winnerID != "" {
+		g.WinnerID = &winnerID
+	}
+	completedAt := at.Unix()
+	g.CompletedAt = &completedAt
+}
+
+func (g *Game) Moved(at time.Time) {
+	g.LastMoveAt = at.Unix()
+}
+
+// MoveDeadline implements turns.Timed: in timed mode each move must be made
+// within the limit of the last one
+func (g *Game) MoveDeadline() time.Time {
+	if g.Mode != GameModeTimed {
+		return time.Time{}
+	}
+	limit := g.MoveTimeLimit
+	if limit <= 0 {
+		limit = defaultMoveTimeLimit
+	}
+	return time.Unix(g.LastMoveAt+int64(limit), 0)
+}
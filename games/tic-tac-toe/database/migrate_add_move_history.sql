@@ -0,0 +1,28 @@
+-- Migration: Persist moves for match history and replay
+-- Run against tictactoe_db:
+--   psql -U activityhub -h localhost -p 5555 -d tictactoe_db -f games/tic-tac-toe/database/migrate_add_move_history.sql
+--
+-- The old moves table referenced games(id), which only exists once a series is
+-- complete, and was never written to. It is recreated keyed by the Redis game ID.
+
+DROP TABLE IF EXISTS moves;
+
+CREATE TABLE moves (
+    id SERIAL PRIMARY KEY,
+    game_id VARCHAR(255) NOT NULL,
+    round INTEGER NOT NULL DEFAULT 1,
+    player_id VARCHAR(255) NOT NULL,
+    position INTEGER NOT NULL,
+    symbol VARCHAR(1) NOT NULL,
+    move_number INTEGER NOT NULL,
+    created_at TIMESTAMP(3) DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (game_id, move_number)
+);
+
+CREATE INDEX IF NOT EXISTS idx_moves_game ON moves(game_id);
+CREATE INDEX IF NOT EXISTS idx_moves_player ON moves(player_id);
+
+ALTER TABLE games ADD COLUMN IF NOT EXISTS game_key VARCHAR(255) UNIQUE;
+
+GRANT ALL ON moves TO pubgames;
+GRANT ALL ON SEQUENCE moves_id_seq TO pubgames;
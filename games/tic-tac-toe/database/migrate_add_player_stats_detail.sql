@@ -0,0 +1,102 @@
+-- Migration: Streaks, X/O split, game length and head-to-head stats
+-- Run against tictactoe_db:
+--   psql -U activityhub -h localhost -p 5555 -d tictactoe_db -f games/tic-tac-toe/database/migrate_add_player_stats_detail.sql
+--
+-- Adds the new player_stats columns and the opponent_stats table, then fills
+-- them in from the games already saved. Totals (games_played etc.) are left
+-- as they are; everything added here is rebuilt from the games table, so the
+-- migration can be re-run.
+
+ALTER TABLE player_stats ADD COLUMN IF NOT EXISTS games_as_x INTEGER DEFAULT 0;
+ALTER TABLE player_stats ADD COLUMN IF NOT EXISTS wins_as_x INTEGER DEFAULT 0;
+ALTER TABLE player_stats ADD COLUMN IF NOT EXISTS games_as_o INTEGER DEFAULT 0;
+ALTER TABLE player_stats ADD COLUMN IF NOT EXISTS wins_as_o INTEGER DEFAULT 0;
+ALTER TABLE player_stats ADD COLUMN IF NOT EXISTS total_seconds BIGINT DEFAULT 0;
+ALTER TABLE player_stats ADD COLUMN IF NOT EXISTS current_streak_result VARCHAR(10);
+ALTER TABLE player_stats ADD COLUMN IF NOT EXISTS current_streak INTEGER DEFAULT 0;
+ALTER TABLE player_stats ADD COLUMN IF NOT EXISTS best_win_streak INTEGER DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS opponent_stats (
+    user_id VARCHAR(255) NOT NULL,
+    opponent_id VARCHAR(255) NOT NULL,
+    opponent_name VARCHAR(100) NOT NULL,
+    games_played INTEGER DEFAULT 0,
+    games_won INTEGER DEFAULT 0,
+    games_lost INTEGER DEFAULT 0,
+    games_draw INTEGER DEFAULT 0,
+    last_played TIMESTAMP,
+    PRIMARY KEY (user_id, opponent_id)
+);
+
+BEGIN;
+
+-- Each saved game from both players' side (player 1 is always X)
+CREATE TEMP TABLE results ON COMMIT DROP AS
+SELECT player1_id AS user_id, player2_id AS opponent_id, player2_name AS opponent_name, 'X' AS symbol,
+       CASE WHEN winner_id IS NULL THEN 'draw' WHEN winner_id = player1_id THEN 'win' ELSE 'loss' END AS result,
+       GREATEST(EXTRACT(EPOCH FROM completed_at - created_at), 0)::BIGINT AS seconds,
+       completed_at, id
+FROM games WHERE completed_at IS NOT NULL
+UNION ALL
+SELECT player2_id, player1_id, player1_name, 'O',
+       CASE WHEN winner_id IS NULL THEN 'draw' WHEN winner_id = player2_id THEN 'win' ELSE 'loss' END,
+       GREATEST(EXTRACT(EPOCH FROM completed_at - created_at), 0)::BIGINT,
+       completed_at, id
+FROM games WHERE completed_at IS NOT NULL;
+
+-- Runs of the same result: rows in one run share (result, run)
+CREATE TEMP TABLE runs ON COMMIT DROP AS
+SELECT user_id, result, COUNT(*) AS length, MAX(completed_at) AS ended_at
+FROM (
+    SELECT user_id, result, completed_at,
+           ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY completed_at, id)
+         - ROW_NUMBER() OVER (PARTITION BY user_id, result ORDER BY completed_at, id) AS run
+    FROM results
+) r
+GROUP BY user_id, result, run;
+
+UPDATE player_stats ps SET
+    games_as_x = s.games_as_x,
+    wins_as_x = s.wins_as_x,
+    games_as_o = s.games_as_o,
+    wins_as_o = s.wins_as_o,
+    total_seconds = s.total_seconds
+FROM (
+    SELECT user_id,
+           COUNT(*) FILTER (WHERE symbol = 'X') AS games_as_x,
+           COUNT(*) FILTER (WHERE symbol = 'X' AND result = 'win') AS wins_as_x,
+           COUNT(*) FILTER (WHERE symbol = 'O') AS games_as_o,
+           COUNT(*) FILTER (WHERE symbol = 'O' AND result = 'win') AS wins_as_o,
+           SUM(seconds) AS total_seconds
+    FROM results GROUP BY user_id
+) s
+WHERE ps.user_id = s.user_id;
+
+UPDATE player_stats ps SET
+    current_streak_result = cur.result,
+    current_streak = cur.length,
+    best_win_streak = COALESCE(best.length, 0)
+FROM (
+    SELECT DISTINCT ON (user_id) user_id, result, length
+    FROM runs ORDER BY user_id, ended_at DESC
+) cur
+LEFT JOIN (
+    SELECT user_id, MAX(length) AS length FROM runs WHERE result = 'win' GROUP BY user_id
+) best ON best.user_id = cur.user_id
+WHERE ps.user_id = cur.user_id;
+
+DELETE FROM opponent_stats;
+INSERT INTO opponent_stats (user_id, opponent_id, opponent_name, games_played, games_won, games_lost, games_draw, last_played)
+SELECT user_id, opponent_id,
+       (ARRAY_AGG(opponent_name ORDER BY completed_at DESC))[1],
+       COUNT(*),
+       COUNT(*) FILTER (WHERE result = 'win'),
+       COUNT(*) FILTER (WHERE result = 'loss'),
+       COUNT(*) FILTER (WHERE result = 'draw'),
+       MAX(completed_at)
+FROM results
+GROUP BY user_id, opponent_id;
+
+COMMIT;
+
+GRANT ALL ON opponent_stats TO pubgames;
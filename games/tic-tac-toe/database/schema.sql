@@ -21,18 +21,23 @@ CREATE TABLE IF NOT EXISTS games (
     player2_score INTEGER DEFAULT 0,
     total_rounds INTEGER DEFAULT 0,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    completed_at TIMESTAMP
+    completed_at TIMESTAMP,
+    game_key VARCHAR(255) UNIQUE -- Redis game ID, links to moves
 );
 
 -- Moves table (for game history/replay)
+-- Saved as each move is played, so game_id is the Redis game ID (the games
+-- row only exists once the series is complete)
 CREATE TABLE IF NOT EXISTS moves (
     id SERIAL PRIMARY KEY,
-    game_id INTEGER NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+    game_id VARCHAR(255) NOT NULL,
+    round INTEGER NOT NULL DEFAULT 1,
     player_id VARCHAR(255) NOT NULL,
     position INTEGER NOT NULL,
     symbol VARCHAR(1) NOT NULL,
     move_number INTEGER NOT NULL,
-    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    created_at TIMESTAMP(3) DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (game_id, move_number)
 );
 
 -- Player stats table
@@ -45,11 +50,33 @@ CREATE TABLE IF NOT EXISTS player_stats (
     games_draw INTEGER DEFAULT 0,
     total_moves INTEGER DEFAULT 0,
     fastest_win_moves INTEGER,
+    games_as_x INTEGER DEFAULT 0,
+    wins_as_x INTEGER DEFAULT 0,
+    games_as_o INTEGER DEFAULT 0,
+    wins_as_o INTEGER DEFAULT 0,
+    total_seconds BIGINT DEFAULT 0,
+    current_streak_result VARCHAR(10), -- win, loss or draw
+    current_streak INTEGER DEFAULT 0,
+    best_win_streak INTEGER DEFAULT 0,
     last_played TIMESTAMP,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
+-- Head-to-head records, one row per player per opponent
+-- Updated with player_stats when a game is saved
+CREATE TABLE IF NOT EXISTS opponent_stats (
+    user_id VARCHAR(255) NOT NULL,
+    opponent_id VARCHAR(255) NOT NULL,
+    opponent_name VARCHAR(100) NOT NULL,
+    games_played INTEGER DEFAULT 0,
+    games_won INTEGER DEFAULT 0,
+    games_lost INTEGER DEFAULT 0,
+    games_draw INTEGER DEFAULT 0,
+    last_played TIMESTAMP,
+    PRIMARY KEY (user_id, opponent_id)
+);
+
 -- Indexes
 CREATE INDEX IF NOT EXISTS idx_games_challenge ON games(challenge_id);
 CREATE INDEX IF NOT EXISTS idx_games_player1 ON games(player1_id);
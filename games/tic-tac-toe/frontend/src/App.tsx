@@ -9,7 +9,7 @@ function useQueryParams() {
       gameId: params.get('gameId'),
       userId: params.get('userId'),
       userName: params.get('userName') || params.get('userId') || 'Player',
-      token: params.get('token'),
+      token: params.get('token') || sessionStorage.getItem('token'),
     };
   }, []);
 }
@@ -1,5 +1,20 @@
 import { useState, useEffect, useRef, useCallback } from 'react';
 
+// Single-use stream token from the shell, so the session token never goes in
+// the EventSource URL. Empty on failure: the stream then rejects the connection
+// and the normal reconnect path retries.
+async function fetchStreamToken(token: string): Promise<string> {
+  try {
+    const res = await fetch(`http://${window.location.hostname}:3001/api/auth/stream-token`, {
+      method: 'POST',
+      headers: { 'Authorization': `Bearer ${token}` },
+    });
+    return res.ok ? (await res.json()).token : '';
+  } catch {
+    return '';
+  }
+}
+
 // Game types matching backend
 export interface Game {
   id: string;
@@ -27,6 +42,8 @@ export interface Game {
 
 interface SSEEvent {
   type: string;
+  version: number; // Payload schema version; see GET /api/events/schema
+  session?: string;
   payload?: any;
 }
 
@@ -92,7 +109,7 @@ export function useGameSocket(gameId: string | null, userId: string, token: stri
     setClaimWinCountdown(null);
   }, []);
 
-  const connect = useCallback(() => {
+  const connect = useCallback(async () => {
     if (!gameId || !userId) return;
 
     // Close existing connection if any
@@ -108,9 +125,10 @@ export function useGameSocket(gameId: string | null, userId: string, token: stri
     console.log('[SSE] Connecting to:', sseUrl, `(attempt ${retryCountRef.current + 1})`);
     setConnectionStatus(retryCountRef.current > 0 ? 'reconnecting' : 'connecting');
 
-    // EventSource doesn't support custom headers, so we include token in URL temporarily
-    // Note: This is less secure but EventSource API limitation
-    const sseUrlWithAuth = `${sseUrl}?token=${encodeURIComponent(token)}`;
+    // EventSource doesn't support custom headers, so the URL carries a
+    // single-use stream token rather than the session token
+    const streamToken = await fetchStreamToken(token);
+    const sseUrlWithAuth = `${sseUrl}?token=${encodeURIComponent(streamToken)}`;
     const eventSource = new EventSource(sseUrlWithAuth);
     eventSourceRef.current = eventSource;
 
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/achgithub/activity-hub-common/activity"
+	"github.com/go-redis/redis/v8"
+)
+
+// Activity feed: game backends publish events to activity.Channel and the shell
+// keeps the most recent ones in a sorted set scored by time, for the home page
+// feed and display widgets. Storing the raw message makes the write idempotent,
+// so several shell instances can aggregate side by side.
+const activityFeedKey = "activity:feed:recent"
+
+// Events kept for paging back through the feed
+const activityFeedMax = 500
+
+const (
+	defaultActivityLimit = 20
+	maxActivityLimit     = 100
+)
+
+// runActivityAggregator stores every published activity event. Runs for the
+// life of the process; the subscription reconnects on its own after Redis blips.
+func runActivityAggregator() {
+	pubsub := redisClient.Subscribe(ctx, activity.Channel)
+	defer pubsub.Close()
+
+	for msg := range pubsub.Channel() {
+		ev, err := activity.Decode(msg.Payload)
+		if err != nil {
+			log.Printf("⚠️  Ignoring activity event: %v", err)
+			continue
+		}
+		pipe := redisClient.TxPipeline()
+		pipe.ZAdd(ctx, activityFeedKey, &redis.Z{Score: float64(ev.CreatedAt.UnixMilli()), Member: msg.Payload})
+		pipe.ZRemRangeByRank(ctx, activityFeedKey, 0, -activityFeedMax-1)
+		if _, err := pipe.Exec(ctx); err != nil {
+			log.Printf("⚠️  Failed to store activity event %s: %v", ev.ID, err)
+		}
+	}
+}
+
+// publishActivity sends an event to the feed. Best effort: a lost feed line
+// never fails the action behind it.
+func publishActivity(ev activity.Event) {
+	msg, err := activity.Encode(ev)
+	if err != nil {
+		log.Printf("⚠️  Invalid activity event: %v", err)
+		return
+	}
+	if err := redisClient.Publish(ctx, activity.Channel, msg).Err(); err != nil {
+		log.Printf("⚠️  Failed to publish activity event: %v", err)
+	}
+}
+
+// appName is the registry name for an app, falling back to its ID.
+func appName(appID string) string {
+	if app := GetAppByID(appID); app != nil {
+		return app.Name
+	}
+	return appID
+}
+
+// activityVisible reports whether an event belongs in a venue's feed.
+// Events without a venue are shown everywhere; venue 0 means all venues.
+func activityVisible(ev activity.Event, venueID int) bool {
+	return venueID == 0 || ev.VenueID == 0 || ev.VenueID == venueID
+}
+
+// handleGetActivity - GET /api/activity?limit={n}&before={RFC3339}&venue={id}
+// Most recent events first. Page back by passing the last event's createdAt as before.
+func handleGetActivity(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	limit := defaultActivityLimit
+	if n, err := strconv.Atoi(q.Get("limit")); err == nil && n > 0 {
+		limit = n
+	}
+	if limit > maxActivityLimit {
+		limit = maxActivityLimit
+	}
+
+	max := "+inf"
+	if before := q.Get("before"); before != "" {
+		t, err := time.Parse(time.RFC3339Nano, before)
+		if err != nil {
+			http.Error(w, "Invalid before timestamp", http.StatusBadRequest)
+			return
+		}
+		max = fmt.Sprintf("(%d", t.UnixMilli())
+	}
+	venueID, _ := strconv.Atoi(q.Get("venue"))
+
+	// Venue filtering happens after the fetch, so read in batches until the page is full
+	events := []activity.Event{}
+	hasMore := false
+	for offset := int64(0); ; offset += maxActivityLimit {
+		msgs, err := redisClient.ZRevRangeByScore(ctx, activityFeedKey, &redis.ZRangeBy{
+			Min: "-inf", Max: max, Offset: offset, Count: maxActivityLimit,
+		}).Result()
+		if err != nil {
+			log.Printf("⚠️  Failed to read activity feed: %v", err)
+			http.Error(w, "Activity feed unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		for _, msg := range msgs {
+			ev, err := activity.Decode(msg)
+			if err != nil || !activityVisible(ev, venueID) {
+				continue
+			}
+			if len(events) == limit {
+				hasMore = true
+				break
+			}
+			events = append(events, ev)
+		}
+		if hasMore || len(msgs) < maxActivityLimit {
+			break
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"events":  events,
+		"hasMore": hasMore,
+	})
+}
+
+// handleActivityStream - GET /api/activity/stream?venue={id}
+// Server-Sent Events: each new activity event as it is published. Public, like
+// the feed itself, so TV displays can subscribe without a user token.
+func handleActivityStream(w http.ResponseWriter, r *http.Request) {
+	venueID, _ := strconv.Atoi(r.URL.Query().Get("venue"))
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	sub, err := sseBroker.Subscribe(r.Context(), "activity", activity.Channel)
+	if err != nil {
+		log.Printf("⚠️  Failed to open activity stream: %v", err)
+		http.Error(w, "Activity feed unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+
+	fmt.Fprintf(w, "data: {\"type\":\"connected\"}\n\n")
+	flusher.Flush()
+
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case msg := <-sub.C:
+			ev, err := activity.Decode(msg)
+			if err != nil || !activityVisible(ev, venueID) {
+				continue
+			}
+			fmt.Fprintf(w, "data: %s\n\n", msg)
+			flusher.Flush()
+
+		case <-ticker.C:
+			fmt.Fprintf(w, ": ping\n\n")
+			flusher.Flush()
+
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
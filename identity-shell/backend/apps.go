@@ -5,6 +5,10 @@ import (
 	"log"
 	"sync"
 
+	authlib "github.com/achgithub/activity-hub-common/auth"
+	apphttp "github.com/achgithub/activity-hub-common/http"
+	"github.com/achgithub/activity-hub-common/maintenance"
+	"github.com/achgithub/activity-hub-common/services"
 	"github.com/lib/pq"
 )
 
@@ -25,6 +29,10 @@ type AppDefinition struct {
 	Enabled         bool     `json:"enabled"`
 	DisplayOrder    int      `json:"displayOrder"`
 	GuestAccessible bool     `json:"guestAccessible,omitempty"`
+	VenueIDs        []int64  `json:"venueIds,omitempty"` // empty = offered at all venues
+
+	Maintenance  *maintenance.Notice   `json:"maintenance,omitempty"`  // Only sent to admins; players don't see the app
+	Capabilities *apphttp.Capabilities `json:"capabilities,omitempty"` // From the app's /api/config, once it has answered
 }
 
 // AppRegistry holds the loaded apps configuration
@@ -35,6 +43,14 @@ type AppRegistry struct {
 
 var appRegistry = &AppRegistry{}
 
+// appMaintenance is which apps setup admins have put into maintenance. The
+// apps' backends turn players away themselves; the shell hides them.
+var appMaintenance *maintenance.Set
+
+// backends calls the apps' backends (creating games for challenges,
+// anonymising leaderboard history), found through the same registry
+var backends *services.Registry
+
 // LoadAppRegistry loads apps from database
 func LoadAppRegistry() error {
 	appRegistry.mu.Lock()
@@ -46,7 +62,7 @@ func LoadAppRegistry() error {
 		       COALESCE(url, ''), COALESCE(backend_port, 0), COALESCE(realtime, 'none'),
 		       min_players, max_players,
 		       COALESCE(required_roles, '{}'), enabled, display_order,
-		       COALESCE(guest_accessible, FALSE)
+		       COALESCE(guest_accessible, FALSE), COALESCE(venue_ids, '{}')
 		FROM applications
 		WHERE enabled = TRUE
 		ORDER BY display_order, name
@@ -60,6 +76,7 @@ func LoadAppRegistry() error {
 	for rows.Next() {
 		var app AppDefinition
 		var requiredRoles pq.StringArray
+		var venueIDs pq.Int64Array
 		var minPlayers, maxPlayers sql.NullInt64
 
 		err := rows.Scan(
@@ -67,7 +84,7 @@ func LoadAppRegistry() error {
 			&app.URL, &app.BackendPort, &app.Realtime,
 			&minPlayers, &maxPlayers,
 			&requiredRoles, &app.Enabled, &app.DisplayOrder,
-			&app.GuestAccessible,
+			&app.GuestAccessible, &venueIDs,
 		)
 		if err != nil {
 			return err
@@ -84,6 +101,7 @@ func LoadAppRegistry() error {
 		}
 
 		app.RequiredRoles = requiredRoles
+		app.VenueIDs = venueIDs
 		apps = append(apps, app)
 	}
 
@@ -97,8 +115,19 @@ func LoadAppRegistry() error {
 }
 
 // ReloadAppRegistry reloads apps from database (useful after admin updates)
+// The shell's CORS policy and backend client follow the registry ports, so they are reloaded too.
 func ReloadAppRegistry() error {
-	return LoadAppRegistry()
+	if err := corsPolicy.Reload(); err != nil {
+		log.Printf("⚠️  Failed to reload CORS policy: %v", err)
+	}
+	if err := backends.Reload(); err != nil {
+		log.Printf("⚠️  Failed to reload backend registry: %v", err)
+	}
+	if err := LoadAppRegistry(); err != nil {
+		return err
+	}
+	go refreshCapabilities()
+	return nil
 }
 
 // GetAppByID returns an app definition by ID, or nil if not found
@@ -125,13 +154,19 @@ func GetAllApps() []AppDefinition {
 // GetAppsForUser returns apps visible to a user based on their roles or guest status
 // If isGuest is true, only returns apps with guest_accessible = true
 // Otherwise, returns apps based on role requirements
-func GetAppsForUser(userRoles []string, isGuest bool) []AppDefinition {
+// venueID restricts to apps offered at that venue (0 = no venue filter)
+func GetAppsForUser(userRoles []string, isGuest bool, venueID int) []AppDefinition {
 	appRegistry.mu.RLock()
 	defer appRegistry.mu.RUnlock()
 
 	var visibleApps []AppDefinition
 
 	for _, app := range appRegistry.Apps {
+		// Venue restriction applies to everyone, guests included
+		if !app.offeredAtVenue(venueID) {
+			continue
+		}
+
 		// Guest mode: only show guest-accessible apps
 		if isGuest {
 			if app.GuestAccessible {
@@ -156,6 +191,37 @@ func GetAppsForUser(userRoles []string, isGuest bool) []AppDefinition {
 	return visibleApps
 }
 
+// withMaintenance hides apps in maintenance from players, and flags them for
+// admins (maintenance.Exempt), who can still open them
+func withMaintenance(apps []AppDefinition, user *authlib.AuthUser) []AppDefinition {
+	exempt := maintenance.Exempt(user)
+	shown := apps[:0:0]
+	for _, app := range apps {
+		if notice, ok := appMaintenance.Get(app.ID); ok {
+			if !exempt {
+				continue
+			}
+			app.Maintenance = &notice
+		}
+		shown = append(shown, app)
+	}
+	return shown
+}
+
+// offeredAtVenue reports whether the app is available at a venue
+// Apps with no venue list are offered everywhere
+func (app AppDefinition) offeredAtVenue(venueID int) bool {
+	if venueID == 0 || len(app.VenueIDs) == 0 {
+		return true
+	}
+	for _, id := range app.VenueIDs {
+		if int(id) == venueID {
+			return true
+		}
+	}
+	return false
+}
+
 // hasAnyRole checks if user has any of the required roles
 func hasAnyRole(userRoles, requiredRoles []string) bool {
 	for _, required := range requiredRoles {
@@ -168,11 +234,15 @@ func hasAnyRole(userRoles, requiredRoles []string) bool {
 	return false
 }
 
-// IsGameApp checks if an app is a game that can be challenged
+// IsGameApp checks if an app is a game that can be challenged. Apps that
+// declare capabilities decide for themselves; the rest go by category.
 func IsGameApp(appID string) bool {
 	app := GetAppByID(appID)
-	if app == nil {
+	if app == nil || app.BackendPort == 0 {
 		return false
 	}
-	return app.Category == "game" && app.BackendPort > 0
+	if caps, ok := capabilitiesFor(appID); ok {
+		return caps.Challenges
+	}
+	return app.Category == "game"
 }
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/achgithub/activity-hub-common/bus"
+	"github.com/achgithub/activity-hub-common/events"
+	redislib "github.com/achgithub/activity-hub-common/redis"
+	"github.com/lib/pq"
+	redisv9 "github.com/redis/go-redis/v9"
+)
+
+// Each step of a challenge (issued, accepted, started, declined) goes on the
+// challenges stream of the event bus. The shell's own consumer group writes
+// them to the challenges table behind suggestions and the digest, and picks
+// up from the last step it recorded after a restart. Other services can
+// follow challenges with groups of their own. Players are still told over
+// their pub/sub channels as before; those only matter while they're connected.
+
+// busClient is the shared library's Redis client, separate from the shell's
+// own. nil when unavailable, in which case steps are recorded straight away.
+var busClient *redisv9.Client
+
+func initBus(ctx context.Context) {
+	client, err := redislib.InitRedis()
+	if err != nil {
+		log.Printf("⚠️  Event bus unavailable, challenge history is written directly: %v", err)
+		return
+	}
+	busClient = client
+	go bus.NewConsumer(client, "identity-shell", handleChallengeEvent, bus.StreamChallenges).Run(ctx)
+}
+
+// publishChallengeStep puts a challenge step on the bus, or records it if the
+// bus can't take it. Failures are logged only: Redis is the source of truth
+// for active challenges.
+func publishChallengeStep(step events.Type[bus.ChallengeEvent], ev bus.ChallengeEvent) {
+	ev.At = time.Now()
+	e := step.New(ev.ChallengeID, ev)
+
+	if busClient != nil {
+		_, err := bus.Publish(ctx, busClient, bus.StreamChallenges, e)
+		if err == nil {
+			return
+		}
+		log.Printf("⚠️  %v", err)
+	}
+	if err := recordChallengeStep(e); err != nil {
+		log.Printf("Failed to save challenge %s to database: %v", ev.ChallengeID, err)
+	}
+}
+
+func handleChallengeEvent(ctx context.Context, msg bus.Message) error {
+	return recordChallengeStep(msg.Event)
+}
+
+// recordChallengeStep writes one step to the challenges table. Every write is
+// safe to repeat, as the bus delivers at least once.
+func recordChallengeStep(e events.Envelope) error {
+	var step events.Type[bus.ChallengeEvent]
+	var status string
+	switch e.Type {
+	case bus.ChallengeIssued.Name:
+		return recordChallengeIssued(e)
+	case bus.ChallengeAccepted.Name:
+		step, status = bus.ChallengeAccepted, "accepted"
+	case bus.ChallengeStarted.Name:
+		step, status = bus.ChallengeStarted, "active"
+	case bus.ChallengeDeclined.Name:
+		step, status = bus.ChallengeDeclined, "rejected"
+	default:
+		return nil
+	}
+
+	ev, err := step.Payload(e)
+	if err != nil {
+		log.Printf("⚠️  Ignoring challenge event %s: %v", e.Type, err)
+		return nil
+	}
+
+	var accepted interface{}
+	if status == "active" {
+		accepted = pq.Array(ev.Players)
+	}
+	_, err = db.Exec(`
+		UPDATE challenges
+		SET status = $2, responded_at = $3, decline_reason = NULLIF($4, ''), accepted = COALESCE($5, accepted)
+		WHERE id = $1
+	`, ev.ChallengeID, status, ev.At, ev.Reason, accepted)
+	return err
+}
+
+// recordChallengeIssued adds a new challenge unless it's already there
+func recordChallengeIssued(e events.Envelope) error {
+	ev, err := bus.ChallengeIssued.Payload(e)
+	if err != nil {
+		log.Printf("⚠️  Ignoring challenge event %s: %v", e.Type, err)
+		return nil
+	}
+
+	if ev.MinPlayers > 0 {
+		_, err = db.Exec(`
+			INSERT INTO challenges (id, initiator_id, player_ids, app_id, status, min_players, max_players, expires_at)
+			SELECT $1, $2, $3, $4, 'pending', $5, $6, $7
+			WHERE NOT EXISTS (SELECT 1 FROM challenges WHERE id = $1)
+		`, ev.ChallengeID, ev.From, pq.Array(ev.Players), ev.AppID, ev.MinPlayers, ev.MaxPlayers, ev.ExpiresAt)
+		return err
+	}
+
+	var toUser string
+	if len(ev.Players) > 0 {
+		toUser = ev.Players[0]
+	}
+	_, err = db.Exec(`
+		INSERT INTO challenges (id, from_user, to_user, app_id, status, expires_at)
+		SELECT $1, $2, $3, $4, 'pending', $5
+		WHERE NOT EXISTS (SELECT 1 FROM challenges WHERE id = $1)
+	`, ev.ChallengeID, ev.From, toUser, ev.AppID, ev.ExpiresAt)
+	return err
+}
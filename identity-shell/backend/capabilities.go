@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	apphttp "github.com/achgithub/activity-hub-common/http"
+)
+
+// capabilityRefreshInterval is how often the apps' /api/config is re-read, so
+// a redeployed app's new capabilities show up without restarting the shell
+const capabilityRefreshInterval = 5 * time.Minute
+
+// capabilityFetchTimeout bounds each config request, like the health probe
+const capabilityFetchTimeout = 2 * time.Second
+
+// appCapabilities caches what each app's /api/config says it supports. Apps
+// that haven't answered yet are missing, and the frontend falls back to the
+// registry's realtime and category columns for them.
+var appCapabilities = struct {
+	sync.RWMutex
+	byApp map[string]apphttp.Capabilities
+}{byApp: map[string]apphttp.Capabilities{}}
+
+// runCapabilityRefresh reads the apps' capabilities now and every interval
+func runCapabilityRefresh() {
+	refreshCapabilities()
+	ticker := time.NewTicker(capabilityRefreshInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		refreshCapabilities()
+	}
+}
+
+// refreshCapabilities fetches /api/config from each enabled app backend
+// concurrently. An app that is down keeps its last known capabilities.
+func refreshCapabilities() {
+	client := &http.Client{Timeout: capabilityFetchTimeout}
+
+	var wg sync.WaitGroup
+	for _, app := range GetAllApps() {
+		if app.BackendPort == 0 || !app.Enabled {
+			continue
+		}
+
+		wg.Add(1)
+		go func(appID string, port int) {
+			defer wg.Done()
+
+			caps, err := fetchCapabilities(client, port)
+			if err != nil {
+				log.Printf("⚠️  Failed to read %s capabilities: %v", appID, err)
+				return
+			}
+			appCapabilities.Lock()
+			appCapabilities.byApp[appID] = *caps
+			appCapabilities.Unlock()
+		}(app.ID, app.BackendPort)
+	}
+	wg.Wait()
+}
+
+// fetchCapabilities reads one backend's config. Apps that don't declare
+// capabilities yet return an error, so they aren't treated as supporting nothing.
+func fetchCapabilities(client *http.Client, port int) (*apphttp.Capabilities, error) {
+	resp, err := client.Get(fmt.Sprintf("http://127.0.0.1:%d/api/config", port))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+
+	var config struct {
+		Capabilities *apphttp.Capabilities `json:"capabilities"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&config); err != nil {
+		return nil, err
+	}
+	if config.Capabilities == nil {
+		return nil, fmt.Errorf("no capabilities in /api/config")
+	}
+	return config.Capabilities, nil
+}
+
+// capabilitiesFor returns an app's cached capabilities
+func capabilitiesFor(appID string) (apphttp.Capabilities, bool) {
+	appCapabilities.RLock()
+	defer appCapabilities.RUnlock()
+	caps, ok := appCapabilities.byApp[appID]
+	return caps, ok
+}
+
+// withCapabilities adds the cached capabilities to each app
+func withCapabilities(apps []AppDefinition) []AppDefinition {
+	for i := range apps {
+		if caps, ok := capabilitiesFor(apps[i].ID); ok {
+			apps[i].Capabilities = &caps
+		}
+	}
+	return apps
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	apphttp "github.com/achgithub/activity-hub-common/http"
+)
+
+// corsPolicy is the shell's own copy of the shared CORS policy. Other backends
+// load theirs from the same tables and refresh within apphttp.CORSRefreshInterval.
+var corsPolicy *apphttp.CORSPolicy
+
+var hostnamePattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?(\.[a-z0-9]([a-z0-9-]*[a-z0-9])?)*$`)
+
+// normalizeCORSHost accepts a bare hostname or IP address
+func normalizeCORSHost(host string) (string, error) {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if net.ParseIP(host) != nil || hostnamePattern.MatchString(host) {
+		return host, nil
+	}
+	return "", fmt.Errorf("invalid host %q: use a hostname or IP address without scheme or port", host)
+}
+
+// normalizeCORSOrigin accepts scheme://host[:port] with nothing after it
+func normalizeCORSOrigin(origin string) (string, error) {
+	u, err := url.Parse(strings.ToLower(strings.TrimSpace(origin)))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" ||
+		strings.TrimSuffix(u.Path, "/") != "" || u.RawQuery != "" || u.User != nil {
+		return "", fmt.Errorf("invalid origin %q: use scheme://host[:port]", origin)
+	}
+	return u.Scheme + "://" + u.Host, nil
+}
+
+func loadCORSList(query, env string) ([]string, error) {
+	rows, err := db.Query(query, env)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []string{}
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		list = append(list, v)
+	}
+	return list, rows.Err()
+}
+
+// handleAdminGetCORS - GET /api/admin/cors
+// The CORS policy for this environment: configured hosts and extra origins,
+// plus the registry ports they combine with.
+func handleAdminGetCORS(w http.ResponseWriter, r *http.Request) {
+	env := apphttp.Environment()
+
+	hosts, err := loadCORSList(`SELECT host FROM cors_hosts WHERE environment = $1 ORDER BY host`, env)
+	if err != nil {
+		log.Printf("Error loading CORS hosts: %v", err)
+		http.Error(w, "Failed to load CORS policy", http.StatusInternalServerError)
+		return
+	}
+	origins, err := loadCORSList(`SELECT origin FROM cors_origins WHERE environment = $1 ORDER BY origin`, env)
+	if err != nil {
+		log.Printf("Error loading CORS origins: %v", err)
+		http.Error(w, "Failed to load CORS policy", http.StatusInternalServerError)
+		return
+	}
+	ports, err := apphttp.RegistryPorts(db)
+	if err != nil {
+		log.Printf("Error loading registry ports: %v", err)
+		http.Error(w, "Failed to load CORS policy", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"environment": env,
+		"hosts":       hosts,
+		"origins":     origins,
+		"ports":       ports,
+	})
+}
+
+// handleAdminUpdateCORS - PUT /api/admin/cors {"hosts": [...], "origins": [...]}
+// Replaces this environment's hosts and extra origins. The shell applies the
+// change at once; other backends on their next refresh.
+func handleAdminUpdateCORS(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Hosts   []string `json:"hosts"`
+		Origins []string `json:"origins"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	hosts := make([]string, 0, len(req.Hosts))
+	for _, h := range req.Hosts {
+		host, err := normalizeCORSHost(h)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		hosts = append(hosts, host)
+	}
+	origins := make([]string, 0, len(req.Origins))
+	for _, o := range req.Origins {
+		origin, err := normalizeCORSOrigin(o)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		origins = append(origins, origin)
+	}
+
+	env := apphttp.Environment()
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("Error starting CORS update: %v", err)
+		http.Error(w, "Failed to update CORS policy", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	for _, table := range []string{"cors_hosts", "cors_origins"} {
+		if _, err := tx.Exec(`DELETE FROM `+table+` WHERE environment = $1`, env); err != nil {
+			log.Printf("Error clearing %s: %v", table, err)
+			http.Error(w, "Failed to update CORS policy", http.StatusInternalServerError)
+			return
+		}
+	}
+	for _, host := range hosts {
+		if _, err := tx.Exec(`INSERT INTO cors_hosts (environment, host) VALUES ($1, $2) ON CONFLICT DO NOTHING`, env, host); err != nil {
+			log.Printf("Error saving CORS host: %v", err)
+			http.Error(w, "Failed to update CORS policy", http.StatusInternalServerError)
+			return
+		}
+	}
+	for _, origin := range origins {
+		if _, err := tx.Exec(`INSERT INTO cors_origins (environment, origin) VALUES ($1, $2) ON CONFLICT DO NOTHING`, env, origin); err != nil {
+			log.Printf("Error saving CORS origin: %v", err)
+			http.Error(w, "Failed to update CORS policy", http.StatusInternalServerError)
+			return
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing CORS update: %v", err)
+		http.Error(w, "Failed to update CORS policy", http.StatusInternalServerError)
+		return
+	}
+
+	if err := corsPolicy.Reload(); err != nil {
+		log.Printf("⚠️  Failed to reload CORS policy: %v", err)
+	}
+	log.Printf("🌐 CORS policy updated for %s: %d hosts, %d origins", env, len(hosts), len(origins))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"hosts":   hosts,
+		"origins": origins,
+	})
+}
@@ -0,0 +1,384 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/achgithub/activity-hub-common/jobs"
+	"github.com/achgithub/activity-hub-common/mailer"
+	redislib "github.com/achgithub/activity-hub-common/redis"
+	"github.com/achgithub/activity-hub-common/services"
+	"github.com/lib/pq"
+)
+
+// ============================================================
+// Email digest
+// ============================================================
+//
+// Players who opt in (digest_frequency "daily" or "weekly" in their settings)
+// get an email of the challenges they missed and what's coming up: LMS picks
+// due and scheduled quiz nights. The apps answer POST /api/digest for their
+// part (services.Registry.FetchDigest). notification_level "none" stops the
+// digest, and "challenges" keeps only the missed challenges.
+
+// digestApps are the apps asked for upcoming items
+var digestApps = []string{"last-man-standing", "quiz-player"}
+
+const (
+	digestHour       = 8           // When the job runs, server time
+	digestWeekday    = time.Monday // When weekly digests go out
+	digestBatchSize  = 200         // Players per call to the apps
+	digestSentTTL    = 36 * time.Hour
+	digestAppTimeout = 30 * time.Second
+)
+
+// digestScheduler runs the digest job; nil when Redis is unavailable
+var digestScheduler *jobs.Scheduler
+
+// initDigest registers the digest job. The job runner needs the shared
+// library's Redis client, separate from the shell's own.
+func initDigest(ctx context.Context) {
+	client, err := redislib.InitRedis()
+	if err != nil {
+		log.Printf("⚠️  Email digest disabled: %v", err)
+		return
+	}
+	digestScheduler = jobs.New(client, "identity-shell")
+	digestScheduler.RegisterWithTimeout("email-digest", jobs.DailyAt(digestHour, 0), 30*time.Minute, sendDigests)
+	go digestScheduler.Start(ctx)
+
+	if !mailer.FromEnv().Enabled() {
+		log.Println("⚠️  SMTP_HOST not set: email digests will be logged, not sent")
+	}
+}
+
+// digestRecipient is a player due a digest in this run
+type digestRecipient struct {
+	Email     string
+	Name      string
+	VenueID   int
+	Frequency string // daily | weekly
+	Level     string // notification_level: all | challenges
+}
+
+// missedChallenge is a challenge that expired without an answer
+type missedChallenge struct {
+	From  string
+	App   string
+	Multi bool
+	At    time.Time
+}
+
+// digest is one player's email
+type digest struct {
+	Recipient digestRecipient
+	Missed    []missedChallenge
+	Upcoming  []services.DigestItem
+}
+
+func (d digest) empty() bool {
+	return len(d.Missed) == 0 && len(d.Upcoming) == 0
+}
+
+// sendDigests is the daily job: it emails everyone due a digest today
+func sendDigests(ctx context.Context) error {
+	now := time.Now()
+	frequencies := []string{"daily"}
+	if now.Weekday() == digestWeekday {
+		frequencies = append(frequencies, "weekly")
+	}
+
+	recipients, err := loadDigestRecipients(frequencies)
+	if err != nil {
+		return fmt.Errorf("failed to load digest recipients: %w", err)
+	}
+
+	m := mailer.FromEnv()
+	sent, failed := 0, 0
+	for _, frequency := range frequencies {
+		period := 24 * time.Hour
+		if frequency == "weekly" {
+			period = 7 * 24 * time.Hour
+		}
+
+		var group []digestRecipient
+		for _, r := range recipients {
+			if r.Frequency == frequency {
+				group = append(group, r)
+			}
+		}
+
+		for start := 0; start < len(group); start += digestBatchSize {
+			end := start + digestBatchSize
+			if end > len(group) {
+				end = len(group)
+			}
+			for _, d := range buildDigests(ctx, group[start:end], now.Add(-period), now, now.Add(period)) {
+				if d.empty() || !claimDigest(d.Recipient.Email, now) {
+					continue
+				}
+				if err := m.Send(ctx, renderDigest(d, frequency)); err != nil {
+					log.Printf("❌ Failed to send digest to %s: %v", d.Recipient.Email, err)
+					releaseDigest(d.Recipient.Email, now)
+					failed++
+					continue
+				}
+				sent++
+			}
+		}
+	}
+
+	log.Printf("📧 Email digest: %d sent, %d failed, %d opted in", sent, failed, len(recipients))
+	if failed > 0 {
+		return fmt.Errorf("%d digests failed to send", failed)
+	}
+	return nil
+}
+
+// loadDigestRecipients returns active players who chose one of the
+// frequencies and haven't turned notifications off. Table devices and guests
+// have no settings, so they never appear.
+func loadDigestRecipients(frequencies []string) ([]digestRecipient, error) {
+	rows, err := db.Query(`
+		SELECT u.email, COALESCE(u.name, ''), COALESCE(u.venue_id, 0), f.value #>> '{}',
+		       COALESCE(l.value #>> '{}', 'all')
+		FROM users u
+		JOIN user_settings f ON f.user_email = u.email AND f.key = 'digest_frequency'
+		LEFT JOIN user_settings l ON l.user_email = u.email AND l.key = 'notification_level'
+		WHERE COALESCE(u.is_active, TRUE) AND f.value #>> '{}' = ANY($1)
+		  AND COALESCE(l.value #>> '{}', 'all') <> 'none'
+		ORDER BY u.email
+	`, pq.Array(frequencies))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var recipients []digestRecipient
+	for rows.Next() {
+		var r digestRecipient
+		if err := rows.Scan(&r.Email, &r.Name, &r.VenueID, &r.Frequency, &r.Level); err != nil {
+			return nil, err
+		}
+		if authlib.IsTableEmail(r.Email) {
+			continue
+		}
+		recipients = append(recipients, r)
+	}
+	return recipients, rows.Err()
+}
+
+// buildDigests gathers the missed challenges between since and now and the
+// apps' items up to until for a batch of recipients
+func buildDigests(ctx context.Context, recipients []digestRecipient, since, now, until time.Time) []digest {
+	digests := make([]digest, len(recipients))
+	emails := make([]string, len(recipients))
+	for i, r := range recipients {
+		digests[i].Recipient = r
+		emails[i] = r.Email
+	}
+
+	missed, err := loadMissedChallenges(emails, since, now)
+	if err != nil {
+		log.Printf("⚠️  Failed to load missed challenges for digest: %v", err)
+	}
+
+	// Upcoming items only go to players who want more than challenges
+	var upcomingFor []string
+	for _, r := range recipients {
+		if r.Level == "all" {
+			upcomingFor = append(upcomingFor, r.Email)
+		}
+	}
+	upcoming := map[string][]services.DigestItem{}
+	var everyone []services.DigestItem
+	if len(upcomingFor) > 0 {
+		for _, appID := range digestApps {
+			appCtx, cancel := context.WithTimeout(ctx, digestAppTimeout)
+			d, err := backends.FetchDigest(appCtx, appID, services.DigestRequest{Emails: upcomingFor, Until: until})
+			cancel()
+			if err != nil {
+				log.Printf("⚠️  Failed to fetch %s digest items: %v", appID, err)
+				continue
+			}
+			for email, items := range d.Items {
+				upcoming[email] = append(upcoming[email], items...)
+			}
+			everyone = append(everyone, d.Everyone...)
+		}
+	}
+
+	for i := range digests {
+		r := digests[i].Recipient
+		digests[i].Missed = missed[r.Email]
+		if r.Level != "all" {
+			continue
+		}
+		items := append([]services.DigestItem{}, upcoming[r.Email]...)
+		for _, item := range everyone {
+			if item.VenueID == 0 || item.VenueID == r.VenueID {
+				items = append(items, item)
+			}
+		}
+		sort.SliceStable(items, func(a, b int) bool { return items[a].At.Before(items[b].At) })
+		digests[i].Upcoming = items
+	}
+	return digests
+}
+
+// loadMissedChallenges returns challenges to the players that expired between
+// since and now while still pending. A multi-player challenge counts for
+// every invited player but the initiator.
+func loadMissedChallenges(emails []string, since, now time.Time) (map[string][]missedChallenge, error) {
+	rows, err := db.Query(`
+		SELECT recipient, sender, app_id, multi, expires_at
+		FROM (
+			SELECT to_user AS recipient, from_user AS sender, app_id, FALSE AS multi, expires_at, status
+			FROM challenges WHERE to_user = ANY($1)
+			UNION ALL
+			SELECT p, initiator_id, app_id, TRUE, expires_at, status
+			FROM challenges, unnest(player_ids) p
+			WHERE p = ANY($1) AND p <> initiator_id
+		) c
+		WHERE status = 'pending' AND expires_at > $2 AND expires_at <= $3
+		ORDER BY expires_at
+	`, pq.Array(emails), since, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	missed := map[string][]missedChallenge{}
+	var senders []string
+	for rows.Next() {
+		var recipient string
+		var c missedChallenge
+		if err := rows.Scan(&recipient, &c.From, &c.App, &c.Multi, &c.At); err != nil {
+			return nil, err
+		}
+		missed[recipient] = append(missed[recipient], c)
+		senders = append(senders, c.From)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Senders by their public names, apps by their registry names
+	names := authlib.PublicNames(db, senders)
+	apps := map[string]string{}
+	for _, app := range GetAllApps() {
+		apps[app.ID] = app.Name
+	}
+	for _, list := range missed {
+		for i := range list {
+			if name := names[list[i].From]; name != "" {
+				list[i].From = name
+			}
+			if name := apps[list[i].App]; name != "" {
+				list[i].App = name
+			}
+		}
+	}
+	return missed, nil
+}
+
+// claimDigest records that a player's digest for today is being sent, so a
+// manual re-run of the job doesn't email them twice
+func claimDigest(email string, now time.Time) bool {
+	ok, err := redisClient.SetNX(ctx, digestSentKey(email, now), 1, digestSentTTL).Result()
+	if err != nil {
+		log.Printf("⚠️  Failed to record digest for %s: %v", email, err)
+		return true
+	}
+	return ok
+}
+
+func releaseDigest(email string, now time.Time) {
+	redisClient.Del(ctx, digestSentKey(email, now))
+}
+
+func digestSentKey(email string, now time.Time) string {
+	return fmt.Sprintf("digest:sent:%s:%s", now.Format("2006-01-02"), email)
+}
+
+var digestHTML = template.Must(template.New("digest").Parse(`<!DOCTYPE html>
+<html><body style="font-family: sans-serif; color: #222; max-width: 560px;">
+<p>Hi {{.Name}},</p>
+{{if .Missed}}<h3>Challenges you missed</h3><ul>
+{{range .Missed}}<li>{{.Line}}</li>
+{{end}}</ul>{{end}}
+{{if .Upcoming}}<h3>Coming up</h3><ul>
+{{range .Upcoming}}<li><strong>{{.When}}</strong> – {{.Title}}{{if .Detail}} <span style="color: #666;">({{.Detail}})</span>{{end}}</li>
+{{end}}</ul>{{end}}
+<p style="color: #666; font-size: 12px;">You get this {{.Frequency}} digest because you turned it on in your Activity Hub settings.
+Change how often it comes, or stop it, in Settings{{if .SettingsURL}}: <a href="{{.SettingsURL}}">{{.SettingsURL}}</a>{{end}}.</p>
+</body></html>`))
+
+// renderDigest writes a digest as a text and HTML email
+func renderDigest(d digest, frequency string) mailer.Message {
+	type missedLine struct{ Line string }
+	type upcomingLine struct{ When, Title, Detail string }
+
+	name := d.Recipient.Name
+	if name == "" {
+		name = "there"
+	}
+	settingsURL := strings.TrimRight(getEnv("HUB_PUBLIC_URL", ""), "/")
+
+	view := struct {
+		Name, Frequency, SettingsURL string
+		Missed                       []missedLine
+		Upcoming                     []upcomingLine
+	}{Name: name, Frequency: frequency, SettingsURL: settingsURL}
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "Hi %s,\n", name)
+	if len(d.Missed) > 0 {
+		text.WriteString("\nChallenges you missed\n")
+		for _, c := range d.Missed {
+			line := fmt.Sprintf("%s challenged you to %s (%s)", c.From, c.App, c.At.Format("Mon 2 Jan 15:04"))
+			if c.Multi {
+				line = fmt.Sprintf("%s invited you to a game of %s (%s)", c.From, c.App, c.At.Format("Mon 2 Jan 15:04"))
+			}
+			fmt.Fprintf(&text, "  - %s\n", line)
+			view.Missed = append(view.Missed, missedLine{line})
+		}
+	}
+	if len(d.Upcoming) > 0 {
+		text.WriteString("\nComing up\n")
+		for _, item := range d.Upcoming {
+			when := item.At.Format("Mon 2 Jan 15:04")
+			fmt.Fprintf(&text, "  - %s: %s", when, item.Title)
+			if item.Detail != "" {
+				fmt.Fprintf(&text, " (%s)", item.Detail)
+			}
+			text.WriteString("\n")
+			view.Upcoming = append(view.Upcoming, upcomingLine{when, item.Title, item.Detail})
+		}
+	}
+	fmt.Fprintf(&text, "\nYou get this %s digest because you turned it on in your Activity Hub settings.\n", frequency)
+	text.WriteString("Change how often it comes, or stop it, in Settings")
+	if settingsURL != "" {
+		text.WriteString(": " + settingsURL)
+	}
+	text.WriteString(".\n")
+
+	var html bytes.Buffer
+	if err := digestHTML.Execute(&html, view); err != nil {
+		log.Printf("⚠️  Failed to render digest HTML: %v", err)
+		html.Reset()
+	}
+
+	subject := "Your Activity Hub daily digest"
+	if frequency == "weekly" {
+		subject = "Your Activity Hub week ahead"
+	}
+	return mailer.Message{To: d.Recipient.Email, Subject: subject, Text: text.String(), HTML: html.String()}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"time"
+
+	"github.com/achgithub/activity-hub-common/events"
+)
+
+// Lobby stream events, published on the user, device and presence channels.
+// The lobby stream is per user, so envelopes carry no session.
+
+type ChallengeRef struct {
+	ChallengeID string `json:"challengeId"`
+}
+
+// ChallengeResponse tells the challenger how a challenge was answered, with
+// enough detail to suggest someone else after a decline
+type ChallengeResponse struct {
+	ChallengeID string                 `json:"challengeId"`
+	AppID       string                 `json:"appId"`
+	ToUser      string                 `json:"toUser"`
+	Options     map[string]interface{} `json:"options,omitempty"`
+	Reason      string                 `json:"reason,omitempty"`
+	ReasonText  string                 `json:"reasonText,omitempty"`
+}
+
+// ScheduledChallengeRef is a booked challenge; ChallengeID is set once it's sent
+type ScheduledChallengeRef struct {
+	ID          int       `json:"id"`
+	FromUser    string    `json:"fromUser"`
+	ToUser      string    `json:"toUser"`
+	AppID       string    `json:"appId"`
+	ScheduledAt time.Time `json:"scheduledAt"`
+	ChallengeID string    `json:"challengeId,omitempty"`
+}
+
+// SessionExpiring warns that an idle session is about to be logged out
+type SessionExpiring struct {
+	ExpiresAt   int64 `json:"expiresAt"` // Unix seconds
+	SecondsLeft int   `json:"secondsLeft"`
+}
+
+type GameStarted struct {
+	AppID  string `json:"appId"`
+	GameID string `json:"gameId"`
+}
+
+var (
+	evLobbyConnected    = events.Register[events.NoPayload]("connected", 1, "Lobby stream opened")
+	evChallengeReceived = events.Register[ChallengeRef]("challenge_received", 1, "A challenge arrived")
+	evChallengeAccepted = events.Register[ChallengeResponse]("accepted", 1, "A sent challenge was accepted")
+	evChallengeRejected = events.Register[ChallengeResponse]("rejected", 1, "A sent challenge was declined")
+	evChallengeUpdate   = events.Register[ChallengeRef]("challenge_update", 1, "A player accepted a multi-player challenge")
+	evGameStarted       = events.Register[GameStarted]("game_started", 1, "A challenge became a game; open it")
+	evPresenceUpdate    = events.Register[events.NoPayload]("presence_update", 1, "Someone came online, went away or left")
+
+	evScheduledChallengeReceived  = events.Register[ScheduledChallengeRef]("scheduled_challenge_received", 1, "Someone booked a challenge with you")
+	evScheduledChallengeReminder  = events.Register[ScheduledChallengeRef]("scheduled_challenge_reminder", 1, "A booked challenge starts soon")
+	evScheduledChallengeExpired   = events.Register[ScheduledChallengeRef]("scheduled_challenge_expired", 1, "A booked challenge lapsed as a player wasn't free")
+	evScheduledChallengeCancelled = events.Register[ScheduledChallengeRef]("scheduled_challenge_cancelled", 1, "The other player cancelled a booked challenge")
+
+	evSessionExpiring = events.Register[SessionExpiring]("session_expiring", 1, "The session is idle and will be logged out soon; any activity keeps it")
+	evSessionExpired  = events.Register[events.NoPayload]("session_expired", 1, "The session was logged out after inactivity")
+)
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/achgithub/activity-hub-common/flags"
+)
+
+// featureFlags is the shell's copy of the feature flags managed in setup-admin
+var featureFlags *flags.Set
+
+// handleGetFlags - GET /api/flags
+// Every feature flag evaluated for the caller, so frontends can show or hide
+// features. Without a token only flags with no venue or role targeting are on.
+func handleGetFlags(w http.ResponseWriter, r *http.Request) {
+	user, _ := requestUser(r)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"flags": featureFlags.Evaluate(user),
+	})
+}
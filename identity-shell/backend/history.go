@@ -0,0 +1,82 @@
+package main
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// handleGetUserGames - GET /api/user/games?app={app}&limit={n}&before={RFC3339}
+// The user's completed games across all apps ("My games" on the profile page).
+// History is kept by the leaderboard app, so the request is passed through with the user's token.
+func handleGetUserGames(w http.ResponseWriter, r *http.Request) {
+	forwardToLeaderboard(w, r, "/api/history/me?"+r.URL.RawQuery, "game history")
+}
+
+// handleGetPendingResults - GET /api/user/results/pending
+// Self-reported results the user's opponents are waiting for them to confirm or dispute.
+func handleGetPendingResults(w http.ResponseWriter, r *http.Request) {
+	forwardToLeaderboard(w, r, "/api/results/pending", "pending results")
+}
+
+// handleConfirmResult - POST /api/user/results/{gameId}/confirm
+func handleConfirmResult(w http.ResponseWriter, r *http.Request) {
+	gameID := url.PathEscape(mux.Vars(r)["gameId"])
+	forwardToLeaderboard(w, r, "/api/results/"+gameID+"/confirm", "result confirmation")
+}
+
+// handleDisputeResult - POST /api/user/results/{gameId}/dispute
+// Body: {"reason": "..."}
+func handleDisputeResult(w http.ResponseWriter, r *http.Request) {
+	gameID := url.PathEscape(mux.Vars(r)["gameId"])
+	forwardToLeaderboard(w, r, "/api/results/"+gameID+"/dispute", "result dispute")
+}
+
+// forwardToLeaderboard passes the request (method, body and the user's token)
+// through to the leaderboard app and copies the response back.
+func forwardToLeaderboard(w http.ResponseWriter, r *http.Request, path, what string) {
+	email := extractEmailFromRequest(r)
+	if email == "" {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	leaderboardURL := getGameBackendURL("leaderboard")
+	if leaderboardURL == "" {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Leaderboard not installed", http.StatusNotFound)
+			return
+		}
+		// Leaderboard not installed - nothing to show
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("[]"))
+		return
+	}
+
+	req, err := http.NewRequest(r.Method, leaderboardURL+path, r.Body)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	req.Header.Set("Authorization", r.Header.Get("Authorization"))
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		log.Printf("⚠️  Failed to reach leaderboard for %s (%s): %v", what, email, err)
+		http.Error(w, "Leaderboard unavailable", http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+
+	w.Header().Set("Content-Type", resp.Header.Get("Content-Type"))
+	w.WriteHeader(resp.StatusCode)
+	io.Copy(w, resp.Body)
+}
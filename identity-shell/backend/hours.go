@@ -0,0 +1,23 @@
+package main
+
+import (
+	"time"
+
+	"github.com/achgithub/activity-hub-common/hours"
+)
+
+// openingHours is the shell's copy of the venue opening hours managed in setup-admin
+var openingHours *hours.Hours
+
+// errLobbyClosed is the message key (see i18n/) returned for new challenges
+// while the challenger's venue is closed and enforces its hours
+const errLobbyClosed = "lobby_closed"
+
+// lobbyClosedFor reports whether a player's venue has closed the lobby. The
+// lobby endpoints don't carry a token, so the venue comes from the users
+// table; guests and unknown players follow the default hours.
+func lobbyClosedFor(email string) bool {
+	var venueID int
+	db.QueryRow("SELECT COALESCE(venue_id, 0) FROM users WHERE email = $1", email).Scan(&venueID)
+	return openingHours.LobbyClosed(venueID, time.Now())
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/achgithub/activity-hub-common/events"
+	"github.com/achgithub/activity-hub-common/i18n"
+	"github.com/go-redis/redis/v8"
+)
+
+// Inactivity timeout: a session nobody has used for SESSION_IDLE_TIMEOUT_SECONDS
+// is logged out, so a pub tablet left signed in doesn't stay that way. Off
+// (0) by default. The lobby stream warns SESSION_IDLE_WARNING_SECONDS before,
+// and on expiry the user's presence is removed and the token refused until
+// the user logs in again - by every backend, as the identity DB
+// session_activity table the shared auth resolver checks is kept up to date
+// alongside the Redis keys the expiry loop works from.
+//
+// Activity is any authenticated shell request (the frontend pings
+// /api/session/activity as the user taps and types), a lobby action that
+// marks the device active, or being in a game.
+//
+//	session:activity   sorted set of session hashes by last activity
+//	session:owners     session hash -> user email, for the lobby stream
+//	session:warned     sessions already sent the warning
+var (
+	sessionIdleTimeout = envSeconds("SESSION_IDLE_TIMEOUT_SECONDS", 0)
+	sessionIdleWarning = envSeconds("SESSION_IDLE_WARNING_SECONDS", 60)
+)
+
+const (
+	sessionActivityKey  = "session:activity"
+	sessionOwnersKey    = "session:owners"
+	sessionWarnedKey    = "session:warned"
+	sessionIdleInterval = 10 * time.Second
+)
+
+// startSessionActivity starts the inactivity clock for a session at login.
+// A token can be issued again (demo tokens are the same for every login), so
+// this also clears an earlier expiry. Rows untouched for a month are pruned.
+func startSessionActivity(token, email string) {
+	if sessionIdleTimeout == 0 || token == "" {
+		return
+	}
+	hash := authlib.HashSessionToken(token)
+	pipe := redisClient.TxPipeline()
+	pipe.ZAdd(ctx, sessionActivityKey, &redis.Z{Score: float64(time.Now().Unix()), Member: hash})
+	pipe.HSet(ctx, sessionOwnersKey, hash, email)
+	pipe.SRem(ctx, sessionWarnedKey, hash)
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("⚠️  Failed to start session activity for %s: %v", email, err)
+	}
+
+	db.Exec(`DELETE FROM session_activity WHERE last_seen_at < NOW() - INTERVAL '30 days'`)
+	_, err := db.Exec(`
+		INSERT INTO session_activity (token_hash, user_email, last_seen_at, idle_timeout_seconds)
+		VALUES ($1, $2, NOW(), $3)
+		ON CONFLICT (token_hash) DO UPDATE
+		SET user_email = EXCLUDED.user_email, last_seen_at = NOW(),
+		    idle_timeout_seconds = EXCLUDED.idle_timeout_seconds, expired_at = NULL
+	`, hash, email, int(sessionIdleTimeout.Seconds()))
+	if err != nil {
+		log.Printf("⚠️  Failed to record session activity for %s: %v", email, err)
+	}
+}
+
+// touchSession records activity on a session that is being tracked. Sessions
+// from before the timeout was turned on, and tokens that aren't sessions
+// (impersonation, signed app tokens), are left alone, as are expired ones.
+// The identity DB is only written once the last write is an expiry interval old.
+func touchSession(hash string, at time.Time) {
+	pipe := redisClient.TxPipeline()
+	pipe.ZAddArgs(ctx, sessionActivityKey, redis.ZAddArgs{XX: true, GT: true, Members: []redis.Z{{Score: float64(at.Unix()), Member: hash}}})
+	pipe.SRem(ctx, sessionWarnedKey, hash)
+	pipe.Exec(ctx)
+
+	db.Exec(`
+		UPDATE session_activity SET last_seen_at = to_timestamp($2)
+		WHERE token_hash = $1 AND expired_at IS NULL
+		  AND last_seen_at < to_timestamp($2) - $3 * INTERVAL '1 second'
+	`, hash, at.Unix(), int(sessionIdleInterval.Seconds()))
+}
+
+// sessionExpired reports whether a token's session has timed out, including
+// one past its time that the expiry loop hasn't reached yet. The check is the
+// shared resolver's, so the shell and the app backends agree.
+func sessionExpired(token string) bool {
+	if token == "" {
+		return false
+	}
+	return errors.Is(authlib.CheckSessionActivity(db, token), authlib.ErrSessionExpired)
+}
+
+// idleSessionMiddleware refuses shell API requests on an expired session and
+// counts the rest as activity. Login, validate and logout still get through
+// (validate answers valid: false for an expired session).
+func idleSessionMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if sessionIdleTimeout == 0 || token == "" || !strings.HasPrefix(r.URL.Path, "/api/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/api/login"), r.URL.Path == "/api/validate", r.URL.Path == "/api/logout":
+		case sessionExpired(token):
+			http.Error(w, i18n.T(r, "session_expired"), http.StatusUnauthorized)
+			return
+		default:
+			touchSession(authlib.HashSessionToken(token), time.Now())
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handleSessionActivity - POST /api/session/activity
+// Activity ping from the frontend (the middleware has already counted it).
+// Returns the policy so the frontend knows whether to keep pinging.
+func handleSessionActivity(w http.ResponseWriter, r *http.Request) {
+	resp := map[string]interface{}{
+		"idleTimeoutSeconds": int(sessionIdleTimeout.Seconds()),
+		"warningSeconds":     int(sessionIdleWarning.Seconds()),
+	}
+	if sessionIdleTimeout > 0 {
+		resp["expiresAt"] = time.Now().Add(sessionIdleTimeout).Unix()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// runSessionExpiry warns and expires idle sessions. With the timeout off,
+// sessions tracked while it was on are forgotten so no backend expires them.
+func runSessionExpiry() {
+	if sessionIdleTimeout == 0 {
+		if _, err := db.Exec(`DELETE FROM session_activity`); err != nil {
+			log.Printf("⚠️  Failed to clear session activity: %v", err)
+		}
+		return
+	}
+	log.Printf("⏲️  Sessions expire after %s idle (warning %s before)", sessionIdleTimeout, sessionIdleWarning)
+
+	ticker := time.NewTicker(sessionIdleInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		checkIdleSessions()
+	}
+}
+
+// checkIdleSessions goes through the sessions due a warning or expiry. Being
+// in a game or a recent lobby action on one of the user's devices counts as
+// activity, so those sessions are moved on instead.
+func checkIdleSessions() {
+	now := time.Now()
+	warnFrom := now.Add(-sessionIdleTimeout + sessionIdleWarning)
+	due, err := redisClient.ZRangeByScoreWithScores(ctx, sessionActivityKey, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(warnFrom.Unix(), 10),
+	}).Result()
+	if err != nil {
+		log.Printf("⚠️  Failed to read session activity: %v", err)
+		return
+	}
+
+	for _, z := range due {
+		hash := z.Member.(string)
+		email, err := redisClient.HGet(ctx, sessionOwnersKey, hash).Result()
+		if err != nil {
+			// Owner unknown: nobody to warn, just forget it
+			redisClient.ZRem(ctx, sessionActivityKey, hash)
+			continue
+		}
+
+		lastActive := time.Unix(int64(z.Score), 0)
+		if seen := presenceActivity(email); seen.After(lastActive) {
+			touchSession(hash, seen)
+			lastActive = seen
+		}
+		expiresAt := lastActive.Add(sessionIdleTimeout)
+
+		switch {
+		case !expiresAt.After(now):
+			expireSession(hash, email)
+		case expiresAt.Sub(now) <= sessionIdleWarning:
+			if added, _ := redisClient.SAdd(ctx, sessionWarnedKey, hash).Result(); added > 0 {
+				warning := SessionExpiring{ExpiresAt: expiresAt.Unix(), SecondsLeft: int(expiresAt.Sub(now).Seconds())}
+				if err := publishToUser(email, evSessionExpiring.New("", warning)); err != nil {
+					log.Printf("Failed to warn %s of session expiry: %v", email, err)
+				}
+			}
+		}
+	}
+}
+
+// presenceActivity is when the user last did something the presence tracker
+// saw: now if they're in a game, else their devices' latest lobby action
+func presenceActivity(email string) time.Time {
+	if game, err := GetInGame(email); err == nil && game != nil {
+		return time.Now()
+	}
+	devices, err := getDevices(email)
+	if err != nil || len(devices) == 0 {
+		return time.Time{}
+	}
+	return time.Unix(devices[0].LastActive, 0) // Most recently active first
+}
+
+// expireSession ends an idle session: the token is refused from now on, the
+// user's presence is removed and their open lobby streams told to log out
+func expireSession(hash, email string) {
+	if _, err := db.Exec(`UPDATE session_activity SET expired_at = NOW() WHERE token_hash = $1`, hash); err != nil {
+		log.Printf("⚠️  Failed to expire session for %s: %v", email, err)
+		return
+	}
+	pipe := redisClient.TxPipeline()
+	pipe.ZRem(ctx, sessionActivityKey, hash)
+	pipe.HDel(ctx, sessionOwnersKey, hash)
+	pipe.SRem(ctx, sessionWarnedKey, hash)
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("⚠️  Failed to expire session for %s: %v", email, err)
+	}
+
+	if err := RemoveUserPresence(email); err != nil {
+		log.Printf("Failed to remove presence of %s: %v", email, err)
+	}
+	if err := publishToUser(email, evSessionExpired.New("", events.NoPayload{})); err != nil {
+		log.Printf("Failed to notify %s of session expiry: %v", email, err)
+	}
+	log.Printf("⏲️  Session expired after inactivity: %s", email)
+}
+
+// publishToUser sends an event to every lobby stream the user has open
+func publishToUser(email string, event events.Envelope) error {
+	return redisClient.Publish(ctx, "user:"+email, event.Encode()).Err()
+}
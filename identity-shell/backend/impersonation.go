@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"log"
 	"net/http"
+	"strconv"
 
 	"github.com/google/uuid"
 	"github.com/lib/pq"
@@ -189,13 +190,108 @@ func handleEndImpersonation(w http.ResponseWriter, r *http.Request) {
 	log.Printf("✅ Impersonation ended: %s", session.SuperUserEmail)
 
 	// Return original super_user token and info
-	json.NewEncoder(w).Encode(map[string]interface{}{
+	json.NewEncoder(w).Encode(withSession(w, session.OriginalToken, session.SuperUserEmail, map[string]interface{}{
 		"success": true,
-		"token":   session.OriginalToken,
 		"user": map[string]interface{}{
 			"email": superUser.Email,
 			"name":  superUser.Name,
 			"roles": superUser.Roles,
 		},
+	}))
+}
+
+// handleGetImpersonationLog - GET /api/admin/impersonation-log
+// Returns recent impersonation sessions and the requests made under them across all backends.
+// Optional query params: superUser (filter by super_user email), limit (default 100, max 500)
+func handleGetImpersonationLog(w http.ResponseWriter, r *http.Request) {
+	superUser := r.URL.Query().Get("superUser")
+
+	limit := 100
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 {
+		limit = l
+	}
+	if limit > 500 {
+		limit = 500
+	}
+
+	sessionRows, err := db.Query(`
+		SELECT id, super_user_email, impersonated_email, started_at, ended_at, COALESCE(is_active, FALSE)
+		FROM impersonation_sessions
+		WHERE $1 = '' OR super_user_email = $1
+		ORDER BY started_at DESC
+		LIMIT $2
+	`, superUser, limit)
+	if err != nil {
+		log.Printf("Error querying impersonation sessions: %v", err)
+		http.Error(w, "Failed to fetch impersonation log", http.StatusInternalServerError)
+		return
+	}
+	defer sessionRows.Close()
+
+	sessions := []map[string]interface{}{}
+	for sessionRows.Next() {
+		var id int
+		var superUserEmail, impersonatedEmail string
+		var startedAt, endedAt sql.NullTime
+		var isActive bool
+
+		if err := sessionRows.Scan(&id, &superUserEmail, &impersonatedEmail, &startedAt, &endedAt, &isActive); err != nil {
+			log.Printf("Error scanning impersonation session: %v", err)
+			continue
+		}
+
+		session := map[string]interface{}{
+			"id":                id,
+			"superUserEmail":    superUserEmail,
+			"impersonatedEmail": impersonatedEmail,
+			"isActive":          isActive,
+		}
+		if startedAt.Valid {
+			session["startedAt"] = startedAt.Time
+		}
+		if endedAt.Valid {
+			session["endedAt"] = endedAt.Time
+		}
+		sessions = append(sessions, session)
+	}
+
+	activityRows, err := db.Query(`
+		SELECT super_user_email, impersonated_email, COALESCE(service_host, ''), method, path, created_at
+		FROM impersonation_activity
+		WHERE $1 = '' OR super_user_email = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`, superUser, limit)
+	if err != nil {
+		log.Printf("Error querying impersonation activity: %v", err)
+		http.Error(w, "Failed to fetch impersonation log", http.StatusInternalServerError)
+		return
+	}
+	defer activityRows.Close()
+
+	activity := []map[string]interface{}{}
+	for activityRows.Next() {
+		var superUserEmail, impersonatedEmail, serviceHost, method, path string
+		var createdAt sql.NullTime
+
+		if err := activityRows.Scan(&superUserEmail, &impersonatedEmail, &serviceHost, &method, &path, &createdAt); err != nil {
+			log.Printf("Error scanning impersonation activity: %v", err)
+			continue
+		}
+
+		activity = append(activity, map[string]interface{}{
+			"superUserEmail":    superUserEmail,
+			"impersonatedEmail": impersonatedEmail,
+			"service":           serviceHost,
+			"method":            method,
+			"path":              path,
+			"createdAt":         createdAt.Time,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"sessions": sessions,
+		"activity": activity,
 	})
 }
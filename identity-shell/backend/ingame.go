@@ -0,0 +1,245 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/achgithub/activity-hub-common/i18n"
+	"github.com/go-redis/redis/v8"
+	"github.com/gorilla/mux"
+)
+
+// In-game presence is set by game backends, not by the players' devices: when
+// a game starts its backend marks the players as in it, and clears them when
+// it ends. The lobby shows them as "Playing Tic-Tac-Toe vs Dave" and turns
+// away challenges until then. A game that never reports its end (a crashed
+// backend, an abandoned game) is cleared by the TTL.
+//
+//	user:ingame:{email}              the user's current game JSON
+//	presence:game:{appId}:{gameId}   set of the game's players, for clearing
+
+// How long a game's presence lasts unless its backend sets it again
+var gamePresenceTTL = envSeconds("PRESENCE_GAME_TTL_SECONDS", 3600)
+
+// maxGamePlayers bounds one presence update
+const maxGamePlayers = 16
+
+// InGamePresence is the game a user is playing, as reported by its backend
+type InGamePresence struct {
+	AppID     string   `json:"appId"`
+	AppName   string   `json:"appName"`
+	GameID    string   `json:"gameId"`
+	Opponents []string `json:"opponents"` // Public names of the other players
+	Since     int64    `json:"since"`
+}
+
+func inGameKey(email string) string {
+	return fmt.Sprintf("user:ingame:%s", email)
+}
+
+func gamePlayersKey(appID, gameID string) string {
+	return fmt.Sprintf("presence:game:%s:%s", appID, gameID)
+}
+
+// requireService only lets through calls made with a service token. The app
+// the token was minted for is passed on in the X-Service-App header.
+func requireService(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		appID, err := authlib.VerifyServiceToken(token)
+		if err != nil {
+			http.Error(w, "Unauthorized - service token required", http.StatusUnauthorized)
+			return
+		}
+		r.Header.Set("X-Service-App", appID)
+		next(w, r)
+	}
+}
+
+// serviceMayUpdate reports whether the calling app may change presence for
+// the app in the path. Apps can only speak for their own games.
+func serviceMayUpdate(w http.ResponseWriter, r *http.Request) (appID, gameID string, ok bool) {
+	vars := mux.Vars(r)
+	appID, gameID = vars["appId"], vars["gameId"]
+	if r.Header.Get("X-Service-App") != appID {
+		http.Error(w, "Forbidden - a service can only update its own games", http.StatusForbidden)
+		return "", "", false
+	}
+	return appID, gameID, true
+}
+
+// handleSetGamePresence - PUT /api/presence/games/{appId}/{gameId} {players: [emails]}
+// Marks the players as in the game. Repeat it to keep a long game's presence alive.
+func handleSetGamePresence(w http.ResponseWriter, r *http.Request) {
+	appID, gameID, ok := serviceMayUpdate(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Players []string `json:"players"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if len(req.Players) == 0 || len(req.Players) > maxGamePlayers {
+		http.Error(w, fmt.Sprintf("Between 1 and %d players required", maxGamePlayers), http.StatusBadRequest)
+		return
+	}
+
+	if err := SetGamePresence(appID, gameID, req.Players); err != nil {
+		log.Printf("⚠️  Failed to set game presence for %s %s: %v", appID, gameID, err)
+		http.Error(w, "Failed to update presence", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"success": true})
+}
+
+// handleClearGamePresence - DELETE /api/presence/games/{appId}/{gameId}
+func handleClearGamePresence(w http.ResponseWriter, r *http.Request) {
+	appID, gameID, ok := serviceMayUpdate(w, r)
+	if !ok {
+		return
+	}
+
+	if err := ClearGamePresence(appID, gameID); err != nil {
+		log.Printf("⚠️  Failed to clear game presence for %s %s: %v", appID, gameID, err)
+		http.Error(w, "Failed to update presence", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"success": true})
+}
+
+// SetGamePresence marks players as in a game, replacing any game they were in
+func SetGamePresence(appID, gameID string, players []string) error {
+	names := authlib.PublicNames(db, players)
+	now := time.Now().Unix()
+
+	pipe := redisClient.TxPipeline()
+	for _, email := range players {
+		game := InGamePresence{AppID: appID, AppName: appName(appID), GameID: gameID, Opponents: []string{}, Since: now}
+		for _, other := range players {
+			if other != email {
+				game.Opponents = append(game.Opponents, names[other])
+			}
+		}
+		data, err := json.Marshal(game)
+		if err != nil {
+			return fmt.Errorf("failed to marshal game presence: %w", err)
+		}
+		pipe.Set(ctx, inGameKey(email), data, gamePresenceTTL)
+		pipe.SAdd(ctx, gamePlayersKey(appID, gameID), email)
+	}
+	pipe.Expire(ctx, gamePlayersKey(appID, gameID), gamePresenceTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to save game presence: %w", err)
+	}
+
+	publishPresenceUpdate()
+	return nil
+}
+
+// ClearGamePresence frees a game's players. Players who have since started
+// another game keep that one.
+func ClearGamePresence(appID, gameID string) error {
+	players, err := redisClient.SMembers(ctx, gamePlayersKey(appID, gameID)).Result()
+	if err != nil {
+		return fmt.Errorf("failed to get game players: %w", err)
+	}
+
+	cleared := 0
+	for _, email := range players {
+		game, err := GetInGame(email)
+		if err != nil {
+			return err
+		}
+		if game != nil && game.AppID == appID && game.GameID == gameID {
+			redisClient.Del(ctx, inGameKey(email))
+			cleared++
+		}
+	}
+	redisClient.Del(ctx, gamePlayersKey(appID, gameID))
+
+	if cleared > 0 {
+		publishPresenceUpdate()
+	}
+	return nil
+}
+
+// GetInGame returns the game a user is in, or nil if they aren't in one
+func GetInGame(email string) (*InGamePresence, error) {
+	data, err := redisClient.Get(ctx, inGameKey(email)).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get game presence: %w", err)
+	}
+
+	var game InGamePresence
+	if err := json.Unmarshal([]byte(data), &game); err != nil {
+		return nil, fmt.Errorf("failed to parse game presence: %w", err)
+	}
+	return &game, nil
+}
+
+// applyGamePresence shows users their backends have in a game as in_game.
+// Best effort: on lookup failure the users are left as their devices report.
+func applyGamePresence(users []UserPresence) {
+	if len(users) == 0 {
+		return
+	}
+
+	keys := make([]string, len(users))
+	for i, u := range users {
+		keys[i] = inGameKey(u.Email)
+	}
+	values, err := redisClient.MGet(ctx, keys...).Result()
+	if err != nil {
+		log.Printf("⚠️  Failed to load game presence: %v", err)
+		return
+	}
+
+	for i, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			continue
+		}
+		var game InGamePresence
+		if err := json.Unmarshal([]byte(data), &game); err != nil {
+			continue
+		}
+		users[i].Status = "in_game"
+		users[i].CurrentApp = game.AppID
+		users[i].InGame = &game
+	}
+}
+
+// challengeBlockedByGame returns why a user can't be challenged right now, or
+// "" if they can, in the request's language. Lookup failures don't block
+// challenges.
+func challengeBlockedByGame(r *http.Request, email string) string {
+	game, err := GetInGame(email)
+	if err != nil {
+		log.Printf("⚠️  Failed to check game presence for %s: %v", email, err)
+		return ""
+	}
+	if game == nil {
+		return ""
+	}
+	name := email
+	if names := authlib.PublicNames(db, []string{email}); names[email] != "" {
+		name = names[email]
+	}
+	return i18n.T(r, "player_in_game", name, game.AppName)
+}
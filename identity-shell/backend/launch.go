@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	authlib "github.com/achgithub/activity-hub-common/auth"
+)
+
+// Session tokens are long-lived, so they never go in a URL. The shell mints
+// single-use signed tokens instead: stream tokens for EventSource connections
+// (verified by the shared SSEMiddleware in every backend) and launch tokens for
+// handing off to a mini-app, which swaps its launch token for the session token
+// in a POST body.
+
+// requestUser resolves the bearer token on a shell request.
+func requestUser(r *http.Request) (*authlib.AuthUser, bool) {
+	authHeader := r.Header.Get("Authorization")
+	if !strings.HasPrefix(authHeader, "Bearer ") {
+		return nil, false
+	}
+	user, err := authlib.ResolveToken(db, strings.TrimPrefix(authHeader, "Bearer "))
+	if err != nil {
+		return nil, false
+	}
+	return user, true
+}
+
+func mintToken(w http.ResponseWriter, r *http.Request, purpose string, ttl time.Duration) {
+	user, ok := requestUser(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	// A table device only picks players; apps are opened as one of them
+	if purpose == authlib.PurposeLaunch && user.TableID != 0 && !user.IsTablePlayer() {
+		http.Error(w, "Choose a player at this table first", http.StatusBadRequest)
+		return
+	}
+
+	token, err := authlib.MintSignedToken(user, purpose, ttl)
+	if err != nil {
+		log.Printf("❌ Failed to mint %s token for %s: %v", purpose, user.Email, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"token":     token,
+		"expiresAt": time.Now().Add(ttl),
+	})
+}
+
+// handleMintStreamToken - POST /api/auth/stream-token
+// A single-use token for one EventSource connection (?token=...), valid for a minute.
+func handleMintStreamToken(w http.ResponseWriter, r *http.Request) {
+	mintToken(w, r, authlib.PurposeStream, authlib.StreamTokenTTL)
+}
+
+// handleMintLaunchToken - POST /api/auth/launch-token
+// A single-use token for the ?launch= parameter when opening a mini-app.
+func handleMintLaunchToken(w http.ResponseWriter, r *http.Request) {
+	mintToken(w, r, authlib.PurposeLaunch, authlib.LaunchTokenTTL)
+}
+
+// handleExchangeLaunchToken - POST /api/auth/launch {"launch": "...", "cookie": bool}
+// Called by a mini-app on load: consumes the launch token and returns the
+// session token to keep in memory/sessionStorage. The shell itself passes
+// cookie=true, which in cookie session mode sets the session cookie instead.
+func handleExchangeLaunchToken(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Launch string `json:"launch"`
+		Cookie bool   `json:"cookie"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Launch == "" {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	user, err := authlib.ConsumeSignedToken(db, req.Launch, authlib.PurposeLaunch)
+	if err != nil {
+		log.Printf("❌ Launch token rejected: %v", err)
+		http.Error(w, "Invalid or expired launch token", http.StatusUnauthorized)
+		return
+	}
+
+	token, err := sessionTokenFor(user)
+	if err != nil {
+		log.Printf("❌ No session to launch for %s: %v", user.Email, err)
+		http.Error(w, "Session ended", http.StatusUnauthorized)
+		return
+	}
+
+	resp := map[string]interface{}{
+		"user": map[string]interface{}{
+			"email":         user.Email,
+			"name":          user.Name,
+			"is_admin":      user.IsAdmin,
+			"roles":         user.Roles,
+			"impersonating": user.IsImpersonating,
+		},
+	}
+	if req.Cookie {
+		resp = withSession(w, token, user.Email, resp)
+	} else {
+		resp["token"] = token
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// sessionTokenFor returns the session token the launching user holds:
+// a guest, table or demo token, or the live impersonation token of their super_user.
+func sessionTokenFor(user *authlib.AuthUser) (string, error) {
+	if token := authlib.TableSessionToken(user.Email); token != "" {
+		return token, nil
+	}
+	if strings.HasPrefix(user.Email, "guest-") {
+		return "guest-token-" + strings.TrimPrefix(user.Email, "guest-"), nil
+	}
+	if user.IsImpersonating {
+		var token string
+		err := db.QueryRow(`
+			SELECT impersonation_token FROM impersonation_sessions
+			WHERE super_user_email = $1 AND impersonated_email = $2 AND is_active = TRUE
+			ORDER BY started_at DESC LIMIT 1
+		`, user.ImpersonatedBy, user.Email).Scan(&token)
+		return token, err
+	}
+	return "demo-token-" + user.Email, nil
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/achgithub/activity-hub-common/events"
+	"github.com/achgithub/activity-hub-common/loadtest"
+	"github.com/gorilla/mux"
+)
+
+// Load test mode (LOAD_TEST=true) fills the lobby with synthetic users, to
+// check the shell copes with a full pub. Each bot is a guest doing what the
+// lobby page does: it keeps a lobby stream open, sends a presence heartbeat
+// every 20 seconds and refetches the online list and its challenges when
+// told to. Bots show as online to real users while the test runs and are
+// removed from presence when it ends.
+
+const lobbyBotHeartbeat = 20 * time.Second // As the lobby page
+
+var loadTests = loadtest.NewRunner()
+
+// loadTestURL is where bots send their requests: this backend directly, or
+// the gateway (LOAD_TEST_URL) to include the network in the test
+func loadTestURL() string {
+	if u := getEnv("LOAD_TEST_URL", ""); u != "" {
+		return strings.TrimRight(u, "/")
+	}
+	return "http://127.0.0.1:" + getEnv("PORT", "3001")
+}
+
+// handleStartLoadTest - POST /api/admin/loadtest {"bots": 80, "rampUpSeconds": 30, "durationSeconds": 600}
+func handleStartLoadTest(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Bots            int `json:"bots"`
+		RampUpSeconds   int `json:"rampUpSeconds"`
+		DurationSeconds int `json:"durationSeconds"` // 0 = until stopped
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	run, err := loadTests.Start(loadtest.Config{
+		Kind:     "lobby",
+		Bots:     body.Bots,
+		RampUp:   time.Duration(body.RampUpSeconds) * time.Second,
+		Duration: time.Duration(body.DurationSeconds) * time.Second,
+	}, lobbyBot(loadTestURL()))
+	if err == loadtest.ErrRunning {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	log.Printf("🤖 Load test %s: %d lobby bots", run.ID, body.Bots)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(run.Summary())
+}
+
+// handleListLoadTests - GET /api/admin/loadtest
+func handleListLoadTests(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"runs": loadTests.List()})
+}
+
+// handleGetLoadTest - GET /api/admin/loadtest/{id}
+func handleGetLoadTest(w http.ResponseWriter, r *http.Request) {
+	run, ok := loadTests.Get(mux.Vars(r)["id"])
+	if !ok {
+		http.Error(w, "Load test not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(run.Summary())
+}
+
+// handleStopLoadTest - POST /api/admin/loadtest/{id}/stop
+func handleStopLoadTest(w http.ResponseWriter, r *http.Request) {
+	run, ok := loadTests.Get(mux.Vars(r)["id"])
+	if !ok {
+		http.Error(w, "Load test not found", http.StatusNotFound)
+		return
+	}
+	run.Stop()
+	<-run.Done()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(run.Summary())
+}
+
+// lobbyBot plays one synthetic user sitting in the lobby
+func lobbyBot(baseURL string) loadtest.Bot {
+	return func(ctx context.Context, g loadtest.Guest, rec *loadtest.Recorder) error {
+		c := loadtest.NewClient(baseURL, g.Token, rec)
+		deviceID := "loadtest-" + g.Email
+		query := "?email=" + url.QueryEscape(g.Email) + "&deviceId=" + url.QueryEscape(deviceID)
+
+		heartbeat := func() error {
+			return c.Do(ctx, "presence heartbeat", "POST", "/api/lobby/presence", map[string]string{
+				"email": g.Email, "name": g.Name, "status": "online", "deviceId": deviceID,
+			}, nil)
+		}
+		if err := heartbeat(); err != nil {
+			return err
+		}
+		defer func() {
+			// The test is over, so not ctx: the bot leaves the lobby regardless
+			c.Do(context.Background(), "presence remove", "POST", "/api/lobby/presence/remove"+query, nil, nil)
+		}()
+
+		// What the lobby page fetches on load and on presence updates
+		refresh := make(chan struct{}, 1)
+		refresh <- struct{}{}
+		go func() {
+			ticker := time.NewTicker(lobbyBotHeartbeat)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-refresh:
+					c.Do(ctx, "online users", "GET", "/api/lobby/presence", nil, nil)
+					c.Do(ctx, "challenges", "GET", "/api/lobby/challenges"+query, nil, nil)
+					c.Do(ctx, "sent challenges", "GET", "/api/lobby/challenges/sent"+query, nil, nil)
+				case <-ticker.C:
+					heartbeat()
+					c.Do(ctx, "sent challenges", "GET", "/api/lobby/challenges/sent"+query, nil, nil)
+				}
+			}
+		}()
+
+		for ctx.Err() == nil {
+			c.Stream(ctx, "lobby stream", "/api/lobby/stream"+query, func(e events.Envelope) bool {
+				if e.Type == evPresenceUpdate.Name {
+					select {
+					case refresh <- struct{}{}:
+					default: // A refresh is already due
+					}
+				}
+				return true
+			})
+			// Reconnect as EventSource would
+			select {
+			case <-ctx.Done():
+			case <-time.After(3 * time.Second):
+			}
+		}
+		return nil
+	}
+}
@@ -1,13 +1,18 @@
 package main
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"log"
 	"net/http"
 	"time"
+
+	"github.com/achgithub/activity-hub-common/activity"
+	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/achgithub/activity-hub-common/bus"
+	"github.com/achgithub/activity-hub-common/events"
+	"github.com/achgithub/activity-hub-common/i18n"
 )
 
 // UserPresence represents a user's online status
@@ -17,6 +22,12 @@ type UserPresence struct {
 	Status      string `json:"status"`
 	CurrentApp  string `json:"currentApp,omitempty"`
 	LastSeen    int64  `json:"lastSeen"`
+	Flair       string `json:"flair,omitempty"`
+	AvatarURL   string `json:"avatarUrl,omitempty"`
+	DeviceCount int    `json:"deviceCount,omitempty"`
+
+	InGame  *InGamePresence  `json:"inGame,omitempty"` // Set by the game's backend
+	Devices []DevicePresence `json:"-"`                // Per-device detail behind the merged view
 }
 
 // Challenge represents a game challenge between users
@@ -41,18 +52,23 @@ type Challenge struct {
 	ExpiresAt   int64                  `json:"expiresAt"`
 	RespondedAt int64                  `json:"respondedAt,omitempty"`
 	Options     map[string]interface{} `json:"options,omitempty"`
+
+	DeclineReason string `json:"declineReason,omitempty"` // See declineReasons
 }
 
 // HandleGetPresence - GET /api/lobby/presence
-// Returns list of all currently online users
+// Returns list of all currently online users (except those hiding their presence)
 func HandleGetPresence(w http.ResponseWriter, r *http.Request) {
 	users, err := GetOnlineUsers()
 	if err != nil {
 		log.Printf("Failed to fetch online users: %v", err)
-		http.Error(w, "Failed to fetch online users", http.StatusInternalServerError)
+		http.Error(w, i18n.T(r, "online_users_failed"), http.StatusInternalServerError)
 		return
 	}
 
+	users = filterHiddenPresence(users)
+	attachPresenceProfiles(users)
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"users": users,
@@ -68,21 +84,27 @@ func HandleUpdatePresence(w http.ResponseWriter, r *http.Request) {
 		Name       string `json:"name"`
 		Status     string `json:"status"`
 		CurrentApp string `json:"currentApp"`
+		DeviceID   string `json:"deviceId"` // Browser session sending the heartbeat
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		http.Error(w, i18n.T(r, "invalid_request"), http.StatusBadRequest)
 		return
 	}
 
 	if req.Email == "" || req.Name == "" || req.Status == "" {
-		http.Error(w, "Missing required fields", http.StatusBadRequest)
+		http.Error(w, i18n.T(r, "missing_fields"), http.StatusBadRequest)
 		return
 	}
 
-	if err := SetUserPresence(req.Email, req.Name, req.Status, req.CurrentApp); err != nil {
+	if _, ok := statusRank[req.Status]; !ok {
+		http.Error(w, i18n.T(r, "invalid_status"), http.StatusBadRequest)
+		return
+	}
+
+	if err := SetUserPresence(req.Email, req.Name, req.Status, req.CurrentApp, req.DeviceID); err != nil {
 		log.Printf("Failed to update presence: %v", err)
-		http.Error(w, "Failed to update presence", http.StatusInternalServerError)
+		http.Error(w, i18n.T(r, "presence_failed"), http.StatusInternalServerError)
 		return
 	}
 
@@ -90,16 +112,22 @@ func HandleUpdatePresence(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(map[string]interface{}{"success": true})
 }
 
-// HandleRemovePresence - POST /api/lobby/presence/remove
-// Removes a user's presence (for logout/disconnect)
+// HandleRemovePresence - POST /api/lobby/presence/remove?email={email}&deviceId={id}
+// Removes a device's presence (for logout/disconnect); without deviceId, all devices
 func HandleRemovePresence(w http.ResponseWriter, r *http.Request) {
 	email := r.URL.Query().Get("email")
 	if email == "" {
-		http.Error(w, "Email parameter required", http.StatusBadRequest)
+		http.Error(w, i18n.T(r, "email_required"), http.StatusBadRequest)
 		return
 	}
 
-	if err := RemoveUserPresence(email); err != nil {
+	var err error
+	if deviceID := r.URL.Query().Get("deviceId"); deviceID != "" {
+		err = RemoveDevicePresence(email, deviceID)
+	} else {
+		err = RemoveUserPresence(email)
+	}
+	if err != nil {
 		log.Printf("Failed to remove presence: %v", err)
 		// Don't return error - best effort removal
 	}
@@ -113,14 +141,14 @@ func HandleRemovePresence(w http.ResponseWriter, r *http.Request) {
 func HandleGetChallenges(w http.ResponseWriter, r *http.Request) {
 	email := r.URL.Query().Get("email")
 	if email == "" {
-		http.Error(w, "Email parameter required", http.StatusBadRequest)
+		http.Error(w, i18n.T(r, "email_required"), http.StatusBadRequest)
 		return
 	}
 
 	challenges, err := GetUserChallenges(email)
 	if err != nil {
 		log.Printf("Failed to fetch challenges: %v", err)
-		http.Error(w, "Failed to fetch challenges", http.StatusInternalServerError)
+		http.Error(w, i18n.T(r, "challenges_failed"), http.StatusInternalServerError)
 		return
 	}
 
@@ -135,14 +163,14 @@ func HandleGetChallenges(w http.ResponseWriter, r *http.Request) {
 func HandleGetSentChallenges(w http.ResponseWriter, r *http.Request) {
 	email := r.URL.Query().Get("email")
 	if email == "" {
-		http.Error(w, "Email parameter required", http.StatusBadRequest)
+		http.Error(w, i18n.T(r, "email_required"), http.StatusBadRequest)
 		return
 	}
 
 	challenges, err := GetSentChallenges(email)
 	if err != nil {
 		log.Printf("Failed to fetch sent challenges: %v", err)
-		http.Error(w, "Failed to fetch sent challenges", http.StatusInternalServerError)
+		http.Error(w, i18n.T(r, "sent_challenges_failed"), http.StatusInternalServerError)
 		return
 	}
 
@@ -163,44 +191,45 @@ func HandleSendChallenge(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		http.Error(w, i18n.T(r, "invalid_request"), http.StatusBadRequest)
 		return
 	}
 
 	if req.FromUser == "" || req.ToUser == "" || req.AppID == "" {
-		http.Error(w, "Missing required fields", http.StatusBadRequest)
+		http.Error(w, i18n.T(r, "missing_fields"), http.StatusBadRequest)
+		return
+	}
+
+	if lobbyClosedFor(req.FromUser) {
+		http.Error(w, i18n.T(r, errLobbyClosed), http.StatusForbidden)
 		return
 	}
 
 	// Check if recipient is online (direct Redis check, more accurate)
 	recipientOnline, err := IsUserOnline(req.ToUser)
 	if err != nil {
-		http.Error(w, "Failed to verify user status", http.StatusInternalServerError)
+		http.Error(w, i18n.T(r, "user_status_failed"), http.StatusInternalServerError)
 		return
 	}
 
 	if !recipientOnline {
-		http.Error(w, "User is not online", http.StatusBadRequest)
+		http.Error(w, i18n.T(r, "user_offline"), http.StatusBadRequest)
 		return
 	}
 
-	// Create challenge in Redis (with game options)
-	challengeID, err := CreateChallenge(req.FromUser, req.ToUser, req.AppID, req.Options)
-	if err != nil {
-		log.Printf("Failed to create challenge: %v", err)
-		http.Error(w, "Failed to create challenge", http.StatusInternalServerError)
+	if reason := challengeBlockedByGame(r, req.ToUser); reason != "" {
+		http.Error(w, reason, http.StatusConflict)
 		return
 	}
 
-	// Save to PostgreSQL for history
-	_, err = db.Exec(`
-		INSERT INTO challenges (id, from_user, to_user, app_id, status, expires_at)
-		VALUES ($1, $2, $3, $4, 'pending', NOW() + INTERVAL '60 seconds')
-	`, challengeID, req.FromUser, req.ToUser, req.AppID)
+	// Replies to this challenge go to the device it was sent from
+	TouchDevicePresence(req.FromUser, r.URL.Query().Get("deviceId"))
 
+	challengeID, err := issueChallenge(req.FromUser, req.ToUser, req.AppID, req.Options)
 	if err != nil {
-		log.Printf("Failed to save challenge to database: %v", err)
-		// Don't fail the request - Redis is source of truth for active challenges
+		log.Printf("Failed to create challenge: %v", err)
+		http.Error(w, i18n.T(r, "challenge_failed"), http.StatusInternalServerError)
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -223,23 +252,28 @@ func HandleSendMultiChallenge(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		http.Error(w, i18n.T(r, "invalid_request"), http.StatusBadRequest)
 		return
 	}
 
 	// Validation
 	if req.InitiatorID == "" || len(req.PlayerIDs) < req.MinPlayers || req.AppID == "" {
-		http.Error(w, "Missing required fields or insufficient players", http.StatusBadRequest)
+		http.Error(w, i18n.T(r, "challenge_players_missing"), http.StatusBadRequest)
 		return
 	}
 
 	if req.MinPlayers < 2 || req.MaxPlayers < req.MinPlayers {
-		http.Error(w, "Invalid player count constraints", http.StatusBadRequest)
+		http.Error(w, i18n.T(r, "challenge_player_counts"), http.StatusBadRequest)
 		return
 	}
 
 	if len(req.PlayerIDs) > req.MaxPlayers {
-		http.Error(w, fmt.Sprintf("Too many players (max: %d)", req.MaxPlayers), http.StatusBadRequest)
+		http.Error(w, i18n.T(r, "challenge_too_many_players", req.MaxPlayers), http.StatusBadRequest)
+		return
+	}
+
+	if lobbyClosedFor(req.InitiatorID) {
+		http.Error(w, i18n.T(r, errLobbyClosed), http.StatusForbidden)
 		return
 	}
 
@@ -247,42 +281,119 @@ func HandleSendMultiChallenge(w http.ResponseWriter, r *http.Request) {
 	for _, playerID := range req.PlayerIDs {
 		online, err := IsUserOnline(playerID)
 		if err != nil {
-			http.Error(w, "Failed to verify player status", http.StatusInternalServerError)
+			http.Error(w, i18n.T(r, "player_status_failed"), http.StatusInternalServerError)
 			return
 		}
 		if !online {
-			http.Error(w, fmt.Sprintf("Player %s is not online", playerID), http.StatusBadRequest)
+			http.Error(w, i18n.T(r, "player_offline", playerID), http.StatusBadRequest)
+			return
+		}
+		if playerID == req.InitiatorID {
+			continue
+		}
+		if reason := challengeBlockedByGame(r, playerID); reason != "" {
+			http.Error(w, reason, http.StatusConflict)
 			return
 		}
 	}
 
-	// Create multi-player challenge in Redis (120s TTL for multi-player)
-	challengeID, err := CreateMultiChallenge(req.InitiatorID, req.PlayerIDs, req.AppID, req.MinPlayers, req.MaxPlayers, req.Options)
+	// Replies to this challenge go to the device it was sent from
+	TouchDevicePresence(req.InitiatorID, r.URL.Query().Get("deviceId"))
+
+	challengeID, err := issueMultiChallenge(req.InitiatorID, req.PlayerIDs, req.AppID, req.MinPlayers, req.MaxPlayers, req.Options)
 	if err != nil {
 		log.Printf("Failed to create multi-player challenge: %v", err)
-		http.Error(w, "Failed to create challenge", http.StatusInternalServerError)
+		http.Error(w, i18n.T(r, "challenge_failed"), http.StatusInternalServerError)
 		return
 	}
 
-	// Save to PostgreSQL for history
-	playerIDsJSON, _ := json.Marshal(req.PlayerIDs)
-	_, err = db.Exec(`
-		INSERT INTO challenges (id, initiator_id, player_ids, app_id, status, min_players, max_players, expires_at)
-		VALUES ($1, $2, $3, $4, 'pending', $5, $6, NOW() + INTERVAL '120 seconds')
-	`, challengeID, req.InitiatorID, playerIDsJSON, req.AppID, req.MinPlayers, req.MaxPlayers)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":     true,
+		"challengeId": challengeID,
+	})
+}
 
+// issueChallenge creates a 2-player challenge in Redis (with game options),
+// records it for history and announces it on the activity feed
+func issueChallenge(fromUser, toUser, appID string, options map[string]interface{}) (string, error) {
+	options = withHandicaps(appID, []string{fromUser, toUser}, options)
+	challengeID, err := CreateChallenge(fromUser, toUser, appID, options)
 	if err != nil {
-		log.Printf("Failed to save multi-player challenge to database: %v", err)
-		// Don't fail - Redis is source of truth
+		return "", err
 	}
 
-	log.Printf("✅ Multi-player challenge created: %s for %d players", challengeID, len(req.PlayerIDs))
+	// Saved to PostgreSQL for history from the event bus (see bus.go)
+	publishChallengeStep(bus.ChallengeIssued, bus.ChallengeEvent{
+		ChallengeID: challengeID, AppID: appID, From: fromUser, Players: []string{toUser},
+		ExpiresAt: time.Now().Add(60 * time.Second),
+	})
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"success":     true,
-		"challengeId": challengeID,
+	names := authlib.PublicNames(db, []string{fromUser, toUser})
+	publishActivity(activity.Event{
+		Type:    activity.TypeChallenge,
+		App:     appID,
+		Text:    fmt.Sprintf("%s challenged %s to %s", names[fromUser], names[toUser], appName(appID)),
+		Players: []string{names[fromUser], names[toUser]},
 	})
+
+	return challengeID, nil
+}
+
+// withHandicaps adds the players' leaderboard handicaps to a challenge's
+// options as "handicaps" (services.Handicaps), so the game sets the match up
+// with them. Game types without handicaps are left as they are, and so is the
+// challenge if the leaderboard can't be reached.
+func withHandicaps(appID string, players []string, options map[string]interface{}) map[string]interface{} {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	handicaps, err := backends.FetchHandicaps(ctx, appID, players)
+	if err != nil {
+		log.Printf("⚠️  Failed to fetch %s handicaps: %v", appID, err)
+		return options
+	}
+	if handicaps == nil {
+		return options
+	}
+
+	merged := make(map[string]interface{}, len(options)+1)
+	for k, v := range options {
+		merged[k] = v
+	}
+	merged["handicaps"] = handicaps
+	return merged
+}
+
+// issueMultiChallenge creates a multi-player challenge in Redis (120s TTL),
+// records it for history and announces it on the activity feed
+func issueMultiChallenge(initiatorID string, playerIDs []string, appID string, minPlayers, maxPlayers int, options map[string]interface{}) (string, error) {
+	options = withHandicaps(appID, playerIDs, options)
+	challengeID, err := CreateMultiChallenge(initiatorID, playerIDs, appID, minPlayers, maxPlayers, options)
+	if err != nil {
+		return "", err
+	}
+
+	// Saved to PostgreSQL for history from the event bus (see bus.go)
+	publishChallengeStep(bus.ChallengeIssued, bus.ChallengeEvent{
+		ChallengeID: challengeID, AppID: appID, From: initiatorID, Players: playerIDs,
+		MinPlayers: minPlayers, MaxPlayers: maxPlayers, ExpiresAt: time.Now().Add(120 * time.Second),
+	})
+
+	log.Printf("✅ Multi-player challenge created: %s for %d players", challengeID, len(playerIDs))
+
+	names := authlib.PublicNames(db, playerIDs)
+	players := make([]string, 0, len(playerIDs))
+	for _, id := range playerIDs {
+		players = append(players, names[id])
+	}
+	publishActivity(activity.Event{
+		Type:    activity.TypeChallenge,
+		App:     appID,
+		Text:    fmt.Sprintf("%s started a %d-player game of %s", names[initiatorID], len(playerIDs), appName(appID)),
+		Players: players,
+	})
+
+	return challengeID, nil
 }
 
 // HandleAcceptChallenge - POST /api/lobby/challenge/accept
@@ -292,7 +403,7 @@ func HandleAcceptChallenge(w http.ResponseWriter, r *http.Request) {
 	challengeID := r.URL.Query().Get("id")
 	acceptingUser := r.URL.Query().Get("userId") // Required for multi-player
 	if challengeID == "" {
-		http.Error(w, "Challenge ID parameter required", http.StatusBadRequest)
+		http.Error(w, i18n.T(r, "challenge_id_required"), http.StatusBadRequest)
 		return
 	}
 
@@ -300,7 +411,7 @@ func HandleAcceptChallenge(w http.ResponseWriter, r *http.Request) {
 	challenge, err := GetChallenge(challengeID)
 	if err != nil {
 		log.Printf("Failed to get challenge: %v", err)
-		http.Error(w, "Challenge not found or expired", http.StatusBadRequest)
+		http.Error(w, i18n.T(r, "challenge_not_found"), http.StatusBadRequest)
 		return
 	}
 
@@ -310,10 +421,13 @@ func HandleAcceptChallenge(w http.ResponseWriter, r *http.Request) {
 	if isMultiPlayer {
 		// Multi-player challenge flow
 		if acceptingUser == "" {
-			http.Error(w, "userId parameter required for multi-player challenges", http.StatusBadRequest)
+			http.Error(w, i18n.T(r, "challenge_user_required"), http.StatusBadRequest)
 			return
 		}
 
+		// The game opens on the device the challenge was accepted from
+		TouchDevicePresence(acceptingUser, r.URL.Query().Get("deviceId"))
+
 		// Add user to accepted list
 		readyToStart, err := AcceptMultiPlayerChallenge(challengeID, acceptingUser)
 		if err != nil {
@@ -325,7 +439,7 @@ func HandleAcceptChallenge(w http.ResponseWriter, r *http.Request) {
 		// Get updated challenge
 		challenge, err = GetChallenge(challengeID)
 		if err != nil {
-			http.Error(w, "Failed to get updated challenge", http.StatusInternalServerError)
+			http.Error(w, i18n.T(r, "challenge_reload_failed"), http.StatusInternalServerError)
 			return
 		}
 
@@ -334,25 +448,22 @@ func HandleAcceptChallenge(w http.ResponseWriter, r *http.Request) {
 			gameID, err := createGameForMultiChallenge(challenge)
 			if err != nil {
 				log.Printf("Failed to create multi-player game: %v", err)
-				http.Error(w, "Failed to create game", http.StatusInternalServerError)
+				http.Error(w, i18n.T(r, "game_create_failed"), http.StatusInternalServerError)
 				return
 			}
 
 			log.Printf("✅ Multi-player game created: %s for challenge %s", gameID, challengeID)
 
-			// Update PostgreSQL
-			_, err = db.Exec(`
-				UPDATE challenges
-				SET status = 'active', responded_at = NOW()
-				WHERE id = $1
-			`, challengeID)
-			if err != nil {
-				log.Printf("Failed to update challenge in database: %v", err)
-			}
-
 			// Get updated challenge to see who accepted
 			challenge, _ = GetChallenge(challengeID)
 
+			publishChallengeStep(bus.ChallengeStarted, bus.ChallengeEvent{
+				ChallengeID: challengeID, AppID: challenge.AppID, From: challenge.InitiatorID, Players: challenge.Accepted,
+				MinPlayers: challenge.MinPlayers, MaxPlayers: challenge.MaxPlayers,
+			})
+
+			go awardChallengePoints(challengeID, challenge.AppID, challenge.Accepted)
+
 			// Notify all accepted players
 			for _, playerID := range challenge.Accepted {
 				if err := PublishGameStarted(playerID, challenge.AppID, gameID); err != nil {
@@ -388,6 +499,8 @@ func HandleAcceptChallenge(w http.ResponseWriter, r *http.Request) {
 		}
 	} else {
 		// Legacy 2-player challenge flow
+		TouchDevicePresence(challenge.ToUser, r.URL.Query().Get("deviceId"))
+
 		// Get player display names from presence
 		player1Name := challenge.FromUser // default to email
 		player2Name := challenge.ToUser
@@ -403,28 +516,23 @@ func HandleAcceptChallenge(w http.ResponseWriter, r *http.Request) {
 		gameID, err := createGameForChallenge(challenge, player1Name, player2Name)
 		if err != nil {
 			log.Printf("Failed to create game: %v", err)
-			http.Error(w, "Failed to create game", http.StatusInternalServerError)
+			http.Error(w, i18n.T(r, "game_create_failed"), http.StatusInternalServerError)
 			return
 		}
 
 		log.Printf("✅ Game created: %s for challenge %s", gameID, challengeID)
 
 		// Update challenge status
-		if err := UpdateChallengeStatus(challengeID, "accepted"); err != nil {
+		if err := UpdateChallengeStatus(challengeID, "accepted", ""); err != nil {
 			log.Printf("Failed to accept challenge: %v", err)
 			// Game was created, continue anyway
 		}
 
-		// Update PostgreSQL
-		_, err = db.Exec(`
-			UPDATE challenges
-			SET status = 'accepted', responded_at = NOW()
-			WHERE id = $1
-		`, challengeID)
+		publishChallengeStep(bus.ChallengeAccepted, bus.ChallengeEvent{
+			ChallengeID: challengeID, AppID: challenge.AppID, From: challenge.FromUser, Players: []string{challenge.ToUser},
+		})
 
-		if err != nil {
-			log.Printf("Failed to update challenge in database: %v", err)
-		}
+		go awardChallengePoints(challengeID, challenge.AppID, []string{challenge.FromUser, challenge.ToUser})
 
 		// Notify both players that game has started
 		log.Printf("📢 Notifying players: %s and %s about game %s", challenge.FromUser, challenge.ToUser, gameID)
@@ -450,12 +558,6 @@ func HandleAcceptChallenge(w http.ResponseWriter, r *http.Request) {
 
 // createGameForChallenge calls the game's API to create a new game
 func createGameForChallenge(challenge *Challenge, player1Name, player2Name string) (string, error) {
-	// Get the game backend URL from app registry
-	gameURL := getGameBackendURL(challenge.AppID)
-	if gameURL == "" {
-		return "", fmt.Errorf("unknown app: %s", challenge.AppID)
-	}
-
 	// Create game request with base fields
 	reqBody := map[string]interface{}{
 		"challengeId": challenge.ID,
@@ -486,53 +588,11 @@ func createGameForChallenge(challenge *Challenge, player1Name, player2Name strin
 		}
 	}
 
-	jsonBody, err := json.Marshal(reqBody)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	req, err := http.NewRequest("POST", gameURL+"/api/game", bytes.NewBuffer(jsonBody))
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer demo-token-"+challenge.FromUser)
-
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("failed to call game API: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("game API error: %s", string(body))
-	}
-
-	var result struct {
-		Success bool   `json:"success"`
-		GameID  string `json:"gameId"`
-	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", fmt.Errorf("failed to parse response: %w", err)
-	}
-
-	if !result.Success {
-		return "", fmt.Errorf("game creation failed")
-	}
-
-	return result.GameID, nil
+	return backends.CreateGame(context.Background(), challenge.AppID, "demo-token-"+challenge.FromUser, reqBody)
 }
 
 // createGameForMultiChallenge calls the game's API to create a multi-player game
 func createGameForMultiChallenge(challenge *Challenge) (string, error) {
-	// Get the game backend URL from app registry
-	gameURL := getGameBackendURL(challenge.AppID)
-	if gameURL == "" {
-		return "", fmt.Errorf("unknown app: %s", challenge.AppID)
-	}
-
 	// Build players array with names from presence
 	players := []map[string]interface{}{}
 	for _, playerID := range challenge.Accepted {
@@ -561,45 +621,9 @@ func createGameForMultiChallenge(challenge *Challenge) (string, error) {
 		}
 	}
 
-	jsonBody, err := json.Marshal(reqBody)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
-	}
-
 	log.Printf("🎮 Creating multi-player game with %d players", len(players))
 
-	req, err := http.NewRequest("POST", gameURL+"/api/game", bytes.NewBuffer(jsonBody))
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer demo-token-"+challenge.InitiatorID)
-
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("failed to call game API: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("game API error: %s", string(body))
-	}
-
-	var result struct {
-		Success bool   `json:"success"`
-		GameID  string `json:"gameId"`
-	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", fmt.Errorf("failed to parse response: %w", err)
-	}
-
-	if !result.Success {
-		return "", fmt.Errorf("game creation failed")
-	}
-
-	return result.GameID, nil
+	return backends.CreateGame(context.Background(), challenge.AppID, "demo-token-"+challenge.InitiatorID, reqBody)
 }
 
 // getGameBackendURL returns the backend URL for a game app from the registry
@@ -611,84 +635,71 @@ func getGameBackendURL(appID string) string {
 	return fmt.Sprintf("http://127.0.0.1:%d", app.BackendPort)
 }
 
-// HandleRejectChallenge - POST /api/lobby/challenge/reject
-// Rejects a challenge
+// HandleRejectChallenge - POST /api/lobby/challenge/reject?id={id}&reason={reason}
+// Rejects a challenge, optionally saying why (see declineReasons)
 func HandleRejectChallenge(w http.ResponseWriter, r *http.Request) {
 	challengeID := r.URL.Query().Get("id")
 	if challengeID == "" {
-		http.Error(w, "Challenge ID parameter required", http.StatusBadRequest)
+		http.Error(w, i18n.T(r, "challenge_id_required"), http.StatusBadRequest)
 		return
 	}
 
-	if err := UpdateChallengeStatus(challengeID, "rejected"); err != nil {
+	reason := r.URL.Query().Get("reason")
+	if _, ok := declineReasons[reason]; reason != "" && !ok {
+		http.Error(w, i18n.T(r, "decline_reason_unknown"), http.StatusBadRequest)
+		return
+	}
+
+	if err := UpdateChallengeStatus(challengeID, "rejected", reason); err != nil {
 		log.Printf("Failed to reject challenge: %v", err)
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	// Update PostgreSQL
-	_, err := db.Exec(`
-		UPDATE challenges
-		SET status = 'rejected', responded_at = NOW()
-		WHERE id = $1
-	`, challengeID)
-
-	if err != nil {
-		log.Printf("Failed to update challenge in database: %v", err)
-	}
+	publishChallengeStep(bus.ChallengeDeclined, bus.ChallengeEvent{ChallengeID: challengeID, Reason: reason})
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{"success": true})
 }
 
-// HandleLobbyStream - GET /api/lobby/stream
+// HandleLobbyStream - GET /api/lobby/stream?email={email}&deviceId={id}
 // Server-Sent Events endpoint for real-time lobby updates
 func HandleLobbyStream(w http.ResponseWriter, r *http.Request) {
 	email := r.URL.Query().Get("email")
 	if email == "" {
-		http.Error(w, "Email parameter required", http.StatusBadRequest)
+		http.Error(w, i18n.T(r, "email_required"), http.StatusBadRequest)
 		return
 	}
 
+	// Subscribe to user's Redis pub/sub channel
+	sub, err := SubscribeToUserEvents(r.Context(), email, r.URL.Query().Get("deviceId"))
+	if err != nil {
+		log.Printf("Failed to open lobby stream for %s: %v", email, err)
+		http.Error(w, "Live updates unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	defer sub.Close()
+
 	// Set SSE headers
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
 	w.Header().Set("Connection", "keep-alive")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-
-	// Subscribe to user's Redis pub/sub channel
-	pubsub := SubscribeToUserEvents(email)
-	defer pubsub.Close()
 
 	// Send initial connection event
-	fmt.Fprintf(w, "data: {\"type\":\"connected\"}\n\n")
-	if flusher, ok := w.(http.Flusher); ok {
-		flusher.Flush()
-	}
+	events.Send(w, evLobbyConnected.New("", events.NoPayload{}))
 
 	// Listen for events
-	ch := pubsub.Channel()
 	ticker := time.NewTicker(30 * time.Second)
 	defer ticker.Stop()
 
 	for {
 		select {
-		case msg := <-ch:
-			// Parse and send event to client
-			event := parseSSEPayload(msg.Payload)
-			data, _ := json.Marshal(event)
-			log.Printf("📤 SSE to %s: %s", email, string(data))
-			fmt.Fprintf(w, "data: %s\n\n", data)
-			if flusher, ok := w.(http.Flusher); ok {
-				flusher.Flush()
-			}
+		case msg := <-sub.C:
+			log.Printf("📤 SSE to %s: %s", email, msg)
+			events.Forward(w, msg)
 
 		case <-ticker.C:
-			// Send keepalive ping
-			fmt.Fprintf(w, ": ping\n\n")
-			if flusher, ok := w.(http.Flusher); ok {
-				flusher.Flush()
-			}
+			events.Send(w, events.Ping.New("", events.NoPayload{}))
 
 		case <-r.Context().Done():
 			// Client disconnected
@@ -697,26 +708,3 @@ func HandleLobbyStream(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 }
-
-// parseSSEPayload converts Redis pub/sub messages to SSE event format
-func parseSSEPayload(payload string) map[string]interface{} {
-	// Check for game_started:appId:gameId format
-	if len(payload) > 13 && payload[:13] == "game_started:" {
-		parts := payload[13:] // Remove "game_started:" prefix
-		// Find the separator between appId and gameId
-		for i, c := range parts {
-			if c == ':' {
-				appID := parts[:i]
-				gameID := parts[i+1:]
-				return map[string]interface{}{
-					"type":   "game_started",
-					"appId":  appID,
-					"gameId": gameID,
-				}
-			}
-		}
-	}
-
-	// Default: simple type message
-	return map[string]interface{}{"type": payload}
-}
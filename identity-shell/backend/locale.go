@@ -0,0 +1,42 @@
+package main
+
+import (
+	"embed"
+	"log"
+	"net/http"
+
+	"github.com/achgithub/activity-hub-common/i18n"
+)
+
+// The shell's own messages (lobby, challenges, presets) in every language it
+// speaks, on top of the platform catalogs built into the i18n package
+//
+//go:embed i18n/*.json
+var messages embed.FS
+
+func initMessages() {
+	if err := i18n.LoadFS(messages, "i18n"); err != nil {
+		log.Fatal("Failed to load message catalogs:", err)
+	}
+	log.Printf("🌐 Message catalogs: %v", i18n.Locales())
+}
+
+// userLocale puts the signed-in user's "locale" setting into the request
+// context, as authlib.Middleware does for the game backends. The shell's
+// handlers read the token themselves, so it isn't in the context otherwise.
+// Without a token or a setting, messages follow Accept-Language.
+func userLocale(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if email := extractEmailFromRequest(r); email != "" {
+			var locale string
+			err := db.QueryRow(`
+				SELECT COALESCE(value #>> '{}', '') FROM user_settings
+				WHERE user_email = $1 AND key = 'locale'
+			`, email).Scan(&locale)
+			if err == nil && locale != "" {
+				r = r.WithContext(i18n.WithLocale(r.Context(), locale))
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
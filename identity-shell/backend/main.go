@@ -1,17 +1,28 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/achgithub/activity-hub-common/analytics"
 	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/achgithub/activity-hub-common/cli"
+	"github.com/achgithub/activity-hub-common/events"
+	"github.com/achgithub/activity-hub-common/flags"
+	"github.com/achgithub/activity-hub-common/hours"
+	"github.com/achgithub/activity-hub-common/loadtest"
+	"github.com/achgithub/activity-hub-common/maintenance"
+	apphttp "github.com/achgithub/activity-hub-common/http"
+	"github.com/achgithub/activity-hub-common/services"
 	"github.com/google/uuid"
-	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
 	"github.com/lib/pq"
 	"golang.org/x/crypto/bcrypt"
@@ -20,8 +31,19 @@ import (
 var db *sql.DB
 
 func main() {
-	var err error
+	cli.New("identity-shell",
+		cli.Serve(serve),
+		// The identity schema itself is made by the scripts/migrate_add_*.sh
+		// scripts; an existing database needs migrate --baseline once
+		cli.Migrate(openDatabase, cli.Migrations{
+			Dir:      "../data/migrations",
+			Upgrades: []string{"001_add_multiplayer_challenges.sql", "002_add_challenge_decline_reason.sql"},
+		}),
+	).Main()
+}
 
+// openDatabase connects to the identity database
+func openDatabase() (*sql.DB, error) {
 	// Get database connection string from environment or use default
 	dbHost := getEnv("DB_HOST", "127.0.0.1") // Use TCP/IP for password auth
 	dbPort := getEnv("DB_PORT", "5555")      // Pi uses port 5555
@@ -32,17 +54,25 @@ func main() {
 	connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
 		dbHost, dbPort, dbUser, dbPass, dbName)
 
-	// Initialize database
-	db, err = sql.Open("postgres", connStr)
+	conn, err := sql.Open("postgres", connStr)
 	if err != nil {
-		log.Fatal("Failed to open database:", err)
+		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
-	defer db.Close()
+	if err := conn.Ping(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	return conn, nil
+}
+
+func serve() {
+	var err error
 
-	// Test connection
-	if err := db.Ping(); err != nil {
+	db, err = openDatabase()
+	if err != nil {
 		log.Fatal("Failed to connect to database:", err)
 	}
+	defer db.Close()
 
 	log.Println("✅ Connected to PostgreSQL database")
 
@@ -53,10 +83,40 @@ func main() {
 
 	log.Println("✅ Connected to Redis")
 
+	// Lobby streams share one Redis subscription per instance (see redis.go)
+	if err := initSSEBroker(); err != nil {
+		log.Fatal("Failed to start lobby stream broker:", err)
+	}
+
+	// Collect activity events from the game backends for the home page feed
+	go runActivityAggregator()
+	log.Printf("👥 Presence TTLs: online %s, away %s, in game %s", onlinePresenceTTL, awayPresenceTTL, inGamePresenceTTL)
+
+	// CORS: the platform's own pages only, per the app registry and admin-managed origins
+	corsPolicy = apphttp.NewCORSPolicy(db)
+	backends = services.NewRegistry(db)
+	featureFlags = flags.New(db)
+	appMaintenance = maintenance.New(db)
+	openingHours = hours.New(db)
+	initMessages()
+
 	// Load app registry
 	if err := LoadAppRegistry(); err != nil {
 		log.Printf("Warning: Failed to load app registry: %v", err)
 	}
+	go runCapabilityRefresh()
+
+	// Booked challenges: reminders, then sent at the booked time
+	go runScheduledChallenges()
+
+	// Idle sessions: warned, then logged out (off unless SESSION_IDLE_TIMEOUT_SECONDS is set)
+	go runSessionExpiry()
+
+	// Daily/weekly email digests for players who opt in
+	initDigest(context.Background())
+
+	// Challenge history is written from the event bus (see bus.go)
+	initBus(context.Background())
 
 	// Setup router
 	r := mux.NewRouter()
@@ -66,18 +126,78 @@ func main() {
 
 	// API routes
 	api := r.PathPrefix("/api").Subrouter()
+	api.Use(userLocale)
 	api.HandleFunc("/health", handleHealth).Methods("GET")
 	api.HandleFunc("/login", handleLogin).Methods("POST")
 	api.HandleFunc("/login/guest", handleGuestLogin).Methods("POST")
+	api.HandleFunc("/login/table", handleTableLogin).Methods("POST")
+	api.HandleFunc("/logout", handleLogout).Methods("POST")
+	api.HandleFunc("/session/activity", handleSessionActivity).Methods("POST")
 	api.HandleFunc("/validate", handleValidate).Methods("POST")
 	api.HandleFunc("/apps", handleGetApps).Methods("GET")
+	api.HandleFunc("/flags", handleGetFlags).Methods("GET")
+	api.HandleFunc("/events/schema", events.SchemaHandler).Methods("GET")
+	api.HandleFunc("/openapi.json", apiSpec().Handler).Methods("GET")
+
+	// Single-use signed tokens, so session tokens stay out of URLs
+	api.HandleFunc("/auth/stream-token", handleMintStreamToken).Methods("POST")
+	api.HandleFunc("/auth/launch-token", handleMintLaunchToken).Methods("POST")
+	api.HandleFunc("/auth/launch", handleExchangeLaunchToken).Methods("POST")
 
 	// User preferences endpoints (require authentication)
 	api.HandleFunc("/user/preferences", handleGetUserPreferences).Methods("GET")
 	api.HandleFunc("/user/preferences", handleUpdateUserPreferences).Methods("PUT")
+	api.HandleFunc("/user/preferences/settings", handlePatchUserSettings).Methods("PATCH")
+
+	// User profile (nickname, flair, avatar)
+	api.HandleFunc("/user/profile", handleGetUserProfile).Methods("GET")
+	api.HandleFunc("/user/profile", handleUpdateUserProfile).Methods("PUT")
+	api.HandleFunc("/user/profile/avatar", handleUploadAvatar).Methods("POST")
+	api.HandleFunc("/user/profile/avatar", handleDeleteAvatar).Methods("DELETE")
+	api.HandleFunc("/profiles", handleGetProfiles).Methods("GET")
+	api.HandleFunc("/avatars/{hash:[0-9a-f]{64}}", handleGetAvatar).Methods("GET")
+
+	// Challenge presets ("usual game" quick actions in the lobby)
+	api.HandleFunc("/user/challenge-presets", handleGetChallengePresets).Methods("GET")
+	api.HandleFunc("/user/challenge-presets", handleCreateChallengePreset).Methods("POST")
+	api.HandleFunc("/user/challenge-presets/{id:[0-9]+}", handleUpdateChallengePreset).Methods("PUT")
+	api.HandleFunc("/user/challenge-presets/{id:[0-9]+}", handleDeleteChallengePreset).Methods("DELETE")
+	api.HandleFunc("/user/challenge-presets/{id:[0-9]+}/challenge", handleSendPresetChallenge).Methods("POST")
+
+	// Game history across all apps and result confirmations (kept by the leaderboard)
+	api.HandleFunc("/user/games", handleGetUserGames).Methods("GET")
+	api.HandleFunc("/user/results/pending", handleGetPendingResults).Methods("GET")
+	api.HandleFunc("/user/results/{gameId}/confirm", handleConfirmResult).Methods("POST")
+	api.HandleFunc("/user/results/{gameId}/dispute", handleDisputeResult).Methods("POST")
+
+	// Loyalty points ("your points this month" on the profile page)
+	api.HandleFunc("/user/points", handleGetUserPoints).Methods("GET")
+
+	// Platform-wide activity feed (public: shown on the home page and TV displays)
+	api.HandleFunc("/activity", handleGetActivity).Methods("GET")
+	api.HandleFunc("/activity/stream", handleActivityStream).Methods("GET")
+
+	// Anonymous usage events from the frontends (token optional, only its venue is kept)
+	api.Handle("/analytics/events", authlib.OptionalMiddleware(db)(analytics.IngestHandler(db))).Methods("POST")
+
+	// In-game presence, set by game backends with a service token
+	api.HandleFunc("/presence/games/{appId}/{gameId}", requireService(handleSetGamePresence)).Methods("PUT")
+	api.HandleFunc("/presence/games/{appId}/{gameId}", requireService(handleClearGamePresence)).Methods("DELETE")
+
+	// Table devices: a shared tablet with a player profile per person at the table
+	api.HandleFunc("/table/players", handleGetTablePlayers).Methods("GET")
+	api.HandleFunc("/table/players", handleAddTablePlayer).Methods("POST")
+	api.HandleFunc("/table/players/{id:[0-9]+}", handleRemoveTablePlayer).Methods("DELETE")
+	api.HandleFunc("/table/players/{id:[0-9]+}/launch-token", handleMintTablePlayerLaunchToken).Methods("POST")
+	api.HandleFunc("/table/end", handleEndTable).Methods("POST")
+
+	// Privacy: personal data export and account deletion
+	api.HandleFunc("/user/data-export", handleExportUserData).Methods("GET")
+	api.HandleFunc("/user/data", handleDeleteUserData).Methods("DELETE")
 
 	// Lobby endpoints
 	lobby := r.PathPrefix("/api/lobby").Subrouter()
+	lobby.Use(userLocale)
 	lobby.HandleFunc("/presence", HandleGetPresence).Methods("GET")
 	lobby.HandleFunc("/presence", HandleUpdatePresence).Methods("POST")
 	lobby.HandleFunc("/presence/remove", HandleRemovePresence).Methods("POST")
@@ -87,6 +207,10 @@ func main() {
 	lobby.HandleFunc("/challenge/multi", HandleSendMultiChallenge).Methods("POST") // Multi-player challenges
 	lobby.HandleFunc("/challenge/accept", HandleAcceptChallenge).Methods("POST")
 	lobby.HandleFunc("/challenge/reject", HandleRejectChallenge).Methods("POST")
+	lobby.HandleFunc("/challenge/suggestions", HandleGetOpponentSuggestions).Methods("GET")
+	lobby.HandleFunc("/challenges/scheduled", HandleGetScheduledChallenges).Methods("GET")
+	lobby.HandleFunc("/challenge/scheduled", HandleScheduleChallenge).Methods("POST")
+	lobby.HandleFunc("/challenge/scheduled/cancel", HandleCancelScheduledChallenge).Methods("POST")
 	lobby.HandleFunc("/stream", HandleLobbyStream).Methods("GET")
 
 	// Admin endpoints (require setup_admin role)
@@ -94,10 +218,35 @@ func main() {
 	admin.HandleFunc("/apps", requireSetupAdmin(handleAdminGetApps)).Methods("GET")
 	admin.HandleFunc("/apps/{id}", requireSetupAdmin(handleAdminUpdateApp)).Methods("PUT")
 	admin.HandleFunc("/apps/{id}/{action:enable|disable}", requireSetupAdmin(handleAdminToggleApp)).Methods("POST")
+	admin.HandleFunc("/cors", requireSetupAdmin(handleAdminGetCORS)).Methods("GET")
+	admin.HandleFunc("/cors", requireSetupAdmin(handleAdminUpdateCORS)).Methods("PUT")
+
+	// Live platform stats (require super_user role)
+	admin.HandleFunc("/stats", requireSuperUser(handleAdminGetStats)).Methods("GET")
 
 	// Impersonation endpoints (require super_user role)
 	admin.HandleFunc("/impersonate", requireSuperUser(handleStartImpersonation)).Methods("POST")
 	admin.HandleFunc("/end-impersonation", handleEndImpersonation).Methods("POST")
+	admin.HandleFunc("/impersonation-log", requireSuperUser(handleGetImpersonationLog)).Methods("GET")
+
+	// Duplicate account merges (require super_user role)
+	admin.HandleFunc("/users/merge", requireSuperUser(handleAdminMergeUsers)).Methods("POST")
+	admin.HandleFunc("/users/merges", requireSuperUser(handleAdminGetUserMerges)).Methods("GET")
+	admin.HandleFunc("/users/merges/{id:[0-9]+}/publish", requireSuperUser(handleAdminPublishUserMerge)).Methods("POST")
+
+	// Scheduled jobs (the email digest): list, history and run now (require super_user role)
+	if digestScheduler != nil {
+		admin.HandleFunc("/jobs", requireSuperUser(digestScheduler.AdminHandler().ServeHTTP)).Methods("GET", "POST")
+	}
+
+	// Synthetic lobby users for rehearsing a busy night (require super_user role)
+	if loadtest.Enabled() {
+		admin.HandleFunc("/loadtest", requireSuperUser(handleStartLoadTest)).Methods("POST")
+		admin.HandleFunc("/loadtest", requireSuperUser(handleListLoadTests)).Methods("GET")
+		admin.HandleFunc("/loadtest/{id}", requireSuperUser(handleGetLoadTest)).Methods("GET")
+		admin.HandleFunc("/loadtest/{id}/stop", requireSuperUser(handleStopLoadTest)).Methods("POST")
+		log.Printf("🤖 Load test mode on: POST /api/admin/loadtest starts lobby bots")
+	}
 
 	// Serve frontend React app (includes /static/ for JS/CSS bundles)
 	frontendDir := "../frontend/build"
@@ -110,16 +259,15 @@ func main() {
 		http.ServeFile(w, r, frontendDir+"/index.html")
 	})
 
-	// CORS configuration - Allow requests from frontend on local network
-	corsHandler := handlers.CORS(
-		handlers.AllowedOrigins([]string{"*"}),
-		handlers.AllowedMethods([]string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}),
-		handlers.AllowedHeaders([]string{"Content-Type", "Authorization"}),
-	)
 
 	// Start server
-	log.Println("Identity Shell Backend starting on :3001")
-	log.Fatal(http.ListenAndServe(":3001", corsHandler(r)))
+	if cookieSessions {
+		log.Println("🍪 Cookie session mode enabled")
+	}
+	// PORT lets a second instance run alongside the first behind a load balancer
+	port := getEnv("PORT", "3001")
+	log.Printf("Identity Shell Backend starting on :%s", port)
+	log.Fatal(http.ListenAndServe(":"+port, corsPolicy.Middleware(cookieSessionMiddleware(idleSessionMiddleware(apphttp.Versioned(r))))))
 }
 
 func handleHealth(w http.ResponseWriter, r *http.Request) {
@@ -148,10 +296,12 @@ func handleLogin(w http.ResponseWriter, r *http.Request) {
 		CodeHash string
 		IsAdmin  bool
 		Roles    []string
+		IsActive bool
+		VenueID  int
 	}
 
-	err := db.QueryRow("SELECT email, name, code_hash, is_admin, COALESCE(roles, '{}') FROM users WHERE email = $1", req.Email).
-		Scan(&user.Email, &user.Name, &user.CodeHash, &user.IsAdmin, (*pq.StringArray)(&user.Roles))
+	err := db.QueryRow("SELECT email, name, code_hash, is_admin, COALESCE(roles, '{}'), COALESCE(is_active, TRUE), COALESCE(venue_id, 0) FROM users WHERE email = $1", req.Email).
+		Scan(&user.Email, &user.Name, &user.CodeHash, &user.IsAdmin, (*pq.StringArray)(&user.Roles), &user.IsActive, &user.VenueID)
 
 	if err == sql.ErrNoRows {
 		http.Error(w, "Invalid credentials", http.StatusUnauthorized)
@@ -168,19 +318,25 @@ func handleLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Deactivated accounts cannot log in
+	if !user.IsActive {
+		http.Error(w, "Account deactivated", http.StatusForbidden)
+		return
+	}
+
 	// Generate simple demo token
 	token := "demo-token-" + user.Email
 
-	json.NewEncoder(w).Encode(map[string]interface{}{
+	json.NewEncoder(w).Encode(withSession(w, token, user.Email, map[string]interface{}{
 		"success": true,
-		"token":   token,
 		"user": map[string]interface{}{
 			"email":    user.Email,
 			"name":     user.Name,
 			"is_admin": user.IsAdmin,
 			"roles":    user.Roles,
+			"venue_id": user.VenueID,
 		},
-	})
+	}))
 }
 
 func handleGuestLogin(w http.ResponseWriter, r *http.Request) {
@@ -189,9 +345,8 @@ func handleGuestLogin(w http.ResponseWriter, r *http.Request) {
 	guestToken := "guest-token-" + guestID
 
 	// Return guest token and user info
-	json.NewEncoder(w).Encode(map[string]interface{}{
+	json.NewEncoder(w).Encode(withSession(w, guestToken, "guest-"+guestID, map[string]interface{}{
 		"success": true,
-		"token":   guestToken,
 		"user": map[string]interface{}{
 			"email":    "guest-" + guestID,
 			"name":     "Guest",
@@ -199,7 +354,7 @@ func handleGuestLogin(w http.ResponseWriter, r *http.Request) {
 			"roles":    []string{},
 			"is_guest": true,
 		},
-	})
+	}))
 
 	log.Printf("✅ Guest login: %s", guestID)
 }
@@ -214,6 +369,21 @@ func handleValidate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Cookie sessions send no token; validate the session cookie instead
+	// (promoted to the Authorization header by cookieSessionMiddleware)
+	if req.Token == "" {
+		req.Token = strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	}
+
+	// Logged out after inactivity (see idle.go)
+	if sessionExpired(req.Token) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"valid":   false,
+			"expired": true,
+		})
+		return
+	}
+
 	// Check for guest token
 	if len(req.Token) > 12 && req.Token[:12] == "guest-token-" {
 		guestID := req.Token[12:]
@@ -231,6 +401,23 @@ func handleValidate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Check for table device or table player token
+	if strings.HasPrefix(req.Token, authlib.TableTokenPrefix) || strings.HasPrefix(req.Token, authlib.TablePlayerTokenPrefix) {
+		table, err := authlib.ResolveToken(db, req.Token)
+		if err != nil {
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"valid": false,
+			})
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"valid": true,
+			"user":  tableUserResponse(table),
+		})
+		return
+	}
+
 	// Check for impersonation token
 	if len(req.Token) > 12 && req.Token[:12] == "impersonate-" {
 		var session struct {
@@ -257,10 +444,11 @@ func handleValidate(w http.ResponseWriter, r *http.Request) {
 			Name    string
 			IsAdmin bool
 			Roles   []string
+			VenueID int
 		}
 
-		err = db.QueryRow("SELECT email, name, is_admin, COALESCE(roles, '{}') FROM users WHERE email = $1", session.ImpersonatedEmail).
-			Scan(&user.Email, &user.Name, &user.IsAdmin, (*pq.StringArray)(&user.Roles))
+		err = db.QueryRow("SELECT email, name, is_admin, COALESCE(roles, '{}'), COALESCE(venue_id, 0) FROM users WHERE email = $1 AND COALESCE(is_active, TRUE)", session.ImpersonatedEmail).
+			Scan(&user.Email, &user.Name, &user.IsAdmin, (*pq.StringArray)(&user.Roles), &user.VenueID)
 
 		if err != nil {
 			json.NewEncoder(w).Encode(map[string]interface{}{
@@ -276,6 +464,7 @@ func handleValidate(w http.ResponseWriter, r *http.Request) {
 				"name":          user.Name,
 				"is_admin":      user.IsAdmin,
 				"roles":         user.Roles,
+				"venue_id":      user.VenueID,
 				"impersonating": true,
 				"superUser":     session.SuperUserEmail,
 			},
@@ -293,10 +482,11 @@ func handleValidate(w http.ResponseWriter, r *http.Request) {
 			Name    string
 			IsAdmin bool
 			Roles   []string
+			VenueID int
 		}
 
-		err := db.QueryRow("SELECT email, name, is_admin, COALESCE(roles, '{}') FROM users WHERE email = $1", email).
-			Scan(&user.Email, &user.Name, &user.IsAdmin, (*pq.StringArray)(&user.Roles))
+		err := db.QueryRow("SELECT email, name, is_admin, COALESCE(roles, '{}'), COALESCE(venue_id, 0) FROM users WHERE email = $1 AND COALESCE(is_active, TRUE)", email).
+			Scan(&user.Email, &user.Name, &user.IsAdmin, (*pq.StringArray)(&user.Roles), &user.VenueID)
 
 		if err != nil {
 			json.NewEncoder(w).Encode(map[string]interface{}{
@@ -312,6 +502,7 @@ func handleValidate(w http.ResponseWriter, r *http.Request) {
 				"name":     user.Name,
 				"is_admin": user.IsAdmin,
 				"roles":    user.Roles,
+				"venue_id": user.VenueID,
 			},
 		})
 		return
@@ -351,14 +542,19 @@ func handleGetApps(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get apps filtered by user roles or guest access
+	// Venue comes from the user's account, or ?venue= for guests on a venue's kiosk/QR link
 	var userRoles []string
+	venueID, _ := strconv.Atoi(r.URL.Query().Get("venue"))
 	if user != nil {
 		userRoles = user.Roles
+		if user.VenueID != 0 {
+			venueID = user.VenueID
+		}
 	}
-	apps := GetAppsForUser(userRoles, isGuest)
+	apps := withCapabilities(withMaintenance(GetAppsForUser(userRoles, isGuest, venueID), user))
 
 	// Apply user preferences if authenticated (not guest)
-	if user != nil && !isGuest {
+	if user != nil && !isGuest && user.TableID == 0 {
 		apps = applyUserPreferences(apps, user.Email)
 	}
 
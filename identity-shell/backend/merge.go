@@ -0,0 +1,323 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/achgithub/activity-hub-common/accounts"
+	"github.com/gorilla/mux"
+	"github.com/lib/pq"
+)
+
+// Account merges: a player who signed up twice (usually a mistyped email) has
+// their history split across two accounts. A super_user folds the duplicate
+// into the account they keep. Identity data moves here in one transaction;
+// game backends hold their own per-user rows, so the merge is published on
+// accounts.MergedChannel for them to re-key. Every merge is kept in
+// user_merges and can be re-sent for a backend that was down at the time.
+
+// mergeSummary is what a merge moved in the identity database
+type mergeSummary struct {
+	RolesAdded          []string `json:"rolesAdded"`
+	SettingsMoved       int64    `json:"settingsMoved"`
+	AppPreferencesMoved int64    `json:"appPreferencesMoved"`
+	PresetsMoved        int64    `json:"presetsMoved"`
+	ProfileMoved        bool     `json:"profileMoved"`
+	PointsMoved         int64    `json:"pointsMoved"`
+	PointsDropped       int64    `json:"pointsDropped"` // Awards the kept account already had for the same activity
+	RedemptionsMoved    int64    `json:"redemptionsMoved"`
+}
+
+// UserMerge is one entry in the merge log
+type UserMerge struct {
+	ID          int             `json:"id"`
+	FromEmail   string          `json:"fromEmail"`
+	ToEmail     string          `json:"toEmail"`
+	MergedBy    string          `json:"mergedBy"`
+	Reason      string          `json:"reason,omitempty"`
+	Summary     json.RawMessage `json:"summary"`
+	MergedAt    time.Time       `json:"mergedAt"`
+	PublishedAt *time.Time      `json:"publishedAt,omitempty"`
+}
+
+// handleAdminMergeUsers - POST /api/admin/users/merge {fromEmail, toEmail, reason}
+// Moves fromEmail's roles, settings, app preferences, profile and points onto
+// toEmail and deletes fromEmail. Where both accounts have something, toEmail's wins.
+func handleAdminMergeUsers(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		FromEmail string `json:"fromEmail"`
+		ToEmail   string `json:"toEmail"`
+		Reason    string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+	from := strings.TrimSpace(req.FromEmail)
+	to := strings.TrimSpace(req.ToEmail)
+	reason := strings.TrimSpace(req.Reason)
+	if from == "" || to == "" {
+		http.Error(w, "fromEmail and toEmail are required", http.StatusBadRequest)
+		return
+	}
+	if from == to {
+		http.Error(w, "Cannot merge an account into itself", http.StatusBadRequest)
+		return
+	}
+	superUser := extractEmailFromRequest(r)
+	if from == superUser {
+		http.Error(w, "Cannot merge away your own account", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("Failed to begin transaction: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	// Lock both accounts so nothing else changes their roles mid-merge
+	roles := map[string][]string{}
+	admin := map[string]bool{}
+	rows, err := tx.Query(`
+		SELECT email, COALESCE(roles, '{}'), is_admin FROM users
+		WHERE email = ANY($1) FOR UPDATE
+	`, pq.Array([]string{from, to}))
+	if err != nil {
+		log.Printf("Merge: failed to load accounts: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	for rows.Next() {
+		var email string
+		var userRoles pq.StringArray
+		var isAdmin bool
+		if err := rows.Scan(&email, &userRoles, &isAdmin); err != nil {
+			rows.Close()
+			log.Printf("Merge: failed to read account: %v", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		roles[email] = userRoles
+		admin[email] = isAdmin
+	}
+	rows.Close()
+	for _, email := range []string{from, to} {
+		if _, ok := roles[email]; !ok {
+			http.Error(w, "User not found: "+email, http.StatusNotFound)
+			return
+		}
+	}
+
+	summary := mergeSummary{RolesAdded: []string{}}
+	kept := roles[to]
+	for _, role := range roles[from] {
+		if !containsString(kept, role) {
+			kept = append(kept, role)
+			summary.RolesAdded = append(summary.RolesAdded, role)
+		}
+	}
+	_, err = tx.Exec("UPDATE users SET roles = $2, is_admin = is_admin OR $3 WHERE email = $1",
+		to, pq.Array(kept), admin[from])
+
+	// Each move is followed by a delete of whatever the kept account already had
+	moves := []struct {
+		stmt  string
+		count *int64
+	}{
+		{`UPDATE user_settings f SET user_email = $2 WHERE user_email = $1
+		  AND NOT EXISTS (SELECT 1 FROM user_settings t WHERE t.user_email = $2 AND t.key = f.key)`, &summary.SettingsMoved},
+		{"DELETE FROM user_settings WHERE user_email = $1", nil},
+		{`UPDATE user_app_preferences f SET user_email = $2 WHERE user_email = $1
+		  AND NOT EXISTS (SELECT 1 FROM user_app_preferences t WHERE t.user_email = $2 AND t.app_id = f.app_id)`, &summary.AppPreferencesMoved},
+		{"DELETE FROM user_app_preferences WHERE user_email = $1", nil},
+		{"DELETE FROM user_preference_versions WHERE user_email = $1", nil},
+		{`UPDATE challenge_presets f SET user_email = $2 WHERE user_email = $1
+		  AND NOT EXISTS (SELECT 1 FROM challenge_presets t WHERE t.user_email = $2 AND t.name = f.name)`, &summary.PresetsMoved},
+		{"DELETE FROM challenge_presets WHERE user_email = $1", nil},
+		// Other players' presets name the kept account instead (never its own)
+		{`UPDATE challenge_presets SET opponents = array_remove(array_replace(opponents, $1, $2), user_email)
+		  WHERE $1 = ANY(opponents)`, nil},
+		{"UPDATE scheduled_challenges SET from_user = $2 WHERE from_user = $1", nil},
+		{"UPDATE scheduled_challenges SET to_user = $2 WHERE to_user = $1", nil},
+		{`DELETE FROM points_ledger f USING points_ledger t
+		  WHERE f.user_email = $1 AND t.user_email = $2 AND t.activity = f.activity AND t.ref = f.ref`, &summary.PointsDropped},
+		{"UPDATE points_ledger SET user_email = $2 WHERE user_email = $1", &summary.PointsMoved},
+		{"UPDATE points_redemptions SET user_email = $2 WHERE user_email = $1", &summary.RedemptionsMoved},
+		{"UPDATE table_devices SET created_by = $2 WHERE created_by = $1", nil},
+	}
+	for _, m := range moves {
+		if err != nil {
+			break
+		}
+		var res sql.Result
+		res, err = tx.Exec(m.stmt, from, to)
+		if err == nil && m.count != nil {
+			*m.count, _ = res.RowsAffected()
+		}
+	}
+
+	// The profile moves only if the kept account has none; otherwise it goes with the user row
+	if err == nil {
+		var res sql.Result
+		res, err = tx.Exec(`
+			UPDATE user_profiles SET user_email = $2 WHERE user_email = $1
+			AND NOT EXISTS (SELECT 1 FROM user_profiles WHERE user_email = $2)
+		`, from, to)
+		if err == nil {
+			n, _ := res.RowsAffected()
+			summary.ProfileMoved = n > 0
+		}
+	}
+
+	// Devices that had the kept account's preferences cached need to refetch
+	if err == nil && summary.SettingsMoved+summary.AppPreferencesMoved > 0 {
+		_, err = tx.Exec(`
+			INSERT INTO user_preference_versions (user_email, version) VALUES ($1, 1)
+			ON CONFLICT (user_email) DO UPDATE
+			SET version = user_preference_versions.version + 1, updated_at = CURRENT_TIMESTAMP
+		`, to)
+	}
+	if err == nil {
+		_, err = tx.Exec("UPDATE impersonation_sessions SET is_active = FALSE, ended_at = COALESCE(ended_at, CURRENT_TIMESTAMP) WHERE impersonated_email = $1", from)
+	}
+	if err == nil {
+		_, err = tx.Exec("DELETE FROM users WHERE email = $1", from)
+	}
+
+	merge := accounts.Merge{From: from, To: to, MergedBy: superUser}
+	if err == nil {
+		summaryJSON, _ := json.Marshal(summary)
+		err = tx.QueryRow(`
+			INSERT INTO user_merges (from_email, to_email, merged_by, reason, summary)
+			VALUES ($1, $2, $3, NULLIF($4, ''), $5)
+			RETURNING id, merged_at
+		`, from, to, superUser, reason, summaryJSON).Scan(&merge.ID, &merge.MergedAt)
+	}
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		log.Printf("Failed to merge %s into %s: %v", from, to, err)
+		http.Error(w, "Failed to merge accounts", http.StatusInternalServerError)
+		return
+	}
+
+	RemoveUserPresence(from)
+	published := publishUserMerge(merge)
+
+	log.Printf("🔀 Merged account %s into %s (by %s)", from, to, superUser)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":   true,
+		"mergeId":   merge.ID,
+		"summary":   summary,
+		"published": published,
+	})
+}
+
+// publishUserMerge tells the game backends about a merge and records when it
+// went out. Reports whether it was published.
+func publishUserMerge(m accounts.Merge) bool {
+	msg, err := accounts.Encode(m)
+	if err != nil {
+		log.Printf("⚠️  Invalid account merge %d: %v", m.ID, err)
+		return false
+	}
+	if err := redisClient.Publish(ctx, accounts.MergedChannel, msg).Err(); err != nil {
+		log.Printf("⚠️  Failed to publish account merge %d: %v", m.ID, err)
+		return false
+	}
+	if _, err := db.Exec("UPDATE user_merges SET published_at = CURRENT_TIMESTAMP WHERE id = $1", m.ID); err != nil {
+		log.Printf("⚠️  Failed to record publish of account merge %d: %v", m.ID, err)
+	}
+	return true
+}
+
+// handleAdminGetUserMerges - GET /api/admin/users/merges?limit={n}
+// Most recent merges first
+func handleAdminGetUserMerges(w http.ResponseWriter, r *http.Request) {
+	limit := 50
+	if n, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && n > 0 && n <= 500 {
+		limit = n
+	}
+
+	rows, err := db.Query(`
+		SELECT id, from_email, to_email, merged_by, COALESCE(reason, ''), summary, merged_at, published_at
+		FROM user_merges
+		ORDER BY merged_at DESC
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		log.Printf("Failed to load account merges: %v", err)
+		http.Error(w, "Failed to load merges", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	merges := []UserMerge{}
+	for rows.Next() {
+		var m UserMerge
+		var summary []byte
+		var publishedAt sql.NullTime
+		if err := rows.Scan(&m.ID, &m.FromEmail, &m.ToEmail, &m.MergedBy, &m.Reason, &summary, &m.MergedAt, &publishedAt); err != nil {
+			continue
+		}
+		m.Summary = summary
+		if publishedAt.Valid {
+			m.PublishedAt = &publishedAt.Time
+		}
+		merges = append(merges, m)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"merges": merges})
+}
+
+// handleAdminPublishUserMerge - POST /api/admin/users/merges/{id}/publish
+// Sends a merge to the game backends again, e.g. after one was down when it happened
+func handleAdminPublishUserMerge(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid merge ID", http.StatusBadRequest)
+		return
+	}
+
+	m := accounts.Merge{ID: id}
+	err = db.QueryRow("SELECT from_email, to_email, merged_by, merged_at FROM user_merges WHERE id = $1", id).
+		Scan(&m.From, &m.To, &m.MergedBy, &m.MergedAt)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Merge not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Printf("Failed to load account merge %d: %v", id, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if !publishUserMerge(m) {
+		http.Error(w, "Failed to publish merge", http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"success": true})
+}
+
+// containsString reports whether list holds s
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,316 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/achgithub/activity-hub-common/activity"
+	"github.com/achgithub/activity-hub-common/analytics"
+	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/achgithub/activity-hub-common/jobs"
+	"github.com/achgithub/activity-hub-common/loadtest"
+	"github.com/achgithub/activity-hub-common/openapi"
+	"github.com/achgithub/activity-hub-common/points"
+)
+
+// Response shapes shared by several routes
+var (
+	success     = openapi.Fields{"success": true}
+	successMsg  = openapi.Fields{"success": true, "message": ""}
+	sessionUser = openapi.Fields{
+		"email": "", "name": "", "is_admin": true, "roles": []string{}, "venue_id": 0,
+		"is_guest": true, "impersonating": true, "superUser": "", "is_table": true, "table_id": 0,
+	}
+	// token is set for bearer sessions, session: "cookie" in cookie session mode
+	loginResult = openapi.Fields{"success": true, "user": sessionUser, "token": "", "session": ""}
+	signedToken = openapi.Fields{"token": "", "expiresAt": ""}
+	challenges  = openapi.Fields{"challenges": []Challenge{}}
+)
+
+// apiSpec describes the routes registered in main.go for GET /api/openapi.json.
+// Add a route here whenever one is added there.
+func apiSpec() *openapi.Spec {
+	spec := openapi.New("identity-shell", "1.0.0",
+		"Login, sessions, the app registry, user profiles and preferences, the lobby and platform admin. "+
+			"Lobby routes identify the user by the email parameter; admin routes need the setup_admin or super_user role.").
+		TextErrors()
+
+	public := spec.Group("Public")
+	public.Route("GET", "/api/health", "Health check").
+		Returns(http.StatusOK, openapi.Fields{"status": "", "service": "", "timestamp": ""})
+	public.Route("GET", "/api/apps", "Apps the caller can launch (a token is optional)").
+		Query("venue", "Venue ID for guests on a venue's kiosk or QR link").
+		Returns(http.StatusOK, openapi.Fields{"apps": []AppDefinition{}})
+	public.Route("GET", "/api/flags", "Feature flags evaluated for the caller (a token is optional)").
+		Returns(http.StatusOK, openapi.Fields{"flags": map[string]bool{}})
+	public.Route("GET", "/api/events/schema", "JSON Schema of the lobby stream events").
+		Produces(http.StatusOK, "application/schema+json")
+	public.Route("GET", "/api/openapi.json", "This document").
+		Returns(http.StatusOK, nil)
+	public.Route("GET", "/api/profiles", "Profiles for a list of players").
+		Query("emails", "Comma-separated emails (max 200)").
+		Returns(http.StatusOK, openapi.Fields{"profiles": map[string]authlib.Profile{}})
+	public.Route("GET", "/api/avatars/{hash:[0-9a-f]{64}}", "An avatar image by content hash").
+		Produces(http.StatusOK, "image/*")
+	public.Route("GET", "/api/activity", "Recent activity feed, newest first").
+		Query("limit", "Maximum events").
+		Query("before", "Only events before this time (RFC3339)").
+		Query("venue", "Only events at this venue").
+		Returns(http.StatusOK, openapi.Fields{"events": []activity.Event{}, "hasMore": true})
+	public.Route("GET", "/api/activity/stream", "New activity events as they are published").
+		Query("venue", "Only events at this venue").
+		Stream()
+	public.Route("POST", "/api/analytics/events", "Log an anonymous usage event (a token is optional; only its venue is kept)").
+		Body(openapi.Fields{"event": analytics.EventAppOpened, "app": "", "value": 0.0}).
+		Returns(http.StatusNoContent, nil)
+
+	auth := spec.Group("Auth")
+	auth.Route("POST", "/api/login", "Log in").
+		Body(openapi.Fields{"email": "", "code": ""}).
+		Returns(http.StatusOK, loginResult)
+	auth.Route("POST", "/api/login/guest", "Start a guest session").
+		Returns(http.StatusOK, loginResult)
+	auth.Route("POST", "/api/logout", "End a cookie session").
+		Returns(http.StatusOK, success)
+	auth.Route("POST", "/api/validate", "Check a session token (expired: logged out after inactivity)").
+		Body(openapi.Fields{"token": ""}).
+		Returns(http.StatusOK, openapi.Fields{"valid": true, "user": sessionUser, "expired": true})
+	auth.Route("POST", "/api/auth/launch", "Swap a mini-app launch token for the session token").
+		Body(openapi.Fields{"launch": "", "cookie": true}).
+		Returns(http.StatusOK, openapi.Fields{"user": sessionUser, "token": "", "session": ""})
+
+	tokens := spec.Group("Auth").Auth()
+	tokens.Route("POST", "/api/session/activity",
+		"User activity; keeps the session from the inactivity timeout (timeout 0 = no timeout, 401 once expired)").
+		Returns(http.StatusOK, openapi.Fields{"idleTimeoutSeconds": 0, "warningSeconds": 0, "expiresAt": 0})
+	tokens.Route("POST", "/api/auth/stream-token", "Single-use token for one EventSource connection").
+		Returns(http.StatusOK, signedToken)
+	tokens.Route("POST", "/api/auth/launch-token", "Single-use token for opening a mini-app").
+		Returns(http.StatusOK, signedToken)
+	tokens.Route("POST", "/api/login/table", "Sign this device in as a table (staff session, replaced by the table's)").
+		Body(openapi.Fields{"name": ""}).
+		Returns(http.StatusOK, loginResult)
+
+	// Table device session: players at a shared tablet
+	table := spec.Group("Table").Auth()
+	table.Route("GET", "/api/table/players", "The table's players").
+		Returns(http.StatusOK, openapi.Fields{"players": []TablePlayer{}})
+	table.Route("POST", "/api/table/players", "Add a player to the table (409 if the name is taken)").
+		Body(openapi.Fields{"name": "", "flair": ""}).
+		Returns(http.StatusOK, openapi.Fields{"success": true, "player": TablePlayer{}})
+	table.Route("DELETE", "/api/table/players/{id}", "Remove a player from the table").
+		Returns(http.StatusOK, success)
+	table.Route("POST", "/api/table/players/{id}/launch-token", "Single-use token for opening a mini-app as a player").
+		Returns(http.StatusOK, openapi.Fields{"token": "", "player": TablePlayer{}})
+	table.Route("POST", "/api/table/end", "Sign the table and its players out").
+		Returns(http.StatusOK, success)
+
+	user := spec.Group("User").Auth()
+	user.Route("GET", "/api/user/preferences", "App preferences and settings").
+		Returns(http.StatusOK, openapi.Fields{
+			"preferences": []UserAppPreference{}, "settings": map[string]interface{}{}, "version": 0,
+		})
+	user.Route("PUT", "/api/user/preferences", "Replace app preferences and settings (409 if changed on another device)").
+		Body(openapi.Fields{"preferences": []UserAppPreference{}, "settings": map[string]interface{}{}}).
+		Returns(http.StatusOK, openapi.Fields{"success": true, "message": "", "version": 0}).
+		Returns(http.StatusConflict, openapi.Fields{"error": "", "version": 0})
+	user.Route("PATCH", "/api/user/preferences/settings", "Change some settings").
+		Body(openapi.Fields{"settings": map[string]interface{}{}}).
+		Returns(http.StatusOK, openapi.Fields{"success": true, "settings": map[string]interface{}{}, "version": 0})
+	user.Route("GET", "/api/user/profile", "The caller's profile").
+		Returns(http.StatusOK, openapi.Fields{"profile": authlib.Profile{}, "displayName": ""})
+	user.Route("PUT", "/api/user/profile", "Set nickname and flair").
+		Body(openapi.Fields{"nickname": "", "flair": ""}).
+		Returns(http.StatusOK, success)
+	user.Route("POST", "/api/user/profile/avatar", "Upload an avatar (PNG, JPEG, GIF or WebP up to 256KB)").
+		Upload("avatar").
+		Returns(http.StatusOK, openapi.Fields{"success": true, "avatarUrl": ""})
+	user.Route("DELETE", "/api/user/profile/avatar", "Remove the avatar").
+		Returns(http.StatusOK, success)
+	user.Route("GET", "/api/user/points", "Points this month, monthly tallies, balance, recent awards and rules").
+		Returns(http.StatusOK, openapi.Fields{
+			"month": points.Tally{}, "months": []points.Tally{}, "balance": 0,
+			"recent": []PointsEntry{}, "rules": []PointsRule{},
+		})
+	user.Route("GET", "/api/user/challenge-presets", "Saved challenge presets, most recently used first").
+		Returns(http.StatusOK, openapi.Fields{"presets": []ChallengePreset{}})
+	user.Route("POST", "/api/user/challenge-presets", "Save a challenge preset (409 if the name is taken)").
+		Body(presetRequest{}).
+		Returns(http.StatusOK, openapi.Fields{"success": true, "preset": ChallengePreset{}})
+	user.Route("PUT", "/api/user/challenge-presets/{id}", "Change a challenge preset (409 if the name is taken)").
+		Body(presetRequest{}).
+		Returns(http.StatusOK, openapi.Fields{"success": true, "preset": ChallengePreset{}})
+	user.Route("DELETE", "/api/user/challenge-presets/{id}", "Delete a challenge preset").
+		Returns(http.StatusOK, success)
+	user.Route("POST", "/api/user/challenge-presets/{id}/challenge",
+		"Challenge the preset's free opponents (409 if too few are free, 410 if the game has gone)").
+		Query("deviceId", "Sending device").
+		Returns(http.StatusOK, openapi.Fields{"success": true, "challengeId": "", "appId": "", "challenged": []string{}})
+	user.Route("GET", "/api/user/data-export", "Everything held about the caller").
+		Returns(http.StatusOK, openapi.Fields{
+			"exportedAt": "", "account": openapi.Fields{}, "profile": openapi.Fields{}, "settings": map[string]interface{}{},
+			"appPreferences": []openapi.Fields{}, "challengePresets": []ChallengePreset{},
+			"impersonations": []openapi.Fields{}, "points": []openapi.Fields{},
+			"gameResultsNote": "",
+		})
+	user.Route("DELETE", "/api/user/data", "Delete the caller's account and personal data").
+		Body(openapi.Fields{"confirm": "DELETE"}).
+		Returns(http.StatusOK, successMsg)
+
+	// History is kept by the leaderboard app; these pass through to it
+	history := spec.Group("History").Auth()
+	history.Route("GET", "/api/user/games", "Completed games across all apps").
+		Query("app", "Only games of this app").
+		Query("limit", "Maximum games").
+		Query("before", "Only games before this time (RFC3339)").
+		Returns(http.StatusOK, nil)
+	history.Route("GET", "/api/user/results/pending", "Results waiting for the caller to confirm or dispute").
+		Returns(http.StatusOK, nil)
+	history.Route("POST", "/api/user/results/{gameId}/confirm", "Confirm a result").
+		Returns(http.StatusOK, nil)
+	history.Route("POST", "/api/user/results/{gameId}/dispute", "Dispute a result").
+		Body(openapi.Fields{"reason": ""}).
+		Returns(http.StatusOK, nil)
+
+	lobby := spec.Group("Lobby")
+	lobby.Route("GET", "/api/lobby/presence", "Online users").
+		Returns(http.StatusOK, openapi.Fields{"users": []UserPresence{}, "count": 0})
+	lobby.Route("POST", "/api/lobby/presence", "Presence heartbeat from a device").
+		Body(openapi.Fields{"email": "", "name": "", "status": "", "currentApp": "", "deviceId": ""}).
+		Returns(http.StatusOK, success)
+	lobby.Route("POST", "/api/lobby/presence/remove", "Remove a device's presence, or all of a user's").
+		Query("email", "User email").
+		Query("deviceId", "Device to remove").
+		Returns(http.StatusOK, success)
+	lobby.Route("GET", "/api/lobby/challenges", "Challenges received").
+		Query("email", "User email").
+		Returns(http.StatusOK, challenges)
+	lobby.Route("GET", "/api/lobby/challenges/sent", "Challenges sent").
+		Query("email", "User email").
+		Returns(http.StatusOK, challenges)
+	lobby.Route("POST", "/api/lobby/challenge", "Challenge another player (403 while the venue has closed the lobby, 409 while they're in a game)").
+		Query("deviceId", "Sending device").
+		Body(openapi.Fields{"fromUser": "", "toUser": "", "appId": "", "options": openapi.Fields{}}).
+		Returns(http.StatusOK, openapi.Fields{"success": true, "challengeId": ""})
+	lobby.Route("POST", "/api/lobby/challenge/multi", "Challenge several players (403 while the venue has closed the lobby, 409 while one is in a game)").
+		Query("deviceId", "Sending device").
+		Body(openapi.Fields{
+			"initiatorId": "", "playerIds": []string{}, "appId": "",
+			"minPlayers": 0, "maxPlayers": 0, "options": openapi.Fields{},
+		}).
+		Returns(http.StatusOK, openapi.Fields{"success": true, "challengeId": ""})
+	lobby.Route("POST", "/api/lobby/challenge/accept", "Accept a challenge; gameId is set once the game starts").
+		Query("id", "Challenge ID").
+		Query("userId", "Accepting player (multi-player challenges)").
+		Query("deviceId", "Accepting device").
+		Returns(http.StatusOK, openapi.Fields{
+			"success": true, "gameId": "", "appId": "", "readyToStart": true, "accepted": 0, "minPlayers": 0,
+		})
+	lobby.Route("POST", "/api/lobby/challenge/reject", "Reject a challenge").
+		Query("id", "Challenge ID").
+		Query("reason", "Why it was rejected").
+		Returns(http.StatusOK, success)
+	lobby.Route("GET", "/api/lobby/challenge/suggestions", "Opponents to suggest for an app").
+		Query("email", "User email").
+		Query("appId", "App to play").
+		Query("exclude", "Comma-separated emails to leave out").
+		Returns(http.StatusOK, openapi.Fields{"suggestions": []OpponentSuggestion{}})
+	lobby.Route("GET", "/api/lobby/challenges/scheduled", "Booked challenges, sent or received, by day").
+		Query("email", "User email").
+		Query("from", "First day (YYYY-MM-DD, default today)").
+		Query("days", "Days to list (default 14, max 62)").
+		Returns(http.StatusOK, openapi.Fields{"from": "", "days": []CalendarDay{}})
+	lobby.Route("POST", "/api/lobby/challenge/scheduled", "Book a 2-player challenge for later; it's sent at that time if both players are free").
+		Body(openapi.Fields{"fromUser": "", "toUser": "", "appId": "", "options": openapi.Fields{}, "scheduledAt": ""}).
+		Returns(http.StatusOK, openapi.Fields{"success": true, "scheduled": ScheduledChallenge{}})
+	lobby.Route("POST", "/api/lobby/challenge/scheduled/cancel", "Cancel a booked challenge that hasn't been sent (either player)").
+		Query("id", "Booking ID").
+		Query("email", "User email").
+		Returns(http.StatusOK, success)
+
+	// Called by game backends with a service token (services.Registry.SetInGame)
+	gamePresence := spec.Group("Game presence").Auth()
+	gamePresence.Route("PUT", "/api/presence/games/{appId}/{gameId}", "Mark players as in a game of the calling app").
+		Body(openapi.Fields{"players": []string{}}).
+		Returns(http.StatusOK, success)
+	gamePresence.Route("DELETE", "/api/presence/games/{appId}/{gameId}", "A game of the calling app ended; free its players").
+		Returns(http.StatusOK, success)
+	lobby.Route("GET", "/api/lobby/stream", "Presence and challenge events for a user").
+		Query("email", "User email").
+		Query("deviceId", "Listening device").
+		Stream()
+
+	admin := spec.Group("Admin").Auth()
+	admin.Route("GET", "/api/admin/apps", "All registered apps").
+		Returns(http.StatusOK, openapi.Fields{"apps": []openapi.Fields{{
+			"id": "", "name": "", "icon": "", "type": "", "description": "", "category": "", "url": "",
+			"backendPort": 0, "realtime": "", "requiredRoles": []string{}, "enabled": true, "displayOrder": 0,
+			"minPlayers": 0, "maxPlayers": 0, "createdAt": "", "updatedAt": "",
+		}}})
+	admin.Route("PUT", "/api/admin/apps/{id}", "Update an app").
+		Body(openapi.Fields{
+			"name": "", "icon": "", "description": "", "category": "", "url": "", "backendPort": 0,
+			"realtime": "", "minPlayers": 0, "maxPlayers": 0, "requiredRoles": []string{},
+			"enabled": true, "displayOrder": 0,
+		}).
+		Returns(http.StatusOK, successMsg)
+	admin.Route("POST", "/api/admin/apps/{id}/{action:enable|disable}", "Enable or disable an app").
+		Returns(http.StatusOK, successMsg)
+	admin.Route("GET", "/api/admin/cors", "Allowed CORS hosts and origins").
+		Returns(http.StatusOK, openapi.Fields{"environment": "", "hosts": []string{}, "origins": []string{}, "ports": []int{}})
+	admin.Route("PUT", "/api/admin/cors", "Set allowed CORS hosts and origins").
+		Body(openapi.Fields{"hosts": []string{}, "origins": []string{}}).
+		Returns(http.StatusOK, openapi.Fields{"success": true, "hosts": []string{}, "origins": []string{}})
+	admin.Route("GET", "/api/admin/stats", "Live platform overview").
+		Returns(http.StatusOK, openapi.Fields{
+			"generatedAt": 0,
+			"users":       openapi.Fields{"online": 0, "byStatus": map[string]int{}, "devices": 0},
+			"challenges":  openapi.Fields{"pending": 0},
+			"sse":         openapi.Fields{"lobbyConnections": 0},
+			"services":    map[string]string{},
+			"apps":        []AppStats{},
+		})
+	admin.Route("POST", "/api/admin/impersonate", "Act as another user").
+		Body(openapi.Fields{"targetEmail": ""}).
+		Returns(http.StatusOK, openapi.Fields{"success": true, "token": "", "user": sessionUser})
+	admin.Route("POST", "/api/admin/end-impersonation", "Return to the super user's own session").
+		Returns(http.StatusOK, loginResult)
+	admin.Route("GET", "/api/admin/impersonation-log", "Impersonation sessions and what was done in them").
+		Query("superUser", "Only this super user's sessions").
+		Query("limit", "Maximum entries").
+		Returns(http.StatusOK, openapi.Fields{
+			"sessions": []openapi.Fields{{"id": 0, "superUserEmail": "", "impersonatedEmail": "", "isActive": true}},
+			"activity": []openapi.Fields{{
+				"superUserEmail": "", "impersonatedEmail": "", "service": "", "method": "", "path": "", "createdAt": "",
+			}},
+		})
+	admin.Route("POST", "/api/admin/users/merge", "Merge a duplicate account into another and tell the game backends").
+		Body(openapi.Fields{"fromEmail": "", "toEmail": "", "reason": ""}).
+		Returns(http.StatusOK, openapi.Fields{"success": true, "mergeId": 0, "summary": mergeSummary{}, "published": true})
+	admin.Route("GET", "/api/admin/users/merges", "Account merges, most recent first").
+		Query("limit", "Maximum entries").
+		Returns(http.StatusOK, openapi.Fields{"merges": []UserMerge{}})
+	admin.Route("POST", "/api/admin/users/merges/{id}/publish", "Send a merge to the game backends again").
+		Returns(http.StatusOK, openapi.Fields{"success": true})
+	admin.Route("GET", "/api/admin/jobs", "Scheduled jobs (the email digest), or one job's run history with ?job=").
+		Query("job", "Job name, e.g. email-digest").
+		Query("limit", "Maximum runs").
+		Returns(http.StatusOK, openapi.Fields{"jobs": []jobs.JobInfo{}})
+	admin.Route("POST", "/api/admin/jobs", "Run a job now").
+		Query("job", "Job name").
+		Returns(http.StatusAccepted, openapi.Fields{"success": true, "job": ""})
+
+	// Only registered with LOAD_TEST=true
+	if loadtest.Enabled() {
+		admin.Route("POST", "/api/admin/loadtest", "Start synthetic lobby users (409 while another run is going)").
+			Body(openapi.Fields{"bots": 0, "rampUpSeconds": 0, "durationSeconds": 0}).
+			Returns(http.StatusAccepted, loadtest.Summary{})
+		admin.Route("GET", "/api/admin/loadtest", "Recent load test runs, newest first").
+			Returns(http.StatusOK, openapi.Fields{"runs": []loadtest.Summary{}})
+		admin.Route("GET", "/api/admin/loadtest/{id}", "A run's latency and error summary so far").
+			Returns(http.StatusOK, loadtest.Summary{})
+		admin.Route("POST", "/api/admin/loadtest/{id}/stop", "Stop a run and wait for its bots to leave").
+			Returns(http.StatusOK, loadtest.Summary{})
+	}
+
+	return spec
+}
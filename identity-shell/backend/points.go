@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/achgithub/activity-hub-common/points"
+)
+
+// Loyalty points: apps award points with activity-hub-common points; the shell
+// shows players their points and awards challenge_played itself when a
+// challenge from the lobby turns into a game.
+
+// pointsHistoryMonths is how many monthly tallies the profile page shows
+const pointsHistoryMonths = 6
+
+// PointsEntry is one award in the user's recent points
+type PointsEntry struct {
+	Activity  string    `json:"activity"`
+	App       string    `json:"app"`
+	Points    int       `json:"points"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// PointsRule is what an activity is worth
+type PointsRule struct {
+	Activity    string `json:"activity"`
+	Points      int    `json:"points"`
+	Description string `json:"description"`
+}
+
+// handleGetUserPoints - GET /api/user/points
+// This month's points, the last few months' tallies, the unspent balance,
+// recent awards and what each activity is worth.
+func handleGetUserPoints(w http.ResponseWriter, r *http.Request) {
+	email := extractEmailFromRequest(r)
+	if email == "" {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	tallies, err := points.MonthlyTallies(db, email, pointsHistoryMonths)
+	if err != nil {
+		log.Printf("⚠️  Failed to load points for %s: %v", email, err)
+		http.Error(w, "Failed to load points", http.StatusInternalServerError)
+		return
+	}
+	balance, err := points.Balance(db, email)
+	if err != nil {
+		log.Printf("⚠️  Failed to load points balance for %s: %v", email, err)
+		http.Error(w, "Failed to load points", http.StatusInternalServerError)
+		return
+	}
+
+	recent := []PointsEntry{}
+	if rows, err := db.Query(`
+		SELECT activity, app, points, created_at
+		FROM points_ledger WHERE user_email = $1
+		ORDER BY created_at DESC
+		LIMIT 20
+	`, email); err == nil {
+		for rows.Next() {
+			var e PointsEntry
+			if rows.Scan(&e.Activity, &e.App, &e.Points, &e.CreatedAt) == nil {
+				recent = append(recent, e)
+			}
+		}
+		rows.Close()
+	}
+
+	rules := []PointsRule{}
+	if rows, err := db.Query(`
+		SELECT activity, points, COALESCE(description, '')
+		FROM points_rules WHERE points > 0
+		ORDER BY points DESC, activity
+	`); err == nil {
+		for rows.Next() {
+			var rule PointsRule
+			if rows.Scan(&rule.Activity, &rule.Points, &rule.Description) == nil {
+				rules = append(rules, rule)
+			}
+		}
+		rows.Close()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"month":   tallies[0],
+		"months":  tallies,
+		"balance": balance,
+		"recent":  recent,
+		"rules":   rules,
+	})
+}
+
+// awardChallengePoints gives everyone in a challenge that became a game
+// their challenge_played points. Runs in the background.
+func awardChallengePoints(challengeID, appID string, players []string) {
+	for _, email := range players {
+		awarded, err := points.Record(db, points.Award{
+			UserEmail: email,
+			Activity:  points.ActivityChallengePlayed,
+			App:       appID,
+			Ref:       challengeID,
+		})
+		if err != nil {
+			log.Printf("⚠️  Failed to award challenge points to %s: %v", email, err)
+			continue
+		}
+		if awarded > 0 {
+			log.Printf("⭐ %s earned %d points for challenge %s", email, awarded, challengeID)
+		}
+	}
+}
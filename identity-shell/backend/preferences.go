@@ -15,7 +15,8 @@ type UserAppPreference struct {
 }
 
 // handleGetUserPreferences - GET /api/user/preferences
-// Returns the current user's app preferences
+// Returns the current user's app preferences and settings.
+// The ETag header carries the preference version for use with If-Match on updates.
 func handleGetUserPreferences(w http.ResponseWriter, r *http.Request) {
 	// Extract user email from token
 	email := extractEmailFromRequest(r)
@@ -56,14 +57,32 @@ func handleGetUserPreferences(w http.ResponseWriter, r *http.Request) {
 		preferences = append(preferences, pref)
 	}
 
+	settings, err := loadUserSettings(email)
+	if err != nil {
+		log.Printf("Error querying settings: %v", err)
+		http.Error(w, "Failed to fetch preferences", http.StatusInternalServerError)
+		return
+	}
+
+	version, err := getPreferencesVersion(email)
+	if err != nil {
+		log.Printf("Error querying preference version: %v", err)
+		http.Error(w, "Failed to fetch preferences", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("ETag", preferencesETag(version))
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"preferences": preferences,
+		"settings":    settings,
+		"version":     version,
 	})
 }
 
 // handleUpdateUserPreferences - PUT /api/user/preferences
-// Updates the current user's app preferences (replaces all)
+// Replaces the current user's app preferences and/or settings (whichever fields are sent).
+// Send If-Match with the last ETag; a stale version gets 412 Precondition Failed.
 func handleUpdateUserPreferences(w http.ResponseWriter, r *http.Request) {
 	// Extract user email from token
 	email := extractEmailFromRequest(r)
@@ -74,7 +93,8 @@ func handleUpdateUserPreferences(w http.ResponseWriter, r *http.Request) {
 
 	// Parse request body
 	var req struct {
-		Preferences []UserAppPreference `json:"preferences"`
+		Preferences []UserAppPreference        `json:"preferences"`
+		Settings    map[string]json.RawMessage `json:"settings"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -82,6 +102,16 @@ func handleUpdateUserPreferences(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	for key, raw := range req.Settings {
+		if string(raw) == "null" {
+			continue
+		}
+		if err := validateSetting(key, raw); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
 	// Begin transaction
 	tx, err := db.Begin()
 	if err != nil {
@@ -91,33 +121,40 @@ func handleUpdateUserPreferences(w http.ResponseWriter, r *http.Request) {
 	}
 	defer tx.Rollback()
 
-	// Delete existing preferences
-	_, err = tx.Exec("DELETE FROM user_app_preferences WHERE user_email = $1", email)
-	if err != nil {
-		log.Printf("Failed to delete old preferences: %v", err)
-		http.Error(w, "Failed to update preferences", http.StatusInternalServerError)
+	if !lockPreferencesVersion(w, r, tx, email) {
 		return
 	}
 
-	// Insert new preferences
-	for _, pref := range req.Preferences {
-		var customOrder *int
-		if pref.CustomOrder != nil {
-			customOrder = pref.CustomOrder
+	// App preferences are only replaced when the field is sent
+	if req.Preferences != nil {
+		if err := replaceAppPreferences(tx, email, req.Preferences); err != nil {
+			log.Printf("Failed to replace app preferences: %v", err)
+			http.Error(w, "Failed to update preferences", http.StatusInternalServerError)
+			return
 		}
+	}
 
-		_, err = tx.Exec(`
-			INSERT INTO user_app_preferences (user_email, app_id, is_hidden, is_favorite, custom_order, updated_at)
-			VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP)
-		`, email, pref.AppID, pref.IsHidden, pref.IsFavorite, customOrder)
-
-		if err != nil {
-			log.Printf("Failed to insert preference: %v", err)
+	// Settings are replaced wholesale when sent (use PATCH .../settings to merge)
+	if req.Settings != nil {
+		if _, err := tx.Exec("DELETE FROM user_settings WHERE user_email = $1", email); err != nil {
+			log.Printf("Failed to delete old settings: %v", err)
+			http.Error(w, "Failed to update preferences", http.StatusInternalServerError)
+			return
+		}
+		if err := writeSettings(tx, email, req.Settings); err != nil {
+			log.Printf("Failed to save settings: %v", err)
 			http.Error(w, "Failed to update preferences", http.StatusInternalServerError)
 			return
 		}
 	}
 
+	version, err := bumpPreferencesVersion(tx, email)
+	if err != nil {
+		log.Printf("Failed to bump preference version: %v", err)
+		http.Error(w, "Failed to update preferences", http.StatusInternalServerError)
+		return
+	}
+
 	// Commit transaction
 	if err := tx.Commit(); err != nil {
 		log.Printf("Failed to commit transaction: %v", err)
@@ -127,13 +164,42 @@ func handleUpdateUserPreferences(w http.ResponseWriter, r *http.Request) {
 
 	log.Printf("✅ Updated preferences for user: %s", email)
 
+	w.Header().Set("ETag", preferencesETag(version))
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"success": true,
 		"message": "Preferences updated successfully",
+		"version": version,
 	})
 }
 
+// replaceAppPreferences swaps a user's app hide/favourite/order rows inside a transaction
+func replaceAppPreferences(tx *sql.Tx, email string, preferences []UserAppPreference) error {
+	// Delete existing preferences
+	if _, err := tx.Exec("DELETE FROM user_app_preferences WHERE user_email = $1", email); err != nil {
+		return err
+	}
+
+	// Insert new preferences
+	for _, pref := range preferences {
+		var customOrder *int
+		if pref.CustomOrder != nil {
+			customOrder = pref.CustomOrder
+		}
+
+		_, err := tx.Exec(`
+			INSERT INTO user_app_preferences (user_email, app_id, is_hidden, is_favorite, custom_order, updated_at)
+			VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP)
+		`, email, pref.AppID, pref.IsHidden, pref.IsFavorite, customOrder)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // extractEmailFromRequest extracts email from Authorization header
 // Supports both demo-token and impersonate-token formats
 func extractEmailFromRequest(r *http.Request) string {
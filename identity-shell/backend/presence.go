@@ -0,0 +1,347 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/achgithub/activity-hub-common/events"
+)
+
+// Presence is tracked per device (one per browser tab session) and merged into a
+// user-level view on read, so a phone left on the table doesn't hide the tablet in use.
+//
+//	user:devices:{email}            set of the user's device IDs
+//	user:device:{email}:{deviceId}  device presence JSON, expires after its status TTL
+
+// Presence TTLs by device status, configurable via env. Backgrounded tabs heartbeat
+// rarely (browsers throttle timers) and game pages don't heartbeat at all.
+var (
+	onlinePresenceTTL = envSeconds("PRESENCE_TTL_SECONDS", 30)
+	awayPresenceTTL   = envSeconds("PRESENCE_AWAY_TTL_SECONDS", 120)
+	inGamePresenceTTL = envSeconds("PRESENCE_IN_GAME_TTL_SECONDS", 900)
+)
+
+// defaultDeviceID is used for clients that don't send a device ID
+const defaultDeviceID = "default"
+
+// statusRank orders statuses for the merged view (any device in a game = in game)
+var statusRank = map[string]int{
+	"away":    1,
+	"online":  2,
+	"in_game": 3,
+}
+
+// DevicePresence is the presence of one of a user's devices
+type DevicePresence struct {
+	DeviceID    string `json:"deviceId"`
+	DisplayName string `json:"displayName"`
+	Status      string `json:"status"`
+	CurrentApp  string `json:"currentApp,omitempty"`
+	LastSeen    int64  `json:"lastSeen"`   // Last heartbeat
+	LastActive  int64  `json:"lastActive"` // Last status/app change or challenge sent/accepted
+}
+
+func envSeconds(key string, defaultSeconds int) time.Duration {
+	if s, err := strconv.Atoi(getEnv(key, "")); err == nil && s > 0 {
+		return time.Duration(s) * time.Second
+	}
+	return time.Duration(defaultSeconds) * time.Second
+}
+
+// presenceTTL returns how long a device's presence lasts without a heartbeat
+func presenceTTL(status string) time.Duration {
+	switch status {
+	case "away":
+		return awayPresenceTTL
+	case "in_game":
+		return inGamePresenceTTL
+	default:
+		return onlinePresenceTTL
+	}
+}
+
+// maxPresenceTTL is how long the device index is kept (it must outlive every device key)
+func maxPresenceTTL() time.Duration {
+	ttl := onlinePresenceTTL
+	for _, t := range []time.Duration{awayPresenceTTL, inGamePresenceTTL} {
+		if t > ttl {
+			ttl = t
+		}
+	}
+	return ttl
+}
+
+func devicesKey(email string) string {
+	return fmt.Sprintf("user:devices:%s", email)
+}
+
+func deviceKey(email, deviceID string) string {
+	return fmt.Sprintf("user:device:%s:%s", email, deviceID)
+}
+
+// deviceChannel is the pub/sub channel for notifications routed to one device
+func deviceChannel(email, deviceID string) string {
+	return fmt.Sprintf("user:%s:device:%s", email, deviceID)
+}
+
+// SetUserPresence records a heartbeat or status change from one of the user's devices.
+// The device becomes the active one when its status or current app changes.
+func SetUserPresence(email, name, status, currentApp, deviceID string) error {
+	if deviceID == "" {
+		deviceID = defaultDeviceID
+	}
+
+	now := time.Now().Unix()
+	device := DevicePresence{
+		DeviceID:    deviceID,
+		DisplayName: name,
+		Status:      status,
+		CurrentApp:  currentApp,
+		LastSeen:    now,
+		LastActive:  now,
+	}
+	if prev, err := getDevicePresence(email, deviceID); err == nil && prev.Status == status && prev.CurrentApp == currentApp {
+		device.LastActive = prev.LastActive // plain heartbeat
+	}
+
+	if err := saveDevicePresence(email, device); err != nil {
+		return err
+	}
+
+	// Notify all users about presence change
+	publishPresenceUpdate()
+
+	return nil
+}
+
+// TouchDevicePresence marks a device as the one the user is actively using
+// (e.g. they just sent or accepted a challenge from it)
+func TouchDevicePresence(email, deviceID string) {
+	if deviceID == "" {
+		return
+	}
+
+	device, err := getDevicePresence(email, deviceID)
+	if err != nil {
+		return // Device unknown or expired - next heartbeat registers it
+	}
+
+	device.LastActive = time.Now().Unix()
+	if err := saveDevicePresence(email, *device); err != nil {
+		log.Printf("⚠️  Failed to update device presence for %s: %v", email, err)
+	}
+}
+
+func saveDevicePresence(email string, device DevicePresence) error {
+	data, err := json.Marshal(device)
+	if err != nil {
+		return fmt.Errorf("failed to marshal presence: %w", err)
+	}
+
+	pipe := redisClient.TxPipeline()
+	pipe.Set(ctx, deviceKey(email, device.DeviceID), data, presenceTTL(device.Status))
+	pipe.SAdd(ctx, devicesKey(email), device.DeviceID)
+	pipe.Expire(ctx, devicesKey(email), maxPresenceTTL())
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to save presence: %w", err)
+	}
+
+	return nil
+}
+
+func getDevicePresence(email, deviceID string) (*DevicePresence, error) {
+	data, err := redisClient.Get(ctx, deviceKey(email, deviceID)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var device DevicePresence
+	if err := json.Unmarshal([]byte(data), &device); err != nil {
+		return nil, fmt.Errorf("failed to parse presence: %w", err)
+	}
+
+	return &device, nil
+}
+
+// getDevices returns the user's live devices, most recently active first.
+// Expired devices are pruned from the index.
+func getDevices(email string) ([]DevicePresence, error) {
+	ids, err := redisClient.SMembers(ctx, devicesKey(email)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get devices: %w", err)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = deviceKey(email, id)
+	}
+	values, err := redisClient.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get device presence: %w", err)
+	}
+
+	devices := []DevicePresence{}
+	for i, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			redisClient.SRem(ctx, devicesKey(email), ids[i])
+			continue
+		}
+		var device DevicePresence
+		if err := json.Unmarshal([]byte(data), &device); err != nil {
+			continue
+		}
+		devices = append(devices, device)
+	}
+
+	sort.Slice(devices, func(i, j int) bool {
+		return devices[i].LastActive > devices[j].LastActive
+	})
+
+	return devices, nil
+}
+
+// mergePresence builds the user-level view from the user's devices (most recently active first).
+// Status and app come from the "most present" device, ties going to the most recently active.
+func mergePresence(email string, devices []DevicePresence) *UserPresence {
+	if len(devices) == 0 {
+		return nil
+	}
+
+	primary := devices[0]
+	lastSeen := int64(0)
+	for _, d := range devices {
+		if statusRank[d.Status] > statusRank[primary.Status] {
+			primary = d
+		}
+		if d.LastSeen > lastSeen {
+			lastSeen = d.LastSeen
+		}
+	}
+
+	return &UserPresence{
+		Email:       email,
+		DisplayName: primary.DisplayName,
+		Status:      primary.Status,
+		CurrentApp:  primary.CurrentApp,
+		LastSeen:    lastSeen,
+		DeviceCount: len(devices),
+		Devices:     devices,
+	}
+}
+
+// GetOnlineUsers retrieves all currently online users from Redis
+func GetOnlineUsers() ([]UserPresence, error) {
+	keys, err := redisClient.Keys(ctx, "user:devices:*").Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get presence keys: %w", err)
+	}
+
+	users := []UserPresence{}
+	for _, key := range keys {
+		email := strings.TrimPrefix(key, "user:devices:")
+		devices, err := getDevices(email)
+		if err != nil {
+			continue
+		}
+		if presence := mergePresence(email, devices); presence != nil {
+			users = append(users, *presence)
+		}
+	}
+
+	applyGamePresence(users)
+	return users, nil
+}
+
+// GetUserPresence retrieves a user's merged presence from Redis
+func GetUserPresence(email string) (*UserPresence, error) {
+	devices, err := getDevices(email)
+	if err != nil {
+		return nil, err
+	}
+
+	presence := mergePresence(email, devices)
+	if presence == nil {
+		return nil, fmt.Errorf("user not found or offline")
+	}
+	users := []UserPresence{*presence}
+	applyGamePresence(users)
+	presence = &users[0]
+
+	return presence, nil
+}
+
+// IsUserOnline checks if a specific user is currently online on any device
+func IsUserOnline(email string) (bool, error) {
+	devices, err := getDevices(email)
+	if err != nil {
+		return false, err
+	}
+	return len(devices) > 0, nil
+}
+
+// RemoveDevicePresence removes one device's presence (tab closed / logged out on that device)
+func RemoveDevicePresence(email, deviceID string) error {
+	pipe := redisClient.TxPipeline()
+	pipe.Del(ctx, deviceKey(email, deviceID))
+	pipe.SRem(ctx, devicesKey(email), deviceID)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+
+	// Notify all users about presence change
+	publishPresenceUpdate()
+
+	return nil
+}
+
+// RemoveUserPresence removes a user's presence on all devices
+func RemoveUserPresence(email string) error {
+	ids, err := redisClient.SMembers(ctx, devicesKey(email)).Result()
+	if err != nil {
+		return err
+	}
+
+	keys := []string{devicesKey(email)}
+	for _, id := range ids {
+		keys = append(keys, deviceKey(email, id))
+	}
+	if err := redisClient.Del(ctx, keys...).Err(); err != nil {
+		return err
+	}
+
+	// Notify all users about presence change
+	publishPresenceUpdate()
+
+	return nil
+}
+
+// publishPresenceUpdate tells every lobby to refresh its online list
+func publishPresenceUpdate() {
+	redisClient.Publish(ctx, "presence:updates", evPresenceUpdate.New("", events.NoPayload{}).Encode())
+}
+
+// publishToActiveDevice sends a notification to the device the user is actually using.
+// Devices are tried most recently active first (a device in a game page has no lobby
+// stream, so it receives nothing); if no device is listening, all devices get it.
+func publishToActiveDevice(email string, event events.Envelope) error {
+	payload := event.Encode()
+	if devices, err := getDevices(email); err == nil {
+		for _, d := range devices {
+			receivers, err := redisClient.Publish(ctx, deviceChannel(email, d.DeviceID), payload).Result()
+			if err == nil && receivers > 0 {
+				return nil
+			}
+		}
+	}
+
+	return redisClient.Publish(ctx, fmt.Sprintf("user:%s", email), payload).Err()
+}
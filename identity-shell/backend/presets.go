@@ -0,0 +1,367 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/achgithub/activity-hub-common/i18n"
+	"github.com/gorilla/mux"
+	"github.com/lib/pq"
+)
+
+// Challenge presets ("usual game"): a player saves the app, options and
+// opponents they usually play, and the lobby shows each as a quick action.
+// Using one challenges whichever of the preferred opponents are free right
+// now - the first for a 2-player game, all of them for a multi-player one.
+
+const (
+	maxChallengePresets = 10
+	maxPresetOpponents  = 8
+	maxPresetNameLength = 50
+)
+
+// ChallengePreset is a saved challenge configuration
+type ChallengePreset struct {
+	ID         int                    `json:"id"`
+	Name       string                 `json:"name"`
+	AppID      string                 `json:"appId"`
+	Options    map[string]interface{} `json:"options"`
+	Opponents  []string               `json:"opponents"` // Most preferred first
+	LastUsedAt *time.Time             `json:"lastUsedAt,omitempty"`
+	CreatedAt  time.Time              `json:"createdAt"`
+}
+
+// presetRequest is the body of a create or update
+type presetRequest struct {
+	Name      string                 `json:"name"`
+	AppID     string                 `json:"appId"`
+	Options   map[string]interface{} `json:"options"`
+	Opponents []string               `json:"opponents"`
+}
+
+// validate trims the request and returns what's wrong with it, or ""
+func (p *presetRequest) validate(owner string) string {
+	p.Name = strings.TrimSpace(p.Name)
+	if p.Name == "" || len(p.Name) > maxPresetNameLength {
+		return fmt.Sprintf("Name must be 1-%d characters", maxPresetNameLength)
+	}
+	if !IsGameApp(p.AppID) {
+		return "Unknown game"
+	}
+	if p.Options == nil {
+		p.Options = map[string]interface{}{}
+	}
+
+	opponents := []string{}
+	for _, email := range p.Opponents {
+		email = strings.TrimSpace(email)
+		if email == "" || email == owner || containsString(opponents, email) {
+			continue
+		}
+		opponents = append(opponents, email)
+	}
+	if len(opponents) == 0 {
+		return "Choose at least one opponent"
+	}
+	if len(opponents) > maxPresetOpponents {
+		return fmt.Sprintf("Too many opponents (max: %d)", maxPresetOpponents)
+	}
+	p.Opponents = opponents
+	return ""
+}
+
+// handleGetChallengePresets - GET /api/user/challenge-presets
+// Returns the current user's presets, most recently used first
+func handleGetChallengePresets(w http.ResponseWriter, r *http.Request) {
+	email := extractEmailFromRequest(r)
+	if email == "" {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	presets, err := loadChallengePresets(email)
+	if err != nil {
+		log.Printf("Failed to load challenge presets: %v", err)
+		http.Error(w, "Failed to fetch presets", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"presets": presets})
+}
+
+func loadChallengePresets(email string) ([]ChallengePreset, error) {
+	rows, err := db.Query(`
+		SELECT id, name, app_id, options, opponents, last_used_at, created_at
+		FROM challenge_presets
+		WHERE user_email = $1
+		ORDER BY last_used_at DESC NULLS LAST, created_at
+	`, email)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	presets := []ChallengePreset{}
+	for rows.Next() {
+		p, err := scanChallengePreset(rows)
+		if err != nil {
+			return nil, err
+		}
+		presets = append(presets, *p)
+	}
+	return presets, rows.Err()
+}
+
+func scanChallengePreset(row interface{ Scan(...interface{}) error }) (*ChallengePreset, error) {
+	var p ChallengePreset
+	var options []byte
+	var lastUsed sql.NullTime
+	if err := row.Scan(&p.ID, &p.Name, &p.AppID, &options, pq.Array(&p.Opponents), &lastUsed, &p.CreatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(options, &p.Options); err != nil {
+		return nil, err
+	}
+	if p.Opponents == nil {
+		p.Opponents = []string{}
+	}
+	if lastUsed.Valid {
+		p.LastUsedAt = &lastUsed.Time
+	}
+	return &p, nil
+}
+
+// handleCreateChallengePreset - POST /api/user/challenge-presets {name, appId, options, opponents}
+func handleCreateChallengePreset(w http.ResponseWriter, r *http.Request) {
+	email := extractEmailFromRequest(r)
+	if email == "" {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req presetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if problem := req.validate(email); problem != "" {
+		http.Error(w, problem, http.StatusBadRequest)
+		return
+	}
+
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM challenge_presets WHERE user_email = $1", email).Scan(&count); err != nil {
+		log.Printf("Failed to count challenge presets: %v", err)
+		http.Error(w, "Failed to save preset", http.StatusInternalServerError)
+		return
+	}
+	if count >= maxChallengePresets {
+		http.Error(w, fmt.Sprintf("You can save up to %d presets", maxChallengePresets), http.StatusBadRequest)
+		return
+	}
+
+	options, _ := json.Marshal(req.Options)
+	preset, err := scanChallengePreset(db.QueryRow(`
+		INSERT INTO challenge_presets (user_email, name, app_id, options, opponents)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_email, name) DO NOTHING
+		RETURNING id, name, app_id, options, opponents, last_used_at, created_at
+	`, email, req.Name, req.AppID, string(options), pq.Array(req.Opponents)))
+	if err == sql.ErrNoRows {
+		http.Error(w, "You already have a preset with that name", http.StatusConflict)
+		return
+	} else if err != nil {
+		log.Printf("Failed to create challenge preset: %v", err)
+		http.Error(w, "Failed to save preset", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "preset": preset})
+}
+
+// handleUpdateChallengePreset - PUT /api/user/challenge-presets/{id} {name, appId, options, opponents}
+func handleUpdateChallengePreset(w http.ResponseWriter, r *http.Request) {
+	email := extractEmailFromRequest(r)
+	if email == "" {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid preset ID", http.StatusBadRequest)
+		return
+	}
+
+	var req presetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if problem := req.validate(email); problem != "" {
+		http.Error(w, problem, http.StatusBadRequest)
+		return
+	}
+
+	options, _ := json.Marshal(req.Options)
+	preset, err := scanChallengePreset(db.QueryRow(`
+		UPDATE challenge_presets
+		SET name = $3, app_id = $4, options = $5, opponents = $6, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND user_email = $2
+		AND NOT EXISTS (SELECT 1 FROM challenge_presets WHERE user_email = $2 AND name = $3 AND id <> $1)
+		RETURNING id, name, app_id, options, opponents, last_used_at, created_at
+	`, id, email, req.Name, req.AppID, string(options), pq.Array(req.Opponents)))
+	if err == sql.ErrNoRows {
+		// Either it isn't theirs or the new name is taken
+		var exists bool
+		db.QueryRow("SELECT EXISTS (SELECT 1 FROM challenge_presets WHERE id = $1 AND user_email = $2)", id, email).Scan(&exists)
+		if exists {
+			http.Error(w, "You already have a preset with that name", http.StatusConflict)
+		} else {
+			http.Error(w, "Preset not found", http.StatusNotFound)
+		}
+		return
+	} else if err != nil {
+		log.Printf("Failed to update challenge preset %d: %v", id, err)
+		http.Error(w, "Failed to save preset", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "preset": preset})
+}
+
+// handleDeleteChallengePreset - DELETE /api/user/challenge-presets/{id}
+func handleDeleteChallengePreset(w http.ResponseWriter, r *http.Request) {
+	email := extractEmailFromRequest(r)
+	if email == "" {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid preset ID", http.StatusBadRequest)
+		return
+	}
+
+	res, err := db.Exec("DELETE FROM challenge_presets WHERE id = $1 AND user_email = $2", id, email)
+	if err != nil {
+		log.Printf("Failed to delete challenge preset %d: %v", id, err)
+		http.Error(w, "Failed to delete preset", http.StatusInternalServerError)
+		return
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		http.Error(w, "Preset not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"success": true})
+}
+
+// handleSendPresetChallenge - POST /api/user/challenge-presets/{id}/challenge?deviceId={id}
+// Issues the preset's challenge to whichever preferred opponents are online
+// and not in a game. 409 if too few are free.
+func handleSendPresetChallenge(w http.ResponseWriter, r *http.Request) {
+	email := extractEmailFromRequest(r)
+	if email == "" {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid preset ID", http.StatusBadRequest)
+		return
+	}
+
+	preset, err := scanChallengePreset(db.QueryRow(`
+		SELECT id, name, app_id, options, opponents, last_used_at, created_at
+		FROM challenge_presets WHERE id = $1 AND user_email = $2
+	`, id, email))
+	if err == sql.ErrNoRows {
+		http.Error(w, i18n.T(r, "preset_not_found"), http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Printf("Failed to load challenge preset %d: %v", id, err)
+		http.Error(w, i18n.T(r, "preset_send_failed"), http.StatusInternalServerError)
+		return
+	}
+
+	if lobbyClosedFor(email) {
+		http.Error(w, i18n.T(r, errLobbyClosed), http.StatusForbidden)
+		return
+	}
+
+	app := GetAppByID(preset.AppID)
+	if app == nil || !app.Enabled || !IsGameApp(preset.AppID) {
+		http.Error(w, i18n.T(r, "game_unavailable"), http.StatusGone)
+		return
+	}
+	if notice, down := appMaintenance.Get(app.ID); down {
+		http.Error(w, notice.MessageFor(r), http.StatusServiceUnavailable)
+		return
+	}
+	minPlayers, maxPlayers := 2, 2
+	if app.MinPlayers != nil && *app.MinPlayers > 2 {
+		minPlayers = *app.MinPlayers
+		maxPlayers = minPlayers
+		if app.MaxPlayers != nil && *app.MaxPlayers > minPlayers {
+			maxPlayers = *app.MaxPlayers
+		}
+	}
+
+	// Preferred opponents who could take a challenge now, in preference order
+	free := []string{}
+	for _, opponent := range preset.Opponents {
+		online, err := IsUserOnline(opponent)
+		if err != nil {
+			log.Printf("Failed to check presence of %s: %v", opponent, err)
+			continue
+		}
+		if online && challengeBlockedByGame(r, opponent) == "" {
+			free = append(free, opponent)
+		}
+		if len(free) == maxPlayers-1 {
+			break
+		}
+	}
+	if len(free) < minPlayers-1 {
+		http.Error(w, i18n.T(r, "opponents_busy"), http.StatusConflict)
+		return
+	}
+
+	// Replies to this challenge go to the device it was sent from
+	TouchDevicePresence(email, r.URL.Query().Get("deviceId"))
+
+	var challengeID string
+	if minPlayers == 2 {
+		challengeID, err = issueChallenge(email, free[0], preset.AppID, preset.Options)
+		free = free[:1]
+	} else {
+		challengeID, err = issueMultiChallenge(email, append([]string{email}, free...), preset.AppID, minPlayers, maxPlayers, preset.Options)
+	}
+	if err != nil {
+		log.Printf("Failed to create challenge from preset %d: %v", id, err)
+		http.Error(w, i18n.T(r, "challenge_failed"), http.StatusInternalServerError)
+		return
+	}
+
+	if _, err := db.Exec("UPDATE challenge_presets SET last_used_at = CURRENT_TIMESTAMP WHERE id = $1", id); err != nil {
+		log.Printf("Failed to mark challenge preset %d used: %v", id, err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":     true,
+		"challengeId": challengeID,
+		"appId":       preset.AppID,
+		"challenged":  free,
+	})
+}
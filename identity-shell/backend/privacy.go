@@ -0,0 +1,286 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/achgithub/activity-hub-common/services"
+	"github.com/lib/pq"
+)
+
+// filterHiddenPresence drops users who set privacy_hide_presence from the lobby list
+// Best effort: on lookup failure the list is returned unfiltered
+func filterHiddenPresence(users []UserPresence) []UserPresence {
+	if len(users) == 0 {
+		return users
+	}
+
+	emails := make([]string, 0, len(users))
+	for _, u := range users {
+		emails = append(emails, u.Email)
+	}
+
+	rows, err := db.Query(`
+		SELECT user_email FROM user_settings
+		WHERE key = 'privacy_hide_presence' AND value = 'true'::jsonb AND user_email = ANY($1)
+	`, pq.Array(emails))
+	if err != nil {
+		log.Printf("⚠️  Failed to load presence privacy settings: %v", err)
+		return users
+	}
+	defer rows.Close()
+
+	hidden := map[string]bool{}
+	for rows.Next() {
+		var email string
+		if rows.Scan(&email) == nil {
+			hidden[email] = true
+		}
+	}
+
+	if len(hidden) == 0 {
+		return users
+	}
+
+	visible := make([]UserPresence, 0, len(users))
+	for _, u := range users {
+		if !hidden[u.Email] {
+			visible = append(visible, u)
+		}
+	}
+	return visible
+}
+
+// isImpersonatedRequest reports whether the request uses an impersonation token
+// Data export and deletion must be done by the user themselves
+func isImpersonatedRequest(r *http.Request) bool {
+	return strings.HasPrefix(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "), "impersonate-")
+}
+
+// handleExportUserData - GET /api/user/data-export
+// Returns everything the identity service stores about the current user as a JSON download
+func handleExportUserData(w http.ResponseWriter, r *http.Request) {
+	email := extractEmailFromRequest(r)
+	if email == "" {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if isImpersonatedRequest(r) {
+		http.Error(w, "Data export is not available while impersonating", http.StatusForbidden)
+		return
+	}
+
+	var account struct {
+		Email     string    `json:"email"`
+		Name      string    `json:"name"`
+		IsAdmin   bool      `json:"is_admin"`
+		Roles     []string  `json:"roles"`
+		IsActive  bool      `json:"is_active"`
+		CreatedAt time.Time `json:"created_at"`
+	}
+
+	err := db.QueryRow(`
+		SELECT email, name, is_admin, COALESCE(roles, '{}'), COALESCE(is_active, TRUE), created_at
+		FROM users WHERE email = $1
+	`, email).Scan(&account.Email, &account.Name, &account.IsAdmin, (*pq.StringArray)(&account.Roles), &account.IsActive, &account.CreatedAt)
+	if err == sql.ErrNoRows {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Printf("Export: failed to load account: %v", err)
+		http.Error(w, "Failed to export data", http.StatusInternalServerError)
+		return
+	}
+
+	profile := map[string]interface{}{}
+	var nickname, flair, avatarHash sql.NullString
+	err = db.QueryRow("SELECT nickname, flair, avatar_hash FROM user_profiles WHERE user_email = $1", email).
+		Scan(&nickname, &flair, &avatarHash)
+	if err == nil {
+		profile["nickname"] = nickname.String
+		profile["flair"] = flair.String
+		profile["avatar_hash"] = avatarHash.String
+	} else if err != sql.ErrNoRows {
+		log.Printf("Export: failed to load profile: %v", err)
+	}
+
+	settings, err := loadUserSettings(email)
+	if err != nil {
+		log.Printf("Export: failed to load settings: %v", err)
+	}
+
+	appPreferences := []map[string]interface{}{}
+	if rows, err := db.Query(`
+		SELECT app_id, is_hidden, COALESCE(is_favorite, FALSE), custom_order
+		FROM user_app_preferences WHERE user_email = $1
+	`, email); err == nil {
+		for rows.Next() {
+			var appID string
+			var hidden, favorite bool
+			var order sql.NullInt64
+			if rows.Scan(&appID, &hidden, &favorite, &order) == nil {
+				appPreferences = append(appPreferences, map[string]interface{}{
+					"app_id": appID, "is_hidden": hidden, "is_favorite": favorite, "custom_order": order.Int64,
+				})
+			}
+		}
+		rows.Close()
+	}
+
+	challengePresets, err := loadChallengePresets(email)
+	if err != nil {
+		log.Printf("Export: failed to load challenge presets: %v", err)
+	}
+
+	// Transparency: when a super_user impersonated this account
+	impersonations := []map[string]interface{}{}
+	if rows, err := db.Query(`
+		SELECT super_user_email, started_at, ended_at
+		FROM impersonation_sessions WHERE impersonated_email = $1
+		ORDER BY started_at DESC
+	`, email); err == nil {
+		for rows.Next() {
+			var superUser string
+			var startedAt time.Time
+			var endedAt sql.NullTime
+			if rows.Scan(&superUser, &startedAt, &endedAt) == nil {
+				entry := map[string]interface{}{"super_user": superUser, "started_at": startedAt}
+				if endedAt.Valid {
+					entry["ended_at"] = endedAt.Time
+				}
+				impersonations = append(impersonations, entry)
+			}
+		}
+		rows.Close()
+	}
+
+	// Loyalty points earned and redeemed
+	pointsLedger := []map[string]interface{}{}
+	if rows, err := db.Query(`
+		SELECT 'earned', activity || ' (' || app || ')', points, created_at FROM points_ledger WHERE user_email = $1
+		UNION ALL
+		SELECT 'redeemed', reward, points, created_at FROM points_redemptions WHERE user_email = $1
+		ORDER BY 4 DESC
+	`, email); err == nil {
+		for rows.Next() {
+			var kind, what string
+			var amount int
+			var at time.Time
+			if rows.Scan(&kind, &what, &amount, &at) == nil {
+				pointsLedger = append(pointsLedger, map[string]interface{}{
+					"type": kind, "for": what, "points": amount, "at": at,
+				})
+			}
+		}
+		rows.Close()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="activity-hub-data-%s.json"`, time.Now().Format("20060102")))
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"exportedAt":       time.Now(),
+		"account":          account,
+		"profile":          profile,
+		"settings":         settings,
+		"appPreferences":   appPreferences,
+		"challengePresets": challengePresets,
+		"impersonations":   impersonations,
+		"points":           pointsLedger,
+		"gameResultsNote":  "Game results are held by the leaderboard app: GET /api/player/{email}",
+	})
+}
+
+// handleDeleteUserData - DELETE /api/user/data  {confirm: "DELETE"}
+// Permanently deletes the current user's account and personal data.
+// Leaderboard history is anonymised rather than removed so other players' records stay intact.
+func handleDeleteUserData(w http.ResponseWriter, r *http.Request) {
+	email := extractEmailFromRequest(r)
+	if email == "" {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if isImpersonatedRequest(r) {
+		http.Error(w, "Account deletion is not available while impersonating", http.StatusForbidden)
+		return
+	}
+
+	var req struct {
+		Confirm string `json:"confirm"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Confirm != "DELETE" {
+		http.Error(w, `Confirmation required: {"confirm": "DELETE"}`, http.StatusBadRequest)
+		return
+	}
+
+	// Anonymise game history first - the leaderboard authenticates with the user's
+	// token, which stops working once the account is gone
+	if err := anonymiseLeaderboardHistory(r.Header.Get("Authorization")); err != nil {
+		log.Printf("⚠️  Leaderboard anonymisation failed for %s: %v", email, err)
+		http.Error(w, "Failed to anonymise leaderboard history, nothing was deleted", http.StatusBadGateway)
+		return
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("Failed to begin transaction: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	statements := []string{
+		"DELETE FROM user_settings WHERE user_email = $1",
+		"DELETE FROM user_preference_versions WHERE user_email = $1",
+		"DELETE FROM user_app_preferences WHERE user_email = $1",
+		"DELETE FROM user_profiles WHERE user_email = $1",
+		"DELETE FROM challenge_presets WHERE user_email = $1",
+		"UPDATE challenge_presets SET opponents = array_remove(opponents, $1) WHERE $1 = ANY(opponents)",
+		"DELETE FROM scheduled_challenges WHERE from_user = $1 OR to_user = $1",
+		"DELETE FROM points_ledger WHERE user_email = $1",
+		"DELETE FROM points_redemptions WHERE user_email = $1",
+		"UPDATE table_devices SET created_by = NULL WHERE created_by = $1",
+		"UPDATE impersonation_sessions SET is_active = FALSE, ended_at = COALESCE(ended_at, CURRENT_TIMESTAMP) WHERE impersonated_email = $1",
+		"DELETE FROM users WHERE email = $1",
+	}
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt, email); err != nil {
+			log.Printf("Failed to delete user data (%s): %v", stmt, err)
+			http.Error(w, "Failed to delete data", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Failed to commit deletion: %v", err)
+		http.Error(w, "Failed to delete data", http.StatusInternalServerError)
+		return
+	}
+
+	RemoveUserPresence(email)
+
+	log.Printf("🗑️  Deleted account and personal data for %s", email)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": "Your account and personal data have been deleted",
+	})
+}
+
+// anonymiseLeaderboardHistory asks the leaderboard app to strip the user's identity from results
+func anonymiseLeaderboardHistory(authHeader string) error {
+	token := strings.TrimPrefix(authHeader, "Bearer ")
+	err := backends.Client(services.LeaderboardApp).Delete(context.Background(), "/api/player/me", token, nil)
+	if errors.Is(err, services.ErrUnknownApp) {
+		// Leaderboard not installed - nothing to anonymise
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/achgithub/activity-hub-common/upload"
+	"github.com/gorilla/mux"
+)
+
+const (
+	maxNicknameLength = 30
+	maxFlairRunes     = 4 // emoji with modifiers/ZWJ sequences span several runes
+)
+
+// handleGetUserProfile - GET /api/user/profile
+// Returns the current user's profile
+func handleGetUserProfile(w http.ResponseWriter, r *http.Request) {
+	email := extractEmailFromRequest(r)
+	if email == "" {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	profiles, err := authlib.LoadProfiles(db, []string{email})
+	if err != nil {
+		log.Printf("Error loading profile: %v", err)
+		http.Error(w, "Failed to fetch profile", http.StatusInternalServerError)
+		return
+	}
+
+	profile, ok := profiles[email]
+	if !ok {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"profile":     profile,
+		"displayName": profile.DisplayName(),
+	})
+}
+
+// handleUpdateUserProfile - PUT /api/user/profile
+// Sets nickname and emoji flair (empty strings clear them)
+func handleUpdateUserProfile(w http.ResponseWriter, r *http.Request) {
+	email := extractEmailFromRequest(r)
+	if email == "" {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req struct {
+		Nickname string `json:"nickname"`
+		Flair    string `json:"flair"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	req.Nickname = strings.TrimSpace(req.Nickname)
+	req.Flair = strings.TrimSpace(req.Flair)
+
+	if utf8.RuneCountInString(req.Nickname) > maxNicknameLength {
+		http.Error(w, "Nickname too long", http.StatusBadRequest)
+		return
+	}
+	if utf8.RuneCountInString(req.Flair) > maxFlairRunes || len(req.Flair) > 16 {
+		http.Error(w, "Flair must be a single emoji", http.StatusBadRequest)
+		return
+	}
+
+	_, err := db.Exec(`
+		INSERT INTO user_profiles (user_email, nickname, flair, updated_at)
+		VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), CURRENT_TIMESTAMP)
+		ON CONFLICT (user_email) DO UPDATE
+		SET nickname = EXCLUDED.nickname, flair = EXCLUDED.flair, updated_at = CURRENT_TIMESTAMP
+	`, email, req.Nickname, req.Flair)
+	if err != nil {
+		log.Printf("Failed to update profile: %v", err)
+		http.Error(w, "Failed to update profile", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("✅ Profile updated for %s", email)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+	})
+}
+
+// handleUploadAvatar - POST /api/user/profile/avatar
+// Accepts a multipart "avatar" image. Images are stored once by content hash,
+// so re-uploads and shared images don't duplicate data.
+func handleUploadAvatar(w http.ResponseWriter, r *http.Request) {
+	email := extractEmailFromRequest(r)
+	if email == "" {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// upload.Avatar: PNG, JPEG, GIF or WebP up to 256KB, sniffed and re-encoded
+	file, err := upload.Read(w, r, "avatar", upload.Avatar)
+	if err != nil {
+		http.Error(w, "Avatar: "+err.Error(), upload.StatusCode(err))
+		return
+	}
+	data, contentType := file.Data, file.ContentType
+
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("Failed to begin transaction: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO avatars (hash, content_type, data)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (hash) DO NOTHING
+	`, hash, contentType, data)
+	if err != nil {
+		log.Printf("Failed to store avatar: %v", err)
+		http.Error(w, "Failed to store avatar", http.StatusInternalServerError)
+		return
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO user_profiles (user_email, avatar_hash, updated_at)
+		VALUES ($1, $2, CURRENT_TIMESTAMP)
+		ON CONFLICT (user_email) DO UPDATE
+		SET avatar_hash = EXCLUDED.avatar_hash, updated_at = CURRENT_TIMESTAMP
+	`, email, hash)
+	if err != nil {
+		log.Printf("Failed to set avatar: %v", err)
+		http.Error(w, "Failed to set avatar", http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Failed to commit avatar: %v", err)
+		http.Error(w, "Failed to set avatar", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("✅ Avatar updated for %s (%s)", email, hash[:12])
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":   true,
+		"avatarUrl": authlib.AvatarPath(hash),
+	})
+}
+
+// handleDeleteAvatar - DELETE /api/user/profile/avatar
+// Clears the current user's avatar (the image itself is kept for other users sharing it)
+func handleDeleteAvatar(w http.ResponseWriter, r *http.Request) {
+	email := extractEmailFromRequest(r)
+	if email == "" {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if _, err := db.Exec("UPDATE user_profiles SET avatar_hash = NULL, updated_at = CURRENT_TIMESTAMP WHERE user_email = $1", email); err != nil {
+		log.Printf("Failed to clear avatar: %v", err)
+		http.Error(w, "Failed to clear avatar", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+	})
+}
+
+// handleGetAvatar - GET /api/avatars/{hash}
+// Serves an avatar image. Content is immutable for a given hash so it can be cached forever.
+func handleGetAvatar(w http.ResponseWriter, r *http.Request) {
+	hash := mux.Vars(r)["hash"]
+
+	var contentType string
+	var data []byte
+	err := db.QueryRow("SELECT content_type, data FROM avatars WHERE hash = $1", hash).Scan(&contentType, &data)
+	if err == sql.ErrNoRows {
+		http.NotFound(w, r)
+		return
+	} else if err != nil {
+		log.Printf("Failed to load avatar: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Write(data)
+}
+
+// handleGetProfiles - GET /api/profiles?emails=a@x.com,b@x.com
+// Batch profile lookup for frontends rendering lists of players
+func handleGetProfiles(w http.ResponseWriter, r *http.Request) {
+	var emails []string
+	for _, e := range strings.Split(r.URL.Query().Get("emails"), ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			emails = append(emails, e)
+		}
+	}
+
+	if len(emails) > 200 {
+		http.Error(w, "Too many emails (max 200)", http.StatusBadRequest)
+		return
+	}
+
+	profiles, err := authlib.LoadProfiles(db, emails)
+	if err != nil {
+		log.Printf("Error loading profiles: %v", err)
+		http.Error(w, "Failed to fetch profiles", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"profiles": profiles,
+	})
+}
+
+// attachPresenceProfiles decorates online users with nickname, flair and avatar
+// Best effort: presence is still returned if the profile lookup fails
+func attachPresenceProfiles(users []UserPresence) {
+	emails := make([]string, 0, len(users))
+	for _, u := range users {
+		emails = append(emails, u.Email)
+	}
+
+	profiles, err := authlib.LoadProfiles(db, emails)
+	if err != nil {
+		log.Printf("⚠️  Failed to load presence profiles: %v", err)
+		return
+	}
+
+	for i := range users {
+		if p, ok := profiles[users[i].Email]; ok {
+			users[i].DisplayName = p.DisplayName()
+			users[i].Flair = p.Flair
+			users[i].AvatarURL = p.AvatarURL
+		}
+	}
+}
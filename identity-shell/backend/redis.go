@@ -7,12 +7,30 @@ import (
 	"log"
 	"time"
 
+	redislib "github.com/achgithub/activity-hub-common/redis"
+	"github.com/achgithub/activity-hub-common/sse"
 	"github.com/go-redis/redis/v8"
 )
 
 var redisClient *redis.Client
 var ctx = context.Background()
 
+// sseBroker feeds lobby streams from one shared Redis subscription. Nothing
+// about a stream is held only in this process, so several shell instances
+// can serve the lobby side by side.
+var sseBroker *sse.Broker
+
+// initSSEBroker starts the broker on the shared library's Redis client,
+// separate from the shell's own
+func initSSEBroker() error {
+	client, err := redislib.InitRedis()
+	if err != nil {
+		return err
+	}
+	sseBroker = sse.NewBroker(client)
+	return nil
+}
+
 // InitRedis initializes the Redis connection
 func InitRedis() error {
 	redisClient = redis.NewClient(&redis.Options{
@@ -29,79 +47,6 @@ func InitRedis() error {
 	return nil
 }
 
-// SetUserPresence updates a user's presence in Redis with 30s TTL
-func SetUserPresence(email, name, status, currentApp string) error {
-	key := fmt.Sprintf("user:presence:%s", email)
-	presence := map[string]interface{}{
-		"email":       email,
-		"displayName": name,
-		"status":      status,
-		"currentApp":  currentApp,
-		"lastSeen":    time.Now().Unix(),
-	}
-
-	data, err := json.Marshal(presence)
-	if err != nil {
-		return fmt.Errorf("failed to marshal presence: %w", err)
-	}
-
-	if err := redisClient.Set(ctx, key, data, 30*time.Second).Err(); err != nil {
-		return err
-	}
-
-	// Notify all users about presence change
-	redisClient.Publish(ctx, "presence:updates", "presence_update")
-
-	return nil
-}
-
-// GetOnlineUsers retrieves all currently online users from Redis
-func GetOnlineUsers() ([]UserPresence, error) {
-	keys, err := redisClient.Keys(ctx, "user:presence:*").Result()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get presence keys: %w", err)
-	}
-
-	users := []UserPresence{}
-	for _, key := range keys {
-		data, err := redisClient.Get(ctx, key).Result()
-		if err != nil {
-			continue // Key expired between Keys() and Get()
-		}
-
-		var presence UserPresence
-		if err := json.Unmarshal([]byte(data), &presence); err != nil {
-			continue
-		}
-		users = append(users, presence)
-	}
-
-	return users, nil
-}
-
-// RemoveUserPresence removes a user's presence from Redis
-func RemoveUserPresence(email string) error {
-	key := fmt.Sprintf("user:presence:%s", email)
-	if err := redisClient.Del(ctx, key).Err(); err != nil {
-		return err
-	}
-
-	// Notify all users about presence change
-	redisClient.Publish(ctx, "presence:updates", "presence_update")
-
-	return nil
-}
-
-// IsUserOnline checks if a specific user is currently online
-func IsUserOnline(email string) (bool, error) {
-	key := fmt.Sprintf("user:presence:%s", email)
-	exists, err := redisClient.Exists(ctx, key).Result()
-	if err != nil {
-		return false, err
-	}
-	return exists > 0, nil
-}
-
 // HasPendingChallengeBetween checks if there's already a pending challenge between two users (in either direction)
 func HasPendingChallengeBetween(user1, user2 string) (bool, error) {
 	// Check user1's received challenges for any from user2
@@ -196,9 +141,8 @@ func CreateChallenge(fromUser, toUser, appID string, options map[string]interfac
 	}
 	redisClient.Expire(ctx, senderQueueKey, 5*time.Minute)
 
-	// Publish notification to recipient
-	channel := fmt.Sprintf("user:%s", toUser)
-	if err := redisClient.Publish(ctx, channel, "challenge_received").Err(); err != nil {
+	// Publish notification to the recipient's active device
+	if err := publishToActiveDevice(toUser, evChallengeReceived.New("", ChallengeRef{ChallengeID: challengeID})); err != nil {
 		return challengeID, fmt.Errorf("challenge created but notification failed: %w", err)
 	}
 
@@ -246,9 +190,8 @@ func CreateMultiChallenge(initiatorID string, playerIDs []string, appID string,
 		}
 		redisClient.Expire(ctx, recipientQueueKey, 5*time.Minute)
 
-		// Publish notification to each player
-		channel := fmt.Sprintf("user:%s", playerID)
-		if err := redisClient.Publish(ctx, channel, "challenge_received").Err(); err != nil {
+		// Publish notification to each player's active device
+		if err := publishToActiveDevice(playerID, evChallengeReceived.New("", ChallengeRef{ChallengeID: challengeID})); err != nil {
 			log.Printf("Failed to notify player %s: %v", playerID, err)
 		}
 	}
@@ -321,8 +264,9 @@ func getChallengesFromQueue(queueKey string) ([]Challenge, error) {
 	return challenges, nil
 }
 
-// UpdateChallengeStatus updates a challenge's status and notifies the challenger
-func UpdateChallengeStatus(challengeID, status string) error {
+// UpdateChallengeStatus updates a challenge's status and notifies the challenger.
+// reason is an optional decline reason code passed on to the challenger.
+func UpdateChallengeStatus(challengeID, status, reason string) error {
 	key := fmt.Sprintf("challenge:%s", challengeID)
 
 	// Get current challenge
@@ -339,6 +283,9 @@ func UpdateChallengeStatus(challengeID, status string) error {
 	// Update status
 	challenge["status"] = status
 	challenge["respondedAt"] = time.Now().Unix()
+	if reason != "" {
+		challenge["declineReason"] = reason
+	}
 
 	newData, err := json.Marshal(challenge)
 	if err != nil {
@@ -352,8 +299,9 @@ func UpdateChallengeStatus(challengeID, status string) error {
 	}
 
 	// Remove from both sender and recipient queues
-	toUser := challenge["toUser"].(string)
-	fromUser := challenge["fromUser"].(string)
+	toUser, _ := challenge["toUser"].(string)
+	fromUser, _ := challenge["fromUser"].(string)
+	appID, _ := challenge["appId"].(string)
 
 	recipientQueueKey := fmt.Sprintf("user:challenges:received:%s", toUser)
 	senderQueueKey := fmt.Sprintf("user:challenges:sent:%s", fromUser)
@@ -361,20 +309,35 @@ func UpdateChallengeStatus(challengeID, status string) error {
 	redisClient.LRem(ctx, recipientQueueKey, 1, challengeID)
 	redisClient.LRem(ctx, senderQueueKey, 1, challengeID)
 
-	// Notify challenger
+	// Notify challenger (with enough detail to suggest someone else after a decline)
+	response := ChallengeResponse{ChallengeID: challengeID, AppID: appID, ToUser: toUser, Reason: reason}
+	response.Options, _ = challenge["options"].(map[string]interface{})
+	if reason != "" {
+		response.ReasonText = declineReasons[reason]
+	}
+	event := evChallengeAccepted.New("", response)
+	if status == "rejected" {
+		event = evChallengeRejected.New("", response)
+	}
+
 	channel := fmt.Sprintf("user:%s", fromUser)
-	if err := redisClient.Publish(ctx, channel, status).Err(); err != nil {
+	if err := redisClient.Publish(ctx, channel, event.Encode()).Err(); err != nil {
 		return fmt.Errorf("challenge updated but notification failed: %w", err)
 	}
 
 	return nil
 }
 
-// SubscribeToUserEvents creates a Redis pub/sub subscription for user notifications
-func SubscribeToUserEvents(email string) *redis.PubSub {
+// SubscribeToUserEvents opens a lobby stream subscription for user notifications
+func SubscribeToUserEvents(streamCtx context.Context, email, deviceID string) (*sse.Subscription, error) {
 	userChannel := fmt.Sprintf("user:%s", email)
 	// Subscribe to both user-specific channel and global presence updates
-	return redisClient.Subscribe(ctx, userChannel, "presence:updates")
+	channels := []string{userChannel, "presence:updates"}
+	if deviceID != "" {
+		// Plus notifications routed to this device only
+		channels = append(channels, deviceChannel(email, deviceID))
+	}
+	return sseBroker.Subscribe(streamCtx, "lobby", channels...)
 }
 
 // GetChallenge retrieves a challenge by ID from Redis
@@ -393,27 +356,9 @@ func GetChallenge(challengeID string) (*Challenge, error) {
 	return &challenge, nil
 }
 
-// GetUserPresence retrieves a user's presence info from Redis
-func GetUserPresence(email string) (*UserPresence, error) {
-	key := fmt.Sprintf("user:presence:%s", email)
-	data, err := redisClient.Get(ctx, key).Result()
-	if err != nil {
-		return nil, fmt.Errorf("user not found or offline")
-	}
-
-	var presence UserPresence
-	if err := json.Unmarshal([]byte(data), &presence); err != nil {
-		return nil, fmt.Errorf("failed to parse presence: %w", err)
-	}
-
-	return &presence, nil
-}
-
-// PublishGameStarted notifies a user that a game has started
+// PublishGameStarted notifies a user that a game has started (on the device they're using)
 func PublishGameStarted(email, appID, gameID string) error {
-	channel := fmt.Sprintf("user:%s", email)
-	payload := fmt.Sprintf("game_started:%s:%s", appID, gameID)
-	return redisClient.Publish(ctx, channel, payload).Err()
+	return publishToActiveDevice(email, evGameStarted.New("", GameStarted{AppID: appID, GameID: gameID}))
 }
 
 // AcceptMultiPlayerChallenge adds a player to the accepted list
@@ -483,6 +428,5 @@ func AcceptMultiPlayerChallenge(challengeID, acceptingUser string) (bool, error)
 // PublishChallengeUpdate notifies the initiator about challenge acceptance progress
 func PublishChallengeUpdate(challenge *Challenge) error {
 	channel := fmt.Sprintf("user:%s", challenge.InitiatorID)
-	payload := fmt.Sprintf("challenge_update:%s", challenge.ID)
-	return redisClient.Publish(ctx, channel, payload).Err()
+	return redisClient.Publish(ctx, channel, evChallengeUpdate.New("", ChallengeRef{ChallengeID: challenge.ID}).Encode()).Err()
 }
@@ -0,0 +1,382 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/achgithub/activity-hub-common/i18n"
+)
+
+// Scheduled challenges ("book a game for 8pm"): a 2-player challenge booked
+// for later. Both players are reminded shortly before; at the booked time the
+// challenge is sent as usual once both are online and out of a game, or the
+// booking expires if that hasn't happened by the end of the grace period.
+
+const (
+	scheduledMinLead       = 5 * time.Minute     // Earliest booking, from now
+	scheduledMaxLead       = 30 * 24 * time.Hour // Latest booking, from now
+	scheduledReminderLead  = 10 * time.Minute    // Reminder before the booked time
+	scheduledGrace         = 10 * time.Minute    // How long activation waits for both players
+	scheduledCheckInterval = 30 * time.Second
+	scheduledCalendarDays  = 14 // Default days listed by the calendar
+	scheduledMaxDays       = 62
+)
+
+// ScheduledChallenge is a booked challenge. Status is scheduled, activated
+// (sent as ChallengeID), expired or cancelled.
+type ScheduledChallenge struct {
+	ID          int                    `json:"id"`
+	FromUser    string                 `json:"fromUser"`
+	ToUser      string                 `json:"toUser"`
+	AppID       string                 `json:"appId"`
+	Options     map[string]interface{} `json:"options,omitempty"`
+	ScheduledAt time.Time              `json:"scheduledAt"`
+	Status      string                 `json:"status"`
+	ChallengeID string                 `json:"challengeId,omitempty"`
+	RemindedAt  *time.Time             `json:"remindedAt,omitempty"`
+	CreatedAt   time.Time              `json:"createdAt"`
+}
+
+// CalendarDay is one day of a player's bookings, in booked-time order
+type CalendarDay struct {
+	Date       string               `json:"date"` // YYYY-MM-DD, server time
+	Challenges []ScheduledChallenge `json:"challenges"`
+}
+
+const scheduledColumns = `id, from_user, to_user, app_id, options, scheduled_at, status,
+	COALESCE(challenge_id, ''), reminded_at, created_at`
+
+func scanScheduledChallenge(row interface{ Scan(...interface{}) error }) (*ScheduledChallenge, error) {
+	var c ScheduledChallenge
+	var options []byte
+	var remindedAt sql.NullTime
+	if err := row.Scan(&c.ID, &c.FromUser, &c.ToUser, &c.AppID, &options, &c.ScheduledAt, &c.Status,
+		&c.ChallengeID, &remindedAt, &c.CreatedAt); err != nil {
+		return nil, err
+	}
+	if len(options) > 0 {
+		json.Unmarshal(options, &c.Options)
+	}
+	if remindedAt.Valid {
+		c.RemindedAt = &remindedAt.Time
+	}
+	return &c, nil
+}
+
+// HandleScheduleChallenge - POST /api/lobby/challenge/scheduled
+// Books a 2-player challenge for a later time. The opponent needn't be online.
+func HandleScheduleChallenge(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		FromUser    string                 `json:"fromUser"`
+		ToUser      string                 `json:"toUser"`
+		AppID       string                 `json:"appId"`
+		Options     map[string]interface{} `json:"options"`
+		ScheduledAt string                 `json:"scheduledAt"` // RFC3339
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, i18n.T(r, "invalid_request"), http.StatusBadRequest)
+		return
+	}
+
+	if req.FromUser == "" || req.ToUser == "" || req.AppID == "" || req.ScheduledAt == "" {
+		http.Error(w, i18n.T(r, "missing_fields"), http.StatusBadRequest)
+		return
+	}
+	if req.FromUser == req.ToUser {
+		http.Error(w, i18n.T(r, "scheduled_self"), http.StatusBadRequest)
+		return
+	}
+
+	at, err := time.Parse(time.RFC3339, req.ScheduledAt)
+	if err != nil {
+		http.Error(w, i18n.T(r, "scheduled_time_invalid"), http.StatusBadRequest)
+		return
+	}
+	if until := time.Until(at); until < scheduledMinLead || until > scheduledMaxLead {
+		http.Error(w, i18n.T(r, "scheduled_time_range", int(scheduledMinLead.Minutes()), int(scheduledMaxLead.Hours()/24)), http.StatusBadRequest)
+		return
+	}
+
+	// Multi-player games gather players in the lobby instead
+	app := GetAppByID(req.AppID)
+	if app == nil || !app.Enabled || !IsGameApp(req.AppID) || (app.MinPlayers != nil && *app.MinPlayers > 2) {
+		http.Error(w, i18n.T(r, "game_unavailable"), http.StatusBadRequest)
+		return
+	}
+
+	var venueID int
+	db.QueryRow("SELECT COALESCE(venue_id, 0) FROM users WHERE email = $1", req.FromUser).Scan(&venueID)
+	if openingHours.LobbyClosed(venueID, at) {
+		http.Error(w, i18n.T(r, "scheduled_lobby_closed"), http.StatusBadRequest)
+		return
+	}
+
+	if req.Options == nil {
+		req.Options = map[string]interface{}{}
+	}
+	options, _ := json.Marshal(req.Options)
+
+	booking, err := scanScheduledChallenge(db.QueryRow(`
+		INSERT INTO scheduled_challenges (from_user, to_user, app_id, options, scheduled_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING `+scheduledColumns,
+		req.FromUser, req.ToUser, req.AppID, options, at))
+	if err != nil {
+		log.Printf("Failed to save scheduled challenge: %v", err)
+		http.Error(w, i18n.T(r, "scheduled_failed"), http.StatusInternalServerError)
+		return
+	}
+
+	if err := publishToActiveDevice(booking.ToUser, evScheduledChallengeReceived.New("", booking.ref())); err != nil {
+		log.Printf("Failed to notify %s of scheduled challenge %d: %v", booking.ToUser, booking.ID, err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":   true,
+		"scheduled": booking,
+	})
+}
+
+// HandleGetScheduledChallenges - GET /api/lobby/challenges/scheduled?email={email}&from={date}&days={n}
+// Returns the player's bookings, sent or received, grouped by day
+func HandleGetScheduledChallenges(w http.ResponseWriter, r *http.Request) {
+	email := r.URL.Query().Get("email")
+	if email == "" {
+		http.Error(w, i18n.T(r, "email_required"), http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	if s := r.URL.Query().Get("from"); s != "" {
+		d, err := time.ParseInLocation("2006-01-02", s, time.Local)
+		if err != nil {
+			http.Error(w, i18n.T(r, "scheduled_time_invalid"), http.StatusBadRequest)
+			return
+		}
+		from = d
+	}
+	days := scheduledCalendarDays
+	if s := r.URL.Query().Get("days"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 || n > scheduledMaxDays {
+			http.Error(w, i18n.T(r, "scheduled_days_invalid", scheduledMaxDays), http.StatusBadRequest)
+			return
+		}
+		days = n
+	}
+
+	rows, err := db.Query(`
+		SELECT `+scheduledColumns+`
+		FROM scheduled_challenges
+		WHERE (from_user = $1 OR to_user = $1)
+		  AND scheduled_at >= $2 AND scheduled_at < $3
+		  AND status <> 'cancelled'
+		ORDER BY scheduled_at, id
+	`, email, from, from.AddDate(0, 0, days))
+	if err != nil {
+		log.Printf("Failed to fetch scheduled challenges: %v", err)
+		http.Error(w, i18n.T(r, "challenges_failed"), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	calendar := []CalendarDay{}
+	for rows.Next() {
+		c, err := scanScheduledChallenge(rows)
+		if err != nil {
+			log.Printf("Failed to read scheduled challenge: %v", err)
+			http.Error(w, i18n.T(r, "challenges_failed"), http.StatusInternalServerError)
+			return
+		}
+		date := c.ScheduledAt.In(time.Local).Format("2006-01-02")
+		if len(calendar) == 0 || calendar[len(calendar)-1].Date != date {
+			calendar = append(calendar, CalendarDay{Date: date, Challenges: []ScheduledChallenge{}})
+		}
+		day := &calendar[len(calendar)-1]
+		day.Challenges = append(day.Challenges, *c)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"from": from.Format("2006-01-02"),
+		"days": calendar,
+	})
+}
+
+// HandleCancelScheduledChallenge - POST /api/lobby/challenge/scheduled/cancel?id={id}&email={email}
+// Either player can cancel a booking that hasn't been sent yet
+func HandleCancelScheduledChallenge(w http.ResponseWriter, r *http.Request) {
+	email := r.URL.Query().Get("email")
+	id, err := strconv.Atoi(r.URL.Query().Get("id"))
+	if email == "" || err != nil {
+		http.Error(w, i18n.T(r, "missing_fields"), http.StatusBadRequest)
+		return
+	}
+
+	booking, err := scanScheduledChallenge(db.QueryRow(`
+		UPDATE scheduled_challenges SET status = 'cancelled'
+		WHERE id = $1 AND (from_user = $2 OR to_user = $2) AND status = 'scheduled'
+		RETURNING `+scheduledColumns, id, email))
+	if err == sql.ErrNoRows {
+		http.Error(w, i18n.T(r, "scheduled_not_found"), http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Printf("Failed to cancel scheduled challenge %d: %v", id, err)
+		http.Error(w, i18n.T(r, "scheduled_failed"), http.StatusInternalServerError)
+		return
+	}
+
+	other := booking.ToUser
+	if email == booking.ToUser {
+		other = booking.FromUser
+	}
+	if err := publishToActiveDevice(other, evScheduledChallengeCancelled.New("", booking.ref())); err != nil {
+		log.Printf("Failed to notify %s of cancelled challenge %d: %v", other, id, err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"success": true})
+}
+
+// ref is the booking as sent on the lobby stream
+func (c *ScheduledChallenge) ref() ScheduledChallengeRef {
+	return ScheduledChallengeRef{
+		ID:          c.ID,
+		FromUser:    c.FromUser,
+		ToUser:      c.ToUser,
+		AppID:       c.AppID,
+		ScheduledAt: c.ScheduledAt,
+		ChallengeID: c.ChallengeID,
+	}
+}
+
+// runScheduledChallenges sends reminders and activates or expires bookings
+func runScheduledChallenges() {
+	ticker := time.NewTicker(scheduledCheckInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		remindScheduledChallenges()
+		activateScheduledChallenges()
+	}
+}
+
+// remindScheduledChallenges tells both players about bookings coming up.
+// Each booking is claimed by setting reminded_at, so it is only reminded once.
+func remindScheduledChallenges() {
+	rows, err := db.Query(`
+		UPDATE scheduled_challenges SET reminded_at = CURRENT_TIMESTAMP
+		WHERE status = 'scheduled' AND reminded_at IS NULL
+		  AND scheduled_at <= $1
+		RETURNING `+scheduledColumns, time.Now().Add(scheduledReminderLead))
+	if err != nil {
+		log.Printf("⚠️  Failed to fetch scheduled challenge reminders: %v", err)
+		return
+	}
+	var due []*ScheduledChallenge
+	for rows.Next() {
+		c, err := scanScheduledChallenge(rows)
+		if err != nil {
+			log.Printf("⚠️  Failed to read scheduled challenge: %v", err)
+			continue
+		}
+		due = append(due, c)
+	}
+	rows.Close()
+
+	for _, c := range due {
+		for _, email := range []string{c.FromUser, c.ToUser} {
+			if err := publishToActiveDevice(email, evScheduledChallengeReminder.New("", c.ref())); err != nil {
+				log.Printf("Failed to remind %s of scheduled challenge %d: %v", email, c.ID, err)
+			}
+		}
+	}
+}
+
+// activateScheduledChallenges sends each due booking as a challenge once
+// both players are online and out of a game, and expires it after the grace
+// period. A booking that can't be sent yet is tried again next time round.
+func activateScheduledChallenges() {
+	rows, err := db.Query(`
+		SELECT ` + scheduledColumns + `
+		FROM scheduled_challenges
+		WHERE status = 'scheduled' AND scheduled_at <= CURRENT_TIMESTAMP
+		ORDER BY scheduled_at, id
+	`)
+	if err != nil {
+		log.Printf("⚠️  Failed to fetch due scheduled challenges: %v", err)
+		return
+	}
+	var due []*ScheduledChallenge
+	for rows.Next() {
+		c, err := scanScheduledChallenge(rows)
+		if err != nil {
+			log.Printf("⚠️  Failed to read scheduled challenge: %v", err)
+			continue
+		}
+		due = append(due, c)
+	}
+	rows.Close()
+
+	for _, c := range due {
+		if scheduledPlayersReady(c) {
+			challengeID, err := issueChallenge(c.FromUser, c.ToUser, c.AppID, c.Options)
+			if err == nil {
+				markScheduledChallenge(c, "activated", challengeID)
+				log.Printf("✅ Scheduled challenge %d sent as %s", c.ID, challengeID)
+				continue
+			}
+			log.Printf("Failed to send scheduled challenge %d: %v", c.ID, err)
+		}
+
+		if time.Since(c.ScheduledAt) > scheduledGrace {
+			if markScheduledChallenge(c, "expired", "") {
+				for _, email := range []string{c.FromUser, c.ToUser} {
+					if err := publishToActiveDevice(email, evScheduledChallengeExpired.New("", c.ref())); err != nil {
+						log.Printf("Failed to notify %s of expired challenge %d: %v", email, c.ID, err)
+					}
+				}
+			}
+		}
+	}
+}
+
+// scheduledPlayersReady reports whether both players could take the challenge now
+func scheduledPlayersReady(c *ScheduledChallenge) bool {
+	if lobbyClosedFor(c.FromUser) {
+		return false
+	}
+	for _, email := range []string{c.FromUser, c.ToUser} {
+		online, err := IsUserOnline(email)
+		if err != nil || !online {
+			return false
+		}
+		if game, err := GetInGame(email); err != nil || game != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// markScheduledChallenge moves a booking out of scheduled, reporting whether
+// it was still scheduled (a player may have cancelled it meanwhile)
+func markScheduledChallenge(c *ScheduledChallenge, status, challengeID string) bool {
+	res, err := db.Exec(`
+		UPDATE scheduled_challenges SET status = $2, challenge_id = NULLIF($3, '')
+		WHERE id = $1 AND status = 'scheduled'
+	`, c.ID, status, challengeID)
+	if err != nil {
+		log.Printf("Failed to mark scheduled challenge %d %s: %v", c.ID, status, err)
+		return false
+	}
+	n, _ := res.RowsAffected()
+	c.Status = status
+	c.ChallengeID = challengeID
+	return n > 0
+}
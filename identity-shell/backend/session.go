@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	authlib "github.com/achgithub/activity-hub-common/auth"
+)
+
+// Cookie session mode (SESSION_COOKIES=true) is opt-in: the session token is set
+// in an HttpOnly, SameSite cookie instead of being returned for the frontend to
+// keep in localStorage. Off by default, login responses carry the token as before.
+var cookieSessions = getEnv("SESSION_COOKIES", "") == "true"
+
+// cookieSessionMiddleware lets cookie sessions through the shell's bearer-token
+// handlers unchanged: a request with the session cookie and no Authorization
+// header gets the cookie's token as its bearer token, provided it passes the
+// CSRF check. Without a valid CSRF token the cookie is ignored, so login and
+// validate still work with a stale cookie while everything else returns 401.
+func cookieSessionMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token, fromCookie := authlib.SessionToken(r); fromCookie {
+			if authlib.ValidCSRF(r, token) {
+				r.Header.Set("Authorization", "Bearer "+token)
+			} else {
+				log.Printf("⚠️  CSRF check failed for %s %s - ignoring session cookie", r.Method, r.URL.Path)
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// withSession adds a new session token to a login-style response: as cookies in
+// cookie session mode ("session": "cookie"), otherwise as "token" in the body.
+// Without a CSRF token to protect them (no AUTH_SIGNING_KEY) cookies aren't set
+// and the token goes in the body. email is the session's user, for the
+// inactivity timeout.
+func withSession(w http.ResponseWriter, token, email string, resp map[string]interface{}) map[string]interface{} {
+	startSessionActivity(token, email)
+	if cookieSessions {
+		err := authlib.SetSessionCookies(w, token)
+		if err == nil {
+			resp["session"] = "cookie"
+			return resp
+		}
+		log.Printf("⚠️  Cookie session refused (%v) - returning a bearer token", err)
+	}
+	resp["token"] = token
+	return resp
+}
+
+// handleLogout - POST /api/logout
+// Ends a cookie session. Bearer-token clients just discard their token.
+func handleLogout(w http.ResponseWriter, r *http.Request) {
+	authlib.ClearSessionCookies(w)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"success": true})
+}
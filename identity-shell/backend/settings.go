@@ -0,0 +1,281 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/achgithub/activity-hub-common/i18n"
+)
+
+// maxSettingValueBytes caps the size of a single custom setting value
+const maxSettingValueBytes = 2048
+
+// settingKeyPattern restricts custom keys to short, namespaced identifiers (e.g. "quiz.show_hints")
+var settingKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_.-]{0,63}$`)
+
+// knownSettings validates values for keys the platform understands.
+// Any other key matching settingKeyPattern is stored as opaque JSON for apps to use.
+var knownSettings = map[string]func(interface{}) bool{
+	"theme":              oneOf("light", "dark", "system"),
+	"sound_enabled":      isBool,
+	"sound_volume":       numberInRange(0, 100),
+	"notification_level": oneOf("all", "challenges", "none"),
+	"digest_frequency":   oneOf("off", "daily", "weekly"), // Email digest, see digest.go
+	"vibration_enabled":  isBool,
+	"reduce_motion":      isBool,
+	"locale":             isLocale, // Language for server messages; "" follows the browser
+
+	// Privacy (enforced server-side, see privacy.go)
+	"privacy_hide_presence": isBool,
+	"privacy_anonymous":     isBool,
+	"privacy_alias":         stringMaxLen(30),
+}
+
+func isBool(v interface{}) bool {
+	_, ok := v.(bool)
+	return ok
+}
+
+// isLocale accepts "" or a locale with a message catalog (see i18n)
+func isLocale(v interface{}) bool {
+	s, ok := v.(string)
+	return ok && (s == "" || i18n.Supported(s) != "")
+}
+
+func oneOf(options ...string) func(interface{}) bool {
+	return func(v interface{}) bool {
+		s, ok := v.(string)
+		if !ok {
+			return false
+		}
+		for _, o := range options {
+			if s == o {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func stringMaxLen(max int) func(interface{}) bool {
+	return func(v interface{}) bool {
+		s, ok := v.(string)
+		return ok && utf8.RuneCountInString(s) <= max
+	}
+}
+
+func numberInRange(min, max float64) func(interface{}) bool {
+	return func(v interface{}) bool {
+		n, ok := v.(float64)
+		return ok && n >= min && n <= max
+	}
+}
+
+// validateSetting checks a key/value pair before it is stored
+func validateSetting(key string, raw json.RawMessage) error {
+	if !settingKeyPattern.MatchString(key) {
+		return fmt.Errorf("invalid setting key: %s", key)
+	}
+	if len(raw) > maxSettingValueBytes {
+		return fmt.Errorf("setting %s is too large", key)
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return fmt.Errorf("setting %s is not valid JSON", key)
+	}
+
+	if validate, known := knownSettings[key]; known && !validate(value) {
+		return fmt.Errorf("invalid value for %s", key)
+	}
+	return nil
+}
+
+// loadUserSettings returns all settings for a user as raw JSON values
+func loadUserSettings(email string) (map[string]json.RawMessage, error) {
+	rows, err := db.Query("SELECT key, value FROM user_settings WHERE user_email = $1", email)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	settings := map[string]json.RawMessage{}
+	for rows.Next() {
+		var key string
+		var value []byte
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, err
+		}
+		settings[key] = json.RawMessage(value)
+	}
+	return settings, rows.Err()
+}
+
+// preferencesETag formats a preference version as an HTTP entity tag
+func preferencesETag(version int64) string {
+	return fmt.Sprintf(`"v%d"`, version)
+}
+
+// getPreferencesVersion returns the user's current preference version (0 if never saved)
+func getPreferencesVersion(email string) (int64, error) {
+	var version int64
+	err := db.QueryRow("SELECT version FROM user_preference_versions WHERE user_email = $1", email).Scan(&version)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return version, err
+}
+
+// lockPreferencesVersion locks the user's version row for the rest of the transaction
+// and enforces If-Match. Returns false (after writing a response) if the client is stale.
+func lockPreferencesVersion(w http.ResponseWriter, r *http.Request, tx *sql.Tx, email string) bool {
+	_, err := tx.Exec(`
+		INSERT INTO user_preference_versions (user_email, version)
+		VALUES ($1, 0)
+		ON CONFLICT (user_email) DO NOTHING
+	`, email)
+	if err != nil {
+		log.Printf("Failed to init preference version: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return false
+	}
+
+	var version int64
+	if err := tx.QueryRow("SELECT version FROM user_preference_versions WHERE user_email = $1 FOR UPDATE", email).Scan(&version); err != nil {
+		log.Printf("Failed to lock preference version: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return false
+	}
+
+	ifMatch := strings.TrimSpace(r.Header.Get("If-Match"))
+	if ifMatch == "" || ifMatch == "*" {
+		return true
+	}
+
+	current := preferencesETag(version)
+	if strings.TrimPrefix(ifMatch, "W/") != current {
+		w.Header().Set("ETag", current)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusPreconditionFailed)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "Preferences were changed on another device",
+			"version": version,
+		})
+		return false
+	}
+	return true
+}
+
+// bumpPreferencesVersion increments the user's version inside the transaction
+func bumpPreferencesVersion(tx *sql.Tx, email string) (int64, error) {
+	var version int64
+	err := tx.QueryRow(`
+		UPDATE user_preference_versions SET version = version + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE user_email = $1
+		RETURNING version
+	`, email).Scan(&version)
+	return version, err
+}
+
+// handlePatchUserSettings - PATCH /api/user/preferences/settings
+// Merges settings into the user's store. A null value deletes the key.
+// Send If-Match with the last ETag to avoid clobbering changes from another device.
+func handlePatchUserSettings(w http.ResponseWriter, r *http.Request) {
+	email := extractEmailFromRequest(r)
+	if email == "" {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req struct {
+		Settings map[string]json.RawMessage `json:"settings"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Settings) == 0 {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	for key, raw := range req.Settings {
+		if string(raw) == "null" {
+			continue
+		}
+		if err := validateSetting(key, raw); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("Failed to begin transaction: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	if !lockPreferencesVersion(w, r, tx, email) {
+		return
+	}
+
+	if err := writeSettings(tx, email, req.Settings); err != nil {
+		log.Printf("Failed to save settings: %v", err)
+		http.Error(w, "Failed to update settings", http.StatusInternalServerError)
+		return
+	}
+
+	version, err := bumpPreferencesVersion(tx, email)
+	if err != nil {
+		log.Printf("Failed to bump preference version: %v", err)
+		http.Error(w, "Failed to update settings", http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Failed to commit transaction: %v", err)
+		http.Error(w, "Failed to update settings", http.StatusInternalServerError)
+		return
+	}
+
+	settings, err := loadUserSettings(email)
+	if err != nil {
+		log.Printf("Failed to reload settings: %v", err)
+	}
+
+	w.Header().Set("ETag", preferencesETag(version))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":  true,
+		"settings": settings,
+		"version":  version,
+	})
+}
+
+// writeSettings upserts (or, for null values, deletes) settings inside a transaction
+func writeSettings(tx *sql.Tx, email string, settings map[string]json.RawMessage) error {
+	for key, raw := range settings {
+		if string(raw) == "null" {
+			if _, err := tx.Exec("DELETE FROM user_settings WHERE user_email = $1 AND key = $2", email, key); err != nil {
+				return err
+			}
+			continue
+		}
+
+		_, err := tx.Exec(`
+			INSERT INTO user_settings (user_email, key, value, updated_at)
+			VALUES ($1, $2, $3, CURRENT_TIMESTAMP)
+			ON CONFLICT (user_email, key) DO UPDATE
+			SET value = EXCLUDED.value, updated_at = CURRENT_TIMESTAMP
+		`, email, key, []byte(raw))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
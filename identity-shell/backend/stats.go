@@ -0,0 +1,211 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// healthCheckTimeout bounds each app's health probe so one hung backend can't stall the dashboard
+const healthCheckTimeout = 2 * time.Second
+
+// AppStats is one app's live state on the admin dashboard
+type AppStats struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Enabled     bool   `json:"enabled"`
+	Health      string `json:"health"` // up, unhealthy, down, or none (no backend)
+	LatencyMs   int64  `json:"latencyMs,omitempty"`
+	Error       string `json:"error,omitempty"`
+	ActiveGames int    `json:"activeGames"` // Sessions in the session registry
+	Players     int    `json:"players"`     // Participants across those sessions
+	Challenges  int    `json:"pendingChallenges"`
+}
+
+// handleAdminGetStats - GET /api/admin/stats
+// Live platform overview for super users: online users, pending challenges,
+// active games per app (from the awareness session registry), lobby SSE
+// connections and per-app backend health
+func handleAdminGetStats(w http.ResponseWriter, r *http.Request) {
+	users, err := GetOnlineUsers()
+	if err != nil {
+		log.Printf("Failed to fetch online users: %v", err)
+		http.Error(w, "Failed to fetch online users", http.StatusInternalServerError)
+		return
+	}
+
+	byStatus := map[string]int{}
+	devices := 0
+	for _, u := range users {
+		byStatus[u.Status]++
+		devices += u.DeviceCount
+	}
+
+	pending := countPendingChallenges()
+	sessions, players := countActiveSessions()
+
+	apps := []AppStats{}
+	for _, app := range GetAllApps() {
+		if app.ID == "lobby" {
+			continue
+		}
+		apps = append(apps, AppStats{
+			ID:          app.ID,
+			Name:        app.Name,
+			Enabled:     app.Enabled,
+			Health:      "none",
+			ActiveGames: sessions[app.ID],
+			Players:     players[app.ID],
+			Challenges:  pending[app.ID],
+		})
+	}
+	checkAppHealth(apps)
+
+	totalPending := 0
+	for _, n := range pending {
+		totalPending += n
+	}
+
+	services := map[string]string{"postgres": "up", "redis": "up"}
+	if err := db.Ping(); err != nil {
+		services["postgres"] = "down"
+	}
+	if err := redisClient.Ping(ctx).Err(); err != nil {
+		services["redis"] = "down"
+	}
+
+	// Counted across every shell instance
+	lobbyConnections, err := sseBroker.Connections(r.Context(), "lobby")
+	if err != nil {
+		log.Printf("⚠️  Failed to count lobby streams: %v", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"generatedAt": time.Now().Unix(),
+		"users": map[string]interface{}{
+			"online":   len(users),
+			"byStatus": byStatus,
+			"devices":  devices,
+		},
+		"challenges": map[string]interface{}{
+			"pending": totalPending,
+		},
+		"sse": map[string]interface{}{
+			"lobbyConnections": lobbyConnections,
+		},
+		"services": services,
+		"apps":     apps,
+	})
+}
+
+// countPendingChallenges returns pending (unexpired) challenges per app
+func countPendingChallenges() map[string]int {
+	counts := map[string]int{}
+
+	keys, err := redisClient.Keys(ctx, "challenge:*").Result()
+	if err != nil || len(keys) == 0 {
+		return counts
+	}
+
+	values, err := redisClient.MGet(ctx, keys...).Result()
+	if err != nil {
+		log.Printf("⚠️  Failed to read challenges: %v", err)
+		return counts
+	}
+
+	for _, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			continue // Expired between Keys() and MGet()
+		}
+		var challenge Challenge
+		if err := json.Unmarshal([]byte(data), &challenge); err != nil {
+			continue
+		}
+		if challenge.Status == "pending" {
+			counts[challenge.AppID]++
+		}
+	}
+
+	return counts
+}
+
+// countActiveSessions reads the awareness service's session registry
+// (session:app:{appId}:{sessionId} hashes of participants) and returns
+// sessions and participants per app
+func countActiveSessions() (map[string]int, map[string]int) {
+	sessions := map[string]int{}
+	players := map[string]int{}
+
+	keys, err := redisClient.Keys(ctx, "session:app:*").Result()
+	if err != nil {
+		log.Printf("⚠️  Failed to read session registry: %v", err)
+		return sessions, players
+	}
+
+	for _, key := range keys {
+		parts := strings.SplitN(strings.TrimPrefix(key, "session:app:"), ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		n, err := redisClient.HLen(ctx, key).Result()
+		if err != nil || n == 0 {
+			continue
+		}
+		sessions[parts[0]]++
+		players[parts[0]] += int(n)
+	}
+
+	return sessions, players
+}
+
+// checkAppHealth probes each enabled app backend's /api/health concurrently
+func checkAppHealth(apps []AppStats) {
+	client := &http.Client{Timeout: healthCheckTimeout}
+
+	var wg sync.WaitGroup
+	for i := range apps {
+		app := GetAppByID(apps[i].ID)
+		if app == nil || app.BackendPort == 0 || !app.Enabled {
+			continue
+		}
+
+		wg.Add(1)
+		go func(stats *AppStats, port int) {
+			defer wg.Done()
+
+			start := time.Now()
+			resp, err := client.Get(fmt.Sprintf("http://127.0.0.1:%d/api/health", port))
+			stats.LatencyMs = time.Since(start).Milliseconds()
+			if err != nil {
+				stats.Health = "down"
+				stats.Error = err.Error()
+				return
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode == http.StatusOK {
+				stats.Health = "up"
+			} else {
+				stats.Health = "unhealthy"
+				stats.Error = fmt.Sprintf("status %d", resp.StatusCode)
+			}
+		}(&apps[i], app.BackendPort)
+	}
+	wg.Wait()
+
+	// Problems first, then busiest
+	healthy := func(s AppStats) bool { return s.Health == "up" || s.Health == "none" }
+	sort.SliceStable(apps, func(i, j int) bool {
+		if healthy(apps[i]) != healthy(apps[j]) {
+			return !healthy(apps[i])
+		}
+		return apps[i].Players > apps[j].Players
+	})
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// declineReasons are the reasons a player can give when declining a challenge
+// (code -> text shown to the challenger)
+var declineReasons = map[string]string{
+	"busy":         "Busy right now",
+	"unknown_game": "Doesn't know that game",
+	"not_now":      "Maybe another time",
+}
+
+const maxOpponentSuggestions = 5
+
+// OpponentSuggestion is an online user who has recently played an app
+type OpponentSuggestion struct {
+	UserPresence
+	GamesPlayed int   `json:"gamesPlayed"`
+	LastPlayed  int64 `json:"lastPlayed"` // Unix timestamp
+}
+
+// HandleGetOpponentSuggestions - GET /api/lobby/challenge/suggestions?email={email}&appId={app}&exclude={a,b}
+// Suggests other online users who played the app in the last 30 days, most recent first
+// (shown to a challenger after their challenge is declined)
+func HandleGetOpponentSuggestions(w http.ResponseWriter, r *http.Request) {
+	email := r.URL.Query().Get("email")
+	appID := r.URL.Query().Get("appId")
+	if email == "" || appID == "" {
+		http.Error(w, "email and appId parameters required", http.StatusBadRequest)
+		return
+	}
+
+	excluded := map[string]bool{email: true}
+	for _, e := range strings.Split(r.URL.Query().Get("exclude"), ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			excluded[e] = true
+		}
+	}
+
+	users, err := GetOnlineUsers()
+	if err != nil {
+		log.Printf("Failed to fetch online users: %v", err)
+		http.Error(w, "Failed to fetch online users", http.StatusInternalServerError)
+		return
+	}
+	users = filterHiddenPresence(users)
+
+	// Only users who could take a challenge now
+	candidates := map[string]UserPresence{}
+	emails := []string{}
+	for _, u := range users {
+		if excluded[u.Email] || u.Status == "in_game" {
+			continue
+		}
+		candidates[u.Email] = u
+		emails = append(emails, u.Email)
+	}
+
+	suggestions := []OpponentSuggestion{}
+	if len(emails) == 0 {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"suggestions": suggestions})
+		return
+	}
+
+	// Players from accepted 2-player challenges and started multi-player challenges
+	rows, err := db.Query(`
+		SELECT player, COUNT(*), EXTRACT(EPOCH FROM MAX(responded_at))::BIGINT
+		FROM (
+			SELECT from_user AS player, responded_at FROM challenges
+			WHERE app_id = $1 AND status = 'accepted'
+			UNION ALL
+			SELECT to_user, responded_at FROM challenges
+			WHERE app_id = $1 AND status = 'accepted'
+			UNION ALL
+			SELECT unnest(player_ids), responded_at FROM challenges
+			WHERE app_id = $1 AND status = 'active'
+		) played
+		WHERE player = ANY($2) AND responded_at > NOW() - INTERVAL '30 days'
+		GROUP BY player
+		ORDER BY MAX(responded_at) DESC
+		LIMIT $3
+	`, appID, pq.Array(emails), maxOpponentSuggestions)
+	if err != nil {
+		log.Printf("Failed to query recent players: %v", err)
+		http.Error(w, "Failed to fetch suggestions", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s OpponentSuggestion
+		var player string
+		if err := rows.Scan(&player, &s.GamesPlayed, &s.LastPlayed); err != nil {
+			continue
+		}
+		s.UserPresence = candidates[player]
+		suggestions = append(suggestions, s)
+	}
+
+	presences := make([]UserPresence, len(suggestions))
+	for i := range suggestions {
+		presences[i] = suggestions[i].UserPresence
+	}
+	attachPresenceProfiles(presences)
+	for i := range suggestions {
+		suggestions[i].UserPresence = presences[i]
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"suggestions": suggestions})
+}
@@ -0,0 +1,319 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	authlib "github.com/achgithub/activity-hub-common/auth"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// Table devices: one shared tablet per pub table. Staff sign the tablet in as
+// a table, then the people sitting there add themselves as players. The
+// table's session only picks a player; apps are launched as that player, with
+// their own session, so their quiz answers and game scores are kept apart.
+
+const (
+	maxTablePlayers         = 8
+	maxTableNameLength      = 50
+	maxTablePlayerNameRunes = 30
+)
+
+// Staff who may sign a tablet in as a table
+var tableDeviceRoles = []string{"game_admin", "quiz_master", "super_user"}
+
+// TablePlayer is a player profile on a table device
+type TablePlayer struct {
+	ID    int    `json:"id"`
+	Email string `json:"email"` // What they play as: table-player-{key}
+	Name  string `json:"name"`
+	Flair string `json:"flair,omitempty"`
+}
+
+// tableDevice resolves the request's session, which must be a table device
+// rather than one of its players
+func tableDevice(w http.ResponseWriter, r *http.Request) (*authlib.AuthUser, bool) {
+	user, ok := requestUser(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return nil, false
+	}
+	if user.TableID == 0 || user.IsTablePlayer() {
+		http.Error(w, "Forbidden - table device session required", http.StatusForbidden)
+		return nil, false
+	}
+	return user, true
+}
+
+// tableUserResponse is the user object login and validate return for a table
+// device or one of its players
+func tableUserResponse(user *authlib.AuthUser) map[string]interface{} {
+	return map[string]interface{}{
+		"email":    user.Email,
+		"name":     user.Name,
+		"is_admin": false,
+		"roles":    []string{},
+		"venue_id": user.VenueID,
+		"is_table": !user.IsTablePlayer(),
+		"table_id": user.TableID,
+	}
+}
+
+// handleTableLogin - POST /api/login/table {name}
+// Signs this device in as a table. Sent with the session of a staff member,
+// which the table session replaces on the device.
+func handleTableLogin(w http.ResponseWriter, r *http.Request) {
+	staff, ok := requestUser(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if staff.TableID != 0 || !hasAnyRole(staff.Roles, tableDeviceRoles) {
+		http.Error(w, "Forbidden - staff role required", http.StatusForbidden)
+		return
+	}
+
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || utf8.RuneCountInString(req.Name) > maxTableNameLength {
+		http.Error(w, fmt.Sprintf("Table name must be 1-%d characters", maxTableNameLength), http.StatusBadRequest)
+		return
+	}
+
+	var venueID interface{}
+	if staff.VenueID != 0 {
+		venueID = staff.VenueID
+	}
+	key := uuid.New().String()
+	var tableID int
+	err := db.QueryRow(`
+		INSERT INTO table_devices (device_key, name, venue_id, created_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`, key, req.Name, venueID, staff.ActorEmail()).Scan(&tableID)
+	if err != nil {
+		log.Printf("Failed to create table device: %v", err)
+		http.Error(w, "Failed to sign in table", http.StatusInternalServerError)
+		return
+	}
+
+	table := &authlib.AuthUser{
+		Email:   authlib.TableEmailPrefix + key,
+		Name:    req.Name,
+		VenueID: staff.VenueID,
+		TableID: tableID,
+	}
+	log.Printf("✅ Table login: %s (%d) by %s", req.Name, tableID, staff.ActorEmail())
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(withSession(w, authlib.TableSessionToken(table.Email), table.Email, map[string]interface{}{
+		"success": true,
+		"user":    tableUserResponse(table),
+	}))
+}
+
+// handleEndTable - POST /api/table/end
+// Signs the table out. Its players' sessions end with it; their answers and
+// scores stay under the names they played as.
+func handleEndTable(w http.ResponseWriter, r *http.Request) {
+	table, ok := tableDevice(w, r)
+	if !ok {
+		return
+	}
+
+	if _, err := db.Exec("UPDATE table_devices SET ended_at = CURRENT_TIMESTAMP WHERE id = $1", table.TableID); err != nil {
+		log.Printf("Failed to end table device: %v", err)
+		http.Error(w, "Failed to sign out table", http.StatusInternalServerError)
+		return
+	}
+	authlib.ClearSessionCookies(w)
+	log.Printf("✅ Table signed out: %s (%d)", table.Name, table.TableID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"success": true})
+}
+
+// handleGetTablePlayers - GET /api/table/players
+func handleGetTablePlayers(w http.ResponseWriter, r *http.Request) {
+	table, ok := tableDevice(w, r)
+	if !ok {
+		return
+	}
+
+	players, err := loadTablePlayers(table.TableID)
+	if err != nil {
+		log.Printf("Failed to load table players: %v", err)
+		http.Error(w, "Failed to fetch players", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"players": players})
+}
+
+func loadTablePlayers(tableID int) ([]TablePlayer, error) {
+	rows, err := db.Query(`
+		SELECT id, player_key, name, COALESCE(flair, '')
+		FROM table_players
+		WHERE table_device_id = $1
+		ORDER BY created_at, id
+	`, tableID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	players := []TablePlayer{}
+	for rows.Next() {
+		var p TablePlayer
+		var key string
+		if err := rows.Scan(&p.ID, &key, &p.Name, &p.Flair); err != nil {
+			return nil, err
+		}
+		p.Email = authlib.TablePlayerEmailPrefix + key
+		players = append(players, p)
+	}
+	return players, rows.Err()
+}
+
+// handleAddTablePlayer - POST /api/table/players {name, flair}
+func handleAddTablePlayer(w http.ResponseWriter, r *http.Request) {
+	table, ok := tableDevice(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Name  string `json:"name"`
+		Flair string `json:"flair"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	req.Flair = strings.TrimSpace(req.Flair)
+	if req.Name == "" || utf8.RuneCountInString(req.Name) > maxTablePlayerNameRunes {
+		http.Error(w, fmt.Sprintf("Name must be 1-%d characters", maxTablePlayerNameRunes), http.StatusBadRequest)
+		return
+	}
+	if utf8.RuneCountInString(req.Flair) > maxFlairRunes || len(req.Flair) > 16 {
+		http.Error(w, "Flair must be a single emoji", http.StatusBadRequest)
+		return
+	}
+
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM table_players WHERE table_device_id = $1", table.TableID).Scan(&count); err != nil {
+		log.Printf("Failed to count table players: %v", err)
+		http.Error(w, "Failed to add player", http.StatusInternalServerError)
+		return
+	}
+	if count >= maxTablePlayers {
+		http.Error(w, fmt.Sprintf("This table is full (max: %d players)", maxTablePlayers), http.StatusBadRequest)
+		return
+	}
+
+	p := TablePlayer{Name: req.Name, Flair: req.Flair}
+	key := uuid.New().String()
+	err := db.QueryRow(`
+		INSERT INTO table_players (table_device_id, player_key, name, flair)
+		VALUES ($1, $2, $3, NULLIF($4, ''))
+		ON CONFLICT (table_device_id, name) DO NOTHING
+		RETURNING id
+	`, table.TableID, key, req.Name, req.Flair).Scan(&p.ID)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Someone at this table already has that name", http.StatusConflict)
+		return
+	} else if err != nil {
+		log.Printf("Failed to add table player: %v", err)
+		http.Error(w, "Failed to add player", http.StatusInternalServerError)
+		return
+	}
+	p.Email = authlib.TablePlayerEmailPrefix + key
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "player": p})
+}
+
+// handleRemoveTablePlayer - DELETE /api/table/players/{id}
+func handleRemoveTablePlayer(w http.ResponseWriter, r *http.Request) {
+	table, ok := tableDevice(w, r)
+	if !ok {
+		return
+	}
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid player ID", http.StatusBadRequest)
+		return
+	}
+
+	res, err := db.Exec("DELETE FROM table_players WHERE id = $1 AND table_device_id = $2", id, table.TableID)
+	if err != nil {
+		log.Printf("Failed to remove table player: %v", err)
+		http.Error(w, "Failed to remove player", http.StatusInternalServerError)
+		return
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		http.Error(w, "Player not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"success": true})
+}
+
+// handleMintTablePlayerLaunchToken - POST /api/table/players/{id}/launch-token
+// A single-use launch token that opens a mini-app as one of the table's players.
+func handleMintTablePlayerLaunchToken(w http.ResponseWriter, r *http.Request) {
+	table, ok := tableDevice(w, r)
+	if !ok {
+		return
+	}
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid player ID", http.StatusBadRequest)
+		return
+	}
+
+	player := &authlib.AuthUser{Roles: []string{}, VenueID: table.VenueID, TableID: table.TableID}
+	var key string
+	err = db.QueryRow(`
+		SELECT player_key, name FROM table_players WHERE id = $1 AND table_device_id = $2
+	`, id, table.TableID).Scan(&key, &player.Name)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Player not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Printf("Failed to load table player: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	player.Email = authlib.TablePlayerEmailPrefix + key
+
+	token, err := authlib.MintSignedToken(player, authlib.PurposeLaunch, authlib.LaunchTokenTTL)
+	if err != nil {
+		log.Printf("❌ Failed to mint launch token for %s: %v", player.Email, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"token":  token,
+		"player": TablePlayer{ID: id, Email: player.Email, Name: player.Name},
+	})
+}
@@ -0,0 +1,12 @@
+-- Migration: Add challenge decline reasons
+-- Date: 2026-10-17
+-- Description: Stores the optional reason a player gave when declining a challenge
+
+ALTER TABLE challenges
+  ADD COLUMN IF NOT EXISTS decline_reason VARCHAR(50);
+
+COMMENT ON COLUMN challenges.decline_reason IS 'Optional decline reason code (busy, unknown_game, not_now)';
+
+-- Suggestions look up recent players of an app
+CREATE INDEX IF NOT EXISTS idx_challenges_app_responded
+  ON challenges(app_id, responded_at);
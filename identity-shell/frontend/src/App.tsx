@@ -4,6 +4,8 @@ import './App.css';
 import { User } from './types';
 import LoginView from './components/LoginView';
 import Shell from './components/Shell';
+import TableView from './components/TableView';
+import { authHeaders, clearSession, hasSession, storeSession } from './session';
 
 // Dynamically determine API base URL based on current hostname
 // This allows the app to work regardless of Pi's IP address
@@ -14,32 +16,57 @@ function App() {
   const [loading, setLoading] = useState(true);
 
   useEffect(() => {
-    // Check for token in URL parameters first (for impersonation redirects)
+    // Check for a launch token in URL parameters first (for impersonation redirects)
     const params = new URLSearchParams(window.location.search);
+    const launch = params.get('launch');
     const urlToken = params.get('token');
 
-    if (urlToken) {
+    if (launch) {
+      exchangeLaunchToken(launch);
+      window.history.replaceState({}, document.title, window.location.pathname);
+    } else if (urlToken) {
+      // Legacy: session token in the URL
       // Store token from URL and validate
       localStorage.setItem('token', urlToken);
       validateToken(urlToken);
       // Clean up URL to remove token parameter
       window.history.replaceState({}, document.title, window.location.pathname);
     } else {
-      // Check for existing token in localStorage
-      const token = localStorage.getItem('token');
-      if (token) {
-        validateToken(token);
+      // Check for an existing session (token in localStorage or session cookie)
+      if (hasSession()) {
+        validateToken(localStorage.getItem('token') || '');
       } else {
         setLoading(false);
       }
     }
   }, []);
 
+  // Swap a single-use launch token for the session token
+  const exchangeLaunchToken = async (launch: string) => {
+    try {
+      const response = await fetch(`${API_BASE}/auth/launch`, {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ launch, cookie: true }),
+      });
+      if (!response.ok) {
+        setLoading(false);
+        return;
+      }
+      const data = await response.json();
+      storeSession(data);
+      await validateToken(data.token || '');
+    } catch (error) {
+      console.error('Launch token exchange failed:', error);
+      setLoading(false);
+    }
+  };
+
   const validateToken = async (token: string) => {
     try {
       const response = await fetch(`${API_BASE}/validate`, {
         method: 'POST',
-        headers: { 'Content-Type': 'application/json' },
+        headers: { 'Content-Type': 'application/json', ...authHeaders() },
         body: JSON.stringify({ token }),
       });
 
@@ -47,7 +74,7 @@ function App() {
       if (data.valid) {
         setUser(data.user);
       } else {
-        localStorage.removeItem('token');
+        await clearSession(API_BASE);
       }
     } catch (error) {
       console.error('Token validation failed:', error);
@@ -71,7 +98,7 @@ function App() {
 
       const data = await response.json();
       if (data.success) {
-        localStorage.setItem('token', data.token);
+        storeSession(data);
         setUser(data.user);
         return true;
       }
@@ -83,20 +110,19 @@ function App() {
   };
 
   const handleLogout = () => {
-    localStorage.removeItem('token');
+    clearSession(API_BASE);
     setUser(null);
   };
 
   const handleEndImpersonation = async () => {
-    const token = localStorage.getItem('token');
-    if (!token) return;
+    if (!hasSession()) return;
 
     try {
       const response = await fetch(`${API_BASE}/admin/end-impersonation`, {
         method: 'POST',
         headers: {
           'Content-Type': 'application/json',
-          'Authorization': `Bearer ${token}`,
+          ...authHeaders(),
         },
       });
 
@@ -104,7 +130,7 @@ function App() {
         const data = await response.json();
         if (data.success) {
           // Update token and user to super_user
-          localStorage.setItem('token', data.token);
+          storeSession(data);
           setUser(data.user);
         }
       }
@@ -126,7 +152,7 @@ function App() {
 
       const data = await response.json();
       if (data.success) {
-        localStorage.setItem('token', data.token);
+        storeSession(data);
         setUser(data.user);
         return true;
       }
@@ -137,6 +163,32 @@ function App() {
     }
   };
 
+  // Staff sign this device in as a table; the table session replaces theirs
+  const handleTableLogin = async (name: string): Promise<boolean> => {
+    try {
+      const response = await fetch(`${API_BASE}/login/table`, {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json', ...authHeaders() },
+        body: JSON.stringify({ name }),
+      });
+
+      if (!response.ok) {
+        return false;
+      }
+
+      const data = await response.json();
+      if (data.success) {
+        storeSession(data);
+        setUser(data.user);
+        return true;
+      }
+      return false;
+    } catch (error) {
+      console.error('Table login failed:', error);
+      return false;
+    }
+  };
+
   if (loading) {
     return (
       <div className="app-loading">
@@ -162,8 +214,15 @@ function App() {
         <Route
           path="/*"
           element={
-            user ? (
-              <Shell user={user} onLogout={handleLogout} onEndImpersonation={handleEndImpersonation} />
+            user?.is_table ? (
+              <TableView user={user} onLogout={handleLogout} />
+            ) : user ? (
+              <Shell
+                user={user}
+                onLogout={handleLogout}
+                onEndImpersonation={handleEndImpersonation}
+                onTableLogin={handleTableLogin}
+              />
             ) : (
               <Navigate to="/login" replace />
             )
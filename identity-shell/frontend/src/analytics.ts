@@ -0,0 +1,17 @@
+// Anonymous usage events for the landlord's dashboard. Only the venue of the
+// session is kept with an event, never the user.
+
+import { authHeaders } from './session';
+
+const API_BASE = `http://${window.location.hostname}:3001/api`;
+
+// Log an event without waiting for it. keepalive lets it finish while the
+// page navigates away to the app.
+export function logEvent(event: string, app: string, value?: number) {
+  fetch(`${API_BASE}/analytics/events`, {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json', ...authHeaders() },
+    body: JSON.stringify({ event, app, value: value || 0 }),
+    keepalive: true,
+  }).catch(() => {});
+}
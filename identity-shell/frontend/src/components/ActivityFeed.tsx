@@ -0,0 +1,49 @@
+import React from 'react';
+import './ActivityFeed.css';
+import { ActivityType } from '../types';
+import { useActivityFeed } from '../hooks/useActivityFeed';
+
+const ACTIVITY_ICONS: Record<ActivityType, string> = {
+  game_result: '🏆',
+  quiz_winner: '🧠',
+  lms_elimination: '⚽',
+  challenge: '⚔️',
+};
+
+// "just now", "5m ago", "3h ago", then the date
+function timeAgo(iso: string): string {
+  const seconds = Math.floor((Date.now() - new Date(iso).getTime()) / 1000);
+  if (seconds < 60) return 'just now';
+  if (seconds < 3600) return `${Math.floor(seconds / 60)}m ago`;
+  if (seconds < 86400) return `${Math.floor(seconds / 3600)}h ago`;
+  return new Date(iso).toLocaleDateString();
+}
+
+const ActivityFeed: React.FC = () => {
+  const { events, hasMore, loading, loadMore } = useActivityFeed();
+
+  if (events.length === 0) {
+    return <div className="activity-empty">{loading ? 'Loading…' : 'Nothing happening yet'}</div>;
+  }
+
+  return (
+    <div className="activity-feed">
+      <ul className="activity-list">
+        {events.map((e) => (
+          <li key={e.id} className={`activity-item ${e.type}`}>
+            <span className="activity-icon">{ACTIVITY_ICONS[e.type] || '•'}</span>
+            <span className="activity-text">{e.text}</span>
+            <span className="activity-time">{timeAgo(e.createdAt)}</span>
+          </li>
+        ))}
+      </ul>
+      {hasMore && (
+        <button className="activity-more" onClick={loadMore} disabled={loading}>
+          {loading ? 'Loading…' : 'Show more'}
+        </button>
+      )}
+    </div>
+  );
+};
+
+export default ActivityFeed;
@@ -1,8 +1,8 @@
-import React, { useEffect } from 'react';
+import React, { useEffect, useState } from 'react';
 import { useParams, useNavigate, useSearchParams } from 'react-router-dom';
 import './AppContainer.css';
 import { AppDefinition, User } from '../types';
-import { buildAppUrl } from '../hooks/useApps';
+import { buildAppUrl, fetchLaunchToken } from '../hooks/useApps';
 
 interface AppContainerProps {
   apps: AppDefinition[];
@@ -19,6 +19,17 @@ const AppContainer: React.FC<AppContainerProps> = ({ apps, user }) => {
 
   const app = apps.find((a) => a.id === appId);
 
+  // Single-use launch token for the iframe URL, minted each time an app is opened
+  const [launch, setLaunch] = useState<string | null>(null);
+  useEffect(() => {
+    let cancelled = false;
+    setLaunch(null);
+    fetchLaunchToken().then((t) => {
+      if (!cancelled) setLaunch(t || '');
+    });
+    return () => { cancelled = true; };
+  }, [appId, gameId]);
+
   // Listen for messages from iframe apps (e.g., "close app" requests)
   useEffect(() => {
     const handleMessage = (event: MessageEvent) => {
@@ -44,12 +55,13 @@ const AppContainer: React.FC<AppContainerProps> = ({ apps, user }) => {
   }
 
   // Build the iframe URL with user context
-  const iframeUrl = app.type === 'iframe' && app.url
+  const iframeUrl = app.type === 'iframe' && app.url && launch !== null
     ? buildAppUrl(app, {
         userId: user.email,
         userName: user.name,
         isAdmin: user.is_admin,
         gameId,
+        launch: launch || undefined,
       })
     : null;
 
@@ -82,6 +94,11 @@ const AppContainer: React.FC<AppContainerProps> = ({ apps, user }) => {
             className="app-iframe"
             sandbox="allow-same-origin allow-scripts allow-forms allow-popups"
           />
+        ) : app.type === 'iframe' && launch === null ? (
+          <div className="app-placeholder">
+            <div className="app-icon-large">{app.icon}</div>
+            <p>Loading {app.name}…</p>
+          </div>
         ) : app.type === 'internal' ? (
           <div className="app-placeholder">
             <div className="app-icon-large">{app.icon}</div>
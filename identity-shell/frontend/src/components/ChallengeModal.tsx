@@ -6,6 +6,7 @@ interface ChallengeModalProps {
   targetUser: string;
   challengeableApps: AppDefinition[];
   onConfirm: (appId: string, options: ChallengeOptions) => void;
+  onSchedule?: (appId: string, options: ChallengeOptions, scheduledAt: string) => void;
   onCancel: () => void;
   fetchGameConfig: (appId: string, backendPort: number) => Promise<GameConfig | null>;
 }
@@ -14,6 +15,7 @@ const ChallengeModal: React.FC<ChallengeModalProps> = ({
   targetUser,
   challengeableApps,
   onConfirm,
+  onSchedule,
   onCancel,
   fetchGameConfig,
 }) => {
@@ -24,6 +26,7 @@ const ChallengeModal: React.FC<ChallengeModalProps> = ({
   const [config, setConfig] = useState<GameConfig | null>(null);
   const [loading, setLoading] = useState(false);
   const [options, setOptions] = useState<ChallengeOptions>({});
+  const [bookFor, setBookFor] = useState(''); // datetime-local value; empty = play now
 
   // Load game config when app is selected
   useEffect(() => {
@@ -172,6 +175,17 @@ const ChallengeModal: React.FC<ChallengeModalProps> = ({
               ) : (
                 <p className="challenge-no-options">No additional options for this game.</p>
               )}
+
+              {onSchedule && (
+                <div className="challenge-option">
+                  <label>Book for later (optional)</label>
+                  <input
+                    type="datetime-local"
+                    value={bookFor}
+                    onChange={(e) => setBookFor(e.target.value)}
+                  />
+                </div>
+              )}
             </div>
 
             <div className="challenge-modal-footer">
@@ -183,13 +197,23 @@ const ChallengeModal: React.FC<ChallengeModalProps> = ({
               <button className="challenge-cancel-btn" onClick={onCancel}>
                 Cancel
               </button>
-              <button
-                className="challenge-confirm-btn"
-                onClick={() => onConfirm(selectedApp.id, options)}
-                disabled={loading}
-              >
-                Send Challenge
-              </button>
+              {bookFor && onSchedule ? (
+                <button
+                  className="challenge-confirm-btn"
+                  onClick={() => onSchedule(selectedApp.id, options, new Date(bookFor).toISOString())}
+                  disabled={loading}
+                >
+                  Book Game
+                </button>
+              ) : (
+                <button
+                  className="challenge-confirm-btn"
+                  onClick={() => onConfirm(selectedApp.id, options)}
+                  disabled={loading}
+                >
+                  Send Challenge
+                </button>
+              )}
             </div>
           </>
         )}
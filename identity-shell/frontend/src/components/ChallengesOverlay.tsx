@@ -1,5 +1,5 @@
 import React, { useState, useEffect } from 'react';
-import { Challenge, AppDefinition, UserPresence } from '../types';
+import { Challenge, AppDefinition, UserPresence, DeclineReason } from '../types';
 import ChallengeProgress from './ChallengeProgress';
 import './ChallengesOverlay.css';
 
@@ -11,10 +11,17 @@ interface ChallengesOverlayProps {
   userName: string;
   onlineUsers: UserPresence[];
   onAccept: (challengeId: string, userId?: string) => Promise<boolean>;
-  onReject: (challengeId: string) => Promise<boolean>;
+  onReject: (challengeId: string, reason?: DeclineReason) => Promise<boolean>;
   onClose: () => void;
 }
 
+// Reasons offered when declining (passed on to the challenger)
+const declineReasons: { value: DeclineReason; label: string }[] = [
+  { value: 'busy', label: 'Busy' },
+  { value: 'unknown_game', label: "Don't know it" },
+  { value: 'not_now', label: 'Another time' },
+];
+
 const ChallengesOverlay: React.FC<ChallengesOverlayProps> = ({
   receivedChallenges,
   sentChallenges,
@@ -28,6 +35,7 @@ const ChallengesOverlay: React.FC<ChallengesOverlayProps> = ({
 }) => {
   // Force re-render every second to update timers
   const [, setTick] = useState(0);
+  const [decliningId, setDecliningId] = useState<string | null>(null);
 
   useEffect(() => {
     const timer = setInterval(() => {
@@ -57,8 +65,9 @@ const ChallengesOverlay: React.FC<ChallengesOverlayProps> = ({
     }
   };
 
-  const handleRejectChallenge = async (challengeId: string) => {
-    await onReject(challengeId);
+  const handleRejectChallenge = async (challengeId: string, reason?: DeclineReason) => {
+    setDecliningId(null);
+    await onReject(challengeId, reason);
   };
 
   return (
@@ -122,20 +131,40 @@ const ChallengesOverlay: React.FC<ChallengesOverlayProps> = ({
                       <div className="challenge-info">
                         <strong>{challenge.fromUser}</strong> → <strong>{appName}</strong>
                       </div>
-                      <div className="challenge-actions">
-                        <button
-                          className="accept-btn"
-                          onClick={() => handleAcceptChallenge(challenge.id)}
-                        >
-                          Accept
-                        </button>
-                        <button
-                          className="reject-btn"
-                          onClick={() => handleRejectChallenge(challenge.id)}
-                        >
-                          Decline
-                        </button>
-                      </div>
+                      {decliningId === challenge.id ? (
+                        <div className="challenge-actions decline-reasons">
+                          {declineReasons.map(r => (
+                            <button
+                              key={r.value}
+                              className="reject-btn"
+                              onClick={() => handleRejectChallenge(challenge.id, r.value)}
+                            >
+                              {r.label}
+                            </button>
+                          ))}
+                          <button
+                            className="reject-btn"
+                            onClick={() => handleRejectChallenge(challenge.id)}
+                          >
+                            No reason
+                          </button>
+                        </div>
+                      ) : (
+                        <div className="challenge-actions">
+                          <button
+                            className="accept-btn"
+                            onClick={() => handleAcceptChallenge(challenge.id)}
+                          >
+                            Accept
+                          </button>
+                          <button
+                            className="reject-btn"
+                            onClick={() => setDecliningId(challenge.id)}
+                          >
+                            Decline
+                          </button>
+                        </div>
+                      )}
                       <div className="challenge-timer">
                         Expires in {Math.max(0, Math.floor((challenge.expiresAt * 1000 - Date.now()) / 1000))}s
                       </div>
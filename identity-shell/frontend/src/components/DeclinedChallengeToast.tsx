@@ -0,0 +1,67 @@
+import React, { useEffect, useState } from 'react';
+import './ChallengeToast.css';
+import { AppDefinition, ChallengeOptions, DeclinedChallenge } from '../types';
+
+interface DeclinedChallengeToastProps {
+  declined: DeclinedChallenge;
+  apps: AppDefinition[];
+  onChallenge: (toUser: string, appId: string, options?: ChallengeOptions) => Promise<boolean>;
+  onDismiss: () => void;
+}
+
+const DeclinedChallengeToast: React.FC<DeclinedChallengeToastProps> = ({
+  declined,
+  apps,
+  onChallenge,
+  onDismiss,
+}) => {
+  const [sending, setSending] = useState(false);
+  const appName = apps.find(a => a.id === declined.appId)?.name || declined.appId;
+  const hasSuggestions = declined.suggestions.length > 0;
+
+  // Stay up longer when there are players to pick from
+  useEffect(() => {
+    const timer = setTimeout(() => {
+      onDismiss();
+    }, hasSuggestions ? 20000 : 5000);
+
+    return () => clearTimeout(timer);
+  }, [onDismiss, hasSuggestions]);
+
+  const handleChallenge = async (email: string) => {
+    setSending(true);
+    await onChallenge(email, declined.appId, declined.options);
+    setSending(false);
+    onDismiss();
+  };
+
+  return (
+    <div className="challenge-toast declined-toast">
+      <div className="challenge-toast-icon">🙅</div>
+      <div className="challenge-toast-content">
+        <p className="challenge-toast-message">
+          <strong>{declined.toUser}</strong> declined <strong>{appName}</strong>
+          {declined.reasonText && <>: {declined.reasonText}</>}
+        </p>
+        {hasSuggestions && (
+          <div className="declined-suggestions">
+            <span className="declined-suggestions-label">Recently played {appName}:</span>
+            {declined.suggestions.map(s => (
+              <button
+                key={s.email}
+                className="declined-suggestion"
+                disabled={sending}
+                onClick={() => handleChallenge(s.email)}
+              >
+                {s.displayName || s.email}
+              </button>
+            ))}
+          </div>
+        )}
+      </div>
+      <button className="declined-toast-close" onClick={onDismiss} title="Dismiss">✕</button>
+    </div>
+  );
+};
+
+export default DeclinedChallengeToast;
@@ -1,5 +1,6 @@
 import React, { useState, useEffect } from 'react';
 import { AppDefinition, UserPresence, GameConfig, GameOption, ChallengeOptions } from '../types';
+import { playingLabel } from '../hooks/useLobby';
 import './GameChallengeModal.css';
 
 interface GameChallengeModalProps {
@@ -8,6 +9,7 @@ interface GameChallengeModalProps {
   onlineUsers: UserPresence[];
   favoriteUsers: Set<string>;
   onConfirm: (appId: string, playerIds: string[], options: ChallengeOptions) => void;
+  onSavePreset?: (name: string, appId: string, playerIds: string[], options: ChallengeOptions) => Promise<boolean>;
   onCancel: () => void;
   fetchGameConfig: (appId: string, backendPort: number) => Promise<GameConfig | null>;
 }
@@ -18,6 +20,7 @@ const GameChallengeModal: React.FC<GameChallengeModalProps> = ({
   onlineUsers,
   favoriteUsers,
   onConfirm,
+  onSavePreset,
   onCancel,
   fetchGameConfig,
 }) => {
@@ -27,6 +30,8 @@ const GameChallengeModal: React.FC<GameChallengeModalProps> = ({
   const [config, setConfig] = useState<GameConfig | null>(null);
   const [loading, setLoading] = useState(false);
   const [options, setOptions] = useState<ChallengeOptions>({});
+  const [saveAsPreset, setSaveAsPreset] = useState(false);
+  const [presetName, setPresetName] = useState('');
 
   // Determine if this is a 1v1 or group game
   const isGroupGame = (app.minPlayers ?? 0) > 2;
@@ -78,6 +83,8 @@ const GameChallengeModal: React.FC<GameChallengeModalProps> = ({
   }, [app, fetchGameConfig]);
 
   const togglePlayer = (email: string) => {
+    // Players in a game can't be challenged until it ends
+    if (onlineUsers.find(u => u.email === email)?.inGame) return;
     if (isGroupGame) {
       // Multi-select for group games
       if (selectedPlayers.includes(email)) {
@@ -217,13 +224,15 @@ const GameChallengeModal: React.FC<GameChallengeModalProps> = ({
                   filteredUsers.map(user => (
                     <div
                       key={user.email}
-                      className={`player-item ${selectedPlayers.includes(user.email) ? 'selected' : ''} ${favoriteUsers.has(user.email) ? 'favorite' : ''}`}
+                      className={`player-item ${selectedPlayers.includes(user.email) ? 'selected' : ''} ${favoriteUsers.has(user.email) ? 'favorite' : ''} ${user.inGame ? 'busy' : ''}`}
                       onClick={() => togglePlayer(user.email)}
                     >
                       <div className="player-info">
                         <span className={`status-dot ${user.status}`}></span>
                         <span className="player-name">{user.displayName}</span>
-                        {user.currentApp && (
+                        {user.inGame ? (
+                          <span className="player-app">{playingLabel(user)}</span>
+                        ) : user.currentApp && (
                           <span className="player-app">Playing {user.currentApp}</span>
                         )}
                       </div>
@@ -285,6 +294,30 @@ const GameChallengeModal: React.FC<GameChallengeModalProps> = ({
               ) : (
                 <p className="no-options">No additional options for this game.</p>
               )}
+
+              {/* Save this setup as a one-tap "usual game" in the lobby */}
+              {onSavePreset && selectedPlayers.length > 0 && (
+                <div className="gcm-save-preset">
+                  <label>
+                    <input
+                      type="checkbox"
+                      checked={saveAsPreset}
+                      onChange={(e) => setSaveAsPreset(e.target.checked)}
+                    />
+                    Save as a usual game
+                  </label>
+                  {saveAsPreset && (
+                    <input
+                      type="text"
+                      className="gcm-preset-name"
+                      value={presetName}
+                      maxLength={50}
+                      placeholder={`${app.name} night`}
+                      onChange={(e) => setPresetName(e.target.value)}
+                    />
+                  )}
+                </div>
+              )}
             </div>
 
             <div className="game-challenge-footer">
@@ -296,7 +329,12 @@ const GameChallengeModal: React.FC<GameChallengeModalProps> = ({
               </button>
               <button
                 className="gcm-confirm-btn"
-                onClick={() => onConfirm(app.id, selectedPlayers, options)}
+                onClick={async () => {
+                  if (onSavePreset && saveAsPreset && selectedPlayers.length > 0) {
+                    await onSavePreset(presetName.trim() || `${app.name} night`, app.id, selectedPlayers, options);
+                  }
+                  onConfirm(app.id, selectedPlayers, options);
+                }}
                 disabled={loading}
               >
                 {selectedPlayers.length === 0 ? 'Start Game' : 'Send Challenge'}
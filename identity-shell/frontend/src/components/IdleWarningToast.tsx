@@ -0,0 +1,36 @@
+import React, { useEffect, useState } from 'react';
+import './ChallengeToast.css';
+
+interface IdleWarningToastProps {
+  expiresAt: number; // unix seconds
+  onStayActive: () => void;
+}
+
+// Counts down to the inactivity logout; any tap or key press (or the button)
+// keeps the session
+const IdleWarningToast: React.FC<IdleWarningToastProps> = ({ expiresAt, onStayActive }) => {
+  const [now, setNow] = useState(Date.now());
+
+  useEffect(() => {
+    const timer = setInterval(() => setNow(Date.now()), 1000);
+    return () => clearInterval(timer);
+  }, []);
+
+  const secondsLeft = Math.max(0, Math.ceil(expiresAt - now / 1000));
+
+  return (
+    <div className="challenge-toast idle-toast">
+      <div className="challenge-toast-icon">⏲️</div>
+      <div className="challenge-toast-content">
+        <p className="challenge-toast-message">
+          You'll be logged out for inactivity in <strong>{secondsLeft}s</strong>
+        </p>
+        <button className="declined-suggestion idle-toast-button" onClick={onStayActive}>
+          I'm still here
+        </button>
+      </div>
+    </div>
+  );
+};
+
+export default IdleWarningToast;
@@ -1,9 +1,13 @@
 import React, { useState, useEffect } from 'react';
 import './Lobby.css';
-import { AppDefinition, UserPresence, ChallengeOptions, GameConfig } from '../types';
+import { AppDefinition, CalendarDay, UserPresence, ChallengeOptions, ChallengePreset, GameConfig } from '../types';
+import { playingLabel } from '../hooks/useLobby';
 import ChallengeModal from './ChallengeModal';
 import MultiPlayerChallengeModal from './MultiPlayerChallengeModal';
 import GameChallengeModal from './GameChallengeModal';
+import ActivityFeed from './ActivityFeed';
+import { fetchLaunchToken } from '../hooks/useApps';
+import { authHeaders, hasSession } from '../session';
 
 interface LobbyProps {
   apps: AppDefinition[];
@@ -13,6 +17,10 @@ interface LobbyProps {
   onlineUsers: UserPresence[];
   onSendChallenge: (toUser: string, appId: string, options?: ChallengeOptions) => Promise<boolean>;
   onSendMultiChallenge: (playerIds: string[], appId: string, minPlayers: number, maxPlayers: number, options?: ChallengeOptions) => Promise<boolean>;
+  onSendPresetChallenge: (preset: ChallengePreset) => Promise<boolean>;
+  scheduledChallenges: CalendarDay[];
+  onScheduleChallenge: (toUser: string, appId: string, scheduledAt: string, options?: ChallengeOptions) => Promise<boolean>;
+  onCancelScheduledChallenge: (id: number) => Promise<void>;
   fetchGameConfig: (appId: string, backendPort: number) => Promise<GameConfig | null>;
 }
 
@@ -25,6 +33,18 @@ interface AppPreference {
 
 const API_BASE = `http://${window.location.hostname}:3001/api`;
 
+// Tags on an app card for what the app supports beyond opening it
+const CapabilityBadges: React.FC<{ app: AppDefinition }> = ({ app }) => {
+  const caps = app.capabilities;
+  if (!caps || (!caps.displays && !caps.teams)) return null;
+  return (
+    <div className="app-capability-badges">
+      {caps.teams && <span className="app-capability-badge" title="Play as a team">👥 Teams</span>}
+      {caps.displays && <span className="app-capability-badge" title="Shows on the pub TVs">📺 On TV</span>}
+    </div>
+  );
+};
+
 const Lobby: React.FC<LobbyProps> = ({
   apps,
   onAppClick,
@@ -33,6 +53,10 @@ const Lobby: React.FC<LobbyProps> = ({
   onlineUsers,
   onSendChallenge,
   onSendMultiChallenge,
+  onSendPresetChallenge,
+  scheduledChallenges,
+  onScheduleChallenge,
+  onCancelScheduledChallenge,
   fetchGameConfig,
 }) => {
   // Online users overlay state
@@ -55,12 +79,11 @@ const Lobby: React.FC<LobbyProps> = ({
   // Fetch app preferences (including favorites) on mount
   useEffect(() => {
     const fetchPreferences = async () => {
-      const token = localStorage.getItem('token');
-      if (!token) return;
+      if (!hasSession()) return;
 
       try {
         const response = await fetch(`${API_BASE}/user/preferences`, {
-          headers: { 'Authorization': `Bearer ${token}` }
+          headers: authHeaders()
         });
         const data = await response.json();
 
@@ -82,6 +105,79 @@ const Lobby: React.FC<LobbyProps> = ({
     fetchPreferences();
   }, []);
 
+  // Challenge presets ("usual game") - one-tap challenges to usual opponents
+  const [presets, setPresets] = useState<ChallengePreset[]>([]);
+
+  const fetchPresets = async () => {
+    if (!hasSession()) return;
+
+    try {
+      const response = await fetch(`${API_BASE}/user/challenge-presets`, {
+        headers: authHeaders()
+      });
+      if (response.ok) {
+        const data = await response.json();
+        setPresets(data.presets || []);
+      }
+    } catch (error) {
+      console.error('Failed to fetch challenge presets:', error);
+    }
+  };
+
+  useEffect(() => {
+    fetchPresets();
+    // eslint-disable-next-line react-hooks/exhaustive-deps
+  }, []);
+
+  const savePreset = async (name: string, appId: string, opponents: string[], options: ChallengeOptions) => {
+    try {
+      const response = await fetch(`${API_BASE}/user/challenge-presets`, {
+        method: 'POST',
+        headers: {
+          'Content-Type': 'application/json',
+          ...authHeaders()
+        },
+        body: JSON.stringify({ name, appId, opponents, options })
+      });
+      if (!response.ok) {
+        console.error('Failed to save preset:', (await response.text()).trim());
+        return false;
+      }
+      fetchPresets();
+      return true;
+    } catch (error) {
+      console.error('Failed to save preset:', error);
+      return false;
+    }
+  };
+
+  const deletePreset = async (presetId: number, e: React.MouseEvent) => {
+    e.stopPropagation(); // Prevent sending the challenge
+
+    setPresets(presets.filter(p => p.id !== presetId));
+    try {
+      await fetch(`${API_BASE}/user/challenge-presets/${presetId}`, {
+        method: 'DELETE',
+        headers: authHeaders()
+      });
+    } catch (error) {
+      console.error('Failed to delete preset:', error);
+    }
+    fetchPresets();
+  };
+
+  const handlePresetChallenge = async (preset: ChallengePreset) => {
+    if (await onSendPresetChallenge(preset)) {
+      fetchPresets(); // Most recently used first
+    }
+  };
+
+  // "Dave, Sam" for a preset's opponents
+  const presetOpponentNames = (preset: ChallengePreset) =>
+    preset.opponents
+      .map(email => onlineUsers.find(u => u.email === email)?.displayName || email.split('@')[0])
+      .join(', ');
+
   const toggleSection = (sectionId: string) => {
     const newCollapsed = new Set(collapsedSections);
     if (newCollapsed.has(sectionId)) {
@@ -136,8 +232,7 @@ const Lobby: React.FC<LobbyProps> = ({
     setFavoriteAppIds(newFavorites);
 
     // Save to database
-    const token = localStorage.getItem('token');
-    if (!token) return;
+    if (!hasSession()) return;
 
     try {
       // Build updated preferences array
@@ -159,7 +254,7 @@ const Lobby: React.FC<LobbyProps> = ({
         method: 'PUT',
         headers: {
           'Content-Type': 'application/json',
-          'Authorization': `Bearer ${token}`
+          ...authHeaders()
         },
         body: JSON.stringify({ preferences: updatedPreferences })
       });
@@ -191,12 +286,12 @@ const Lobby: React.FC<LobbyProps> = ({
     app: AppDefinition;
   } | null>(null);
 
-  // Get challengeable games (category: game, has realtime support, has backend port)
+  // Get challengeable games: the app says so in its /api/config, or, until it
+  // has answered, it's a game with realtime support and a backend port
   const challengeableApps = apps.filter(app =>
-    app.category === 'game' &&
-    app.realtime &&
-    app.realtime !== 'none' &&
-    app.backendPort
+    app.backendPort && (app.capabilities
+      ? app.capabilities.challenges
+      : app.category === 'game' && app.realtime && app.realtime !== 'none')
   );
 
   // Split into 2-player and multi-player apps
@@ -225,6 +320,19 @@ const Lobby: React.FC<LobbyProps> = ({
     }
   };
 
+  // Book the challenge for later instead of sending it now
+  const handleScheduleChallenge = async (appId: string, options: ChallengeOptions, scheduledAt: string) => {
+    if (challengeModal && await onScheduleChallenge(challengeModal.targetUser, appId, scheduledAt, options)) {
+      setChallengeModal(null);
+    }
+  };
+
+  // "Dave" for a booked game's other player
+  const bookedOpponentName = (fromUser: string, toUser: string) => {
+    const other = fromUser === userEmail ? toUser : fromUser;
+    return onlineUsers.find(u => u.email === other)?.displayName || other.split('@')[0];
+  };
+
   // Send multi-player challenge from modal
   const handleConfirmMultiChallenge = async (appId: string, playerIds: string[], options: ChallengeOptions) => {
     const app = apps.find(a => a.id === appId);
@@ -246,6 +354,7 @@ const Lobby: React.FC<LobbyProps> = ({
 
       // Create game via backend
       try {
+        // Cookie sessions have no token for the cross-origin call - just open the app
         const token = localStorage.getItem('token');
         if (!token || !app?.backendPort) {
           onAppClick(appId);
@@ -269,7 +378,8 @@ const Lobby: React.FC<LobbyProps> = ({
           const result = await response.json();
           // Launch app with gameId (use gameId from response, or game.id as fallback)
           const gameId = result.gameId || result.game?.id || result.id;
-          const appUrl = `http://${window.location.hostname}:${app.backendPort}?gameId=${gameId}&userId=${userEmail}&userName=${encodeURIComponent(userName)}&token=${token}`;
+          const launch = await fetchLaunchToken();
+          const appUrl = `http://${window.location.hostname}:${app.backendPort}?gameId=${gameId}&userId=${userEmail}&userName=${encodeURIComponent(userName)}${launch ? `&launch=${launch}` : ''}`;
           window.location.href = appUrl;
         } else {
           // Fallback: just launch app
@@ -348,7 +458,9 @@ const Lobby: React.FC<LobbyProps> = ({
                   <div className="user-info">
                     <span className={`status-dot ${user.status}`}></span>
                     <span className="user-name">{user.displayName}</span>
-                    {user.currentApp && (
+                    {user.inGame ? (
+                      <span className="user-app">{playingLabel(user)}</span>
+                    ) : user.currentApp && (
                       <span className="user-app">in {user.currentApp}</span>
                     )}
                   </div>
@@ -378,6 +490,105 @@ const Lobby: React.FC<LobbyProps> = ({
       <div className="lobby-sections">
         {/* Available Apps Section */}
         <section className="lobby-section lobby-apps-full">
+          {/* Usual Games - saved challenge presets */}
+          {presets.length > 0 && (
+            <div className="app-section">
+              <div className="app-section-header" onClick={() => toggleSection('presets')}>
+                <h3 className="app-section-title">Usual Games</h3>
+                <span className={`section-toggle ${collapsedSections.has('presets') ? 'collapsed' : ''}`}>
+                  ▼
+                </span>
+              </div>
+              <div className={`app-section-content ${collapsedSections.has('presets') ? 'collapsed' : ''}`}>
+                <div className="preset-list">
+                  {presets.map((preset) => {
+                    const app = apps.find(a => a.id === preset.appId);
+                    const freeCount = preset.opponents.filter(email =>
+                      onlineUsers.some(u => u.email === email && !u.inGame)
+                    ).length;
+                    return (
+                      <button
+                        key={preset.id}
+                        className="preset-card"
+                        onClick={() => handlePresetChallenge(preset)}
+                        disabled={!app || freeCount === 0}
+                        title={freeCount === 0 ? 'None of these players are free right now' : `Challenge ${presetOpponentNames(preset)}`}
+                      >
+                        <span className="preset-icon">{app?.icon || '🎮'}</span>
+                        <span className="preset-info">
+                          <span className="preset-name">{preset.name}</span>
+                          <span className="preset-opponents">
+                            vs {presetOpponentNames(preset)} · {freeCount} free
+                          </span>
+                        </span>
+                        <span
+                          className="preset-delete"
+                          role="button"
+                          onClick={(e) => deletePreset(preset.id, e)}
+                          title="Delete preset"
+                        >
+                          ✕
+                        </span>
+                      </button>
+                    );
+                  })}
+                </div>
+              </div>
+            </div>
+          )}
+
+          {/* Booked Games - scheduled challenges by day */}
+          {scheduledChallenges.length > 0 && (
+            <div className="app-section">
+              <div className="app-section-header" onClick={() => toggleSection('booked')}>
+                <h3 className="app-section-title">Booked Games</h3>
+                <span className={`section-toggle ${collapsedSections.has('booked') ? 'collapsed' : ''}`}>
+                  ▼
+                </span>
+              </div>
+              <div className={`app-section-content ${collapsedSections.has('booked') ? 'collapsed' : ''}`}>
+                {scheduledChallenges.map((day) => (
+                  <div key={day.date} className="booked-day">
+                    <div className="booked-date">
+                      {new Date(`${day.date}T00:00:00`).toLocaleDateString([], { weekday: 'long', day: 'numeric', month: 'short' })}
+                    </div>
+                    <div className="preset-list">
+                      {day.challenges.map((booking) => {
+                        const app = apps.find(a => a.id === booking.appId);
+                        return (
+                          <div key={booking.id} className={`preset-card booked-${booking.status}`}>
+                            <span className="preset-icon">{app?.icon || '🎮'}</span>
+                            <span className="preset-info">
+                              <span className="preset-name">
+                                {new Date(booking.scheduledAt).toLocaleTimeString([], { hour: '2-digit', minute: '2-digit' })}
+                                {' '}{app?.name || booking.appId}
+                              </span>
+                              <span className="preset-opponents">
+                                vs {bookedOpponentName(booking.fromUser, booking.toUser)}
+                                {booking.status === 'activated' && ' · started'}
+                                {booking.status === 'expired' && ' · lapsed'}
+                              </span>
+                            </span>
+                            {booking.status === 'scheduled' && (
+                              <span
+                                className="preset-delete"
+                                role="button"
+                                onClick={() => onCancelScheduledChallenge(booking.id)}
+                                title="Cancel booking"
+                              >
+                                ✕
+                              </span>
+                            )}
+                          </div>
+                        );
+                      })}
+                    </div>
+                  </div>
+                ))}
+              </div>
+            </div>
+          )}
+
           {/* Favorites Section - Always show */}
           <div className="app-section">
             <div className="app-section-header" onClick={() => toggleSection('favorites')}>
@@ -419,6 +630,10 @@ const Lobby: React.FC<LobbyProps> = ({
                         </button>
                         <div className="app-icon">{app.icon}</div>
                         <h3>{app.name}</h3>
+                        {app.maintenance && (
+                          <span className="app-maintenance-badge" title={app.maintenance.message}>🔧 Maintenance</span>
+                        )}
+                        <CapabilityBadges app={app} />
                         <p>{app.description}</p>
                       </button>
                     );
@@ -428,6 +643,19 @@ const Lobby: React.FC<LobbyProps> = ({
             </div>
           </div>
 
+          {/* Recent Activity Section */}
+          <div className="app-section">
+            <div className="app-section-header" onClick={() => toggleSection('activity')}>
+              <h3 className="app-section-title">Recent Activity</h3>
+              <span className={`section-toggle ${collapsedSections.has('activity') ? 'collapsed' : ''}`}>
+                ▼
+              </span>
+            </div>
+            <div className={`app-section-content ${collapsedSections.has('activity') ? 'collapsed' : ''}`}>
+              <ActivityFeed />
+            </div>
+          </div>
+
           {/* Games Section */}
           {gameApps.length > 0 && (
             <div className="app-section">
@@ -462,6 +690,10 @@ const Lobby: React.FC<LobbyProps> = ({
                         </button>
                         <div className="app-icon">{app.icon}</div>
                         <h3>{app.name}</h3>
+                        {app.maintenance && (
+                          <span className="app-maintenance-badge" title={app.maintenance.message}>🔧 Maintenance</span>
+                        )}
+                        <CapabilityBadges app={app} />
                         <p>{app.description}</p>
                       </button>
                     );
@@ -497,6 +729,10 @@ const Lobby: React.FC<LobbyProps> = ({
                       </button>
                       <div className="app-icon">{app.icon}</div>
                       <h3>{app.name}</h3>
+                      {app.maintenance && (
+                        <span className="app-maintenance-badge" title={app.maintenance.message}>🔧 Maintenance</span>
+                      )}
+                      <CapabilityBadges app={app} />
                       <p>{app.description}</p>
                     </button>
                   ))}
@@ -531,6 +767,10 @@ const Lobby: React.FC<LobbyProps> = ({
                       </button>
                       <div className="app-icon">{app.icon}</div>
                       <h3>{app.name}</h3>
+                      {app.maintenance && (
+                        <span className="app-maintenance-badge" title={app.maintenance.message}>🔧 Maintenance</span>
+                      )}
+                      <CapabilityBadges app={app} />
                       <p>{app.description}</p>
                     </button>
                   ))}
@@ -547,6 +787,7 @@ const Lobby: React.FC<LobbyProps> = ({
           targetUser={challengeModal.targetUser}
           challengeableApps={twoPlayerApps}
           onConfirm={handleConfirmChallenge}
+          onSchedule={handleScheduleChallenge}
           onCancel={() => setChallengeModal(null)}
           fetchGameConfig={fetchGameConfig}
         />
@@ -572,6 +813,7 @@ const Lobby: React.FC<LobbyProps> = ({
           onlineUsers={onlineUsers}
           favoriteUsers={favoriteUsers}
           onConfirm={handleNewChallengeConfirm}
+          onSavePreset={hasSession() ? savePreset : undefined}
           onCancel={() => setNewChallengeModal(null)}
           fetchGameConfig={fetchGameConfig}
         />
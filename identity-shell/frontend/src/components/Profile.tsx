@@ -0,0 +1,306 @@
+import React, { useState, useEffect } from 'react';
+import './Profile.css';
+import { AppDefinition, User } from '../types';
+import { authHeaders, hasSession } from '../session';
+
+const API_BASE = `http://${window.location.hostname}:3001/api`;
+const PAGE_SIZE = 20;
+
+interface ProfileProps {
+  user: User;
+  apps: AppDefinition[];
+}
+
+interface HistoryPlayer {
+  id: string;
+  name: string;
+  outcome?: 'win' | 'loss' | 'draw';
+}
+
+interface HistoryRecord {
+  id: number;
+  app: string;
+  gameId: string;
+  players: HistoryPlayer[];
+  result?: string;
+  duration?: number;
+  outcome?: 'win' | 'loss' | 'draw';
+  completedAt: string;
+}
+
+// A self-reported result (e.g. a claimed win) waiting for the user to confirm or dispute
+interface PendingResult {
+  gameId: string;
+  gameType: string;
+  winnerId: string;
+  winnerName: string;
+  loserName: string;
+  isDraw: boolean;
+  score?: string;
+  playedAt: string;
+  confirmBy?: string;
+}
+
+interface PointsTally {
+  month: string;
+  earned: number;
+  redeemed: number;
+  byActivity: Record<string, number>;
+}
+
+interface PointsSummary {
+  month: PointsTally;
+  months: PointsTally[];
+  balance: number;
+  rules: { activity: string; points: number; description: string }[];
+}
+
+const formatMonth = (month: string) =>
+  new Date(`${month}-01T00:00:00`).toLocaleDateString(undefined, { month: 'long', year: 'numeric' });
+
+const outcomeLabels: Record<string, string> = {
+  win: 'Won',
+  loss: 'Lost',
+  draw: 'Draw',
+};
+
+const formatDuration = (seconds?: number) => {
+  if (!seconds) return '';
+  const mins = Math.floor(seconds / 60);
+  const secs = seconds % 60;
+  return mins > 0 ? `${mins}m ${secs}s` : `${secs}s`;
+};
+
+const Profile: React.FC<ProfileProps> = ({ user, apps }) => {
+  const [games, setGames] = useState<HistoryRecord[]>([]);
+  const [appFilter, setAppFilter] = useState('');
+  const [loading, setLoading] = useState(true);
+  const [hasMore, setHasMore] = useState(false);
+  const [error, setError] = useState('');
+  const [pending, setPending] = useState<PendingResult[]>([]);
+  const [points, setPoints] = useState<PointsSummary | null>(null);
+
+  useEffect(() => {
+    fetchPending();
+    fetchPoints();
+  }, []);
+
+  useEffect(() => {
+    setGames([]);
+    fetchGames();
+    // eslint-disable-next-line react-hooks/exhaustive-deps
+  }, [appFilter]);
+
+  const fetchGames = async (before?: string) => {
+    if (!hasSession()) return;
+
+    setLoading(true);
+    setError('');
+    try {
+      const params = new URLSearchParams({ limit: String(PAGE_SIZE) });
+      if (appFilter) params.set('app', appFilter);
+      if (before) params.set('before', before);
+
+      const response = await fetch(`${API_BASE}/user/games?${params}`, {
+        headers: authHeaders()
+      });
+      if (!response.ok) {
+        throw new Error(await response.text());
+      }
+      const data: HistoryRecord[] = await response.json();
+
+      setGames(prev => (before ? [...prev, ...data] : data));
+      setHasMore(data.length === PAGE_SIZE);
+    } catch (err) {
+      console.error('Failed to fetch game history:', err);
+      setError('Could not load your games');
+    }
+    setLoading(false);
+  };
+
+  const fetchPending = async () => {
+    if (!hasSession()) return;
+    try {
+      const response = await fetch(`${API_BASE}/user/results/pending`, {
+        headers: authHeaders()
+      });
+      if (response.ok) {
+        setPending(await response.json());
+      }
+    } catch (err) {
+      console.error('Failed to fetch pending results:', err);
+    }
+  };
+
+  const fetchPoints = async () => {
+    if (!hasSession()) return;
+    try {
+      const response = await fetch(`${API_BASE}/user/points`, {
+        headers: authHeaders()
+      });
+      if (response.ok) {
+        setPoints(await response.json());
+      }
+    } catch (err) {
+      console.error('Failed to fetch points:', err);
+    }
+  };
+
+  const activityLabel = (activity: string) =>
+    points?.rules.find(r => r.activity === activity)?.description || activity;
+
+  const answerResult = async (result: PendingResult, action: 'confirm' | 'dispute') => {
+    let body: string | undefined;
+    if (action === 'dispute') {
+      const reason = window.prompt('What was wrong with this result?');
+      if (!reason || !reason.trim()) return;
+      body = JSON.stringify({ reason: reason.trim() });
+    }
+
+    try {
+      const response = await fetch(`${API_BASE}/user/results/${encodeURIComponent(result.gameId)}/${action}`, {
+        method: 'POST',
+        headers: { ...authHeaders(), 'Content-Type': 'application/json' },
+        body
+      });
+      if (!response.ok) {
+        throw new Error(await response.text());
+      }
+      setPending(prev => prev.filter(p => p.gameId !== result.gameId));
+    } catch (err) {
+      console.error(`Failed to ${action} result:`, err);
+      window.alert(`Could not ${action} the result`);
+    }
+  };
+
+  const appName = (appId: string) => apps.find(a => a.id === appId)?.name || appId;
+  const appIcon = (appId: string) => apps.find(a => a.id === appId)?.icon || '🎮';
+  const opponents = (game: HistoryRecord) =>
+    game.players.filter(p => p.id !== user.email).map(p => p.name || p.id).join(', ');
+
+  const gameApps = apps.filter(a => a.category === 'game');
+
+  return (
+    <div className="profile-view">
+      <div className="profile-header">
+        <h2>👤 {user.name}</h2>
+        <span className="profile-email">{user.email}</span>
+      </div>
+
+      {points && (
+        <div className="profile-section profile-points">
+          <div className="profile-section-header">
+            <h3>Your points this month</h3>
+            <span className="profile-points-balance">{points.balance} to spend</span>
+          </div>
+          <div className="profile-points-total">{points.month.earned}</div>
+          <ul className="profile-points-breakdown">
+            {Object.entries(points.month.byActivity).map(([activity, earned]) => (
+              <li key={activity}>{activityLabel(activity)}: <strong>{earned}</strong></li>
+            ))}
+          </ul>
+          {points.month.earned === 0 && points.rules.length > 0 && (
+            <p className="profile-pending-hint">
+              Earn points by taking part: {points.rules.map(r => `${r.description || r.activity} (${r.points})`).join(', ')}.
+            </p>
+          )}
+          <div className="profile-points-months">
+            {points.months.slice(1).map(m => (
+              <span key={m.month}>{formatMonth(m.month)}: {m.earned}</span>
+            ))}
+          </div>
+        </div>
+      )}
+
+      {pending.length > 0 && (
+        <div className="profile-section profile-pending">
+          <div className="profile-section-header">
+            <h3>Results to confirm</h3>
+          </div>
+          <p className="profile-pending-hint">
+            Your opponent reported these results. Unanswered results are confirmed automatically.
+          </p>
+          <ul className="profile-games">
+            {pending.map(result => (
+              <li key={result.gameId} className="profile-game">
+                <span className="profile-game-icon">{appIcon(result.gameType)}</span>
+                <div className="profile-game-details">
+                  <div className="profile-game-title">
+                    {appName(result.gameType)}
+                    <span className="profile-game-vs">
+                      {result.isDraw
+                        ? ` · ${result.winnerName} drew with ${result.loserName}`
+                        : ` · ${result.winnerName} beat ${result.loserName}`}
+                    </span>
+                  </div>
+                  <div className="profile-game-meta">
+                    {new Date(result.playedAt).toLocaleString()}
+                    {result.score ? ` · ${result.score}` : ''}
+                    {result.confirmBy ? ` · confirms itself ${new Date(result.confirmBy).toLocaleString()}` : ''}
+                  </div>
+                </div>
+                <div className="profile-pending-actions">
+                  <button onClick={() => answerResult(result, 'confirm')}>Confirm</button>
+                  <button className="profile-dispute" onClick={() => answerResult(result, 'dispute')}>Dispute</button>
+                </div>
+              </li>
+            ))}
+          </ul>
+        </div>
+      )}
+
+      <div className="profile-section">
+        <div className="profile-section-header">
+          <h3>My games</h3>
+          <select value={appFilter} onChange={e => setAppFilter(e.target.value)}>
+            <option value="">All games</option>
+            {gameApps.map(app => (
+              <option key={app.id} value={app.id}>{app.name}</option>
+            ))}
+          </select>
+        </div>
+
+        {error && <p className="profile-error">{error}</p>}
+
+        {!loading && !error && games.length === 0 && (
+          <p className="profile-empty">No completed games yet.</p>
+        )}
+
+        <ul className="profile-games">
+          {games.map(game => (
+            <li key={game.id} className={`profile-game outcome-${game.outcome || 'none'}`}>
+              <span className="profile-game-icon">{appIcon(game.app)}</span>
+              <div className="profile-game-details">
+                <div className="profile-game-title">
+                  {appName(game.app)}
+                  {opponents(game) && <span className="profile-game-vs"> vs {opponents(game)}</span>}
+                </div>
+                <div className="profile-game-meta">
+                  {new Date(game.completedAt).toLocaleString()}
+                  {game.duration ? ` · ${formatDuration(game.duration)}` : ''}
+                </div>
+              </div>
+              <div className="profile-game-result">
+                {game.outcome && <span className="profile-game-outcome">{outcomeLabels[game.outcome]}</span>}
+                {game.result && <span className="profile-game-score">{game.result}</span>}
+              </div>
+            </li>
+          ))}
+        </ul>
+
+        {loading && <p className="profile-empty">Loading...</p>}
+
+        {!loading && hasMore && (
+          <button
+            className="profile-load-more"
+            onClick={() => fetchGames(games[games.length - 1].completedAt)}
+          >
+            Load more
+          </button>
+        )}
+      </div>
+    </div>
+  );
+};
+
+export default Profile;
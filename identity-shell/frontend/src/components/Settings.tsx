@@ -1,6 +1,7 @@
 import React, { useState, useEffect } from 'react';
 import './Settings.css';
 import { AppDefinition } from '../types';
+import { authHeaders, hasSession } from '../session';
 
 const API_BASE = `http://${window.location.hostname}:3001/api`;
 
@@ -21,6 +22,8 @@ const Settings: React.FC<SettingsProps> = ({ apps, onClose, onSave }) => {
   const [appPreferences, setAppPreferences] = useState<AppPreference[]>([]);
   const [loading, setLoading] = useState(true);
   const [saving, setSaving] = useState(false);
+  const [digestFrequency, setDigestFrequency] = useState('off');
+  const [locale, setLocale] = useState(''); // '' follows the browser
 
   useEffect(() => {
     fetchPreferences();
@@ -28,14 +31,19 @@ const Settings: React.FC<SettingsProps> = ({ apps, onClose, onSave }) => {
   }, []);
 
   const fetchPreferences = async () => {
-    const token = localStorage.getItem('token');
-    if (!token) return;
+    if (!hasSession()) return;
 
     try {
       const response = await fetch(`${API_BASE}/user/preferences`, {
-        headers: { 'Authorization': `Bearer ${token}` }
+        headers: authHeaders()
       });
       const data = await response.json();
+      if (data.settings?.digest_frequency) {
+        setDigestFrequency(data.settings.digest_frequency);
+      }
+      if (data.settings?.locale) {
+        setLocale(data.settings.locale);
+      }
 
       // Build preference map from existing preferences
       const prefsMap = new Map<string, AppPreference>();
@@ -109,20 +117,29 @@ const Settings: React.FC<SettingsProps> = ({ apps, onClose, onSave }) => {
 
   const handleSave = async () => {
     setSaving(true);
-    const token = localStorage.getItem('token');
-    if (!token) return;
+    if (!hasSession()) return;
 
     try {
       const response = await fetch(`${API_BASE}/user/preferences`, {
         method: 'PUT',
         headers: {
           'Content-Type': 'application/json',
-          'Authorization': `Bearer ${token}`
+          ...authHeaders()
         },
         body: JSON.stringify({ preferences: appPreferences })
       });
 
-      if (response.ok) {
+      // Merged into the other settings (PUT would replace them all)
+      const settingsResponse = await fetch(`${API_BASE}/user/preferences/settings`, {
+        method: 'PATCH',
+        headers: {
+          'Content-Type': 'application/json',
+          ...authHeaders()
+        },
+        body: JSON.stringify({ settings: { digest_frequency: digestFrequency, locale } })
+      });
+
+      if (response.ok && settingsResponse.ok) {
         onSave(); // Refresh apps
         onClose();
       } else {
@@ -215,6 +232,39 @@ const Settings: React.FC<SettingsProps> = ({ apps, onClose, onSave }) => {
                   </div>
                 ))}
               </div>
+
+              <div className="digest-setting">
+                <label htmlFor="digest-frequency">Email digest</label>
+                <select
+                  id="digest-frequency"
+                  value={digestFrequency}
+                  onChange={(e) => setDigestFrequency(e.target.value)}
+                >
+                  <option value="off">Off</option>
+                  <option value="daily">Daily</option>
+                  <option value="weekly">Weekly (Mondays)</option>
+                </select>
+                <p className="settings-hint">
+                  Challenges you missed, LMS picks due and quiz nights coming up.
+                </p>
+              </div>
+
+              <div className="digest-setting">
+                <label htmlFor="locale">Language</label>
+                <select
+                  id="locale"
+                  value={locale}
+                  onChange={(e) => setLocale(e.target.value)}
+                >
+                  <option value="">Same as browser</option>
+                  <option value="en">English</option>
+                  <option value="fr">Français</option>
+                  <option value="es">Español</option>
+                </select>
+                <p className="settings-hint">
+                  For messages from the server, such as lobby and quiz errors.
+                </p>
+              </div>
             </div>
 
             <div className="settings-footer">
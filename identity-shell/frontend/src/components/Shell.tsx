@@ -4,19 +4,28 @@ import './Shell.css';
 import { User } from '../types';
 import { useLobby } from '../hooks/useLobby';
 import { useApps, buildAppUrl } from '../hooks/useApps';
+import { useIdleSession } from '../hooks/useIdleSession';
+import { logEvent } from '../analytics';
 import Lobby from './Lobby';
 import AppContainer from './AppContainer';
 import ChallengeToast from './ChallengeToast';
+import DeclinedChallengeToast from './DeclinedChallengeToast';
+import IdleWarningToast from './IdleWarningToast';
 import Settings from './Settings';
+import Profile from './Profile';
 import ChallengesOverlay from './ChallengesOverlay';
 
 interface ShellProps {
   user: User;
   onLogout: () => void;
   onEndImpersonation: () => void;
+  onTableLogin: (name: string) => Promise<boolean>;
 }
 
-const Shell: React.FC<ShellProps> = ({ user, onLogout, onEndImpersonation }) => {
+// Staff who can set a shared tablet up as a table
+const TABLE_SETUP_ROLES = ['game_admin', 'quiz_master', 'super_user'];
+
+const Shell: React.FC<ShellProps> = ({ user, onLogout, onEndImpersonation, onTableLogin }) => {
   const navigate = useNavigate();
   const [toastChallenge, setToastChallenge] = useState<any | null>(null);
   const [showSettings, setShowSettings] = useState(false);
@@ -28,14 +37,19 @@ const Shell: React.FC<ShellProps> = ({ user, onLogout, onEndImpersonation }) =>
   // Fetch apps from registry
   const { apps, loading: appsLoading, refreshApps } = useApps();
 
+  // Inactivity timeout: pings on activity, warning and logout over the lobby stream
+  const idle = useIdleSession(onLogout);
+
   const handleNewChallenge = (challenge: any) => {
     setToastChallenge(challenge);
   };
 
   // Redirect to game (not popup - iOS Safari blocks popups from SSE handlers)
-  const handleGameStart = (appId: string, gameId: string) => {
+  const handleGameStart = async (appId: string, gameId: string) => {
     const app = apps.find(a => a.id === appId);
     if (app) {
+      await enterApp(appId, 'in_game');
+      logEvent('app_opened', appId);
       const gameUrl = buildAppUrl(app, {
         userId: user.email,
         userName: user.name,
@@ -54,19 +68,31 @@ const Shell: React.FC<ShellProps> = ({ user, onLogout, onEndImpersonation }) =>
     sentChallenges,
     sendChallenge,
     sendMultiChallenge,
+    sendPresetChallenge,
+    scheduleChallenge,
+    cancelScheduledChallenge,
+    scheduledChallenges,
+    bookingNotice,
     acceptChallenge,
     rejectChallenge,
     fetchGameConfig,
+    enterApp,
+    declinedChallenge,
+    dismissDeclined,
   } = useLobby(user.email, {
     onNewChallenge: handleNewChallenge,
     onGameStart: handleGameStart,
+    onSessionExpiring: idle.warn,
+    onSessionExpired: onLogout,
   });
   const notificationCount = receivedChallenges.filter(c => c.status === 'pending').length;
 
   // Redirect to app (leaves the shell entirely)
-  const handleAppClick = (appId: string) => {
+  const handleAppClick = async (appId: string) => {
     const app = apps.find(a => a.id === appId);
     if (app) {
+      await enterApp(appId, app.category === 'game' ? 'in_game' : 'online');
+      logEvent('app_opened', appId);
       const appUrl = buildAppUrl(app, {
         userId: user.email,
         userName: user.name,
@@ -77,6 +103,16 @@ const Shell: React.FC<ShellProps> = ({ user, onLogout, onEndImpersonation }) =>
     }
   };
 
+  const canSetUpTable = !user.impersonating && (user.roles || []).some(r => TABLE_SETUP_ROLES.includes(r));
+
+  const handleTableSetup = async () => {
+    const name = window.prompt('Table name for this device (e.g. "Table 4")');
+    if (!name || !name.trim()) return;
+    if (!(await onTableLogin(name.trim()))) {
+      window.alert('Could not set this device up as a table');
+    }
+  };
+
   const handleDismissToast = () => {
     setToastChallenge(null);
   };
@@ -109,6 +145,11 @@ const Shell: React.FC<ShellProps> = ({ user, onLogout, onEndImpersonation }) =>
               </button>
             </>
           )}
+          {canSetUpTable && (
+            <button className="settings-icon-button" onClick={handleTableSetup} title="Use this device as a shared table tablet">
+              Table mode
+            </button>
+          )}
           <div className="user-menu">
             <button
               className="user-email-btn"
@@ -151,6 +192,31 @@ const Shell: React.FC<ShellProps> = ({ user, onLogout, onEndImpersonation }) =>
         />
       )}
 
+      {/* Inactivity logout coming up */}
+      {idle.expiresAt && (
+        <IdleWarningToast expiresAt={idle.expiresAt} onStayActive={idle.stayActive} />
+      )}
+
+      {/* Declined challenge - suggest other opponents */}
+      {/* Booked game made, coming up, lapsed or cancelled */}
+      {bookingNotice && (
+        <div className="challenge-toast">
+          <div className="challenge-toast-icon">📅</div>
+          <div className="challenge-toast-content">
+            <p className="challenge-toast-message">{bookingNotice}</p>
+          </div>
+        </div>
+      )}
+
+      {declinedChallenge && (
+        <DeclinedChallengeToast
+          declined={declinedChallenge}
+          apps={apps}
+          onChallenge={sendChallenge}
+          onDismiss={dismissDeclined}
+        />
+      )}
+
       {/* Main Content Area */}
       <main className="shell-content">
         {appsLoading ? (
@@ -189,6 +255,10 @@ const Shell: React.FC<ShellProps> = ({ user, onLogout, onEndImpersonation }) =>
                     onlineUsers={onlineUsers}
                     onSendChallenge={sendChallenge}
                     onSendMultiChallenge={sendMultiChallenge}
+                    onSendPresetChallenge={sendPresetChallenge}
+                    scheduledChallenges={scheduledChallenges}
+                    onScheduleChallenge={scheduleChallenge}
+                    onCancelScheduledChallenge={cancelScheduledChallenge}
                     fetchGameConfig={fetchGameConfig}
                   />
                 }
@@ -200,12 +270,7 @@ const Shell: React.FC<ShellProps> = ({ user, onLogout, onEndImpersonation }) =>
             />
             <Route
               path="/profile"
-              element={
-                <div className="placeholder-view">
-                  <h2>👤 Profile</h2>
-                  <p>Profile management coming soon...</p>
-                </div>
-              }
+              element={<Profile user={user} apps={apps} />}
             />
             <Route path="*" element={<Navigate to="/lobby" replace />} />
           </Routes>
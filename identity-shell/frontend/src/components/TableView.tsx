@@ -0,0 +1,227 @@
+import React, { useEffect, useState } from 'react';
+import './Shell.css';
+import './TableView.css';
+import { AppDefinition, TablePlayer, User } from '../types';
+import { useApps, buildAppUrl } from '../hooks/useApps';
+import { useIdleSession } from '../hooks/useIdleSession';
+import { authHeaders } from '../session';
+import { logEvent } from '../analytics';
+
+const API_BASE = `http://${window.location.hostname}:3001/api`;
+
+interface TableViewProps {
+  user: User;
+  onLogout: () => void;
+}
+
+// A shared tablet signed in as a pub table. Everyone at the table adds
+// themselves as a player; each app is opened as one of them, so their quiz
+// answers and game scores are their own.
+const TableView: React.FC<TableViewProps> = ({ user, onLogout }) => {
+  const { apps, loading: appsLoading } = useApps();
+  // No lobby stream here: an idle table is caught by the hook's session check
+  useIdleSession(onLogout);
+  const [players, setPlayers] = useState<TablePlayer[]>([]);
+  const [newName, setNewName] = useState('');
+  const [newFlair, setNewFlair] = useState('');
+  const [error, setError] = useState('');
+  const [pickingFor, setPickingFor] = useState<AppDefinition | null>(null);
+
+  const fetchPlayers = async () => {
+    try {
+      const response = await fetch(`${API_BASE}/table/players`, { headers: authHeaders() });
+      if (response.ok) {
+        const data = await response.json();
+        setPlayers(data.players || []);
+      }
+    } catch (err) {
+      console.error('Failed to fetch table players:', err);
+    }
+  };
+
+  useEffect(() => {
+    fetchPlayers();
+    // eslint-disable-next-line react-hooks/exhaustive-deps
+  }, []);
+
+  const handleAddPlayer = async (e: React.FormEvent) => {
+    e.preventDefault();
+    setError('');
+    try {
+      const response = await fetch(`${API_BASE}/table/players`, {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json', ...authHeaders() },
+        body: JSON.stringify({ name: newName, flair: newFlair }),
+      });
+      if (!response.ok) {
+        setError(await response.text());
+        return;
+      }
+      setNewName('');
+      setNewFlair('');
+      fetchPlayers();
+    } catch (err) {
+      console.error('Failed to add table player:', err);
+      setError('Failed to add player');
+    }
+  };
+
+  const handleRemovePlayer = async (player: TablePlayer) => {
+    if (!window.confirm(`Remove ${player.name} from this table?`)) return;
+    try {
+      await fetch(`${API_BASE}/table/players/${player.id}`, {
+        method: 'DELETE',
+        headers: authHeaders(),
+      });
+      fetchPlayers();
+    } catch (err) {
+      console.error('Failed to remove table player:', err);
+    }
+  };
+
+  // Open the app as the chosen player (leaves the shell entirely)
+  const openAppAs = async (app: AppDefinition, player: TablePlayer) => {
+    try {
+      const response = await fetch(`${API_BASE}/table/players/${player.id}/launch-token`, {
+        method: 'POST',
+        headers: authHeaders(),
+      });
+      if (!response.ok) {
+        setError(await response.text());
+        return;
+      }
+      const data = await response.json();
+      logEvent('app_opened', app.id);
+      window.location.href = buildAppUrl(app, {
+        userId: player.email,
+        userName: player.name,
+        isAdmin: false,
+        launch: data.token,
+      });
+    } catch (err) {
+      console.error('Failed to open app for table player:', err);
+    }
+  };
+
+  const handleAppClick = (app: AppDefinition) => {
+    setError('');
+    if (players.length === 0) {
+      setError('Add a player first');
+    } else if (players.length === 1) {
+      openAppAs(app, players[0]);
+    } else {
+      setPickingFor(app);
+    }
+  };
+
+  const handleSignOut = async () => {
+    if (!window.confirm('Sign this table out? Players will need to be added again.')) return;
+    try {
+      await fetch(`${API_BASE}/table/end`, { method: 'POST', headers: authHeaders() });
+    } catch (err) {
+      console.error('Failed to sign table out:', err);
+    }
+    onLogout();
+  };
+
+  return (
+    <div className="shell">
+      <header className="shell-header">
+        <div className="shell-header-left">
+          <span className="logo-text">Activity Hub</span>
+        </div>
+        <div className="shell-header-right">
+          <div className="user-menu">
+            <span className="table-name">🍺 {user.name}</span>
+            <button className="logout-button" onClick={handleSignOut}>
+              Sign out table
+            </button>
+          </div>
+        </div>
+      </header>
+
+      <main className="shell-content table-view">
+        <section className="table-players">
+          <h2>Who's at the table?</h2>
+          <div className="table-player-list">
+            {players.map(player => (
+              <div key={player.id} className="table-player-chip">
+                {player.flair && <span className="table-player-flair">{player.flair}</span>}
+                <span>{player.name}</span>
+                <button
+                  className="table-player-remove"
+                  onClick={() => handleRemovePlayer(player)}
+                  title={`Remove ${player.name}`}
+                >
+                  ×
+                </button>
+              </div>
+            ))}
+            {players.length === 0 && (
+              <p className="guest-notice-text">Add everyone who's playing - each player's answers and scores are kept separately.</p>
+            )}
+          </div>
+          <form className="table-add-player" onSubmit={handleAddPlayer}>
+            <input
+              type="text"
+              value={newName}
+              onChange={(e) => setNewName(e.target.value)}
+              placeholder="Name"
+              maxLength={30}
+              required
+            />
+            <input
+              type="text"
+              value={newFlair}
+              onChange={(e) => setNewFlair(e.target.value)}
+              placeholder="😀"
+              className="table-flair-input"
+            />
+            <button type="submit" className="table-add-button">Add player</button>
+          </form>
+          {error && <div className="table-error">{error}</div>}
+        </section>
+
+        <section className="table-apps">
+          <h2>Play</h2>
+          {appsLoading ? (
+            <div className="loading-apps">Loading apps...</div>
+          ) : (
+            <div className="apps-grid">
+              {apps.filter(app => app.type === 'iframe').map(app => (
+                <div key={app.id} className="app-card" onClick={() => handleAppClick(app)}>
+                  <span className="app-icon">{app.icon}</span>
+                  <span className="app-name">{app.name}</span>
+                  <p className="app-description">{app.description}</p>
+                </div>
+              ))}
+            </div>
+          )}
+        </section>
+      </main>
+
+      {pickingFor && (
+        <div className="table-picker-backdrop" onClick={() => setPickingFor(null)}>
+          <div className="table-picker" onClick={(e) => e.stopPropagation()}>
+            <h3>Who's playing {pickingFor.name}?</h3>
+            {players.map(player => (
+              <button
+                key={player.id}
+                className="table-picker-player"
+                onClick={() => openAppAs(pickingFor, player)}
+              >
+                {player.flair && <span className="table-player-flair">{player.flair}</span>}
+                {player.name}
+              </button>
+            ))}
+            <button className="table-picker-cancel" onClick={() => setPickingFor(null)}>
+              Cancel
+            </button>
+          </div>
+        </div>
+      )}
+    </div>
+  );
+};
+
+export default TableView;
@@ -0,0 +1,53 @@
+import { useState, useEffect, useCallback } from 'react';
+import { ActivityEvent } from '../types';
+
+const API_BASE = `http://${window.location.hostname}:3001/api`;
+
+const PAGE_SIZE = 20;
+
+// Platform-wide activity feed: the latest page on load, new events live over SSE,
+// older pages on demand
+export function useActivityFeed() {
+  const [events, setEvents] = useState<ActivityEvent[]>([]);
+  const [hasMore, setHasMore] = useState(false);
+  const [loading, setLoading] = useState(false);
+
+  const fetchPage = useCallback(async (before?: string) => {
+    setLoading(true);
+    try {
+      const params = new URLSearchParams({ limit: String(PAGE_SIZE) });
+      if (before) params.set('before', before);
+      const response = await fetch(`${API_BASE}/activity?${params}`);
+      if (!response.ok) return;
+      const data = await response.json();
+      const page: ActivityEvent[] = data.events || [];
+      setEvents((prev) => (before ? [...prev, ...page] : page));
+      setHasMore(!!data.hasMore);
+    } catch (err) {
+      console.error('Failed to fetch activity feed:', err);
+    } finally {
+      setLoading(false);
+    }
+  }, []);
+
+  useEffect(() => {
+    fetchPage();
+
+    const eventSource = new EventSource(`${API_BASE}/activity/stream`);
+    eventSource.onmessage = (event) => {
+      const data = JSON.parse(event.data);
+      if (!data.id) return; // connected
+      setEvents((prev) => (prev.some((e) => e.id === data.id) ? prev : [data as ActivityEvent, ...prev]));
+    };
+
+    return () => eventSource.close();
+  }, [fetchPage]);
+
+  const loadMore = useCallback(() => {
+    if (events.length > 0) {
+      fetchPage(events[events.length - 1].createdAt);
+    }
+  }, [events, fetchPage]);
+
+  return { events, hasMore, loading, loadMore };
+}
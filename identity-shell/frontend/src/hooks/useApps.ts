@@ -1,5 +1,6 @@
 import { useState, useEffect } from 'react';
 import { AppDefinition, AppsRegistry } from '../types';
+import { authHeaders, hasSession } from '../session';
 
 const API_BASE = `http://${window.location.hostname}:3001`;
 
@@ -10,14 +11,8 @@ export function useApps() {
 
   const fetchApps = async () => {
     try {
-      // Get token from localStorage to fetch role-based apps
-      const token = localStorage.getItem('token');
-      const headers: HeadersInit = {};
-      if (token) {
-        headers['Authorization'] = `Bearer ${token}`;
-      }
-
-      const response = await fetch(`${API_BASE}/api/apps`, { headers });
+      // Send the session (if any) to fetch role-based apps
+      const response = await fetch(`${API_BASE}/api/apps`, { headers: authHeaders() });
       if (!response.ok) {
         throw new Error('Failed to fetch apps');
       }
@@ -55,10 +50,29 @@ export function useApps() {
   return { apps, loading, error, refreshApps };
 }
 
+// Mint a single-use launch token for handing off to a mini-app. The app swaps it
+// for the session token (POST /api/auth/launch), so the session token never
+// appears in the iframe URL, browser history or server logs.
+export async function fetchLaunchToken(): Promise<string | null> {
+  if (!hasSession()) return null;
+  try {
+    const response = await fetch(`${API_BASE}/api/auth/launch-token`, {
+      method: 'POST',
+      headers: authHeaders(),
+    });
+    if (!response.ok) return null;
+    const data = await response.json();
+    return data.token;
+  } catch (err) {
+    console.error('Failed to mint launch token:', err);
+    return null;
+  }
+}
+
 // Helper to build the app URL with query params
 export function buildAppUrl(
   app: AppDefinition,
-  params: { userId?: string; userName?: string; isAdmin?: boolean; gameId?: string }
+  params: { userId?: string; userName?: string; isAdmin?: boolean; gameId?: string; launch?: string }
 ): string {
   console.log('🔍 buildAppUrl called:', {
     appId: app.id,
@@ -71,17 +85,13 @@ export function buildAppUrl(
   // Replace {host} placeholder with current hostname
   let url = app.url.replace('{host}', window.location.hostname);
 
-  // Get token from localStorage (stored as 'token' by App.tsx handleLogin)
-  const token = localStorage.getItem('token');
-  console.log('🔍 Token from localStorage:', token ? `${token.substring(0, 20)}...` : 'MISSING');
-
   // Add query params
   const searchParams = new URLSearchParams();
   if (params.userId) searchParams.set('userId', params.userId);
   if (params.userName) searchParams.set('userName', params.userName);
   if (params.isAdmin !== undefined) searchParams.set('isAdmin', params.isAdmin.toString());
   if (params.gameId) searchParams.set('gameId', params.gameId);
-  if (token) searchParams.set('token', token);
+  if (params.launch) searchParams.set('launch', params.launch);
 
   const queryString = searchParams.toString();
   if (queryString) {
@@ -0,0 +1,87 @@
+import { useState, useEffect, useRef, useCallback } from 'react';
+import { authHeaders } from '../session';
+
+const API_BASE = `http://${window.location.hostname}:3001/api`;
+
+// Ping at most this often while the user is tapping and typing
+const PING_INTERVAL_MS = 30000;
+
+// How often to check the session is still valid (catches expiry on devices
+// without a lobby stream, e.g. table tablets)
+const CHECK_INTERVAL_MS = 60000;
+
+// Inactivity timeout (SESSION_IDLE_TIMEOUT_SECONDS on the backend). Reports
+// activity as the user interacts, holds the warning sent over the lobby
+// stream, and calls onExpired once the backend logs the session out. Does
+// nothing when the timeout is off.
+export function useIdleSession(onExpired: () => void) {
+  const [enabled, setEnabled] = useState(false);
+  const [expiresAt, setExpiresAt] = useState<number | null>(null);
+  const lastPingRef = useRef(0);
+  const onExpiredRef = useRef(onExpired);
+
+  useEffect(() => {
+    onExpiredRef.current = onExpired;
+  }, [onExpired]);
+
+  const ping = useCallback(async () => {
+    lastPingRef.current = Date.now();
+    try {
+      const response = await fetch(`${API_BASE}/session/activity`, {
+        method: 'POST',
+        headers: authHeaders(),
+      });
+      if (response.status === 401) {
+        onExpiredRef.current();
+        return;
+      }
+      if (!response.ok) return;
+      const data = await response.json();
+      setEnabled(data.idleTimeoutSeconds > 0);
+      setExpiresAt(null);
+    } catch (err) {
+      console.error('Failed to report activity:', err);
+    }
+  }, []);
+
+  // Policy on mount, then activity as it happens
+  useEffect(() => {
+    ping();
+  }, [ping]);
+
+  useEffect(() => {
+    if (!enabled) return;
+
+    const onActivity = () => {
+      if (Date.now() - lastPingRef.current >= PING_INTERVAL_MS) ping();
+    };
+    window.addEventListener('pointerdown', onActivity);
+    window.addEventListener('keydown', onActivity);
+
+    // Validate doesn't count as activity, so checking doesn't keep it alive
+    const check = setInterval(async () => {
+      try {
+        const response = await fetch(`${API_BASE}/validate`, {
+          method: 'POST',
+          headers: { 'Content-Type': 'application/json', ...authHeaders() },
+          body: JSON.stringify({ token: localStorage.getItem('token') || '' }),
+        });
+        const data = await response.json();
+        if (data.expired) onExpiredRef.current();
+      } catch (err) {
+        console.error('Failed to check session:', err);
+      }
+    }, CHECK_INTERVAL_MS);
+
+    return () => {
+      window.removeEventListener('pointerdown', onActivity);
+      window.removeEventListener('keydown', onActivity);
+      clearInterval(check);
+    };
+  }, [enabled, ping]);
+
+  // Warning from the lobby stream (expiresAt in unix seconds)
+  const warn = useCallback((at: number) => setExpiresAt(at), []);
+
+  return { expiresAt, warn, stayActive: ping };
+}
@@ -1,25 +1,51 @@
 import { useState, useEffect, useRef, useCallback } from 'react';
-import { LobbyState, Challenge, ChallengeOptions, GameConfig } from '../types';
+import { LobbyState, CalendarDay, Challenge, ChallengeOptions, ChallengePreset, GameConfig, DeclineReason, DeclinedChallenge, OpponentSuggestion, UserPresence } from '../types';
+import { authHeaders } from '../session';
 
 const API_BASE = `http://${window.location.hostname}:3001/api`;
 
+// One ID per browser tab session: presence is tracked per device, and challenges
+// and game starts are routed to the device the user is actually using
+const getDeviceId = () => {
+  let id = sessionStorage.getItem('lobbyDeviceId');
+  if (!id) {
+    id = `${Date.now().toString(36)}-${Math.random().toString(36).slice(2, 10)}`;
+    sessionStorage.setItem('lobbyDeviceId', id);
+  }
+  return id;
+};
+const DEVICE_ID = getDeviceId();
+
+// "Playing Tic-Tac-Toe vs Dave" for a user their game's backend has in a game, else ""
+export function playingLabel(user: UserPresence): string {
+  if (!user.inGame) return '';
+  const vs = user.inGame.opponents.length > 0 ? ` vs ${user.inGame.opponents.join(', ')}` : '';
+  return `Playing ${user.inGame.appName}${vs}`;
+}
+
 interface UseLobbyOptions {
   onNewChallenge?: (challenge: Challenge) => void;
   onGameStart?: (appId: string, gameId: string) => void;
+  onSessionExpiring?: (expiresAt: number) => void;
+  onSessionExpired?: () => void;
 }
 
 export function useLobby(userEmail: string, options?: UseLobbyOptions) {
-  const { onNewChallenge, onGameStart } = options || {};
+  const { onNewChallenge, onGameStart, onSessionExpiring, onSessionExpired } = options || {};
 
   // Use refs to avoid stale closures in SSE handler
   const onNewChallengeRef = useRef(onNewChallenge);
   const onGameStartRef = useRef(onGameStart);
+  const onSessionExpiringRef = useRef(onSessionExpiring);
+  const onSessionExpiredRef = useRef(onSessionExpired);
 
   // Keep refs updated
   useEffect(() => {
     onNewChallengeRef.current = onNewChallenge;
     onGameStartRef.current = onGameStart;
-  }, [onNewChallenge, onGameStart]);
+    onSessionExpiringRef.current = onSessionExpiring;
+    onSessionExpiredRef.current = onSessionExpired;
+  }, [onNewChallenge, onGameStart, onSessionExpiring, onSessionExpired]);
   const [lobbyState, setLobbyState] = useState<LobbyState>({
     onlineUsers: [],
     receivedChallenges: [],
@@ -28,8 +54,12 @@ export function useLobby(userEmail: string, options?: UseLobbyOptions) {
   });
 
   const eventSourceRef = useRef<EventSource | null>(null);
+  const leavingForAppRef = useRef(false);
   const notifiedChallenges = useRef<Set<string>>(new Set());
   const [notification, setNotification] = useState<string | null>(null);
+  const [declinedChallenge, setDeclinedChallenge] = useState<DeclinedChallenge | null>(null);
+  const [scheduledChallenges, setScheduledChallenges] = useState<CalendarDay[]>([]);
+  const [bookingNotice, setBookingNotice] = useState<string | null>(null);
 
   // Update user's presence
   const updatePresence = useCallback(async (status: 'online' | 'in_game' | 'away', currentApp?: string) => {
@@ -42,6 +72,7 @@ export function useLobby(userEmail: string, options?: UseLobbyOptions) {
           name: userEmail.split('@')[0],
           status,
           currentApp,
+          deviceId: DEVICE_ID,
         }),
       });
     } catch (err) {
@@ -106,6 +137,41 @@ export function useLobby(userEmail: string, options?: UseLobbyOptions) {
     }
   }, [userEmail]);
 
+  // Fetch booked games, sent or received, by day
+  const fetchScheduledChallenges = useCallback(async () => {
+    try {
+      const response = await fetch(`${API_BASE}/lobby/challenges/scheduled?email=${encodeURIComponent(userEmail)}`);
+      const data = await response.json();
+      setScheduledChallenges(data.days || []);
+    } catch (err) {
+      console.error('Failed to fetch scheduled challenges:', err);
+    }
+  }, [userEmail]);
+
+  // A sent challenge was declined - offer other recent players of the same app
+  const handleDeclined = useCallback(async (event: any) => {
+    let suggestions: OpponentSuggestion[] = [];
+    try {
+      const params = new URLSearchParams({ email: userEmail, appId: event.appId, exclude: event.toUser || '' });
+      const response = await fetch(`${API_BASE}/lobby/challenge/suggestions?${params}`);
+      const data = await response.json();
+      suggestions = data.suggestions || [];
+    } catch (err) {
+      console.error('Failed to fetch opponent suggestions:', err);
+    }
+
+    setDeclinedChallenge({
+      challengeId: event.challengeId,
+      appId: event.appId,
+      toUser: event.toUser,
+      reasonText: event.reasonText,
+      options: event.options || undefined,
+      suggestions,
+    });
+  }, [userEmail]);
+
+  const dismissDeclined = useCallback(() => setDeclinedChallenge(null), []);
+
   // Fetch game config from mini-app
   // appId reserved for future use (e.g., caching by app)
   const fetchGameConfig = async (_appId: string, backendPort: number): Promise<GameConfig | null> => {
@@ -123,7 +189,7 @@ export function useLobby(userEmail: string, options?: UseLobbyOptions) {
   // Send a challenge with optional game options
   const sendChallenge = async (toUser: string, appId: string, options?: ChallengeOptions) => {
     try {
-      const response = await fetch(`${API_BASE}/lobby/challenge`, {
+      const response = await fetch(`${API_BASE}/lobby/challenge?deviceId=${DEVICE_ID}`, {
         method: 'POST',
         headers: { 'Content-Type': 'application/json' },
         body: JSON.stringify({
@@ -172,7 +238,7 @@ export function useLobby(userEmail: string, options?: UseLobbyOptions) {
     options?: ChallengeOptions
   ) => {
     try {
-      const response = await fetch(`${API_BASE}/lobby/challenge/multi`, {
+      const response = await fetch(`${API_BASE}/lobby/challenge/multi?deviceId=${DEVICE_ID}`, {
         method: 'POST',
         headers: { 'Content-Type': 'application/json' },
         body: JSON.stringify({
@@ -212,12 +278,93 @@ export function useLobby(userEmail: string, options?: UseLobbyOptions) {
     }
   };
 
+  // Challenge a preset's usual opponents - whichever of them are free right now
+  const sendPresetChallenge = async (preset: ChallengePreset) => {
+    try {
+      const response = await fetch(`${API_BASE}/user/challenge-presets/${preset.id}/challenge?deviceId=${DEVICE_ID}`, {
+        method: 'POST',
+        headers: authHeaders(),
+      });
+
+      if (!response.ok) {
+        const error = (await response.text()).trim();
+        fetchOnlineUsers();
+        setNotification(error || 'Failed to send challenge');
+        setTimeout(() => setNotification(null), 3000);
+        return false;
+      }
+
+      const data = await response.json();
+      fetchSentChallenges();
+
+      const challenged: string[] = data.challenged || [];
+      const names = challenged.map((email) =>
+        lobbyState.onlineUsers.find((u) => u.email === email)?.displayName || email
+      );
+      setNotification(`Challenge sent to ${names.join(', ')}!`);
+      setTimeout(() => setNotification(null), 2000);
+
+      return true;
+    } catch (err) {
+      console.error('Failed to send preset challenge:', err);
+      setNotification('Failed to send challenge');
+      setTimeout(() => setNotification(null), 3000);
+      return false;
+    }
+  };
+
+  // Book a 2-player challenge for later; the opponent needn't be online
+  const scheduleChallenge = async (toUser: string, appId: string, scheduledAt: string, options?: ChallengeOptions) => {
+    try {
+      const response = await fetch(`${API_BASE}/lobby/challenge/scheduled`, {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({
+          fromUser: userEmail,
+          toUser,
+          appId,
+          options: options || {},
+          scheduledAt,
+        }),
+      });
+
+      if (!response.ok) {
+        const error = (await response.text()).trim();
+        setNotification(error || 'Failed to book game');
+        setTimeout(() => setNotification(null), 3000);
+        return false;
+      }
+
+      fetchScheduledChallenges();
+      setNotification('Game booked!');
+      setTimeout(() => setNotification(null), 2000);
+      return true;
+    } catch (err) {
+      console.error('Failed to book challenge:', err);
+      setNotification('Failed to book game');
+      setTimeout(() => setNotification(null), 3000);
+      return false;
+    }
+  };
+
+  // Cancel a booked game that hasn't started (either player can)
+  const cancelScheduledChallenge = async (id: number) => {
+    try {
+      await fetch(`${API_BASE}/lobby/challenge/scheduled/cancel?id=${id}&email=${encodeURIComponent(userEmail)}`, {
+        method: 'POST',
+      });
+    } catch (err) {
+      console.error('Failed to cancel booked game:', err);
+    }
+    fetchScheduledChallenges();
+  };
+
   // Accept a challenge (with optional userId for multi-player)
   const acceptChallenge = async (challengeId: string, userId?: string) => {
     try {
       const url = userId
-        ? `${API_BASE}/lobby/challenge/accept?id=${challengeId}&userId=${encodeURIComponent(userId)}`
-        : `${API_BASE}/lobby/challenge/accept?id=${challengeId}`;
+        ? `${API_BASE}/lobby/challenge/accept?id=${challengeId}&userId=${encodeURIComponent(userId)}&deviceId=${DEVICE_ID}`
+        : `${API_BASE}/lobby/challenge/accept?id=${challengeId}&deviceId=${DEVICE_ID}`;
 
       await fetch(url, {
         method: 'POST',
@@ -234,9 +381,10 @@ export function useLobby(userEmail: string, options?: UseLobbyOptions) {
   };
 
   // Reject a challenge
-  const rejectChallenge = async (challengeId: string) => {
+  const rejectChallenge = async (challengeId: string, reason?: DeclineReason) => {
     try {
-      await fetch(`${API_BASE}/lobby/challenge/reject?id=${challengeId}`, {
+      const reasonParam = reason ? `&reason=${reason}` : '';
+      await fetch(`${API_BASE}/lobby/challenge/reject?id=${challengeId}${reasonParam}`, {
         method: 'POST',
       });
 
@@ -251,10 +399,13 @@ export function useLobby(userEmail: string, options?: UseLobbyOptions) {
 
   // Setup SSE connection
   useEffect(() => {
-    const eventSource = new EventSource(`${API_BASE}/lobby/stream?email=${encodeURIComponent(userEmail)}`);
+    const eventSource = new EventSource(
+      `${API_BASE}/lobby/stream?email=${encodeURIComponent(userEmail)}&deviceId=${DEVICE_ID}`
+    );
     eventSourceRef.current = eventSource;
 
     eventSource.onmessage = (event) => {
+      // Event envelope: { type, version, payload } (see GET /api/events/schema)
       const data = JSON.parse(event.data);
 
       if (data.type === 'challenge_received') {
@@ -264,10 +415,29 @@ export function useLobby(userEmail: string, options?: UseLobbyOptions) {
         // Refresh when challenge is responded to
         fetchChallenges();
         fetchSentChallenges();
+        if (data.type === 'rejected' && data.payload?.appId) {
+          handleDeclined(data.payload);
+        }
       } else if (data.type === 'challenge_update') {
         // Multi-player challenge acceptance progress
         fetchChallenges();
         fetchSentChallenges();
+      } else if (data.type.startsWith('scheduled_challenge_')) {
+        // A booked game was made, is coming up, lapsed or was cancelled
+        fetchScheduledChallenges();
+        const p = data.payload || {};
+        const other = p.fromUser === userEmail ? p.toUser : p.fromUser;
+        const at = p.scheduledAt ? new Date(p.scheduledAt).toLocaleTimeString([], { hour: '2-digit', minute: '2-digit' }) : '';
+        const messages: Record<string, string> = {
+          scheduled_challenge_received: `${other} booked a game with you at ${at}`,
+          scheduled_challenge_reminder: `Your game with ${other} starts at ${at}`,
+          scheduled_challenge_expired: `Your ${at} game with ${other} lapsed - one of you wasn't free`,
+          scheduled_challenge_cancelled: `${other} cancelled your ${at} game`,
+        };
+        if (messages[data.type]) {
+          setBookingNotice(messages[data.type]);
+          setTimeout(() => setBookingNotice(null), 8000);
+        }
       } else if (data.type === 'presence_update') {
         // Refresh online users and sent challenges (removes offline users)
         fetchOnlineUsers();
@@ -275,9 +445,19 @@ export function useLobby(userEmail: string, options?: UseLobbyOptions) {
       } else if (data.type === 'game_started') {
         // Game has been created - navigate to it
         // Use ref to avoid stale closure
-        if (onGameStartRef.current && data.appId && data.gameId) {
-          console.log('🎮 game_started received, navigating to:', data.appId, data.gameId);
-          onGameStartRef.current(data.appId, data.gameId);
+        const { appId, gameId } = data.payload || {};
+        if (onGameStartRef.current && appId && gameId) {
+          console.log('🎮 game_started received, navigating to:', appId, gameId);
+          onGameStartRef.current(appId, gameId);
+        }
+      } else if (data.type === 'session_expiring') {
+        // Inactivity logout coming up
+        if (onSessionExpiringRef.current && data.payload?.expiresAt) {
+          onSessionExpiringRef.current(data.payload.expiresAt);
+        }
+      } else if (data.type === 'session_expired') {
+        if (onSessionExpiredRef.current) {
+          onSessionExpiredRef.current();
         }
       }
     };
@@ -291,6 +471,7 @@ export function useLobby(userEmail: string, options?: UseLobbyOptions) {
     fetchOnlineUsers();
     fetchChallenges();
     fetchSentChallenges();
+    fetchScheduledChallenges();
 
     // Heartbeat every 20 seconds (also refresh sent challenges to remove offline users)
     const heartbeat = setInterval(() => {
@@ -304,14 +485,17 @@ export function useLobby(userEmail: string, options?: UseLobbyOptions) {
       eventSource.close();
       updatePresence('away');
     };
-  }, [userEmail, updatePresence, fetchOnlineUsers, fetchChallenges, fetchSentChallenges]);
+  }, [userEmail, updatePresence, fetchOnlineUsers, fetchChallenges, fetchSentChallenges, fetchScheduledChallenges, handleDeclined]);
 
   // Browser lifecycle detection
   useEffect(() => {
     const handleVisibilityChange = () => {
       if (document.hidden) {
+        if (leavingForAppRef.current) return;
         updatePresence('away');
       } else {
+        // Back in the shell (e.g. browser back from a game)
+        leavingForAppRef.current = false;
         updatePresence('online');
         fetchOnlineUsers();
         fetchChallenges();
@@ -319,9 +503,12 @@ export function useLobby(userEmail: string, options?: UseLobbyOptions) {
     };
 
     const handleBeforeUnload = () => {
-      // Send synchronous beacon to remove presence
+      // Leaving for an app keeps this device's in-game presence (see enterApp)
+      if (leavingForAppRef.current) return;
+
+      // Send synchronous beacon to remove this device's presence
       navigator.sendBeacon(
-        `${API_BASE}/lobby/presence/remove?email=${encodeURIComponent(userEmail)}`
+        `${API_BASE}/lobby/presence/remove?email=${encodeURIComponent(userEmail)}&deviceId=${DEVICE_ID}`
       );
     };
 
@@ -334,12 +521,27 @@ export function useLobby(userEmail: string, options?: UseLobbyOptions) {
     };
   }, [userEmail, updatePresence, fetchOnlineUsers, fetchChallenges]);
 
+  // Record the app this device is opening before the shell is left, so the
+  // lobby shows it and challenges route to the user's other devices meanwhile
+  const enterApp = useCallback(async (appId: string, status: 'online' | 'in_game') => {
+    leavingForAppRef.current = true;
+    await updatePresence(status, appId);
+  }, [updatePresence]);
+
   return {
     ...lobbyState,
     notification,
+    declinedChallenge,
+    dismissDeclined,
+    scheduledChallenges,
+    bookingNotice,
     updatePresence,
+    enterApp,
     sendChallenge,
     sendMultiChallenge,
+    sendPresetChallenge,
+    scheduleChallenge,
+    cancelScheduledChallenge,
     acceptChallenge,
     rejectChallenge,
     fetchGameConfig,
@@ -0,0 +1,43 @@
+// Session handling for shell API calls.
+//
+// By default the session token is kept in localStorage and sent as a bearer
+// token. In cookie session mode (SESSION_COOKIES=true on the backend) login
+// responses carry no token: the session lives in an HttpOnly cookie the browser
+// sends itself, and requests echo the readable CSRF cookie in X-CSRF-Token.
+
+const CSRF_COOKIE = 'ah_csrf';
+
+function csrfToken(): string | null {
+  const cookie = document.cookie.split('; ').find((c) => c.startsWith(`${CSRF_COOKIE}=`));
+  return cookie ? cookie.slice(CSRF_COOKIE.length + 1) : null;
+}
+
+// Whether there is a session to send: a stored token or a cookie session
+export function hasSession(): boolean {
+  return !!localStorage.getItem('token') || !!csrfToken();
+}
+
+// Auth headers for a shell API request
+export function authHeaders(): Record<string, string> {
+  const token = localStorage.getItem('token');
+  if (token) return { 'Authorization': `Bearer ${token}` };
+  const csrf = csrfToken();
+  return csrf ? { 'X-CSRF-Token': csrf } : {};
+}
+
+// Keep the session from a login-style response. Cookie sessions have no token.
+export function storeSession(data: { token?: string }) {
+  if (data.token) {
+    localStorage.setItem('token', data.token);
+  } else {
+    localStorage.removeItem('token');
+  }
+}
+
+export async function clearSession(apiBase: string) {
+  const cookieSession = !localStorage.getItem('token') && !!csrfToken();
+  localStorage.removeItem('token');
+  if (cookieSession) {
+    await fetch(`${apiBase}/logout`, { method: 'POST' }).catch(() => {});
+  }
+}
@@ -6,6 +6,17 @@ export interface User {
   impersonating?: boolean;
   superUser?: string;  // Original super_user email
   is_guest?: boolean;  // True for guest users
+  roles?: string[];
+  is_table?: boolean;  // True for a shared table device (players are picked per app)
+  table_id?: number;
+}
+
+// A player profile on a table device; apps are opened as them
+export interface TablePlayer {
+  id: number;
+  email: string;  // table-player-{key}
+  name: string;
+  flair?: string;
 }
 
 export interface AuthResponse {
@@ -22,14 +33,26 @@ export interface ValidateResponse {
 // Presence types
 export type UserStatus = 'online' | 'in_game' | 'away';
 
+// A game the user's game backend says they're playing; challenges are refused until it ends
+export interface InGamePresence {
+  appId: string;
+  appName: string;
+  gameId: string;
+  opponents: string[];
+  since: number; // Unix timestamp
+}
+
 export interface UserPresence {
   email: string;
   displayName: string;
   status: UserStatus;
   currentApp?: string;
   lastSeen: number; // Unix timestamp
+  deviceCount?: number; // Devices the user is signed in on
+  inGame?: InGamePresence;
 }
 
+
 // App types
 export type AppType = 'internal' | 'iframe';
 export type RealtimeType = 'websocket' | 'sse' | 'none';
@@ -48,6 +71,23 @@ export interface AppDefinition {
   maxPlayers?: number; // Maximum players for multi-player games (e.g., 6)
   guestAccessible?: boolean; // True if guests can access this app
   displayOrder?: number; // Display order for sorting
+  maintenance?: AppMaintenance; // Only sent to admins - players don't see apps in maintenance
+  capabilities?: AppCapabilities; // From the app's /api/config; missing until the app has answered
+}
+
+// What an app supports, as declared in its /api/config
+export interface AppCapabilities {
+  challenges: boolean;
+  sse: boolean;
+  displays: boolean;
+  teams: boolean;
+}
+
+export interface AppMaintenance {
+  appId: string;
+  message: string;
+  since: string;
+  by?: string;
 }
 
 export interface AppsRegistry {
@@ -73,6 +113,57 @@ export interface Challenge {
   status: 'pending' | 'accepted' | 'rejected' | 'expired' | 'ready' | 'active';
   createdAt: number; // Unix timestamp
   expiresAt: number; // Unix timestamp
+  declineReason?: DeclineReason;
+}
+
+// Optional reason given when declining a challenge
+export type DeclineReason = 'busy' | 'unknown_game' | 'not_now';
+
+// Online user who recently played an app (suggested after a decline)
+export interface OpponentSuggestion extends UserPresence {
+  gamesPlayed: number;
+  lastPlayed: number; // Unix timestamp
+}
+
+// A saved "usual game": app, options and preferred opponents (most preferred first)
+export interface ChallengePreset {
+  id: number;
+  name: string;
+  appId: string;
+  options: ChallengeOptions;
+  opponents: string[];
+  lastUsedAt?: string;
+  createdAt: string;
+}
+
+// A challenge booked for later; sent at scheduledAt once both players are free
+export interface ScheduledChallenge {
+  id: number;
+  fromUser: string;
+  toUser: string;
+  appId: string;
+  options?: ChallengeOptions;
+  scheduledAt: string;
+  status: 'scheduled' | 'activated' | 'expired' | 'cancelled';
+  challengeId?: string;
+  remindedAt?: string;
+  createdAt: string;
+}
+
+// One day of the booked games calendar
+export interface CalendarDay {
+  date: string;
+  challenges: ScheduledChallenge[];
+}
+
+// A sent challenge that was declined, with other players to try instead
+export interface DeclinedChallenge {
+  challengeId: string;
+  appId: string;
+  toUser: string;
+  reasonText?: string;
+  options?: ChallengeOptions;
+  suggestions: OpponentSuggestion[];
 }
 
 // Lobby types
@@ -103,7 +194,9 @@ export interface GameConfig {
   appId: string;
   name: string;
   icon: string;
+  version: string;
   description: string;
+  capabilities: AppCapabilities;
   gameOptions: GameOption[];
 }
 
@@ -111,3 +204,16 @@ export interface GameConfig {
 export interface ChallengeOptions {
   [key: string]: string | number | boolean;
 }
+
+// Activity feed (GET /api/activity, /api/activity/stream)
+export type ActivityType = 'game_result' | 'quiz_winner' | 'lms_elimination' | 'challenge';
+
+export interface ActivityEvent {
+  id: string;
+  type: ActivityType;
+  app: string;
+  text: string;
+  players?: string[];
+  venueId?: number;
+  createdAt: string; // RFC3339
+}
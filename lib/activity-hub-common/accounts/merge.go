@@ -0,0 +1,74 @@
+// Package accounts carries identity account changes that game backends act on.
+//
+// When a super_user merges a duplicate account into another, the identity
+// shell moves the identity data and publishes a Merge on MergedChannel. Each
+// backend that keys data by email subscribes and re-keys its own rows from
+// Merge.From to Merge.To. A merge can be published more than once (the shell
+// can re-send it for a backend that was down), so handlers must be idempotent.
+package accounts
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MergedChannel is the Redis pub/sub channel merges are published on.
+const MergedChannel = "identity:user_merged"
+
+// Merge says that account From was folded into account To.
+type Merge struct {
+	ID       int       `json:"id"`   // The identity DB user_merges row
+	From     string    `json:"from"` // Email of the duplicate; no longer exists
+	To       string    `json:"to"`   // Email of the account that was kept
+	MergedBy string    `json:"mergedBy"`
+	MergedAt time.Time `json:"mergedAt"`
+}
+
+// Encode validates a merge and returns the pub/sub message to publish on
+// MergedChannel. Like the activity feed, the package has no Redis dependency.
+//
+// Usage:
+//
+//	msg, err := accounts.Encode(accounts.Merge{ID: id, From: from, To: to, MergedBy: admin})
+//	if err == nil {
+//	    redisClient.Publish(ctx, accounts.MergedChannel, msg)
+//	}
+func Encode(m Merge) (string, error) {
+	if err := m.validate(); err != nil {
+		return "", err
+	}
+	if m.MergedAt.IsZero() {
+		m.MergedAt = time.Now()
+	}
+	m.MergedAt = m.MergedAt.UTC()
+
+	data, err := json.Marshal(m)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal merge: %w", err)
+	}
+	return string(data), nil
+}
+
+// Decode parses a pub/sub message published with Encode.
+func Decode(msg string) (Merge, error) {
+	var m Merge
+	if err := json.Unmarshal([]byte(msg), &m); err != nil {
+		return m, fmt.Errorf("failed to unmarshal merge: %w", err)
+	}
+	if err := m.validate(); err != nil {
+		return m, err
+	}
+	return m, nil
+}
+
+func (m Merge) validate() error {
+	if strings.TrimSpace(m.From) == "" || strings.TrimSpace(m.To) == "" {
+		return fmt.Errorf("from and to are required")
+	}
+	if strings.EqualFold(m.From, m.To) {
+		return fmt.Errorf("cannot merge %s into itself", m.From)
+	}
+	return nil
+}
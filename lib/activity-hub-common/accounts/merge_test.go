@@ -0,0 +1,56 @@
+package accounts
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEncodeRoundTrip(t *testing.T) {
+	msg, err := Encode(Merge{ID: 7, From: "bob2@example.com", To: "bob@example.com", MergedBy: "admin@example.com"})
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	m, err := Decode(msg)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if m.ID != 7 || m.From != "bob2@example.com" || m.To != "bob@example.com" || m.MergedBy != "admin@example.com" {
+		t.Errorf("unexpected merge: %+v", m)
+	}
+	if m.MergedAt.IsZero() || m.MergedAt.Location() != time.UTC {
+		t.Errorf("MergedAt = %v, want UTC now", m.MergedAt)
+	}
+}
+
+func TestEncodeKeepsTime(t *testing.T) {
+	at := time.Date(2026, 10, 17, 20, 30, 0, 0, time.UTC)
+	msg, err := Encode(Merge{From: "a@example.com", To: "b@example.com", MergedAt: at})
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	m, _ := Decode(msg)
+	if !m.MergedAt.Equal(at) {
+		t.Errorf("MergedAt = %v, want %v", m.MergedAt, at)
+	}
+}
+
+func TestEncodeRejectsBadMerges(t *testing.T) {
+	for _, m := range []Merge{
+		{To: "b@example.com"},
+		{From: "a@example.com", To: "  "},
+		{From: "a@example.com", To: "A@example.com"},
+	} {
+		if _, err := Encode(m); err == nil {
+			t.Errorf("Encode(%+v) expected error", m)
+		}
+	}
+}
+
+func TestDecodeRejectsBadMessages(t *testing.T) {
+	for _, msg := range []string{"not json", `{"from":"a@example.com"}`} {
+		if _, err := Decode(msg); err == nil {
+			t.Errorf("Decode(%q) expected error", msg)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package activity
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Channel is the Redis pub/sub channel every backend publishes feed events to.
+// The identity shell aggregates it into the platform-wide activity feed.
+const Channel = "activity:feed"
+
+// Event types
+const (
+	TypeGameResult     = "game_result"
+	TypeQuizWinner     = "quiz_winner"
+	TypeLMSElimination = "lms_elimination"
+	TypeChallenge      = "challenge"
+)
+
+// Event is one line in the activity feed. Everything in it is shown publicly,
+// so Players must hold public names (auth.Profile.PublicName), never emails.
+type Event struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	App       string    `json:"app"`
+	Text      string    `json:"text"`              // One-line summary, e.g. "Alice beat Bob at Dots (12-8)"
+	Players   []string  `json:"players,omitempty"` // Public names of the players involved
+	VenueID   int       `json:"venueId,omitempty"` // 0 = not tied to a venue
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Encode validates an event, fills in ID and CreatedAt if missing and returns
+// the pub/sub message to publish on Channel. The package has no Redis
+// dependency, so backends publish with whichever client they already use.
+//
+// Usage:
+//
+//	names := auth.PublicNames(identityDB, []string{winnerID, loserID})
+//	msg, err := activity.Encode(activity.Event{
+//	    Type:    activity.TypeGameResult,
+//	    App:     "dots",
+//	    Text:    names[winnerID] + " beat " + names[loserID] + " at Dots",
+//	    Players: []string{names[winnerID], names[loserID]},
+//	})
+//	if err == nil {
+//	    redisClient.Publish(ctx, activity.Channel, msg)
+//	}
+func Encode(ev Event) (string, error) {
+	if ev.Type == "" || ev.App == "" || ev.Text == "" {
+		return "", fmt.Errorf("type, app and text are required")
+	}
+	if ev.ID == "" {
+		id := make([]byte, 8)
+		if _, err := rand.Read(id); err != nil {
+			return "", fmt.Errorf("failed to generate event id: %w", err)
+		}
+		ev.ID = hex.EncodeToString(id)
+	}
+	if ev.CreatedAt.IsZero() {
+		ev.CreatedAt = time.Now()
+	}
+	ev.CreatedAt = ev.CreatedAt.UTC()
+
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal activity event: %w", err)
+	}
+	return string(data), nil
+}
+
+// Decode parses a pub/sub message published with Encode.
+func Decode(msg string) (Event, error) {
+	var ev Event
+	if err := json.Unmarshal([]byte(msg), &ev); err != nil {
+		return ev, fmt.Errorf("failed to unmarshal activity event: %w", err)
+	}
+	if ev.ID == "" || ev.Type == "" || ev.CreatedAt.IsZero() {
+		return ev, fmt.Errorf("incomplete activity event")
+	}
+	return ev, nil
+}
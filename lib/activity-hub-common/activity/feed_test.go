@@ -0,0 +1,58 @@
+package activity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEncodeFillsDefaults(t *testing.T) {
+	msg, err := Encode(Event{
+		Type:    TypeGameResult,
+		App:     "dots",
+		Text:    "Alice beat Bob at Dots (12-8)",
+		Players: []string{"Alice", "Bob"},
+	})
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	ev, err := Decode(msg)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if ev.ID == "" {
+		t.Error("ID should be generated")
+	}
+	if ev.CreatedAt.IsZero() || ev.CreatedAt.Location() != time.UTC {
+		t.Errorf("CreatedAt = %v, want UTC now", ev.CreatedAt)
+	}
+	if ev.App != "dots" || len(ev.Players) != 2 {
+		t.Errorf("unexpected event: %+v", ev)
+	}
+}
+
+func TestEncodeKeepsIDAndTime(t *testing.T) {
+	at := time.Date(2026, 10, 17, 20, 30, 0, 0, time.UTC)
+	msg, err := Encode(Event{ID: "abc", Type: TypeChallenge, App: "tic-tac-toe", Text: "x", CreatedAt: at})
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	ev, _ := Decode(msg)
+	if ev.ID != "abc" || !ev.CreatedAt.Equal(at) {
+		t.Errorf("got id %q at %v", ev.ID, ev.CreatedAt)
+	}
+}
+
+func TestEncodeRequiresFields(t *testing.T) {
+	if _, err := Encode(Event{Type: TypeQuizWinner, App: "quiz"}); err == nil {
+		t.Error("expected error for missing text")
+	}
+}
+
+func TestDecodeRejectsIncompleteEvents(t *testing.T) {
+	for _, msg := range []string{"not json", `{"type":"game_result"}`} {
+		if _, err := Decode(msg); err == nil {
+			t.Errorf("Decode(%q) expected error", msg)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+// Package analytics collects anonymous usage events (an app opened, how long
+// a game lasted, how many played in a quiz) from frontends and backends, and
+// sums them per day for the landlord's dashboard (game-admin → Analytics).
+//
+// Events live in the identity database (scripts/migrate_add_analytics.sh):
+// analytics_events holds one row per event with its venue, and nothing about
+// who caused it - no email, name or token is stored.
+package analytics
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// Events. Value is what gets summed and averaged per day; events that are
+// only counted leave it 0.
+const (
+	EventAppOpened         = "app_opened"         // Value unused
+	EventGameLength        = "game_length"        // Value: seconds
+	EventQuizParticipation = "quiz_participation" // Value: players in the session
+)
+
+// DayFormat is how days are written in aggregates and query parameters
+const DayFormat = "2006-01-02"
+
+// MaxDays is the longest range Daily will sum
+const MaxDays = 366
+
+// Event names and app IDs are short lowercase slugs ("game_length", "last-man-standing")
+var slug = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,49}$`)
+
+// Event is one anonymous usage event.
+type Event struct {
+	Event      string    `json:"event"`
+	App        string    `json:"app"`
+	Value      float64   `json:"value,omitempty"`
+	VenueID    int       `json:"venueId,omitempty"` // 0 = not tied to a venue
+	OccurredAt time.Time `json:"occurredAt"`        // Set by Record when zero
+}
+
+// Validate checks an event before it is recorded.
+func (e Event) Validate() error {
+	if !slug.MatchString(e.Event) || !slug.MatchString(e.App) {
+		return fmt.Errorf("event and app must be lowercase names of up to 50 characters")
+	}
+	if e.Value < 0 {
+		return fmt.Errorf("value must not be negative")
+	}
+	if e.VenueID < 0 {
+		return fmt.Errorf("invalid venue")
+	}
+	return nil
+}
+
+// Record stores an event.
+//
+// Usage:
+//
+//	go func() {
+//	    if err := analytics.Record(identityDB, analytics.Event{
+//	        Event:   analytics.EventGameLength,
+//	        App:     "dots",
+//	        Value:   float64(duration),
+//	        VenueID: user.VenueID,
+//	    }); err != nil {
+//	        log.Printf("Failed to record game length: %v", err)
+//	    }
+//	}()
+func Record(identityDB *sql.DB, e Event) error {
+	if err := e.Validate(); err != nil {
+		return err
+	}
+	if e.OccurredAt.IsZero() {
+		e.OccurredAt = time.Now()
+	}
+	_, err := identityDB.Exec(`
+		INSERT INTO analytics_events (event, app, value, venue_id, occurred_at)
+		VALUES ($1, $2, $3, NULLIF($4, 0), $5)
+	`, e.Event, e.App, e.Value, e.VenueID, e.OccurredAt)
+	if err != nil {
+		return fmt.Errorf("failed to record analytics event: %w", err)
+	}
+	return nil
+}
+
+// Aggregate is one event from one app summed over a day.
+type Aggregate struct {
+	Day     string  `json:"day"` // DayFormat
+	App     string  `json:"app"`
+	Event   string  `json:"event"`
+	Count   int     `json:"count"`
+	Total   float64 `json:"total"`
+	Average float64 `json:"average"`
+}
+
+// Filter narrows Daily to one venue, app or event. Zero values match everything.
+type Filter struct {
+	VenueID int
+	App     string
+	Event   string
+}
+
+// Daily sums events per day, app and event from the first day up to and
+// excluding the second (see ParseDays), oldest day first. Days with no
+// events are left out.
+func Daily(identityDB *sql.DB, from, to time.Time, f Filter) ([]Aggregate, error) {
+	rows, err := identityDB.Query(`
+		SELECT to_char(occurred_at, 'YYYY-MM-DD'), app, event, COUNT(*), SUM(value), AVG(value)
+		FROM analytics_events
+		WHERE occurred_at >= $1 AND occurred_at < $2
+		  AND ($3 = 0 OR venue_id = $3)
+		  AND ($4 = '' OR app = $4)
+		  AND ($5 = '' OR event = $5)
+		GROUP BY 1, 2, 3
+		ORDER BY 1, 2, 3
+	`, from, to, f.VenueID, f.App, f.Event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load analytics: %w", err)
+	}
+	defer rows.Close()
+
+	aggregates := []Aggregate{}
+	for rows.Next() {
+		var a Aggregate
+		if err := rows.Scan(&a.Day, &a.App, &a.Event, &a.Count, &a.Total, &a.Average); err != nil {
+			return nil, fmt.Errorf("failed to scan analytics: %w", err)
+		}
+		aggregates = append(aggregates, a)
+	}
+	return aggregates, rows.Err()
+}
+
+// ParseDays parses an inclusive range of DayFormat days. An empty to means
+// today and an empty from the 30 days up to to. Returns the first instant of
+// from and of the day after to.
+func ParseDays(from, to string) (time.Time, time.Time, error) {
+	var end time.Time
+	if to == "" {
+		now := time.Now()
+		end = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	} else {
+		t, err := time.ParseInLocation(DayFormat, to, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("to must be YYYY-MM-DD")
+		}
+		end = t
+	}
+	end = end.AddDate(0, 0, 1)
+
+	start := end.AddDate(0, 0, -30)
+	if from != "" {
+		t, err := time.ParseInLocation(DayFormat, from, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("from must be YYYY-MM-DD")
+		}
+		start = t
+	}
+	if !start.Before(end) {
+		return time.Time{}, time.Time{}, fmt.Errorf("from must not be after to")
+	}
+	if start.AddDate(0, 0, MaxDays).Before(end) {
+		return time.Time{}, time.Time{}, fmt.Errorf("at most %d days at a time", MaxDays)
+	}
+	return start, end, nil
+}
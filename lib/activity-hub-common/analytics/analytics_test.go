@@ -0,0 +1,81 @@
+package analytics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		event Event
+		ok    bool
+	}{
+		{Event{Event: EventAppOpened, App: "dots"}, true},
+		{Event{Event: EventGameLength, App: "last-man-standing", Value: 312}, true},
+		{Event{Event: "", App: "dots"}, false},
+		{Event{Event: EventAppOpened, App: ""}, false},
+		{Event{Event: "App Opened", App: "dots"}, false},
+		{Event{Event: EventAppOpened, App: strings.Repeat("a", 51)}, false},
+		{Event{Event: EventGameLength, App: "dots", Value: -1}, false},
+		{Event{Event: EventAppOpened, App: "dots", VenueID: -2}, false},
+	}
+	for _, tt := range tests {
+		if err := tt.event.Validate(); (err == nil) != tt.ok {
+			t.Errorf("Validate(%+v) = %v, want ok=%v", tt.event, err, tt.ok)
+		}
+	}
+}
+
+func TestParseDays(t *testing.T) {
+	from, to, err := ParseDays("2026-10-01", "2026-10-17")
+	if err != nil {
+		t.Fatalf("ParseDays: %v", err)
+	}
+	if from.Format(DayFormat) != "2026-10-01" || to.Format(DayFormat) != "2026-10-18" {
+		t.Errorf("Got %s to %s, want 2026-10-01 to 2026-10-18", from.Format(DayFormat), to.Format(DayFormat))
+	}
+
+	from, to, err = ParseDays("", "2026-10-17")
+	if err != nil || to.Sub(from) < 29*24*time.Hour || from.Format(DayFormat) != "2026-09-18" {
+		t.Errorf("Default range: %s to %s (%v)", from.Format(DayFormat), to.Format(DayFormat), err)
+	}
+
+	for _, bad := range [][2]string{
+		{"17/10/2026", ""},
+		{"", "tomorrow"},
+		{"2026-10-18", "2026-10-17"},
+		{"2024-01-01", "2026-10-17"},
+	} {
+		if _, _, err := ParseDays(bad[0], bad[1]); err == nil {
+			t.Errorf("ParseDays(%q, %q): expected an error", bad[0], bad[1])
+		}
+	}
+}
+
+func TestHandlersRejectBadRequests(t *testing.T) {
+	tests := []struct {
+		handler http.Handler
+		method  string
+		url     string
+		body    string
+		want    int
+	}{
+		{IngestHandler(nil), "GET", "/api/analytics/events", "", http.StatusMethodNotAllowed},
+		{IngestHandler(nil), "POST", "/api/analytics/events", "not json", http.StatusBadRequest},
+		{IngestHandler(nil), "POST", "/api/analytics/events", `{"event": "app_opened"}`, http.StatusBadRequest},
+		{IngestHandler(nil), "POST", "/api/analytics/events", `{"event": "app_opened", "app": "dots", "pad": "` + strings.Repeat("x", maxEventBody) + `"}`, http.StatusBadRequest},
+		{DailyHandler(nil), "POST", "/api/analytics/daily", "", http.StatusMethodNotAllowed},
+		{DailyHandler(nil), "GET", "/api/analytics/daily?from=yesterday", "", http.StatusBadRequest},
+		{DailyHandler(nil), "GET", "/api/analytics/daily?venue=pub", "", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		tt.handler.ServeHTTP(w, httptest.NewRequest(tt.method, tt.url, strings.NewReader(tt.body)))
+		if w.Code != tt.want {
+			t.Errorf("%s %s: status %d, want %d", tt.method, tt.url, w.Code, tt.want)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package analytics
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/achgithub/activity-hub-common/auth"
+)
+
+// maxEventBody caps an ingested event; real ones are well under 200 bytes
+const maxEventBody = 4 << 10
+
+// IngestHandler takes usage events from frontends. Mount it behind
+// auth.OptionalMiddleware: a signed-in user's venue is recorded, the user
+// isn't, and anonymous events are welcome.
+//
+//	POST {"event": "app_opened", "app": "dots", "value": 0}   204 when recorded
+//
+// Usage:
+//
+//	r.Handle("/api/analytics/events", auth.OptionalMiddleware(identityDB)(analytics.IngestHandler(identityDB))).Methods("POST")
+func IngestHandler(identityDB *sql.DB) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var req struct {
+			Event string  `json:"event"`
+			App   string  `json:"app"`
+			Value float64 `json:"value"`
+		}
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxEventBody)).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+			return
+		}
+
+		e := Event{Event: req.Event, App: req.App, Value: req.Value}
+		if user, ok := auth.GetUserFromContext(r.Context()); ok {
+			e.VenueID = user.VenueID
+		}
+		if err := e.Validate(); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		if err := Record(identityDB, e); err != nil {
+			log.Printf("❌ %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to record event"})
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// DailyHandler serves daily aggregates to the landlord's dashboard. Mount it
+// behind your admin auth middleware (after auth.Middleware). Users tied to a
+// venue only see their own; chain-wide users see every venue, or pick one.
+//
+//	GET ?from=&to=&venue=&app=&event=   {"from", "to", "aggregates": [...]}
+//
+// from and to are inclusive YYYY-MM-DD days (default: the last 30 days).
+//
+// Usage:
+//
+//	r.Handle("/api/analytics/daily", requireAdmin(analytics.DailyHandler(identityDB))).Methods("GET")
+func DailyHandler(identityDB *sql.DB) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		q := r.URL.Query()
+		from, to, err := ParseDays(q.Get("from"), q.Get("to"))
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+
+		f := Filter{App: q.Get("app"), Event: q.Get("event")}
+		if v := q.Get("venue"); v != "" {
+			if f.VenueID, err = strconv.Atoi(v); err != nil || f.VenueID < 0 {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid venue"})
+				return
+			}
+		}
+		if user, ok := auth.GetUserFromContext(r.Context()); ok {
+			if f.VenueID == 0 && !user.CanAccessVenue(0) {
+				f.VenueID = user.VenueID
+			}
+			if !user.CanAccessVenue(f.VenueID) {
+				writeJSON(w, http.StatusForbidden, map[string]string{"error": "not your venue"})
+				return
+			}
+		}
+
+		aggregates, err := Daily(identityDB, from, to, f)
+		if err != nil {
+			log.Printf("❌ %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to load analytics"})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"from":       from.Format(DayFormat),
+			"to":         to.AddDate(0, 0, -1).Format(DayFormat),
+			"aggregates": aggregates,
+		})
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		log.Printf("❌ Failed to encode JSON response: %v", err)
+	}
+}
@@ -0,0 +1,165 @@
+// Package audit records admin changes in an app's audit_log table, with
+// JSON snapshots of the changed row before and after, so "who changed the
+// quiz pack at 6pm, and what did they change?" can be answered from the
+// admin app.
+//
+// Each admin backend keeps its own audit_log in its own database, with the
+// same columns:
+//
+//	id SERIAL, admin_email, impersonated_by, action_type, target_id,
+//	details JSONB, before_snapshot JSONB, after_snapshot JSONB, created_at
+package audit
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// Entry is one audited admin action. Before is empty for something created
+// and After for something deleted; both are empty for actions recorded
+// without snapshots.
+type Entry struct {
+	ID             int64           `json:"id"`
+	AdminEmail     string          `json:"adminEmail"`
+	ImpersonatedBy string          `json:"impersonatedBy,omitempty"`
+	Action         string          `json:"action"`
+	TargetID       string          `json:"targetId"`
+	Details        json.RawMessage `json:"details,omitempty"`
+	Before         json.RawMessage `json:"before,omitempty"`
+	After          json.RawMessage `json:"after,omitempty"`
+	CreatedAt      time.Time       `json:"createdAt"`
+}
+
+// Log is an app's audit log.
+type Log struct {
+	db *sql.DB
+}
+
+// New returns the audit log in the app's database.
+//
+// Usage:
+//
+//	auditLog := audit.New(appDB)
+//
+//	const questionRow = `SELECT * FROM questions WHERE id = $1`
+//	before, _ := audit.Snapshot(quizDB, questionRow, id)
+//	// ... update the question ...
+//	after, _ := audit.Snapshot(quizDB, questionRow, id)
+//	err := auditLog.Record(audit.Entry{AdminEmail: email, Action: "quiz_question_update",
+//	    TargetID: strconv.Itoa(id), Before: before, After: after})
+func New(db *sql.DB) *Log {
+	return &Log{db: db}
+}
+
+// Record writes an entry. ID and CreatedAt are set by the database.
+func (l *Log) Record(e Entry) error {
+	_, err := l.db.Exec(`
+		INSERT INTO audit_log (admin_email, impersonated_by, action_type, target_id, details,
+		                       before_snapshot, after_snapshot)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, e.AdminEmail, nullString(e.ImpersonatedBy), e.Action, e.TargetID,
+		nullJSON(e.Details), nullJSON(e.Before), nullJSON(e.After))
+	return err
+}
+
+// Querier is a *sql.DB or *sql.Tx
+type Querier interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// Snapshot returns the row selected by query as a JSON object, or nil when
+// there's no row (not created yet, or gone). Use the same query before and
+// after a change; it should select a single row, and may nest related rows
+// with json_agg.
+func Snapshot(db Querier, query string, args ...any) (json.RawMessage, error) {
+	var raw []byte
+	err := db.QueryRow(`SELECT row_to_json(s) FROM (`+query+`) s`, args...).Scan(&raw)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(raw), nil
+}
+
+// Change is one field that differs between snapshots. Fields of nested
+// objects are dotted ("settings.theme"); arrays are compared whole.
+type Change struct {
+	Field  string          `json:"field"`
+	Before json.RawMessage `json:"before"`
+	After  json.RawMessage `json:"after"`
+}
+
+// Diff lists the fields that differ between two snapshots, in field order.
+// A missing snapshot counts as an empty object, so a created row lists
+// every field.
+func Diff(before, after json.RawMessage) []Change {
+	changes := []Change{}
+	diff("", decode(before), decode(after), &changes)
+	return changes
+}
+
+func diff(path string, a, b any, out *[]Change) {
+	am, aObj := a.(map[string]any)
+	bm, bObj := b.(map[string]any)
+	if (aObj || a == nil) && (bObj || b == nil) && (aObj || bObj || path == "") {
+		keys := map[string]bool{}
+		for k := range am {
+			keys[k] = true
+		}
+		for k := range bm {
+			keys[k] = true
+		}
+		sorted := make([]string, 0, len(keys))
+		for k := range keys {
+			sorted = append(sorted, k)
+		}
+		sort.Strings(sorted)
+		for _, k := range sorted {
+			field := k
+			if path != "" {
+				field = path + "." + k
+			}
+			diff(field, am[k], bm[k], out)
+		}
+		return
+	}
+	if reflect.DeepEqual(a, b) {
+		return
+	}
+	*out = append(*out, Change{Field: path, Before: encode(a), After: encode(b)})
+}
+
+func decode(raw json.RawMessage) any {
+	if len(raw) == 0 {
+		return nil
+	}
+	d := json.NewDecoder(bytes.NewReader(raw))
+	d.UseNumber() // Compare numbers as written, so large IDs don't round
+	var v any
+	if err := d.Decode(&v); err != nil {
+		return nil
+	}
+	return v
+}
+
+func encode(v any) json.RawMessage {
+	b, _ := json.Marshal(v)
+	return b
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+func nullJSON(raw json.RawMessage) any {
+	if len(raw) == 0 {
+		return nil
+	}
+	return string(raw)
+}
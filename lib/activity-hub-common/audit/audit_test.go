@@ -0,0 +1,78 @@
+package audit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	before := json.RawMessage(`{"id": 7, "name": "Friday Quiz", "rounds": [1, 2], "settings": {"theme": "dark", "timer": 30}}`)
+	after := json.RawMessage(`{"id": 7, "name": "Friday Night Quiz", "rounds": [2, 1], "settings": {"theme": "dark", "timer": 45}, "notes": "new"}`)
+
+	got := Diff(before, after)
+	want := []Change{
+		{Field: "name", Before: json.RawMessage(`"Friday Quiz"`), After: json.RawMessage(`"Friday Night Quiz"`)},
+		{Field: "notes", Before: json.RawMessage(`null`), After: json.RawMessage(`"new"`)},
+		{Field: "rounds", Before: json.RawMessage(`[1,2]`), After: json.RawMessage(`[2,1]`)},
+		{Field: "settings.timer", Before: json.RawMessage(`30`), After: json.RawMessage(`45`)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff =\n%s\nwant\n%s", show(got), show(want))
+	}
+}
+
+func TestDiffCreatedAndDeleted(t *testing.T) {
+	row := json.RawMessage(`{"id": 3, "title": "Happy hour"}`)
+
+	created := Diff(nil, row)
+	if len(created) != 2 || created[0].Field != "id" || string(created[0].Before) != "null" {
+		t.Errorf("Created row: %s", show(created))
+	}
+	deleted := Diff(row, nil)
+	if len(deleted) != 2 || string(deleted[1].After) != "null" {
+		t.Errorf("Deleted row: %s", show(deleted))
+	}
+	if same := Diff(row, row); len(same) != 0 {
+		t.Errorf("Expected no changes, got %s", show(same))
+	}
+	if none := Diff(nil, nil); none == nil || len(none) != 0 {
+		t.Errorf("Expected an empty list, got %#v", none)
+	}
+}
+
+func TestDiffKeepsNumbersExact(t *testing.T) {
+	before := json.RawMessage(`{"id": 9007199254740993}`)
+	after := json.RawMessage(`{"id": 9007199254740992}`)
+	if changes := Diff(before, after); len(changes) != 1 {
+		t.Errorf("Expected the IDs to differ, got %s", show(changes))
+	}
+}
+
+func TestAdminHandlerRejectsBadRequests(t *testing.T) {
+	h := New(nil).AdminHandler()
+	tests := []struct {
+		method string
+		url    string
+		want   int
+	}{
+		{"GET", "/api/audit?id=abc", http.StatusBadRequest},
+		{"GET", "/api/audit?from=6pm", http.StatusBadRequest},
+		{"GET", "/api/audit?sort=admin", http.StatusBadRequest},
+		{"POST", "/api/audit", http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(tt.method, tt.url, nil))
+		if w.Code != tt.want {
+			t.Errorf("%s %s: status %d, want %d", tt.method, tt.url, w.Code, tt.want)
+		}
+	}
+}
+
+func show(changes []Change) string {
+	b, _ := json.Marshal(changes)
+	return string(b)
+}
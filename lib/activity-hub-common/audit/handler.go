@@ -0,0 +1,147 @@
+package audit
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/achgithub/activity-hub-common/listquery"
+)
+
+// entryList is what the audit log can be filtered by
+var entryList = listquery.Spec{
+	Sorts:       map[string]string{"created": "created_at"},
+	DefaultSort: "-created",
+	Tiebreak:    "id",
+	Filters: map[string]listquery.Filter{
+		"action": listquery.Equals("action_type"),
+		"target": listquery.Equals("target_id"),
+		"admin":  listquery.Search("admin_email", "impersonated_by"),
+		"from":   listquery.From("created_at"),
+		"to":     listquery.To("created_at"),
+	},
+}
+
+// Summary is an entry in the audit log list: the snapshots are left out, and
+// Changed names the fields that differ between them.
+type Summary struct {
+	Entry
+	Changed []string `json:"changed,omitempty"`
+}
+
+// AdminHandler serves the audit log to admin UIs. Mount it behind your
+// admin auth middleware - it performs no authorization of its own.
+//
+//	GET ?action=&target=&admin=&from=&to=   a page of entries, newest first (listquery paging)
+//	GET ?id={id}                            one entry with its snapshots and changes
+//
+// from and to take "2026-10-17T18:00" or a date.
+//
+// Usage:
+//
+//	r.Handle("/api/audit", requireAdmin(auditLog.AdminHandler())).Methods("GET")
+func (l *Log) AdminHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if id := r.URL.Query().Get("id"); id != "" {
+			l.serveEntry(w, id)
+			return
+		}
+		l.serveList(w, r)
+	})
+}
+
+func (l *Log) serveEntry(w http.ResponseWriter, idParam string) {
+	id, err := strconv.ParseInt(idParam, 10, 64)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid id"})
+		return
+	}
+	rows, err := l.db.Query(entrySelect+` WHERE id = $1`, id)
+	if err != nil {
+		log.Printf("❌ Failed to load audit entry %d: %v", id, err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to load audit entry"})
+		return
+	}
+	entries, err := scanEntries(rows)
+	if err != nil {
+		log.Printf("❌ Failed to load audit entry %d: %v", id, err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to load audit entry"})
+		return
+	}
+	if len(entries) == 0 {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "audit entry not found"})
+		return
+	}
+	e := entries[0]
+	writeJSON(w, http.StatusOK, map[string]interface{}{"entry": e, "changes": Diff(e.Before, e.After)})
+}
+
+func (l *Log) serveList(w http.ResponseWriter, r *http.Request) {
+	q, err := listquery.Parse(r.URL.Query(), entryList)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	query, args := q.SQL(entrySelect)
+	rows, err := l.db.Query(query, args...)
+	if err != nil {
+		log.Printf("❌ Failed to list audit log: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to load audit log"})
+		return
+	}
+	entries, err := scanEntries(rows)
+	if err != nil {
+		log.Printf("❌ Failed to list audit log: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to load audit log"})
+		return
+	}
+
+	summaries := make([]Summary, 0, len(entries))
+	for _, e := range entries {
+		s := Summary{Entry: e}
+		if len(e.Before) > 0 || len(e.After) > 0 {
+			for _, c := range Diff(e.Before, e.After) {
+				s.Changed = append(s.Changed, c.Field)
+			}
+		}
+		s.Before, s.After = nil, nil
+		summaries = append(summaries, s)
+	}
+	summaries, page := listquery.Finish(q, summaries)
+	writeJSON(w, http.StatusOK, map[string]interface{}{"entries": summaries, "page": page})
+}
+
+const entrySelect = `
+	SELECT id, admin_email, COALESCE(impersonated_by, ''), action_type, target_id,
+	       details, before_snapshot, after_snapshot, created_at
+	FROM audit_log`
+
+func scanEntries(rows *sql.Rows) ([]Entry, error) {
+	defer rows.Close()
+	var entries []Entry
+	for rows.Next() {
+		var e Entry
+		var details, before, after []byte
+		if err := rows.Scan(&e.ID, &e.AdminEmail, &e.ImpersonatedBy, &e.Action, &e.TargetID,
+			&details, &before, &after, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		e.Details, e.Before, e.After = details, before, after
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		log.Printf("❌ Failed to encode JSON response: %v", err)
+	}
+}
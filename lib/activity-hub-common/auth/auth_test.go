@@ -2,7 +2,16 @@ package auth
 
 import (
 	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestGetUserFromContext(t *testing.T) {
@@ -49,6 +58,538 @@ func TestAuthUser(t *testing.T) {
 	}
 }
 
+func TestActorEmail(t *testing.T) {
+	user := AuthUser{Email: "player@test.com"}
+	if got := user.ActorEmail(); got != "player@test.com" {
+		t.Errorf("Expected actor player@test.com, got %s", got)
+	}
+
+	impersonated := AuthUser{
+		Email:           "player@test.com",
+		IsImpersonating: true,
+		ImpersonatedBy:  "super@test.com",
+	}
+	if got := impersonated.ActorEmail(); got != "super@test.com" {
+		t.Errorf("Expected actor super@test.com, got %s", got)
+	}
+}
+
+func TestCanAccessVenue(t *testing.T) {
+	chainWide := AuthUser{Email: "owner@test.com"}
+	if !chainWide.CanAccessVenue(2) {
+		t.Error("Expected chain-wide user to access any venue")
+	}
+
+	venueUser := AuthUser{Email: "manager@test.com", VenueID: 1}
+	if !venueUser.CanAccessVenue(1) {
+		t.Error("Expected user to access their own venue")
+	}
+	if venueUser.CanAccessVenue(2) {
+		t.Error("Expected user to be denied another venue")
+	}
+
+	superUser := AuthUser{Email: "super@test.com", VenueID: 1, Roles: []string{"super_user"}}
+	if !superUser.CanAccessVenue(2) {
+		t.Error("Expected super_user to access any venue")
+	}
+}
+
+func TestTableSessions(t *testing.T) {
+	device := AuthUser{Email: "table-4f2a", TableID: 3}
+	player := AuthUser{Email: "table-player-9c1d", TableID: 3}
+	if device.IsTablePlayer() {
+		t.Error("Expected table device not to be a player")
+	}
+	if !player.IsTablePlayer() {
+		t.Error("Expected table player to be a player")
+	}
+	if !IsTableEmail(device.Email) || !IsTableEmail(player.Email) || IsTableEmail("player@test.com") {
+		t.Error("Expected only table sessions to have table emails")
+	}
+
+	tests := map[string]string{
+		"table-4f2a":        "table-token-4f2a",
+		"table-player-9c1d": "table-player-token-9c1d",
+		"player@test.com":   "",
+		"guest-1234":        "",
+	}
+	for email, want := range tests {
+		if got := TableSessionToken(email); got != want {
+			t.Errorf("TableSessionToken(%q) = %q, want %q", email, got, want)
+		}
+	}
+}
+
+func TestKioskGrant(t *testing.T) {
+	k := KioskGrant{
+		Capabilities: []string{"leaderboard:view"},
+		Endpoints:    []string{"GET /api/standings", "GET /api/standings/*", "get /api/config"},
+	}
+	tests := []struct {
+		method, path string
+		want         bool
+	}{
+		{"GET", "/api/standings", true},
+		{"GET", "/api/standings/tic-tac-toe", true},
+		{"GET", "/api/config", true},
+		{"POST", "/api/standings", false},
+		{"GET", "/api/standingsx", false},
+		{"POST", "/api/result", false},
+	}
+	for _, tt := range tests {
+		if got := k.Allows(tt.method, tt.path); got != tt.want {
+			t.Errorf("Allows(%s %s) = %v, want %v", tt.method, tt.path, got, tt.want)
+		}
+	}
+	if !k.Can("leaderboard:view") || k.Can("quiz:display") {
+		t.Error("Expected only the issued capability")
+	}
+
+	if !IsKioskToken("kiosk-abc") || IsKioskToken("demo-token-a@b.com") {
+		t.Error("Expected only kiosk- tokens to be kiosk tokens")
+	}
+	if HashKioskToken("kiosk-abc") == HashKioskToken("kiosk-abd") || len(HashKioskToken("kiosk-abc")) != 64 {
+		t.Error("Expected distinct SHA-256 hex hashes")
+	}
+	if _, err := ResolveToken(nil, "kiosk-abc"); err == nil {
+		t.Error("Expected ResolveToken to reject a kiosk token")
+	}
+
+	kiosk := &AuthUser{Email: "kiosk-1", Kiosk: &k}
+	w := httptest.NewRecorder()
+	if kioskAllowed(w, httptest.NewRequest("POST", "/api/result", nil), kiosk) || w.Code != http.StatusForbidden {
+		t.Errorf("Expected kiosk to be refused with 403, got %d", w.Code)
+	}
+	if !kioskAllowed(httptest.NewRecorder(), httptest.NewRequest("POST", "/api/result", nil), &AuthUser{Email: "a@b.com"}) {
+		t.Error("Expected a person to pass the kiosk check")
+	}
+}
+
+func TestRequireKioskCapability(t *testing.T) {
+	handler := RequireKioskCapability("quiz:display")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	serve := func(user *AuthUser) int {
+		r := httptest.NewRequest("GET", "/api/display/session/ABC123", nil)
+		if user != nil {
+			r = r.WithContext(withUser(r.Context(), user))
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	leaderboardTV := &AuthUser{Email: "kiosk-1", Kiosk: &KioskGrant{
+		Capabilities: []string{"leaderboard:view"},
+		Endpoints:    []string{"GET /api/display/session/*"},
+	}}
+	quizTV := &AuthUser{Email: "kiosk-2", Kiosk: &KioskGrant{Capabilities: []string{"quiz:display"}}}
+
+	if code := serve(leaderboardTV); code != http.StatusForbidden {
+		t.Errorf("kiosk without the capability = %d, want 403", code)
+	}
+	if code := serve(quizTV); code != http.StatusOK {
+		t.Errorf("kiosk with the capability = %d, want 200", code)
+	}
+	if code := serve(&AuthUser{Email: "host@pub.com"}); code != http.StatusOK {
+		t.Errorf("person = %d, want 200", code)
+	}
+}
+
+func TestProfileDisplayName(t *testing.T) {
+	p := Profile{Email: "player@test.com", Name: "Player One"}
+	if got := p.DisplayName(); got != "Player One" {
+		t.Errorf("Expected Player One, got %s", got)
+	}
+
+	p.Nickname = "P1"
+	if got := p.DisplayName(); got != "P1" {
+		t.Errorf("Expected nickname P1, got %s", got)
+	}
+}
+
+func TestAvatarPath(t *testing.T) {
+	if got := AvatarPath(""); got != "" {
+		t.Errorf("Expected empty path, got %s", got)
+	}
+
+	if got := AvatarPath("abc123"); got != "/api/avatars/abc123" {
+		t.Errorf("Expected /api/avatars/abc123, got %s", got)
+	}
+}
+
+func TestProfilePublic(t *testing.T) {
+	p := Profile{Email: "player@test.com", Name: "Player One", Flair: "🎯"}
+	if got := p.Public(); got.Email != "player@test.com" || got.PublicName() != "Player One" {
+		t.Errorf("Expected non-anonymous profile unchanged, got %+v", got)
+	}
+
+	p.Anonymous = true
+	public := p.Public()
+	if public.Email != "" || public.Flair != "" {
+		t.Errorf("Expected anonymous profile to hide email and flair, got %+v", public)
+	}
+	if public.Name != AnonymousAlias("player@test.com") {
+		t.Errorf("Expected generated alias, got %s", public.Name)
+	}
+
+	p.Alias = "Mystery Guest"
+	if got := p.PublicName(); got != "Mystery Guest" {
+		t.Errorf("Expected alias Mystery Guest, got %s", got)
+	}
+}
+
+func TestSignedTokenRoundTrip(t *testing.T) {
+	t.Setenv("AUTH_SIGNING_KEY", "test-key")
+	user := &AuthUser{Email: "player@test.com", IsImpersonating: true, ImpersonatedBy: "super@test.com"}
+
+	token, err := MintSignedToken(user, PurposeStream, StreamTokenTTL)
+	if err != nil {
+		t.Fatalf("MintSignedToken() error = %v", err)
+	}
+	if !IsSignedToken(token) || IsSignedToken("demo-token-player@test.com") {
+		t.Error("IsSignedToken misclassified a token")
+	}
+
+	claims, err := verifySignedToken(token, PurposeStream)
+	if err != nil {
+		t.Fatalf("verifySignedToken() error = %v", err)
+	}
+	if claims.Email != "player@test.com" || claims.Actor != "super@test.com" || claims.ID == "" {
+		t.Errorf("unexpected claims: %+v", claims)
+	}
+}
+
+func TestSignedTokenRejections(t *testing.T) {
+	t.Setenv("AUTH_SIGNING_KEY", "test-key")
+	user := &AuthUser{Email: "player@test.com"}
+
+	stream, _ := MintSignedToken(user, PurposeStream, StreamTokenTTL)
+	if _, err := verifySignedToken(stream, PurposeLaunch); err == nil {
+		t.Error("expected a stream token to be rejected as a launch token")
+	}
+
+	expired, _ := MintSignedToken(user, PurposeStream, -time.Minute)
+	if _, err := verifySignedToken(expired, PurposeStream); err == nil {
+		t.Error("expected an expired token to be rejected")
+	}
+
+	tampered := stream[:len(stream)-2] + "xx"
+	if _, err := verifySignedToken(tampered, PurposeStream); err == nil {
+		t.Error("expected a tampered token to be rejected")
+	}
+
+	t.Setenv("AUTH_SIGNING_KEY", "other-key")
+	if _, err := verifySignedToken(stream, PurposeStream); err == nil {
+		t.Error("expected a token signed with another key to be rejected")
+	}
+}
+
+func TestSigningKeyRequiredOutsideDevelopment(t *testing.T) {
+	t.Setenv("AUTH_SIGNING_KEY", "")
+	t.Setenv("ACTIVITY_HUB_ENV", "development")
+	user := &AuthUser{Email: "player@test.com"}
+
+	devToken, err := MintSignedToken(user, PurposeStream, StreamTokenTTL)
+	if err != nil {
+		t.Fatalf("MintSignedToken() in development error = %v", err)
+	}
+	devService, _ := MintServiceToken("dots", ServiceTokenTTL)
+
+	t.Setenv("ACTIVITY_HUB_ENV", "production")
+	if _, err := MintSignedToken(user, PurposeStream, StreamTokenTTL); err == nil {
+		t.Error("expected minting to be refused without a signing key")
+	}
+	if _, err := MintServiceToken("dots", ServiceTokenTTL); err == nil {
+		t.Error("expected service token minting to be refused without a signing key")
+	}
+	if _, err := verifySignedToken(devToken, PurposeStream); err == nil {
+		t.Error("expected a development-key token to be rejected without a signing key")
+	}
+	if _, err := VerifyServiceToken(devService); err == nil {
+		t.Error("expected a development-key service token to be rejected without a signing key")
+	}
+}
+
+func TestServiceToken(t *testing.T) {
+	t.Setenv("AUTH_SIGNING_KEY", "test-key")
+
+	token, err := MintServiceToken("tic-tac-toe", ServiceTokenTTL)
+	if err != nil {
+		t.Fatalf("MintServiceToken() error = %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if app, err := VerifyServiceToken(token); err != nil || app != "tic-tac-toe" {
+			t.Errorf("VerifyServiceToken() = %q, %v", app, err)
+		}
+	}
+
+	stream, _ := MintSignedToken(&AuthUser{Email: "player@test.com"}, PurposeStream, StreamTokenTTL)
+	if _, err := VerifyServiceToken(stream); err == nil {
+		t.Error("expected a stream token to be rejected as a service token")
+	}
+	if _, err := MintServiceToken("", ServiceTokenTTL); err == nil {
+		t.Error("expected an error for an empty app ID")
+	}
+}
+
+func TestServiceMiddleware(t *testing.T) {
+	t.Setenv("AUTH_SIGNING_KEY", "test-key")
+	handler := ServiceMiddleware("identity-shell")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Header.Get("X-Service-App")))
+	}))
+
+	shell, _ := MintServiceToken("identity-shell", ServiceTokenTTL)
+	other, _ := MintServiceToken("dots", ServiceTokenTTL)
+	for _, tc := range []struct {
+		token string
+		want  int
+	}{
+		{shell, http.StatusOK},
+		{other, http.StatusForbidden},
+		{"demo-token-player@test.com", http.StatusUnauthorized},
+		{"", http.StatusUnauthorized},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/api/digest", nil)
+		req.Header.Set("Authorization", "Bearer "+tc.token)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tc.want {
+			t.Errorf("token %.20q: status %d, want %d", tc.token, rec.Code, tc.want)
+		}
+		if rec.Code == http.StatusOK && rec.Body.String() != "identity-shell" {
+			t.Errorf("X-Service-App = %q", rec.Body.String())
+		}
+	}
+}
+
+func TestSessionTokenPrefersBearer(t *testing.T) {
+	r := httptest.NewRequest("GET", "/api/x", nil)
+	r.AddCookie(&http.Cookie{Name: SessionCookieName, Value: "demo-token-cookie@example.com"})
+	if token, fromCookie := SessionToken(r); token != "demo-token-cookie@example.com" || !fromCookie {
+		t.Errorf("cookie only: got %q, %v", token, fromCookie)
+	}
+
+	r.Header.Set("Authorization", "Bearer demo-token-bearer@example.com")
+	if token, fromCookie := SessionToken(r); token != "demo-token-bearer@example.com" || fromCookie {
+		t.Errorf("bearer + cookie: got %q, %v", token, fromCookie)
+	}
+}
+
+func TestCSRFMiddleware(t *testing.T) {
+	t.Setenv("AUTH_SIGNING_KEY", "test-key")
+	session := "demo-token-alice@example.com"
+	handler := CSRFMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	valid, _ := CSRFToken(session)
+	other, _ := CSRFToken("demo-token-mallory@example.com")
+
+	tests := []struct {
+		name   string
+		method string
+		csrf   string
+		bearer bool
+		want   int
+	}{
+		{"safe method", "GET", "", false, http.StatusOK},
+		{"missing token", "POST", "", false, http.StatusForbidden},
+		{"wrong token", "POST", other, false, http.StatusForbidden},
+		{"valid token", "DELETE", valid, false, http.StatusOK},
+		{"bearer request", "POST", "", true, http.StatusOK},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(tt.method, "/api/x", nil)
+		r.AddCookie(&http.Cookie{Name: SessionCookieName, Value: session})
+		if tt.csrf != "" {
+			r.Header.Set(CSRFHeaderName, tt.csrf)
+		}
+		if tt.bearer {
+			r.Header.Set("Authorization", "Bearer "+session)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		if w.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.want)
+		}
+	}
+}
+
+func TestOptionalMiddleware(t *testing.T) {
+	var got *AuthUser
+	handler := OptionalMiddleware(sessionActivityDB(nil))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = GetUserFromContext(r.Context())
+	}))
+
+	tests := []struct {
+		name      string
+		method    string
+		bearer    string
+		cookie    string
+		wantEmail string // empty = anonymous
+	}{
+		{"no token", "GET", "", "", ""},
+		{"guest token", "GET", "guest-token-abc", "", "guest-abc"},
+		{"invalid token", "GET", strings.Repeat("x", 513), "", ""},
+		{"cookie without CSRF", "POST", "", "guest-token-abc", ""},
+		{"cookie on safe method", "GET", "", "guest-token-abc", "guest-abc"},
+	}
+	for _, tt := range tests {
+		got = nil
+		r := httptest.NewRequest(tt.method, "/api/x", nil)
+		if tt.bearer != "" {
+			r.Header.Set("Authorization", "Bearer "+tt.bearer)
+		}
+		if tt.cookie != "" {
+			r.AddCookie(&http.Cookie{Name: SessionCookieName, Value: tt.cookie})
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		if w.Code != http.StatusOK {
+			t.Errorf("%s: status = %d, want 200", tt.name, w.Code)
+		}
+		email := ""
+		if got != nil {
+			email = got.Email
+		}
+		if email != tt.wantEmail {
+			t.Errorf("%s: user = %q, want %q", tt.name, email, tt.wantEmail)
+		}
+	}
+}
+
+func TestSetSessionCookies(t *testing.T) {
+	t.Setenv("AUTH_SIGNING_KEY", "test-key")
+	w := httptest.NewRecorder()
+	if err := SetSessionCookies(w, "demo-token-alice@example.com"); err != nil {
+		t.Fatalf("SetSessionCookies() error = %v", err)
+	}
+	want, _ := CSRFToken("demo-token-alice@example.com")
+
+	cookies := map[string]*http.Cookie{}
+	for _, c := range w.Result().Cookies() {
+		cookies[c.Name] = c
+	}
+	if c := cookies[SessionCookieName]; c == nil || !c.HttpOnly || c.SameSite != http.SameSiteLaxMode {
+		t.Errorf("session cookie = %+v, want HttpOnly SameSite=Lax", c)
+	}
+	if c := cookies[CSRFCookieName]; c == nil || c.HttpOnly || c.Value != want {
+		t.Errorf("csrf cookie = %+v, want readable CSRF token", c)
+	}
+}
+
+func TestCSRFRefusedWithoutSigningKey(t *testing.T) {
+	session := "demo-token-alice@example.com"
+	t.Setenv("AUTH_SIGNING_KEY", "")
+	t.Setenv("ACTIVITY_HUB_ENV", "development")
+	devToken, err := CSRFToken(session)
+	if err != nil {
+		t.Fatalf("CSRFToken() in development error = %v", err)
+	}
+
+	t.Setenv("ACTIVITY_HUB_ENV", "production")
+	if _, err := CSRFToken(session); err == nil {
+		t.Error("expected CSRFToken() to fail without a signing key")
+	}
+
+	w := httptest.NewRecorder()
+	if err := SetSessionCookies(w, session); err == nil {
+		t.Error("expected SetSessionCookies() to fail without a signing key")
+	}
+	if len(w.Result().Cookies()) != 0 {
+		t.Errorf("cookies = %v, want none", w.Result().Cookies())
+	}
+
+	r := httptest.NewRequest("POST", "/api/x", nil)
+	r.AddCookie(&http.Cookie{Name: SessionCookieName, Value: session})
+	r.Header.Set(CSRFHeaderName, devToken)
+	if ValidCSRF(r, session) {
+		t.Error("expected a development-key CSRF token to be refused without a signing key")
+	}
+	w = httptest.NewRecorder()
+	CSRFMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})).ServeHTTP(w, r)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("CSRFMiddleware status = %d, want 403", w.Code)
+	}
+}
+
+func TestIdleSessionsRefused(t *testing.T) {
+	identityDB := sessionActivityDB(map[string][]driver.Value{
+		HashSessionToken("guest-token-active"):  {false, 30.0, int64(600)},
+		HashSessionToken("guest-token-overdue"): {false, 700.0, int64(600)},
+		HashSessionToken("guest-token-expired"): {true, 5.0, int64(600)},
+	})
+	handler := Middleware(identityDB)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	tests := []struct {
+		token string
+		want  int
+	}{
+		{"guest-token-untracked", http.StatusOK},
+		{"guest-token-active", http.StatusOK},
+		{"guest-token-overdue", http.StatusUnauthorized},
+		{"guest-token-expired", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/api/x", nil)
+		r.Header.Set("Authorization", "Bearer "+tt.token)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		if w.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.token, w.Code, tt.want)
+		}
+	}
+
+	if _, err := ResolveToken(identityDB, "guest-token-expired"); !errors.Is(err, ErrSessionExpired) {
+		t.Errorf("ResolveToken() error = %v, want ErrSessionExpired", err)
+	}
+}
+
+// sessionActivityDB is an identity DB holding only session_activity rows
+// (expired, idle seconds, timeout seconds by token hash) - enough for
+// ResolveToken on guest tokens, which need nothing else
+func sessionActivityDB(rows map[string][]driver.Value) *sql.DB {
+	return sql.OpenDB(sessionActivityConnector{rows})
+}
+
+type sessionActivityConnector struct{ rows map[string][]driver.Value }
+
+func (c sessionActivityConnector) Connect(context.Context) (driver.Conn, error) {
+	return sessionActivityConn(c), nil
+}
+
+func (c sessionActivityConnector) Driver() driver.Driver { return nil }
+
+type sessionActivityConn sessionActivityConnector
+
+func (c sessionActivityConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if !strings.Contains(query, "FROM session_activity") || len(args) != 1 {
+		return nil, fmt.Errorf("unexpected query: %s", query)
+	}
+	row, ok := c.rows[args[0].Value.(string)]
+	return &sessionActivityRows{row: row, done: !ok}, nil
+}
+
+func (c sessionActivityConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+func (c sessionActivityConn) Close() error              { return nil }
+func (c sessionActivityConn) Begin() (driver.Tx, error) { return nil, errors.New("not supported") }
+
+type sessionActivityRows struct {
+	row  []driver.Value
+	done bool
+}
+
+func (r *sessionActivityRows) Columns() []string { return []string{"expired", "idle", "timeout"} }
+func (r *sessionActivityRows) Close() error      { return nil }
+func (r *sessionActivityRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	copy(dest, r.row)
+	r.done = true
+	return nil
+}
+
 // Integration tests (require PostgreSQL)
 // Run with: go test -tags=integration ./...
 
@@ -0,0 +1,137 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/achgithub/activity-hub-common/config"
+	"github.com/achgithub/activity-hub-common/i18n"
+)
+
+// Cookie sessions are an opt-in alternative to bearer tokens for browser flows:
+// identity-shell sets the session token in an HttpOnly, SameSite cookie, so page
+// scripts never hold it. Cookies are not port-scoped, so every app on the host
+// receives it. Because the browser attaches the cookie on its own, state-changing
+// requests authenticated by it must also carry a CSRF token (double-submit: the
+// readable CSRF cookie echoed back in the X-CSRF-Token header). The CSRF token is
+// an HMAC of the session token, so it cannot be forged for another session.
+const (
+	SessionCookieName = "ah_session"
+	CSRFCookieName    = "ah_csrf"
+	CSRFHeaderName    = "X-CSRF-Token"
+)
+
+// SessionCookieTTL is how long a cookie session lasts without logging in again
+const SessionCookieTTL = 30 * 24 * time.Hour
+
+// cookieSecure marks cookies HTTPS-only; the Pi deployment serves plain HTTP on the LAN
+func cookieSecure() bool {
+	return config.GetEnv("AUTH_COOKIE_SECURE", "") == "true"
+}
+
+// CSRFToken derives the CSRF token for a session token. Without a signing key
+// (outside development) there is none, and cookie sessions are refused.
+func CSRFToken(sessionToken string) (string, error) {
+	return sign("csrf:" + sessionToken)
+}
+
+// SetSessionCookies starts a cookie session: the HttpOnly session cookie plus
+// the readable CSRF cookie the frontend echoes back. No cookie is set if the
+// CSRF token can't be issued.
+//
+// Usage:
+//
+//	if err := auth.SetSessionCookies(w, token); err != nil { ... }
+func SetSessionCookies(w http.ResponseWriter, sessionToken string) error {
+	csrf, err := CSRFToken(sessionToken)
+	if err != nil {
+		return err
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookieName,
+		Value:    sessionToken,
+		Path:     "/",
+		MaxAge:   int(SessionCookieTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   cookieSecure(),
+		SameSite: http.SameSiteLaxMode,
+	})
+	setCSRFCookie(w, csrf)
+	return nil
+}
+
+// IssueCSRFToken (re)sets the CSRF cookie for a session and returns its value.
+func IssueCSRFToken(w http.ResponseWriter, sessionToken string) (string, error) {
+	token, err := CSRFToken(sessionToken)
+	if err != nil {
+		return "", err
+	}
+	setCSRFCookie(w, token)
+	return token, nil
+}
+
+func setCSRFCookie(w http.ResponseWriter, token string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     CSRFCookieName,
+		Value:    token,
+		Path:     "/",
+		MaxAge:   int(SessionCookieTTL.Seconds()),
+		Secure:   cookieSecure(),
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+// ClearSessionCookies ends a cookie session.
+func ClearSessionCookies(w http.ResponseWriter) {
+	for _, name := range []string{SessionCookieName, CSRFCookieName} {
+		http.SetCookie(w, &http.Cookie{Name: name, Value: "", Path: "/", MaxAge: -1})
+	}
+}
+
+// SessionToken returns the request's session token: the bearer token if
+// present, otherwise the session cookie (fromCookie = true).
+func SessionToken(r *http.Request) (token string, fromCookie bool) {
+	if authHeader := r.Header.Get("Authorization"); strings.HasPrefix(authHeader, "Bearer ") {
+		return strings.TrimPrefix(authHeader, "Bearer "), false
+	}
+	if cookie, err := r.Cookie(SessionCookieName); err == nil && cookie.Value != "" {
+		return cookie.Value, true
+	}
+	return "", false
+}
+
+// ValidCSRF reports whether the request carries the CSRF token for sessionToken.
+// Safe methods (GET, HEAD, OPTIONS) always pass; anything else fails when no
+// signing key is available.
+func ValidCSRF(r *http.Request, sessionToken string) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	want, err := CSRFToken(sessionToken)
+	if err != nil {
+		return false
+	}
+	header := r.Header.Get(CSRFHeaderName)
+	return header != "" && hmac.Equal([]byte(header), []byte(want))
+}
+
+// CSRFMiddleware rejects state-changing requests authenticated by the session
+// cookie that lack a valid CSRF token. Bearer-token requests pass through, since
+// a browser never attaches those on its own. Middleware applies the same check,
+// so this is only needed on routes that read the cookie themselves.
+//
+// Usage:
+//
+//	r.Handle("/api/logout", auth.CSRFMiddleware(http.HandlerFunc(handleLogout)))
+func CSRFMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token, fromCookie := SessionToken(r); fromCookie && !ValidCSRF(r, token) {
+			http.Error(w, i18n.T(r, "auth_csrf"), http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Inactivity timeout: identity-shell logs out sessions nobody has used for
+// SESSION_IDLE_TIMEOUT_SECONDS. It tracks each session in the identity DB
+// session_activity table (last use, the timeout it runs under and when it was
+// expired), and ResolveToken checks that table so every backend refuses an
+// expired token, not just the shell. Tokens without a row - the timeout is
+// off, or the session predates it - are unaffected.
+
+// ErrSessionExpired is returned for a session logged out after inactivity
+var ErrSessionExpired = errors.New("session expired after inactivity")
+
+// HashSessionToken returns the form a session token is tracked in.
+func HashSessionToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CheckSessionActivity returns ErrSessionExpired if the token's session has
+// been expired, or has been idle past its timeout and the shell's expiry loop
+// hasn't reached it yet.
+func CheckSessionActivity(identityDB *sql.DB, token string) error {
+	var expired bool
+	var idleSeconds float64
+	var timeoutSeconds int64
+	err := identityDB.QueryRow(`
+		SELECT expired_at IS NOT NULL, EXTRACT(EPOCH FROM NOW() - last_seen_at), idle_timeout_seconds
+		FROM session_activity
+		WHERE token_hash = $1
+	`, HashSessionToken(token)).Scan(&expired, &idleSeconds, &timeoutSeconds)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("session activity lookup: %w", err)
+	}
+
+	idle := time.Duration(idleSeconds * float64(time.Second))
+	if expired || idle > time.Duration(timeoutSeconds)*time.Second {
+		return ErrSessionExpired
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/achgithub/activity-hub-common/i18n"
+	"github.com/lib/pq"
+)
+
+// Kiosk tokens are long-lived tokens for unattended devices - a leaderboard
+// TV, a quiz display, the dice screen on the bar. setup-admin issues them with
+// a set of capabilities and the endpoints those allow; Middleware and
+// SSEMiddleware accept them only on those endpoints, so a token left on a
+// device can't be used for anything else. They are never a user: ResolveToken
+// rejects them.
+//
+// Token format: kiosk-{random}. Only its SHA-256 is stored (kiosk_tokens).
+const KioskTokenPrefix = "kiosk-"
+
+// KioskGrant is what a kiosk token allows
+type KioskGrant struct {
+	ID           int
+	Capabilities []string // e.g. "leaderboard:view", "quiz:display", "dice:room"
+	Endpoints    []string // "METHOD /path", a trailing * matches any rest of the path
+}
+
+// Can reports whether the kiosk was issued a capability.
+func (k *KioskGrant) Can(capability string) bool {
+	for _, c := range k.Capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// Allows reports whether a request may be made with the kiosk token.
+func (k *KioskGrant) Allows(method, path string) bool {
+	for _, e := range k.Endpoints {
+		m, pattern, ok := strings.Cut(e, " ")
+		if !ok || !strings.EqualFold(m, method) {
+			continue
+		}
+		if prefix, wild := strings.CutSuffix(pattern, "*"); wild {
+			if strings.HasPrefix(path, prefix) {
+				return true
+			}
+		} else if path == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// IsKioskToken reports whether a token is a kiosk token rather than a user's.
+func IsKioskToken(token string) bool {
+	return strings.HasPrefix(token, KioskTokenPrefix)
+}
+
+// HashKioskToken returns the form a kiosk token is stored and looked up in.
+func HashKioskToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// ResolveKioskToken looks up a kiosk token. The user it returns is the
+// device (email kiosk-{id}, named by its label) with Kiosk set; check
+// Kiosk.Allows before serving it, as the middlewares do.
+func ResolveKioskToken(identityDB *sql.DB, token string) (*AuthUser, error) {
+	if !IsKioskToken(token) {
+		return nil, fmt.Errorf("not a kiosk token")
+	}
+
+	grant := KioskGrant{}
+	user := AuthUser{Roles: []string{}, Kiosk: &grant}
+	err := identityDB.QueryRow(`
+		SELECT id, label, COALESCE(venue_id, 0), capabilities, endpoints
+		FROM kiosk_tokens
+		WHERE token_hash = $1 AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > NOW())
+	`, HashKioskToken(token)).Scan(&grant.ID, &user.Name, &user.VenueID, pq.Array(&grant.Capabilities), pq.Array(&grant.Endpoints))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("invalid, expired or revoked kiosk token")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("kiosk token lookup: %w", err)
+	}
+	user.Email = fmt.Sprintf("kiosk-%d", grant.ID)
+
+	// Kiosks poll, so last use is only recorded every few minutes
+	identityDB.Exec(`
+		UPDATE kiosk_tokens SET last_used_at = NOW()
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '5 minutes')
+	`, grant.ID)
+	return &user, nil
+}
+
+// kioskAllowed writes a 403 and returns false if a kiosk user is outside the
+// endpoints its token was issued for. People always pass.
+func kioskAllowed(w http.ResponseWriter, r *http.Request, user *AuthUser) bool {
+	if user.Kiosk == nil || user.Kiosk.Allows(r.Method, r.URL.Path) {
+		return true
+	}
+	log.Printf("❌ Kiosk %s (%s) not allowed %s %s", user.Email, user.Name, r.Method, r.URL.Path)
+	http.Error(w, i18n.T(r, "kiosk_forbidden"), http.StatusForbidden)
+	return false
+}
+
+// RequireKioskCapability returns a middleware that lets a kiosk through only
+// if it was issued the capability, so a token given extra endpoints can't use
+// an app's kiosk routes without it. People pass. Must be used after
+// Middleware, OptionalMiddleware or SSEMiddleware.
+//
+// Usage:
+//
+//	kiosk := auth.RequireKioskCapability("leaderboard:view")
+//	r.Handle("/api/standings", authMiddleware(kiosk(http.HandlerFunc(handleStandings))))
+func RequireKioskCapability(capability string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, ok := GetUserFromContext(r.Context())
+			if ok && user.Kiosk != nil && !user.Kiosk.Can(capability) {
+				log.Printf("❌ Kiosk %s (%s) lacks %s for %s %s", user.Email, user.Name, capability, r.Method, r.URL.Path)
+				http.Error(w, i18n.T(r, "kiosk_forbidden"), http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -8,10 +8,14 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/achgithub/activity-hub-common/i18n"
 	"github.com/lib/pq"
 )
 
 // Middleware validates a demo-token or impersonate- token and sets user in context.
+// Kiosk tokens are accepted too, but only on the endpoints they were issued for.
+// The token comes from the Authorization header or, in cookie session mode, the
+// session cookie - in which case unsafe methods must also pass the CSRF check.
 // Returns func(http.Handler) http.Handler for use with gorilla/mux router.Use().
 //
 // Usage:
@@ -21,29 +25,89 @@ import (
 func Middleware(identityDB *sql.DB) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			authHeader := r.Header.Get("Authorization")
-			if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
-				http.Error(w, "Missing or invalid authorization", http.StatusUnauthorized)
+			token, fromCookie := SessionToken(r)
+			if token == "" {
+				http.Error(w, i18n.T(r, "auth_missing"), http.StatusUnauthorized)
+				return
+			}
+			if fromCookie && !ValidCSRF(r, token) {
+				log.Printf("❌ CSRF check failed for %s %s", r.Method, r.URL.Path)
+				http.Error(w, i18n.T(r, "auth_csrf"), http.StatusForbidden)
 				return
 			}
 
-			token := strings.TrimPrefix(authHeader, "Bearer ")
-			user, err := ResolveToken(identityDB, token)
+			var user *AuthUser
+			var err error
+			if IsKioskToken(token) {
+				user, err = ResolveKioskToken(identityDB, token)
+			} else {
+				user, err = ResolveToken(identityDB, token)
+			}
 			if err != nil {
 				log.Printf("❌ Auth failed for %s %s: %v", r.Method, r.URL.Path, err)
-				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				http.Error(w, i18n.T(r, "unauthorized"), http.StatusUnauthorized)
+				return
+			}
+			if !kioskAllowed(w, r, user) {
 				return
 			}
 
 			log.Printf("✅ Authenticated: %s (impersonating=%v)", user.Email, user.IsImpersonating)
-			ctx := context.WithValue(r.Context(), userContextKey, *user)
-			next.ServeHTTP(w, r.WithContext(ctx))
+			if user.IsImpersonating {
+				recordImpersonationActivity(identityDB, user, r)
+			}
+			next.ServeHTTP(w, r.WithContext(withUser(r.Context(), user)))
+		})
+	}
+}
+
+// OptionalMiddleware is Middleware for apps that work signed out too (utility
+// micro-apps such as the dice roller). A valid token sets the user in context
+// as Middleware does; a missing, invalid or expired token, a failed CSRF check
+// or a kiosk token off its endpoints lets the request through anonymously, so
+// handlers check GetUserFromContext's ok.
+//
+// Usage:
+//
+//	api.Use(auth.OptionalMiddleware(identityDB))
+func OptionalMiddleware(identityDB *sql.DB) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, fromCookie := SessionToken(r)
+			if token == "" || (fromCookie && !ValidCSRF(r, token)) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			var user *AuthUser
+			var err error
+			if IsKioskToken(token) {
+				user, err = ResolveKioskToken(identityDB, token)
+			} else {
+				user, err = ResolveToken(identityDB, token)
+			}
+			if err != nil {
+				log.Printf("⚠️  Optional auth ignored token for %s %s: %v", r.Method, r.URL.Path, err)
+				next.ServeHTTP(w, r)
+				return
+			}
+			if user.Kiosk != nil && !user.Kiosk.Allows(r.Method, r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if user.IsImpersonating {
+				recordImpersonationActivity(identityDB, user, r)
+			}
+			next.ServeHTTP(w, r.WithContext(withUser(r.Context(), user)))
 		})
 	}
 }
 
 // SSEMiddleware validates a token from the query parameter (for EventSource compatibility).
-// EventSource does not support custom headers so the token must be in the URL.
+// EventSource does not support custom headers so the token must be in the URL; clients
+// should fetch a single-use stream token from identity-shell (POST /api/auth/stream-token)
+// for each connection. Session tokens are still accepted for now but logged as deprecated.
 //
 // Usage:
 //
@@ -53,20 +117,35 @@ func SSEMiddleware(identityDB *sql.DB) func(http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			token := r.URL.Query().Get("token")
 			if token == "" {
-				http.Error(w, "Missing authorization token", http.StatusUnauthorized)
+				http.Error(w, i18n.T(r, "auth_missing"), http.StatusUnauthorized)
 				return
 			}
 
-			user, err := ResolveToken(identityDB, token)
+			var user *AuthUser
+			var err error
+			if IsSignedToken(token) {
+				user, err = ConsumeSignedToken(identityDB, token, PurposeStream)
+			} else if IsKioskToken(token) {
+				user, err = ResolveKioskToken(identityDB, token)
+			} else {
+				log.Printf("⚠️  Deprecated: session token in SSE URL for %s - use a stream token", r.URL.Path)
+				user, err = ResolveToken(identityDB, token)
+			}
 			if err != nil {
 				log.Printf("❌ SSE auth failed for %s: %v", r.URL.Path, err)
-				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				http.Error(w, i18n.T(r, "unauthorized"), http.StatusUnauthorized)
+				return
+			}
+
+			if !kioskAllowed(w, r, user) {
 				return
 			}
 
 			log.Printf("✅ SSE authenticated: %s", user.Email)
-			ctx := context.WithValue(r.Context(), userContextKey, *user)
-			next.ServeHTTP(w, r.WithContext(ctx))
+			if user.IsImpersonating {
+				recordImpersonationActivity(identityDB, user, r)
+			}
+			next.ServeHTTP(w, r.WithContext(withUser(r.Context(), user)))
 		})
 	}
 }
@@ -83,16 +162,52 @@ func RequireRole(role string) func(http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			user, ok := GetUserFromContext(r.Context())
 			if !ok {
-				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				http.Error(w, i18n.T(r, "unauthorized"), http.StatusUnauthorized)
 				return
 			}
 
 			if !user.HasRole(role) {
 				log.Printf("❌ RequireRole(%s): user %s missing role", role, user.Email)
+				http.Error(w, i18n.T(r, "forbidden"), http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ServiceMiddleware accepts only service tokens (MintServiceToken) from the
+// listed apps, for routes other backends call as themselves rather than for
+// a user. The calling app is passed on in the X-Service-App header.
+//
+// Usage:
+//
+//	r.Handle("/api/digest", auth.ServiceMiddleware(services.ShellApp)(http.HandlerFunc(handleDigest)))
+func ServiceMiddleware(apps ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			appID, err := VerifyServiceToken(token)
+			if err != nil {
+				http.Error(w, "Unauthorized - service token required", http.StatusUnauthorized)
+				return
+			}
+
+			allowed := false
+			for _, app := range apps {
+				if app == appID {
+					allowed = true
+					break
+				}
+			}
+			if !allowed {
+				log.Printf("❌ ServiceMiddleware: %s may not call %s", appID, r.URL.Path)
 				http.Error(w, "Forbidden", http.StatusForbidden)
 				return
 			}
 
+			r.Header.Set("X-Service-App", appID)
 			next.ServeHTTP(w, r)
 		})
 	}
@@ -104,13 +219,13 @@ func AdminMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		user, ok := GetUserFromContext(r.Context())
 		if !ok {
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			http.Error(w, i18n.T(r, "unauthorized"), http.StatusUnauthorized)
 			return
 		}
 
 		if !user.IsAdmin {
 			log.Printf("❌ AdminMiddleware: user %s is not admin", user.Email)
-			http.Error(w, "Forbidden: admin access required", http.StatusForbidden)
+			http.Error(w, i18n.T(r, "admin_required"), http.StatusForbidden)
 			return
 		}
 
@@ -129,8 +244,18 @@ func GetUserFromContext(ctx context.Context) (*AuthUser, bool) {
 	return &user, true
 }
 
+// withUser puts the user in ctx, with their locale for i18n
+func withUser(ctx context.Context, user *AuthUser) context.Context {
+	if user.Locale != "" {
+		ctx = i18n.WithLocale(ctx, user.Locale)
+	}
+	return context.WithValue(ctx, userContextKey, *user)
+}
+
 // ResolveToken validates a token and returns the associated user.
-// Supports demo-token-{email}, guest-token-{uuid}, and impersonate-{uuid} formats.
+// Supports demo-token-{email}, guest-token-{uuid}, impersonate-{uuid}, and the
+// table-token-{key} and table-player-token-{key} formats of table devices.
+// Sessions logged out after inactivity fail with ErrSessionExpired.
 // This is the centralized token validation function - all token parsing must go through here.
 func ResolveToken(identityDB *sql.DB, token string) (*AuthUser, error) {
 	if token == "" {
@@ -142,6 +267,14 @@ func ResolveToken(identityDB *sql.DB, token string) (*AuthUser, error) {
 		return nil, fmt.Errorf("token exceeds maximum length")
 	}
 
+	if IsKioskToken(token) {
+		return nil, fmt.Errorf("kiosk tokens are not user tokens")
+	}
+
+	if err := CheckSessionActivity(identityDB, token); err != nil {
+		return nil, err
+	}
+
 	if strings.HasPrefix(token, "impersonate-") {
 		var impersonatedEmail, superUserEmail string
 		err := identityDB.QueryRow(`
@@ -176,6 +309,14 @@ func ResolveToken(identityDB *sql.DB, token string) (*AuthUser, error) {
 		}, nil
 	}
 
+	if strings.HasPrefix(token, TablePlayerTokenPrefix) {
+		return lookupTableUser(identityDB, TablePlayerEmailPrefix+strings.TrimPrefix(token, TablePlayerTokenPrefix))
+	}
+
+	if strings.HasPrefix(token, TableTokenPrefix) {
+		return lookupTableUser(identityDB, TableEmailPrefix+strings.TrimPrefix(token, TableTokenPrefix))
+	}
+
 	if strings.HasPrefix(token, "demo-token-") {
 		email := strings.TrimPrefix(token, "demo-token-")
 		return lookupUser(identityDB, email)
@@ -184,16 +325,32 @@ func ResolveToken(identityDB *sql.DB, token string) (*AuthUser, error) {
 	return nil, fmt.Errorf("unrecognized token format")
 }
 
-// lookupUser fetches user details and roles from the identity database.
+// recordImpersonationActivity writes a row to the global impersonation activity log
+// so super_user actions are traceable across every backend, not just identity-shell.
+// Failures are logged and never block the request.
+func recordImpersonationActivity(identityDB *sql.DB, user *AuthUser, r *http.Request) {
+	_, err := identityDB.Exec(`
+		INSERT INTO impersonation_activity (super_user_email, impersonated_email, service_host, method, path)
+		VALUES ($1, $2, $3, $4, $5)
+	`, user.ImpersonatedBy, user.Email, r.Host, r.Method, r.URL.Path)
+	if err != nil {
+		log.Printf("⚠️  Failed to record impersonation activity: %v", err)
+	}
+}
+
+// lookupUser fetches user details, roles and venue from the identity database.
 func lookupUser(identityDB *sql.DB, email string) (*AuthUser, error) {
 	var user AuthUser
 	var roles []string
+	var isActive bool
 
 	err := identityDB.QueryRow(`
-		SELECT email, name, is_admin, COALESCE(roles, '{}')
-		FROM users
-		WHERE email = $1
-	`, email).Scan(&user.Email, &user.Name, &user.IsAdmin, pq.Array(&roles))
+		SELECT u.email, u.name, u.is_admin, COALESCE(u.roles, '{}'), COALESCE(u.is_active, TRUE), COALESCE(u.venue_id, 0),
+		       COALESCE(loc.value #>> '{}', '')
+		FROM users u
+		LEFT JOIN user_settings loc ON loc.user_email = u.email AND loc.key = 'locale'
+		WHERE u.email = $1
+	`, email).Scan(&user.Email, &user.Name, &user.IsAdmin, pq.Array(&roles), &isActive, &user.VenueID, &user.Locale)
 
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("user not found: %s", email)
@@ -201,6 +358,9 @@ func lookupUser(identityDB *sql.DB, email string) (*AuthUser, error) {
 	if err != nil {
 		return nil, fmt.Errorf("user lookup: %w", err)
 	}
+	if !isActive {
+		return nil, fmt.Errorf("user deactivated: %s", email)
+	}
 
 	user.Roles = roles
 	return &user, nil
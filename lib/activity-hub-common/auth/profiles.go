@@ -0,0 +1,125 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// Profile is the public-facing identity of a user: what other players see
+// in lobbies, leaderboards and quizzes instead of a raw email address.
+type Profile struct {
+	Email     string `json:"email"`
+	Name      string `json:"name"`
+	Nickname  string `json:"nickname,omitempty"`
+	Flair     string `json:"flair,omitempty"`     // single emoji shown next to the name
+	AvatarURL string `json:"avatarUrl,omitempty"` // served by identity-shell
+	Anonymous bool   `json:"anonymous,omitempty"` // privacy_anonymous setting
+	Alias     string `json:"-"`                   // privacy_alias setting
+}
+
+// DisplayName returns the nickname if set, otherwise the account name.
+func (p Profile) DisplayName() string {
+	if p.Nickname != "" {
+		return p.Nickname
+	}
+	return p.Name
+}
+
+// PublicName is the name to show on public surfaces (leaderboards, TV displays).
+// Users who opted into anonymity appear under their alias.
+func (p Profile) PublicName() string {
+	if !p.Anonymous {
+		return p.DisplayName()
+	}
+	if p.Alias != "" {
+		return p.Alias
+	}
+	return AnonymousAlias(p.Email)
+}
+
+// Public returns a copy safe to show to other players: anonymous users lose
+// their email, avatar and flair and are named by alias.
+func (p Profile) Public() Profile {
+	if !p.Anonymous {
+		return p
+	}
+	return Profile{Name: p.PublicName(), Anonymous: true}
+}
+
+// AnonymousAlias returns a stable, non-reversible placeholder name for an email.
+func AnonymousAlias(email string) string {
+	sum := sha256.Sum256([]byte(email))
+	return "Player " + hex.EncodeToString(sum[:2])
+}
+
+// AvatarPath returns the identity-shell URL path for an avatar content hash.
+func AvatarPath(hash string) string {
+	if hash == "" {
+		return ""
+	}
+	return "/api/avatars/" + hash
+}
+
+// LoadProfiles fetches profiles for a set of emails in one query.
+// Emails with no account (e.g. guests) are omitted from the result; players on
+// a table device get the name and flair they chose on it.
+//
+// Usage:
+//
+//	profiles, err := auth.LoadProfiles(identityDB, []string{"a@x.com", "b@x.com"})
+//	name := profiles["a@x.com"].DisplayName()
+func LoadProfiles(identityDB *sql.DB, emails []string) (map[string]Profile, error) {
+	profiles := make(map[string]Profile, len(emails))
+	if len(emails) == 0 {
+		return profiles, nil
+	}
+
+	rows, err := identityDB.Query(`
+		SELECT u.email, u.name, COALESCE(p.nickname, ''), COALESCE(p.flair, ''), COALESCE(p.avatar_hash, ''),
+		       COALESCE(anon.value = 'true'::jsonb, FALSE), COALESCE(alias.value #>> '{}', '')
+		FROM users u
+		LEFT JOIN user_profiles p ON p.user_email = u.email
+		LEFT JOIN user_settings anon ON anon.user_email = u.email AND anon.key = 'privacy_anonymous'
+		LEFT JOIN user_settings alias ON alias.user_email = u.email AND alias.key = 'privacy_alias'
+		WHERE u.email = ANY($1)
+		UNION ALL
+		SELECT 'table-player-' || t.player_key::text, t.name, '', COALESCE(t.flair, ''), '', FALSE, ''
+		FROM table_players t
+		WHERE 'table-player-' || t.player_key::text = ANY($1)
+	`, pq.Array(emails))
+	if err != nil {
+		return nil, fmt.Errorf("profile lookup: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p Profile
+		var avatarHash string
+		if err := rows.Scan(&p.Email, &p.Name, &p.Nickname, &p.Flair, &avatarHash, &p.Anonymous, &p.Alias); err != nil {
+			return nil, fmt.Errorf("profile scan: %w", err)
+		}
+		p.AvatarURL = AvatarPath(avatarHash)
+		profiles[p.Email] = p
+	}
+
+	return profiles, rows.Err()
+}
+
+// PublicNames maps each email to the name to show on public surfaces.
+// Emails with no account, or all of them if the lookup fails, get AnonymousAlias.
+func PublicNames(identityDB *sql.DB, emails []string) map[string]string {
+	profiles, _ := LoadProfiles(identityDB, emails)
+	names := make(map[string]string, len(emails))
+	for _, email := range emails {
+		if p, ok := profiles[email]; ok {
+			names[email] = p.PublicName()
+		} else {
+			names[email] = AnonymousAlias(email)
+		}
+	}
+	return names
+}
@@ -0,0 +1,235 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/achgithub/activity-hub-common/config"
+	apphttp "github.com/achgithub/activity-hub-common/http"
+)
+
+// Signed tokens stand in for the user's bearer token wherever a credential has
+// to travel in a URL: EventSource streams and the shell's hand-off to mini-apps.
+// They are minted by identity-shell, expire within minutes and are consumed on
+// first use, so a URL copied from a log, browser history or TV is worthless.
+const (
+	PurposeStream  = "stream"  // EventSource connections (SSEMiddleware)
+	PurposeLaunch  = "launch"  // Shell hand-off, exchanged for the session token
+	PurposeService = "service" // Backend-to-backend calls made on no user's behalf
+)
+
+// Lifetimes: long enough to open the stream or load the app, no longer
+const (
+	StreamTokenTTL  = time.Minute
+	LaunchTokenTTL  = 2 * time.Minute
+	ServiceTokenTTL = 5 * time.Minute
+)
+
+const signedTokenPrefix = "st1."
+
+// devSigningKey is only for local development (ACTIVITY_HUB_ENV=development);
+// every backend on a shared deployment must be given the same AUTH_SIGNING_KEY.
+// The key is public, so anywhere else tokens are neither minted nor accepted
+// without a configured one.
+const devSigningKey = "activity-hub-dev-signing-key"
+
+var errNoSigningKey = fmt.Errorf("AUTH_SIGNING_KEY is not set")
+
+var warnDevKey sync.Once
+
+type signedClaims struct {
+	Email   string `json:"sub"`
+	Actor   string `json:"act,omitempty"` // super_user behind an impersonated session
+	Purpose string `json:"pur"`
+	ID      string `json:"jti"`
+	Expires int64  `json:"exp"` // Unix seconds
+}
+
+func signingKey() ([]byte, error) {
+	if key := config.GetEnv("AUTH_SIGNING_KEY", ""); key != "" {
+		return []byte(key), nil
+	}
+	if apphttp.Environment() != "development" {
+		return nil, errNoSigningKey
+	}
+	warnDevKey.Do(func() {
+		log.Printf("⚠️  AUTH_SIGNING_KEY not set - using the development signing key")
+	})
+	return []byte(devSigningKey), nil
+}
+
+func sign(payload string) (string, error) {
+	key, err := signingKey()
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+// sealClaims encodes and signs claims as a signed token.
+func sealClaims(claims signedClaims) (string, error) {
+	data, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal token claims: %w", err)
+	}
+	payload := base64.RawURLEncoding.EncodeToString(data)
+	sig, err := sign(payload)
+	if err != nil {
+		return "", err
+	}
+	return signedTokenPrefix + payload + "." + sig, nil
+}
+
+// IsSignedToken reports whether a token is a signed stream/launch token
+// rather than a session token.
+func IsSignedToken(token string) bool {
+	return strings.HasPrefix(token, signedTokenPrefix)
+}
+
+// MintSignedToken issues a single-use token for the user, valid for ttl.
+//
+// Usage:
+//
+//	token, err := auth.MintSignedToken(user, auth.PurposeStream, auth.StreamTokenTTL)
+func MintSignedToken(user *AuthUser, purpose string, ttl time.Duration) (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("failed to generate token id: %w", err)
+	}
+	claims := signedClaims{
+		Email:   user.Email,
+		Purpose: purpose,
+		ID:      hex.EncodeToString(id),
+		Expires: time.Now().Add(ttl).Unix(),
+	}
+	if user.IsImpersonating {
+		claims.Actor = user.ImpersonatedBy
+	}
+	return sealClaims(claims)
+}
+
+// verifySignedToken checks signature, expiry and purpose without consuming the token.
+func verifySignedToken(token, purpose string) (signedClaims, error) {
+	var claims signedClaims
+	if !IsSignedToken(token) {
+		return claims, fmt.Errorf("not a signed token")
+	}
+	payload, sig, ok := strings.Cut(strings.TrimPrefix(token, signedTokenPrefix), ".")
+	if !ok {
+		return claims, fmt.Errorf("invalid token signature")
+	}
+	want, err := sign(payload)
+	if err != nil {
+		return claims, err
+	}
+	if !hmac.Equal([]byte(sig), []byte(want)) {
+		return claims, fmt.Errorf("invalid token signature")
+	}
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return claims, fmt.Errorf("malformed token payload")
+	}
+	if err := json.Unmarshal(data, &claims); err != nil {
+		return claims, fmt.Errorf("malformed token claims")
+	}
+	if claims.Purpose != purpose {
+		return claims, fmt.Errorf("token is for %q, not %q", claims.Purpose, purpose)
+	}
+	if time.Now().Unix() > claims.Expires {
+		return claims, fmt.Errorf("token expired")
+	}
+	return claims, nil
+}
+
+// ConsumeSignedToken verifies a signed token, marks it used and returns its user.
+// A second use of the same token fails, on any backend sharing the identity DB.
+func ConsumeSignedToken(identityDB *sql.DB, token, purpose string) (*AuthUser, error) {
+	claims, err := verifySignedToken(token, purpose)
+	if err != nil {
+		return nil, err
+	}
+
+	identityDB.Exec(`DELETE FROM signed_token_uses WHERE expires_at < NOW()`)
+	res, err := identityDB.Exec(`
+		INSERT INTO signed_token_uses (token_id, user_email, purpose, expires_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (token_id) DO NOTHING
+	`, claims.ID, claims.Email, claims.Purpose, time.Unix(claims.Expires, 0))
+	if err != nil {
+		return nil, fmt.Errorf("token use lookup: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, fmt.Errorf("token already used")
+	}
+	return signedTokenUser(identityDB, claims)
+}
+
+// PeekSignedToken verifies a signed token and returns its user without
+// consuming it, for checks made before the handler that will (the maintenance
+// middleware, say). A token that has already been used still passes.
+func PeekSignedToken(identityDB *sql.DB, token, purpose string) (*AuthUser, error) {
+	claims, err := verifySignedToken(token, purpose)
+	if err != nil {
+		return nil, err
+	}
+	return signedTokenUser(identityDB, claims)
+}
+
+func signedTokenUser(identityDB *sql.DB, claims signedClaims) (*AuthUser, error) {
+	if strings.HasPrefix(claims.Email, "guest-") {
+		return &AuthUser{Email: claims.Email, Name: "Guest", Roles: []string{}}, nil
+	}
+	if IsTableEmail(claims.Email) {
+		return lookupTableUser(identityDB, claims.Email)
+	}
+	user, err := lookupUser(identityDB, claims.Email)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Actor != "" {
+		user.IsImpersonating = true
+		user.ImpersonatedBy = claims.Actor
+	}
+	return user, nil
+}
+
+// MintServiceToken issues a token a backend sends when it calls another
+// backend as itself rather than for a user, e.g. a game marking its players
+// as in a game. The app ID is carried as the token's subject. Unlike stream
+// and launch tokens it isn't consumed: a backend reuses one until it expires.
+func MintServiceToken(appID string, ttl time.Duration) (string, error) {
+	if appID == "" {
+		return "", fmt.Errorf("app ID is required")
+	}
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("failed to generate token id: %w", err)
+	}
+	return sealClaims(signedClaims{
+		Email:   appID,
+		Purpose: PurposeService,
+		ID:      hex.EncodeToString(id),
+		Expires: time.Now().Add(ttl).Unix(),
+	})
+}
+
+// VerifyServiceToken checks a service token and returns the app that sent it.
+func VerifyServiceToken(token string) (string, error) {
+	claims, err := verifySignedToken(token, PurposeService)
+	if err != nil {
+		return "", err
+	}
+	return claims.Email, nil
+}
@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// Table devices are shared tablets, one per pub table. Staff sign a tablet in
+// as a table, and the people sitting there add lightweight player profiles to
+// it. The table's own session only browses and picks a player; each player
+// has their own session, so quiz answers and game scores stay separate.
+//
+// Token formats:
+//
+//	table-token-{key}         the device session, user table-{key}
+//	table-player-token-{key}  a player on the device, user table-player-{key}
+const (
+	TableTokenPrefix       = "table-token-"
+	TablePlayerTokenPrefix = "table-player-token-"
+	TableEmailPrefix       = "table-"
+	TablePlayerEmailPrefix = "table-player-"
+)
+
+// IsTableEmail reports whether an email belongs to a table device or one of
+// its players rather than to an account.
+func IsTableEmail(email string) bool {
+	return strings.HasPrefix(email, TableEmailPrefix)
+}
+
+// IsTablePlayer reports whether the user is a player profile on a table device.
+func (u *AuthUser) IsTablePlayer() bool {
+	return strings.HasPrefix(u.Email, TablePlayerEmailPrefix)
+}
+
+// TableSessionToken returns the session token for a table device or player
+// email, or "" if the email isn't one.
+func TableSessionToken(email string) string {
+	if strings.HasPrefix(email, TablePlayerEmailPrefix) {
+		return TablePlayerTokenPrefix + strings.TrimPrefix(email, TablePlayerEmailPrefix)
+	}
+	if strings.HasPrefix(email, TableEmailPrefix) {
+		return TableTokenPrefix + strings.TrimPrefix(email, TableEmailPrefix)
+	}
+	return ""
+}
+
+// lookupTableUser resolves a table device or player email. Sessions on a
+// device that has been signed out no longer resolve.
+func lookupTableUser(identityDB *sql.DB, email string) (*AuthUser, error) {
+	user := AuthUser{Email: email, Roles: []string{}}
+	var err error
+	if strings.HasPrefix(email, TablePlayerEmailPrefix) {
+		err = identityDB.QueryRow(`
+			SELECT p.name, d.id, COALESCE(d.venue_id, 0)
+			FROM table_players p
+			JOIN table_devices d ON d.id = p.table_device_id
+			WHERE p.player_key::text = $1 AND d.ended_at IS NULL
+		`, strings.TrimPrefix(email, TablePlayerEmailPrefix)).Scan(&user.Name, &user.TableID, &user.VenueID)
+	} else {
+		err = identityDB.QueryRow(`
+			SELECT name, id, COALESCE(venue_id, 0)
+			FROM table_devices
+			WHERE device_key::text = $1 AND ended_at IS NULL
+		`, strings.TrimPrefix(email, TableEmailPrefix)).Scan(&user.Name, &user.TableID, &user.VenueID)
+	}
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("invalid or ended table session")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("table lookup: %w", err)
+	}
+	return &user, nil
+}
@@ -7,7 +7,11 @@ type AuthUser struct {
 	IsAdmin         bool
 	Roles           []string
 	IsImpersonating bool
-	ImpersonatedBy  string // email of the super_user who started the session
+	ImpersonatedBy  string      // email of the super_user who started the session
+	VenueID         int         // venue the user belongs to; 0 = chain-wide (all venues)
+	TableID         int         // table device the session is on; 0 = a personal session
+	Kiosk           *KioskGrant // set for kiosk tokens, which are devices rather than people
+	Locale          string      // the user's "locale" setting; "" follows the browser
 }
 
 // HasRole reports whether the user has the given role.
@@ -20,6 +24,22 @@ func (u *AuthUser) HasRole(role string) bool {
 	return false
 }
 
+// ActorEmail returns the email of the person actually making the request.
+// For impersonated sessions this is the super_user, otherwise the user themselves.
+// Use this when recording who performed an action in audit trails.
+func (u *AuthUser) ActorEmail() string {
+	if u.IsImpersonating && u.ImpersonatedBy != "" {
+		return u.ImpersonatedBy
+	}
+	return u.Email
+}
+
+// CanAccessVenue reports whether the user may see or manage data for a venue.
+// Chain-wide users (VenueID 0) and super_users can access every venue.
+func (u *AuthUser) CanAccessVenue(venueID int) bool {
+	return u.VenueID == 0 || u.VenueID == venueID || u.HasRole("super_user")
+}
+
 // Context key for storing authenticated user
 type contextKey string
 
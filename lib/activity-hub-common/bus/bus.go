@@ -0,0 +1,53 @@
+// Package bus carries events between backends on Redis Streams.
+//
+// Pub/sub only reaches subscribers that are connected when a message is sent,
+// so a backend that restarts misses everything published meanwhile. A stream
+// keeps its entries instead: each consuming service reads it through its own
+// consumer group and acknowledges what it has handled, and after a restart
+// carries on from its last acknowledged entry.
+//
+// Entries are the same events.Envelope that real-time streams send. The
+// events on each stream are declared in this package (see events.go) so
+// publishers and consumers share one definition.
+package bus
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/achgithub/activity-hub-common/events"
+	"github.com/redis/go-redis/v9"
+)
+
+// Streams
+const (
+	StreamChallenges      = "bus:challenges"       // Challenge lifecycle, published by the identity shell
+	StreamResults         = "bus:results"          // Finished games, published by game backends
+	StreamDisplayCommands = "bus:display-commands" // Remote commands for TVs, published by display admin
+)
+
+// MaxLen is roughly how many entries each stream keeps. Consumers that fall
+// further behind than this lose the oldest entries.
+const MaxLen = 10000
+
+// eventField is the stream entry field holding the encoded envelope
+const eventField = "event"
+
+// Publish appends an event to a stream and returns the entry ID.
+//
+// Usage:
+//
+//	busClient, _ := redis.InitRedis()
+//	_, err := bus.Publish(ctx, busClient, bus.StreamResults, bus.ResultReported.New(gameID, result))
+func Publish(ctx context.Context, client *redis.Client, stream string, e events.Envelope) (string, error) {
+	id, err := client.XAdd(ctx, &redis.XAddArgs{
+		Stream: stream,
+		MaxLen: MaxLen,
+		Approx: true,
+		Values: map[string]interface{}{eventField: e.Encode()},
+	}).Result()
+	if err != nil {
+		return "", fmt.Errorf("failed to publish %s to %s: %w", e.Type, stream, err)
+	}
+	return id, nil
+}
@@ -0,0 +1,94 @@
+package bus
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/achgithub/activity-hub-common/services"
+	"github.com/redis/go-redis/v9"
+)
+
+// Integration tests (require Redis on port 6379)
+// Run with: go test -tags=integration ./...
+
+// TODO: Add integration tests for replay after restart and claiming from a dead consumer
+
+func TestDecodeEntry(t *testing.T) {
+	result := services.Result{GameType: "dots", GameID: "g-1", WinnerID: "alice@x", LoserID: "bob@x", Score: "12-8"}
+	entry := redis.XMessage{
+		ID:     "1700000000000-0",
+		Values: map[string]interface{}{eventField: ResultReported.New("g-1", result).Encode()},
+	}
+
+	msg, err := decode(StreamResults, entry)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if msg.ID != entry.ID || msg.Stream != StreamResults || msg.Event.Session != "g-1" {
+		t.Errorf("Unexpected message %+v", msg)
+	}
+
+	got, err := ResultReported.Payload(msg.Event)
+	if err != nil {
+		t.Fatalf("Payload: %v", err)
+	}
+	if got != result {
+		t.Errorf("Payload = %+v, want %+v", got, result)
+	}
+
+	// The wrong event type for the payload is refused
+	if _, err := ChallengeIssued.Payload(msg.Event); err == nil {
+		t.Error("Expected an error reading a result as a challenge")
+	}
+}
+
+func TestDecodeRejectsBadEntries(t *testing.T) {
+	cases := map[string]redis.XMessage{
+		"trimmed":      {ID: "1-0"},
+		"wrong field":  {ID: "1-0", Values: map[string]interface{}{"payload": `{"type":"x","version":1}`}},
+		"not json":     {ID: "1-0", Values: map[string]interface{}{eventField: "hello"}},
+		"not envelope": {ID: "1-0", Values: map[string]interface{}{eventField: `{"foo":1}`}},
+	}
+	for name, entry := range cases {
+		if _, err := decode(StreamResults, entry); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestReadArgs(t *testing.T) {
+	got := readArgs([]string{StreamChallenges, StreamResults})
+	want := []string{StreamChallenges, StreamResults, ">", ">"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("readArgs = %v, want %v", got, want)
+	}
+}
+
+func TestSafeHandleRecoversPanics(t *testing.T) {
+	err := safeHandle(context.Background(), func(ctx context.Context, msg Message) error {
+		panic("boom")
+	}, Message{})
+	if err == nil || err.Error() != "panic: boom" {
+		t.Errorf("safeHandle = %v, want panic error", err)
+	}
+
+	failed := errors.New("db down")
+	if err := safeHandle(context.Background(), func(ctx context.Context, msg Message) error {
+		return failed
+	}, Message{}); err != failed {
+		t.Errorf("safeHandle = %v, want %v", err, failed)
+	}
+}
+
+func TestNewConsumerDefaults(t *testing.T) {
+	c := NewConsumer(nil, "leaderboard", func(ctx context.Context, msg Message) error { return nil }, StreamResults)
+	if c.Name == "" {
+		t.Error("Name should default to the host name")
+	}
+	if c.ClaimIdle != time.Minute || c.MaxDeliveries != 5 {
+		t.Errorf("Unexpected defaults: ClaimIdle %v, MaxDeliveries %d", c.ClaimIdle, c.MaxDeliveries)
+	}
+}
@@ -0,0 +1,268 @@
+package bus
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/achgithub/activity-hub-common/events"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	batchSize  = 20
+	readBlock  = 5 * time.Second // How long each read waits for new entries
+	retryDelay = 5 * time.Second // Pause after Redis errors
+)
+
+// Message is one stream entry delivered to a Handler
+type Message struct {
+	ID     string // Stream entry ID
+	Stream string
+	Event  events.Envelope
+}
+
+// Handler processes one message. Returning an error leaves the entry
+// unacknowledged so it is delivered again after ClaimIdle; handlers must
+// therefore be idempotent. Return nil for events that can never succeed
+// (unknown types, bad payloads) so they aren't retried.
+type Handler func(ctx context.Context, msg Message) error
+
+// Consumer reads streams as one member of a consumer group. Each service
+// uses its own group (usually its app ID), so every service sees every entry
+// while instances of the same service share the work.
+type Consumer struct {
+	client  *redis.Client
+	group   string
+	streams []string
+	handler Handler
+
+	// Name identifies this instance in the group. It defaults to the host
+	// name, so a restarted backend finds the entries it had been given but
+	// not acknowledged.
+	Name string
+
+	// ClaimIdle is how long an entry may stay unacknowledged (its handler
+	// failed, or its consumer went away) before it is delivered again.
+	ClaimIdle time.Duration
+
+	// MaxDeliveries is how many times an entry is delivered before it is
+	// dropped with an error in the log.
+	MaxDeliveries int64
+}
+
+// NewConsumer creates a consumer of streams for a service's group. Groups are
+// created on first run and start at new entries; from then on the group
+// remembers how far the service got.
+//
+// Usage:
+//
+//	busClient, _ := redis.InitRedis()
+//	consumer := bus.NewConsumer(busClient, "leaderboard", handleBusEvent, bus.StreamResults)
+//	go consumer.Run(ctx)
+func NewConsumer(client *redis.Client, group string, handler Handler, streams ...string) *Consumer {
+	name, err := os.Hostname()
+	if err != nil || name == "" {
+		name = "default"
+	}
+	return &Consumer{
+		client:        client,
+		group:         group,
+		streams:       streams,
+		handler:       handler,
+		Name:          name,
+		ClaimIdle:     time.Minute,
+		MaxDeliveries: 5,
+	}
+}
+
+// Run consumes until ctx is cancelled. Entries delivered to this consumer
+// before a restart and never acknowledged are handled first.
+func (c *Consumer) Run(ctx context.Context) {
+	c.createGroups(ctx)
+	log.Printf("📬 Bus consumer %s/%s reading %s", c.group, c.Name, strings.Join(c.streams, ", "))
+
+	for _, stream := range c.streams {
+		c.replayPending(ctx, stream)
+	}
+
+	lastClaim := time.Now()
+	for ctx.Err() == nil {
+		if time.Since(lastClaim) >= c.ClaimIdle {
+			for _, stream := range c.streams {
+				c.claimStale(ctx, stream)
+			}
+			lastClaim = time.Now()
+		}
+
+		res, err := c.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    c.group,
+			Consumer: c.Name,
+			Streams:  readArgs(c.streams),
+			Count:    batchSize,
+			Block:    readBlock,
+		}).Result()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			if strings.HasPrefix(err.Error(), "NOGROUP") {
+				// The stream went (Redis was flushed): start again from new entries
+				c.createGroups(ctx)
+				continue
+			}
+			log.Printf("⚠️  Bus consumer %s: failed to read: %v", c.group, err)
+			sleep(ctx, retryDelay)
+			continue
+		}
+
+		for _, s := range res {
+			for _, m := range s.Messages {
+				c.handle(ctx, s.Stream, m)
+			}
+		}
+	}
+}
+
+// createGroups creates the group on each stream (and the stream if needed).
+// Existing groups keep their position.
+func (c *Consumer) createGroups(ctx context.Context) {
+	for _, stream := range c.streams {
+		err := c.client.XGroupCreateMkStream(ctx, stream, c.group, "$").Err()
+		if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+			log.Printf("⚠️  Bus consumer %s: failed to create group on %s: %v", c.group, stream, err)
+		}
+	}
+}
+
+// replayPending handles the entries this consumer was given but never
+// acknowledged, oldest first
+func (c *Consumer) replayPending(ctx context.Context, stream string) {
+	start := "0"
+	for ctx.Err() == nil {
+		res, err := c.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    c.group,
+			Consumer: c.Name,
+			Streams:  []string{stream, start},
+			Count:    batchSize,
+			Block:    -1,
+		}).Result()
+		if err != nil {
+			if err != redis.Nil {
+				log.Printf("⚠️  Bus consumer %s: failed to read pending %s entries: %v", c.group, stream, err)
+			}
+			return
+		}
+		if len(res) == 0 || len(res[0].Messages) == 0 {
+			return
+		}
+		for _, m := range res[0].Messages {
+			c.handle(ctx, stream, m)
+			start = m.ID
+		}
+	}
+}
+
+// claimStale drops entries that have used up their deliveries, then takes
+// over the rest of the group's entries left unacknowledged for ClaimIdle
+func (c *Consumer) claimStale(ctx context.Context, stream string) {
+	pending, err := c.client.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: stream,
+		Group:  c.group,
+		Idle:   c.ClaimIdle,
+		Start:  "-",
+		End:    "+",
+		Count:  100,
+	}).Result()
+	if err != nil {
+		log.Printf("⚠️  Bus consumer %s: failed to list pending %s entries: %v", c.group, stream, err)
+		return
+	}
+	for _, p := range pending {
+		if p.RetryCount >= c.MaxDeliveries {
+			log.Printf("❌ Bus consumer %s: dropping %s entry %s after %d deliveries", c.group, stream, p.ID, p.RetryCount)
+			c.client.XAck(ctx, stream, c.group, p.ID)
+		}
+	}
+
+	msgs, _, err := c.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+		Stream:   stream,
+		Group:    c.group,
+		MinIdle:  c.ClaimIdle,
+		Start:    "0",
+		Count:    batchSize,
+		Consumer: c.Name,
+	}).Result()
+	if err != nil {
+		log.Printf("⚠️  Bus consumer %s: failed to claim %s entries: %v", c.group, stream, err)
+		return
+	}
+	for _, m := range msgs {
+		c.handle(ctx, stream, m)
+	}
+}
+
+// handle runs the handler for one entry and acknowledges it unless the
+// handler failed
+func (c *Consumer) handle(ctx context.Context, stream string, m redis.XMessage) {
+	msg, err := decode(stream, m)
+	if err != nil {
+		// Retrying can't fix it
+		log.Printf("⚠️  Bus consumer %s: skipping %s entry %s: %v", c.group, stream, m.ID, err)
+	} else if err := safeHandle(ctx, c.handler, msg); err != nil {
+		log.Printf("❌ Bus consumer %s: %s %s failed, will retry: %v", c.group, msg.Event.Type, m.ID, err)
+		return
+	}
+
+	if err := c.client.XAck(ctx, stream, c.group, m.ID).Err(); err != nil {
+		log.Printf("⚠️  Bus consumer %s: failed to acknowledge %s: %v", c.group, m.ID, err)
+	}
+}
+
+// decode reads the envelope out of a stream entry. Entries trimmed from the
+// stream while still pending come back without fields.
+func decode(stream string, m redis.XMessage) (Message, error) {
+	raw, ok := m.Values[eventField].(string)
+	if !ok {
+		return Message{}, fmt.Errorf("entry has no %s field", eventField)
+	}
+	e, err := events.Decode(raw)
+	if err != nil {
+		return Message{}, err
+	}
+	return Message{ID: m.ID, Stream: stream, Event: e}, nil
+}
+
+// safeHandle converts a panicking handler into an error so one bad event
+// can't stop the consumer
+func safeHandle(ctx context.Context, handler Handler, msg Message) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return handler(ctx, msg)
+}
+
+// readArgs lists the streams followed by ">" for each: only entries never
+// delivered to the group
+func readArgs(streams []string) []string {
+	args := append([]string{}, streams...)
+	for range streams {
+		args = append(args, ">")
+	}
+	return args
+}
+
+func sleep(ctx context.Context, d time.Duration) {
+	select {
+	case <-ctx.Done():
+	case <-time.After(d):
+	}
+}
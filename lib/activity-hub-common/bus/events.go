@@ -0,0 +1,48 @@
+package bus
+
+import (
+	"time"
+
+	"github.com/achgithub/activity-hub-common/events"
+	"github.com/achgithub/activity-hub-common/services"
+)
+
+// Bus events aren't registered with events.Register: they never reach a
+// frontend, so they stay out of the backends' /api/events/schema.
+
+// ChallengeEvent is the payload of every event on StreamChallenges. The
+// envelope's session is the challenge ID.
+type ChallengeEvent struct {
+	ChallengeID string    `json:"challengeId"`
+	AppID       string    `json:"appId"`
+	From        string    `json:"from"`                 // Challenger, or the initiator of a multi-player challenge
+	Players     []string  `json:"players"`              // The opponent, or everyone invited to a multi-player challenge
+	MinPlayers  int       `json:"minPlayers,omitempty"` // Multi-player challenges only
+	MaxPlayers  int       `json:"maxPlayers,omitempty"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+	Reason      string    `json:"reason,omitempty"` // Why it was declined, if the player said
+	At          time.Time `json:"at"`
+}
+
+// Events on StreamChallenges
+var (
+	ChallengeIssued   = events.Type[ChallengeEvent]{Name: "challenge_issued", Version: 1}
+	ChallengeAccepted = events.Type[ChallengeEvent]{Name: "challenge_accepted", Version: 1}
+	ChallengeStarted  = events.Type[ChallengeEvent]{Name: "challenge_started", Version: 1} // Enough players accepted a multi-player challenge
+	ChallengeDeclined = events.Type[ChallengeEvent]{Name: "challenge_declined", Version: 1}
+)
+
+// ResultReported is a finished game on StreamResults, in the form games post
+// to the leaderboard. The envelope's session is the game ID.
+var ResultReported = events.Type[services.Result]{Name: "result_reported", Version: 1}
+
+// DisplayCommand is a remote command queued for a TV (display_commands row)
+type DisplayCommand struct {
+	CommandID int    `json:"commandId"`
+	DisplayID int    `json:"displayId"`
+	Command   string `json:"command"`
+}
+
+// DisplayCommandQueued is a command on StreamDisplayCommands, waiting to be
+// pushed to the TV. The envelope's session is empty.
+var DisplayCommandQueued = events.Type[DisplayCommand]{Name: "display_command_queued", Version: 1}
@@ -0,0 +1,192 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrMiss is returned by Store.Get when the key isn't cached
+var ErrMiss = errors.New("cache miss")
+
+// Store is the part of a Redis client the cache uses. The package has no
+// Redis dependency, so backends wrap whichever client they already use.
+//
+// Usage (go-redis v8):
+//
+//	type redisStore struct{ client *redis.Client }
+//
+//	func (s redisStore) Get(ctx context.Context, key string) (string, error) {
+//	    v, err := s.client.Get(ctx, key).Result()
+//	    if err == redis.Nil {
+//	        return "", cache.ErrMiss
+//	    }
+//	    return v, err
+//	}
+//
+//	func (s redisStore) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+//	    return s.client.Set(ctx, key, value, ttl).Err()
+//	}
+//
+//	func (s redisStore) Del(ctx context.Context, keys ...string) error {
+//	    return s.client.Del(ctx, keys...).Err()
+//	}
+type Store interface {
+	Get(ctx context.Context, key string) (string, error)
+	Set(ctx context.Context, key, value string, ttl time.Duration) error
+	Del(ctx context.Context, keys ...string) error
+}
+
+// Stats counts cache traffic for one key group (the part of a key before its
+// first colon, e.g. "standings" for "standings:dots"). Errors are lookups that
+// fell back to the loader because the store failed; they count as misses too.
+type Stats struct {
+	Hits          int64   `json:"hits"`
+	Misses        int64   `json:"misses"`
+	Errors        int64   `json:"errors"`
+	Invalidations int64   `json:"invalidations"`
+	HitRate       float64 `json:"hitRate"` // Hits / (Hits + Misses), 0-1
+}
+
+// Cache is a read-through JSON cache in front of a Store. Keys are namespaced
+// with the cache's prefix, and every entry expires after the TTL so a missed
+// invalidation can't serve stale data for long. A nil *Cache loads every time.
+type Cache struct {
+	store  Store
+	prefix string
+	ttl    time.Duration
+
+	mu    sync.Mutex
+	stats map[string]*Stats
+}
+
+// New creates a cache storing keys under prefix (e.g. "cache:leaderboard:")
+// that expire after ttl.
+func New(store Store, prefix string, ttl time.Duration) *Cache {
+	return &Cache{store: store, prefix: prefix, ttl: ttl, stats: map[string]*Stats{}}
+}
+
+// Fetch returns the cached value for key, or calls load, caches its result and
+// returns it. Load errors are returned as is and nothing is cached. If the
+// store fails the value is loaded as if there were no cache.
+//
+// Usage:
+//
+//	standings, err := cache.Fetch(r.Context(), readCache, "standings:"+gameType,
+//	    func() ([]Standing, error) { return loadStandings(gameType) })
+func Fetch[T any](ctx context.Context, c *Cache, key string, load func() (T, error)) (T, error) {
+	if c == nil {
+		return load()
+	}
+
+	group := groupOf(key)
+	data, err := c.store.Get(ctx, c.prefix+key)
+	if err == nil {
+		var value T
+		if json.Unmarshal([]byte(data), &value) == nil {
+			c.record(group, func(s *Stats) { s.Hits++ })
+			return value, nil
+		}
+	}
+	c.record(group, func(s *Stats) {
+		s.Misses++
+		if err != nil && err != ErrMiss {
+			s.Errors++
+		}
+	})
+
+	value, err := load()
+	if err != nil {
+		return value, err
+	}
+	if encoded, err := json.Marshal(value); err == nil {
+		if c.store.Set(ctx, c.prefix+key, string(encoded), c.ttl) != nil {
+			c.record(group, func(s *Stats) { s.Errors++ })
+		}
+	}
+	return value, nil
+}
+
+// Invalidate drops keys from the cache. Call it after every write that
+// changes what a cached key would load.
+func (c *Cache) Invalidate(ctx context.Context, keys ...string) error {
+	if c == nil || len(keys) == 0 {
+		return nil
+	}
+	full := make([]string, len(keys))
+	for i, key := range keys {
+		full[i] = c.prefix + key
+		c.record(groupOf(key), func(s *Stats) { s.Invalidations++ })
+	}
+	return c.store.Del(ctx, full...)
+}
+
+// Stats returns a copy of the counters for each key group
+func (c *Cache) Stats() map[string]Stats {
+	out := map[string]Stats{}
+	if c == nil {
+		return out
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for group, s := range c.stats {
+		snapshot := *s
+		if total := s.Hits + s.Misses; total > 0 {
+			snapshot.HitRate = float64(s.Hits) / float64(total)
+		}
+		out[group] = snapshot
+	}
+	return out
+}
+
+// StatsReport is the body served by StatsHandler
+type StatsReport struct {
+	TTLSeconds int              `json:"ttlSeconds"`
+	Groups     []string         `json:"groups"`
+	Stats      map[string]Stats `json:"stats"`
+}
+
+// StatsHandler serves the cache's counters as JSON. Backends mount it at
+// GET /api/cache/stats.
+func (c *Cache) StatsHandler(w http.ResponseWriter, r *http.Request) {
+	stats := c.Stats()
+	groups := make([]string, 0, len(stats))
+	for group := range stats {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+
+	var ttl time.Duration
+	if c != nil {
+		ttl = c.ttl
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(StatsReport{
+		TTLSeconds: int(ttl.Seconds()),
+		Groups:     groups,
+		Stats:      stats,
+	})
+}
+
+func (c *Cache) record(group string, update func(*Stats)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s, ok := c.stats[group]
+	if !ok {
+		s = &Stats{}
+		c.stats[group] = s
+	}
+	update(s)
+}
+
+func groupOf(key string) string {
+	if i := strings.IndexByte(key, ':'); i >= 0 {
+		return key[:i]
+	}
+	return key
+}
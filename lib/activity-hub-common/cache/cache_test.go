@@ -0,0 +1,170 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// memStore is an in-memory Store; down makes every call fail like a lost Redis
+type memStore struct {
+	data map[string]string
+	ttls map[string]time.Duration
+	down bool
+}
+
+func newMemStore() *memStore {
+	return &memStore{data: map[string]string{}, ttls: map[string]time.Duration{}}
+}
+
+var errDown = errors.New("connection refused")
+
+func (m *memStore) Get(ctx context.Context, key string) (string, error) {
+	if m.down {
+		return "", errDown
+	}
+	v, ok := m.data[key]
+	if !ok {
+		return "", ErrMiss
+	}
+	return v, nil
+}
+
+func (m *memStore) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	if m.down {
+		return errDown
+	}
+	m.data[key] = value
+	m.ttls[key] = ttl
+	return nil
+}
+
+func (m *memStore) Del(ctx context.Context, keys ...string) error {
+	if m.down {
+		return errDown
+	}
+	for _, k := range keys {
+		delete(m.data, k)
+	}
+	return nil
+}
+
+type standing struct {
+	Name   string `json:"name"`
+	Points int    `json:"points"`
+}
+
+func TestFetchReadsThrough(t *testing.T) {
+	store := newMemStore()
+	c := New(store, "cache:test:", time.Minute)
+	ctx := context.Background()
+
+	loads := 0
+	load := func() ([]standing, error) {
+		loads++
+		return []standing{{"Alice", 9}, {"Bob", 3}}, nil
+	}
+
+	for i := 0; i < 3; i++ {
+		got, err := Fetch(ctx, c, "standings:dots", load)
+		if err != nil {
+			t.Fatalf("Fetch() error = %v", err)
+		}
+		if len(got) != 2 || got[0].Name != "Alice" || got[1].Points != 3 {
+			t.Fatalf("Fetch() = %+v", got)
+		}
+	}
+	if loads != 1 {
+		t.Errorf("loaded %d times, want 1", loads)
+	}
+	if store.ttls["cache:test:standings:dots"] != time.Minute {
+		t.Errorf("stored with ttl %v, want 1m", store.ttls["cache:test:standings:dots"])
+	}
+
+	s := c.Stats()["standings"]
+	if s.Hits != 2 || s.Misses != 1 || s.Errors != 0 {
+		t.Errorf("stats = %+v, want 2 hits, 1 miss", s)
+	}
+	if s.HitRate < 0.66 || s.HitRate > 0.67 {
+		t.Errorf("hit rate = %v, want 2/3", s.HitRate)
+	}
+}
+
+func TestInvalidateReloads(t *testing.T) {
+	c := New(newMemStore(), "cache:test:", time.Minute)
+	ctx := context.Background()
+
+	points := 1
+	load := func() (int, error) { return points, nil }
+
+	Fetch(ctx, c, "standings:dots", load)
+	points = 4
+	if got, _ := Fetch(ctx, c, "standings:dots", load); got != 1 {
+		t.Fatalf("before invalidation got %d, want cached 1", got)
+	}
+	if err := c.Invalidate(ctx, "standings:dots"); err != nil {
+		t.Fatalf("Invalidate() error = %v", err)
+	}
+	if got, _ := Fetch(ctx, c, "standings:dots", load); got != 4 {
+		t.Errorf("after invalidation got %d, want 4", got)
+	}
+	if n := c.Stats()["standings"].Invalidations; n != 1 {
+		t.Errorf("invalidations = %d, want 1", n)
+	}
+}
+
+func TestLoadErrorIsNotCached(t *testing.T) {
+	store := newMemStore()
+	c := New(store, "cache:test:", time.Minute)
+	ctx := context.Background()
+
+	notFound := errors.New("not found")
+	_, err := Fetch(ctx, c, "session:ABC", func() (string, error) { return "", notFound })
+	if err != notFound {
+		t.Fatalf("Fetch() error = %v, want the load error", err)
+	}
+	if len(store.data) != 0 {
+		t.Errorf("load error was cached: %v", store.data)
+	}
+}
+
+func TestStoreDownFallsBackToLoad(t *testing.T) {
+	store := newMemStore()
+	store.down = true
+	c := New(store, "cache:test:", time.Minute)
+
+	got, err := Fetch(context.Background(), c, "playlist:7", func() (string, error) { return "live", nil })
+	if err != nil || got != "live" {
+		t.Fatalf("Fetch() = %q, %v; want the loaded value", got, err)
+	}
+	s := c.Stats()["playlist"]
+	if s.Misses != 1 || s.Errors != 2 {
+		t.Errorf("stats = %+v, want 1 miss and 2 errors (get and set)", s)
+	}
+}
+
+func TestNilCacheLoads(t *testing.T) {
+	var c *Cache
+	got, err := Fetch(context.Background(), c, "x", func() (int, error) { return 5, nil })
+	if err != nil || got != 5 {
+		t.Fatalf("Fetch() = %d, %v", got, err)
+	}
+	if err := c.Invalidate(context.Background(), "x"); err != nil {
+		t.Errorf("Invalidate() on nil cache = %v", err)
+	}
+}
+
+func TestStatsHandler(t *testing.T) {
+	c := New(newMemStore(), "cache:test:", 30*time.Second)
+	Fetch(context.Background(), c, "recent:all", func() (int, error) { return 1, nil })
+
+	w := httptest.NewRecorder()
+	c.StatsHandler(w, httptest.NewRequest("GET", "/api/cache/stats", nil))
+	body := w.Body.String()
+	if !strings.Contains(body, `"ttlSeconds":30`) || !strings.Contains(body, `"recent":{"hits":0,"misses":1`) {
+		t.Errorf("unexpected stats body: %s", body)
+	}
+}
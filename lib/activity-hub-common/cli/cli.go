@@ -0,0 +1,212 @@
+// Package cli gives each backend binary a few maintenance commands beside
+// serving, so an operator SSH'd into the Pi can run them instead of
+// hand-written SQL:
+//
+//	go run *.go                 # serve, as always
+//	go run *.go migrate --status
+//	go run *.go help
+//
+// With no command the binary serves, so existing start scripts don't change.
+// Commands are plain functions; Serve and Migrate are the ones every backend
+// has, and apps add their own (create-admin in setup-admin, say).
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Command is one subcommand of a backend binary
+type Command struct {
+	Name  string
+	Args  string // Positional arguments for the usage line, e.g. "<email> <name>"
+	Short string // One line for the command list
+
+	// Flags defines the command's flags, if it has any
+	Flags func(fs *flag.FlagSet)
+
+	// Run does the work with the arguments left after the flags. A
+	// Usagef error prints the command's usage as well.
+	Run func(args []string) error
+}
+
+// App is a backend binary's set of commands
+type App struct {
+	Name     string
+	Commands []Command
+
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// New creates a binary's command set. The first command runs when none is
+// given, so put Serve first.
+//
+// Usage:
+//
+//	func main() {
+//		cli.New("tic-tac-toe",
+//			cli.Serve(serve),
+//			cli.Migrate(openAppDB, cli.Migrations{Dir: "../database", Schema: []string{"schema.sql"}}),
+//		).Main()
+//	}
+func New(name string, cmds ...Command) *App {
+	return &App{Name: name, Commands: cmds, stdout: os.Stdout, stderr: os.Stderr}
+}
+
+// Main runs the command named in os.Args and exits with its status
+func (a *App) Main() {
+	os.Exit(a.Run(os.Args[1:]))
+}
+
+// Run runs the command named in args[0] and returns the exit status: 0 for
+// success, 1 if the command failed and 2 for a usage mistake
+func (a *App) Run(args []string) int {
+	if len(args) == 0 {
+		if len(a.Commands) == 0 {
+			return 0
+		}
+		return a.run(a.Commands[0], nil)
+	}
+
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		if len(args) > 1 {
+			if cmd, ok := a.find(args[1]); ok {
+				a.commandUsage(a.stdout, cmd, nil)
+				return 0
+			}
+		}
+		a.usage(a.stdout)
+		return 0
+	}
+
+	cmd, ok := a.find(args[0])
+	if !ok {
+		fmt.Fprintf(a.stderr, "Unknown command %q\n\n", args[0])
+		a.usage(a.stderr)
+		return 2
+	}
+	return a.run(cmd, args[1:])
+}
+
+func (a *App) run(cmd Command, args []string) int {
+	fs := flag.NewFlagSet(a.Name+" "+cmd.Name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	if cmd.Flags != nil {
+		cmd.Flags(fs)
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			a.commandUsage(a.stdout, cmd, fs)
+			return 0
+		}
+		fmt.Fprintf(a.stderr, "%v\n\n", err)
+		a.commandUsage(a.stderr, cmd, fs)
+		return 2
+	}
+
+	err := cmd.Run(fs.Args())
+	var usageErr usageError
+	if errors.As(err, &usageErr) {
+		fmt.Fprintf(a.stderr, "%v\n\n", err)
+		a.commandUsage(a.stderr, cmd, fs)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(a.stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func (a *App) find(name string) (Command, bool) {
+	for _, cmd := range a.Commands {
+		if cmd.Name == name {
+			return cmd, true
+		}
+	}
+	return Command{}, false
+}
+
+func (a *App) usage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: %s [command]\n\nCommands:\n", a.Name)
+	width := len("help")
+	for _, cmd := range a.Commands {
+		if len(cmd.Name) > width {
+			width = len(cmd.Name)
+		}
+	}
+	for i, cmd := range a.Commands {
+		short := cmd.Short
+		if i == 0 {
+			short += " (the default)"
+		}
+		fmt.Fprintf(w, "  %-*s  %s\n", width, cmd.Name, short)
+	}
+	fmt.Fprintf(w, "  %-*s  %s\n", width, "help", "Show this, or a command's flags with help <command>")
+}
+
+func (a *App) commandUsage(w io.Writer, cmd Command, fs *flag.FlagSet) {
+	if fs == nil {
+		fs = flag.NewFlagSet(a.Name+" "+cmd.Name, flag.ContinueOnError)
+		if cmd.Flags != nil {
+			cmd.Flags(fs)
+		}
+	}
+
+	line := []string{"Usage:", a.Name, cmd.Name}
+	hasFlags := false
+	fs.VisitAll(func(*flag.Flag) { hasFlags = true })
+	if hasFlags {
+		line = append(line, "[flags]")
+	}
+	if cmd.Args != "" {
+		line = append(line, cmd.Args)
+	}
+	fmt.Fprintf(w, "%s\n\n%s\n", strings.Join(line, " "), cmd.Short)
+	if hasFlags {
+		fmt.Fprintln(w, "\nFlags:")
+		fs.SetOutput(w)
+		fs.PrintDefaults()
+	}
+}
+
+type usageError struct {
+	msg string
+}
+
+func (e usageError) Error() string {
+	return e.msg
+}
+
+// Usagef reports that a command was called wrongly (missing arguments, say);
+// the command's usage is printed after the message.
+//
+// Usage:
+//
+//	if len(args) != 1 {
+//		return cli.Usagef("expected a display ID")
+//	}
+func Usagef(format string, args ...interface{}) error {
+	return usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+// Serve is the command that runs the server: the backend's old main
+func Serve(run func()) Command {
+	return Command{
+		Name:  "serve",
+		Short: "Run the server",
+		Run: func(args []string) error {
+			if len(args) > 0 {
+				return Usagef("serve takes no arguments")
+			}
+			run()
+			return nil
+		},
+	}
+}
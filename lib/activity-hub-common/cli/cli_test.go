@@ -0,0 +1,153 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func testApp(ran *[]string) (*App, *bytes.Buffer, *bytes.Buffer) {
+	var verbose bool
+	app := New("dots",
+		Serve(func() { *ran = append(*ran, "serve") }),
+		Command{
+			Name:  "reindex-stats",
+			Args:  "<player>",
+			Short: "Rebuild player stats",
+			Flags: func(fs *flag.FlagSet) {
+				fs.BoolVar(&verbose, "verbose", false, "Say more")
+			},
+			Run: func(args []string) error {
+				if len(args) != 1 {
+					return Usagef("expected a player")
+				}
+				if args[0] == "broken" {
+					return errors.New("no such table")
+				}
+				*ran = append(*ran, "reindex "+args[0]+map[bool]string{true: " verbose"}[verbose])
+				return nil
+			},
+		},
+	)
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	app.stdout, app.stderr = stdout, stderr
+	return app, stdout, stderr
+}
+
+func TestRunDefaultsToServe(t *testing.T) {
+	var ran []string
+	app, _, _ := testApp(&ran)
+	if code := app.Run(nil); code != 0 || !reflect.DeepEqual(ran, []string{"serve"}) {
+		t.Errorf("Run() = %d, ran %v; want 0 and serve", code, ran)
+	}
+}
+
+func TestRunCommand(t *testing.T) {
+	var ran []string
+	app, _, stderr := testApp(&ran)
+
+	if code := app.Run([]string{"reindex-stats", "--verbose", "alice"}); code != 0 {
+		t.Fatalf("Run = %d (%s)", code, stderr)
+	}
+	if !reflect.DeepEqual(ran, []string{"reindex alice verbose"}) {
+		t.Errorf("ran %v", ran)
+	}
+
+	if code := app.Run([]string{"reindex-stats", "broken"}); code != 1 {
+		t.Errorf("failing command = %d, want 1", code)
+	}
+	if !strings.Contains(stderr.String(), "Error: no such table") {
+		t.Errorf("stderr = %q", stderr)
+	}
+}
+
+func TestRunUsageMistakes(t *testing.T) {
+	var ran []string
+	app, _, stderr := testApp(&ran)
+
+	for _, args := range [][]string{
+		{"reindex"},                  // Unknown command
+		{"reindex-stats"},            // Missing argument
+		{"reindex-stats", "--bogus"}, // Unknown flag
+		{"serve", "extra"},           // Serve takes nothing
+	} {
+		stderr.Reset()
+		if code := app.Run(args); code != 2 {
+			t.Errorf("Run(%v) = %d, want 2", args, code)
+		}
+		if !strings.Contains(stderr.String(), "Usage:") {
+			t.Errorf("Run(%v) printed no usage: %q", args, stderr)
+		}
+	}
+	if len(ran) != 0 {
+		t.Errorf("ran %v after usage mistakes", ran)
+	}
+}
+
+func TestHelp(t *testing.T) {
+	var ran []string
+	app, stdout, _ := testApp(&ran)
+
+	app.Run([]string{"help"})
+	for _, want := range []string{"serve", "Run the server (the default)", "reindex-stats", "Rebuild player stats"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("help is missing %q:\n%s", want, stdout)
+		}
+	}
+
+	stdout.Reset()
+	app.Run([]string{"help", "reindex-stats"})
+	if !strings.Contains(stdout.String(), "Usage: dots reindex-stats [flags] <player>") || !strings.Contains(stdout.String(), "-verbose") {
+		t.Errorf("command help = %q", stdout)
+	}
+}
+
+func TestMigrationPlan(t *testing.T) {
+	m := Migrations{
+		Schema:   []string{"schema.sql", "schema_v2.sql"},
+		Upgrades: []string{"migrate_add_guids.sql", "migrate_add_trash.sql"},
+	}
+	applied := func(files ...string) map[string]bool {
+		out := map[string]bool{}
+		for _, f := range files {
+			out[f] = true
+		}
+		return out
+	}
+
+	// An empty database is created from the schema, which has the upgrades
+	p, err := m.plan(migrationState{empty: true, applied: applied()}, false)
+	if err != nil || !p.create || !reflect.DeepEqual(p.run, m.Schema) || len(p.record) != 4 {
+		t.Errorf("empty database: %+v, %v", p, err)
+	}
+
+	// Tables without history: refuse rather than guess
+	if _, err := m.plan(migrationState{applied: applied()}, false); err == nil || !strings.Contains(err.Error(), "--baseline") {
+		t.Errorf("untracked database: err = %v, want a --baseline hint", err)
+	}
+
+	// ...unless told it's up to date
+	p, err = m.plan(migrationState{applied: applied()}, true)
+	if err != nil || len(p.run) != 0 || len(p.record) != 4 {
+		t.Errorf("baseline: %+v, %v", p, err)
+	}
+
+	// A tracked database runs only the upgrades it hasn't had
+	p, err = m.plan(migrationState{tracked: true, applied: applied("schema.sql", "schema_v2.sql", "migrate_add_guids.sql")}, false)
+	if err != nil || p.create || !reflect.DeepEqual(p.run, []string{"migrate_add_trash.sql"}) {
+		t.Errorf("tracked database: %+v, %v", p, err)
+	}
+
+	p, _ = m.plan(migrationState{tracked: true, applied: applied("schema.sql", "schema_v2.sql", "migrate_add_guids.sql", "migrate_add_trash.sql")}, false)
+	if !p.upToDate() {
+		t.Errorf("up-to-date database: %+v", p)
+	}
+
+	// No schema file (the identity database is made by the setup scripts)
+	if _, err := (Migrations{Upgrades: m.Upgrades}).plan(migrationState{empty: true}, false); err == nil {
+		t.Error("Expected an error creating a database with no schema")
+	}
+}
@@ -0,0 +1,299 @@
+package cli
+
+import (
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Migrations are an app's SQL files, applied by the migrate command and
+// recorded in the app database's schema_migrations table.
+//
+// An empty database gets the schema files, in one transaction, and the
+// upgrades are recorded as done: the schema already has them. After that
+// only upgrades not yet recorded run, each on its own, so an upgrade file
+// may have its own BEGIN/COMMIT but a schema file mustn't.
+type Migrations struct {
+	Dir      string   // Relative to the backend directory, usually "../database"
+	Schema   []string // Create the app's tables in an empty database, in order
+	Upgrades []string // Bring an older database up to date, in order
+}
+
+// migrationState is what the app database has so far
+type migrationState struct {
+	tracked bool            // schema_migrations exists
+	empty   bool            // No tables besides schema_migrations
+	applied map[string]bool // Files recorded in schema_migrations
+}
+
+// migrationPlan is what a migrate does: files to run, then files to record
+// as applied without running them
+type migrationPlan struct {
+	create bool // run is the schema, in one transaction
+	run    []string
+	record []string
+}
+
+func (p migrationPlan) upToDate() bool {
+	return len(p.run) == 0 && len(p.record) == 0
+}
+
+// plan works out a migrate from the database's state. With baseline, every
+// file not yet recorded is recorded without running: for a database brought
+// up to date by hand before there was a migrate command.
+func (m Migrations) plan(s migrationState, baseline bool) (migrationPlan, error) {
+	var p migrationPlan
+	all := append(append([]string{}, m.Schema...), m.Upgrades...)
+
+	switch {
+	case baseline:
+		for _, f := range all {
+			if !s.applied[f] {
+				p.record = append(p.record, f)
+			}
+		}
+	case !s.tracked && s.empty:
+		if len(m.Schema) == 0 {
+			return p, errors.New("the database is empty and there's no schema file to create it from")
+		}
+		p.create = true
+		p.run = m.Schema
+		p.record = all
+	case !s.tracked:
+		return p, errors.New("the database has tables but no migration history. " +
+			"If it's up to date, record that with --baseline; if not, run the upgrades it's missing by hand first")
+	default:
+		for _, f := range m.Upgrades {
+			if !s.applied[f] {
+				p.run = append(p.run, f)
+				p.record = append(p.record, f)
+			}
+		}
+	}
+	return p, nil
+}
+
+// Migrate is the command that brings an app's database up to date from its
+// SQL files. open connects to the app database.
+//
+// Usage:
+//
+//	cli.Migrate(openAppDB, cli.Migrations{
+//		Dir:      "../database",
+//		Schema:   []string{"schema.sql"},
+//		Upgrades: []string{"migrate_add_guids.sql", "migrate_add_trash.sql"},
+//	})
+func Migrate(open func() (*sql.DB, error), m Migrations) Command {
+	var dryRun, baseline bool
+	return Command{
+		Name:  "migrate",
+		Short: "Create or upgrade the app's database from its SQL files",
+		Flags: func(fs *flag.FlagSet) {
+			fs.BoolVar(&dryRun, "dry-run", false, "Show what would run without changing anything")
+			fs.BoolVar(&baseline, "baseline", false, "Record every file as applied without running it, for a database already up to date")
+		},
+		Run: func(args []string) error {
+			if len(args) > 0 {
+				return Usagef("migrate takes no arguments")
+			}
+			db, err := open()
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			state, err := readMigrationState(db)
+			if err != nil {
+				return err
+			}
+			p, err := m.plan(state, baseline)
+			if err != nil {
+				return err
+			}
+			if p.upToDate() {
+				fmt.Println("Database is up to date")
+				return nil
+			}
+			if dryRun {
+				for _, f := range p.run {
+					fmt.Printf("Would run %s\n", f)
+				}
+				for _, f := range p.record {
+					if !contains(p.run, f) {
+						fmt.Printf("Would record %s as applied\n", f)
+					}
+				}
+				return nil
+			}
+			return m.apply(db, p)
+		},
+	}
+}
+
+// MigrateFunc is the migrate command for an app that creates its tables in
+// code (CREATE TABLE IF NOT EXISTS at startup): it runs create without
+// starting the server.
+//
+// Usage:
+//
+//	cli.MigrateFunc(openAppDB, createTables)
+func MigrateFunc(open func() (*sql.DB, error), create func(db *sql.DB) error) Command {
+	return Command{
+		Name:  "migrate",
+		Short: "Create or upgrade the app's tables",
+		Run: func(args []string) error {
+			if len(args) > 0 {
+				return Usagef("migrate takes no arguments")
+			}
+			db, err := open()
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			if err := create(db); err != nil {
+				return err
+			}
+			fmt.Println("Database is up to date")
+			return nil
+		},
+	}
+}
+
+func readMigrationState(db *sql.DB) (migrationState, error) {
+	s := migrationState{applied: map[string]bool{}}
+
+	err := db.QueryRow(`SELECT to_regclass('public.schema_migrations') IS NOT NULL`).Scan(&s.tracked)
+	if err != nil {
+		return s, fmt.Errorf("failed to read migration history: %w", err)
+	}
+
+	var tables int
+	err = db.QueryRow(`
+		SELECT COUNT(*) FROM information_schema.tables
+		WHERE table_schema = 'public' AND table_name <> 'schema_migrations'
+	`).Scan(&tables)
+	if err != nil {
+		return s, fmt.Errorf("failed to list tables: %w", err)
+	}
+	s.empty = tables == 0
+
+	if !s.tracked {
+		return s, nil
+	}
+	rows, err := db.Query(`SELECT filename FROM schema_migrations`)
+	if err != nil {
+		return s, fmt.Errorf("failed to read migration history: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var f string
+		if err := rows.Scan(&f); err != nil {
+			return s, err
+		}
+		s.applied[f] = true
+	}
+	return s, rows.Err()
+}
+
+func (m Migrations) apply(db *sql.DB, p migrationPlan) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			filename VARCHAR(255) PRIMARY KEY,
+			applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	if p.create {
+		return m.create(db, p)
+	}
+
+	for _, f := range p.run {
+		sqlText, err := m.read(f)
+		if err != nil {
+			return err
+		}
+		// One statement list runs as one transaction, unless the file
+		// has its own
+		if _, err := db.Exec(sqlText); err != nil {
+			return fmt.Errorf("%s failed: %w", f, err)
+		}
+		if err := recordMigration(db, f); err != nil {
+			return err
+		}
+		fmt.Printf("Ran %s\n", f)
+	}
+	for _, f := range p.record {
+		if contains(p.run, f) {
+			continue
+		}
+		if err := recordMigration(db, f); err != nil {
+			return err
+		}
+		fmt.Printf("Recorded %s as applied\n", f)
+	}
+	return nil
+}
+
+// create runs the schema into an empty database, all or nothing
+func (m Migrations) create(db *sql.DB, p migrationPlan) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, f := range p.run {
+		sqlText, err := m.read(f)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(sqlText); err != nil {
+			return fmt.Errorf("%s failed: %w", f, err)
+		}
+	}
+	for _, f := range p.record {
+		if err := recordMigration(tx, f); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	fmt.Printf("Created the database from %s\n", strings.Join(p.run, ", "))
+	return nil
+}
+
+func (m Migrations) read(f string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(m.Dir, f))
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s (run from the backend directory): %w", f, err)
+	}
+	return string(data), nil
+}
+
+func recordMigration(db interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}, f string) error {
+	_, err := db.Exec(`INSERT INTO schema_migrations (filename) VALUES ($1) ON CONFLICT DO NOTHING`, f)
+	if err != nil {
+		return fmt.Errorf("failed to record %s: %w", f, err)
+	}
+	return nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
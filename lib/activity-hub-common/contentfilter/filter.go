@@ -0,0 +1,214 @@
+// Package contentfilter checks player-entered text (team names, answers)
+// against a word list before it reaches shared screens.
+package contentfilter
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Actions a venue can choose for text that matches its word list
+const (
+	ActionFlag  = "flag"  // Accept it, but mask it publicly until a host approves it (default)
+	ActionBlock = "block" // Refuse it
+)
+
+// Filter matches whole words and phrases, ignoring case, common character
+// swaps (0→o, 1→i, 3→e, 4→a, 5→s, 7→t, @→a, $→s) and stretched letters ("fuuun").
+type Filter struct {
+	entries  map[string]string // Normalized phrase → list entry
+	squeezed map[string]string // Same, with repeated letters collapsed
+	longest  int               // Most words in a phrase
+}
+
+// Match is one word-list hit in a checked text.
+type Match struct {
+	Entry string // The word-list entry that matched
+	Start int    // Byte offsets of the matched text
+	End   int
+}
+
+// New builds a filter from a word list. Entries may be single words or
+// phrases; blank entries are ignored.
+//
+// Usage:
+//
+//	f := contentfilter.New([]string{"darn", "heck off"})
+//	if matches := f.Check(teamName); len(matches) > 0 {
+//	    public = f.Mask(teamName)
+//	}
+func New(words []string) *Filter {
+	f := &Filter{entries: map[string]string{}, squeezed: map[string]string{}}
+	for _, w := range words {
+		toks := tokenize(w)
+		if len(toks) == 0 {
+			continue
+		}
+		f.entries[joinTokens(toks, false)] = strings.TrimSpace(w)
+		f.squeezed[joinTokens(toks, true)] = strings.TrimSpace(w)
+		if len(toks) > f.longest {
+			f.longest = len(toks)
+		}
+	}
+	return f
+}
+
+// Empty reports whether the filter has no entries (everything passes).
+func (f *Filter) Empty() bool {
+	return f == nil || len(f.entries) == 0
+}
+
+// Check returns the word-list hits in text, in order (nil when clean).
+func (f *Filter) Check(text string) []Match {
+	if f.Empty() {
+		return nil
+	}
+	toks := tokenize(text)
+	var matches []Match
+	for i := 0; i < len(toks); {
+		matched := false
+		// Prefer the longest phrase starting at this word
+		for n := min(f.longest, len(toks)-i); n >= 1; n-- {
+			if entry, ok := f.lookup(toks[i : i+n]); ok {
+				matches = append(matches, Match{Entry: entry, Start: toks[i].start, End: toks[i+n-1].end})
+				i += n
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			i++
+		}
+	}
+	return matches
+}
+
+// Words returns the distinct word-list entries found in text.
+func (f *Filter) Words(text string) []string {
+	seen := map[string]bool{}
+	var words []string
+	for _, m := range f.Check(text) {
+		if !seen[m.Entry] {
+			seen[m.Entry] = true
+			words = append(words, m.Entry)
+		}
+	}
+	return words
+}
+
+// Mask replaces each matched word with its first letter followed by asterisks.
+func (f *Filter) Mask(text string) string {
+	matches := f.Check(text)
+	if len(matches) == 0 {
+		return text
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		b.WriteString(text[last:m.Start])
+		for i, r := range []rune(text[m.Start:m.End]) {
+			switch {
+			case unicode.IsSpace(r):
+				b.WriteRune(r)
+			case i == 0:
+				b.WriteRune(r)
+			default:
+				b.WriteRune('*')
+			}
+		}
+		last = m.End
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// lookup matches a run of words exactly, or with repeated letters collapsed
+// when one of them is stretched (so "as" doesn't match "ass", but "asssss" does)
+func (f *Filter) lookup(toks []token) (string, bool) {
+	if entry, ok := f.entries[joinTokens(toks, false)]; ok {
+		return entry, true
+	}
+	for _, t := range toks {
+		if t.stretched {
+			entry, ok := f.squeezed[joinTokens(toks, true)]
+			return entry, ok
+		}
+	}
+	return "", false
+}
+
+type token struct {
+	norm       string
+	squeezed   string
+	stretched  bool // Has a letter repeated 3+ times
+	start, end int
+}
+
+var swaps = map[rune]rune{
+	'0': 'o', '1': 'i', '3': 'e', '4': 'a', '5': 's', '7': 't', '@': 'a', '$': 's',
+}
+
+// tokenize splits text into normalized words with their byte offsets
+func tokenize(text string) []token {
+	var toks []token
+	var cur []rune
+	start := -1
+	flush := func(end int) {
+		if start >= 0 {
+			squeezed, stretched := squeeze(cur)
+			toks = append(toks, token{norm: string(cur), squeezed: squeezed, stretched: stretched, start: start, end: end})
+		}
+		cur = cur[:0]
+		start = -1
+	}
+	for i, r := range text {
+		if s, ok := swaps[r]; ok {
+			r = s
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+			}
+			cur = append(cur, unicode.ToLower(r))
+			continue
+		}
+		// Apostrophes stay inside a word ("don't")
+		if r == '\'' && start >= 0 {
+			continue
+		}
+		flush(i)
+	}
+	flush(len(text))
+	return toks
+}
+
+// squeeze collapses runs of the same letter so "fuuun" and "fun" compare equal,
+// reporting whether any run was 3 or longer
+func squeeze(rs []rune) (string, bool) {
+	var b strings.Builder
+	stretched := false
+	run := 0
+	for i, r := range rs {
+		if i > 0 && r == rs[i-1] {
+			run++
+			if run >= 3 {
+				stretched = true
+			}
+			continue
+		}
+		run = 1
+		b.WriteRune(r)
+	}
+	return b.String(), stretched
+}
+
+func joinTokens(toks []token, squeezed bool) string {
+	parts := make([]string, len(toks))
+	for i, t := range toks {
+		parts[i] = t.norm
+		if squeezed {
+			parts[i] = t.squeezed
+		}
+	}
+	return strings.Join(parts, " ")
+}
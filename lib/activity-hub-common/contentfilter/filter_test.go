@@ -0,0 +1,67 @@
+package contentfilter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCheckWholeWords(t *testing.T) {
+	f := New([]string{"darn", "Heck Off", "  "})
+
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"The Quizzy Bears", nil},
+		{"darn it", []string{"darn"}},
+		{"DARN!", []string{"darn"}},
+		{"darning needles", nil},
+		{"d4rn", []string{"darn"}},
+		{"oh heck   off, darn", []string{"Heck Off", "darn"}},
+		{"heck", nil},
+	}
+	for _, tt := range tests {
+		if got := f.Words(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Words(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestCheckStretchedLetters(t *testing.T) {
+	f := New([]string{"ass"})
+
+	if got := f.Words("as good as it gets"); got != nil {
+		t.Errorf("Expected 'as' not to match 'ass', got %v", got)
+	}
+	if got := f.Words("asssss"); len(got) != 1 {
+		t.Errorf("Expected stretched word to match, got %v", got)
+	}
+	if got := f.Words("a$$"); len(got) != 1 {
+		t.Errorf("Expected swapped characters to match, got %v", got)
+	}
+}
+
+func TestMask(t *testing.T) {
+	f := New([]string{"darn", "heck off"})
+
+	tests := []struct{ text, want string }{
+		{"Darn Good Team", "D*** Good Team"},
+		{"heck off, quizzers", "h*** ***, quizzers"},
+		{"Clean Name", "Clean Name"},
+	}
+	for _, tt := range tests {
+		if got := f.Mask(tt.text); got != tt.want {
+			t.Errorf("Mask(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestEmptyFilter(t *testing.T) {
+	var nilFilter *Filter
+	if !nilFilter.Empty() || nilFilter.Check("anything") != nil {
+		t.Error("Expected nil filter to pass everything")
+	}
+	if !New(nil).Empty() {
+		t.Error("Expected filter with no words to be empty")
+	}
+}
@@ -26,3 +26,28 @@ func TestScanNullString(t *testing.T) {
 
 // TODO: Add integration tests for InitDatabase
 // TODO: Add integration tests for InitIdentityDatabase
+
+func TestConfigurePool(t *testing.T) {
+	db, err := sql.Open("postgres", "host=127.0.0.1 port=1 dbname=unused sslmode=disable")
+	if err != nil {
+		t.Fatalf("sql.Open: %v", err)
+	}
+	defer db.Close()
+
+	configurePool(db)
+	if got := db.Stats().MaxOpenConnections; got != 25 {
+		t.Errorf("default MaxOpenConnections = %d, want 25", got)
+	}
+
+	t.Setenv("DB_MAX_OPEN_CONNS", "4")
+	configurePool(db)
+	if got := db.Stats().MaxOpenConnections; got != 4 {
+		t.Errorf("MaxOpenConnections = %d, want 4 from DB_MAX_OPEN_CONNS", got)
+	}
+
+	t.Setenv("DB_MAX_OPEN_CONNS", "lots")
+	configurePool(db)
+	if got := db.Stats().MaxOpenConnections; got != 25 {
+		t.Errorf("MaxOpenConnections = %d, want the default for a bad value", got)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strconv"
 	"time"
 
 	_ "github.com/lib/pq"
@@ -40,9 +41,7 @@ func InitDatabase(appName string) (*sql.DB, error) {
 	}
 
 	// Configure connection pool to prevent resource exhaustion
-	db.SetMaxOpenConns(25)
-	db.SetMaxIdleConns(5)
-	db.SetConnMaxLifetime(5 * time.Minute)
+	configurePool(db)
 
 	log.Printf("✅ Connected to app database: %s", dbName)
 	return db, nil
@@ -73,9 +72,7 @@ func InitDatabaseByName(dbName string) (*sql.DB, error) {
 	}
 
 	// Configure connection pool to prevent resource exhaustion
-	db.SetMaxOpenConns(25)
-	db.SetMaxIdleConns(5)
-	db.SetConnMaxLifetime(5 * time.Minute)
+	configurePool(db)
 
 	log.Printf("✅ Connected to database: %s", dbName)
 	return db, nil
@@ -110,9 +107,7 @@ func InitIdentityDatabase() (*sql.DB, error) {
 	}
 
 	// Configure connection pool to prevent resource exhaustion
-	db.SetMaxOpenConns(25)
-	db.SetMaxIdleConns(5)
-	db.SetConnMaxLifetime(5 * time.Minute)
+	configurePool(db)
 
 	log.Printf("✅ Connected to identity database: %s", dbName)
 	return db, nil
@@ -132,6 +127,16 @@ func ScanNullString(ns sql.NullString) string {
 	return ""
 }
 
+// configurePool sizes a connection pool: 25 open and 5 idle connections, or
+// DB_MAX_OPEN_CONNS and DB_MAX_IDLE_CONNS. Each backend process has its own
+// pools, so the supervisor lowers these to keep a Pi running every backend
+// under PostgreSQL's max_connections.
+func configurePool(db *sql.DB) {
+	db.SetMaxOpenConns(getEnvInt("DB_MAX_OPEN_CONNS", 25))
+	db.SetMaxIdleConns(getEnvInt("DB_MAX_IDLE_CONNS", 5))
+	db.SetConnMaxLifetime(5 * time.Minute)
+}
+
 // getEnv retrieves an environment variable with a fallback default value
 func getEnv(key, defaultValue string) string {
 	value := os.Getenv(key)
@@ -140,3 +145,12 @@ func getEnv(key, defaultValue string) string {
 	}
 	return value
 }
+
+// getEnvInt is getEnv for a positive number; anything else gets the default
+func getEnvInt(key string, defaultValue int) int {
+	n, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || n <= 0 {
+		return defaultValue
+	}
+	return n
+}
@@ -0,0 +1,170 @@
+// Package events defines the envelope every real-time stream (lobby, quiz and
+// game SSE) sends, and a registry of the event types each backend emits.
+//
+// Every message on a stream is one JSON envelope:
+//
+//	{"type": "move_update", "version": 1, "session": "g-123", "payload": {...}}
+//
+// so frontends can share one parser and dispatch on type. Each event type is
+// registered once with a typed payload struct; the registry is exported as
+// JSON Schema (SchemaHandler) for frontend types and tests.
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// Envelope is one event on a stream or pub/sub channel.
+type Envelope struct {
+	Type    string          `json:"type"`
+	Version int             `json:"version"`           // Payload schema version for Type
+	Session string          `json:"session,omitempty"` // Game ID, quiz session ID; empty for per-user streams
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// Encode returns the envelope as a pub/sub message
+func (e Envelope) Encode() string {
+	data, err := json.Marshal(e)
+	if err != nil {
+		// Payload is already valid JSON, so this can't happen
+		log.Printf("❌ Failed to marshal %s event: %v", e.Type, err)
+		return "{}"
+	}
+	return string(data)
+}
+
+// Decode parses a pub/sub message published with Encode.
+func Decode(msg string) (Envelope, error) {
+	var e Envelope
+	if err := json.Unmarshal([]byte(msg), &e); err != nil {
+		return e, fmt.Errorf("failed to unmarshal event: %w", err)
+	}
+	if e.Type == "" || e.Version < 1 {
+		return e, fmt.Errorf("not an event envelope: %.60s", msg)
+	}
+	return e, nil
+}
+
+// Send writes the envelope to an SSE stream and flushes it.
+func Send(w http.ResponseWriter, e Envelope) {
+	Forward(w, e.Encode())
+}
+
+// Forward writes an encoded envelope (a pub/sub message) to an SSE stream as
+// is and flushes it.
+func Forward(w http.ResponseWriter, msg string) {
+	fmt.Fprintf(w, "data: %s\n\n", msg)
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Type is a registered event type with payload P.
+type Type[P any] struct {
+	Name    string
+	Version int
+}
+
+// New builds an envelope for this event type.
+func (t Type[P]) New(session string, payload P) Envelope {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("❌ Failed to marshal %s payload: %v", t.Name, err)
+		data = nil
+	}
+	return Envelope{Type: t.Name, Version: t.Version, Session: session, Payload: data}
+}
+
+// Payload decodes the payload of an envelope of this type.
+func (t Type[P]) Payload(e Envelope) (P, error) {
+	var p P
+	if e.Type != t.Name {
+		return p, fmt.Errorf("event is %s, not %s", e.Type, t.Name)
+	}
+	if e.Version > t.Version {
+		return p, fmt.Errorf("%s event version %d is newer than %d", t.Name, e.Version, t.Version)
+	}
+	if len(e.Payload) > 0 {
+		if err := json.Unmarshal(e.Payload, &p); err != nil {
+			return p, fmt.Errorf("failed to unmarshal %s payload: %w", t.Name, err)
+		}
+	}
+	return p, nil
+}
+
+// Schema describes a registered event type.
+type Schema struct {
+	Type        string
+	Version     int
+	Description string
+	payload     reflect.Type
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Schema{}
+)
+
+// Register declares an event type and its payload struct. Call it once per
+// type, from a package-level var; registering the same name twice panics.
+//
+// Usage:
+//
+//	type MoveUpdate struct {
+//	    Game Game `json:"game"`
+//	}
+//
+//	var MoveUpdated = events.Register[MoveUpdate]("move_update", 1, "A move was played")
+//
+//	PublishGameEvent(MoveUpdated.New(gameID, MoveUpdate{Game: game}))
+func Register[P any](name string, version int, description string) Type[P] {
+	if name == "" || version < 1 {
+		panic("events: type name and version >= 1 are required")
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, exists := registry[name]; exists {
+		panic("events: duplicate event type " + name)
+	}
+	registry[name] = Schema{
+		Type:        name,
+		Version:     version,
+		Description: description,
+		payload:     reflect.TypeOf((*P)(nil)).Elem(),
+	}
+	return Type[P]{Name: name, Version: version}
+}
+
+// Registered lists the registered event types, sorted by name.
+func Registered() []Schema {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	list := make([]Schema, 0, len(registry))
+	for _, s := range registry {
+		list = append(list, s)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Type < list[j].Type })
+	return list
+}
+
+// NoPayload is the payload of events that carry none
+type NoPayload struct{}
+
+// ErrorPayload is sent when a stream can't continue
+type ErrorPayload struct {
+	Message string `json:"message"`
+}
+
+// Event types every stream may send
+var (
+	Ping  = Register[NoPayload]("ping", 1, "Keepalive")
+	Error = Register[ErrorPayload]("error", 1, "The stream is closing because of an error")
+)
@@ -0,0 +1,156 @@
+package events
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testBoard struct {
+	Cells []int `json:"cells"`
+}
+
+type testMove struct {
+	testBoard
+	Player   string             `json:"player"`
+	Position int                `json:"position"`
+	Note     string             `json:"note,omitempty"`
+	Winner   *string            `json:"winner"`
+	At       time.Time          `json:"at"`
+	Next     *testMove          `json:"next,omitempty"`
+	Extra    []byte             `json:"extra,omitempty"`
+	Scores   map[string]float64 `json:"scores,omitempty"`
+	internal int
+}
+
+var testMoved = Register[testMove]("test_move", 2, "A test move")
+
+func TestEnvelopeRoundTrip(t *testing.T) {
+	env := testMoved.New("g-1", testMove{Player: "alice", Position: 4, testBoard: testBoard{Cells: []int{0, 1}}})
+	if env.Type != "test_move" || env.Version != 2 || env.Session != "g-1" {
+		t.Errorf("Unexpected envelope %+v", env)
+	}
+
+	decoded, err := Decode(env.Encode())
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	move, err := testMoved.Payload(decoded)
+	if err != nil {
+		t.Fatalf("Payload: %v", err)
+	}
+	if move.Player != "alice" || move.Position != 4 || !reflect.DeepEqual(move.Cells, []int{0, 1}) {
+		t.Errorf("Unexpected payload %+v", move)
+	}
+
+	// Frontends written before the envelope read type and payload only
+	var legacy struct {
+		Type    string                 `json:"type"`
+		Payload map[string]interface{} `json:"payload"`
+	}
+	json.Unmarshal([]byte(env.Encode()), &legacy)
+	if legacy.Type != "test_move" || legacy.Payload["player"] != "alice" {
+		t.Errorf("Expected type/payload fields, got %+v", legacy)
+	}
+}
+
+func TestDecodeRejects(t *testing.T) {
+	for _, msg := range []string{"game_started:dots:123", `{"type":"x"}`, `{"version":1}`} {
+		if _, err := Decode(msg); err == nil {
+			t.Errorf("Expected %q to be rejected", msg)
+		}
+	}
+
+	if _, err := testMoved.Payload(Ping.New("", NoPayload{})); err == nil {
+		t.Error("Expected payload of another type to be rejected")
+	}
+	newer := testMoved.New("", testMove{})
+	newer.Version = 3
+	if _, err := testMoved.Payload(newer); err == nil {
+		t.Error("Expected newer version to be rejected")
+	}
+}
+
+func TestRegisterDuplicatePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected duplicate registration to panic")
+		}
+	}()
+	Register[NoPayload]("ping", 1, "again")
+}
+
+func TestSend(t *testing.T) {
+	w := httptest.NewRecorder()
+	Send(w, Error.New("q-7", ErrorPayload{Message: "Game not found"}))
+
+	body := w.Body.String()
+	if !strings.HasPrefix(body, "data: ") || !strings.HasSuffix(body, "\n\n") {
+		t.Fatalf("Expected one SSE data message, got %q", body)
+	}
+	if !w.Flushed {
+		t.Error("Expected the stream to be flushed")
+	}
+	env, err := Decode(strings.TrimSpace(strings.TrimPrefix(body, "data: ")))
+	if err != nil || env.Type != "error" || env.Session != "q-7" {
+		t.Errorf("Unexpected event %+v (%v)", env, err)
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	w := httptest.NewRecorder()
+	SchemaHandler(w, httptest.NewRequest(http.MethodGet, "/api/events/schema", nil))
+
+	var doc struct {
+		OneOf []map[string]string `json:"oneOf"`
+		Defs  map[string]struct {
+			Properties struct {
+				Type    map[string]interface{} `json:"type"`
+				Version map[string]interface{} `json:"version"`
+				Payload struct {
+					Type       string                            `json:"type"`
+					Properties map[string]map[string]interface{} `json:"properties"`
+					Required   []string                          `json:"required"`
+				} `json:"payload"`
+			} `json:"properties"`
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("Invalid schema JSON: %v", err)
+	}
+	if len(doc.OneOf) != len(Registered()) {
+		t.Errorf("Expected one entry per registered type, got %d", len(doc.OneOf))
+	}
+
+	def, ok := doc.Defs["test_move"]
+	if !ok {
+		t.Fatal("Expected test_move in $defs")
+	}
+	if def.Properties.Type["const"] != "test_move" || def.Properties.Version["const"] != float64(2) {
+		t.Errorf("Unexpected type/version %v %v", def.Properties.Type, def.Properties.Version)
+	}
+
+	props := def.Properties.Payload.Properties
+	want := map[string]string{"cells": "array", "player": "string", "position": "integer", "note": "string", "at": "string", "extra": "string", "scores": "object"}
+	for name, typ := range want {
+		if props[name]["type"] != typ {
+			t.Errorf("Expected %s to be %s, got %v", name, typ, props[name])
+		}
+	}
+	if _, ok := props["winner"]["anyOf"]; !ok {
+		t.Errorf("Expected pointer field to be nullable, got %v", props["winner"])
+	}
+	if _, ok := props["internal"]; ok {
+		t.Error("Expected unexported fields to be skipped")
+	}
+	if next := props["next"]; next["anyOf"] == nil {
+		t.Errorf("Expected recursive field to be described, got %v", next)
+	}
+	if !reflect.DeepEqual(def.Properties.Payload.Required, []string{"cells", "player", "position", "winner", "at"}) {
+		t.Errorf("Unexpected required fields %v", def.Properties.Payload.Required)
+	}
+}
@@ -0,0 +1,131 @@
+package events
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// JSONSchema returns a JSON Schema (draft 2020-12) document for the envelope
+// of every registered event type.
+func JSONSchema() map[string]interface{} {
+	defs := map[string]interface{}{}
+	var oneOf []interface{}
+	for _, s := range Registered() {
+		defs[s.Type] = map[string]interface{}{
+			"description": s.Description,
+			"type":        "object",
+			"properties": map[string]interface{}{
+				"type":    map[string]interface{}{"const": s.Type},
+				"version": map[string]interface{}{"const": s.Version},
+				"session": map[string]interface{}{"type": "string"},
+				"payload": typeSchema(s.payload, map[reflect.Type]bool{}),
+			},
+			"required": []string{"type", "version"},
+		}
+		oneOf = append(oneOf, map[string]interface{}{"$ref": "#/$defs/" + s.Type})
+	}
+	return map[string]interface{}{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"title":   "Activity Hub stream event",
+		"oneOf":   oneOf,
+		"$defs":   defs,
+	}
+}
+
+// SchemaHandler serves JSONSchema. Backends mount it at GET /api/events/schema.
+func SchemaHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/schema+json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(JSONSchema())
+}
+
+// typeSchema maps a Go type to a JSON Schema following encoding/json's rules.
+// seen guards against recursive types.
+func typeSchema(t reflect.Type, seen map[reflect.Type]bool) map[string]interface{} {
+	switch t {
+	case timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case rawMessageType:
+		return map[string]interface{}{}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return map[string]interface{}{
+			"anyOf": []interface{}{typeSchema(t.Elem(), seen), map[string]interface{}{"type": "null"}},
+		}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "contentEncoding": "base64"}
+		}
+		return map[string]interface{}{"type": "array", "items": typeSchema(t.Elem(), seen)}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": typeSchema(t.Elem(), seen)}
+	case reflect.Struct:
+		if seen[t] {
+			return map[string]interface{}{"type": "object"}
+		}
+		seen[t] = true
+		defer delete(seen, t)
+
+		properties := map[string]interface{}{}
+		required := []string{}
+		addFields(t, seen, properties, &required)
+		schema := map[string]interface{}{"type": "object", "properties": properties}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		return schema
+	}
+	// interface{} and anything else: any JSON value
+	return map[string]interface{}{}
+}
+
+// addFields adds a struct's JSON fields, flattening embedded structs.
+func addFields(t reflect.Type, seen map[reflect.Type]bool, properties map[string]interface{}, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" || (!f.IsExported() && !f.Anonymous) {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		ft := f.Type
+		if f.Anonymous && name == "" {
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				addFields(ft, seen, properties, required)
+				continue
+			}
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		properties[name] = typeSchema(ft, seen)
+		if !strings.Contains(opts, "omitempty") {
+			*required = append(*required, name)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+// Package flags evaluates the platform's feature flags, so a risky feature
+// can be switched on for one venue or one role before everyone gets it.
+//
+// Flags live in the identity DB's feature_flags table and are edited in
+// setup-admin. Each backend keeps its own copy, reloaded within
+// RefreshInterval, so checking a flag never touches the database.
+package flags
+
+import (
+	"database/sql"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/achgithub/activity-hub-common/auth"
+	"github.com/lib/pq"
+)
+
+// RefreshInterval is how often flags are reloaded from the identity DB
+const RefreshInterval = 30 * time.Second
+
+// Flag is one feature flag and its targeting
+type Flag struct {
+	Key         string   `json:"key"`
+	Description string   `json:"description"`
+	Enabled     bool     `json:"enabled"`
+	VenueIDs    []int64  `json:"venueIds"` // Empty = every venue
+	Roles       []string `json:"roles"`    // Empty = everyone
+}
+
+// On reports whether the flag applies to a user at a venue with the given
+// roles. Chain-wide users (venue 0) only see flags that aren't limited to
+// particular venues.
+func (f Flag) On(venueID int, roles []string) bool {
+	if !f.Enabled {
+		return false
+	}
+	if len(f.VenueIDs) > 0 && !containsVenue(f.VenueIDs, venueID) {
+		return false
+	}
+	if len(f.Roles) > 0 && !containsAny(f.Roles, roles) {
+		return false
+	}
+	return true
+}
+
+func containsVenue(ids []int64, venueID int) bool {
+	for _, id := range ids {
+		if id == int64(venueID) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAny(want, have []string) bool {
+	for _, w := range want {
+		for _, h := range have {
+			if w == h {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Set is a backend's copy of the feature flags, reloaded lazily.
+// Unknown flags are off.
+type Set struct {
+	identityDB *sql.DB
+
+	mu       sync.RWMutex
+	flags    map[string]Flag
+	loadedAt time.Time
+}
+
+// New creates a flag set read from the identity DB.
+//
+// Usage:
+//
+//	featureFlags := flags.New(identityDB)
+//
+//	user, _ := authlib.GetUserFromContext(r.Context())
+//	if featureFlags.ForUser("quiz_auto_mark", user) { ... }
+//
+//	// No user to go on, e.g. a TV display: venue-wide flags only
+//	if featureFlags.ForVenue("quiz_auto_mark", session.VenueID) { ... }
+func New(identityDB *sql.DB) *Set {
+	return &Set{identityDB: identityDB, flags: map[string]Flag{}}
+}
+
+// Reload reads every flag from the identity DB. Callers that have just
+// changed a flag can call it to skip the refresh interval.
+func (s *Set) Reload() error {
+	rows, err := s.identityDB.Query(`
+		SELECT key, COALESCE(description, ''), enabled,
+		       COALESCE(venue_ids, '{}'), COALESCE(roles, '{}')
+		FROM feature_flags
+	`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	loaded := map[string]Flag{}
+	for rows.Next() {
+		var f Flag
+		var venueIDs pq.Int64Array
+		var roles pq.StringArray
+		if err := rows.Scan(&f.Key, &f.Description, &f.Enabled, &venueIDs, &roles); err != nil {
+			return err
+		}
+		f.VenueIDs = venueIDs
+		f.Roles = roles
+		loaded[f.Key] = f
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.flags = loaded
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *Set) refresh() {
+	s.mu.RLock()
+	stale := time.Since(s.loadedAt) > RefreshInterval
+	s.mu.RUnlock()
+	if !stale {
+		return
+	}
+	if err := s.Reload(); err != nil {
+		log.Printf("⚠️  Feature flag reload failed, keeping previous: %v", err)
+		// Back off until the next interval rather than hitting the DB on every check
+		s.mu.Lock()
+		s.loadedAt = time.Now()
+		s.mu.Unlock()
+	}
+}
+
+// On reports whether a flag applies at a venue for a user with the given roles
+func (s *Set) On(key string, venueID int, roles []string) bool {
+	s.refresh()
+	s.mu.RLock()
+	f, ok := s.flags[key]
+	s.mu.RUnlock()
+	return ok && f.On(venueID, roles)
+}
+
+// ForUser reports whether a flag applies to an authenticated user. A nil
+// user (a public route) only sees flags with no venue or role targeting.
+func (s *Set) ForUser(key string, user *auth.AuthUser) bool {
+	if user == nil {
+		return s.On(key, 0, nil)
+	}
+	return s.On(key, user.VenueID, user.Roles)
+}
+
+// ForVenue reports whether a flag applies at a venue regardless of role, for
+// checks made on behalf of a venue rather than a user (a quiz session, a TV
+// display). Role-targeted flags are off.
+func (s *Set) ForVenue(key string, venueID int) bool {
+	return s.On(key, venueID, nil)
+}
+
+// Evaluate returns every flag's value for a user, for frontends that need to
+// show or hide features
+func (s *Set) Evaluate(user *auth.AuthUser) map[string]bool {
+	s.refresh()
+	venueID, roles := 0, []string(nil)
+	if user != nil {
+		venueID, roles = user.VenueID, user.Roles
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	values := make(map[string]bool, len(s.flags))
+	for key, f := range s.flags {
+		values[key] = f.On(venueID, roles)
+	}
+	return values
+}
@@ -0,0 +1,69 @@
+package flags
+
+import (
+	"testing"
+	"time"
+
+	"github.com/achgithub/activity-hub-common/auth"
+)
+
+func TestFlagOn(t *testing.T) {
+	tests := []struct {
+		name    string
+		flag    Flag
+		venueID int
+		roles   []string
+		want    bool
+	}{
+		{"disabled", Flag{}, 1, nil, false},
+		{"everyone", Flag{Enabled: true}, 0, nil, true},
+		{"target venue", Flag{Enabled: true, VenueIDs: []int64{2}}, 2, nil, true},
+		{"other venue", Flag{Enabled: true, VenueIDs: []int64{2}}, 3, nil, false},
+		{"chain-wide user, venue flag", Flag{Enabled: true, VenueIDs: []int64{2}}, 0, nil, false},
+		{"target role", Flag{Enabled: true, Roles: []string{"quiz_master"}}, 1, []string{"player", "quiz_master"}, true},
+		{"missing role", Flag{Enabled: true, Roles: []string{"quiz_master"}}, 1, []string{"player"}, false},
+		{"venue and role", Flag{Enabled: true, VenueIDs: []int64{2}, Roles: []string{"quiz_master"}}, 2, []string{"quiz_master"}, true},
+		{"role at other venue", Flag{Enabled: true, VenueIDs: []int64{2}, Roles: []string{"quiz_master"}}, 3, []string{"quiz_master"}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.flag.On(tt.venueID, tt.roles); got != tt.want {
+			t.Errorf("%s: On(%d, %v) = %v, want %v", tt.name, tt.venueID, tt.roles, got, tt.want)
+		}
+	}
+}
+
+// loaded returns a set that won't reload from the (missing) database
+func loaded(flags ...Flag) *Set {
+	s := New(nil)
+	for _, f := range flags {
+		s.flags[f.Key] = f
+	}
+	s.loadedAt = time.Now()
+	return s
+}
+
+func TestSet(t *testing.T) {
+	s := loaded(
+		Flag{Key: "quiz_auto_mark", Enabled: true, VenueIDs: []int64{2}},
+		Flag{Key: "staff_beta", Enabled: true, Roles: []string{"game_manager"}},
+	)
+	user := &auth.AuthUser{Email: "host@pub.test", VenueID: 2, Roles: []string{"game_manager"}}
+
+	if !s.ForUser("quiz_auto_mark", user) || !s.ForUser("staff_beta", user) {
+		t.Error("Expected both flags on for a venue 2 game manager")
+	}
+	if s.ForUser("quiz_auto_mark", nil) {
+		t.Error("Venue-targeted flag should be off without a user")
+	}
+	if !s.ForVenue("quiz_auto_mark", 2) || s.ForVenue("staff_beta", 2) {
+		t.Error("ForVenue should ignore role-targeted flags")
+	}
+	if s.ForUser("unknown", user) {
+		t.Error("Unknown flags should be off")
+	}
+
+	values := s.Evaluate(&auth.AuthUser{VenueID: 3})
+	if len(values) != 2 || values["quiz_auto_mark"] || values["staff_beta"] {
+		t.Errorf("Unexpected evaluation %v", values)
+	}
+}
@@ -0,0 +1,101 @@
+package history
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/achgithub/activity-hub-common/config"
+)
+
+// Player outcomes
+const (
+	OutcomeWin  = "win"
+	OutcomeLoss = "loss"
+	OutcomeDraw = "draw"
+)
+
+// Player is one participant in a completed game.
+// Outcome is OutcomeWin, OutcomeLoss or OutcomeDraw (empty for non-competitive apps).
+type Player struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Outcome string `json:"outcome,omitempty"`
+}
+
+// Record is a compact summary of a completed game.
+type Record struct {
+	App         string                 `json:"app"`
+	GameID      string                 `json:"gameId"`
+	Players     []Player               `json:"players"`
+	Result      string                 `json:"result,omitempty"`   // Short summary, e.g. "3-2"
+	Duration    int                    `json:"duration,omitempty"` // Seconds
+	Options     map[string]interface{} `json:"options,omitempty"`  // Challenge options the game was played with
+	CompletedAt time.Time              `json:"completedAt"`
+}
+
+var client = &http.Client{Timeout: 10 * time.Second}
+
+// serviceURL returns the history service base URL.
+// History lives alongside the leaderboard, so LEADERBOARD_URL is used when HISTORY_URL isn't set.
+func serviceURL() string {
+	url := config.GetEnv("HISTORY_URL", config.GetEnv("LEADERBOARD_URL", "http://127.0.0.1:5030"))
+	return strings.TrimRight(url, "/")
+}
+
+// Report posts a completed game to the history service using a player's token
+// (the service only accepts records from one of the game's players).
+// Reporting the same app/gameId twice is harmless.
+//
+// Usage:
+//
+//	go func() {
+//	    if err := history.Report(token, history.Record{
+//	        App:    "tic-tac-toe",
+//	        GameID: game.ID,
+//	        Players: []history.Player{
+//	            {ID: game.Player1ID, Name: game.Player1Name, Outcome: history.OutcomeWin},
+//	            {ID: game.Player2ID, Name: game.Player2Name, Outcome: history.OutcomeLoss},
+//	        },
+//	        Result:      "3-1",
+//	        CompletedAt: time.Now(),
+//	    }); err != nil {
+//	        log.Printf("Failed to report history: %v", err)
+//	    }
+//	}()
+func Report(token string, rec Record) error {
+	if rec.App == "" || rec.GameID == "" || len(rec.Players) == 0 {
+		return fmt.Errorf("app, gameId and players are required")
+	}
+	if rec.CompletedAt.IsZero() {
+		rec.CompletedAt = time.Now()
+	}
+
+	body, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to marshal history record: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, serviceURL()+"/api/history", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create history request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to report history: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("history service returned %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package history
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestReportPostsRecord(t *testing.T) {
+	var got Record
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/history" || r.Method != http.MethodPost {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		auth = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+	t.Setenv("HISTORY_URL", server.URL+"/")
+
+	err := Report("demo-token-alice@example.com", Record{
+		App:    "dots",
+		GameID: "game-1",
+		Players: []Player{
+			{ID: "alice@example.com", Name: "Alice", Outcome: OutcomeWin},
+			{ID: "bob@example.com", Name: "Bob", Outcome: OutcomeLoss},
+		},
+		Result: "12-8",
+	})
+	if err != nil {
+		t.Fatalf("Report() error = %v", err)
+	}
+
+	if auth != "Bearer demo-token-alice@example.com" {
+		t.Errorf("Authorization = %q", auth)
+	}
+	if got.GameID != "game-1" || len(got.Players) != 2 || got.Players[0].Outcome != OutcomeWin {
+		t.Errorf("unexpected record: %+v", got)
+	}
+	if got.CompletedAt.IsZero() {
+		t.Error("CompletedAt should default to now")
+	}
+}
+
+func TestReportReturnsServiceErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "not a player", http.StatusForbidden)
+	}))
+	defer server.Close()
+	t.Setenv("HISTORY_URL", server.URL)
+
+	err := Report("token", Record{App: "dots", GameID: "g", Players: []Player{{ID: "a"}}})
+	if err == nil {
+		t.Fatal("expected error for 403 response")
+	}
+}
+
+func TestReportValidatesRecord(t *testing.T) {
+	if err := Report("token", Record{App: "dots"}); err == nil {
+		t.Error("expected error for record without gameId/players")
+	}
+}
+
+func TestServiceURLFallsBackToLeaderboard(t *testing.T) {
+	t.Setenv("HISTORY_URL", "")
+	t.Setenv("LEADERBOARD_URL", "http://leaderboard:5030")
+	if got := serviceURL(); got != "http://leaderboard:5030" {
+		t.Errorf("serviceURL() = %q", got)
+	}
+}
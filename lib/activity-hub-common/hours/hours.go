@@ -0,0 +1,229 @@
+// Package hours answers "is the pub open?" for the platform: displays switch
+// to their after-hours playlist when a venue closes, the lobby can refuse
+// new challenges outside opening hours, and maintenance work waits until
+// every venue has closed.
+//
+// Hours live in the identity DB (venue_hours and venue_hours_settings) and
+// are edited in setup-admin. Venue 0 holds the default hours, used by venues
+// without hours of their own and by single-venue deployments. Times are the
+// server's local time - every backend runs on the same machine as the pub.
+package hours
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// RefreshInterval is how often hours are reloaded from the identity DB
+const RefreshInterval = 30 * time.Second
+
+// Window is one opening period. Opens and Closes are "HH:MM"; a window that
+// closes at or before it opens runs past midnight into the next day, so
+// Friday 18:00-01:00 is open until 1am Saturday.
+type Window struct {
+	Day    time.Weekday `json:"day"`
+	Opens  string       `json:"opens"`
+	Closes string       `json:"closes"`
+}
+
+// minutes parses "HH:MM" into minutes after midnight
+func minutes(hhmm string) (int, error) {
+	t, err := time.Parse("15:04", hhmm)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, want HH:MM", hhmm)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Validate checks the window's day and times
+func (w Window) Validate() error {
+	if w.Day < time.Sunday || w.Day > time.Saturday {
+		return fmt.Errorf("invalid day %d, want 0 (Sunday) to 6", w.Day)
+	}
+	if _, err := minutes(w.Opens); err != nil {
+		return err
+	}
+	_, err := minutes(w.Closes)
+	return err
+}
+
+// Contains reports whether t falls inside the window
+func (w Window) Contains(t time.Time) bool {
+	opens, err1 := minutes(w.Opens)
+	closes, err2 := minutes(w.Closes)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	now := t.Hour()*60 + t.Minute()
+	if closes > opens {
+		return t.Weekday() == w.Day && now >= opens && now < closes
+	}
+	// Past midnight: the evening of Day and the small hours of the day after
+	return (t.Weekday() == w.Day && now >= opens) ||
+		(t.Weekday() == (w.Day+1)%7 && now < closes)
+}
+
+// Venue is one venue's opening hours and how strictly they're enforced
+type Venue struct {
+	Windows []Window `json:"windows"`
+
+	// LobbyClosed makes the lobby refuse new challenges outside the windows
+	LobbyClosed bool `json:"lobbyClosed"`
+}
+
+// OpenAt reports whether the venue is open at t. A venue with no windows is
+// always open.
+func (v Venue) OpenAt(t time.Time) bool {
+	if len(v.Windows) == 0 {
+		return true
+	}
+	for _, w := range v.Windows {
+		if w.Contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// Hours is a backend's copy of every venue's opening hours, reloaded lazily
+type Hours struct {
+	identityDB *sql.DB
+
+	mu       sync.RWMutex
+	venues   map[int]Venue
+	loadedAt time.Time
+}
+
+// New creates an opening hours lookup read from the identity DB.
+//
+// Usage:
+//
+//	openingHours := hours.New(identityDB)
+//
+//	if openingHours.LobbyClosed(user.VenueID, time.Now()) {
+//	    http.Error(w, "The lobby is closed outside opening hours", http.StatusForbidden)
+//	    return
+//	}
+//
+//	// Maintenance waits until every venue has closed
+//	scheduler.Register("purge", jobs.OffPeak(jobs.Every(time.Hour), openingHours.Peak), purge)
+func New(identityDB *sql.DB) *Hours {
+	return &Hours{identityDB: identityDB, venues: map[int]Venue{}}
+}
+
+// Reload reads every venue's hours from the identity DB. Callers that have
+// just changed them can call it to skip the refresh interval.
+func (h *Hours) Reload() error {
+	venues := map[int]Venue{}
+
+	rows, err := h.identityDB.Query(`
+		SELECT venue_id, day_of_week, to_char(opens, 'HH24:MI'), to_char(closes, 'HH24:MI')
+		FROM venue_hours
+		ORDER BY venue_id, day_of_week, opens
+	`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var venueID, day int
+		var w Window
+		if err := rows.Scan(&venueID, &day, &w.Opens, &w.Closes); err != nil {
+			return err
+		}
+		w.Day = time.Weekday(day)
+		v := venues[venueID]
+		v.Windows = append(v.Windows, w)
+		venues[venueID] = v
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	settings, err := h.identityDB.Query(`SELECT venue_id, lobby_closed FROM venue_hours_settings`)
+	if err != nil {
+		return err
+	}
+	defer settings.Close()
+	for settings.Next() {
+		var venueID int
+		var lobbyClosed bool
+		if err := settings.Scan(&venueID, &lobbyClosed); err != nil {
+			return err
+		}
+		v := venues[venueID]
+		v.LobbyClosed = lobbyClosed
+		venues[venueID] = v
+	}
+	if err := settings.Err(); err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	h.venues = venues
+	h.loadedAt = time.Now()
+	h.mu.Unlock()
+	return nil
+}
+
+func (h *Hours) refresh() {
+	if h.identityDB == nil {
+		return
+	}
+	h.mu.RLock()
+	stale := time.Since(h.loadedAt) > RefreshInterval
+	h.mu.RUnlock()
+	if !stale {
+		return
+	}
+	if err := h.Reload(); err != nil {
+		log.Printf("⚠️  Opening hours reload failed, keeping previous: %v", err)
+		// Back off until the next interval rather than hitting the DB on every check
+		h.mu.Lock()
+		h.loadedAt = time.Now()
+		h.mu.Unlock()
+	}
+}
+
+// Venue returns a venue's hours, or the default hours (venue 0) if it has
+// none of its own
+func (h *Hours) Venue(venueID int) Venue {
+	h.refresh()
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if v, ok := h.venues[venueID]; ok && len(v.Windows) > 0 {
+		return v
+	}
+	return h.venues[0]
+}
+
+// IsOpen reports whether a venue is open at t. Venues without hours, when no
+// default hours are set either, are always open.
+func (h *Hours) IsOpen(venueID int, t time.Time) bool {
+	return h.Venue(venueID).OpenAt(t)
+}
+
+// LobbyClosed reports whether the lobby should refuse new challenges for a
+// venue's players at t: the venue is closed and enforces its hours.
+func (h *Hours) LobbyClosed(venueID int, t time.Time) bool {
+	v := h.Venue(venueID)
+	return v.LobbyClosed && !v.OpenAt(t)
+}
+
+// Peak reports whether any venue with opening hours is open at t, so
+// maintenance can wait for the quiet hours. With no hours set anywhere
+// nothing is peak.
+func (h *Hours) Peak(t time.Time) bool {
+	h.refresh()
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for _, v := range h.venues {
+		if len(v.Windows) > 0 && v.OpenAt(t) {
+			return true
+		}
+	}
+	return false
+}
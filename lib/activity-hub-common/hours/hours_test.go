@@ -0,0 +1,98 @@
+package hours
+
+import (
+	"testing"
+	"time"
+)
+
+// 2026-03-06 is a Friday
+func at(day, hour, minute int) time.Time {
+	return time.Date(2026, 3, day, hour, minute, 0, 0, time.UTC)
+}
+
+func TestWindowContains(t *testing.T) {
+	lunch := Window{Day: time.Friday, Opens: "11:00", Closes: "15:00"}
+	late := Window{Day: time.Friday, Opens: "18:00", Closes: "01:00"}
+
+	tests := []struct {
+		name   string
+		window Window
+		t      time.Time
+		want   bool
+	}{
+		{"opening minute", lunch, at(6, 11, 0), true},
+		{"closing minute", lunch, at(6, 15, 0), false},
+		{"before opening", lunch, at(6, 10, 59), false},
+		{"other day", lunch, at(7, 12, 0), false},
+		{"evening", late, at(6, 23, 30), true},
+		{"after midnight", late, at(7, 0, 30), true},
+		{"after close", late, at(7, 1, 0), false},
+		{"small hours before", late, at(6, 0, 30), false},
+	}
+	for _, tt := range tests {
+		if got := tt.window.Contains(tt.t); got != tt.want {
+			t.Errorf("%s: Contains(%v) = %v, want %v", tt.name, tt.t, got, tt.want)
+		}
+	}
+
+	// Saturday night runs into Sunday
+	sat := Window{Day: time.Saturday, Opens: "20:00", Closes: "02:00"}
+	if !sat.Contains(at(8, 1, 0)) {
+		t.Error("Saturday window should cover early Sunday")
+	}
+}
+
+func TestWindowValidate(t *testing.T) {
+	if err := (Window{Day: time.Monday, Opens: "11:00", Closes: "23:30"}).Validate(); err != nil {
+		t.Errorf("Valid window rejected: %v", err)
+	}
+	for _, w := range []Window{
+		{Day: 7, Opens: "11:00", Closes: "23:00"},
+		{Day: time.Monday, Opens: "11", Closes: "23:00"},
+		{Day: time.Monday, Opens: "11:00", Closes: "24:00"},
+	} {
+		if w.Validate() == nil {
+			t.Errorf("Expected %+v to be rejected", w)
+		}
+	}
+}
+
+// loaded returns hours that won't reload from the (missing) database
+func loaded(venues map[int]Venue) *Hours {
+	h := New(nil)
+	h.venues = venues
+	h.loadedAt = time.Now()
+	return h
+}
+
+func TestHours(t *testing.T) {
+	h := loaded(map[int]Venue{
+		0: {Windows: []Window{{Day: time.Friday, Opens: "11:00", Closes: "23:00"}}},
+		2: {Windows: []Window{{Day: time.Friday, Opens: "17:00", Closes: "02:00"}}, LobbyClosed: true},
+	})
+
+	if !h.IsOpen(1, at(6, 12, 0)) || h.IsOpen(1, at(6, 23, 30)) {
+		t.Error("Venue without hours should follow the default hours")
+	}
+	if h.IsOpen(2, at(6, 12, 0)) || !h.IsOpen(2, at(7, 1, 0)) {
+		t.Error("Venue 2 should use its own hours")
+	}
+
+	if h.LobbyClosed(1, at(6, 23, 30)) {
+		t.Error("Default hours don't close the lobby")
+	}
+	if !h.LobbyClosed(2, at(6, 12, 0)) || h.LobbyClosed(2, at(6, 18, 0)) {
+		t.Error("Venue 2 closes its lobby outside its hours")
+	}
+
+	if !h.Peak(at(6, 12, 0)) || !h.Peak(at(7, 1, 0)) || h.Peak(at(7, 3, 0)) {
+		t.Error("Peak should be whenever any venue is open")
+	}
+}
+
+func TestNoHours(t *testing.T) {
+	h := loaded(map[int]Venue{})
+	if !h.IsOpen(3, at(6, 4, 0)) || h.LobbyClosed(3, at(6, 4, 0)) || h.Peak(at(6, 20, 0)) {
+		t.Error("Without hours venues are always open and nothing is peak")
+	}
+}
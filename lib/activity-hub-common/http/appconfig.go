@@ -0,0 +1,104 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+)
+
+// The GET /api/config contract every app follows. The identity shell reads it
+// to render challenge options and to adapt its UI to what each app supports,
+// so apps build it with AppConfig rather than their own map.
+
+// Capabilities are the platform features an app supports.
+type Capabilities struct {
+	Challenges bool `json:"challenges"` // Can be started from a lobby challenge
+	SSE        bool `json:"sse"`        // Pushes live updates over server-sent events
+	Displays   bool `json:"displays"`   // Has a view for the pub TV displays
+	Teams      bool `json:"teams"`      // Players take part as teams
+}
+
+// AppConfig is an app's GET /api/config response.
+//
+// Version defaults to APP_VERSION from the environment, then "1.0.0".
+// GameOptions is the challenge options schema the shell renders. Extra holds
+// app-specific fields, such as a caller's permission level; they are added
+// alongside the standard ones and can't replace them. The json tags are for
+// API docs generated from the struct; MarshalJSON writes the response.
+type AppConfig struct {
+	AppID        string                 `json:"appId"`
+	Name         string                 `json:"name"`
+	Icon         string                 `json:"icon"`
+	Version      string                 `json:"version"`
+	Description  string                 `json:"description"`
+	Capabilities Capabilities           `json:"capabilities"`
+	GameOptions  interface{}            `json:"gameOptions"`
+	API          APIVersionPolicy       `json:"api"` // Always set from VersionPolicy
+	Extra        map[string]interface{} `json:"-"`
+}
+
+// MarshalJSON writes the standard fields, the API versioning policy and the
+// extras as one flat object.
+func (c AppConfig) MarshalJSON() ([]byte, error) {
+	out := make(map[string]interface{}, len(c.Extra)+8)
+	for k, v := range c.Extra {
+		out[k] = v
+	}
+
+	version := c.Version
+	if version == "" {
+		version = os.Getenv("APP_VERSION")
+	}
+	if version == "" {
+		version = "1.0.0"
+	}
+
+	out["appId"] = c.AppID
+	out["name"] = c.Name
+	out["icon"] = c.Icon
+	out["version"] = version
+	out["description"] = c.Description
+	out["capabilities"] = c.Capabilities
+	out["api"] = VersionPolicy()
+	if c.GameOptions != nil {
+		out["gameOptions"] = c.GameOptions
+	} else {
+		out["gameOptions"] = []interface{}{}
+	}
+	return json.Marshal(out)
+}
+
+// With returns a copy of the config with extra fields added, for handlers
+// whose response depends on the request.
+func (c AppConfig) With(extra map[string]interface{}) AppConfig {
+	merged := make(map[string]interface{}, len(c.Extra)+len(extra))
+	for k, v := range c.Extra {
+		merged[k] = v
+	}
+	for k, v := range extra {
+		merged[k] = v
+	}
+	c.Extra = merged
+	return c
+}
+
+// WriteConfig writes the config as the JSON response.
+func WriteConfig(w http.ResponseWriter, c AppConfig) {
+	SuccessJSON(w, c, http.StatusOK)
+}
+
+// ConfigHandler serves a fixed config at GET /api/config.
+//
+// Usage:
+//
+//	r.HandleFunc("/api/config", apphttp.ConfigHandler(apphttp.AppConfig{
+//	    AppID:        "tic-tac-toe",
+//	    Name:         "Tic-Tac-Toe",
+//	    Icon:         "⭕",
+//	    Capabilities: apphttp.Capabilities{Challenges: true, SSE: true},
+//	})).Methods("GET")
+func ConfigHandler(c AppConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		WriteConfig(w, c)
+	}
+}
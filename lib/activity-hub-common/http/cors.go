@@ -0,0 +1,235 @@
+package http
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/achgithub/activity-hub-common/config"
+)
+
+// CORS policy shared by every backend, replacing AllowedOrigins("*").
+//
+// An origin is allowed when it is one of the platform's own pages: scheme
+// http/https, a known host and a port taken from the app registry (the
+// applications table), the identity shell or the display runtime. Known hosts are the host the
+// request was sent to - the apps all run on one machine - plus any configured
+// for the environment in cors_hosts. Origins outside that pattern (a venue's
+// signage page, say) are listed in cors_origins. Setup admins edit both
+// through identity-shell; backends pick changes up within CORSRefreshInterval.
+
+// CORSRefreshInterval is how often the policy is reloaded from the identity DB
+const CORSRefreshInterval = 30 * time.Second
+
+// platformPorts serve pages but are not in the app registry: the identity
+// shell and the TV display runtime
+var platformPorts = []string{"3001", "5051"}
+
+func basePorts() map[string]bool {
+	ports := map[string]bool{}
+	for _, port := range platformPorts {
+		ports[port] = true
+	}
+	return ports
+}
+
+// Environment is the deployment environment the policy is read for
+// (ACTIVITY_HUB_ENV, default "production").
+func Environment() string {
+	return config.GetEnv("ACTIVITY_HUB_ENV", "production")
+}
+
+// CORSPolicy is the allowed-origin set for one environment, reloaded lazily.
+type CORSPolicy struct {
+	identityDB *sql.DB
+	env        string
+
+	mu       sync.RWMutex
+	hosts    map[string]bool
+	ports    map[string]bool
+	origins  map[string]bool
+	loadedAt time.Time
+}
+
+// NewCORSPolicy loads the policy for the current environment.
+func NewCORSPolicy(identityDB *sql.DB) *CORSPolicy {
+	p := &CORSPolicy{identityDB: identityDB, env: Environment(), ports: basePorts()}
+	if err := p.Reload(); err != nil {
+		log.Printf("⚠️  CORS policy not loaded, allowing same-host platform origins only: %v", err)
+	}
+	return p
+}
+
+// Reload reads hosts, extra origins and registry ports from the identity DB.
+// On error the previous policy stays in place.
+func (p *CORSPolicy) Reload() error {
+	hosts, err := p.loadSet(`SELECT host FROM cors_hosts WHERE environment = $1`, p.env)
+	if err != nil {
+		return fmt.Errorf("cors_hosts: %w", err)
+	}
+	origins, err := p.loadSet(`SELECT origin FROM cors_origins WHERE environment = $1`, p.env)
+	if err != nil {
+		return fmt.Errorf("cors_origins: %w", err)
+	}
+	ports, err := RegistryPorts(p.identityDB)
+	if err != nil {
+		return fmt.Errorf("applications: %w", err)
+	}
+
+	p.mu.Lock()
+	p.hosts = hosts
+	p.origins = origins
+	p.ports = basePorts()
+	for _, port := range ports {
+		p.ports[port] = true
+	}
+	p.loadedAt = time.Now()
+	p.mu.Unlock()
+	return nil
+}
+
+func (p *CORSPolicy) loadSet(query string, args ...interface{}) (map[string]bool, error) {
+	rows, err := p.identityDB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	set := map[string]bool{}
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		set[strings.ToLower(v)] = true
+	}
+	return set, rows.Err()
+}
+
+// RegistryPorts returns the ports of the enabled apps in the app registry,
+// from their URLs (http://{host}:4001) and backend ports.
+func RegistryPorts(identityDB *sql.DB) ([]string, error) {
+	rows, err := identityDB.Query(`
+		SELECT COALESCE(url, ''), COALESCE(backend_port, 0)
+		FROM applications
+		WHERE enabled = TRUE
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	seen := map[string]bool{}
+	var ports []string
+	add := func(port string) {
+		if port != "" && !seen[port] {
+			seen[port] = true
+			ports = append(ports, port)
+		}
+	}
+	for rows.Next() {
+		var appURL string
+		var backendPort int
+		if err := rows.Scan(&appURL, &backendPort); err != nil {
+			return nil, err
+		}
+		if u, err := url.Parse(strings.ReplaceAll(appURL, "{host}", "localhost")); err == nil {
+			add(u.Port())
+		}
+		if backendPort > 0 {
+			add(strconv.Itoa(backendPort))
+		}
+	}
+	return ports, rows.Err()
+}
+
+func (p *CORSPolicy) refresh() {
+	p.mu.RLock()
+	stale := time.Since(p.loadedAt) > CORSRefreshInterval
+	p.mu.RUnlock()
+	if !stale {
+		return
+	}
+	if err := p.Reload(); err != nil {
+		log.Printf("⚠️  CORS policy reload failed, keeping previous: %v", err)
+		// Back off until the next interval rather than hitting the DB on every request
+		p.mu.Lock()
+		p.loadedAt = time.Now()
+		p.mu.Unlock()
+	}
+}
+
+// Allowed reports whether a cross-origin request from origin may read the
+// response. requestHost is the Host the request was sent to.
+func (p *CORSPolicy) Allowed(origin, requestHost string) bool {
+	u, err := url.Parse(origin)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.Path != "" {
+		return false
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.origins[strings.ToLower(origin)] {
+		return true
+	}
+	port := u.Port()
+	if port == "" || !p.ports[port] {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	if reqHost, _, err := net.SplitHostPort(requestHost); err == nil {
+		requestHost = reqHost
+	}
+	return host == strings.ToLower(requestHost) || p.hosts[host]
+}
+
+// Middleware applies the policy: allowed origins get their own origin echoed
+// back with credentials allowed; others get no CORS headers, so the browser
+// blocks the response. Preflight requests are answered here.
+//
+// Usage:
+//
+//	cors := apphttp.NewCORSPolicy(identityDB)
+//	log.Fatal(http.ListenAndServe(":"+port, cors.Middleware(r)))
+func (p *CORSPolicy) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		p.refresh()
+		w.Header().Add("Vary", "Origin")
+		allowed := p.Allowed(origin, r.Host)
+		if allowed {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+			w.Header().Set("Access-Control-Expose-Headers", strings.Join(append([]string{"ETag"}, VersionHeaders...), ", "))
+		} else {
+			log.Printf("🚫 CORS: origin %s not allowed for %s %s", origin, r.Method, r.URL.Path)
+		}
+
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			if allowed {
+				w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+				if headers := r.Header.Get("Access-Control-Request-Headers"); headers != "" {
+					w.Header().Set("Access-Control-Allow-Headers", headers)
+				}
+				w.Header().Set("Access-Control-Max-Age", "600")
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
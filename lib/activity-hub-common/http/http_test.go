@@ -1,9 +1,11 @@
 package http
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestErrorJSON(t *testing.T) {
@@ -38,3 +40,139 @@ func TestSuccessJSON(t *testing.T) {
 // TODO: Add tests for ParseJSON
 // TODO: Add tests for CORSMiddleware
 // TODO: Add tests for LoggingMiddleware
+
+func testPolicy() *CORSPolicy {
+	return &CORSPolicy{
+		hosts:    map[string]bool{"pubgames.local": true},
+		ports:    map[string]bool{"3001": true, "4001": true},
+		origins:  map[string]bool{"https://signage.example.com": true},
+		loadedAt: time.Now(),
+	}
+}
+
+func TestCORSPolicyAllowed(t *testing.T) {
+	p := testPolicy()
+	tests := []struct {
+		origin, host string
+		want         bool
+	}{
+		{"http://192.168.1.45:4001", "192.168.1.45:3001", true},   // same host, registry port
+		{"http://pubgames.local:3001", "192.168.1.45:4001", true}, // configured host
+		{"https://signage.example.com", "192.168.1.45:3001", true},
+		{"http://192.168.1.45:9999", "192.168.1.45:3001", false}, // port not in registry
+		{"http://evil.example.com:4001", "192.168.1.45:3001", false},
+		{"http://192.168.1.45", "192.168.1.45:3001", false},
+		{"null", "192.168.1.45:3001", false},
+	}
+	for _, tt := range tests {
+		if got := p.Allowed(tt.origin, tt.host); got != tt.want {
+			t.Errorf("Allowed(%q, %q) = %v, want %v", tt.origin, tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestCORSPolicyMiddleware(t *testing.T) {
+	handler := testPolicy().Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	r := httptest.NewRequest("OPTIONS", "http://192.168.1.45:4001/api/game", nil)
+	r.Header.Set("Origin", "http://192.168.1.45:3001")
+	r.Header.Set("Access-Control-Request-Method", "POST")
+	r.Header.Set("Access-Control-Request-Headers", "Authorization, X-CSRF-Token")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "http://192.168.1.45:3001" {
+		t.Errorf("Allow-Origin = %q", got)
+	}
+	if w.Header().Get("Access-Control-Allow-Credentials") != "true" || w.Code != http.StatusNoContent {
+		t.Errorf("preflight: code %d, headers %v", w.Code, w.Header())
+	}
+
+	r = httptest.NewRequest("GET", "http://192.168.1.45:4001/api/game", nil)
+	r.Header.Set("Origin", "http://evil.example.com")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("disallowed origin got Allow-Origin %q", got)
+	}
+}
+
+func TestVersioned(t *testing.T) {
+	var gotPath string
+	handler := Versioned(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+	}))
+
+	// Versioned path is served by the /api route
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/game/g-1?player=a", nil))
+	if gotPath != "/api/game/g-1" || w.Header().Get("API-Version") != "v1" {
+		t.Errorf("v1: path %q, headers %v", gotPath, w.Header())
+	}
+	if w.Header().Get("Deprecation") != "" {
+		t.Error("Versioned path should not be deprecated")
+	}
+
+	// Legacy path still works, flagged with its successor
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/api/game/g-1?player=a", nil))
+	if gotPath != "/api/game/g-1" {
+		t.Errorf("legacy: path %q", gotPath)
+	}
+	if w.Header().Get("Deprecation") == "" || w.Header().Get("Sunset") == "" {
+		t.Errorf("legacy: missing deprecation headers %v", w.Header())
+	}
+	if got := w.Header().Get("Link"); got != `</api/v1/game/g-1?player=a>; rel="successor-version"` {
+		t.Errorf("legacy: Link = %q", got)
+	}
+
+	// Unknown versions are rejected; non-API paths pass through untouched
+	gotPath = ""
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/api/v9/game", nil))
+	if w.Code != http.StatusNotFound || gotPath != "" {
+		t.Errorf("v9: code %d, path %q", w.Code, gotPath)
+	}
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/static/app.js", nil))
+	if gotPath != "/static/app.js" || w.Header().Get("Deprecation") != "" {
+		t.Errorf("static: path %q, headers %v", gotPath, w.Header())
+	}
+}
+
+func TestConfigHandler(t *testing.T) {
+	base := AppConfig{
+		AppID:        "dots",
+		Name:         "Dots & Boxes",
+		Icon:         "🔵",
+		Capabilities: Capabilities{Challenges: true, SSE: true},
+		Extra:        map[string]interface{}{"gridSizes": 4},
+	}
+	cfg := base.With(map[string]interface{}{"appId": "spoofed", "permissionLevel": "full"})
+
+	w := httptest.NewRecorder()
+	ConfigHandler(cfg)(w, httptest.NewRequest("GET", "/api/config", nil))
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got["appId"] != "dots" {
+		t.Errorf("appId = %v, extras must not replace standard fields", got["appId"])
+	}
+	if got["version"] != "1.0.0" || got["permissionLevel"] != "full" || got["gridSizes"] != float64(4) {
+		t.Errorf("unexpected config %v", got)
+	}
+	caps, _ := got["capabilities"].(map[string]interface{})
+	if caps["challenges"] != true || caps["sse"] != true || caps["displays"] != false || caps["teams"] != false {
+		t.Errorf("capabilities = %v", got["capabilities"])
+	}
+	if opts, ok := got["gameOptions"].([]interface{}); !ok || len(opts) != 0 {
+		t.Errorf("gameOptions = %v, want empty list", got["gameOptions"])
+	}
+	if api, _ := got["api"].(map[string]interface{}); api["current"] != APIVersion {
+		t.Errorf("api = %v", got["api"])
+	}
+	if _, ok := base.Extra["permissionLevel"]; ok {
+		t.Error("With modified the original config")
+	}
+}
@@ -7,6 +7,8 @@ import (
 
 // CORSMiddleware adds CORS headers to all responses.
 //
+// Deprecated: allows any origin. Use NewCORSPolicy(identityDB).Middleware.
+//
 // Usage:
 //   r := mux.NewRouter()
 //   r.Use(http.CORSMiddleware)
@@ -0,0 +1,134 @@
+package http
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// API versioning shared by every backend.
+//
+// Routes are registered once, under /api as before. Versioned wraps the router
+// so the same routes answer at /api/v1/..., the path frontends and displays
+// should call. The unversioned /api/... paths stay as aliases until
+// LegacyAPISunset, answering with Deprecation and Sunset headers (RFC 9745,
+// RFC 8594) and a Link to the versioned path.
+//
+// A breaking change to a route ships under the next version (/api/v2) while
+// the previous version keeps working for at least LegacyAPIGrace, announced
+// the same way.
+
+// APIVersion is the current API version
+const APIVersion = "v1"
+
+// LegacyAPIGrace is the minimum time a deprecated version or path keeps working
+const LegacyAPIGrace = 180 * 24 * time.Hour
+
+// LegacyAPIDeprecated is when the unversioned /api paths were deprecated
+var LegacyAPIDeprecated = time.Date(2026, time.October, 17, 0, 0, 0, 0, time.UTC)
+
+// LegacyAPISunset is when the unversioned /api paths may be removed
+var LegacyAPISunset = LegacyAPIDeprecated.Add(LegacyAPIGrace)
+
+// supportedAPIVersions are the versions Versioned serves
+var supportedAPIVersions = []string{APIVersion}
+
+// versionedPath matches /api/v{n} and /api/v{n}/...
+var versionedPath = regexp.MustCompile(`^/api/(v[0-9]+)(/.*)?$`)
+
+// VersionHeaders lists the response headers Versioned sets, for
+// Access-Control-Expose-Headers
+var VersionHeaders = []string{"API-Version", "Deprecation", "Sunset", "Link"}
+
+// APIVersionPolicy describes versioning for clients; backends include it in
+// GET /api/config.
+type APIVersionPolicy struct {
+	Current          string    `json:"current"`
+	Supported        []string  `json:"supported"`
+	Prefix           string    `json:"prefix"`
+	LegacyPrefix     string    `json:"legacyPrefix"`
+	LegacyDeprecated time.Time `json:"legacyDeprecated"`
+	LegacySunset     time.Time `json:"legacySunset"`
+	GraceDays        int       `json:"graceDays"`
+	Policy           string    `json:"policy"`
+}
+
+// VersionPolicy returns the platform's API versioning policy.
+func VersionPolicy() APIVersionPolicy {
+	return APIVersionPolicy{
+		Current:          APIVersion,
+		Supported:        append([]string(nil), supportedAPIVersions...),
+		Prefix:           "/api/" + APIVersion,
+		LegacyPrefix:     "/api",
+		LegacyDeprecated: LegacyAPIDeprecated,
+		LegacySunset:     LegacyAPISunset,
+		GraceDays:        int(LegacyAPIGrace / (24 * time.Hour)),
+		Policy: "Call /api/" + APIVersion + "/... paths. Breaking changes ship under a new version; " +
+			"deprecated versions and the unversioned /api/... paths keep working for at least graceDays, " +
+			"and answer with Deprecation, Sunset and Link (rel=\"successor-version\") headers until they are removed.",
+	}
+}
+
+// Versioned serves the router's /api routes under /api/v1 as well, marks the
+// unversioned paths deprecated and rejects unknown versions. Paths outside
+// /api (static files, the frontend) pass through untouched.
+//
+// Usage:
+//
+//	cors := apphttp.NewCORSPolicy(identityDB)
+//	log.Fatal(http.ListenAndServe(":"+port, cors.Middleware(apphttp.Versioned(r))))
+func Versioned(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Path
+		if path != "/api" && !strings.HasPrefix(path, "/api/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if m := versionedPath.FindStringSubmatch(path); m != nil {
+			version, rest := m[1], m[2]
+			if !supportedVersion(version) {
+				ErrorJSON(w, "Unsupported API version "+version+" (current: "+APIVersion+")", http.StatusNotFound)
+				return
+			}
+			w.Header().Set("API-Version", version)
+			next.ServeHTTP(w, stripVersion(r, version, rest))
+			return
+		}
+
+		// Legacy alias: same route, flagged for removal
+		successor := "/api/" + APIVersion + strings.TrimPrefix(path, "/api")
+		if r.URL.RawQuery != "" {
+			successor += "?" + r.URL.RawQuery
+		}
+		w.Header().Set("API-Version", APIVersion)
+		w.Header().Set("Deprecation", "@"+strconv.FormatInt(LegacyAPIDeprecated.Unix(), 10))
+		w.Header().Set("Sunset", LegacyAPISunset.Format(http.TimeFormat))
+		w.Header().Add("Link", "<"+successor+`>; rel="successor-version"`)
+		next.ServeHTTP(w, r)
+	})
+}
+
+func supportedVersion(version string) bool {
+	for _, v := range supportedAPIVersions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// stripVersion returns a shallow copy of r with the version taken out of its
+// path, as http.StripPrefix does
+func stripVersion(r *http.Request, version, rest string) *http.Request {
+	r2 := new(http.Request)
+	*r2 = *r
+	r2.URL = new(url.URL)
+	*r2.URL = *r.URL
+	r2.URL.Path = "/api" + rest
+	r2.URL.RawPath = strings.Replace(r.URL.RawPath, "/api/"+version, "/api", 1)
+	return r2
+}
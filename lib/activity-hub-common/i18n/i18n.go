@@ -0,0 +1,236 @@
+// Package i18n translates the user-facing strings backends send: error
+// messages, lobby notices and quiz prompts. Messages live in per-locale
+// catalogs keyed by a stable code, which error responses also carry so
+// clients can react to an error without matching its text.
+//
+// A request's locale is the signed-in user's "locale" setting (put in the
+// request context by the auth middleware), else the best match for the
+// browser's Accept-Language, else DefaultLocale. A message missing from a
+// locale's catalog falls back to the base language ("fr-CA" to "fr"), then to
+// English, then to the key itself, so an untranslated string never blanks.
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultLocale is used when neither the user nor the browser names a
+// locale we have a catalog for
+const DefaultLocale = "en"
+
+// Catalog maps message keys to one locale's messages. Messages are fmt
+// formats; translations that reorder arguments use explicit indexes (%[2]s).
+type Catalog map[string]string
+
+var (
+	mu       sync.RWMutex
+	catalogs = map[string]Catalog{}
+)
+
+//go:embed catalogs/*.json
+var builtin embed.FS
+
+func init() {
+	if err := LoadFS(builtin, "catalogs"); err != nil {
+		panic(err)
+	}
+}
+
+// Register adds messages to a locale's catalog. Later registrations win, so
+// an app can reword a platform message as well as add its own.
+func Register(locale string, messages Catalog) {
+	locale = normalize(locale)
+	mu.Lock()
+	defer mu.Unlock()
+	c, ok := catalogs[locale]
+	if !ok {
+		c = Catalog{}
+		catalogs[locale] = c
+	}
+	for k, v := range messages {
+		c[k] = v
+	}
+}
+
+// LoadFS registers every <locale>.json in dir, each a flat object of key to
+// message.
+//
+// Usage:
+//
+//	//go:embed i18n/*.json
+//	var messages embed.FS
+//
+//	if err := i18n.LoadFS(messages, "i18n"); err != nil {
+//	    log.Fatal(err)
+//	}
+func LoadFS(fsys fs.FS, dir string) error {
+	files, err := fs.Glob(fsys, path.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		raw, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return err
+		}
+		var messages Catalog
+		if err := json.Unmarshal(raw, &messages); err != nil {
+			return fmt.Errorf("i18n catalog %s: %w", file, err)
+		}
+		Register(strings.TrimSuffix(path.Base(file), ".json"), messages)
+	}
+	return nil
+}
+
+// Locales lists the locales that have a catalog, sorted
+func Locales() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	locales := make([]string, 0, len(catalogs))
+	for l := range catalogs {
+		locales = append(locales, l)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Supported returns the catalog locale to use for locale: itself, or its base
+// language, or "" when there's neither
+func Supported(locale string) string {
+	locale = normalize(locale)
+	mu.RLock()
+	defer mu.RUnlock()
+	if _, ok := catalogs[locale]; ok {
+		return locale
+	}
+	if base := baseLanguage(locale); base != locale {
+		if _, ok := catalogs[base]; ok {
+			return base
+		}
+	}
+	return ""
+}
+
+// Translate returns key's message in locale, formatted with args
+func Translate(locale, key string, args ...interface{}) string {
+	locale = normalize(locale)
+	msg := lookup(key, locale, baseLanguage(locale), DefaultLocale)
+	if msg == "" {
+		msg = key
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+func lookup(key string, locales ...string) string {
+	mu.RLock()
+	defer mu.RUnlock()
+	for _, l := range locales {
+		if msg, ok := catalogs[l][key]; ok {
+			return msg
+		}
+	}
+	return ""
+}
+
+// Negotiate picks the best supported locale from an Accept-Language header,
+// e.g. "fr-CH, fr;q=0.9, en;q=0.8". It returns DefaultLocale when nothing
+// listed is supported.
+func Negotiate(acceptLanguage string) string {
+	type tag struct {
+		locale string
+		q      float64
+	}
+	var tags []tag
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		t := tag{locale: strings.TrimSpace(fields[0]), q: 1}
+		for _, param := range fields[1:] {
+			if v, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				if q, err := strconv.ParseFloat(v, 64); err == nil {
+					t.q = q
+				}
+			}
+		}
+		if t.locale != "" && t.locale != "*" && t.q > 0 {
+			tags = append(tags, t)
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+
+	for _, t := range tags {
+		if l := Supported(t.locale); l != "" {
+			return l
+		}
+	}
+	return DefaultLocale
+}
+
+type contextKey struct{}
+
+// WithLocale returns ctx carrying a user's chosen locale. The auth middleware
+// calls it for users with a locale setting.
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, contextKey{}, locale)
+}
+
+// FromContext returns the locale set by WithLocale
+func FromContext(ctx context.Context) (string, bool) {
+	locale, ok := ctx.Value(contextKey{}).(string)
+	return locale, ok && locale != ""
+}
+
+// Locale returns the request's locale: the user's setting, else
+// Accept-Language, else DefaultLocale
+func Locale(r *http.Request) string {
+	if locale, ok := FromContext(r.Context()); ok {
+		if l := Supported(locale); l != "" {
+			return l
+		}
+	}
+	return Negotiate(r.Header.Get("Accept-Language"))
+}
+
+// T translates key for the request's locale
+//
+// Usage:
+//
+//	msg := i18n.T(r, "team_full")
+func T(r *http.Request, key string, args ...interface{}) string {
+	return Translate(Locale(r), key, args...)
+}
+
+// Error writes {"error": message, "code": key} with the given status, the
+// message translated for the request's locale
+//
+// Usage:
+//
+//	i18n.Error(w, r, "session_not_found", http.StatusNotFound)
+func Error(w http.ResponseWriter, r *http.Request, key string, status int, args ...interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Language", Locale(r))
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": T(r, key, args...), "code": key})
+}
+
+// normalize lowercases a locale and uses "-" between its parts ("pt_BR" to "pt-br")
+func normalize(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
+
+func baseLanguage(locale string) string {
+	base, _, _ := strings.Cut(locale, "-")
+	return base
+}
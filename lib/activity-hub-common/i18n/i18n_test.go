@@ -0,0 +1,127 @@
+package i18n
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+func TestTranslateFallsBack(t *testing.T) {
+	Register("fr", Catalog{"test_greeting": "Bonjour %s"})
+	Register("en", Catalog{"test_greeting": "Hello %s", "test_only_english": "Only English"})
+
+	cases := []struct {
+		locale, key, want string
+	}{
+		{"fr", "test_greeting", "Bonjour Sam"},
+		{"fr-CA", "test_greeting", "Bonjour Sam"}, // Base language
+		{"FR_ca", "test_greeting", "Bonjour Sam"}, // Normalized
+		{"de", "test_greeting", "Hello Sam"},      // No catalog: English
+		{"fr", "test_only_english", "Only English"},
+		{"fr", "test_missing_key", "test_missing_key"},
+	}
+	for _, c := range cases {
+		var got string
+		if c.key == "test_greeting" {
+			got = Translate(c.locale, c.key, "Sam")
+		} else {
+			got = Translate(c.locale, c.key)
+		}
+		if got != c.want {
+			t.Errorf("Translate(%q, %q) = %q, want %q", c.locale, c.key, got, c.want)
+		}
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	cases := map[string]string{
+		"":                                 DefaultLocale,
+		"fr-CH, fr;q=0.9, en;q=0.8":        "fr",
+		"de-DE, es;q=0.5":                  "es",
+		"en;q=0.2, es;q=0.9":               "es",
+		"de, *;q=0.5":                      DefaultLocale,
+		"es;q=0, fr;q=0.1":                 "fr",
+		"  ES-mx ;q=0.7 , xx":              "es",
+		"not a header at all;;;q=nonsense": DefaultLocale,
+	}
+	for header, want := range cases {
+		if got := Negotiate(header); got != want {
+			t.Errorf("Negotiate(%q) = %q, want %q", header, got, want)
+		}
+	}
+}
+
+func TestLocalePrefersUserSetting(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept-Language", "es")
+	if got := Locale(r); got != "es" {
+		t.Errorf("Locale() from Accept-Language = %q, want es", got)
+	}
+
+	r = r.WithContext(WithLocale(r.Context(), "fr"))
+	if got := Locale(r); got != "fr" {
+		t.Errorf("Locale() with user setting = %q, want fr", got)
+	}
+
+	// A setting we have no catalog for falls back to the browser
+	r = r.WithContext(WithLocale(r.Context(), "xx"))
+	if got := Locale(r); got != "es" {
+		t.Errorf("Locale() with unsupported setting = %q, want es", got)
+	}
+}
+
+func TestError(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept-Language", "fr")
+	w := httptest.NewRecorder()
+
+	Error(w, r, "not_found", http.StatusNotFound)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d", w.Code)
+	}
+	if got := w.Header().Get("Content-Language"); got != "fr" {
+		t.Errorf("Content-Language = %q", got)
+	}
+	var body map[string]string
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if body["code"] != "not_found" || body["error"] != Translate("fr", "not_found") {
+		t.Errorf("body = %v", body)
+	}
+}
+
+func TestLoadFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"i18n/en.json": {Data: []byte(`{"test_loaded": "Loaded"}`)},
+		"i18n/es.json": {Data: []byte(`{"test_loaded": "Cargado"}`)},
+	}
+	if err := LoadFS(fsys, "i18n"); err != nil {
+		t.Fatal(err)
+	}
+	if got := Translate("es", "test_loaded"); got != "Cargado" {
+		t.Errorf("Translate = %q", got)
+	}
+
+	bad := fstest.MapFS{"i18n/en.json": {Data: []byte(`["not", "an", "object"]`)}}
+	if err := LoadFS(bad, "i18n"); err == nil {
+		t.Error("expected an error for a malformed catalog")
+	}
+}
+
+func TestBuiltinCatalogsMatch(t *testing.T) {
+	// Every platform message is translated in every built-in catalog
+	for _, locale := range []string{"fr", "es"} {
+		for key := range catalogs[DefaultLocale] {
+			if len(key) > 5 && key[:5] == "test_" {
+				continue
+			}
+			if _, ok := catalogs[locale][key]; !ok {
+				t.Errorf("%s catalog is missing %q", locale, key)
+			}
+		}
+	}
+}
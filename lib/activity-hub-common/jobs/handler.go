@@ -0,0 +1,70 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+// AdminHandler exposes the scheduler for admin UIs. Mount it behind your
+// admin auth middleware - it performs no authorization of its own.
+//
+//	GET  ?                      list jobs with next/last run
+//	GET  ?job={name}&limit=20   run history for one job
+//	POST ?job={name}            trigger a job now (runs in the background)
+//
+// Usage:
+//
+//	r.Handle("/api/admin/jobs", auth.Middleware(identityDB)(auth.RequireRole("super_user")(scheduler.AdminHandler())))
+func (s *Scheduler) AdminHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Query().Get("job")
+
+		switch r.Method {
+		case http.MethodGet:
+			if name == "" {
+				writeJSON(w, http.StatusOK, map[string]interface{}{"jobs": s.Jobs(r.Context())})
+				return
+			}
+
+			limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+			runs, err := s.History(r.Context(), name, limit)
+			if err != nil {
+				writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+				return
+			}
+			writeJSON(w, http.StatusOK, map[string]interface{}{"job": name, "runs": runs})
+
+		case http.MethodPost:
+			s.mu.RLock()
+			_, ok := s.jobs[name]
+			s.mu.RUnlock()
+			if !ok {
+				writeJSON(w, http.StatusNotFound, map[string]string{"error": "unknown job: " + name})
+				return
+			}
+
+			// Run detached from the request so slow jobs don't hold the connection open
+			go func() {
+				if _, err := s.RunNow(context.Background(), name); err != nil {
+					log.Printf("⚠️  Manual run of %s not started: %v", name, err)
+				}
+			}()
+			log.Printf("🔄 Job %s triggered manually", name)
+			writeJSON(w, http.StatusAccepted, map[string]interface{}{"success": true, "job": name})
+
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		log.Printf("❌ Failed to encode JSON response: %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// Unit tests for jobs package
+
+// Integration tests (require Redis on port 6379)
+// Run with: go test -tags=integration ./...
+
+// TODO: Add integration tests for slot claiming across instances
+// TODO: Add integration tests for RunNow lock contention
+
+func TestEveryAlignsToInterval(t *testing.T) {
+	s := Every(15 * time.Minute)
+	after := time.Date(2026, 3, 1, 10, 7, 30, 0, time.UTC)
+
+	next := s.Next(after)
+	want := time.Date(2026, 3, 1, 10, 15, 0, 0, time.UTC)
+	if !next.Equal(want) {
+		t.Errorf("Next(%v) = %v, want %v", after, next, want)
+	}
+
+	// Exactly on a slot boundary moves to the following slot
+	if got := s.Next(want); !got.Equal(want.Add(15 * time.Minute)) {
+		t.Errorf("Next(%v) = %v, want %v", want, got, want.Add(15*time.Minute))
+	}
+
+	if s.String() != "every 15m0s" {
+		t.Errorf("String() = %q", s.String())
+	}
+}
+
+func TestEveryPanicsOnNonPositiveInterval(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Every(0) should panic")
+		}
+	}()
+	Every(0)
+}
+
+func TestDailyAt(t *testing.T) {
+	s := DailyAt(4, 30)
+
+	before := time.Date(2026, 3, 1, 2, 0, 0, 0, time.UTC)
+	if got, want := s.Next(before), time.Date(2026, 3, 1, 4, 30, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("Next(%v) = %v, want %v", before, got, want)
+	}
+
+	afterTime := time.Date(2026, 3, 1, 4, 30, 0, 0, time.UTC)
+	if got, want := s.Next(afterTime), time.Date(2026, 3, 2, 4, 30, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("Next(%v) = %v, want %v", afterTime, got, want)
+	}
+
+	if s.String() != "daily at 04:30" {
+		t.Errorf("String() = %q", s.String())
+	}
+}
+
+func TestOffPeakSkipsPeakSlots(t *testing.T) {
+	// Open 11:00-23:00
+	peak := func(at time.Time) bool { return at.Hour() >= 11 && at.Hour() < 23 }
+	s := OffPeak(Every(time.Hour), peak)
+
+	after := time.Date(2026, 3, 1, 10, 30, 0, 0, time.UTC)
+	if got, want := s.Next(after), time.Date(2026, 3, 1, 23, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("Next(%v) = %v, want %v", after, got, want)
+	}
+
+	// Always busy: runs at the next slot anyway rather than never
+	always := OffPeak(Every(time.Hour), func(time.Time) bool { return true })
+	if got, want := always.Next(after), time.Date(2026, 3, 1, 11, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("Next(%v) = %v, want %v", after, got, want)
+	}
+
+	if s.String() != "every 1h0m0s, off-peak" {
+		t.Errorf("String() = %q", s.String())
+	}
+}
+
+func TestSafeRunRecoversPanic(t *testing.T) {
+	err := safeRun(context.Background(), func(ctx context.Context) error {
+		panic("boom")
+	})
+	if err == nil || err.Error() != "panic: boom" {
+		t.Errorf("safeRun() = %v, want panic error", err)
+	}
+
+	want := errors.New("failed")
+	if err := safeRun(context.Background(), func(ctx context.Context) error { return want }); err != want {
+		t.Errorf("safeRun() = %v, want %v", err, want)
+	}
+}
+
+func TestKeyNamespacing(t *testing.T) {
+	s := &Scheduler{service: "leaderboard"}
+	if got := s.key("slot", "cleanup", "1700000000"); got != "jobs:leaderboard:slot:cleanup:1700000000" {
+		t.Errorf("key() = %q", got)
+	}
+}
+
+func TestSafeRunTaskRecoversPanic(t *testing.T) {
+	_, err := safeRunTask(context.Background(), func(ctx context.Context, p *Progress) (interface{}, error) {
+		panic("bad round")
+	}, &Progress{})
+	if err == nil || err.Error() != "panic: bad round" {
+		t.Errorf("safeRunTask() = %v, want panic error", err)
+	}
+
+	result, err := safeRunTask(context.Background(), func(ctx context.Context, p *Progress) (interface{}, error) {
+		return map[string]int{"processed": 3}, nil
+	}, &Progress{})
+	if err != nil || result.(map[string]int)["processed"] != 3 {
+		t.Errorf("safeRunTask() = %v, %v", result, err)
+	}
+}
+
+func TestTaskKeysAndIDs(t *testing.T) {
+	s := &Scheduler{service: "game-admin"}
+	if got := s.key("task-lock", "lms-round", "12/R3"); got != "jobs:game-admin:task-lock:lms-round:12/R3" {
+		t.Errorf("key() = %q", got)
+	}
+
+	a, b := newTaskID(), newTaskID()
+	if len(a) != 16 || a == b {
+		t.Errorf("newTaskID() = %q, %q; want distinct 16-char IDs", a, b)
+	}
+}
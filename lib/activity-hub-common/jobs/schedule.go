@@ -0,0 +1,101 @@
+package jobs
+
+import (
+	"fmt"
+	"time"
+)
+
+// Schedule decides when a job next runs.
+// Next must be deterministic for a given time so every instance computes the
+// same run slots - that is what lets the Redis slot claim deduplicate runs.
+type Schedule interface {
+	Next(after time.Time) time.Time
+	String() string
+}
+
+// Every runs a job at a fixed interval, aligned to the Unix epoch
+// (Every(time.Hour) fires on the hour, Every(15*time.Minute) at :00, :15, :30, :45).
+//
+// Usage:
+//
+//	scheduler.Register("challenge-cleanup", jobs.Every(5*time.Minute), cleanupChallenges)
+func Every(interval time.Duration) Schedule {
+	if interval <= 0 {
+		panic("jobs.Every: interval must be positive")
+	}
+	return everySchedule{interval: interval}
+}
+
+type everySchedule struct {
+	interval time.Duration
+}
+
+func (s everySchedule) Next(after time.Time) time.Time {
+	return after.Truncate(s.interval).Add(s.interval)
+}
+
+func (s everySchedule) String() string {
+	return "every " + s.interval.String()
+}
+
+// DailyAt runs a job once a day at the given local time.
+//
+// Usage:
+//
+//	scheduler.Register("season-rollover", jobs.DailyAt(4, 0), rolloverSeasons)
+func DailyAt(hour, minute int) Schedule {
+	if hour < 0 || hour > 23 || minute < 0 || minute > 59 {
+		panic("jobs.DailyAt: invalid time of day")
+	}
+	return dailySchedule{hour: hour, minute: minute}
+}
+
+type dailySchedule struct {
+	hour, minute int
+}
+
+func (s dailySchedule) Next(after time.Time) time.Time {
+	next := time.Date(after.Year(), after.Month(), after.Day(), s.hour, s.minute, 0, 0, after.Location())
+	if !next.After(after) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+func (s dailySchedule) String() string {
+	return fmt.Sprintf("daily at %02d:%02d", s.hour, s.minute)
+}
+
+// offPeakHorizon bounds how far OffPeak looks for a quiet slot; a job whose
+// every slot in that time is peak runs at its next slot regardless
+const offPeakHorizon = 8 * 24 * time.Hour
+
+// OffPeak skips a schedule's slots that fall in peak times, for maintenance
+// that shouldn't compete with a busy pub. peak is usually an opening hours
+// check such as hours.Hours.Peak.
+//
+// Usage:
+//
+//	scheduler.Register("trash-purge", jobs.OffPeak(jobs.Every(time.Hour), openingHours.Peak), purgeTrash)
+func OffPeak(schedule Schedule, peak func(time.Time) bool) Schedule {
+	return offPeakSchedule{schedule: schedule, peak: peak}
+}
+
+type offPeakSchedule struct {
+	schedule Schedule
+	peak     func(time.Time) bool
+}
+
+func (s offPeakSchedule) Next(after time.Time) time.Time {
+	first := s.schedule.Next(after)
+	for next := first; next.Sub(after) <= offPeakHorizon; next = s.schedule.Next(next) {
+		if !s.peak(next) {
+			return next
+		}
+	}
+	return first
+}
+
+func (s offPeakSchedule) String() string {
+	return s.schedule.String() + ", off-peak"
+}
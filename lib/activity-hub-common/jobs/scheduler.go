@@ -0,0 +1,298 @@
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// historyLimit is how many past runs are kept per job
+const historyLimit = 50
+
+// JobFunc is the work a job performs. It should respect ctx cancellation.
+type JobFunc func(ctx context.Context) error
+
+// Run records one execution of a job
+type Run struct {
+	Job        string    `json:"job"`
+	Instance   string    `json:"instance"`
+	Trigger    string    `json:"trigger"` // "schedule" or "manual"
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt"`
+	DurationMs int64     `json:"durationMs"`
+	Success    bool      `json:"success"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// JobInfo describes a registered job for admin listings
+type JobInfo struct {
+	Name     string    `json:"name"`
+	Schedule string    `json:"schedule"`
+	Timeout  string    `json:"timeout"`
+	NextRun  time.Time `json:"nextRun"`
+	LastRun  *Run      `json:"lastRun,omitempty"`
+	Running  bool      `json:"running"`
+}
+
+type job struct {
+	name     string
+	schedule Schedule
+	timeout  time.Duration
+	fn       JobFunc
+}
+
+// Scheduler runs registered jobs on their schedules. Any number of backend
+// instances may run the same Scheduler; Redis guarantees each scheduled slot
+// runs once and a job never overlaps itself.
+type Scheduler struct {
+	client   *redis.Client
+	service  string
+	instance string
+
+	mu   sync.RWMutex
+	jobs map[string]*job
+}
+
+// ErrJobRunning is returned by RunNow when another instance holds the job lock,
+// and by Submit when the same task is already running
+var ErrJobRunning = fmt.Errorf("job is already running")
+
+// New creates a Scheduler. service namespaces the Redis keys so two apps can
+// register jobs with the same name without clashing.
+//
+// Usage:
+//
+//	redisClient, _ := redis.InitRedis()
+//	scheduler := jobs.New(redisClient, "tic-tac-toe")
+//	scheduler.Register("stale-games", jobs.Every(10*time.Minute), cleanupStaleGames)
+//	go scheduler.Start(ctx)
+func New(client *redis.Client, service string) *Scheduler {
+	host, _ := os.Hostname()
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+
+	return &Scheduler{
+		client:   client,
+		service:  service,
+		instance: fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(suffix)),
+		jobs:     map[string]*job{},
+	}
+}
+
+// Register adds a job. The default timeout is 5 minutes; use RegisterWithTimeout
+// for long-running work. Registering the same name twice replaces the job.
+func (s *Scheduler) Register(name string, schedule Schedule, fn JobFunc) {
+	s.RegisterWithTimeout(name, schedule, 5*time.Minute, fn)
+}
+
+// RegisterWithTimeout adds a job with an explicit timeout.
+// The timeout also bounds how long the job's Redis lock is held.
+func (s *Scheduler) RegisterWithTimeout(name string, schedule Schedule, timeout time.Duration, fn JobFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[name] = &job{name: name, schedule: schedule, timeout: timeout, fn: fn}
+}
+
+// Start runs the scheduling loop until ctx is cancelled.
+func (s *Scheduler) Start(ctx context.Context) {
+	var wg sync.WaitGroup
+	s.mu.RLock()
+	for _, j := range s.jobs {
+		log.Printf("🕐 Job scheduled: %s (%s)", j.name, j.schedule)
+		wg.Add(1)
+		go func(j *job) {
+			defer wg.Done()
+			s.loop(ctx, j)
+		}(j)
+	}
+	s.mu.RUnlock()
+	wg.Wait()
+}
+
+// loop waits for each scheduled slot and claims it before running
+func (s *Scheduler) loop(ctx context.Context, j *job) {
+	for {
+		due := j.schedule.Next(time.Now())
+		timer := time.NewTimer(time.Until(due))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		// Only one instance wins each slot
+		slotKey := s.key("slot", j.name, fmt.Sprint(due.Unix()))
+		claimed, err := s.client.SetNX(ctx, slotKey, s.instance, 24*time.Hour).Result()
+		if err != nil {
+			log.Printf("⚠️  Job %s: failed to claim slot: %v", j.name, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		if _, err := s.execute(ctx, j, "schedule"); err == ErrJobRunning {
+			log.Printf("⚠️  Job %s: previous run still in progress, skipping slot", j.name)
+		}
+	}
+}
+
+// RunNow triggers a job immediately (e.g. from an admin endpoint).
+// Returns ErrJobRunning if the job is already running on any instance.
+func (s *Scheduler) RunNow(ctx context.Context, name string) (*Run, error) {
+	s.mu.RLock()
+	j, ok := s.jobs[name]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown job: %s", name)
+	}
+	return s.execute(ctx, j, "manual")
+}
+
+// execute runs a job under its lock and records the result in history
+func (s *Scheduler) execute(ctx context.Context, j *job, trigger string) (*Run, error) {
+	lockKey := s.key("lock", j.name)
+	locked, err := s.client.SetNX(ctx, lockKey, s.instance, j.timeout+time.Minute).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire job lock: %w", err)
+	}
+	if !locked {
+		return nil, ErrJobRunning
+	}
+	defer s.releaseLock(lockKey)
+
+	run := Run{Job: j.name, Instance: s.instance, Trigger: trigger, StartedAt: time.Now()}
+
+	jobCtx, cancel := context.WithTimeout(ctx, j.timeout)
+	err = safeRun(jobCtx, j.fn)
+	cancel()
+
+	run.FinishedAt = time.Now()
+	run.DurationMs = run.FinishedAt.Sub(run.StartedAt).Milliseconds()
+	run.Success = err == nil
+	if err != nil {
+		run.Error = err.Error()
+		log.Printf("❌ Job %s failed after %dms: %v", j.name, run.DurationMs, err)
+	} else {
+		log.Printf("✅ Job %s completed in %dms", j.name, run.DurationMs)
+	}
+
+	s.recordRun(run)
+	return &run, nil
+}
+
+// safeRun converts a panicking job into an error so one bad job can't kill the backend
+func safeRun(ctx context.Context, fn JobFunc) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return fn(ctx)
+}
+
+// releaseLockScript deletes the lock only if its owner still holds it
+var releaseLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+func (s *Scheduler) releaseLock(lockKey string) {
+	s.releaseLockFor(lockKey, s.instance)
+}
+
+// releaseLockFor deletes a lock only if it still holds owner (a task's lock
+// holds the task ID rather than the instance)
+func (s *Scheduler) releaseLockFor(lockKey, owner string) {
+	if err := releaseLockScript.Run(context.Background(), s.client, []string{lockKey}, owner).Err(); err != nil && err != redis.Nil {
+		log.Printf("⚠️  Failed to release job lock %s: %v", lockKey, err)
+	}
+}
+
+func (s *Scheduler) recordRun(run Run) {
+	data, err := json.Marshal(run)
+	if err != nil {
+		return
+	}
+
+	ctx := context.Background()
+	historyKey := s.key("history", run.Job)
+	pipe := s.client.TxPipeline()
+	pipe.LPush(ctx, historyKey, data)
+	pipe.LTrim(ctx, historyKey, 0, historyLimit-1)
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("⚠️  Failed to record run history for %s: %v", run.Job, err)
+	}
+}
+
+// History returns the most recent runs of a job, newest first.
+func (s *Scheduler) History(ctx context.Context, name string, limit int) ([]Run, error) {
+	if limit <= 0 || limit > historyLimit {
+		limit = historyLimit
+	}
+
+	entries, err := s.client.LRange(ctx, s.key("history", name), 0, int64(limit-1)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load job history: %w", err)
+	}
+
+	runs := make([]Run, 0, len(entries))
+	for _, entry := range entries {
+		var run Run
+		if json.Unmarshal([]byte(entry), &run) == nil {
+			runs = append(runs, run)
+		}
+	}
+	return runs, nil
+}
+
+// Jobs lists registered jobs with their next run, last run and running state.
+func (s *Scheduler) Jobs(ctx context.Context) []JobInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+	infos := make([]JobInfo, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		info := JobInfo{
+			Name:     j.name,
+			Schedule: j.schedule.String(),
+			Timeout:  j.timeout.String(),
+			NextRun:  j.schedule.Next(now),
+		}
+
+		if history, err := s.History(ctx, j.name, 1); err == nil && len(history) > 0 {
+			info.LastRun = &history[0]
+		}
+		if n, err := s.client.Exists(ctx, s.key("lock", j.name)).Result(); err == nil {
+			info.Running = n > 0
+		}
+
+		infos = append(infos, info)
+	}
+
+	sort.Slice(infos, func(a, b int) bool { return infos[a].Name < infos[b].Name })
+	return infos
+}
+
+// key builds a namespaced Redis key: jobs:{service}:{parts...}
+func (s *Scheduler) key(kind string, parts ...string) string {
+	k := "jobs:" + s.service + ":" + kind
+	for _, p := range parts {
+		k += ":" + p
+	}
+	return k
+}
@@ -0,0 +1,168 @@
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// taskTTL is how long a task's status stays readable after it was last updated
+const taskTTL = 24 * time.Hour
+
+// Task states
+const (
+	TaskRunning   = "running"
+	TaskSucceeded = "succeeded"
+	TaskFailed    = "failed"
+)
+
+// ErrTaskNotFound is returned by Task for unknown or expired task IDs
+var ErrTaskNotFound = fmt.Errorf("task not found")
+
+// TaskFunc is one-off background work started with Submit. It reports
+// progress as it goes and returns a JSON-encodable result.
+type TaskFunc func(ctx context.Context, progress *Progress) (interface{}, error)
+
+// TaskStatus is a task's state as stored in Redis and served to clients
+type TaskStatus struct {
+	ID         string          `json:"id"`
+	Name       string          `json:"name"`
+	Key        string          `json:"key,omitempty"`
+	Instance   string          `json:"instance"`
+	State      string          `json:"state"`
+	Done       int             `json:"done"`
+	Total      int             `json:"total"`
+	Message    string          `json:"message,omitempty"`
+	Result     json.RawMessage `json:"result,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	StartedAt  time.Time       `json:"startedAt"`
+	FinishedAt *time.Time      `json:"finishedAt,omitempty"`
+}
+
+// Progress lets a running task report how far it has got
+type Progress struct {
+	s      *Scheduler
+	mu     sync.Mutex
+	status TaskStatus
+}
+
+// Update records progress (done of total, with a short description of the
+// current step). Each call writes to Redis, so call it per batch, not per row.
+func (p *Progress) Update(done, total int, message string) {
+	p.mu.Lock()
+	p.status.Done, p.status.Total, p.status.Message = done, total, message
+	status := p.status
+	p.mu.Unlock()
+	p.s.saveTask(status)
+}
+
+// Submit starts fn in the background and returns the task ID to poll with
+// Task. key names what the task works on (e.g. a game and round): while a
+// task with the same name and key is running on any instance, Submit returns
+// that task's ID with ErrJobRunning instead of starting another.
+//
+// Usage:
+//
+//	id, err := scheduler.Submit("lms-round", "12/R3", 10*time.Minute,
+//	    func(ctx context.Context, p *jobs.Progress) (interface{}, error) {
+//	        p.Update(0, len(picks), "Scoring picks")
+//	        ...
+//	        return summary, nil
+//	    })
+func (s *Scheduler) Submit(name, key string, timeout time.Duration, fn TaskFunc) (string, error) {
+	ctx := context.Background()
+	id := newTaskID()
+
+	lockKey := s.key("task-lock", name, key)
+	locked, err := s.client.SetNX(ctx, lockKey, id, timeout+time.Minute).Result()
+	if err != nil {
+		return "", fmt.Errorf("failed to acquire task lock: %w", err)
+	}
+	if !locked {
+		running, _ := s.client.Get(ctx, lockKey).Result()
+		return running, ErrJobRunning
+	}
+
+	p := &Progress{s: s, status: TaskStatus{
+		ID: id, Name: name, Key: key, Instance: s.instance,
+		State: TaskRunning, StartedAt: time.Now(),
+	}}
+	s.saveTask(p.status)
+
+	go func() {
+		defer s.releaseLockFor(lockKey, id)
+
+		taskCtx, cancel := context.WithTimeout(context.Background(), timeout)
+		result, err := safeRunTask(taskCtx, fn, p)
+		cancel()
+
+		p.mu.Lock()
+		finished := time.Now()
+		p.status.FinishedAt = &finished
+		if err != nil {
+			p.status.State = TaskFailed
+			p.status.Error = err.Error()
+			log.Printf("❌ Task %s %s (%s) failed: %v", name, key, id, err)
+		} else {
+			p.status.State = TaskSucceeded
+			if data, err := json.Marshal(result); err == nil {
+				p.status.Result = data
+			}
+			log.Printf("✅ Task %s %s (%s) completed in %dms", name, key, id, finished.Sub(p.status.StartedAt).Milliseconds())
+		}
+		status := p.status
+		p.mu.Unlock()
+		s.saveTask(status)
+	}()
+
+	return id, nil
+}
+
+// Task returns a task's current status
+func (s *Scheduler) Task(ctx context.Context, id string) (*TaskStatus, error) {
+	data, err := s.client.Get(ctx, s.key("task", id)).Result()
+	if err == redis.Nil {
+		return nil, ErrTaskNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load task: %w", err)
+	}
+	var status TaskStatus
+	if err := json.Unmarshal([]byte(data), &status); err != nil {
+		return nil, fmt.Errorf("failed to decode task: %w", err)
+	}
+	return &status, nil
+}
+
+func (s *Scheduler) saveTask(status TaskStatus) {
+	data, err := json.Marshal(status)
+	if err != nil {
+		return
+	}
+	if err := s.client.Set(context.Background(), s.key("task", status.ID), data, taskTTL).Err(); err != nil {
+		log.Printf("⚠️  Failed to save task %s status: %v", status.ID, err)
+	}
+}
+
+// safeRunTask converts a panicking task into an error, like safeRun
+func safeRunTask(ctx context.Context, fn TaskFunc, p *Progress) (result interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return fn(ctx, p)
+}
+
+func newTaskID() string {
+	id := make([]byte, 8)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
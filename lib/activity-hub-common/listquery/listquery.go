@@ -0,0 +1,341 @@
+// Package listquery reads paging, sorting and filtering from a list
+// endpoint's query string and turns them into SQL, so admin lists answer in
+// pages of the same shape instead of returning every row.
+//
+// A request asks for ?limit=50&offset=100&sort=-created plus any filters the
+// endpoint declares (?status=draft&q=smith). Sorts and filters are
+// whitelisted per endpoint: a column name never comes from the request.
+// Responses keep their existing list key and add a Page:
+//
+//	{"users": [...], "page": {"limit": 50, "offset": 0, "sort": "name", "hasMore": true, "nextOffset": 50}}
+package listquery
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limits used when a Spec doesn't set its own
+const (
+	DefaultLimit = 50
+	MaxLimit     = 500
+)
+
+// Filter turns a query-string value into a SQL condition, with ? for each
+// argument. A filter that returns an empty condition is skipped.
+type Filter func(value string) (cond string, args []any, err error)
+
+// Spec is what a list endpoint allows.
+type Spec struct {
+	// Sorts maps ?sort= names to SQL expressions. An expression may list
+	// several comma-separated columns; a leading "-" on the name sorts each
+	// of them descending.
+	Sorts map[string]string
+	// DefaultSort is used without ?sort=, e.g. "-created"
+	DefaultSort string
+	// Tiebreak is a unique column added after the sort so pages don't
+	// overlap when sorted values repeat, e.g. "id"
+	Tiebreak string
+	// Filters maps query parameters to conditions
+	Filters map[string]Filter
+
+	DefaultLimit int // DefaultLimit when zero
+	MaxLimit     int // MaxLimit when zero
+}
+
+// Query is a parsed list request.
+type Query struct {
+	Limit  int
+	Offset int
+	Sort   string // As requested, or the default: "name", "-created"
+
+	order string
+	conds []string
+	args  []any
+}
+
+// Page describes the page returned, for the response
+type Page struct {
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset"`
+	Sort       string `json:"sort,omitempty"`
+	HasMore    bool   `json:"hasMore"`
+	NextOffset *int   `json:"nextOffset,omitempty"`
+}
+
+// Parse reads a list request. Errors describe the bad parameter and should
+// be answered with 400; parameters the spec doesn't know are ignored.
+//
+// Usage:
+//
+//	var userList = listquery.Spec{
+//	    Sorts:       map[string]string{"name": "name", "created": "created_at"},
+//	    DefaultSort: "name",
+//	    Tiebreak:    "email",
+//	    Filters:     map[string]listquery.Filter{"q": listquery.Search("name", "email")},
+//	}
+//
+//	q, err := listquery.Parse(r.URL.Query(), userList)
+//	if err != nil {
+//	    sendError(w, err.Error(), http.StatusBadRequest)
+//	    return
+//	}
+//	q.Where("venue_id = ?", venueID)
+//	query, args := q.SQL("SELECT email, name FROM users")
+//	rows, err := db.Query(query, args...)
+//	...
+//	users, page := listquery.Finish(q, users)
+func Parse(values url.Values, spec Spec) (*Query, error) {
+	q := &Query{Limit: spec.DefaultLimit}
+	if q.Limit <= 0 {
+		q.Limit = DefaultLimit
+	}
+	max := spec.MaxLimit
+	if max <= 0 {
+		max = MaxLimit
+	}
+
+	if v := values.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid limit %q", v)
+		}
+		q.Limit = min(n, max)
+	}
+	if v := values.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid offset %q", v)
+		}
+		q.Offset = n
+	}
+
+	q.Sort = values.Get("sort")
+	if q.Sort == "" {
+		q.Sort = spec.DefaultSort
+	}
+	desc := strings.HasPrefix(q.Sort, "-")
+	expr := ""
+	if q.Sort != "" {
+		var ok bool
+		if expr, ok = spec.Sorts[strings.TrimPrefix(q.Sort, "-")]; !ok {
+			return nil, fmt.Errorf("invalid sort %q", q.Sort)
+		}
+		q.order = orderBy(expr, desc)
+	}
+	if spec.Tiebreak != "" && expr != spec.Tiebreak {
+		if q.order != "" {
+			q.order += ", "
+		}
+		q.order += orderBy(spec.Tiebreak, desc)
+	}
+
+	// In name order, so the same request always builds the same SQL
+	params := make([]string, 0, len(spec.Filters))
+	for param := range spec.Filters {
+		params = append(params, param)
+	}
+	sort.Strings(params)
+	for _, param := range params {
+		filter := spec.Filters[param]
+		v := strings.TrimSpace(values.Get(param))
+		if v == "" {
+			continue
+		}
+		cond, args, err := filter(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %v", param, err)
+		}
+		if cond != "" {
+			q.Where(cond, args...)
+		}
+	}
+	return q, nil
+}
+
+// Where adds a condition the endpoint always applies (the caller's venue, the
+// parent record), with ? for each argument.
+func (q *Query) Where(cond string, args ...any) {
+	q.conds = append(q.conds, cond)
+	q.args = append(q.args, args...)
+}
+
+// SQL appends the conditions, order and page to a query without a WHERE
+// clause or arguments of its own. It asks for one row more than the limit;
+// Finish drops it and reports that there's another page.
+func (q *Query) SQL(selectFrom string) (string, []any) {
+	var b strings.Builder
+	b.WriteString(selectFrom)
+	n := 0
+	for i, cond := range q.conds {
+		if i == 0 {
+			b.WriteString("\nWHERE ")
+		} else {
+			b.WriteString("\n  AND ")
+		}
+		b.WriteString("(")
+		for _, c := range cond {
+			if c == '?' {
+				n++
+				fmt.Fprintf(&b, "$%d", n)
+				continue
+			}
+			b.WriteRune(c)
+		}
+		b.WriteString(")")
+	}
+	if q.order != "" {
+		b.WriteString("\nORDER BY " + q.order)
+	}
+	fmt.Fprintf(&b, "\nLIMIT %d OFFSET %d", q.Limit+1, q.Offset)
+	return b.String(), q.args
+}
+
+// Finish trims the extra row SQL asked for and describes the page.
+func Finish[T any](q *Query, items []T) ([]T, Page) {
+	page := Page{Limit: q.Limit, Offset: q.Offset, Sort: q.Sort}
+	if len(items) > q.Limit {
+		items = items[:q.Limit]
+		next := q.Offset + q.Limit
+		page.HasMore = true
+		page.NextOffset = &next
+	}
+	if items == nil {
+		items = []T{}
+	}
+	return items, page
+}
+
+// orderBy applies a direction to each column of a sort expression
+func orderBy(expr string, desc bool) string {
+	cols := splitColumns(expr)
+	if desc {
+		for i := range cols {
+			cols[i] += " DESC"
+		}
+	}
+	return strings.Join(cols, ", ")
+}
+
+// splitColumns splits an expression on the commas between columns, leaving
+// those inside function calls: "COALESCE(seed, 999), name"
+func splitColumns(expr string) []string {
+	var cols []string
+	depth, start := 0, 0
+	for i, c := range expr {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				cols = append(cols, strings.TrimSpace(expr[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(cols, strings.TrimSpace(expr[start:]))
+}
+
+// Equals matches a column to the value
+func Equals(column string) Filter {
+	return func(v string) (string, []any, error) {
+		return column + " = ?", []any{v}, nil
+	}
+}
+
+// Int matches a column to a whole-number value
+func Int(column string) Filter {
+	return func(v string) (string, []any, error) {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return "", nil, fmt.Errorf("%q is not a number", v)
+		}
+		return column + " = ?", []any{n}, nil
+	}
+}
+
+// Bool matches a boolean column to "true" or "false"
+func Bool(column string) Filter {
+	return func(v string) (string, []any, error) {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return "", nil, fmt.Errorf("%q is not true or false", v)
+		}
+		return column + " = ?", []any{b}, nil
+	}
+}
+
+// Present keeps rows where the column is set ("true") or NULL ("false")
+func Present(column string) Filter {
+	return func(v string) (string, []any, error) {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return "", nil, fmt.Errorf("%q is not true or false", v)
+		}
+		if b {
+			return column + " IS NOT NULL", nil, nil
+		}
+		return column + " IS NULL", nil, nil
+	}
+}
+
+// Search matches the value anywhere in any of the columns, ignoring case.
+// % and _ in the value match themselves.
+func Search(columns ...string) Filter {
+	return func(v string) (string, []any, error) {
+		pattern := "%" + strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(v) + "%"
+		conds := make([]string, len(columns))
+		args := make([]any, len(columns))
+		for i, col := range columns {
+			conds[i] = col + " ILIKE ?"
+			args[i] = pattern
+		}
+		return strings.Join(conds, " OR "), args, nil
+	}
+}
+
+// From keeps rows where the time column is at or after the value: RFC 3339,
+// or the server's local time as "2006-01-02T15:04" or "2006-01-02".
+func From(column string) Filter {
+	return func(v string) (string, []any, error) {
+		t, _, err := parseTime(v)
+		if err != nil {
+			return "", nil, err
+		}
+		return column + " >= ?", []any{t}, nil
+	}
+}
+
+// To keeps rows where the time column is before the value, in the same
+// formats as From. A date alone includes the whole day.
+func To(column string) Filter {
+	return func(v string) (string, []any, error) {
+		t, dateOnly, err := parseTime(v)
+		if err != nil {
+			return "", nil, err
+		}
+		if dateOnly {
+			t = t.AddDate(0, 0, 1)
+		}
+		return column + " < ?", []any{t}, nil
+	}
+}
+
+func parseTime(v string) (time.Time, bool, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, false, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02T15:04", v, time.Local); err == nil {
+		return t, false, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", v, time.Local); err == nil {
+		return t, true, nil
+	}
+	return time.Time{}, false, fmt.Errorf("%q is not a date or time", v)
+}
@@ -0,0 +1,167 @@
+package listquery
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var questionList = Spec{
+	Sorts: map[string]string{
+		"id":       "q.id",
+		"created":  "q.created_at",
+		"category": "q.category, q.type",
+		"position": "COALESCE(q.position, 999), q.text",
+	},
+	DefaultSort: "-created",
+	Tiebreak:    "q.id",
+	Filters: map[string]Filter{
+		"type":     Equals("q.type"),
+		"pack":     Int("q.pack_id"),
+		"tiebreak": Present("q.tiebreak_answer"),
+		"q":        Search("q.text", "q.answer"),
+	},
+}
+
+func parse(t *testing.T, query string) *Query {
+	t.Helper()
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, err := Parse(values, questionList)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", query, err)
+	}
+	return q
+}
+
+func TestParseDefaults(t *testing.T) {
+	q := parse(t, "")
+	if q.Limit != DefaultLimit || q.Offset != 0 || q.Sort != "-created" {
+		t.Errorf("Got limit %d, offset %d, sort %q", q.Limit, q.Offset, q.Sort)
+	}
+	query, args := q.SQL("SELECT id FROM questions q")
+	want := "SELECT id FROM questions q\nORDER BY q.created_at DESC, q.id DESC\nLIMIT 51 OFFSET 0"
+	if query != want || len(args) != 0 {
+		t.Errorf("Got %q %v, want %q", query, args, want)
+	}
+}
+
+func TestParseLimits(t *testing.T) {
+	if q := parse(t, "limit=10&offset=20"); q.Limit != 10 || q.Offset != 20 {
+		t.Errorf("Got limit %d, offset %d", q.Limit, q.Offset)
+	}
+	if q := parse(t, "limit=100000"); q.Limit != MaxLimit {
+		t.Errorf("Expected the limit capped at %d, got %d", MaxLimit, q.Limit)
+	}
+
+	spec := Spec{DefaultLimit: 20, MaxLimit: 40}
+	if q, _ := Parse(url.Values{}, spec); q.Limit != 20 {
+		t.Errorf("Expected the spec's default limit, got %d", q.Limit)
+	}
+	if q, _ := Parse(url.Values{"limit": {"100"}}, spec); q.Limit != 40 {
+		t.Errorf("Expected the spec's max limit, got %d", q.Limit)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, query := range []string{
+		"limit=0",
+		"limit=ten",
+		"offset=-1",
+		"sort=password",
+		"sort=-q.id",
+		"pack=first",
+		"tiebreak=maybe",
+	} {
+		values, _ := url.ParseQuery(query)
+		if _, err := Parse(values, questionList); err == nil {
+			t.Errorf("Parse(%q): expected an error", query)
+		}
+	}
+}
+
+func TestSorts(t *testing.T) {
+	tests := []struct {
+		sort string
+		want string
+	}{
+		{"created", "ORDER BY q.created_at, q.id"},
+		{"-category", "ORDER BY q.category DESC, q.type DESC, q.id DESC"},
+		{"position", "ORDER BY COALESCE(q.position, 999), q.text, q.id"},
+		{"-id", "ORDER BY q.id DESC"},
+	}
+	for _, tt := range tests {
+		query, _ := parse(t, "sort="+tt.sort).SQL("SELECT id FROM questions q")
+		if !strings.Contains(query, tt.want+"\n") {
+			t.Errorf("sort=%s: got %q, want %q", tt.sort, query, tt.want)
+		}
+	}
+}
+
+func TestFilters(t *testing.T) {
+	q := parse(t, "type=picture&tiebreak=true&q=50%25_off&pack=3&ignored=x")
+	q.Where("q.venue_id = ? OR q.venue_id = ?", 7, 0)
+	query, args := q.SQL("SELECT id FROM questions q")
+
+	want := "SELECT id FROM questions q\n" +
+		"WHERE (q.pack_id = $1)\n" +
+		"  AND (q.text ILIKE $2 OR q.answer ILIKE $3)\n" +
+		"  AND (q.tiebreak_answer IS NOT NULL)\n" +
+		"  AND (q.type = $4)\n" +
+		"  AND (q.venue_id = $5 OR q.venue_id = $6)\n" +
+		"ORDER BY q.created_at DESC, q.id DESC\n" +
+		"LIMIT 51 OFFSET 0"
+	if query != want {
+		t.Errorf("Got\n%s\nwant\n%s", query, want)
+	}
+	wantArgs := []any{3, `%50\%\_off%`, `%50\%\_off%`, "picture", 7, 0}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("Got args %v, want %v", args, wantArgs)
+	}
+
+	if _, args := parse(t, "tiebreak=false").SQL(""); len(args) != 0 {
+		t.Errorf("Expected IS NULL without an argument, got %v", args)
+	}
+}
+
+func TestFinish(t *testing.T) {
+	q := parse(t, "limit=2&offset=4")
+
+	items, page := Finish(q, []int{1, 2, 3})
+	if len(items) != 2 || !page.HasMore || page.NextOffset == nil || *page.NextOffset != 6 {
+		t.Errorf("Got %v, %+v", items, page)
+	}
+
+	items, page = Finish(q, []int{1, 2})
+	if len(items) != 2 || page.HasMore || page.NextOffset != nil {
+		t.Errorf("Got %v, %+v", items, page)
+	}
+
+	var none []string
+	if items, _ := Finish(q, none); items == nil {
+		t.Error("Expected an empty list, not nil")
+	}
+}
+
+func TestTimeFilters(t *testing.T) {
+	spec := Spec{Filters: map[string]Filter{"from": From("created_at"), "to": To("created_at")}}
+	values := url.Values{"from": {"2026-10-17T18:00"}, "to": {"2026-10-17"}}
+	q, err := Parse(values, spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, args := q.SQL("SELECT id FROM audit_log")
+	from := time.Date(2026, 10, 17, 18, 0, 0, 0, time.Local)
+	to := time.Date(2026, 10, 18, 0, 0, 0, 0, time.Local)
+	if len(args) != 2 || !args[0].(time.Time).Equal(from) || !args[1].(time.Time).Equal(to) {
+		t.Errorf("Got args %v, want %v and %v", args, from, to)
+	}
+
+	if _, err := Parse(url.Values{"from": {"6pm"}}, spec); err == nil {
+		t.Error("Expected an error for an unreadable time")
+	}
+}
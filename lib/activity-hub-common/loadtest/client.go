@@ -0,0 +1,149 @@
+package loadtest
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/achgithub/activity-hub-common/events"
+)
+
+// requestTimeout bounds each bot request; streams aren't bounded
+const requestTimeout = 15 * time.Second
+
+// Client makes a bot's calls to a backend, recording each one
+type Client struct {
+	BaseURL string // e.g. http://127.0.0.1:4041
+	Token   string // Sent as the bearer token
+	HTTP    *http.Client
+
+	rec *Recorder
+}
+
+// NewClient returns a bot's client. Bots of a run can share an http.Client;
+// the default is one without a timeout, as streams stay open.
+func NewClient(baseURL, token string, rec *Recorder) *Client {
+	return &Client{BaseURL: strings.TrimRight(baseURL, "/"), Token: token, HTTP: http.DefaultClient, rec: rec}
+}
+
+// Do sends a JSON request and decodes a JSON response into out (if not nil),
+// recording it as op. Statuses other than 2xx are errors.
+//
+// Usage:
+//
+//	var joined struct{ SessionID int `json:"sessionId"` }
+//	err := c.Do(ctx, "join", "POST", "/api/sessions/join", map[string]string{"joinCode": code}, &joined)
+func (c *Client) Do(ctx context.Context, op, method, path string, body, out interface{}) error {
+	return c.rec.Time(op, func() error {
+		ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+		defer cancel()
+
+		var reader io.Reader
+		if body != nil {
+			data, err := json.Marshal(body)
+			if err != nil {
+				return err
+			}
+			reader = bytes.NewReader(data)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, reader)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+
+		resp, err := c.HTTP.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if err := statusError(resp); err != nil {
+			return err
+		}
+		if out != nil {
+			return json.NewDecoder(resp.Body).Decode(out)
+		}
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	})
+}
+
+// Stream opens an SSE stream at path (which carries its own auth, e.g. a
+// token parameter) and calls onEvent with each event until ctx is done,
+// the stream ends or onEvent returns false. Opening the stream is recorded
+// as op, up to its first event; each event is counted as "op events".
+func (c *Client) Stream(ctx context.Context, op, path string, onEvent func(events.Envelope) bool) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.BaseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	start := time.Now()
+	resp, err := c.HTTP.Do(req)
+	if err == nil {
+		err = statusError(resp)
+		if err != nil {
+			resp.Body.Close()
+		}
+	}
+	if err != nil {
+		c.rec.Record(op, time.Since(start), err)
+		return err
+	}
+	defer resp.Body.Close()
+
+	opened := false
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		if !opened {
+			c.rec.Record(op, time.Since(start), nil)
+			opened = true
+		}
+		e, err := events.Decode(data)
+		if err != nil {
+			c.rec.Fail(op, fmt.Errorf("undecodable event: %w", err))
+			continue
+		}
+		c.rec.Count(op + " events")
+		if !onEvent(e) {
+			return nil
+		}
+	}
+
+	if ctx.Err() != nil {
+		return nil
+	}
+	err = scanner.Err()
+	if err == nil {
+		err = fmt.Errorf("stream closed by server")
+	}
+	if !opened {
+		c.rec.Record(op, time.Since(start), err)
+	} else {
+		c.rec.Fail(op, err)
+	}
+	return err
+}
+
+func statusError(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+	return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(msg)))
+}
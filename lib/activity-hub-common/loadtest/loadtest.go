@@ -0,0 +1,284 @@
+// Package loadtest drives synthetic users against a running backend, so an
+// operator can check the Pi copes with a full pub (80 quiz players, say)
+// before the night rather than during it.
+//
+// It is a test mode: backends only register their load test routes when
+// LOAD_TEST=true. Bots sign in as guests, so they never touch real accounts,
+// and go through the backend's own HTTP routes and streams like a phone would.
+package loadtest
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/achgithub/activity-hub-common/config"
+)
+
+const (
+	MaxBots  = 200 // Per run
+	keepRuns = 10  // Finished runs kept for their summaries
+)
+
+// ErrRunning is returned by Start while another run is going
+var ErrRunning = errors.New("a load test is already running")
+
+// Enabled reports whether load testing is switched on (LOAD_TEST=true)
+func Enabled() bool {
+	return config.GetEnv("LOAD_TEST", "") == "true"
+}
+
+// Guest is the identity a bot plays as
+type Guest struct {
+	N     int    // 1..Bots
+	Email string // guest-loadtest-<run>-<n>
+	Token string // Guest session token for Email
+	Name  string
+}
+
+// Bot plays one synthetic user until ctx is done or it has nothing left to
+// do. An error ends the bot and counts it as failed.
+type Bot func(ctx context.Context, g Guest, rec *Recorder) error
+
+// Config describes a run
+type Config struct {
+	Kind     string        // What the bots do (e.g. "quiz-player")
+	Bots     int           // 1..MaxBots
+	RampUp   time.Duration // Bot starts are spread evenly across this
+	Duration time.Duration // Bots are stopped after this; 0 = when they finish or Stop
+}
+
+// Run is one load test in progress or finished
+type Run struct {
+	ID  string
+	cfg Config
+	rec *Recorder
+
+	startedAt time.Time
+	cancel    context.CancelFunc
+	done      chan struct{}
+	active    int64
+	failed    int64
+
+	mu      sync.Mutex
+	endedAt time.Time
+	stopped bool
+}
+
+// Summary is a run's state and results, as served to operators
+type Summary struct {
+	ID             string               `json:"id"`
+	Kind           string               `json:"kind"`
+	State          string               `json:"state"` // running, finished or stopped
+	Bots           int                  `json:"bots"`
+	Active         int64                `json:"active"`
+	Failed         int64                `json:"failed"`
+	StartedAt      time.Time            `json:"startedAt"`
+	EndedAt        *time.Time           `json:"endedAt,omitempty"`
+	ElapsedSeconds float64              `json:"elapsedSeconds"`
+	Ops            map[string]OpSummary `json:"ops"`
+	Counters       map[string]int64     `json:"counters"`
+}
+
+// Runner runs a backend's load tests, one at a time
+type Runner struct {
+	mu   sync.Mutex
+	runs []*Run // Oldest first
+}
+
+func NewRunner() *Runner {
+	return &Runner{}
+}
+
+// Start launches cfg.Bots copies of bot and returns straight away.
+//
+// Usage:
+//
+//	run, err := runner.Start(loadtest.Config{Kind: "quiz-player", Bots: 80, RampUp: 20 * time.Second}, quizBot(joinCode))
+//	...
+//	json.NewEncoder(w).Encode(run.Summary())
+func (r *Runner) Start(cfg Config, bot Bot) (*Run, error) {
+	if cfg.Bots < 1 || cfg.Bots > MaxBots {
+		return nil, fmt.Errorf("bots must be between 1 and %d", MaxBots)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, run := range r.runs {
+		if run.running() {
+			return nil, ErrRunning
+		}
+	}
+
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if cfg.Duration > 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), cfg.Duration)
+	} else {
+		ctx, cancel = context.WithCancel(context.Background())
+	}
+	run := &Run{
+		ID:        newRunID(),
+		cfg:       cfg,
+		rec:       NewRecorder(),
+		startedAt: time.Now(),
+		cancel:    cancel,
+		done:      make(chan struct{}),
+	}
+	r.runs = append(r.runs, run)
+	if len(r.runs) > keepRuns {
+		r.runs = r.runs[len(r.runs)-keepRuns:]
+	}
+
+	go run.play(ctx, bot)
+	return run, nil
+}
+
+// Get returns a run by ID
+func (r *Runner) Get(id string) (*Run, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, run := range r.runs {
+		if run.ID == id {
+			return run, true
+		}
+	}
+	return nil, false
+}
+
+// List summarises the kept runs, newest first
+func (r *Runner) List() []Summary {
+	r.mu.Lock()
+	runs := append([]*Run(nil), r.runs...)
+	r.mu.Unlock()
+
+	out := make([]Summary, 0, len(runs))
+	for i := len(runs) - 1; i >= 0; i-- {
+		out = append(out, runs[i].Summary())
+	}
+	return out
+}
+
+// Stop ends the run's bots early
+func (run *Run) Stop() {
+	run.mu.Lock()
+	if run.endedAt.IsZero() {
+		run.stopped = true
+	}
+	run.mu.Unlock()
+	run.cancel()
+}
+
+// Done is closed once every bot has finished
+func (run *Run) Done() <-chan struct{} {
+	return run.done
+}
+
+// Guest returns the identity of bot n. Emails start with the run's prefix,
+// so a backend can pick out (and tidy up) what its bots left behind.
+func (run *Run) Guest(n int) Guest {
+	id := fmt.Sprintf("loadtest-%s-%d", run.ID, n)
+	return Guest{N: n, Email: "guest-" + id, Token: "guest-token-" + id, Name: fmt.Sprintf("Bot %d", n)}
+}
+
+// EmailPrefix is the start of every email the run's bots use
+func (run *Run) EmailPrefix() string {
+	return "guest-loadtest-" + run.ID + "-"
+}
+
+// Summary reports the run so far
+func (run *Run) Summary() Summary {
+	run.mu.Lock()
+	endedAt, stopped := run.endedAt, run.stopped
+	run.mu.Unlock()
+
+	s := Summary{
+		ID:        run.ID,
+		Kind:      run.cfg.Kind,
+		State:     "running",
+		Bots:      run.cfg.Bots,
+		Active:    atomic.LoadInt64(&run.active),
+		Failed:    atomic.LoadInt64(&run.failed),
+		StartedAt: run.startedAt,
+		Ops:       run.rec.Summary(),
+		Counters:  run.rec.Counters(),
+	}
+	end := time.Now()
+	if !endedAt.IsZero() {
+		end = endedAt
+		s.EndedAt = &endedAt
+		s.State = "finished"
+		if stopped {
+			s.State = "stopped"
+		}
+	}
+	s.ElapsedSeconds = end.Sub(run.startedAt).Seconds()
+	return s
+}
+
+func (run *Run) running() bool {
+	run.mu.Lock()
+	defer run.mu.Unlock()
+	return run.endedAt.IsZero()
+}
+
+// play starts the bots across the ramp-up and waits for them all
+func (run *Run) play(ctx context.Context, bot Bot) {
+	defer close(run.done)
+	defer run.cancel()
+
+	var gap time.Duration
+	if run.cfg.Bots > 1 {
+		gap = run.cfg.RampUp / time.Duration(run.cfg.Bots-1)
+	}
+
+	var wg sync.WaitGroup
+	for n := 1; n <= run.cfg.Bots; n++ {
+		if n > 1 && gap > 0 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(gap):
+			}
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		atomic.AddInt64(&run.active, 1)
+		go func(g Guest) {
+			defer wg.Done()
+			defer atomic.AddInt64(&run.active, -1)
+			if err := safePlay(ctx, bot, g, run.rec); err != nil && ctx.Err() == nil {
+				atomic.AddInt64(&run.failed, 1)
+				run.rec.Fail("bot", err)
+			}
+		}(run.Guest(n))
+	}
+	wg.Wait()
+
+	run.mu.Lock()
+	run.endedAt = time.Now()
+	run.mu.Unlock()
+}
+
+// safePlay runs a bot, turning a panic into its error
+func safePlay(ctx context.Context, bot Bot, g Guest, rec *Recorder) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	return bot(ctx, g, rec)
+}
+
+func newRunID() string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,163 @@
+package loadtest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/achgithub/activity-hub-common/events"
+)
+
+func TestRecorderSummary(t *testing.T) {
+	rec := NewRecorder()
+	for i := 1; i <= 100; i++ {
+		var err error
+		if i%10 == 0 {
+			err = errors.New("503 Service Unavailable")
+		}
+		rec.Record("answer", time.Duration(i)*time.Millisecond, err)
+	}
+	rec.Count("stream events")
+	rec.Count("stream events")
+
+	s := rec.Summary()["answer"]
+	if s.Count != 100 || s.Errors != 10 || s.ErrorRate != 0.1 {
+		t.Errorf("count/errors = %d/%d (%v), want 100/10 (0.1)", s.Count, s.Errors, s.ErrorRate)
+	}
+	if s.P50Ms != 50 || s.P95Ms != 95 || s.P99Ms != 99 || s.MaxMs != 100 {
+		t.Errorf("percentiles = %v/%v/%v max %v, want 50/95/99 max 100", s.P50Ms, s.P95Ms, s.P99Ms, s.MaxMs)
+	}
+	if s.LastError != "503 Service Unavailable" {
+		t.Errorf("LastError = %q", s.LastError)
+	}
+	if got := rec.Counters()["stream events"]; got != 2 {
+		t.Errorf("counter = %d, want 2", got)
+	}
+}
+
+func TestRunnerRunsBots(t *testing.T) {
+	runner := NewRunner()
+	if _, err := runner.Start(Config{Bots: 0}, nil); err == nil {
+		t.Error("Expected an error for no bots")
+	}
+	if _, err := runner.Start(Config{Bots: MaxBots + 1}, nil); err == nil {
+		t.Error("Expected an error for too many bots")
+	}
+
+	var played int64
+	release := make(chan struct{})
+	run, err := runner.Start(Config{Kind: "test", Bots: 3}, func(ctx context.Context, g Guest, rec *Recorder) error {
+		atomic.AddInt64(&played, 1)
+		<-release
+		if g.N == 2 {
+			return errors.New("gave up")
+		}
+		if g.N == 3 {
+			panic("boom")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if _, err := runner.Start(Config{Bots: 1}, func(context.Context, Guest, *Recorder) error { return nil }); err != ErrRunning {
+		t.Errorf("second Start = %v, want ErrRunning", err)
+	}
+
+	close(release)
+	<-run.Done()
+
+	s := run.Summary()
+	if played != 3 || s.State != "finished" || s.Failed != 2 || s.Active != 0 {
+		t.Errorf("played %d, summary %+v", played, s)
+	}
+	if len(runner.List()) != 1 {
+		t.Errorf("List = %d runs, want 1", len(runner.List()))
+	}
+	if _, ok := runner.Get(run.ID); !ok {
+		t.Error("Get couldn't find the run")
+	}
+}
+
+func TestRunnerStop(t *testing.T) {
+	runner := NewRunner()
+	run, err := runner.Start(Config{Bots: 2}, func(ctx context.Context, g Guest, rec *Recorder) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	run.Stop()
+	<-run.Done()
+
+	// Bots ended by a stop haven't failed
+	if s := run.Summary(); s.State != "stopped" || s.Failed != 0 {
+		t.Errorf("summary = %+v, want stopped with no failures", s)
+	}
+}
+
+func TestGuest(t *testing.T) {
+	run := &Run{ID: "ab12"}
+	g := run.Guest(7)
+	if g.Email != "guest-loadtest-ab12-7" || g.Token != "guest-token-loadtest-ab12-7" {
+		t.Errorf("Guest(7) = %+v", g)
+	}
+	if !strings.HasPrefix(g.Email, run.EmailPrefix()) {
+		t.Errorf("%q doesn't start with %q", g.Email, run.EmailPrefix())
+	}
+}
+
+func TestClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/join":
+			if r.Header.Get("Authorization") != "Bearer guest-token-x" {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"sessionId": 12}`))
+		case "/api/stream":
+			events.Send(w, events.Ping.New("12", events.NoPayload{}))
+			events.Send(w, events.Ping.New("12", events.NoPayload{}))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	rec := NewRecorder()
+	c := NewClient(srv.URL, "guest-token-x", rec)
+
+	var joined struct {
+		SessionID int `json:"sessionId"`
+	}
+	if err := c.Do(context.Background(), "join", "POST", "/api/join", map[string]string{"joinCode": "ABC"}, &joined); err != nil || joined.SessionID != 12 {
+		t.Errorf("Do = %v, session %d", err, joined.SessionID)
+	}
+	if err := c.Do(context.Background(), "missing", "GET", "/api/missing", nil, nil); err == nil || !strings.HasPrefix(err.Error(), "404") {
+		t.Errorf("Do on a missing route = %v, want a 404 error", err)
+	}
+
+	seen := 0
+	err := c.Stream(context.Background(), "stream", "/api/stream", func(e events.Envelope) bool {
+		seen++
+		return true
+	})
+	if seen != 2 || err == nil {
+		t.Errorf("Stream saw %d events (err %v), want 2 and a closed stream", seen, err)
+	}
+
+	ops := rec.Summary()
+	if ops["join"].Count != 1 || ops["missing"].Errors != 1 || ops["stream"].Count != 1 {
+		t.Errorf("unexpected ops %+v", ops)
+	}
+	if rec.Counters()["stream events"] != 2 {
+		t.Errorf("counters = %v", rec.Counters())
+	}
+}
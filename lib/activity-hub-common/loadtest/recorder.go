@@ -0,0 +1,152 @@
+package loadtest
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// maxSamples caps the latencies kept per operation. Past it, new samples
+// replace random old ones, so percentiles stay representative of the run.
+const maxSamples = 20000
+
+// Recorder collects the latency and errors of every operation the bots make
+type Recorder struct {
+	mu       sync.Mutex
+	ops      map[string]*opStats
+	counters map[string]int64
+}
+
+type opStats struct {
+	count     int64
+	errors    int64
+	samples   []time.Duration
+	max       time.Duration
+	lastError string
+}
+
+// OpSummary is one operation's results. Latencies are in milliseconds and
+// cover successful and failed calls alike.
+type OpSummary struct {
+	Count     int64   `json:"count"`
+	Errors    int64   `json:"errors"`
+	ErrorRate float64 `json:"errorRate"` // 0-1
+	P50Ms     float64 `json:"p50Ms"`
+	P95Ms     float64 `json:"p95Ms"`
+	P99Ms     float64 `json:"p99Ms"`
+	MaxMs     float64 `json:"maxMs"`
+	LastError string  `json:"lastError,omitempty"`
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{ops: map[string]*opStats{}, counters: map[string]int64{}}
+}
+
+// Time runs fn and records how long it took and whether it failed
+func (r *Recorder) Time(op string, fn func() error) error {
+	start := time.Now()
+	err := fn()
+	r.Record(op, time.Since(start), err)
+	return err
+}
+
+// Record adds one call of op
+func (r *Recorder) Record(op string, d time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := r.op(op)
+	s.count++
+	if err != nil {
+		s.errors++
+		s.lastError = err.Error()
+	}
+	if d > s.max {
+		s.max = d
+	}
+	if len(s.samples) < maxSamples {
+		s.samples = append(s.samples, d)
+	} else if i := rand.Int63n(s.count); i < maxSamples {
+		s.samples[i] = d
+	}
+}
+
+// Fail records an error that isn't a timed call (a bot giving up, say)
+func (r *Recorder) Fail(op string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := r.op(op)
+	s.errors++
+	s.lastError = err.Error()
+}
+
+// Count adds to a counter, for things that happen rather than take time
+// (stream events received, say)
+func (r *Recorder) Count(name string) {
+	r.mu.Lock()
+	r.counters[name]++
+	r.mu.Unlock()
+}
+
+// Summary reports every operation so far
+func (r *Recorder) Summary() map[string]OpSummary {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make(map[string]OpSummary, len(r.ops))
+	for name, s := range r.ops {
+		sorted := append([]time.Duration(nil), s.samples...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+		sum := OpSummary{
+			Count:     s.count,
+			Errors:    s.errors,
+			P50Ms:     millis(percentile(sorted, 50)),
+			P95Ms:     millis(percentile(sorted, 95)),
+			P99Ms:     millis(percentile(sorted, 99)),
+			MaxMs:     millis(s.max),
+			LastError: s.lastError,
+		}
+		if s.count > 0 {
+			sum.ErrorRate = float64(s.errors) / float64(s.count)
+		}
+		out[name] = sum
+	}
+	return out
+}
+
+// Counters reports every counter so far
+func (r *Recorder) Counters() map[string]int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make(map[string]int64, len(r.counters))
+	for k, v := range r.counters {
+		out[k] = v
+	}
+	return out
+}
+
+func (r *Recorder) op(name string) *opStats {
+	s, ok := r.ops[name]
+	if !ok {
+		s = &opStats{}
+		r.ops[name] = s
+	}
+	return s
+}
+
+// percentile is the nearest-rank percentile p of sorted latencies
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+func millis(d time.Duration) float64 {
+	return math.Round(float64(d)/float64(time.Millisecond)*10) / 10
+}
@@ -0,0 +1,212 @@
+// Package mailer sends email through the venue's SMTP relay, for digests and
+// other mail the platform sends on its own.
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/achgithub/activity-hub-common/config"
+)
+
+// DefaultTimeout bounds one Send, from connecting to the relay's final answer
+const DefaultTimeout = 30 * time.Second
+
+// Message is one email. Text is required; HTML, when set, is sent alongside
+// it as the preferred alternative.
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Mailer sends through one SMTP relay. With no Host it only logs what it would
+// have sent, so development machines need no relay.
+type Mailer struct {
+	Host     string
+	Port     string // Default 587
+	Username string // Empty for relays that don't authenticate
+	Password string
+	From     string // e.g. "Activity Hub <hub@example.com>"
+	Timeout  time.Duration
+}
+
+// FromEnv reads the relay from SMTP_HOST, SMTP_PORT, SMTP_USERNAME,
+// SMTP_PASSWORD and MAIL_FROM.
+//
+// Usage:
+//
+//	m := mailer.FromEnv()
+//	err := m.Send(ctx, mailer.Message{To: email, Subject: "Your week", Text: body})
+func FromEnv() *Mailer {
+	return &Mailer{
+		Host:     config.GetEnv("SMTP_HOST", ""),
+		Port:     config.GetEnv("SMTP_PORT", "587"),
+		Username: config.GetEnv("SMTP_USERNAME", ""),
+		Password: config.GetEnv("SMTP_PASSWORD", ""),
+		From:     config.GetEnv("MAIL_FROM", "Activity Hub <noreply@localhost>"),
+	}
+}
+
+// Enabled reports whether a relay is configured
+func (m *Mailer) Enabled() bool {
+	return m != nil && m.Host != ""
+}
+
+// Send delivers msg. STARTTLS is used when the relay offers it, and is
+// required before sending a password.
+func (m *Mailer) Send(ctx context.Context, msg Message) error {
+	if _, err := mail.ParseAddress(msg.To); err != nil {
+		return fmt.Errorf("invalid recipient %q: %w", msg.To, err)
+	}
+	from, err := mail.ParseAddress(m.From)
+	if err != nil {
+		return fmt.Errorf("invalid MAIL_FROM %q: %w", m.From, err)
+	}
+	if !m.Enabled() {
+		log.Printf("📧 SMTP not configured, not sending %q to %s", msg.Subject, msg.To)
+		return nil
+	}
+
+	body, err := build(m.From, msg, time.Now())
+	if err != nil {
+		return err
+	}
+
+	timeout := m.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	port := m.Port
+	if port == "" {
+		port = "587"
+	}
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(m.Host, port))
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP relay: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, m.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to start SMTP session: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: m.Host}); err != nil {
+			return fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+	if m.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", m.Username, m.Password, m.Host)); err != nil {
+			return fmt.Errorf("SMTP authentication failed: %w", err)
+		}
+	}
+
+	if err := client.Mail(from.Address); err != nil {
+		return fmt.Errorf("relay refused sender: %w", err)
+	}
+	if err := client.Rcpt(msg.To); err != nil {
+		return fmt.Errorf("relay refused recipient %s: %w", msg.To, err)
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("relay refused message: %w", err)
+	}
+	if _, err := w.Write(body); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("relay rejected message: %w", err)
+	}
+	return client.Quit()
+}
+
+// build writes msg as a MIME message: plain text alone, or text and HTML as
+// multipart/alternative. Bodies are quoted-printable so long lines and
+// non-ASCII names survive any relay.
+func build(from string, msg Message, now time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+	header := func(k, v string) {
+		fmt.Fprintf(&buf, "%s: %s\r\n", k, v)
+	}
+	header("From", from)
+	header("To", msg.To)
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", now.Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+
+	if msg.HTML == "" {
+		header("Content-Type", "text/plain; charset=utf-8")
+		header("Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+		if err := writeQP(&buf, msg.Text); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	boundary, err := newBoundary()
+	if err != nil {
+		return nil, err
+	}
+	header("Content-Type", `multipart/alternative; boundary="`+boundary+`"`)
+	buf.WriteString("\r\n")
+
+	parts := []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", msg.Text},
+		{"text/html; charset=utf-8", msg.HTML},
+	}
+	for _, p := range parts {
+		fmt.Fprintf(&buf, "--%s\r\n", boundary)
+		header("Content-Type", p.contentType)
+		header("Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+		if err := writeQP(&buf, p.body); err != nil {
+			return nil, err
+		}
+		buf.WriteString("\r\n")
+	}
+	fmt.Fprintf(&buf, "--%s--\r\n", boundary)
+	return buf.Bytes(), nil
+}
+
+// writeQP writes s quoted-printable with CRLF line endings
+func writeQP(buf *bytes.Buffer, s string) error {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\n", "\r\n")
+	qp := quotedprintable.NewWriter(buf)
+	if _, err := qp.Write([]byte(s)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+func newBoundary() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "ah-" + hex.EncodeToString(b), nil
+}
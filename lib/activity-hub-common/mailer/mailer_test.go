@@ -0,0 +1,180 @@
+package mailer
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildPlainText(t *testing.T) {
+	raw, err := build("Hub <hub@example.com>", Message{
+		To:      "sam@example.com",
+		Subject: "Your week at the Crown – 3 things",
+		Text:    "Hello\nLMS closes Friday",
+	}, time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := mail.ReadMessage(strings.NewReader(string(raw)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := decodeHeader(t, m.Header.Get("Subject")); got != "Your week at the Crown – 3 things" {
+		t.Errorf("subject = %q", got)
+	}
+	if got := m.Header.Get("Content-Type"); got != "text/plain; charset=utf-8" {
+		t.Errorf("content type = %q", got)
+	}
+	body, _ := io.ReadAll(m.Body)
+	if !strings.Contains(string(body), "Hello\r\nLMS closes Friday") {
+		t.Errorf("body = %q", body)
+	}
+}
+
+func TestBuildAlternative(t *testing.T) {
+	raw, err := build("hub@example.com", Message{
+		To:      "sam@example.com",
+		Subject: "Digest",
+		Text:    "plain",
+		HTML:    "<p>rich</p>",
+	}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := mail.ReadMessage(strings.NewReader(string(raw)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ct := m.Header.Get("Content-Type")
+	if !strings.HasPrefix(ct, "multipart/alternative") {
+		t.Fatalf("content type = %q", ct)
+	}
+	boundary := ct[strings.Index(ct, `boundary="`)+len(`boundary="`) : len(ct)-1]
+
+	r := multipart.NewReader(m.Body, boundary)
+	var types, bodies []string
+	for {
+		p, err := r.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := io.ReadAll(p) // multipart decodes quoted-printable
+		types = append(types, p.Header.Get("Content-Type"))
+		bodies = append(bodies, string(b))
+	}
+	if len(types) != 2 || !strings.HasPrefix(types[0], "text/plain") || !strings.HasPrefix(types[1], "text/html") {
+		t.Fatalf("parts = %v", types)
+	}
+	if bodies[0] != "plain" || bodies[1] != "<p>rich</p>" {
+		t.Errorf("bodies = %q", bodies)
+	}
+}
+
+func TestSendWithoutRelayOnlyLogs(t *testing.T) {
+	m := &Mailer{From: "hub@example.com"}
+	if m.Enabled() {
+		t.Fatal("mailer without a host should be disabled")
+	}
+	if err := m.Send(context.Background(), Message{To: "sam@example.com", Subject: "x", Text: "y"}); err != nil {
+		t.Errorf("Send() = %v, want nil", err)
+	}
+	if err := m.Send(context.Background(), Message{To: "not an address", Text: "y"}); err == nil {
+		t.Error("expected an error for an invalid recipient")
+	}
+}
+
+// fakeRelay accepts one message over plain SMTP and returns what it received
+func fakeRelay(t *testing.T) (addr string, got <-chan string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	out := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(s string) { conn.Write([]byte(s + "\r\n")) }
+
+		var transcript strings.Builder
+		reply("220 fake ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			transcript.WriteString(line)
+			cmd := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"):
+				reply("250-fake")
+				reply("250 8BITMIME")
+			case cmd == "DATA":
+				reply("354 go ahead")
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if l == ".\r\n" {
+						break
+					}
+					transcript.WriteString(l)
+				}
+				reply("250 queued")
+			case cmd == "QUIT":
+				reply("221 bye")
+				out <- transcript.String()
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+	return ln.Addr().String(), out
+}
+
+func TestSendThroughRelay(t *testing.T) {
+	addr, got := fakeRelay(t)
+	host, port, _ := net.SplitHostPort(addr)
+
+	m := &Mailer{Host: host, Port: port, From: "Hub <hub@example.com>", Timeout: 5 * time.Second}
+	err := m.Send(context.Background(), Message{To: "sam@example.com", Subject: "Digest", Text: "Quiz night Thursday"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transcript := <-got
+	for _, want := range []string{"MAIL FROM:<hub@example.com>", "RCPT TO:<sam@example.com>", "Subject: Digest", "Quiz night Thursday"} {
+		if !strings.Contains(transcript, want) {
+			t.Errorf("relay didn't receive %q:\n%s", want, transcript)
+		}
+	}
+}
+
+func decodeHeader(t *testing.T, s string) string {
+	t.Helper()
+	out, err := new(mime.WordDecoder).DecodeHeader(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
@@ -0,0 +1,194 @@
+// Package maintenance takes one app offline while it's being worked on,
+// without disabling it in the app registry.
+//
+// Setup admins switch maintenance on per app (applications.maintenance, with
+// a message for players) in setup-admin. The identity shell hides the app
+// from players and flags it for admins; the app's backend answers API calls
+// from everyone but admins with 503 and the message. Each backend keeps its
+// own copy, reloaded within RefreshInterval.
+package maintenance
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/achgithub/activity-hub-common/auth"
+	"github.com/achgithub/activity-hub-common/i18n"
+)
+
+// RefreshInterval is how often maintenance is reloaded from the identity DB
+const RefreshInterval = 30 * time.Second
+
+// DefaultMessage is shown when an admin gives no message. It's sent in the
+// player's language (see MessageFor).
+const DefaultMessage = "This app is down for maintenance. Please try again soon."
+
+// Notice is an app's maintenance
+type Notice struct {
+	AppID   string    `json:"appId"`
+	Message string    `json:"message"`
+	Since   time.Time `json:"since"`
+	By      string    `json:"by,omitempty"`
+}
+
+// MessageFor returns the notice's message for a request: the admin's own
+// words as given, or DefaultMessage translated (i18n key "maintenance")
+func (n Notice) MessageFor(r *http.Request) string {
+	if n.Message == DefaultMessage {
+		return i18n.T(r, "maintenance")
+	}
+	return n.Message
+}
+
+// Set is a backend's copy of which apps are in maintenance, reloaded lazily.
+type Set struct {
+	identityDB *sql.DB
+
+	mu       sync.RWMutex
+	notices  map[string]Notice
+	loadedAt time.Time
+}
+
+// New creates a maintenance set read from the identity DB.
+//
+// Usage:
+//
+//	appMaintenance := maintenance.New(identityDB)
+//
+//	// Wrap the whole router, inside CORS so browsers can read the 503
+//	handler := cors.Middleware(appMaintenance.Middleware("leaderboard")(apphttp.Versioned(r)))
+//
+//	if notice, ok := appMaintenance.Get("leaderboard"); ok { ... }
+func New(identityDB *sql.DB) *Set {
+	return &Set{identityDB: identityDB, notices: map[string]Notice{}}
+}
+
+// Reload reads the apps in maintenance from the identity DB. Callers that
+// have just changed one can call it to skip the refresh interval.
+func (s *Set) Reload() error {
+	rows, err := s.identityDB.Query(`
+		SELECT id, COALESCE(maintenance_message, ''), COALESCE(maintenance_since, NOW()),
+		       COALESCE(maintenance_by, '')
+		FROM applications
+		WHERE maintenance
+	`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	loaded := map[string]Notice{}
+	for rows.Next() {
+		var n Notice
+		if err := rows.Scan(&n.AppID, &n.Message, &n.Since, &n.By); err != nil {
+			return err
+		}
+		if n.Message == "" {
+			n.Message = DefaultMessage
+		}
+		loaded[n.AppID] = n
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.notices = loaded
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *Set) refresh() {
+	s.mu.RLock()
+	stale := time.Since(s.loadedAt) > RefreshInterval
+	s.mu.RUnlock()
+	if !stale {
+		return
+	}
+	if err := s.Reload(); err != nil {
+		log.Printf("⚠️  Maintenance reload failed, keeping previous: %v", err)
+		// Back off until the next interval rather than hitting the DB on every request
+		s.mu.Lock()
+		s.loadedAt = time.Now()
+		s.mu.Unlock()
+	}
+}
+
+// Get returns the app's maintenance notice, if it's in maintenance
+func (s *Set) Get(appID string) (Notice, bool) {
+	s.refresh()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	n, ok := s.notices[appID]
+	return n, ok
+}
+
+// Exempt reports whether a user can use apps in maintenance: admins, who
+// need to check their work before letting players back in
+func Exempt(user *auth.AuthUser) bool {
+	if user == nil || user.Kiosk != nil {
+		return false
+	}
+	return user.IsAdmin || user.HasRole("setup_admin") || user.HasRole("super_user")
+}
+
+// Middleware answers the app's API requests with 503 and {"error": message,
+// "code": "maintenance"} while it's in maintenance, except from admins (see
+// Exempt) and for the health check. Pages are still served, so admins can
+// open the app from the shell; players' API calls fail with the message.
+func (s *Set) Middleware(appID string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			notice, ok := s.Get(appID)
+			if !ok || r.Method == http.MethodOptions || !strings.HasPrefix(r.URL.Path, "/api/") ||
+				isHealthCheck(r.URL.Path) || Exempt(requestUser(s.identityDB, r)) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Cache-Control", "no-store")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(map[string]string{"error": notice.MessageFor(r), "code": "maintenance"})
+		})
+	}
+}
+
+func isHealthCheck(path string) bool {
+	return path == "/api/health" || path == "/api/v1/health"
+}
+
+// requestUser resolves the caller's token: the session token from the
+// Authorization header or cookie, or an SSE stream's ?token=, which is only
+// peeked at so the stream can still consume it. Only done while the app is in
+// maintenance.
+func requestUser(identityDB *sql.DB, r *http.Request) *auth.AuthUser {
+	if identityDB == nil {
+		return nil
+	}
+	token, _ := auth.SessionToken(r)
+	if token == "" {
+		token = r.URL.Query().Get("token")
+	}
+
+	var user *auth.AuthUser
+	var err error
+	switch {
+	case token == "" || auth.IsKioskToken(token):
+		return nil
+	case auth.IsSignedToken(token):
+		user, err = auth.PeekSignedToken(identityDB, token, auth.PurposeStream)
+	default:
+		user, err = auth.ResolveToken(identityDB, token)
+	}
+	if err != nil {
+		return nil
+	}
+	return user
+}
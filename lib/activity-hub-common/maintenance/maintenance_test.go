@@ -0,0 +1,91 @@
+package maintenance
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/achgithub/activity-hub-common/auth"
+)
+
+// loaded returns a set that won't reload from the (missing) database
+func loaded(notices ...Notice) *Set {
+	s := New(nil)
+	for _, n := range notices {
+		s.notices[n.AppID] = n
+	}
+	s.loadedAt = time.Now()
+	return s
+}
+
+func TestMiddleware(t *testing.T) {
+	s := loaded(Notice{AppID: "dots", Message: "New board sizes, back at 8pm"})
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name   string
+		app    string
+		method string
+		path   string
+		want   int
+	}{
+		{"app not in maintenance", "spoof", "GET", "/api/config", http.StatusOK},
+		{"api request", "dots", "GET", "/api/game/1", http.StatusServiceUnavailable},
+		{"versioned api request", "dots", "POST", "/api/v1/move", http.StatusServiceUnavailable},
+		{"page request", "dots", "GET", "/", http.StatusOK},
+		{"health check", "dots", "GET", "/api/health", http.StatusOK},
+		{"versioned health check", "dots", "GET", "/api/v1/health", http.StatusOK},
+		{"preflight", "dots", "OPTIONS", "/api/game/1", http.StatusOK},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		s.Middleware(tt.app)(ok).ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+		if w.Code != tt.want {
+			t.Errorf("%s: status %d, want %d", tt.name, w.Code, tt.want)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	s.Middleware("dots")(ok).ServeHTTP(w, httptest.NewRequest("GET", "/api/game/1", nil))
+	var body map[string]string
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatalf("Expected a JSON error: %v", err)
+	}
+	if body["error"] != "New board sizes, back at 8pm" || body["code"] != "maintenance" {
+		t.Errorf("Unexpected body %v", body)
+	}
+}
+
+func TestGet(t *testing.T) {
+	s := loaded(Notice{AppID: "dots", Message: "Back soon"})
+	if n, ok := s.Get("dots"); !ok || n.Message != "Back soon" {
+		t.Errorf("Get(dots) = %v, %v", n, ok)
+	}
+	if _, ok := s.Get("spoof"); ok {
+		t.Error("Expected spoof not to be in maintenance")
+	}
+}
+
+func TestExempt(t *testing.T) {
+	tests := []struct {
+		name string
+		user *auth.AuthUser
+		want bool
+	}{
+		{"no user", nil, false},
+		{"player", &auth.AuthUser{Roles: []string{"player"}}, false},
+		{"admin", &auth.AuthUser{IsAdmin: true}, true},
+		{"setup admin", &auth.AuthUser{Roles: []string{"setup_admin"}}, true},
+		{"super user", &auth.AuthUser{Roles: []string{"super_user"}}, true},
+		{"kiosk", &auth.AuthUser{IsAdmin: true, Kiosk: &auth.KioskGrant{}}, false},
+	}
+	for _, tt := range tests {
+		if got := Exempt(tt.user); got != tt.want {
+			t.Errorf("%s: Exempt = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
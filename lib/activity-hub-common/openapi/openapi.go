@@ -0,0 +1,347 @@
+// Package openapi builds an OpenAPI 3.1 document from route metadata declared
+// next to a backend's router, and serves it at GET /api/openapi.json so
+// frontends and third-party integrators have a machine-readable contract.
+//
+// Request and response bodies are described by example values: a struct (or
+// pointer, slice or map of one) is documented from its type following
+// encoding/json's rules, and handlers that answer with ad-hoc maps are
+// documented with Fields, whose values give each field's type.
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Fields describes a JSON object field by field, for handlers that build
+// their responses as maps. Each value is an example of the field's type:
+//
+//	openapi.Fields{"success": true, "jobId": ""}
+//	openapi.Fields{"rounds": []openapi.Fields{{"id": 0, "label": 0}}}
+type Fields map[string]interface{}
+
+// Spec is one backend's API description
+type Spec struct {
+	title       string
+	version     string
+	description string
+	textErrors  bool
+
+	mu  sync.Mutex
+	ops []*Operation
+}
+
+// New creates an empty spec for a backend.
+//
+// Usage:
+//
+//	spec := openapi.New("game-admin", "1.0.0", "Admin console for LMS, quizzes and sweepstakes")
+//	lms := spec.Group("LMS").Auth()
+//	lms.Route("GET", "/api/lms/rounds/{gameId}", "List a game's rounds").
+//	    Returns(http.StatusOK, openapi.Fields{"rounds": []Round{}})
+//	lms.Route("POST", "/api/lms/rounds", "Create a round").
+//	    Body(CreateRoundRequest{}).Returns(http.StatusOK, openapi.Fields{"success": true})
+//
+//	r.HandleFunc("/api/openapi.json", spec.Handler).Methods("GET")
+func New(title, version, description string) *Spec {
+	return &Spec{title: title, version: version, description: description}
+}
+
+// TextErrors documents failures as plain text (http.Error) rather than the
+// usual {"error": "..."} JSON
+func (s *Spec) TextErrors() *Spec {
+	s.textErrors = true
+	return s
+}
+
+// Group starts a set of routes sharing a tag (and, with Auth, a bearer token)
+func (s *Spec) Group(tag string) *Group {
+	return &Group{spec: s, tag: tag}
+}
+
+// Route declares an untagged, unauthenticated route
+func (s *Spec) Route(method, path, summary string) *Operation {
+	return s.add(method, path, summary, "", false)
+}
+
+func (s *Spec) add(method, path, summary, tag string, auth bool) *Operation {
+	op := &Operation{
+		method:    strings.ToUpper(method),
+		path:      path,
+		summary:   summary,
+		tag:       tag,
+		auth:      auth,
+		responses: map[int]response{},
+	}
+	s.mu.Lock()
+	s.ops = append(s.ops, op)
+	s.mu.Unlock()
+	return op
+}
+
+// Group is a set of routes sharing a tag and authentication
+type Group struct {
+	spec *Spec
+	tag  string
+	auth bool
+}
+
+// Auth marks the group's routes as needing an Authorization: Bearer token
+func (g *Group) Auth() *Group {
+	g.auth = true
+	return g
+}
+
+// Route declares a route in the group
+func (g *Group) Route(method, path, summary string) *Operation {
+	return g.spec.add(method, path, summary, g.tag, g.auth)
+}
+
+// Operation is one method on one path. Its methods return it for chaining.
+type Operation struct {
+	method  string
+	path    string
+	summary string
+	tag     string
+	auth    bool
+
+	body      interface{}
+	query     []queryParam
+	responses map[int]response
+}
+
+type queryParam struct {
+	name        string
+	description string
+}
+
+type response struct {
+	contentType string
+	example     interface{}
+}
+
+// Body documents the JSON request body
+func (o *Operation) Body(example interface{}) *Operation {
+	o.body = example
+	return o
+}
+
+// Upload documents a multipart/form-data request body with a single file field
+func (o *Operation) Upload(field string) *Operation {
+	o.body = multipart{field: field}
+	return o
+}
+
+// Query documents an optional query string parameter
+func (o *Operation) Query(name, description string) *Operation {
+	o.query = append(o.query, queryParam{name: name, description: description})
+	return o
+}
+
+// Paged documents the limit, offset and sort parameters of a list endpoint
+// built with the listquery package. Document its filters with Query.
+func (o *Operation) Paged() *Operation {
+	return o.Query("limit", "Page size").
+		Query("offset", "Rows to skip; the previous page's nextOffset").
+		Query("sort", "Sort field, prefixed with - for descending")
+}
+
+// Returns documents a JSON response. A nil example documents the status alone.
+func (o *Operation) Returns(status int, example interface{}) *Operation {
+	o.responses[status] = response{contentType: "application/json", example: example}
+	return o
+}
+
+// Stream documents a Server-Sent Events response. Its events are described
+// by the backend's GET /api/events/schema.
+func (o *Operation) Stream() *Operation {
+	o.responses[http.StatusOK] = response{contentType: "text/event-stream"}
+	return o
+}
+
+// Produces documents a non-JSON response, such as a file download
+func (o *Operation) Produces(status int, contentType string) *Operation {
+	o.responses[status] = response{contentType: contentType}
+	return o
+}
+
+type multipart struct {
+	field string
+}
+
+// pathParams strips gorilla/mux patterns from a route ("/avatars/{hash:[0-9a-f]{64}}"
+// becomes "/avatars/{hash}") and returns the path variable names. Patterns may
+// contain braces of their own, so they're matched by depth rather than a regexp.
+func pathParams(route string) (string, []string) {
+	var path strings.Builder
+	var names []string
+	for i := 0; i < len(route); i++ {
+		if route[i] != '{' {
+			path.WriteByte(route[i])
+			continue
+		}
+		depth, end := 1, i+1
+		for ; end < len(route) && depth > 0; end++ {
+			switch route[end] {
+			case '{':
+				depth++
+			case '}':
+				depth--
+			}
+		}
+		name, _, _ := strings.Cut(route[i+1:end-1], ":")
+		names = append(names, name)
+		path.WriteString("{" + name + "}")
+		i = end - 1
+	}
+	return path.String(), names
+}
+
+// Document returns the OpenAPI document for every declared route
+func (s *Spec) Document() map[string]interface{} {
+	s.mu.Lock()
+	ops := append([]*Operation(nil), s.ops...)
+	s.mu.Unlock()
+
+	schemas := newSchemaSet()
+	paths := map[string]interface{}{}
+	tags := map[string]bool{}
+	for _, op := range ops {
+		path, _ := pathParams(op.path)
+		item, ok := paths[path].(map[string]interface{})
+		if !ok {
+			item = map[string]interface{}{}
+			paths[path] = item
+		}
+		item[strings.ToLower(op.method)] = op.document(schemas, s.textErrors)
+		if op.tag != "" {
+			tags[op.tag] = true
+		}
+	}
+
+	tagNames := make([]string, 0, len(tags))
+	for tag := range tags {
+		tagNames = append(tagNames, tag)
+	}
+	sort.Strings(tagNames)
+	tagList := make([]interface{}, len(tagNames))
+	for i, tag := range tagNames {
+		tagList[i] = map[string]interface{}{"name": tag}
+	}
+
+	schemas.defs["Error"] = map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{"error": map[string]interface{}{"type": "string"}},
+		"required":   []string{"error"},
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.1.0",
+		"info": map[string]interface{}{
+			"title":       s.title,
+			"version":     s.version,
+			"description": s.description,
+		},
+		"tags":  tagList,
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": schemas.defs,
+			"securitySchemes": map[string]interface{}{
+				"bearerAuth": map[string]interface{}{"type": "http", "scheme": "bearer"},
+			},
+		},
+	}
+}
+
+func (o *Operation) document(schemas *schemaSet, textErrors bool) map[string]interface{} {
+	doc := map[string]interface{}{"summary": o.summary}
+	if o.tag != "" {
+		doc["tags"] = []string{o.tag}
+	}
+	if o.auth {
+		doc["security"] = []interface{}{map[string]interface{}{"bearerAuth": []string{}}}
+	}
+
+	var params []interface{}
+	_, names := pathParams(o.path)
+	for _, name := range names {
+		params = append(params, map[string]interface{}{
+			"name": name, "in": "path", "required": true,
+			"schema": map[string]interface{}{"type": "string"},
+		})
+	}
+	for _, q := range o.query {
+		params = append(params, map[string]interface{}{
+			"name": q.name, "in": "query", "description": q.description,
+			"schema": map[string]interface{}{"type": "string"},
+		})
+	}
+	if len(params) > 0 {
+		doc["parameters"] = params
+	}
+
+	switch body := o.body.(type) {
+	case nil:
+	case multipart:
+		doc["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content": map[string]interface{}{"multipart/form-data": map[string]interface{}{
+				"schema": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						body.field: map[string]interface{}{"type": "string", "contentMediaType": "application/octet-stream"},
+					},
+					"required": []string{body.field},
+				},
+			}},
+		}
+	default:
+		doc["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content": map[string]interface{}{"application/json": map[string]interface{}{
+				"schema": schemas.valueSchema(body),
+			}},
+		}
+	}
+
+	responses := map[string]interface{}{}
+	for status, resp := range o.responses {
+		r := map[string]interface{}{"description": http.StatusText(status)}
+		switch {
+		case resp.contentType == "application/json" && resp.example != nil:
+			r["content"] = map[string]interface{}{resp.contentType: map[string]interface{}{
+				"schema": schemas.valueSchema(resp.example),
+			}}
+		case resp.contentType != "application/json":
+			r["content"] = map[string]interface{}{resp.contentType: map[string]interface{}{}}
+		}
+		responses[strconv.Itoa(status)] = r
+	}
+	if len(o.responses) == 0 {
+		responses["200"] = map[string]interface{}{"description": "OK"}
+	}
+	// Backends answer failures with {"error": "..."} unless told otherwise
+	errorContent := map[string]interface{}{"application/json": map[string]interface{}{
+		"schema": map[string]interface{}{"$ref": "#/components/schemas/Error"},
+	}}
+	if textErrors {
+		errorContent = map[string]interface{}{"text/plain": map[string]interface{}{
+			"schema": map[string]interface{}{"type": "string"},
+		}}
+	}
+	responses["default"] = map[string]interface{}{"description": "Error", "content": errorContent}
+	doc["responses"] = responses
+	return doc
+}
+
+// Handler serves the document. Backends mount it at GET /api/openapi.json.
+func (s *Spec) Handler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(s.Document())
+}
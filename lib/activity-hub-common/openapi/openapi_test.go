@@ -0,0 +1,171 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type testRound struct {
+	ID       int        `json:"id"`
+	Label    string     `json:"label"`
+	Deadline *time.Time `json:"deadline,omitempty"`
+	Next     *testRound `json:"next,omitempty"`
+}
+
+type createRoundRequest struct {
+	GameID int    `json:"gameId"`
+	Label  string `json:"label"`
+}
+
+func testSpec() *Spec {
+	spec := New("test", "1.0.0", "Test backend")
+	spec.Route("GET", "/api/config", "App config").
+		Returns(http.StatusOK, Fields{"appName": "", "version": ""})
+
+	lms := spec.Group("LMS").Auth()
+	lms.Route("GET", "/api/lms/rounds/{gameId:[0-9]+}", "List rounds").
+		Query("status", "Only rounds with this status").
+		Returns(http.StatusOK, Fields{"rounds": []testRound{}})
+	lms.Route("POST", "/api/lms/rounds", "Create a round").
+		Body(createRoundRequest{}).
+		Returns(http.StatusOK, Fields{"success": true, "rounds": []Fields{{"id": 0}}})
+	lms.Route("POST", "/api/lms/fixtures/upload", "Upload fixtures").Upload("file")
+	lms.Route("GET", "/api/lms/stream", "Round updates").Stream()
+	return spec
+}
+
+// decode round-trips the document through JSON so tests see what clients see
+func decode(t *testing.T, doc map[string]interface{}) map[string]interface{} {
+	t.Helper()
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var out map[string]interface{}
+	json.Unmarshal(data, &out)
+	return out
+}
+
+// at walks a decoded document by keys
+func at(t *testing.T, v interface{}, keys ...string) interface{} {
+	t.Helper()
+	for _, k := range keys {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			t.Fatalf("no %q in %v", k, v)
+		}
+		v = m[k]
+	}
+	return v
+}
+
+func TestDocumentPathsAndParams(t *testing.T) {
+	doc := decode(t, testSpec().Document())
+
+	if doc["openapi"] != "3.1.0" || at(t, doc, "info", "title") != "test" {
+		t.Errorf("Unexpected header %v %v", doc["openapi"], doc["info"])
+	}
+
+	// mux patterns are stripped from the path
+	get := at(t, doc, "paths", "/api/lms/rounds/{gameId}", "get")
+	if get == nil {
+		t.Fatalf("Missing rounds path: %v", at(t, doc, "paths"))
+	}
+	params := at(t, get, "parameters").([]interface{})
+	if len(params) != 2 || at(t, params[0], "in") != "path" || at(t, params[0], "name") != "gameId" ||
+		at(t, params[1], "in") != "query" || at(t, params[1], "name") != "status" {
+		t.Errorf("Unexpected parameters %v", params)
+	}
+	if at(t, get, "security") == nil || at(t, get, "tags").([]interface{})[0] != "LMS" {
+		t.Errorf("Grouped route should be tagged and authenticated: %v", get)
+	}
+	if at(t, doc, "paths", "/api/config", "get", "security") != nil {
+		t.Error("Ungrouped route should not need auth")
+	}
+	if at(t, get, "responses", "default", "content", "application/json", "schema", "$ref") != "#/components/schemas/Error" {
+		t.Error("Expected the shared error response")
+	}
+}
+
+func TestDocumentSchemas(t *testing.T) {
+	doc := decode(t, testSpec().Document())
+
+	rounds := at(t, doc, "paths", "/api/lms/rounds/{gameId}", "get", "responses", "200",
+		"content", "application/json", "schema", "properties", "rounds")
+	if at(t, rounds, "type") != "array" || at(t, rounds, "items", "$ref") != "#/components/schemas/testRound" {
+		t.Errorf("Expected an array of testRound, got %v", rounds)
+	}
+
+	round := at(t, doc, "components", "schemas", "testRound")
+	if at(t, round, "properties", "deadline", "anyOf") == nil {
+		t.Errorf("Pointer fields should be nullable: %v", round)
+	}
+	if required := at(t, round, "required").([]interface{}); len(required) != 2 {
+		t.Errorf("omitempty fields should be optional, got required %v", required)
+	}
+	next := at(t, round, "properties", "next", "anyOf").([]interface{})
+	if at(t, next[0], "$ref") != "#/components/schemas/testRound" {
+		t.Errorf("Recursive field should refer to its own schema: %v", next)
+	}
+
+	post := at(t, doc, "paths", "/api/lms/rounds", "post")
+	if at(t, post, "requestBody", "content", "application/json", "schema", "$ref") != "#/components/schemas/createRoundRequest" {
+		t.Errorf("Unexpected request body %v", at(t, post, "requestBody"))
+	}
+	// Fields are described from their values, nested ones included
+	resp := at(t, post, "responses", "200", "content", "application/json", "schema", "properties")
+	if at(t, resp, "success", "type") != "boolean" || at(t, resp, "rounds", "items", "properties", "id", "type") != "integer" {
+		t.Errorf("Unexpected Fields schema %v", resp)
+	}
+
+	upload := at(t, doc, "paths", "/api/lms/fixtures/upload", "post", "requestBody", "content", "multipart/form-data")
+	if at(t, upload, "schema", "properties", "file") == nil {
+		t.Errorf("Expected a multipart file field, got %v", upload)
+	}
+	if at(t, doc, "paths", "/api/lms/stream", "get", "responses", "200", "content", "text/event-stream") == nil {
+		t.Error("Expected an event stream response")
+	}
+}
+
+func TestTextErrors(t *testing.T) {
+	spec := New("test", "1.0.0", "").TextErrors()
+	spec.Route("GET", "/api/standings", "Standings")
+	doc := decode(t, spec.Document())
+	if at(t, doc, "paths", "/api/standings", "get", "responses", "default", "content", "text/plain") == nil {
+		t.Error("Expected plain text errors")
+	}
+}
+
+func TestPathParams(t *testing.T) {
+	for route, want := range map[string]string{
+		"/api/sessions/{id}/answers/{questionId}": "/api/sessions/{id}/answers/{questionId}",
+		"/api/avatars/{hash:[0-9a-f]{64}}":        "/api/avatars/{hash}",
+		"/api/apps/{id}/{action:enable|disable}":  "/api/apps/{id}/{action}",
+	} {
+		if got, _ := pathParams(route); got != want {
+			t.Errorf("pathParams(%q) = %q, want %q", route, got, want)
+		}
+	}
+	if _, names := pathParams("/api/apps/{id}/{action:enable|disable}"); len(names) != 2 || names[1] != "action" {
+		t.Errorf("Unexpected names %v", names)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	w := httptest.NewRecorder()
+	testSpec().Handler(w, httptest.NewRequest("GET", "/api/openapi.json", nil))
+
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("Handler served invalid JSON: %v", err)
+	}
+	if len(at(t, doc, "paths").(map[string]interface{})) != 5 {
+		t.Errorf("Expected 5 paths, got %v", at(t, doc, "paths"))
+	}
+}
@@ -0,0 +1,173 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// schemaSet collects named struct types as reusable component schemas
+type schemaSet struct {
+	defs  map[string]interface{}
+	names map[reflect.Type]string
+	taken map[string]bool
+}
+
+func newSchemaSet() *schemaSet {
+	return &schemaSet{
+		defs:  map[string]interface{}{},
+		names: map[reflect.Type]string{},
+		taken: map[string]bool{"Error": true},
+	}
+}
+
+// valueSchema describes an example value. Maps with interface{} values (Fields)
+// and slices of them are described from their contents; everything else from
+// its type.
+func (s *schemaSet) valueSchema(v interface{}) map[string]interface{} {
+	if v == nil {
+		return map[string]interface{}{}
+	}
+	rv := reflect.ValueOf(v)
+	t := rv.Type()
+
+	switch {
+	case t.Kind() == reflect.Map && t.Key().Kind() == reflect.String && t.Elem().Kind() == reflect.Interface:
+		properties := map[string]interface{}{}
+		iter := rv.MapRange()
+		for iter.Next() {
+			properties[iter.Key().String()] = s.valueSchema(iter.Value().Interface())
+		}
+		return map[string]interface{}{"type": "object", "properties": properties}
+	case (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && rv.Len() > 0 && describedByValue(t.Elem()):
+		return map[string]interface{}{"type": "array", "items": s.valueSchema(rv.Index(0).Interface())}
+	}
+	return s.typeSchema(t, map[reflect.Type]bool{})
+}
+
+// describedByValue reports whether values of t need their contents inspected
+func describedByValue(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Interface:
+		return true
+	case reflect.Map:
+		return t.Key().Kind() == reflect.String && t.Elem().Kind() == reflect.Interface
+	}
+	return false
+}
+
+// typeSchema maps a Go type to a JSON Schema following encoding/json's rules.
+// Named structs become component schemas referenced by $ref; seen guards
+// against recursive anonymous types.
+func (s *schemaSet) typeSchema(t reflect.Type, seen map[reflect.Type]bool) map[string]interface{} {
+	switch t {
+	case timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case rawMessageType:
+		return map[string]interface{}{}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return map[string]interface{}{
+			"anyOf": []interface{}{s.typeSchema(t.Elem(), seen), map[string]interface{}{"type": "null"}},
+		}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "contentEncoding": "base64"}
+		}
+		return map[string]interface{}{"type": "array", "items": s.typeSchema(t.Elem(), seen)}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": s.typeSchema(t.Elem(), seen)}
+	case reflect.Struct:
+		if t.Name() != "" {
+			return map[string]interface{}{"$ref": "#/components/schemas/" + s.define(t)}
+		}
+		if seen[t] {
+			return map[string]interface{}{"type": "object"}
+		}
+		seen[t] = true
+		defer delete(seen, t)
+		return s.structSchema(t, seen)
+	}
+	// interface{} and anything else: any JSON value
+	return map[string]interface{}{}
+}
+
+// define adds a named struct to the component schemas once and returns its name
+func (s *schemaSet) define(t reflect.Type) string {
+	if name, ok := s.names[t]; ok {
+		return name
+	}
+
+	// Generic instantiations carry their type arguments in the name
+	base := strings.NewReplacer("[", "_", "]", "", ",", "_", "/", "_", "*", "").Replace(t.Name())
+	name := base
+	for i := 2; s.taken[name]; i++ {
+		name = base + strconv.Itoa(i)
+	}
+
+	// Record the name before describing the fields so recursive types can refer to it
+	s.names[t] = name
+	s.taken[name] = true
+	s.defs[name] = s.structSchema(t, map[reflect.Type]bool{})
+	return name
+}
+
+func (s *schemaSet) structSchema(t reflect.Type, seen map[reflect.Type]bool) map[string]interface{} {
+	properties := map[string]interface{}{}
+	required := []string{}
+	s.addFields(t, seen, properties, &required)
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// addFields adds a struct's JSON fields, flattening embedded structs.
+func (s *schemaSet) addFields(t reflect.Type, seen map[reflect.Type]bool, properties map[string]interface{}, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" || (!f.IsExported() && !f.Anonymous) {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		ft := f.Type
+		if f.Anonymous && name == "" {
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				s.addFields(ft, seen, properties, required)
+				continue
+			}
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		properties[name] = s.typeSchema(ft, seen)
+		if !strings.Contains(opts, "omitempty") {
+			*required = append(*required, name)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+// Package points is the loyalty points ledger: players earn points for taking
+// part (quiz attendance, LMS entry, challenges played) and landlords redeem
+// them for rewards behind the bar.
+//
+// Everything lives in the identity database (scripts/migrate_add_loyalty_points.sh):
+// points_rules holds the points per activity, points_ledger one row per award
+// and points_redemptions the landlord's redemption log.
+package points
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Activities that earn points. The points for each are set in points_rules
+// (game-admin → Points); an activity without a rule earns nothing.
+const (
+	ActivityQuizAttendance  = "quiz_attendance"
+	ActivityLMSEntry        = "lms_entry"
+	ActivityChallengePlayed = "challenge_played"
+)
+
+// MonthFormat is how months are written in tallies and query parameters
+const MonthFormat = "2006-01"
+
+// ErrInsufficientPoints is returned by Redeem when the balance is too low
+var ErrInsufficientPoints = errors.New("not enough points")
+
+// Award is one player taking part in something.
+type Award struct {
+	UserEmail string
+	Activity  string
+	App       string
+	Ref       string // What was attended or played (session, game or challenge ID)
+	VenueID   int    // 0 = not tied to a venue
+}
+
+// Validate checks an award before it is recorded.
+func (a Award) Validate() error {
+	if a.UserEmail == "" || a.Activity == "" || a.App == "" || a.Ref == "" {
+		return fmt.Errorf("userEmail, activity, app and ref are required")
+	}
+	return nil
+}
+
+// IsGuest reports whether the award is for a guest session. Guests don't
+// collect points.
+func (a Award) IsGuest() bool {
+	return strings.HasPrefix(a.UserEmail, "guest-")
+}
+
+// Record awards the points the activity is worth. Each user earns once per
+// activity and ref, so recording the same award twice is harmless. Returns the
+// points awarded (0 for a repeat, a guest or an activity with no rule).
+//
+// Usage:
+//
+//	go func() {
+//	    if _, err := points.Record(identityDB, points.Award{
+//	        UserEmail: user.Email,
+//	        Activity:  points.ActivityLMSEntry,
+//	        App:       "last-man-standing",
+//	        Ref:       strconv.Itoa(gameID),
+//	    }); err != nil {
+//	        log.Printf("Failed to award points: %v", err)
+//	    }
+//	}()
+func Record(identityDB *sql.DB, a Award) (int, error) {
+	if err := a.Validate(); err != nil {
+		return 0, err
+	}
+	if a.IsGuest() {
+		return 0, nil
+	}
+
+	var awarded int
+	err := identityDB.QueryRow(`
+		INSERT INTO points_ledger (user_email, activity, app, ref, venue_id, points)
+		SELECT $1, $2, $3, $4, NULLIF($5, 0), r.points
+		FROM points_rules r
+		WHERE r.activity = $2 AND r.points > 0
+		ON CONFLICT (user_email, activity, ref) DO NOTHING
+		RETURNING points
+	`, a.UserEmail, a.Activity, a.App, a.Ref, a.VenueID).Scan(&awarded)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to record points: %w", err)
+	}
+	return awarded, nil
+}
+
+// Tally is one user's points for one month.
+type Tally struct {
+	Month      string         `json:"month"` // MonthFormat
+	Earned     int            `json:"earned"`
+	Redeemed   int            `json:"redeemed"`
+	ByActivity map[string]int `json:"byActivity"` // Points earned per activity
+}
+
+// MonthlyTallies returns the user's tallies for the current month and the
+// months before it, newest first. Months with no points are included.
+func MonthlyTallies(identityDB *sql.DB, email string, months int) ([]Tally, error) {
+	if months < 1 {
+		months = 1
+	}
+	first, _, _ := ParseMonth("")
+
+	tallies := make([]Tally, months)
+	index := map[string]*Tally{}
+	for i := range tallies {
+		month := first.AddDate(0, -i, 0).Format(MonthFormat)
+		tallies[i] = Tally{Month: month, ByActivity: map[string]int{}}
+		index[month] = &tallies[i]
+	}
+	since := first.AddDate(0, -(months - 1), 0)
+
+	rows, err := identityDB.Query(`
+		SELECT to_char(created_at, 'YYYY-MM'), activity, SUM(points)
+		FROM points_ledger
+		WHERE user_email = $1 AND created_at >= $2
+		GROUP BY 1, 2
+	`, email, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load points: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var month, activity string
+		var sum int
+		if err := rows.Scan(&month, &activity, &sum); err != nil {
+			return nil, fmt.Errorf("failed to scan points: %w", err)
+		}
+		if t := index[month]; t != nil {
+			t.Earned += sum
+			t.ByActivity[activity] += sum
+		}
+	}
+
+	rows, err = identityDB.Query(`
+		SELECT to_char(created_at, 'YYYY-MM'), SUM(points)
+		FROM points_redemptions
+		WHERE user_email = $1 AND created_at >= $2
+		GROUP BY 1
+	`, email, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load redemptions: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var month string
+		var sum int
+		if err := rows.Scan(&month, &sum); err != nil {
+			return nil, fmt.Errorf("failed to scan redemptions: %w", err)
+		}
+		if t := index[month]; t != nil {
+			t.Redeemed = sum
+		}
+	}
+	return tallies, nil
+}
+
+// Balance returns the points the user has earned and not yet redeemed.
+func Balance(identityDB *sql.DB, email string) (int, error) {
+	var balance int
+	err := identityDB.QueryRow(balanceQuery, email).Scan(&balance)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load points balance: %w", err)
+	}
+	return balance, nil
+}
+
+const balanceQuery = `
+	SELECT COALESCE((SELECT SUM(points) FROM points_ledger WHERE user_email = $1), 0)
+	     - COALESCE((SELECT SUM(points) FROM points_redemptions WHERE user_email = $1), 0)`
+
+// Redemption is a reward handed over by the landlord.
+type Redemption struct {
+	UserEmail  string
+	Points     int
+	Reward     string // e.g. "Free pint"
+	RedeemedBy string // Admin email
+	VenueID    int    // 0 = not tied to a venue
+}
+
+// Redeem records a redemption and returns the user's remaining balance, or
+// ErrInsufficientPoints if they haven't got enough.
+func Redeem(identityDB *sql.DB, rd Redemption) (int, error) {
+	if rd.UserEmail == "" || rd.Points <= 0 || strings.TrimSpace(rd.Reward) == "" {
+		return 0, fmt.Errorf("userEmail, points and reward are required")
+	}
+
+	tx, err := identityDB.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to start redemption: %w", err)
+	}
+	defer tx.Rollback()
+
+	// One redemption per user at a time, so two tills can't spend the same points
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext('points:' || $1::text))`, rd.UserEmail); err != nil {
+		return 0, fmt.Errorf("failed to lock points balance: %w", err)
+	}
+	var balance int
+	if err := tx.QueryRow(balanceQuery, rd.UserEmail).Scan(&balance); err != nil {
+		return 0, fmt.Errorf("failed to load points balance: %w", err)
+	}
+	if balance < rd.Points {
+		return balance, ErrInsufficientPoints
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO points_redemptions (user_email, points, reward, redeemed_by, venue_id)
+		VALUES ($1, $2, $3, $4, NULLIF($5, 0))
+	`, rd.UserEmail, rd.Points, strings.TrimSpace(rd.Reward), rd.RedeemedBy, rd.VenueID); err != nil {
+		return 0, fmt.Errorf("failed to record redemption: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to record redemption: %w", err)
+	}
+	return balance - rd.Points, nil
+}
+
+// ParseMonth parses a MonthFormat month ("2026-10"); empty means this month.
+// Returns the first instant of the month and of the month after it.
+func ParseMonth(month string) (time.Time, time.Time, error) {
+	var start time.Time
+	if month == "" {
+		now := time.Now()
+		start = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	} else {
+		t, err := time.ParseInLocation(MonthFormat, month, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("month must be YYYY-MM")
+		}
+		start = t
+	}
+	return start, start.AddDate(0, 1, 0), nil
+}
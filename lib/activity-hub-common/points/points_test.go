@@ -0,0 +1,53 @@
+package points
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAwardValidate(t *testing.T) {
+	valid := Award{UserEmail: "alice@pub.local", Activity: ActivityLMSEntry, App: "last-man-standing", Ref: "12"}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	if valid.IsGuest() {
+		t.Error("expected a registered user not to be a guest")
+	}
+
+	missingRef := valid
+	missingRef.Ref = ""
+	if err := missingRef.Validate(); err == nil {
+		t.Error("expected error for missing ref")
+	}
+
+	guest := valid
+	guest.UserEmail = "guest-3f2a"
+	if err := guest.Validate(); err != nil || !guest.IsGuest() {
+		t.Errorf("expected a valid guest award, got guest=%v err=%v", guest.IsGuest(), err)
+	}
+}
+
+func TestParseMonth(t *testing.T) {
+	start, end, err := ParseMonth("2026-12")
+	if err != nil {
+		t.Fatalf("ParseMonth() error = %v", err)
+	}
+	if start.Format(MonthFormat) != "2026-12" || end.Format(MonthFormat) != "2027-01" {
+		t.Errorf("got %v - %v", start, end)
+	}
+	if start.Day() != 1 || start.Hour() != 0 {
+		t.Errorf("expected start of month, got %v", start)
+	}
+
+	start, _, err = ParseMonth("")
+	if err != nil || start.Format(MonthFormat) != time.Now().Format(MonthFormat) {
+		t.Errorf("empty month = %v (%v), want this month", start, err)
+	}
+
+	for _, bad := range []string{"2026-13", "October", "2026/10"} {
+		if _, _, err := ParseMonth(bad); err == nil {
+			t.Errorf("ParseMonth(%q) expected error", bad)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/achgithub/activity-hub-common/auth"
+)
+
+// LeaderboardApp is the leaderboard's app ID in the registry
+const LeaderboardApp = "leaderboard"
+
+// ShellApp is the identity shell. It isn't in the app registry, so it's
+// found on its fixed port unless IDENTITY_SHELL_URL is set.
+const ShellApp = "identity-shell"
+
+const shellURL = "http://127.0.0.1:3001"
+
+// CreateGame asks a game backend to start a game for an accepted challenge
+// (POST /api/game) and returns the new game's ID. body carries the challenge
+// ID, players and options in the game's own format; token is the
+// challenger's. It isn't retried once the game backend has seen it, so a slow
+// backend can't end up with two games for one challenge.
+func (r *Registry) CreateGame(ctx context.Context, appID, token string, body interface{}) (string, error) {
+	var result struct {
+		Success bool   `json:"success"`
+		GameID  string `json:"gameId"`
+	}
+	if err := r.Client(appID).Post(ctx, "/api/game", token, body, &result); err != nil {
+		return "", err
+	}
+	if !result.Success || result.GameID == "" {
+		return "", fmt.Errorf("%s did not create the game", appID)
+	}
+	return result.GameID, nil
+}
+
+// Result is a completed two-player game as the leaderboard records it
+type Result struct {
+	GameType   string `json:"gameType"`
+	GameID     string `json:"gameId"`
+	WinnerID   string `json:"winnerId"`
+	WinnerName string `json:"winnerName"`
+	LoserID    string `json:"loserId"`
+	LoserName  string `json:"loserName"`
+	IsDraw     bool   `json:"isDraw"`
+	Score      string `json:"score"`    // e.g. "3-2"
+	Duration   int    `json:"duration"` // Seconds
+
+	// SelfReported marks results only the winner vouches for (claim-win
+	// after a disconnect)
+	SelfReported bool `json:"selfReported,omitempty"`
+}
+
+// ReportResult posts a completed game to the leaderboard (POST /api/result)
+// with a player's token. The leaderboard ignores game IDs it has already
+// recorded, so the call is retried like a GET.
+func (r *Registry) ReportResult(ctx context.Context, token string, result Result) error {
+	return r.Client(LeaderboardApp).Do(ctx, Request{
+		Method:     http.MethodPost,
+		Path:       "/api/result",
+		Token:      token,
+		Body:       result,
+		Idempotent: true,
+	}, nil)
+}
+
+// gamePresencePath is the shell's route for one game's in-game presence
+func gamePresencePath(appID, gameID string) string {
+	return "/api/presence/games/" + url.PathEscape(appID) + "/" + url.PathEscape(gameID)
+}
+
+// SetInGame tells the shell that players (emails) are in a game of appID, so
+// the lobby shows them as playing and turns challenges to them away. It lasts
+// until ClearInGame or the shell's in-game TTL; call it again to extend a long
+// game. The call is made as the app, with a service token, not as a player.
+func (r *Registry) SetInGame(ctx context.Context, appID, gameID string, players []string) error {
+	token, err := auth.MintServiceToken(appID, auth.ServiceTokenTTL)
+	if err != nil {
+		return err
+	}
+	return r.Client(ShellApp).Do(ctx, Request{
+		Method: http.MethodPut,
+		Path:   gamePresencePath(appID, gameID),
+		Token:  token,
+		Body:   map[string][]string{"players": players},
+	}, nil)
+}
+
+// ClearInGame tells the shell a game of appID has ended, so its players are
+// free to be challenged again.
+func (r *Registry) ClearInGame(ctx context.Context, appID, gameID string) error {
+	token, err := auth.MintServiceToken(appID, auth.ServiceTokenTTL)
+	if err != nil {
+		return err
+	}
+	return r.Client(ShellApp).Delete(ctx, gamePresencePath(appID, gameID), token, nil)
+}
+
+// DigestItem is one line of a user's email digest: an LMS pick due, a quiz
+// night coming up.
+type DigestItem struct {
+	Title  string    `json:"title"`            // e.g. "Pick for Round 3 of Office LMS"
+	Detail string    `json:"detail,omitempty"` // e.g. "You haven't picked yet"
+	At     time.Time `json:"at"`               // The deadline or start time
+	URL    string    `json:"url,omitempty"`    // Where to act on it, relative to the hub
+
+	// VenueID limits items for everyone to one venue's players; 0 is every venue
+	VenueID int `json:"venueId,omitempty"`
+}
+
+// DigestRequest asks an app what its players have coming up before Until
+type DigestRequest struct {
+	Emails []string  `json:"emails"`
+	Until  time.Time `json:"until"`
+}
+
+// Digest is an app's answer: items for particular players, and items for
+// everyone (or everyone at a venue).
+type Digest struct {
+	Items    map[string][]DigestItem `json:"items"`
+	Everyone []DigestItem            `json:"everyone"`
+}
+
+// FetchDigest asks an app for the digest items of a batch of players
+// (POST /api/digest). The shell makes the call with its own service token; it
+// only reads, so it's retried like a GET.
+func (r *Registry) FetchDigest(ctx context.Context, appID string, req DigestRequest) (*Digest, error) {
+	token, err := auth.MintServiceToken(ShellApp, auth.ServiceTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+	var digest Digest
+	err = r.Client(appID).Do(ctx, Request{
+		Method:     http.MethodPost,
+		Path:       "/api/digest",
+		Token:      token,
+		Body:       req,
+		Idempotent: true,
+	}, &digest)
+	if err != nil {
+		return nil, err
+	}
+	return &digest, nil
+}
+
+// Handicaps are the head starts players get in a match of a game type, from
+// the leaderboard's handicap rules and ratings
+type Handicaps struct {
+	GameType string         `json:"gameType"`
+	Kind     string         `json:"kind"`    // start_score (darts: points off the start score) or frames (pool: frames up)
+	Players  map[string]int `json:"players"` // Email to handicap; 0 plays off scratch
+}
+
+// FetchHandicaps asks the leaderboard for players' handicaps in a game type
+// (GET /api/handicaps/{gameType}/match). It returns nil for game types
+// without handicaps.
+func (r *Registry) FetchHandicaps(ctx context.Context, gameType string, players []string) (*Handicaps, error) {
+	path := "/api/handicaps/" + url.PathEscape(gameType) + "/match?players=" + url.QueryEscape(strings.Join(players, ","))
+	var handicaps Handicaps
+	err := r.Client(LeaderboardApp).Get(ctx, path, "", &handicaps)
+	if StatusCode(err) == http.StatusNotFound {
+		return nil, nil // A leaderboard without handicaps
+	}
+	if err != nil {
+		return nil, err
+	}
+	if handicaps.Kind == "" {
+		return nil, nil
+	}
+	return &handicaps, nil
+}
@@ -0,0 +1,211 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultTimeout bounds each attempt of a call
+	DefaultTimeout = 10 * time.Second
+
+	// DefaultRetries is how many times a failed call is repeated
+	DefaultRetries = 2
+)
+
+// retryBackoff is the wait before the first retry, doubling after each
+var retryBackoff = 200 * time.Millisecond
+
+// Client calls one app's backend. It's safe for concurrent use.
+type Client struct {
+	app      string
+	registry *Registry
+	http     *http.Client
+	retries  int
+}
+
+// Client returns a client for an app's backend with DefaultTimeout and
+// DefaultRetries.
+//
+// Usage:
+//
+//	registry := services.NewRegistry(identityDB)
+//	var game Game
+//	err := registry.Client("tic-tac-toe").Get(ctx, "/api/game/"+id, token, &game)
+func (r *Registry) Client(appID string) *Client {
+	return &Client{
+		app:      appID,
+		registry: r,
+		http:     &http.Client{Timeout: DefaultTimeout},
+		retries:  DefaultRetries,
+	}
+}
+
+// WithTimeout returns a copy of the client with a different per-attempt timeout
+func (c *Client) WithTimeout(timeout time.Duration) *Client {
+	c2 := *c
+	c2.http = &http.Client{Timeout: timeout}
+	return &c2
+}
+
+// WithRetries returns a copy of the client that retries failed calls n times
+func (c *Client) WithRetries(n int) *Client {
+	c2 := *c
+	c2.retries = n
+	return &c2
+}
+
+// Request is one call to a backend
+type Request struct {
+	Method string
+	Path   string      // e.g. "/api/game", with any query string
+	Token  string      // Sent as Authorization: Bearer; empty for public routes
+	Body   interface{} // Encoded as JSON when non-nil
+
+	// Idempotent marks a POST as safe to repeat, e.g. because the backend
+	// ignores duplicates. GET, PUT and DELETE always are.
+	Idempotent bool
+}
+
+// Error is a backend's non-2xx answer
+type Error struct {
+	App     string
+	Method  string
+	Path    string
+	Status  int
+	Message string // The backend's {"error": ...} or plain text body
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s %s %s returned %d: %s", e.App, e.Method, e.Path, e.Status, e.Message)
+}
+
+// StatusCode returns the HTTP status of an *Error in err's chain, or 0
+func StatusCode(err error) int {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Status
+	}
+	return 0
+}
+
+// Do makes the call and decodes a JSON response into out (when non-nil).
+//
+// Calls that can't connect are retried whatever the method, since the
+// backend never saw them. Idempotent calls are also retried after timeouts
+// and 502/503/504 answers. Other failures return at once: a *Error for a
+// non-2xx answer, otherwise the transport error.
+func (c *Client) Do(ctx context.Context, req Request, out interface{}) error {
+	baseURL, err := c.registry.BaseURL(c.app)
+	if err != nil {
+		return err
+	}
+
+	var body []byte
+	if req.Body != nil {
+		if body, err = json.Marshal(req.Body); err != nil {
+			return fmt.Errorf("failed to marshal %s request: %w", c.app, err)
+		}
+	}
+	idempotent := req.Idempotent || req.Method == http.MethodGet ||
+		req.Method == http.MethodPut || req.Method == http.MethodDelete
+
+	for attempt := 0; ; attempt++ {
+		retry, err := c.attempt(ctx, baseURL, req, body, idempotent, out)
+		if err == nil || !retry || attempt >= c.retries {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(retryBackoff << attempt):
+		}
+	}
+}
+
+// attempt makes one call, reporting whether a failure may be retried
+func (c *Client) attempt(ctx context.Context, baseURL string, req Request, body []byte, idempotent bool, out interface{}) (bool, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.Method, baseURL+req.Path, reader)
+	if err != nil {
+		return false, fmt.Errorf("failed to create %s request: %w", c.app, err)
+	}
+	if body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	if req.Token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+req.Token)
+	}
+
+	resp, err := c.http.Do(httpReq)
+	if err != nil {
+		retry := ctx.Err() == nil && (idempotent || dialFailed(err))
+		return retry, fmt.Errorf("failed to call %s: %w", c.app, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		retry := idempotent && (resp.StatusCode == http.StatusBadGateway ||
+			resp.StatusCode == http.StatusServiceUnavailable || resp.StatusCode == http.StatusGatewayTimeout)
+		return retry, &Error{
+			App:     c.app,
+			Method:  req.Method,
+			Path:    req.Path,
+			Status:  resp.StatusCode,
+			Message: errorMessage(resp.Body),
+		}
+	}
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return false, fmt.Errorf("failed to parse %s response: %w", c.app, err)
+		}
+	}
+	return false, nil
+}
+
+// dialFailed reports whether err happened before the request was sent
+func dialFailed(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// errorMessage reads an error body: ErrorJSON's {"error": ...} or http.Error's text
+func errorMessage(body io.Reader) string {
+	data, _ := io.ReadAll(io.LimitReader(body, 512))
+	var e struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(data, &e) == nil && e.Error != "" {
+		return e.Error
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// Get fetches path into out
+func (c *Client) Get(ctx context.Context, path, token string, out interface{}) error {
+	return c.Do(ctx, Request{Method: http.MethodGet, Path: path, Token: token}, out)
+}
+
+// Post sends body to path and decodes the answer into out. It is only
+// retried if the backend couldn't be reached; use Do with Idempotent for
+// routes that are safe to repeat.
+func (c *Client) Post(ctx context.Context, path, token string, body, out interface{}) error {
+	return c.Do(ctx, Request{Method: http.MethodPost, Path: path, Token: token, Body: body}, out)
+}
+
+// Delete deletes path, decoding any answer into out
+func (c *Client) Delete(ctx context.Context, path, token string, out interface{}) error {
+	return c.Do(ctx, Request{Method: http.MethodDelete, Path: path, Token: token}, out)
+}
@@ -0,0 +1,120 @@
+// Package services calls other Activity Hub backends: the identity shell
+// creating games on a game backend, a game reporting results to the
+// leaderboard. Backends are found through the app registry, calls get a
+// timeout, retries where they are safe, and the caller's token.
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/achgithub/activity-hub-common/config"
+)
+
+// RegistryRefreshInterval is how often backend ports are reloaded from the identity DB
+const RegistryRefreshInterval = 30 * time.Second
+
+// ErrUnknownApp is returned for apps that are not enabled in the registry
+// and have no URL override
+var ErrUnknownApp = errors.New("unknown app")
+
+// Registry resolves app IDs to backend base URLs. A {APP_ID}_URL environment
+// variable (LEADERBOARD_URL, TIC_TAC_TOE_URL) takes precedence; otherwise
+// the backend_port of the enabled app in the applications table is used,
+// on 127.0.0.1 since the backends all run on one machine.
+type Registry struct {
+	identityDB *sql.DB
+
+	mu       sync.RWMutex
+	ports    map[string]int
+	loadedAt time.Time
+}
+
+// NewRegistry creates a registry reading the app registry from the identity
+// DB. With a nil DB only the environment overrides are used.
+func NewRegistry(identityDB *sql.DB) *Registry {
+	return &Registry{identityDB: identityDB}
+}
+
+// envVar is the override for an app's URL: "tic-tac-toe" -> TIC_TAC_TOE_URL
+func envVar(appID string) string {
+	return strings.ToUpper(strings.ReplaceAll(appID, "-", "_")) + "_URL"
+}
+
+// BaseURL returns the backend URL for an app, without a trailing slash.
+func (r *Registry) BaseURL(appID string) (string, error) {
+	if url := config.GetEnv(envVar(appID), ""); url != "" {
+		return strings.TrimRight(url, "/"), nil
+	}
+	if appID == ShellApp {
+		return shellURL, nil
+	}
+	if r.identityDB == nil {
+		return "", fmt.Errorf("%w: %s", ErrUnknownApp, appID)
+	}
+
+	r.refresh()
+	r.mu.RLock()
+	port := r.ports[appID]
+	r.mu.RUnlock()
+	if port == 0 {
+		return "", fmt.Errorf("%w: %s", ErrUnknownApp, appID)
+	}
+	return fmt.Sprintf("http://127.0.0.1:%d", port), nil
+}
+
+// Reload reads the backend ports of the enabled apps from the identity DB.
+// Callers that edit the registry can call it to skip the refresh interval.
+func (r *Registry) Reload() error {
+	rows, err := r.identityDB.Query(`
+		SELECT id, COALESCE(backend_port, 0)
+		FROM applications
+		WHERE enabled = TRUE
+	`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	ports := map[string]int{}
+	for rows.Next() {
+		var id string
+		var port int
+		if err := rows.Scan(&id, &port); err != nil {
+			return err
+		}
+		if port > 0 {
+			ports[id] = port
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.ports = ports
+	r.loadedAt = time.Now()
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *Registry) refresh() {
+	r.mu.RLock()
+	stale := time.Since(r.loadedAt) > RegistryRefreshInterval
+	r.mu.RUnlock()
+	if !stale {
+		return
+	}
+	if err := r.Reload(); err != nil {
+		log.Printf("⚠️  App registry reload failed, keeping previous: %v", err)
+		// Back off until the next interval rather than hitting the DB on every call
+		r.mu.Lock()
+		r.loadedAt = time.Now()
+		r.mu.Unlock()
+	}
+}
@@ -0,0 +1,237 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/achgithub/activity-hub-common/auth"
+)
+
+func init() {
+	retryBackoff = time.Millisecond
+}
+
+func TestBaseURL(t *testing.T) {
+	t.Setenv("TIC_TAC_TOE_URL", "http://games.local:4001/")
+	registry := NewRegistry(nil)
+
+	url, err := registry.BaseURL("tic-tac-toe")
+	if err != nil || url != "http://games.local:4001" {
+		t.Errorf("BaseURL = %q, %v", url, err)
+	}
+	if _, err := registry.BaseURL("dots"); !errors.Is(err, ErrUnknownApp) {
+		t.Errorf("Expected ErrUnknownApp, got %v", err)
+	}
+	if url, err := registry.BaseURL(ShellApp); err != nil || url != "http://127.0.0.1:3001" {
+		t.Errorf("BaseURL(shell) = %q, %v", url, err)
+	}
+}
+
+// backend serves one handler as the "game" app
+func backend(t *testing.T, handler http.HandlerFunc) *Registry {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	t.Setenv("GAME_URL", server.URL)
+	return NewRegistry(nil)
+}
+
+func TestDoSendsTokenAndBody(t *testing.T) {
+	registry := backend(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tok" || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Unexpected headers %v", r.Header)
+		}
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		json.NewEncoder(w).Encode(map[string]string{"echo": body["name"]})
+	})
+
+	var out struct {
+		Echo string `json:"echo"`
+	}
+	err := registry.Client("game").Post(context.Background(), "/api/echo", "tok", map[string]string{"name": "Alice"}, &out)
+	if err != nil || out.Echo != "Alice" {
+		t.Errorf("Post = %+v, %v", out, err)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	registry := backend(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/json" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"bad move"}`))
+			return
+		}
+		http.Error(w, "Game not found", http.StatusNotFound)
+	})
+	client := registry.Client("game")
+
+	err := client.Get(context.Background(), "/api/json", "", nil)
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusBadRequest || apiErr.Message != "bad move" {
+		t.Errorf("Expected a 400 with the JSON message, got %v", err)
+	}
+	if err := client.Get(context.Background(), "/api/text", "", nil); StatusCode(err) != http.StatusNotFound {
+		t.Errorf("Expected a 404, got %v", err)
+	}
+}
+
+func TestRetries(t *testing.T) {
+	var calls int32
+	registry := backend(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{}`))
+	})
+	client := registry.Client("game")
+
+	if err := client.Get(context.Background(), "/api/state", "", nil); err != nil || calls != 3 {
+		t.Errorf("GET should succeed on the third attempt: %v after %d calls", err, calls)
+	}
+
+	// A POST the backend has seen isn't repeated
+	atomic.StoreInt32(&calls, 0)
+	if err := client.Post(context.Background(), "/api/game", "", map[string]int{}, nil); StatusCode(err) != http.StatusServiceUnavailable || calls != 1 {
+		t.Errorf("POST should fail after one call: %v after %d calls", err, calls)
+	}
+
+	// Unless it's marked idempotent
+	atomic.StoreInt32(&calls, 0)
+	err := client.Do(context.Background(), Request{Method: http.MethodPost, Path: "/api/result", Idempotent: true}, nil)
+	if err != nil || calls != 3 {
+		t.Errorf("Idempotent POST should be retried: %v after %d calls", err, calls)
+	}
+
+	atomic.StoreInt32(&calls, 0)
+	if err := client.WithRetries(0).Get(context.Background(), "/api/state", "", nil); err == nil || calls != 1 {
+		t.Errorf("WithRetries(0) should make one call: %v after %d calls", err, calls)
+	}
+}
+
+func TestRetriesUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	t.Setenv("GAME_URL", server.URL)
+
+	start := time.Now()
+	err := NewRegistry(nil).Client("game").Post(context.Background(), "/api/game", "", nil, nil)
+	if err == nil || StatusCode(err) != 0 {
+		t.Errorf("Expected a transport error, got %v", err)
+	}
+	if time.Since(start) > DefaultTimeout {
+		t.Error("Unreachable backend should fail fast")
+	}
+}
+
+func TestCreateGame(t *testing.T) {
+	registry := backend(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/game" {
+			t.Errorf("Unexpected call %s %s", r.Method, r.URL.Path)
+		}
+		w.Write([]byte(`{"success":true,"gameId":"g1"}`))
+	})
+
+	gameID, err := registry.CreateGame(context.Background(), "game", "tok", map[string]string{"challengeId": "c1"})
+	if err != nil || gameID != "g1" {
+		t.Errorf("CreateGame = %q, %v", gameID, err)
+	}
+}
+
+func TestInGame(t *testing.T) {
+	t.Setenv("AUTH_SIGNING_KEY", "test-key")
+	var calls []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		token := r.Header.Get("Authorization")[len("Bearer "):]
+		if app, err := auth.VerifyServiceToken(token); err != nil || app != "tic-tac-toe" {
+			t.Errorf("Service token = %q, %v", app, err)
+		}
+		if r.Method == http.MethodPut {
+			var body struct {
+				Players []string `json:"players"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			if len(body.Players) != 2 {
+				t.Errorf("Players = %v", body.Players)
+			}
+		}
+		w.Write([]byte(`{"success":true}`))
+	}))
+	defer server.Close()
+	t.Setenv("IDENTITY_SHELL_URL", server.URL)
+	registry := NewRegistry(nil)
+
+	if err := registry.SetInGame(context.Background(), "tic-tac-toe", "g 1", []string{"a@x.com", "b@x.com"}); err != nil {
+		t.Fatalf("SetInGame() error = %v", err)
+	}
+	if err := registry.ClearInGame(context.Background(), "tic-tac-toe", "g 1"); err != nil {
+		t.Fatalf("ClearInGame() error = %v", err)
+	}
+	want := []string{"PUT /api/presence/games/tic-tac-toe/g 1", "DELETE /api/presence/games/tic-tac-toe/g 1"}
+	if len(calls) != 2 || calls[0] != want[0] || calls[1] != want[1] {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+}
+
+func TestFetchDigest(t *testing.T) {
+	t.Setenv("AUTH_SIGNING_KEY", "test-key")
+	until := time.Date(2026, 3, 9, 8, 0, 0, 0, time.UTC)
+	registry := backend(t, func(w http.ResponseWriter, r *http.Request) {
+		token := r.Header.Get("Authorization")[len("Bearer "):]
+		if app, err := auth.VerifyServiceToken(token); err != nil || app != ShellApp {
+			t.Errorf("Service token = %q, %v", app, err)
+		}
+		var req DigestRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if len(req.Emails) != 1 || !req.Until.Equal(until) {
+			t.Errorf("Request = %+v", req)
+		}
+		w.Write([]byte(`{"items":{"a@x.com":[{"title":"Pick for Round 3","at":"2026-03-06T19:00:00Z"}]},"everyone":[]}`))
+	})
+
+	digest, err := registry.FetchDigest(context.Background(), "game", DigestRequest{Emails: []string{"a@x.com"}, Until: until})
+	if err != nil {
+		t.Fatalf("FetchDigest() error = %v", err)
+	}
+	if items := digest.Items["a@x.com"]; len(items) != 1 || items[0].Title != "Pick for Round 3" {
+		t.Errorf("Items = %+v", digest.Items)
+	}
+}
+
+func TestFetchHandicaps(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/handicaps/darts/match":
+			if got := r.URL.Query().Get("players"); got != "a@x.com,b@x.com" {
+				t.Errorf("players = %q", got)
+			}
+			w.Write([]byte(`{"gameType":"darts","kind":"start_score","players":{"a@x.com":0,"b@x.com":50}}`))
+		case "/api/handicaps/dots/match":
+			w.Write([]byte(`{"gameType":"dots","kind":"","players":{}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("LEADERBOARD_URL", server.URL)
+	registry := NewRegistry(nil)
+	ctx := context.Background()
+
+	h, err := registry.FetchHandicaps(ctx, "darts", []string{"a@x.com", "b@x.com"})
+	if err != nil || h == nil || h.Kind != "start_score" || h.Players["b@x.com"] != 50 {
+		t.Errorf("FetchHandicaps(darts) = %+v, %v", h, err)
+	}
+	for _, gameType := range []string{"dots", "pool"} {
+		if h, err := registry.FetchHandicaps(ctx, gameType, []string{"a@x.com"}); h != nil || err != nil {
+			t.Errorf("FetchHandicaps(%s) = %+v, %v, want nil, nil", gameType, h, err)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package sse
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	subscriptionBuffer = 64               // Messages queued per stream before it starts dropping
+	instanceTTL        = 30 * time.Second // A replica that stops heartbeating stops counting after this
+	heartbeatInterval  = 10 * time.Second
+)
+
+// Broker fans Redis pub/sub channels out to a backend's SSE streams over one
+// shared subscription, rather than a Redis connection per stream.
+//
+// Nothing about a stream is kept only in process: events reach streams by
+// being published to Redis, so every replica of a backend receives every
+// event and a client can be served by whichever replica it reaches, with no
+// sticky sessions. Open streams are counted in Redis too (Connections), so
+// the count covers every replica.
+type Broker struct {
+	client   *redis.Client
+	instance string
+	pubsub   *redis.PubSub
+
+	mu   sync.Mutex
+	subs map[string]map[*Subscription]bool // Channel → this process's streams on it
+}
+
+// Subscription is one SSE stream's feed from the broker
+type Subscription struct {
+	// C receives the payload of every message on the stream's channels
+	C <-chan string
+
+	ch       chan string
+	broker   *Broker
+	stream   string
+	channels []string
+	once     sync.Once
+}
+
+// NewBroker starts a broker on client. A backend needs one, shared by all
+// its streams.
+//
+// Usage:
+//
+//	redisClient, _ := redis.InitRedis()
+//	broker := sse.NewBroker(redisClient)
+//
+//	sub, err := broker.Subscribe(r.Context(), "quiz-player", "quiz:session:12:events")
+//	defer sub.Close()
+//	for msg := range sub.C { events.Forward(w, msg) }
+func NewBroker(client *redis.Client) *Broker {
+	host, _ := os.Hostname()
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+
+	b := &Broker{
+		client:   client,
+		instance: fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(suffix)),
+		pubsub:   client.Subscribe(context.Background()),
+		subs:     map[string]map[*Subscription]bool{},
+	}
+	go b.dispatch()
+	go b.heartbeat()
+	return b
+}
+
+// Subscribe opens a stream on channels. stream names what the stream is for
+// (e.g. "lobby"), for Connections. Close the subscription when the client
+// goes.
+func (b *Broker) Subscribe(ctx context.Context, stream string, channels ...string) (*Subscription, error) {
+	ch := make(chan string, subscriptionBuffer)
+	sub := &Subscription{C: ch, ch: ch, broker: b, stream: stream, channels: channels}
+
+	b.mu.Lock()
+	added := b.add(sub)
+	if len(added) > 0 {
+		// The first stream on a channel subscribes this process to it
+		if err := b.pubsub.Subscribe(ctx, added...); err != nil {
+			b.remove(sub)
+			b.mu.Unlock()
+			return nil, fmt.Errorf("failed to subscribe to %v: %w", added, err)
+		}
+	}
+	b.mu.Unlock()
+
+	if err := b.client.HIncrBy(ctx, connectionsKey(stream), b.instance, 1).Err(); err != nil {
+		log.Printf("⚠️  Failed to count %s stream: %v", stream, err)
+	}
+	return sub, nil
+}
+
+// Close stops the subscription and closes C. Safe to call more than once.
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		b := s.broker
+		b.mu.Lock()
+		if emptied := b.remove(s); len(emptied) > 0 {
+			// The last stream on a channel unsubscribes this process from it
+			if err := b.pubsub.Unsubscribe(context.Background(), emptied...); err != nil {
+				log.Printf("⚠️  Failed to unsubscribe from %v: %v", emptied, err)
+			}
+		}
+		close(s.ch)
+		b.mu.Unlock()
+
+		if err := b.client.HIncrBy(context.Background(), connectionsKey(s.stream), b.instance, -1).Err(); err != nil {
+			log.Printf("⚠️  Failed to count %s stream: %v", s.stream, err)
+		}
+	})
+}
+
+// Connections counts the open streams named stream across every replica.
+// Replicas that stopped without closing their streams drop out of the count
+// within instanceTTL.
+func (b *Broker) Connections(ctx context.Context, stream string) (int64, error) {
+	key := connectionsKey(stream)
+	counts, err := b.client.HGetAll(ctx, key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %s connections: %w", stream, err)
+	}
+
+	instances := make([]string, 0, len(counts))
+	pipe := b.client.Pipeline()
+	alive := make([]*redis.IntCmd, 0, len(counts))
+	for instance := range counts {
+		instances = append(instances, instance)
+		alive = append(alive, pipe.Exists(ctx, instanceKey(instance)))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return 0, fmt.Errorf("failed to check %s replicas: %w", stream, err)
+	}
+
+	var total int64
+	for i, instance := range instances {
+		if alive[i].Val() == 0 {
+			b.client.HDel(ctx, key, instance)
+			continue
+		}
+		n, _ := strconv.ParseInt(counts[instance], 10, 64)
+		total += n
+	}
+	return total, nil
+}
+
+// add registers a subscription and returns the channels it is the first on
+func (b *Broker) add(sub *Subscription) []string {
+	var added []string
+	for _, channel := range sub.channels {
+		if b.subs[channel] == nil {
+			b.subs[channel] = map[*Subscription]bool{}
+			added = append(added, channel)
+		}
+		b.subs[channel][sub] = true
+	}
+	return added
+}
+
+// remove drops a subscription and returns the channels nobody is left on
+func (b *Broker) remove(sub *Subscription) []string {
+	var emptied []string
+	for _, channel := range sub.channels {
+		delete(b.subs[channel], sub)
+		if len(b.subs[channel]) == 0 {
+			delete(b.subs, channel)
+			emptied = append(emptied, channel)
+		}
+	}
+	return emptied
+}
+
+// deliver hands a message to every stream on its channel. A stream too far
+// behind to take it (a stalled client) misses it rather than holding up the
+// rest; it returns how many did.
+func (b *Broker) deliver(channel, payload string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	dropped := 0
+	for sub := range b.subs[channel] {
+		select {
+		case sub.ch <- payload:
+		default:
+			dropped++
+		}
+	}
+	return dropped
+}
+
+// dispatch reads the shared subscription for the life of the process. The
+// subscription reconnects and resubscribes on its own after Redis blips.
+func (b *Broker) dispatch() {
+	for msg := range b.pubsub.Channel() {
+		if dropped := b.deliver(msg.Channel, msg.Payload); dropped > 0 {
+			log.Printf("⚠️  %d SSE streams on %s are full, dropped a message", dropped, msg.Channel)
+		}
+	}
+}
+
+// heartbeat marks this replica alive so its streams count in Connections
+func (b *Broker) heartbeat() {
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+	for {
+		if err := b.client.Set(context.Background(), instanceKey(b.instance), 1, instanceTTL).Err(); err != nil {
+			log.Printf("⚠️  SSE broker heartbeat failed: %v", err)
+		}
+		<-ticker.C
+	}
+}
+
+func connectionsKey(stream string) string {
+	return "sse:connections:" + stream
+}
+
+func instanceKey(instance string) string {
+	return "sse:instance:" + instance
+}
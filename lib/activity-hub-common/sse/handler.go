@@ -68,7 +68,6 @@ func HandleStream(w http.ResponseWriter, r *http.Request, config StreamConfig) e
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
 	w.Header().Set("Connection", "keep-alive")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
 
 	// Ensure response is flushed
 	flusher, ok := w.(http.Flusher)
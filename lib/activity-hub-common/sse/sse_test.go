@@ -1,6 +1,8 @@
 package sse
 
 import (
+	"reflect"
+	"sort"
 	"testing"
 )
 
@@ -22,7 +24,71 @@ func TestFormatSSE(t *testing.T) {
 	}
 }
 
+func newTestSubscription(b *Broker, channels ...string) *Subscription {
+	ch := make(chan string, 2)
+	return &Subscription{C: ch, ch: ch, broker: b, stream: "test", channels: channels}
+}
+
+func TestBrokerSubscribesOncePerChannel(t *testing.T) {
+	b := &Broker{subs: map[string]map[*Subscription]bool{}}
+
+	alice := newTestSubscription(b, "user:alice", "presence:updates")
+	bob := newTestSubscription(b, "user:bob", "presence:updates")
+
+	if added := b.add(alice); !reflect.DeepEqual(added, []string{"user:alice", "presence:updates"}) {
+		t.Errorf("add(alice) = %v", added)
+	}
+	// presence:updates is already subscribed for alice
+	if added := b.add(bob); !reflect.DeepEqual(added, []string{"user:bob"}) {
+		t.Errorf("add(bob) = %v, want only user:bob", added)
+	}
+
+	if emptied := b.remove(alice); !reflect.DeepEqual(emptied, []string{"user:alice"}) {
+		t.Errorf("remove(alice) = %v, want only user:alice", emptied)
+	}
+	emptied := b.remove(bob)
+	sort.Strings(emptied)
+	if !reflect.DeepEqual(emptied, []string{"presence:updates", "user:bob"}) {
+		t.Errorf("remove(bob) = %v", emptied)
+	}
+	if len(b.subs) != 0 {
+		t.Errorf("Expected no channels left, got %v", b.subs)
+	}
+}
+
+func TestBrokerDeliver(t *testing.T) {
+	b := &Broker{subs: map[string]map[*Subscription]bool{}}
+	alice := newTestSubscription(b, "user:alice", "presence:updates")
+	bob := newTestSubscription(b, "presence:updates")
+	b.add(alice)
+	b.add(bob)
+
+	if dropped := b.deliver("presence:updates", "p1"); dropped != 0 {
+		t.Errorf("deliver dropped %d", dropped)
+	}
+	b.deliver("user:alice", "a1")
+	b.deliver("user:carol", "nobody")
+
+	if got := <-alice.C; got != "p1" {
+		t.Errorf("alice got %q, want p1", got)
+	}
+	if got := <-alice.C; got != "a1" {
+		t.Errorf("alice got %q, want a1", got)
+	}
+	if got := <-bob.C; got != "p1" {
+		t.Errorf("bob got %q, want p1", got)
+	}
+
+	// A full stream misses messages rather than blocking the others
+	b.deliver("presence:updates", "p2")
+	b.deliver("presence:updates", "p3")
+	if dropped := b.deliver("presence:updates", "p4"); dropped != 2 {
+		t.Errorf("deliver to full streams dropped %d, want 2", dropped)
+	}
+}
+
 // Integration tests (require Redis)
 // Run with: go test -tags=integration ./...
 
 // TODO: Add integration tests for HandleStream
+// TODO: Add integration tests for Broker.Connections across instances
@@ -0,0 +1,338 @@
+// Package turns is the engine for turn-based games: seat order, whose turn it
+// is, persistence of the game between moves, forfeits, claimed wins and the
+// lifecycle hooks a backend broadcasts from. A game supplies its state type
+// (implementing Game) and, for each move, the rules; the engine does the rest.
+//
+// Usage:
+//
+//	engine := turns.New[*Game](redisStore{redisClient}, "game:")
+//	engine.Hooks.OnMove = func(ctx context.Context, g *Game, m turns.Move) {
+//	    PublishGameEvent(evMoveUpdate.New(g.ID, GameUpdate{Game: g, Message: m.Outcome.Message}))
+//	}
+//
+//	game, _, err := engine.Play(ctx, gameID, user.Email, func(g *Game, seat int) (turns.Outcome, error) {
+//	    if g.Board[pos] != "" {
+//	        return turns.Outcome{}, &turns.Error{Code: 400, Message: "Cell already occupied"}
+//	    }
+//	    g.Board[pos] = symbols[seat]
+//	    return checkResult(g), nil
+//	})
+package turns
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+// Game is what the engine needs from a game's state. Implement it on the
+// pointer type that is stored (e.g. *Game), so Play's rules can change it.
+type Game interface {
+	GameID() string
+	Seats() []string // Player IDs in turn order
+	Turn() int       // Seat whose turn it is
+	SetTurn(seat int)
+	Over() bool
+	Finish(winnerID string, at time.Time) // winnerID is "" for a draw or an abandoned game
+	Moved(at time.Time)                   // Called after every move
+}
+
+// Timed is implemented by games with a per-move time limit
+type Timed interface {
+	// MoveDeadline is when the player whose turn it is runs out of time;
+	// zero if there's no limit.
+	MoveDeadline() time.Time
+}
+
+// ErrNotFound is returned by Store.Get when the key doesn't exist
+var ErrNotFound = errors.New("not found")
+
+// Store is the part of a Redis client the engine uses. As with the cache
+// package, backends wrap whichever client they already use.
+type Store interface {
+	Get(ctx context.Context, key string) (string, error)
+	Set(ctx context.Context, key, value string, ttl time.Duration) error
+}
+
+// Error is a move the engine or a game's rules refused. Code is the HTTP status to answer with.
+type Error struct {
+	Code    int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+var (
+	ErrGameNotFound = &Error{Code: 404, Message: "Game not found"}
+	ErrGameOver     = &Error{Code: 400, Message: "Game is not active"}
+	ErrNotAPlayer   = &Error{Code: 403, Message: "Not a player in this game"}
+	ErrNotYourTurn  = &Error{Code: 400, Message: "Not your turn"}
+	ErrNoTimeout    = &Error{Code: 400, Message: "Opponent still has time to move"}
+)
+
+// Ways a game can end
+const (
+	ReasonComplete   = "game_complete" // The rules ended it
+	ReasonForfeit    = "forfeit"
+	ReasonDisconnect = "disconnect" // A player left and the other claimed the win
+	ReasonTimeout    = "timeout"    // A player ran out of time and the other claimed the win
+)
+
+// Outcome is what the rules decided about a move
+type Outcome struct {
+	Over     bool   // The game has finished
+	WinnerID string // If Over; "" for a draw
+	HoldTurn bool   // Don't pass the turn on: the player moves again, or the rules set the turn themselves
+	Message  string // Shown to the players, e.g. "Round won! Next round starting..."
+}
+
+// Move describes a move that was played, for OnMove
+type Move struct {
+	PlayerID string
+	Seat     int
+	Outcome  Outcome
+}
+
+// End describes how a game finished, for OnEnd
+type End struct {
+	Reason   string // One of the Reason constants
+	PlayerID string // Who made it end: the last mover, the forfeiter or the claimant
+	WinnerID string // "" for a draw
+	Message  string // The rules' message when Reason is ReasonComplete
+}
+
+// Hooks are called after the game has been saved. They run synchronously, in
+// the request that caused them, so keep slow work in goroutines.
+type Hooks[G Game] struct {
+	OnStart func(ctx context.Context, g G)
+	OnMove  func(ctx context.Context, g G, m Move)
+	OnEnd   func(ctx context.Context, g G, e End)
+}
+
+// Engine runs one kind of turn-based game
+type Engine[G Game] struct {
+	store  Store
+	prefix string
+
+	ActiveTTL   time.Duration // How long a game in play is kept between moves
+	FinishedTTL time.Duration // How long a finished game is kept, for late readers
+	Hooks       Hooks[G]
+
+	locks [64]sync.Mutex
+	now   func() time.Time
+}
+
+// New creates an engine storing games under prefix (e.g. "game:")
+func New[G Game](store Store, prefix string) *Engine[G] {
+	return &Engine[G]{
+		store:       store,
+		prefix:      prefix,
+		ActiveTTL:   time.Hour,
+		FinishedTTL: 5 * time.Minute,
+		now:         time.Now,
+	}
+}
+
+// lock serialises changes to one game. Games share a fixed set of locks, so
+// there is nothing to clean up when a game ends.
+func (e *Engine[G]) lock(id string) func() {
+	h := fnv.New32a()
+	h.Write([]byte(id))
+	mu := &e.locks[h.Sum32()%uint32(len(e.locks))]
+	mu.Lock()
+	return mu.Unlock
+}
+
+// Create stores a new game and calls OnStart
+func (e *Engine[G]) Create(ctx context.Context, g G) error {
+	if len(g.Seats()) < 2 {
+		return fmt.Errorf("a game needs at least 2 players")
+	}
+	if err := e.Save(ctx, g); err != nil {
+		return err
+	}
+	if e.Hooks.OnStart != nil {
+		e.Hooks.OnStart(ctx, g)
+	}
+	return nil
+}
+
+// Load returns a stored game, or ErrGameNotFound
+func (e *Engine[G]) Load(ctx context.Context, id string) (G, error) {
+	var g G
+	data, err := e.store.Get(ctx, e.prefix+id)
+	if err == ErrNotFound {
+		return g, ErrGameNotFound
+	}
+	if err != nil {
+		return g, fmt.Errorf("failed to load game: %w", err)
+	}
+	if err := json.Unmarshal([]byte(data), &g); err != nil {
+		return g, fmt.Errorf("failed to unmarshal game: %w", err)
+	}
+	return g, nil
+}
+
+// Save stores a game, keeping it for ActiveTTL while in play and FinishedTTL once over
+func (e *Engine[G]) Save(ctx context.Context, g G) error {
+	data, err := json.Marshal(g)
+	if err != nil {
+		return fmt.Errorf("failed to marshal game: %w", err)
+	}
+	ttl := e.ActiveTTL
+	if g.Over() {
+		ttl = e.FinishedTTL
+	}
+	if err := e.store.Set(ctx, e.prefix+g.GameID(), string(data), ttl); err != nil {
+		return fmt.Errorf("failed to save game: %w", err)
+	}
+	return nil
+}
+
+// Play makes a move for playerID. It checks the game is in play and it's the
+// player's turn, then calls rules with the game and the player's seat. If the
+// rules return an error the game is left as it was. Otherwise the turn passes
+// to the next seat (unless Outcome.HoldTurn), the game is finished if
+// Outcome.Over, and it's saved before OnMove and OnEnd are called.
+func (e *Engine[G]) Play(ctx context.Context, id, playerID string, rules func(g G, seat int) (Outcome, error)) (G, Outcome, error) {
+	defer e.lock(id)()
+
+	var out Outcome
+	g, err := e.Load(ctx, id)
+	if err != nil {
+		return g, out, err
+	}
+	if g.Over() {
+		return g, out, ErrGameOver
+	}
+	seat := SeatOf(g, playerID)
+	if seat < 0 {
+		return g, out, ErrNotAPlayer
+	}
+	if seat != g.Turn() {
+		return g, out, ErrNotYourTurn
+	}
+
+	out, err = rules(g, seat)
+	if err != nil {
+		return g, out, err
+	}
+
+	now := e.now()
+	g.Moved(now)
+	if out.Over {
+		g.Finish(out.WinnerID, now)
+	} else if !out.HoldTurn {
+		g.SetTurn((seat + 1) % len(g.Seats()))
+	}
+	if err := e.Save(ctx, g); err != nil {
+		return g, out, err
+	}
+
+	if e.Hooks.OnMove != nil {
+		e.Hooks.OnMove(ctx, g, Move{PlayerID: playerID, Seat: seat, Outcome: out})
+	}
+	if out.Over && e.Hooks.OnEnd != nil {
+		e.Hooks.OnEnd(ctx, g, End{Reason: ReasonComplete, PlayerID: playerID, WinnerID: out.WinnerID, Message: out.Message})
+	}
+	return g, out, nil
+}
+
+// Forfeit ends the game because playerID gave up. In a two-player game the
+// other player wins; otherwise nobody does.
+func (e *Engine[G]) Forfeit(ctx context.Context, id, playerID string) (G, error) {
+	return e.end(ctx, id, playerID, ReasonForfeit, func(g G, seat int) (string, error) {
+		seats := g.Seats()
+		if len(seats) == 2 {
+			return seats[1-seat], nil
+		}
+		return "", nil
+	})
+}
+
+// ClaimWin ends the game in the claimant's favour, for a reason the caller has
+// checked, e.g. ReasonDisconnect once the opponent's grace period has run out
+// (see Presence).
+func (e *Engine[G]) ClaimWin(ctx context.Context, id, claimantID, reason string) (G, error) {
+	return e.end(ctx, id, claimantID, reason, func(g G, seat int) (string, error) {
+		return claimantID, nil
+	})
+}
+
+// ClaimTimeout ends the game in the claimant's favour if the player whose
+// turn it is has run out of time. Games that aren't Timed, or are played
+// without a limit, answer ErrNoTimeout.
+func (e *Engine[G]) ClaimTimeout(ctx context.Context, id, claimantID string) (G, error) {
+	return e.end(ctx, id, claimantID, ReasonTimeout, func(g G, seat int) (string, error) {
+		if seat == g.Turn() {
+			return "", &Error{Code: 400, Message: "You can't claim a timeout on your own turn"}
+		}
+		timed, ok := any(g).(Timed)
+		if !ok {
+			return "", ErrNoTimeout
+		}
+		deadline := timed.MoveDeadline()
+		if deadline.IsZero() || e.now().Before(deadline) {
+			return "", ErrNoTimeout
+		}
+		return claimantID, nil
+	})
+}
+
+// end finishes a game outside of a move. winner picks the winner from the
+// game and the seat of playerID, or refuses.
+func (e *Engine[G]) end(ctx context.Context, id, playerID, reason string, winner func(g G, seat int) (string, error)) (G, error) {
+	defer e.lock(id)()
+
+	g, err := e.Load(ctx, id)
+	if err != nil {
+		return g, err
+	}
+	if g.Over() {
+		return g, &Error{Code: 400, Message: "Game already ended"}
+	}
+	seat := SeatOf(g, playerID)
+	if seat < 0 {
+		return g, ErrNotAPlayer
+	}
+	winnerID, err := winner(g, seat)
+	if err != nil {
+		return g, err
+	}
+
+	g.Finish(winnerID, e.now())
+	if err := e.Save(ctx, g); err != nil {
+		return g, err
+	}
+	if e.Hooks.OnEnd != nil {
+		e.Hooks.OnEnd(ctx, g, End{Reason: reason, PlayerID: playerID, WinnerID: winnerID})
+	}
+	return g, nil
+}
+
+// SeatOf returns playerID's seat in g, or -1 if they aren't playing
+func SeatOf(g Game, playerID string) int {
+	for i, id := range g.Seats() {
+		if id == playerID {
+			return i
+		}
+	}
+	return -1
+}
+
+// Opponents returns everyone in g but playerID, in seat order
+func Opponents(g Game, playerID string) []string {
+	others := []string{}
+	for _, id := range g.Seats() {
+		if id != playerID {
+			others = append(others, id)
+		}
+	}
+	return others
+}
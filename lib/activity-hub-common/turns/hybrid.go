@@ -0,0 +1,97 @@
+package turns
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// HybridStore keeps games in memory in front of a Redis Store. Reads of a game
+// this process has seen are served from memory; writes go to memory and to
+// Redis, so a restarted backend picks its games up from Redis where it left
+// off. Only use it where one backend process runs each game, as on the Pi: a
+// second process would read its own stale copy.
+type HybridStore struct {
+	store Store
+
+	mu      sync.Mutex
+	entries map[string]hybridEntry
+	writes  int
+	now     func() time.Time
+}
+
+type hybridEntry struct {
+	value   string
+	expires time.Time // Zero for no expiry
+}
+
+// sweepEvery is how many writes pass between sweeps of expired games
+const sweepEvery = 256
+
+// reloadTTL is how long a game read from Redis is kept in memory. Its Redis
+// TTL isn't known, so it's re-read now and then in case it has expired there.
+const reloadTTL = time.Minute
+
+// NewHybridStore puts an in-memory layer in front of store
+func NewHybridStore(store Store) *HybridStore {
+	return &HybridStore{store: store, entries: map[string]hybridEntry{}, now: time.Now}
+}
+
+// Get returns the game from memory, or loads it from Redis (e.g. after a
+// restart) and keeps it for reloadTTL.
+func (h *HybridStore) Get(ctx context.Context, key string) (string, error) {
+	now := h.now()
+	h.mu.Lock()
+	e, ok := h.entries[key]
+	h.mu.Unlock()
+	if ok && (e.expires.IsZero() || now.Before(e.expires)) {
+		return e.value, nil
+	}
+
+	value, err := h.store.Get(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	h.remember(key, value, reloadTTL)
+	return value, nil
+}
+
+// Set writes through to Redis before updating memory, so memory never holds
+// a game Redis doesn't.
+func (h *HybridStore) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	if err := h.store.Set(ctx, key, value, ttl); err != nil {
+		h.mu.Lock()
+		delete(h.entries, key)
+		h.mu.Unlock()
+		return err
+	}
+	h.remember(key, value, ttl)
+	return nil
+}
+
+func (h *HybridStore) remember(key, value string, ttl time.Duration) {
+	now := h.now()
+	e := hybridEntry{value: value}
+	if ttl > 0 {
+		e.expires = now.Add(ttl)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.entries[key] = e
+	h.writes++
+	if h.writes%sweepEvery == 0 {
+		for k, e := range h.entries {
+			if !e.expires.IsZero() && !now.Before(e.expires) {
+				delete(h.entries, k)
+			}
+		}
+	}
+}
+
+// Len is the number of games held in memory, expired or not
+func (h *HybridStore) Len() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.entries)
+}
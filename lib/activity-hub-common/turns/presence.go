@@ -0,0 +1,135 @@
+package turns
+
+import (
+	"sync"
+	"time"
+)
+
+// Presence tracks which players have a game's stream open, so a player whose
+// opponent has left can claim the win. When a player's last stream closes
+// they get a grace period to come back; if they don't, Gone reports true and
+// onGone is called (typically to tell the opponent they may claim the win).
+//
+// Presence is held in memory, so it assumes one backend instance per game.
+type Presence struct {
+	grace  time.Duration
+	onGone func(gameID, playerID string)
+
+	mu          sync.Mutex
+	connections map[string]map[string]int // gameID -> playerID -> open streams
+	timers      map[string]*time.Timer    // gameID:playerID -> grace timer
+	gone        map[string]bool           // gameID:playerID -> grace period ran out
+}
+
+// NewPresence creates a tracker with the given grace period. onGone may be nil.
+func NewPresence(grace time.Duration, onGone func(gameID, playerID string)) *Presence {
+	return &Presence{
+		grace:       grace,
+		onGone:      onGone,
+		connections: map[string]map[string]int{},
+		timers:      map[string]*time.Timer{},
+		gone:        map[string]bool{},
+	}
+}
+
+// Grace is how long a player has to come back before they count as gone
+func (p *Presence) Grace() time.Duration {
+	return p.grace
+}
+
+// Connect records a stream opening. It reports whether the player was coming
+// back within their grace period (or after it ran out), i.e. whether their
+// opponent should be told they reconnected.
+func (p *Presence) Connect(gameID, playerID string) (reconnected bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := gameID + ":" + playerID
+	if timer, ok := p.timers[key]; ok {
+		timer.Stop()
+		delete(p.timers, key)
+		reconnected = true
+	}
+	if p.gone[key] {
+		delete(p.gone, key)
+		reconnected = true
+	}
+
+	if p.connections[gameID] == nil {
+		p.connections[gameID] = map[string]int{}
+	}
+	p.connections[gameID][playerID]++
+	return reconnected
+}
+
+// Disconnect records a stream closing. When the player's last stream for the
+// game closes their grace period starts.
+func (p *Presence) Disconnect(gameID, playerID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if players := p.connections[gameID]; players != nil {
+		players[playerID]--
+		if players[playerID] > 0 {
+			return
+		}
+		delete(players, playerID)
+		if len(players) == 0 {
+			delete(p.connections, gameID)
+		}
+	}
+
+	key := gameID + ":" + playerID
+	if timer, ok := p.timers[key]; ok {
+		timer.Stop()
+	}
+	p.timers[key] = time.AfterFunc(p.grace, func() {
+		p.mu.Lock()
+		if _, pending := p.timers[key]; !pending {
+			// Reconnected or forgotten in the meantime
+			p.mu.Unlock()
+			return
+		}
+		delete(p.timers, key)
+		p.gone[key] = true
+		p.mu.Unlock()
+
+		if p.onGone != nil {
+			p.onGone(gameID, playerID)
+		}
+	})
+}
+
+// Connected reports whether the player has a stream open
+func (p *Presence) Connected(gameID, playerID string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.connections[gameID][playerID] > 0
+}
+
+// Gone reports whether the player left and their grace period ran out
+func (p *Presence) Gone(gameID, playerID string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.gone[gameID+":"+playerID]
+}
+
+// Forget drops everything held for a game, e.g. once it has ended
+func (p *Presence) Forget(gameID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	prefix := gameID + ":"
+	for key, timer := range p.timers {
+		if len(key) > len(prefix) && key[:len(prefix)] == prefix {
+			timer.Stop()
+			delete(p.timers, key)
+		}
+	}
+	for key := range p.gone {
+		if len(key) > len(prefix) && key[:len(prefix)] == prefix {
+			delete(p.gone, key)
+		}
+	}
+	delete(p.connections, gameID)
+}
@@ -0,0 +1,476 @@
+package turns
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memStore is an in-memory Store
+type memStore struct {
+	mu   sync.Mutex
+	data map[string]string
+	ttls map[string]time.Duration
+}
+
+func newMemStore() *memStore {
+	return &memStore{data: map[string]string{}, ttls: map[string]time.Duration{}}
+}
+
+func (m *memStore) Get(ctx context.Context, key string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	v, ok := m.data[key]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return v, nil
+}
+
+func (m *memStore) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data[key] = value
+	m.ttls[key] = ttl
+	return nil
+}
+
+// countGame: players take turns adding to a count; whoever reaches Target wins
+type countGame struct {
+	ID        string   `json:"id"`
+	Players   []string `json:"players"`
+	Current   int      `json:"current"`
+	Count     int      `json:"count"`
+	Target    int      `json:"target"`
+	Done      bool     `json:"done"`
+	Winner    string   `json:"winner"`
+	LastMove  int64    `json:"lastMove"`
+	TimeLimit int64    `json:"timeLimit"` // Seconds, 0 = none
+}
+
+func (g *countGame) GameID() string     { return g.ID }
+func (g *countGame) Seats() []string    { return g.Players }
+func (g *countGame) Turn() int          { return g.Current }
+func (g *countGame) SetTurn(seat int)   { g.Current = seat }
+func (g *countGame) Over() bool         { return g.Done }
+func (g *countGame) Moved(at time.Time) { g.LastMove = at.Unix() }
+func (g *countGame) Finish(winnerID string, at time.Time) {
+	g.Done = true
+	g.Winner = winnerID
+}
+func (g *countGame) MoveDeadline() time.Time {
+	if g.TimeLimit == 0 {
+		return time.Time{}
+	}
+	return time.Unix(g.LastMove+g.TimeLimit, 0)
+}
+
+// add is the rules: add n (1-3); a 3 earns another turn
+func add(n int) func(g *countGame, seat int) (Outcome, error) {
+	return func(g *countGame, seat int) (Outcome, error) {
+		if n < 1 || n > 3 {
+			return Outcome{}, &Error{Code: 400, Message: "Add 1 to 3"}
+		}
+		g.Count += n
+		if g.Count >= g.Target {
+			return Outcome{Over: true, WinnerID: g.Players[seat], Message: "Target reached"}, nil
+		}
+		return Outcome{HoldTurn: n == 3}, nil
+	}
+}
+
+func newEngine(t *testing.T, store *memStore, start time.Time) *Engine[*countGame] {
+	t.Helper()
+	e := New[*countGame](store, "count:")
+	e.now = func() time.Time { return start }
+	err := e.Create(context.Background(), &countGame{ID: "g1", Players: []string{"a", "b"}, Target: 10, LastMove: start.Unix()})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	return e
+}
+
+func TestPlayPassesTurn(t *testing.T) {
+	ctx := context.Background()
+	e := newEngine(t, newMemStore(), time.Now())
+
+	g, _, err := e.Play(ctx, "g1", "a", add(1))
+	if err != nil {
+		t.Fatalf("Play() error = %v", err)
+	}
+	if g.Count != 1 || g.Turn() != 1 {
+		t.Errorf("count = %d, turn = %d; want 1, 1", g.Count, g.Turn())
+	}
+
+	if _, _, err := e.Play(ctx, "g1", "a", add(1)); err != ErrNotYourTurn {
+		t.Errorf("out-of-turn Play() error = %v, want ErrNotYourTurn", err)
+	}
+	if _, _, err := e.Play(ctx, "g1", "c", add(1)); err != ErrNotAPlayer {
+		t.Errorf("stranger Play() error = %v, want ErrNotAPlayer", err)
+	}
+	if _, _, err := e.Play(ctx, "missing", "a", add(1)); err != ErrGameNotFound {
+		t.Errorf("missing game Play() error = %v, want ErrGameNotFound", err)
+	}
+}
+
+func TestPlayRulesErrorLeavesGame(t *testing.T) {
+	ctx := context.Background()
+	e := newEngine(t, newMemStore(), time.Now())
+
+	if _, _, err := e.Play(ctx, "g1", "a", add(5)); err == nil || err.(*Error).Code != 400 {
+		t.Fatalf("Play() error = %v, want the rules' error", err)
+	}
+	g, _ := e.Load(ctx, "g1")
+	if g.Count != 0 || g.Turn() != 0 {
+		t.Errorf("game changed after refused move: %+v", g)
+	}
+}
+
+func TestPlayHoldTurnAndEnd(t *testing.T) {
+	ctx := context.Background()
+	store := newMemStore()
+	e := newEngine(t, store, time.Now())
+
+	var moves []Move
+	var ends []End
+	e.Hooks.OnMove = func(ctx context.Context, g *countGame, m Move) { moves = append(moves, m) }
+	e.Hooks.OnEnd = func(ctx context.Context, g *countGame, end End) { ends = append(ends, end) }
+
+	// a: 3 (again), 3 (again), 2 -> 8; b: 2 -> 10 wins
+	for _, step := range []struct {
+		player string
+		n      int
+	}{{"a", 3}, {"a", 3}, {"a", 2}, {"b", 2}} {
+		if _, _, err := e.Play(ctx, "g1", step.player, add(step.n)); err != nil {
+			t.Fatalf("Play(%s, %d) error = %v", step.player, step.n, err)
+		}
+	}
+
+	g, _ := e.Load(ctx, "g1")
+	if !g.Over() || g.Winner != "b" {
+		t.Errorf("game = %+v, want won by b", g)
+	}
+	if len(moves) != 4 || len(ends) != 1 {
+		t.Fatalf("hooks: %d moves, %d ends; want 4, 1", len(moves), len(ends))
+	}
+	if ends[0].Reason != ReasonComplete || ends[0].WinnerID != "b" || ends[0].Message != "Target reached" {
+		t.Errorf("end = %+v", ends[0])
+	}
+	if store.ttls["count:g1"] != e.FinishedTTL {
+		t.Errorf("finished TTL = %v, want %v", store.ttls["count:g1"], e.FinishedTTL)
+	}
+
+	if _, _, err := e.Play(ctx, "g1", "a", add(1)); err != ErrGameOver {
+		t.Errorf("Play() after end error = %v, want ErrGameOver", err)
+	}
+}
+
+func TestForfeit(t *testing.T) {
+	ctx := context.Background()
+	e := newEngine(t, newMemStore(), time.Now())
+
+	var end End
+	e.Hooks.OnEnd = func(ctx context.Context, g *countGame, e End) { end = e }
+
+	g, err := e.Forfeit(ctx, "g1", "a")
+	if err != nil {
+		t.Fatalf("Forfeit() error = %v", err)
+	}
+	if !g.Over() || g.Winner != "b" {
+		t.Errorf("game = %+v, want won by b", g)
+	}
+	if end.Reason != ReasonForfeit || end.PlayerID != "a" || end.WinnerID != "b" {
+		t.Errorf("end = %+v", end)
+	}
+	if _, err := e.Forfeit(ctx, "g1", "b"); err == nil {
+		t.Error("second Forfeit() expected error")
+	}
+}
+
+func TestClaimTimeout(t *testing.T) {
+	ctx := context.Background()
+	start := time.Unix(1_700_000_000, 0)
+	e := newEngine(t, newMemStore(), start)
+
+	// No limit set
+	if _, err := e.ClaimTimeout(ctx, "g1", "b"); err != ErrNoTimeout {
+		t.Fatalf("ClaimTimeout() without limit error = %v, want ErrNoTimeout", err)
+	}
+
+	g, _ := e.Load(ctx, "g1")
+	g.TimeLimit = 30
+	e.Save(ctx, g)
+
+	if _, err := e.ClaimTimeout(ctx, "g1", "a"); err == nil {
+		t.Error("ClaimTimeout() on own turn expected error")
+	}
+	e.now = func() time.Time { return start.Add(10 * time.Second) }
+	if _, err := e.ClaimTimeout(ctx, "g1", "b"); err != ErrNoTimeout {
+		t.Errorf("early ClaimTimeout() error = %v, want ErrNoTimeout", err)
+	}
+	e.now = func() time.Time { return start.Add(31 * time.Second) }
+	g, err := e.ClaimTimeout(ctx, "g1", "b")
+	if err != nil {
+		t.Fatalf("ClaimTimeout() error = %v", err)
+	}
+	if g.Winner != "b" {
+		t.Errorf("winner = %q, want b", g.Winner)
+	}
+}
+
+func TestPresence(t *testing.T) {
+	gone := make(chan string, 1)
+	p := NewPresence(20*time.Millisecond, func(gameID, playerID string) { gone <- playerID })
+
+	if p.Connect("g1", "a") {
+		t.Error("first Connect() reported a reconnect")
+	}
+	p.Connect("g1", "a") // second tab
+	p.Disconnect("g1", "a")
+	if !p.Connected("g1", "a") {
+		t.Error("player with a stream still open reported disconnected")
+	}
+
+	// Back within the grace period
+	p.Disconnect("g1", "a")
+	if !p.Connect("g1", "a") {
+		t.Error("Connect() within grace period should report a reconnect")
+	}
+
+	// Gone after the grace period
+	p.Disconnect("g1", "a")
+	select {
+	case id := <-gone:
+		if id != "a" {
+			t.Errorf("onGone(%q), want a", id)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("onGone not called")
+	}
+	if !p.Gone("g1", "a") || p.Connected("g1", "a") {
+		t.Error("player should be gone")
+	}
+
+	p.Forget("g1")
+	if p.Gone("g1", "a") {
+		t.Error("Forget() should clear gone players")
+	}
+}
+
+func TestHybridStore(t *testing.T) {
+	ctx := context.Background()
+	redis := newMemStore()
+	start := time.Now()
+	h := NewHybridStore(redis)
+	h.now = func() time.Time { return start }
+
+	if err := h.Set(ctx, "game:1", "a", time.Hour); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if redis.data["game:1"] != "a" || redis.ttls["game:1"] != time.Hour {
+		t.Errorf("Set() didn't write through: %q, %v", redis.data["game:1"], redis.ttls["game:1"])
+	}
+
+	// Served from memory
+	redis.data["game:1"] = "changed elsewhere"
+	if v, _ := h.Get(ctx, "game:1"); v != "a" {
+		t.Errorf("Get() = %q, want the in-memory a", v)
+	}
+
+	// After a restart games come back from Redis
+	restarted := NewHybridStore(redis)
+	if v, err := restarted.Get(ctx, "game:1"); err != nil || v != "changed elsewhere" {
+		t.Errorf("Get() after restart = %q, %v", v, err)
+	}
+	if _, err := restarted.Get(ctx, "game:2"); err != ErrNotFound {
+		t.Errorf("Get() missing error = %v, want ErrNotFound", err)
+	}
+
+	// Expired in memory: re-read
+	h.now = func() time.Time { return start.Add(2 * time.Hour) }
+	if v, _ := h.Get(ctx, "game:1"); v != "changed elsewhere" {
+		t.Errorf("Get() after expiry = %q, want the Redis value", v)
+	}
+}
+
+// memQueue is an in-memory Queue; lists are head first
+type memQueue struct {
+	mu    sync.Mutex
+	lists map[string][]string
+	down  bool
+}
+
+func newMemQueue() *memQueue {
+	return &memQueue{lists: map[string][]string{}}
+}
+
+func (q *memQueue) Push(ctx context.Context, list, value string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.down {
+		return errors.New("connection refused")
+	}
+	q.lists[list] = append([]string{value}, q.lists[list]...)
+	return nil
+}
+
+func (q *memQueue) Take(ctx context.Context, from, to string) (string, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	l := q.lists[from]
+	if len(l) == 0 {
+		return "", ErrNotFound
+	}
+	v := l[len(l)-1]
+	q.lists[from] = l[:len(l)-1]
+	q.lists[to] = append([]string{v}, q.lists[to]...)
+	return v, nil
+}
+
+func (q *memQueue) Range(ctx context.Context, list string) ([]string, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return append([]string{}, q.lists[list]...), nil
+}
+
+func (q *memQueue) Remove(ctx context.Context, list, value string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	l := q.lists[list]
+	for i, v := range l {
+		if v == value {
+			q.lists[list] = append(l[:i:i], l[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (q *memQueue) len(list string) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.lists[list])
+}
+
+func TestWriteBehindOrderAndRetry(t *testing.T) {
+	ctx := context.Background()
+	q := newMemQueue()
+	w := NewWriteBehind(q, "test")
+	w.RetryDelay = time.Millisecond
+
+	var written []int
+	failures := 2
+	Handle(w, "n", func(ctx context.Context, n int) error {
+		if n == 2 && failures > 0 {
+			failures--
+			return errors.New("db busy")
+		}
+		written = append(written, n)
+		return nil
+	})
+
+	for n := 1; n <= 3; n++ {
+		if err := w.Enqueue(ctx, "n", n); err != nil {
+			t.Fatalf("Enqueue() error = %v", err)
+		}
+	}
+	if len(written) != 0 {
+		t.Fatal("Enqueue() wrote before the worker ran")
+	}
+	if err := w.Drain(ctx); err != nil {
+		t.Fatalf("Drain() error = %v", err)
+	}
+
+	if len(written) != 3 || written[0] != 1 || written[1] != 2 || written[2] != 3 {
+		t.Errorf("written = %v, want [1 2 3]", written)
+	}
+	if st := w.Stats(); st.Queued != 3 || st.Written != 3 || st.Retried != 2 {
+		t.Errorf("stats = %+v", st)
+	}
+	if q.len(w.working) != 0 || q.len(w.pending) != 0 {
+		t.Error("lists not empty after Drain()")
+	}
+}
+
+func TestWriteBehindGivesUp(t *testing.T) {
+	ctx := context.Background()
+	q := newMemQueue()
+	w := NewWriteBehind(q, "test")
+	w.RetryDelay = time.Millisecond
+	w.MaxAttempts = 2
+	Handle(w, "n", func(ctx context.Context, n int) error { return errors.New("constraint violation") })
+
+	w.Enqueue(ctx, "n", 1)
+	w.Drain(ctx)
+	if q.len(w.failed) != 1 || q.len(w.working) != 0 {
+		t.Errorf("failed = %d, working = %d; want 1, 0", q.len(w.failed), q.len(w.working))
+	}
+	if st := w.Stats(); st.Failed != 1 {
+		t.Errorf("stats = %+v", st)
+	}
+}
+
+func TestWriteBehindRecoversAndFallsBack(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	q := newMemQueue()
+
+	// A previous run stopped mid-write
+	old := NewWriteBehind(q, "test")
+	old.Enqueue(ctx, "n", 1)
+	old.Enqueue(ctx, "n", 2)
+	if _, err := q.Take(ctx, old.pending, old.working); err != nil {
+		t.Fatal(err)
+	}
+
+	w := NewWriteBehind(q, "test")
+	var mu sync.Mutex
+	var written []int
+	Handle(w, "n", func(ctx context.Context, n int) error {
+		mu.Lock()
+		written = append(written, n)
+		mu.Unlock()
+		return nil
+	})
+	w.Start(ctx)
+	if st := w.Stats(); st.Recovered != 1 {
+		t.Errorf("recovered = %d, want 1", st.Recovered)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		mu.Lock()
+		n := len(written)
+		mu.Unlock()
+		if n == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("written = %v, want [1 2]", written)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	mu.Lock()
+	if written[0] != 1 {
+		t.Errorf("written = %v, want the in-progress write first", written)
+	}
+	mu.Unlock()
+
+	// Redis down: written inline
+	q.mu.Lock()
+	q.down = true
+	q.mu.Unlock()
+	if err := w.Enqueue(ctx, "n", 3); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	mu.Lock()
+	if len(written) != 3 || written[2] != 3 {
+		t.Errorf("written = %v, want 3 written inline", written)
+	}
+	mu.Unlock()
+	if st := w.Stats(); st.Inline != 1 {
+		t.Errorf("inline = %d, want 1", st.Inline)
+	}
+}
@@ -0,0 +1,276 @@
+package turns
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// Queue is the part of a Redis client the write-behind uses: two lists, so a
+// write taken off the queue but not yet persisted survives a restart.
+//
+// Usage (go-redis v8):
+//
+//	type redisQueue struct{ client *redis.Client }
+//
+//	func (q redisQueue) Push(ctx context.Context, list, value string) error {
+//	    return q.client.LPush(ctx, list, value).Err()
+//	}
+//
+//	func (q redisQueue) Take(ctx context.Context, from, to string) (string, error) {
+//	    v, err := q.client.RPopLPush(ctx, from, to).Result()
+//	    if err == redis.Nil {
+//	        return "", turns.ErrNotFound
+//	    }
+//	    return v, err
+//	}
+//
+//	func (q redisQueue) Range(ctx context.Context, list string) ([]string, error) {
+//	    return q.client.LRange(ctx, list, 0, -1).Result()
+//	}
+//
+//	func (q redisQueue) Remove(ctx context.Context, list, value string) error {
+//	    return q.client.LRem(ctx, list, 1, value).Err()
+//	}
+type Queue interface {
+	Push(ctx context.Context, list, value string) error        // Add to the head
+	Take(ctx context.Context, from, to string) (string, error) // Move the tail of from to the head of to; ErrNotFound if from is empty
+	Range(ctx context.Context, list string) ([]string, error)  // Head first
+	Remove(ctx context.Context, list, value string) error
+}
+
+// WriteBehind persists game data to PostgreSQL off the request path. Writes
+// are queued in Redis and a single worker applies them in the order they were
+// queued, so e.g. a game's moves are saved before the game that counts them.
+// A write that fails is retried in place, holding back the ones behind it,
+// until MaxAttempts; then it's moved to the failed list and logged.
+//
+// Writes must be safe to apply twice: one that was being applied when the
+// backend stopped is applied again when it restarts.
+type WriteBehind struct {
+	queue   Queue
+	pending string
+	working string
+	failed  string
+
+	RetryDelay  time.Duration // Between attempts at a failing write
+	MaxAttempts int
+
+	drainMu  sync.Mutex // One writer at a time keeps writes in order
+	mu       sync.Mutex
+	handlers map[string]func(ctx context.Context, data []byte) error
+	stats    WriteStats
+	wake     chan struct{}
+}
+
+// WriteStats counts write-behind traffic since the backend started
+type WriteStats struct {
+	Queued    int64 `json:"queued"`
+	Written   int64 `json:"written"`
+	Retried   int64 `json:"retried"`
+	Failed    int64 `json:"failed"`
+	Inline    int64 `json:"inline"`    // Written in the request because Redis couldn't queue them
+	Recovered int64 `json:"recovered"` // Found in progress at startup
+}
+
+// queuedWrite is a write as it's held in Redis
+type queuedWrite struct {
+	ID   string          `json:"id"` // Keeps identical writes distinct in the lists
+	Kind string          `json:"kind"`
+	Data json.RawMessage `json:"data"`
+}
+
+// NewWriteBehind creates a write-behind queue for a service, keeping its
+// lists under "writebehind:<service>:".
+func NewWriteBehind(queue Queue, service string) *WriteBehind {
+	prefix := "writebehind:" + service + ":"
+	return &WriteBehind{
+		queue:       queue,
+		pending:     prefix + "pending",
+		working:     prefix + "working",
+		failed:      prefix + "failed",
+		RetryDelay:  2 * time.Second,
+		MaxAttempts: 5,
+		handlers:    map[string]func(ctx context.Context, data []byte) error{},
+		wake:        make(chan struct{}, 1),
+	}
+}
+
+// Handle registers how writes of a kind are applied. Register every kind
+// before Start.
+//
+// Usage:
+//
+//	turns.Handle(writes, "move", func(ctx context.Context, m Move) error {
+//	    return SaveMove(&m)
+//	})
+func Handle[T any](w *WriteBehind, kind string, apply func(ctx context.Context, v T) error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.handlers[kind] = func(ctx context.Context, data []byte) error {
+		var v T
+		if err := json.Unmarshal(data, &v); err != nil {
+			return fmt.Errorf("failed to unmarshal %s write: %w", kind, err)
+		}
+		return apply(ctx, v)
+	}
+}
+
+// Enqueue queues a write. If Redis can't take it the write is applied before
+// Enqueue returns, so nothing is lost; its error is returned only then.
+func (w *WriteBehind) Enqueue(ctx context.Context, kind string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s write: %w", kind, err)
+	}
+	entry, err := json.Marshal(queuedWrite{ID: newWriteID(), Kind: kind, Data: data})
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s write: %w", kind, err)
+	}
+
+	if err := w.queue.Push(ctx, w.pending, string(entry)); err != nil {
+		log.Printf("⚠️ Write-behind queue unavailable, writing %s inline: %v", kind, err)
+		w.count(func(s *WriteStats) { s.Inline++ })
+		return w.apply(ctx, kind, data)
+	}
+	w.count(func(s *WriteStats) { s.Queued++ })
+
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Start applies any writes left in progress by a previous run, then runs the
+// worker until ctx is cancelled.
+func (w *WriteBehind) Start(ctx context.Context) {
+	w.recover(ctx)
+	go w.run(ctx)
+}
+
+// recover applies writes that were taken off the queue but not finished when
+// the backend last stopped. They're older than anything still pending.
+func (w *WriteBehind) recover(ctx context.Context) {
+	w.drainMu.Lock()
+	defer w.drainMu.Unlock()
+
+	entries, err := w.queue.Range(ctx, w.working)
+	if err != nil {
+		log.Printf("⚠️ Failed to read in-progress writes: %v", err)
+		return
+	}
+	// Oldest is at the tail
+	for i := len(entries) - 1; i >= 0; i-- {
+		w.count(func(s *WriteStats) { s.Recovered++ })
+		w.process(ctx, entries[i])
+	}
+	if len(entries) > 0 {
+		log.Printf("🔁 Recovered %d in-progress writes", len(entries))
+	}
+}
+
+func (w *WriteBehind) run(ctx context.Context) {
+	for {
+		if err := w.Drain(ctx); err != nil {
+			log.Printf("⚠️ Write-behind queue unavailable: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.wake:
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// Drain applies every pending write. The worker calls it whenever writes are
+// queued; call it directly to flush before shutting down.
+func (w *WriteBehind) Drain(ctx context.Context) error {
+	w.drainMu.Lock()
+	defer w.drainMu.Unlock()
+
+	for ctx.Err() == nil {
+		entry, err := w.queue.Take(ctx, w.pending, w.working)
+		if err == ErrNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		w.process(ctx, entry)
+	}
+	return ctx.Err()
+}
+
+// process applies one write from the working list, retrying it until it
+// succeeds or runs out of attempts, then takes it off the list.
+func (w *WriteBehind) process(ctx context.Context, entry string) {
+	var qw queuedWrite
+	err := json.Unmarshal([]byte(entry), &qw)
+	if err == nil {
+		for attempt := 1; ; attempt++ {
+			if err = w.apply(ctx, qw.Kind, qw.Data); err == nil {
+				w.count(func(s *WriteStats) { s.Written++ })
+				break
+			}
+			if attempt >= w.MaxAttempts || ctx.Err() != nil {
+				break
+			}
+			w.count(func(s *WriteStats) { s.Retried++ })
+			select {
+			case <-ctx.Done():
+			case <-time.After(w.RetryDelay):
+			}
+		}
+	}
+	if err != nil && ctx.Err() != nil {
+		// Stopping: leave it on the working list for the next run
+		return
+	}
+
+	if err != nil {
+		log.Printf("❌ Giving up on %s write: %v", qw.Kind, err)
+		w.count(func(s *WriteStats) { s.Failed++ })
+		if err := w.queue.Push(ctx, w.failed, entry); err != nil {
+			log.Printf("⚠️ Failed to keep failed write: %v", err)
+		}
+	}
+	if err := w.queue.Remove(ctx, w.working, entry); err != nil {
+		log.Printf("⚠️ Failed to finish write: %v", err)
+	}
+}
+
+func (w *WriteBehind) apply(ctx context.Context, kind string, data []byte) error {
+	w.mu.Lock()
+	handler, ok := w.handlers[kind]
+	w.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("no handler for %s writes", kind)
+	}
+	return handler(ctx, data)
+}
+
+func (w *WriteBehind) count(f func(s *WriteStats)) {
+	w.mu.Lock()
+	f(&w.stats)
+	w.mu.Unlock()
+}
+
+// Stats returns the counts since the backend started
+func (w *WriteBehind) Stats() WriteStats {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.stats
+}
+
+func newWriteID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
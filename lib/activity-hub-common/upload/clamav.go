@@ -0,0 +1,94 @@
+package upload
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/achgithub/activity-hub-common/config"
+)
+
+// Scanner checks a file for malware. Scan returns nil for a clean file, an
+// error wrapping ErrInfected for a detection and one wrapping
+// ErrScanUnavailable when the scan couldn't be done.
+type Scanner interface {
+	Scan(ctx context.Context, data []byte) error
+}
+
+// scanner is used by Check for every upload. It is a ClamAV daemon when
+// CLAMAV_ADDR (host:port of clamd) is set, otherwise there is no scan.
+var scanner Scanner = defaultScanner()
+
+func defaultScanner() Scanner {
+	if addr := config.GetEnv("CLAMAV_ADDR", ""); addr != "" {
+		return ClamAV{Addr: addr}
+	}
+	return nil
+}
+
+// SetScanner replaces the scanner used for uploads; nil turns scanning off.
+func SetScanner(s Scanner) {
+	scanner = s
+}
+
+// ClamAV scans files with a clamd daemon over TCP (INSTREAM). Uploads are
+// refused while clamd is unreachable rather than stored unscanned.
+type ClamAV struct {
+	Addr    string        // host:port, e.g. 127.0.0.1:3310
+	Timeout time.Duration // Whole scan, default 30s
+}
+
+// clamdChunkSize is the largest INSTREAM chunk sent to clamd
+const clamdChunkSize = 64 << 10
+
+// Scan streams data to clamd and reads its verdict
+func (c ClamAV) Scan(ctx context.Context, data []byte) error {
+	timeout := c.Timeout
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", c.Addr)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrScanUnavailable, err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	w := bufio.NewWriter(conn)
+	w.WriteString("zINSTREAM\x00")
+	for len(data) > 0 {
+		n := min(len(data), clamdChunkSize)
+		binary.Write(w, binary.BigEndian, uint32(n))
+		w.Write(data[:n])
+		data = data[n:]
+	}
+	binary.Write(w, binary.BigEndian, uint32(0))
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("%w: %v", ErrScanUnavailable, err)
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrScanUnavailable, err)
+	}
+	// "stream: OK", "stream: <signature> FOUND" or "<message> ERROR"
+	reply = strings.TrimSuffix(reply, "\x00")
+	switch {
+	case strings.HasSuffix(reply, " OK"):
+		return nil
+	case strings.HasSuffix(reply, " FOUND"):
+		signature := strings.TrimSuffix(strings.TrimPrefix(reply, "stream: "), " FOUND")
+		return fmt.Errorf("%w (%s)", ErrInfected, signature)
+	}
+	return fmt.Errorf("%w: clamd: %s", ErrScanUnavailable, reply)
+}
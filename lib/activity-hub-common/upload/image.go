@@ -0,0 +1,242 @@
+package upload
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+)
+
+// MaxImagePixels guards against small files that decode to huge images
+const MaxImagePixels = 40_000_000
+
+// jpegQuality is used when re-encoding JPEGs
+const jpegQuality = 90
+
+// sanitizeImage decodes and re-encodes an image in its own format, dropping
+// metadata (EXIF, comments) and anything appended after the image data. A
+// JPEG's EXIF orientation is applied to the pixels first, so phone photos
+// stay the right way up once the tag is gone. The standard library can't
+// decode WebP, so WebP files are rebuilt from their image chunks instead.
+func sanitizeImage(contentType string, data []byte) ([]byte, error) {
+	if contentType == "image/webp" {
+		return sanitizeWebP(data)
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidFile
+	}
+	if err := checkDimensions(cfg.Width, cfg.Height); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	var encodeErr error
+	switch contentType {
+	case "image/png":
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, ErrInvalidFile
+		}
+		encodeErr = png.Encode(&buf, img)
+	case "image/jpeg":
+		img, err := jpeg.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, ErrInvalidFile
+		}
+		img = orient(img, jpegOrientation(data))
+		encodeErr = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+	case "image/gif":
+		// DecodeAll/EncodeAll keep animation
+		g, err := gif.DecodeAll(bytes.NewReader(data))
+		if err != nil {
+			return nil, ErrInvalidFile
+		}
+		encodeErr = gif.EncodeAll(&buf, g)
+	default:
+		return nil, ErrUnsupportedType
+	}
+	if encodeErr != nil {
+		return nil, fmt.Errorf("re-encode %s: %w", contentType, encodeErr)
+	}
+	return buf.Bytes(), nil
+}
+
+// jpegOrientation reads the EXIF Orientation tag (1-8) from a JPEG's APP1
+// segment, or 1 when there isn't one.
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+	pos := 2
+	for pos+4 <= len(data) && data[pos] == 0xFF {
+		marker := data[pos+1]
+		if marker == 0xDA { // start of scan: no more metadata
+			break
+		}
+		n := int(binary.BigEndian.Uint16(data[pos+2 : pos+4]))
+		if n < 2 || pos+2+n > len(data) {
+			break
+		}
+		segment := data[pos+4 : pos+2+n]
+		if marker == 0xE1 && len(segment) > 6 && string(segment[:6]) == "Exif\x00\x00" {
+			return exifOrientation(segment[6:])
+		}
+		pos += 2 + n
+	}
+	return 1
+}
+
+// exifOrientation finds the Orientation tag in IFD0 of a TIFF header.
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	ifd := int(order.Uint32(tiff[4:8]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+	count := int(order.Uint16(tiff[ifd : ifd+2]))
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + 12*i
+		if entry+12 > len(tiff) {
+			break
+		}
+		if order.Uint16(tiff[entry:entry+2]) == 0x0112 {
+			if v := int(order.Uint16(tiff[entry+8 : entry+10])); v >= 1 && v <= 8 {
+				return v
+			}
+			break
+		}
+	}
+	return 1
+}
+
+// orient returns img turned and/or mirrored as its EXIF orientation says it
+// should be shown. Orientations 5-8 swap width and height.
+func orient(img image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	out := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // mirrored
+				sx, sy = w-1-x, y
+			case 3: // upside down
+				sx, sy = w-1-x, h-1-y
+			case 4: // mirrored upside down
+				sx, sy = x, h-1-y
+			case 5: // mirrored, turned left
+				sx, sy = y, x
+			case 6: // turned left, so rotate clockwise
+				sx, sy = y, h-1-x
+			case 7: // mirrored, turned right
+				sx, sy = w-1-y, h-1-x
+			case 8: // turned right, so rotate anticlockwise
+				sx, sy = w-1-y, x
+			}
+			out.Set(x, y, img.At(b.Min.X+sx, b.Min.Y+sy))
+		}
+	}
+	return out
+}
+
+func checkDimensions(width, height int) error {
+	if width <= 0 || height <= 0 {
+		return ErrInvalidFile
+	}
+	if int64(width)*int64(height) > MaxImagePixels {
+		return fmt.Errorf("%w (image is %dx%d)", ErrTooLarge, width, height)
+	}
+	return nil
+}
+
+// webpImageChunks are the RIFF chunks kept from a WebP file. Metadata (EXIF,
+// XMP, ICCP) and unknown chunks are dropped.
+var webpImageChunks = map[string]bool{
+	"VP8 ": true, "VP8L": true, "VP8X": true, "ALPH": true, "ANIM": true, "ANMF": true,
+}
+
+// VP8X flags for the metadata chunks that are dropped
+const webpMetadataFlags = 0x20 | 0x08 | 0x04 // ICC profile, EXIF, XMP
+
+// sanitizeWebP rebuilds a WebP container from its image chunks, dropping
+// metadata chunks and any bytes after the RIFF payload.
+func sanitizeWebP(data []byte) ([]byte, error) {
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, ErrInvalidFile
+	}
+	size := int64(binary.LittleEndian.Uint32(data[4:8]))
+	if size < 4 || size+8 > int64(len(data)) {
+		return nil, ErrInvalidFile
+	}
+
+	body := data[12 : 8+size]
+	var chunks []byte
+	hasImage := false
+	for len(body) > 0 {
+		if len(body) < 8 {
+			return nil, ErrInvalidFile
+		}
+		id := string(body[0:4])
+		n := int64(binary.LittleEndian.Uint32(body[4:8]))
+		padded := n + n&1
+		if 8+padded > int64(len(body)) {
+			return nil, ErrInvalidFile
+		}
+		chunk := body[:8+padded]
+		body = body[8+padded:]
+
+		if !webpImageChunks[id] {
+			continue
+		}
+		switch id {
+		case "VP8X":
+			if n < 10 {
+				return nil, ErrInvalidFile
+			}
+			chunk = append([]byte(nil), chunk...)
+			chunk[8] &^= webpMetadataFlags
+			// Canvas size is stored as 24-bit width-1, height-1
+			width := int(chunk[12]) | int(chunk[13])<<8 | int(chunk[14])<<16 + 1
+			height := int(chunk[15]) | int(chunk[16])<<8 | int(chunk[17])<<16 + 1
+			if err := checkDimensions(width, height); err != nil {
+				return nil, err
+			}
+		case "VP8 ", "VP8L", "ANMF":
+			hasImage = true
+		}
+		chunks = append(chunks, chunk...)
+	}
+	if !hasImage {
+		return nil, ErrInvalidFile
+	}
+
+	out := make([]byte, 0, 12+len(chunks))
+	out = append(out, "RIFF"...)
+	out = binary.LittleEndian.AppendUint32(out, uint32(4+len(chunks)))
+	out = append(out, "WEBP"...)
+	return append(out, chunks...), nil
+}
@@ -0,0 +1,255 @@
+// Package upload reads and checks user-uploaded files before they are stored.
+//
+// The type of a file is sniffed from its content, never taken from its name or
+// the client's Content-Type. Size limits are set per type here rather than in
+// each handler, images are re-encoded so nothing but pixels survives, and
+// files can be passed to a virus scanner (see ClamAV).
+package upload
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"unicode/utf8"
+)
+
+// Kind is a class of file a handler accepts
+type Kind string
+
+const (
+	Image Kind = "image" // PNG, JPEG, GIF, WebP
+	Audio Kind = "audio" // MP3, WAV, Ogg, M4A
+	CSV   Kind = "csv"   // UTF-8 text
+)
+
+// limits are the largest files accepted of each kind
+var limits = map[Kind]int64{
+	Image: 10 << 20,
+	Audio: 20 << 20,
+	CSV:   5 << 20,
+}
+
+// formOverhead allows for the multipart boundaries and other form fields
+const formOverhead = 64 << 10
+
+// Policy is what an upload endpoint accepts.
+type Policy struct {
+	Kinds    []Kind
+	MaxBytes int64 // Optional cap below the per-kind limits
+}
+
+// Policies for the platform's upload endpoints
+var (
+	Images = Policy{Kinds: []Kind{Image}}
+	Media  = Policy{Kinds: []Kind{Image, Audio}}
+	Sheets = Policy{Kinds: []Kind{CSV}}
+	Avatar = Policy{Kinds: []Kind{Image}, MaxBytes: 256 << 10}
+)
+
+func (p Policy) accepts(kind Kind) bool {
+	for _, k := range p.Kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Limit is the largest file of the given kind the policy accepts
+func (p Policy) Limit(kind Kind) int64 {
+	limit := limits[kind]
+	if p.MaxBytes > 0 && p.MaxBytes < limit {
+		limit = p.MaxBytes
+	}
+	return limit
+}
+
+func (p Policy) maxBytes() int64 {
+	var max int64
+	for _, k := range p.Kinds {
+		if l := p.Limit(k); l > max {
+			max = l
+		}
+	}
+	return max
+}
+
+// Errors returned by Read and Check. Their messages are safe to show users;
+// StatusCode maps them to a response status.
+var (
+	ErrMissingFile     = errors.New("file is required")
+	ErrInvalidForm     = errors.New("invalid upload form")
+	ErrTooLarge        = errors.New("file too large")
+	ErrUnsupportedType = errors.New("unsupported file type")
+	ErrInvalidFile     = errors.New("file is corrupt or not what it claims to be")
+	ErrInfected        = errors.New("file rejected by virus scan")
+	ErrScanUnavailable = errors.New("virus scan unavailable, try again later")
+)
+
+// StatusCode is the HTTP status to respond with for an error from Read or Check
+func StatusCode(err error) int {
+	switch {
+	case errors.Is(err, ErrMissingFile), errors.Is(err, ErrInvalidForm), errors.Is(err, ErrInvalidFile):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, ErrUnsupportedType):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, ErrInfected):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, ErrScanUnavailable):
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
+// File is an upload that passed its policy, ready to store.
+type File struct {
+	Name        string // Client's filename, for display only - never use it as a path
+	Kind        Kind
+	ContentType string // Sniffed from the content
+	Ext         string // Extension for ContentType, e.g. ".png"
+	Data        []byte // Sanitized content: images are re-encoded
+}
+
+// Read parses a multipart form (if not already parsed) and checks the file in
+// the given field against the policy. The request body is capped at the
+// policy's largest limit, so oversized uploads are cut off, not buffered.
+//
+// Usage:
+//
+//	file, err := upload.Read(w, r, "file", upload.Media)
+//	if err != nil {
+//		http.Error(w, err.Error(), upload.StatusCode(err))
+//		return
+//	}
+//	os.WriteFile(filepath.Join(dir, id+file.Ext), file.Data, 0644)
+func Read(w http.ResponseWriter, r *http.Request, field string, p Policy) (*File, error) {
+	max := p.maxBytes()
+	if r.MultipartForm == nil {
+		r.Body = http.MaxBytesReader(w, r.Body, max+formOverhead)
+		if err := r.ParseMultipartForm(max); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				return nil, tooLargeError(max)
+			}
+			return nil, ErrInvalidForm
+		}
+	}
+
+	f, header, err := r.FormFile(field)
+	if err != nil {
+		return nil, ErrMissingFile
+	}
+	defer f.Close()
+	if header.Size > max {
+		return nil, tooLargeError(max)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(f, max+1))
+	if err != nil {
+		return nil, ErrInvalidForm
+	}
+	return Check(r.Context(), header.Filename, data, p)
+}
+
+// Check sniffs data, enforces the policy and size limit for its kind, scans it
+// if a scanner is configured and sanitizes it.
+func Check(ctx context.Context, name string, data []byte, p Policy) (*File, error) {
+	if len(data) == 0 {
+		return nil, ErrMissingFile
+	}
+	if max := p.maxBytes(); int64(len(data)) > max {
+		return nil, tooLargeError(max)
+	}
+
+	contentType, kind := Sniff(data)
+	if kind == "" || !p.accepts(kind) {
+		return nil, ErrUnsupportedType
+	}
+	if limit := p.Limit(kind); int64(len(data)) > limit {
+		return nil, tooLargeError(limit)
+	}
+
+	if s := scanner; s != nil {
+		if err := s.Scan(ctx, data); err != nil {
+			log.Printf("⚠️  Upload %q not accepted: %v", name, err)
+			return nil, err
+		}
+	}
+
+	clean := data
+	var err error
+	switch kind {
+	case Image:
+		clean, err = sanitizeImage(contentType, data)
+	case CSV:
+		if bytes.IndexByte(data, 0) >= 0 || !utf8.Valid(data) {
+			err = ErrInvalidFile
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &File{
+		Name:        name,
+		Kind:        kind,
+		ContentType: contentType,
+		Ext:         extensions[contentType],
+		Data:        clean,
+	}, nil
+}
+
+func tooLargeError(limit int64) error {
+	if limit >= 1<<20 {
+		return fmt.Errorf("%w (max %d MB)", ErrTooLarge, limit>>20)
+	}
+	return fmt.Errorf("%w (max %d KB)", ErrTooLarge, limit>>10)
+}
+
+// extensions are the file extensions stored for each sniffed type
+var extensions = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+	"audio/mpeg": ".mp3",
+	"audio/wav":  ".wav",
+	"audio/ogg":  ".ogg",
+	"audio/mp4":  ".m4a",
+	"text/csv":   ".csv",
+}
+
+// Sniff identifies a file from its first bytes. It returns an empty kind for
+// anything the platform doesn't accept.
+func Sniff(data []byte) (contentType string, kind Kind) {
+	// Formats http.DetectContentType doesn't know or misreports
+	switch {
+	case len(data) >= 12 && string(data[4:8]) == "ftyp" &&
+		(string(data[8:12]) == "M4A " || string(data[8:12]) == "M4B "):
+		return "audio/mp4", Audio
+	case len(data) >= 3 && data[0] == 0xFF && data[1]&0xE0 == 0xE0 && data[1]&0x06 != 0 &&
+		data[2]&0xF0 != 0xF0 && data[2]&0x0C != 0x0C:
+		// MPEG audio frame header (sync, layer, bitrate, sample rate) for MP3s without an ID3 tag
+		return "audio/mpeg", Audio
+	}
+
+	switch detected := http.DetectContentType(data); detected {
+	case "image/png", "image/jpeg", "image/gif", "image/webp":
+		return detected, Image
+	case "audio/mpeg":
+		return detected, Audio
+	case "audio/wave":
+		return "audio/wav", Audio
+	case "application/ogg":
+		return "audio/ogg", Audio
+	case "text/plain; charset=utf-8":
+		return "text/csv", CSV
+	}
+	return "", ""
+}
@@ -0,0 +1,445 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testPNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	img.Set(0, 0, color.RGBA{R: 255, A: 255})
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func riffChunk(id string, payload []byte) []byte {
+	c := append([]byte(id), binary.LittleEndian.AppendUint32(nil, uint32(len(payload)))...)
+	c = append(c, payload...)
+	if len(payload)%2 == 1 {
+		c = append(c, 0)
+	}
+	return c
+}
+
+func testWebP(chunks ...[]byte) []byte {
+	body := []byte("WEBP")
+	for _, c := range chunks {
+		body = append(body, c...)
+	}
+	out := append([]byte("RIFF"), binary.LittleEndian.AppendUint32(nil, uint32(len(body)))...)
+	return append(out, body...)
+}
+
+func TestSniff(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		kind Kind
+		ext  string
+	}{
+		{"png", testPNG(t, 1, 1), Image, ".png"},
+		{"jpeg", []byte("\xFF\xD8\xFF\xE0\x00\x10JFIF\x00"), Image, ".jpg"},
+		{"gif", []byte("GIF89a\x01\x00\x01\x00"), Image, ".gif"},
+		{"webp", testWebP(riffChunk("VP8L", []byte{0x2f})), Image, ".webp"},
+		{"mp3 id3", []byte("ID3\x04\x00\x00\x00\x00\x00\x00"), Audio, ".mp3"},
+		{"mp3 frame", []byte{0xFF, 0xFB, 0x90, 0x64, 0x00}, Audio, ".mp3"},
+		{"wav", []byte("RIFF\x24\x00\x00\x00WAVEfmt "), Audio, ".wav"},
+		{"ogg", []byte("OggS\x00\x02\x00\x00"), Audio, ".ogg"},
+		{"m4a", []byte("\x00\x00\x00\x20ftypM4A \x00\x00\x00\x00"), Audio, ".m4a"},
+		{"csv", []byte("text,answer\nCapital of France?,Paris\n"), CSV, ".csv"},
+		{"html", []byte("<html><script>alert(1)</script>"), "", ""},
+		{"exe", []byte("MZ\x90\x00\x03\x00\x00\x00"), "", ""},
+		{"mp4 video", []byte("\x00\x00\x00\x18ftypmp42\x00\x00\x00\x00mp42isom"), "", ""},
+	}
+	for _, tt := range tests {
+		contentType, kind := Sniff(tt.data)
+		if kind != tt.kind || extensions[contentType] != tt.ext {
+			t.Errorf("%s: Sniff = %q, %q; want kind %q, ext %q", tt.name, contentType, kind, tt.kind, tt.ext)
+		}
+	}
+}
+
+func TestCheckIgnoresClaimedType(t *testing.T) {
+	ctx := context.Background()
+
+	// An HTML file named like an image
+	_, err := Check(ctx, "cat.png", []byte("<html><body>hi</body></html>"), Images)
+	if !errors.Is(err, ErrUnsupportedType) || StatusCode(err) != http.StatusUnsupportedMediaType {
+		t.Errorf("Expected unsupported type, got %v", err)
+	}
+
+	// Audio where only images are accepted
+	_, err = Check(ctx, "clip.png", []byte("ID3\x04\x00\x00\x00\x00\x00\x00"), Images)
+	if !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("Expected unsupported type for audio, got %v", err)
+	}
+
+	// A truncated image
+	_, err = Check(ctx, "broken.png", testPNG(t, 4, 4)[:40], Images)
+	if !errors.Is(err, ErrInvalidFile) || StatusCode(err) != http.StatusBadRequest {
+		t.Errorf("Expected invalid file, got %v", err)
+	}
+}
+
+func TestCheckReencodesImages(t *testing.T) {
+	// Trailing payload after the PNG's IEND chunk
+	data := append(testPNG(t, 2, 2), []byte("<?php system($_GET['c']); ?>")...)
+
+	f, err := Check(context.Background(), "photo.PNG", data, Media)
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if f.Kind != Image || f.ContentType != "image/png" || f.Ext != ".png" || f.Name != "photo.PNG" {
+		t.Errorf("Unexpected file %+v", f)
+	}
+	if bytes.Contains(f.Data, []byte("php")) {
+		t.Error("Expected trailing payload to be stripped")
+	}
+	if _, err := png.Decode(bytes.NewReader(f.Data)); err != nil {
+		t.Errorf("Re-encoded image doesn't decode: %v", err)
+	}
+}
+
+func TestCheckSizeLimits(t *testing.T) {
+	ctx := context.Background()
+
+	big := append(testPNG(t, 1, 1), make([]byte, 300<<10)...)
+	_, err := Check(ctx, "avatar.png", big, Avatar)
+	if !errors.Is(err, ErrTooLarge) || StatusCode(err) != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected too large for avatar, got %v", err)
+	}
+	if _, err := Check(ctx, "photo.png", big, Images); err != nil {
+		t.Errorf("Expected image under the image limit to pass, got %v", err)
+	}
+
+	// Audio may be bigger than images in the same policy
+	audio := append([]byte("ID3\x04\x00\x00\x00\x00\x00\x00"), make([]byte, 12<<20)...)
+	if _, err := Check(ctx, "song.mp3", audio, Media); err != nil {
+		t.Errorf("Expected 12MB audio to pass, got %v", err)
+	}
+	pic := append(testPNG(t, 1, 1), make([]byte, 12<<20)...)
+	if _, err := Check(ctx, "huge.png", pic, Media); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Expected 12MB image to be too large, got %v", err)
+	}
+
+	// Small file, huge canvas
+	header := testPNG(t, 1, 1)
+	binary.BigEndian.PutUint32(header[16:20], 100000)
+	binary.BigEndian.PutUint32(header[20:24], 100000)
+	if _, err := Check(ctx, "bomb.png", header, Images); !errors.Is(err, ErrTooLarge) && !errors.Is(err, ErrInvalidFile) {
+		t.Errorf("Expected oversized dimensions to be refused, got %v", err)
+	}
+}
+
+func TestCheckCSV(t *testing.T) {
+	ctx := context.Background()
+	if _, err := Check(ctx, "q.csv", []byte("text,answer\nA,B\n"), Sheets); err != nil {
+		t.Errorf("Expected CSV to pass, got %v", err)
+	}
+	if _, err := Check(ctx, "q.csv", testPNG(t, 1, 1), Sheets); !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("Expected image to be refused as CSV, got %v", err)
+	}
+}
+
+// testJPEGWithOrientation is a w x h JPEG, red in the top-left corner, with
+// an EXIF APP1 segment (big-endian TIFF) carrying the given orientation
+func testJPEGWithOrientation(t *testing.T, w, h, orientation int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{B: 255, A: 255})
+		}
+	}
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			img.Set(x, y, color.RGBA{R: 255, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatal(err)
+	}
+
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08")  // header, IFD0 at offset 8
+	tiff = binary.BigEndian.AppendUint16(tiff, 1) // one entry
+	tiff = binary.BigEndian.AppendUint16(tiff, 0x0112)
+	tiff = binary.BigEndian.AppendUint16(tiff, 3) // SHORT
+	tiff = binary.BigEndian.AppendUint32(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, uint16(orientation))
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0) // value padding, no next IFD
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+
+	app1 := []byte{0xFF, 0xE1}
+	app1 = binary.BigEndian.AppendUint16(app1, uint16(len(payload)+2))
+	app1 = append(app1, payload...)
+
+	data := buf.Bytes()
+	out := append([]byte{}, data[:2]...)
+	out = append(out, app1...)
+	return append(out, data[2:]...)
+}
+
+func TestCheckAppliesJPEGOrientation(t *testing.T) {
+	cases := []struct {
+		orientation   int
+		width, height int
+		redX, redY    int // where the top-left corner ends up
+	}{
+		{1, 32, 16, 0, 0},
+		{3, 32, 16, 31, 15},
+		{6, 16, 32, 15, 0},
+		{8, 16, 32, 0, 31},
+	}
+	for _, c := range cases {
+		data := testJPEGWithOrientation(t, 32, 16, c.orientation)
+		if got := jpegOrientation(data); got != c.orientation {
+			t.Errorf("jpegOrientation = %d, want %d", got, c.orientation)
+		}
+
+		f, err := Check(context.Background(), "photo.jpg", data, Images)
+		if err != nil {
+			t.Fatalf("Check: %v", err)
+		}
+		if bytes.Contains(f.Data, []byte("Exif")) {
+			t.Errorf("Orientation %d: expected EXIF to be stripped", c.orientation)
+		}
+		img, err := jpeg.Decode(bytes.NewReader(f.Data))
+		if err != nil {
+			t.Fatalf("Re-encoded image doesn't decode: %v", err)
+		}
+		if b := img.Bounds(); b.Dx() != c.width || b.Dy() != c.height {
+			t.Errorf("Orientation %d: got %dx%d, want %dx%d", c.orientation, b.Dx(), b.Dy(), c.width, c.height)
+			continue
+		}
+		if r, _, bl, _ := img.At(c.redX, c.redY).RGBA(); r < bl {
+			t.Errorf("Orientation %d: expected the red corner at (%d,%d)", c.orientation, c.redX, c.redY)
+		}
+	}
+}
+
+func TestSanitizeWebP(t *testing.T) {
+	vp8x := make([]byte, 10)
+	vp8x[0] = 0x08 | 0x04 // EXIF and XMP present
+	data := testWebP(
+		riffChunk("VP8X", vp8x),
+		riffChunk("EXIF", []byte("Exif\x00\x00secret-gps")),
+		riffChunk("VP8L", []byte{0x2f, 0x00, 0x00}),
+		riffChunk("XMP ", []byte("<x:xmpmeta/>")),
+	)
+	data = append(data, []byte("trailing")...)
+
+	f, err := Check(context.Background(), "pic.webp", data, Images)
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	for _, s := range []string{"EXIF", "secret-gps", "xmpmeta", "trailing"} {
+		if bytes.Contains(f.Data, []byte(s)) {
+			t.Errorf("Expected %q to be stripped", s)
+		}
+	}
+	if want := testWebP(riffChunk("VP8X", make([]byte, 10)), riffChunk("VP8L", []byte{0x2f, 0x00, 0x00})); !bytes.Equal(f.Data, want) {
+		t.Errorf("Unexpected sanitized WebP % x", f.Data)
+	}
+
+	if _, err := Check(context.Background(), "meta.webp", testWebP(riffChunk("VP8X", make([]byte, 10)), riffChunk("EXIF", []byte("x"))), Images); !errors.Is(err, ErrInvalidFile) {
+		t.Errorf("Expected WebP with no image data to be refused, got %v", err)
+	}
+}
+
+func uploadRequest(t *testing.T, field, filename string, data []byte) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	mw.WriteField("title", "Quiz night")
+	fw, _ := mw.CreateFormFile(field, filename)
+	fw.Write(data)
+	mw.Close()
+
+	r := httptest.NewRequest(http.MethodPost, "/api/content/upload", &body)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+	return r
+}
+
+func TestRead(t *testing.T) {
+	r := uploadRequest(t, "image", "poster.jpg", testPNG(t, 3, 3))
+	f, err := Read(httptest.NewRecorder(), r, "image", Images)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if f.Ext != ".png" || f.Name != "poster.jpg" {
+		t.Errorf("Expected sniffed .png for poster.jpg, got %+v", f)
+	}
+	if r.FormValue("title") != "Quiz night" {
+		t.Error("Expected other form fields to stay readable")
+	}
+
+	r = uploadRequest(t, "image", "poster.png", testPNG(t, 3, 3))
+	if _, err := Read(httptest.NewRecorder(), r, "file", Images); !errors.Is(err, ErrMissingFile) {
+		t.Errorf("Expected missing file, got %v", err)
+	}
+
+	r = uploadRequest(t, "avatar", "me.png", append(testPNG(t, 1, 1), make([]byte, 1<<20)...))
+	if _, err := Read(httptest.NewRecorder(), r, "avatar", Avatar); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Expected oversized body to be cut off, got %v", err)
+	}
+}
+
+// fakeClamd answers one INSTREAM request with reply
+func fakeClamd(t *testing.T, reply string) (addr string, received chan []byte) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("Cannot listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	received = make(chan []byte, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		cmd := make([]byte, len("zINSTREAM\x00"))
+		io.ReadFull(conn, cmd)
+		var data []byte
+		for {
+			var n uint32
+			if binary.Read(conn, binary.BigEndian, &n) != nil || n == 0 {
+				break
+			}
+			chunk := make([]byte, n)
+			io.ReadFull(conn, chunk)
+			data = append(data, chunk...)
+		}
+		received <- data
+		conn.Write([]byte(reply + "\x00"))
+	}()
+	return ln.Addr().String(), received
+}
+
+func TestClamAVScanner(t *testing.T) {
+	ctx := context.Background()
+	csv := []byte("text,answer\nA,B\n")
+
+	addr, received := fakeClamd(t, "stream: OK")
+	SetScanner(ClamAV{Addr: addr})
+	defer SetScanner(nil)
+	if _, err := Check(ctx, "q.csv", csv, Sheets); err != nil {
+		t.Errorf("Expected clean file to pass, got %v", err)
+	}
+	if got := <-received; !bytes.Equal(got, csv) {
+		t.Errorf("clamd received %q", got)
+	}
+
+	addr, _ = fakeClamd(t, "stream: Eicar-Test-Signature FOUND")
+	SetScanner(ClamAV{Addr: addr})
+	_, err := Check(ctx, "q.csv", csv, Sheets)
+	if !errors.Is(err, ErrInfected) || !strings.Contains(err.Error(), "Eicar-Test-Signature") {
+		t.Errorf("Expected infected, got %v", err)
+	}
+
+	// Nothing listening: refuse rather than store unscanned
+	ln, _ := net.Listen("tcp", "127.0.0.1:0")
+	closed := ln.Addr().String()
+	ln.Close()
+	SetScanner(ClamAV{Addr: closed})
+	if _, err := Check(ctx, "q.csv", csv, Sheets); !errors.Is(err, ErrScanUnavailable) || StatusCode(err) != http.StatusServiceUnavailable {
+		t.Errorf("Expected scan unavailable, got %v", err)
+	}
+}
+
+func TestFileServer(t *testing.T) {
+	dir := t.TempDir()
+	audio := bytes.Repeat([]byte{0xFF, 0xFB, 0x90, 0x64}, 256)
+	os.MkdirAll(filepath.Join(dir, "quiz", "audios"), 0755)
+	os.WriteFile(filepath.Join(dir, "quiz", "audios", "1-clip.mp3"), audio, 0644)
+	os.WriteFile(filepath.Join(dir, "photo.JPG"), []byte("<html><script>alert(1)</script>"), 0644)
+	os.WriteFile(filepath.Join(dir, "evil.html"), []byte("<script>alert(1)</script>"), 0644)
+	os.WriteFile(filepath.Join(dir, "evil.svg"), []byte("<svg onload=alert(1)>"), 0644)
+	srv := http.StripPrefix("/uploads/", FileServer(dir))
+
+	get := func(method, target string, header map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, nil)
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// The type comes from the extension, never the content
+	rec := get("GET", "/uploads/photo.JPG", nil)
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "image/jpeg" {
+		t.Errorf("photo: %d %q, want 200 image/jpeg", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	for header, want := range map[string]string{
+		"X-Content-Type-Options": "nosniff",
+		"Cache-Control":          CacheControl,
+	} {
+		if got := rec.Header().Get(header); got != want {
+			t.Errorf("%s = %q, want %q", header, got, want)
+		}
+	}
+	if !strings.Contains(rec.Header().Get("Content-Security-Policy"), "sandbox") {
+		t.Errorf("Content-Security-Policy = %q, want a sandbox", rec.Header().Get("Content-Security-Policy"))
+	}
+
+	// Nothing that could run, no listings, no escaping the directory
+	for _, target := range []string{"/uploads/evil.html", "/uploads/evil.svg", "/uploads/", "/uploads/quiz/", "/uploads/../upload.go"} {
+		if rec := get("GET", target, nil); rec.Code != http.StatusNotFound {
+			t.Errorf("GET %s = %d, want 404", target, rec.Code)
+		}
+	}
+	if rec := get("POST", "/uploads/photo.JPG", nil); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST = %d, want 405", rec.Code)
+	}
+
+	// Audio seeks with ranges
+	rec = get("GET", "/uploads/quiz/audios/1-clip.mp3", map[string]string{"Range": "bytes=100-199"})
+	if rec.Code != http.StatusPartialContent || rec.Body.Len() != 100 || !bytes.Equal(rec.Body.Bytes(), audio[100:200]) {
+		t.Errorf("range: %d, %d bytes; want 206 with 100 bytes", rec.Code, rec.Body.Len())
+	}
+	if rec.Header().Get("Content-Type") != "audio/mpeg" {
+		t.Errorf("range Content-Type = %q", rec.Header().Get("Content-Type"))
+	}
+
+	// A TV checking a file it has
+	etag := get("HEAD", "/uploads/quiz/audios/1-clip.mp3", nil).Header().Get("ETag")
+	if rec := get("GET", "/uploads/quiz/audios/1-clip.mp3", map[string]string{"If-None-Match": etag}); etag == "" || rec.Code != http.StatusNotModified {
+		t.Errorf("revalidation with ETag %q = %d, want 304", etag, rec.Code)
+	}
+}
+
+func TestServeFileKeepsCacheControl(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "answer.png"), testPNG(t, 2, 2), 0644)
+
+	rec := httptest.NewRecorder()
+	rec.Header().Set("Cache-Control", "private, max-age=3600")
+	ServeFile(rec, httptest.NewRequest("GET", "/api/sessions/1/photos/2", nil), filepath.Join(dir, "answer.png"))
+	if rec.Code != http.StatusOK || rec.Header().Get("Cache-Control") != "private, max-age=3600" {
+		t.Errorf("ServeFile: %d, Cache-Control %q; want 200 and the handler's own", rec.Code, rec.Header().Get("Cache-Control"))
+	}
+}
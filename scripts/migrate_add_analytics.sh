@@ -0,0 +1,34 @@
+#!/bin/bash
+# Migration: Add usage analytics
+# Purpose: Anonymous usage events (apps opened, game lengths, quiz turnout)
+#          from any frontend or backend, summed per day for the landlord's
+#          dashboard. Written by activity-hub-common analytics, so kept in the
+#          identity DB. No user is stored with an event.
+
+set -e
+
+DB_HOST="${DB_HOST:-127.0.0.1}"
+DB_PORT="${DB_PORT:-5555}"
+DB_USER="${DB_USER:-activityhub}"
+DB_NAME="activity_hub"
+
+echo "🔄 Running analytics migration on $DB_NAME..."
+
+psql -U "$DB_USER" -h "$DB_HOST" -p "$DB_PORT" -d "$DB_NAME" <<SQL
+
+-- One row per event; value is seconds for game_length, players for quiz_participation
+CREATE TABLE IF NOT EXISTS analytics_events (
+    id BIGSERIAL PRIMARY KEY,
+    event VARCHAR(50) NOT NULL,
+    app VARCHAR(50) NOT NULL,
+    value DOUBLE PRECISION NOT NULL DEFAULT 0 CHECK (value >= 0),
+    venue_id INTEGER REFERENCES venues(id) ON DELETE SET NULL,
+    occurred_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_analytics_events_occurred ON analytics_events(occurred_at);
+CREATE INDEX IF NOT EXISTS idx_analytics_events_venue ON analytics_events(venue_id, occurred_at);
+
+SQL
+
+echo "✅ Analytics migration completed successfully"
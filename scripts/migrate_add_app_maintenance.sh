@@ -0,0 +1,25 @@
+#!/bin/bash
+# Migration: Add per-app maintenance mode
+# Purpose: Let setup admins take one app offline with a message for players.
+#          The shell hides it from players; its backend answers them with 503
+#          (activity-hub-common maintenance). Admins can still use it.
+
+set -e
+
+DB_HOST="${DB_HOST:-127.0.0.1}"
+DB_PORT="${DB_PORT:-5555}"
+DB_USER="${DB_USER:-activityhub}"
+DB_NAME="activity_hub"
+
+echo "🔄 Running app maintenance migration on $DB_NAME..."
+
+psql -U "$DB_USER" -h "$DB_HOST" -p "$DB_PORT" -d "$DB_NAME" <<SQL
+
+ALTER TABLE applications ADD COLUMN IF NOT EXISTS maintenance BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE applications ADD COLUMN IF NOT EXISTS maintenance_message TEXT;
+ALTER TABLE applications ADD COLUMN IF NOT EXISTS maintenance_since TIMESTAMP;
+ALTER TABLE applications ADD COLUMN IF NOT EXISTS maintenance_by VARCHAR(255);
+
+SQL
+
+echo "✅ App maintenance migration completed successfully"
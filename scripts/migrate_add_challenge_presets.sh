@@ -0,0 +1,37 @@
+#!/bin/bash
+# Migration: Add challenge presets
+# Purpose: Let players save their usual challenge (game, options, opponents)
+#          and issue it from a lobby quick action
+
+set -e
+
+DB_HOST="${DB_HOST:-127.0.0.1}"
+DB_PORT="${DB_PORT:-5555}"
+DB_USER="${DB_USER:-activityhub}"
+DB_NAME="activity_hub"
+
+echo "🔄 Running challenge presets migration on $DB_NAME..."
+
+psql -U "$DB_USER" -h "$DB_HOST" -p "$DB_PORT" -d "$DB_NAME" <<SQL
+
+-- Opponents are emails, most preferred first
+CREATE TABLE IF NOT EXISTS challenge_presets (
+    id SERIAL PRIMARY KEY,
+    user_email VARCHAR(255) NOT NULL,
+    name VARCHAR(50) NOT NULL,
+    app_id VARCHAR(50) NOT NULL,
+    options JSONB NOT NULL DEFAULT '{}',
+    opponents TEXT[] NOT NULL DEFAULT '{}',
+    last_used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_email, name)
+);
+
+-- Merges and account deletion find presets naming a player
+CREATE INDEX IF NOT EXISTS idx_challenge_presets_opponents
+  ON challenge_presets USING GIN(opponents);
+
+SQL
+
+echo "✅ Challenge presets migration completed successfully"
@@ -0,0 +1,39 @@
+#!/bin/bash
+# Migration: Add per-environment CORS policy tables
+# Purpose: Backends allow cross-origin requests only from the platform's own
+#          pages (app registry ports on known hosts) instead of any origin.
+#          Setup admins manage the lists from identity-shell; backends reload
+#          them every 30 seconds, so no redeploy is needed.
+
+set -e
+
+DB_HOST="${DB_HOST:-127.0.0.1}"
+DB_PORT="${DB_PORT:-5555}"
+DB_USER="${DB_USER:-activityhub}"
+DB_NAME="activity_hub"
+
+echo "🔄 Running CORS policy migration on $DB_NAME..."
+
+psql -U "$DB_USER" -h "$DB_HOST" -p "$DB_PORT" -d "$DB_NAME" <<SQL
+
+-- Extra hostnames the platform is reached by (the request's own host is always allowed)
+-- Combined with every app registry port, e.g. pubgames.local -> http://pubgames.local:4001
+CREATE TABLE IF NOT EXISTS cors_hosts (
+    environment VARCHAR(50) NOT NULL,
+    host VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (environment, host)
+);
+
+-- Exact origins outside the registry pattern, e.g. https://signage.example.com
+CREATE TABLE IF NOT EXISTS cors_origins (
+    environment VARCHAR(50) NOT NULL,
+    origin VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (environment, origin)
+);
+
+SQL
+
+echo "✅ CORS policy migration completed successfully"
+echo "ℹ️  Set ACTIVITY_HUB_ENV on every backend if not running as 'production'"
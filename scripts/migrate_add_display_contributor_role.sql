@@ -0,0 +1,15 @@
+-- migrate_add_display_contributor_role.sql
+-- Documents the display_contributor role. No schema change needed — roles is TEXT[].
+-- Contributors can upload Display Admin content, which waits in a pending state until a
+-- display admin (is_admin) or setup_admin approves it. Display Admin creates its own
+-- approval columns and notifications table on startup.
+
+-- To grant display_contributor role to a user:
+-- UPDATE users SET roles = array_append(roles, 'display_contributor') WHERE email = 'user@example.com';
+
+-- To view users with display_contributor role:
+-- SELECT email, name, roles FROM users WHERE 'display_contributor' = ANY(roles);
+
+-- Verify role column exists:
+SELECT column_name, data_type FROM information_schema.columns
+WHERE table_schema = 'public' AND table_name = 'users' AND column_name = 'roles';
@@ -0,0 +1,34 @@
+#!/bin/bash
+# Migration: Add feature flags
+# Purpose: Roll risky features out gradually - to one venue or one role first -
+#          and switch them off again without a deploy. Flags are managed in
+#          setup-admin and evaluated by every backend (activity-hub-common/flags).
+
+set -e
+
+DB_HOST="${DB_HOST:-127.0.0.1}"
+DB_PORT="${DB_PORT:-5555}"
+DB_USER="${DB_USER:-activityhub}"
+DB_NAME="activity_hub"
+
+echo "🔄 Running feature flags migration on $DB_NAME..."
+
+psql -U "$DB_USER" -h "$DB_HOST" -p "$DB_PORT" -d "$DB_NAME" <<SQL
+
+-- A flag is on for a user when it is enabled and the user matches its targeting:
+-- their venue is in venue_ids (NULL or empty = every venue) and they have one of
+-- roles (NULL or empty = everyone)
+CREATE TABLE IF NOT EXISTS feature_flags (
+    key VARCHAR(100) PRIMARY KEY,
+    description TEXT,
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    venue_ids INTEGER[],
+    roles TEXT[],
+    updated_by VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+SQL
+
+echo "✅ Feature flags migration completed successfully"
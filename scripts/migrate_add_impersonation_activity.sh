@@ -0,0 +1,33 @@
+#!/bin/bash
+# Migration: Add global impersonation activity log
+# Purpose: Record every request made under an impersonation token, in any backend
+#          using the shared auth middleware, so super_user actions can be audited
+
+set -e
+
+DB_HOST="${DB_HOST:-127.0.0.1}"
+DB_PORT="${DB_PORT:-5555}"
+DB_USER="${DB_USER:-activityhub}"
+DB_NAME="activity_hub"
+
+echo "🔄 Running impersonation activity migration on $DB_NAME..."
+
+psql -U "$DB_USER" -h "$DB_HOST" -p "$DB_PORT" -d "$DB_NAME" <<SQL
+
+-- One row per impersonated request (written by activity-hub-common auth middleware)
+CREATE TABLE IF NOT EXISTS impersonation_activity (
+    id SERIAL PRIMARY KEY,
+    super_user_email VARCHAR(255) NOT NULL,
+    impersonated_email VARCHAR(255) NOT NULL,
+    service_host VARCHAR(255),
+    method VARCHAR(10) NOT NULL,
+    path TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_impersonation_activity_super ON impersonation_activity(super_user_email, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_impersonation_activity_created ON impersonation_activity(created_at DESC);
+
+SQL
+
+echo "✅ Impersonation activity migration completed successfully"
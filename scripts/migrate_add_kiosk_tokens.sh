@@ -0,0 +1,44 @@
+#!/bin/bash
+# Migration: Add kiosk tokens
+# Purpose: Long-lived tokens for unattended devices (leaderboard TVs, quiz
+#          displays, dice screens), issued by setup-admin. The shared auth
+#          middleware accepts each only on the endpoints it was issued for.
+
+set -e
+
+DB_HOST="${DB_HOST:-127.0.0.1}"
+DB_PORT="${DB_PORT:-5555}"
+DB_USER="${DB_USER:-activityhub}"
+DB_NAME="activity_hub"
+
+echo "🔄 Running kiosk tokens migration on $DB_NAME..."
+
+psql -U "$DB_USER" -h "$DB_HOST" -p "$DB_PORT" -d "$DB_NAME" <<SQL
+
+-- Only the SHA-256 of a token is kept; token_hint is its last 4 characters
+-- Endpoints are "METHOD /path" with an optional trailing * (activity-hub-common auth.KioskGrant)
+CREATE TABLE IF NOT EXISTS kiosk_tokens (
+    id SERIAL PRIMARY KEY,
+    label VARCHAR(100) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    token_hint VARCHAR(4) NOT NULL,
+    venue_id INTEGER,
+    capabilities TEXT[] NOT NULL DEFAULT '{}',
+    endpoints TEXT[] NOT NULL DEFAULT '{}',
+    created_by VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP,
+    last_used_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    revoked_by VARCHAR(255)
+);
+
+CREATE INDEX IF NOT EXISTS idx_kiosk_tokens_venue ON kiosk_tokens(venue_id);
+
+-- dice:room was first issued with GET /api/config only; the dice screen also calls GET /api/me
+UPDATE kiosk_tokens SET endpoints = array_append(endpoints, 'GET /api/me')
+WHERE 'dice:room' = ANY(capabilities) AND NOT 'GET /api/me' = ANY(endpoints);
+
+SQL
+
+echo "✅ Kiosk tokens migration completed successfully"
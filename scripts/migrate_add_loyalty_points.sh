@@ -0,0 +1,65 @@
+#!/bin/bash
+# Migration: Add loyalty points
+# Purpose: Players earn points for taking part (quiz attendance, LMS entry,
+#          challenges played) and landlords redeem them for rewards. Written by
+#          activity-hub-common points from any backend, so kept in the identity DB.
+
+set -e
+
+DB_HOST="${DB_HOST:-127.0.0.1}"
+DB_PORT="${DB_PORT:-5555}"
+DB_USER="${DB_USER:-activityhub}"
+DB_NAME="activity_hub"
+
+echo "🔄 Running loyalty points migration on $DB_NAME..."
+
+psql -U "$DB_USER" -h "$DB_HOST" -p "$DB_PORT" -d "$DB_NAME" <<SQL
+
+-- Points per activity (edited in game-admin; 0 = earns nothing)
+CREATE TABLE IF NOT EXISTS points_rules (
+    activity VARCHAR(50) PRIMARY KEY,
+    points INTEGER NOT NULL DEFAULT 0 CHECK (points >= 0),
+    description TEXT,
+    updated_by VARCHAR(255),
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO points_rules (activity, points, description) VALUES
+    ('quiz_attendance', 10, 'Played in a quiz'),
+    ('lms_entry', 5, 'Entered a Last Man Standing game'),
+    ('challenge_played', 2, 'Played a challenge from the lobby')
+ON CONFLICT (activity) DO NOTHING;
+
+-- One row per award; ref is the session, game or challenge the points were earned for
+CREATE TABLE IF NOT EXISTS points_ledger (
+    id SERIAL PRIMARY KEY,
+    user_email VARCHAR(255) NOT NULL,
+    activity VARCHAR(50) NOT NULL,
+    app VARCHAR(50) NOT NULL,
+    ref VARCHAR(255) NOT NULL,
+    venue_id INTEGER REFERENCES venues(id) ON DELETE SET NULL,
+    points INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_email, activity, ref)
+);
+
+CREATE INDEX IF NOT EXISTS idx_points_ledger_user ON points_ledger(user_email, created_at);
+CREATE INDEX IF NOT EXISTS idx_points_ledger_created ON points_ledger(created_at);
+
+-- Landlord's redemption log
+CREATE TABLE IF NOT EXISTS points_redemptions (
+    id SERIAL PRIMARY KEY,
+    user_email VARCHAR(255) NOT NULL,
+    points INTEGER NOT NULL CHECK (points > 0),
+    reward TEXT NOT NULL,
+    redeemed_by VARCHAR(255) NOT NULL,
+    venue_id INTEGER REFERENCES venues(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_points_redemptions_user ON points_redemptions(user_email, created_at);
+CREATE INDEX IF NOT EXISTS idx_points_redemptions_created ON points_redemptions(created_at);
+
+SQL
+
+echo "✅ Loyalty points migration completed successfully"
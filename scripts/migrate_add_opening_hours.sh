@@ -0,0 +1,41 @@
+#!/bin/bash
+# Migration: Add venue opening hours
+# Purpose: Let each venue say when it is open. Displays switch to their
+#          after-hours playlist when it closes, the lobby can refuse new
+#          challenges outside hours, and maintenance jobs wait for the quiet
+#          hours. Hours are managed in setup-admin and read by every backend
+#          through activity-hub-common/hours.
+
+set -e
+
+DB_HOST="${DB_HOST:-127.0.0.1}"
+DB_PORT="${DB_PORT:-5555}"
+DB_USER="${DB_USER:-activityhub}"
+DB_NAME="activity_hub"
+
+echo "🔄 Running opening hours migration on $DB_NAME..."
+
+psql -U "$DB_USER" -h "$DB_HOST" -p "$DB_PORT" -d "$DB_NAME" <<SQL
+
+-- One row per opening period. venue_id 0 holds the default hours for venues
+-- without their own. A period that closes at or before it opens runs past
+-- midnight, e.g. Friday 18:00-01:00. day_of_week: 0 = Sunday ... 6 = Saturday
+CREATE TABLE IF NOT EXISTS venue_hours (
+    venue_id INTEGER NOT NULL DEFAULT 0,
+    day_of_week SMALLINT NOT NULL CHECK (day_of_week BETWEEN 0 AND 6),
+    opens TIME NOT NULL,
+    closes TIME NOT NULL,
+    PRIMARY KEY (venue_id, day_of_week, opens)
+);
+
+-- lobby_closed: refuse new lobby challenges while the venue is closed
+CREATE TABLE IF NOT EXISTS venue_hours_settings (
+    venue_id INTEGER PRIMARY KEY,
+    lobby_closed BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_by VARCHAR(255),
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+SQL
+
+echo "✅ Opening hours migration completed successfully"
@@ -0,0 +1,34 @@
+-- Register pub-olympics app in the activity hub
+INSERT INTO applications (id, name, icon, type, category, description, url, backend_port, realtime, min_players, max_players, required_roles, enabled, display_order, guest_accessible)
+VALUES (
+  'pub-olympics',
+  'Pub Olympics',
+  '🏅',
+  'iframe',
+  'admin',
+  'Run a Pub Olympics across several games with a combined medal table',
+  'http://{host}:5090',
+  5090,
+  'none',
+  1,
+  999,
+  ARRAY['game_manager'],
+  true,
+  50,
+  false
+)
+ON CONFLICT (id) DO UPDATE SET
+  name = EXCLUDED.name,
+  icon = EXCLUDED.icon,
+  type = EXCLUDED.type,
+  category = EXCLUDED.category,
+  description = EXCLUDED.description,
+  url = EXCLUDED.url,
+  backend_port = EXCLUDED.backend_port,
+  realtime = EXCLUDED.realtime,
+  min_players = EXCLUDED.min_players,
+  max_players = EXCLUDED.max_players,
+  required_roles = EXCLUDED.required_roles,
+  enabled = EXCLUDED.enabled,
+  display_order = EXCLUDED.display_order,
+  guest_accessible = EXCLUDED.guest_accessible;
@@ -0,0 +1,15 @@
+-- migrate_add_question_contributor_role.sql
+-- Adds the question_contributor role. No schema change needed — roles is TEXT[].
+-- Contributors open Game Admin from the lobby and only see the question
+-- submission portal; everything else there still needs game_admin.
+
+-- Let contributors see the Game Admin tile (required_roles is any-of)
+UPDATE applications
+SET required_roles = array_append(required_roles, 'question_contributor')
+WHERE id = 'game-admin' AND NOT ('question_contributor' = ANY(required_roles));
+
+-- To grant question_contributor role to a user:
+-- UPDATE users SET roles = array_append(roles, 'question_contributor') WHERE email = 'user@example.com';
+
+-- To view users with question_contributor role:
+-- SELECT email, name, roles FROM users WHERE 'question_contributor' = ANY(roles);
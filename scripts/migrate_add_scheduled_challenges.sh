@@ -0,0 +1,39 @@
+#!/bin/bash
+# Migration: Add scheduled challenges
+# Purpose: Let players book a 2-player challenge for later ("a game at 8pm");
+#          the shell reminds both players and sends it at the booked time
+
+set -e
+
+DB_HOST="${DB_HOST:-127.0.0.1}"
+DB_PORT="${DB_PORT:-5555}"
+DB_USER="${DB_USER:-activityhub}"
+DB_NAME="activity_hub"
+
+echo "🔄 Running scheduled challenges migration on $DB_NAME..."
+
+psql -U "$DB_USER" -h "$DB_HOST" -p "$DB_PORT" -d "$DB_NAME" <<SQL
+
+-- status: scheduled, activated (sent as challenge_id), expired or cancelled
+CREATE TABLE IF NOT EXISTS scheduled_challenges (
+    id SERIAL PRIMARY KEY,
+    from_user VARCHAR(255) NOT NULL,
+    to_user VARCHAR(255) NOT NULL,
+    app_id VARCHAR(50) NOT NULL,
+    options JSONB NOT NULL DEFAULT '{}',
+    scheduled_at TIMESTAMPTZ NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'scheduled',
+    challenge_id VARCHAR(255),
+    reminded_at TIMESTAMPTZ,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- The shell polls for bookings that are due
+CREATE INDEX IF NOT EXISTS idx_scheduled_challenges_due
+  ON scheduled_challenges(scheduled_at) WHERE status = 'scheduled';
+CREATE INDEX IF NOT EXISTS idx_scheduled_challenges_from ON scheduled_challenges(from_user, scheduled_at);
+CREATE INDEX IF NOT EXISTS idx_scheduled_challenges_to ON scheduled_challenges(to_user, scheduled_at);
+
+SQL
+
+echo "✅ Scheduled challenges migration completed successfully"
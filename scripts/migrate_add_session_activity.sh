@@ -0,0 +1,33 @@
+#!/bin/bash
+# Migration: Add session activity tracking for the inactivity timeout
+# Purpose: identity-shell logs out sessions left idle (SESSION_IDLE_TIMEOUT_SECONDS).
+#          It records each session's last use and expiry in the shared identity
+#          database, so every backend's auth middleware refuses an expired token.
+
+set -e
+
+DB_HOST="${DB_HOST:-127.0.0.1}"
+DB_PORT="${DB_PORT:-5555}"
+DB_USER="${DB_USER:-activityhub}"
+DB_NAME="activity_hub"
+
+echo "🔄 Running session activity migration on $DB_NAME..."
+
+psql -U "$DB_USER" -h "$DB_HOST" -p "$DB_PORT" -d "$DB_NAME" <<SQL
+
+-- One row per tracked session, keyed by the token's SHA-256 (written by
+-- identity-shell, checked by activity-hub-common auth.ResolveToken)
+-- Rows untouched for 30 days are pruned
+CREATE TABLE IF NOT EXISTS session_activity (
+    token_hash VARCHAR(64) PRIMARY KEY,
+    user_email VARCHAR(255) NOT NULL,
+    last_seen_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    idle_timeout_seconds INTEGER NOT NULL,
+    expired_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_session_activity_last_seen ON session_activity(last_seen_at);
+
+SQL
+
+echo "✅ Session activity migration completed successfully"
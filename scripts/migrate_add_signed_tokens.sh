@@ -0,0 +1,33 @@
+#!/bin/bash
+# Migration: Add single-use tracking for signed stream/launch tokens
+# Purpose: Signed tokens minted by identity-shell replace session tokens in URLs
+#          (EventSource streams, mini-app hand-off). Each may be used once, on
+#          any backend, so uses are recorded in the shared identity database.
+
+set -e
+
+DB_HOST="${DB_HOST:-127.0.0.1}"
+DB_PORT="${DB_PORT:-5555}"
+DB_USER="${DB_USER:-activityhub}"
+DB_NAME="activity_hub"
+
+echo "🔄 Running signed token migration on $DB_NAME..."
+
+psql -U "$DB_USER" -h "$DB_HOST" -p "$DB_PORT" -d "$DB_NAME" <<SQL
+
+-- One row per consumed token (written by activity-hub-common auth.ConsumeSignedToken)
+-- Rows are pruned once the token has expired
+CREATE TABLE IF NOT EXISTS signed_token_uses (
+    token_id VARCHAR(64) PRIMARY KEY,
+    user_email VARCHAR(255) NOT NULL,
+    purpose VARCHAR(20) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_signed_token_uses_expires ON signed_token_uses(expires_at);
+
+SQL
+
+echo "✅ Signed token migration completed successfully"
+echo "ℹ️  Set the same AUTH_SIGNING_KEY for identity-shell and every app backend (required outside development)"
@@ -0,0 +1,44 @@
+#!/bin/bash
+# Migration: Add table devices
+# Purpose: Let staff sign a shared tablet in as a pub table, with lightweight
+#          player profiles that each play quizzes and games as themselves
+
+set -e
+
+DB_HOST="${DB_HOST:-127.0.0.1}"
+DB_PORT="${DB_PORT:-5555}"
+DB_USER="${DB_USER:-activityhub}"
+DB_NAME="activity_hub"
+
+echo "🔄 Running table devices migration on $DB_NAME..."
+
+psql -U "$DB_USER" -h "$DB_HOST" -p "$DB_PORT" -d "$DB_NAME" <<SQL
+
+-- The device session is table-token-{device_key}; signing out sets ended_at
+CREATE TABLE IF NOT EXISTS table_devices (
+    id SERIAL PRIMARY KEY,
+    device_key UUID NOT NULL UNIQUE,
+    name VARCHAR(50) NOT NULL,
+    venue_id INTEGER,
+    created_by VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    ended_at TIMESTAMP
+);
+
+-- Each player plays as table-player-{player_key}, so their answers and
+-- scores are kept apart from everyone else's at the table
+CREATE TABLE IF NOT EXISTS table_players (
+    id SERIAL PRIMARY KEY,
+    table_device_id INTEGER NOT NULL REFERENCES table_devices(id) ON DELETE CASCADE,
+    player_key UUID NOT NULL UNIQUE,
+    name VARCHAR(30) NOT NULL,
+    flair VARCHAR(16),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (table_device_id, name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_table_devices_created_by ON table_devices(created_by);
+
+SQL
+
+echo "✅ Table devices migration completed successfully"
@@ -0,0 +1,29 @@
+#!/bin/bash
+# Migration: Add user lifecycle columns
+# Purpose: Allow setup-admin to deactivate/reactivate accounts and track login code rotation
+
+set -e
+
+DB_HOST="${DB_HOST:-127.0.0.1}"
+DB_PORT="${DB_PORT:-5555}"
+DB_USER="${DB_USER:-activityhub}"
+DB_NAME="activity_hub"
+
+echo "🔄 Running user lifecycle migration on $DB_NAME..."
+
+psql -U "$DB_USER" -h "$DB_HOST" -p "$DB_PORT" -d "$DB_NAME" <<SQL
+
+-- Deactivated users cannot log in and their tokens stop validating
+ALTER TABLE users ADD COLUMN IF NOT EXISTS is_active BOOLEAN DEFAULT TRUE;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deactivated_at TIMESTAMP;
+
+-- Set whenever setup-admin issues a new login code
+ALTER TABLE users ADD COLUMN IF NOT EXISTS code_rotated_at TIMESTAMP;
+
+UPDATE users SET is_active = TRUE WHERE is_active IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_users_active ON users(is_active);
+
+SQL
+
+echo "✅ User lifecycle migration completed successfully"
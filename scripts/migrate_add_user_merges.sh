@@ -0,0 +1,34 @@
+#!/bin/bash
+# Migration: Add account merge log
+# Purpose: Record super_user merges of duplicate accounts, so game backends that
+#          missed the merge event can be sent it again
+
+set -e
+
+DB_HOST="${DB_HOST:-127.0.0.1}"
+DB_PORT="${DB_PORT:-5555}"
+DB_USER="${DB_USER:-activityhub}"
+DB_NAME="activity_hub"
+
+echo "🔄 Running account merge migration on $DB_NAME..."
+
+psql -U "$DB_USER" -h "$DB_HOST" -p "$DB_PORT" -d "$DB_NAME" <<SQL
+
+-- One row per merge; summary holds what was moved in the identity database
+CREATE TABLE IF NOT EXISTS user_merges (
+    id SERIAL PRIMARY KEY,
+    from_email VARCHAR(255) NOT NULL,
+    to_email VARCHAR(255) NOT NULL,
+    merged_by VARCHAR(255) NOT NULL,
+    reason TEXT,
+    summary JSONB NOT NULL DEFAULT '{}',
+    merged_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    published_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_merges_from ON user_merges(from_email);
+CREATE INDEX IF NOT EXISTS idx_user_merges_to ON user_merges(to_email);
+
+SQL
+
+echo "✅ Account merge migration completed successfully"
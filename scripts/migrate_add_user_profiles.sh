@@ -0,0 +1,35 @@
+#!/bin/bash
+# Migration: Add user profiles and avatar storage
+# Purpose: Let players pick a nickname, emoji flair and avatar so lobbies,
+#          leaderboards and quizzes no longer show raw email addresses
+
+set -e
+
+DB_HOST="${DB_HOST:-127.0.0.1}"
+DB_PORT="${DB_PORT:-5555}"
+DB_USER="${DB_USER:-activityhub}"
+DB_NAME="activity_hub"
+
+echo "🔄 Running user profiles migration on $DB_NAME..."
+
+psql -U "$DB_USER" -h "$DB_HOST" -p "$DB_PORT" -d "$DB_NAME" <<SQL
+
+-- Avatar images, keyed by SHA-256 of the content so identical uploads are stored once
+CREATE TABLE IF NOT EXISTS avatars (
+    hash VARCHAR(64) PRIMARY KEY,
+    content_type VARCHAR(50) NOT NULL,
+    data BYTEA NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS user_profiles (
+    user_email VARCHAR(255) PRIMARY KEY REFERENCES users(email) ON DELETE CASCADE,
+    nickname VARCHAR(30),
+    flair VARCHAR(16),
+    avatar_hash VARCHAR(64) REFERENCES avatars(hash) ON DELETE SET NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+SQL
+
+echo "✅ User profiles migration completed successfully"
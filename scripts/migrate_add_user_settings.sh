@@ -0,0 +1,35 @@
+#!/bin/bash
+# Migration: Add generic user settings store
+# Purpose: Sync theme, sound and notification settings (plus app-defined keys)
+#          across devices, with a per-user version used as the ETag for If-Match
+
+set -e
+
+DB_HOST="${DB_HOST:-127.0.0.1}"
+DB_PORT="${DB_PORT:-5555}"
+DB_USER="${DB_USER:-activityhub}"
+DB_NAME="activity_hub"
+
+echo "🔄 Running user settings migration on $DB_NAME..."
+
+psql -U "$DB_USER" -h "$DB_HOST" -p "$DB_PORT" -d "$DB_NAME" <<SQL
+
+-- One row per user/key; value is JSON so booleans, numbers and strings round-trip
+CREATE TABLE IF NOT EXISTS user_settings (
+    user_email VARCHAR(255) NOT NULL,
+    key VARCHAR(64) NOT NULL,
+    value JSONB NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_email, key)
+);
+
+-- Bumped on every preferences/settings write; exposed as the ETag
+CREATE TABLE IF NOT EXISTS user_preference_versions (
+    user_email VARCHAR(255) PRIMARY KEY,
+    version BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+SQL
+
+echo "✅ User settings migration completed successfully"
@@ -0,0 +1,35 @@
+#!/bin/bash
+# Migration: Add venues (multi-pub deployments)
+# Purpose: Let one deployment serve a small pub chain. Users, apps, displays and
+#          leaderboard results are scoped to a venue; NULL venue_id means chain-wide.
+
+set -e
+
+DB_HOST="${DB_HOST:-127.0.0.1}"
+DB_PORT="${DB_PORT:-5555}"
+DB_USER="${DB_USER:-activityhub}"
+DB_NAME="activity_hub"
+
+echo "🔄 Running venues migration on $DB_NAME..."
+
+psql -U "$DB_USER" -h "$DB_HOST" -p "$DB_PORT" -d "$DB_NAME" <<SQL
+
+CREATE TABLE IF NOT EXISTS venues (
+    id SERIAL PRIMARY KEY,
+    slug VARCHAR(50) UNIQUE NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    address TEXT,
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Home venue for each user (NULL = chain-wide staff, e.g. the owner)
+ALTER TABLE users ADD COLUMN IF NOT EXISTS venue_id INTEGER REFERENCES venues(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_users_venue ON users(venue_id);
+
+-- Venues an app is offered at (NULL or empty = all venues)
+ALTER TABLE applications ADD COLUMN IF NOT EXISTS venue_ids INTEGER[];
+
+SQL
+
+echo "✅ Venues migration completed successfully"
@@ -1,5 +1,5 @@
 #!/bin/bash
-# Start core services: identity-shell, setup-admin, game-admin, tic-tac-toe, dots, last-man-standing, lms-manager, sweepstakes, sweepstakes-knockout, quiz-player, quiz-master, quiz-display, mobile-test, component-library, leaderboard, rrroll-the-dice, sudoku, bulls-and-cows
+# Start core services: identity-shell, setup-admin, game-admin, tic-tac-toe, dots, last-man-standing, lms-manager, sweepstakes, sweepstakes-knockout, quiz-player, quiz-master, quiz-display, mobile-test, component-library, leaderboard, rrroll-the-dice, sudoku, bulls-and-cows, pub-olympics, operator-dashboard
 
 # Check if tmux session exists
 if tmux has-session -t core 2>/dev/null; then
@@ -84,6 +84,14 @@ tmux send-keys -t core:sudoku "cd ~/pub-games-v3/games/sudoku/backend && go run
 tmux new-window -t core -n bulls-and-cows
 tmux send-keys -t core:bulls-and-cows "cd ~/pub-games-v3/games/bulls-and-cows/backend && go run *.go" C-m
 
+# Pub Olympics (port 5090)
+tmux new-window -t core -n pub-olympics
+tmux send-keys -t core:pub-olympics "cd ~/pub-games-v3/games/pub-olympics/backend && go run *.go" C-m
+
+# Operator Dashboard (port 5100)
+tmux new-window -t core -n operator-dashboard
+tmux send-keys -t core:operator-dashboard "cd ~/pub-games-v3/games/operator-dashboard/backend && go run *.go" C-m
+
 echo "Core services starting in tmux session 'core'..."
 echo "Waiting for services to be ready..."
 echo ""
@@ -108,6 +116,8 @@ declare -A SERVICES=(
     ["rrroll-the-dice"]="4071"
     ["sudoku"]="4081"
     ["bulls-and-cows"]="4091"
+    ["pub-olympics"]="5090"
+    ["operator-dashboard"]="5100"
 )
 
 # Wait for services to start (max 30 seconds)
@@ -46,6 +46,7 @@ check_port 5081 "Quiz Display"
 check_port 4061 "Mobile Test"
 check_port 5010 "Component Library"
 check_port 5030 "Leaderboard"
+check_port 5090 "Pub Olympics"
 
 echo ""
 echo "Database Status:"
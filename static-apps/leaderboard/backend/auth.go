@@ -13,6 +13,13 @@ type AuthUser struct {
 	Email   string
 	Name    string
 	IsAdmin bool
+	VenueID int // 0 = chain-wide
+}
+
+// CanAccessVenue reports whether the user may see the venue's results.
+// Chain-wide users can see every venue.
+func (u *AuthUser) CanAccessVenue(venueID int) bool {
+	return u.VenueID == 0 || u.VenueID == venueID
 }
 
 // Context key for storing authenticated user
@@ -53,14 +60,7 @@ func AuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
 		email := strings.TrimPrefix(token, "demo-token-")
 		log.Printf("🔍 Validating token for user: %s", email)
 
-		// Query user from identity database
-		var user AuthUser
-		err := identityDB.QueryRow(`
-			SELECT email, name, is_admin
-			FROM users
-			WHERE email = $1
-		`, email).Scan(&user.Email, &user.Name, &user.IsAdmin)
-
+		user, err := lookupUser(email)
 		if err == sql.ErrNoRows {
 			log.Printf("❌ User not found in identity database: %s", email)
 			http.Error(w, "User not found", http.StatusUnauthorized)
@@ -79,6 +79,30 @@ func AuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+// lookupUser reads a user from the identity database
+func lookupUser(email string) (AuthUser, error) {
+	var user AuthUser
+	err := identityDB.QueryRow(`
+		SELECT email, name, is_admin, COALESCE(venue_id, 0)
+		FROM users
+		WHERE email = $1
+	`, email).Scan(&user.Email, &user.Name, &user.IsAdmin, &user.VenueID)
+	return user, err
+}
+
+// optionalUser returns the user a public request carries a token for, or nil
+func optionalUser(r *http.Request) *AuthUser {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !strings.HasPrefix(token, "demo-token-") {
+		return nil
+	}
+	user, err := lookupUser(strings.TrimPrefix(token, "demo-token-"))
+	if err != nil {
+		return nil
+	}
+	return &user
+}
+
 // getUserFromContext extracts authenticated user from request context
 func getUserFromContext(r *http.Request) *AuthUser {
 	user, ok := r.Context().Value(userContextKey).(AuthUser)
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Honour-reported results (a claimed win, a pool frame scored by one player)
+// count straight away but are marked provisional until the opponent confirms
+// them. If nobody confirms or disputes within the window the result is
+// confirmed automatically; a dispute stops the clock until an admin resolves
+// it in game-admin.
+
+// confirmWindow is how long the opponent has to confirm or dispute (RESULT_CONFIRM_WINDOW)
+var confirmWindow = loadConfirmWindow()
+
+// autoConfirmInterval is how often expired provisional results are confirmed
+const autoConfirmInterval = time.Minute
+
+func loadConfirmWindow() time.Duration {
+	window, err := time.ParseDuration(getEnv("RESULT_CONFIRM_WINDOW", "24h"))
+	if err != nil || window <= 0 {
+		log.Printf("⚠️ Invalid RESULT_CONFIRM_WINDOW, using 24h")
+		return 24 * time.Hour
+	}
+	return window
+}
+
+// newConfirmation returns the confirmation columns for a new result.
+// reportedBy is set only for self-reported results.
+func newConfirmation(reportedBy string) (string, *time.Time) {
+	if reportedBy == "" {
+		return "confirmed", nil
+	}
+	confirmBy := time.Now().Add(confirmWindow)
+	return "provisional", &confirmBy
+}
+
+// reportedByPlayer reports whether email played in the result being reported
+func reportedByPlayer(email, winnerID, loserID string, winnerTeam, loserTeam *TeamInput) bool {
+	if strings.EqualFold(email, winnerID) || strings.EqualFold(email, loserID) {
+		return true
+	}
+	for _, team := range []*TeamInput{winnerTeam, loserTeam} {
+		if team == nil {
+			continue
+		}
+		for _, p := range team.Players {
+			if strings.EqualFold(email, strings.TrimSpace(p.ID)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// opponentOfReporter matches results where $1 played on the other side from
+// reported_by (the other player, or a member of the other team)
+const opponentOfReporter = `(
+	(g.winner_id = $1 AND g.loser_id = g.reported_by) OR
+	(g.loser_id = $1 AND g.winner_id = g.reported_by) OR
+	EXISTS (
+		SELECT 1 FROM team_members me
+		JOIN team_members rep ON rep.player_id = g.reported_by
+		WHERE me.player_id = $1
+		  AND ((me.team_id = g.winner_team_id AND rep.team_id = g.loser_team_id) OR
+		       (me.team_id = g.loser_team_id AND rep.team_id = g.winner_team_id))))`
+
+// HandleGetPendingResults - GET /api/results/pending
+// Provisional results reported by the caller's opponents, waiting for the caller
+func HandleGetPendingResults(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	rows, err := db.Query(`
+		SELECT `+resultColumns+`
+		FROM game_results g
+		WHERE g.confirmation = 'provisional' AND NOT g.voided AND `+opponentOfReporter+`
+		ORDER BY g.confirm_by
+		LIMIT 50
+	`, user.Email)
+	if err != nil {
+		log.Printf("Failed to query pending results: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	results := scanResults(rows)
+	attachResultProfiles(results)
+	attachResultTeams(results)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
+}
+
+// HandleConfirmResult - POST /api/results/{gameId}/confirm
+// The opponent of a self-reported result agrees with it
+func HandleConfirmResult(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	gameID := mux.Vars(r)["gameId"]
+	res, err := db.Exec(`
+		UPDATE game_results g
+		SET confirmation = 'confirmed', confirmed_by = $1, confirmed_at = NOW()
+		WHERE g.game_id = $2 AND g.confirmation = 'provisional' AND `+opponentOfReporter,
+		user.Email, gameID)
+	if err != nil {
+		log.Printf("Failed to confirm result %s: %v", gameID, err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		http.Error(w, "No result waiting for your confirmation", http.StatusNotFound)
+		return
+	}
+
+	log.Printf("✅ Result %s confirmed by opponent", gameID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]bool{"success": true})
+}
+
+// markDisputed stops the confirmation clock on a provisional result
+func markDisputed(resultID int) {
+	if _, err := db.Exec(`
+		UPDATE game_results SET confirmation = 'disputed'
+		WHERE id = $1 AND confirmation = 'provisional'
+	`, resultID); err != nil {
+		log.Printf("Failed to mark result %d disputed: %v", resultID, err)
+	}
+}
+
+// autoConfirmResults confirms provisional results nobody answered in time
+func autoConfirmResults() {
+	res, err := db.Exec(`
+		UPDATE game_results SET confirmation = 'confirmed', confirmed_at = NOW()
+		WHERE confirmation = 'provisional' AND confirm_by <= NOW()
+	`)
+	if err != nil {
+		log.Printf("Failed to auto-confirm results: %v", err)
+		return
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		log.Printf("✅ Auto-confirmed %d provisional result(s)", n)
+	}
+}
+
+// startAutoConfirm runs autoConfirmResults in the background
+func startAutoConfirm() {
+	go func() {
+		ticker := time.NewTicker(autoConfirmInterval)
+		defer ticker.Stop()
+		for {
+			autoConfirmResults()
+			<-ticker.C
+		}
+	}()
+}
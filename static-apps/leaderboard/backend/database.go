@@ -91,6 +91,137 @@ func createTables(db *sql.DB) error {
 
 	-- Index for recent games
 	CREATE INDEX IF NOT EXISTS idx_game_results_played_at ON game_results(played_at DESC);
+
+	-- Venue the game was played at (NULL = chain-wide / unknown)
+	ALTER TABLE game_results ADD COLUMN IF NOT EXISTS venue_id INT;
+	CREATE INDEX IF NOT EXISTS idx_game_results_venue ON game_results(venue_id);
+
+	-- Teams for doubles/team games. team_key is a hash of the sorted member IDs,
+	-- so the same line-up always maps to the same team.
+	CREATE TABLE IF NOT EXISTS teams (
+		id SERIAL PRIMARY KEY,
+		team_key VARCHAR(64) NOT NULL UNIQUE,
+		name VARCHAR(100),
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS team_members (
+		team_id INT NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+		player_id VARCHAR(255) NOT NULL,
+		player_name VARCHAR(255),
+		PRIMARY KEY (team_id, player_id)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_team_members_player ON team_members(player_id);
+
+	-- Team results leave winner_id/loser_id NULL so individual standings are unaffected
+	ALTER TABLE game_results ADD COLUMN IF NOT EXISTS winner_team_id INT REFERENCES teams(id);
+	ALTER TABLE game_results ADD COLUMN IF NOT EXISTS loser_team_id INT REFERENCES teams(id);
+	CREATE INDEX IF NOT EXISTS idx_game_results_winner_team ON game_results(winner_team_id);
+	CREATE INDEX IF NOT EXISTS idx_game_results_loser_team ON game_results(loser_team_id);
+
+	-- Leagues segment standings (e.g. Tuesday pool league vs casual games).
+	-- Results not reported into a league belong to the default league for their
+	-- game type, whose slug is the game type itself.
+	CREATE TABLE IF NOT EXISTS leagues (
+		slug VARCHAR(64) PRIMARY KEY,
+		name VARCHAR(100) NOT NULL,
+		game_type VARCHAR(50) NOT NULL,
+		venue_id INT,
+		created_by VARCHAR(255),
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	ALTER TABLE game_results ADD COLUMN IF NOT EXISTS league VARCHAR(64);
+	UPDATE game_results SET league = game_type WHERE league IS NULL;
+	CREATE INDEX IF NOT EXISTS idx_game_results_league ON game_results(league);
+
+	-- Voided results stay for the record but are excluded from every standing.
+	-- Standings are always calculated from game_results, so a void or correction
+	-- takes effect on the next read.
+	ALTER TABLE game_results ADD COLUMN IF NOT EXISTS voided BOOLEAN DEFAULT FALSE;
+	ALTER TABLE game_results ADD COLUMN IF NOT EXISTS voided_reason TEXT;
+
+	-- Players flag results they believe are wrong; admins review them in game-admin
+	CREATE TABLE IF NOT EXISTS result_disputes (
+		id SERIAL PRIMARY KEY,
+		result_id INT NOT NULL REFERENCES game_results(id) ON DELETE CASCADE,
+		raised_by VARCHAR(255) NOT NULL,
+		reason TEXT NOT NULL,
+		status VARCHAR(20) NOT NULL DEFAULT 'open', -- open, upheld, rejected
+		resolution TEXT,
+		resolved_by VARCHAR(255),
+		resolved_at TIMESTAMP,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_result_disputes_status ON result_disputes(status);
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_result_disputes_open
+		ON result_disputes(result_id, raised_by) WHERE status = 'open';
+
+	-- History of admin corrections (before/after snapshots of the result row)
+	CREATE TABLE IF NOT EXISTS result_corrections (
+		id SERIAL PRIMARY KEY,
+		result_id INT NOT NULL REFERENCES game_results(id) ON DELETE CASCADE,
+		action VARCHAR(20) NOT NULL, -- void, restore, correct
+		before_state JSONB NOT NULL,
+		after_state JSONB NOT NULL,
+		reason TEXT,
+		corrected_by VARCHAR(255) NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_result_corrections_result ON result_corrections(result_id);
+
+	-- Honour-reported results (a claimed win, a pool frame scored by one player)
+	-- are provisional until the opponent confirms them or confirm_by passes.
+	-- A dispute stops the clock; the result stays disputed until an admin resolves it.
+	ALTER TABLE game_results ADD COLUMN IF NOT EXISTS confirmation VARCHAR(20) NOT NULL DEFAULT 'confirmed'; -- confirmed, provisional, disputed
+	ALTER TABLE game_results ADD COLUMN IF NOT EXISTS reported_by VARCHAR(255);
+	ALTER TABLE game_results ADD COLUMN IF NOT EXISTS confirm_by TIMESTAMP;
+	ALTER TABLE game_results ADD COLUMN IF NOT EXISTS confirmed_by VARCHAR(255);
+	ALTER TABLE game_results ADD COLUMN IF NOT EXISTS confirmed_at TIMESTAMP;
+	CREATE INDEX IF NOT EXISTS idx_game_results_provisional ON game_results(confirm_by) WHERE confirmation = 'provisional';
+
+	-- Cross-app game history: a compact record of every completed game, posted by
+	-- each game backend (lib history.Report) and shown on the player's profile page
+	CREATE TABLE IF NOT EXISTS game_history (
+		id SERIAL PRIMARY KEY,
+		app VARCHAR(50) NOT NULL,
+		game_id VARCHAR(100) NOT NULL,
+		players JSONB NOT NULL, -- [{"id": ..., "name": ..., "outcome": "win|loss|draw"}]
+		result VARCHAR(100),
+		duration INT DEFAULT 0,
+		options JSONB,
+		venue_id INT,
+		reported_by VARCHAR(255) NOT NULL,
+		completed_at TIMESTAMP NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE (app, game_id)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_game_history_players ON game_history USING GIN (players jsonb_path_ops);
+	CREATE INDEX IF NOT EXISTS idx_game_history_completed ON game_history(completed_at DESC);
+
+	-- Handicaps: what a head start means for a game type, and admins' per-player
+	-- handicaps. Players without one get the handicap suggested by their rating.
+	CREATE TABLE IF NOT EXISTS handicap_rules (
+		game_type VARCHAR(50) PRIMARY KEY,
+		kind VARCHAR(20) NOT NULL, -- start_score (darts: points off the start score), frames (pool: frames up)
+		step INT NOT NULL DEFAULT 1,
+		max_handicap INT NOT NULL,
+		updated_by VARCHAR(255),
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS player_handicaps (
+		player_id VARCHAR(255) NOT NULL,
+		game_type VARCHAR(50) NOT NULL,
+		handicap INT NOT NULL,
+		set_by VARCHAR(255),
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (player_id, game_type)
+	);
 	`
 
 	_, err := db.Exec(schema)
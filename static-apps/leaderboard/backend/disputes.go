@@ -0,0 +1,129 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/lib/pq"
+)
+
+// HandleDisputeResult - POST /api/results/{gameId}/dispute
+// Lets a player who took part in a game flag its result for admin review.
+// Review, void and correction happen in game-admin.
+func HandleDisputeResult(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" {
+		http.Error(w, "reason is required", http.StatusBadRequest)
+		return
+	}
+	if len(req.Reason) > 1000 {
+		http.Error(w, "reason too long", http.StatusBadRequest)
+		return
+	}
+
+	// Only participants (directly or as a team member) can dispute a result
+	var resultID int
+	err := db.QueryRow(`
+		SELECT g.id FROM game_results g
+		WHERE g.game_id = $1
+		  AND (g.winner_id = $2 OR g.loser_id = $2 OR EXISTS (
+		      SELECT 1 FROM team_members m
+		      WHERE m.player_id = $2 AND m.team_id IN (g.winner_team_id, g.loser_team_id)))
+	`, mux.Vars(r)["gameId"], user.Email).Scan(&resultID)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Result not found or you did not play in it", http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Printf("Failed to look up disputed result: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	var d Dispute
+	err = db.QueryRow(`
+		INSERT INTO result_disputes (result_id, raised_by, reason)
+		VALUES ($1, $2, $3)
+		RETURNING id, status, created_at
+	`, resultID, user.Email, req.Reason).Scan(&d.ID, &d.Status, &d.CreatedAt)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			http.Error(w, "You already have an open dispute for this result", http.StatusConflict)
+			return
+		}
+		log.Printf("Failed to save dispute: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	d.ResultID = resultID
+	d.GameID = mux.Vars(r)["gameId"]
+	d.Reason = req.Reason
+
+	// A disputed provisional result is no longer confirmed automatically
+	markDisputed(resultID)
+
+	log.Printf("⚠️ Result %s disputed by %s", d.GameID, user.Email)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(d)
+}
+
+// HandleGetMyDisputes - GET /api/disputes/mine
+// Returns the caller's disputes and how they were resolved
+func HandleGetMyDisputes(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	rows, err := db.Query(`
+		SELECT d.id, d.result_id, g.game_id, g.game_type, d.reason, d.status,
+		       COALESCE(d.resolution, ''), d.created_at, d.resolved_at
+		FROM result_disputes d
+		JOIN game_results g ON g.id = d.result_id
+		WHERE d.raised_by = $1
+		ORDER BY d.created_at DESC
+		LIMIT 50
+	`, user.Email)
+	if err != nil {
+		log.Printf("Failed to query disputes: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	disputes := []Dispute{}
+	for rows.Next() {
+		var d Dispute
+		var resolvedAt sql.NullTime
+		if err := rows.Scan(&d.ID, &d.ResultID, &d.GameID, &d.GameType, &d.Reason, &d.Status, &d.Resolution, &d.CreatedAt, &resolvedAt); err != nil {
+			continue
+		}
+		if resolvedAt.Valid {
+			t := resolvedAt.Time
+			d.ResolvedAt = &t
+		}
+		disputes = append(disputes, d)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(disputes)
+}
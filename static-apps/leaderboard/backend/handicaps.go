@@ -0,0 +1,382 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// Handicaps give weaker players a head start in the game types an admin has
+// set a rule for: points off a darts start score, frames up in a pool match.
+// Suggestions come from a rating replayed over the game type's confirmed
+// results, so a void or correction changes them on the next read. The
+// strongest established player plays off scratch and everyone else gets a
+// step per ratingPerStep below them. An admin's handicap for a player
+// replaces the suggestion until it's cleared.
+
+const (
+	initialRating = 1000.0
+	ratingK       = 32.0 // Rating moved by an upset
+	ratingPerStep = 50.0 // Rating gap worth one handicap step
+	minRatedGames = 5    // Results a player needs before a handicap is suggested
+
+	maxMatchPlayers = 20
+)
+
+var handicapKinds = map[string]bool{
+	"start_score": true, // Darts: points off the player's start score
+	"frames":      true, // Pool: frames the player starts the match up
+}
+
+// getHandicapRule loads a game type's rule (nil if it doesn't use handicaps)
+func getHandicapRule(gameType string) (*HandicapRule, error) {
+	rule := HandicapRule{GameType: gameType}
+	err := db.QueryRow(`
+		SELECT kind, step, max_handicap FROM handicap_rules WHERE game_type = $1
+	`, gameType).Scan(&rule.Kind, &rule.Step, &rule.MaxHandicap)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// rateGameType replays a game type's confirmed individual results in order,
+// Elo style, and returns each player's rating and number of results
+func rateGameType(gameType string) (map[string]*PlayerHandicap, error) {
+	rows, err := db.Query(`
+		SELECT winner_id, COALESCE(winner_name, ''), loser_id, COALESCE(loser_name, ''), is_draw
+		FROM game_results
+		WHERE game_type = $1 AND NOT voided AND confirmation = 'confirmed'
+		  AND COALESCE(winner_id, '') <> '' AND COALESCE(loser_id, '') <> ''
+		ORDER BY played_at, id
+	`, gameType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ratings := map[string]float64{}
+	players := map[string]*PlayerHandicap{}
+	player := func(id, name string) *PlayerHandicap {
+		p, ok := players[id]
+		if !ok {
+			p = &PlayerHandicap{PlayerID: id}
+			players[id] = p
+			ratings[id] = initialRating
+		}
+		if name != "" {
+			p.PlayerName = name
+		}
+		p.Games++
+		return p
+	}
+
+	for rows.Next() {
+		var winnerID, winnerName, loserID, loserName string
+		var isDraw bool
+		if err := rows.Scan(&winnerID, &winnerName, &loserID, &loserName, &isDraw); err != nil {
+			continue
+		}
+		winner, loser := player(winnerID, winnerName), player(loserID, loserName)
+
+		score := 1.0
+		if isDraw {
+			score = 0.5
+		}
+		expected := 1 / (1 + math.Pow(10, (ratings[loser.PlayerID]-ratings[winner.PlayerID])/400))
+		delta := ratingK * (score - expected)
+		ratings[winner.PlayerID] += delta
+		ratings[loser.PlayerID] -= delta
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for id, p := range players {
+		p.Rating = int(math.Round(ratings[id]))
+	}
+	return players, nil
+}
+
+// suggestHandicap is the rule's head start for a rating below the best one
+func suggestHandicap(rule *HandicapRule, rating, best int) int {
+	step := rule.Step
+	if step < 1 {
+		step = 1
+	}
+	h := int(math.Round(float64(best-rating)/ratingPerStep)) * step
+	if h < 0 {
+		return 0
+	}
+	if h > rule.MaxHandicap {
+		return rule.MaxHandicap
+	}
+	return h
+}
+
+// gameTypeHandicaps rates a game type's players and works out everyone's
+// handicap: the admin's if set, else the suggestion, else scratch
+func gameTypeHandicaps(rule *HandicapRule) (map[string]*PlayerHandicap, error) {
+	players, err := rateGameType(rule.GameType)
+	if err != nil {
+		return nil, err
+	}
+
+	best := math.MinInt
+	for _, p := range players {
+		if p.Games >= minRatedGames && p.Rating > best {
+			best = p.Rating
+		}
+	}
+	for _, p := range players {
+		if p.Games >= minRatedGames {
+			suggested := suggestHandicap(rule, p.Rating, best)
+			p.Suggested = &suggested
+			p.Handicap = suggested
+		}
+	}
+
+	rows, err := db.Query(`SELECT player_id, handicap FROM player_handicaps WHERE game_type = $1`, rule.GameType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id string
+		var handicap int
+		if err := rows.Scan(&id, &handicap); err != nil {
+			continue
+		}
+		p, ok := players[id]
+		if !ok {
+			p = &PlayerHandicap{PlayerID: id, Rating: initialRating}
+			players[id] = p
+		}
+		p.Handicap = handicap
+		p.Manual = true
+	}
+	return players, rows.Err()
+}
+
+// HandleGetHandicaps - GET /api/handicaps/{gameType}
+// The game type's rule and every rated player's handicap, strongest first.
+// 404 if the game type doesn't use handicaps.
+func HandleGetHandicaps(w http.ResponseWriter, r *http.Request) {
+	rule, err := getHandicapRule(mux.Vars(r)["gameType"])
+	if err != nil {
+		log.Printf("Failed to load handicap rule: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if rule == nil {
+		http.Error(w, "This game doesn't use handicaps", http.StatusNotFound)
+		return
+	}
+
+	players, err := gameTypeHandicaps(rule)
+	if err != nil {
+		log.Printf("Failed to work out handicaps for %s: %v", rule.GameType, err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	list := make([]PlayerHandicap, 0, len(players))
+	emails := make([]string, 0, len(players))
+	for _, p := range players {
+		list = append(list, *p)
+		emails = append(emails, p.PlayerID)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Rating != list[j].Rating {
+			return list[i].Rating > list[j].Rating
+		}
+		return list[i].PlayerID < list[j].PlayerID
+	})
+
+	// Public names, as on the standings
+	profiles := loadProfiles(emails)
+	for i := range list {
+		p, ok := profiles[list[i].PlayerID]
+		if !ok {
+			continue
+		}
+		if name := p.publicName(list[i].PlayerID); name != "" {
+			list[i].PlayerName = name
+		}
+		if p.Anonymous {
+			list[i].PlayerID = anonymousID(list[i].PlayerID)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"rule": rule, "players": list})
+}
+
+// HandleGetMatchHandicaps - GET /api/handicaps/{gameType}/match?players={email},{email}
+// The handicaps to set a match up with (lib services.FetchHandicaps). Kind is
+// empty for game types without handicaps.
+func HandleGetMatchHandicaps(w http.ResponseWriter, r *http.Request) {
+	gameType := mux.Vars(r)["gameType"]
+	emails := []string{}
+	for _, e := range strings.Split(r.URL.Query().Get("players"), ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			emails = append(emails, e)
+		}
+	}
+	if len(emails) == 0 || len(emails) > maxMatchPlayers {
+		http.Error(w, "players must list between 1 and 20 players", http.StatusBadRequest)
+		return
+	}
+
+	resp := struct {
+		GameType string         `json:"gameType"`
+		Kind     string         `json:"kind"`
+		Players  map[string]int `json:"players"`
+	}{GameType: gameType, Players: map[string]int{}}
+
+	rule, err := getHandicapRule(gameType)
+	if err != nil {
+		log.Printf("Failed to load handicap rule: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if rule != nil {
+		players, err := gameTypeHandicaps(rule)
+		if err != nil {
+			log.Printf("Failed to work out handicaps for %s: %v", gameType, err)
+			http.Error(w, "Database error", http.StatusInternalServerError)
+			return
+		}
+		resp.Kind = rule.Kind
+		for _, e := range emails {
+			resp.Players[e] = 0
+			if p, ok := players[e]; ok {
+				resp.Players[e] = p.Handicap
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// HandleSetHandicapRule - PUT/DELETE /api/handicaps/{gameType}
+// Admin only. PUT turns handicaps on for a game type (or changes what they
+// mean); DELETE turns them off, keeping players' handicaps for if they return.
+func HandleSetHandicapRule(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil || !user.IsAdmin {
+		http.Error(w, "Admin access required", http.StatusForbidden)
+		return
+	}
+	gameType := mux.Vars(r)["gameType"]
+
+	if r.Method == http.MethodDelete {
+		if _, err := db.Exec(`DELETE FROM handicap_rules WHERE game_type = $1`, gameType); err != nil {
+			log.Printf("Failed to delete handicap rule: %v", err)
+			http.Error(w, "Database error", http.StatusInternalServerError)
+			return
+		}
+		log.Printf("🗑️ Handicaps off for %s by %s", gameType, user.Email)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]bool{"success": true})
+		return
+	}
+
+	var rule HandicapRule
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	rule.GameType = gameType
+	if !handicapKinds[rule.Kind] {
+		http.Error(w, "kind must be start_score or frames", http.StatusBadRequest)
+		return
+	}
+	if rule.Step < 1 || rule.MaxHandicap < rule.Step || rule.MaxHandicap > 1000 {
+		http.Error(w, "step must be at least 1 and maxHandicap between step and 1000", http.StatusBadRequest)
+		return
+	}
+
+	_, err := db.Exec(`
+		INSERT INTO handicap_rules (game_type, kind, step, max_handicap, updated_by)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (game_type) DO UPDATE SET
+			kind = EXCLUDED.kind, step = EXCLUDED.step, max_handicap = EXCLUDED.max_handicap,
+			updated_by = EXCLUDED.updated_by, updated_at = CURRENT_TIMESTAMP
+	`, rule.GameType, rule.Kind, rule.Step, rule.MaxHandicap, user.Email)
+	if err != nil {
+		log.Printf("Failed to save handicap rule: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("✅ Handicaps for %s: %s, step %d, max %d (by %s)", rule.GameType, rule.Kind, rule.Step, rule.MaxHandicap, user.Email)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rule)
+}
+
+// HandleSetPlayerHandicap - PUT /api/handicaps/{gameType}/players/{playerId}
+// Admin only. {"handicap": n} fixes a player's handicap; {"handicap": null}
+// goes back to the suggestion.
+func HandleSetPlayerHandicap(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil || !user.IsAdmin {
+		http.Error(w, "Admin access required", http.StatusForbidden)
+		return
+	}
+	vars := mux.Vars(r)
+	gameType, playerID := vars["gameType"], vars["playerId"]
+
+	var req struct {
+		Handicap *int `json:"handicap"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	rule, err := getHandicapRule(gameType)
+	if err != nil {
+		log.Printf("Failed to load handicap rule: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if rule == nil {
+		http.Error(w, "This game doesn't use handicaps", http.StatusNotFound)
+		return
+	}
+
+	if req.Handicap == nil {
+		_, err = db.Exec(`DELETE FROM player_handicaps WHERE player_id = $1 AND game_type = $2`, playerID, gameType)
+	} else {
+		if *req.Handicap < 0 || *req.Handicap > rule.MaxHandicap {
+			http.Error(w, "handicap must be between 0 and the game's maxHandicap", http.StatusBadRequest)
+			return
+		}
+		_, err = db.Exec(`
+			INSERT INTO player_handicaps (player_id, game_type, handicap, set_by)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (player_id, game_type) DO UPDATE SET
+				handicap = EXCLUDED.handicap, set_by = EXCLUDED.set_by, updated_at = CURRENT_TIMESTAMP
+		`, playerID, gameType, *req.Handicap, user.Email)
+	}
+	if err != nil {
+		log.Printf("Failed to save player handicap: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "handicap": req.Handicap})
+}
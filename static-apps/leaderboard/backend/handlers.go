@@ -1,9 +1,11 @@
 package main
 
 import (
+	"database/sql"
 	"encoding/json"
 	"log"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gorilla/mux"
@@ -20,19 +22,55 @@ func HandleConfig(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(config)
 }
 
+// venueParam returns the ?venue= filter for read endpoints (0 = all venues).
+// The whole chain's boards are public; one venue's are for users who can
+// access that venue. On false the error has been written.
+func venueParam(w http.ResponseWriter, r *http.Request) (int, bool) {
+	v := r.URL.Query().Get("venue")
+	if v == "" {
+		return 0, true
+	}
+	venueID, err := strconv.Atoi(v)
+	if err != nil || venueID < 0 {
+		http.Error(w, "Invalid venue", http.StatusBadRequest)
+		return 0, false
+	}
+	if venueID == 0 {
+		return 0, true
+	}
+	user := optionalUser(r)
+	if user == nil {
+		http.Error(w, "Sign in to see a venue's results", http.StatusUnauthorized)
+		return 0, false
+	}
+	if !user.CanAccessVenue(venueID) {
+		http.Error(w, "Forbidden - not your venue", http.StatusForbidden)
+		return 0, false
+	}
+	return venueID, true
+}
+
 // HandleReportResult - POST /api/result
 // Called by games when a game ends
+// The result is recorded against the reporting player's venue
+// Team games (darts/pool doubles) send winnerTeam/loserTeam instead of winnerId/loserId
 func HandleReportResult(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		GameType   string `json:"gameType"`
-		GameID     string `json:"gameId"`
-		WinnerID   string `json:"winnerId"`
-		WinnerName string `json:"winnerName"`
-		LoserID    string `json:"loserId"`
-		LoserName  string `json:"loserName"`
-		IsDraw     bool   `json:"isDraw"`
-		Score      string `json:"score"`
-		Duration   int    `json:"duration"`
+		GameType   string     `json:"gameType"`
+		GameID     string     `json:"gameId"`
+		WinnerID   string     `json:"winnerId"`
+		WinnerName string     `json:"winnerName"`
+		LoserID    string     `json:"loserId"`
+		LoserName  string     `json:"loserName"`
+		WinnerTeam *TeamInput `json:"winnerTeam"`
+		LoserTeam  *TeamInput `json:"loserTeam"`
+		League     string     `json:"league"`
+		IsDraw     bool       `json:"isDraw"`
+		Score      string     `json:"score"`
+		Duration   int        `json:"duration"`
+
+		// Honour-reported (e.g. a claimed win): the opponent confirms or disputes it
+		SelfReported bool `json:"selfReported"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -46,6 +84,48 @@ func HandleReportResult(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	user := getUserFromContext(r)
+	var venueID interface{}
+	if user != nil && user.VenueID != 0 {
+		venueID = user.VenueID
+	}
+
+	// Self-reported results wait for the opponent, so the reporter must have played
+	var reportedBy string
+	if req.SelfReported {
+		if user == nil || !reportedByPlayer(user.Email, req.WinnerID, req.LoserID, req.WinnerTeam, req.LoserTeam) {
+			http.Error(w, "Self-reported results must be reported by one of the players", http.StatusForbidden)
+			return
+		}
+		reportedBy = user.Email
+	}
+
+	// Results go to the default league for the game type unless a league is named
+	league, err := resolveLeague(req.League, req.GameType, user)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Team result - tracked separately so individual standings are unaffected
+	if req.WinnerTeam != nil || req.LoserTeam != nil {
+		if err := validateTeams(req.WinnerTeam, req.LoserTeam); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := recordTeamResult(req.GameType, req.GameID, league, req.WinnerTeam, req.LoserTeam, req.IsDraw, req.Score, req.Duration, venueID, reportedBy); err != nil {
+			log.Printf("Failed to insert team result: %v", err)
+			http.Error(w, "Failed to save result", http.StatusInternalServerError)
+			return
+		}
+
+		log.Printf("📊 Recorded team result: %s game %s", req.GameType, req.GameID)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]bool{"success": true})
+		return
+	}
+
 	// For non-draw games, winner is required
 	if !req.IsDraw && req.WinnerID == "" {
 		http.Error(w, "winnerId required for non-draw games", http.StatusBadRequest)
@@ -53,11 +133,14 @@ func HandleReportResult(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Insert result
-	_, err := db.Exec(`
-		INSERT INTO game_results (game_type, game_id, winner_id, winner_name, loser_id, loser_name, is_draw, score, duration, played_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	confirmation, confirmBy := newConfirmation(reportedBy)
+	_, err = db.Exec(`
+		INSERT INTO game_results (game_type, game_id, winner_id, winner_name, loser_id, loser_name, is_draw, score, duration, played_at, venue_id, league,
+		                          confirmation, reported_by, confirm_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, NULLIF($14, ''), $15)
 		ON CONFLICT (game_id) DO NOTHING
-	`, req.GameType, req.GameID, req.WinnerID, req.WinnerName, req.LoserID, req.LoserName, req.IsDraw, req.Score, req.Duration, time.Now())
+	`, req.GameType, req.GameID, req.WinnerID, req.WinnerName, req.LoserID, req.LoserName, req.IsDraw, req.Score, req.Duration, time.Now(), venueID, league,
+		confirmation, reportedBy, confirmBy)
 
 	if err != nil {
 		log.Printf("Failed to insert game result: %v", err)
@@ -65,14 +148,14 @@ func HandleReportResult(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	log.Printf("📊 Recorded result: %s game %s - Winner: %s", req.GameType, req.GameID, req.WinnerName)
+	log.Printf("📊 Recorded %s result: %s game %s - Winner: %s", confirmation, req.GameType, req.GameID, req.WinnerName)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]bool{"success": true})
 }
 
-// HandleGetStandings - GET /api/standings/{gameType}
-// Returns leaderboard for a specific game type
+// HandleGetStandings - GET /api/standings/{gameType}?venue={id}&league={slug}
+// Returns leaderboard for a specific game type, optionally for a single venue or league
 func HandleGetStandings(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	gameType := vars["gameType"]
@@ -81,43 +164,61 @@ func HandleGetStandings(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "gameType is required", http.StatusBadRequest)
 		return
 	}
+	venueID, ok := venueParam(w, r)
+	if !ok {
+		return
+	}
 
+	standings, err := queryStandings(gameType, venueID, r.URL.Query().Get("league"))
+	if err != nil {
+		log.Printf("Failed to query standings: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(standings)
+}
+
+// queryStandings calculates individual standings for a game type.
+// venueID 0 = all venues, league "" = all leagues.
+func queryStandings(gameType string, venueID int, league string) ([]Standing, error) {
 	// Query to calculate standings
 	// Points: 3 for win, 1 for draw, 0 for loss
 	rows, err := db.Query(`
 		WITH player_stats AS (
 			-- Get wins
 			SELECT winner_id as player_id, winner_name as player_name,
-				   COUNT(*) as wins, 0 as losses, 0 as draws
+				   COUNT(*) as wins, 0 as losses, 0 as draws, COUNT(*) FILTER (WHERE confirmation <> 'confirmed') as unconfirmed
 			FROM game_results
-			WHERE game_type = $1 AND NOT is_draw AND winner_id IS NOT NULL
+			WHERE game_type = $1 AND NOT is_draw AND winner_id IS NOT NULL AND NOT voided AND ($2 = 0 OR venue_id = $2) AND ($3 = '' OR league = $3)
 			GROUP BY winner_id, winner_name
 
 			UNION ALL
 
 			-- Get losses
 			SELECT loser_id as player_id, loser_name as player_name,
-				   0 as wins, COUNT(*) as losses, 0 as draws
+				   0 as wins, COUNT(*) as losses, 0 as draws, COUNT(*) FILTER (WHERE confirmation <> 'confirmed') as unconfirmed
 			FROM game_results
-			WHERE game_type = $1 AND NOT is_draw AND loser_id IS NOT NULL
+			WHERE game_type = $1 AND NOT is_draw AND loser_id IS NOT NULL AND NOT voided AND ($2 = 0 OR venue_id = $2) AND ($3 = '' OR league = $3)
 			GROUP BY loser_id, loser_name
 
 			UNION ALL
 
 			-- Get draws (winner side)
 			SELECT winner_id as player_id, winner_name as player_name,
-				   0 as wins, 0 as losses, COUNT(*) as draws
+				   0 as wins, 0 as losses, COUNT(*) as draws, COUNT(*) FILTER (WHERE confirmation <> 'confirmed') as unconfirmed
 			FROM game_results
-			WHERE game_type = $1 AND is_draw AND winner_id IS NOT NULL
+			WHERE game_type = $1 AND is_draw AND winner_id IS NOT NULL AND NOT voided AND ($2 = 0 OR venue_id = $2) AND ($3 = '' OR league = $3)
 			GROUP BY winner_id, winner_name
 
 			UNION ALL
 
 			-- Get draws (loser side - in draws, both players are stored)
 			SELECT loser_id as player_id, loser_name as player_name,
-				   0 as wins, 0 as losses, COUNT(*) as draws
+				   0 as wins, 0 as losses, COUNT(*) as draws, COUNT(*) FILTER (WHERE confirmation <> 'confirmed') as unconfirmed
 			FROM game_results
-			WHERE game_type = $1 AND is_draw AND loser_id IS NOT NULL
+			WHERE game_type = $1 AND is_draw AND loser_id IS NOT NULL AND NOT voided AND ($2 = 0 OR venue_id = $2) AND ($3 = '' OR league = $3)
 			GROUP BY loser_id, loser_name
 		)
 		SELECT
@@ -127,18 +228,17 @@ func HandleGetStandings(w http.ResponseWriter, r *http.Request) {
 			SUM(losses) as losses,
 			SUM(draws) as draws,
 			SUM(wins) + SUM(losses) + SUM(draws) as total_games,
-			SUM(wins) * 3 + SUM(draws) as points
+			SUM(wins) * 3 + SUM(draws) as points,
+			SUM(unconfirmed) as unconfirmed
 		FROM player_stats
 		WHERE player_id IS NOT NULL AND player_id != ''
 		GROUP BY player_id
 		ORDER BY points DESC, wins DESC, total_games DESC
 		LIMIT 50
-	`, gameType)
+	`, gameType, venueID, league)
 
 	if err != nil {
-		log.Printf("Failed to query standings: %v", err)
-		http.Error(w, "Database error", http.StatusInternalServerError)
-		return
+		return nil, err
 	}
 	defer rows.Close()
 
@@ -147,7 +247,7 @@ func HandleGetStandings(w http.ResponseWriter, r *http.Request) {
 	for rows.Next() {
 		var s Standing
 		var totalGames, points int
-		err := rows.Scan(&s.PlayerID, &s.PlayerName, &s.Wins, &s.Losses, &s.Draws, &totalGames, &points)
+		err := rows.Scan(&s.PlayerID, &s.PlayerName, &s.Wins, &s.Losses, &s.Draws, &totalGames, &points, &s.Unconfirmed)
 		if err != nil {
 			continue
 		}
@@ -161,15 +261,24 @@ func HandleGetStandings(w http.ResponseWriter, r *http.Request) {
 		rank++
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(standings)
+	attachStandingProfiles(standings)
+	return standings, nil
 }
 
-// HandleGetAllStandings - GET /api/standings
+// HandleGetAllStandings - GET /api/standings?venue={id}
 // Returns standings for all game types
 func HandleGetAllStandings(w http.ResponseWriter, r *http.Request) {
+	venueID, ok := venueParam(w, r)
+	if !ok {
+		return
+	}
+
 	// Get list of game types
-	rows, err := db.Query(`SELECT DISTINCT game_type FROM game_results ORDER BY game_type`)
+	rows, err := db.Query(`
+		SELECT DISTINCT game_type FROM game_results
+		WHERE winner_team_id IS NULL AND NOT voided AND ($1 = 0 OR venue_id = $1)
+		ORDER BY game_type
+	`, venueID)
 	if err != nil {
 		http.Error(w, "Database error", http.StatusInternalServerError)
 		return
@@ -183,26 +292,51 @@ func HandleGetAllStandings(w http.ResponseWriter, r *http.Request) {
 		gameTypes = append(gameTypes, gt)
 	}
 
+	// Game types with team results (shown as a second table)
+	teamRows, err := db.Query(`
+		SELECT DISTINCT game_type FROM game_results
+		WHERE winner_team_id IS NOT NULL AND NOT voided AND ($1 = 0 OR venue_id = $1)
+		ORDER BY game_type
+	`, venueID)
+	if err != nil {
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer teamRows.Close()
+
+	teamGameTypes := []string{}
+	for teamRows.Next() {
+		var gt string
+		teamRows.Scan(&gt)
+		teamGameTypes = append(teamGameTypes, gt)
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"gameTypes": gameTypes,
+		"gameTypes":     gameTypes,
+		"teamGameTypes": teamGameTypes,
 	})
 }
 
-// HandleGetRecentGames - GET /api/recent/{gameType}
+// HandleGetRecentGames - GET /api/recent/{gameType}?venue={id}&league={slug}
 // Returns recent games for a game type
 func HandleGetRecentGames(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	gameType := vars["gameType"]
+	venueID, ok := venueParam(w, r)
+	if !ok {
+		return
+	}
 
 	query := `
-		SELECT id, game_type, game_id, winner_id, winner_name, loser_id, loser_name, is_draw, score, duration, played_at
+		SELECT ` + resultColumns + `
 		FROM game_results
+		WHERE NOT voided AND ($1 = 0 OR venue_id = $1) AND ($2 = '' OR league = $2)
 	`
-	args := []interface{}{}
+	args := []interface{}{venueID, r.URL.Query().Get("league")}
 
 	if gameType != "" && gameType != "all" {
-		query += " WHERE game_type = $1"
+		query += " AND game_type = $3"
 		args = append(args, gameType)
 	}
 
@@ -216,11 +350,27 @@ func HandleGetRecentGames(w http.ResponseWriter, r *http.Request) {
 	}
 	defer rows.Close()
 
+	results := scanResults(rows)
+
+	attachResultProfiles(results)
+	attachResultTeams(results)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
+}
+
+// resultColumns are the game_results columns scanResults reads
+const resultColumns = `id, game_type, game_id, winner_id, winner_name, loser_id, loser_name, is_draw, score, duration, COALESCE(venue_id, 0), played_at,
+		       COALESCE(winner_team_id, 0), COALESCE(loser_team_id, 0), COALESCE(league, ''), confirmation, confirm_by`
+
+// scanResults reads rows selected with resultColumns
+func scanResults(rows *sql.Rows) []GameResult {
 	results := []GameResult{}
 	for rows.Next() {
 		var r GameResult
 		var winnerID, winnerName, loserID, loserName, score *string
-		err := rows.Scan(&r.ID, &r.GameType, &r.GameID, &winnerID, &winnerName, &loserID, &loserName, &r.IsDraw, &score, &r.Duration, &r.PlayedAt)
+		err := rows.Scan(&r.ID, &r.GameType, &r.GameID, &winnerID, &winnerName, &loserID, &loserName, &r.IsDraw, &score, &r.Duration, &r.VenueID, &r.PlayedAt,
+			&r.WinnerTeamID, &r.LoserTeamID, &r.League, &r.Confirmation, &r.ConfirmBy)
 		if err != nil {
 			continue
 		}
@@ -241,9 +391,7 @@ func HandleGetRecentGames(w http.ResponseWriter, r *http.Request) {
 		}
 		results = append(results, r)
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(results)
+	return results
 }
 
 // HandleGetPlayerStats - GET /api/player/{playerId}
@@ -257,13 +405,20 @@ func HandleGetPlayerStats(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Anonymous players' stats are not looked up by email on the public board
+	if p, ok := loadProfiles([]string{playerID})[playerID]; ok && p.Anonymous {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode([]PlayerStats{})
+		return
+	}
+
 	rows, err := db.Query(`
 		SELECT game_type,
 			   SUM(CASE WHEN winner_id = $1 AND NOT is_draw THEN 1 ELSE 0 END) as wins,
 			   SUM(CASE WHEN loser_id = $1 AND NOT is_draw THEN 1 ELSE 0 END) as losses,
 			   SUM(CASE WHEN (winner_id = $1 OR loser_id = $1) AND is_draw THEN 1 ELSE 0 END) as draws
 		FROM game_results
-		WHERE winner_id = $1 OR loser_id = $1
+		WHERE (winner_id = $1 OR loser_id = $1) AND NOT voided
 		GROUP BY game_type
 	`, playerID)
 
@@ -291,3 +446,67 @@ func HandleGetPlayerStats(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(stats)
 }
+
+// HandleAnonymisePlayer - DELETE /api/player/me
+// Called by identity-shell when a user deletes their account.
+// Results are kept (they belong to opponents too) but the player's identity is removed.
+func HandleAnonymisePlayer(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	anonID := anonymousID(user.Email)
+
+	tx, err := db.Begin()
+	if err != nil {
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`UPDATE game_results SET winner_id = $1, winner_name = 'Deleted player' WHERE winner_id = $2`, anonID, user.Email); err != nil {
+		log.Printf("Failed to anonymise winner rows: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if _, err := tx.Exec(`UPDATE game_results SET loser_id = $1, loser_name = 'Deleted player' WHERE loser_id = $2`, anonID, user.Email); err != nil {
+		log.Printf("Failed to anonymise loser rows: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if _, err := tx.Exec(`UPDATE team_members SET player_id = $1, player_name = 'Deleted player' WHERE player_id = $2`, anonID, user.Email); err != nil {
+		log.Printf("Failed to anonymise team memberships: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if _, err := tx.Exec(`DELETE FROM player_handicaps WHERE player_id = $1`, user.Email); err != nil {
+		log.Printf("Failed to delete handicaps: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if _, err := tx.Exec(`
+		UPDATE game_history SET players = (
+			SELECT jsonb_agg(CASE WHEN p->>'id' = $2
+				THEN p || jsonb_build_object('id', $1::text, 'name', 'Deleted player')
+				ELSE p END)
+			FROM jsonb_array_elements(players) p
+		)
+		WHERE players @> jsonb_build_array(jsonb_build_object('id', $2::text))
+	`, anonID, user.Email); err != nil {
+		log.Printf("Failed to anonymise game history: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("🗑️ Anonymised leaderboard history for deleted account")
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]bool{"success": true})
+}
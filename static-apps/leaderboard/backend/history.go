@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const maxHistoryPlayers = 20
+
+// HandleRecordHistory - POST /api/history
+// Called by game backends when a game completes (see lib history.Report).
+// Like results, the record must be posted with the token of one of its players.
+func HandleRecordHistory(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req HistoryRecord
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.App == "" || req.GameID == "" || len(req.Players) == 0 {
+		http.Error(w, "app, gameId and players are required", http.StatusBadRequest)
+		return
+	}
+	if len(req.Players) > maxHistoryPlayers {
+		http.Error(w, "Too many players", http.StatusBadRequest)
+		return
+	}
+
+	isPlayer := false
+	for _, p := range req.Players {
+		if p.ID == "" {
+			http.Error(w, "Every player needs an id", http.StatusBadRequest)
+			return
+		}
+		switch p.Outcome {
+		case "", "win", "loss", "draw":
+		default:
+			http.Error(w, "outcome must be win, loss or draw", http.StatusBadRequest)
+			return
+		}
+		if strings.EqualFold(p.ID, user.Email) {
+			isPlayer = true
+		}
+	}
+	if !isPlayer {
+		http.Error(w, "Only a player in the game can record it", http.StatusForbidden)
+		return
+	}
+
+	if req.CompletedAt.IsZero() {
+		req.CompletedAt = time.Now()
+	}
+
+	players, _ := json.Marshal(req.Players)
+	var options interface{}
+	if len(req.Options) > 0 {
+		b, _ := json.Marshal(req.Options)
+		options = string(b)
+	}
+	var venueID interface{}
+	if user.VenueID != 0 {
+		venueID = user.VenueID
+	}
+
+	_, err := db.Exec(`
+		INSERT INTO game_history (app, game_id, players, result, duration, options, venue_id, reported_by, completed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (app, game_id) DO NOTHING
+	`, req.App, req.GameID, string(players), req.Result, req.Duration, options, venueID, user.Email, req.CompletedAt)
+	if err != nil {
+		log.Printf("Failed to record game history: %v", err)
+		http.Error(w, "Failed to save history", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("📜 Recorded history: %s game %s", req.App, req.GameID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]bool{"success": true})
+}
+
+// HandleGetMyHistory - GET /api/history/me?app={app}&limit={n}&before={RFC3339}
+// The caller's completed games across every app, newest first (used by the shell profile page)
+func HandleGetMyHistory(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	before := time.Now().Add(time.Minute)
+	if t, err := time.Parse(time.RFC3339, r.URL.Query().Get("before")); err == nil {
+		before = t
+	}
+
+	me, _ := json.Marshal([]map[string]string{{"id": user.Email}})
+	rows, err := db.Query(`
+		SELECT id, app, game_id, players::text, COALESCE(result, ''), duration, COALESCE(options::text, ''), completed_at
+		FROM game_history
+		WHERE players @> $1::jsonb AND ($2 = '' OR app = $2) AND completed_at < $3
+		ORDER BY completed_at DESC
+		LIMIT $4
+	`, string(me), r.URL.Query().Get("app"), before, limit)
+	if err != nil {
+		log.Printf("Failed to query game history: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	records := []HistoryRecord{}
+	opponents := []string{}
+	for rows.Next() {
+		var rec HistoryRecord
+		var players, options string
+		if err := rows.Scan(&rec.ID, &rec.App, &rec.GameID, &players, &rec.Result, &rec.Duration, &options, &rec.CompletedAt); err != nil {
+			continue
+		}
+		json.Unmarshal([]byte(players), &rec.Players)
+		if options != "" {
+			json.Unmarshal([]byte(options), &rec.Options)
+		}
+		for _, p := range rec.Players {
+			if p.ID == user.Email {
+				rec.Outcome = p.Outcome
+			} else {
+				opponents = append(opponents, p.ID)
+			}
+		}
+		records = append(records, rec)
+	}
+
+	// Opponents are shown by their public names (nicknames/aliases), like standings
+	profiles := loadProfiles(opponents)
+	for i := range records {
+		for j := range records[i].Players {
+			p := &records[i].Players[j]
+			if p.ID == user.Email {
+				continue
+			}
+			if profile, ok := profiles[p.ID]; ok {
+				if name := profile.publicName(p.ID); name != "" {
+					p.Name = name
+				}
+				if profile.Anonymous {
+					p.ID = anonymousID(p.ID)
+				}
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(records)
+}
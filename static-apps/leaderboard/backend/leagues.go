@@ -0,0 +1,278 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/lib/pq"
+)
+
+var nonSlugChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slugify turns a league name into a URL-safe slug ("Tuesday Pool League" -> "tuesday-pool-league")
+func slugify(name string) string {
+	slug := strings.Trim(nonSlugChars.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if len(slug) > 64 {
+		slug = strings.TrimRight(slug[:64], "-")
+	}
+	return slug
+}
+
+// getLeague loads a league by slug (nil if it doesn't exist)
+func getLeague(slug string) (*League, error) {
+	var l League
+	err := db.QueryRow(`
+		SELECT l.slug, l.name, l.game_type, COALESCE(l.venue_id, 0), COALESCE(l.created_by, ''), l.created_at,
+		       (SELECT COUNT(*) FROM game_results g WHERE g.league = l.slug AND NOT g.voided)
+		FROM leagues l
+		WHERE l.slug = $1
+	`, slug).Scan(&l.Slug, &l.Name, &l.GameType, &l.VenueID, &l.CreatedBy, &l.CreatedAt, &l.Games)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+// resolveLeague picks the league a reported result belongs to.
+// An empty league means the default league for the game type.
+func resolveLeague(slug, gameType string, user *AuthUser) (string, error) {
+	if slug == "" || slug == gameType {
+		return gameType, nil
+	}
+
+	league, err := getLeague(slug)
+	if err != nil {
+		return "", err
+	}
+	if league == nil {
+		return "", fmt.Errorf("unknown league %s", slug)
+	}
+	if league.GameType != gameType {
+		return "", fmt.Errorf("league %s is for %s games", slug, league.GameType)
+	}
+	if league.VenueID != 0 && user != nil && user.VenueID != 0 && user.VenueID != league.VenueID {
+		return "", fmt.Errorf("league %s belongs to another venue", slug)
+	}
+	return league.Slug, nil
+}
+
+// canManageLeague - admins manage leagues; venue admins only their own venue's
+func canManageLeague(user *AuthUser, league *League) bool {
+	if user == nil || !user.IsAdmin {
+		return false
+	}
+	return user.VenueID == 0 || league.VenueID == user.VenueID
+}
+
+// HandleListLeagues - GET /api/leagues?venue={id}&gameType={type}
+// Lists leagues with how many results each holds
+func HandleListLeagues(w http.ResponseWriter, r *http.Request) {
+	venueID, ok := venueParam(w, r)
+	if !ok {
+		return
+	}
+
+	rows, err := db.Query(`
+		SELECT l.slug, l.name, l.game_type, COALESCE(l.venue_id, 0), COALESCE(l.created_by, ''), l.created_at,
+		       (SELECT COUNT(*) FROM game_results g WHERE g.league = l.slug AND NOT g.voided)
+		FROM leagues l
+		WHERE ($1 = 0 OR l.venue_id = $1) AND ($2 = '' OR l.game_type = $2)
+		ORDER BY l.game_type, l.name
+	`, venueID, r.URL.Query().Get("gameType"))
+	if err != nil {
+		log.Printf("Failed to query leagues: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	leagues := []League{}
+	for rows.Next() {
+		var l League
+		if err := rows.Scan(&l.Slug, &l.Name, &l.GameType, &l.VenueID, &l.CreatedBy, &l.CreatedAt, &l.Games); err != nil {
+			continue
+		}
+		leagues = append(leagues, l)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(leagues)
+}
+
+// HandleCreateLeague - POST /api/leagues
+// Admin only. Venue admins' leagues are always tied to their venue.
+func HandleCreateLeague(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil || !user.IsAdmin {
+		http.Error(w, "Admin access required", http.StatusForbidden)
+		return
+	}
+
+	var req struct {
+		Name     string `json:"name"`
+		Slug     string `json:"slug"`
+		GameType string `json:"gameType"`
+		VenueID  int    `json:"venueId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || req.GameType == "" {
+		http.Error(w, "name and gameType are required", http.StatusBadRequest)
+		return
+	}
+	if len(req.Name) > 100 {
+		http.Error(w, "name too long", http.StatusBadRequest)
+		return
+	}
+
+	slug := slugify(req.Slug)
+	if slug == "" {
+		slug = slugify(req.Name)
+	}
+	if slug == "" {
+		http.Error(w, "League name must contain letters or numbers", http.StatusBadRequest)
+		return
+	}
+	if slug == req.GameType {
+		http.Error(w, "Slug is reserved for casual games", http.StatusBadRequest)
+		return
+	}
+
+	venueID := req.VenueID
+	if user.VenueID != 0 {
+		venueID = user.VenueID
+	}
+	var venue interface{}
+	if venueID != 0 {
+		venue = venueID
+	}
+
+	_, err := db.Exec(`
+		INSERT INTO leagues (slug, name, game_type, venue_id, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+	`, slug, req.Name, req.GameType, venue, user.Email)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			http.Error(w, "A league with that slug already exists", http.StatusConflict)
+			return
+		}
+		log.Printf("Failed to create league: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("🏆 League created: %s (%s) by %s", slug, req.GameType, user.Email)
+
+	league, err := getLeague(slug)
+	if err != nil || league == nil {
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(league)
+}
+
+// HandleAssignLeagueGames - POST/DELETE /api/leagues/{slug}/games
+// POST moves results into the league, DELETE moves them back to casual.
+// Body: {"gameIds": ["..."]}. Only results of the league's game type are moved.
+func HandleAssignLeagueGames(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+
+	league, err := getLeague(mux.Vars(r)["slug"])
+	if err != nil {
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if league == nil {
+		http.Error(w, "League not found", http.StatusNotFound)
+		return
+	}
+	if !canManageLeague(user, league) {
+		http.Error(w, "Admin access required", http.StatusForbidden)
+		return
+	}
+
+	var req struct {
+		GameIDs []string `json:"gameIds"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.GameIDs) == 0 {
+		http.Error(w, "gameIds are required", http.StatusBadRequest)
+		return
+	}
+
+	var res sql.Result
+	if r.Method == http.MethodDelete {
+		res, err = db.Exec(`
+			UPDATE game_results SET league = game_type
+			WHERE game_id = ANY($1) AND league = $2
+		`, pq.Array(req.GameIDs), league.Slug)
+	} else {
+		res, err = db.Exec(`
+			UPDATE game_results SET league = $1
+			WHERE game_id = ANY($2) AND game_type = $3 AND ($4 = 0 OR venue_id = $4)
+		`, league.Slug, pq.Array(req.GameIDs), league.GameType, league.VenueID)
+	}
+	if err != nil {
+		log.Printf("Failed to assign games to league: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	updated, _ := res.RowsAffected()
+	log.Printf("🏆 League %s: %s %d game(s)", league.Slug, strings.ToLower(r.Method), updated)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"updated": updated,
+	})
+}
+
+// HandleGetLeagueStandings - GET /api/leagues/{slug}/standings
+// Individual and team standings for one league only
+func HandleGetLeagueStandings(w http.ResponseWriter, r *http.Request) {
+	league, err := getLeague(mux.Vars(r)["slug"])
+	if err != nil {
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if league == nil {
+		http.Error(w, "League not found", http.StatusNotFound)
+		return
+	}
+
+	standings, err := queryStandings(league.GameType, 0, league.Slug)
+	if err != nil {
+		log.Printf("Failed to query league standings: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	teamStandings, err := queryTeamStandings(league.GameType, 0, league.Slug)
+	if err != nil {
+		log.Printf("Failed to query league team standings: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"league":        league,
+		"standings":     standings,
+		"teamStandings": teamStandings,
+	})
+}
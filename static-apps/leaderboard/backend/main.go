@@ -6,7 +6,8 @@ import (
 	"net/http"
 	"os"
 
-	"github.com/gorilla/handlers"
+	apphttp "github.com/achgithub/activity-hub-common/http"
+	"github.com/achgithub/activity-hub-common/maintenance"
 	"github.com/gorilla/mux"
 )
 
@@ -38,6 +39,9 @@ func main() {
 	defer identityDB.Close()
 	log.Println("✅ Connected to identity database")
 
+	// Confirm provisional results whose window has passed
+	startAutoConfirm()
+
 	// Setup router
 	r := mux.NewRouter()
 
@@ -56,26 +60,57 @@ func main() {
 	// Player stats (public)
 	r.HandleFunc("/api/player/{playerId}", HandleGetPlayerStats).Methods("GET")
 
+	// Team standings and line-ups for doubles games (public)
+	r.HandleFunc("/api/teams/standings/{gameType}", HandleGetTeamStandings).Methods("GET")
+	r.HandleFunc("/api/teams/{teamId:[0-9]+}", HandleGetTeam).Methods("GET")
+
+	// Leagues - separate standings for organised leagues vs casual games
+	r.HandleFunc("/api/leagues", HandleListLeagues).Methods("GET")
+	r.HandleFunc("/api/leagues/{slug}/standings", HandleGetLeagueStandings).Methods("GET")
+
+	// Handicaps per player and game type (public; a match's are added to lobby challenges)
+	r.HandleFunc("/api/handicaps/{gameType}", HandleGetHandicaps).Methods("GET")
+	r.HandleFunc("/api/handicaps/{gameType}/match", HandleGetMatchHandicaps).Methods("GET")
+
 	// Result reporting (authentication required - prevents fake results)
 	// Games report results using a player's token to prove legitimacy
 	r.HandleFunc("/api/result", AuthMiddleware(HandleReportResult)).Methods("POST")
 
+	// Account deletion (called by identity-shell with the departing user's token)
+	r.HandleFunc("/api/player/me", AuthMiddleware(HandleAnonymisePlayer)).Methods("DELETE")
+
+	// Result disputes (participants flag results; admins resolve them in game-admin)
+	r.HandleFunc("/api/results/{gameId}/dispute", AuthMiddleware(HandleDisputeResult)).Methods("POST")
+	r.HandleFunc("/api/disputes/mine", AuthMiddleware(HandleGetMyDisputes)).Methods("GET")
+
+	// Confirmation of self-reported results by the opponent (unanswered ones confirm automatically)
+	r.HandleFunc("/api/results/pending", AuthMiddleware(HandleGetPendingResults)).Methods("GET")
+	r.HandleFunc("/api/results/{gameId}/confirm", AuthMiddleware(HandleConfirmResult)).Methods("POST")
+
+	// Cross-app game history (games post completed games; the shell profile page reads them)
+	r.HandleFunc("/api/history", AuthMiddleware(HandleRecordHistory)).Methods("POST")
+	r.HandleFunc("/api/history/me", AuthMiddleware(HandleGetMyHistory)).Methods("GET")
+
+	// League management (admins; venue admins for their own venue)
+	r.HandleFunc("/api/leagues", AuthMiddleware(HandleCreateLeague)).Methods("POST")
+	r.HandleFunc("/api/leagues/{slug}/games", AuthMiddleware(HandleAssignLeagueGames)).Methods("POST", "DELETE")
+
+	// Handicap management (admins)
+	r.HandleFunc("/api/handicaps/{gameType}", AuthMiddleware(HandleSetHandicapRule)).Methods("PUT", "DELETE")
+	r.HandleFunc("/api/handicaps/{gameType}/players/{playerId}", AuthMiddleware(HandleSetPlayerHandicap)).Methods("PUT")
+
 	// Serve static frontend files (React build output)
 	staticDir := getEnv("STATIC_DIR", "./static")
 	r.PathPrefix("/").Handler(spaHandler{staticPath: staticDir, indexPath: "index.html"})
 
 	// CORS configuration
-	corsHandler := handlers.CORS(
-		handlers.AllowedOrigins([]string{"*"}),
-		handlers.AllowedMethods([]string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}),
-		handlers.AllowedHeaders([]string{"Content-Type", "Authorization"}),
-		handlers.AllowCredentials(),
-	)
+	cors := apphttp.NewCORSPolicy(identityDB)
+	appMaintenance := maintenance.New(identityDB)
 
 	// Start server
 	port := getEnv("BACKEND_PORT", BACKEND_PORT)
 	log.Printf("🚀 %s backend listening on :%s", APP_NAME, port)
-	log.Fatal(http.ListenAndServe(":"+port, corsHandler(r)))
+	log.Fatal(http.ListenAndServe(":"+port, cors.Middleware(appMaintenance.Middleware("leaderboard")(r))))
 }
 
 // handleHealth - Health check endpoint
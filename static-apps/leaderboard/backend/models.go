@@ -14,7 +14,17 @@ type GameResult struct {
 	IsDraw     bool      `json:"isDraw"`
 	Score      string    `json:"score"`      // e.g., "3-2" for first-to-3
 	Duration   int       `json:"duration"`   // Game duration in seconds
+	VenueID    int       `json:"venueId,omitempty"`
 	PlayedAt   time.Time `json:"playedAt"`
+
+	// Team games (doubles etc.) - winner/loser names are the team names
+	WinnerTeamID int `json:"winnerTeamId,omitempty"`
+	LoserTeamID  int `json:"loserTeamId,omitempty"`
+
+	League string `json:"league,omitempty"` // league slug (defaults to the game type)
+
+	Confirmation string     `json:"confirmation"`        // confirmed, provisional or disputed
+	ConfirmBy    *time.Time `json:"confirmBy,omitempty"` // provisional: confirmed automatically after this
 }
 
 // PlayerStats represents a player's stats for a specific game type
@@ -31,15 +41,111 @@ type PlayerStats struct {
 
 // Standing represents a player's position in the leaderboard
 type Standing struct {
-	Rank       int     `json:"rank"`
-	PlayerID   string  `json:"playerId"`
-	PlayerName string  `json:"playerName"`
-	Wins       int     `json:"wins"`
-	Losses     int     `json:"losses"`
-	Draws      int     `json:"draws"`
-	TotalGames int     `json:"totalGames"`
-	WinRate    float64 `json:"winRate"`
-	Points     int     `json:"points"` // 3 for win, 1 for draw, 0 for loss
+	Rank        int     `json:"rank"`
+	PlayerID    string  `json:"playerId"`
+	PlayerName  string  `json:"playerName"`
+	Wins        int     `json:"wins"`
+	Losses      int     `json:"losses"`
+	Draws       int     `json:"draws"`
+	TotalGames  int     `json:"totalGames"`
+	WinRate     float64 `json:"winRate"`
+	Points      int     `json:"points"`                // 3 for win, 1 for draw, 0 for loss
+	Unconfirmed int     `json:"unconfirmed,omitempty"` // Provisional or disputed results counted above
+	Flair       string  `json:"flair,omitempty"`
+	AvatarURL   string  `json:"avatarUrl,omitempty"`
+}
+
+// TeamMember is one player in a team
+type TeamMember struct {
+	PlayerID   string `json:"playerId"`
+	PlayerName string `json:"playerName"`
+	Flair      string `json:"flair,omitempty"`
+	AvatarURL  string `json:"avatarUrl,omitempty"`
+}
+
+// Team is a fixed line-up of players (a doubles pair is a team of two)
+type Team struct {
+	ID      int          `json:"id"`
+	Name    string       `json:"name"`
+	Members []TeamMember `json:"members"`
+}
+
+// TeamStanding represents a team's position in the team leaderboard
+type TeamStanding struct {
+	Rank        int          `json:"rank"`
+	TeamID      int          `json:"teamId"`
+	TeamName    string       `json:"teamName"`
+	Members     []TeamMember `json:"members"`
+	Wins        int          `json:"wins"`
+	Losses      int          `json:"losses"`
+	Draws       int          `json:"draws"`
+	TotalGames  int          `json:"totalGames"`
+	WinRate     float64      `json:"winRate"`
+	Points      int          `json:"points"`
+	Unconfirmed int          `json:"unconfirmed,omitempty"`
+}
+
+// League groups results so standings can be kept separate (e.g. a weekly pool league)
+type League struct {
+	Slug      string    `json:"slug"`
+	Name      string    `json:"name"`
+	GameType  string    `json:"gameType"`
+	VenueID   int       `json:"venueId,omitempty"`
+	CreatedBy string    `json:"createdBy,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	Games     int       `json:"games"`
+}
+
+// Dispute is a player's challenge to a reported result
+type Dispute struct {
+	ID         int        `json:"id"`
+	ResultID   int        `json:"resultId"`
+	GameID     string     `json:"gameId"`
+	GameType   string     `json:"gameType"`
+	Reason     string     `json:"reason"`
+	Status     string     `json:"status"` // open, upheld, rejected
+	Resolution string     `json:"resolution,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	ResolvedAt *time.Time `json:"resolvedAt,omitempty"`
+}
+
+// HistoryPlayer is one participant in a game history record
+type HistoryPlayer struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Outcome string `json:"outcome,omitempty"` // win, loss, draw
+}
+
+// HistoryRecord is a compact record of a completed game from any app
+type HistoryRecord struct {
+	ID          int                    `json:"id"`
+	App         string                 `json:"app"`
+	GameID      string                 `json:"gameId"`
+	Players     []HistoryPlayer        `json:"players"`
+	Result      string                 `json:"result,omitempty"`
+	Duration    int                    `json:"duration"`
+	Options     map[string]interface{} `json:"options,omitempty"`
+	Outcome     string                 `json:"outcome,omitempty"` // the requesting player's outcome
+	CompletedAt time.Time              `json:"completedAt"`
+}
+
+// HandicapRule is what a handicap means in a game type
+type HandicapRule struct {
+	GameType    string `json:"gameType"`
+	Kind        string `json:"kind"`        // start_score or frames
+	Step        int    `json:"step"`        // Suggested handicaps are multiples of this
+	MaxHandicap int    `json:"maxHandicap"` // The biggest head start
+}
+
+// PlayerHandicap is a player's handicap in a game type and the rating behind its suggestion
+type PlayerHandicap struct {
+	PlayerID   string `json:"playerId"`
+	PlayerName string `json:"playerName"`
+	Rating     int    `json:"rating"`
+	Games      int    `json:"games"`
+	Handicap   int    `json:"handicap"`            // What matches are set up with
+	Suggested  *int   `json:"suggested,omitempty"` // nil until the player has enough results
+	Manual     bool   `json:"manual"`              // Set by an admin rather than suggested
 }
 
 // Config holds app configuration
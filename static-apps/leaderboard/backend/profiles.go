@@ -0,0 +1,134 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+
+	"github.com/lib/pq"
+)
+
+// playerProfile is the public identity shown on the scoreboard instead of an email
+type playerProfile struct {
+	Nickname  string
+	Flair     string
+	AvatarURL string // served by identity-shell
+	Anonymous bool   // privacy_anonymous: show alias, hide email/avatar
+	Alias     string // privacy_alias
+}
+
+// anonymousAlias returns a stable, non-reversible placeholder for an email
+// (matches auth.AnonymousAlias in activity-hub-common)
+func anonymousAlias(email string) string {
+	sum := sha256.Sum256([]byte(email))
+	return "Player " + hex.EncodeToString(sum[:2])
+}
+
+// anonymousID replaces an email in public responses for anonymous players
+func anonymousID(email string) string {
+	sum := sha256.Sum256([]byte(email))
+	return "anon-" + hex.EncodeToString(sum[:8])
+}
+
+// publicName returns the alias for anonymous players, otherwise the nickname (may be empty)
+func (p playerProfile) publicName(email string) string {
+	if !p.Anonymous {
+		return p.Nickname
+	}
+	if p.Alias != "" {
+		return p.Alias
+	}
+	return anonymousAlias(email)
+}
+
+// loadProfiles fetches nickname, flair and avatar for a set of players from the identity database.
+// Best effort: an empty map is returned if the lookup fails.
+func loadProfiles(emails []string) map[string]playerProfile {
+	profiles := map[string]playerProfile{}
+	if len(emails) == 0 {
+		return profiles
+	}
+
+	rows, err := identityDB.Query(`
+		SELECT u.email, COALESCE(p.nickname, ''), COALESCE(p.flair, ''), COALESCE(p.avatar_hash, ''),
+		       COALESCE(anon.value = 'true'::jsonb, FALSE), COALESCE(alias.value #>> '{}', '')
+		FROM users u
+		LEFT JOIN user_profiles p ON p.user_email = u.email
+		LEFT JOIN user_settings anon ON anon.user_email = u.email AND anon.key = 'privacy_anonymous'
+		LEFT JOIN user_settings alias ON alias.user_email = u.email AND alias.key = 'privacy_alias'
+		WHERE u.email = ANY($1)
+	`, pq.Array(emails))
+	if err != nil {
+		log.Printf("⚠️ Failed to load player profiles: %v", err)
+		return profiles
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var email, avatarHash string
+		var p playerProfile
+		if err := rows.Scan(&email, &p.Nickname, &p.Flair, &avatarHash, &p.Anonymous, &p.Alias); err != nil {
+			continue
+		}
+		if avatarHash != "" && !p.Anonymous {
+			p.AvatarURL = "/api/avatars/" + avatarHash
+		}
+		profiles[email] = p
+	}
+
+	return profiles
+}
+
+// attachStandingProfiles swaps player names for nicknames and adds flair/avatar.
+// Anonymous players get an alias and their email is replaced with an opaque ID.
+func attachStandingProfiles(standings []Standing) {
+	emails := make([]string, 0, len(standings))
+	for _, s := range standings {
+		emails = append(emails, s.PlayerID)
+	}
+
+	profiles := loadProfiles(emails)
+	for i := range standings {
+		p, ok := profiles[standings[i].PlayerID]
+		if !ok {
+			continue
+		}
+		if name := p.publicName(standings[i].PlayerID); name != "" {
+			standings[i].PlayerName = name
+		}
+		if p.Anonymous {
+			standings[i].PlayerID = anonymousID(standings[i].PlayerID)
+			continue
+		}
+		standings[i].Flair = p.Flair
+		standings[i].AvatarURL = p.AvatarURL
+	}
+}
+
+// attachResultProfiles swaps winner/loser names for nicknames (or aliases for anonymous players)
+func attachResultProfiles(results []GameResult) {
+	emails := make([]string, 0, len(results)*2)
+	for _, r := range results {
+		emails = append(emails, r.WinnerID, r.LoserID)
+	}
+
+	profiles := loadProfiles(emails)
+	for i := range results {
+		if p, ok := profiles[results[i].WinnerID]; ok {
+			if name := p.publicName(results[i].WinnerID); name != "" {
+				results[i].WinnerName = name
+			}
+			if p.Anonymous {
+				results[i].WinnerID = anonymousID(results[i].WinnerID)
+			}
+		}
+		if p, ok := profiles[results[i].LoserID]; ok {
+			if name := p.publicName(results[i].LoserID); name != "" {
+				results[i].LoserName = name
+			}
+			if p.Anonymous {
+				results[i].LoserID = anonymousID(results[i].LoserID)
+			}
+		}
+	}
+}
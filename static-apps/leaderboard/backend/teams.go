@@ -0,0 +1,356 @@
+package main
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/lib/pq"
+)
+
+// maxTeamSize caps team line-ups (doubles = 2, but allow small team games)
+const maxTeamSize = 6
+
+// TeamInput is a team as reported by a game
+type TeamInput struct {
+	Name    string `json:"name"` // optional custom name, e.g. "The Arrows"
+	Players []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"players"`
+}
+
+// teamKey identifies a line-up regardless of player order
+func teamKey(playerIDs []string) string {
+	ids := make([]string, len(playerIDs))
+	for i, id := range playerIDs {
+		ids[i] = strings.ToLower(strings.TrimSpace(id))
+	}
+	sort.Strings(ids)
+	sum := sha256.Sum256([]byte(strings.Join(ids, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+func (t *TeamInput) playerIDs() []string {
+	ids := make([]string, 0, len(t.Players))
+	for _, p := range t.Players {
+		ids = append(ids, p.ID)
+	}
+	return ids
+}
+
+// validateTeams checks both sides of a team result
+func validateTeams(winner, loser *TeamInput) error {
+	if winner == nil || loser == nil {
+		return fmt.Errorf("team results need both winnerTeam and loserTeam")
+	}
+
+	seen := map[string]bool{}
+	for _, team := range []*TeamInput{winner, loser} {
+		if len(team.Players) < 2 || len(team.Players) > maxTeamSize {
+			return fmt.Errorf("teams must have between 2 and %d players", maxTeamSize)
+		}
+		if len(team.Name) > 100 {
+			return fmt.Errorf("team name too long")
+		}
+		for _, p := range team.Players {
+			id := strings.ToLower(strings.TrimSpace(p.ID))
+			if id == "" {
+				return fmt.Errorf("every team player needs an id")
+			}
+			if seen[id] {
+				return fmt.Errorf("player %s appears more than once", p.ID)
+			}
+			seen[id] = true
+		}
+	}
+	return nil
+}
+
+// resolveTeam finds or creates the team for a line-up and refreshes member names
+func resolveTeam(tx *sql.Tx, team *TeamInput) (int, error) {
+	var teamID int
+	err := tx.QueryRow(`
+		INSERT INTO teams (team_key, name)
+		VALUES ($1, NULLIF($2, ''))
+		ON CONFLICT (team_key) DO UPDATE SET name = COALESCE(NULLIF(EXCLUDED.name, ''), teams.name)
+		RETURNING id
+	`, teamKey(team.playerIDs()), strings.TrimSpace(team.Name)).Scan(&teamID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to resolve team: %w", err)
+	}
+
+	for _, p := range team.Players {
+		_, err := tx.Exec(`
+			INSERT INTO team_members (team_id, player_id, player_name)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (team_id, player_id) DO UPDATE SET player_name = EXCLUDED.player_name
+		`, teamID, strings.TrimSpace(p.ID), p.Name)
+		if err != nil {
+			return 0, fmt.Errorf("failed to save team member: %w", err)
+		}
+	}
+	return teamID, nil
+}
+
+// recordTeamResult stores a team game result in one transaction.
+// reportedBy is set for self-reported results, which start provisional.
+func recordTeamResult(gameType, gameID, league string, winner, loser *TeamInput, isDraw bool, score string, duration int, venueID interface{}, reportedBy string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	winnerTeamID, err := resolveTeam(tx, winner)
+	if err != nil {
+		return err
+	}
+	loserTeamID, err := resolveTeam(tx, loser)
+	if err != nil {
+		return err
+	}
+
+	confirmation, confirmBy := newConfirmation(reportedBy)
+	_, err = tx.Exec(`
+		INSERT INTO game_results (game_type, game_id, is_draw, score, duration, venue_id, winner_team_id, loser_team_id, league,
+		                          confirmation, reported_by, confirm_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NULLIF($11, ''), $12)
+		ON CONFLICT (game_id) DO NOTHING
+	`, gameType, gameID, isDraw, score, duration, venueID, winnerTeamID, loserTeamID, league, confirmation, reportedBy, confirmBy)
+	if err != nil {
+		return fmt.Errorf("failed to insert team result: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// loadTeams fetches line-ups with public member names (nicknames/aliases, like individual standings).
+// Teams without a custom name are named after their members, e.g. "Alice & Bob".
+func loadTeams(teamIDs []int) map[int]Team {
+	teams := map[int]Team{}
+	if len(teamIDs) == 0 {
+		return teams
+	}
+
+	rows, err := db.Query(`
+		SELECT t.id, COALESCE(t.name, ''), m.player_id, COALESCE(m.player_name, '')
+		FROM teams t
+		JOIN team_members m ON m.team_id = t.id
+		WHERE t.id = ANY($1)
+		ORDER BY t.id, m.player_id
+	`, pq.Array(teamIDs))
+	if err != nil {
+		log.Printf("⚠️ Failed to load teams: %v", err)
+		return teams
+	}
+	defer rows.Close()
+
+	emails := []string{}
+	for rows.Next() {
+		var id int
+		var name string
+		var m TeamMember
+		if err := rows.Scan(&id, &name, &m.PlayerID, &m.PlayerName); err != nil {
+			continue
+		}
+		t := teams[id]
+		t.ID = id
+		t.Name = name
+		t.Members = append(t.Members, m)
+		teams[id] = t
+		emails = append(emails, m.PlayerID)
+	}
+
+	profiles := loadProfiles(emails)
+	for id, t := range teams {
+		names := make([]string, 0, len(t.Members))
+		for i := range t.Members {
+			m := &t.Members[i]
+			if p, ok := profiles[m.PlayerID]; ok {
+				if name := p.publicName(m.PlayerID); name != "" {
+					m.PlayerName = name
+				}
+				if p.Anonymous {
+					m.PlayerID = anonymousID(m.PlayerID)
+				} else {
+					m.Flair = p.Flair
+					m.AvatarURL = p.AvatarURL
+				}
+			}
+			names = append(names, m.PlayerName)
+		}
+		if t.Name == "" {
+			t.Name = strings.Join(names, " & ")
+		}
+		teams[id] = t
+	}
+
+	return teams
+}
+
+// attachResultTeams replaces winner/loser names on team results with current team names
+func attachResultTeams(results []GameResult) {
+	ids := []int{}
+	for _, r := range results {
+		if r.WinnerTeamID != 0 {
+			ids = append(ids, r.WinnerTeamID, r.LoserTeamID)
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	teams := loadTeams(ids)
+	for i := range results {
+		if t, ok := teams[results[i].WinnerTeamID]; ok {
+			results[i].WinnerName = t.Name
+		}
+		if t, ok := teams[results[i].LoserTeamID]; ok {
+			results[i].LoserName = t.Name
+		}
+	}
+}
+
+// HandleGetTeamStandings - GET /api/teams/standings/{gameType}?venue={id}&league={slug}
+// Team leaderboard for a game type, scored the same way as individuals
+func HandleGetTeamStandings(w http.ResponseWriter, r *http.Request) {
+	gameType := mux.Vars(r)["gameType"]
+	venueID, ok := venueParam(w, r)
+	if !ok {
+		return
+	}
+
+	standings, err := queryTeamStandings(gameType, venueID, r.URL.Query().Get("league"))
+	if err != nil {
+		log.Printf("Failed to query team standings: %v", err)
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(standings)
+}
+
+// queryTeamStandings calculates team standings (venueID 0 / league "" = all)
+func queryTeamStandings(gameType string, venueID int, league string) ([]TeamStanding, error) {
+	rows, err := db.Query(`
+		WITH team_stats AS (
+			SELECT winner_team_id AS team_id,
+			       SUM(CASE WHEN is_draw THEN 0 ELSE 1 END) AS wins,
+			       0 AS losses,
+			       SUM(CASE WHEN is_draw THEN 1 ELSE 0 END) AS draws,
+			       COUNT(*) FILTER (WHERE confirmation <> 'confirmed') AS unconfirmed
+			FROM game_results
+			WHERE game_type = $1 AND winner_team_id IS NOT NULL AND NOT voided AND ($2 = 0 OR venue_id = $2) AND ($3 = '' OR league = $3)
+			GROUP BY winner_team_id
+
+			UNION ALL
+
+			SELECT loser_team_id AS team_id,
+			       0 AS wins,
+			       SUM(CASE WHEN is_draw THEN 0 ELSE 1 END) AS losses,
+			       SUM(CASE WHEN is_draw THEN 1 ELSE 0 END) AS draws,
+			       COUNT(*) FILTER (WHERE confirmation <> 'confirmed') AS unconfirmed
+			FROM game_results
+			WHERE game_type = $1 AND loser_team_id IS NOT NULL AND NOT voided AND ($2 = 0 OR venue_id = $2) AND ($3 = '' OR league = $3)
+			GROUP BY loser_team_id
+		)
+		SELECT team_id, SUM(wins), SUM(losses), SUM(draws),
+		       SUM(wins) + SUM(losses) + SUM(draws) AS total_games,
+		       SUM(wins) * 3 + SUM(draws) AS points,
+		       SUM(unconfirmed)
+		FROM team_stats
+		GROUP BY team_id
+		ORDER BY points DESC, SUM(wins) DESC, total_games DESC
+		LIMIT 50
+	`, gameType, venueID, league)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	standings := []TeamStanding{}
+	ids := []int{}
+	for rows.Next() {
+		var s TeamStanding
+		if err := rows.Scan(&s.TeamID, &s.Wins, &s.Losses, &s.Draws, &s.TotalGames, &s.Points, &s.Unconfirmed); err != nil {
+			continue
+		}
+		s.Rank = len(standings) + 1
+		if s.TotalGames > 0 {
+			s.WinRate = float64(s.Wins) / float64(s.TotalGames) * 100
+		}
+		standings = append(standings, s)
+		ids = append(ids, s.TeamID)
+	}
+
+	teams := loadTeams(ids)
+	for i := range standings {
+		t := teams[standings[i].TeamID]
+		standings[i].TeamName = t.Name
+		standings[i].Members = t.Members
+	}
+
+	return standings, nil
+}
+
+// HandleGetTeam - GET /api/teams/{teamId}
+// Returns a team's line-up and its record per game type
+func HandleGetTeam(w http.ResponseWriter, r *http.Request) {
+	teamID, err := strconv.Atoi(mux.Vars(r)["teamId"])
+	if err != nil {
+		http.Error(w, "Invalid team ID", http.StatusBadRequest)
+		return
+	}
+
+	team, ok := loadTeams([]int{teamID})[teamID]
+	if !ok {
+		http.Error(w, "Team not found", http.StatusNotFound)
+		return
+	}
+
+	rows, err := db.Query(`
+		SELECT game_type,
+		       SUM(CASE WHEN winner_team_id = $1 AND NOT is_draw THEN 1 ELSE 0 END),
+		       SUM(CASE WHEN loser_team_id = $1 AND NOT is_draw THEN 1 ELSE 0 END),
+		       SUM(CASE WHEN is_draw THEN 1 ELSE 0 END)
+		FROM game_results
+		WHERE (winner_team_id = $1 OR loser_team_id = $1) AND NOT voided
+		GROUP BY game_type
+	`, teamID)
+	if err != nil {
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	stats := []PlayerStats{}
+	for rows.Next() {
+		var s PlayerStats
+		if err := rows.Scan(&s.GameType, &s.Wins, &s.Losses, &s.Draws); err != nil {
+			continue
+		}
+		s.PlayerID = strconv.Itoa(teamID)
+		s.PlayerName = team.Name
+		s.TotalGames = s.Wins + s.Losses + s.Draws
+		if s.TotalGames > 0 {
+			s.WinRate = float64(s.Wins) / float64(s.TotalGames) * 100
+		}
+		stats = append(stats, s)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"team":  team,
+		"stats": stats,
+	})
+}
@@ -161,7 +161,14 @@ function App() {
                             {s.rank}
                           </span>
                         </td>
-                        <td><strong>{s.playerName}</strong></td>
+                        <td>
+                          <strong>{s.playerName}</strong>
+                          {s.unconfirmed > 0 && (
+                            <span className="result-provisional" title="Results still waiting for the opponent to confirm">
+                              {s.unconfirmed} unconfirmed
+                            </span>
+                          )}
+                        </td>
                         <td>{s.wins}</td>
                         <td>{s.losses}</td>
                         <td>{s.draws}</td>
@@ -202,6 +209,14 @@ function App() {
                       )}
                     </div>
                     <div className="result-meta">
+                      {game.confirmation === 'provisional' && (
+                        <span className="result-provisional" title="Reported by one player; waiting for the opponent to confirm">
+                          Provisional
+                        </span>
+                      )}
+                      {game.confirmation === 'disputed' && (
+                        <span className="result-provisional">Disputed</span>
+                      )}
                       {formatDate(game.playedAt)}
                     </div>
                   </div>
@@ -0,0 +1,315 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Service states, as shown by status
+const (
+	stateStarting  = "starting"  // Running, not yet answering health checks
+	stateHealthy   = "healthy"   // Answering
+	stateUnhealthy = "unhealthy" // Was healthy, now failing checks
+	stateBackoff   = "backoff"   // Exited; waiting to start again
+	stateStopped   = "stopped"
+)
+
+const (
+	minBackoff = time.Second
+	maxBackoff = time.Minute
+)
+
+// ServiceStatus is one service's state for the status API
+type ServiceStatus struct {
+	Name      string     `json:"name"`
+	Port      int        `json:"port"`
+	State     string     `json:"state"`
+	PID       int        `json:"pid,omitempty"`
+	Since     *time.Time `json:"since,omitempty"` // When it entered this state
+	Restarts  int        `json:"restarts"`
+	LastError string     `json:"lastError,omitempty"`
+}
+
+// child looks after one service: start it, watch it, start it again
+type child struct {
+	svc     Service
+	cfg     *Config
+	out     io.Writer     // The supervisor's stdout, lines prefixed with the name
+	restart chan struct{} // Manual restart requests
+	quit    chan struct{}
+	done    chan struct{}
+
+	mu     sync.Mutex
+	status ServiceStatus
+}
+
+func newChild(cfg *Config, svc Service) *child {
+	return &child{
+		svc:     svc,
+		cfg:     cfg,
+		out:     prefixWriter(os.Stdout, svc.Name),
+		restart: make(chan struct{}, 1),
+		quit:    make(chan struct{}),
+		done:    make(chan struct{}),
+		status:  ServiceStatus{Name: svc.Name, Port: svc.Port, State: stateStopped},
+	}
+}
+
+func (c *child) snapshot() ServiceStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.status
+}
+
+func (c *child) setState(state string, pid int, lastErr string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.status.State != state {
+		now := time.Now()
+		c.status.Since = &now
+	}
+	c.status.State = state
+	c.status.PID = pid
+	if lastErr != "" {
+		c.status.LastError = lastErr
+	}
+}
+
+// requestRestart restarts the service now, skipping any backoff
+func (c *child) requestRestart() {
+	select {
+	case c.restart <- struct{}{}:
+	default: // One's already pending
+	}
+}
+
+// run keeps the service up until quit is closed
+func (c *child) run() {
+	defer close(c.done)
+	backoff := minBackoff
+
+	for {
+		healthy, reason := c.runOnce()
+
+		select {
+		case <-c.quit:
+			c.setState(stateStopped, 0, "")
+			return
+		default:
+		}
+
+		// A service that got healthy is a fresh start; one that never did
+		// waits longer each time so a broken one doesn't spin
+		if healthy {
+			backoff = minBackoff
+		}
+		c.mu.Lock()
+		c.status.Restarts++
+		c.mu.Unlock()
+		c.setState(stateBackoff, 0, reason)
+		log.Printf("⚠️  %s: %s; starting again in %v", c.svc.Name, reason, backoff)
+
+		select {
+		case <-c.quit:
+			c.setState(stateStopped, 0, "")
+			return
+		case <-c.restart:
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// runOnce starts the service and watches it until it exits, fails its
+// health checks or is told to stop or restart. It reports whether the
+// service was ever healthy and why it ended.
+func (c *child) runOnce() (wasHealthy bool, reason string) {
+	cmd, err := c.command()
+	if err != nil {
+		return false, err.Error()
+	}
+	if err := cmd.Start(); err != nil {
+		return false, fmt.Sprintf("failed to start: %v", err)
+	}
+	c.setState(stateStarting, cmd.Process.Pid, "")
+	log.Printf("🚀 %s started (pid %d)", c.svc.Name, cmd.Process.Pid)
+
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+
+	ticker := time.NewTicker(time.Duration(c.cfg.HealthInterval))
+	defer ticker.Stop()
+	startDeadline := time.Now().Add(time.Duration(c.cfg.StartTimeout))
+	failures := 0
+
+	for {
+		select {
+		case err := <-exited:
+			// go run has gone; don't leave the server it started behind
+			syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+			if err == nil {
+				return wasHealthy, "exited"
+			}
+			return wasHealthy, fmt.Sprintf("exited: %v", err)
+
+		case <-c.quit:
+			c.stopProcess(cmd, exited)
+			return wasHealthy, "stopped"
+
+		case <-c.restart:
+			log.Printf("🔄 %s: restart requested", c.svc.Name)
+			c.stopProcess(cmd, exited)
+			return wasHealthy, "restarted on request"
+
+		case <-ticker.C:
+			err := c.check()
+			if err == nil {
+				if !wasHealthy || failures > 0 {
+					log.Printf("✅ %s healthy", c.svc.Name)
+				}
+				wasHealthy = true
+				failures = 0
+				c.setState(stateHealthy, cmd.Process.Pid, "")
+				continue
+			}
+
+			// Still compiling or connecting: go run on a Pi takes a while
+			if !wasHealthy && time.Now().Before(startDeadline) {
+				continue
+			}
+			failures++
+			c.setState(stateUnhealthy, cmd.Process.Pid, err.Error())
+			if failures < c.cfg.UnhealthyAfter {
+				continue
+			}
+			c.stopProcess(cmd, exited)
+			if !wasHealthy {
+				return false, fmt.Sprintf("not healthy after %v: %v", time.Duration(c.cfg.StartTimeout), err)
+			}
+			return true, fmt.Sprintf("failed %d health checks: %v", failures, err)
+		}
+	}
+}
+
+// command builds the service's process: its own process group, so stopping
+// it stops what go run started too
+func (c *child) command() (*exec.Cmd, error) {
+	argv := c.svc.Command
+	if len(argv) == 0 {
+		argv = c.cfg.Command
+	}
+	dir := c.svc.Dir
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(c.cfg.Root, dir)
+	}
+	if _, err := os.Stat(dir); err != nil {
+		return nil, fmt.Errorf("no service directory: %v", err)
+	}
+
+	cmd := exec.Command(argv[0], argv[1:]...)
+	cmd.Dir = dir
+	cmd.Env = c.env()
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Stdout = c.out
+	cmd.Stderr = c.out
+	// A leftover grandchild holding the output open mustn't stall Wait
+	cmd.WaitDelay = time.Duration(c.cfg.StopTimeout)
+	return cmd, nil
+}
+
+// env is the supervisor's environment plus the pool caps, the shared
+// variables and the service's own, in that order so the service wins
+func (c *child) env() []string {
+	env := os.Environ()
+	if c.cfg.DBPool.MaxOpen > 0 {
+		env = append(env, fmt.Sprintf("DB_MAX_OPEN_CONNS=%d", c.cfg.DBPool.MaxOpen))
+	}
+	if c.cfg.DBPool.MaxIdle > 0 {
+		env = append(env, fmt.Sprintf("DB_MAX_IDLE_CONNS=%d", c.cfg.DBPool.MaxIdle))
+	}
+	for k, v := range c.cfg.Env {
+		env = append(env, k+"="+v)
+	}
+	for k, v := range c.svc.Env {
+		env = append(env, k+"="+v)
+	}
+	return env
+}
+
+// stopProcess asks the service's process group to stop, then makes it
+func (c *child) stopProcess(cmd *exec.Cmd, exited <-chan error) {
+	pgid := cmd.Process.Pid
+	syscall.Kill(-pgid, syscall.SIGTERM)
+	select {
+	case <-exited:
+	case <-time.After(time.Duration(c.cfg.StopTimeout)):
+		log.Printf("⚠️  %s didn't stop in %v; killing it", c.svc.Name, time.Duration(c.cfg.StopTimeout))
+		syscall.Kill(-pgid, syscall.SIGKILL)
+		<-exited
+	}
+}
+
+var healthClient = &http.Client{Timeout: 5 * time.Second}
+
+// check is one health check: GET the health path, or with none, connect to
+// the port. Any answer short of a 5xx counts; a backend whose health route
+// needs a login still proves it's up.
+func (c *child) check() error {
+	if c.svc.Port == 0 {
+		return nil // Nothing to check; exiting is the only failure
+	}
+	addr := fmt.Sprintf("127.0.0.1:%d", c.svc.Port)
+	if c.svc.Health == "" {
+		conn, err := net.DialTimeout("tcp", addr, 5*time.Second)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+
+	resp, err := healthClient.Get("http://" + addr + c.svc.Health)
+	if err != nil {
+		return err
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("health check returned %s", resp.Status)
+	}
+	return nil
+}
+
+// prefixWriter writes each line to w as "[name] line", keeping lines from
+// different services whole
+func prefixWriter(w io.Writer, name string) io.Writer {
+	pr, pw := io.Pipe()
+	go func() {
+		scanner := bufio.NewScanner(pr)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		prefix := []byte("[" + name + "] ")
+		for scanner.Scan() {
+			line := append(append(append([]byte{}, prefix...), scanner.Bytes()...), '\n')
+			outputMu.Lock()
+			w.Write(line)
+			outputMu.Unlock()
+		}
+		// A line too long to scan: drain the rest so the service never blocks
+		io.Copy(io.Discard, pr)
+	}()
+	return pw
+}
+
+var outputMu sync.Mutex
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Config is the supervisor's file (supervisor.json): which backends to run
+// and how to look after them
+type Config struct {
+	// Root is the repository checkout; service directories are relative to
+	// it. A relative root is relative to the config file.
+	Root string `json:"root"`
+
+	// Status is where the status API listens (status and restart commands)
+	Status string `json:"status"`
+
+	// Command starts a service from its directory, e.g. ["go", "run", "."]
+	// or ["./backend"] for built binaries
+	Command []string `json:"command"`
+
+	HealthInterval Duration `json:"healthInterval"` // Between health checks
+	StartTimeout   Duration `json:"startTimeout"`   // Grace for compiling and connecting before checks count
+	UnhealthyAfter int      `json:"unhealthyAfter"` // Failed checks in a row before a restart
+	StopTimeout    Duration `json:"stopTimeout"`    // From SIGTERM to SIGKILL
+
+	// DBPool caps every service's PostgreSQL pools (DB_MAX_OPEN_CONNS and
+	// DB_MAX_IDLE_CONNS), so all of them together stay under max_connections
+	DBPool struct {
+		MaxOpen int `json:"maxOpen"`
+		MaxIdle int `json:"maxIdle"`
+	} `json:"dbPool"`
+
+	// Env is added to every service's environment
+	Env map[string]string `json:"env"`
+
+	Services []Service `json:"services"`
+}
+
+// Service is one backend
+type Service struct {
+	Name    string            `json:"name"`
+	Dir     string            `json:"dir"`
+	Port    int               `json:"port"`
+	Health  string            `json:"health"`  // HTTP path; empty checks the port accepts connections
+	Command []string          `json:"command"` // Overrides Config.Command
+	Env     map[string]string `json:"env"`
+	Enabled *bool             `json:"enabled"` // Default true
+}
+
+func (s Service) enabled() bool {
+	return s.Enabled == nil || *s.Enabled
+}
+
+// Duration is a time.Duration written as "10s" in the config file
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("durations are strings like \"10s\": %w", err)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// loadConfig reads the config file, filling in defaults
+func loadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &Config{
+		Root:           ".",
+		Status:         "127.0.0.1:5110",
+		Command:        []string{"go", "run", "."},
+		HealthInterval: Duration(10 * time.Second),
+		StartTimeout:   Duration(3 * time.Minute),
+		UnhealthyAfter: 3,
+		StopTimeout:    Duration(10 * time.Second),
+	}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if !filepath.IsAbs(cfg.Root) {
+		cfg.Root = filepath.Join(filepath.Dir(path), cfg.Root)
+	}
+	if len(cfg.Command) == 0 {
+		return nil, fmt.Errorf("%s: command is empty", path)
+	}
+	if cfg.UnhealthyAfter < 1 {
+		cfg.UnhealthyAfter = 1
+	}
+
+	names := map[string]bool{}
+	ports := map[int]string{}
+	for _, s := range cfg.Services {
+		if s.Name == "" || s.Dir == "" {
+			return nil, fmt.Errorf("%s: every service needs a name and a dir", path)
+		}
+		if names[s.Name] {
+			return nil, fmt.Errorf("%s: service %s is listed twice", path, s.Name)
+		}
+		names[s.Name] = true
+		if !s.enabled() || s.Port == 0 {
+			continue
+		}
+		if other, ok := ports[s.Port]; ok {
+			return nil, fmt.Errorf("%s: %s and %s both use port %d", path, other, s.Name, s.Port)
+		}
+		ports[s.Port] = s.Name
+	}
+	return cfg, nil
+}
+
+// selected returns the services to run: the named ones, or every enabled one
+func (c *Config) selected(names []string) ([]Service, error) {
+	if len(names) == 0 {
+		var out []Service
+		for _, s := range c.Services {
+			if s.enabled() {
+				out = append(out, s)
+			}
+		}
+		return out, nil
+	}
+
+	var out []Service
+	for _, name := range names {
+		found := false
+		for _, s := range c.Services {
+			if s.Name == name {
+				out = append(out, s)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("no service %q in the config", name)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,173 @@
+// Supervisor runs the Activity Hub backends from one config file instead of
+// twenty tmux windows: it starts each as a child process, checks its health
+// and restarts it when it dies or stops answering.
+//
+// Every backend is its own Go module with its own globals and port, and the
+// frontends reach them by port, so they can't share one process or one HTTP
+// listener; what they can share is a database budget, so the supervisor caps
+// each child's PostgreSQL pools (DB_MAX_OPEN_CONNS, DB_MAX_IDLE_CONNS).
+//
+//	go run . -config supervisor.json                      # Everything enabled
+//	go run . -config supervisor.json run identity-shell quiz-player
+//	go run . status
+//	go run . restart quiz-master
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/achgithub/activity-hub-common/cli"
+)
+
+func main() {
+	// -config comes before the command so every command shares it
+	configPath := flag.String("config", "supervisor.json", "Config file")
+	flag.Parse()
+
+	os.Exit(cli.New("supervisor",
+		runCommand(configPath),
+		statusCommand(configPath),
+		restartCommand(configPath),
+	).Run(flag.Args()))
+}
+
+func runCommand(configPath *string) cli.Command {
+	return cli.Command{
+		Name:  "run",
+		Args:  "[service...]",
+		Short: "Start the services (every enabled one if none are named) and keep them up",
+		Run: func(args []string) error {
+			cfg, err := loadConfig(*configPath)
+			if err != nil {
+				return err
+			}
+			services, err := cfg.selected(args)
+			if err != nil {
+				return cli.Usagef("%v", err)
+			}
+			if len(services) == 0 {
+				return fmt.Errorf("no services enabled in %s", *configPath)
+			}
+
+			sup := newSupervisor(cfg, services)
+			server := &http.Server{Addr: cfg.Status, Handler: sup.routes()}
+			go func() {
+				log.Printf("📊 Status API on %s", cfg.Status)
+				if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+					log.Printf("❌ Status API failed: %v", err)
+				}
+			}()
+
+			sup.start()
+			log.Printf("✅ Supervising %d services", len(services))
+
+			stop := make(chan os.Signal, 1)
+			signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+			sig := <-stop
+			log.Printf("🛑 %v: stopping services", sig)
+
+			server.Close()
+			sup.stop()
+			log.Println("✅ All services stopped")
+			return nil
+		},
+	}
+}
+
+func statusCommand(configPath *string) cli.Command {
+	return cli.Command{
+		Name:  "status",
+		Short: "Show each service's state, from a running supervisor",
+		Run: func(args []string) error {
+			if len(args) > 0 {
+				return cli.Usagef("status takes no arguments")
+			}
+			cfg, err := loadConfig(*configPath)
+			if err != nil {
+				return err
+			}
+
+			var statuses []ServiceStatus
+			if err := callSupervisor(cfg, "GET", "/api/status", &statuses); err != nil {
+				return err
+			}
+			sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "SERVICE\tPORT\tSTATE\tPID\tUP\tRESTARTS\tLAST ERROR")
+			for _, s := range statuses {
+				up := "-"
+				if s.Since != nil && s.State == stateHealthy {
+					up = time.Since(*s.Since).Round(time.Second).String()
+				}
+				pid := "-"
+				if s.PID != 0 {
+					pid = fmt.Sprint(s.PID)
+				}
+				fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%d\t%s\n", s.Name, s.Port, s.State, pid, up, s.Restarts, s.LastError)
+			}
+			return w.Flush()
+		},
+	}
+}
+
+func restartCommand(configPath *string) cli.Command {
+	return cli.Command{
+		Name:  "restart",
+		Args:  "<service>",
+		Short: "Restart one service in a running supervisor",
+		Run: func(args []string) error {
+			if len(args) != 1 {
+				return cli.Usagef("expected a service name")
+			}
+			cfg, err := loadConfig(*configPath)
+			if err != nil {
+				return err
+			}
+			if err := callSupervisor(cfg, "POST", "/api/services/"+args[0]+"/restart", nil); err != nil {
+				return err
+			}
+			fmt.Printf("Restarting %s\n", args[0])
+			return nil
+		},
+	}
+}
+
+// callSupervisor calls a running supervisor's status API
+func callSupervisor(cfg *Config, method, path string, out interface{}) error {
+	req, err := http.NewRequest(method, "http://"+cfg.Status+path, nil)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("no supervisor running on %s: %w", cfg.Status, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var body struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&body)
+		if body.Error == "" {
+			body.Error = resp.Status
+		}
+		return fmt.Errorf("%s", body.Error)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync"
+
+	"github.com/gorilla/mux"
+)
+
+// supervisor is the set of children and the status API over them
+type supervisor struct {
+	children []*child
+	byName   map[string]*child
+}
+
+func newSupervisor(cfg *Config, services []Service) *supervisor {
+	s := &supervisor{byName: map[string]*child{}}
+	for _, svc := range services {
+		c := newChild(cfg, svc)
+		s.children = append(s.children, c)
+		s.byName[svc.Name] = c
+	}
+	return s
+}
+
+func (s *supervisor) start() {
+	for _, c := range s.children {
+		go c.run()
+	}
+}
+
+// stop stops every service at once and waits for them all
+func (s *supervisor) stop() {
+	var wg sync.WaitGroup
+	for _, c := range s.children {
+		wg.Add(1)
+		go func(c *child) {
+			defer wg.Done()
+			close(c.quit)
+			<-c.done
+		}(c)
+	}
+	wg.Wait()
+}
+
+// routes is the status API. It listens on localhost by default: restart
+// has no login, as the backends' maintenance commands have none.
+func (s *supervisor) routes() http.Handler {
+	r := mux.NewRouter()
+	r.HandleFunc("/api/health", s.handleHealth).Methods("GET")
+	r.HandleFunc("/api/status", s.handleStatus).Methods("GET")
+	r.HandleFunc("/api/services/{name}/restart", s.handleRestart).Methods("POST")
+	return r
+}
+
+// handleHealth - GET /api/health: healthy once every service is
+func (s *supervisor) handleHealth(w http.ResponseWriter, r *http.Request) {
+	healthy := 0
+	for _, c := range s.children {
+		if c.snapshot().State == stateHealthy {
+			healthy++
+		}
+	}
+	status := http.StatusOK
+	if healthy < len(s.children) {
+		status = http.StatusServiceUnavailable
+	}
+	respondJSON(w, status, map[string]interface{}{
+		"healthy":  healthy,
+		"services": len(s.children),
+	})
+}
+
+// handleStatus - GET /api/status
+func (s *supervisor) handleStatus(w http.ResponseWriter, r *http.Request) {
+	statuses := make([]ServiceStatus, 0, len(s.children))
+	for _, c := range s.children {
+		statuses = append(statuses, c.snapshot())
+	}
+	respondJSON(w, http.StatusOK, statuses)
+}
+
+// handleRestart - POST /api/services/{name}/restart
+func (s *supervisor) handleRestart(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	c, ok := s.byName[name]
+	if !ok {
+		respondJSON(w, http.StatusNotFound, map[string]string{"error": "no service " + name + " in this supervisor"})
+		return
+	}
+	log.Printf("🔄 Restart of %s requested from %s", name, r.RemoteAddr)
+	c.requestRestart()
+	respondJSON(w, http.StatusOK, map[string]bool{"success": true})
+}
+
+func respondJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}